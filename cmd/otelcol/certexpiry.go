@@ -0,0 +1,251 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	certExpiryWarningDaysEnvVarName = "SPLUNK_CERT_EXPIRY_WARNING_DAYS"
+	defaultCertExpiryWarningDays    = 30
+	certExpiryCheckInterval         = time.Hour
+)
+
+// certFileKeys are the config keys, wherever nested in the config, whose values are the paths of certificate files:
+// those of the collector's TLS settings and the Smart Agent monitors' own TLS options.
+var certFileKeys = map[string]bool{
+	"ca_file":        true,
+	"cert_file":      true,
+	"client_ca_file": true,
+	"caCertPath":     true,
+	"clientCertPath": true,
+}
+
+var (
+	configKeyTag = tag.MustNewKey("config_key")
+	fileTag      = tag.MustNewKey("file")
+	subjectTag   = tag.MustNewKey("subject")
+
+	mCertSecondsUntilExpiry = stats.Float64(
+		"tls_certificate/seconds_until_expiry",
+		"Seconds until the certificates of the TLS files referenced by the configuration expire, negative once expired",
+		stats.UnitSeconds,
+	)
+)
+
+// certExpiryWarningDays returns the SPLUNK_CERT_EXPIRY_WARNING_DAYS number of days, if set, or the default.
+// 0 disables the certificate expiry watchdog.
+func certExpiryWarningDays() (int, error) {
+	value := os.Getenv(certExpiryWarningDaysEnvVarName)
+	if value == "" {
+		return defaultCertExpiryWarningDays, nil
+	}
+	days, err := strconv.Atoi(value)
+	if err != nil || days < 0 {
+		return 0, fmt.Errorf("expected a non-negative number of days in %s env variable but got %q", certExpiryWarningDaysEnvVarName, value)
+	}
+	return days, nil
+}
+
+// certExpiryWatchdog is a MapConverter that inventories the certificate files referenced by the resolved
+// config, on startup and on reloads, without changing it. Every check interval, it records the time until
+// each of their certificates expires as the collector's own tls_certificate/seconds_until_expiry metric,
+// and logs a warning, once a day, for those expiring within the warning period or already expired. The
+// files are read on every check, so rotated certificates are picked up.
+type certExpiryWatchdog struct {
+	now    func() time.Time
+	record func(configKey, file, subject string, secondsUntilExpiry float64)
+	// files are the certificate files by the config keys referencing them
+	files map[string]string
+	// warned are the times of the last warnings by certificate
+	warned  map[string]time.Time
+	failed  map[string]bool
+	warning time.Duration
+	lock    sync.Mutex
+}
+
+func newCertExpiryWatchdog(warningDays int) (*certExpiryWatchdog, error) {
+	if err := view.Register(&view.View{
+		Name:        mCertSecondsUntilExpiry.Name(),
+		Description: mCertSecondsUntilExpiry.Description(),
+		Measure:     mCertSecondsUntilExpiry,
+		TagKeys:     []tag.Key{configKeyTag, fileTag, subjectTag},
+		Aggregation: view.LastValue(),
+	}); err != nil {
+		return nil, fmt.Errorf("failed registering the certificate expiry metric view: %w", err)
+	}
+	return &certExpiryWatchdog{
+		now:     time.Now,
+		record:  recordCertExpiry,
+		files:   map[string]string{},
+		warned:  map[string]time.Time{},
+		failed:  map[string]bool{},
+		warning: time.Duration(warningDays) * 24 * time.Hour,
+	}, nil
+}
+
+func recordCertExpiry(configKey, file, subject string, secondsUntilExpiry float64) {
+	_ = stats.RecordWithTags(
+		context.Background(),
+		[]tag.Mutator{tag.Upsert(configKeyTag, configKey), tag.Upsert(fileTag, file), tag.Upsert(subjectTag, subject)},
+		mCertSecondsUntilExpiry.M(secondsUntilExpiry),
+	)
+}
+
+func (w *certExpiryWatchdog) Convert(_ context.Context, cfgMap *confmap.Conf) error {
+	if cfgMap == nil {
+		return fmt.Errorf("cannot certExpiryWatchdog on nil *confmap.Conf")
+	}
+	files := map[string]string{}
+	for _, section := range []string{"receivers", "processors", "exporters", "extensions"} {
+		if components, ok := cfgMap.Get(section).(map[string]any); ok {
+			certFiles(section, components, files)
+		}
+	}
+
+	w.lock.Lock()
+	w.files = files
+	w.lock.Unlock()
+	w.check()
+	return nil
+}
+
+// start checks the inventoried certificate files every check interval.
+func (w *certExpiryWatchdog) start() {
+	go func() {
+		ticker := time.NewTicker(certExpiryCheckInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			w.check()
+		}
+	}()
+}
+
+// check records the time until the certificates of the inventoried files expire, and warns about those
+// expiring within the warning period.
+func (w *certExpiryWatchdog) check() {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	now := w.now()
+	for _, configKey := range sortedKeys(w.files) {
+		file := w.files[configKey]
+		certs, err := readCertificates(file)
+		if err != nil {
+			if !w.failed[file] {
+				log.Printf("Warning: failed checking the expiry of the certificates of %s, referenced by %s: %v", file, configKey, err)
+			}
+			w.failed[file] = true
+			continue
+		}
+		delete(w.failed, file)
+
+		for _, cert := range certs {
+			subject := cert.Subject.String()
+			untilExpiry := cert.NotAfter.Sub(now)
+			w.record(configKey, file, subject, untilExpiry.Seconds())
+			if untilExpiry > w.warning {
+				continue
+			}
+
+			id := configKey + "|" + subject + "|" + cert.SerialNumber.String()
+			if warned, ok := w.warned[id]; ok && now.Sub(warned) < 24*time.Hour {
+				continue
+			}
+			w.warned[id] = now
+			if untilExpiry <= 0 {
+				log.Printf("Warning: the certificate %q of %s, referenced by %s, expired on %s",
+					subject, file, configKey, cert.NotAfter.UTC().Format(time.RFC3339))
+			} else {
+				log.Printf("Warning: the certificate %q of %s, referenced by %s, expires in %d days on %s",
+					subject, file, configKey, int(untilExpiry.Hours()/24), cert.NotAfter.UTC().Format(time.RFC3339))
+			}
+		}
+	}
+}
+
+// certFiles adds the certificate file paths of the config to the files, by their config keys.
+func certFiles(key string, cfg map[string]any, files map[string]string) {
+	for name, value := range cfg {
+		nameKey := key + confmap.KeyDelimiter + name
+		switch v := value.(type) {
+		case map[string]any:
+			certFiles(nameKey, v, files)
+		case []any:
+			for i, item := range v {
+				if m, ok := item.(map[string]any); ok {
+					certFiles(nameKey+confmap.KeyDelimiter+strconv.Itoa(i), m, files)
+				}
+			}
+		case string:
+			if certFileKeys[name] && strings.TrimSpace(v) != "" {
+				files[nameKey] = v
+			}
+		}
+	}
+}
+
+// readCertificates returns the certificates of the PEM file.
+func readCertificates(file string) ([]*x509.Certificate, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, content = pem.Decode(content)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("no PEM certificates found")
+	}
+	return certs, nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
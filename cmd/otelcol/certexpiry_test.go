@@ -0,0 +1,164 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestCertExpiryWarningDays(t *testing.T) {
+	days, err := certExpiryWarningDays()
+	require.NoError(t, err)
+	assert.Equal(t, 30, days)
+
+	t.Setenv(certExpiryWarningDaysEnvVarName, "0")
+	days, err = certExpiryWarningDays()
+	require.NoError(t, err)
+	assert.Equal(t, 0, days)
+
+	t.Setenv(certExpiryWarningDaysEnvVarName, "14")
+	days, err = certExpiryWarningDays()
+	require.NoError(t, err)
+	assert.Equal(t, 14, days)
+
+	for _, invalid := range []string{"-1", "2w"} {
+		t.Setenv(certExpiryWarningDaysEnvVarName, invalid)
+		_, err = certExpiryWarningDays()
+		require.EqualError(t, err, `expected a non-negative number of days in SPLUNK_CERT_EXPIRY_WARNING_DAYS env variable but got "`+invalid+`"`)
+	}
+}
+
+func TestCertExpiryWatchdog(t *testing.T) {
+	oldWriter := log.Default().Writer()
+	defer log.Default().SetOutput(oldWriter)
+	logs := new(bytes.Buffer)
+	log.Default().SetOutput(logs)
+
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	dir := t.TempDir()
+	gateway := writeCert(t, dir, "gateway.pem", "gateway", now.Add(10*24*time.Hour))
+	ca := writeCert(t, dir, "ca.pem", "ca", now.Add(365*24*time.Hour))
+	expired := writeCert(t, dir, "expired.pem", "expired", now.Add(-time.Hour))
+	missing := filepath.Join(dir, "missing.pem")
+
+	type recorded struct {
+		file    string
+		subject string
+		seconds float64
+	}
+	records := map[string]recorded{}
+	watchdog := &certExpiryWatchdog{
+		now: func() time.Time { return now },
+		record: func(configKey, file, subject string, secondsUntilExpiry float64) {
+			records[configKey] = recorded{file: file, subject: subject, seconds: secondsUntilExpiry}
+		},
+		files:   map[string]string{},
+		warned:  map[string]time.Time{},
+		failed:  map[string]bool{},
+		warning: 30 * 24 * time.Hour,
+	}
+
+	cfgMap := confmap.NewFromStringMap(map[string]any{
+		"receivers": map[string]any{
+			"otlp": map[string]any{
+				"protocols": map[string]any{
+					"grpc": map[string]any{
+						"tls": map[string]any{"cert_file": gateway, "key_file": gateway + ".key", "client_ca_file": ca},
+					},
+				},
+			},
+			"smartagent/redis": map[string]any{"type": "collectd/redis", "caCertPath": expired},
+		},
+		"exporters": map[string]any{
+			"otlp": map[string]any{"endpoint": "gateway:4317", "tls": map[string]any{"ca_file": missing}},
+		},
+		"service": map[string]any{"telemetry": map[string]any{"ca_file": "ignored"}},
+	})
+	require.NoError(t, watchdog.Convert(context.Background(), cfgMap))
+
+	assert.Equal(t, map[string]string{
+		"receivers::otlp::protocols::grpc::tls::cert_file":      gateway,
+		"receivers::otlp::protocols::grpc::tls::client_ca_file": ca,
+		"receivers::smartagent/redis::caCertPath":               expired,
+		"exporters::otlp::tls::ca_file":                         missing,
+	}, watchdog.files)
+	assert.Equal(t, map[string]recorded{
+		"receivers::otlp::protocols::grpc::tls::cert_file":      {file: gateway, subject: "CN=gateway", seconds: 10 * 24 * 3600},
+		"receivers::otlp::protocols::grpc::tls::client_ca_file": {file: ca, subject: "CN=ca", seconds: 365 * 24 * 3600},
+		"receivers::smartagent/redis::caCertPath":               {file: expired, subject: "CN=expired", seconds: -3600},
+	}, records)
+
+	lines := strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 3)
+	assert.Contains(t, lines[0], "Warning: failed checking the expiry of the certificates of "+missing+", referenced by exporters::otlp::tls::ca_file")
+	assert.Contains(t, lines[1], `Warning: the certificate "CN=gateway" of `+gateway+`, referenced by receivers::otlp::protocols::grpc::tls::cert_file, expires in 10 days on 2022-07-11T00:00:00Z`)
+	assert.Contains(t, lines[2], `Warning: the certificate "CN=expired" of `+expired+`, referenced by receivers::smartagent/redis::caCertPath, expired on 2022-06-30T23:00:00Z`)
+
+	// the warnings are only repeated once a day
+	logs.Reset()
+	now = now.Add(time.Hour)
+	watchdog.check()
+	assert.Empty(t, logs.String())
+
+	now = now.Add(24 * time.Hour)
+	watchdog.check()
+	lines = strings.Split(strings.TrimSpace(logs.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Contains(t, lines[0], `Warning: the certificate "CN=gateway" of `+gateway+`, referenced by receivers::otlp::protocols::grpc::tls::cert_file, expires in 8 days`)
+	assert.Contains(t, lines[1], `Warning: the certificate "CN=expired" of `+expired+`, referenced by receivers::smartagent/redis::caCertPath, expired on`)
+	assert.InDelta(t, 8*24*3600+23*3600, records["receivers::otlp::protocols::grpc::tls::cert_file"].seconds, 1)
+
+	// rotated certificates are picked up
+	logs.Reset()
+	writeCert(t, dir, "gateway.pem", "gateway", now.Add(90*24*time.Hour))
+	watchdog.check()
+	assert.Empty(t, logs.String())
+	assert.Equal(t, float64(90*24*3600), records["receivers::otlp::protocols::grpc::tls::cert_file"].seconds)
+}
+
+func writeCert(t *testing.T, dir, name, commonName string, notAfter time.Time) string {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(notAfter.Unix()),
+		Subject:      pkix.Name{CommonName: commonName},
+		NotBefore:    notAfter.Add(-2 * 365 * 24 * time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0600))
+	return path
+}
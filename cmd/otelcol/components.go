@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"go.opentelemetry.io/collector/component"
+
+	"github.com/signalfx/splunk-otel-collector/internal/components"
+	"github.com/signalfx/splunk-otel-collector/internal/version"
+)
+
+const componentsCommand = "components"
+
+// runComponents lists the bundled components and their stability levels, or with --json
+// the full inventory including their config schemas.
+func runComponents(args []string, factories component.Factories, out io.Writer) error {
+	flagSet := flag.NewFlagSet(componentsCommand, flag.ContinueOnError)
+	flagSet.SetOutput(out)
+	asJSON := flagSet.Bool("json", false, "Output the component inventory, including config schemas, as JSON")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() != 0 {
+		return fmt.Errorf("unexpected arguments for the %s command: %v", componentsCommand, flagSet.Args())
+	}
+
+	inventory := components.NewInventory(factories, version.Version)
+	if *asJSON {
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(inventory)
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KIND\tTYPE\tSTABILITY")
+	for _, kind := range []struct {
+		name       string
+		components []components.ComponentInventory
+	}{
+		{"receiver", inventory.Receivers},
+		{"processor", inventory.Processors},
+		{"exporter", inventory.Exporters},
+		{"extension", inventory.Extensions},
+	} {
+		for _, c := range kind.components {
+			fmt.Fprintf(writer, "%s\t%s\t%s\n", kind.name, c.Type, c.Stability)
+		}
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/components"
+)
+
+func TestRunComponents(t *testing.T) {
+	factories, err := components.Get()
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, runComponents(nil, factories, out))
+	assert.Contains(t, out.String(), "KIND")
+	assert.Regexp(t, `(?m)^receiver\s+smartagent\s+beta$`, out.String())
+	assert.Regexp(t, `(?m)^exporter\s+pulsar\s+experimental$`, out.String())
+}
+
+func TestRunComponentsJSON(t *testing.T) {
+	factories, err := components.Get()
+	require.NoError(t, err)
+
+	out := new(bytes.Buffer)
+	require.NoError(t, runComponents([]string{"--json"}, factories, out))
+
+	var inventory components.Inventory
+	require.NoError(t, json.Unmarshal(out.Bytes(), &inventory))
+	assert.Len(t, inventory.Receivers, len(factories.Receivers))
+	assert.Len(t, inventory.Processors, len(factories.Processors))
+	assert.Len(t, inventory.Exporters, len(factories.Exporters))
+	assert.Len(t, inventory.Extensions, len(factories.Extensions))
+	assert.NotEmpty(t, inventory.Version)
+}
+
+func TestRunComponentsInvalidArgs(t *testing.T) {
+	factories, err := components.Get()
+	require.NoError(t, err)
+
+	err = runComponents([]string{"--unknown"}, factories, new(bytes.Buffer))
+	assert.Error(t, err)
+
+	err = runComponents([]string{"receivers"}, factories, new(bytes.Buffer))
+	assert.EqualError(t, err, "unexpected arguments for the components command: [receivers]")
+}
@@ -0,0 +1,218 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/converter/overwritepropertiesconverter"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// setConverterType is the type of the converter of the --set properties.
+var setConverterType = reflect.TypeOf(overwritepropertiesconverter.New(nil))
+
+// configSource is the layer that supplied the final value of a config key.
+type configSource struct {
+	key    string
+	source string
+}
+
+// explainConfigSources returns the layer that supplied each key of the final config, merging
+// them the way the collector does: the config layers, under the config locations in order,
+// with their env var references expanded, under the --set properties, followed by the config
+// conversions. The last layer setting a key supplies it, along with the env vars its value
+// references. Config source references aren't expanded, so no secret is retrieved, and the
+// config_sources keys, which are removed once expanded, aren't reported.
+func explainConfigSources(ctx context.Context, locations, sets []string, layers []configconverter.Layer, converters []confmap.Converter) ([]configSource, error) {
+	merged := confmap.New()
+	sources := map[string]string{}
+	merge := func(source string, conf *confmap.Conf) error {
+		if err := merged.Merge(conf); err != nil {
+			return fmt.Errorf("failed merging the %s config: %w", source, err)
+		}
+		for _, key := range conf.AllKeys() {
+			sources[key] = source
+		}
+		return nil
+	}
+
+	for _, layer := range layers {
+		conf, err := layer.Retrieve(ctx, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed retrieving the %s config layer: %w", layer.Name(), err)
+		}
+		if conf != nil {
+			err = merge(layer.Name(), conf)
+		}
+		if closeErr := layer.Close(ctx); err == nil {
+			err = closeErr
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, location := range locations {
+		source, conf, err := retrieveLocation(ctx, location)
+		if err != nil {
+			return nil, err
+		}
+		if err = merge(source, conf); err != nil {
+			return nil, err
+		}
+	}
+
+	merged = confmap.NewFromStringMap(expandEnvVars(merged.ToStringMap(), "", sources).(map[string]any))
+
+	if len(sets) != 0 {
+		conf := confmap.New()
+		if err := overwritepropertiesconverter.New(sets).Convert(ctx, conf); err != nil {
+			return nil, fmt.Errorf("failed parsing the --set properties: %w", err)
+		}
+		if err := merge("--set", conf); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, converter := range converters {
+		if reflect.TypeOf(converter) == setConverterType {
+			// already merged as the --set layer
+			continue
+		}
+		before := flattenConf(merged)
+		if err := converter.Convert(ctx, merged); err != nil {
+			return nil, err
+		}
+		source := fmt.Sprintf("%T", converter)
+		source = "config conversion " + source[strings.LastIndex(source, ".")+1:]
+		for key, value := range flattenConf(merged) {
+			if previous, ok := before[key]; !ok || !reflect.DeepEqual(previous, value) {
+				sources[key] = source
+			}
+		}
+	}
+
+	var explained []configSource
+	for _, key := range merged.AllKeys() {
+		if key == "config_sources" || strings.HasPrefix(key, "config_sources::") {
+			continue
+		}
+		explained = append(explained, configSource{key: key, source: sources[key]})
+	}
+	return explained, nil
+}
+
+// retrieveLocation returns the config map of the config location and the name of its layer.
+// Locations without the scheme of a supported provider are file paths, as for the collector.
+func retrieveLocation(ctx context.Context, location string) (string, *confmap.Conf, error) {
+	var provider confmap.Provider = fileprovider.New()
+	path := location
+	if scheme, p, ok := strings.Cut(location, ":"); ok {
+		switch scheme {
+		case "env":
+			provider, path = envprovider.New(), p
+		case "file":
+			path = p
+		}
+	}
+
+	retrieved, err := provider.Retrieve(ctx, provider.Scheme()+":"+path, nil)
+	if err != nil {
+		return "", nil, err
+	}
+	conf, err := retrieved.AsConf()
+	if closeErr := retrieved.Close(ctx); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	switch {
+	case provider.Scheme() == "env":
+		return "env var " + path, conf, nil
+	case path == defaultDockerSAPMConfig || path == defaultLocalSAPMConfig:
+		return "default config " + path, conf, nil
+	default:
+		return "config file " + path, conf, nil
+	}
+}
+
+// expandEnvVars returns the value with the env var references of its strings expanded, as the
+// collector does for each config location, adding the env vars to the sources of their keys.
+func expandEnvVars(value any, key string, sources map[string]string) any {
+	switch v := value.(type) {
+	case string:
+		expanded, names := configprovider.ExpandEnvVars(v)
+		for _, name := range names {
+			if source := ", env var " + name; !strings.Contains(sources[key]+",", source+",") {
+				sources[key] += source
+			}
+		}
+		return expanded
+	case map[string]any:
+		expanded := make(map[string]any, len(v))
+		for k, item := range v {
+			itemKey := k
+			if key != "" {
+				itemKey = key + confmap.KeyDelimiter + k
+			}
+			expanded[k] = expandEnvVars(item, itemKey, sources)
+		}
+		return expanded
+	case []any:
+		expanded := make([]any, len(v))
+		for i, item := range v {
+			expanded[i] = expandEnvVars(item, key, sources)
+		}
+		return expanded
+	}
+	return value
+}
+
+// flattenConf returns the values of the config map by key.
+func flattenConf(conf *confmap.Conf) map[string]any {
+	values := map[string]any{}
+	for _, key := range conf.AllKeys() {
+		values[key] = conf.Get(key)
+	}
+	return values
+}
+
+// printConfigSources writes the layer that supplied each key of the final config, without
+// their values, which may be secrets.
+func printConfigSources(ctx context.Context, out io.Writer, locations, sets []string, layers []configconverter.Layer, converters []confmap.Converter) error {
+	explained, err := explainConfigSources(ctx, locations, sets, layers, converters)
+	if err != nil {
+		return err
+	}
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "KEY\tSOURCE")
+	for _, source := range explained {
+		fmt.Fprintf(writer, "%s\t%s\n", source.key, source.source)
+	}
+	return writer.Flush()
+}
@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/converter/overwritepropertiesconverter"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+)
+
+func TestExplainConfigSources(t *testing.T) {
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.yaml")
+	require.NoError(t, os.WriteFile(base, []byte(`config_sources:
+  env:
+    defaults:
+      REALM: us0
+receivers:
+  otlp:
+    protocols:
+      grpc:
+        endpoint: ${GRPC_HOST}:4317
+exporters:
+  otlp:
+    endpoint: localhost:4317
+    insecure: true
+processors:
+  memory_limiter:
+    ballast_size_mib: 100
+    check_interval: 2s
+`), 0600))
+	override := filepath.Join(dir, "override.yaml")
+	require.NoError(t, os.WriteFile(override, []byte(`exporters:
+  otlp:
+    endpoint: collector:4317
+`), 0600))
+	t.Setenv("GRPC_HOST", "0.0.0.0")
+	t.Setenv(configOverlayEnvVarName, "exporters:\n  otlp:\n    compression: gzip\n    endpoint: overlay:4317\n")
+
+	explained, err := explainConfigSources(
+		context.Background(),
+		[]string{base, "file:" + override},
+		[]string{"processors.memory_limiter.check_interval=5s"},
+		configLayers(),
+		[]confmap.Converter{
+			overwritepropertiesconverter.New([]string{"processors.memory_limiter.check_interval=5s"}),
+			configconverter.RemoveBallastKey{},
+			configconverter.MoveOTLPInsecureKey{},
+		},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []configSource{
+		{key: "exporters::otlp::compression", source: "env overlay"},
+		{key: "exporters::otlp::endpoint", source: "config file " + override},
+		{key: "exporters::otlp::tls::insecure", source: "config conversion MoveOTLPInsecureKey"},
+		{key: "processors::memory_limiter::check_interval", source: "--set"},
+		{key: "receivers::otlp::protocols::grpc::endpoint", source: "config file " + base + ", env var GRPC_HOST"},
+	}, explained)
+}
+
+func TestExplainConfigSourcesEnvLocation(t *testing.T) {
+	t.Setenv(configYamlEnvVarName, "receivers:\n  hostmetrics:\n    collection_interval: 10s\n")
+
+	explained, err := explainConfigSources(context.Background(), []string{"env:" + configYamlEnvVarName}, nil, nil, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []configSource{
+		{key: "receivers::hostmetrics::collection_interval", source: "env var " + configYamlEnvVarName},
+	}, explained)
+}
+
+func TestPrintConfigSources(t *testing.T) {
+	config := filepath.Join(t.TempDir(), "config.yaml")
+	require.NoError(t, os.WriteFile(config, []byte("exporters:\n  signalfx:\n    access_token: secret\n"), 0600))
+
+	out := &bytes.Buffer{}
+	require.NoError(t, printConfigSources(context.Background(), out, []string{config}, nil, nil, nil))
+	assert.Equal(t, "KEY                                SOURCE\nexporters::signalfx::access_token  config file "+config+"\n", out.String())
+	assert.NotContains(t, out.String(), "secret")
+
+	err := printConfigSources(context.Background(), out, []string{filepath.Join(t.TempDir(), "missing.yaml")}, nil, nil, nil)
+	assert.Error(t, err)
+}
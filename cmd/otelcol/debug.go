@@ -0,0 +1,62 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+)
+
+// debugComponents returns the troubleshooting components enabled by the SPLUNK_DEBUG env var, a
+// comma-separated list of pprof, zpages, and logging, or true for all of them, along with their names.
+func debugComponents() (configconverter.EnableDebug, []string, error) {
+	var debug configconverter.EnableDebug
+	value := strings.TrimSpace(os.Getenv(debugEnvVarName))
+	if value == "" {
+		return debug, nil, nil
+	}
+	if enabled, err := strconv.ParseBool(value); err == nil {
+		if !enabled {
+			return debug, nil, nil
+		}
+		value = "pprof,zpages,logging"
+	}
+
+	var names []string
+	for _, name := range strings.Split(value, ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "pprof":
+			debug.PProf = true
+		case "zpages":
+			debug.ZPages = true
+		case "logging":
+			debug.Logging = true
+		case "":
+			continue
+		default:
+			return configconverter.EnableDebug{}, nil, fmt.Errorf(
+				"expected true, false, or a comma-separated list of pprof, zpages, and logging in %s env variable but got %q",
+				debugEnvVarName, os.Getenv(debugEnvVarName),
+			)
+		}
+		names = append(names, name)
+	}
+	return debug, names, nil
+}
@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
+)
+
+func TestDebugComponents(t *testing.T) {
+	for _, test := range []struct {
+		value    string
+		expected configconverter.EnableDebug
+		names    []string
+	}{
+		{value: ""},
+		{value: "false"},
+		{
+			value:    "true",
+			expected: configconverter.EnableDebug{PProf: true, ZPages: true, Logging: true},
+			names:    []string{"pprof", "zpages", "logging"},
+		},
+		{
+			value:    "pprof",
+			expected: configconverter.EnableDebug{PProf: true},
+			names:    []string{"pprof"},
+		},
+		{
+			value:    " ZPages, logging,",
+			expected: configconverter.EnableDebug{ZPages: true, Logging: true},
+			names:    []string{"zpages", "logging"},
+		},
+	} {
+		t.Run(test.value, func(t *testing.T) {
+			t.Setenv(debugEnvVarName, test.value)
+			debug, names, err := debugComponents()
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, debug)
+			assert.Equal(t, test.names, names)
+		})
+	}
+}
+
+func TestDebugComponentsInvalid(t *testing.T) {
+	t.Setenv(debugEnvVarName, "pprof,traces")
+	_, _, err := debugComponents()
+	require.EqualError(t, err,
+		`expected true, false, or a comma-separated list of pprof, zpages, and logging in SPLUNK_DEBUG env variable but got "pprof,traces"`)
+}
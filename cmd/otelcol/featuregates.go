@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+const featureGatesEnvVarName = "SPLUNK_FEATURE_GATES"
+
+// setFeatureGates applies the comma-separated SPLUNK_FEATURE_GATES list, whose gates are enabled
+// unless prefixed with "-", after validating it and the --feature-gates flag values against the
+// registered gates.  The --feature-gates flag values are applied by the service afterwards and take precedence.
+func setFeatureGates(registry *featuregate.Registry, flagGates featuregate.FlagValue) (featuregate.FlagValue, error) {
+	envGates := parseFeatureGates(os.Getenv(featureGatesEnvVarName))
+	if err := validateFeatureGates(registry, envGates, featureGatesEnvVarName); err != nil {
+		return nil, err
+	}
+	if err := validateFeatureGates(registry, flagGates, "--feature-gates"); err != nil {
+		return nil, err
+	}
+	registry.Apply(envGates)
+	return envGates, nil
+}
+
+// parseFeatureGates parses a comma-separated feature gate list like the --feature-gates flag,
+// ignoring surrounding whitespace and empty entries.
+func parseFeatureGates(value string) featuregate.FlagValue {
+	gates := featuregate.FlagValue{}
+	for _, id := range strings.Split(value, ",") {
+		id = strings.TrimSpace(id)
+		enabled := true
+		switch {
+		case strings.HasPrefix(id, "-"):
+			id, enabled = id[1:], false
+		case strings.HasPrefix(id, "+"):
+			id = id[1:]
+		}
+		if id != "" {
+			gates[id] = enabled
+		}
+	}
+	return gates
+}
+
+func validateFeatureGates(registry *featuregate.Registry, gates featuregate.FlagValue, source string) error {
+	available := registry.List()
+	registered := make(map[string]bool, len(available))
+	for _, gate := range available {
+		registered[gate.ID] = true
+	}
+
+	var unknown []string
+	for id := range gates {
+		if !registered[id] {
+			unknown = append(unknown, fmt.Sprintf("%q", id))
+		}
+	}
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+	sort.Slice(available, func(i, j int) bool { return available[i].ID < available[j].ID })
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "unknown feature gates in %s: %s", source, strings.Join(unknown, ", "))
+	if len(available) == 0 {
+		sb.WriteString("; no feature gates are available")
+		return errors.New(sb.String())
+	}
+	sb.WriteString("; available feature gates:")
+	for _, gate := range available {
+		state := "disabled"
+		if gate.Enabled {
+			state = "enabled"
+		}
+		fmt.Fprintf(&sb, "\n  %s (%s): %s", gate.ID, state, gate.Description)
+	}
+	return errors.New(sb.String())
+}
@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/service/featuregate"
+)
+
+const (
+	testEnabledGate  = "splunk.test.enabledGate"
+	testDisabledGate = "splunk.test.disabledGate"
+)
+
+func init() {
+	featuregate.GetRegistry().MustRegister(featuregate.Gate{
+		ID:          testEnabledGate,
+		Description: "A test gate enabled by default",
+		Enabled:     true,
+	})
+	featuregate.GetRegistry().MustRegister(featuregate.Gate{
+		ID:          testDisabledGate,
+		Description: "A test gate disabled by default",
+	})
+}
+
+func resetTestGates(t *testing.T) {
+	t.Cleanup(func() {
+		featuregate.GetRegistry().Apply(map[string]bool{testEnabledGate: true, testDisabledGate: false})
+	})
+}
+
+func TestParseFeatureGates(t *testing.T) {
+	assert.Equal(t, featuregate.FlagValue{}, parseFeatureGates(""))
+	assert.Equal(t, featuregate.FlagValue{
+		"a": true,
+		"b": false,
+		"c": true,
+	}, parseFeatureGates(" a, -b,+c,, - "))
+}
+
+func TestSetFeatureGatesFromEnv(t *testing.T) {
+	resetTestGates(t)
+	t.Setenv(featureGatesEnvVarName, "-"+testEnabledGate+", "+testDisabledGate)
+
+	envGates, err := setFeatureGates(featuregate.GetRegistry(), featuregate.FlagValue{})
+	require.NoError(t, err)
+	assert.Equal(t, featuregate.FlagValue{testEnabledGate: false, testDisabledGate: true}, envGates)
+	assert.False(t, featuregate.GetRegistry().IsEnabled(testEnabledGate))
+	assert.True(t, featuregate.GetRegistry().IsEnabled(testDisabledGate))
+}
+
+func TestSetFeatureGatesWithoutEnv(t *testing.T) {
+	resetTestGates(t)
+	t.Setenv(featureGatesEnvVarName, "")
+
+	envGates, err := setFeatureGates(featuregate.GetRegistry(), featuregate.FlagValue{testDisabledGate: true})
+	require.NoError(t, err)
+	assert.Empty(t, envGates)
+	// flag values are applied by the service
+	assert.True(t, featuregate.GetRegistry().IsEnabled(testEnabledGate))
+	assert.False(t, featuregate.GetRegistry().IsEnabled(testDisabledGate))
+}
+
+func TestSetUnknownFeatureGates(t *testing.T) {
+	resetTestGates(t)
+	t.Setenv(featureGatesEnvVarName, testDisabledGate+",unknown.gate,-another.gate")
+
+	envGates, err := setFeatureGates(featuregate.GetRegistry(), featuregate.FlagValue{})
+	require.Error(t, err)
+	assert.Nil(t, envGates)
+	assert.Contains(t, err.Error(), `unknown feature gates in SPLUNK_FEATURE_GATES: "another.gate", "unknown.gate"; available feature gates:`)
+	assert.Contains(t, err.Error(), "\n  "+testDisabledGate+" (disabled): A test gate disabled by default")
+	assert.Contains(t, err.Error(), "\n  "+testEnabledGate+" (enabled): A test gate enabled by default")
+	// nothing is applied when the list is invalid
+	assert.False(t, featuregate.GetRegistry().IsEnabled(testDisabledGate))
+
+	t.Setenv(featureGatesEnvVarName, "")
+	_, err = setFeatureGates(featuregate.GetRegistry(), featuregate.FlagValue{"unknown.gate": true})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `unknown feature gates in --feature-gates: "unknown.gate"`)
+}
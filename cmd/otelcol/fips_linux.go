@@ -0,0 +1,51 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build fips && linux
+// +build fips,linux
+
+package main
+
+import (
+	"crypto/boring"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	// Restricts all crypto/tls usage, including that of every bundled component, to FIPS-approved
+	// protocol versions, cipher suites, and curves.
+	_ "crypto/tls/fipsonly"
+)
+
+// kernelFIPSModeFile reports whether the kernel's crypto API operates in FIPS mode.
+const kernelFIPSModeFile = "/proc/sys/crypto/fips_enabled"
+
+// cryptoMode verifies that the collector was built with a BoringCrypto Go toolchain so that crypto
+// operations are performed by the FIPS 140-2 validated BoringSSL module, and that the host's kernel
+// is running in FIPS mode.
+func cryptoMode() (string, error) {
+	if !boring.Enabled() {
+		return "", errors.New("the collector was built for FIPS mode without GOEXPERIMENT=boringcrypto")
+	}
+	enabled, err := os.ReadFile(kernelFIPSModeFile)
+	if err != nil {
+		return "", fmt.Errorf("failed reading the kernel FIPS mode: %w", err)
+	}
+	if strings.TrimSpace(string(enabled)) != "1" {
+		return "", fmt.Errorf("the kernel must be running in FIPS mode (%s) to run the collector in FIPS mode", kernelFIPSModeFile)
+	}
+	return "BoringCrypto", nil
+}
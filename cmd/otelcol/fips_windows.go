@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build fips && windows
+// +build fips,windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"strings"
+
+	// Restricts all crypto/tls usage, including that of every bundled component, to FIPS-approved
+	// protocol versions, cipher suites, and curves.
+	_ "crypto/tls/fipsonly"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const fipsPolicyRegistryKey = `SYSTEM\CurrentControlSet\Control\Lsa\FipsAlgorithmPolicy`
+
+// cngCryptoExperiments are the Microsoft Go toolchain GOEXPERIMENT values backing crypto with Windows CNG.
+var cngCryptoExperiments = []string{"cngcrypto", "systemcrypto"}
+
+// cryptoMode verifies that the collector was built with a CNG enabled Microsoft Go toolchain and that
+// the system's FIPS algorithm policy is enabled so that the Windows CNG crypto used by FIPS builds
+// operates in its FIPS 140-2 validated mode.
+func cryptoMode() (string, error) {
+	if !cngCryptoEnabled() {
+		return "", errors.New("the collector was built for FIPS mode without a CNG enabled Microsoft Go toolchain")
+	}
+
+	key, err := registry.OpenKey(registry.LOCAL_MACHINE, fipsPolicyRegistryKey, registry.QUERY_VALUE)
+	if err != nil {
+		return "", fmt.Errorf("failed reading the system FIPS algorithm policy: %w", err)
+	}
+	defer key.Close()
+
+	enabled, _, err := key.GetIntegerValue("Enabled")
+	if err != nil {
+		return "", fmt.Errorf("failed reading the system FIPS algorithm policy: %w", err)
+	}
+	if enabled != 1 {
+		return "", errors.New("the system FIPS algorithm policy must be enabled to run the collector in FIPS mode")
+	}
+	return "Windows CNG", nil
+}
+
+// cngCryptoEnabled returns whether the build's GOEXPERIMENT setting enabled the CNG crypto backend.
+func cngCryptoEnabled() bool {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return false
+	}
+	for _, setting := range info.Settings {
+		if setting.Key != "GOEXPERIMENT" {
+			continue
+		}
+		for _, experiment := range strings.Split(setting.Value, ",") {
+			for _, cng := range cngCryptoExperiments {
+				if experiment == cng {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
@@ -26,13 +26,14 @@ const defaultUndeclaredFlag = -1
 
 type flags struct {
 	// Command-line flags that are used by Splunk's distribution of the collector
-	configs           *stringArrayValue
-	sets              *stringArrayValue
-	gatesList         featuregate.FlagValue
-	help              bool
-	noConvertConfig   bool
-	version           bool
-	memBallastSizeMib int
+	configs            *stringArrayValue
+	sets               *stringArrayValue
+	gatesList          featuregate.FlagValue
+	help               bool
+	noConvertConfig    bool
+	printConfigSources bool
+	version            bool
+	memBallastSizeMib  int
 }
 
 // required to support config and set flags
@@ -76,6 +77,7 @@ func parseFlags(args []string) (flags, error) {
 	flagSet.BoolVar(&out.help, "h", false, "")
 	flagSet.BoolVar(&out.help, "help", false, "")
 	flagSet.BoolVar(&out.noConvertConfig, "no-convert-config", false, "")
+	flagSet.BoolVar(&out.printConfigSources, "print-config-sources", false, "")
 	flagSet.BoolVar(&out.version, "v", false, "")
 	flagSet.BoolVar(&out.version, "version", false, "")
 
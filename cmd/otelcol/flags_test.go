@@ -53,6 +53,7 @@ func TestFlagParseSuccess(t *testing.T) {
 		"--version",
 		"--help",
 		"--no-convert-config",
+		"--print-config-sources",
 		"--config", "foo.yml",
 		"--config", "bar.yml",
 		"--mem-ballast-size-mib", "100",
@@ -68,6 +69,7 @@ func TestFlagParseSuccess(t *testing.T) {
 	assert.True(t, inputFlags.version)
 	assert.True(t, inputFlags.help)
 	assert.True(t, inputFlags.noConvertConfig)
+	assert.True(t, inputFlags.printConfigSources)
 
 	assert.Contains(t, inputFlags.configs.values, "foo.yml")
 	assert.Contains(t, inputFlags.configs.values, "bar.yml")
@@ -18,10 +18,12 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"os"
 	"strconv"
+	"strings"
 
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/confmap"
@@ -29,12 +31,14 @@ import (
 	"go.opentelemetry.io/collector/confmap/provider/envprovider"
 	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
 	"go.opentelemetry.io/collector/service"
+	"go.opentelemetry.io/collector/service/featuregate"
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/components"
 	"github.com/signalfx/splunk-otel-collector/internal/configconverter"
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
 	"github.com/signalfx/splunk-otel-collector/internal/configsources"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/privilegecheckextension"
 	"github.com/signalfx/splunk-otel-collector/internal/version"
 )
 
@@ -43,10 +47,13 @@ const (
 	ballastEnvVarName         = "SPLUNK_BALLAST_SIZE_MIB"
 	configEnvVarName          = "SPLUNK_CONFIG"
 	configYamlEnvVarName      = "SPLUNK_CONFIG_YAML"
+	configOverlayEnvVarName   = "SPLUNK_CONFIG_OVERLAY_YAML"
 	configServerEnabledEnvVar = "SPLUNK_DEBUG_CONFIG_SERVER"
+	debugEnvVarName           = "SPLUNK_DEBUG"
 	memLimitMiBEnvVarName     = "SPLUNK_MEMORY_LIMIT_MIB"
 	memTotalEnvVarName        = "SPLUNK_MEMORY_TOTAL_MIB"
 	realmEnvVarName           = "SPLUNK_REALM"
+	shutdownTimeoutEnvVarName = "SPLUNK_SHUTDOWN_TIMEOUT"
 	tokenEnvVarName           = "SPLUNK_ACCESS_TOKEN"
 
 	defaultDockerSAPMConfig        = "/etc/otel/collector/gateway_config.yaml"
@@ -62,15 +69,65 @@ func main() {
 	// TODO: Use same format as the collector
 	log.SetFlags(log.Ldate | log.Ltime | log.Lshortfile)
 
+	if len(os.Args) > 1 && os.Args[1] == componentsCommand {
+		factories, err := components.Get()
+		if err != nil {
+			log.Fatalf("failed to build default components: %v", err)
+		}
+		if err = runComponents(os.Args[2:], factories, os.Stdout); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == monitorsCommand {
+		if err := runMonitors(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == replayCommand {
+		if err := runReplay(os.Args[2:], os.Stdout); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
 	// Core flag parser will handle errors, we don't have to handle them here.
 	inputFlags, err := parseFlags(os.Args[1:])
 	if err != nil {
 		log.Fatalf("Error: %v\nUse \"--help\" to show valid usage", err)
 	}
 
+	if err = loadPlatformConfig(); err != nil {
+		log.Fatalf("Error: failed loading platform config: %v", err)
+	}
+
+	mode, err := cryptoMode()
+	if err != nil {
+		log.Fatalf("Error: FIPS mode verification failed: %v", err)
+	}
+	if mode != "" {
+		log.Printf("FIPS mode enabled: using %s FIPS 140-2 validated crypto and FIPS-approved TLS settings", mode)
+	}
+
+	var rotator *tokenRotator
 	if !inputFlags.help && !inputFlags.version {
+		// first, so that the token files provide the tokens required by the default configs
+		if rotator, err = newTokenRotator(); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
 		checkRuntimeParams(inputFlags)
 		setDefaultEnvVars()
+
+		envGates, err := setFeatureGates(featuregate.GetRegistry(), inputFlags.gatesList)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		if len(envGates) > 0 {
+			log.Printf("Set feature gates %s from the %s env var", envGates.String(), featureGatesEnvVarName)
+		}
 	}
 
 	// Allow dumping configuration locally by default
@@ -84,6 +141,27 @@ func main() {
 		log.Fatalf("failed to build default components: %v", err)
 	}
 
+	if rotator != nil {
+		factories = rotateTokens(factories, rotator)
+		if err = rotator.start(); err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+	}
+
+	timeout, ok, err := shutdownTimeout()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if ok {
+		tracker := newShutdownTracker(timeout)
+		tracker.watchSignals()
+		factories = trackShutdowns(factories, tracker)
+		log.Printf("Set shutdown timeout to %s", timeout)
+	}
+	// last, so that the shutdowns of the kept receivers are tracked
+	keeper := newReceiverKeeper(keptReceiverTypes)
+	factories = keepReceivers(factories, keeper)
+
 	info := component.BuildInfo{
 		Command: "otelcol",
 		Version: version.Version,
@@ -92,6 +170,14 @@ func main() {
 	configMapConverters := []confmap.Converter{
 		overwritepropertiesconverter.New(inputFlags.sets.values),
 	}
+	debug, debugNames, err := debugComponents()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if len(debugNames) != 0 {
+		configMapConverters = append(configMapConverters, debug)
+		log.Printf("Enabled %s debug components from the %s env var", strings.Join(debugNames, ", "), debugEnvVarName)
+	}
 
 	if inputFlags.noConvertConfig {
 		// the collector complains about this flag if we don't remove it. Unfortunately,
@@ -105,23 +191,58 @@ func main() {
 			configconverter.MoveOTLPInsecureKey{},
 			configconverter.MoveHecTLS{},
 			configconverter.RenameK8sTagger{},
+			configconverter.DockerObserverEndpoint{},
+			// last, so that the references added by the other converters are checked too
+			configconverter.NormalizeComponentReferences{},
 		)
 	}
 
 	emp := envprovider.New()
 	fmp := fileprovider.New()
+	// the layers are merged once, under the config of the first location
+	locations := configLocations(inputFlags)
+	layers := configLayers()
+	envLayered := configconverter.NewLayeredProvider(emp, locations[0], layers...)
+	fileLayered := configconverter.NewLayeredProvider(fmp, locations[0], layers...)
+
+	if inputFlags.printConfigSources {
+		err = printConfigSources(context.Background(), os.Stdout, locations, inputFlags.sets.values, fileLayered.Layers(), configMapConverters)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		return
+	}
+
+	// records the privilege requirements of the converted config without changing it, so it's applied even
+	// if the config isn't converted
+	configMapConverters = append(configMapConverters, privilegecheckextension.RequirementsDetector{})
+
+	warningDays, err := certExpiryWarningDays()
+	if err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if warningDays > 0 {
+		watchdog, err := newCertExpiryWatchdog(warningDays)
+		if err != nil {
+			log.Fatalf("Error: %v", err)
+		}
+		watchdog.start()
+		// last, so that the certificate files of the converted config are inventoried
+		configMapConverters = append(configMapConverters, watchdog)
+	}
+
 	serviceConfigProvider, err := service.NewConfigProvider(
 		service.ConfigProviderSettings{
-			Locations: configLocations(inputFlags),
+			Locations: locations,
 			MapProviders: map[string]confmap.Provider{
 				emp.Scheme(): configprovider.NewConfigSourceConfigMapProvider(
-					emp,
+					envLayered,
 					zap.NewNop(), // The service logger is not available yet, setting it to NoP.
 					info,
 					configsources.Get()...,
 				),
 				fmp.Scheme(): configprovider.NewConfigSourceConfigMapProvider(
-					fmp,
+					fileLayered,
 					zap.NewNop(), // The service logger is not available yet, setting it to NoP.
 					info,
 					configsources.Get()...,
@@ -136,10 +257,17 @@ func main() {
 	serviceParams := service.CollectorSettings{
 		BuildInfo:      info,
 		Factories:      factories,
-		ConfigProvider: serviceConfigProvider,
+		ConfigProvider: keeper.configProvider(serviceConfigProvider),
 	}
 
-	if err := run(serviceParams); err != nil {
+	err = run(serviceParams)
+	if stopErr := keeper.stop(context.Background()); stopErr != nil {
+		log.Printf("Error: failed shutting down the receivers kept across config reloads: %v", stopErr)
+	}
+	if rotator != nil {
+		rotator.stop()
+	}
+	if err != nil {
 		log.Fatal(err)
 	}
 }
@@ -153,6 +281,7 @@ func checkRuntimeParams(inputFlags flags) {
 
 	// Set default total memory
 	memTotalSize := defaultMemoryTotalMiB
+	memTotalDetected := false
 	// Check if the total memory is specified via the env var
 	// If so, validate and change total memory
 	if os.Getenv(memTotalEnvVarName) != "" {
@@ -162,10 +291,18 @@ func checkRuntimeParams(inputFlags flags) {
 		if 99 > memTotalSize {
 			log.Fatalf("Expected a number greater than 99 for %s env variable but got %d", memTotalEnvVarName, memTotalSize)
 		}
+	} else if limit, source, ok := containerMemoryLimitMiB(); ok {
+		// Otherwise use the container or job memory limit, if any
+		memTotalSize = limit
+		memTotalDetected = true
+		log.Printf("Set total memory to %d MiB from the %s memory limit", memTotalSize, source)
 	}
 
 	ballastSize := setMemoryBallast(inputFlags, memTotalSize)
 	memLimit := setMemoryLimit(memTotalSize)
+	if memTotalDetected {
+		setGoMemoryLimit(memLimit)
+	}
 
 	// Validate memoryLimit and memoryBallast are sane
 	if 2*ballastSize > memLimit {
@@ -353,6 +490,19 @@ func configLocations(inputFlags flags) []string {
 	}
 }
 
+// configLayers returns the layers merged under the user config: the YAML of the
+// SPLUNK_CONFIG_OVERLAY_YAML env var, when set.
+func configLayers() []configconverter.Layer {
+	return []configconverter.Layer{
+		configconverter.NewProviderLayer("env overlay", envprovider.New(), func() (string, bool) {
+			if os.Getenv(configOverlayEnvVarName) == "" {
+				return "", false
+			}
+			return "env:" + configOverlayEnvVarName, true
+		}),
+	}
+}
+
 func runInteractive(settings service.CollectorSettings) error {
 	cmd := service.NewCommand(settings)
 	if err := cmd.Execute(); err != nil {
@@ -23,3 +23,7 @@ import "go.opentelemetry.io/collector/service"
 func run(params service.CollectorSettings) error {
 	return runInteractive(params)
 }
+
+func loadPlatformConfig() error {
+	return nil
+}
@@ -17,6 +17,7 @@ package main
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"log"
 	"os"
@@ -26,6 +27,11 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+func init() {
+	// the defaults tested here aren't subject to the memory limits of the test environment
+	containerMemoryLimitMiB = func() (int, string, bool) { return 0, "", false }
+}
+
 func testCheckRuntimeParams() {
 	inputFlags, _ := parseFlags(os.Args[1:])
 	checkRuntimeParams(inputFlags)
@@ -54,6 +60,29 @@ func TestCheckRuntimeParams_MemTotalEnv(t *testing.T) {
 	os.Clearenv()
 }
 
+func TestCheckRuntimeParams_ContainerMemoryLimit(t *testing.T) {
+	oldArgs := os.Args
+	previous := containerMemoryLimitMiB
+	containerMemoryLimitMiB = func() (int, string, bool) { return 2000, "cgroup", true }
+	defer func() { containerMemoryLimitMiB = previous }()
+
+	assert.NoError(t, os.Setenv(configEnvVarName, path.Join("../../", defaultLocalSAPMConfig)))
+	testCheckRuntimeParams()
+	assert.Equal(t, "660", os.Getenv(ballastEnvVarName))
+	assert.Equal(t, "1800", os.Getenv(memLimitMiBEnvVarName))
+
+	// the total memory env var takes precedence
+	assert.NoError(t, os.Unsetenv(ballastEnvVarName))
+	assert.NoError(t, os.Unsetenv(memLimitMiBEnvVarName))
+	assert.NoError(t, os.Setenv(memTotalEnvVarName, "1000"))
+	testCheckRuntimeParams()
+	assert.Equal(t, "330", os.Getenv(ballastEnvVarName))
+	assert.Equal(t, "900", os.Getenv(memLimitMiBEnvVarName))
+
+	os.Args = oldArgs
+	os.Clearenv()
+}
+
 func TestCheckRuntimeParams_MemTotalAndBallastEnvs(t *testing.T) {
 	oldArgs := os.Args
 	assert.NoError(t, os.Setenv(configEnvVarName, path.Join("../../", defaultLocalSAPMConfig)))
@@ -306,3 +335,18 @@ func TestRemoveFlag(t *testing.T) {
 	removeFlag(&args, "--aaa")
 	assert.Nil(t, args)
 }
+
+func TestConfigLayers(t *testing.T) {
+	layers := configLayers()
+	assert.Len(t, layers, 1)
+
+	conf, err := layers[0].Retrieve(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Nil(t, conf)
+
+	t.Setenv(configOverlayEnvVarName, "exporters:\n  signalfx:\n    realm: eu0\n")
+	conf, err = layers[0].Retrieve(context.Background(), nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "eu0", conf.Get("exporters::signalfx::realm"))
+	assert.NoError(t, layers[0].Close(context.Background()))
+}
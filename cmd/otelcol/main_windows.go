@@ -54,7 +54,7 @@ func checkUseInteractiveMode() (bool, error) {
 
 func runService(params service.CollectorSettings) error {
 	// do not need to supply service name when startup is invoked through Service Control Manager directly
-	if err := svc.Run("", service.NewSvcHandler(params)); err != nil {
+	if err := svc.Run("", newStatusReportingHandler(service.NewSvcHandler(params))); err != nil {
 		return fmt.Errorf("failed to start service: %w", err)
 	}
 
@@ -0,0 +1,35 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build go1.19
+// +build go1.19
+
+package main
+
+import (
+	"log"
+	"os"
+	"runtime/debug"
+)
+
+// setGoMemoryLimit sets the Go runtime's soft memory limit, unless provided by the GOMEMLIMIT
+// environment variable, so garbage collection intensifies before the memory limiter refuses data.
+func setGoMemoryLimit(limitMiB int) {
+	if _, ok := os.LookupEnv(goMemLimitEnvVarName); ok {
+		return
+	}
+	debug.SetMemoryLimit(int64(limitMiB) * bytesPerMiB)
+	log.Printf("Set Go runtime memory limit to %d MiB", limitMiB)
+}
@@ -0,0 +1,25 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+const (
+	bytesPerMiB          = 1024 * 1024
+	goMemLimitEnvVarName = "GOMEMLIMIT"
+)
+
+// containerMemoryLimitMiB returns the memory limit of the container or job the process runs in
+// and its source, if limited.  It's a variable so tests aren't subject to their environment's limits.
+var containerMemoryLimitMiB = readMemoryLimitMiB
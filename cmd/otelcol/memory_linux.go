@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var (
+	// cgroupRoot is the mount point of the cgroup v2 unified hierarchy.
+	cgroupRoot     = "/sys/fs/cgroup"
+	procSelfCgroup = "/proc/self/cgroup"
+)
+
+// readMemoryLimitMiB returns the cgroup v2 memory.max of the process's cgroup, if limited.
+func readMemoryLimitMiB() (int, string, bool) {
+	for _, dir := range cgroupDirs() {
+		content, err := os.ReadFile(filepath.Join(dir, "memory.max"))
+		if err != nil {
+			continue
+		}
+		value := strings.TrimSpace(string(content))
+		if value == "max" {
+			return 0, "", false
+		}
+		limit, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || limit <= 0 {
+			return 0, "", false
+		}
+		return int(limit / bytesPerMiB), "cgroup", true
+	}
+	return 0, "", false
+}
+
+// cgroupDirs returns the directory of the process's cgroup v2 cgroup, followed by the hierarchy's
+// root, which is the process's cgroup when namespaced like in most containers.
+func cgroupDirs() []string {
+	var dirs []string
+	if content, err := os.ReadFile(procSelfCgroup); err == nil {
+		for _, line := range strings.Split(string(content), "\n") {
+			// the unified hierarchy's entry is "0::<path>"
+			if strings.HasPrefix(line, "0::") {
+				dirs = append(dirs, filepath.Join(cgroupRoot, strings.TrimPrefix(line, "0::")))
+			}
+		}
+	}
+	return append(dirs, cgroupRoot)
+}
@@ -0,0 +1,93 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func setUpCgroup(t *testing.T, cgroupFile string, memoryMax map[string]string) {
+	root := t.TempDir()
+	previousRoot, previousCgroup := cgroupRoot, procSelfCgroup
+	cgroupRoot, procSelfCgroup = root, filepath.Join(root, "cgroup")
+	t.Cleanup(func() { cgroupRoot, procSelfCgroup = previousRoot, previousCgroup })
+
+	require.NoError(t, os.WriteFile(procSelfCgroup, []byte(cgroupFile), 0600))
+	for dir, value := range memoryMax {
+		require.NoError(t, os.MkdirAll(filepath.Join(root, dir), 0700))
+		require.NoError(t, os.WriteFile(filepath.Join(root, dir, "memory.max"), []byte(value), 0600))
+	}
+}
+
+func TestReadMemoryLimitMiB(t *testing.T) {
+	for _, test := range []struct {
+		memoryMax  map[string]string
+		name       string
+		cgroupFile string
+		expected   int
+		limited    bool
+	}{
+		{
+			name:       "namespaced",
+			cgroupFile: "0::/\n",
+			memoryMax:  map[string]string{"": "1073741824\n"},
+			expected:   1024,
+			limited:    true,
+		},
+		{
+			name:       "nested",
+			cgroupFile: "0::/kubepods/pod1/container1\n",
+			memoryMax: map[string]string{
+				"":                         "max\n",
+				"kubepods/pod1/container1": "536870912\n",
+			},
+			expected: 512,
+			limited:  true,
+		},
+		{
+			name:       "unlimited",
+			cgroupFile: "0::/\n",
+			memoryMax:  map[string]string{"": "max\n"},
+		},
+		{
+			name:       "cgroup v1",
+			cgroupFile: "12:memory:/docker/abc\n",
+		},
+		{
+			name:       "invalid",
+			cgroupFile: "0::/\n",
+			memoryMax:  map[string]string{"": "lots\n"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			setUpCgroup(t, test.cgroupFile, test.memoryMax)
+			limit, source, limited := readMemoryLimitMiB()
+			assert.Equal(t, test.limited, limited)
+			assert.Equal(t, test.expected, limit)
+			if limited {
+				assert.Equal(t, "cgroup", source)
+			}
+		})
+	}
+}
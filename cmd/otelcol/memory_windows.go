@@ -0,0 +1,51 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// readMemoryLimitMiB returns the lower of the job and process memory limits of the job object
+// the process is assigned to, if limited.
+func readMemoryLimitMiB() (int, string, bool) {
+	var info windows.JOBOBJECT_EXTENDED_LIMIT_INFORMATION
+	// a zero handle queries the job object of the current process
+	if err := windows.QueryInformationJobObject(
+		0, windows.JobObjectExtendedLimitInformation,
+		uintptr(unsafe.Pointer(&info)), uint32(unsafe.Sizeof(info)), nil,
+	); err != nil {
+		return 0, "", false
+	}
+
+	var limit uintptr
+	flags := info.BasicLimitInformation.LimitFlags
+	if flags&windows.JOB_OBJECT_LIMIT_JOB_MEMORY != 0 {
+		limit = info.JobMemoryLimit
+	}
+	if flags&windows.JOB_OBJECT_LIMIT_PROCESS_MEMORY != 0 && (limit == 0 || info.ProcessMemoryLimit < limit) {
+		limit = info.ProcessMemoryLimit
+	}
+	if limit == 0 {
+		return 0, "", false
+	}
+	return int(limit / bytesPerMiB), "job object", true
+}
@@ -0,0 +1,56 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver"
+)
+
+const monitorsCommand = "monitors"
+
+// runMonitors lists the Smart Agent monitor types of the smartagent receiver with "list", or outputs the
+// JSON schema of a monitor type's config with "schema <type>".
+func runMonitors(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return fmt.Errorf("the %s command requires a list or schema subcommand", monitorsCommand)
+	}
+	switch args[0] {
+	case "list":
+		if len(args) != 1 {
+			return fmt.Errorf("unexpected arguments for the %s list command: %v", monitorsCommand, args[1:])
+		}
+		for _, monitorType := range smartagentreceiver.MonitorTypes() {
+			fmt.Fprintln(out, monitorType)
+		}
+		return nil
+	case "schema":
+		if len(args) != 2 {
+			return fmt.Errorf("the %s schema command requires a single monitor type", monitorsCommand)
+		}
+		schema, err := smartagentreceiver.MonitorConfigSchema(args[1])
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(out)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(schema)
+	}
+	return fmt.Errorf("unknown %s subcommand %q, expected list or schema", monitorsCommand, args[0])
+}
@@ -0,0 +1,59 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunMonitorsList(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, runMonitors([]string{"list"}, out))
+	assert.Regexp(t, `(?m)^collectd/redis$`, out.String())
+	assert.Regexp(t, `(?m)^oracledb$`, out.String())
+}
+
+func TestRunMonitorsSchema(t *testing.T) {
+	out := new(bytes.Buffer)
+	require.NoError(t, runMonitors([]string{"schema", "collectd/redis"}, out))
+
+	var schema map[string]any
+	require.NoError(t, json.Unmarshal(out.Bytes(), &schema))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"])
+	assert.Equal(t, "object", schema["type"])
+	assert.Contains(t, schema["properties"], "host")
+	assert.Contains(t, schema["properties"], "port")
+}
+
+func TestRunMonitorsInvalidArgs(t *testing.T) {
+	for _, test := range []struct {
+		args        []string
+		expectedErr string
+	}{
+		{nil, "the monitors command requires a list or schema subcommand"},
+		{[]string{"list", "collectd/redis"}, "unexpected arguments for the monitors list command: [collectd/redis]"},
+		{[]string{"schema"}, "the monitors schema command requires a single monitor type"},
+		{[]string{"schema", "notamonitor"}, `no known monitor type "notamonitor"`},
+		{[]string{"describe"}, `unknown monitors subcommand "describe", expected list or schema`},
+	} {
+		assert.EqualError(t, runMonitors(test.args, new(bytes.Buffer)), test.expectedErr)
+	}
+}
@@ -0,0 +1,83 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+
+	"golang.org/x/sys/windows/registry"
+)
+
+const (
+	// policiesRegistryKey values are set by Group Policy and take precedence over existing environment variables.
+	policiesRegistryKey = `SOFTWARE\Policies\Splunk\OpenTelemetry Collector`
+	// settingsRegistryKey values are only used for environment variables that aren't already set.
+	settingsRegistryKey = `SOFTWARE\Splunk\OpenTelemetry Collector`
+)
+
+// loadPlatformConfig sets collector options (e.g. SPLUNK_CONFIG, SPLUNK_ACCESS_TOKEN) from the string values
+// of the collector's registry keys so that they can be centrally managed for fleets of Windows hosts.
+func loadPlatformConfig() error {
+	if err := setEnvVarsFromRegistry(registry.LOCAL_MACHINE, policiesRegistryKey, true); err != nil {
+		return err
+	}
+	return setEnvVarsFromRegistry(registry.LOCAL_MACHINE, settingsRegistryKey, false)
+}
+
+func setEnvVarsFromRegistry(root registry.Key, path string, override bool) error {
+	key, err := registry.OpenKey(root, path, registry.QUERY_VALUE)
+	if err != nil {
+		if errors.Is(err, registry.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed opening registry key %q: %w", path, err)
+	}
+	defer key.Close()
+
+	names, err := key.ReadValueNames(0)
+	if err != nil {
+		return fmt.Errorf("failed reading registry key %q values: %w", path, err)
+	}
+	for _, name := range names {
+		if _, set := os.LookupEnv(name); set && !override {
+			continue
+		}
+		value, valueType, err := key.GetStringValue(name)
+		if err != nil {
+			if errors.Is(err, registry.ErrUnexpectedType) {
+				log.Printf("Ignoring non-string registry value %q of %q", name, path)
+				continue
+			}
+			return fmt.Errorf("failed reading registry value %q of %q: %w", name, path, err)
+		}
+		if valueType == registry.EXPAND_SZ {
+			if value, err = registry.ExpandString(value); err != nil {
+				return fmt.Errorf("failed expanding registry value %q of %q: %w", name, path, err)
+			}
+		}
+		if err = os.Setenv(name, value); err != nil {
+			return fmt.Errorf("failed setting %s from registry: %w", name, err)
+		}
+		log.Printf("Set %s from registry key %q", name, path)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/sys/windows/registry"
+)
+
+const testRegistryKey = `SOFTWARE\Splunk\OpenTelemetry Collector Test`
+
+func TestSetEnvVarsFromRegistry(t *testing.T) {
+	key, _, err := registry.CreateKey(registry.CURRENT_USER, testRegistryKey, registry.ALL_ACCESS)
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, key.Close())
+		require.NoError(t, registry.DeleteKey(registry.CURRENT_USER, testRegistryKey))
+	}()
+	require.NoError(t, key.SetStringValue("SPLUNK_REGISTRY_TEST_SET", "from_registry"))
+	require.NoError(t, key.SetStringValue("SPLUNK_REGISTRY_TEST_UNSET", "from_registry"))
+	require.NoError(t, key.SetExpandStringValue("SPLUNK_REGISTRY_TEST_EXPAND", `%SPLUNK_REGISTRY_TEST_DIR%\config.yaml`))
+	require.NoError(t, key.SetDWordValue("SPLUNK_REGISTRY_TEST_DWORD", 1))
+
+	t.Setenv("SPLUNK_REGISTRY_TEST_SET", "from_env")
+	t.Setenv("SPLUNK_REGISTRY_TEST_DIR", `C:\config`)
+	for _, name := range []string{"SPLUNK_REGISTRY_TEST_UNSET", "SPLUNK_REGISTRY_TEST_EXPAND", "SPLUNK_REGISTRY_TEST_DWORD"} {
+		defer os.Unsetenv(name)
+	}
+
+	require.NoError(t, setEnvVarsFromRegistry(registry.CURRENT_USER, testRegistryKey, false))
+	assert.Equal(t, "from_env", os.Getenv("SPLUNK_REGISTRY_TEST_SET"))
+	assert.Equal(t, "from_registry", os.Getenv("SPLUNK_REGISTRY_TEST_UNSET"))
+	assert.Equal(t, `C:\config\config.yaml`, os.Getenv("SPLUNK_REGISTRY_TEST_EXPAND"))
+	_, set := os.LookupEnv("SPLUNK_REGISTRY_TEST_DWORD")
+	assert.False(t, set)
+
+	require.NoError(t, setEnvVarsFromRegistry(registry.CURRENT_USER, testRegistryKey, true))
+	assert.Equal(t, "from_registry", os.Getenv("SPLUNK_REGISTRY_TEST_SET"))
+}
+
+func TestSetEnvVarsFromMissingRegistryKey(t *testing.T) {
+	require.NoError(t, setEnvVarsFromRegistry(registry.CURRENT_USER, `SOFTWARE\Splunk\Nonexistent Collector Key`, true))
+}
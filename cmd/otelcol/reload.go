@@ -0,0 +1,455 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/service"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// keptReceiverTypes are the types of the receivers kept running across config reloads when their
+// config is unchanged. They listen for the data of senders that would otherwise see their
+// connections reset by reloads, and only use the host of the service starting them for reporting
+// fatal errors, which outlives the reloads, or for the extensions referenced by their config.
+var keptReceiverTypes = map[config.Type]bool{
+	"carbon":        true,
+	"collectd":      true,
+	"fluentforward": true,
+	"jaeger":        true,
+	"nagios":        true,
+	"otlp":          true,
+	"sapm":          true,
+	"signalfx":      true,
+	"snmptrap":      true,
+	"splunk_hec":    true,
+	"statsd":        true,
+	"syslog":        true,
+	"tcplog":        true,
+	"zipkin":        true,
+}
+
+var errReceiverShutdown = errors.New("the receiver is shut down")
+
+// receiverKey identifies a receiver created by the service, which creates one for each data type
+// of the pipelines using it.
+type receiverKey struct {
+	id       config.ComponentID
+	dataType config.DataType
+}
+
+// receiverKeeper keeps receivers running across config reloads, which otherwise restart all of the
+// components. The shutdowns of the service retiring on a reload park the receivers that can be kept,
+// and when the service of the reloaded config starts, it takes over the parked receivers whose config
+// and data types are unchanged, which then send their data to its pipelines. The other parked
+// receivers are shut down before any of the new receivers start, so that they release their
+// listeners. Receivers referencing other components, like authenticator or storage extensions, aren't
+// kept, since those are restarted.
+type receiverKeeper struct {
+	types map[config.Type]bool
+	// parked are the receivers of the retiring service, by their key
+	parked map[receiverKey]*keptReceiver
+	// created are the receivers of the service being built, until it starts
+	created  []*keptReceiver
+	stopping bool
+	lock     sync.Mutex
+}
+
+func newReceiverKeeper(types map[config.Type]bool) *receiverKeeper {
+	return &receiverKeeper{
+		types:  types,
+		parked: map[receiverKey]*keptReceiver{},
+	}
+}
+
+// keepReceivers wraps the receiver factories so that their receivers are kept across config
+// reloads by the keeper.
+func keepReceivers(factories component.Factories, keeper *receiverKeeper) component.Factories {
+	receivers := make(map[config.Type]component.ReceiverFactory, len(factories.Receivers))
+	for typ, factory := range factories.Receivers {
+		receivers[typ] = keptReceiverFactory{ReceiverFactory: factory, keeper: keeper}
+	}
+	factories.Receivers = receivers
+	return factories
+}
+
+// configProvider wraps the collector's config provider, which is shut down before the service only
+// when the collector stops, so that the receivers are then shut down instead of being parked.
+func (k *receiverKeeper) configProvider(provider service.ConfigProvider) service.ConfigProvider {
+	return keeperConfigProvider{ConfigProvider: provider, keeper: k}
+}
+
+func (k *receiverKeeper) create(set component.ReceiverCreateSettings, cfg config.Receiver, dataType config.DataType, next interface{},
+	create func(*reloadConsumer) (component.Receiver, error)) (component.Receiver, error) {
+	r := &keptReceiver{
+		keeper:   k,
+		key:      receiverKey{id: cfg.ID(), dataType: dataType},
+		cfg:      cfg,
+		keepable: k.types[cfg.ID().Type()] && !referencesComponents(reflect.ValueOf(cfg)),
+		next:     next,
+		consumer: newReloadConsumer(next),
+		create:   create,
+		logger:   set.Logger,
+	}
+
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	// the receiver is created once the new service starts if it can take over a parked one
+	if _, ok := k.parked[r.key]; !ok || !r.keepable {
+		var err error
+		if r.receiver, err = create(r.consumer); err != nil {
+			return nil, err
+		}
+	}
+	k.created = append(k.created, r)
+	return r, nil
+}
+
+// resolve lets the receivers of the starting service take over the parked receivers, shuts down the
+// others, and creates the receivers that couldn't take over one.
+func (k *receiverKeeper) resolve(ctx context.Context) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if len(k.created) == 0 {
+		return nil
+	}
+	created := k.created
+	k.created = nil
+
+	byID := map[config.ComponentID][]*keptReceiver{}
+	for _, r := range created {
+		byID[r.key.id] = append(byID[r.key.id], r)
+	}
+	kept := map[config.ComponentID]bool{}
+	for id, receivers := range byID {
+		kept[id] = k.canTakeOver(id, receivers)
+	}
+
+	var errs error
+	for key, parked := range k.parked {
+		if !kept[key.id] {
+			delete(k.parked, key)
+			errs = multierr.Append(errs, parked.shutdown(ctx))
+		}
+	}
+	for _, r := range created {
+		if kept[r.key.id] {
+			parked := k.parked[r.key]
+			delete(k.parked, r.key)
+			r.receiver, r.consumer, r.running = parked.receiver, parked.consumer, true
+			r.consumer.resume(r.next)
+			r.logger.Info("Kept the receiver running across the config reload since its config is unchanged")
+			continue
+		}
+		if r.receiver == nil {
+			receiver, err := r.create(r.consumer)
+			if err != nil {
+				errs = multierr.Append(errs, err)
+				continue
+			}
+			r.receiver = receiver
+		}
+	}
+	return errs
+}
+
+// canTakeOver returns whether the receivers of the ID can take over its parked receivers. All of
+// them must be taken over at once, since the receivers of the different data types of an ID, like
+// those of the otlp receiver, can share their instance.
+func (k *receiverKeeper) canTakeOver(id config.ComponentID, receivers []*keptReceiver) bool {
+	parked := 0
+	for key := range k.parked {
+		if key.id == id {
+			parked++
+		}
+	}
+	if parked != len(receivers) {
+		return false
+	}
+	for _, r := range receivers {
+		p, ok := k.parked[r.key]
+		if !ok || !r.keepable || !reflect.DeepEqual(p.cfg, r.cfg) {
+			return false
+		}
+	}
+	return true
+}
+
+// park parks the receiver, unless it can't be kept or the collector is stopping.
+func (k *receiverKeeper) park(r *keptReceiver) bool {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	if k.stopping || !r.keepable || r.receiver == nil {
+		return false
+	}
+	k.parked[r.key] = r
+	return true
+}
+
+func (k *receiverKeeper) setStopping() {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.stopping = true
+}
+
+// stop shuts down the parked receivers, which remain when the service of a reloaded config fails
+// to start.
+func (k *receiverKeeper) stop(ctx context.Context) error {
+	k.lock.Lock()
+	defer k.lock.Unlock()
+	k.stopping = true
+	var errs error
+	for key, parked := range k.parked {
+		delete(k.parked, key)
+		errs = multierr.Append(errs, parked.shutdown(ctx))
+	}
+	return errs
+}
+
+// referencesComponents returns whether the config references other components by their ID.
+func referencesComponents(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return !v.IsNil() && referencesComponents(v.Elem())
+	case reflect.Struct:
+		switch v.Type() {
+		case reflect.TypeOf(config.ComponentID{}):
+			return !v.IsZero()
+		case reflect.TypeOf(config.ReceiverSettings{}):
+			// holds the ID of the receiver itself
+			return false
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if referencesComponents(v.Field(i)) {
+				return true
+			}
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			if referencesComponents(v.Index(i)) {
+				return true
+			}
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() {
+			if referencesComponents(iter.Value()) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type keeperConfigProvider struct {
+	service.ConfigProvider
+	keeper *receiverKeeper
+}
+
+func (p keeperConfigProvider) Shutdown(ctx context.Context) error {
+	p.keeper.setStopping()
+	return p.ConfigProvider.Shutdown(ctx)
+}
+
+type keptReceiverFactory struct {
+	component.ReceiverFactory
+	keeper *receiverKeeper
+}
+
+func (f keptReceiverFactory) CreateTracesReceiver(ctx context.Context, set component.ReceiverCreateSettings, cfg config.Receiver, nextConsumer consumer.Traces) (component.TracesReceiver, error) {
+	return f.keeper.create(set, cfg, config.TracesDataType, nextConsumer, func(c *reloadConsumer) (component.Receiver, error) {
+		return f.ReceiverFactory.CreateTracesReceiver(ctx, set, cfg, c)
+	})
+}
+
+func (f keptReceiverFactory) CreateMetricsReceiver(ctx context.Context, set component.ReceiverCreateSettings, cfg config.Receiver, nextConsumer consumer.Metrics) (component.MetricsReceiver, error) {
+	return f.keeper.create(set, cfg, config.MetricsDataType, nextConsumer, func(c *reloadConsumer) (component.Receiver, error) {
+		return f.ReceiverFactory.CreateMetricsReceiver(ctx, set, cfg, c)
+	})
+}
+
+func (f keptReceiverFactory) CreateLogsReceiver(ctx context.Context, set component.ReceiverCreateSettings, cfg config.Receiver, nextConsumer consumer.Logs) (component.LogsReceiver, error) {
+	return f.keeper.create(set, cfg, config.LogsDataType, nextConsumer, func(c *reloadConsumer) (component.Receiver, error) {
+		return f.ReceiverFactory.CreateLogsReceiver(ctx, set, cfg, c)
+	})
+}
+
+// keptReceiver is a receiver created by the service, which it takes over from the retiring service
+// on reloads when it can.
+type keptReceiver struct {
+	keeper *receiverKeeper
+	key    receiverKey
+	cfg    config.Receiver
+	// keepable is whether the receiver can be kept across reloads
+	keepable bool
+	next     interface{}
+	consumer *reloadConsumer
+	create   func(*reloadConsumer) (component.Receiver, error)
+	logger   *zap.Logger
+	// receiver is nil until the service starts when it can take over a parked receiver
+	receiver component.Receiver
+	// running is whether the receiver was taken over, running already
+	running bool
+}
+
+func (r *keptReceiver) Start(ctx context.Context, host component.Host) error {
+	// the service starts the receivers once all of them are created
+	if err := r.keeper.resolve(ctx); err != nil {
+		return err
+	}
+	if r.running || r.receiver == nil {
+		return nil
+	}
+	return r.receiver.Start(ctx, host)
+}
+
+func (r *keptReceiver) Shutdown(ctx context.Context) error {
+	if r.keeper.park(r) {
+		// waits for the data being consumed by the retiring pipelines
+		r.consumer.pause()
+		return nil
+	}
+	if r.receiver == nil {
+		return nil
+	}
+	err := r.receiver.Shutdown(ctx)
+	r.consumer.close()
+	return err
+}
+
+// shutdown shuts down the parked receiver, failing the data it holds for the reloaded pipelines.
+func (r *keptReceiver) shutdown(ctx context.Context) error {
+	r.consumer.close()
+	return r.receiver.Shutdown(ctx)
+}
+
+// reloadConsumer is the next consumer of the kept receivers, which it switches to the pipelines of
+// the reloaded config. While the receiver is parked, the data it receives waits for them.
+type reloadConsumer struct {
+	// next is nil while the receiver is parked
+	next interface{}
+	// resumed is closed once the consumer has a next consumer again or is closed
+	resumed  chan struct{}
+	closed   bool
+	inflight sync.WaitGroup
+	lock     sync.Mutex
+}
+
+func newReloadConsumer(next interface{}) *reloadConsumer {
+	c := &reloadConsumer{next: next, resumed: make(chan struct{})}
+	close(c.resumed)
+	return c
+}
+
+// acquire returns the next consumer, once the receiver isn't parked, and records the data being
+// consumed by it until released.
+func (c *reloadConsumer) acquire(ctx context.Context) (interface{}, error) {
+	for {
+		c.lock.Lock()
+		if c.closed {
+			c.lock.Unlock()
+			return nil, errReceiverShutdown
+		}
+		if c.next != nil {
+			next := c.next
+			c.inflight.Add(1)
+			c.lock.Unlock()
+			return next, nil
+		}
+		resumed := c.resumed
+		c.lock.Unlock()
+
+		select {
+		case <-resumed:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// pause holds the data received from now on until resumed, and waits for the data being consumed.
+func (c *reloadConsumer) pause() {
+	c.lock.Lock()
+	c.next = nil
+	c.resumed = make(chan struct{})
+	c.lock.Unlock()
+	c.inflight.Wait()
+}
+
+func (c *reloadConsumer) resume(next interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.next = next
+	close(c.resumed)
+}
+
+func (c *reloadConsumer) close() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.closed {
+		return
+	}
+	if c.next == nil {
+		close(c.resumed)
+	}
+	c.closed = true
+}
+
+func (c *reloadConsumer) Capabilities() consumer.Capabilities {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if next, ok := c.next.(interface{ Capabilities() consumer.Capabilities }); ok {
+		return next.Capabilities()
+	}
+	return consumer.Capabilities{}
+}
+
+func (c *reloadConsumer) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	next, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+	return next.(consumer.Traces).ConsumeTraces(ctx, td)
+}
+
+func (c *reloadConsumer) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	next, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+	return next.(consumer.Metrics).ConsumeMetrics(ctx, md)
+}
+
+func (c *reloadConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	next, err := c.acquire(ctx)
+	if err != nil {
+		return err
+	}
+	defer c.inflight.Done()
+	return next.(consumer.Logs).ConsumeLogs(ctx, ld)
+}
@@ -0,0 +1,212 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/service"
+)
+
+type testReceiverConfig struct {
+	config.ReceiverSettings       `mapstructure:",squash"`
+	confighttp.HTTPServerSettings `mapstructure:",squash"`
+}
+
+type testReceiver struct {
+	next     consumer.Metrics
+	started  int
+	shutdown int
+}
+
+func (r *testReceiver) Start(context.Context, component.Host) error {
+	r.started++
+	return nil
+}
+
+func (r *testReceiver) Shutdown(context.Context) error {
+	r.shutdown++
+	return nil
+}
+
+// testReceivers records the receivers created by their factory.
+type testReceivers struct {
+	created []*testReceiver
+	lock    sync.Mutex
+}
+
+func (rs *testReceivers) factory() component.ReceiverFactory {
+	return component.NewReceiverFactory("test", func() config.Receiver {
+		return &testReceiverConfig{
+			ReceiverSettings:   config.NewReceiverSettings(config.NewComponentID("test")),
+			HTTPServerSettings: confighttp.HTTPServerSettings{Endpoint: "localhost:1234"},
+		}
+	}, component.WithMetricsReceiver(func(_ context.Context, _ component.ReceiverCreateSettings, _ config.Receiver, next consumer.Metrics) (component.MetricsReceiver, error) {
+		rs.lock.Lock()
+		defer rs.lock.Unlock()
+		r := &testReceiver{next: next}
+		rs.created = append(rs.created, r)
+		return r, nil
+	}))
+}
+
+func (rs *testReceivers) last() *testReceiver {
+	rs.lock.Lock()
+	defer rs.lock.Unlock()
+	return rs.created[len(rs.created)-1]
+}
+
+func newTestMetrics() pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("metric")
+	return md
+}
+
+// startTestReceiver creates and starts the receiver of the test factory, as a service would.
+func startTestReceiver(t *testing.T, factory component.ReceiverFactory, cfg config.Receiver, next consumer.Metrics) component.Receiver {
+	receiver, err := factory.CreateMetricsReceiver(context.Background(), componenttest.NewNopReceiverCreateSettings(), cfg, next)
+	require.NoError(t, err)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	return receiver
+}
+
+func TestKeepReceiversAcrossReloads(t *testing.T) {
+	receivers := &testReceivers{}
+	keeper := newReceiverKeeper(map[config.Type]bool{"test": true})
+	factory := keepReceivers(component.Factories{
+		Receivers: map[config.Type]component.ReceiverFactory{"test": receivers.factory()},
+	}, keeper).Receivers["test"]
+	ctx := context.Background()
+
+	sink := new(consumertest.MetricsSink)
+	receiver := startTestReceiver(t, factory, factory.CreateDefaultConfig(), sink)
+	kept := receivers.last()
+	require.NoError(t, kept.next.ConsumeMetrics(ctx, newTestMetrics()))
+	assert.Len(t, sink.AllMetrics(), 1)
+
+	// the data received during the reload waits for the pipelines of the reloaded config
+	require.NoError(t, receiver.Shutdown(ctx))
+	consumed := make(chan error, 1)
+	go func() { consumed <- kept.next.ConsumeMetrics(ctx, newTestMetrics()) }()
+	select {
+	case err := <-consumed:
+		t.Fatalf("the data received by a parked receiver was consumed: %v", err)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	reloadedSink := new(consumertest.MetricsSink)
+	receiver = startTestReceiver(t, factory, factory.CreateDefaultConfig(), reloadedSink)
+	require.NoError(t, <-consumed)
+	assert.Len(t, receivers.created, 1)
+	assert.Equal(t, 1, kept.started)
+	assert.Zero(t, kept.shutdown)
+	assert.Len(t, sink.AllMetrics(), 1)
+	assert.Len(t, reloadedSink.AllMetrics(), 1)
+
+	// changed receivers are restarted
+	require.NoError(t, receiver.Shutdown(ctx))
+	cfg := factory.CreateDefaultConfig().(*testReceiverConfig)
+	cfg.Endpoint = "localhost:4321"
+	receiver = startTestReceiver(t, factory, cfg, reloadedSink)
+	require.Len(t, receivers.created, 2)
+	assert.Equal(t, 1, kept.shutdown)
+	assert.ErrorIs(t, kept.next.ConsumeMetrics(ctx, newTestMetrics()), errReceiverShutdown)
+	restarted := receivers.last()
+	assert.Equal(t, 1, restarted.started)
+
+	// the collector stopping shuts down the receivers
+	require.NoError(t, keeper.configProvider(nopConfigProvider{}).Shutdown(ctx))
+	require.NoError(t, receiver.Shutdown(ctx))
+	assert.Equal(t, 1, restarted.shutdown)
+	require.NoError(t, keeper.stop(ctx))
+}
+
+func TestKeepReceiversShutsDownRemovedReceivers(t *testing.T) {
+	receivers := &testReceivers{}
+	keeper := newReceiverKeeper(map[config.Type]bool{"test": true})
+	factory := keepReceivers(component.Factories{
+		Receivers: map[config.Type]component.ReceiverFactory{"test": receivers.factory()},
+	}, keeper).Receivers["test"]
+	ctx := context.Background()
+
+	receiver := startTestReceiver(t, factory, factory.CreateDefaultConfig(), consumertest.NewNop())
+	removed := receivers.last()
+	require.NoError(t, receiver.Shutdown(ctx))
+	assert.Zero(t, removed.shutdown)
+
+	// the parked receiver is shut down before the receivers of the reloaded config start
+	cfg := factory.CreateDefaultConfig().(*testReceiverConfig)
+	cfg.SetIDName("renamed")
+	receiver = startTestReceiver(t, factory, cfg, consumertest.NewNop())
+	assert.Equal(t, 1, removed.shutdown)
+	require.Len(t, receivers.created, 2)
+
+	// parked receivers remaining when the collector stops are shut down
+	require.NoError(t, receiver.Shutdown(ctx))
+	renamed := receivers.last()
+	assert.Zero(t, renamed.shutdown)
+	require.NoError(t, keeper.stop(ctx))
+	assert.Equal(t, 1, renamed.shutdown)
+}
+
+func TestKeepReceiversRestartsReceiversReferencingComponents(t *testing.T) {
+	receivers := &testReceivers{}
+	keeper := newReceiverKeeper(map[config.Type]bool{"test": true})
+	factory := keepReceivers(component.Factories{
+		Receivers: map[config.Type]component.ReceiverFactory{"test": receivers.factory()},
+	}, keeper).Receivers["test"]
+	ctx := context.Background()
+
+	newConfig := func() config.Receiver {
+		cfg := factory.CreateDefaultConfig().(*testReceiverConfig)
+		cfg.Auth = &configauth.Authentication{AuthenticatorID: config.NewComponentID("token_auth")}
+		return cfg
+	}
+	receiver := startTestReceiver(t, factory, newConfig(), consumertest.NewNop())
+	require.NoError(t, receiver.Shutdown(ctx))
+	startTestReceiver(t, factory, newConfig(), consumertest.NewNop())
+	require.Len(t, receivers.created, 2)
+	assert.Equal(t, 1, receivers.created[0].shutdown)
+}
+
+func TestReferencesComponents(t *testing.T) {
+	cfg := &testReceiverConfig{ReceiverSettings: config.NewReceiverSettings(config.NewComponentID("test"))}
+	assert.False(t, referencesComponents(reflect.ValueOf(cfg)))
+	cfg.Auth = &configauth.Authentication{AuthenticatorID: config.NewComponentID("token_auth")}
+	assert.True(t, referencesComponents(reflect.ValueOf(cfg)))
+	assert.True(t, referencesComponents(reflect.ValueOf(map[string][]config.ComponentID{"observers": {config.NewComponentID("k8s_observer")}})))
+}
+
+type nopConfigProvider struct {
+	service.ConfigProvider
+}
+
+func (nopConfigProvider) Shutdown(context.Context) error {
+	return nil
+}
@@ -0,0 +1,120 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/signalfx/splunk-otel-collector/internal/exporter/otlparchiveexporter"
+)
+
+const replayCommand = "replay"
+
+// runReplay re-sends the batches of the otlparchive exporter files and directories in args to
+// an OTLP/HTTP endpoint, in the order they were archived, and reports the number of batches
+// sent for each signal. The incomplete last batch of a partial file is skipped.
+func runReplay(args []string, out io.Writer) error {
+	flagSet := flag.NewFlagSet(replayCommand, flag.ContinueOnError)
+	flagSet.SetOutput(out)
+	endpoint := flagSet.String("endpoint", "http://localhost:4318", "The OTLP/HTTP endpoint to send the archived batches to")
+	includePartial := flagSet.Bool("include-partial", false, "Also replay the archive files still being written")
+	timeout := flagSet.Duration("timeout", 10*time.Second, "The timeout of each request to the endpoint")
+	if err := flagSet.Parse(args); err != nil {
+		return err
+	}
+	if flagSet.NArg() == 0 {
+		return fmt.Errorf("the %s command requires archive files or directories", replayCommand)
+	}
+
+	var files []otlparchiveexporter.ArchiveFile
+	for _, path := range flagSet.Args() {
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			dirFiles, err := otlparchiveexporter.ListArchiveFiles(path, *includePartial)
+			if err != nil {
+				return err
+			}
+			files = append(files, dirFiles...)
+			continue
+		}
+		file, ok := otlparchiveexporter.ParseArchiveFile(path)
+		if !ok {
+			return fmt.Errorf("%s isn't an otlparchive exporter file", path)
+		}
+		files = append(files, file)
+	}
+	// archives split across directories, like those copied from several hosts, are replayed together
+	otlparchiveexporter.SortArchiveFiles(files)
+
+	client := &http.Client{Timeout: *timeout}
+	sent := map[string]int{}
+	for _, file := range files {
+		url := strings.TrimSuffix(*endpoint, "/") + "/v1/" + file.Signal
+		contentType := "application/x-protobuf"
+		if file.Format == otlparchiveexporter.FormatJSON {
+			contentType = "application/json"
+		}
+		err := otlparchiveexporter.ReadBatches(file, func(batch []byte) error {
+			if err := sendBatch(client, url, contentType, batch); err != nil {
+				return err
+			}
+			sent[file.Signal]++
+			return nil
+		})
+		if err != nil && !(file.Partial && errors.Is(err, io.ErrUnexpectedEOF)) {
+			// the last record of a partial file can be incomplete
+			return fmt.Errorf("failed replaying %s: %w", file.Path, err)
+		}
+	}
+
+	writer := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(writer, "SIGNAL\tBATCHES")
+	for _, signal := range []string{otlparchiveexporter.SignalMetrics, otlparchiveexporter.SignalLogs, otlparchiveexporter.SignalTraces} {
+		fmt.Fprintf(writer, "%s\t%d\n", signal, sent[signal])
+	}
+	fmt.Fprintf(writer, "Replayed %d archive files to %s\n", len(files), *endpoint)
+	return writer.Flush()
+}
+
+func sendBatch(client *http.Client, url, contentType string, batch []byte) error {
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(batch))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", contentType)
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("%s responded with %s", url, resp.Status)
+	}
+	return nil
+}
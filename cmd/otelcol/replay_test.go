@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type replayRequest struct {
+	path        string
+	contentType string
+	body        string
+}
+
+func newReplayServer(t *testing.T, status int) (*httptest.Server, func() []replayRequest) {
+	var lock sync.Mutex
+	var requests []replayRequest
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		lock.Lock()
+		requests = append(requests, replayRequest{path: r.URL.Path, contentType: r.Header.Get("Content-Type"), body: string(body)})
+		lock.Unlock()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, func() []replayRequest {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]replayRequest(nil), requests...)
+	}
+}
+
+func writeArchiveFile(t *testing.T, dir, name, content string) string {
+	path := filepath.Join(dir, name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	return path
+}
+
+func TestRunReplay(t *testing.T) {
+	server, requests := newReplayServer(t, http.StatusOK)
+	dir := t.TempDir()
+	writeArchiveFile(t, dir, "metrics-20220701T100000.000000000Z.json", "{\"m\":1}\n{\"m\":2}\n")
+	writeArchiveFile(t, dir, "metrics-20220701T110000.000000000Z.json", "{\"m\":3}\n")
+	writeArchiveFile(t, dir, "traces-20220701T100000.000000000Z.pb", "\x03abc")
+	writeArchiveFile(t, dir, "logs-20220701T120000.000000000Z.json.partial", "{\"l\":1}\n")
+	writeArchiveFile(t, dir, "unrelated.txt", "unrelated")
+
+	out := new(bytes.Buffer)
+	require.NoError(t, runReplay([]string{"--endpoint", server.URL, dir}, out))
+	assert.Equal(t, []replayRequest{
+		{path: "/v1/metrics", contentType: "application/json", body: `{"m":1}`},
+		{path: "/v1/metrics", contentType: "application/json", body: `{"m":2}`},
+		{path: "/v1/metrics", contentType: "application/json", body: `{"m":3}`},
+		{path: "/v1/traces", contentType: "application/x-protobuf", body: "abc"},
+	}, requests())
+	assert.Regexp(t, `(?m)^metrics\s+3$`, out.String())
+	assert.Regexp(t, `(?m)^logs\s+0$`, out.String())
+	assert.Regexp(t, `(?m)^traces\s+1$`, out.String())
+	assert.Contains(t, out.String(), "Replayed 3 archive files to "+server.URL)
+}
+
+func TestRunReplayDirectoriesInOrder(t *testing.T) {
+	server, requests := newReplayServer(t, http.StatusOK)
+	newer, older := t.TempDir(), t.TempDir()
+	writeArchiveFile(t, newer, "metrics-20220701T110000.000000000Z.json", "{\"m\":2}\n")
+	writeArchiveFile(t, older, "metrics-20220701T100000.000000000Z.json", "{\"m\":1}\n")
+	writeArchiveFile(t, older, "logs-20220701T120000.000000000Z.json", "{\"l\":1}\n")
+
+	require.NoError(t, runReplay([]string{"--endpoint", server.URL, newer, older}, new(bytes.Buffer)))
+	assert.Equal(t, []replayRequest{
+		{path: "/v1/logs", contentType: "application/json", body: `{"l":1}`},
+		{path: "/v1/metrics", contentType: "application/json", body: `{"m":1}`},
+		{path: "/v1/metrics", contentType: "application/json", body: `{"m":2}`},
+	}, requests())
+}
+
+func TestRunReplayIncludePartial(t *testing.T) {
+	server, requests := newReplayServer(t, http.StatusOK)
+	dir := t.TempDir()
+	partial := writeArchiveFile(t, dir, "logs-20220701T120000.000000000Z.json.partial", "{\"l\":1}\n{\"l\":")
+
+	require.NoError(t, runReplay([]string{"--endpoint", server.URL, "--include-partial", dir}, new(bytes.Buffer)))
+	assert.Equal(t, []replayRequest{{path: "/v1/logs", contentType: "application/json", body: `{"l":1}`}}, requests())
+
+	// files given explicitly are replayed even if partial
+	require.NoError(t, runReplay([]string{"--endpoint", server.URL, partial}, new(bytes.Buffer)))
+	assert.Len(t, requests(), 2)
+}
+
+func TestRunReplayErrors(t *testing.T) {
+	server, _ := newReplayServer(t, http.StatusServiceUnavailable)
+	dir := t.TempDir()
+	archived := writeArchiveFile(t, dir, "metrics-20220701T100000.000000000Z.json", "{\"m\":1}\n")
+	unrelated := writeArchiveFile(t, dir, "unrelated.txt", "unrelated")
+
+	err := runReplay(nil, new(bytes.Buffer))
+	assert.EqualError(t, err, "the replay command requires archive files or directories")
+
+	err = runReplay([]string{"--unknown", dir}, new(bytes.Buffer))
+	assert.Error(t, err)
+
+	err = runReplay([]string{filepath.Join(dir, "missing")}, new(bytes.Buffer))
+	assert.Error(t, err)
+
+	err = runReplay([]string{unrelated}, new(bytes.Buffer))
+	assert.EqualError(t, err, unrelated+" isn't an otlparchive exporter file")
+
+	err = runReplay([]string{"--endpoint", server.URL, archived}, new(bytes.Buffer))
+	assert.EqualError(t, err, "failed replaying "+archived+": "+server.URL+"/v1/metrics responded with 503 Service Unavailable")
+}
@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"time"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+const (
+	// Service-specific exit codes reported to the Service Control Manager so that configured recovery
+	// actions can be taken and failures can be distinguished in the System event log.
+	exitCodeStartFailure   uint32 = 1
+	exitCodeRuntimeFailure uint32 = 2
+
+	defaultStartPendingInterval = time.Second
+	startPendingWaitHint        = 5 * time.Second
+)
+
+// statusReportingHandler wraps the collector's svc.Handler to report StartPending progress while
+// pipelines are starting and to return service-specific exit codes on failure.
+type statusReportingHandler struct {
+	handler         svc.Handler
+	pendingInterval time.Duration
+}
+
+func newStatusReportingHandler(handler svc.Handler) *statusReportingHandler {
+	return &statusReportingHandler{handler: handler, pendingInterval: defaultStartPendingInterval}
+}
+
+func (h *statusReportingHandler) Execute(args []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	statuses := make(chan svc.Status)
+	running := make(chan bool, 1)
+	forwarded := make(chan struct{})
+	go func() {
+		defer close(forwarded)
+		running <- h.forwardStatuses(statuses, changes)
+	}()
+
+	ssec, errno := h.handler.Execute(args, requests, statuses)
+	close(statuses)
+	<-forwarded
+
+	if ssec || errno == 0 {
+		return ssec, errno
+	}
+	if <-running {
+		return true, exitCodeRuntimeFailure
+	}
+	return true, exitCodeStartFailure
+}
+
+// forwardStatuses relays statuses to the Service Control Manager, periodically reporting StartPending
+// progress until another state is reported. It returns whether the Running state was reached.
+func (h *statusReportingHandler) forwardStatuses(statuses <-chan svc.Status, changes chan<- svc.Status) bool {
+	ticker := time.NewTicker(h.pendingInterval)
+	defer ticker.Stop()
+
+	var current svc.Status
+	var wasRunning bool
+	for {
+		select {
+		case status, ok := <-statuses:
+			if !ok {
+				return wasRunning
+			}
+			if status.State == svc.StartPending && status.WaitHint == 0 {
+				status.WaitHint = uint32(startPendingWaitHint / time.Millisecond)
+			}
+			wasRunning = wasRunning || status.State == svc.Running
+			current = status
+			changes <- status
+		case <-ticker.C:
+			if current.State != svc.StartPending {
+				continue
+			}
+			current.CheckPoint++
+			changes <- current
+		}
+	}
+}
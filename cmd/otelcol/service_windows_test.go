@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/sys/windows/svc"
+)
+
+type fakeHandler struct {
+	execute func(changes chan<- svc.Status) (bool, uint32)
+}
+
+func (h fakeHandler) Execute(_ []string, _ <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	return h.execute(changes)
+}
+
+func executeHandler(t *testing.T, wrapped fakeHandler) ([]svc.Status, bool, uint32) {
+	handler := newStatusReportingHandler(wrapped)
+	handler.pendingInterval = 10 * time.Millisecond
+
+	changes := make(chan svc.Status, 1000)
+	ssec, errno := handler.Execute(nil, make(chan svc.ChangeRequest), changes)
+	close(changes)
+
+	var statuses []svc.Status
+	for status := range changes {
+		statuses = append(statuses, status)
+	}
+	return statuses, ssec, errno
+}
+
+func TestStatusReportingHandlerReportsStartProgress(t *testing.T) {
+	statuses, ssec, errno := executeHandler(t, fakeHandler{execute: func(changes chan<- svc.Status) (bool, uint32) {
+		changes <- svc.Status{State: svc.StartPending}
+		time.Sleep(100 * time.Millisecond)
+		changes <- svc.Status{State: svc.Running}
+		changes <- svc.Status{State: svc.StopPending}
+		return false, 0
+	}})
+	assert.False(t, ssec)
+	assert.Zero(t, errno)
+
+	assert.Equal(t, svc.StopPending, statuses[len(statuses)-1].State)
+	assert.Equal(t, svc.Running, statuses[len(statuses)-2].State)
+
+	pending := statuses[:len(statuses)-2]
+	assert.Greater(t, len(pending), 1)
+	for i, status := range pending {
+		assert.Equal(t, svc.StartPending, status.State)
+		assert.Equal(t, uint32(i), status.CheckPoint)
+		assert.Equal(t, uint32(5000), status.WaitHint)
+	}
+}
+
+func TestStatusReportingHandlerExitCodes(t *testing.T) {
+	_, ssec, errno := executeHandler(t, fakeHandler{execute: func(changes chan<- svc.Status) (bool, uint32) {
+		changes <- svc.Status{State: svc.StartPending}
+		return false, 1064
+	}})
+	assert.True(t, ssec)
+	assert.Equal(t, exitCodeStartFailure, errno)
+
+	_, ssec, errno = executeHandler(t, fakeHandler{execute: func(changes chan<- svc.Status) (bool, uint32) {
+		changes <- svc.Status{State: svc.StartPending}
+		changes <- svc.Status{State: svc.Running}
+		return false, 1064
+	}})
+	assert.True(t, ssec)
+	assert.Equal(t, exitCodeRuntimeFailure, errno)
+
+	_, ssec, errno = executeHandler(t, fakeHandler{execute: func(changes chan<- svc.Status) (bool, uint32) {
+		return true, 42
+	}})
+	assert.True(t, ssec)
+	assert.Equal(t, uint32(42), errno)
+}
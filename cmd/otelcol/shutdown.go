@@ -0,0 +1,317 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+// shutdownTimeout returns the SPLUNK_SHUTDOWN_TIMEOUT duration, if set.
+func shutdownTimeout() (time.Duration, bool, error) {
+	value := os.Getenv(shutdownTimeoutEnvVarName)
+	if value == "" {
+		return 0, false, nil
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout <= 0 {
+		return 0, false, fmt.Errorf("expected a positive duration like 30s in %s env variable but got %q", shutdownTimeoutEnvVarName, value)
+	}
+	return timeout, true, nil
+}
+
+// shutdownTracker bounds the time the collector takes to shut down once asked to stop by a
+// signal. When the timeout expires, it logs the components still shutting down, which exceeded
+// their budget, and exits the process, so that components stuck in shutdown, like Smart Agent
+// monitors waiting on subprocesses, don't hang the termination until the service manager kills
+// the collector. Shutdowns for config reloads aren't bounded.
+type shutdownTracker struct {
+	now  func() time.Time
+	exit func(code int)
+	// pending are the start times of the shutdowns in progress by component
+	pending  map[string]time.Time
+	deadline *time.Timer
+	timeout  time.Duration
+	lock     sync.Mutex
+}
+
+func newShutdownTracker(timeout time.Duration) *shutdownTracker {
+	return &shutdownTracker{
+		timeout: timeout,
+		pending: map[string]time.Time{},
+		now:     time.Now,
+		exit:    os.Exit,
+	}
+}
+
+// watchSignals starts the shutdown deadline when the collector is asked to stop, in addition
+// to the collector's own signal handling.
+func (t *shutdownTracker) watchSignals() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		t.startDeadline()
+	}()
+}
+
+func (t *shutdownTracker) startDeadline() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.deadline == nil {
+		t.deadline = time.AfterFunc(t.timeout, t.expire)
+	}
+}
+
+// track runs the shutdown of the component, recording it as pending until it returns.
+func (t *shutdownTracker) track(ctx context.Context, name string, shutdown func(context.Context) error) error {
+	t.lock.Lock()
+	t.pending[name] = t.now()
+	t.lock.Unlock()
+
+	defer func() {
+		t.lock.Lock()
+		delete(t.pending, name)
+		t.lock.Unlock()
+	}()
+	return shutdown(ctx)
+}
+
+// expire logs the components still shutting down and exits. The exit is successful since the
+// collector was asked to stop, and what remains of its shutdown is abandoned.
+func (t *shutdownTracker) expire() {
+	t.lock.Lock()
+	now := t.now()
+	var stuck []string
+	for name, start := range t.pending {
+		stuck = append(stuck, fmt.Sprintf("%s (%s)", name, now.Sub(start).Round(time.Millisecond)))
+	}
+	t.lock.Unlock()
+	sort.Strings(stuck)
+
+	if len(stuck) == 0 {
+		// the remaining shutdown is of the untracked extensions or the service itself
+		stuck = []string{"none, the extensions or the service are still shutting down"}
+	}
+	log.Printf("Shutdown didn't complete within the %s of %s, forcing exit. Components exceeding their shutdown budget: %s",
+		t.timeout, shutdownTimeoutEnvVarName, strings.Join(stuck, ", "))
+	t.exit(0)
+}
+
+// trackShutdowns wraps the receiver, processor, and exporter factories so that the shutdowns
+// of the components they create are tracked. Extensions aren't wrapped, since components
+// depend on the interfaces of the extensions they use, but their shutdown is still bounded.
+func trackShutdowns(factories component.Factories, tracker *shutdownTracker) component.Factories {
+	receivers := make(map[config.Type]component.ReceiverFactory, len(factories.Receivers))
+	for typ, factory := range factories.Receivers {
+		receivers[typ] = trackedReceiverFactory{ReceiverFactory: factory, tracker: tracker}
+	}
+	processors := make(map[config.Type]component.ProcessorFactory, len(factories.Processors))
+	for typ, factory := range factories.Processors {
+		processors[typ] = trackedProcessorFactory{ProcessorFactory: factory, tracker: tracker}
+	}
+	exporters := make(map[config.Type]component.ExporterFactory, len(factories.Exporters))
+	for typ, factory := range factories.Exporters {
+		exporters[typ] = trackedExporterFactory{ExporterFactory: factory, tracker: tracker}
+	}
+	factories.Receivers = receivers
+	factories.Processors = processors
+	factories.Exporters = exporters
+	return factories
+}
+
+// shutdownTracking identifies a component to its tracker.
+type shutdownTracking struct {
+	tracker *shutdownTracker
+	name    string
+}
+
+func newShutdownTracking(tracker *shutdownTracker, kind string, id config.ComponentID) shutdownTracking {
+	return shutdownTracking{tracker: tracker, name: kind + " " + id.String()}
+}
+
+type trackedReceiverFactory struct {
+	component.ReceiverFactory
+	tracker *shutdownTracker
+}
+
+func (f trackedReceiverFactory) CreateTracesReceiver(ctx context.Context, set component.ReceiverCreateSettings, cfg config.Receiver, nextConsumer consumer.Traces) (component.TracesReceiver, error) {
+	receiver, err := f.ReceiverFactory.CreateTracesReceiver(ctx, set, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return trackedReceiver{Receiver: receiver, shutdownTracking: newShutdownTracking(f.tracker, "receiver", cfg.ID())}, nil
+}
+
+func (f trackedReceiverFactory) CreateMetricsReceiver(ctx context.Context, set component.ReceiverCreateSettings, cfg config.Receiver, nextConsumer consumer.Metrics) (component.MetricsReceiver, error) {
+	receiver, err := f.ReceiverFactory.CreateMetricsReceiver(ctx, set, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return trackedReceiver{Receiver: receiver, shutdownTracking: newShutdownTracking(f.tracker, "receiver", cfg.ID())}, nil
+}
+
+func (f trackedReceiverFactory) CreateLogsReceiver(ctx context.Context, set component.ReceiverCreateSettings, cfg config.Receiver, nextConsumer consumer.Logs) (component.LogsReceiver, error) {
+	receiver, err := f.ReceiverFactory.CreateLogsReceiver(ctx, set, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return trackedReceiver{Receiver: receiver, shutdownTracking: newShutdownTracking(f.tracker, "receiver", cfg.ID())}, nil
+}
+
+type trackedReceiver struct {
+	component.Receiver
+	shutdownTracking
+}
+
+func (r trackedReceiver) Shutdown(ctx context.Context) error {
+	return r.tracker.track(ctx, r.name, r.Receiver.Shutdown)
+}
+
+type trackedProcessorFactory struct {
+	component.ProcessorFactory
+	tracker *shutdownTracker
+}
+
+func (f trackedProcessorFactory) CreateTracesProcessor(ctx context.Context, set component.ProcessorCreateSettings, cfg config.Processor, nextConsumer consumer.Traces) (component.TracesProcessor, error) {
+	processor, err := f.ProcessorFactory.CreateTracesProcessor(ctx, set, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return trackedTracesProcessor{TracesProcessor: processor, shutdownTracking: newShutdownTracking(f.tracker, "processor", cfg.ID())}, nil
+}
+
+func (f trackedProcessorFactory) CreateMetricsProcessor(ctx context.Context, set component.ProcessorCreateSettings, cfg config.Processor, nextConsumer consumer.Metrics) (component.MetricsProcessor, error) {
+	processor, err := f.ProcessorFactory.CreateMetricsProcessor(ctx, set, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return trackedMetricsProcessor{MetricsProcessor: processor, shutdownTracking: newShutdownTracking(f.tracker, "processor", cfg.ID())}, nil
+}
+
+func (f trackedProcessorFactory) CreateLogsProcessor(ctx context.Context, set component.ProcessorCreateSettings, cfg config.Processor, nextConsumer consumer.Logs) (component.LogsProcessor, error) {
+	processor, err := f.ProcessorFactory.CreateLogsProcessor(ctx, set, cfg, nextConsumer)
+	if err != nil {
+		return nil, err
+	}
+	return trackedLogsProcessor{LogsProcessor: processor, shutdownTracking: newShutdownTracking(f.tracker, "processor", cfg.ID())}, nil
+}
+
+type trackedTracesProcessor struct {
+	component.TracesProcessor
+	shutdownTracking
+}
+
+func (p trackedTracesProcessor) Shutdown(ctx context.Context) error {
+	return p.tracker.track(ctx, p.name, p.TracesProcessor.Shutdown)
+}
+
+type trackedMetricsProcessor struct {
+	component.MetricsProcessor
+	shutdownTracking
+}
+
+func (p trackedMetricsProcessor) Shutdown(ctx context.Context) error {
+	return p.tracker.track(ctx, p.name, p.MetricsProcessor.Shutdown)
+}
+
+type trackedLogsProcessor struct {
+	component.LogsProcessor
+	shutdownTracking
+}
+
+func (p trackedLogsProcessor) Shutdown(ctx context.Context) error {
+	return p.tracker.track(ctx, p.name, p.LogsProcessor.Shutdown)
+}
+
+type trackedExporterFactory struct {
+	component.ExporterFactory
+	tracker *shutdownTracker
+}
+
+func (f trackedExporterFactory) CreateTracesExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+	exporter, err := f.ExporterFactory.CreateTracesExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return trackedTracesExporter{TracesExporter: exporter, shutdownTracking: newShutdownTracking(f.tracker, "exporter", cfg.ID())}, nil
+}
+
+func (f trackedExporterFactory) CreateMetricsExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.MetricsExporter, error) {
+	exporter, err := f.ExporterFactory.CreateMetricsExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	tracked := trackedMetricsExporter{MetricsExporter: exporter, shutdownTracking: newShutdownTracking(f.tracker, "exporter", cfg.ID())}
+	if metadataExporter, ok := exporter.(metadata.MetadataExporter); ok {
+		return trackedMetadataMetricsExporter{trackedMetricsExporter: tracked, MetadataExporter: metadataExporter}, nil
+	}
+	return tracked, nil
+}
+
+func (f trackedExporterFactory) CreateLogsExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.LogsExporter, error) {
+	exporter, err := f.ExporterFactory.CreateLogsExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return trackedLogsExporter{LogsExporter: exporter, shutdownTracking: newShutdownTracking(f.tracker, "exporter", cfg.ID())}, nil
+}
+
+type trackedTracesExporter struct {
+	component.TracesExporter
+	shutdownTracking
+}
+
+func (e trackedTracesExporter) Shutdown(ctx context.Context) error {
+	return e.tracker.track(ctx, e.name, e.TracesExporter.Shutdown)
+}
+
+type trackedMetricsExporter struct {
+	component.MetricsExporter
+	shutdownTracking
+}
+
+func (e trackedMetricsExporter) Shutdown(ctx context.Context) error {
+	return e.tracker.track(ctx, e.name, e.MetricsExporter.Shutdown)
+}
+
+// trackedMetadataMetricsExporter keeps exporting the metadata of metrics exporters like signalfx, which
+// receivers find by their metadata.MetadataExporter interface.
+type trackedMetadataMetricsExporter struct {
+	trackedMetricsExporter
+	metadata.MetadataExporter
+}
+
+type trackedLogsExporter struct {
+	component.LogsExporter
+	shutdownTracking
+}
+
+func (e trackedLogsExporter) Shutdown(ctx context.Context) error {
+	return e.tracker.track(ctx, e.name, e.LogsExporter.Shutdown)
+}
@@ -0,0 +1,184 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestShutdownTimeout(t *testing.T) {
+	_, ok, err := shutdownTimeout()
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	t.Setenv(shutdownTimeoutEnvVarName, "45s")
+	timeout, ok, err := shutdownTimeout()
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, 45*time.Second, timeout)
+
+	for _, invalid := range []string{"45", "-1s", "0s"} {
+		t.Setenv(shutdownTimeoutEnvVarName, invalid)
+		_, _, err = shutdownTimeout()
+		require.EqualError(t, err, `expected a positive duration like 30s in SPLUNK_SHUTDOWN_TIMEOUT env variable but got "`+invalid+`"`)
+	}
+}
+
+func TestShutdownTrackerExpires(t *testing.T) {
+	oldWriter := log.Default().Writer()
+	defer log.Default().SetOutput(oldWriter)
+	logs := new(bytes.Buffer)
+	log.Default().SetOutput(logs)
+
+	tracker := newShutdownTracker(50 * time.Millisecond)
+	exited := make(chan int, 1)
+	tracker.exit = func(code int) { exited <- code }
+
+	stuck := make(chan struct{})
+	defer close(stuck)
+	go func() {
+		_ = tracker.track(context.Background(), "receiver smartagent/collectd", func(context.Context) error {
+			<-stuck
+			return nil
+		})
+	}()
+	require.NoError(t, tracker.track(context.Background(), "exporter signalfx", func(context.Context) error { return nil }))
+
+	require.Eventually(t, func() bool {
+		tracker.lock.Lock()
+		defer tracker.lock.Unlock()
+		return len(tracker.pending) == 1
+	}, 5*time.Second, time.Millisecond)
+	tracker.startDeadline()
+
+	select {
+	case code := <-exited:
+		assert.Equal(t, 0, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown deadline didn't expire")
+	}
+	assert.Contains(t, logs.String(), "Shutdown didn't complete within the 50ms of SPLUNK_SHUTDOWN_TIMEOUT, forcing exit. Components exceeding their shutdown budget: receiver smartagent/collectd (")
+	assert.NotContains(t, logs.String(), "exporter signalfx")
+}
+
+func TestShutdownTrackerExpiresWithoutPendingComponents(t *testing.T) {
+	oldWriter := log.Default().Writer()
+	defer log.Default().SetOutput(oldWriter)
+	logs := new(bytes.Buffer)
+	log.Default().SetOutput(logs)
+
+	tracker := newShutdownTracker(time.Millisecond)
+	exited := make(chan int, 1)
+	tracker.exit = func(code int) { exited <- code }
+	tracker.startDeadline()
+	// the deadline starts once, even when signaled again
+	tracker.startDeadline()
+
+	select {
+	case code := <-exited:
+		assert.Equal(t, 0, code)
+	case <-time.After(5 * time.Second):
+		t.Fatal("shutdown deadline didn't expire")
+	}
+	assert.Contains(t, logs.String(), "Components exceeding their shutdown budget: none, the extensions or the service are still shutting down")
+	select {
+	case <-exited:
+		t.Fatal("shutdown deadline expired twice")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestShutdownTrackerWithoutDeadline(t *testing.T) {
+	tracker := newShutdownTracker(time.Millisecond)
+	tracker.exit = func(code int) { t.Errorf("unexpected exit with code %d", code) }
+
+	// shutdowns that aren't requested by signals, like those of config reloads, aren't bounded
+	require.NoError(t, tracker.track(context.Background(), "receiver smartagent/collectd", func(context.Context) error {
+		time.Sleep(10 * time.Millisecond)
+		return nil
+	}))
+	assert.Empty(t, tracker.pending)
+	assert.Nil(t, tracker.deadline)
+}
+
+func TestTrackShutdowns(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	tracker := newShutdownTracker(time.Minute)
+	tracked := trackShutdowns(factories, tracker)
+
+	nop := config.Type("nop")
+	assert.Equal(t, factories.Extensions, tracked.Extensions)
+	require.Contains(t, tracked.Receivers, nop)
+	require.Contains(t, tracked.Processors, nop)
+	require.Contains(t, tracked.Exporters, nop)
+	assert.Equal(t, nop, tracked.Receivers[nop].Type())
+
+	ctx := context.Background()
+	receiver, err := tracked.Receivers[nop].CreateMetricsReceiver(ctx, componenttest.NewNopReceiverCreateSettings(),
+		tracked.Receivers[nop].CreateDefaultConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "receiver nop", receiver.(trackedReceiver).name)
+
+	processor, err := tracked.Processors[nop].CreateLogsProcessor(ctx, componenttest.NewNopProcessorCreateSettings(),
+		tracked.Processors[nop].CreateDefaultConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Equal(t, "processor nop", processor.(trackedLogsProcessor).name)
+
+	exporter, err := tracked.Exporters[nop].CreateTracesExporter(ctx, componenttest.NewNopExporterCreateSettings(),
+		tracked.Exporters[nop].CreateDefaultConfig())
+	require.NoError(t, err)
+	assert.Equal(t, "exporter nop", exporter.(trackedTracesExporter).name)
+
+	// the wrapped components remain usable by their pipelines
+	require.NoError(t, exporter.ConsumeTraces(ctx, ptrace.NewTraces()))
+
+	for _, c := range []component.Component{receiver, processor, exporter} {
+		require.NoError(t, c.Start(ctx, componenttest.NewNopHost()))
+		require.NoError(t, c.Shutdown(ctx))
+	}
+	assert.Empty(t, tracker.pending)
+}
+
+func TestTrackShutdownsKeepsMetadataExporters(t *testing.T) {
+	factories := trackShutdowns(component.Factories{
+		Exporters: map[config.Type]component.ExporterFactory{"signalfx": signalfxexporter.NewFactory()},
+	}, newShutdownTracker(time.Minute))
+	factory := factories.Exporters["signalfx"]
+	cfg := factory.CreateDefaultConfig().(*signalfxexporter.Config)
+	cfg.AccessToken = "token"
+	cfg.Realm = "us0"
+
+	exporter, err := factory.CreateMetricsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	assert.Equal(t, "exporter signalfx", exporter.(trackedMetadataMetricsExporter).name)
+	// receivers like k8s_cluster and smartagent send their metadata updates through this interface
+	_, ok := exporter.(metadata.MetadataExporter)
+	assert.True(t, ok)
+}
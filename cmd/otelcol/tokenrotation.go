@@ -0,0 +1,531 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+
+	"github.com/signalfx/splunk-otel-collector/internal/filewatcher"
+)
+
+const (
+	accessTokenFileEnvVarName          = "SPLUNK_ACCESS_TOKEN_FILE"
+	hecTokenEnvVarName                 = "SPLUNK_HEC_TOKEN"
+	hecTokenFileEnvVarName             = "SPLUNK_HEC_TOKEN_FILE"
+	tokenRotationGracePeriodEnvVarName = "SPLUNK_TOKEN_ROTATION_GRACE_PERIOD"
+	defaultTokenRotationGracePeriod    = 5 * time.Minute
+	// tokenFileDebounce is how long to wait for the bursts of events of a token file being replaced to settle
+	// before reading it.
+	tokenFileDebounce = time.Second
+)
+
+// exporterTokenOptions are the config options of the exporters' tokens, like the signalfx and sapm exporters'
+// access_token and the splunk_hec exporter's token.
+var exporterTokenOptions = map[string]bool{
+	"access_token": true,
+	"token":        true,
+}
+
+// rotatedToken is a token read from a file, set as the env variables referenced by the configs.
+type rotatedToken struct {
+	file        string
+	value       string
+	envVarNames []string
+	failed      bool
+}
+
+// tokenRotator applies the changes of the SPLUNK_ACCESS_TOKEN_FILE and SPLUNK_HEC_TOKEN_FILE tokens to the exporters
+// using them without restarting the collector. The exporters whose token option has the value of a rotated token
+// when they're created are wrapped so that, when the token file changes, an exporter with the new token takes
+// over their data, while the one with the previous token keeps sending its queued data and retries for the grace
+// period before being shut down. The new token is also set as its env variables so that config reloads use it.
+type tokenRotator struct {
+	watcher     *filewatcher.Watcher
+	tokens      []*rotatedToken
+	exporters   map[*rotatingExporter]struct{}
+	gracePeriod time.Duration
+	lock        sync.Mutex
+}
+
+// tokenRotationGracePeriod returns the SPLUNK_TOKEN_ROTATION_GRACE_PERIOD duration, if set, or the default.
+func tokenRotationGracePeriod() (time.Duration, error) {
+	value := os.Getenv(tokenRotationGracePeriodEnvVarName)
+	if value == "" {
+		return defaultTokenRotationGracePeriod, nil
+	}
+	gracePeriod, err := time.ParseDuration(value)
+	if err != nil || gracePeriod < 0 {
+		return 0, fmt.Errorf("expected a non-negative duration like 5m in %s env variable but got %q", tokenRotationGracePeriodEnvVarName, value)
+	}
+	return gracePeriod, nil
+}
+
+// newTokenRotator reads the token files and sets their tokens as the SPLUNK_ACCESS_TOKEN and SPLUNK_HEC_TOKEN env
+// variables, taking precedence over their own values. Without SPLUNK_HEC_TOKEN_FILE, the HEC token defaults to
+// the rotated access token, like it does to SPLUNK_ACCESS_TOKEN. It returns nil if no token file is set.
+func newTokenRotator() (*tokenRotator, error) {
+	accessTokenFile := os.Getenv(accessTokenFileEnvVarName)
+	hecTokenFile := os.Getenv(hecTokenFileEnvVarName)
+	if accessTokenFile == "" && hecTokenFile == "" {
+		return nil, nil
+	}
+	gracePeriod, err := tokenRotationGracePeriod()
+	if err != nil {
+		return nil, err
+	}
+
+	rotator := &tokenRotator{exporters: map[*rotatingExporter]struct{}{}, gracePeriod: gracePeriod}
+	if accessTokenFile != "" {
+		token := &rotatedToken{file: accessTokenFile, envVarNames: []string{tokenEnvVarName}}
+		if _, ok := os.LookupEnv(hecTokenEnvVarName); !ok && hecTokenFile == "" {
+			token.envVarNames = append(token.envVarNames, hecTokenEnvVarName)
+		}
+		rotator.tokens = append(rotator.tokens, token)
+	}
+	if hecTokenFile != "" {
+		rotator.tokens = append(rotator.tokens, &rotatedToken{file: hecTokenFile, envVarNames: []string{hecTokenEnvVarName}})
+	}
+
+	for _, token := range rotator.tokens {
+		if token.value, err = readTokenFile(token.file); err != nil {
+			return nil, fmt.Errorf("failed reading the %s token file: %w", token.envVarNames[0], err)
+		}
+		for _, envVarName := range token.envVarNames {
+			_ = os.Setenv(envVarName, token.value)
+		}
+		log.Printf("Set %s from %s, whose changes are applied to the exporters using it", strings.Join(token.envVarNames, " and "), token.file)
+	}
+	return rotator, nil
+}
+
+// readTokenFile returns the token of the file, without surrounding whitespace like trailing newlines.
+func readTokenFile(file string) (string, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return "", err
+	}
+	token := strings.TrimSpace(string(content))
+	if token == "" {
+		return "", fmt.Errorf("%s is empty", file)
+	}
+	return token, nil
+}
+
+// start checks the token files whenever their content changes, until stopped.
+func (r *tokenRotator) start() error {
+	watcher, err := filewatcher.New(tokenFileDebounce, func([]string) { r.check() }, func(err error) {
+		log.Printf("Warning: error watching the token files: %v", err)
+	})
+	if err != nil {
+		return err
+	}
+	for _, token := range r.tokens {
+		if err = watcher.Add(token.file); err != nil {
+			_ = watcher.Close()
+			return fmt.Errorf("failed watching the %s token file: %w", token.envVarNames[0], err)
+		}
+	}
+	r.watcher = watcher
+	return nil
+}
+
+// stop stops checking the token files, returning once no check is running, so that the tokens and their env
+// variables are no longer changed.
+func (r *tokenRotator) stop() {
+	if r.watcher != nil {
+		_ = r.watcher.Close()
+	}
+}
+
+// check rotates the tokens whose file changed. Token files that can't be read, like while being replaced, are
+// checked again at their next change, keeping their previous token.
+func (r *tokenRotator) check() {
+	for _, token := range r.tokens {
+		value, err := readTokenFile(token.file)
+		if err != nil {
+			if !token.failed {
+				log.Printf("Warning: failed reading the %s token file, keeping the current token: %v", token.envVarNames[0], err)
+			}
+			token.failed = true
+			continue
+		}
+		token.failed = false
+
+		r.lock.Lock()
+		if value == token.value {
+			r.lock.Unlock()
+			continue
+		}
+		token.value = value
+		var exporters []*rotatingExporter
+		for exporter := range r.exporters {
+			if exporter.token == token {
+				exporters = append(exporters, exporter)
+			}
+		}
+		r.lock.Unlock()
+
+		for _, envVarName := range token.envVarNames {
+			_ = os.Setenv(envVarName, value)
+		}
+		log.Printf("Rotating the %s token of %d exporters from %s, with a grace period of %s for the previous token",
+			token.envVarNames[0], len(exporters), token.file, r.gracePeriod)
+		for _, exporter := range exporters {
+			exporter.rotate(value, r.gracePeriod)
+		}
+	}
+}
+
+// rotatedTokenOf returns the rotated token of the exporter config and the name of its token field, if any.
+func (r *tokenRotator) rotatedTokenOf(cfg config.Exporter) (*rotatedToken, string) {
+	field := exporterTokenField(cfg)
+	if field == "" {
+		return nil, ""
+	}
+	value := reflect.ValueOf(cfg).Elem().FieldByName(field).String()
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	for _, token := range r.tokens {
+		if token.value == value {
+			return token, field
+		}
+	}
+	return nil, ""
+}
+
+func (r *tokenRotator) add(exporter *rotatingExporter) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	r.exporters[exporter] = struct{}{}
+}
+
+func (r *tokenRotator) remove(exporter *rotatingExporter) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	delete(r.exporters, exporter)
+}
+
+// exporterTokenField returns the name of the string field of the exporter config struct whose option is a token,
+// if any.
+func exporterTokenField(cfg config.Exporter) string {
+	value := reflect.ValueOf(cfg)
+	if value.Kind() != reflect.Pointer || value.Elem().Kind() != reflect.Struct {
+		return ""
+	}
+	cfgType := value.Elem().Type()
+	for i := 0; i < cfgType.NumField(); i++ {
+		field := cfgType.Field(i)
+		option, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if exporterTokenOptions[option] && field.IsExported() && field.Type.Kind() == reflect.String {
+			return field.Name
+		}
+	}
+	return ""
+}
+
+// hasPersistentQueue returns whether the sending queue of the exporter config is persisted by a storage extension.
+func hasPersistentQueue(cfg config.Exporter) bool {
+	queue, ok := optionValue(reflect.ValueOf(cfg).Elem(), "sending_queue")
+	if !ok || queue.Kind() != reflect.Struct {
+		return false
+	}
+	if storage, ok := optionValue(queue, "storage"); ok && storage.Kind() == reflect.Pointer && !storage.IsNil() {
+		return true
+	}
+	enabled, ok := optionValue(queue, "persistent_storage_enabled")
+	return ok && enabled.Kind() == reflect.Bool && enabled.Bool()
+}
+
+// optionValue returns the value of the exported field of the struct whose option is the provided one, if any.
+func optionValue(value reflect.Value, option string) (reflect.Value, bool) {
+	for i := 0; i < value.NumField(); i++ {
+		field := value.Type().Field(i)
+		name, _, _ := strings.Cut(field.Tag.Get("mapstructure"), ",")
+		if name == option && field.IsExported() {
+			return value.Field(i), true
+		}
+	}
+	return reflect.Value{}, false
+}
+
+// withToken returns a copy of the exporter config with the token.
+func withToken(cfg config.Exporter, field, token string) config.Exporter {
+	value := reflect.ValueOf(cfg).Elem()
+	copied := reflect.New(value.Type())
+	copied.Elem().Set(value)
+	copied.Elem().FieldByName(field).SetString(token)
+	return copied.Interface().(config.Exporter)
+}
+
+// rotateTokens wraps the exporter factories so that the exporters using the rotated tokens are rotated with them.
+func rotateTokens(factories component.Factories, rotator *tokenRotator) component.Factories {
+	exporters := make(map[config.Type]component.ExporterFactory, len(factories.Exporters))
+	for typ, factory := range factories.Exporters {
+		exporters[typ] = rotatingExporterFactory{ExporterFactory: factory, rotator: rotator}
+	}
+	factories.Exporters = exporters
+	return factories
+}
+
+type rotatingExporterFactory struct {
+	component.ExporterFactory
+	rotator *tokenRotator
+}
+
+func (f rotatingExporterFactory) CreateTracesExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.TracesExporter, error) {
+	exporter, err := f.ExporterFactory.CreateTracesExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	rotating, err := f.newRotatingExporter(cfg, exporter, func(ctx context.Context, cfg config.Exporter) (component.Exporter, error) {
+		return f.ExporterFactory.CreateTracesExporter(ctx, set, cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rotating == nil {
+		return exporter, nil
+	}
+	return rotatingTracesExporter{rotating}, nil
+}
+
+func (f rotatingExporterFactory) CreateMetricsExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.MetricsExporter, error) {
+	exporter, err := f.ExporterFactory.CreateMetricsExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	rotating, err := f.newRotatingExporter(cfg, exporter, func(ctx context.Context, cfg config.Exporter) (component.Exporter, error) {
+		return f.ExporterFactory.CreateMetricsExporter(ctx, set, cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rotating == nil {
+		return exporter, nil
+	}
+	if _, ok := exporter.(metadata.MetadataExporter); ok {
+		return rotatingMetadataMetricsExporter{rotatingMetricsExporter{rotating}}, nil
+	}
+	return rotatingMetricsExporter{rotating}, nil
+}
+
+func (f rotatingExporterFactory) CreateLogsExporter(ctx context.Context, set component.ExporterCreateSettings, cfg config.Exporter) (component.LogsExporter, error) {
+	exporter, err := f.ExporterFactory.CreateLogsExporter(ctx, set, cfg)
+	if err != nil {
+		return nil, err
+	}
+	rotating, err := f.newRotatingExporter(cfg, exporter, func(ctx context.Context, cfg config.Exporter) (component.Exporter, error) {
+		return f.ExporterFactory.CreateLogsExporter(ctx, set, cfg)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if rotating == nil {
+		return exporter, nil
+	}
+	return rotatingLogsExporter{rotating}, nil
+}
+
+// newRotatingExporter returns the rotating exporter of the created exporter, or nil if it doesn't use a rotated token.
+// Exporters with a persistent sending queue are rejected, since the exporter of the next token can't take over the
+// queue storage of the current one.
+func (f rotatingExporterFactory) newRotatingExporter(
+	cfg config.Exporter, exporter component.Exporter, create func(context.Context, config.Exporter) (component.Exporter, error),
+) (*rotatingExporter, error) {
+	token, field := f.rotator.rotatedTokenOf(cfg)
+	if token == nil {
+		return nil, nil
+	}
+	if hasPersistentQueue(cfg) {
+		return nil, fmt.Errorf(
+			"exporter %s can't use the %s token of %s, whose changes are applied by replacing the exporter, with a persistent sending_queue: "+
+				"remove the sending_queue storage or provide the token without a token file", cfg.ID(), token.envVarNames[0], token.file,
+		)
+	}
+	return &rotatingExporter{
+		rotator:  f.rotator,
+		token:    token,
+		create:   create,
+		cfg:      cfg,
+		field:    field,
+		current:  exporter,
+		retiring: map[component.Exporter]*time.Timer{},
+	}, nil
+}
+
+// rotatingExporter sends the data it consumes to the exporter of the current token.
+type rotatingExporter struct {
+	host    component.Host
+	current component.Exporter
+	cfg     config.Exporter
+	rotator *tokenRotator
+	token   *rotatedToken
+	create  func(context.Context, config.Exporter) (component.Exporter, error)
+	// retiring are the exporters of the previous tokens by the timers shutting them down once their grace
+	// period ends
+	retiring map[component.Exporter]*time.Timer
+	field    string
+	lock     sync.RWMutex
+}
+
+func (e *rotatingExporter) Start(ctx context.Context, host component.Host) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if err := e.current.Start(ctx, host); err != nil {
+		return err
+	}
+	e.host = host
+	e.rotator.add(e)
+	return nil
+}
+
+func (e *rotatingExporter) Shutdown(ctx context.Context) error {
+	e.rotator.remove(e)
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.host = nil
+	var errs []string
+	for exporter, timer := range e.retiring {
+		// shut down now rather than once their grace period ends
+		timer.Stop()
+		delete(e.retiring, exporter)
+		if err := exporter.Shutdown(ctx); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if err := e.current.Shutdown(ctx); err != nil {
+		errs = append(errs, err.Error())
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("%s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// rotate creates and starts the exporter of the token, which takes over from the current one. The current one is
+// shut down once the grace period ends, so that it still sends the data it has queued. If the exporter of the
+// token can't be started, the current one is kept.
+func (e *rotatingExporter) rotate(token string, gracePeriod time.Duration) {
+	e.lock.RLock()
+	cfg := withToken(e.cfg, e.field, token)
+	host := e.host
+	e.lock.RUnlock()
+
+	ctx := context.Background()
+	next, err := e.create(ctx, cfg)
+	if err == nil {
+		err = next.Start(ctx, host)
+	}
+	if err != nil {
+		log.Printf("Error: failed creating exporter %s with the rotated token, keeping the previous token: %v", cfg.ID(), err)
+		return
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if e.host == nil {
+		// shut down in the meantime
+		_ = next.Shutdown(ctx)
+		return
+	}
+	previous := e.current
+	e.current = next
+	e.cfg = cfg
+	id := cfg.ID()
+	e.retiring[previous] = time.AfterFunc(gracePeriod, func() { e.retire(id, previous) })
+}
+
+func (e *rotatingExporter) retire(id config.ComponentID, exporter component.Exporter) {
+	e.lock.Lock()
+	_, ok := e.retiring[exporter]
+	delete(e.retiring, exporter)
+	e.lock.Unlock()
+	if !ok {
+		return
+	}
+	if err := exporter.Shutdown(context.Background()); err != nil {
+		log.Printf("Warning: failed shutting down exporter %s of the previous token: %v", id, err)
+	}
+}
+
+func (e *rotatingExporter) exporter() component.Exporter {
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.current
+}
+
+type rotatingTracesExporter struct {
+	*rotatingExporter
+}
+
+func (e rotatingTracesExporter) Capabilities() consumer.Capabilities {
+	return e.exporter().(component.TracesExporter).Capabilities()
+}
+
+func (e rotatingTracesExporter) ConsumeTraces(ctx context.Context, td ptrace.Traces) error {
+	return e.exporter().(component.TracesExporter).ConsumeTraces(ctx, td)
+}
+
+type rotatingMetricsExporter struct {
+	*rotatingExporter
+}
+
+func (e rotatingMetricsExporter) Capabilities() consumer.Capabilities {
+	return e.exporter().(component.MetricsExporter).Capabilities()
+}
+
+func (e rotatingMetricsExporter) ConsumeMetrics(ctx context.Context, md pmetric.Metrics) error {
+	return e.exporter().(component.MetricsExporter).ConsumeMetrics(ctx, md)
+}
+
+// rotatingMetadataMetricsExporter also exports the metadata of metrics exporters like signalfx, which receivers
+// find by their metadata.MetadataExporter interface.
+type rotatingMetadataMetricsExporter struct {
+	rotatingMetricsExporter
+}
+
+func (e rotatingMetadataMetricsExporter) ConsumeMetadata(updates []*metadata.MetadataUpdate) error {
+	// the exporter isn't replaced while sending the updates
+	e.lock.RLock()
+	defer e.lock.RUnlock()
+	return e.current.(metadata.MetadataExporter).ConsumeMetadata(updates)
+}
+
+type rotatingLogsExporter struct {
+	*rotatingExporter
+}
+
+func (e rotatingLogsExporter) Capabilities() consumer.Capabilities {
+	return e.exporter().(component.LogsExporter).Capabilities()
+}
+
+func (e rotatingLogsExporter) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	return e.exporter().(component.LogsExporter).ConsumeLogs(ctx, ld)
+}
@@ -0,0 +1,368 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+type tokenExporterConfig struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	AccessToken             string `mapstructure:"access_token"`
+}
+
+// tokenExporter records the metrics and metadata updates it exports with its token.
+type tokenExporter struct {
+	token    string
+	metrics  int64
+	updates  int64
+	started  int64
+	shutdown int64
+}
+
+func (e *tokenExporter) Start(context.Context, component.Host) error {
+	atomic.AddInt64(&e.started, 1)
+	return nil
+}
+
+func (e *tokenExporter) Shutdown(context.Context) error {
+	atomic.AddInt64(&e.shutdown, 1)
+	return nil
+}
+
+func (e *tokenExporter) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{}
+}
+
+func (e *tokenExporter) ConsumeMetrics(context.Context, pmetric.Metrics) error {
+	atomic.AddInt64(&e.metrics, 1)
+	return nil
+}
+
+// metadataTokenExporter also exports metadata, like the signalfx exporter.
+type metadataTokenExporter struct {
+	*tokenExporter
+}
+
+func (e metadataTokenExporter) ConsumeMetadata(updates []*metadata.MetadataUpdate) error {
+	atomic.AddInt64(&e.updates, int64(len(updates)))
+	return nil
+}
+
+type tokenExporters struct {
+	created []*tokenExporter
+	lock    sync.Mutex
+	// metadata is whether the created exporters export metadata
+	metadata bool
+}
+
+func (e *tokenExporters) factory() component.ExporterFactory {
+	return component.NewExporterFactory(
+		"token",
+		func() config.Exporter {
+			return &tokenExporterConfig{ExporterSettings: config.NewExporterSettings(config.NewComponentID("token"))}
+		},
+		component.WithMetricsExporter(func(_ context.Context, _ component.ExporterCreateSettings, cfg config.Exporter) (component.MetricsExporter, error) {
+			exporter := &tokenExporter{token: cfg.(*tokenExporterConfig).AccessToken}
+			e.lock.Lock()
+			defer e.lock.Unlock()
+			e.created = append(e.created, exporter)
+			if e.metadata {
+				return metadataTokenExporter{exporter}, nil
+			}
+			return exporter, nil
+		}),
+	)
+}
+
+func (e *tokenExporters) get(i int) *tokenExporter {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.created[i]
+}
+
+func writeTokenFile(t *testing.T, file, token string) {
+	require.NoError(t, os.WriteFile(file, []byte(token+"\n"), 0600))
+}
+
+func TestTokenRotationGracePeriod(t *testing.T) {
+	gracePeriod, err := tokenRotationGracePeriod()
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Minute, gracePeriod)
+
+	t.Setenv(tokenRotationGracePeriodEnvVarName, "30s")
+	gracePeriod, err = tokenRotationGracePeriod()
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, gracePeriod)
+
+	for _, invalid := range []string{"-1s", "5"} {
+		t.Setenv(tokenRotationGracePeriodEnvVarName, invalid)
+		_, err = tokenRotationGracePeriod()
+		require.EqualError(t, err, `expected a non-negative duration like 5m in SPLUNK_TOKEN_ROTATION_GRACE_PERIOD env variable but got "`+invalid+`"`)
+	}
+}
+
+func TestNewTokenRotator(t *testing.T) {
+	rotator, err := newTokenRotator()
+	require.NoError(t, err)
+	assert.Nil(t, rotator)
+
+	dir := t.TempDir()
+	accessTokenFile := filepath.Join(dir, "access_token")
+	writeTokenFile(t, accessTokenFile, "access1")
+	t.Setenv(tokenEnvVarName, "ignored")
+	t.Setenv(hecTokenEnvVarName, "")
+	require.NoError(t, os.Unsetenv(hecTokenEnvVarName))
+	t.Setenv(accessTokenFileEnvVarName, accessTokenFile)
+
+	// the HEC token defaults to the access token
+	rotator, err = newTokenRotator()
+	require.NoError(t, err)
+	require.NotNil(t, rotator)
+	require.Len(t, rotator.tokens, 1)
+	assert.Equal(t, &rotatedToken{file: accessTokenFile, value: "access1", envVarNames: []string{tokenEnvVarName, hecTokenEnvVarName}}, rotator.tokens[0])
+	assert.Equal(t, "access1", os.Getenv(tokenEnvVarName))
+	assert.Equal(t, "access1", os.Getenv(hecTokenEnvVarName))
+
+	hecTokenFile := filepath.Join(dir, "hec_token")
+	writeTokenFile(t, hecTokenFile, "hec1")
+	t.Setenv(hecTokenFileEnvVarName, hecTokenFile)
+	rotator, err = newTokenRotator()
+	require.NoError(t, err)
+	require.Len(t, rotator.tokens, 2)
+	assert.Equal(t, []string{tokenEnvVarName}, rotator.tokens[0].envVarNames)
+	assert.Equal(t, &rotatedToken{file: hecTokenFile, value: "hec1", envVarNames: []string{hecTokenEnvVarName}}, rotator.tokens[1])
+	assert.Equal(t, "hec1", os.Getenv(hecTokenEnvVarName))
+
+	writeTokenFile(t, hecTokenFile, " ")
+	_, err = newTokenRotator()
+	require.EqualError(t, err, "failed reading the SPLUNK_HEC_TOKEN token file: "+hecTokenFile+" is empty")
+
+	t.Setenv(hecTokenFileEnvVarName, filepath.Join(dir, "missing"))
+	_, err = newTokenRotator()
+	require.ErrorContains(t, err, "failed reading the SPLUNK_HEC_TOKEN token file: open "+filepath.Join(dir, "missing"))
+}
+
+func TestRotateTokens(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access_token")
+	writeTokenFile(t, file, "token1")
+	t.Setenv(tokenEnvVarName, "token1")
+	rotator := &tokenRotator{
+		tokens:      []*rotatedToken{{file: file, value: "token1", envVarNames: []string{tokenEnvVarName}}},
+		exporters:   map[*rotatingExporter]struct{}{},
+		gracePeriod: time.Hour,
+	}
+	exporters := &tokenExporters{}
+	factories := rotateTokens(component.Factories{
+		Exporters: map[config.Type]component.ExporterFactory{"token": exporters.factory()},
+	}, rotator)
+	factory := factories.Exporters["token"]
+
+	ctx := context.Background()
+	cfg := factory.CreateDefaultConfig().(*tokenExporterConfig)
+	cfg.AccessToken = "other"
+	exporter, err := factory.CreateMetricsExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	// exporters not using a rotated token aren't wrapped
+	assert.Equal(t, exporters.get(0), exporter)
+
+	cfg = factory.CreateDefaultConfig().(*tokenExporterConfig)
+	cfg.AccessToken = "token1"
+	exporter, err = factory.CreateMetricsExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.IsType(t, rotatingMetricsExporter{}, exporter)
+	require.NoError(t, exporter.Start(ctx, componenttest.NewNopHost()))
+	require.NoError(t, exporter.ConsumeMetrics(ctx, pmetric.NewMetrics()))
+	first := exporters.get(1)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&first.metrics))
+
+	// unchanged and unreadable files don't rotate the token
+	rotator.check()
+	require.NoError(t, os.Remove(file))
+	rotator.check()
+	assert.Len(t, exporters.created, 2)
+
+	writeTokenFile(t, file, "token2")
+	rotator.check()
+	require.Len(t, exporters.created, 3)
+	second := exporters.get(2)
+	assert.Equal(t, "token2", second.token)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&second.started))
+	assert.Equal(t, "token2", os.Getenv(tokenEnvVarName))
+	assert.Equal(t, "token1", cfg.AccessToken)
+
+	require.NoError(t, exporter.ConsumeMetrics(ctx, pmetric.NewMetrics()))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&first.metrics))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&second.metrics))
+	// the exporter of the previous token is kept for the grace period
+	assert.Zero(t, atomic.LoadInt64(&first.shutdown))
+
+	require.NoError(t, exporter.Shutdown(ctx))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&first.shutdown))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&second.shutdown))
+	assert.Empty(t, rotator.exporters)
+}
+
+func TestRotateTokensOfMetadataExporters(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access_token")
+	writeTokenFile(t, file, "token1")
+	t.Setenv(tokenEnvVarName, "token1")
+	rotator := &tokenRotator{
+		tokens:      []*rotatedToken{{file: file, value: "token1", envVarNames: []string{tokenEnvVarName}}},
+		exporters:   map[*rotatingExporter]struct{}{},
+		gracePeriod: time.Hour,
+	}
+	exporters := &tokenExporters{metadata: true}
+	factory := rotateTokens(component.Factories{
+		Exporters: map[config.Type]component.ExporterFactory{"token": exporters.factory()},
+	}, rotator).Exporters["token"]
+
+	ctx := context.Background()
+	cfg := factory.CreateDefaultConfig().(*tokenExporterConfig)
+	cfg.AccessToken = "token1"
+	exporter, err := factory.CreateMetricsExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Start(ctx, componenttest.NewNopHost()))
+	// receivers like k8s_cluster and smartagent send their metadata updates through this interface
+	metadataExporter, ok := exporter.(metadata.MetadataExporter)
+	require.True(t, ok)
+	require.NoError(t, metadataExporter.ConsumeMetadata([]*metadata.MetadataUpdate{{}}))
+	first := exporters.get(0)
+	assert.Equal(t, int64(1), atomic.LoadInt64(&first.updates))
+
+	writeTokenFile(t, file, "token2")
+	rotator.check()
+	require.NoError(t, metadataExporter.ConsumeMetadata([]*metadata.MetadataUpdate{{}, {}}))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&first.updates))
+	assert.Equal(t, int64(2), atomic.LoadInt64(&exporters.get(1).updates))
+	require.NoError(t, exporter.Shutdown(ctx))
+}
+
+func TestRotatedTokenGracePeriod(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access_token")
+	writeTokenFile(t, file, "token1")
+	t.Setenv(tokenEnvVarName, "token1")
+	rotator := &tokenRotator{
+		tokens:      []*rotatedToken{{file: file, value: "token1", envVarNames: []string{tokenEnvVarName}}},
+		exporters:   map[*rotatingExporter]struct{}{},
+		gracePeriod: 10 * time.Millisecond,
+	}
+	exporters := &tokenExporters{}
+	factory := rotateTokens(component.Factories{
+		Exporters: map[config.Type]component.ExporterFactory{"token": exporters.factory()},
+	}, rotator).Exporters["token"]
+
+	ctx := context.Background()
+	cfg := factory.CreateDefaultConfig().(*tokenExporterConfig)
+	cfg.AccessToken = "token1"
+	exporter, err := factory.CreateMetricsExporter(ctx, componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exporter.Start(ctx, componenttest.NewNopHost()))
+
+	writeTokenFile(t, file, "token2")
+	rotator.check()
+	first := exporters.get(0)
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&first.shutdown) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, exporter.Shutdown(ctx))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&first.shutdown))
+	assert.Equal(t, int64(1), atomic.LoadInt64(&exporters.get(1).shutdown))
+}
+
+func TestTokenRotatorWatchesTokenFiles(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "access_token")
+	writeTokenFile(t, file, "token1")
+	t.Setenv(tokenEnvVarName, "token1")
+	rotator := &tokenRotator{
+		tokens:      []*rotatedToken{{file: file, value: "token1", envVarNames: []string{tokenEnvVarName}}},
+		exporters:   map[*rotatingExporter]struct{}{},
+		gracePeriod: time.Hour,
+	}
+	require.NoError(t, rotator.start())
+
+	writeTokenFile(t, file, "token2")
+	require.Eventually(t, func() bool {
+		return os.Getenv(tokenEnvVarName) == "token2"
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// stopped rotators no longer change the tokens
+	rotator.stop()
+	writeTokenFile(t, file, "token3")
+	time.Sleep(2 * tokenFileDebounce)
+	assert.Equal(t, "token2", os.Getenv(tokenEnvVarName))
+}
+
+type queuedTokenExporterConfig struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	AccessToken             string `mapstructure:"access_token"`
+	QueueSettings           struct {
+		StorageID *config.ComponentID `mapstructure:"storage"`
+	} `mapstructure:"sending_queue"`
+}
+
+func TestRotateTokensRejectsPersistentQueues(t *testing.T) {
+	rotator := &tokenRotator{
+		tokens:    []*rotatedToken{{file: "/etc/token", value: "token1", envVarNames: []string{tokenEnvVarName}}},
+		exporters: map[*rotatingExporter]struct{}{},
+	}
+	factory := rotatingExporterFactory{rotator: rotator}
+
+	cfg := &queuedTokenExporterConfig{ExporterSettings: config.NewExporterSettings(config.NewComponentID("token")), AccessToken: "token1"}
+	assert.False(t, hasPersistentQueue(cfg))
+	rotating, err := factory.newRotatingExporter(cfg, nil, nil)
+	require.NoError(t, err)
+	assert.NotNil(t, rotating)
+
+	storageID := config.NewComponentID("file_storage")
+	cfg.QueueSettings.StorageID = &storageID
+	assert.True(t, hasPersistentQueue(cfg))
+	rotating, err = factory.newRotatingExporter(cfg, nil, nil)
+	require.EqualError(t, err, "exporter token can't use the SPLUNK_ACCESS_TOKEN token of /etc/token, whose changes are applied by replacing the exporter, "+
+		"with a persistent sending_queue: remove the sending_queue storage or provide the token without a token file")
+	assert.Nil(t, rotating)
+
+	// exporters with persistent queues not using a rotated token aren't rejected
+	cfg.AccessToken = "other"
+	rotating, err = factory.newRotatingExporter(cfg, nil, nil)
+	require.NoError(t, err)
+	assert.Nil(t, rotating)
+}
+
+func TestExporterTokenField(t *testing.T) {
+	assert.Equal(t, "AccessToken", exporterTokenField(&tokenExporterConfig{}))
+	assert.Empty(t, exporterTokenField(&config.ExporterSettings{}))
+
+	cfg := &tokenExporterConfig{ExporterSettings: config.NewExporterSettings(config.NewComponentID("token")), AccessToken: "token1"}
+	copied := withToken(cfg, "AccessToken", "token2")
+	assert.Equal(t, &tokenExporterConfig{ExporterSettings: cfg.ExporterSettings, AccessToken: "token2"}, copied)
+	assert.Equal(t, "token1", cfg.AccessToken)
+}
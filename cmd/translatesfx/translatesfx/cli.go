@@ -15,6 +15,7 @@
 package translatesfx
 
 import (
+	"flag"
 	"fmt"
 	"log"
 	"os"
@@ -26,9 +27,25 @@ const header = `# Generated by 'translatesfx'. This tool is currently experiment
 # verify config before attempting to use in production.
 `
 
+// translateOptions controls optional translation behavior.
+type translateOptions struct {
+	// exactFilters translates metricsToExclude and metricsToInclude
+	// preserving the Smart Agent's glob, negation, and override semantics.
+	exactFilters bool
+}
+
 // CLI is the entry point for the translatecfg command.
 func CLI(args []string) {
-	config, warnings := translateConfig(paths(args))
+	var opts translateOptions
+	flags := flag.NewFlagSet(args[0], flag.ExitOnError)
+	flags.BoolVar(
+		&opts.exactFilters, "exact-filters", false,
+		"translate metricsToExclude and metricsToInclude preserving their glob, negation, and override semantics",
+	)
+	// flag parsing stops at the first positional argument, so flags must precede the paths
+	_ = flags.Parse(args[1:])
+	fname, wd := paths(append([]string{args[0]}, flags.Args()...))
+	config, warnings := translateConfig(fname, wd, opts)
 	fmt.Print(warningsToString(warnings))
 	fmt.Print(config)
 }
@@ -58,14 +75,14 @@ func paths(args []string) (cfgFname string, wd string) {
 	case 3:
 		return args[1], args[2]
 	default:
-		log.Fatal("usage: translatesacfg <path/to/smart/agent/config.yaml>")
+		log.Fatal("usage: translatesacfg [-exact-filters] <path/to/smart/agent/config.yaml> [working directory]")
 	}
 	return
 }
 
 // translateConfig takes a Smart Agent config file path and a working directory,
 // then prints a translated Otel configuration to stdout.
-func translateConfig(fname, wd string) (configYaml string, warnings []error) {
+func translateConfig(fname, wd string, opts translateOptions) (configYaml string, warnings []error) {
 	orig, err := loadCfg(fname)
 	if err != nil {
 		log.Fatalf("error loading config %q: %v", fname, err)
@@ -75,7 +92,7 @@ func translateConfig(fname, wd string) (configYaml string, warnings []error) {
 		log.Fatalf("error expanding Smart Agent config: %v", err)
 	}
 	saInfo := saExpandedToCfgInfo(saExpanded)
-	oc, warnings := saInfoToOtelConfig(saInfo, vaultPaths)
+	oc, warnings := saInfoToOtelConfig(saInfo, vaultPaths, opts)
 
 	bytes, err := yaml.Marshal(oc)
 	if err != nil {
@@ -24,7 +24,7 @@ import (
 )
 
 func TestTranslateConfig(t *testing.T) {
-	translated, w := translateConfig("testdata/sa-e2e-input.yaml", "")
+	translated, w := translateConfig("testdata/sa-e2e-input.yaml", "", translateOptions{})
 	assert.Nil(t, w)
 	expected, err := os.ReadFile("testdata/otel-e2e-expected.yaml")
 	require.NoError(t, err)
@@ -0,0 +1,403 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translatesfx
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// translateExactFilters translates the Smart Agent metricsToExclude and
+// metricsToInclude into a filter processor include or exclude, preserving the
+// Smart Agent's glob, negation, and override semantics. Exclusions of metric
+// names alone are matched by name, with the strict match type if the names are
+// all literal and the regexp one otherwise, and any other filters with expr
+// expressions.
+func translateExactFilters(sa saCfgInfo, otel *otelCfg) (warnings []error) {
+	metricsFilter, warnings := saFiltersToMatchProperties(sa.metricsToExclude, sa.metricsToInclude)
+	if metricsFilter == nil {
+		return warnings
+	}
+	otel.Processors[filterProc] = map[string]any{
+		"metrics": metricsFilter,
+	}
+	otel.Service.Pipelines["metrics"].appendProcessor(filterProc)
+	return warnings
+}
+
+// saFiltersToMatchProperties returns the filter processor metrics include or
+// exclude equivalent to the filters, nil if there are none to translate.
+func saFiltersToMatchProperties(excludes, includes []any) (map[string]any, []error) {
+	var warnings []error
+	for _, filters := range []struct {
+		kind    string
+		filters []any
+	}{{"metricsToExclude", excludes}, {"metricsToInclude", includes}} {
+		for _, filterV := range filters.filters {
+			if monitorType, ok := filterV.(map[any]any)["monitorType"]; ok {
+				warnings = append(warnings, fmt.Errorf(
+					"%s filter monitorType %q can't be translated, the filter will apply to all monitors", filters.kind, monitorType,
+				))
+			}
+		}
+	}
+
+	if len(includes) == 0 {
+		if names, negated, ok := metricNameFilters(excludes); ok {
+			if properties, err := metricNamesProperties(names); err == nil {
+				// a negated filter drops the metrics it doesn't match
+				if negated {
+					return map[string]any{"include": properties}, warnings
+				}
+				return map[string]any{"exclude": properties}, warnings
+			}
+		}
+	}
+
+	expressions, w := saFiltersToExactExpr(excludes, includes)
+	warnings = append(warnings, w...)
+	if len(expressions) == 0 {
+		return nil, warnings
+	}
+	return map[string]any{
+		"exclude": map[string]any{
+			"match_type":  "expr",
+			"expressions": expressions,
+		},
+	}, warnings
+}
+
+// metricNameFilters returns the metric names of the filters if they only match
+// metric names without negated matchers, and are either all non-negated or a
+// single negated one.
+func metricNameFilters(filters []any) (names []any, negated bool, ok bool) {
+	for _, filterV := range filters {
+		filter := filterV.(map[any]any)
+		if dims, _ := filter["dimensions"].(map[any]any); len(dims) != 0 {
+			return nil, false, false
+		}
+		filterNames := filterMetricNames(filter)
+		if len(filterNames) == 0 {
+			return nil, false, false
+		}
+		for _, name := range filterNames {
+			if strings.HasPrefix(fmt.Sprintf("%v", name), "!") {
+				return nil, false, false
+			}
+		}
+		if filterNegated, _ := filter["negated"].(bool); filterNegated {
+			if len(filters) != 1 {
+				return nil, false, false
+			}
+			negated = true
+		}
+		names = append(names, filterNames...)
+	}
+	return names, negated, len(names) != 0
+}
+
+// metricNamesProperties returns the strict match properties of the names if
+// they are all literal, otherwise the regexp ones.
+func metricNamesProperties(names []any) (map[string]any, error) {
+	literals := make([]string, 0, len(names))
+	patterns := make([]string, 0, len(names))
+	allLiteral := true
+	for _, nameV := range names {
+		name := fmt.Sprintf("%v", nameV)
+		pattern, err := saMatcherToRegexp(name)
+		if err != nil {
+			return nil, err
+		}
+		if isRegexFilter(name) || strings.ContainsAny(name, `*?[]{}\`) {
+			allLiteral = false
+		}
+		literals = append(literals, name)
+		patterns = append(patterns, pattern)
+	}
+	if allLiteral {
+		return map[string]any{"match_type": "strict", "metric_names": literals}, nil
+	}
+	return map[string]any{"match_type": "regexp", "metric_names": patterns}, nil
+}
+
+// saFiltersToExactExpr returns an expression for every exclude filter. As in
+// the Smart Agent, a datapoint is dropped if it matches any exclude filter (or
+// doesn't match a negated one) unless it also matches any include filter.
+// Since the filter processor evaluates the expressions per datapoint but
+// drops entire metrics, a metric is dropped if any of its datapoints is.
+func saFiltersToExactExpr(excludes, includes []any) (expressions []string, warnings []error) {
+	var includeExpressions []string
+	for _, includeV := range includes {
+		expression, err := exactFilterToExpr(includeV.(map[any]any))
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("metricsToInclude filter not translated: %w", err))
+		} else if expression != "" {
+			includeExpressions = append(includeExpressions, expression)
+		}
+	}
+	includeExpression := strings.Join(includeExpressions, " or ")
+
+	for _, excludeV := range excludes {
+		filter := excludeV.(map[any]any)
+		expression, err := exactFilterToExpr(filter)
+		if err != nil {
+			warnings = append(warnings, fmt.Errorf("metricsToExclude filter not translated: %w", err))
+			continue
+		}
+		if expression == "" {
+			continue
+		}
+		if negated, _ := filter["negated"].(bool); negated {
+			expression = "not (" + expression + ")"
+		}
+		if includeExpression != "" {
+			expression = "(" + expression + ") and not (" + includeExpression + ")"
+		}
+		expressions = append(expressions, expression)
+	}
+	return expressions, warnings
+}
+
+// filterMetricNames returns the metric name matchers of the filter.
+func filterMetricNames(filter map[any]any) []any {
+	if namesV, ok := filter["metricNames"]; ok {
+		names, _ := namesV.([]any)
+		return names
+	}
+	if nameV, ok := filter["metricName"]; ok {
+		return []any{nameV}
+	}
+	return nil
+}
+
+// exactFilterToExpr returns the expression matching the datapoints selected by
+// a Smart Agent filter, ignoring its negation. All of the filter's metric name
+// and dimension matchers must match.
+func exactFilterToExpr(filter map[any]any) (string, error) {
+	var terms []string
+	if names := filterMetricNames(filter); len(names) != 0 {
+		term, err := metricNameMatchersToExpr(names)
+		if err != nil {
+			return "", err
+		}
+		terms = append(terms, term)
+	}
+
+	dims, _ := filter["dimensions"].(map[any]any)
+	keys := make([]string, 0, len(dims))
+	for k := range dims {
+		keys = append(keys, fmt.Sprintf("%v", k))
+	}
+	// map iteration order is random, so sort for stable output
+	sort.Strings(keys)
+	for _, key := range keys {
+		var values []any
+		switch v := dims[key].(type) {
+		case []any:
+			values = v
+		default:
+			values = []any{v}
+		}
+		term, err := matchersToExpr(fmt.Sprintf("Label(%q)", key), values)
+		if err != nil {
+			return "", err
+		}
+		// the Smart Agent requires filtered dimensions to be present, even for negated values
+		terms = append(terms, fmt.Sprintf("HasLabel(%q) and %s", key, term))
+	}
+
+	switch len(terms) {
+	case 0:
+		return "", nil
+	case 1:
+		return terms[0], nil
+	}
+	for i, term := range terms {
+		terms[i] = "(" + term + ")"
+	}
+	return strings.Join(terms, " and "), nil
+}
+
+// metricNameMatchersToExpr follows the Smart Agent's metric name filter semantics,
+// which differ from those of its dimension value filters: literal names are
+// looked up first, and any negated literal name makes all the other names
+// match. Otherwise, the name must match any non-negated glob or regular
+// expression, or not match any negated one.
+func metricNameMatchersToExpr(names []any) (string, error) {
+	var literals []string
+	negatedLiterals := map[string]bool{}
+	var terms []string
+	for _, nameV := range names {
+		name := fmt.Sprintf("%v", nameV)
+		matcher := strings.TrimPrefix(name, "!")
+		isNegated := matcher != name
+		pattern, err := saMatcherToRegexp(matcher)
+		if err != nil {
+			return "", err
+		}
+		switch {
+		case !isRegexFilter(matcher) && !strings.ContainsAny(matcher, "*?[]{}!"):
+			if _, ok := negatedLiterals[pattern]; !ok {
+				literals = append(literals, pattern)
+			}
+			// as in the Smart Agent, the last of the same literal names wins
+			negatedLiterals[pattern] = isNegated
+		case isNegated:
+			terms = append(terms, fmt.Sprintf("not (MetricName matches %q)", pattern))
+		default:
+			terms = append(terms, fmt.Sprintf("MetricName matches %q", pattern))
+		}
+	}
+
+	var negated []string
+	for _, pattern := range literals {
+		if negatedLiterals[pattern] {
+			negated = append(negated, fmt.Sprintf("not (MetricName matches %q)", pattern))
+		}
+	}
+	if len(negated) != 0 {
+		return strings.Join(negated, " and "), nil
+	}
+	for i := len(literals) - 1; i >= 0; i-- {
+		terms = append([]string{fmt.Sprintf("MetricName matches %q", literals[i])}, terms...)
+	}
+	if len(terms) == 1 {
+		return terms[0], nil
+	}
+	return "(" + strings.Join(terms, " or ") + ")", nil
+}
+
+// matchersToExpr follows the Smart Agent's string filter semantics: the value
+// must match any non-negated matcher, if there are any, and none of the
+// negated ("!" prefixed) ones.
+func matchersToExpr(target string, matchers []any) (string, error) {
+	var positive, negated []string
+	for _, matcherV := range matchers {
+		matcher := fmt.Sprintf("%v", matcherV)
+		isNegated := strings.HasPrefix(matcher, "!")
+		if isNegated {
+			matcher = matcher[1:]
+		}
+		pattern, err := saMatcherToRegexp(matcher)
+		if err != nil {
+			return "", err
+		}
+		stmt := fmt.Sprintf("%s matches %q", target, pattern)
+		if isNegated {
+			negated = append(negated, "not ("+stmt+")")
+		} else {
+			positive = append(positive, stmt)
+		}
+	}
+
+	var terms []string
+	switch len(positive) {
+	case 0:
+	case 1:
+		terms = append(terms, positive[0])
+	default:
+		terms = append(terms, "("+strings.Join(positive, " or ")+")")
+	}
+	terms = append(terms, negated...)
+	return strings.Join(terms, " and "), nil
+}
+
+// saMatcherToRegexp returns the regular expression equivalent to a Smart Agent
+// matcher, which is either a regular expression surrounded by slashes or a glob.
+func saMatcherToRegexp(matcher string) (string, error) {
+	pattern := matcher
+	if isRegexFilter(matcher) {
+		pattern = matcher[1 : len(matcher)-1]
+	} else {
+		var err error
+		if pattern, err = saGlobToRegexp(matcher); err != nil {
+			return "", err
+		}
+	}
+	if _, err := regexp.Compile(pattern); err != nil {
+		return "", fmt.Errorf("invalid matcher %q: %w", matcher, err)
+	}
+	return pattern, nil
+}
+
+// saGlobToRegexp translates the glob syntax supported by the Smart Agent:
+// "*" and "?" wildcards, "[...]" and "[!...]" character classes, "{a,b}"
+// alternatives, and "\" escapes. The resulting expression is anchored, since
+// globs must match the entire value.
+func saGlobToRegexp(glob string) (string, error) {
+	var sb strings.Builder
+	sb.WriteByte('^')
+	inAlternatives := false
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '*':
+			sb.WriteString(".*")
+		case '?':
+			sb.WriteByte('.')
+		case '\\':
+			if i+1 == len(glob) {
+				return "", fmt.Errorf("invalid glob %q: trailing escape", glob)
+			}
+			i++
+			sb.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end < 0 {
+				return "", fmt.Errorf("invalid glob %q: unterminated character class", glob)
+			}
+			class := glob[i+1 : i+1+end]
+			sb.WriteByte('[')
+			if strings.HasPrefix(class, "!") {
+				sb.WriteByte('^')
+				class = class[1:]
+			} else if strings.HasPrefix(class, "^") {
+				// a literal caret rather than a negation
+				sb.WriteByte('\\')
+			}
+			sb.WriteString(class)
+			sb.WriteByte(']')
+			i += end + 1
+		case '{':
+			if inAlternatives {
+				return "", fmt.Errorf("invalid glob %q: nested alternatives", glob)
+			}
+			inAlternatives = true
+			sb.WriteString("(?:")
+		case '}':
+			if !inAlternatives {
+				sb.WriteString(`\}`)
+				continue
+			}
+			inAlternatives = false
+			sb.WriteByte(')')
+		case ',':
+			if inAlternatives {
+				sb.WriteByte('|')
+			} else {
+				sb.WriteByte(',')
+			}
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	if inAlternatives {
+		return "", fmt.Errorf("invalid glob %q: unterminated alternatives", glob)
+	}
+	sb.WriteByte('$')
+	return sb.String(), nil
+}
@@ -0,0 +1,311 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package translatesfx
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/antonmedv/expr"
+	"github.com/antonmedv/expr/vm"
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func yamlToOtelConfigWithExactFilters(t *testing.T, filename string) (out *otelCfg, warnings []error) {
+	cfg := fromYAML(t, filename)
+	expanded, vaultPaths, err := expandSA(cfg, "")
+	require.NoError(t, err)
+	info := saExpandedToCfgInfo(expanded)
+	return saInfoToOtelConfig(info, vaultPaths, translateOptions{exactFilters: true})
+}
+
+func TestInfoToOtelConfig_MetricsToExclude_Exact(t *testing.T) {
+	cfg, w := yamlToOtelConfigWithExactFilters(t, "testdata/sa-metrics-to-exclude.yaml")
+	assert.Nil(t, w)
+	fp := cfg.Processors["filter"]
+	require.NotNil(t, fp)
+	assert.Equal(t, map[string]any{
+		"exclude": map[string]any{
+			"match_type": "expr",
+			"expressions": []string{
+				// as in the Smart Agent, the negated literal names match all the other metrics
+				`not (MetricName matches "^node_filesystem_free_bytes$")` +
+					` and not (MetricName matches "^node_filesystem_readonly$")`,
+				`(MetricName matches "^node_network_.*$")` +
+					` and (HasLabel("interface") and Label("interface") matches "^.*$"` +
+					` and not (Label("interface") matches "^eth0$"))`,
+				`(MetricName matches "^node_disk_.*$") and (HasLabel("device") and Label("device") matches "^sr.*$")`,
+			},
+		},
+	}, fp["metrics"])
+	assert.Contains(t, cfg.Service.Pipelines["metrics"].Processors, "filter")
+}
+
+func TestInfoToOtelConfig_MetricsToExclude_Exact_Regex(t *testing.T) {
+	cfg, _ := yamlToOtelConfigWithExactFilters(t, "testdata/sa-metrics-to-exclude-regex.yaml")
+	assert.Equal(t, map[string]any{
+		"exclude": map[string]any{
+			"match_type":   "regexp",
+			"metric_names": []string{`vsphere\.cpu_\w*_percent`},
+		},
+	}, cfg.Processors["filter"]["metrics"])
+}
+
+func TestInfoToOtelConfig_MetricsToExclude_Exact_Monitor(t *testing.T) {
+	cfg, _ := yamlToOtelConfigWithExactFilters(t, "testdata/sa-metrics-to-exclude-monitor.yaml")
+	_, ok := cfg.Processors["filter"]
+	assert.False(t, ok)
+}
+
+func TestSAFiltersToMatchProperties(t *testing.T) {
+	tests := []struct {
+		name     string
+		excludes []any
+		includes []any
+		expected map[string]any
+		warnings []string
+	}{
+		{
+			name: "literal metric names",
+			excludes: []any{
+				map[any]any{"metricNames": []any{"cpu.idle", "cpu.user"}},
+				map[any]any{"metricName": "disk.ops"},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type":   "strict",
+				"metric_names": []string{"cpu.idle", "cpu.user", "disk.ops"},
+			}},
+		},
+		{
+			name: "metric name globs",
+			excludes: []any{
+				map[any]any{"metricNames": []any{"cpu.*", "disk.ops"}},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type":   "regexp",
+				"metric_names": []string{`^cpu\..*$`, `^disk\.ops$`},
+			}},
+		},
+		{
+			name: "negated filter",
+			excludes: []any{
+				map[any]any{"metricName": "foo.*", "negated": true},
+			},
+			expected: map[string]any{"include": map[string]any{
+				"match_type":   "regexp",
+				"metric_names": []string{`^foo\..*$`},
+			}},
+		},
+		{
+			name: "negated metric names",
+			excludes: []any{
+				map[any]any{"metricNames": []any{"cpu.*", "disk.*", "!cpu.utilization"}},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type": "expr",
+				// a negated literal name makes the Smart Agent match all the other names, regardless of the globs
+				"expressions": []string{`not (MetricName matches "^cpu\\.utilization$")`},
+			}},
+		},
+		{
+			name: "negated metric name globs",
+			excludes: []any{
+				map[any]any{"metricNames": []any{"cpu", "!disk.*"}},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type":  "expr",
+				"expressions": []string{`(MetricName matches "^cpu$" or not (MetricName matches "^disk\\..*$"))`},
+			}},
+		},
+		{
+			name: "includes override excludes",
+			excludes: []any{
+				map[any]any{"metricName": "foo.*"},
+				map[any]any{"metricName": "bar", "negated": true},
+			},
+			includes: []any{
+				map[any]any{"metricName": "foo.aaa.*"},
+				map[any]any{"metricName": "foo.bbb", "dimensions": map[any]any{"host": "a"}},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type": "expr",
+				"expressions": []string{
+					`(MetricName matches "^foo\\..*$") and not (MetricName matches "^foo\\.aaa\\..*$"` +
+						` or (MetricName matches "^foo\\.bbb$") and (HasLabel("host") and Label("host") matches "^a$"))`,
+					`(not (MetricName matches "^bar$")) and not (MetricName matches "^foo\\.aaa\\..*$"` +
+						` or (MetricName matches "^foo\\.bbb$") and (HasLabel("host") and Label("host") matches "^a$"))`,
+				},
+			}},
+		},
+		{
+			name: "dimensions only",
+			excludes: []any{
+				map[any]any{"dimensions": map[any]any{"b": []any{"x", "y"}, "a": "!z"}},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type": "expr",
+				"expressions": []string{
+					`(HasLabel("a") and not (Label("a") matches "^z$"))` +
+						` and (HasLabel("b") and (Label("b") matches "^x$" or Label("b") matches "^y$"))`,
+				},
+			}},
+		},
+		{
+			name: "includes without excludes",
+			includes: []any{
+				map[any]any{"metricName": "foo"},
+			},
+		},
+		{
+			name: "monitor type",
+			excludes: []any{
+				map[any]any{"metricName": "foo", "monitorType": "cpu"},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type":   "strict",
+				"metric_names": []string{"foo"},
+			}},
+			warnings: []string{`metricsToExclude filter monitorType "cpu" can't be translated, the filter will apply to all monitors`},
+		},
+		{
+			name: "invalid matcher",
+			excludes: []any{
+				map[any]any{"metricName": "foo{bar"},
+				map[any]any{"metricName": "/foo(/"},
+				map[any]any{"metricName": "bar"},
+			},
+			expected: map[string]any{"exclude": map[string]any{
+				"match_type":  "expr",
+				"expressions": []string{`MetricName matches "^bar$"`},
+			}},
+			warnings: []string{
+				`metricsToExclude filter not translated: invalid glob "foo{bar": unterminated alternatives`,
+				"metricsToExclude filter not translated: invalid matcher \"/foo(/\": error parsing regexp: missing closing ): `foo(`",
+			},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			properties, warnings := saFiltersToMatchProperties(test.excludes, test.includes)
+			assert.Equal(t, test.expected, properties)
+			var warningStrings []string
+			for _, w := range warnings {
+				warningStrings = append(warningStrings, w.Error())
+			}
+			assert.Equal(t, test.warnings, warningStrings)
+		})
+	}
+}
+
+func TestSAFiltersToExactExpr_MatchesSmartAgent(t *testing.T) {
+	excludes := []config.MetricFilter{
+		{MetricNames: []string{"node_filesystem_*", "!node_filesystem_free_bytes"}},
+		{MetricName: "node_network_*", Dimensions: map[string]any{"interface": []any{"*", "!eth0"}}},
+	}
+	includes := []config.MetricFilter{
+		{MetricName: "node_network_up", Dimensions: map[string]any{"interface": "eth1"}},
+	}
+	saFilterSet, err := makeOldFilterSet(excludes, includes)
+	require.NoError(t, err)
+
+	expressions, warnings := saFiltersToExactExpr(
+		[]any{
+			map[any]any{"metricNames": []any{"node_filesystem_*", "!node_filesystem_free_bytes"}},
+			map[any]any{"metricName": "node_network_*", "dimensions": map[any]any{"interface": []any{"*", "!eth0"}}},
+		},
+		[]any{
+			map[any]any{"metricName": "node_network_up", "dimensions": map[any]any{"interface": "eth1"}},
+		},
+	)
+	require.Empty(t, warnings)
+	var programs []*vm.Program
+	for _, expression := range expressions {
+		program, err := expr.Compile(expression)
+		require.NoError(t, err)
+		programs = append(programs, program)
+	}
+
+	for _, dp := range []*datapoint.Datapoint{
+		{Metric: "node_filesystem_size"},
+		{Metric: "node_filesystem_free_bytes"},
+		{Metric: "node_network_up", Dimensions: map[string]string{"interface": "eth0"}},
+		{Metric: "node_network_up", Dimensions: map[string]string{"interface": "eth1"}},
+		{Metric: "node_network_up", Dimensions: map[string]string{"interface": "eth2"}},
+		{Metric: "node_network_up"},
+		{Metric: "cpu.user"},
+	} {
+		dropped := false
+		for _, program := range programs {
+			v, err := expr.Run(program, map[string]any{
+				"MetricName": dp.Metric,
+				"HasLabel": func(key string) bool {
+					_, ok := dp.Dimensions[key]
+					return ok
+				},
+				"Label": func(key string) string {
+					return dp.Dimensions[key]
+				},
+			})
+			require.NoError(t, err)
+			dropped = dropped || v.(bool)
+		}
+		assert.Equal(t, saFilterSet.Matches(dp), dropped, "%s %v", dp.Metric, dp.Dimensions)
+	}
+}
+
+func TestSAGlobToRegexp(t *testing.T) {
+	tests := []struct {
+		glob       string
+		expected   string
+		matches    []string
+		mismatches []string
+	}{
+		{glob: "cpu.*", expected: `^cpu\..*$`, matches: []string{"cpu.", "cpu.user"}, mismatches: []string{"cpux", "a.cpu.user"}},
+		{glob: "cpu.?", expected: `^cpu\..$`, matches: []string{"cpu.a"}, mismatches: []string{"cpu.ab"}},
+		{glob: "cpu.{user,sys}", expected: `^cpu\.(?:user|sys)$`, matches: []string{"cpu.user", "cpu.sys"}, mismatches: []string{"cpu.idle"}},
+		{glob: "disk[0-9]", expected: `^disk[0-9]$`, matches: []string{"disk1"}, mismatches: []string{"diska"}},
+		{glob: "disk[!0-9]", expected: `^disk[^0-9]$`, matches: []string{"diska"}, mismatches: []string{"disk1"}},
+		{glob: "a[^]", expected: `^a[\^]$`, matches: []string{"a^"}, mismatches: []string{"ab"}},
+		{glob: `a\*b`, expected: `^a\*b$`, matches: []string{"a*b"}, mismatches: []string{"axb"}},
+		{glob: "a+b,c}", expected: `^a\+b,c\}$`, matches: []string{"a+b,c}"}, mismatches: []string{"aab,c}"}},
+	}
+	for _, test := range tests {
+		t.Run(test.glob, func(t *testing.T) {
+			actual, err := saGlobToRegexp(test.glob)
+			require.NoError(t, err)
+			assert.Equal(t, test.expected, actual)
+			re := regexp.MustCompile(actual)
+			for _, s := range test.matches {
+				assert.True(t, re.MatchString(s), s)
+			}
+			for _, s := range test.mismatches {
+				assert.False(t, re.MatchString(s), s)
+			}
+		})
+	}
+
+	for glob, expectedErr := range map[string]string{
+		`a\`:   `invalid glob "a\\": trailing escape`,
+		"a[b":  `invalid glob "a[b": unterminated character class`,
+		"{a{b": `invalid glob "{a{b": nested alternatives`,
+		"{a,b": `invalid glob "{a,b": unterminated alternatives`,
+	} {
+		_, err := saGlobToRegexp(glob)
+		assert.EqualError(t, err, expectedErr)
+	}
+}
@@ -42,7 +42,7 @@ var metricsAndTracesReceiverMonitorTypes = map[string]bool{sfxFwder: true}
 // monitors that should be converted to logs receivers only
 var exclusivelyLogsReceiverMonitorTypes = map[string]bool{processlist: true, kubernetesEvents: true}
 
-func saInfoToOtelConfig(sa saCfgInfo, vaultPaths []string) (otel *otelCfg, warnings []error) {
+func saInfoToOtelConfig(sa saCfgInfo, vaultPaths []string, opts translateOptions) (otel *otelCfg, warnings []error) {
 	otel = newOtelCfg()
 	translateExporters(sa, otel)
 	w := translateMonitors(sa, otel)
@@ -51,7 +51,11 @@ func saInfoToOtelConfig(sa saCfgInfo, vaultPaths []string) (otel *otelCfg, warni
 	translateSAExtension(sa, otel)
 	translateObservers(sa, otel)
 	translateConfigSources(sa, otel, vaultPaths)
-	translateFilters(sa, otel)
+	if opts.exactFilters {
+		warnings = append(warnings, translateExactFilters(sa, otel)...)
+	} else {
+		translateFilters(sa, otel)
+	}
 	return otel, warnings
 }
 
@@ -53,7 +53,7 @@ func TestSAToOtelConfig(t *testing.T) {
 		realm:       "us1",
 		accessToken: "s3cr3t",
 		monitors:    []any{testvSphereMonitorCfg()},
-	}, nil)
+	}, nil, translateOptions{})
 	assert.Nil(t, w)
 	require.Equal(t, expected, otelConfig.Receivers["smartagent/vsphere"])
 }
@@ -370,7 +370,7 @@ func yamlToOtelConfig(t *testing.T, filename string) (out *otelCfg, warnings []e
 	require.NoError(t, err)
 	info := saExpandedToCfgInfo(expanded)
 	require.NoError(t, err)
-	return saInfoToOtelConfig(info, vaultPaths)
+	return saInfoToOtelConfig(info, vaultPaths, translateOptions{})
 }
 
 func TestSAExcludesToExpr_Simple(t *testing.T) {
@@ -18,6 +18,11 @@ if command -v setcap >/dev/null 2>&1; then
     setcap CAP_SYS_PTRACE,CAP_DAC_READ_SEARCH=+eip /usr/bin/otelcol
 fi
 
+# allow the journald receiver to read the systemd journal
+if getent group systemd-journal >/dev/null 2>&1; then
+    usermod -a -G systemd-journal splunk-otel-collector
+fi
+
 if [ -f /usr/lib/splunk-otel-collector/agent-bundle/bin/patch-interpreter ]; then
     /usr/lib/splunk-otel-collector/agent-bundle/bin/patch-interpreter /usr/lib/splunk-otel-collector/agent-bundle
 fi
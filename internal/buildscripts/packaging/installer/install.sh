@@ -78,7 +78,6 @@ fluent_capng_c_version="0.2.2"
 fluent_config_dir="${collector_config_dir}/fluentd"
 fluent_config_path="${fluent_config_dir}/fluent.conf"
 fluent_plugin_systemd_version="1.0.1"
-journald_config_path="${fluent_config_dir}/conf.d/journald.conf"
 
 td_agent_repo_base="https://packages.treasuredata.com"
 td_agent_gpg_key_url="${td_agent_repo_base}/GPG-KEY-td-agent"
@@ -319,6 +318,11 @@ create_user_group() {
 
   getent passwd $user >/dev/null 2>&1 || \
     useradd --system --no-user-group --home-dir /etc/otel/collector --no-create-home --shell $(command -v nologin) --groups $group $user
+
+  # allow the journald receiver to read the systemd journal
+  if getent group systemd-journal >/dev/null 2>&1; then
+    usermod -a -G systemd-journal $user
+  fi
 }
 
 configure_service_owner() {
@@ -1044,8 +1048,8 @@ Note: The fluentd service runs as the "td-agent" user.  When adding new input so
 files to the ${fluent_config_dir}/conf.d/ directory, ensure that the "td-agent" user has permissions
 to access the new config files and the paths defined within.
 
-By default, fluentd has been configured to collect systemd journal log events from /var/log/journal.
-See $journald_config_path for the default source configuration.
+The systemd journal log events are collected by the Splunk OpenTelemetry Collector's journald receiver
+instead of fluentd.  See $agent_config_path for its default configuration.
 
 If the fluentd configuration is modified or new config files are added, the fluentd service must be
 restarted to apply the changes by running the following command as root:
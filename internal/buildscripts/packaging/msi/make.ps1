@@ -44,7 +44,7 @@ function New-MSI(
     [string]$Translatesfx="./bin/translatesfx_windows_amd64.exe",
     [string]$Version="0.0.1",
     [string]$BuildDir="./dist",
-    [string]$Config="./cmd/otelcol/config/collector/agent_config.yaml",
+    [string]$Config="./cmd/otelcol/config/collector/agent_config_windows.yaml",
     [string]$FluentdConfig="./internal/buildscripts/packaging/fpm/etc/otel/collector/fluentd/fluent.conf",
     [string]$FluentdConfDir="./internal/buildscripts/packaging/msi/fluentd/conf.d"
 ) {
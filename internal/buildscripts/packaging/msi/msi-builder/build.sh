@@ -19,7 +19,7 @@ set -euo pipefail
 WXS_PATH="/project/internal/buildscripts/packaging/msi/splunk-otel-collector.wxs"
 OTELCOL="/project/bin/otelcol_windows_amd64.exe"
 TRANSLATESFX="/project/bin/translatesfx_windows_amd64.exe"
-AGENT_CONFIG="/project/cmd/otelcol/config/collector/agent_config.yaml"
+AGENT_CONFIG="/project/cmd/otelcol/config/collector/agent_config_windows.yaml"
 GATEWAY_CONFIG="/project/cmd/otelcol/config/collector/gateway_config.yaml"
 FLUENTD_CONFIG="/project/internal/buildscripts/packaging/fpm/etc/otel/collector/fluentd/fluent.conf"
 FLUENTD_CONFD="/project/internal/buildscripts/packaging/msi/fluentd/conf.d"
@@ -170,7 +170,7 @@ parse_args_and_build() {
     candle -arch x64 -out "${configFilesWixObj//\//\\}" "${configFilesWsx//\//\\}"
 
     collectorWixObj="${build_dir}/splunk-otel-collector.wixobj"
-    candle -arch x64 -out "${collectorWixObj//\//\\}" -dVersion="$version" -dOtelcol="$otelcol" -dTranslatesfx="$translatesfx" "${WXS_PATH//\//\\}"
+    candle -arch x64 -ext WixUtilExtension.dll -out "${collectorWixObj//\//\\}" -dVersion="$version" -dOtelcol="$otelcol" -dTranslatesfx="$translatesfx" "${WXS_PATH//\//\\}"
 
     msi="${build_dir}/${msi_name}"
     light -ext WixUtilExtension.dll -sval -out "${msi//\//\\}" -b "${files_dir//\//\\}" "${collectorWixObj//\//\\}" "${configFilesWixObj//\//\\}"
@@ -48,7 +48,7 @@ SMART_AGENT_RELEASE_PATH = REPO_DIR / "internal" / "buildscripts" / "packaging"
 # MSI
 WIX_IMAGE = "quay.io/signalfx/wix-dev:latest"
 WXS_PATH = "internal/buildscripts/packaging/msi/splunk-otel-collector.wxs"
-MSI_CONFIG = "cmd/otelcol/config/collector/agent_config.yaml"
+MSI_CONFIG = "cmd/otelcol/config/collector/agent_config_windows.yaml"
 FLUENTD_CONFIG = "internal/buildscripts/packaging/fpm/etc/otel/collector/fluentd/fluent.conf"
 FLUENTD_CONFD = "internal/buildscripts/packaging/msi/fluentd/conf.d"
 
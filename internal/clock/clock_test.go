@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRealClock(t *testing.T) {
+	c := New()
+	assert.WithinDuration(t, time.Now(), c.Now(), time.Second)
+
+	ticker := c.NewTicker(time.Millisecond)
+	defer ticker.Stop()
+	select {
+	case <-ticker.Chan():
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticker didn't tick")
+	}
+}
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2022, 10, 16, 0, 0, 0, 0, time.UTC)
+	c := NewFake(start)
+	assert.Equal(t, start, c.Now())
+
+	ticker := c.NewTicker(10 * time.Second)
+	assert.Equal(t, 1, c.Tickers())
+	assertNoTick(t, ticker)
+
+	c.Advance(9 * time.Second)
+	assertNoTick(t, ticker)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(10*time.Second), <-ticker.Chan())
+	assertNoTick(t, ticker)
+
+	// ticks that aren't received are dropped
+	c.Advance(35 * time.Second)
+	c.Advance(5 * time.Second)
+	assert.Equal(t, start.Add(45*time.Second), <-ticker.Chan())
+	assertNoTick(t, ticker)
+	c.Advance(9 * time.Second)
+	assert.Equal(t, start.Add(59*time.Second), c.Now())
+	assertNoTick(t, ticker)
+	c.Advance(time.Second)
+	assert.Equal(t, start.Add(60*time.Second), <-ticker.Chan())
+
+	ticker.Stop()
+	assert.Equal(t, 0, c.Tickers())
+	c.Advance(time.Minute)
+	assertNoTick(t, ticker)
+
+	require.PanicsWithValue(t, "non-positive interval for NewTicker", func() { c.NewTicker(0) })
+}
+
+func assertNoTick(t *testing.T, ticker Ticker) {
+	select {
+	case tick := <-ticker.Chan():
+		t.Fatalf("unexpected tick at %v", tick)
+	default:
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package clock
+
+import (
+	"sync"
+	"time"
+)
+
+var _ Clock = (*Fake)(nil)
+
+// Fake is a Clock whose time only changes when advanced.  Like those of a time.Ticker whose reader is
+// slow, ticks are dropped rather than queued, so a ticker ticks at most once per Advance.
+type Fake struct {
+	now     time.Time
+	tickers map[*fakeTicker]struct{}
+	lock    sync.Mutex
+}
+
+// NewFake returns a Fake clock at the time.
+func NewFake(now time.Time) *Fake {
+	return &Fake{now: now, tickers: map[*fakeTicker]struct{}{}}
+}
+
+func (f *Fake) Now() time.Time {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.now
+}
+
+// NewTicker returns a ticker ticking every d the clock is advanced.  It panics if d isn't positive.
+func (f *Fake) NewTicker(d time.Duration) Ticker {
+	if d <= 0 {
+		panic("non-positive interval for NewTicker")
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	t := &fakeTicker{fake: f, c: make(chan time.Time, 1), period: d, next: f.now.Add(d)}
+	f.tickers[t] = struct{}{}
+	return t
+}
+
+// Advance moves the time forward by d, ticking the tickers whose next tick it reaches.
+func (f *Fake) Advance(d time.Duration) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.now = f.now.Add(d)
+	for t := range f.tickers {
+		if t.next.After(f.now) {
+			continue
+		}
+		select {
+		case t.c <- f.now:
+		default:
+		}
+		for !t.next.After(f.now) {
+			t.next = t.next.Add(t.period)
+		}
+	}
+}
+
+// Tickers returns the number of the clock's tickers that aren't stopped, for tests to wait for the
+// tickers of started components before advancing the clock.
+func (f *Fake) Tickers() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return len(f.tickers)
+}
+
+type fakeTicker struct {
+	next   time.Time
+	fake   *Fake
+	c      chan time.Time
+	period time.Duration
+}
+
+func (t *fakeTicker) Chan() <-chan time.Time {
+	return t.c
+}
+
+func (t *fakeTicker) Stop() {
+	t.fake.lock.Lock()
+	defer t.fake.lock.Unlock()
+	delete(t.fake.tickers, t)
+}
@@ -78,10 +78,34 @@ import (
 	"go.uber.org/multierr"
 
 	"github.com/signalfx/splunk-otel-collector/internal/exporter/httpsinkexporter"
+	"github.com/signalfx/splunk-otel-collector/internal/exporter/otlparchiveexporter"
 	"github.com/signalfx/splunk-otel-collector/internal/exporter/pulsarexporter"
+	"github.com/signalfx/splunk-otel-collector/internal/exporter/signalfxdimensionexporter"
+	"github.com/signalfx/splunk-otel-collector/internal/exporter/splunkhecindexqueueexporter"
+	"github.com/signalfx/splunk-otel-collector/internal/exporter/splunkloadbalancingexporter"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/privilegecheckextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/queuehealthextension"
 	"github.com/signalfx/splunk-otel-collector/internal/extension/smartagentextension"
+	"github.com/signalfx/splunk-otel-collector/internal/extension/tokenauthextension"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/cardinalitylimiterprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/dataclassificationprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/hostdetailsprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/lagguardprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/linebreakingprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/logmetricsprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/logsamplingprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/pseudonymizationprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/signalfxeventprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/splunkroutingprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/timestampprocessor"
+	"github.com/signalfx/splunk-otel-collector/internal/processor/tokensanitizerprocessor"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/databricksreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/ibmmqreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/mongodbatlasalertsreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/nagiosreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/signalfxdimensionreceiver"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/snmptrapreceiver"
 )
 
 func Get() (component.Factories, error) {
@@ -96,7 +120,10 @@ func Get() (component.Factories, error) {
 		httpforwarder.NewFactory(),
 		k8sobserver.NewFactory(),
 		pprofextension.NewFactory(),
+		privilegecheckextension.NewFactory(),
+		queuehealthextension.NewFactory(),
 		smartagentextension.NewFactory(),
+		tokenauthextension.NewFactory(),
 		zpagesextension.NewFactory(),
 		ballastextension.NewFactory(),
 	)
@@ -112,6 +139,7 @@ func Get() (component.Factories, error) {
 		fluentforwardreceiver.NewFactory(),
 		filelogreceiver.NewFactory(),
 		hostmetricsreceiver.NewFactory(),
+		ibmmqreceiver.NewFactory(),
 		jaegerreceiver.NewFactory(),
 		journaldreceiver.NewFactory(),
 		k8sclusterreceiver.NewFactory(),
@@ -120,14 +148,18 @@ func Get() (component.Factories, error) {
 		kafkareceiver.NewFactory(),
 		kubeletstatsreceiver.NewFactory(),
 		mongodbatlasreceiver.NewFactory(),
+		mongodbatlasalertsreceiver.NewFactory(),
+		nagiosreceiver.NewFactory(),
 		otlpreceiver.NewFactory(),
 		prometheusexecreceiver.NewFactory(),
 		prometheusreceiver.NewFactory(),
 		receivercreator.NewFactory(),
 		sapmreceiver.NewFactory(),
 		signalfxreceiver.NewFactory(),
+		signalfxdimensionreceiver.NewFactory(),
 		simpleprometheusreceiver.NewFactory(),
 		smartagentreceiver.NewFactory(),
+		snmptrapreceiver.NewFactory(),
 		splunkhecreceiver.NewFactory(),
 		statsdreceiver.NewFactory(),
 		syslogreceiver.NewFactory(),
@@ -148,7 +180,11 @@ func Get() (component.Factories, error) {
 		signalfxexporter.NewFactory(),
 		splunkhecexporter.NewFactory(),
 		httpsinkexporter.NewFactory(),
+		otlparchiveexporter.NewFactory(),
 		pulsarexporter.NewFactory(),
+		signalfxdimensionexporter.NewFactory(),
+		splunkhecindexqueueexporter.NewFactory(),
+		splunkloadbalancingexporter.NewFactory(),
 	)
 	if err != nil {
 		errs = append(errs, err)
@@ -157,16 +193,28 @@ func Get() (component.Factories, error) {
 	processors, err := component.MakeProcessorFactoryMap(
 		attributesprocessor.NewFactory(),
 		batchprocessor.NewFactory(),
+		cardinalitylimiterprocessor.NewFactory(),
+		dataclassificationprocessor.NewFactory(),
 		filterprocessor.NewFactory(),
 		groupbyattrsprocessor.NewFactory(),
+		hostdetailsprocessor.NewFactory(),
 		k8sattributesprocessor.NewFactory(),
+		lagguardprocessor.NewFactory(),
+		linebreakingprocessor.NewFactory(),
+		logmetricsprocessor.NewFactory(),
+		logsamplingprocessor.NewFactory(),
 		memorylimiterprocessor.NewFactory(),
 		metricstransformprocessor.NewFactory(),
 		probabilisticsamplerprocessor.NewFactory(),
+		pseudonymizationprocessor.NewFactory(),
 		resourcedetectionprocessor.NewFactory(),
 		resourceprocessor.NewFactory(),
 		routingprocessor.NewFactory(),
+		signalfxeventprocessor.NewFactory(),
 		spanprocessor.NewFactory(),
+		splunkroutingprocessor.NewFactory(),
+		timestampprocessor.NewFactory(),
+		tokensanitizerprocessor.NewFactory(),
 		transformprocessor.NewFactory(),
 	)
 	if err != nil {
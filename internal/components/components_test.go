@@ -34,7 +34,10 @@ func TestDefaultComponents(t *testing.T) {
 		"http_forwarder",
 		"k8s_observer",
 		"pprof",
+		"privilege_check",
+		"queue_health",
 		"smartagent",
+		"token_auth",
 		"zpages",
 		"memory_ballast",
 		"file_storage",
@@ -47,6 +50,7 @@ func TestDefaultComponents(t *testing.T) {
 		"filelog",
 		"fluentforward",
 		"hostmetrics",
+		"ibmmq",
 		"jaeger",
 		"journald",
 		"k8s_cluster",
@@ -55,6 +59,8 @@ func TestDefaultComponents(t *testing.T) {
 		"kafkametrics",
 		"kubeletstats",
 		"mongodbatlas",
+		"mongodbatlas_alerts",
+		"nagios",
 		"otlp",
 		"prometheus",
 		"prometheus_exec",
@@ -62,7 +68,9 @@ func TestDefaultComponents(t *testing.T) {
 		"receiver_creator",
 		"sapm",
 		"signalfx",
+		"signalfx_dimension",
 		"smartagent",
+		"snmp_trap",
 		"splunk_hec",
 		"statsd",
 		"syslog",
@@ -72,16 +80,28 @@ func TestDefaultComponents(t *testing.T) {
 	expectedProcessors := []config.Type{
 		"attributes",
 		"batch",
+		"cardinality_limiter",
+		"data_classification",
 		"filter",
 		"groupbyattrs",
+		"host_details",
 		"k8sattributes",
+		"lag_guard",
+		"line_breaking",
+		"log_metrics",
+		"log_sampling",
 		"memory_limiter",
 		"metricstransform",
 		"probabilistic_sampler",
+		"pseudonymization",
 		"resource",
 		"resourcedetection",
 		"routing",
+		"signalfx_event",
 		"span",
+		"splunk_routing",
+		"timestamp",
+		"token_sanitizer",
 		"transform",
 	}
 	expectedExporters := []config.Type{
@@ -90,10 +110,14 @@ func TestDefaultComponents(t *testing.T) {
 		"logging",
 		"otlp",
 		"otlphttp",
+		"otlparchive",
 		"pulsar",
 		"sapm",
 		"signalfx",
+		"signalfx_dimension",
 		"splunk_hec",
+		"splunk_hec_index_queue",
+		"splunk_loadbalancing",
 		"httpsink",
 	}
 
@@ -0,0 +1,310 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+// Stability levels of the bundled components, as documented in docs/components.md.
+const (
+	StabilityBeta         = "beta"
+	StabilityAlpha        = "alpha"
+	StabilityExperimental = "experimental"
+	StabilityUndefined    = "undefined"
+)
+
+// maxConfigDepth bounds the nesting of described config fields, guarding against recursive types.
+const maxConfigDepth = 10
+
+var (
+	receiverStability = map[config.Type]string{
+		"carbon":              StabilityAlpha,
+		"cloudfoundry":        StabilityAlpha,
+		"collectd":            StabilityAlpha,
+		"databricks":          StabilityAlpha,
+		"filelog":             StabilityAlpha,
+		"fluentforward":       StabilityBeta,
+		"hostmetrics":         StabilityBeta,
+		"ibmmq":               StabilityAlpha,
+		"jaeger":              StabilityBeta,
+		"journald":            StabilityAlpha,
+		"k8s_cluster":         StabilityBeta,
+		"k8s_events":          StabilityAlpha,
+		"kafka":               StabilityAlpha,
+		"kafkametrics":        StabilityAlpha,
+		"kubeletstats":        StabilityBeta,
+		"mongodbatlas":        StabilityAlpha,
+		"mongodbatlas_alerts": StabilityAlpha,
+		"nagios":              StabilityAlpha,
+		"otlp":                StabilityBeta,
+		"prometheus_simple":   StabilityBeta,
+		"receiver_creator":    StabilityBeta,
+		"sapm":                StabilityBeta,
+		"signalfx":            StabilityBeta,
+		"signalfx_dimension":  StabilityAlpha,
+		"smartagent":          StabilityBeta,
+		"snmp_trap":           StabilityAlpha,
+		"splunk_hec":          StabilityBeta,
+		"statsd":              StabilityAlpha,
+		"syslog":              StabilityAlpha,
+		"tcplog":              StabilityAlpha,
+		"zipkin":              StabilityBeta,
+	}
+	processorStability = map[config.Type]string{
+		"attributes":            StabilityBeta,
+		"batch":                 StabilityBeta,
+		"cardinality_limiter":   StabilityAlpha,
+		"data_classification":   StabilityAlpha,
+		"filter":                StabilityBeta,
+		"groupbyattrs":          StabilityBeta,
+		"host_details":          StabilityAlpha,
+		"k8sattributes":         StabilityBeta,
+		"lag_guard":             StabilityAlpha,
+		"line_breaking":         StabilityAlpha,
+		"log_metrics":           StabilityAlpha,
+		"log_sampling":          StabilityAlpha,
+		"memory_limiter":        StabilityBeta,
+		"metricstransform":      StabilityBeta,
+		"probabilistic_sampler": StabilityBeta,
+		"pseudonymization":      StabilityAlpha,
+		"resource":              StabilityBeta,
+		"resourcedetection":     StabilityBeta,
+		"routing":               StabilityBeta,
+		"signalfx_event":        StabilityAlpha,
+		"span":                  StabilityBeta,
+		"splunk_routing":        StabilityAlpha,
+		"timestamp":             StabilityAlpha,
+		"token_sanitizer":       StabilityAlpha,
+		"transform":             StabilityAlpha,
+	}
+	exporterStability = map[config.Type]string{
+		"file":                   StabilityBeta,
+		"kafka":                  StabilityAlpha,
+		"logging":                StabilityBeta,
+		"otlp":                   StabilityBeta,
+		"otlparchive":            StabilityAlpha,
+		"otlphttp":               StabilityBeta,
+		"pulsar":                 StabilityExperimental,
+		"sapm":                   StabilityBeta,
+		"signalfx":               StabilityBeta,
+		"signalfx_dimension":     StabilityAlpha,
+		"splunk_hec":             StabilityBeta,
+		"splunk_hec_index_queue": StabilityAlpha,
+		"splunk_loadbalancing":   StabilityAlpha,
+	}
+	extensionStability = map[config.Type]string{
+		"docker_observer":   StabilityBeta,
+		"ecs_observer":      StabilityBeta,
+		"ecs_task_observer": StabilityAlpha,
+		"file_storage":      StabilityAlpha,
+		"health_check":      StabilityBeta,
+		"host_observer":     StabilityBeta,
+		"http_forwarder":    StabilityBeta,
+		"k8s_observer":      StabilityBeta,
+		"pprof":             StabilityBeta,
+		"privilege_check":   StabilityAlpha,
+		"queue_health":      StabilityAlpha,
+		"smartagent":        StabilityBeta,
+		"token_auth":        StabilityAlpha,
+		"zpages":            StabilityBeta,
+	}
+)
+
+// Inventory is a machine-readable description of the bundled components, allowing tooling
+// to validate configs against the components of a specific Collector version.
+type Inventory struct {
+	Version    string               `json:"version"`
+	Receivers  []ComponentInventory `json:"receivers"`
+	Processors []ComponentInventory `json:"processors"`
+	Exporters  []ComponentInventory `json:"exporters"`
+	Extensions []ComponentInventory `json:"extensions"`
+}
+
+// ComponentInventory describes a component's stability and config schema.
+type ComponentInventory struct {
+	Type      config.Type   `json:"type"`
+	Stability string        `json:"stability"`
+	Config    []ConfigField `json:"config"`
+}
+
+// ConfigField describes a config field by its key, derived from the config struct's mapstructure tags.
+// Default is the value of the component's default config, if not the zero value.
+type ConfigField struct {
+	Name    string        `json:"name"`
+	Type    string        `json:"type"`
+	Default any           `json:"default,omitempty"`
+	Fields  []ConfigField `json:"fields,omitempty"`
+}
+
+// NewInventory describes the provided factories, sorted by type.
+func NewInventory(factories component.Factories, version string) Inventory {
+	inventory := Inventory{
+		Version:    version,
+		Receivers:  []ComponentInventory{},
+		Processors: []ComponentInventory{},
+		Exporters:  []ComponentInventory{},
+		Extensions: []ComponentInventory{},
+	}
+	for t, f := range factories.Receivers {
+		inventory.Receivers = append(inventory.Receivers, newComponentInventory(t, receiverStability, f.CreateDefaultConfig()))
+	}
+	for t, f := range factories.Processors {
+		inventory.Processors = append(inventory.Processors, newComponentInventory(t, processorStability, f.CreateDefaultConfig()))
+	}
+	for t, f := range factories.Exporters {
+		inventory.Exporters = append(inventory.Exporters, newComponentInventory(t, exporterStability, f.CreateDefaultConfig()))
+	}
+	for t, f := range factories.Extensions {
+		inventory.Extensions = append(inventory.Extensions, newComponentInventory(t, extensionStability, f.CreateDefaultConfig()))
+	}
+	for _, components := range [][]ComponentInventory{
+		inventory.Receivers, inventory.Processors, inventory.Exporters, inventory.Extensions,
+	} {
+		sort.Slice(components, func(i, j int) bool { return components[i].Type < components[j].Type })
+	}
+	return inventory
+}
+
+func newComponentInventory(componentType config.Type, stabilities map[config.Type]string, defaultConfig any) ComponentInventory {
+	stability, ok := stabilities[componentType]
+	if !ok {
+		stability = StabilityUndefined
+	}
+	return ComponentInventory{
+		Type:      componentType,
+		Stability: stability,
+		Config:    configFields(reflect.ValueOf(defaultConfig), 0),
+	}
+}
+
+var durationType = reflect.TypeOf(time.Duration(0))
+
+// configFields describes the fields of the provided struct value, flattening squashed ones.
+func configFields(v reflect.Value, depth int) []ConfigField {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			if v.Kind() == reflect.Interface {
+				return nil
+			}
+			v = reflect.Zero(v.Type().Elem())
+			continue
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct || depth > maxConfigDepth {
+		return nil
+	}
+
+	fields := []ConfigField{}
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(sf.Tag.Get("mapstructure"), ",")
+		if name == "-" {
+			continue
+		}
+		if strings.Contains(options, "squash") {
+			fields = append(fields, configFields(v.Field(i), depth)...)
+			continue
+		}
+		if name == "" {
+			// mapstructure matches untagged fields by their case-insensitive name
+			name = strings.ToLower(sf.Name)
+		}
+		fields = append(fields, configField(name, v.Field(i), depth))
+	}
+	return fields
+}
+
+func configField(name string, v reflect.Value, depth int) ConfigField {
+	field := ConfigField{Name: name}
+	elem := v
+	for elem.Kind() == reflect.Pointer && !elem.IsNil() {
+		elem = elem.Elem()
+	}
+	t := elem.Type()
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch {
+	case t == durationType:
+		field.Type = "duration"
+		if elem.Kind() != reflect.Pointer && !elem.IsZero() {
+			field.Default = time.Duration(elem.Int()).String()
+		}
+		return field
+	case t.Kind() == reflect.Struct:
+		field.Type = "object"
+		field.Fields = configFields(v, depth+1)
+		return field
+	}
+
+	field.Type = kindName(t)
+	if elem.Kind() != reflect.Pointer && !elem.IsZero() && isScalarOrCollection(t) {
+		field.Default = elem.Interface()
+	}
+	return field
+}
+
+func kindName(t reflect.Type) string {
+	switch t.Kind() {
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "int"
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "uint"
+	case reflect.Float32, reflect.Float64:
+		return "float"
+	case reflect.String:
+		return "string"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	case reflect.Map:
+		return "map"
+	}
+	return "any"
+}
+
+// isScalarOrCollection determines whether values of the type are scalars, or lists or maps of them,
+// that can be provided as defaults.
+func isScalarOrCollection(t reflect.Type) bool {
+	switch t.Kind() {
+	case reflect.Slice, reflect.Array:
+		return isScalar(t.Elem())
+	case reflect.Map:
+		return t.Key().Kind() == reflect.String && isScalar(t.Elem())
+	}
+	return isScalar(t)
+}
+
+func isScalar(t reflect.Type) bool {
+	switch kindName(t) {
+	case "any", "list", "map":
+		return false
+	}
+	return true
+}
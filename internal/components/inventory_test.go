@@ -0,0 +1,125 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package components
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+)
+
+func TestStabilitiesAreForBundledComponents(t *testing.T) {
+	factories, err := Get()
+	require.NoError(t, err)
+
+	for componentType := range receiverStability {
+		assert.Contains(t, factories.Receivers, componentType)
+	}
+	for componentType := range processorStability {
+		assert.Contains(t, factories.Processors, componentType)
+	}
+	for componentType := range exporterStability {
+		assert.Contains(t, factories.Exporters, componentType)
+	}
+	for componentType := range extensionStability {
+		assert.Contains(t, factories.Extensions, componentType)
+	}
+}
+
+func TestNewInventory(t *testing.T) {
+	factories, err := Get()
+	require.NoError(t, err)
+
+	inventory := NewInventory(factories, "v1.2.3")
+	assert.Equal(t, "v1.2.3", inventory.Version)
+	assert.Len(t, inventory.Receivers, len(factories.Receivers))
+	assert.Len(t, inventory.Processors, len(factories.Processors))
+	assert.Len(t, inventory.Exporters, len(factories.Exporters))
+	assert.Len(t, inventory.Extensions, len(factories.Extensions))
+
+	for _, components := range [][]ComponentInventory{
+		inventory.Receivers, inventory.Processors, inventory.Exporters, inventory.Extensions,
+	} {
+		for i := 1; i < len(components); i++ {
+			assert.Less(t, components[i-1].Type, components[i].Type)
+		}
+	}
+
+	var batch ComponentInventory
+	for _, p := range inventory.Processors {
+		if p.Type == "batch" {
+			batch = p
+		}
+	}
+	assert.Equal(t, StabilityBeta, batch.Stability)
+	assert.Contains(t, batch.Config, ConfigField{Name: "timeout", Type: "duration", Default: "200ms"})
+	assert.Contains(t, batch.Config, ConfigField{Name: "send_batch_size", Type: "uint", Default: uint32(8192)})
+
+	for _, e := range inventory.Exporters {
+		if e.Type == "httpsink" {
+			assert.Equal(t, StabilityUndefined, e.Stability)
+		}
+	}
+}
+
+type testNested struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// SquashedConfig is exported since mapstructure only squashes exported embedded structs.
+type SquashedConfig struct {
+	Endpoint string `mapstructure:"endpoint"`
+}
+
+type testConfig struct {
+	config.ProcessorSettings `mapstructure:",squash"`
+	SquashedConfig           `mapstructure:",squash"`
+	Nested                   testNested        `mapstructure:"nested"`
+	NilNested                *testNested       `mapstructure:"nil_nested"`
+	Timeout                  time.Duration     `mapstructure:"timeout"`
+	Names                    []string          `mapstructure:"names"`
+	Labels                   map[string]string `mapstructure:"labels"`
+	Raw                      map[string]any    `mapstructure:"raw"`
+	Untagged                 int
+	Ignored                  string `mapstructure:"-"`
+	unexported               string
+}
+
+func TestConfigFields(t *testing.T) {
+	cfg := &testConfig{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID("test")),
+		SquashedConfig:    SquashedConfig{Endpoint: "localhost:1234"},
+		Timeout:           time.Second,
+		Names:             []string{"a", "b"},
+		Raw:               map[string]any{"a": 1},
+		Ignored:           "ignored",
+		unexported:        "unexported",
+	}
+
+	assert.Equal(t, []ConfigField{
+		{Name: "endpoint", Type: "string", Default: "localhost:1234"},
+		{Name: "nested", Type: "object", Fields: []ConfigField{{Name: "enabled", Type: "bool"}}},
+		{Name: "nil_nested", Type: "object", Fields: []ConfigField{{Name: "enabled", Type: "bool"}}},
+		{Name: "timeout", Type: "duration", Default: "1s"},
+		{Name: "names", Type: "list", Default: []string{"a", "b"}},
+		{Name: "labels", Type: "map"},
+		{Name: "raw", Type: "map"},
+		{Name: "untagged", Type: "int"},
+	}, configFields(reflect.ValueOf(cfg), 0))
+}
@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	dockerHostEnvVar     = "DOCKER_HOST"
+	defaultDockerSocket  = "/var/run/docker.sock"
+	rootfulPodmanSocket  = "/run/podman/podman.sock"
+	rootlessPodmanSocket = "podman/podman.sock"
+)
+
+// containerdSockets are the sockets of containerd, whose API isn't compatible with Docker's.
+var containerdSockets = []string{
+	"/run/containerd/containerd.sock",
+	"/run/k3s/containerd/containerd.sock",
+}
+
+// DockerObserverEndpoint is a MapConverter that sets the endpoint of the docker_observer extensions used by the
+// service that don't configure one to the Docker API of the host's container runtime: the DOCKER_HOST env var if
+// set, otherwise the first existing socket of Docker, rootful Podman, and rootless Podman.  Hosts with only a
+// containerd socket, which doesn't serve the Docker API, are only reported, leaving the unusable default endpoint.
+type DockerObserverEndpoint struct {
+	// Sockets are the candidate socket paths, in order of preference, defaulting to those of Docker and Podman.
+	Sockets []string
+	// ContainerdSockets are the containerd socket paths, defaulting to those of containerd and k3s.
+	ContainerdSockets []string
+}
+
+func (d DockerObserverEndpoint) Convert(_ context.Context, cfgMap *confmap.Conf) error {
+	if cfgMap == nil {
+		return fmt.Errorf("cannot DockerObserverEndpoint on nil *confmap.Conf")
+	}
+
+	var observers []string
+	for _, ext := range serviceReferences(cfgMap, "service::extensions") {
+		if typ, _, _ := strings.Cut(ext, "/"); strings.TrimSpace(typ) != "docker_observer" {
+			continue
+		}
+		if endpoint, ok := cfgMap.Get(fmt.Sprintf("extensions::%s::endpoint", ext)).(string); ok && endpoint != "" {
+			continue
+		}
+		observers = append(observers, ext)
+	}
+	if len(observers) == 0 {
+		return nil
+	}
+
+	endpoint := d.endpoint()
+	if endpoint == "" || endpoint == "unix://"+defaultDockerSocket {
+		// the docker_observer default
+		return nil
+	}
+	patch := map[string]any{}
+	for _, ext := range observers {
+		patch[fmt.Sprintf("extensions::%s::endpoint", ext)] = endpoint
+	}
+	log.Printf("Setting the endpoint of %s to %s\n", strings.Join(observers, ", "), endpoint)
+	return cfgMap.Merge(confmap.NewFromStringMap(patch))
+}
+
+// endpoint returns the Docker API endpoint of the host's container runtime, or "" if none was found.
+func (d DockerObserverEndpoint) endpoint() string {
+	if host := os.Getenv(dockerHostEnvVar); host != "" {
+		return host
+	}
+
+	sockets := d.Sockets
+	if sockets == nil {
+		sockets = dockerAPISockets()
+	}
+	for _, socket := range sockets {
+		if isSocket(socket) {
+			return "unix://" + socket
+		}
+	}
+
+	containerd := d.ContainerdSockets
+	if containerd == nil {
+		containerd = containerdSockets
+	}
+	for _, socket := range containerd {
+		if isSocket(socket) {
+			log.Printf("[WARNING] Found the containerd socket %s but no Docker API socket. The docker_observer "+
+				"extension can't discover containerd containers: use the k8s_observer extension on Kubernetes nodes, "+
+				"or configure the endpoint of a Docker API compatible runtime.\n", socket)
+			break
+		}
+	}
+	return ""
+}
+
+// dockerAPISockets returns the default sockets of Docker, rootful Podman, and the collector user's rootless Podman.
+func dockerAPISockets() []string {
+	runtimeDir := os.Getenv("XDG_RUNTIME_DIR")
+	if runtimeDir == "" {
+		runtimeDir = filepath.Join("/run/user", strconv.Itoa(os.Getuid()))
+	}
+	return []string{defaultDockerSocket, rootfulPodmanSocket, filepath.Join(runtimeDir, rootlessPodmanSocket)}
+}
+
+func isSocket(path string) bool {
+	info, err := os.Stat(path)
+	return err == nil && info.Mode()&os.ModeSocket != 0
+}
+
+// serviceReferences returns the component ids referenced by the service list at the key.
+func serviceReferences(cfgMap *confmap.Conf, key string) []string {
+	refs, _ := cfgMap.Get(key).([]any)
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, fmt.Sprint(ref))
+	}
+	return ids
+}
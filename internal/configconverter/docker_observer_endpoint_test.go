@@ -0,0 +1,72 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func listenUnix(t *testing.T, path string) string {
+	listener, err := net.Listen("unix", path)
+	require.NoError(t, err)
+	t.Cleanup(func() { listener.Close() })
+	return path
+}
+
+func TestDockerObserverEndpoint(t *testing.T) {
+	dir := t.TempDir()
+	docker := filepath.Join(dir, "docker.sock")
+	podman := listenUnix(t, filepath.Join(dir, "podman.sock"))
+	containerd := listenUnix(t, filepath.Join(dir, "containerd.sock"))
+
+	for _, tt := range []struct {
+		name       string
+		dockerHost string
+		sockets    []string
+		expected   any
+	}{
+		{name: "podman socket", sockets: []string{docker, podman}, expected: "unix://" + podman},
+		{name: "docker host", dockerHost: "tcp://docker:2375", sockets: []string{podman}, expected: "tcp://docker:2375"},
+		{name: "no socket", sockets: []string{docker}, expected: nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("DOCKER_HOST", tt.dockerHost)
+			cfgMap, err := confmaptest.LoadConf("testdata/docker-observer.yaml")
+			require.NoError(t, err)
+			require.NotNil(t, cfgMap)
+
+			converter := DockerObserverEndpoint{Sockets: tt.sockets, ContainerdSockets: []string{containerd}}
+			require.NoError(t, converter.Convert(context.Background(), cfgMap))
+
+			assert.Equal(t, tt.expected, cfgMap.Get("extensions::docker_observer::endpoint"))
+			assert.Equal(t, tt.expected, cfgMap.Get("extensions::docker_observer/timeout::endpoint"))
+			assert.Equal(t, "10s", cfgMap.Get("extensions::docker_observer/timeout::timeout"))
+			assert.Equal(t, "tcp://localhost:2375", cfgMap.Get("extensions::docker_observer/custom::endpoint"))
+			assert.Nil(t, cfgMap.Get("extensions::docker_observer/unused"))
+			assert.Nil(t, cfgMap.Get("extensions::host_observer"))
+		})
+	}
+}
+
+func TestDockerObserverEndpointNilConf(t *testing.T) {
+	require.EqualError(t, DockerObserverEndpoint{}.Convert(context.Background(), nil), "cannot DockerObserverEndpoint on nil *confmap.Conf")
+}
@@ -0,0 +1,93 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	// DebugLoggingExporter is the logging exporter EnableDebug adds to every pipeline.
+	DebugLoggingExporter = "logging/debug"
+
+	defaultDebugPProfEndpoint  = "localhost:1777"
+	defaultDebugZPagesEndpoint = "localhost:55679"
+)
+
+// EnableDebug is a MapConverter that adds troubleshooting components to the effective config without
+// editing it: the pprof and zpages extensions, listening on localhost only, and a debug level logging
+// exporter in every pipeline.  Extensions already defined by the config keep their settings and are
+// only enabled in the service.
+type EnableDebug struct {
+	PProf   bool
+	ZPages  bool
+	Logging bool
+}
+
+func (ed EnableDebug) Convert(_ context.Context, cfgMap *confmap.Conf) error {
+	if cfgMap == nil {
+		return fmt.Errorf("cannot EnableDebug on nil *confmap.Conf")
+	}
+
+	patch := map[string]any{}
+	var extensions []string
+	if ed.PProf {
+		extensions = append(extensions, "pprof")
+		if !cfgMap.IsSet("extensions::pprof") {
+			patch["extensions::pprof::endpoint"] = defaultDebugPProfEndpoint
+		}
+	}
+	if ed.ZPages {
+		extensions = append(extensions, "zpages")
+		if !cfgMap.IsSet("extensions::zpages") {
+			patch["extensions::zpages::endpoint"] = defaultDebugZPagesEndpoint
+		}
+	}
+	if len(extensions) != 0 {
+		patch["service::extensions"] = appendMissing(cfgMap.Get("service::extensions"), extensions...)
+	}
+
+	if ed.Logging {
+		if !cfgMap.IsSet("exporters::" + DebugLoggingExporter) {
+			patch["exporters::"+DebugLoggingExporter+"::loglevel"] = "debug"
+		}
+		pipelines, _ := cfgMap.Get("service::pipelines").(map[string]any)
+		for pipeline := range pipelines {
+			key := fmt.Sprintf("service::pipelines::%s::exporters", pipeline)
+			patch[key] = appendMissing(cfgMap.Get(key), DebugLoggingExporter)
+		}
+	}
+
+	return cfgMap.Merge(confmap.NewFromStringMap(patch))
+}
+
+// appendMissing returns the items of the config list followed by the ids it doesn't already contain.
+func appendMissing(list any, ids ...string) []any {
+	items, _ := list.([]any)
+	out := append([]any{}, items...)
+	present := map[any]bool{}
+	for _, item := range items {
+		present[item] = true
+	}
+	for _, id := range ids {
+		if !present[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
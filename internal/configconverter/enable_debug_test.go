@@ -0,0 +1,75 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestEnableDebug(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/debug.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	err = EnableDebug{PProf: true, ZPages: true, Logging: true}.Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	// configured extensions keep their settings
+	assert.Equal(t, "0.0.0.0:1777", cfgMap.Get("extensions::pprof::endpoint"))
+	assert.Equal(t, "localhost:55679", cfgMap.Get("extensions::zpages::endpoint"))
+	assert.Equal(t, []any{"health_check", "pprof", "zpages"}, cfgMap.Get("service::extensions"))
+
+	assert.Equal(t, "info", cfgMap.Get("exporters::logging/debug::loglevel"))
+	assert.Equal(t, []any{"signalfx", "logging/debug"}, cfgMap.Get("service::pipelines::metrics::exporters"))
+	assert.Equal(t, []any{"signalfx", "logging/debug"}, cfgMap.Get("service::pipelines::traces::exporters"))
+	assert.Equal(t, []any{"otlp"}, cfgMap.Get("service::pipelines::metrics::receivers"))
+}
+
+func TestEnableDebugDefaults(t *testing.T) {
+	cfgMap := confmap.NewFromStringMap(map[string]any{
+		"exporters::signalfx::realm":             "us0",
+		"service::pipelines::logs::exporters":    []any{"signalfx"},
+		"service::pipelines::metrics::receivers": []any{"otlp"},
+	})
+
+	err := EnableDebug{PProf: true, Logging: true}.Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	assert.Equal(t, "localhost:1777", cfgMap.Get("extensions::pprof::endpoint"))
+	assert.False(t, cfgMap.IsSet("extensions::zpages"))
+	assert.Equal(t, []any{"pprof"}, cfgMap.Get("service::extensions"))
+	assert.Equal(t, "debug", cfgMap.Get("exporters::logging/debug::loglevel"))
+	assert.Equal(t, []any{"signalfx", "logging/debug"}, cfgMap.Get("service::pipelines::logs::exporters"))
+	assert.Equal(t, []any{"logging/debug"}, cfgMap.Get("service::pipelines::metrics::exporters"))
+}
+
+func TestEnableDebugNothingEnabled(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/debug.yaml")
+	require.NoError(t, err)
+	expected := cfgMap.ToStringMap()
+
+	require.NoError(t, EnableDebug{}.Convert(context.Background(), cfgMap))
+	assert.Equal(t, expected, cfgMap.ToStringMap())
+}
+
+func TestEnableDebugNilConf(t *testing.T) {
+	require.EqualError(t, EnableDebug{}.Convert(context.Background(), nil), "cannot EnableDebug on nil *confmap.Conf")
+}
@@ -0,0 +1,176 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+)
+
+var _ confmap.Provider = (*LayeredProvider)(nil)
+
+// Layer is a config map merged with the others of a LayeredProvider.
+type Layer interface {
+	// Name identifies the layer in errors.
+	Name() string
+	// Retrieve returns the layer config map, which is nil if the layer doesn't apply. The watcher is
+	// notified of changes to the layer config, if supported.
+	Retrieve(ctx context.Context, watcher confmap.WatcherFunc) (*confmap.Conf, error)
+	// Close releases the resources of the last retrieval.
+	Close(ctx context.Context) error
+}
+
+// LayeredProvider is a confmap.Provider that merges the config retrieved by the wrapped
+// provider from the first config location over those of its layers, merged in order over
+// each other. The base defaults and env overlays of a distribution are the first layers,
+// with the user config always taking precedence.
+type LayeredProvider struct {
+	wrapped       confmap.Provider
+	firstLocation string
+	layers        []Layer
+}
+
+// NewLayeredProvider returns a LayeredProvider merging the config retrieved by the wrapped
+// provider from the first of the config locations over the layers. The configs of the other
+// locations are retrieved as is, so that the layers are applied once, under all locations,
+// when the collector merges the configs of the locations in order.
+func NewLayeredProvider(wrapped confmap.Provider, firstLocation string, layers ...Layer) *LayeredProvider {
+	return &LayeredProvider{wrapped: wrapped, firstLocation: firstLocation, layers: layers}
+}
+
+func (lp *LayeredProvider) Retrieve(ctx context.Context, location string, watcher confmap.WatcherFunc) (confmap.Retrieved, error) {
+	if location != lp.firstLocation {
+		return lp.wrapped.Retrieve(ctx, location, watcher)
+	}
+
+	merged := confmap.New()
+	var closers []func(context.Context) error
+	closeAll := func(ctx context.Context) error {
+		var errs error
+		for _, closer := range closers {
+			errs = multierr.Append(errs, closer(ctx))
+		}
+		return errs
+	}
+
+	for _, layer := range lp.layers {
+		conf, err := layer.Retrieve(ctx, watcher)
+		if err != nil {
+			return confmap.Retrieved{}, multierr.Append(
+				fmt.Errorf("failed retrieving the %s config layer: %w", layer.Name(), err), closeAll(ctx),
+			)
+		}
+		closers = append(closers, layer.Close)
+		if conf == nil {
+			continue
+		}
+		if err = merged.Merge(conf); err != nil {
+			return confmap.Retrieved{}, multierr.Append(
+				fmt.Errorf("failed merging the %s config layer: %w", layer.Name(), err), closeAll(ctx),
+			)
+		}
+	}
+
+	retrieved, err := lp.wrapped.Retrieve(ctx, location, watcher)
+	if err != nil {
+		return confmap.Retrieved{}, multierr.Append(err, closeAll(ctx))
+	}
+	closers = append(closers, retrieved.Close)
+	conf, err := retrieved.AsConf()
+	if err == nil {
+		err = merged.Merge(conf)
+	}
+	if err != nil {
+		return confmap.Retrieved{}, multierr.Append(err, closeAll(ctx))
+	}
+	return confmap.NewRetrieved(merged.ToStringMap(), confmap.WithRetrievedClose(closeAll))
+}
+
+// Layers returns the layers merged under the config of the first location, in order.
+func (lp *LayeredProvider) Layers() []Layer {
+	return lp.layers
+}
+
+func (lp *LayeredProvider) Scheme() string {
+	return lp.wrapped.Scheme()
+}
+
+func (lp *LayeredProvider) Shutdown(ctx context.Context) error {
+	return lp.wrapped.Shutdown(ctx)
+}
+
+type mapLayer struct {
+	conf *confmap.Conf
+	name string
+}
+
+// NewMapLayer returns a layer of the provided config map, e.g. the base defaults of a distribution.
+func NewMapLayer(name string, cfg map[string]any) Layer {
+	return mapLayer{name: name, conf: confmap.NewFromStringMap(cfg)}
+}
+
+func (ml mapLayer) Name() string {
+	return ml.name
+}
+
+func (ml mapLayer) Retrieve(context.Context, confmap.WatcherFunc) (*confmap.Conf, error) {
+	// the layer conf is copied so merging into it can't change the layer
+	return confmap.NewFromStringMap(ml.conf.ToStringMap()), nil
+}
+
+func (mapLayer) Close(context.Context) error {
+	return nil
+}
+
+// providerLayer is a layer retrieved from a location by a confmap.Provider, e.g. an env var with
+// the envprovider or a file with the fileprovider.
+type providerLayer struct {
+	provider  confmap.Provider
+	retrieved confmap.Retrieved
+	location  func() (string, bool)
+	name      string
+}
+
+// NewProviderLayer returns a layer retrieved by the provider from the location returned by the
+// location func, if any. The location is evaluated on each retrieval so that layers, like env
+// overlays, only apply when configured.
+func NewProviderLayer(name string, provider confmap.Provider, location func() (string, bool)) Layer {
+	return &providerLayer{name: name, provider: provider, location: location}
+}
+
+func (pl *providerLayer) Name() string {
+	return pl.name
+}
+
+func (pl *providerLayer) Retrieve(ctx context.Context, watcher confmap.WatcherFunc) (*confmap.Conf, error) {
+	pl.retrieved = confmap.Retrieved{}
+	location, ok := pl.location()
+	if !ok {
+		return nil, nil
+	}
+	retrieved, err := pl.provider.Retrieve(ctx, location, watcher)
+	if err != nil {
+		return nil, err
+	}
+	pl.retrieved = retrieved
+	return retrieved.AsConf()
+}
+
+func (pl *providerLayer) Close(ctx context.Context) error {
+	return pl.retrieved.Close(ctx)
+}
@@ -0,0 +1,174 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+)
+
+const overlayEnvVar = "LAYERED_PROVIDER_TEST_OVERLAY_YAML"
+
+func envOverlay() Layer {
+	return NewProviderLayer("env overlay", envprovider.New(), func() (string, bool) {
+		if _, ok := os.LookupEnv(overlayEnvVar); !ok {
+			return "", false
+		}
+		return "env:" + overlayEnvVar, true
+	})
+}
+
+func baseDefaults() Layer {
+	return NewMapLayer("base defaults", map[string]any{
+		"exporters": map[string]any{
+			"signalfx": map[string]any{
+				"realm":                "us0",
+				"sync_host_metadata":   true,
+				"send_otlp_histograms": false,
+			},
+		},
+	})
+}
+
+func TestLayeredProvider(t *testing.T) {
+	t.Setenv(overlayEnvVar, `
+exporters:
+  signalfx:
+    realm: eu0
+    send_otlp_histograms: true
+processors:
+  batch:
+`)
+	lp := NewLayeredProvider(fileprovider.New(), "file:testdata/layered-user.yaml", baseDefaults(), envOverlay())
+	assert.Equal(t, "file", lp.Scheme())
+
+	retrieved, err := lp.Retrieve(context.Background(), "file:testdata/layered-user.yaml", nil)
+	require.NoError(t, err)
+	conf, err := retrieved.AsConf()
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"receivers": map[string]any{
+			"otlp": map[string]any{"protocols": map[string]any{"grpc": map[string]any{"endpoint": "0.0.0.0:4317"}}},
+		},
+		"processors": map[string]any{"batch": nil},
+		"exporters": map[string]any{
+			"signalfx": map[string]any{
+				// the user config takes precedence over the layers, and later layers over earlier ones
+				"realm":                "us1",
+				"access_token":         "user-token",
+				"sync_host_metadata":   true,
+				"send_otlp_histograms": true,
+			},
+		},
+	}, conf.ToStringMap())
+	require.NoError(t, retrieved.Close(context.Background()))
+	require.NoError(t, lp.Shutdown(context.Background()))
+}
+
+func TestLayeredProviderSkipsLayersNotApplying(t *testing.T) {
+	lp := NewLayeredProvider(fileprovider.New(), "file:testdata/layered-user.yaml", envOverlay())
+	retrieved, err := lp.Retrieve(context.Background(), "file:testdata/layered-user.yaml", nil)
+	require.NoError(t, err)
+	conf, err := retrieved.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "us1", conf.Get("exporters::signalfx::realm"))
+	assert.False(t, conf.IsSet("exporters::signalfx::sync_host_metadata"))
+}
+
+type fakeLayer struct {
+	err    error
+	closed int
+}
+
+func (*fakeLayer) Name() string {
+	return "fake"
+}
+
+func (fl *fakeLayer) Retrieve(context.Context, confmap.WatcherFunc) (*confmap.Conf, error) {
+	if fl.err != nil {
+		return nil, fl.err
+	}
+	return confmap.NewFromStringMap(map[string]any{"extensions": map[string]any{"health_check": nil}}), nil
+}
+
+func (fl *fakeLayer) Close(context.Context) error {
+	fl.closed++
+	return nil
+}
+
+func TestLayeredProviderClosesLayers(t *testing.T) {
+	layer := &fakeLayer{}
+	lp := NewLayeredProvider(fileprovider.New(), "file:testdata/layered-user.yaml", layer)
+	retrieved, err := lp.Retrieve(context.Background(), "file:testdata/layered-user.yaml", nil)
+	require.NoError(t, err)
+	conf, err := retrieved.AsConf()
+	require.NoError(t, err)
+	assert.True(t, conf.IsSet("extensions::health_check"))
+	assert.Zero(t, layer.closed)
+	require.NoError(t, retrieved.Close(context.Background()))
+	assert.Equal(t, 1, layer.closed)
+
+	// layers are closed when the user config can't be retrieved
+	lp = NewLayeredProvider(fileprovider.New(), "file:testdata/missing.yaml", layer)
+	_, err = lp.Retrieve(context.Background(), "file:testdata/missing.yaml", nil)
+	require.Error(t, err)
+	assert.Equal(t, 2, layer.closed)
+}
+
+func TestLayeredProviderAppliesLayersOnce(t *testing.T) {
+	t.Setenv(overlayEnvVar, `
+exporters:
+  signalfx:
+    realm: eu0
+`)
+	const first, second = "file:testdata/layered-user.yaml", "file:testdata/layered-second.yaml"
+	lp := NewLayeredProvider(fileprovider.New(), first, baseDefaults(), envOverlay())
+
+	// the configs of the other locations are retrieved without the layers, which would otherwise
+	// take precedence over the earlier locations when merged over them
+	retrieved, err := lp.Retrieve(context.Background(), second, nil)
+	require.NoError(t, err)
+	conf, err := retrieved.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{"signalfx": map[string]any{"access_token": "second-token"}},
+	}, conf.ToStringMap())
+
+	retrieved, err = lp.Retrieve(context.Background(), first, nil)
+	require.NoError(t, err)
+	conf, err = retrieved.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "us1", conf.Get("exporters::signalfx::realm"))
+	assert.Equal(t, true, conf.Get("exporters::signalfx::sync_host_metadata"))
+}
+
+func TestLayeredProviderLayerError(t *testing.T) {
+	closed := &fakeLayer{}
+	failing := &fakeLayer{err: errors.New("unavailable")}
+	lp := NewLayeredProvider(fileprovider.New(), "file:testdata/layered-user.yaml", closed, failing)
+	_, err := lp.Retrieve(context.Background(), "file:testdata/layered-user.yaml", nil)
+	require.EqualError(t, err, "failed retrieving the fake config layer: unavailable")
+	assert.Equal(t, 1, closed.closed)
+	assert.Zero(t, failing.closed)
+}
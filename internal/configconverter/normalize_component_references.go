@@ -0,0 +1,249 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/multierr"
+)
+
+// componentKinds are the config sections of the components, and the singular names of their kind.
+var componentKinds = []struct{ section, name string }{
+	{"receivers", "receiver"},
+	{"processors", "processor"},
+	{"exporters", "exporter"},
+	{"extensions", "extension"},
+}
+
+// NormalizeComponentReferences is a MapConverter that checks the references of the service to the
+// components of the merged config before the collector builds its pipelines, returning actionable errors
+// with suggestions for components defined more than once, like `otlp/a` and `otlp/ a` in different
+// config sources, and for references to undefined components, like typos.  Repeated references to the
+// same receiver or exporter in a pipeline, or extension in the service, are removed, and components that
+// aren't used by the service, which the collector doesn't start, are logged.
+type NormalizeComponentReferences struct{}
+
+func (NormalizeComponentReferences) Convert(_ context.Context, cfgMap *confmap.Conf) error {
+	if cfgMap == nil {
+		return fmt.Errorf("cannot NormalizeComponentReferences on nil *confmap.Conf")
+	}
+
+	var errs error
+	defined := map[string]map[string]string{}
+	for _, kind := range componentKinds {
+		ids, err := definedIDs(kind.section, kind.name, cfgMap.Get(kind.section))
+		errs = multierr.Append(errs, err)
+		defined[kind.section] = ids
+	}
+	_, err := definedIDs("service::pipelines", "pipeline", cfgMap.Get("service::pipelines"))
+	errs = multierr.Append(errs, err)
+
+	// the references are checked once all are known, so that suggestions can prefer unused components
+	type reference struct{ referrer, section, ref string }
+	var references []reference
+	used := map[string]map[string]bool{}
+	for _, kind := range componentKinds {
+		used[kind.section] = map[string]bool{}
+	}
+	patch := map[string]any{}
+	addReferences := func(key, referrer, section string, dedupe bool) {
+		refs, _ := cfgMap.Get(key).([]any)
+		if dedupe {
+			if deduped := dedupeReferences(refs); len(deduped) != len(refs) {
+				log.Printf("Removing repeated references from %s\n", key)
+				patch[key] = deduped
+				refs = deduped
+			}
+		}
+		for _, ref := range refs {
+			references = append(references, reference{referrer: referrer, section: section, ref: fmt.Sprint(ref)})
+			used[section][normalizeComponentID(fmt.Sprint(ref))] = true
+		}
+	}
+
+	addReferences("service::extensions", "service::extensions", "extensions", true)
+	pipelines, _ := cfgMap.Get("service::pipelines").(map[string]any)
+	for _, pipeline := range sortedKeys(pipelines) {
+		for _, kind := range componentKinds[:3] {
+			key := fmt.Sprintf("service::pipelines::%s::%s", pipeline, kind.section)
+			// repeated processors are left for the collector to reject, since their order matters
+			addReferences(key, fmt.Sprintf("pipeline %q", pipeline), kind.section, kind.section != "processors")
+		}
+	}
+	for _, r := range references {
+		if _, ok := defined[r.section][normalizeComponentID(r.ref)]; !ok {
+			errs = multierr.Append(errs, undefinedReferenceError(r.referrer, r.section, r.ref, defined, used))
+		}
+	}
+	if errs != nil {
+		return errs
+	}
+
+	var unused []string
+	for _, kind := range componentKinds {
+		for _, id := range sortedIDs(defined[kind.section]) {
+			if !used[kind.section][id] {
+				unused = append(unused, kind.section+"::"+defined[kind.section][id])
+			}
+		}
+	}
+	if len(unused) != 0 {
+		log.Printf("Components defined but not used by the service, which aren't started: %s\n", strings.Join(unused, ", "))
+	}
+
+	return cfgMap.Merge(confmap.NewFromStringMap(patch))
+}
+
+// definedIDs returns the keys of the section by their normalized component ids, with an error for each
+// id defined by more than one key.
+func definedIDs(section, kind string, components any) (map[string]string, error) {
+	componentsMap, _ := components.(map[string]any)
+	ids := map[string]string{}
+	var errs error
+	for _, key := range sortedKeys(componentsMap) {
+		id := normalizeComponentID(key)
+		if previous, ok := ids[id]; ok {
+			errs = multierr.Append(errs, fmt.Errorf(
+				"%s %q and %q both define the %s %q, likely from different config sources: rename or remove one of them",
+				section, previous, key, kind, id,
+			))
+			continue
+		}
+		ids[id] = key
+	}
+	return ids, errs
+}
+
+// normalizeComponentID returns the id the collector parses from a component key or reference, whose
+// type and name are trimmed of whitespace.
+func normalizeComponentID(key string) string {
+	typ, name, found := strings.Cut(key, "/")
+	if !found {
+		return strings.TrimSpace(typ)
+	}
+	return strings.TrimSpace(typ) + "/" + strings.TrimSpace(name)
+}
+
+// undefinedReferenceError describes the reference to an undefined component with a suggestion: a
+// similarly named component of the same kind, preferably an unused one, or a component of the same
+// id defined as another kind.
+func undefinedReferenceError(referrer, section, ref string, defined map[string]map[string]string, used map[string]map[string]bool) error {
+	kindName := section[:len(section)-1]
+	id := normalizeComponentID(ref)
+	if suggestion, ok := similarComponentID(id, defined[section], used[section]); ok {
+		return fmt.Errorf("%s references undefined %s %q: did you mean %q?", referrer, kindName, ref, suggestion)
+	}
+	for _, kind := range componentKinds {
+		if _, ok := defined[kind.section][id]; ok && kind.section != section {
+			return fmt.Errorf("%s references undefined %s %q: it's defined as %s %s, not %s %s",
+				referrer, kindName, ref, article(kind.name), kind.name, article(kindName), kindName)
+		}
+	}
+	return fmt.Errorf("%s references undefined %s %q: define it under %s or remove the reference", referrer, kindName, ref, section)
+}
+
+// similarComponentID returns the defined id closest to the id within a few edits, preferring unused ones.
+func similarComponentID(id string, defined map[string]string, used map[string]bool) (string, bool) {
+	maxDistance := len(id) / 3
+	if maxDistance < 2 {
+		maxDistance = 2
+	}
+	best, bestDistance, bestUnused := "", maxDistance+1, false
+	for _, candidate := range sortedIDs(defined) {
+		distance := editDistance(id, candidate)
+		unused := !used[candidate]
+		if distance < bestDistance || (distance == bestDistance && unused && !bestUnused) {
+			best, bestDistance, bestUnused = defined[candidate], distance, unused
+		}
+	}
+	return best, best != ""
+}
+
+// editDistance returns the Levenshtein distance between the strings.
+func editDistance(a, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			current[j] = min3(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
+
+func article(kind string) string {
+	if strings.ContainsAny(kind[:1], "aeiou") {
+		return "an"
+	}
+	return "a"
+}
+
+// dedupeReferences returns the references without the repetitions of a normalized id.
+func dedupeReferences(refs []any) []any {
+	seen := map[string]bool{}
+	deduped := make([]any, 0, len(refs))
+	for _, ref := range refs {
+		id := normalizeComponentID(fmt.Sprint(ref))
+		if seen[id] {
+			continue
+		}
+		seen[id] = true
+		deduped = append(deduped, ref)
+	}
+	return deduped
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func sortedIDs(ids map[string]string) []string {
+	sorted := make([]string, 0, len(ids))
+	for id := range ids {
+		sorted = append(sorted, id)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
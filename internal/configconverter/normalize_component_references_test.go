@@ -0,0 +1,102 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configconverter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+	"go.uber.org/multierr"
+)
+
+func TestNormalizeComponentReferences(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/component-references.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	err = NormalizeComponentReferences{}.Convert(context.Background(), cfgMap)
+	require.NoError(t, err)
+
+	assert.Equal(t, []any{"health_check"}, cfgMap.Get("service::extensions"))
+	assert.Equal(t, []any{"hostmetrics", "otlp"}, cfgMap.Get("service::pipelines::metrics::receivers"))
+	assert.Equal(t, []any{"memory_limiter", "batch"}, cfgMap.Get("service::pipelines::metrics::processors"))
+	assert.Equal(t, []any{"signalfx"}, cfgMap.Get("service::pipelines::metrics::exporters"))
+	assert.Equal(t, []any{"otlp"}, cfgMap.Get("service::pipelines::traces::receivers"))
+	assert.Equal(t, []any{"logging"}, cfgMap.Get("service::pipelines::traces::exporters"))
+	assert.Equal(t, "10s", cfgMap.Get("receivers::hostmetrics::collection_interval"))
+}
+
+func TestNormalizeInvalidComponentReferences(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/invalid-component-references.yaml")
+	require.NoError(t, err)
+	require.NotNil(t, cfgMap)
+
+	err = NormalizeComponentReferences{}.Convert(context.Background(), cfgMap)
+	require.Error(t, err)
+	assert.Equal(t, []string{
+		`receivers "otlp/ custom" and "otlp/custom" both define the receiver "otlp/custom", likely from different config sources: rename or remove one of them`,
+		`service::pipelines "logs" and "logs " both define the pipeline "logs", likely from different config sources: rename or remove one of them`,
+		`service::extensions references undefined extension "healthcheck": did you mean "health_check"?`,
+		`service::extensions references undefined extension "zpages": define it under extensions or remove the reference`,
+		`pipeline "metrics" references undefined receiver "hostmetric": did you mean "hostmetrics"?`,
+		`pipeline "traces" references undefined receiver "sapm": it's defined as an exporter, not a receiver`,
+		`pipeline "traces" references undefined processor "resource_detection": did you mean "resourcedetection"?`,
+	}, errorMessages(err))
+}
+
+func errorMessages(err error) []string {
+	var messages []string
+	for _, e := range multierr.Errors(err) {
+		messages = append(messages, e.Error())
+	}
+	return messages
+}
+
+func TestNormalizeComponentReferencesOnNil(t *testing.T) {
+	err := NormalizeComponentReferences{}.Convert(context.Background(), nil)
+	require.EqualError(t, err, "cannot NormalizeComponentReferences on nil *confmap.Conf")
+}
+
+func TestNormalizeComponentReferencesWithoutService(t *testing.T) {
+	cfgMap := confmap.NewFromStringMap(map[string]any{"receivers": map[string]any{"otlp": nil}})
+	require.NoError(t, NormalizeComponentReferences{}.Convert(context.Background(), cfgMap))
+}
+
+func TestSimilarComponentIDPrefersUnused(t *testing.T) {
+	defined := map[string]string{"otlp/a": "otlp/a", "otlp/b": "otlp/b", "signalfx": "signalfx"}
+
+	suggestion, ok := similarComponentID("otlp/c", defined, map[string]bool{"otlp/a": true})
+	assert.True(t, ok)
+	assert.Equal(t, "otlp/b", suggestion)
+
+	suggestion, ok = similarComponentID("otlp/c", defined, map[string]bool{})
+	assert.True(t, ok)
+	assert.Equal(t, "otlp/a", suggestion)
+
+	_, ok = similarComponentID("zipkin", defined, map[string]bool{})
+	assert.False(t, ok)
+}
+
+func TestEditDistance(t *testing.T) {
+	assert.Equal(t, 0, editDistance("otlp", "otlp"))
+	assert.Equal(t, 1, editDistance("hostmetric", "hostmetrics"))
+	assert.Equal(t, 1, editDistance("resource_detection", "resourcedetection"))
+	assert.Equal(t, 2, editDistance("sapm", "spam"))
+	assert.Equal(t, 4, editDistance("", "otlp"))
+}
@@ -17,9 +17,13 @@ package configprovider
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"log"
+	"sync"
 
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
 	"go.opentelemetry.io/collector/confmap"
 	"go.uber.org/multierr"
 	"go.uber.org/zap"
@@ -38,8 +42,10 @@ type configSourceConfigMapProvider struct {
 	wrappedProvider  confmap.Provider
 	wrappedRetrieved confmap.Retrieved
 	retrieved        confmap.Retrieved
+	effective        map[string]any
 	buildInfo        component.BuildInfo
 	factories        []Factory
+	lock             sync.Mutex
 }
 
 // NewConfigSourceConfigMapProvider creates a ParserProvider that uses config sources.
@@ -85,9 +91,15 @@ func (c *configSourceConfigMapProvider) Retrieve(
 		return confmap.Retrieved{}, err
 	}
 
+	if onChange != nil {
+		go c.watchForUpdates(c.manager(), onChange)
+	}
+
 	c.retrieved, err = confmap.NewRetrieved(
 		cfg.ToStringMap(),
-		confmap.WithRetrievedClose(wr.Close),
+		confmap.WithRetrievedClose(func(ctx context.Context) error {
+			return multierr.Combine(c.closeManager(ctx), wr.Close(ctx))
+		}),
 	)
 	return c.retrieved, err
 }
@@ -122,6 +134,10 @@ func (c *configSourceConfigMapProvider) Get(context.Context) (*confmap.Conf, err
 	if err != nil {
 		return nil, err
 	}
+	if resolutionReportEnabled() {
+		// The service logger is not available yet.
+		log.Print(resolutionReport(csm.References()))
+	}
 
 	// Only start config server if this is the first config source
 	if c.configServer == nil {
@@ -135,14 +151,21 @@ func (c *configSourceConfigMapProvider) Get(context.Context) (*confmap.Conf, err
 		c.configServer.setEffective(effectiveMap.ToStringMap())
 	}
 
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.csm != nil {
+		// The previous Manager's config sources are superseded by the new one's.
+		_ = c.csm.Close(context.Background())
+	}
 	c.csm = csm
+	c.effective = effectiveMap.ToStringMap()
 	return effectiveMap, nil
 }
 
 // WatchForUpdate is used to monitor for updates on configuration values that
 // were retrieved from config sources.
 func (c *configSourceConfigMapProvider) WatchForUpdate() error {
-	return c.csm.WatchForUpdate()
+	return c.manager().WatchForUpdate()
 }
 
 // Close ends the watch for updates and closes the parser provider and respective
@@ -151,7 +174,103 @@ func (c *configSourceConfigMapProvider) Close(ctx context.Context) error {
 	if c.configServer != nil {
 		_ = c.configServer.shutdown()
 	}
-	return multierr.Combine(c.csm.Close(ctx), c.retrieved.Close(ctx))
+	return multierr.Combine(c.closeManager(ctx), c.retrieved.Close(ctx))
+}
+
+func (c *configSourceConfigMapProvider) manager() *Manager {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.csm
+}
+
+// closeManager closes the current Manager, if it hasn't already been closed.
+func (c *configSourceConfigMapProvider) closeManager(ctx context.Context) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.csm == nil {
+		return nil
+	}
+	err := c.csm.Close(ctx)
+	c.csm = nil
+	return err
+}
+
+// watchForUpdates waits for updates of values retrieved from config sources and notifies onChange only
+// when they change the effective configuration. Updates that don't change it (e.g. a rotated secret
+// with the same value or a touched include file) are absorbed so that the Collector isn't reloaded.
+func (c *configSourceConfigMapProvider) watchForUpdates(csm *Manager, onChange confmap.WatcherFunc) {
+	for csm != nil {
+		err := csm.WatchForUpdate()
+		switch {
+		case errors.Is(err, configsource.ErrSessionClosed):
+			return
+		case !errors.Is(err, configsource.ErrValueUpdated):
+			c.logger.Warn("Stopped watching config sources for updates", zap.Error(err))
+			return
+		}
+
+		var reload bool
+		if csm, reload = c.refresh(csm); reload {
+			onChange(&confmap.ChangeEvent{})
+			return
+		}
+	}
+}
+
+// refresh resolves the configuration with a new Manager after a config source update. If the effective
+// configuration is unchanged, the new Manager replaces the previous one and is returned for continued
+// watching. Otherwise it returns whether the Collector should be reloaded.
+func (c *configSourceConfigMapProvider) refresh(previous *Manager) (*Manager, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.csm != previous {
+		// The provider was closed or has since retrieved a new configuration.
+		return nil, false
+	}
+
+	ctx := context.Background()
+	csm, effective, err := c.resolve(ctx)
+	if err != nil {
+		c.logger.Warn("Failed resolving updated config source values, reloading", zap.Error(err))
+		return nil, true
+	}
+
+	diff := DiffConfigs(c.effective, effective)
+	if diff.IsEmpty() {
+		c.logger.Info("Config source values were updated without changing the effective configuration, skipping reload")
+		_ = previous.Close(ctx)
+		c.csm = csm
+		return csm, false
+	}
+	_ = csm.Close(ctx)
+	c.logger.Info(
+		"Config source values were updated, reloading",
+		zap.Strings("changed_components", diff.Components),
+		zap.Strings("affected_pipelines", diff.Pipelines),
+		zap.Strings("changed_settings", diff.Other),
+	)
+	return nil, true
+}
+
+func (c *configSourceConfigMapProvider) resolve(ctx context.Context) (*Manager, map[string]any, error) {
+	factories, err := makeFactoryMap(c.factories)
+	if err != nil {
+		return nil, nil, err
+	}
+	wrappedMap, err := c.wrappedRetrieved.AsConf()
+	if err != nil {
+		return nil, nil, err
+	}
+	csm, err := NewManager(wrappedMap, c.logger, c.buildInfo, factories)
+	if err != nil {
+		return nil, nil, err
+	}
+	effectiveMap, err := csm.Resolve(ctx, wrappedMap)
+	if err != nil {
+		_ = csm.Close(ctx)
+		return nil, nil, err
+	}
+	return csm, effectiveMap.ToStringMap(), nil
 }
 
 func makeFactoryMap(factories []Factory) (Factories, error) {
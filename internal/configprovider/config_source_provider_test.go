@@ -22,10 +22,13 @@ import (
 	"path"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	expcfg "go.opentelemetry.io/collector/config/experimental/config"
 	"go.opentelemetry.io/collector/config/experimental/configsource"
 	"go.opentelemetry.io/collector/confmap"
 	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
@@ -148,6 +151,53 @@ func TestConfigSourceConfigMapProvider(t *testing.T) {
 	}
 }
 
+func TestConfigSourceConfigMapProviderWatchForUpdates(t *testing.T) {
+	factory := &watchableCfgSrcFactory{
+		value:   "localhost:4317",
+		updates: make(chan struct{}),
+	}
+	pp := NewConfigSourceConfigMapProvider(
+		fileprovider.New(),
+		zap.NewNop(),
+		component.NewDefaultBuildInfo(),
+		factory,
+	)
+	cspp := pp.(*configSourceConfigMapProvider)
+
+	changes := make(chan *confmap.ChangeEvent, 1)
+	r, err := pp.Retrieve(context.Background(), "file:"+path.Join("testdata", "watch_config.yaml"), func(event *confmap.ChangeEvent) {
+		changes <- event
+	})
+	require.NoError(t, err)
+	conf, err := r.AsConf()
+	require.NoError(t, err)
+	assert.Equal(t, "localhost:4317", conf.Get("receivers::otlp::endpoint"))
+
+	// An update that doesn't change the effective configuration doesn't trigger a reload.
+	initial := cspp.manager()
+	factory.update("localhost:4317")
+	require.Eventually(t, func() bool {
+		return cspp.manager() != initial
+	}, 5*time.Second, 10*time.Millisecond)
+	select {
+	case <-changes:
+		t.Fatal("unexpected reload for unchanged configuration")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	factory.update("localhost:14317")
+	select {
+	case event := <-changes:
+		require.NotNil(t, event)
+		assert.NoError(t, event.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no reload for changed configuration")
+	}
+
+	assert.NoError(t, r.Close(context.Background()))
+	assert.Nil(t, cspp.manager())
+}
+
 type mockParserProvider struct {
 	ErrOnGet bool
 }
@@ -182,3 +232,57 @@ func (mpp *mockParserProvider) Close(context.Context) error {
 }
 
 type errOnParserProviderGet struct{ error }
+
+// watchableCfgSrcFactory creates config sources whose values can be updated by tests.
+type watchableCfgSrcFactory struct {
+	updates chan struct{}
+	value   string
+	lock    sync.Mutex
+}
+
+var _ Factory = (*watchableCfgSrcFactory)(nil)
+
+func (f *watchableCfgSrcFactory) Type() config.Type {
+	return "watchsrc"
+}
+
+func (f *watchableCfgSrcFactory) CreateDefaultConfig() expcfg.Source {
+	return &mockCfgSrcSettings{
+		SourceSettings: expcfg.NewSourceSettings(config.NewComponentID("watchsrc")),
+	}
+}
+
+func (f *watchableCfgSrcFactory) CreateConfigSource(context.Context, CreateParams, expcfg.Source) (configsource.ConfigSource, error) {
+	closeCh := make(chan struct{})
+	return &testConfigSource{
+		ValueMap: map[string]valueEntry{
+			"endpoint": {
+				Value: f.current(),
+				WatchForUpdateFn: func() error {
+					select {
+					case <-f.updates:
+						return configsource.ErrValueUpdated
+					case <-closeCh:
+						return configsource.ErrSessionClosed
+					}
+				},
+			},
+		},
+		OnClose: func() {
+			close(closeCh)
+		},
+	}, nil
+}
+
+func (f *watchableCfgSrcFactory) current() string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.value
+}
+
+func (f *watchableCfgSrcFactory) update(value string) {
+	f.lock.Lock()
+	f.value = value
+	f.lock.Unlock()
+	f.updates <- struct{}{}
+}
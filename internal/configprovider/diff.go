@@ -0,0 +1,156 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	serviceKey   = "service"
+	pipelinesKey = "pipelines"
+)
+
+// componentKinds are the top-level configuration sections whose entries are individual components.
+var componentKinds = []string{"receivers", "processors", "exporters", "extensions"}
+
+// pipelineComponentKinds are the pipeline fields referencing components, and their section.
+var pipelineComponentKinds = map[string]string{
+	"receivers":  "receivers",
+	"processors": "processors",
+	"exporters":  "exporters",
+}
+
+// ConfigDiff describes the differences between two resolved configurations at the component level,
+// logged when a config source update reloads the Collector.
+type ConfigDiff struct {
+	// Components contains the keys (e.g. receivers::otlp) of components that were added, removed, or modified.
+	Components []string
+	// Pipelines contains the names of pipelines that were added, removed, or modified, or that use a changed component.
+	Pipelines []string
+	// Other contains the keys of changed settings outside of components and pipelines (e.g. service::telemetry).
+	Other []string
+}
+
+// IsEmpty returns whether the compared configurations are equivalent.
+func (d ConfigDiff) IsEmpty() bool {
+	return len(d.Components) == 0 && len(d.Pipelines) == 0 && len(d.Other) == 0
+}
+
+// DiffConfigs compares the previous and current resolved configurations, as provided by
+// confmap.Conf.ToStringMap().
+func DiffConfigs(previous, current map[string]any) ConfigDiff {
+	var diff ConfigDiff
+	changed := map[string]bool{}
+	for _, kind := range componentKinds {
+		for _, name := range changedKeys(asMap(previous[kind]), asMap(current[kind])) {
+			key := kind + confmap.KeyDelimiter + name
+			changed[key] = true
+			diff.Components = append(diff.Components, key)
+		}
+	}
+
+	previousPipelines := asMap(asMap(previous[serviceKey])[pipelinesKey])
+	currentPipelines := asMap(asMap(current[serviceKey])[pipelinesKey])
+	affected := toSet(changedKeys(previousPipelines, currentPipelines))
+	for name, pipeline := range currentPipelines {
+		for field, kind := range pipelineComponentKinds {
+			for _, id := range asSlice(asMap(pipeline)[field]) {
+				if changed[kind+confmap.KeyDelimiter+fmt.Sprint(id)] {
+					affected[name] = true
+				}
+			}
+		}
+	}
+	for name := range affected {
+		diff.Pipelines = append(diff.Pipelines, name)
+	}
+
+	for _, key := range changedKeys(previous, current) {
+		if isComponentKind(key) || key == serviceKey {
+			continue
+		}
+		diff.Other = append(diff.Other, key)
+	}
+	for _, key := range changedKeys(asMap(previous[serviceKey]), asMap(current[serviceKey])) {
+		if key != pipelinesKey {
+			diff.Other = append(diff.Other, serviceKey+confmap.KeyDelimiter+key)
+		}
+	}
+
+	sort.Strings(diff.Components)
+	sort.Strings(diff.Pipelines)
+	sort.Strings(diff.Other)
+	return diff
+}
+
+// changedKeys returns the keys that were added, removed, or whose values differ.
+func changedKeys(previous, current map[string]any) []string {
+	var keys []string
+	for key, value := range current {
+		if prev, ok := previous[key]; !ok || !reflect.DeepEqual(prev, value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range previous {
+		if _, ok := current[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func isComponentKind(key string) bool {
+	for _, kind := range componentKinds {
+		if key == kind {
+			return true
+		}
+	}
+	return false
+}
+
+func asMap(value any) map[string]any {
+	if m, ok := value.(map[string]any); ok {
+		return m
+	}
+	return map[string]any{}
+}
+
+func asSlice(value any) []any {
+	switch s := value.(type) {
+	case []any:
+		return s
+	case []string:
+		slice := make([]any, 0, len(s))
+		for _, v := range s {
+			slice = append(slice, v)
+		}
+		return slice
+	}
+	return nil
+}
+
+func toSet(values []string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, v := range values {
+		set[v] = true
+	}
+	return set
+}
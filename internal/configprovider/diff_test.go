@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func baseDiffConfig() map[string]any {
+	return map[string]any{
+		"receivers": map[string]any{
+			"otlp":        map[string]any{"endpoint": "0.0.0.0:4317"},
+			"hostmetrics": map[string]any{"collection_interval": "10s"},
+			"filelog":     nil,
+		},
+		"processors": map[string]any{
+			"batch": nil,
+		},
+		"exporters": map[string]any{
+			"signalfx": map[string]any{"access_token": "token"},
+			"splunk_hec": map[string]any{
+				"token": "token",
+			},
+		},
+		"extensions": map[string]any{
+			"health_check": nil,
+		},
+		"service": map[string]any{
+			"extensions": []any{"health_check"},
+			"pipelines": map[string]any{
+				"metrics": map[string]any{
+					"receivers":  []any{"otlp", "hostmetrics"},
+					"processors": []any{"batch"},
+					"exporters":  []any{"signalfx"},
+				},
+				"logs": map[string]any{
+					"receivers": []string{"filelog"},
+					"exporters": []string{"splunk_hec"},
+				},
+			},
+		},
+	}
+}
+
+func TestDiffConfigs(t *testing.T) {
+	tests := []struct {
+		name     string
+		update   func(cfg map[string]any)
+		expected ConfigDiff
+	}{
+		{
+			name:   "unchanged",
+			update: func(map[string]any) {},
+		},
+		{
+			name: "changed_exporter",
+			update: func(cfg map[string]any) {
+				asMap(asMap(cfg["exporters"])["splunk_hec"])["token"] = "rotated"
+			},
+			expected: ConfigDiff{
+				Components: []string{"exporters::splunk_hec"},
+				Pipelines:  []string{"logs"},
+			},
+		},
+		{
+			name: "changed_receiver",
+			update: func(cfg map[string]any) {
+				asMap(cfg["receivers"])["hostmetrics"] = map[string]any{"collection_interval": "1m"}
+			},
+			expected: ConfigDiff{
+				Components: []string{"receivers::hostmetrics"},
+				Pipelines:  []string{"metrics"},
+			},
+		},
+		{
+			name: "added_unused_and_removed_components",
+			update: func(cfg map[string]any) {
+				asMap(cfg["receivers"])["prometheus"] = nil
+				delete(asMap(cfg["extensions"]), "health_check")
+				asMap(cfg["service"])["extensions"] = []any{}
+			},
+			expected: ConfigDiff{
+				Components: []string{"extensions::health_check", "receivers::prometheus"},
+				Other:      []string{"service::extensions"},
+			},
+		},
+		{
+			name: "changed_pipeline",
+			update: func(cfg map[string]any) {
+				pipeline := asMap(asMap(asMap(cfg["service"])["pipelines"])["metrics"])
+				pipeline["processors"] = []any{}
+			},
+			expected: ConfigDiff{
+				Pipelines: []string{"metrics"},
+			},
+		},
+		{
+			name: "changed_other_settings",
+			update: func(cfg map[string]any) {
+				cfg["config_sources"] = map[string]any{"vault": nil}
+				asMap(cfg["service"])["telemetry"] = map[string]any{"logs": map[string]any{"level": "debug"}}
+			},
+			expected: ConfigDiff{
+				Other: []string{"config_sources", "service::telemetry"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			previous, current := baseDiffConfig(), baseDiffConfig()
+			tt.update(current)
+
+			diff := DiffConfigs(previous, current)
+			assert.Equal(t, tt.expected, diff)
+			assert.Equal(t, tt.expected.IsEmpty(), diff.IsEmpty())
+		})
+	}
+}
@@ -21,9 +21,11 @@ import (
 	"log"
 	"net/url"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/spf13/cast"
 	"go.opentelemetry.io/collector/component"
@@ -46,13 +48,39 @@ const (
 	typeAndNameSeparator = '/'
 	// dollarDollarCompatEnvVar is a temporary env var to disable backward compatibility (true by default)
 	dollarDollarCompatEnvVar = "SPLUNK_DOUBLE_DOLLAR_CONFIG_SOURCE_COMPATIBLE"
+	// strictResolutionEnvVar enables failing on unresolvable env var and config source references (false by default)
+	strictResolutionEnvVar = "SPLUNK_CONFIG_SOURCES_STRICT"
+	// strictAllowlistEnvVar is a comma-separated list of env var and config source names whose unresolvable
+	// references are intentionally kept as literals in strict mode.
+	strictAllowlistEnvVar = "SPLUNK_CONFIG_SOURCES_STRICT_ALLOWLIST"
+	// resolutionTimeoutEnvVar is the duration after which resolving the configuration fails, 0 to wait indefinitely
+	// (5m by default).
+	resolutionTimeoutEnvVar  = "SPLUNK_CONFIG_SOURCES_RESOLUTION_TIMEOUT"
+	defaultResolutionTimeout = 5 * time.Minute
 )
 
 // private error types to help with testability
 type (
 	errUnknownConfigSource struct{ error }
+	errUnresolvedReference struct{ error }
+	errResolutionTimeout   struct{ error }
 )
 
+// invocation identifies a config source invocation of the configuration.
+type invocation struct {
+	cfgSrcName       string
+	cfgSrcInvocation string
+}
+
+// prefetchedValue is the value retrieved for an independent config source invocation before resolving the
+// configuration, shared by all its references.
+type prefetchedValue struct {
+	retrieved configsource.Retrieved
+	err       error
+	// watched is whether the watcher of the retrieved value was added, once for all its references.
+	watched bool
+}
+
 var ddBackwardCompatible = func() bool {
 	if v, err := strconv.ParseBool(strings.ToLower(os.Getenv(dollarDollarCompatEnvVar))); err == nil {
 		return v
@@ -172,10 +200,25 @@ var ddBackwardCompatible = func() bool {
 // For an overview about the internals of the Manager refer to the package README.md.
 type Manager struct {
 	configSources map[string]configsource.ConfigSource
-	watchingCh    chan struct{}
-	closeCh       chan struct{}
-	watchers      []configsource.Watchable
-	watchersWG    sync.WaitGroup
+	// strictAllowlist contains the names of references to keep as literals when strict is set.
+	strictAllowlist map[string]bool
+	// references are the env vars and config source values resolved into the configuration.
+	references map[referenceID]*Reference
+	watchingCh chan struct{}
+	closeCh    chan struct{}
+	// resolvingKey is the configuration key whose value is being resolved.
+	resolvingKey string
+	watchers     []configsource.Watchable
+	watchersWG   sync.WaitGroup
+	// strict causes Resolve to fail on references to unset env vars, unknown config sources,
+	// and malformed expansions instead of passing them through.
+	strict bool
+	// collecting is set while collecting the independent config source invocations of the configuration,
+	// in order of appearance, instead of retrieving their values.
+	collecting *[]invocation
+	// prefetched are the values of the independent config source invocations retrieved concurrently.
+	prefetched        map[invocation]*prefetchedValue
+	resolutionTimeout time.Duration
 }
 
 // NewManager creates a new instance of a Manager to be used to inject data from
@@ -202,8 +245,116 @@ func NewManager(configMap *confmap.Conf, logger *zap.Logger, buildInfo component
 // Resolve inspects the given confmap.Conf and resolves all config sources referenced
 // in the configuration, returning a confmap.Conf in which all env vars and config sources on
 // the given input config map are resolved to actual literal values of the env vars or config sources.
-// This method must be called only once per lifetime of a Manager object.
+// This method must be called only once per lifetime of a Manager object. In strict mode, enabled via the
+// SPLUNK_CONFIG_SOURCES_STRICT env var, errors include the key path of the unresolvable value.
+//
+// The values of the invocations without env var or config source references of their own, like
+// $vault:data.password, are retrieved first, concurrently for the different config sources and sequentially
+// for the invocations of each config source, which reuses its client or connection. Identical invocations are
+// retrieved once. The invocations depending on others are then retrieved while resolving the configuration.
+// Resolve fails once the SPLUNK_CONFIG_SOURCES_RESOLUTION_TIMEOUT env var duration, 5m by default, has elapsed.
 func (m *Manager) Resolve(ctx context.Context, configMap *confmap.Conf) (*confmap.Conf, error) {
+	if m.resolutionTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, m.resolutionTimeout)
+		defer cancel()
+	}
+
+	if err := m.prefetch(ctx, configMap); err != nil {
+		return nil, err
+	}
+	res, err := m.resolve(ctx, configMap, true)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, &errResolutionTimeout{fmt.Errorf("config resolution timed out after %v: %w", m.resolutionTimeout, err)}
+	}
+	return res, err
+}
+
+// prefetch retrieves the values of the independent config source invocations of the configuration, concurrently
+// for the different config sources. The retrieval errors are returned when resolving the configuration, so
+// that they're reported like those of the other invocations.
+func (m *Manager) prefetch(ctx context.Context, configMap *confmap.Conf) error {
+	var invocations []invocation
+	m.collecting = &invocations
+	for _, k := range configMap.AllKeys() {
+		if strings.HasPrefix(k, configSourcesKey) {
+			continue
+		}
+		// errors are reported when resolving the configuration
+		_, _ = m.parseConfigValue(ctx, configMap.Get(k))
+	}
+	m.collecting = nil
+	if len(invocations) == 0 {
+		return nil
+	}
+
+	bySource := map[string][]invocation{}
+	prefetched := make(map[invocation]*prefetchedValue, len(invocations))
+	for _, inv := range invocations {
+		if _, ok := prefetched[inv]; ok {
+			continue
+		}
+		prefetched[inv] = &prefetchedValue{}
+		bySource[inv.cfgSrcName] = append(bySource[inv.cfgSrcName], inv)
+	}
+
+	var lock sync.Mutex
+	pending := map[string]bool{}
+	var wg sync.WaitGroup
+	for name, sourceInvocations := range bySource {
+		pending[name] = true
+		wg.Add(1)
+		go func(name string, sourceInvocations []invocation) {
+			defer wg.Done()
+			for _, inv := range sourceInvocations {
+				value := prefetched[inv]
+				if value.err = ctx.Err(); value.err == nil {
+					value.retrieved, value.err = m.retrieveIndependent(ctx, inv)
+				}
+			}
+			lock.Lock()
+			delete(pending, name)
+			lock.Unlock()
+		}(name, sourceInvocations)
+	}
+
+	doneCh := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(doneCh)
+	}()
+	select {
+	case <-doneCh:
+	case <-ctx.Done():
+		lock.Lock()
+		names := make([]string, 0, len(pending))
+		for name := range pending {
+			names = append(names, name)
+		}
+		lock.Unlock()
+		sort.Strings(names)
+		return &errResolutionTimeout{fmt.Errorf(
+			"config resolution timed out after %v waiting for config sources %s: %w",
+			m.resolutionTimeout, strings.Join(names, ", "), ctx.Err(),
+		)}
+	}
+
+	m.prefetched = prefetched
+	return nil
+}
+
+// retrieveIndependent retrieves the value of the config source invocation without references of its own.
+func (m *Manager) retrieveIndependent(ctx context.Context, inv invocation) (configsource.Retrieved, error) {
+	_, selector, paramsConfigMap, err := m.parseInvocation(ctx, inv.cfgSrcInvocation)
+	if err != nil {
+		return nil, err
+	}
+	return m.configSources[inv.cfgSrcName].Retrieve(ctx, selector, paramsConfigMap)
+}
+
+// resolve resolves the configuration, or the params of a config source invocation if not topLevel,
+// whose references are recorded as those of the configuration key being resolved.
+func (m *Manager) resolve(ctx context.Context, configMap *confmap.Conf, topLevel bool) (*confmap.Conf, error) {
 	res := map[string]any{}
 	allKeys := configMap.AllKeys()
 	for _, k := range allKeys {
@@ -214,8 +365,14 @@ func (m *Manager) Resolve(ctx context.Context, configMap *confmap.Conf) (*confma
 			continue
 		}
 
+		if topLevel {
+			m.resolvingKey = k
+		}
 		value, err := m.parseConfigValue(ctx, configMap.Get(k))
 		if err != nil {
+			if m.strict {
+				return nil, fmt.Errorf("failed resolving %q: %w", k, err)
+			}
 			return nil, err
 		}
 		res[k] = value
@@ -293,13 +450,44 @@ func (m *Manager) Close(ctx context.Context) error {
 }
 
 func newManager(configSources map[string]configsource.ConfigSource) *Manager {
+	strict, allowlist := strictResolutionFromEnv()
 	return &Manager{
-		configSources: configSources,
-		watchingCh:    make(chan struct{}),
-		closeCh:       make(chan struct{}),
+		configSources:     configSources,
+		watchingCh:        make(chan struct{}),
+		closeCh:           make(chan struct{}),
+		strict:            strict,
+		strictAllowlist:   allowlist,
+		resolutionTimeout: resolutionTimeoutFromEnv(),
 	}
 }
 
+func strictResolutionFromEnv() (bool, map[string]bool) {
+	strict, err := strconv.ParseBool(strings.ToLower(os.Getenv(strictResolutionEnvVar)))
+	if err != nil || !strict {
+		return false, nil
+	}
+	allowlist := map[string]bool{}
+	for _, name := range strings.Split(os.Getenv(strictAllowlistEnvVar), ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			allowlist[name] = true
+		}
+	}
+	return true, allowlist
+}
+
+func resolutionTimeoutFromEnv() time.Duration {
+	value := os.Getenv(resolutionTimeoutEnvVar)
+	if value == "" {
+		return defaultResolutionTimeout
+	}
+	timeout, err := time.ParseDuration(value)
+	if err != nil || timeout < 0 {
+		log.Printf("Invalid %s value %q, using the default of %v", resolutionTimeoutEnvVar, value, defaultResolutionTimeout)
+		return defaultResolutionTimeout
+	}
+	return timeout
+}
+
 // parseConfigValue takes the value of a "config node" and process it recursively. The processing consists
 // in transforming invocations of config sources and/or environment variables into literal data that can be
 // used directly from a `confmap.Conf` object.
@@ -401,10 +589,12 @@ func (m *Manager) parseStringValue(ctx context.Context, s string) (any, error) {
 				}
 
 				if bwCompatibilityRequired {
-					log.Printf(
-						`Deprecated config source directive %q has been replaced with %q. Please update your config as necessary as this will be removed in future release. To disable this replacement set the SPLUNK_DOUBLE_DOLLAR_CONFIG_SOURCE_COMPATIBLE environment variable to "false" before restarting the Collector.`,
-						s[j:j+2+ww], s[j+1:j+2+ww],
-					)
+					if m.collecting == nil {
+						log.Printf(
+							`Deprecated config source directive %q has been replaced with %q. Please update your config as necessary as this will be removed in future release. To disable this replacement set the SPLUNK_DOUBLE_DOLLAR_CONFIG_SOURCE_COMPATIBLE environment variable to "false" before restarting the Collector.`,
+							s[j:j+2+ww], s[j+1:j+2+ww],
+						)
+					}
 					expandableContent = expanded
 					w = ww + 1
 					cfgSrcName = sourceName
@@ -426,10 +616,29 @@ func (m *Manager) parseStringValue(ctx context.Context, s string) (any, error) {
 			// At this point expandableContent contains a string to be expanded, evaluate and expand it.
 			switch {
 			case cfgSrcName == "":
+				if m.strict {
+					literal, err := m.checkStrictEnvVar(expandableContent, s[j:j+w+1])
+					if err != nil {
+						return nil, err
+					}
+					if literal {
+						buf = append(buf, s[j:j+w+1]...)
+						break
+					}
+				}
 				// Not a config source, expand as os.ExpandEnv
+				if expandableContent != "" && expandableContent != "$" {
+					_, set := os.LookupEnv(expandableContent)
+					m.recordReference(ReferenceEnvVar, expandableContent, "", set)
+				}
 				buf = osExpandEnv(buf, expandableContent, w)
 
 			default:
+				if _, ok := m.configSources[cfgSrcName]; !ok && m.strict && m.strictAllowlist[cfgSrcName] {
+					// An intentionally literal expression resembling a config source invocation.
+					buf = append(buf, s[j:j+w+1]...)
+					break
+				}
 				// A config source, retrieve and apply results.
 				retrieved, err := m.retrieveConfigSourceData(ctx, cfgSrcName, expandableContent)
 				if err != nil {
@@ -487,6 +696,31 @@ func (m *Manager) parseStringValue(ctx context.Context, s string) (any, error) {
 	return string(buf) + s[i:], nil
 }
 
+// checkStrictEnvVar determines whether the env var reference should be kept as a literal because it's
+// allowlisted, or returns an error if it's unresolvable.
+func (m *Manager) checkStrictEnvVar(name, reference string) (literal bool, err error) {
+	switch {
+	case name == "$":
+		// An escaped prefix char.
+		return false, nil
+	case name == "" && len(reference) > 1:
+		return false, &errUnresolvedReference{fmt.Errorf("invalid expansion syntax %q", reference)}
+	case name == "":
+		// A lone prefix char that's kept as is.
+		return false, nil
+	}
+	if _, ok := os.LookupEnv(name); ok {
+		return false, nil
+	}
+	if m.strictAllowlist[name] {
+		return true, nil
+	}
+	return false, &errUnresolvedReference{fmt.Errorf(
+		"environment variable %q referenced by %q is not set; add it to %s if this is intended to be a literal",
+		name, reference, strictAllowlistEnvVar,
+	)}
+}
+
 func getBracketedExpandableContent(s string, i int) (expandableContent string, consumed int, cfgSrcName string) {
 	// Bracketed usage, consume everything until first '}' exactly as os.Expand.
 	expandableContent, consumed = scanToClosingBracket(s[i:])
@@ -518,6 +752,50 @@ func getBareExpandableContent(s string, i int) (expandableContent string, consum
 	return
 }
 
+// ExpandEnvVars expands the env var references of the string as the Manager does, leaving its
+// escaped prefixes and config source invocations as is, and returns the names of the referenced
+// env vars. No config source value is retrieved.
+func ExpandEnvVars(s string) (string, []string) {
+	var buf []byte
+	var names []string
+	i := 0
+	for j := 0; j < len(s); j++ {
+		if s[j] != expandPrefixChar || j+1 >= len(s) {
+			continue
+		}
+		if s[j+1] == expandPrefixChar {
+			// escaped, kept for the Manager to unescape
+			j++
+			continue
+		}
+
+		var expandableContent, cfgSrcName string
+		var w int
+		if s[j+1] == '{' {
+			expandableContent, w, cfgSrcName = getBracketedExpandableContent(s, j+1)
+		} else {
+			expandableContent, w, cfgSrcName = getBareExpandableContent(s, j+1)
+		}
+		if cfgSrcName != "" {
+			j += w
+			continue
+		}
+
+		buf = append(buf, s[i:j]...)
+		if expandableContent != "" && expandableContent != "$" {
+			names = append(names, expandableContent)
+		}
+		buf = osExpandEnv(buf, expandableContent, w)
+		j += w
+		i = j + 1
+	}
+
+	if buf == nil {
+		return s, names
+	}
+	return string(buf) + s[i:], names
+}
+
 // retrieveConfigSourceData retrieves data from the specified config source and injects them into
 // the configuration. The Manager tracks sessions and watcher objects as needed.
 func (m *Manager) retrieveConfigSourceData(ctx context.Context, cfgSrcName, cfgSrcInvocation string) (any, error) {
@@ -525,40 +803,70 @@ func (m *Manager) retrieveConfigSourceData(ctx context.Context, cfgSrcName, cfgS
 	if !ok {
 		return nil, newErrUnknownConfigSource(cfgSrcName)
 	}
+	inv := invocation{cfgSrcName: cfgSrcName, cfgSrcInvocation: cfgSrcInvocation}
 
-	cfgSrcName, selector, paramsConfigMap, err := parseCfgSrcInvocation(cfgSrcInvocation)
+	cfgSrcName, selector, paramsConfigMap, err := m.parseInvocation(ctx, cfgSrcInvocation)
 	if err != nil {
 		return nil, err
 	}
 
+	if m.collecting != nil {
+		// The invocations referencing env vars or config sources depend on them, and are retrieved when
+		// resolving the configuration.
+		if !strings.ContainsRune(cfgSrcInvocation, expandPrefixChar) {
+			*m.collecting = append(*m.collecting, inv)
+		}
+		return nil, nil
+	}
+
+	var retrieved configsource.Retrieved
+	watch := true
+	if prefetched, ok := m.prefetched[inv]; ok {
+		retrieved, err = prefetched.retrieved, prefetched.err
+		watch = !prefetched.watched
+		prefetched.watched = true
+	} else {
+		retrieved, err = cfgSrc.Retrieve(ctx, selector, paramsConfigMap)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("config source %q failed to retrieve value: %w", cfgSrcName, err)
+	}
+
+	if watcher, ok := retrieved.(configsource.Watchable); ok && watch {
+		m.watchers = append(m.watchers, watcher)
+	}
+	m.recordReference(ReferenceConfigSource, cfgSrcName, selector, true)
+
+	return retrieved.Value(), nil
+}
+
+// parseInvocation returns the config source name, expanded selector, and resolved parameters of the config source
+// invocation.
+func (m *Manager) parseInvocation(ctx context.Context, cfgSrcInvocation string) (cfgSrcName, selector string, paramsConfigMap *confmap.Conf, err error) {
+	cfgSrcName, selector, paramsConfigMap, err = parseCfgSrcInvocation(cfgSrcInvocation)
+	if err != nil {
+		return "", "", nil, err
+	}
+
 	// Recursively expand the selector.
 	var expandedSelector any
 	expandedSelector, err = m.parseStringValue(ctx, selector)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process selector for config source %q selector %q: %w", cfgSrcName, selector, err)
+		return "", "", nil, fmt.Errorf("failed to process selector for config source %q selector %q: %w", cfgSrcName, selector, err)
 	}
+	var ok bool
 	if selector, ok = expandedSelector.(string); !ok {
-		return nil, fmt.Errorf("processed selector must be a string instead got a %T %v", expandedSelector, expandedSelector)
+		return "", "", nil, fmt.Errorf("processed selector must be a string instead got a %T %v", expandedSelector, expandedSelector)
 	}
 
 	// Recursively resolve/parse any config source on the parameters.
 	if paramsConfigMap != nil {
-		paramsConfigMap, err = m.Resolve(ctx, paramsConfigMap)
+		paramsConfigMap, err = m.resolve(ctx, paramsConfigMap, false)
 		if err != nil {
-			return nil, fmt.Errorf("failed to process parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
+			return "", "", nil, fmt.Errorf("failed to process parameters for config source %q invocation %q: %w", cfgSrcName, cfgSrcInvocation, err)
 		}
 	}
-
-	retrieved, err := cfgSrc.Retrieve(ctx, selector, paramsConfigMap)
-	if err != nil {
-		return nil, fmt.Errorf("config source %q failed to retrieve value: %w", cfgSrcName, err)
-	}
-
-	if watcher, ok := retrieved.(configsource.Watchable); ok {
-		m.watchers = append(m.watchers, watcher)
-	}
-
-	return retrieved.Value(), nil
+	return cfgSrcName, selector, paramsConfigMap, nil
 }
 
 func newErrUnknownConfigSource(cfgSrcName string) error {
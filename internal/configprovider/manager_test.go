@@ -17,9 +17,12 @@ package configprovider
 import (
 	"context"
 	"errors"
+	"fmt"
 	"os"
 	"path"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -450,6 +453,223 @@ func TestConfigSourceManager_EnvVarHandling(t *testing.T) {
 	assert.NoError(t, manager.Close(ctx))
 }
 
+func TestConfigSourceManager_StrictResolution(t *testing.T) {
+	t.Setenv("STRICT_SET", "set_value")
+	t.Setenv(strictResolutionEnvVar, "true")
+	t.Setenv(strictAllowlistEnvVar, "STRICT_LITERAL, literalsrc")
+
+	ctx := context.Background()
+	configSources := map[string]configsource.ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector": {Value: "cfgsrc_value"},
+			},
+		},
+	}
+
+	tests := []struct {
+		config    map[string]any
+		expected  map[string]any
+		errTarget any
+		name      string
+		wantErr   string
+	}{
+		{
+			name: "resolvable",
+			config: map[string]any{
+				"top": map[string]any{
+					"envvar":  "${STRICT_SET}/suffix",
+					"bare":    "$STRICT_SET",
+					"cfgsrc":  "${tstcfgsrc:selector}",
+					"escaped": "$$STRICT_UNSET",
+					"lone":    "pattern$",
+				},
+			},
+			expected: map[string]any{
+				"top": map[string]any{
+					"envvar":  "set_value/suffix",
+					"bare":    "set_value",
+					"cfgsrc":  "cfgsrc_value",
+					"escaped": "$STRICT_UNSET",
+					"lone":    "pattern$",
+				},
+			},
+		},
+		{
+			name: "allowlisted_literals",
+			config: map[string]any{
+				"envvar": "host-${STRICT_LITERAL}",
+				"cfgsrc": "${literalsrc:value}",
+			},
+			expected: map[string]any{
+				"envvar": "host-${STRICT_LITERAL}",
+				"cfgsrc": "${literalsrc:value}",
+			},
+		},
+		{
+			name: "unset_envvar",
+			config: map[string]any{
+				"top": map[string]any{"field": "${STRICT_UNSET}"},
+			},
+			wantErr:   `failed resolving "top::field": environment variable "STRICT_UNSET" referenced by "${STRICT_UNSET}" is not set`,
+			errTarget: new(*errUnresolvedReference),
+		},
+		{
+			name: "unknown_cfgsrc",
+			config: map[string]any{
+				"top": map[string]any{"field": "$unknown:selector"},
+			},
+			wantErr:   `failed resolving "top::field": config source "unknown" not found`,
+			errTarget: new(*errUnknownConfigSource),
+		},
+		{
+			name: "invalid_syntax",
+			config: map[string]any{
+				"field": "${STRICT_SET",
+			},
+			wantErr:   `failed resolving "field": invalid expansion syntax "${"`,
+			errTarget: new(*errUnresolvedReference),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			manager := newManager(configSources)
+			require.True(t, manager.strict)
+
+			res, err := manager.Resolve(ctx, confmap.NewFromStringMap(tt.config))
+			if tt.wantErr != "" {
+				require.Error(t, err)
+				assert.Contains(t, err.Error(), tt.wantErr)
+				assert.True(t, errors.As(err, tt.errTarget))
+				require.Nil(t, res)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, res.ToStringMap())
+		})
+	}
+}
+
+func TestConfigSourceManager_NonStrictResolution(t *testing.T) {
+	t.Setenv(strictAllowlistEnvVar, "STRICT_UNSET")
+
+	manager := newManager(map[string]configsource.ConfigSource{})
+	require.False(t, manager.strict)
+
+	res, err := manager.Resolve(context.Background(), confmap.NewFromStringMap(map[string]any{
+		"field": "prefix-${STRICT_UNSET}",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{"field": "prefix-"}, res.ToStringMap())
+}
+
+func TestConfigSourceManager_ConcurrentResolution(t *testing.T) {
+	// Each config source waits for the other one to be retrieving before returning its values, so resolving the
+	// configuration only succeeds if they're retrieved concurrently.
+	var lock sync.Mutex
+	retrievals := map[string]int{}
+	started := map[string]chan struct{}{"src0": make(chan struct{}), "src1": make(chan struct{})}
+	startedOnce := map[string]*sync.Once{"src0": {}, "src1": {}}
+	onRetrieve := func(name, other string) func(context.Context, string, *confmap.Conf) error {
+		return func(ctx context.Context, selector string, _ *confmap.Conf) error {
+			lock.Lock()
+			retrievals[name+":"+selector]++
+			lock.Unlock()
+			startedOnce[name].Do(func() { close(started[name]) })
+			select {
+			case <-started[other]:
+				return nil
+			case <-time.After(5 * time.Second):
+				return fmt.Errorf("%s was not retrieved concurrently", other)
+			}
+		}
+	}
+
+	manager := newManager(map[string]configsource.ConfigSource{
+		"src0": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector": {Value: "src0_value", WatchForUpdateFn: func() error { return nil }},
+			},
+			OnRetrieve: onRetrieve("src0", "src1"),
+		},
+		"src1": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector":   {Value: "src1_value"},
+				"src0_value": {Value: "dependent_value"},
+			},
+			OnRetrieve: onRetrieve("src1", "src0"),
+		},
+	})
+
+	res, err := manager.Resolve(context.Background(), confmap.NewFromStringMap(map[string]any{
+		"field0":    "$src0:selector",
+		"field1":    "${src1:selector}/suffix",
+		"repeated":  []any{"${src0:selector}", map[string]any{"nested": "$src0:selector"}},
+		"dependent": "$src1:$src0:selector",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"field0":    "src0_value",
+		"field1":    "src1_value/suffix",
+		"repeated":  []any{"src0_value", map[string]any{"nested": "src0_value"}},
+		"dependent": "dependent_value",
+	}, res.ToStringMap())
+
+	// Identical invocations are retrieved and watched once.
+	assert.Equal(t, map[string]int{
+		"src0:selector":   1,
+		"src1:selector":   1,
+		"src1:src0_value": 1,
+	}, retrievals)
+	assert.Len(t, manager.watchers, 1)
+}
+
+func TestConfigSourceManager_ResolutionTimeout(t *testing.T) {
+	t.Setenv(resolutionTimeoutEnvVar, "10ms")
+
+	// The blocking config source ignores the context, like one waiting on an unresponsive server.
+	unblock := make(chan struct{})
+	defer close(unblock)
+	manager := newManager(map[string]configsource.ConfigSource{
+		"blocking": &testConfigSource{
+			OnRetrieve: func(context.Context, string, *confmap.Conf) error {
+				<-unblock
+				return nil
+			},
+		},
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector": {Value: "cfgsrc_value"},
+			},
+		},
+	})
+	require.Equal(t, 10*time.Millisecond, manager.resolutionTimeout)
+
+	res, err := manager.Resolve(context.Background(), confmap.NewFromStringMap(map[string]any{
+		"field0": "$blocking:selector",
+		"field1": "$tstcfgsrc:selector",
+	}))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "config resolution timed out after 10ms waiting for config sources blocking")
+	assert.True(t, errors.As(err, new(*errResolutionTimeout)))
+	assert.Nil(t, res)
+}
+
+func TestResolutionTimeoutFromEnv(t *testing.T) {
+	assert.Equal(t, defaultResolutionTimeout, resolutionTimeoutFromEnv())
+
+	t.Setenv(resolutionTimeoutEnvVar, "0")
+	assert.Equal(t, time.Duration(0), resolutionTimeoutFromEnv())
+
+	t.Setenv(resolutionTimeoutEnvVar, "30s")
+	assert.Equal(t, 30*time.Second, resolutionTimeoutFromEnv())
+
+	for _, invalid := range []string{"-1s", "30"} {
+		t.Setenv(resolutionTimeoutEnvVar, invalid)
+		assert.Equal(t, defaultResolutionTimeout, resolutionTimeoutFromEnv())
+	}
+}
+
 func TestManager_expandString(t *testing.T) {
 	ctx := context.Background()
 	manager := newManager(map[string]configsource.ConfigSource{
@@ -596,6 +816,32 @@ func TestManager_expandString(t *testing.T) {
 	}
 }
 
+func TestExpandEnvVars(t *testing.T) {
+	t.Setenv("envvar", "envvar_value")
+
+	tests := []struct {
+		name      string
+		input     string
+		want      string
+		wantNames []string
+	}{
+		{name: "no_references", input: "literal", want: "literal"},
+		{name: "bracketed", input: "prefix-${envvar}-suffix", want: "prefix-envvar_value-suffix", wantNames: []string{"envvar"}},
+		{name: "bare", input: "$envvar", want: "envvar_value", wantNames: []string{"envvar"}},
+		{name: "unset", input: "${unset_envvar}/${envvar}", want: "/envvar_value", wantNames: []string{"unset_envvar", "envvar"}},
+		{name: "escaped", input: "$$envvar", want: "$$envvar"},
+		{name: "config_source", input: "${tstcfgsrc:str_key}-$envvar", want: "${tstcfgsrc:str_key}-envvar_value", wantNames: []string{"envvar"}},
+		{name: "bare_config_source", input: "$envvar-$tstcfgsrc:$envvar", want: "envvar_value-$tstcfgsrc:$envvar", wantNames: []string{"envvar"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, names := ExpandEnvVars(tt.input)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, tt.wantNames, names)
+		})
+	}
+}
+
 func Test_parseCfgSrc(t *testing.T) {
 	tests := []struct {
 		params     any
@@ -0,0 +1,128 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// resolutionReportEnvVar enables the report of the env vars and config source values resolved into the
+// configuration (false by default).
+const resolutionReportEnvVar = "SPLUNK_CONFIG_RESOLUTION_REPORT"
+
+const (
+	// ReferenceEnvVar is the kind of references to environment variables.
+	ReferenceEnvVar = "env"
+	// ReferenceConfigSource is the kind of references to config source values.
+	ReferenceConfigSource = "config_source"
+)
+
+// Reference is an environment variable or config source value resolved into the configuration.
+type Reference struct {
+	// Kind is either ReferenceEnvVar or ReferenceConfigSource.
+	Kind string
+	// Name is the name of the environment variable or config source.
+	Name string
+	// Selector is the selector of the config source value, empty for environment variables.
+	Selector string
+	// Keys are the configuration keys whose values reference it, sorted.
+	Keys []string
+	// Set is whether the environment variable was set, always true for config source values.
+	Set bool
+}
+
+type referenceID struct {
+	kind     string
+	name     string
+	selector string
+}
+
+// recordReference records the reference made by the value of the configuration key being resolved.
+func (m *Manager) recordReference(kind, name, selector string, set bool) {
+	if m.collecting != nil {
+		return
+	}
+	id := referenceID{kind: kind, name: name, selector: selector}
+	if m.references == nil {
+		m.references = map[referenceID]*Reference{}
+	}
+	ref, ok := m.references[id]
+	if !ok {
+		ref = &Reference{Kind: kind, Name: name, Selector: selector, Set: set}
+		m.references[id] = ref
+	}
+	for _, key := range ref.Keys {
+		if key == m.resolvingKey {
+			return
+		}
+	}
+	ref.Keys = append(ref.Keys, m.resolvingKey)
+	sort.Strings(ref.Keys)
+}
+
+// References returns the environment variables and config source values resolved into the configuration,
+// sorted by kind, name, and selector.
+func (m *Manager) References() []Reference {
+	refs := make([]Reference, 0, len(m.references))
+	for _, ref := range m.references {
+		refs = append(refs, *ref)
+	}
+	sort.Slice(refs, func(i, j int) bool {
+		if refs[i].Kind != refs[j].Kind {
+			return refs[i].Kind < refs[j].Kind
+		}
+		if refs[i].Name != refs[j].Name {
+			return refs[i].Name < refs[j].Name
+		}
+		return refs[i].Selector < refs[j].Selector
+	})
+	return refs
+}
+
+func resolutionReportEnabled() bool {
+	enabled, _ := strconv.ParseBool(strings.ToLower(os.Getenv(resolutionReportEnvVar)))
+	return enabled
+}
+
+// resolutionReport describes the references resolved into the configuration, without their values.
+func resolutionReport(refs []Reference) string {
+	var envVars, values int
+	for _, ref := range refs {
+		if ref.Kind == ReferenceEnvVar {
+			envVars++
+		} else {
+			values++
+		}
+	}
+
+	var report strings.Builder
+	fmt.Fprintf(&report, "Config resolution report: %d environment variables and %d config source values were resolved into the effective config (values redacted)", envVars, values)
+	for _, ref := range refs {
+		switch {
+		case ref.Kind == ReferenceConfigSource:
+			fmt.Fprintf(&report, "\n  config source %q selector %q", ref.Name, ref.Selector)
+		case ref.Set:
+			fmt.Fprintf(&report, "\n  env var %q", ref.Name)
+		default:
+			fmt.Fprintf(&report, "\n  env var %q (unset)", ref.Name)
+		}
+		fmt.Fprintf(&report, " used by %s", strings.Join(ref.Keys, ", "))
+	}
+	return report.String()
+}
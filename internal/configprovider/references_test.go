@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configprovider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+func TestConfigSourceManager_References(t *testing.T) {
+	t.Setenv("REF_SET", "secret_value")
+
+	manager := newManager(map[string]configsource.ConfigSource{
+		"tstcfgsrc": &testConfigSource{
+			ValueMap: map[string]valueEntry{
+				"selector":   {Value: "cfgsrc_value"},
+				"params_key": {Value: "params_value"},
+			},
+		},
+	})
+	_, err := manager.Resolve(context.Background(), confmap.NewFromStringMap(map[string]any{
+		"top": map[string]any{
+			"envvar":  "${REF_SET}/suffix",
+			"unset":   "$REF_UNSET",
+			"cfgsrc":  "${tstcfgsrc:selector}",
+			"mixed":   "${tstcfgsrc:selector}-${REF_SET}",
+			"params":  "${tstcfgsrc:params_key?p0=$REF_PARAM}",
+			"escaped": "$$REF_ESCAPED",
+			"lone":    "pattern$",
+			"literal": "plain",
+		},
+		"list": []any{"$REF_SET", "${REF_SET}"},
+	}))
+	require.NoError(t, err)
+
+	assert.Equal(t, []Reference{
+		{Kind: ReferenceConfigSource, Name: "tstcfgsrc", Selector: "params_key", Keys: []string{"top::params"}, Set: true},
+		{Kind: ReferenceConfigSource, Name: "tstcfgsrc", Selector: "selector", Keys: []string{"top::cfgsrc", "top::mixed"}, Set: true},
+		{Kind: ReferenceEnvVar, Name: "REF_PARAM", Keys: []string{"top::params"}},
+		{Kind: ReferenceEnvVar, Name: "REF_SET", Keys: []string{"list", "top::envvar", "top::mixed"}, Set: true},
+		{Kind: ReferenceEnvVar, Name: "REF_UNSET", Keys: []string{"top::unset"}},
+	}, manager.References())
+	assert.NoError(t, manager.Close(context.Background()))
+}
+
+func TestResolutionReport(t *testing.T) {
+	report := resolutionReport([]Reference{
+		{Kind: ReferenceConfigSource, Name: "vault", Selector: "secret/data/hec", Keys: []string{"exporters::splunk_hec::token"}, Set: true},
+		{Kind: ReferenceEnvVar, Name: "SPLUNK_ACCESS_TOKEN", Keys: []string{"exporters::sapm::access_token", "exporters::signalfx::access_token"}, Set: true},
+		{Kind: ReferenceEnvVar, Name: "SPLUNK_LISTEN_INTERFACE", Keys: []string{"receivers::otlp::protocols::grpc::endpoint"}},
+	})
+	assert.Equal(t, `Config resolution report: 2 environment variables and 1 config source values were resolved into the effective config (values redacted)
+  config source "vault" selector "secret/data/hec" used by exporters::splunk_hec::token
+  env var "SPLUNK_ACCESS_TOKEN" used by exporters::sapm::access_token, exporters::signalfx::access_token
+  env var "SPLUNK_LISTEN_INTERFACE" (unset) used by receivers::otlp::protocols::grpc::endpoint`, report)
+}
+
+func TestResolutionReportEnabled(t *testing.T) {
+	assert.False(t, resolutionReportEnabled())
+	t.Setenv(resolutionReportEnvVar, "true")
+	assert.True(t, resolutionReportEnabled())
+	t.Setenv(resolutionReportEnvVar, "not a bool")
+	assert.False(t, resolutionReportEnabled())
+}
@@ -32,6 +32,7 @@ type testConfigSource struct {
 	ErrOnClose       error
 
 	OnRetrieve func(ctx context.Context, selector string, paramsConfigMap *confmap.Conf) error
+	OnClose    func()
 }
 
 type valueEntry struct {
@@ -76,5 +77,8 @@ func (t *testConfigSource) RetrieveEnd(context.Context) error {
 }
 
 func (t *testConfigSource) Close(context.Context) error {
+	if t.OnClose != nil {
+		t.OnClose()
+	}
 	return t.ErrOnClose
 }
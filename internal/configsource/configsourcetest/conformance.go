@@ -0,0 +1,195 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package configsourcetest provides a conformance suite enforcing the semantics the config source
+// manager relies on, and a mock config source for the tests of config source users.
+package configsourcetest
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const (
+	// watchTimeout bounds how long watchers take to return after an update or the closing of their source.
+	watchTimeout = 10 * time.Second
+	// blockedTime is how long watchers must keep blocking without an update or the closing of their source.
+	blockedTime = 100 * time.Millisecond
+
+	concurrentRetrievers  = 8
+	retrievesPerRetriever = 4
+)
+
+// Fixture is a config source under test, with a selector whose value it retrieves.
+type Fixture struct {
+	// Source is the config source under test.
+	Source configsource.ConfigSource
+	// Selector is a selector whose retrieval succeeds.
+	Selector string
+	// Params are the retrieval params of the Selector, if any.
+	Params *confmap.Conf
+	// ExpectedValue is the value retrieved for the Selector.
+	ExpectedValue any
+	// MissingSelector is a selector whose retrieval fails. Failed retrievals aren't tested if empty, for
+	// sources retrieving default values of missing selectors.
+	MissingSelector string
+	// Update changes the value of the Selector once it's being watched. Updates aren't tested if nil,
+	// for sources whose retrieved values aren't watchable.
+	Update func(t *testing.T)
+}
+
+// TestConformance runs the conformance suite against the config sources of the fixtures returned by
+// newFixture, which is called once per subtest. It verifies that:
+//
+//   - the Selector is retrieved as the ExpectedValue, and the retrieval of the MissingSelector fails;
+//   - retrievals are safe for concurrent use, when run with the race detector;
+//   - Close succeeds without retrievals, and when called again;
+//   - the watchers of retrieved values block until the value is updated or the source is closed,
+//     returning errors wrapping configsource.ErrValueUpdated or configsource.ErrSessionClosed;
+//   - closing the source ends the watchers of all its retrieved values, including those started later.
+func TestConformance(t *testing.T, newFixture func(t *testing.T) Fixture) {
+	t.Run("retrieve", func(t *testing.T) {
+		testRetrieve(t, newFixture(t))
+	})
+	t.Run("retrieve_missing", func(t *testing.T) {
+		testRetrieveMissing(t, newFixture(t))
+	})
+	t.Run("concurrent_retrieve", func(t *testing.T) {
+		testConcurrentRetrieve(t, newFixture(t))
+	})
+	t.Run("close", func(t *testing.T) {
+		testClose(t, newFixture(t))
+	})
+	t.Run("watch_close", func(t *testing.T) {
+		testWatchClose(t, newFixture(t))
+	})
+	t.Run("watch_update", func(t *testing.T) {
+		testWatchUpdate(t, newFixture(t))
+	})
+}
+
+func testRetrieve(t *testing.T, f Fixture) {
+	retrieved, err := f.Source.Retrieve(context.Background(), f.Selector, f.Params)
+	require.NoError(t, err)
+	require.NotNil(t, retrieved)
+	assert.Equal(t, f.ExpectedValue, retrieved.Value())
+	assert.NoError(t, f.Source.Close(context.Background()))
+}
+
+func testRetrieveMissing(t *testing.T, f Fixture) {
+	defer func() { assert.NoError(t, f.Source.Close(context.Background())) }()
+	if f.MissingSelector == "" {
+		t.Skip("the source has no missing selector")
+	}
+	retrieved, err := f.Source.Retrieve(context.Background(), f.MissingSelector, f.Params)
+	assert.Error(t, err)
+	assert.Nil(t, retrieved)
+}
+
+func testConcurrentRetrieve(t *testing.T, f Fixture) {
+	retrieved := make([]configsource.Retrieved, concurrentRetrievers*retrievesPerRetriever)
+	errs := make([]error, len(retrieved))
+	var wg sync.WaitGroup
+	for i := 0; i < concurrentRetrievers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := i * retrievesPerRetriever; j < (i+1)*retrievesPerRetriever; j++ {
+				retrieved[j], errs[j] = f.Source.Retrieve(context.Background(), f.Selector, f.Params)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	var watchers []<-chan error
+	for i := range retrieved {
+		require.NoError(t, errs[i])
+		assert.Equal(t, f.ExpectedValue, retrieved[i].Value())
+		if watchable, ok := retrieved[i].(configsource.Watchable); ok {
+			watchers = append(watchers, watch(watchable))
+		}
+	}
+	require.NoError(t, f.Source.Close(context.Background()))
+	for _, watcher := range watchers {
+		requireWatcherReturns(t, watcher, configsource.ErrSessionClosed)
+	}
+}
+
+func testClose(t *testing.T, f Fixture) {
+	assert.NoError(t, f.Source.Close(context.Background()))
+	assert.NotPanics(t, func() {
+		assert.NoError(t, f.Source.Close(context.Background()))
+	}, "closing the source again")
+}
+
+func testWatchClose(t *testing.T, f Fixture) {
+	retrieved, err := f.Source.Retrieve(context.Background(), f.Selector, f.Params)
+	require.NoError(t, err)
+	watchable, ok := retrieved.(configsource.Watchable)
+	if !ok {
+		require.NoError(t, f.Source.Close(context.Background()))
+		t.Skip("the retrieved value isn't watchable")
+	}
+
+	watcher := watch(watchable)
+	select {
+	case err = <-watcher:
+		t.Fatalf("the watcher returned without an update or the source being closed: %v", err)
+	case <-time.After(blockedTime):
+	}
+	require.NoError(t, f.Source.Close(context.Background()))
+	requireWatcherReturns(t, watcher, configsource.ErrSessionClosed)
+	requireWatcherReturns(t, watch(watchable), configsource.ErrSessionClosed)
+}
+
+func testWatchUpdate(t *testing.T, f Fixture) {
+	defer func() { assert.NoError(t, f.Source.Close(context.Background())) }()
+	if f.Update == nil {
+		t.Skip("the source has no update")
+	}
+	retrieved, err := f.Source.Retrieve(context.Background(), f.Selector, f.Params)
+	require.NoError(t, err)
+	watchable, ok := retrieved.(configsource.Watchable)
+	require.True(t, ok, "the retrieved value of an updatable source must be watchable")
+
+	watcher := watch(watchable)
+	f.Update(t)
+	requireWatcherReturns(t, watcher, configsource.ErrValueUpdated)
+}
+
+// watch runs WatchForUpdate in a goroutine, returning a channel receiving its error.
+func watch(watchable configsource.Watchable) <-chan error {
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchable.WatchForUpdate()
+	}()
+	return errCh
+}
+
+func requireWatcherReturns(t *testing.T, watcher <-chan error, expected error) {
+	select {
+	case err := <-watcher:
+		require.Truef(t, errors.Is(err, expected), "the watcher returned %v instead of an error wrapping %v", err, expected)
+	case <-time.After(watchTimeout):
+		require.Failf(t, "the watcher didn't return", "expected an error wrapping %v within %s", expected, watchTimeout)
+	}
+}
@@ -0,0 +1,58 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsourcetest
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/config"
+	expcfg "go.opentelemetry.io/collector/config/experimental/config"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// Config is the configuration of mock config sources, which have no settings.
+type Config struct {
+	expcfg.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+}
+
+func (*Config) Validate() error {
+	return nil
+}
+
+type mockFactory struct {
+	source  *ConfigSource
+	typeStr config.Type
+}
+
+// NewFactory creates a factory of config sources of the type, which all are the mock config source.
+func NewFactory(typeStr config.Type, source *ConfigSource) configprovider.Factory {
+	return &mockFactory{source: source, typeStr: typeStr}
+}
+
+func (f *mockFactory) Type() config.Type {
+	return f.typeStr
+}
+
+func (f *mockFactory) CreateDefaultConfig() expcfg.Source {
+	return &Config{
+		SourceSettings: expcfg.NewSourceSettings(config.NewComponentID(f.typeStr)),
+	}
+}
+
+func (f *mockFactory) CreateConfigSource(context.Context, configprovider.CreateParams, expcfg.Source) (configsource.ConfigSource, error) {
+	return f.source, nil
+}
@@ -0,0 +1,159 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsourcetest
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+// ConfigSource is a mock config source for the tests of config source users, whose values can be
+// updated, deleted, and whose watchers can be failed. It passes the conformance suite.
+type ConfigSource struct {
+	values      map[string]any
+	updated     map[string]chan struct{}
+	retrievals  map[string]int
+	failed      chan struct{}
+	watchErr    error
+	retrieveErr error
+	closeCh     chan struct{}
+	closeOnce   sync.Once
+	lock        sync.Mutex
+}
+
+var _ configsource.ConfigSource = (*ConfigSource)(nil)
+
+// NewConfigSource creates a mock config source retrieving the values of their selectors.
+func NewConfigSource(values map[string]any) *ConfigSource {
+	s := &ConfigSource{
+		values:     map[string]any{},
+		updated:    map[string]chan struct{}{},
+		retrievals: map[string]int{},
+		failed:     make(chan struct{}),
+		closeCh:    make(chan struct{}),
+	}
+	for selector, value := range values {
+		s.values[selector] = value
+	}
+	return s
+}
+
+// Retrieve returns the value of the selector, watchable for its updates, or an error if it has no value.
+func (s *ConfigSource) Retrieve(_ context.Context, selector string, _ *confmap.Conf) (configsource.Retrieved, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.retrieveErr != nil {
+		return nil, s.retrieveErr
+	}
+	value, ok := s.values[selector]
+	if !ok {
+		return nil, fmt.Errorf("selector %q not found", selector)
+	}
+	s.retrievals[selector]++
+
+	updated, ok := s.updated[selector]
+	if !ok {
+		updated = make(chan struct{})
+		s.updated[selector] = updated
+	}
+	failed := s.failed
+	return configprovider.NewWatchableRetrieved(value, func() error {
+		select {
+		case <-s.closeCh:
+			return configsource.ErrSessionClosed
+		default:
+		}
+		select {
+		case <-s.closeCh:
+			return configsource.ErrSessionClosed
+		case <-updated:
+			return fmt.Errorf("selector %q updated: %w", selector, configsource.ErrValueUpdated)
+		case <-failed:
+			s.lock.Lock()
+			defer s.lock.Unlock()
+			return s.watchErr
+		}
+	}), nil
+}
+
+// Close ends the watchers of the retrieved values. It can be called more than once.
+func (s *ConfigSource) Close(context.Context) error {
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
+	return nil
+}
+
+// Set sets the value of the selector, ending the watchers of its retrieved values with an update.
+func (s *ConfigSource) Set(selector string, value any) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.values[selector] = value
+	s.notifyUpdate(selector)
+}
+
+// Delete deletes the value of the selector, ending the watchers of its retrieved values with an update.
+func (s *ConfigSource) Delete(selector string) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	delete(s.values, selector)
+	s.notifyUpdate(selector)
+}
+
+func (s *ConfigSource) notifyUpdate(selector string) {
+	if updated, ok := s.updated[selector]; ok {
+		close(updated)
+		delete(s.updated, selector)
+	}
+}
+
+// FailWatchers ends the watchers of the values retrieved so far with the error.
+func (s *ConfigSource) FailWatchers(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.watchErr = err
+	close(s.failed)
+	s.failed = make(chan struct{})
+}
+
+// SetRetrieveError sets the error returned by all retrievals, none if nil.
+func (s *ConfigSource) SetRetrieveError(err error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.retrieveErr = err
+}
+
+// Retrievals returns the number of successful retrievals of the selector.
+func (s *ConfigSource) Retrievals(selector string) int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.retrievals[selector]
+}
+
+// Closed returns whether the source was closed.
+func (s *ConfigSource) Closed() bool {
+	select {
+	case <-s.closeCh:
+		return true
+	default:
+		return false
+	}
+}
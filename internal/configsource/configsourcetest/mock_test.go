@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package configsourcetest
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestConfigSourceConformance(t *testing.T) {
+	TestConformance(t, func(t *testing.T) Fixture {
+		source := NewConfigSource(map[string]any{"key": "value"})
+		return Fixture{
+			Source:          source,
+			Selector:        "key",
+			ExpectedValue:   "value",
+			MissingSelector: "missing",
+			Update: func(t *testing.T) {
+				source.Set("key", "updated")
+			},
+		}
+	})
+}
+
+func TestConfigSourceSetAndDelete(t *testing.T) {
+	source := NewConfigSource(map[string]any{"key": "value"})
+	t.Cleanup(func() { assert.NoError(t, source.Close(context.Background())) })
+
+	retrieved, err := source.Retrieve(context.Background(), "key", nil)
+	require.NoError(t, err)
+	source.Set("key", "updated")
+	assert.ErrorIs(t, retrieved.(configsource.Watchable).WatchForUpdate(), configsource.ErrValueUpdated)
+
+	retrieved, err = source.Retrieve(context.Background(), "key", nil)
+	require.NoError(t, err)
+	assert.Equal(t, "updated", retrieved.Value())
+	assert.Equal(t, 2, source.Retrievals("key"))
+
+	source.Delete("key")
+	assert.ErrorIs(t, retrieved.(configsource.Watchable).WatchForUpdate(), configsource.ErrValueUpdated)
+	_, err = source.Retrieve(context.Background(), "key", nil)
+	assert.Error(t, err)
+	assert.Equal(t, 2, source.Retrievals("key"))
+}
+
+func TestConfigSourceFailWatchers(t *testing.T) {
+	source := NewConfigSource(map[string]any{"key": "value"})
+	t.Cleanup(func() { assert.NoError(t, source.Close(context.Background())) })
+
+	retrieved, err := source.Retrieve(context.Background(), "key", nil)
+	require.NoError(t, err)
+	watchErr := errors.New("watch failed")
+	source.FailWatchers(watchErr)
+	assert.Equal(t, watchErr, retrieved.(configsource.Watchable).WatchForUpdate())
+
+	source.SetRetrieveError(errors.New("retrieve failed"))
+	_, err = source.Retrieve(context.Background(), "key", nil)
+	assert.EqualError(t, err, "retrieve failed")
+	source.SetRetrieveError(nil)
+	_, err = source.Retrieve(context.Background(), "key", nil)
+	assert.NoError(t, err)
+}
+
+func TestConfigSourceClose(t *testing.T) {
+	source := NewConfigSource(nil)
+	assert.False(t, source.Closed())
+	require.NoError(t, source.Close(context.Background()))
+	assert.True(t, source.Closed())
+}
+
+func TestFactory(t *testing.T) {
+	source := NewConfigSource(nil)
+	factory := NewFactory("mock", source)
+	assert.Equal(t, config.Type("mock"), factory.Type())
+
+	cfg := factory.CreateDefaultConfig()
+	assert.Equal(t, config.NewComponentID("mock"), cfg.ID())
+	assert.NoError(t, cfg.(*Config).Validate())
+
+	created, err := factory.CreateConfigSource(context.Background(), configprovider.CreateParams{}, cfg)
+	require.NoError(t, err)
+	assert.Same(t, source, created)
+}
@@ -0,0 +1,43 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"time"
+
+	expcfg "go.opentelemetry.io/collector/config/experimental/config"
+)
+
+// Config defines consulconfigsource configuration
+type Config struct {
+	expcfg.SourceSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Endpoint is the address of the Consul agent the config source connects to.
+	Endpoint string `mapstructure:"endpoint"`
+
+	// Token is the optional ACL token used to authenticate with Consul.
+	Token string `mapstructure:"token"`
+
+	// Datacenter is the optional datacenter to query. The agent's datacenter is used by default.
+	Datacenter string `mapstructure:"datacenter"`
+
+	// WaitTime is the maximum duration of the blocking queries used to watch
+	// retrieved keys for updates.
+	WaitTime time.Duration `mapstructure:"wait_time"`
+}
+
+func (*Config) Validate() error {
+	return nil
+}
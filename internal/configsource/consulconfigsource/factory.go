@@ -0,0 +1,83 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	expcfg "go.opentelemetry.io/collector/config/experimental/config"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const (
+	// The "type" of consul config sources in configuration.
+	typeStr = "consul"
+
+	defaultEndpoint = "http://localhost:8500"
+	defaultWaitTime = 5 * time.Minute
+	// maxWaitTime is the longest blocking query supported by Consul.
+	maxWaitTime = 10 * time.Minute
+)
+
+// Private error types to help with testability.
+type (
+	errMissingEndpoint struct{ error }
+	errInvalidEndpoint struct{ error }
+	errInvalidWaitTime struct{ error }
+)
+
+type consulFactory struct{}
+
+func (v *consulFactory) Type() config.Type {
+	return typeStr
+}
+
+func (v *consulFactory) CreateDefaultConfig() expcfg.Source {
+	return &Config{
+		SourceSettings: expcfg.NewSourceSettings(config.NewComponentID(typeStr)),
+		Endpoint:       defaultEndpoint,
+		WaitTime:       defaultWaitTime,
+	}
+}
+
+func (v *consulFactory) CreateConfigSource(_ context.Context, params configprovider.CreateParams, cfg expcfg.Source) (configsource.ConfigSource, error) {
+	consulCfg := cfg.(*Config)
+
+	if consulCfg.Endpoint == "" {
+		return nil, &errMissingEndpoint{errors.New("cannot connect to consul without an endpoint")}
+	}
+
+	if _, err := url.ParseRequestURI(consulCfg.Endpoint); err != nil {
+		return nil, &errInvalidEndpoint{fmt.Errorf("invalid endpoint %q: %w", consulCfg.Endpoint, err)}
+	}
+
+	if consulCfg.WaitTime <= 0 || consulCfg.WaitTime > maxWaitTime {
+		return nil, &errInvalidWaitTime{fmt.Errorf("wait_time must be positive and at most %v, got %v", maxWaitTime, consulCfg.WaitTime)}
+	}
+
+	return newConfigSource(params, consulCfg)
+}
+
+// NewFactory creates a new consulFactory instance
+func NewFactory() configprovider.Factory {
+	return &consulFactory{}
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+func TestConsulFactory_CreateConfigSource(t *testing.T) {
+	factory := NewFactory()
+	assert.Equal(t, config.Type("consul"), factory.Type())
+	createParams := configprovider.CreateParams{
+		Logger: zap.NewNop(),
+	}
+	tests := []struct {
+		wantErr error
+		config  *Config
+		name    string
+	}{
+		{
+			name:    "missing_endpoint",
+			config:  &Config{WaitTime: time.Minute},
+			wantErr: &errMissingEndpoint{},
+		},
+		{
+			name: "invalid_endpoint",
+			config: &Config{
+				Endpoint: "some\bad/endpoint",
+				WaitTime: time.Minute,
+			},
+			wantErr: &errInvalidEndpoint{},
+		},
+		{
+			name: "missing_wait_time",
+			config: &Config{
+				Endpoint: "http://localhost:8500",
+			},
+			wantErr: &errInvalidWaitTime{},
+		},
+		{
+			name: "wait_time_too_long",
+			config: &Config{
+				Endpoint: "http://localhost:8500",
+				WaitTime: time.Hour,
+			},
+			wantErr: &errInvalidWaitTime{},
+		},
+		{
+			name:   "default_config",
+			config: factory.CreateDefaultConfig().(*Config),
+		},
+		{
+			name: "success",
+			config: &Config{
+				Endpoint:   "https://localhost:8501",
+				Token:      "token",
+				Datacenter: "dc2",
+				WaitTime:   time.Minute,
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			actual, err := factory.CreateConfigSource(context.Background(), createParams, tt.config)
+			require.IsType(t, tt.wantErr, err)
+			if tt.wantErr == nil {
+				assert.NotNil(t, actual)
+			} else {
+				assert.Nil(t, actual)
+			}
+		})
+	}
+}
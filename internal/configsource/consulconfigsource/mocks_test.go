@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"sync"
+
+	"github.com/hashicorp/consul/api"
+)
+
+type mockResponse struct {
+	value *string
+	index uint64
+}
+
+// mockKV answers non-blocking queries from db and blocking queries
+// from the responses and errors channels, recording the WaitIndex of all queries.
+type mockKV struct {
+	db          map[string]string
+	responses   chan mockResponse
+	errors      chan error
+	waitIndexes []uint64
+	index       uint64
+	lock        sync.Mutex
+}
+
+func newMockKV(db map[string]string) *mockKV {
+	return &mockKV{
+		db:        db,
+		responses: make(chan mockResponse),
+		errors:    make(chan error),
+		index:     10,
+	}
+}
+
+func (kv *mockKV) Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error) {
+	kv.lock.Lock()
+	kv.waitIndexes = append(kv.waitIndexes, q.WaitIndex)
+	kv.lock.Unlock()
+	if q.WaitIndex == 0 {
+		meta := &api.QueryMeta{LastIndex: kv.index}
+		if v, ok := kv.db[key]; ok {
+			return &api.KVPair{Key: key, Value: []byte(v)}, meta, nil
+		}
+		return nil, meta, nil
+	}
+
+	select {
+	case <-q.Context().Done():
+		return nil, nil, q.Context().Err()
+	case err := <-kv.errors:
+		return nil, nil, err
+	case resp := <-kv.responses:
+		meta := &api.QueryMeta{LastIndex: resp.index}
+		if resp.value == nil {
+			return nil, meta, nil
+		}
+		return &api.KVPair{Key: key, Value: []byte(*resp.value)}, meta, nil
+	}
+}
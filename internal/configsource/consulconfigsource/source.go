@@ -0,0 +1,151 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/cenkalti/backoff/v4"
+	"github.com/hashicorp/consul/api"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+)
+
+const maxBackoffTime = time.Second * 60
+
+// kvClient defines the subset of the Consul KV API used by the config source
+// so that it can be mocked in tests.
+type kvClient interface {
+	Get(key string, q *api.QueryOptions) (*api.KVPair, *api.QueryMeta, error)
+}
+
+// consulConfigSource implements the configsource.Session interface.
+type consulConfigSource struct {
+	logger     *zap.Logger
+	kv         kvClient
+	closeFuncs []func()
+	waitTime   time.Duration
+	closed     bool
+	lock       sync.Mutex
+}
+
+func newConfigSource(params configprovider.CreateParams, cfg *Config) (configsource.ConfigSource, error) {
+	// The default config honors the CONSUL_* environment variables, e.g. for TLS settings.
+	consulCfg := api.DefaultConfig()
+	consulCfg.Address = cfg.Endpoint
+	if cfg.Token != "" {
+		consulCfg.Token = cfg.Token
+	}
+	if cfg.Datacenter != "" {
+		consulCfg.Datacenter = cfg.Datacenter
+	}
+	consulClient, err := api.NewClient(consulCfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &consulConfigSource{
+		logger:     params.Logger,
+		kv:         consulClient.KV(),
+		closeFuncs: []func(){},
+		waitTime:   cfg.WaitTime,
+	}, nil
+}
+
+func (s *consulConfigSource) Retrieve(ctx context.Context, selector string, _ *confmap.Conf) (configsource.Retrieved, error) {
+	pair, meta, err := s.kv.Get(selector, (&api.QueryOptions{}).WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	if pair == nil {
+		return nil, fmt.Errorf("key %q not found", selector)
+	}
+
+	watchCtx, cancel := context.WithCancel(context.Background())
+	s.addCloseFunc(cancel)
+
+	return configprovider.NewWatchableRetrieved(string(pair.Value), s.newWatcher(watchCtx, selector, pair.Value, meta.LastIndex)), nil
+}
+
+func (s *consulConfigSource) Close(context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
+	for _, cancel := range s.closeFuncs {
+		cancel()
+	}
+	s.closeFuncs = nil
+
+	return nil
+}
+
+// addCloseFunc registers the cancellation of a watcher, cancelling it right away if the source is closed.
+func (s *consulConfigSource) addCloseFunc(cancel func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		cancel()
+		return
+	}
+	s.closeFuncs = append(s.closeFuncs, cancel)
+}
+
+// newWatcher returns a function that performs blocking queries on the key until its value changes.
+func (s *consulConfigSource) newWatcher(ctx context.Context, selector string, value []byte, index uint64) func() error {
+	return func() error {
+		ebo := backoff.NewExponentialBackOff()
+		ebo.MaxElapsedTime = maxBackoffTime
+		for {
+			opts := &api.QueryOptions{WaitIndex: index, WaitTime: s.waitTime}
+			pair, meta, err := s.kv.Get(selector, opts.WithContext(ctx))
+			if err != nil {
+				if ctx.Err() != nil {
+					return configsource.ErrSessionClosed
+				}
+
+				s.logger.Info("error watching", zap.String("selector", selector), zap.Error(err))
+				next := ebo.NextBackOff()
+				if next == backoff.Stop {
+					return err
+				}
+				select {
+				case <-time.After(next):
+					continue
+				case <-ctx.Done():
+					return configsource.ErrSessionClosed
+				}
+			}
+			ebo.Reset()
+
+			if pair == nil || !bytes.Equal(pair.Value, value) {
+				return configsource.ErrValueUpdated
+			}
+
+			// The blocking query timed out or woke up without a change to the value.
+			// Per Consul's guidance the index is reset if it goes backwards.
+			index = meta.LastIndex
+			if index < opts.WaitIndex {
+				index = 0
+			}
+		}
+	}
+}
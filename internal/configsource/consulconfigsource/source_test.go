@@ -0,0 +1,164 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package consulconfigsource
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/experimental/configsource"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/configsourcetest"
+)
+
+func sPtr(s string) *string {
+	return &s
+}
+
+func TestSessionRetrieve(t *testing.T) {
+	kv := newMockKV(map[string]string{
+		"k1":       "v1",
+		"d1/d2/k1": "v5",
+	})
+
+	source := &consulConfigSource{logger: zap.NewNop(), kv: kv, waitTime: defaultWaitTime}
+	testsCases := []struct {
+		expect *string
+		name   string
+		key    string
+	}{
+		{name: "present", key: "k1", expect: sPtr("v1")},
+		{name: "present/path", key: "d1/d2/k1", expect: sPtr("v5")},
+		{name: "absent", key: "k2", expect: nil},
+	}
+
+	for _, c := range testsCases {
+		t.Run(c.name, func(t *testing.T) {
+			retrieved, err := source.Retrieve(context.Background(), c.key, nil)
+			if c.expect != nil {
+				require.NoError(t, err)
+				assert.Equal(t, *c.expect, retrieved.Value())
+				_, okWatcher := retrieved.(configsource.Watchable)
+				assert.True(t, okWatcher)
+				return
+			}
+			assert.EqualError(t, err, `key "k2" not found`)
+			assert.Nil(t, retrieved)
+		})
+	}
+	assert.NoError(t, source.Close(context.Background()))
+}
+
+func TestWatcher(t *testing.T) {
+	testsCases := []struct {
+		err         error
+		name        string
+		responses   []mockResponse
+		waitIndexes []uint64
+		close       bool
+	}{
+		{
+			name:        "updated",
+			responses:   []mockResponse{{value: sPtr("v2"), index: 11}},
+			waitIndexes: []uint64{0, 10},
+		},
+		{
+			name:        "deleted",
+			responses:   []mockResponse{{index: 11}},
+			waitIndexes: []uint64{0, 10},
+		},
+		{
+			name: "unchanged-then-updated",
+			responses: []mockResponse{
+				{value: sPtr("v1"), index: 10},
+				{value: sPtr("v1"), index: 12},
+				{value: sPtr("v2"), index: 13},
+			},
+			waitIndexes: []uint64{0, 10, 10, 12},
+		},
+		{
+			name: "index-reset",
+			responses: []mockResponse{
+				{value: sPtr("v1"), index: 5},
+				{value: sPtr("v2"), index: 6},
+			},
+			waitIndexes: []uint64{0, 10, 0, 10},
+		},
+		{
+			name:        "client-error-retried",
+			err:         errors.New("client error"),
+			responses:   []mockResponse{{value: sPtr("v2"), index: 11}},
+			waitIndexes: []uint64{0, 10, 10},
+		},
+		{
+			name:        "source-closed",
+			close:       true,
+			waitIndexes: []uint64{0, 10},
+		},
+	}
+
+	for _, c := range testsCases {
+		t.Run(c.name, func(t *testing.T) {
+			kv := newMockKV(map[string]string{"k1": "v1"})
+			source := &consulConfigSource{logger: zap.NewNop(), kv: kv, waitTime: defaultWaitTime}
+			retrieved, err := source.Retrieve(context.Background(), "k1", nil)
+			require.NoError(t, err)
+			assert.Equal(t, "v1", retrieved.Value())
+			retrievedWatcher, okWatcher := retrieved.(configsource.Watchable)
+			require.True(t, okWatcher)
+
+			go func() {
+				if c.close {
+					source.Close(context.Background())
+					return
+				}
+				if c.err != nil {
+					kv.errors <- c.err
+				}
+				for _, resp := range c.responses {
+					kv.responses <- resp
+				}
+			}()
+
+			err = retrievedWatcher.WatchForUpdate()
+			if c.close {
+				assert.ErrorIs(t, err, configsource.ErrSessionClosed)
+			} else {
+				assert.ErrorIs(t, err, configsource.ErrValueUpdated)
+			}
+			assert.Equal(t, c.waitIndexes, kv.waitIndexes)
+			assert.NoError(t, source.Close(context.Background()))
+		})
+	}
+}
+
+func TestConformance(t *testing.T) {
+	configsourcetest.TestConformance(t, func(t *testing.T) configsourcetest.Fixture {
+		kv := newMockKV(map[string]string{"k1": "v1"})
+		return configsourcetest.Fixture{
+			Source:          &consulConfigSource{logger: zap.NewNop(), kv: kv, waitTime: defaultWaitTime},
+			Selector:        "k1",
+			ExpectedValue:   "v1",
+			MissingSelector: "k2",
+			Update: func(t *testing.T) {
+				kv.responses <- mockResponse{value: sPtr("v2"), index: 11}
+			},
+		}
+	})
+}
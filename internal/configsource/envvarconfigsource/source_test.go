@@ -24,6 +24,9 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/config/experimental/configsource"
 	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/configsourcetest"
 )
 
 func TestEnvVarConfigSource_Session(t *testing.T) {
@@ -109,3 +112,16 @@ func TestEnvVarConfigSource_Session(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvVarConfigSource_Conformance(t *testing.T) {
+	const testEnvVarName = "_TEST_ENV_VAR_CFG_SRC_CONFORMANCE"
+	t.Setenv(testEnvVarName, "test_env_value")
+	configsourcetest.TestConformance(t, func(t *testing.T) configsourcetest.Fixture {
+		return configsourcetest.Fixture{
+			Source:          newConfigSource(configprovider.CreateParams{}, &Config{}),
+			Selector:        testEnvVarName,
+			ExpectedValue:   "test_env_value",
+			MissingSelector: "UNDEFINED_ENV_VAR",
+		}
+	})
+}
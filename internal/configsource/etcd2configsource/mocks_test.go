@@ -18,6 +18,7 @@ package etcd2configsource
 import (
 	"context"
 	"errors"
+	"sync"
 
 	"go.etcd.io/etcd/client/v2"
 )
@@ -26,6 +27,7 @@ type MockWatcher struct {
 	values chan string
 	errors chan error
 	closed bool
+	lock   sync.Mutex
 }
 
 func newMockWatcher() *MockWatcher {
@@ -38,7 +40,9 @@ func newMockWatcher() *MockWatcher {
 func (w *MockWatcher) Next(ctx context.Context) (*client.Response, error) {
 	select {
 	case <-ctx.Done():
+		w.lock.Lock()
 		w.closed = true
+		w.lock.Unlock()
 		return nil, context.Canceled
 	case err := <-w.errors:
 		return nil, err
@@ -51,6 +55,12 @@ func (w *MockWatcher) Next(ctx context.Context) (*client.Response, error) {
 	}
 }
 
+func (w *MockWatcher) isClosed() bool {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.closed
+}
+
 type MockKeysAPI struct {
 	db            map[string]string
 	activeWatcher *MockWatcher
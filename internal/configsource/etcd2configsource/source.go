@@ -17,6 +17,7 @@ package etcd2configsource
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/cenkalti/backoff/v4"
@@ -35,6 +36,8 @@ type etcd2ConfigSource struct {
 	logger     *zap.Logger
 	kapi       client.KeysAPI
 	closeFuncs []func()
+	closed     bool
+	lock       sync.Mutex
 }
 
 func newConfigSource(params configprovider.CreateParams, cfg *Config) (configsource.ConfigSource, error) {
@@ -68,19 +71,34 @@ func (s *etcd2ConfigSource) Retrieve(ctx context.Context, selector string, _ *co
 	}
 
 	watchCtx, cancel := context.WithCancel(context.Background())
-	s.closeFuncs = append(s.closeFuncs, cancel)
+	s.addCloseFunc(cancel)
 
 	return configprovider.NewWatchableRetrieved(resp.Node.Value, s.newWatcher(watchCtx, selector, resp.Node.ModifiedIndex)), nil
 }
 
 func (s *etcd2ConfigSource) Close(context.Context) error {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.closed = true
 	for _, cancel := range s.closeFuncs {
 		cancel()
 	}
+	s.closeFuncs = nil
 
 	return nil
 }
 
+// addCloseFunc registers the cancellation of a watcher, cancelling it right away if the source is closed.
+func (s *etcd2ConfigSource) addCloseFunc(cancel func()) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.closed {
+		cancel()
+		return
+	}
+	s.closeFuncs = append(s.closeFuncs, cancel)
+}
+
 func (s *etcd2ConfigSource) newWatcher(ctx context.Context, selector string, index uint64) func() error {
 	return func() error {
 		watcher := s.kapi.Watcher(selector, &client.WatcherOptions{AfterIndex: index})
@@ -93,7 +111,8 @@ func (s *etcd2ConfigSource) newWatcher(ctx context.Context, selector string, ind
 				return configsource.ErrValueUpdated
 			}
 
-			if err == context.Canceled {
+			// The client may wrap the cancellation of the context, e.g. in a cluster error.
+			if ctx.Err() != nil {
 				return configsource.ErrSessionClosed
 			}
 
@@ -23,6 +23,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/config/experimental/configsource"
 	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/configsourcetest"
 )
 
 func sPtr(s string) *string {
@@ -92,7 +94,7 @@ func TestWatcher(t *testing.T) {
 			assert.NotNil(t, retrieved.Value)
 			retrievedWatcher, okWatcher := retrieved.(configsource.Watchable)
 			assert.True(t, okWatcher)
-			assert.False(t, watcher.closed)
+			assert.False(t, watcher.isClosed())
 
 			go func() {
 				switch {
@@ -110,7 +112,7 @@ func TestWatcher(t *testing.T) {
 			switch {
 			case c.close:
 				assert.ErrorIs(t, err, configsource.ErrSessionClosed)
-				assert.True(t, watcher.closed)
+				assert.True(t, watcher.isClosed())
 			case c.err != nil:
 				assert.ErrorIs(t, err, c.err)
 			case c.result != "":
@@ -119,3 +121,18 @@ func TestWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestConformance(t *testing.T) {
+	configsourcetest.TestConformance(t, func(t *testing.T) configsourcetest.Fixture {
+		watcher := newMockWatcher()
+		return configsourcetest.Fixture{
+			Source:          &etcd2ConfigSource{logger: zap.NewNop(), kapi: &MockKeysAPI{db: map[string]string{"k1": "v1"}, activeWatcher: watcher}},
+			Selector:        "k1",
+			ExpectedValue:   "v1",
+			MissingSelector: "k2",
+			Update: func(t *testing.T) {
+				watcher.values <- "v2"
+			},
+		}
+	})
+}
@@ -16,6 +16,8 @@
 package includeconfigsource
 
 import (
+	"time"
+
 	expcfg "go.opentelemetry.io/collector/config/experimental/config"
 )
 
@@ -31,6 +33,10 @@ type Config struct {
 	// be watched for updates or not. The default value is 'false'.
 	// Set it to 'true' to watch the referenced files for changes.
 	WatchFiles bool `mapstructure:"watch_files"`
+	// WatchDebounce is how long to wait after the last change to a watched
+	// file before reloading the configuration, so that files written in
+	// several steps are only reloaded once. The default value is '1s'.
+	WatchDebounce time.Duration `mapstructure:"watch_debounce"`
 }
 
 func (*Config) Validate() error {
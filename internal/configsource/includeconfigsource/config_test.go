@@ -19,6 +19,7 @@ import (
 	"context"
 	"path"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -45,14 +46,22 @@ func TestIncludeConfigSourceLoadConfig(t *testing.T) {
 	expectedSettings := map[string]expcfg.Source{
 		"include": &Config{
 			SourceSettings: expcfg.NewSourceSettings(config.NewComponentID(typeStr)),
+			WatchDebounce:  time.Second,
 		},
 		"include/delete_files": &Config{
 			SourceSettings: expcfg.NewSourceSettings(config.NewComponentIDWithName(typeStr, "delete_files")),
 			DeleteFiles:    true,
+			WatchDebounce:  time.Second,
 		},
 		"include/watch_files": &Config{
 			SourceSettings: expcfg.NewSourceSettings(config.NewComponentIDWithName(typeStr, "watch_files")),
 			WatchFiles:     true,
+			WatchDebounce:  time.Second,
+		},
+		"include/watch_debounce": &Config{
+			SourceSettings: expcfg.NewSourceSettings(config.NewComponentIDWithName(typeStr, "watch_debounce")),
+			WatchFiles:     true,
+			WatchDebounce:  5 * time.Second,
 		},
 	}
 
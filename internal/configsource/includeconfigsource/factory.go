@@ -17,6 +17,7 @@ package includeconfigsource
 
 import (
 	"context"
+	"time"
 
 	"go.opentelemetry.io/collector/config"
 	expcfg "go.opentelemetry.io/collector/config/experimental/config"
@@ -28,6 +29,8 @@ import (
 const (
 	// The "type" of file config sources in configuration.
 	typeStr = "include"
+
+	defaultWatchDebounce = time.Second
 )
 
 type includeFactory struct{}
@@ -39,6 +42,7 @@ func (f *includeFactory) Type() config.Type {
 func (f *includeFactory) CreateDefaultConfig() expcfg.Source {
 	return &Config{
 		SourceSettings: expcfg.NewSourceSettings(config.NewComponentID(typeStr)),
+		WatchDebounce:  defaultWatchDebounce,
 	}
 }
 
@@ -18,6 +18,7 @@ package includeconfigsource
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"go.opentelemetry.io/collector/config"
@@ -42,24 +43,21 @@ func TestIncludeConfigSourceFactory_CreateConfigSource(t *testing.T) {
 		{
 			name: "default",
 			expected: &includeConfigSource{
-				Config:       &Config{},
-				watchedFiles: make(map[string]struct{}),
+				Config: &Config{},
 			},
 		},
 		{
 			name:   "delete_files",
 			config: Config{DeleteFiles: true},
 			expected: &includeConfigSource{
-				Config:       &Config{DeleteFiles: true},
-				watchedFiles: make(map[string]struct{}),
+				Config: &Config{DeleteFiles: true},
 			},
 		},
 		{
 			name:   "watch_files",
 			config: Config{WatchFiles: true},
 			expected: &includeConfigSource{
-				Config:       &Config{WatchFiles: true},
-				watchedFiles: make(map[string]struct{}),
+				Config: &Config{WatchFiles: true},
 			},
 		},
 		{
@@ -70,6 +68,14 @@ func TestIncludeConfigSourceFactory_CreateConfigSource(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "err_on_negative_watch_debounce",
+			config: Config{
+				WatchFiles:    true,
+				WatchDebounce: -time.Second,
+			},
+			wantErr: true,
+		},
 	}
 
 	for _, tt := range tests {
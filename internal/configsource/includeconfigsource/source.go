@@ -21,13 +21,14 @@ import (
 	"errors"
 	"fmt"
 	"os"
+	"sync"
 	"text/template"
 
-	"github.com/fsnotify/fsnotify"
 	"go.opentelemetry.io/collector/config/experimental/configsource"
 	"go.opentelemetry.io/collector/confmap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/filewatcher"
 )
 
 // Private error types to help with testability.
@@ -38,21 +39,24 @@ type (
 // includeConfigSource implements the configsource.Session interface.
 type includeConfigSource struct {
 	*Config
-	watcher      *fsnotify.Watcher
-	watchedFiles map[string]struct{}
+	watcher *filewatcher.Watcher
+	// updated receives the watched files whose content changed, and watchErrs the errors watching them.
+	updated   chan []string
+	watchErrs chan error
+	closed    chan struct{}
+	lock      sync.Mutex
 }
 
 func newConfigSource(_ configprovider.CreateParams, config *Config) (configsource.ConfigSource, error) {
 	if config.DeleteFiles && config.WatchFiles {
 		return nil, errors.New(`cannot be configured with "delete_files" and "watch_files" at the same time`)
 	}
+	if config.WatchDebounce < 0 {
+		return nil, fmt.Errorf(`"watch_debounce" must be non-negative: %s`, config.WatchDebounce)
+	}
 
-	return &includeConfigSource{
-		Config:       config,
-		watchedFiles: make(map[string]struct{}),
-	}, nil
+	return &includeConfigSource{Config: config}, nil
 }
-
 func (is *includeConfigSource) Retrieve(_ context.Context, selector string, paramsConfigMap *confmap.Conf) (configsource.Retrieved, error) {
 	tmpl, err := template.ParseFiles(selector)
 	if err != nil {
@@ -86,54 +90,67 @@ func (is *includeConfigSource) Retrieve(_ context.Context, selector string, para
 }
 
 func (is *includeConfigSource) Close(context.Context) error {
+	is.lock.Lock()
+	defer is.lock.Unlock()
 	if is.watcher != nil {
-		return is.watcher.Close()
+		close(is.closed)
+		err := is.watcher.Close()
+		is.watcher = nil
+		return err
 	}
 
 	return nil
 }
 
+// watchFile adds the file to the set of watched ones. Only the first call returns a watch for
+// update function.
 func (is *includeConfigSource) watchFile(file string) (func() error, error) {
-	var watchForUpdateFn func() error
-	if _, watched := is.watchedFiles[file]; watched {
-		// This file is already watched another watch function is not needed.
-		return watchForUpdateFn, nil
-	}
+	is.lock.Lock()
+	defer is.lock.Unlock()
 
+	var watchForUpdateFn func() error
 	if is.watcher == nil {
 		// First watcher create a real watch for update function.
-		var err error
-		is.watcher, err = fsnotify.NewWatcher()
+		is.updated = make(chan []string, 1)
+		is.watchErrs = make(chan error, 1)
+		is.closed = make(chan struct{})
+		updated, watchErrs := is.updated, is.watchErrs
+		watcher, err := filewatcher.New(is.WatchDebounce, func(changed []string) {
+			select {
+			case updated <- changed:
+			default:
+			}
+		}, func(err error) {
+			select {
+			case watchErrs <- err:
+			default:
+			}
+		})
 		if err != nil {
 			return nil, err
 		}
-
-		watchForUpdateFn = func() error {
-			for {
-				select {
-				case event, ok := <-is.watcher.Events:
-					if !ok {
-						return configsource.ErrSessionClosed
-					}
-					if event.Op&fsnotify.Write == fsnotify.Write {
-						return fmt.Errorf("file used in the config modified: %q: %w", event.Name, configsource.ErrValueUpdated)
-					}
-				case watcherErr, ok := <-is.watcher.Errors:
-					if !ok {
-						return configsource.ErrSessionClosed
-					}
-					return watcherErr
-				}
-			}
-		}
+		is.watcher = watcher
+		watchForUpdateFn = is.waitForUpdate
 	}
 
-	// Now just add the file.
 	if err := is.watcher.Add(file); err != nil {
 		return nil, err
 	}
-
-	is.watchedFiles[file] = struct{}{}
-
 	return watchForUpdateFn, nil
 }
+
+// waitForUpdate blocks until the content of a watched file has changed.
+func (is *includeConfigSource) waitForUpdate() error {
+	is.lock.Lock()
+	updated, watchErrs, closed := is.updated, is.watchErrs, is.closed
+	is.lock.Unlock()
+
+	select {
+	case changed := <-updated:
+		return fmt.Errorf("file used in the config modified: %q: %w", changed[0], configsource.ErrValueUpdated)
+	case err := <-watchErrs:
+		return err
+	case <-closed:
+		return configsource.ErrSessionClosed
+	}
+}
@@ -17,11 +17,13 @@ package includeconfigsource
 
 import (
 	"context"
+	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
 	"runtime"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -29,6 +31,7 @@ import (
 	"go.opentelemetry.io/collector/confmap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/configsourcetest"
 )
 
 func TestIncludeConfigSource_Session(t *testing.T) {
@@ -147,3 +150,146 @@ func TestIncludeConfigSource_DeleteFileError(t *testing.T) {
 	assert.IsType(t, &errFailedToDeleteFile{}, err)
 	assert.Nil(t, r)
 }
+
+func TestIncludeConfigSource_WatchFileUpdate(t *testing.T) {
+	s, err := newConfigSource(configprovider.CreateParams{}, &Config{WatchFiles: true, WatchDebounce: 50 * time.Millisecond})
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+	defer func() {
+		assert.NoError(t, s.Close(ctx))
+	}()
+
+	dir := t.TempDir()
+	file := path.Join(dir, "scalar_data_file")
+	require.NoError(t, os.WriteFile(file, []byte("42"), 0600))
+	otherFile := path.Join(dir, "other_scalar_data_file")
+	require.NoError(t, os.WriteFile(otherFile, []byte("24"), 0600))
+
+	r, err := s.Retrieve(ctx, file, nil)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("42"), r.Value())
+	watchable, ok := r.(configsource.Watchable)
+	require.True(t, ok)
+
+	// Only the first retrieved value is watchable, but updates to any watched file are reported.
+	r, err = s.Retrieve(ctx, otherFile, nil)
+	require.NoError(t, err)
+	_, ok = r.(configsource.Watchable)
+	assert.False(t, ok)
+
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- watchable.WatchForUpdate()
+	}()
+
+	// Rewriting the same content isn't an update.
+	require.NoError(t, os.WriteFile(file, []byte("42"), 0600))
+	select {
+	case err = <-watchErr:
+		t.Fatalf("unexpected update: %v", err)
+	case <-time.After(250 * time.Millisecond):
+	}
+
+	require.NoError(t, os.WriteFile(otherFile, []byte("2"), 0600))
+	require.NoError(t, os.WriteFile(otherFile, []byte("24242"), 0600))
+	select {
+	case err = <-watchErr:
+		assert.ErrorIs(t, err, configsource.ErrValueUpdated)
+		assert.Contains(t, err.Error(), "other_scalar_data_file")
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected update wasn't reported")
+	}
+}
+
+func TestIncludeConfigSource_WatchFileReplaced(t *testing.T) {
+	s, err := newConfigSource(configprovider.CreateParams{}, &Config{WatchFiles: true})
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	ctx := context.Background()
+	defer func() {
+		assert.NoError(t, s.Close(ctx))
+	}()
+
+	dir := t.TempDir()
+	file := path.Join(dir, "scalar_data_file")
+	require.NoError(t, os.WriteFile(file, []byte("42"), 0600))
+
+	r, err := s.Retrieve(ctx, file, nil)
+	require.NoError(t, err)
+	watchable, ok := r.(configsource.Watchable)
+	require.True(t, ok)
+
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- watchable.WatchForUpdate()
+	}()
+
+	// Config management tools commonly write a temporary file and rename it over the original one.
+	replacement := path.Join(dir, ".scalar_data_file.tmp")
+	require.NoError(t, os.WriteFile(replacement, []byte("43"), 0600))
+	require.NoError(t, os.Rename(replacement, file))
+
+	select {
+	case err = <-watchErr:
+		assert.ErrorIs(t, err, configsource.ErrValueUpdated)
+	case <-time.After(5 * time.Second):
+		t.Fatal("expected update wasn't reported")
+	}
+}
+
+func TestIncludeConfigSource_WatchClosed(t *testing.T) {
+	s, err := newConfigSource(configprovider.CreateParams{}, &Config{WatchFiles: true})
+	require.NoError(t, err)
+	require.NotNil(t, s)
+
+	file := path.Join(t.TempDir(), "scalar_data_file")
+	require.NoError(t, os.WriteFile(file, []byte("42"), 0600))
+
+	r, err := s.Retrieve(context.Background(), file, nil)
+	require.NoError(t, err)
+	watchable, ok := r.(configsource.Watchable)
+	require.True(t, ok)
+
+	watchErr := make(chan error, 1)
+	go func() {
+		watchErr <- watchable.WatchForUpdate()
+	}()
+
+	require.NoError(t, s.Close(context.Background()))
+	select {
+	case err = <-watchErr:
+		assert.ErrorIs(t, err, configsource.ErrSessionClosed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("watch wasn't closed")
+	}
+}
+
+func TestIncludeConfigSource_Conformance(t *testing.T) {
+	for _, watchFiles := range []bool{false, true} {
+		t.Run(fmt.Sprintf("watch_files_%t", watchFiles), func(t *testing.T) {
+			configsourcetest.TestConformance(t, func(t *testing.T) configsourcetest.Fixture {
+				s, err := newConfigSource(configprovider.CreateParams{}, &Config{WatchFiles: watchFiles})
+				require.NoError(t, err)
+
+				dir := t.TempDir()
+				file := path.Join(dir, "scalar_data_file")
+				require.NoError(t, os.WriteFile(file, []byte("42"), 0600))
+				fixture := configsourcetest.Fixture{
+					Source:          s,
+					Selector:        file,
+					ExpectedValue:   []byte("42"),
+					MissingSelector: path.Join(dir, "missing_file"),
+				}
+				if watchFiles {
+					fixture.Update = func(t *testing.T) {
+						require.NoError(t, os.WriteFile(file, []byte("43"), 0600))
+					}
+				}
+				return fixture
+			})
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hashicorp/vault/api"
@@ -52,6 +53,10 @@ type vaultConfigSource struct {
 	path string
 
 	pollInterval time.Duration
+
+	// lock guards the secret against concurrent retrievals.
+	lock      sync.Mutex
+	closeOnce sync.Once
 }
 
 func newConfigSource(params configprovider.CreateParams, cfg *Config) (configsource.ConfigSource, error) {
@@ -89,6 +94,8 @@ func (v *vaultConfigSource) Retrieve(_ context.Context, selector string, _ *conf
 	// value read from the vault secret.
 	var watchForUpdateFn func() error
 
+	v.lock.Lock()
+	defer v.lock.Unlock()
 	if v.secret == nil {
 		if err := v.readSecret(); err != nil {
 			return nil, err
@@ -115,7 +122,7 @@ func (v *vaultConfigSource) Retrieve(_ context.Context, selector string, _ *conf
 }
 
 func (v *vaultConfigSource) Close(context.Context) error {
-	close(v.doneCh)
+	v.closeOnce.Do(func() { close(v.doneCh) })
 
 	// Vault doesn't have a close for its client, close is completed.
 	return nil
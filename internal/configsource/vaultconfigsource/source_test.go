@@ -20,10 +20,13 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"os/exec"
 	"runtime"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -33,6 +36,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/configsourcetest"
 )
 
 const (
@@ -426,6 +430,63 @@ func TestVaultRetrieveErrors(t *testing.T) {
 	}
 }
 
+// fakeKVStore serves a KV v2 secret and its metadata as Vault does, its version bumped by update.
+type fakeKVStore struct {
+	version int
+	lock    sync.Mutex
+}
+
+func (s *fakeKVStore) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	var data map[string]any
+	switch r.URL.Path {
+	case "/v1/secret/data/kv":
+		data = map[string]any{
+			"data":     map[string]any{"k0": "v0"},
+			"metadata": map[string]any{"created_time": "2021-04-02T22:30:51Z", "version": s.version},
+		}
+	case "/v1/secret/metadata/kv":
+		data = map[string]any{"updated_time": fmt.Sprintf("2021-04-02T22:30:5%dZ", s.version), "current_version": s.version}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{"data": data})
+}
+
+func (s *fakeKVStore) update() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.version++
+}
+
+func TestConformance(t *testing.T) {
+	configsourcetest.TestConformance(t, func(t *testing.T) configsourcetest.Fixture {
+		store := &fakeKVStore{version: 1}
+		server := httptest.NewServer(store)
+		t.Cleanup(server.Close)
+
+		source, err := newConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, &Config{
+			Endpoint:       server.URL,
+			Authentication: &Authentication{Token: &tokenStr},
+			Path:           "secret/data/kv",
+			PollInterval:   10 * time.Millisecond,
+		})
+		require.NoError(t, err)
+		return configsourcetest.Fixture{
+			Source:          source,
+			Selector:        "data.k0",
+			ExpectedValue:   "v0",
+			MissingSelector: "data.missing",
+			Update: func(t *testing.T) {
+				store.update()
+			},
+		}
+	})
+}
+
 func Test_vaultSession_extractVersionMetadata(t *testing.T) {
 	tests := []struct {
 		metadataMap map[string]any
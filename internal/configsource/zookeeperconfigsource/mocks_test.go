@@ -18,6 +18,7 @@ package zookeeperconfigsource
 import (
 	"context"
 	"fmt"
+	"sync"
 
 	"github.com/go-zookeeper/zk"
 )
@@ -31,6 +32,7 @@ func newMockConnectFunc(conn zkConnection) connectFunc {
 type mockConnection struct {
 	db      map[string]string
 	watches map[string]chan zk.Event
+	lock    sync.Mutex
 }
 
 func newMockConnection(db map[string]string) *mockConnection {
@@ -41,6 +43,8 @@ func newMockConnection(db map[string]string) *mockConnection {
 }
 
 func (m *mockConnection) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, error) {
+	m.lock.Lock()
+	defer m.lock.Unlock()
 	if value, ok := m.db[key]; ok {
 		ch := make(chan zk.Event)
 		m.watches[key] = ch
@@ -48,3 +52,11 @@ func (m *mockConnection) GetW(key string) ([]byte, *zk.Stat, <-chan zk.Event, er
 	}
 	return nil, nil, nil, fmt.Errorf("value not found")
 }
+
+// notify sends the event to the last watch of the key.
+func (m *mockConnection) notify(key string, event zk.Event) {
+	m.lock.Lock()
+	ch := m.watches[key]
+	m.lock.Unlock()
+	ch <- event
+}
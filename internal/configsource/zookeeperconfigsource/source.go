@@ -19,6 +19,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/go-zookeeper/zk"
@@ -31,9 +32,10 @@ import (
 
 // zkConfigSource implements the configsource.Session interface.
 type zkConfigSource struct {
-	logger  *zap.Logger
-	connect connectFunc
-	closeCh chan struct{}
+	logger    *zap.Logger
+	connect   connectFunc
+	closeCh   chan struct{}
+	closeOnce sync.Once
 }
 
 func newConfigSource(params configprovider.CreateParams, cfg *Config) (configsource.ConfigSource, error) {
@@ -66,7 +68,9 @@ func (s *zkConfigSource) Retrieve(ctx context.Context, selector string, _ *confm
 }
 
 func (s *zkConfigSource) Close(context.Context) error {
-	close(s.closeCh)
+	s.closeOnce.Do(func() {
+		close(s.closeCh)
+	})
 	return nil
 }
 
@@ -95,15 +99,19 @@ func newWatcher(watchCh <-chan zk.Event, closeCh <-chan struct{}) func() error {
 // underlying connection until it is lost.
 func newConnectFunc(endpoints []string, timeout time.Duration) connectFunc {
 	var conn *zk.Conn
+	var lock sync.Mutex
 	return func(ctx context.Context) (zkConnection, error) {
+		lock.Lock()
+		defer lock.Unlock()
 		if conn != nil && conn.State() != zk.StateDisconnected {
 			return conn, nil
 		}
 
-		conn, _, err := zk.Connect(endpoints, timeout, zk.WithLogInfo(false))
+		newConn, _, err := zk.Connect(endpoints, timeout, zk.WithLogInfo(false))
 		if err != nil {
 			return nil, err
 		}
+		conn = newConn
 		return conn, nil
 	}
 }
@@ -26,6 +26,7 @@ import (
 	"go.uber.org/zap"
 
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/configsourcetest"
 )
 
 func sPtr(s string) *string {
@@ -120,3 +121,18 @@ func TestWatcher(t *testing.T) {
 		})
 	}
 }
+
+func TestConformance(t *testing.T) {
+	configsourcetest.TestConformance(t, func(t *testing.T) configsourcetest.Fixture {
+		conn := newMockConnection(map[string]string{"k1": "v1"})
+		return configsourcetest.Fixture{
+			Source:          newZkConfigSource(configprovider.CreateParams{Logger: zap.NewNop()}, newMockConnectFunc(conn)),
+			Selector:        "k1",
+			ExpectedValue:   []byte("v1"),
+			MissingSelector: "k2",
+			Update: func(t *testing.T) {
+				conn.notify("k1", zk.Event{Type: zk.EventNodeDataChanged})
+			},
+		}
+	})
+}
@@ -18,6 +18,7 @@ package configsources
 
 import (
 	"github.com/signalfx/splunk-otel-collector/internal/configprovider"
+	"github.com/signalfx/splunk-otel-collector/internal/configsource/consulconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/envvarconfigsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/etcd2configsource"
 	"github.com/signalfx/splunk-otel-collector/internal/configsource/includeconfigsource"
@@ -28,6 +29,7 @@ import (
 // Get returns the factories to all config sources available to the user.
 func Get() []configprovider.Factory {
 	return []configprovider.Factory{
+		consulconfigsource.NewFactory(),
 		envvarconfigsource.NewFactory(),
 		etcd2configsource.NewFactory(),
 		includeconfigsource.NewFactory(),
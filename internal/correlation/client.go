@@ -0,0 +1,134 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/cenkalti/backoff/v4"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Type is the kind of APM value correlated with a dimension.
+type Type string
+
+const (
+	Service     Type = "service"
+	Environment Type = "environment"
+)
+
+// Correlation associates an APM service or environment with an infrastructure dimension value.
+type Correlation struct {
+	DimensionName  string
+	DimensionValue string
+	Type           Type
+	Value          string
+}
+
+func (c Correlation) String() string {
+	return fmt.Sprintf("%s %q of %s %q", c.Type, c.Value, c.DimensionName, c.DimensionValue)
+}
+
+// Correlator creates and deletes correlations.
+type Correlator interface {
+	Correlate(ctx context.Context, correlation Correlation) error
+	Delete(ctx context.Context, correlation Correlation) error
+}
+
+// Client is a Correlator using the SignalFx API's correlation endpoints, retrying failed requests.
+type Client struct {
+	httpClient  *http.Client
+	endpoint    string
+	accessToken string
+	retry       exporterhelper.RetrySettings
+}
+
+var _ Correlator = (*Client)(nil)
+
+// NewClient creates a Client for the configured endpoint, whose http client can use the host's extensions.
+func NewClient(cfg Config, host component.Host, settings component.TelemetrySettings) (*Client, error) {
+	httpClient, err := cfg.HTTPClientSettings.ToClient(host.GetExtensions(), settings)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating http client: %w", err)
+	}
+	return &Client{
+		httpClient:  httpClient,
+		endpoint:    strings.TrimSuffix(cfg.Endpoint, "/"),
+		accessToken: cfg.AccessToken,
+		retry:       cfg.RetrySettings,
+	}, nil
+}
+
+// Correlate creates the correlation, replacing any prior environment of the dimension value.
+func (c *Client) Correlate(ctx context.Context, correlation Correlation) error {
+	return c.do(ctx, http.MethodPut, c.url(correlation), correlation.Value)
+}
+
+// Delete removes the correlation.
+func (c *Client) Delete(ctx context.Context, correlation Correlation) error {
+	return c.do(ctx, http.MethodDelete, c.url(correlation, correlation.Value), "")
+}
+
+func (c *Client) url(correlation Correlation, extraPath ...string) string {
+	elements := append([]string{correlation.DimensionName, correlation.DimensionValue, string(correlation.Type)}, extraPath...)
+	for i, element := range elements {
+		elements[i] = url.PathEscape(element)
+	}
+	return c.endpoint + "/v2/apm/correlate/" + strings.Join(elements, "/")
+}
+
+func (c *Client) do(ctx context.Context, method, u, body string) error {
+	request := func() error {
+		req, err := http.NewRequestWithContext(ctx, method, u, strings.NewReader(body))
+		if err != nil {
+			return backoff.Permanent(err)
+		}
+		req.Header.Set("Content-Type", "text/plain")
+		req.Header.Set("X-SF-Token", c.accessToken)
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return err
+		}
+		defer resp.Body.Close()
+		_, _ = io.Copy(io.Discard, resp.Body)
+
+		switch {
+		case resp.StatusCode >= 200 && resp.StatusCode < 300:
+			return nil
+		case method == http.MethodDelete && resp.StatusCode == http.StatusNotFound:
+			// already deleted or expired
+			return nil
+		case resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500:
+			return fmt.Errorf("%s %s: %s", method, u, resp.Status)
+		}
+		return backoff.Permanent(fmt.Errorf("%s %s: %s", method, u, resp.Status))
+	}
+
+	var policy backoff.BackOff = &backoff.StopBackOff{}
+	if c.retry.Enabled {
+		expBackoff := backoff.NewExponentialBackOff()
+		expBackoff.InitialInterval = c.retry.InitialInterval
+		expBackoff.MaxInterval = c.retry.MaxInterval
+		expBackoff.MaxElapsedTime = c.retry.MaxElapsedTime
+		policy = expBackoff
+	}
+	return backoff.Retry(request, backoff.WithContext(policy, ctx))
+}
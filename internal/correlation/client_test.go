@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+)
+
+type request struct {
+	method string
+	path   string
+	token  string
+	body   string
+}
+
+type correlationAPI struct {
+	statuses []int
+	requests []request
+	lock     sync.Mutex
+}
+
+func (api *correlationAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.requests = append(api.requests, request{
+		method: r.Method, path: r.URL.EscapedPath(), token: r.Header.Get("X-SF-Token"), body: string(body),
+	})
+	status := http.StatusOK
+	if len(api.statuses) > 0 {
+		status, api.statuses = api.statuses[0], api.statuses[1:]
+	}
+	w.WriteHeader(status)
+}
+
+func newTestClient(t *testing.T, api *correlationAPI, retry bool) *Client {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	cfg := DefaultConfig()
+	cfg.Endpoint = server.URL + "/"
+	cfg.AccessToken = "token"
+	cfg.RetrySettings.Enabled = retry
+	cfg.RetrySettings.InitialInterval = time.Millisecond
+	cfg.RetrySettings.MaxInterval = time.Millisecond
+	client, err := NewClient(cfg, componenttest.NewNopHost(), componenttest.NewNopTelemetrySettings())
+	require.NoError(t, err)
+	return client
+}
+
+var testCorrelation = Correlation{DimensionName: "host", DimensionValue: "my host", Type: Service, Value: "checkout"}
+
+func TestClientCorrelate(t *testing.T) {
+	api := &correlationAPI{}
+	client := newTestClient(t, api, false)
+	require.NoError(t, client.Correlate(context.Background(), testCorrelation))
+	require.NoError(t, client.Delete(context.Background(), testCorrelation))
+	assert.Equal(t, []request{
+		{method: http.MethodPut, path: "/v2/apm/correlate/host/my%20host/service", token: "token", body: "checkout"},
+		{method: http.MethodDelete, path: "/v2/apm/correlate/host/my%20host/service/checkout", token: "token"},
+	}, api.requests)
+}
+
+func TestClientRetries(t *testing.T) {
+	api := &correlationAPI{statuses: []int{http.StatusServiceUnavailable, http.StatusTooManyRequests}}
+	client := newTestClient(t, api, true)
+	require.NoError(t, client.Correlate(context.Background(), testCorrelation))
+	assert.Len(t, api.requests, 3)
+}
+
+func TestClientDoesntRetryPermanentErrors(t *testing.T) {
+	api := &correlationAPI{statuses: []int{http.StatusBadRequest}}
+	client := newTestClient(t, api, true)
+	err := client.Correlate(context.Background(), testCorrelation)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "400 Bad Request")
+	assert.Len(t, api.requests, 1)
+}
+
+func TestClientWithoutRetries(t *testing.T) {
+	api := &correlationAPI{statuses: []int{http.StatusServiceUnavailable}}
+	client := newTestClient(t, api, false)
+	err := client.Correlate(context.Background(), testCorrelation)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "503 Service Unavailable")
+	assert.Len(t, api.requests, 1)
+}
+
+func TestClientDeleteNotFound(t *testing.T) {
+	api := &correlationAPI{statuses: []int{http.StatusNotFound}}
+	client := newTestClient(t, api, true)
+	require.NoError(t, client.Delete(context.Background(), testCorrelation))
+	assert.Len(t, api.requests, 1)
+}
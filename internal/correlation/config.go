@@ -0,0 +1,104 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package correlation correlates the APM services and environments of traces with the infrastructure
+// dimensions, like host and container, of their resources using the SignalFx API.  It's shared by the
+// distribution's trace pipeline components, independent of the exporter the traces are sent with.
+package correlation
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+// Config configures the correlation of the APM services and environments of traces with the infrastructure
+// dimensions of their resources.  It's intended to be embedded by components under a `correlation` key.
+type Config struct {
+	// Resource attributes to the dimension names their values are correlated with, in addition to the
+	// host.name resource attribute's host dimension.
+	SyncAttributes map[string]string `mapstructure:"sync_attributes"`
+	// The SignalFx API access token.
+	AccessToken string `mapstructure:"access_token"`
+	// The resource attribute whose value is the traces' environment.
+	EnvironmentAttribute string `mapstructure:"environment_attribute"`
+	// The SignalFx API URL is the endpoint.
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+	// The retry of failed correlation requests, with an exponential backoff.
+	exporterhelper.RetrySettings `mapstructure:"retry_on_failure"`
+	// How long a correlated service or environment can go without being seen before its correlation is deleted.
+	StaleServiceTimeout time.Duration `mapstructure:"stale_service_timeout"`
+	// How often the buffered correlation updates are sent in a batch of requests.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// The maximum number of correlation updates buffered between flushes, beyond which new ones are dropped.
+	MaxBuffered int `mapstructure:"max_buffered"`
+	// The maximum number of concurrent correlation requests.
+	MaxRequests int `mapstructure:"max_requests"`
+}
+
+// DefaultConfig returns the default correlation config, without the endpoint and access token.
+func DefaultConfig() Config {
+	return Config{
+		SyncAttributes: map[string]string{
+			conventions.AttributeK8SPodUID:   conventions.AttributeK8SPodUID,
+			conventions.AttributeContainerID: conventions.AttributeContainerID,
+		},
+		EnvironmentAttribute: conventions.AttributeDeploymentEnvironment,
+		HTTPClientSettings:   confighttp.HTTPClientSettings{Timeout: 5 * time.Second},
+		RetrySettings:        exporterhelper.NewDefaultRetrySettings(),
+		StaleServiceTimeout:  5 * time.Minute,
+		FlushInterval:        5 * time.Second,
+		MaxBuffered:          10000,
+		MaxRequests:          20,
+	}
+}
+
+// Validate checks if the correlation config is valid.
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	if u, err := url.Parse(cfg.Endpoint); err != nil || u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("invalid endpoint %q", cfg.Endpoint)
+	}
+	if cfg.AccessToken == "" {
+		return errors.New("access_token must not be empty")
+	}
+	if cfg.EnvironmentAttribute == "" {
+		return errors.New("environment_attribute must not be empty")
+	}
+	for attribute, dimension := range cfg.SyncAttributes {
+		if attribute == "" || dimension == "" {
+			return errors.New("sync_attributes must map non-empty attributes to non-empty dimensions")
+		}
+	}
+	if cfg.StaleServiceTimeout <= 0 {
+		return fmt.Errorf("stale_service_timeout must be positive, not %s", cfg.StaleServiceTimeout)
+	}
+	if cfg.FlushInterval <= 0 {
+		return fmt.Errorf("flush_interval must be positive, not %s", cfg.FlushInterval)
+	}
+	if cfg.MaxBuffered <= 0 {
+		return fmt.Errorf("max_buffered must be positive, not %d", cfg.MaxBuffered)
+	}
+	if cfg.MaxRequests <= 0 {
+		return fmt.Errorf("max_requests must be positive, not %d", cfg.MaxRequests)
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDefaultConfig(t *testing.T) {
+	cfg := DefaultConfig()
+	assert.Equal(t, map[string]string{"k8s.pod.uid": "k8s.pod.uid", "container.id": "container.id"}, cfg.SyncAttributes)
+	assert.Equal(t, "deployment.environment", cfg.EnvironmentAttribute)
+	assert.Equal(t, 5*time.Second, cfg.Timeout)
+	assert.True(t, cfg.RetrySettings.Enabled)
+	assert.Equal(t, 5*time.Minute, cfg.StaleServiceTimeout)
+	assert.Equal(t, 5*time.Second, cfg.FlushInterval)
+	assert.Equal(t, 10000, cfg.MaxBuffered)
+	assert.Equal(t, 20, cfg.MaxRequests)
+	assert.EqualError(t, cfg.Validate(), "endpoint must not be empty")
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		modify      func(*Config)
+		name        string
+		expectedErr string
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{
+			name:        "invalid endpoint",
+			modify:      func(cfg *Config) { cfg.Endpoint = "api.signalfx.com" },
+			expectedErr: `invalid endpoint "api.signalfx.com"`,
+		},
+		{
+			name:        "missing access token",
+			modify:      func(cfg *Config) { cfg.AccessToken = "" },
+			expectedErr: "access_token must not be empty",
+		},
+		{
+			name:        "missing environment attribute",
+			modify:      func(cfg *Config) { cfg.EnvironmentAttribute = "" },
+			expectedErr: "environment_attribute must not be empty",
+		},
+		{
+			name:        "empty sync attribute dimension",
+			modify:      func(cfg *Config) { cfg.SyncAttributes["container.id"] = "" },
+			expectedErr: "sync_attributes must map non-empty attributes to non-empty dimensions",
+		},
+		{
+			name:        "non-positive stale service timeout",
+			modify:      func(cfg *Config) { cfg.StaleServiceTimeout = 0 },
+			expectedErr: "stale_service_timeout must be positive, not 0s",
+		},
+		{
+			name:        "non-positive flush interval",
+			modify:      func(cfg *Config) { cfg.FlushInterval = -time.Second },
+			expectedErr: "flush_interval must be positive, not -1s",
+		},
+		{
+			name:        "non-positive max buffered",
+			modify:      func(cfg *Config) { cfg.MaxBuffered = 0 },
+			expectedErr: "max_buffered must be positive, not 0",
+		},
+		{
+			name:        "non-positive max requests",
+			modify:      func(cfg *Config) { cfg.MaxRequests = -1 },
+			expectedErr: "max_requests must be positive, not -1",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := DefaultConfig()
+			cfg.Endpoint = "https://api.us0.signalfx.com"
+			cfg.AccessToken = "token"
+			test.modify(&cfg)
+			err := cfg.Validate()
+			if test.expectedErr == "" {
+				assert.NoError(t, err)
+			} else {
+				assert.EqualError(t, err, test.expectedErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,211 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+	"go.uber.org/zap"
+)
+
+// hostDimension is the dimension correlated with the host.name resource attribute's value.
+const hostDimension = "host"
+
+// Tracker correlates the services and environments of consumed traces with the infrastructure dimensions
+// of their resources.  New correlations are buffered and sent with the Correlator every flush interval,
+// and correlations not seen within the stale service timeout are deleted.  It's safe for concurrent use.
+type Tracker struct {
+	correlator Correlator
+	logger     *zap.Logger
+	now        func() time.Time
+	ctx        context.Context
+	cancel     context.CancelFunc
+	// the last time each created correlation was seen
+	active map[Correlation]time.Time
+	// the buffered updates, whether to create or delete each correlation
+	pending map[Correlation]bool
+	cfg     Config
+	wg      sync.WaitGroup
+	lock    sync.Mutex
+}
+
+// NewTracker creates a Tracker sending the correlations with the provided Correlator.
+func NewTracker(cfg Config, correlator Correlator, logger *zap.Logger) *Tracker {
+	return &Tracker{
+		correlator: correlator,
+		logger:     logger,
+		now:        time.Now,
+		active:     map[Correlation]time.Time{},
+		pending:    map[Correlation]bool{},
+		cfg:        cfg,
+	}
+}
+
+// Start flushes the buffered updates every flush interval until Shutdown.
+func (t *Tracker) Start() {
+	t.ctx, t.cancel = context.WithCancel(context.Background())
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := time.NewTicker(t.cfg.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.ctx.Done():
+				return
+			case <-ticker.C:
+				t.flush(t.ctx)
+			}
+		}
+	}()
+}
+
+// Shutdown stops flushing, canceling any in-flight requests.  Remaining correlations are left to expire.
+func (t *Tracker) Shutdown(context.Context) error {
+	if t.cancel != nil {
+		t.cancel()
+	}
+	t.wg.Wait()
+	return nil
+}
+
+// ConsumeTraces records the correlations of the traces' resources.  It never fails, so it can be called
+// by trace pipeline components without affecting their own consumption.
+func (t *Tracker) ConsumeTraces(_ context.Context, td ptrace.Traces) error {
+	now := t.now()
+	resourceSpans := td.ResourceSpans()
+	for i := 0; i < resourceSpans.Len(); i++ {
+		for _, correlation := range t.correlations(resourceSpans.At(i).Resource().Attributes()) {
+			t.observe(correlation, now)
+		}
+	}
+	return nil
+}
+
+// correlations returns the service and environment correlations of the resource's dimensions.
+func (t *Tracker) correlations(attributes pcommon.Map) []Correlation {
+	var values []Correlation
+	if service := stringAttribute(attributes, conventions.AttributeServiceName); service != "" {
+		values = append(values, Correlation{Type: Service, Value: service})
+	}
+	if environment := stringAttribute(attributes, t.cfg.EnvironmentAttribute); environment != "" {
+		values = append(values, Correlation{Type: Environment, Value: environment})
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	dimensions := map[string]string{}
+	if host := stringAttribute(attributes, conventions.AttributeHostName); host != "" {
+		dimensions[hostDimension] = host
+	}
+	for attribute, dimension := range t.cfg.SyncAttributes {
+		if value := stringAttribute(attributes, attribute); value != "" {
+			dimensions[dimension] = value
+		}
+	}
+
+	correlations := make([]Correlation, 0, len(dimensions)*len(values))
+	for dimension, dimensionValue := range dimensions {
+		for _, value := range values {
+			value.DimensionName = dimension
+			value.DimensionValue = dimensionValue
+			correlations = append(correlations, value)
+		}
+	}
+	return correlations
+}
+
+func stringAttribute(attributes pcommon.Map, key string) string {
+	if value, ok := attributes.Get(key); ok && value.Type() == pcommon.ValueTypeString {
+		return value.StringVal()
+	}
+	return ""
+}
+
+func (t *Tracker) observe(correlation Correlation, now time.Time) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if _, ok := t.active[correlation]; !ok && !t.enqueue(correlation, true) {
+		// not tracked as active so that it's created when seen again
+		return
+	}
+	t.active[correlation] = now
+}
+
+// enqueue buffers the update, replacing any buffered one of the same correlation.  It returns false if
+// the update was dropped because the buffer is full.  The lock must be held.
+func (t *Tracker) enqueue(correlation Correlation, create bool) bool {
+	if _, ok := t.pending[correlation]; !ok && len(t.pending) >= t.cfg.MaxBuffered {
+		t.logger.Debug("Dropping correlation update exceeding max_buffered", zap.Stringer("correlation", correlation))
+		return false
+	}
+	t.pending[correlation] = create
+	return true
+}
+
+// flush buffers the deletion of stale correlations and sends the buffered updates, with at most
+// max_requests concurrent requests.
+func (t *Tracker) flush(ctx context.Context) {
+	t.lock.Lock()
+	staleBefore := t.now().Add(-t.cfg.StaleServiceTimeout)
+	for correlation, lastSeen := range t.active {
+		if lastSeen.Before(staleBefore) {
+			delete(t.active, correlation)
+			t.enqueue(correlation, false)
+		}
+	}
+	pending := t.pending
+	t.pending = map[Correlation]bool{}
+	t.lock.Unlock()
+
+	var wg sync.WaitGroup
+	requests := make(chan struct{}, t.cfg.MaxRequests)
+	for correlation, create := range pending {
+		requests <- struct{}{}
+		wg.Add(1)
+		go func(correlation Correlation, create bool) {
+			defer func() {
+				<-requests
+				wg.Done()
+			}()
+			t.send(ctx, correlation, create)
+		}(correlation, create)
+	}
+	wg.Wait()
+}
+
+func (t *Tracker) send(ctx context.Context, correlation Correlation, create bool) {
+	if !create {
+		if err := t.correlator.Delete(ctx, correlation); err != nil && ctx.Err() == nil {
+			t.logger.Warn("Failed deleting stale correlation", zap.Stringer("correlation", correlation), zap.Error(err))
+		}
+		return
+	}
+	if err := t.correlator.Correlate(ctx, correlation); err != nil {
+		if ctx.Err() == nil {
+			t.logger.Warn("Failed creating correlation", zap.Stringer("correlation", correlation), zap.Error(err))
+		}
+		// retried when seen again
+		t.lock.Lock()
+		delete(t.active, correlation)
+		t.lock.Unlock()
+	}
+}
@@ -0,0 +1,190 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package correlation
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+type fakeCorrelator struct {
+	err     error
+	created []Correlation
+	deleted []Correlation
+	lock    sync.Mutex
+}
+
+func (c *fakeCorrelator) Correlate(_ context.Context, correlation Correlation) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.created = append(c.created, correlation)
+	return c.err
+}
+
+func (c *fakeCorrelator) Delete(_ context.Context, correlation Correlation) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.deleted = append(c.deleted, correlation)
+	return c.err
+}
+
+func (c *fakeCorrelator) reset() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.created, c.deleted = nil, nil
+}
+
+func newTestTraces(resources ...map[string]string) ptrace.Traces {
+	td := ptrace.NewTraces()
+	for _, resource := range resources {
+		attributes := td.ResourceSpans().AppendEmpty().Resource().Attributes()
+		for k, v := range resource {
+			attributes.InsertString(k, v)
+		}
+	}
+	return td
+}
+
+func newTestTracker(correlator Correlator) (*Tracker, *time.Time) {
+	cfg := DefaultConfig()
+	tracker := NewTracker(cfg, correlator, zap.NewNop())
+	now := time.Now()
+	tracker.now = func() time.Time { return now }
+	return tracker, &now
+}
+
+func TestTrackerCorrelations(t *testing.T) {
+	correlator := &fakeCorrelator{}
+	tracker, _ := newTestTracker(correlator)
+
+	td := newTestTraces(
+		map[string]string{
+			"service.name": "checkout", "deployment.environment": "prod", "host.name": "host-1", "container.id": "abc",
+		},
+		map[string]string{"service.name": "checkout", "host.name": "host-1"},
+		// without dimensions or services
+		map[string]string{"service.name": "cart"},
+		map[string]string{"host.name": "host-2"},
+	)
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), td))
+	tracker.flush(context.Background())
+
+	assert.ElementsMatch(t, []Correlation{
+		{DimensionName: "host", DimensionValue: "host-1", Type: Service, Value: "checkout"},
+		{DimensionName: "host", DimensionValue: "host-1", Type: Environment, Value: "prod"},
+		{DimensionName: "container.id", DimensionValue: "abc", Type: Service, Value: "checkout"},
+		{DimensionName: "container.id", DimensionValue: "abc", Type: Environment, Value: "prod"},
+	}, correlator.created)
+	assert.Empty(t, correlator.deleted)
+
+	// already created correlations aren't sent again
+	correlator.reset()
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), td))
+	tracker.flush(context.Background())
+	assert.Empty(t, correlator.created)
+	assert.Empty(t, correlator.deleted)
+}
+
+func TestTrackerDeletesStaleCorrelations(t *testing.T) {
+	correlator := &fakeCorrelator{}
+	tracker, now := newTestTracker(correlator)
+
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), newTestTraces(
+		map[string]string{"service.name": "checkout", "host.name": "host-1"},
+		map[string]string{"service.name": "cart", "host.name": "host-1"},
+	)))
+	tracker.flush(context.Background())
+	require.Len(t, correlator.created, 2)
+
+	*now = now.Add(3 * time.Minute)
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), newTestTraces(
+		map[string]string{"service.name": "cart", "host.name": "host-1"},
+	)))
+	*now = now.Add(3 * time.Minute)
+	correlator.reset()
+	tracker.flush(context.Background())
+	assert.Empty(t, correlator.created)
+	assert.Equal(t, []Correlation{{DimensionName: "host", DimensionValue: "host-1", Type: Service, Value: "checkout"}}, correlator.deleted)
+
+	// recreated when seen again
+	correlator.reset()
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), newTestTraces(
+		map[string]string{"service.name": "checkout", "host.name": "host-1"},
+	)))
+	tracker.flush(context.Background())
+	assert.Equal(t, []Correlation{{DimensionName: "host", DimensionValue: "host-1", Type: Service, Value: "checkout"}}, correlator.created)
+}
+
+func TestTrackerRetriesFailedCorrelations(t *testing.T) {
+	correlator := &fakeCorrelator{err: errors.New("unavailable")}
+	tracker, _ := newTestTracker(correlator)
+	td := newTestTraces(map[string]string{"service.name": "checkout", "host.name": "host-1"})
+
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), td))
+	tracker.flush(context.Background())
+	require.Len(t, correlator.created, 1)
+
+	correlator.reset()
+	correlator.err = nil
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), td))
+	tracker.flush(context.Background())
+	assert.Len(t, correlator.created, 1)
+}
+
+func TestTrackerMaxBuffered(t *testing.T) {
+	correlator := &fakeCorrelator{}
+	tracker, _ := newTestTracker(correlator)
+	tracker.cfg.MaxBuffered = 1
+
+	td := newTestTraces(
+		map[string]string{"service.name": "checkout", "host.name": "host-1"},
+		map[string]string{"service.name": "cart", "host.name": "host-1"},
+	)
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), td))
+	tracker.flush(context.Background())
+	assert.Equal(t, []Correlation{{DimensionName: "host", DimensionValue: "host-1", Type: Service, Value: "checkout"}}, correlator.created)
+
+	// the dropped correlation is buffered when seen again
+	correlator.reset()
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), td))
+	tracker.flush(context.Background())
+	assert.Equal(t, []Correlation{{DimensionName: "host", DimensionValue: "host-1", Type: Service, Value: "cart"}}, correlator.created)
+}
+
+func TestTrackerStartAndShutdown(t *testing.T) {
+	correlator := &fakeCorrelator{}
+	tracker := NewTracker(DefaultConfig(), correlator, zap.NewNop())
+	tracker.cfg.FlushInterval = time.Millisecond
+	tracker.Start()
+	require.NoError(t, tracker.ConsumeTraces(context.Background(), newTestTraces(
+		map[string]string{"service.name": "checkout", "host.name": "host-1"},
+	)))
+	assert.Eventually(t, func() bool {
+		correlator.lock.Lock()
+		defer correlator.lock.Unlock()
+		return len(correlator.created) == 1
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, tracker.Shutdown(context.Background()))
+
+	require.NoError(t, NewTracker(DefaultConfig(), correlator, zap.NewNop()).Shutdown(context.Background()))
+}
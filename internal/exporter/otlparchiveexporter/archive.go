@@ -0,0 +1,228 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// The signals of archived batches, which prefix the names of their archive files.
+const (
+	SignalMetrics = "metrics"
+	SignalLogs    = "logs"
+	SignalTraces  = "traces"
+)
+
+const (
+	protoExtension   = ".pb"
+	jsonExtension    = ".json"
+	zstdExtension    = ".zst"
+	partialExtension = ".partial"
+
+	// timestampLayout sorts archive file names of the same signal by their creation time.
+	timestampLayout = "20060102T150405.000000000Z"
+	// maxRecordSize guards against allocating the length of a corrupt proto record.
+	maxRecordSize = 1 << 30
+)
+
+// ArchiveFile is an archive file written by the exporter, described by its name.
+type ArchiveFile struct {
+	Path   string
+	Signal string
+	Format string
+	// Whether the file is zstd compressed.
+	Compressed bool
+	// Whether the file is still being written, or its writing was interrupted.
+	Partial bool
+}
+
+func archiveFileName(signal, format, compression string, created time.Time) string {
+	name := signal + "-" + created.UTC().Format(timestampLayout) + protoExtension
+	if format == FormatJSON {
+		name = strings.TrimSuffix(name, protoExtension) + jsonExtension
+	}
+	if compression == CompressionZstd {
+		name += zstdExtension
+	}
+	return name
+}
+
+// ParseArchiveFile describes the archive file at the path from its name, returning false if it isn't the
+// name of an archive file.
+func ParseArchiveFile(path string) (ArchiveFile, bool) {
+	file := ArchiveFile{Path: path}
+	name := filepath.Base(path)
+	if strings.HasSuffix(name, partialExtension) {
+		file.Partial = true
+		name = strings.TrimSuffix(name, partialExtension)
+	}
+	if strings.HasSuffix(name, zstdExtension) {
+		file.Compressed = true
+		name = strings.TrimSuffix(name, zstdExtension)
+	}
+	switch {
+	case strings.HasSuffix(name, protoExtension):
+		file.Format = FormatProto
+		name = strings.TrimSuffix(name, protoExtension)
+	case strings.HasSuffix(name, jsonExtension):
+		file.Format = FormatJSON
+		name = strings.TrimSuffix(name, jsonExtension)
+	default:
+		return ArchiveFile{}, false
+	}
+	signal, timestamp, ok := strings.Cut(name, "-")
+	if !ok {
+		return ArchiveFile{}, false
+	}
+	switch signal {
+	case SignalMetrics, SignalLogs, SignalTraces:
+	default:
+		return ArchiveFile{}, false
+	}
+	if _, err := time.Parse(timestampLayout, timestamp); err != nil {
+		return ArchiveFile{}, false
+	}
+	file.Signal = signal
+	return file, true
+}
+
+// ListArchiveFiles returns the archive files of the directory, oldest first for each signal. Partial
+// files are only included if requested.
+func ListArchiveFiles(dir string, includePartial bool) ([]ArchiveFile, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var files []ArchiveFile
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		file, ok := ParseArchiveFile(filepath.Join(dir, entry.Name()))
+		if !ok || (file.Partial && !includePartial) {
+			continue
+		}
+		files = append(files, file)
+	}
+	SortArchiveFiles(files)
+	return files, nil
+}
+
+// SortArchiveFiles sorts the archive files by signal, then oldest first, regardless of their directories.
+func SortArchiveFiles(files []ArchiveFile) {
+	// names sort by signal, then creation time
+	sort.SliceStable(files, func(i, j int) bool { return filepath.Base(files[i].Path) < filepath.Base(files[j].Path) })
+}
+
+// ReadBatches calls fn with each OTLP batch of the archive file, encoded in the file's format, in the order
+// they were written. A record truncated by an interrupted write is reported after the complete records as an
+// error wrapping io.ErrUnexpectedEOF.
+func ReadBatches(file ArchiveFile, fn func(batch []byte) error) error {
+	f, err := os.Open(file.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var reader *bufio.Reader
+	if file.Compressed {
+		decoder, err := zstd.NewReader(f)
+		if err != nil {
+			return fmt.Errorf("failed reading zstd content: %w", err)
+		}
+		defer decoder.Close()
+		if file.Partial {
+			// the zstd frame of a file being written isn't terminated yet
+			reader = bufio.NewReader(unterminatedFrameReader{decoder})
+		} else {
+			reader = bufio.NewReader(decoder)
+		}
+	} else {
+		reader = bufio.NewReader(f)
+	}
+
+	if file.Format == FormatJSON {
+		return readJSONBatches(reader, fn)
+	}
+	return readProtoBatches(reader, fn)
+}
+
+func readProtoBatches(reader *bufio.Reader, fn func(batch []byte) error) error {
+	for {
+		size, err := binary.ReadUvarint(reader)
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("failed reading record size: %w", err)
+		}
+		if size > maxRecordSize {
+			return fmt.Errorf("invalid record size %d", size)
+		}
+		batch := make([]byte, size)
+		if _, err = io.ReadFull(reader, batch); err != nil {
+			return fmt.Errorf("failed reading record: %w", err)
+		}
+		if err = fn(batch); err != nil {
+			return err
+		}
+	}
+}
+
+func readJSONBatches(reader *bufio.Reader, fn func(batch []byte) error) error {
+	for {
+		line, err := reader.ReadBytes('\n')
+		if len(line) != 0 && line[len(line)-1] != '\n' {
+			return fmt.Errorf("truncated record: %w", io.ErrUnexpectedEOF)
+		}
+		if line = bytes.TrimSpace(line); len(line) != 0 {
+			if fnErr := fn(line); fnErr != nil {
+				return fnErr
+			}
+		}
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// unterminatedFrameReader ends the content of a zstd frame that isn't terminated, whose flushed blocks
+// contain complete records, instead of failing.
+type unterminatedFrameReader struct {
+	reader io.Reader
+}
+
+func (r unterminatedFrameReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		err = io.EOF
+	}
+	return n, err
+}
@@ -0,0 +1,185 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func testConfig(t *testing.T, format, compression string) *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Directory = t.TempDir()
+	cfg.Format = format
+	cfg.Compression = compression
+	return cfg
+}
+
+func readAll(t *testing.T, file ArchiveFile) []string {
+	var batches []string
+	require.NoError(t, ReadBatches(file, func(batch []byte) error {
+		batches = append(batches, string(batch))
+		return nil
+	}))
+	return batches
+}
+
+func TestArchiveWriterRoundTrip(t *testing.T) {
+	for _, format := range []string{FormatProto, FormatJSON} {
+		for _, compression := range []string{CompressionZstd, CompressionNone} {
+			t.Run(format+"/"+compression, func(t *testing.T) {
+				cfg := testConfig(t, format, compression)
+				writer := newArchiveWriter(cfg, SignalMetrics, zap.NewNop())
+				batches := []string{`{"resourceMetrics":[]}`, `{"resourceMetrics":[{}]}`, "\x0a\x00"}
+				if format == FormatJSON {
+					batches = batches[:2]
+				}
+				for _, batch := range batches {
+					require.NoError(t, writer.write([]byte(batch)))
+				}
+
+				// the file being written is partial
+				files, err := ListArchiveFiles(cfg.Directory, false)
+				require.NoError(t, err)
+				assert.Empty(t, files)
+				files, err = ListArchiveFiles(cfg.Directory, true)
+				require.NoError(t, err)
+				require.Len(t, files, 1)
+				assert.True(t, files[0].Partial)
+				assert.Equal(t, batches, readAll(t, files[0]))
+
+				require.NoError(t, writer.close())
+				files, err = ListArchiveFiles(cfg.Directory, true)
+				require.NoError(t, err)
+				require.Len(t, files, 1)
+				assert.Equal(t, ArchiveFile{
+					Path:       writer.path,
+					Signal:     SignalMetrics,
+					Format:     format,
+					Compressed: compression == CompressionZstd,
+				}, files[0])
+				assert.Equal(t, batches, readAll(t, files[0]))
+			})
+		}
+	}
+}
+
+func TestArchiveWriterRotation(t *testing.T) {
+	cfg := testConfig(t, FormatProto, CompressionNone)
+	cfg.MaxFileSizeMiB = 1
+	cfg.MaxFiles = 2
+	writer := newArchiveWriter(cfg, SignalLogs, zap.NewNop())
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	writer.now = func() time.Time {
+		now = now.Add(time.Second)
+		return now
+	}
+
+	batch := make([]byte, 700*1024)
+	for i := 0; i < 6; i++ {
+		batch[0] = byte(i)
+		require.NoError(t, writer.write(batch))
+	}
+	require.NoError(t, writer.close())
+
+	// each file holds two batches and the oldest file was removed
+	files, err := ListArchiveFiles(cfg.Directory, true)
+	require.NoError(t, err)
+	require.Len(t, files, 2)
+	assert.Equal(t, filepath.Join(cfg.Directory, "logs-20220701T000002.000000000Z.pb"), files[0].Path)
+	assert.Equal(t, filepath.Join(cfg.Directory, "logs-20220701T000003.000000000Z.pb"), files[1].Path)
+	var first []byte
+	require.NoError(t, ReadBatches(files[0], func(b []byte) error {
+		first = append(first, b[0])
+		return nil
+	}))
+	assert.Equal(t, []byte{2, 3}, first)
+}
+
+func TestArchiveWriterAgeRotation(t *testing.T) {
+	cfg := testConfig(t, FormatJSON, CompressionZstd)
+	cfg.MaxFileAge = 10 * time.Millisecond
+	writer := newArchiveWriter(cfg, SignalTraces, zap.NewNop())
+	require.NoError(t, writer.write([]byte(`{}`)))
+
+	require.Eventually(t, func() bool {
+		files, err := ListArchiveFiles(cfg.Directory, false)
+		return err == nil && len(files) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, writer.close())
+}
+
+func TestReadBatchesTruncated(t *testing.T) {
+	dir := t.TempDir()
+	proto := filepath.Join(dir, "metrics-20220701T000000.000000000Z.pb")
+	require.NoError(t, os.WriteFile(proto, []byte{2, 'a', 'b', 5, 'c'}, 0600))
+	file, ok := ParseArchiveFile(proto)
+	require.True(t, ok)
+	var batches []string
+	err := ReadBatches(file, func(batch []byte) error {
+		batches = append(batches, string(batch))
+		return nil
+	})
+	require.EqualError(t, err, "failed reading record: unexpected EOF")
+	assert.Equal(t, []string{"ab"}, batches)
+
+	json := filepath.Join(dir, "logs-20220701T000000.000000000Z.json")
+	require.NoError(t, os.WriteFile(json, []byte("{}\n{\"resourceLogs\""), 0600))
+	file, ok = ParseArchiveFile(json)
+	require.True(t, ok)
+	require.EqualError(t, ReadBatches(file, func([]byte) error { return nil }), "truncated record: unexpected EOF")
+
+	fnErr := fmt.Errorf("consumer failed")
+	require.Equal(t, fnErr, ReadBatches(file, func([]byte) error { return fnErr }))
+}
+
+func TestParseArchiveFile(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		expected ArchiveFile
+		ok       bool
+	}{
+		{
+			name:     "traces-20220701T000000.000000000Z.json.zst.partial",
+			expected: ArchiveFile{Signal: SignalTraces, Format: FormatJSON, Compressed: true, Partial: true},
+			ok:       true,
+		},
+		{
+			name:     "metrics-20220701T000000.000000000Z.pb",
+			expected: ArchiveFile{Signal: SignalMetrics, Format: FormatProto},
+			ok:       true,
+		},
+		{name: "metrics-20220701T000000.000000000Z.txt"},
+		{name: "profiles-20220701T000000.000000000Z.pb"},
+		{name: "metrics-yesterday.pb"},
+		{name: "metrics.pb"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			file, ok := ParseArchiveFile(filepath.Join("dir", test.name))
+			assert.Equal(t, test.ok, ok)
+			if test.ok {
+				test.expected.Path = filepath.Join("dir", test.name)
+			}
+			assert.Equal(t, test.expected, file)
+		})
+	}
+}
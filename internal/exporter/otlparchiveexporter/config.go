@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	// FormatProto archives length-delimited OTLP protobuf batches.
+	FormatProto = "proto"
+	// FormatJSON archives OTLP JSON batches, one per line.
+	FormatJSON = "json"
+
+	// CompressionZstd compresses the archive files with zstd.
+	CompressionZstd = "zstd"
+	// CompressionNone leaves the archive files uncompressed.
+	CompressionNone = "none"
+)
+
+// Config defines configuration for the otlparchive exporter.
+type Config struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	// The directory the archive files are written to, created if missing.
+	Directory string `mapstructure:"directory"`
+	// The OTLP encoding of the archived batches: proto (default) or json.
+	Format string `mapstructure:"format"`
+	// The compression of the archive files: zstd (default) or none.
+	Compression string `mapstructure:"compression"`
+	// The size of an archive file, after compression, at which it's rotated.
+	MaxFileSizeMiB int `mapstructure:"max_file_size_mib"`
+	// The age of an archive file at which it's rotated, even if idle.
+	MaxFileAge time.Duration `mapstructure:"max_file_age"`
+	// The number of rotated archive files of each signal to keep, removing the oldest ones. 0 keeps all of them.
+	MaxFiles int `mapstructure:"max_files"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Directory == "" {
+		return errors.New("directory must not be empty")
+	}
+	switch cfg.Format {
+	case FormatProto, FormatJSON:
+	default:
+		return fmt.Errorf("format must be %q or %q, not %q", FormatProto, FormatJSON, cfg.Format)
+	}
+	switch cfg.Compression {
+	case CompressionZstd, CompressionNone:
+	default:
+		return fmt.Errorf("compression must be %q or %q, not %q", CompressionZstd, CompressionNone, cfg.Compression)
+	}
+	if cfg.MaxFileSizeMiB <= 0 {
+		return fmt.Errorf("max_file_size_mib must be positive, not %d", cfg.MaxFileSizeMiB)
+	}
+	if cfg.MaxFileAge <= 0 {
+		return fmt.Errorf("max_file_age must be positive, not %s", cfg.MaxFileAge)
+	}
+	if cfg.MaxFiles < 0 {
+		return fmt.Errorf("max_files must not be negative, not %d", cfg.MaxFiles)
+	}
+	return nil
+}
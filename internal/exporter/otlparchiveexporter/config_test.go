@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.Directory = "/var/lib/otelcol/archive"
+	assert.Equal(t, expected, e0)
+
+	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "json")]
+	assert.Equal(t,
+		&Config{
+			ExporterSettings: config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "json")),
+			Directory:        "/var/lib/otelcol/archive/json",
+			Format:           FormatJSON,
+			Compression:      CompressionNone,
+			MaxFileSizeMiB:   10,
+			MaxFileAge:       5 * time.Minute,
+			MaxFiles:         24,
+		}, e1)
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "no directory",
+			modify:      func(cfg *Config) { cfg.Directory = "" },
+			expectedErr: "directory must not be empty",
+		},
+		{
+			name:        "unknown format",
+			modify:      func(cfg *Config) { cfg.Format = "avro" },
+			expectedErr: `format must be "proto" or "json", not "avro"`,
+		},
+		{
+			name:        "unknown compression",
+			modify:      func(cfg *Config) { cfg.Compression = "gzip" },
+			expectedErr: `compression must be "zstd" or "none", not "gzip"`,
+		},
+		{
+			name:        "zero max file size",
+			modify:      func(cfg *Config) { cfg.MaxFileSizeMiB = 0 },
+			expectedErr: "max_file_size_mib must be positive, not 0",
+		},
+		{
+			name:        "zero max file age",
+			modify:      func(cfg *Config) { cfg.MaxFileAge = 0 },
+			expectedErr: "max_file_age must be positive, not 0s",
+		},
+		{
+			name:        "negative max files",
+			modify:      func(cfg *Config) { cfg.MaxFiles = -1 },
+			expectedErr: "max_files must not be negative, not -1",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Directory = "/tmp/archive"
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.expectedErr)
+		})
+	}
+}
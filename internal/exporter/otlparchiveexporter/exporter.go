@@ -0,0 +1,87 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+)
+
+// archiveExporter archives the batches of a signal to the configured directory.
+type archiveExporter struct {
+	cfg              *Config
+	writer           *archiveWriter
+	metricsMarshaler pmetric.Marshaler
+	logsMarshaler    plog.Marshaler
+	tracesMarshaler  ptrace.Marshaler
+}
+
+func newArchiveExporter(cfg *Config, signal string, logger *zap.Logger) *archiveExporter {
+	exp := &archiveExporter{
+		cfg:              cfg,
+		writer:           newArchiveWriter(cfg, signal, logger),
+		metricsMarshaler: pmetric.NewProtoMarshaler(),
+		logsMarshaler:    plog.NewProtoMarshaler(),
+		tracesMarshaler:  ptrace.NewProtoMarshaler(),
+	}
+	if cfg.Format == FormatJSON {
+		exp.metricsMarshaler = pmetric.NewJSONMarshaler()
+		exp.logsMarshaler = plog.NewJSONMarshaler()
+		exp.tracesMarshaler = ptrace.NewJSONMarshaler()
+	}
+	return exp
+}
+
+func (e *archiveExporter) start(context.Context, component.Host) error {
+	if err := os.MkdirAll(e.cfg.Directory, 0700); err != nil {
+		return fmt.Errorf("failed creating archive directory: %w", err)
+	}
+	return nil
+}
+
+func (e *archiveExporter) shutdown(context.Context) error {
+	return e.writer.close()
+}
+
+func (e *archiveExporter) pushMetrics(_ context.Context, md pmetric.Metrics) error {
+	batch, err := e.metricsMarshaler.MarshalMetrics(md)
+	if err != nil {
+		return err
+	}
+	return e.writer.write(batch)
+}
+
+func (e *archiveExporter) pushLogs(_ context.Context, ld plog.Logs) error {
+	batch, err := e.logsMarshaler.MarshalLogs(ld)
+	if err != nil {
+		return err
+	}
+	return e.writer.write(batch)
+}
+
+func (e *archiveExporter) pushTraces(_ context.Context, td ptrace.Traces) error {
+	batch, err := e.tracesMarshaler.MarshalTraces(td)
+	if err != nil {
+		return err
+	}
+	return e.writer.write(batch)
+}
@@ -0,0 +1,101 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+const (
+	typeStr = "otlparchive"
+
+	defaultMaxFileSizeMiB = 100
+	defaultMaxFileAge     = time.Hour
+)
+
+// NewFactory creates a factory for the otlparchive exporter.
+func NewFactory() component.ExporterFactory {
+	return component.NewExporterFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsExporter(createMetricsExporter),
+		component.WithLogsExporter(createLogsExporter),
+		component.WithTracesExporter(createTracesExporter),
+	)
+}
+
+func createDefaultConfig() config.Exporter {
+	return &Config{
+		ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
+		Format:           FormatProto,
+		Compression:      CompressionZstd,
+		MaxFileSizeMiB:   defaultMaxFileSizeMiB,
+		MaxFileAge:       defaultMaxFileAge,
+	}
+}
+
+func createMetricsExporter(
+	_ context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.MetricsExporter, error) {
+	exp := newArchiveExporter(cfg.(*Config), SignalMetrics, settings.Logger)
+	return exporterhelper.NewMetricsExporter(
+		cfg,
+		settings,
+		exp.pushMetrics,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
+
+func createLogsExporter(
+	_ context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.LogsExporter, error) {
+	exp := newArchiveExporter(cfg.(*Config), SignalLogs, settings.Logger)
+	return exporterhelper.NewLogsExporter(
+		cfg,
+		settings,
+		exp.pushLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
+
+func createTracesExporter(
+	_ context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.TracesExporter, error) {
+	exp := newArchiveExporter(cfg.(*Config), SignalTraces, settings.Logger)
+	return exporterhelper.NewTracesExporter(
+		cfg,
+		settings,
+		exp.pushTraces,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
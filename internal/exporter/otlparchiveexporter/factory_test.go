@@ -0,0 +1,98 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestExportersArchiveBatches(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Directory = t.TempDir()
+	ctx := context.Background()
+	settings := componenttest.NewNopExporterCreateSettings()
+	host := componenttest.NewNopHost()
+
+	me, err := factory.CreateMetricsExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, me.Start(ctx, host))
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetName("some.metric")
+	require.NoError(t, me.ConsumeMetrics(ctx, md))
+	require.NoError(t, me.Shutdown(ctx))
+
+	le, err := factory.CreateLogsExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, le.Start(ctx, host))
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("some log")
+	require.NoError(t, le.ConsumeLogs(ctx, ld))
+	require.NoError(t, le.Shutdown(ctx))
+
+	cfg.Format = FormatJSON
+	te, err := factory.CreateTracesExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, te.Start(ctx, host))
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("some span")
+	require.NoError(t, te.ConsumeTraces(ctx, td))
+	require.NoError(t, te.Shutdown(ctx))
+
+	files, err := ListArchiveFiles(cfg.Directory, false)
+	require.NoError(t, err)
+	require.Len(t, files, 3)
+
+	assert.Equal(t, SignalLogs, files[0].Signal)
+	require.NoError(t, ReadBatches(files[0], func(batch []byte) error {
+		archived, err := plog.NewProtoUnmarshaler().UnmarshalLogs(batch)
+		require.NoError(t, err)
+		assert.Equal(t, ld, archived)
+		return nil
+	}))
+
+	assert.Equal(t, SignalMetrics, files[1].Signal)
+	require.NoError(t, ReadBatches(files[1], func(batch []byte) error {
+		archived, err := pmetric.NewProtoUnmarshaler().UnmarshalMetrics(batch)
+		require.NoError(t, err)
+		assert.Equal(t, md, archived)
+		return nil
+	}))
+
+	assert.Equal(t, SignalTraces, files[2].Signal)
+	assert.Equal(t, FormatJSON, files[2].Format)
+	require.NoError(t, ReadBatches(files[2], func(batch []byte) error {
+		archived, err := ptrace.NewJSONUnmarshaler().UnmarshalTraces(batch)
+		require.NoError(t, err)
+		assert.Equal(t, td, archived)
+		return nil
+	}))
+}
@@ -0,0 +1,185 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package otlparchiveexporter
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// archiveWriter appends the batches of a signal to its current archive file, which is written with
+// a partial extension and renamed when rotated, once its size or age exceeds the configured maximum,
+// or when the writer is closed.
+type archiveWriter struct {
+	now    func() time.Time
+	cfg    *Config
+	logger *zap.Logger
+	file   *os.File
+	// encoder compresses to the file, nil when uncompressed
+	encoder  *zstd.Encoder
+	out      io.Writer
+	ageTimer *time.Timer
+	signal   string
+	path     string
+	size     int64
+	lock     sync.Mutex
+}
+
+func newArchiveWriter(cfg *Config, signal string, logger *zap.Logger) *archiveWriter {
+	return &archiveWriter{cfg: cfg, signal: signal, logger: logger, now: time.Now}
+}
+
+// write appends the batch, encoded in the configured format, to the current archive file.
+func (w *archiveWriter) write(batch []byte) error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	if w.file != nil && w.size >= int64(w.cfg.MaxFileSizeMiB)*1024*1024 {
+		if err := w.rotateLocked(); err != nil {
+			return err
+		}
+	}
+	if w.file == nil {
+		if err := w.openLocked(); err != nil {
+			return err
+		}
+	}
+
+	var record []byte
+	if w.cfg.Format == FormatJSON {
+		record = append(append(make([]byte, 0, len(batch)+1), batch...), '\n')
+	} else {
+		var size [binary.MaxVarintLen64]byte
+		n := binary.PutUvarint(size[:], uint64(len(batch)))
+		record = append(append(make([]byte, 0, n+len(batch)), size[:n]...), batch...)
+	}
+	if _, err := w.out.Write(record); err != nil {
+		return fmt.Errorf("failed writing to %s: %w", w.path, err)
+	}
+	// flushed so that the file only ends with a truncated record if the collector crashes mid-write
+	if w.encoder != nil {
+		if err := w.encoder.Flush(); err != nil {
+			return fmt.Errorf("failed writing to %s: %w", w.path, err)
+		}
+	}
+	return nil
+}
+
+func (w *archiveWriter) openLocked() error {
+	name := archiveFileName(w.signal, w.cfg.Format, w.cfg.Compression, w.now())
+	w.path = filepath.Join(w.cfg.Directory, name)
+	file, err := os.OpenFile(w.path+partialExtension, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("failed creating archive file: %w", err)
+	}
+	w.file = file
+	w.size = 0
+	counter := &countingWriter{writer: file, count: &w.size}
+	w.out = counter
+	if w.cfg.Compression == CompressionZstd {
+		if w.encoder, err = zstd.NewWriter(counter); err != nil {
+			_ = file.Close()
+			w.file = nil
+			return fmt.Errorf("failed creating zstd encoder: %w", err)
+		}
+		w.out = w.encoder
+	}
+	// rotated when aged even if idle, so that it can be forwarded
+	w.ageTimer = time.AfterFunc(w.cfg.MaxFileAge, func() { w.rotateIfCurrent(file) })
+	return nil
+}
+
+// rotateIfCurrent rotates the file if it's still the current one.
+func (w *archiveWriter) rotateIfCurrent(file *os.File) {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if w.file != file {
+		return
+	}
+	if err := w.rotateLocked(); err != nil {
+		w.logger.Error("failed rotating aged archive file", zap.String("path", w.path), zap.Error(err))
+	}
+}
+
+// rotateLocked closes the current file, if any, and removes the oldest rotated files exceeding max_files.
+func (w *archiveWriter) rotateLocked() error {
+	if w.file == nil {
+		return nil
+	}
+	w.ageTimer.Stop()
+	var err error
+	if w.encoder != nil {
+		err = w.encoder.Close()
+		w.encoder = nil
+	}
+	err = multierr.Append(err, w.file.Close())
+	w.file = nil
+	if err != nil {
+		// left partial since its content may be incomplete
+		return fmt.Errorf("failed closing archive file %s: %w", w.path, err)
+	}
+	if err = os.Rename(w.path+partialExtension, w.path); err != nil {
+		return fmt.Errorf("failed renaming rotated archive file: %w", err)
+	}
+	return w.removeOldFiles()
+}
+
+func (w *archiveWriter) removeOldFiles() error {
+	if w.cfg.MaxFiles == 0 {
+		return nil
+	}
+	files, err := ListArchiveFiles(w.cfg.Directory, false)
+	if err != nil {
+		return fmt.Errorf("failed listing archive files: %w", err)
+	}
+	var signalFiles []ArchiveFile
+	for _, file := range files {
+		if file.Signal == w.signal {
+			signalFiles = append(signalFiles, file)
+		}
+	}
+	var errs error
+	for i := 0; i < len(signalFiles)-w.cfg.MaxFiles; i++ {
+		errs = multierr.Append(errs, os.Remove(signalFiles[i].Path))
+	}
+	return errs
+}
+
+// close rotates the current file, if any.
+func (w *archiveWriter) close() error {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	return w.rotateLocked()
+}
+
+type countingWriter struct {
+	writer io.Writer
+	count  *int64
+}
+
+func (c *countingWriter) Write(p []byte) (int, error) {
+	n, err := c.writer.Write(p)
+	*c.count += int64(n)
+	return n, err
+}
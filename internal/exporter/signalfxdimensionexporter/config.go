@@ -0,0 +1,59 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionexporter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Config defines configuration for the SignalFx dimension update exporter.
+type Config struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	// HTTPClientSettings are the settings of the client of the SignalFx API, whose URL is the endpoint.
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+	exporterhelper.QueueSettings  `mapstructure:"sending_queue"`
+	exporterhelper.RetrySettings  `mapstructure:"retry_on_failure"`
+	// AccessToken authenticates the updates, unless they're passed through with their own.
+	AccessToken string `mapstructure:"access_token"`
+	// AccessTokenPassthrough determines whether to authenticate the updates with their
+	// "com.splunk.signalfx.access_token" resource attribute, if any, instead of the AccessToken.
+	AccessTokenPassthrough bool `mapstructure:"access_token_passthrough"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return fmt.Errorf("invalid endpoint: %w", err)
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" {
+		return fmt.Errorf("endpoint must be an http or https URL, not %q", cfg.Endpoint)
+	}
+	if cfg.AccessToken == "" && !cfg.AccessTokenPassthrough {
+		return errors.New("access_token must not be empty without access_token_passthrough")
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), e0)
+
+	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "token")]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "token"))
+	expected.Endpoint = "https://api.eu0.signalfx.com"
+	expected.Timeout = 10 * time.Second
+	expected.RetrySettings.Enabled = false
+	expected.AccessToken = "my-token"
+	expected.AccessTokenPassthrough = false
+	assert.Equal(t, expected, e1)
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "no endpoint",
+			modify:      func(cfg *Config) { cfg.Endpoint = "" },
+			expectedErr: "endpoint must not be empty",
+		},
+		{
+			name:        "endpoint without scheme",
+			modify:      func(cfg *Config) { cfg.Endpoint = "api.us0.signalfx.com" },
+			expectedErr: `endpoint must be an http or https URL, not "api.us0.signalfx.com"`,
+		},
+		{
+			name: "no access token",
+			modify: func(cfg *Config) {
+				cfg.AccessToken = ""
+				cfg.AccessTokenPassthrough = false
+			},
+			expectedErr: "access_token must not be empty without access_token_passthrough",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.AccessToken = "token"
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.expectedErr)
+		})
+	}
+}
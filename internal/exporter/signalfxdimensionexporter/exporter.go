@@ -0,0 +1,160 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionexporter
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/signalfxdimension"
+)
+
+const (
+	dimensionPathPrefix = "/v2/dimension/"
+	// the suffix used by the Smart Agent for merging updates
+	sfxAgentPathSuffix = "/_/sfxagent"
+	accessTokenHeader  = "X-Sf-Token"
+)
+
+// dimensionExporter sends the dimension updates of entity state log records, like those of the
+// signalfx_dimension receiver, to the SignalFx dimension API.
+type dimensionExporter struct {
+	config   *Config
+	settings component.TelemetrySettings
+	client   *http.Client
+}
+
+func newDimensionExporter(config *Config, settings component.TelemetrySettings) *dimensionExporter {
+	return &dimensionExporter{config: config, settings: settings}
+}
+
+func (e *dimensionExporter) start(_ context.Context, host component.Host) error {
+	client, err := e.config.HTTPClientSettings.ToClient(host.GetExtensions(), e.settings)
+	if err != nil {
+		return err
+	}
+	e.client = client
+	return nil
+}
+
+// pushLogs sends the updates of the log records, skipping the records that aren't dimension entity
+// states.  The records whose updates failed to be sent are retried, unless they were rejected.
+func (e *dimensionExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	var retryableErrs, permanentErrs error
+	failed := ld.Clone()
+	failed.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+		accessToken := e.accessToken(rl)
+		rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				err := e.export(ctx, lr, accessToken)
+				switch {
+				case err == nil:
+					return true
+				case consumererror.IsPermanent(err):
+					permanentErrs = multierr.Append(permanentErrs, err)
+					return true
+				}
+				retryableErrs = multierr.Append(retryableErrs, err)
+				return false
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return rl.ScopeLogs().Len() == 0
+	})
+
+	if retryableErrs == nil {
+		return permanentErrs
+	}
+	if permanentErrs != nil {
+		// a permanent error would prevent the retry of the failed updates
+		e.settings.Logger.Error("Dropping rejected dimension updates", zap.Error(permanentErrs))
+	}
+	return consumererror.NewLogs(retryableErrs, failed)
+}
+
+// export sends the update of the log record, if it's a dimension entity state.
+func (e *dimensionExporter) export(ctx context.Context, lr plog.LogRecord, accessToken string) error {
+	update, err := signalfxdimension.FromLogRecord(lr)
+	switch {
+	case errors.Is(err, signalfxdimension.ErrNotUpdate):
+		return nil
+	case err != nil:
+		return consumererror.NewPermanent(err)
+	}
+	return e.send(ctx, update, accessToken)
+}
+
+// accessToken returns the access token of the updates of the resource.
+func (e *dimensionExporter) accessToken(rl plog.ResourceLogs) string {
+	if e.config.AccessTokenPassthrough {
+		if accessToken, ok := rl.Resource().Attributes().Get(signalfxdimension.AccessTokenKey); ok && accessToken.StringVal() != "" {
+			return accessToken.StringVal()
+		}
+	}
+	return e.config.AccessToken
+}
+
+// send replaces the properties and tags of the dimension with PUT /v2/dimension/{key}/{value}, or
+// merges them with PATCH /v2/dimension/{key}/{value}/_/sfxagent, as the Smart Agent does.
+func (e *dimensionExporter) send(ctx context.Context, update signalfxdimension.Update, accessToken string) error {
+	if accessToken == "" {
+		return consumererror.NewPermanent(fmt.Errorf("no access token for the update of dimension %s=%s", update.Key, update.Value))
+	}
+	body, err := json.Marshal(update)
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+
+	method := http.MethodPut
+	endpoint := strings.TrimSuffix(e.config.Endpoint, "/") + dimensionPathPrefix + url.PathEscape(update.Key) + "/" + url.PathEscape(update.Value)
+	if update.Merge {
+		method = http.MethodPatch
+		endpoint += sfxAgentPathSuffix
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return consumererror.NewPermanent(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(accessTokenHeader, accessToken)
+
+	resp, err := e.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to update dimension %s=%s: %w", update.Key, update.Value, err)
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= 200 && resp.StatusCode < 300:
+		return nil
+	case resp.StatusCode >= 400 && resp.StatusCode < 500 && resp.StatusCode != http.StatusTooManyRequests:
+		return consumererror.NewPermanent(fmt.Errorf("update of dimension %s=%s rejected with status %d", update.Key, update.Value, resp.StatusCode))
+	}
+	return fmt.Errorf("failed to update dimension %s=%s: status %d", update.Key, update.Value, resp.StatusCode)
+}
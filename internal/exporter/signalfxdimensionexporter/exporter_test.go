@@ -0,0 +1,184 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionexporter
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/internal/signalfxdimension"
+)
+
+type dimensionRequest struct {
+	method, path, token, body string
+}
+
+// dimensionAPI records the requests it receives, responding to them with the status of their path.
+type dimensionAPI struct {
+	statuses map[string]int
+	requests []dimensionRequest
+	lock     sync.Mutex
+}
+
+func (api *dimensionAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, _ := io.ReadAll(r.Body)
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.requests = append(api.requests, dimensionRequest{
+		method: r.Method,
+		path:   r.URL.EscapedPath(),
+		token:  r.Header.Get("X-SF-Token"),
+		body:   string(body),
+	})
+	if status, ok := api.statuses[r.URL.EscapedPath()]; ok {
+		w.WriteHeader(status)
+	}
+}
+
+func newTestExporter(t *testing.T, api *dimensionAPI, passthrough bool) component.LogsExporter {
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.AccessToken = "config-token"
+	cfg.AccessTokenPassthrough = passthrough
+	cfg.QueueSettings.Enabled = false
+	cfg.RetrySettings.Enabled = false
+	exp, err := factory.CreateLogsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	require.NoError(t, err)
+	require.NoError(t, exp.Start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { assert.NoError(t, exp.Shutdown(context.Background())) })
+	return exp
+}
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// updateLogs returns the log records of the updates, with a log record that isn't an update.
+func updateLogs(accessToken string, updates ...signalfxdimension.Update) plog.Logs {
+	logs := plog.NewLogs()
+	for _, update := range updates {
+		update.ToLogs(accessToken).ResourceLogs().MoveAndAppendTo(logs.ResourceLogs())
+	}
+	logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().AppendEmpty().Body().SetStringVal("not an update")
+	return logs
+}
+
+func TestExportUpdates(t *testing.T) {
+	api := &dimensionAPI{}
+	exp := newTestExporter(t, api, true)
+
+	logs := updateLogs("token",
+		signalfxdimension.Update{
+			Key:              "host",
+			Value:            "my/host",
+			CustomProperties: map[string]*string{"role": strPtr("db"), "obsolete": nil},
+			Tags:             []string{"tag1"},
+			TagsToRemove:     []string{"tag2"},
+			Merge:            true,
+		},
+		signalfxdimension.Update{
+			Key:              "container_id",
+			Value:            "abc",
+			CustomProperties: map[string]*string{"image": strPtr("nginx")},
+		},
+	)
+	require.NoError(t, exp.ConsumeLogs(context.Background(), logs))
+
+	require.Len(t, api.requests, 2)
+	assert.Equal(t, http.MethodPatch, api.requests[0].method)
+	assert.Equal(t, "/v2/dimension/host/my%2Fhost/_/sfxagent", api.requests[0].path)
+	assert.Equal(t, "token", api.requests[0].token)
+	assert.JSONEq(t,
+		`{"key": "host", "value": "my/host", "customProperties": {"role": "db", "obsolete": null}, "tags": ["tag1"], "tagsToRemove": ["tag2"]}`,
+		api.requests[0].body,
+	)
+	assert.Equal(t, http.MethodPut, api.requests[1].method)
+	assert.Equal(t, "/v2/dimension/container_id/abc", api.requests[1].path)
+	assert.JSONEq(t,
+		`{"key": "container_id", "value": "abc", "customProperties": {"image": "nginx"}, "tags": null}`,
+		api.requests[1].body,
+	)
+}
+
+func TestExportAccessToken(t *testing.T) {
+	update := signalfxdimension.Update{Key: "host", Value: "my-host"}
+	for _, test := range []struct {
+		name          string
+		passthrough   bool
+		receivedToken string
+		expectedToken string
+	}{
+		{name: "passthrough", passthrough: true, receivedToken: "token", expectedToken: "token"},
+		{name: "passthrough without token", passthrough: true, expectedToken: "config-token"},
+		{name: "no passthrough", receivedToken: "token", expectedToken: "config-token"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			api := &dimensionAPI{}
+			exp := newTestExporter(t, api, test.passthrough)
+			require.NoError(t, exp.ConsumeLogs(context.Background(), updateLogs(test.receivedToken, update)))
+			require.Len(t, api.requests, 1)
+			assert.Equal(t, test.expectedToken, api.requests[0].token)
+		})
+	}
+}
+
+func TestExportErrors(t *testing.T) {
+	api := &dimensionAPI{statuses: map[string]int{
+		"/v2/dimension/host/rejected":    http.StatusBadRequest,
+		"/v2/dimension/host/unavailable": http.StatusServiceUnavailable,
+	}}
+	exp := newTestExporter(t, api, true)
+
+	logs := updateLogs("token",
+		signalfxdimension.Update{Key: "host", Value: "rejected"},
+		signalfxdimension.Update{Key: "host", Value: "unavailable"},
+		signalfxdimension.Update{Key: "host", Value: "accepted"},
+	)
+	err := exp.ConsumeLogs(context.Background(), logs)
+	require.EqualError(t, err, "failed to update dimension host=unavailable: status 503")
+	assert.False(t, consumererror.IsPermanent(err))
+	assert.Len(t, api.requests, 3)
+
+	// only the unavailable update is retried
+	var logsErr consumererror.Logs
+	require.ErrorAs(t, err, &logsErr)
+	failed := logsErr.GetLogs()
+	require.Equal(t, 1, failed.LogRecordCount())
+	update, err := signalfxdimension.FromLogRecord(failed.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0))
+	require.NoError(t, err)
+	assert.Equal(t, "unavailable", update.Value)
+	token, ok := failed.ResourceLogs().At(0).Resource().Attributes().Get(signalfxdimension.AccessTokenKey)
+	require.True(t, ok)
+	assert.Equal(t, "token", token.StringVal())
+
+	err = exp.ConsumeLogs(context.Background(), updateLogs("token", signalfxdimension.Update{Key: "host", Value: "rejected"}))
+	require.EqualError(t, err, "Permanent error: update of dimension host=rejected rejected with status 400")
+	assert.True(t, consumererror.IsPermanent(err))
+}
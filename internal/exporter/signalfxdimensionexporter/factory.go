@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionexporter
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "signalfx_dimension"
+
+	defaultEndpoint = "https://api.us0.signalfx.com"
+	defaultTimeout  = 5 * time.Second
+)
+
+// NewFactory creates a factory for the SignalFx dimension update exporter.
+func NewFactory() component.ExporterFactory {
+	return component.NewExporterFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithLogsExporter(createLogsExporter),
+	)
+}
+
+func createDefaultConfig() config.Exporter {
+	return &Config{
+		ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
+		HTTPClientSettings: confighttp.HTTPClientSettings{
+			Endpoint: defaultEndpoint,
+			Timeout:  defaultTimeout,
+		},
+		QueueSettings:          exporterhelper.NewDefaultQueueSettings(),
+		RetrySettings:          exporterhelper.NewDefaultRetrySettings(),
+		AccessTokenPassthrough: true,
+	}
+}
+
+func createLogsExporter(
+	_ context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.LogsExporter, error) {
+	exp := newDimensionExporter(cfg.(*Config), settings.TelemetrySettings)
+	return exporterhelper.NewLogsExporter(
+		cfg,
+		settings,
+		exp.pushLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithQueue(cfg.(*Config).QueueSettings),
+		exporterhelper.WithRetry(cfg.(*Config).RetrySettings),
+	)
+}
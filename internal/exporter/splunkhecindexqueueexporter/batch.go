@@ -0,0 +1,50 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// batch is a batch of log records or datapoints of a single index.
+type batch interface {
+	records() int
+	marshal() ([]byte, error)
+}
+
+type logsBatch struct{ plog.Logs }
+
+func (b logsBatch) records() int { return b.LogRecordCount() }
+
+func (b logsBatch) marshal() ([]byte, error) { return plog.NewProtoMarshaler().MarshalLogs(b.Logs) }
+
+func unmarshalLogsBatch(data []byte) (batch, error) {
+	ld, err := plog.NewProtoUnmarshaler().UnmarshalLogs(data)
+	return logsBatch{ld}, err
+}
+
+type metricsBatch struct{ pmetric.Metrics }
+
+func (b metricsBatch) records() int { return b.DataPointCount() }
+
+func (b metricsBatch) marshal() ([]byte, error) {
+	return pmetric.NewProtoMarshaler().MarshalMetrics(b.Metrics)
+}
+
+func unmarshalMetricsBatch(data []byte) (batch, error) {
+	md, err := pmetric.NewProtoUnmarshaler().UnmarshalMetrics(data)
+	return metricsBatch{md}, err
+}
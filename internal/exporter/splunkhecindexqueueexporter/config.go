@@ -0,0 +1,132 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the Splunk HEC index queue exporter.
+type Config struct {
+	config.ExporterSettings `mapstructure:",squash"`
+	// HEC are the settings of the splunk_hec exporters sending the records of each index. Their
+	// sending_queue is replaced by the index queues, and their retry_on_failure applies to each batch.
+	HEC splunkhecexporter.Config `mapstructure:"splunk_hec"`
+	// IndexAttribute is the record or resource attribute with the index of a record. Records without
+	// it are queued for the index of the splunk_hec settings.
+	IndexAttribute string `mapstructure:"index_attribute"`
+	// MaxIndexes is the number of index queues, beyond which the records of further indexes share the
+	// queue of the records without an index.
+	MaxIndexes int `mapstructure:"max_indexes"`
+	// IndexQueueSettings are the default settings of every index queue.
+	IndexQueueSettings `mapstructure:",squash"`
+	// Indexes are the queue settings of specific indexes, whose unset settings are the defaults.
+	Indexes map[string]IndexQueueSettings `mapstructure:"indexes"`
+	// Overflow persists the batches that don't fit in the queues of their index.
+	Overflow OverflowSettings `mapstructure:"overflow"`
+}
+
+// IndexQueueSettings are the settings of the queue of an index.
+type IndexQueueSettings struct {
+	// QueueSize is the number of batches held in memory.
+	QueueSize int `mapstructure:"queue_size"`
+	// NumConsumers is the number of batches sent concurrently.
+	NumConsumers int `mapstructure:"num_consumers"`
+	// RecordsPerSecond is the rate of log records or datapoints sent, unlimited if 0.
+	RecordsPerSecond float64 `mapstructure:"records_per_second"`
+	// Burst is the number of records that can be sent at once, defaulting to a second's worth.
+	Burst int `mapstructure:"burst"`
+}
+
+// OverflowSettings are the settings of the persistent overflow of the index queues.
+type OverflowSettings struct {
+	// Directory is where the batches that don't fit in the memory queue of their index, or are still
+	// queued at shutdown, are written until they're sent. They're dropped if empty.
+	Directory string `mapstructure:"directory"`
+	// MaxSizeMiB is the size of the overflowed batches of each signal beyond which further batches
+	// are dropped.
+	MaxSizeMiB int `mapstructure:"max_size_mib"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.IndexAttribute == "" {
+		return errors.New("index_attribute must not be empty")
+	}
+	if cfg.MaxIndexes <= 0 {
+		return fmt.Errorf("max_indexes must be positive, not %d", cfg.MaxIndexes)
+	}
+	if err := cfg.IndexQueueSettings.validate(); err != nil {
+		return err
+	}
+	for index := range cfg.Indexes {
+		if index == "" {
+			return errors.New("indexes must not contain an empty index")
+		}
+		if err := cfg.settings(index).validate(); err != nil {
+			return fmt.Errorf("index %q: %w", index, err)
+		}
+	}
+	if cfg.Overflow.Directory != "" && cfg.Overflow.MaxSizeMiB <= 0 {
+		return fmt.Errorf("overflow max_size_mib must be positive, not %d", cfg.Overflow.MaxSizeMiB)
+	}
+	return nil
+}
+
+func (settings IndexQueueSettings) validate() error {
+	if settings.QueueSize <= 0 {
+		return fmt.Errorf("queue_size must be positive, not %d", settings.QueueSize)
+	}
+	if settings.NumConsumers <= 0 {
+		return fmt.Errorf("num_consumers must be positive, not %d", settings.NumConsumers)
+	}
+	if settings.RecordsPerSecond < 0 {
+		return fmt.Errorf("records_per_second must not be negative, not %v", settings.RecordsPerSecond)
+	}
+	if settings.Burst < 0 {
+		return fmt.Errorf("burst must not be negative, not %d", settings.Burst)
+	}
+	return nil
+}
+
+// settings returns the queue settings of the index, with the defaults of those it doesn't set.
+func (cfg *Config) settings(index string) IndexQueueSettings {
+	settings := cfg.IndexQueueSettings
+	overrides, ok := cfg.Indexes[index]
+	if !ok {
+		return settings
+	}
+	if overrides.QueueSize != 0 {
+		settings.QueueSize = overrides.QueueSize
+	}
+	if overrides.NumConsumers != 0 {
+		settings.NumConsumers = overrides.NumConsumers
+	}
+	if overrides.RecordsPerSecond != 0 {
+		settings.RecordsPerSecond = overrides.RecordsPerSecond
+		// a burst sized for the default rate doesn't apply to the index's
+		settings.Burst = 0
+	}
+	if overrides.Burst != 0 {
+		settings.Burst = overrides.Burst
+	}
+	return settings
+}
@@ -0,0 +1,122 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.HEC.Token = "some-token"
+	expected.HEC.Endpoint = "https://splunk:8088/services/collector"
+	assert.Equal(t, expected, e0)
+
+	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "throttled")].(*Config)
+	expected = factory.CreateDefaultConfig().(*Config)
+	expected.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "throttled"))
+	expected.HEC.Token = "some-token"
+	expected.HEC.Endpoint = "https://splunk:8088/services/collector"
+	expected.HEC.Index = "main"
+	expected.IndexAttribute = "index"
+	expected.MaxIndexes = 10
+	expected.IndexQueueSettings = IndexQueueSettings{QueueSize: 100, NumConsumers: 4, RecordsPerSecond: 5000}
+	expected.Indexes = map[string]IndexQueueSettings{
+		"security": {QueueSize: 5000, RecordsPerSecond: 200, Burst: 1000},
+		"debug":    {NumConsumers: 1},
+	}
+	expected.Overflow = OverflowSettings{Directory: "/var/lib/otelcol/hec_overflow", MaxSizeMiB: 512}
+	assert.Equal(t, expected, e1)
+
+	assert.Equal(t, IndexQueueSettings{QueueSize: 5000, NumConsumers: 4, RecordsPerSecond: 200, Burst: 1000}, e1.settings("security"))
+	assert.Equal(t, IndexQueueSettings{QueueSize: 100, NumConsumers: 1, RecordsPerSecond: 5000}, e1.settings("debug"))
+	assert.Equal(t, e1.IndexQueueSettings, e1.settings("main"))
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "no index attribute",
+			modify:      func(cfg *Config) { cfg.IndexAttribute = "" },
+			expectedErr: "index_attribute must not be empty",
+		},
+		{
+			name:        "zero max indexes",
+			modify:      func(cfg *Config) { cfg.MaxIndexes = 0 },
+			expectedErr: "max_indexes must be positive, not 0",
+		},
+		{
+			name:        "zero queue size",
+			modify:      func(cfg *Config) { cfg.QueueSize = 0 },
+			expectedErr: "queue_size must be positive, not 0",
+		},
+		{
+			name:        "zero consumers",
+			modify:      func(cfg *Config) { cfg.NumConsumers = 0 },
+			expectedErr: "num_consumers must be positive, not 0",
+		},
+		{
+			name:        "negative rate",
+			modify:      func(cfg *Config) { cfg.RecordsPerSecond = -1 },
+			expectedErr: "records_per_second must not be negative, not -1",
+		},
+		{
+			name:        "negative index burst",
+			modify:      func(cfg *Config) { cfg.Indexes = map[string]IndexQueueSettings{"main": {Burst: -1}} },
+			expectedErr: `index "main": burst must not be negative, not -1`,
+		},
+		{
+			name:        "empty index",
+			modify:      func(cfg *Config) { cfg.Indexes = map[string]IndexQueueSettings{"": {QueueSize: 10}} },
+			expectedErr: "indexes must not contain an empty index",
+		},
+		{
+			name: "zero overflow size",
+			modify: func(cfg *Config) {
+				cfg.Overflow = OverflowSettings{Directory: "/var/lib/otelcol/hec_overflow"}
+			},
+			expectedErr: "overflow max_size_mib must be positive, not 0",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.expectedErr)
+		})
+	}
+}
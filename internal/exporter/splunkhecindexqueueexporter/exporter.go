@@ -0,0 +1,239 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	signalLogs    = "logs"
+	signalMetrics = "metrics"
+)
+
+type newHECFunc func(
+	ctx context.Context, settings component.ExporterCreateSettings, cfg *splunkhecexporter.Config,
+) (*hecExporter, error)
+
+func newLogsHEC(
+	ctx context.Context, settings component.ExporterCreateSettings, cfg *splunkhecexporter.Config,
+) (*hecExporter, error) {
+	exp, err := hecFactory.CreateLogsExporter(ctx, settings, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &hecExporter{Exporter: exp, send: func(ctx context.Context, b batch) error {
+		return exp.ConsumeLogs(ctx, b.(logsBatch).Logs)
+	}}, nil
+}
+
+func newMetricsHEC(
+	ctx context.Context, settings component.ExporterCreateSettings, cfg *splunkhecexporter.Config,
+) (*hecExporter, error) {
+	exp, err := hecFactory.CreateMetricsExporter(ctx, settings, cfg)
+	if err != nil {
+		return nil, err
+	}
+	return &hecExporter{Exporter: exp, send: func(ctx context.Context, b batch) error {
+		return exp.ConsumeMetrics(ctx, b.(metricsBatch).Metrics)
+	}}, nil
+}
+
+// indexQueueExporter queues the records of a signal by their index, so that an index that's throttled
+// or failing doesn't delay the others.
+type indexQueueExporter struct {
+	cfg       *Config
+	signal    string
+	settings  component.ExporterCreateSettings
+	newHEC    newHECFunc
+	unmarshal func([]byte) (batch, error)
+	tags      []tag.Mutator
+	budget    *overflowBudget
+
+	lock   sync.Mutex
+	host   component.Host
+	queues map[string]*indexQueue
+}
+
+func newIndexQueueExporter(
+	cfg *Config,
+	signal string,
+	settings component.ExporterCreateSettings,
+	newHEC newHECFunc,
+	unmarshal func([]byte) (batch, error),
+) (*indexQueueExporter, error) {
+	e := &indexQueueExporter{
+		cfg:       cfg,
+		signal:    signal,
+		settings:  settings,
+		newHEC:    newHEC,
+		unmarshal: unmarshal,
+		tags:      []tag.Mutator{tag.Upsert(exporterKey, cfg.ID().String()), tag.Upsert(signalKey, signal)},
+		budget:    &overflowBudget{max: int64(cfg.Overflow.MaxSizeMiB) << 20},
+		queues:    map[string]*indexQueue{},
+	}
+	indexes := []string{""}
+	for index := range cfg.Indexes {
+		indexes = append(indexes, index)
+	}
+	sort.Strings(indexes)
+	// creating the splunk_hec exporters of the known indexes validates their settings
+	for _, index := range indexes {
+		q, err := e.newQueue(context.Background(), index)
+		if err != nil {
+			return nil, err
+		}
+		e.queues[index] = q
+	}
+	return e, nil
+}
+
+func (e *indexQueueExporter) newQueue(ctx context.Context, index string) (*indexQueue, error) {
+	name := e.cfg.ID().String()
+	hecCfg := e.cfg.HEC
+	if index != "" {
+		name += "/" + index
+		hecCfg.Index = index
+	}
+	hecCfg.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(hecTypeStr, name))
+	hecCfg.QueueSettings.Enabled = false
+
+	settings := e.settings
+	settings.Logger = e.settings.Logger.With(zap.String("index", index))
+	hec, err := e.newHEC(ctx, settings, &hecCfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed creating splunk_hec exporter for index %q: %w", index, err)
+	}
+	return newIndexQueue(index, e.cfg.settings(index), hec, nil, e.unmarshal, settings.Logger, e.tags), nil
+}
+
+func (e *indexQueueExporter) overflowDir() string {
+	if e.cfg.Overflow.Directory == "" {
+		return ""
+	}
+	return filepath.Join(e.cfg.Overflow.Directory, e.signal)
+}
+
+func (e *indexQueueExporter) start(ctx context.Context, host component.Host) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	e.host = host
+	if dir := e.overflowDir(); dir != "" {
+		// resume sending the batches of all the indexes overflowed before a restart
+		entries, err := os.ReadDir(dir)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("failed reading overflow directory: %w", err)
+		}
+		for _, entry := range entries {
+			index, ok := parseIndexDirName(entry.Name())
+			if _, exists := e.queues[index]; !ok || !entry.IsDir() || exists {
+				continue
+			}
+			q, err := e.newQueue(ctx, index)
+			if err != nil {
+				return err
+			}
+			e.queues[index] = q
+		}
+	}
+	for _, q := range e.queues {
+		if err := e.startQueue(ctx, q); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *indexQueueExporter) startQueue(ctx context.Context, q *indexQueue) error {
+	if dir := e.overflowDir(); dir != "" {
+		overflow, err := openOverflowStore(filepath.Join(dir, indexDirName(q.index)), e.budget)
+		if err != nil {
+			return err
+		}
+		q.overflow = overflow
+	}
+	if err := q.hec.Start(ctx, e.host); err != nil {
+		return fmt.Errorf("failed starting splunk_hec exporter for index %q: %w", q.index, err)
+	}
+	q.start()
+	return nil
+}
+
+// queue returns the queue of the index, created if needed. The records of indexes whose queue can't be
+// created are queued for the default index.
+func (e *indexQueueExporter) queue(index string) *indexQueue {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if q, ok := e.queues[index]; ok {
+		return q
+	}
+	if len(e.queues) >= e.cfg.MaxIndexes {
+		e.settings.Logger.Debug("queueing records for the default index, max_indexes reached", zap.String("index", index))
+		return e.queues[""]
+	}
+	q, err := e.newQueue(context.Background(), index)
+	if err == nil {
+		if err = e.startQueue(context.Background(), q); err != nil {
+			_ = q.hec.Shutdown(context.Background())
+		}
+	}
+	if err != nil {
+		e.settings.Logger.Warn("queueing records for the default index", zap.String("index", index), zap.Error(err))
+		return e.queues[""]
+	}
+	e.queues[index] = q
+	return q
+}
+
+func (e *indexQueueExporter) shutdown(ctx context.Context) error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	var errs error
+	for _, q := range e.queues {
+		errs = multierr.Append(errs, q.shutdown(ctx))
+	}
+	return errs
+}
+
+func (e *indexQueueExporter) pushLogs(_ context.Context, ld plog.Logs) error {
+	var errs error
+	for index, ld := range splitLogs(ld, e.cfg.IndexAttribute) {
+		errs = multierr.Append(errs, e.queue(index).offer(logsBatch{ld}))
+	}
+	return errs
+}
+
+func (e *indexQueueExporter) pushMetrics(_ context.Context, md pmetric.Metrics) error {
+	var errs error
+	for index, md := range splitMetrics(md, e.cfg.IndexAttribute) {
+		errs = multierr.Append(errs, e.queue(index).offer(metricsBatch{md}))
+	}
+	return errs
+}
@@ -0,0 +1,148 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// newTestExporter returns a logs exporter whose splunk_hec exporters are fakes, by index, blocking
+// on the release channels of the index if any.
+func newTestExporter(
+	t *testing.T, cfg *Config, releases map[string]chan struct{},
+) (*indexQueueExporter, map[string]*fakeHEC) {
+	hecs := map[string]*fakeHEC{}
+	newHEC := func(
+		_ context.Context, _ component.ExporterCreateSettings, hecCfg *splunkhecexporter.Config,
+	) (*hecExporter, error) {
+		assert.False(t, hecCfg.QueueSettings.Enabled)
+		hec := &fakeHEC{release: releases[hecCfg.Index]}
+		hecs[hecCfg.Index] = hec
+		return hec.exporter(), nil
+	}
+	exp, err := newIndexQueueExporter(cfg, signalLogs, componenttest.NewNopExporterCreateSettings(), newHEC, unmarshalLogsBatch)
+	require.NoError(t, err)
+	return exp, hecs
+}
+
+// newTestLogs returns logs with the number of records of each index.
+func newTestLogs(records map[string]int) plog.Logs {
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	for index, count := range records {
+		for i := 0; i < count; i++ {
+			lr := lrs.AppendEmpty()
+			lr.Body().SetStringVal("some log")
+			if index != "" {
+				lr.Attributes().InsertString(defaultIndexAttribute, index)
+			}
+		}
+	}
+	return ld
+}
+
+func TestExporterQueuesByIndex(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MaxIndexes = 3
+	cfg.Indexes = map[string]IndexQueueSettings{"main": {QueueSize: 10}}
+	exp, hecs := newTestExporter(t, cfg, nil)
+	assert.Len(t, hecs, 2)
+	assert.Contains(t, hecs, "")
+	assert.Contains(t, hecs, "main")
+	assert.Equal(t, 10, exp.queues["main"].settings.QueueSize)
+	assert.Equal(t, defaultQueueSize, exp.queues[""].settings.QueueSize)
+
+	ctx := context.Background()
+	require.NoError(t, exp.start(ctx, componenttest.NewNopHost()))
+	require.NoError(t, exp.pushLogs(ctx, newTestLogs(map[string]int{"main": 2, "security": 3})))
+	// the audit index is beyond max_indexes and shares the queue of the default index
+	require.NoError(t, exp.pushLogs(ctx, newTestLogs(map[string]int{"audit": 4, "": 1})))
+
+	require.Eventually(t, func() bool {
+		return len(hecs[""].sentBatches()) == 2 && len(hecs["main"].sentBatches()) == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Len(t, hecs, 3)
+	require.Eventually(t, func() bool { return len(hecs["security"].sentBatches()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"2"}, hecs["main"].sentBatches())
+	assert.Equal(t, []string{"3"}, hecs["security"].sentBatches())
+	defaultBatches := hecs[""].sentBatches()
+	sort.Strings(defaultBatches)
+	assert.Equal(t, []string{"1", "4"}, defaultBatches)
+
+	require.NoError(t, exp.shutdown(ctx))
+	for index, hec := range hecs {
+		assert.True(t, hec.started, index)
+		assert.True(t, hec.shutdown, index)
+	}
+}
+
+func TestExporterThrottledIndexDoesntBlockOthers(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.QueueSize = 1
+	cfg.NumConsumers = 1
+	throttled := make(chan struct{})
+	exp, hecs := newTestExporter(t, cfg, map[string]chan struct{}{"throttled": throttled})
+	ctx := context.Background()
+	require.NoError(t, exp.start(ctx, componenttest.NewNopHost()))
+
+	require.NoError(t, exp.pushLogs(ctx, newTestLogs(map[string]int{"throttled": 1})))
+	require.Eventually(t, func() bool { return len(exp.queue("throttled").items) == 0 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.pushLogs(ctx, newTestLogs(map[string]int{"throttled": 1})))
+	assert.ErrorIs(t, exp.pushLogs(ctx, newTestLogs(map[string]int{"throttled": 1, "main": 1})), errQueueFull)
+	require.Eventually(t, func() bool { return len(hecs["main"].sentBatches()) == 1 }, 5*time.Second, 10*time.Millisecond)
+
+	for i := 0; i < 5; i++ {
+		require.NoError(t, exp.pushLogs(ctx, newTestLogs(map[string]int{"main": 1})))
+		require.Eventually(t, func() bool { return len(hecs["main"].sentBatches()) == i+2 }, 5*time.Second, 10*time.Millisecond)
+	}
+	assert.Empty(t, hecs["throttled"].sentBatches())
+
+	close(throttled)
+	require.Eventually(t, func() bool { return len(hecs["throttled"].sentBatches()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	require.NoError(t, exp.shutdown(ctx))
+}
+
+func TestExporterResumesOverflowedIndexes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Overflow.Directory = t.TempDir()
+	store, err := openOverflowStore(
+		filepath.Join(cfg.Overflow.Directory, signalLogs, indexDirName("security")), &overflowBudget{max: 1 << 20},
+	)
+	require.NoError(t, err)
+	batch, err := logsBatch{newTestLogs(map[string]int{"security": 3})}.marshal()
+	require.NoError(t, err)
+	require.NoError(t, store.write(batch))
+
+	exp, hecs := newTestExporter(t, cfg, nil)
+	assert.NotContains(t, hecs, "security")
+	ctx := context.Background()
+	require.NoError(t, exp.start(ctx, componenttest.NewNopHost()))
+	require.Contains(t, hecs, "security")
+	require.Eventually(t, func() bool { return len(hecs["security"].sentBatches()) == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, []string{"3"}, hecs["security"].sentBatches())
+	require.NoError(t, exp.shutdown(ctx))
+	assert.Equal(t, int64(0), exp.budget.used)
+}
@@ -0,0 +1,119 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"context"
+	"sync"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.uber.org/zap"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "splunk_hec_index_queue"
+	// The type of the exporters sending the records of each index.
+	hecTypeStr = "splunk_hec"
+
+	defaultIndexAttribute     = "com.splunk.index"
+	defaultMaxIndexes         = 100
+	defaultQueueSize          = 1000
+	defaultNumConsumers       = 2
+	defaultOverflowMaxSizeMiB = 1024
+)
+
+var (
+	hecFactory        = splunkhecexporter.NewFactory()
+	registerViewsOnce sync.Once
+)
+
+// NewFactory creates a factory for the Splunk HEC index queue exporter.
+func NewFactory() component.ExporterFactory {
+	return component.NewExporterFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsExporter(createMetricsExporter),
+		component.WithLogsExporter(createLogsExporter),
+	)
+}
+
+func createDefaultConfig() config.Exporter {
+	return &Config{
+		ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
+		HEC:              *hecFactory.CreateDefaultConfig().(*splunkhecexporter.Config),
+		IndexAttribute:   defaultIndexAttribute,
+		MaxIndexes:       defaultMaxIndexes,
+		IndexQueueSettings: IndexQueueSettings{
+			QueueSize:    defaultQueueSize,
+			NumConsumers: defaultNumConsumers,
+		},
+		Overflow: OverflowSettings{MaxSizeMiB: defaultOverflowMaxSizeMiB},
+	}
+}
+
+func registerViews(logger *zap.Logger) {
+	// the views are shared by all instances, whose measurements are distinguished by the exporter tag
+	registerViewsOnce.Do(func() {
+		if err := view.Register(metricViews()...); err != nil {
+			logger.Warn("failed registering splunk_hec index queue metric views", zap.Error(err))
+		}
+	})
+}
+
+func createMetricsExporter(
+	_ context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.MetricsExporter, error) {
+	registerViews(settings.Logger)
+	exp, err := newIndexQueueExporter(cfg.(*Config), signalMetrics, settings, newMetricsHEC, unmarshalMetricsBatch)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewMetricsExporter(
+		cfg,
+		settings,
+		exp.pushMetrics,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
+
+func createLogsExporter(
+	_ context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.LogsExporter, error) {
+	registerViews(settings.Logger)
+	exp, err := newIndexQueueExporter(cfg.(*Config), signalLogs, settings, newLogsHEC, unmarshalLogsBatch)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewLogsExporter(
+		cfg,
+		settings,
+		exp.pushLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
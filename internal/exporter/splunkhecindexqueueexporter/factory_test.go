@@ -0,0 +1,62 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestCreateExporters(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.HEC.Token = "some-token"
+	cfg.HEC.Endpoint = "http://localhost:8088/services/collector"
+	cfg.Indexes = map[string]IndexQueueSettings{"main": {RecordsPerSecond: 100}}
+	cfg.Overflow.Directory = t.TempDir()
+	ctx := context.Background()
+	settings := componenttest.NewNopExporterCreateSettings()
+	host := componenttest.NewNopHost()
+
+	me, err := factory.CreateMetricsExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, me.Start(ctx, host))
+	require.NoError(t, me.Shutdown(ctx))
+
+	le, err := factory.CreateLogsExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, le.Start(ctx, host))
+	require.NoError(t, le.Shutdown(ctx))
+
+	assert.DirExists(t, cfg.Overflow.Directory+"/metrics/index-main")
+	assert.DirExists(t, cfg.Overflow.Directory+"/logs/index-")
+
+	// the splunk_hec settings are validated by creating the exporters of the known indexes
+	cfg.HEC.Endpoint = ""
+	_, err = factory.CreateLogsExporter(ctx, settings, cfg)
+	assert.Error(t, err)
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+const (
+	reasonQueueFull    = "queue_full"
+	reasonOverflowFull = "overflow_full"
+	reasonSendFailed   = "send_failed"
+	reasonShutdown     = "shutdown"
+)
+
+var (
+	exporterKey = tag.MustNewKey("exporter")
+	signalKey   = tag.MustNewKey("signal")
+	indexKey    = tag.MustNewKey("index")
+	reasonKey   = tag.MustNewKey("reason")
+
+	mQueueSize = stats.Int64(
+		typeStr+"/queue_size", "Number of batches in the memory queue of an index", stats.UnitDimensionless,
+	)
+	mOverflowSize = stats.Int64(
+		typeStr+"/overflow_size", "Size of the overflowed batches of an index", stats.UnitBytes,
+	)
+	mSentRecords = stats.Int64(
+		typeStr+"/sent_records", "Number of log records and datapoints sent to an index", stats.UnitDimensionless,
+	)
+	mOverflowedRecords = stats.Int64(
+		typeStr+"/overflowed_records", "Number of log records and datapoints written to the overflow directory",
+		stats.UnitDimensionless,
+	)
+	mDroppedRecords = stats.Int64(
+		typeStr+"/dropped_records", "Number of log records and datapoints dropped without being sent to an index",
+		stats.UnitDimensionless,
+	)
+)
+
+// metricViews returns the views of the exporter's metrics, which are reported by the collector's own telemetry.
+func metricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mQueueSize.Name(),
+			Description: mQueueSize.Description(),
+			Measure:     mQueueSize,
+			TagKeys:     []tag.Key{exporterKey, signalKey, indexKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mOverflowSize.Name(),
+			Description: mOverflowSize.Description(),
+			Measure:     mOverflowSize,
+			TagKeys:     []tag.Key{exporterKey, signalKey, indexKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mSentRecords.Name(),
+			Description: mSentRecords.Description(),
+			Measure:     mSentRecords,
+			TagKeys:     []tag.Key{exporterKey, signalKey, indexKey},
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mOverflowedRecords.Name(),
+			Description: mOverflowedRecords.Description(),
+			Measure:     mOverflowedRecords,
+			TagKeys:     []tag.Key{exporterKey, signalKey, indexKey},
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mDroppedRecords.Name(),
+			Description: mDroppedRecords.Description(),
+			Measure:     mDroppedRecords,
+			TagKeys:     []tag.Key{exporterKey, signalKey, indexKey, reasonKey},
+			Aggregation: view.Sum(),
+		},
+	}
+}
@@ -0,0 +1,215 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	batchExtension   = ".pb"
+	tmpExtension     = ".tmp"
+	indexDirPrefix   = "index-"
+	sequenceNumWidth = 20
+)
+
+var errOverflowFull = errors.New("overflow directory is full")
+
+// overflowBudget bounds the size of the overflowed batches of all the index queues of a signal.
+type overflowBudget struct {
+	lock sync.Mutex
+	max  int64
+	used int64
+}
+
+func (b *overflowBudget) reserve(size int64) bool {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.used+size > b.max {
+		return false
+	}
+	b.used += size
+	return true
+}
+
+func (b *overflowBudget) release(size int64) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	b.used -= size
+}
+
+// overflowFile is a batch written to the overflow directory of an index.
+type overflowFile struct {
+	path string
+	size int64
+}
+
+// overflowStore persists the batches of an index queue to a directory, one file per batch named by its
+// sequence number so that they're sent in the order they were written.
+type overflowStore struct {
+	dir    string
+	budget *overflowBudget
+	lock   sync.Mutex
+	// the files that aren't being sent, oldest first
+	pending []overflowFile
+	size    int64
+	nextSeq uint64
+}
+
+func indexDirName(index string) string {
+	return indexDirPrefix + url.PathEscape(index)
+}
+
+// parseIndexDirName returns the index of an overflow directory name, and false if it isn't one.
+func parseIndexDirName(name string) (string, bool) {
+	if !strings.HasPrefix(name, indexDirPrefix) {
+		return "", false
+	}
+	index, err := url.PathUnescape(strings.TrimPrefix(name, indexDirPrefix))
+	if err != nil {
+		return "", false
+	}
+	return index, true
+}
+
+// openOverflowStore creates the directory if needed, and otherwise resumes with the batches written to it
+// before, which count toward the budget even if they exceed it.
+func openOverflowStore(dir string, budget *overflowBudget) (*overflowStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed creating overflow directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading overflow directory: %w", err)
+	}
+	store := &overflowStore{dir: dir, budget: budget}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() {
+			continue
+		}
+		if strings.HasSuffix(name, tmpExtension) {
+			// a batch whose writing was interrupted
+			_ = os.Remove(filepath.Join(dir, name))
+			continue
+		}
+		seq, err := strconv.ParseUint(strings.TrimSuffix(name, batchExtension), 10, 64)
+		if err != nil || !strings.HasSuffix(name, batchExtension) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed reading overflow directory: %w", err)
+		}
+		store.pending = append(store.pending, overflowFile{path: filepath.Join(dir, name), size: info.Size()})
+		store.size += info.Size()
+		if seq >= store.nextSeq {
+			store.nextSeq = seq + 1
+		}
+	}
+	// the zero padded names sort by sequence number
+	sort.Slice(store.pending, func(i, j int) bool { return store.pending[i].path < store.pending[j].path })
+	budget.lock.Lock()
+	budget.used += store.size
+	budget.lock.Unlock()
+	return store, nil
+}
+
+// write persists the batch, returning errOverflowFull if it would exceed the budget.
+func (s *overflowStore) write(batch []byte) error {
+	size := int64(len(batch))
+	if !s.budget.reserve(size) {
+		return errOverflowFull
+	}
+	s.lock.Lock()
+	seq := s.nextSeq
+	s.nextSeq++
+	s.lock.Unlock()
+
+	path := filepath.Join(s.dir, fmt.Sprintf("%0*d%s", sequenceNumWidth, seq, batchExtension))
+	// the batch is renamed once complete so that interrupted writes aren't resumed
+	if err := os.WriteFile(path+tmpExtension, batch, 0600); err != nil {
+		_ = os.Remove(path + tmpExtension)
+		s.budget.release(size)
+		return fmt.Errorf("failed writing overflow batch: %w", err)
+	}
+	if err := os.Rename(path+tmpExtension, path); err != nil {
+		_ = os.Remove(path + tmpExtension)
+		s.budget.release(size)
+		return fmt.Errorf("failed writing overflow batch: %w", err)
+	}
+
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	file := overflowFile{path: path, size: size}
+	// concurrent writes can complete out of order
+	i := sort.Search(len(s.pending), func(i int) bool { return s.pending[i].path > path })
+	s.pending = append(s.pending, overflowFile{})
+	copy(s.pending[i+1:], s.pending[i:])
+	s.pending[i] = file
+	s.size += size
+	return nil
+}
+
+// next takes the oldest pending batch to be sent, returning false if there isn't any. The batch is
+// removed by done once sent, and sent again after a restart otherwise.
+func (s *overflowStore) next() (overflowFile, []byte, bool, error) {
+	s.lock.Lock()
+	if len(s.pending) == 0 {
+		s.lock.Unlock()
+		return overflowFile{}, nil, false, nil
+	}
+	file := s.pending[0]
+	s.pending = s.pending[1:]
+	s.lock.Unlock()
+
+	batch, err := os.ReadFile(file.path)
+	if err != nil {
+		s.done(file)
+		return file, nil, true, fmt.Errorf("failed reading overflow batch: %w", err)
+	}
+	return file, batch, true, nil
+}
+
+// done removes the batch taken by next.
+func (s *overflowStore) done(file overflowFile) {
+	_ = os.Remove(file.path)
+	s.budget.release(file.size)
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.size -= file.size
+}
+
+// pendingCount returns the number of batches that aren't being sent.
+func (s *overflowStore) pendingCount() int {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return len(s.pending)
+}
+
+// totalSize returns the size of the batches that haven't been sent.
+func (s *overflowStore) totalSize() int64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	return s.size
+}
@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIndexDirName(t *testing.T) {
+	for _, index := range []string{"", "main", "..", "a/b", "index-x"} {
+		parsed, ok := parseIndexDirName(indexDirName(index))
+		require.True(t, ok)
+		assert.Equal(t, index, parsed)
+	}
+	assert.Equal(t, "index-a%2Fb", indexDirName("a/b"))
+	_, ok := parseIndexDirName("main")
+	assert.False(t, ok)
+	_, ok = parseIndexDirName("index-%zz")
+	assert.False(t, ok)
+}
+
+func TestOverflowStore(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "index-main")
+	budget := &overflowBudget{max: 10}
+	store, err := openOverflowStore(dir, budget)
+	require.NoError(t, err)
+
+	require.NoError(t, store.write([]byte("one")))
+	require.NoError(t, store.write([]byte("two")))
+	require.NoError(t, store.write([]byte("six")))
+	assert.ErrorIs(t, store.write([]byte("four")), errOverflowFull)
+	assert.Equal(t, 3, store.pendingCount())
+	assert.Equal(t, int64(9), store.totalSize())
+	assert.Equal(t, int64(9), budget.used)
+
+	file, batch, ok, err := store.next()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "one", string(batch))
+	store.done(file)
+	assert.NoFileExists(t, file.path)
+	assert.Equal(t, int64(6), budget.used)
+
+	// the batch taken without being done is sent again after a restart
+	_, batch, ok, err = store.next()
+	require.NoError(t, err)
+	require.True(t, ok)
+	assert.Equal(t, "two", string(batch))
+	assert.Equal(t, 1, store.pendingCount())
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "00000000000000000009.pb.tmp"), []byte("partial"), 0600))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "unrelated"), []byte("unrelated"), 0600))
+	budget = &overflowBudget{max: 10}
+	store, err = openOverflowStore(dir, budget)
+	require.NoError(t, err)
+	assert.NoFileExists(t, filepath.Join(dir, "00000000000000000009.pb.tmp"))
+	assert.Equal(t, 2, store.pendingCount())
+	assert.Equal(t, int64(6), budget.used)
+
+	require.NoError(t, store.write([]byte("ten")))
+	var batches []string
+	for {
+		file, batch, ok, err := store.next()
+		require.NoError(t, err)
+		if !ok {
+			break
+		}
+		batches = append(batches, string(batch))
+		store.done(file)
+	}
+	assert.Equal(t, []string{"two", "six", "ten"}, batches)
+	assert.Equal(t, int64(0), budget.used)
+	assert.Equal(t, int64(0), store.totalSize())
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+	assert.Equal(t, "unrelated", entries[0].Name())
+}
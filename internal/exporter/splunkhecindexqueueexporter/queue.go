@@ -0,0 +1,285 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"context"
+	"errors"
+	"math"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+var errQueueFull = errors.New("index queue is full")
+
+// hecExporter is the splunk_hec exporter sending the batches of an index.
+type hecExporter struct {
+	component.Exporter
+	send func(ctx context.Context, b batch) error
+}
+
+// indexQueue sends the batches of an index with its splunk_hec exporter, at the configured rate. The
+// batches that don't fit in its memory queue are written to its overflow store, if any, and sent
+// once the memory queue is empty.
+type indexQueue struct {
+	index      string
+	settings   IndexQueueSettings
+	hec        *hecExporter
+	items      chan batch
+	limiter    *rate.Limiter
+	overflow   *overflowStore
+	overflowed chan struct{}
+	unmarshal  func([]byte) (batch, error)
+	logger     *zap.Logger
+	tags       []tag.Mutator
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func newIndexQueue(
+	index string,
+	settings IndexQueueSettings,
+	hec *hecExporter,
+	overflow *overflowStore,
+	unmarshal func([]byte) (batch, error),
+	logger *zap.Logger,
+	tags []tag.Mutator,
+) *indexQueue {
+	q := &indexQueue{
+		index:      index,
+		settings:   settings,
+		hec:        hec,
+		items:      make(chan batch, settings.QueueSize),
+		overflow:   overflow,
+		overflowed: make(chan struct{}, 1),
+		unmarshal:  unmarshal,
+		logger:     logger,
+		tags:       append(tags[:len(tags):len(tags)], tag.Upsert(indexKey, index)),
+	}
+	if settings.RecordsPerSecond > 0 {
+		if q.settings.Burst == 0 {
+			q.settings.Burst = int(math.Ceil(settings.RecordsPerSecond))
+		}
+		q.limiter = rate.NewLimiter(rate.Limit(settings.RecordsPerSecond), q.settings.Burst)
+	}
+	q.ctx, q.cancel = context.WithCancel(context.Background())
+	return q
+}
+
+func (q *indexQueue) start() {
+	if q.overflow != nil && q.overflow.pendingCount() > 0 {
+		q.logger.Info("sending batches overflowed before restart", zap.Int("batches", q.overflow.pendingCount()))
+		q.recordOverflowSize()
+	}
+	for i := 0; i < q.settings.NumConsumers; i++ {
+		q.wg.Add(1)
+		go q.consume()
+	}
+}
+
+// offer queues the batch without blocking, returning an error if it's dropped.
+func (q *indexQueue) offer(b batch) error {
+	select {
+	case q.items <- b:
+		q.record(mQueueSize.M(int64(len(q.items))))
+		return nil
+	default:
+	}
+	if q.overflow == nil {
+		q.recordDropped(b.records(), reasonQueueFull)
+		return errQueueFull
+	}
+	return q.persist(b)
+}
+
+// persist writes the batch to the overflow store.
+func (q *indexQueue) persist(b batch) error {
+	data, err := b.marshal()
+	if err == nil {
+		err = q.overflow.write(data)
+	}
+	if err != nil {
+		reason := reasonOverflowFull
+		if !errors.Is(err, errOverflowFull) {
+			q.logger.Error("failed overflowing batch", zap.Error(err))
+			reason = reasonSendFailed
+		}
+		q.recordDropped(b.records(), reason)
+		return err
+	}
+	q.record(mOverflowedRecords.M(int64(b.records())))
+	q.recordOverflowSize()
+	select {
+	case q.overflowed <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (q *indexQueue) consume() {
+	defer q.wg.Done()
+	for {
+		b, file, ok := q.next()
+		if !ok {
+			return
+		}
+		q.send(b, file)
+	}
+}
+
+// next returns the next batch to send, preferring the memory queue, and false once shut down.
+func (q *indexQueue) next() (batch, *overflowFile, bool) {
+	for {
+		select {
+		case <-q.ctx.Done():
+			return nil, nil, false
+		case b := <-q.items:
+			q.record(mQueueSize.M(int64(len(q.items))))
+			return b, nil, true
+		default:
+		}
+		if b, file, ok := q.nextOverflowed(); ok {
+			return b, file, true
+		}
+		select {
+		case <-q.ctx.Done():
+			return nil, nil, false
+		case b := <-q.items:
+			q.record(mQueueSize.M(int64(len(q.items))))
+			return b, nil, true
+		case <-q.overflowed:
+		}
+	}
+}
+
+func (q *indexQueue) nextOverflowed() (batch, *overflowFile, bool) {
+	if q.overflow == nil {
+		return nil, nil, false
+	}
+	for {
+		file, data, ok, err := q.overflow.next()
+		if !ok {
+			return nil, nil, false
+		}
+		if q.overflow.pendingCount() > 0 {
+			// wake another consumer for the remaining batches
+			select {
+			case q.overflowed <- struct{}{}:
+			default:
+			}
+		}
+		if err == nil {
+			var b batch
+			if b, err = q.unmarshal(data); err == nil {
+				return b, &file, true
+			}
+			q.overflow.done(file)
+		}
+		q.logger.Error("dropping unreadable overflow batch", zap.String("path", file.path), zap.Error(err))
+		q.recordOverflowSize()
+	}
+}
+
+// send waits for the rate limit and sends the batch, which is overflowed again if interrupted by shutdown.
+func (q *indexQueue) send(b batch, file *overflowFile) {
+	err := q.wait(b.records())
+	if err == nil {
+		err = q.hec.send(q.ctx, b)
+	}
+	if err != nil && q.ctx.Err() != nil {
+		if file == nil {
+			q.requeue(b)
+		}
+		// batches taken from the overflow store are left for the next start
+		return
+	}
+	if file != nil {
+		q.overflow.done(*file)
+		q.recordOverflowSize()
+	}
+	if err != nil {
+		q.logger.Error("failed sending batch", zap.Int("records", b.records()), zap.Error(err))
+		q.recordDropped(b.records(), reasonSendFailed)
+		return
+	}
+	q.record(mSentRecords.M(int64(b.records())))
+}
+
+// wait blocks until the rate limit allows sending the records, in bursts if there are more of them.
+func (q *indexQueue) wait(records int) error {
+	if q.limiter == nil {
+		return nil
+	}
+	for records > 0 {
+		n := records
+		if n > q.settings.Burst {
+			n = q.settings.Burst
+		}
+		if err := q.limiter.WaitN(q.ctx, n); err != nil {
+			return err
+		}
+		records -= n
+	}
+	return nil
+}
+
+// requeue overflows a batch left at shutdown, or drops it if there's no overflow store.
+func (q *indexQueue) requeue(b batch) {
+	if q.overflow == nil {
+		q.recordDropped(b.records(), reasonShutdown)
+		return
+	}
+	_ = q.persist(b)
+}
+
+// shutdown stops sending, overflowing the queued batches if possible, and shuts down the splunk_hec exporter.
+func (q *indexQueue) shutdown(ctx context.Context) error {
+	q.cancel()
+	q.wg.Wait()
+	dropped := 0
+	for len(q.items) > 0 {
+		b := <-q.items
+		if q.overflow == nil {
+			dropped += b.records()
+		}
+		q.requeue(b)
+	}
+	if dropped > 0 {
+		q.logger.Warn("dropped queued records at shutdown, configure an overflow directory to keep them",
+			zap.Int("records", dropped))
+	}
+	q.record(mQueueSize.M(0))
+	return q.hec.Shutdown(ctx)
+}
+
+func (q *indexQueue) recordDropped(records int, reason string) {
+	tags := append(q.tags[:len(q.tags):len(q.tags)], tag.Upsert(reasonKey, reason))
+	_ = stats.RecordWithTags(context.Background(), tags, mDroppedRecords.M(int64(records)))
+}
+
+func (q *indexQueue) recordOverflowSize() {
+	q.record(mOverflowSize.M(q.overflow.totalSize()))
+}
+
+func (q *indexQueue) record(measurement stats.Measurement) {
+	_ = stats.RecordWithTags(context.Background(), q.tags, measurement)
+}
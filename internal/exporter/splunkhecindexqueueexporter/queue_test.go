@@ -0,0 +1,237 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// testBatch is a batch of records named by their batch.
+type testBatch struct {
+	name  string
+	count int
+}
+
+func (b testBatch) records() int { return b.count }
+
+func (b testBatch) marshal() ([]byte, error) {
+	return []byte(b.name + ":" + strconv.Itoa(b.count)), nil
+}
+
+func unmarshalTestBatch(data []byte) (batch, error) {
+	name, count, ok := strings.Cut(string(data), ":")
+	if !ok {
+		return nil, errors.New("invalid test batch")
+	}
+	n, err := strconv.Atoi(count)
+	return testBatch{name: name, count: n}, err
+}
+
+// fakeHEC records the batches sent, blocking until released if it has a release channel.
+type fakeHEC struct {
+	lock     sync.Mutex
+	sent     []string
+	err      error
+	release  chan struct{}
+	started  bool
+	shutdown bool
+}
+
+func (f *fakeHEC) Start(context.Context, component.Host) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.started = true
+	return nil
+}
+
+func (f *fakeHEC) Shutdown(context.Context) error {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.shutdown = true
+	return nil
+}
+
+func (f *fakeHEC) send(ctx context.Context, b batch) error {
+	if f.release != nil {
+		select {
+		case <-f.release:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.err != nil {
+		return f.err
+	}
+	if tb, ok := b.(testBatch); ok {
+		f.sent = append(f.sent, tb.name)
+	} else {
+		f.sent = append(f.sent, strconv.Itoa(b.records()))
+	}
+	return nil
+}
+
+func (f *fakeHEC) sentBatches() []string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return append([]string{}, f.sent...)
+}
+
+func (f *fakeHEC) exporter() *hecExporter {
+	return &hecExporter{Exporter: f, send: f.send}
+}
+
+func newTestQueue(t *testing.T, hec *fakeHEC, settings IndexQueueSettings, overflowDir string) *indexQueue {
+	var overflow *overflowStore
+	if overflowDir != "" {
+		var err error
+		overflow, err = openOverflowStore(overflowDir, &overflowBudget{max: 1 << 20})
+		require.NoError(t, err)
+	}
+	return newIndexQueue("main", settings, hec.exporter(), overflow, unmarshalTestBatch, zap.NewNop(), nil)
+}
+
+func TestIndexQueueSends(t *testing.T) {
+	hec := &fakeHEC{}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 10, NumConsumers: 1}, "")
+	q.start()
+	require.NoError(t, q.offer(testBatch{name: "a", count: 1}))
+	require.NoError(t, q.offer(testBatch{name: "b", count: 1}))
+	require.Eventually(t, func() bool { return len(hec.sentBatches()) == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, hec.sentBatches())
+	require.NoError(t, q.shutdown(context.Background()))
+	assert.True(t, hec.shutdown)
+}
+
+func TestIndexQueueFull(t *testing.T) {
+	hec := &fakeHEC{release: make(chan struct{})}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 1, NumConsumers: 1}, "")
+	q.start()
+	require.NoError(t, q.offer(testBatch{name: "a", count: 1}))
+	// a is being sent
+	require.Eventually(t, func() bool { return len(q.items) == 0 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, q.offer(testBatch{name: "b", count: 1}))
+	assert.ErrorIs(t, q.offer(testBatch{name: "c", count: 1}), errQueueFull)
+
+	close(hec.release)
+	require.Eventually(t, func() bool { return len(hec.sentBatches()) == 2 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b"}, hec.sentBatches())
+	require.NoError(t, q.shutdown(context.Background()))
+}
+
+func TestIndexQueueOverflow(t *testing.T) {
+	dir := t.TempDir()
+	hec := &fakeHEC{release: make(chan struct{})}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 1, NumConsumers: 1}, dir)
+	q.start()
+	require.NoError(t, q.offer(testBatch{name: "a", count: 1}))
+	require.Eventually(t, func() bool { return len(q.items) == 0 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, q.offer(testBatch{name: "b", count: 1}))
+	require.NoError(t, q.offer(testBatch{name: "c", count: 1}))
+	require.NoError(t, q.offer(testBatch{name: "d", count: 1}))
+	assert.Equal(t, 2, q.overflow.pendingCount())
+
+	close(hec.release)
+	require.Eventually(t, func() bool { return len(hec.sentBatches()) == 4 }, 5*time.Second, time.Millisecond)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, hec.sentBatches())
+	assert.Equal(t, int64(0), q.overflow.totalSize())
+	require.NoError(t, q.shutdown(context.Background()))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestIndexQueueShutdownOverflows(t *testing.T) {
+	dir := t.TempDir()
+	hec := &fakeHEC{release: make(chan struct{})}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 1, NumConsumers: 1}, dir)
+	q.start()
+	require.NoError(t, q.offer(testBatch{name: "a", count: 1}))
+	require.Eventually(t, func() bool { return len(q.items) == 0 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, q.offer(testBatch{name: "b", count: 1}))
+	require.NoError(t, q.offer(testBatch{name: "c", count: 1}))
+	require.NoError(t, q.shutdown(context.Background()))
+	assert.Empty(t, hec.sentBatches())
+
+	// the interrupted, queued, and overflowed batches are sent after a restart
+	hec = &fakeHEC{}
+	q = newTestQueue(t, hec, IndexQueueSettings{QueueSize: 1, NumConsumers: 2}, dir)
+	assert.Equal(t, 3, q.overflow.pendingCount())
+	q.start()
+	require.Eventually(t, func() bool { return len(hec.sentBatches()) == 3 }, 5*time.Second, time.Millisecond)
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, hec.sentBatches())
+	require.NoError(t, q.shutdown(context.Background()))
+}
+
+func TestIndexQueueShutdownDrops(t *testing.T) {
+	hec := &fakeHEC{release: make(chan struct{})}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 1, NumConsumers: 1}, "")
+	q.start()
+	require.NoError(t, q.offer(testBatch{name: "a", count: 1}))
+	require.Eventually(t, func() bool { return len(q.items) == 0 }, 5*time.Second, time.Millisecond)
+	require.NoError(t, q.offer(testBatch{name: "b", count: 1}))
+	require.NoError(t, q.shutdown(context.Background()))
+	assert.Empty(t, hec.sentBatches())
+	assert.Empty(t, q.items)
+}
+
+func TestIndexQueueSendFailure(t *testing.T) {
+	dir := t.TempDir()
+	hec := &fakeHEC{err: errors.New("throttled")}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 1, NumConsumers: 1}, dir)
+	require.NoError(t, q.overflow.write([]byte("a:1")))
+	require.NoError(t, q.overflow.write([]byte("unreadable")))
+	q.start()
+	require.NoError(t, q.offer(testBatch{name: "b", count: 1}))
+	// the failed and unreadable batches are dropped
+	require.Eventually(t, func() bool {
+		return q.overflow.totalSize() == 0 && len(q.items) == 0
+	}, 5*time.Second, time.Millisecond)
+	require.NoError(t, q.shutdown(context.Background()))
+
+	entries, err := os.ReadDir(dir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestIndexQueueRateLimit(t *testing.T) {
+	hec := &fakeHEC{}
+	q := newTestQueue(t, hec, IndexQueueSettings{QueueSize: 10, NumConsumers: 1, RecordsPerSecond: 100, Burst: 10}, "")
+	q.start()
+	start := time.Now()
+	require.NoError(t, q.offer(testBatch{name: "a", count: 10}))
+	require.NoError(t, q.offer(testBatch{name: "b", count: 20}))
+	require.Eventually(t, func() bool { return len(hec.sentBatches()) == 2 }, 5*time.Second, time.Millisecond)
+	// the burst allows sending a right away, and b takes another 200ms
+	assert.GreaterOrEqual(t, time.Since(start), 150*time.Millisecond)
+	require.NoError(t, q.shutdown(context.Background()))
+
+	q = newTestQueue(t, hec, IndexQueueSettings{QueueSize: 10, NumConsumers: 1, RecordsPerSecond: 0.5}, "")
+	assert.Equal(t, 1, q.settings.Burst)
+}
@@ -0,0 +1,154 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// recordIndex returns the index of a record, from its attributes or those of its resource.
+func recordIndex(attribute string, resourceAttrs, recordAttrs pcommon.Map) string {
+	if value, ok := recordAttrs.Get(attribute); ok {
+		return value.AsString()
+	}
+	if value, ok := resourceAttrs.Get(attribute); ok {
+		return value.AsString()
+	}
+	return ""
+}
+
+// splitLogs groups the log records by their index. The batch is returned as is if all of its records
+// have the same index.
+func splitLogs(ld plog.Logs, attribute string) map[string]plog.Logs {
+	indexes := map[string]bool{}
+	forEachLogRecord(ld, func(resourceAttrs pcommon.Map, lr plog.LogRecord) bool {
+		indexes[recordIndex(attribute, resourceAttrs, lr.Attributes())] = true
+		return false
+	})
+	batches := make(map[string]plog.Logs, len(indexes))
+	if len(indexes) <= 1 {
+		for index := range indexes {
+			batches[index] = ld
+		}
+		return batches
+	}
+	for index := range indexes {
+		batch := ld.Clone()
+		forEachLogRecord(batch, func(resourceAttrs pcommon.Map, lr plog.LogRecord) bool {
+			return recordIndex(attribute, resourceAttrs, lr.Attributes()) != index
+		})
+		batches[index] = batch
+	}
+	return batches
+}
+
+// forEachLogRecord calls fn with every log record, removing those for which it returns true along with
+// the scopes and resources they leave empty.
+func forEachLogRecord(ld plog.Logs, fn func(resourceAttrs pcommon.Map, lr plog.LogRecord) bool) {
+	rls := ld.ResourceLogs()
+	rls.RemoveIf(func(rl plog.ResourceLogs) bool {
+		resourceAttrs := rl.Resource().Attributes()
+		removed := false
+		sls := rl.ScopeLogs()
+		sls.RemoveIf(func(sl plog.ScopeLogs) bool {
+			lrs := sl.LogRecords()
+			lrs.RemoveIf(func(lr plog.LogRecord) bool {
+				remove := fn(resourceAttrs, lr)
+				removed = removed || remove
+				return remove
+			})
+			return removed && lrs.Len() == 0
+		})
+		return removed && sls.Len() == 0
+	})
+}
+
+// splitMetrics groups the datapoints by their index. The batch is returned as is if all of its
+// datapoints have the same index.
+func splitMetrics(md pmetric.Metrics, attribute string) map[string]pmetric.Metrics {
+	indexes := map[string]bool{}
+	forEachDataPoint(md, func(resourceAttrs, dpAttrs pcommon.Map) bool {
+		indexes[recordIndex(attribute, resourceAttrs, dpAttrs)] = true
+		return false
+	})
+	batches := make(map[string]pmetric.Metrics, len(indexes))
+	if len(indexes) <= 1 {
+		for index := range indexes {
+			batches[index] = md
+		}
+		return batches
+	}
+	for index := range indexes {
+		batch := md.Clone()
+		forEachDataPoint(batch, func(resourceAttrs, dpAttrs pcommon.Map) bool {
+			return recordIndex(attribute, resourceAttrs, dpAttrs) != index
+		})
+		batches[index] = batch
+	}
+	return batches
+}
+
+// forEachDataPoint calls fn with the attributes of every datapoint, removing those for which it returns
+// true along with the metrics, scopes, and resources they leave empty.
+func forEachDataPoint(md pmetric.Metrics, fn func(resourceAttrs, dpAttrs pcommon.Map) bool) {
+	rms := md.ResourceMetrics()
+	rms.RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		resourceAttrs := rm.Resource().Attributes()
+		removed := false
+		sms := rm.ScopeMetrics()
+		sms.RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			metrics := sm.Metrics()
+			metrics.RemoveIf(func(metric pmetric.Metric) bool {
+				return removeDataPoints(metric, func(dpAttrs pcommon.Map) bool {
+					remove := fn(resourceAttrs, dpAttrs)
+					removed = removed || remove
+					return remove
+				}) == 0 && removed
+			})
+			return removed && metrics.Len() == 0
+		})
+		return removed && sms.Len() == 0
+	})
+}
+
+// removeDataPoints removes the datapoints of the metric for which fn returns true, and returns the
+// number of those left.
+func removeDataPoints(metric pmetric.Metric, fn func(dpAttrs pcommon.Map) bool) int {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		dps.RemoveIf(func(dp pmetric.NumberDataPoint) bool { return fn(dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		dps.RemoveIf(func(dp pmetric.NumberDataPoint) bool { return fn(dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		dps.RemoveIf(func(dp pmetric.HistogramDataPoint) bool { return fn(dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		dps.RemoveIf(func(dp pmetric.ExponentialHistogramDataPoint) bool { return fn(dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		dps.RemoveIf(func(dp pmetric.SummaryDataPoint) bool { return fn(dp.Attributes()) })
+		return dps.Len()
+	}
+	return 0
+}
@@ -0,0 +1,123 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkhecindexqueueexporter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func TestSplitLogs(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString(defaultIndexAttribute, "main")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Body().SetStringVal("main")
+	lr := lrs.AppendEmpty()
+	lr.Body().SetStringVal("security")
+	lr.Attributes().InsertString(defaultIndexAttribute, "security")
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("default")
+	original := ld.Clone()
+
+	batches := splitLogs(ld, defaultIndexAttribute)
+	require.Len(t, batches, 3)
+	assert.Equal(t, original, ld)
+	for index, body := range map[string]string{"main": "main", "security": "security", "": "default"} {
+		batch := batches[index]
+		require.Equal(t, 1, batch.LogRecordCount(), index)
+		require.Equal(t, 1, batch.ResourceLogs().Len(), index)
+		assert.Equal(t, body, batch.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Body().StringVal())
+	}
+	_, ok := batches["main"].ResourceLogs().At(0).Resource().Attributes().Get(defaultIndexAttribute)
+	assert.True(t, ok)
+
+	batches = splitLogs(batches["main"], defaultIndexAttribute)
+	require.Len(t, batches, 1)
+	assert.Equal(t, 1, batches["main"].LogRecordCount())
+
+	assert.Empty(t, splitLogs(plog.NewLogs(), defaultIndexAttribute))
+}
+
+func TestSplitMetrics(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("index", "main")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("gauge")
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty().SetIntVal(1)
+	dp := gauge.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(2)
+	dp.Attributes().InsertString("index", "security")
+
+	histogram := metrics.AppendEmpty()
+	histogram.SetName("histogram")
+	histogram.SetDataType(pmetric.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("index", "security")
+
+	empty := metrics.AppendEmpty()
+	empty.SetName("empty")
+	empty.SetDataType(pmetric.MetricDataTypeSum)
+
+	sum := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	sum.SetName("sum")
+	sum.SetDataType(pmetric.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty().SetIntVal(3)
+	original := md.Clone()
+
+	batches := splitMetrics(md, "index")
+	require.Len(t, batches, 3)
+	assert.Equal(t, original, md)
+
+	mainBatch := batches["main"]
+	assert.Equal(t, 1, mainBatch.DataPointCount())
+	require.Equal(t, 1, mainBatch.ResourceMetrics().Len())
+	// metrics without datapoints are removed along with those of other indexes
+	require.Equal(t, []string{"gauge"}, metricNames(mainBatch))
+	dps := mainBatch.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	assert.Equal(t, int64(1), dps.At(0).IntVal())
+
+	security := batches["security"]
+	assert.Equal(t, 2, security.DataPointCount())
+	assert.Equal(t, []string{"gauge", "histogram"}, metricNames(security))
+
+	assert.Equal(t, 1, batches[""].DataPointCount())
+	assert.Equal(t, []string{"sum"}, metricNames(batches[""]))
+
+	batches = splitMetrics(batches[""], "index")
+	require.Len(t, batches, 1)
+	assert.Equal(t, 1, batches[""].DataPointCount())
+}
+
+func metricNames(md pmetric.Metrics) []string {
+	var names []string
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				names = append(names, metrics.At(k).Name())
+			}
+		}
+	}
+	return names
+}
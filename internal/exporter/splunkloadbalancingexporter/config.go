@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+// Config defines configuration for the Splunk load balancing exporter.
+type Config struct {
+	config.ExporterSettings        `mapstructure:",squash"`
+	exporterhelper.TimeoutSettings `mapstructure:",squash"`
+	exporterhelper.QueueSettings   `mapstructure:"sending_queue"`
+	exporterhelper.RetrySettings   `mapstructure:"retry_on_failure"`
+	// Gateways are the endpoints of the downstream gateways: the ingest_url of the signalfx exporters,
+	// or the endpoint of the splunk_hec exporters, sending to each of them.
+	Gateways []string `mapstructure:"gateways"`
+	// Exporter is the type of the exporters sending to the gateways, signalfx or splunk_hec.
+	Exporter string `mapstructure:"exporter"`
+	// SignalFx are the settings of the signalfx exporters, whose ingest_url is that of their gateway.
+	SignalFx signalfxexporter.Config `mapstructure:"signalfx"`
+	// HEC are the settings of the splunk_hec exporters, whose endpoint is that of their gateway.
+	HEC splunkhecexporter.Config `mapstructure:"splunk_hec"`
+	// RoutingAttributes are the resource attributes identifying the resources consistently routed to
+	// the same gateway. All the resource attributes identify them if empty.
+	RoutingAttributes []string `mapstructure:"routing_attributes"`
+	// Health are the settings of the eviction of failing gateways.
+	Health HealthSettings `mapstructure:"health"`
+}
+
+// HealthSettings are the settings of the eviction of failing gateways, whose resources are routed to
+// the next gateways while they're evicted.
+type HealthSettings struct {
+	// FailureThreshold is the number of consecutive failed requests after which a gateway is evicted.
+	FailureThreshold int `mapstructure:"failure_threshold"`
+	// EvictionDuration is how long a gateway is evicted for, before it's sent requests again.
+	EvictionDuration time.Duration `mapstructure:"eviction_duration"`
+}
+
+var _ config.Exporter = (*Config)(nil)
+
+// Validate checks if the exporter configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Gateways) == 0 {
+		return errors.New("gateways must not be empty")
+	}
+	gateways := map[string]bool{}
+	for _, gateway := range cfg.Gateways {
+		if gateway == "" {
+			return errors.New("gateways must not contain an empty endpoint")
+		}
+		if gateways[gateway] {
+			return fmt.Errorf("gateways must be unique, %q is repeated", gateway)
+		}
+		gateways[gateway] = true
+	}
+	if cfg.Exporter != signalfxTypeStr && cfg.Exporter != hecTypeStr {
+		return fmt.Errorf("exporter must be %s or %s, not %q", signalfxTypeStr, hecTypeStr, cfg.Exporter)
+	}
+	for _, attribute := range cfg.RoutingAttributes {
+		if attribute == "" {
+			return errors.New("routing_attributes must not contain an empty attribute")
+		}
+	}
+	if cfg.Health.FailureThreshold <= 0 {
+		return fmt.Errorf("health failure_threshold must be positive, not %d", cfg.Health.FailureThreshold)
+	}
+	if cfg.Health.EvictionDuration <= 0 {
+		return fmt.Errorf("health eviction_duration must be positive, not %s", cfg.Health.EvictionDuration)
+	}
+	return nil
+}
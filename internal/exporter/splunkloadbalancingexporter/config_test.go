@@ -0,0 +1,113 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Exporters[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Exporters[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.Gateways = []string{"http://gateway-0:9943", "http://gateway-1:9943"}
+	expected.SignalFx.AccessToken = "some-token"
+	expected.SignalFx.APIURL = "http://gateway:6060"
+	assert.Equal(t, expected, e0)
+
+	e1 := cfg.Exporters[config.NewComponentIDWithName(typeStr, "hec")]
+	expected = factory.CreateDefaultConfig().(*Config)
+	expected.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(typeStr, "hec"))
+	expected.Gateways = []string{
+		"https://gateway-0:8088/services/collector",
+		"https://gateway-1:8088/services/collector",
+		"https://gateway-2:8088/services/collector",
+	}
+	expected.Exporter = "splunk_hec"
+	expected.HEC.Token = "some-token"
+	expected.HEC.Index = "main"
+	expected.RoutingAttributes = []string{"host.name", "k8s.cluster.name"}
+	expected.Health = HealthSettings{FailureThreshold: 5, EvictionDuration: time.Minute}
+	assert.Equal(t, expected, e1)
+}
+
+func TestConfigValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "no gateways",
+			modify:      func(cfg *Config) { cfg.Gateways = nil },
+			expectedErr: "gateways must not be empty",
+		},
+		{
+			name:        "empty gateway",
+			modify:      func(cfg *Config) { cfg.Gateways = append(cfg.Gateways, "") },
+			expectedErr: "gateways must not contain an empty endpoint",
+		},
+		{
+			name:        "repeated gateway",
+			modify:      func(cfg *Config) { cfg.Gateways = append(cfg.Gateways, cfg.Gateways[0]) },
+			expectedErr: `gateways must be unique, "http://gateway-0:9943" is repeated`,
+		},
+		{
+			name:        "unknown exporter",
+			modify:      func(cfg *Config) { cfg.Exporter = "otlp" },
+			expectedErr: `exporter must be signalfx or splunk_hec, not "otlp"`,
+		},
+		{
+			name:        "empty routing attribute",
+			modify:      func(cfg *Config) { cfg.RoutingAttributes = []string{"host.name", ""} },
+			expectedErr: "routing_attributes must not contain an empty attribute",
+		},
+		{
+			name:        "zero failure threshold",
+			modify:      func(cfg *Config) { cfg.Health.FailureThreshold = 0 },
+			expectedErr: "health failure_threshold must be positive, not 0",
+		},
+		{
+			name:        "negative eviction duration",
+			modify:      func(cfg *Config) { cfg.Health.EvictionDuration = -time.Second },
+			expectedErr: "health eviction_duration must be positive, not -1s",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Gateways = []string{"http://gateway-0:9943", "http://gateway-1:9943"}
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,291 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+// member is the exporter sending to a gateway, with the gateway's health.
+type member struct {
+	exporter component.Exporter
+	send     func(ctx context.Context, data any) error
+	endpoint string
+	// failures is the number of consecutive failed requests
+	failures     int
+	evictedUntil time.Time
+}
+
+type newMemberFunc func(
+	ctx context.Context, settings component.ExporterCreateSettings, cfg *Config, endpoint string, index int,
+) (*member, error)
+
+// memberConfig returns the factory and the config of the exporter sending to the gateway at the endpoint. Its
+// own queue and retries are disabled: failed requests are sent to the next gateways, and retried by the
+// load balancing exporter once none is left.
+func memberConfig(cfg *Config, endpoint string, index int) (component.ExporterFactory, config.Exporter) {
+	name := fmt.Sprintf("%s/%d", cfg.ID().String(), index)
+	if cfg.Exporter == hecTypeStr {
+		hecCfg := cfg.HEC
+		hecCfg.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(hecTypeStr, name))
+		hecCfg.Endpoint = endpoint
+		hecCfg.QueueSettings.Enabled = false
+		hecCfg.RetrySettings.Enabled = false
+		return hecFactory, &hecCfg
+	}
+	sfxCfg := cfg.SignalFx
+	sfxCfg.ExporterSettings = config.NewExporterSettings(config.NewComponentIDWithName(signalfxTypeStr, name))
+	sfxCfg.IngestURL = endpoint
+	sfxCfg.QueueSettings.Enabled = false
+	sfxCfg.RetrySettings.Enabled = false
+	return signalfxFactory, &sfxCfg
+}
+
+func newMetricsMember(
+	ctx context.Context, settings component.ExporterCreateSettings, cfg *Config, endpoint string, index int,
+) (*member, error) {
+	factory, memberCfg := memberConfig(cfg, endpoint, index)
+	exp, err := factory.CreateMetricsExporter(ctx, settings, memberCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &member{exporter: exp, endpoint: endpoint, send: func(ctx context.Context, data any) error {
+		return exp.ConsumeMetrics(ctx, data.(pmetric.Metrics))
+	}}, nil
+}
+
+func newLogsMember(
+	ctx context.Context, settings component.ExporterCreateSettings, cfg *Config, endpoint string, index int,
+) (*member, error) {
+	factory, memberCfg := memberConfig(cfg, endpoint, index)
+	exp, err := factory.CreateLogsExporter(ctx, settings, memberCfg)
+	if err != nil {
+		return nil, err
+	}
+	return &member{exporter: exp, endpoint: endpoint, send: func(ctx context.Context, data any) error {
+		return exp.ConsumeLogs(ctx, data.(plog.Logs))
+	}}, nil
+}
+
+// loadBalancingExporter consistently routes the resources of a signal to the gateways by their identity,
+// evicting the gateways that keep failing and sending their resources to the next gateways of the ring.
+type loadBalancingExporter struct {
+	cfg     *Config
+	logger  *zap.Logger
+	now     func() time.Time
+	ring    *hashRing
+	members []*member
+	lock    sync.Mutex
+}
+
+func newLoadBalancingExporter(
+	ctx context.Context,
+	cfg *Config,
+	settings component.ExporterCreateSettings,
+	newMember newMemberFunc,
+) (*loadBalancingExporter, error) {
+	e := &loadBalancingExporter{
+		cfg:    cfg,
+		logger: settings.Logger,
+		now:    time.Now,
+		ring:   newHashRing(cfg.Gateways),
+	}
+	// creating the exporters of the gateways validates their settings
+	for i, endpoint := range cfg.Gateways {
+		memberSettings := settings
+		memberSettings.Logger = settings.Logger.With(zap.String("gateway", endpoint))
+		m, err := newMember(ctx, memberSettings, cfg, endpoint, i)
+		if err != nil {
+			return nil, fmt.Errorf("failed creating %s exporter for gateway %q: %w", cfg.Exporter, endpoint, err)
+		}
+		e.members = append(e.members, m)
+	}
+	return e, nil
+}
+
+func (e *loadBalancingExporter) start(ctx context.Context, host component.Host) error {
+	for _, m := range e.members {
+		if err := m.exporter.Start(ctx, host); err != nil {
+			return fmt.Errorf("failed starting exporter for gateway %q: %w", m.endpoint, err)
+		}
+	}
+	return nil
+}
+
+func (e *loadBalancingExporter) shutdown(ctx context.Context) error {
+	var errs error
+	for _, m := range e.members {
+		errs = multierr.Append(errs, m.exporter.Shutdown(ctx))
+	}
+	return errs
+}
+
+func (e *loadBalancingExporter) pushMetrics(ctx context.Context, md pmetric.Metrics) error {
+	rms := md.ResourceMetrics()
+	keys := make([]uint64, rms.Len())
+	for i := range keys {
+		keys[i] = resourceKey(rms.At(i).Resource(), e.cfg.RoutingAttributes)
+	}
+	subset := func(indexes []int) any {
+		if len(indexes) == rms.Len() {
+			return md
+		}
+		out := pmetric.NewMetrics()
+		for _, i := range indexes {
+			rms.At(i).CopyTo(out.ResourceMetrics().AppendEmpty())
+		}
+		return out
+	}
+	failed, err := e.route(ctx, keys, subset)
+	if len(failed) != 0 {
+		return consumererror.NewMetrics(err, subset(failed).(pmetric.Metrics))
+	}
+	return err
+}
+
+func (e *loadBalancingExporter) pushLogs(ctx context.Context, ld plog.Logs) error {
+	rls := ld.ResourceLogs()
+	keys := make([]uint64, rls.Len())
+	for i := range keys {
+		keys[i] = resourceKey(rls.At(i).Resource(), e.cfg.RoutingAttributes)
+	}
+	subset := func(indexes []int) any {
+		if len(indexes) == rls.Len() {
+			return ld
+		}
+		out := plog.NewLogs()
+		for _, i := range indexes {
+			rls.At(i).CopyTo(out.ResourceLogs().AppendEmpty())
+		}
+		return out
+	}
+	failed, err := e.route(ctx, keys, subset)
+	if len(failed) != 0 {
+		return consumererror.NewLogs(err, subset(failed).(plog.Logs))
+	}
+	return err
+}
+
+// route sends the resources, by index, to the gateways their keys hash to, sending those of a failed request
+// to the next gateways of the ring. It returns the resources no gateway accepted, to be retried, and the
+// errors of the failed requests. Resources rejected with a permanent error aren't sent to the next gateways.
+func (e *loadBalancingExporter) route(ctx context.Context, keys []uint64, subset func(indexes []int) any) ([]int, error) {
+	tried := make([]map[int]bool, len(keys))
+	pending := make([]int, len(keys))
+	for i := range pending {
+		pending[i] = i
+	}
+
+	var failed []int
+	var errs, permanentErrs error
+	for len(pending) != 0 {
+		groups := map[int][]int{}
+		var order []int
+		for _, i := range pending {
+			gateway := e.pick(keys[i], tried[i])
+			if gateway < 0 {
+				failed = append(failed, i)
+				continue
+			}
+			if _, ok := groups[gateway]; !ok {
+				order = append(order, gateway)
+			}
+			groups[gateway] = append(groups[gateway], i)
+		}
+
+		pending = nil
+		for _, gateway := range order {
+			indexes := groups[gateway]
+			m := e.members[gateway]
+			err := m.send(ctx, subset(indexes))
+			e.recordResult(m, err)
+			switch {
+			case err == nil:
+			case consumererror.IsPermanent(err):
+				permanentErrs = multierr.Append(permanentErrs, fmt.Errorf("gateway %q: %w", m.endpoint, err))
+			default:
+				errs = multierr.Append(errs, fmt.Errorf("gateway %q: %w", m.endpoint, err))
+				for _, i := range indexes {
+					if tried[i] == nil {
+						tried[i] = map[int]bool{}
+					}
+					tried[i][gateway] = true
+				}
+				pending = append(pending, indexes...)
+			}
+		}
+	}
+
+	if len(failed) != 0 {
+		return failed, multierr.Append(errs, permanentErrs)
+	}
+	if errs != nil {
+		e.logger.Debug("Sent the resources of failed requests to other gateways", zap.Error(errs))
+	}
+	if permanentErrs != nil {
+		return nil, consumererror.NewPermanent(permanentErrs)
+	}
+	return nil, nil
+}
+
+// pick returns the gateway of the resource key, skipping the tried and evicted gateways, -1 if all have been
+// tried. Evicted gateways are only picked when all the gateways left are, rather than dropping the resource.
+func (e *loadBalancingExporter) pick(key uint64, tried map[int]bool) int {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	now := e.now()
+	gateway := e.ring.pick(key, func(gateway int) bool {
+		return !tried[gateway] && !now.Before(e.members[gateway].evictedUntil)
+	})
+	if gateway < 0 {
+		gateway = e.ring.pick(key, func(gateway int) bool { return !tried[gateway] })
+	}
+	return gateway
+}
+
+// recordResult updates the health of the gateway with the result of a request, evicting it once it has failed
+// failure_threshold consecutive requests. Requests rejected with a permanent error were handled by the gateway.
+func (e *loadBalancingExporter) recordResult(m *member, err error) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if err == nil || consumererror.IsPermanent(err) {
+		m.failures = 0
+		return
+	}
+	m.failures++
+	if m.failures < e.cfg.Health.FailureThreshold {
+		return
+	}
+	m.evictedUntil = e.now().Add(e.cfg.Health.EvictionDuration)
+	// a gateway failing its first request after its eviction is evicted again
+	m.failures = e.cfg.Health.FailureThreshold - 1
+	e.logger.Warn(
+		"Evicting gateway after consecutive failed requests",
+		zap.String("gateway", m.endpoint),
+		zap.Duration("eviction_duration", e.cfg.Health.EvictionDuration),
+		zap.Error(err),
+	)
+}
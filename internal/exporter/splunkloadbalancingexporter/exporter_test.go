@@ -0,0 +1,306 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumererror"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// fakeGateways records the host.name of the resources sent to each gateway, failing the requests to the
+// gateways with an error.
+type fakeGateways struct {
+	errs     map[string]error
+	received map[string][]string
+	requests map[string]int
+	lock     sync.Mutex
+}
+
+func newFakeGateways() *fakeGateways {
+	return &fakeGateways{errs: map[string]error{}, received: map[string][]string{}, requests: map[string]int{}}
+}
+
+func (f *fakeGateways) setErr(endpoint string, err error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.errs[endpoint] = err
+}
+
+func (f *fakeGateways) reset() {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.received = map[string][]string{}
+	f.requests = map[string]int{}
+}
+
+func (f *fakeGateways) newMember(
+	_ context.Context, _ component.ExporterCreateSettings, _ *Config, endpoint string, _ int,
+) (*member, error) {
+	return &member{exporter: nopExporter{}, endpoint: endpoint, send: func(_ context.Context, data any) error {
+		var hosts []string
+		switch data := data.(type) {
+		case pmetric.Metrics:
+			for i := 0; i < data.ResourceMetrics().Len(); i++ {
+				host, _ := data.ResourceMetrics().At(i).Resource().Attributes().Get("host.name")
+				hosts = append(hosts, host.StringVal())
+			}
+		case plog.Logs:
+			for i := 0; i < data.ResourceLogs().Len(); i++ {
+				host, _ := data.ResourceLogs().At(i).Resource().Attributes().Get("host.name")
+				hosts = append(hosts, host.StringVal())
+			}
+		}
+		f.lock.Lock()
+		defer f.lock.Unlock()
+		f.requests[endpoint]++
+		if err := f.errs[endpoint]; err != nil {
+			return err
+		}
+		f.received[endpoint] = append(f.received[endpoint], hosts...)
+		return nil
+	}}, nil
+}
+
+type nopExporter struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// gatewayOf returns the gateway each host was received by, failing if it was received by more than one.
+func (f *fakeGateways) gatewayOf(t *testing.T) map[string]string {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	gateways := map[string]string{}
+	for endpoint, hosts := range f.received {
+		for _, host := range hosts {
+			if previous, ok := gateways[host]; ok {
+				t.Errorf("host %s received by both %s and %s", host, previous, endpoint)
+			}
+			gateways[host] = endpoint
+		}
+	}
+	return gateways
+}
+
+func newTestExporter(t *testing.T, gateways *fakeGateways, modify func(cfg *Config)) *loadBalancingExporter {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Gateways = []string{"http://gateway-0:9943", "http://gateway-1:9943", "http://gateway-2:9943"}
+	if modify != nil {
+		modify(cfg)
+	}
+	require.NoError(t, cfg.Validate())
+	exp, err := newLoadBalancingExporter(context.Background(), cfg, componenttest.NewNopExporterCreateSettings(), gateways.newMember)
+	require.NoError(t, err)
+	require.NoError(t, exp.start(context.Background(), componenttest.NewNopHost()))
+	t.Cleanup(func() { require.NoError(t, exp.shutdown(context.Background())) })
+	return exp
+}
+
+func newTestMetrics(hosts int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	for i := 0; i < hosts; i++ {
+		rm := md.ResourceMetrics().AppendEmpty()
+		rm.Resource().Attributes().InsertString("host.name", fmt.Sprintf("host-%d", i))
+		rm.Resource().Attributes().InsertString("os.type", "linux")
+		metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+		metric.SetName("cpu.utilization")
+		metric.SetDataType(pmetric.MetricDataTypeGauge)
+		metric.Gauge().DataPoints().AppendEmpty().SetDoubleVal(float64(i))
+	}
+	return md
+}
+
+func TestConsistentRouting(t *testing.T) {
+	gateways := newFakeGateways()
+	exp := newTestExporter(t, gateways, nil)
+
+	require.NoError(t, exp.pushMetrics(context.Background(), newTestMetrics(300)))
+	routed := gateways.gatewayOf(t)
+	assert.Len(t, routed, 300)
+	for _, endpoint := range exp.cfg.Gateways {
+		// each gateway receives its share of the hosts, in a single request
+		assert.Greater(t, len(gateways.received[endpoint]), 50, endpoint)
+		assert.Equal(t, 1, gateways.requests[endpoint], endpoint)
+	}
+
+	// the hosts are routed to the same gateways by every exporter instance
+	other := newFakeGateways()
+	otherExp := newTestExporter(t, other, nil)
+	require.NoError(t, otherExp.pushMetrics(context.Background(), newTestMetrics(300)))
+	assert.Equal(t, routed, other.gatewayOf(t))
+
+	// and only the hosts of a removed gateway move
+	fewer := newFakeGateways()
+	fewerExp := newTestExporter(t, fewer, func(cfg *Config) { cfg.Gateways = cfg.Gateways[:2] })
+	require.NoError(t, fewerExp.pushMetrics(context.Background(), newTestMetrics(300)))
+	for host, endpoint := range fewer.gatewayOf(t) {
+		if routed[host] != "http://gateway-2:9943" {
+			assert.Equal(t, routed[host], endpoint, host)
+		}
+	}
+}
+
+func TestRoutingAttributes(t *testing.T) {
+	gateways := newFakeGateways()
+	exp := newTestExporter(t, gateways, func(cfg *Config) { cfg.RoutingAttributes = []string{"host.name"} })
+
+	md := newTestMetrics(100)
+	require.NoError(t, exp.pushMetrics(context.Background(), md))
+	routed := gateways.gatewayOf(t)
+
+	// other resource attributes don't change the gateway of a host
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		md.ResourceMetrics().At(i).Resource().Attributes().UpsertString("os.type", "windows")
+	}
+	gateways.reset()
+	require.NoError(t, exp.pushMetrics(context.Background(), md))
+	assert.Equal(t, routed, gateways.gatewayOf(t))
+}
+
+func TestFailoverAndEviction(t *testing.T) {
+	gateways := newFakeGateways()
+	now := time.Unix(1654041600, 0)
+	exp := newTestExporter(t, gateways, func(cfg *Config) {
+		cfg.Health = HealthSettings{FailureThreshold: 2, EvictionDuration: time.Minute}
+	})
+	exp.now = func() time.Time { return now }
+
+	require.NoError(t, exp.pushMetrics(context.Background(), newTestMetrics(100)))
+	routed := gateways.gatewayOf(t)
+
+	down := "http://gateway-1:9943"
+	gateways.setErr(down, errors.New("connection refused"))
+	for i := 0; i < 2; i++ {
+		gateways.reset()
+		// the resources of the failing gateway are sent to the others
+		require.NoError(t, exp.pushMetrics(context.Background(), newTestMetrics(100)))
+		assert.Equal(t, 1, gateways.requests[down])
+		failedOver := gateways.gatewayOf(t)
+		assert.Len(t, failedOver, 100)
+		for host, endpoint := range failedOver {
+			assert.NotEqual(t, down, endpoint)
+			if routed[host] != down {
+				assert.Equal(t, routed[host], endpoint, host)
+			}
+		}
+	}
+
+	// the failing gateway is evicted after failure_threshold failed requests
+	gateways.reset()
+	require.NoError(t, exp.pushMetrics(context.Background(), newTestMetrics(100)))
+	assert.Zero(t, gateways.requests[down])
+	assert.Len(t, gateways.gatewayOf(t), 100)
+
+	// and sent requests again once its eviction has expired
+	now = now.Add(time.Minute)
+	gateways.setErr(down, nil)
+	gateways.reset()
+	require.NoError(t, exp.pushMetrics(context.Background(), newTestMetrics(100)))
+	assert.Equal(t, 1, gateways.requests[down])
+	assert.Equal(t, routed, gateways.gatewayOf(t))
+}
+
+func TestAllGatewaysFailing(t *testing.T) {
+	gateways := newFakeGateways()
+	exp := newTestExporter(t, gateways, nil)
+	for _, endpoint := range exp.cfg.Gateways {
+		gateways.setErr(endpoint, errors.New("connection refused"))
+	}
+
+	err := exp.pushMetrics(context.Background(), newTestMetrics(10))
+	require.Error(t, err)
+	assert.False(t, consumererror.IsPermanent(err))
+	var metricsErr consumererror.Metrics
+	require.True(t, errors.As(err, &metricsErr))
+	// every gateway was tried, and the resources are returned to be retried
+	assert.Equal(t, 10, metricsErr.GetMetrics().ResourceMetrics().Len())
+	for _, endpoint := range exp.cfg.Gateways {
+		// the resources failing over from the other gateways are sent in a request per round
+		assert.GreaterOrEqual(t, gateways.requests[endpoint], 1, endpoint)
+		assert.LessOrEqual(t, gateways.requests[endpoint], len(exp.cfg.Gateways), endpoint)
+	}
+}
+
+func TestPushLogs(t *testing.T) {
+	gateways := newFakeGateways()
+	exp := newTestExporter(t, gateways, nil)
+	require.NoError(t, exp.pushMetrics(context.Background(), newTestMetrics(30)))
+	routed := gateways.gatewayOf(t)
+
+	ld := plog.NewLogs()
+	for i := 0; i < 30; i++ {
+		rl := ld.ResourceLogs().AppendEmpty()
+		rl.Resource().Attributes().InsertString("host.name", fmt.Sprintf("host-%d", i))
+		rl.Resource().Attributes().InsertString("os.type", "linux")
+		rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("a log")
+	}
+
+	// the logs of a resource are sent to the gateway of its metrics
+	gateways.reset()
+	require.NoError(t, exp.pushLogs(context.Background(), ld))
+	assert.Equal(t, routed, gateways.gatewayOf(t))
+
+	// and to the gateway left when the others fail
+	gateways.setErr("http://gateway-0:9943", errors.New("connection refused"))
+	gateways.setErr("http://gateway-1:9943", errors.New("connection refused"))
+	gateways.reset()
+	require.NoError(t, exp.pushLogs(context.Background(), ld))
+	assert.Len(t, gateways.received["http://gateway-2:9943"], 30)
+}
+
+func TestPermanentErrorsDontEvict(t *testing.T) {
+	gateways := newFakeGateways()
+	exp := newTestExporter(t, gateways, func(cfg *Config) { cfg.Health.FailureThreshold = 1 })
+	rejecting := "http://gateway-0:9943"
+	gateways.setErr(rejecting, consumererror.NewPermanent(errors.New("bad request")))
+
+	err := exp.pushMetrics(context.Background(), newTestMetrics(100))
+	require.Error(t, err)
+	assert.True(t, consumererror.IsPermanent(err))
+	assert.Equal(t, 1, gateways.requests[rejecting])
+	for _, m := range exp.members {
+		assert.True(t, m.evictedUntil.IsZero(), m.endpoint)
+	}
+}
+
+func TestHashRingPick(t *testing.T) {
+	ring := newHashRing([]string{"a", "b", "c"})
+	assert.Len(t, ring.points, 3*ringPointsPerGateway)
+
+	counts := map[int]int{}
+	for i := uint64(0); i < 3000; i++ {
+		key := mix(i)
+		gateway := ring.pick(key, func(int) bool { return true })
+		counts[gateway]++
+		// the next gateway of a key is never the skipped one
+		assert.NotEqual(t, gateway, ring.pick(key, func(g int) bool { return g != gateway }))
+	}
+	for gateway := 0; gateway < 3; gateway++ {
+		assert.Greater(t, counts[gateway], 600, gateway)
+	}
+	assert.Equal(t, -1, ring.pick(0, func(int) bool { return false }))
+}
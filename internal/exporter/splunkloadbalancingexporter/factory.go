@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"context"
+	"time"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/signalfxexporter"
+	"github.com/open-telemetry/opentelemetry-collector-contrib/exporter/splunkhecexporter"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "splunk_loadbalancing"
+	// The types of the exporters sending to each gateway.
+	signalfxTypeStr = "signalfx"
+	hecTypeStr      = "splunk_hec"
+
+	defaultFailureThreshold = 3
+	defaultEvictionDuration = 30 * time.Second
+)
+
+var (
+	signalfxFactory = signalfxexporter.NewFactory()
+	hecFactory      = splunkhecexporter.NewFactory()
+)
+
+// NewFactory creates a factory for the Splunk load balancing exporter.
+func NewFactory() component.ExporterFactory {
+	return component.NewExporterFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsExporter(createMetricsExporter),
+		component.WithLogsExporter(createLogsExporter),
+	)
+}
+
+func createDefaultConfig() config.Exporter {
+	return &Config{
+		ExporterSettings: config.NewExporterSettings(config.NewComponentID(typeStr)),
+		TimeoutSettings:  exporterhelper.NewDefaultTimeoutSettings(),
+		QueueSettings:    exporterhelper.NewDefaultQueueSettings(),
+		RetrySettings:    exporterhelper.NewDefaultRetrySettings(),
+		Exporter:         signalfxTypeStr,
+		SignalFx:         *signalfxFactory.CreateDefaultConfig().(*signalfxexporter.Config),
+		HEC:              *hecFactory.CreateDefaultConfig().(*splunkhecexporter.Config),
+		Health: HealthSettings{
+			FailureThreshold: defaultFailureThreshold,
+			EvictionDuration: defaultEvictionDuration,
+		},
+	}
+}
+
+func createMetricsExporter(
+	ctx context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.MetricsExporter, error) {
+	lbCfg := cfg.(*Config)
+	exp, err := newLoadBalancingExporter(ctx, lbCfg, settings, newMetricsMember)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewMetricsExporter(
+		cfg,
+		settings,
+		exp.pushMetrics,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(lbCfg.TimeoutSettings),
+		exporterhelper.WithRetry(lbCfg.RetrySettings),
+		exporterhelper.WithQueue(lbCfg.QueueSettings),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
+
+func createLogsExporter(
+	ctx context.Context,
+	settings component.ExporterCreateSettings,
+	cfg config.Exporter,
+) (component.LogsExporter, error) {
+	lbCfg := cfg.(*Config)
+	exp, err := newLoadBalancingExporter(ctx, lbCfg, settings, newLogsMember)
+	if err != nil {
+		return nil, err
+	}
+	return exporterhelper.NewLogsExporter(
+		cfg,
+		settings,
+		exp.pushLogs,
+		exporterhelper.WithCapabilities(consumer.Capabilities{MutatesData: false}),
+		exporterhelper.WithTimeout(lbCfg.TimeoutSettings),
+		exporterhelper.WithRetry(lbCfg.RetrySettings),
+		exporterhelper.WithQueue(lbCfg.QueueSettings),
+		exporterhelper.WithStart(exp.start),
+		exporterhelper.WithShutdown(exp.shutdown),
+	)
+}
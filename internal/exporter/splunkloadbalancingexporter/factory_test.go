@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig()
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestCreateExporters(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.Gateways = []string{"http://localhost:9943", "http://localhost:9944"}
+	cfg.SignalFx.AccessToken = "some-token"
+	cfg.SignalFx.APIURL = "http://localhost:6060"
+	ctx := context.Background()
+	settings := componenttest.NewNopExporterCreateSettings()
+	host := componenttest.NewNopHost()
+
+	me, err := factory.CreateMetricsExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, me.Start(ctx, host))
+	require.NoError(t, me.Shutdown(ctx))
+
+	cfg.Exporter = hecTypeStr
+	cfg.Gateways = []string{"http://localhost:8088/services/collector", "http://localhost:8089/services/collector"}
+	cfg.HEC.Token = "some-token"
+	le, err := factory.CreateLogsExporter(ctx, settings, cfg)
+	require.NoError(t, err)
+	require.NoError(t, le.Start(ctx, host))
+	require.NoError(t, le.Shutdown(ctx))
+
+	// the settings of the exporters sending to the gateways are validated by creating them
+	cfg.HEC.Token = ""
+	_, err = factory.CreateLogsExporter(ctx, settings, cfg)
+	assert.ErrorContains(t, err, `failed creating splunk_hec exporter for gateway "http://localhost:8088/services/collector"`)
+}
@@ -0,0 +1,102 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkloadbalancingexporter
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"hash/fnv"
+	"sort"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+// The number of points of each gateway on the ring, so that resources are evenly spread across them.
+const ringPointsPerGateway = 100
+
+// hashRing consistently hashes resources to gateways, so that removing or evicting a gateway only
+// moves its own resources, to the next gateways of the ring.
+type hashRing struct {
+	points []ringPoint
+}
+
+type ringPoint struct {
+	hash    uint64
+	gateway int
+}
+
+func newHashRing(gateways []string) *hashRing {
+	ring := &hashRing{points: make([]ringPoint, 0, len(gateways)*ringPointsPerGateway)}
+	for gateway, endpoint := range gateways {
+		for i := 0; i < ringPointsPerGateway; i++ {
+			sum := sha256.Sum256([]byte(endpoint + "#" + strconv.Itoa(i)))
+			ring.points = append(ring.points, ringPoint{hash: binary.BigEndian.Uint64(sum[:8]), gateway: gateway})
+		}
+	}
+	sort.Slice(ring.points, func(i, j int) bool {
+		return ring.points[i].hash < ring.points[j].hash
+	})
+	return ring
+}
+
+// pick returns the first gateway accepted by the accept func from the point of the key onwards, -1 if none is.
+func (r *hashRing) pick(key uint64, accept func(gateway int) bool) int {
+	start := sort.Search(len(r.points), func(i int) bool { return r.points[i].hash >= key })
+	for i := 0; i < len(r.points); i++ {
+		point := r.points[(start+i)%len(r.points)]
+		if accept(point.gateway) {
+			return point.gateway
+		}
+	}
+	return -1
+}
+
+// resourceKey returns the ring key of the resource, identified by its routing attributes, or all its attributes
+// if there are none.
+func resourceKey(resource pcommon.Resource, routingAttributes []string) uint64 {
+	attrs := resource.Attributes()
+	keys := routingAttributes
+	if len(keys) == 0 {
+		keys = make([]string, 0, attrs.Len())
+		attrs.Range(func(k string, _ pcommon.Value) bool {
+			keys = append(keys, k)
+			return true
+		})
+		sort.Strings(keys)
+	}
+
+	h := fnv.New64a()
+	for _, k := range keys {
+		if v, ok := attrs.Get(k); ok {
+			_, _ = h.Write([]byte(k))
+			_, _ = h.Write([]byte{0})
+			_, _ = h.Write([]byte(v.AsString()))
+			_, _ = h.Write([]byte{0})
+		}
+	}
+	return mix(h.Sum64())
+}
+
+// mix spreads the bits of the fnv hash, whose high bits barely change with the last bytes, over the ring.
+func mix(h uint64) uint64 {
+	// the splitmix64 finalizer
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return h
+}
@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+// The capabilities needed to listen on privileged ports, to read any file, and to send ICMP echo requests
+// with raw sockets.
+const (
+	capabilityNetBindService = "CAP_NET_BIND_SERVICE"
+	capabilityDACReadSearch  = "CAP_DAC_READ_SEARCH"
+	capabilityNetRaw         = "CAP_NET_RAW"
+)
+
+// capabilityBits are the bits of the Linux capabilities in the capability sets of /proc/<pid>/status.
+var capabilityBits = map[string]uint{
+	"CAP_CHOWN":            0,
+	"CAP_DAC_OVERRIDE":     1,
+	"CAP_DAC_READ_SEARCH":  2,
+	"CAP_FOWNER":           3,
+	"CAP_KILL":             5,
+	"CAP_SETGID":           6,
+	"CAP_SETUID":           7,
+	"CAP_NET_BIND_SERVICE": 10,
+	"CAP_NET_BROADCAST":    11,
+	"CAP_NET_ADMIN":        12,
+	"CAP_NET_RAW":          13,
+	"CAP_IPC_LOCK":         14,
+	"CAP_SYS_CHROOT":       18,
+	"CAP_SYS_PTRACE":       19,
+	"CAP_SYS_ADMIN":        21,
+	"CAP_SYS_RESOURCE":     24,
+	"CAP_SYSLOG":           34,
+	"CAP_AUDIT_READ":       37,
+	"CAP_PERFMON":          38,
+	"CAP_BPF":              39,
+}
+
+// privileges are those of the collector process.
+type privileges interface {
+	// hasCapability returns whether the capability is in the effective set of the process.
+	hasCapability(capability string) bool
+	// unprivilegedPortStart returns the lowest port that can be listened on without CAP_NET_BIND_SERVICE.
+	unprivilegedPortStart() int
+}
@@ -0,0 +1,73 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the privilege check extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Components are the privilege requirements of the configured components by component id.
+	Components map[string]Requirements `mapstructure:"components"`
+	// DetectComponents is whether the requirements of the components used by the service's pipelines,
+	// like the files of filelog receivers, are added to Components by the collector before it starts.
+	DetectComponents bool `mapstructure:"detect_components"`
+}
+
+// Requirements are the privileges a component needs.
+type Requirements struct {
+	// Paths are the file globs the component reads.
+	Paths []string `mapstructure:"paths"`
+	// Ports are the ports the component listens on.
+	Ports []int `mapstructure:"ports"`
+	// Capabilities are the Linux capabilities the component needs, like CAP_NET_RAW.
+	Capabilities []string `mapstructure:"capabilities"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	ids := make([]string, 0, len(cfg.Components))
+	for id := range cfg.Components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		requirements := cfg.Components[id]
+		for _, path := range requirements.Paths {
+			if path == "" {
+				return fmt.Errorf("components::%s: paths cannot be empty", id)
+			}
+		}
+		for _, port := range requirements.Ports {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("components::%s: invalid port %d", id, port)
+			}
+		}
+		for _, capability := range requirements.Capabilities {
+			if _, ok := capabilityBits[capability]; !ok {
+				return fmt.Errorf("components::%s: unknown capability %q", id, capability)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), e0)
+
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		Components: map[string]Requirements{
+			"filelog":         {Paths: []string{"/var/log/secure", "/var/log/audit/*.log"}},
+			"syslog":          {Ports: []int{514}},
+			"smartagent/ping": {Capabilities: []string{"CAP_NET_RAW"}},
+		},
+	}, e1)
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	factories.Extensions[typeStr] = NewFactory()
+
+	_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "invalid_capability.yaml"), factories)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `components::smartagent/ping: unknown capability "NET_RAW"`)
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		requirements Requirements
+		err          string
+	}{
+		{requirements: Requirements{Paths: []string{""}}, err: "components::filelog: paths cannot be empty"},
+		{requirements: Requirements{Ports: []int{0}}, err: "components::filelog: invalid port 0"},
+		{requirements: Requirements{Ports: []int{65536}}, err: "components::filelog: invalid port 65536"},
+		{requirements: Requirements{Capabilities: []string{"cap_net_raw"}}, err: `components::filelog: unknown capability "cap_net_raw"`},
+	} {
+		t.Run(test.err, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Components = map[string]Requirements{"filelog": {Paths: []string{"/var/log/*.log"}, Ports: []int{80}, Capabilities: []string{"CAP_NET_RAW"}}}
+			require.NoError(t, cfg.Validate())
+			cfg.Components["filelog"] = test.requirements
+			require.EqualError(t, cfg.Validate(), test.err)
+		})
+	}
+}
@@ -0,0 +1,189 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/confmap"
+)
+
+// listenerReceivers are the receiver types whose endpoint or listen_address settings, wherever nested in
+// their config, are addresses they listen on.
+var listenerReceivers = map[string]bool{
+	"carbon":        true,
+	"collectd":      true,
+	"fluentforward": true,
+	"jaeger":        true,
+	"nagios":        true,
+	"otlp":          true,
+	"sapm":          true,
+	"signalfx":      true,
+	"snmp_trap":     true,
+	"splunk_hec":    true,
+	"statsd":        true,
+	"syslog":        true,
+	"tcplog":        true,
+	"udplog":        true,
+	"zipkin":        true,
+}
+
+// icmpPlugins are the Nagios plugins sending ICMP echo requests.
+var icmpPlugins = map[string]bool{
+	"check_fping": true,
+	"check_icmp":  true,
+	"check_ping":  true,
+}
+
+// detected are the requirements of the receivers used by the service's pipelines of the last loaded config.
+var detected struct {
+	requirements map[string]Requirements
+	sync.Mutex
+}
+
+// RequirementsDetector is a MapConverter that records the privilege requirements of the receivers used by
+// the service's pipelines for the privilege_check extensions whose detect_components setting isn't disabled:
+// the files read by filelog and journald receivers, the ports of listening receivers, the capabilities of
+// the hostmetrics process scraper, and CAP_NET_RAW for the ICMP checks of nagios receivers.  It doesn't
+// change the config, so it's applied even when the config isn't converted.
+type RequirementsDetector struct{}
+
+func (RequirementsDetector) Convert(_ context.Context, cfgMap *confmap.Conf) error {
+	if cfgMap == nil {
+		return fmt.Errorf("cannot RequirementsDetector on nil *confmap.Conf")
+	}
+	requirements := map[string]Requirements{}
+	pipelines, _ := cfgMap.Get("service::pipelines").(map[string]any)
+	for _, pipeline := range sortedKeys(pipelines) {
+		for _, receiver := range serviceReferences(cfgMap, fmt.Sprintf("service::pipelines::%s::receivers", pipeline)) {
+			if _, ok := requirements[receiver]; ok {
+				continue
+			}
+			receiverCfg, _ := cfgMap.Get("receivers::" + receiver).(map[string]any)
+			if r := receiverRequirements(receiver, receiverCfg); !r.empty() {
+				requirements[receiver] = r
+			}
+		}
+	}
+
+	detected.Lock()
+	defer detected.Unlock()
+	detected.requirements = requirements
+	return nil
+}
+
+// detectedRequirements returns the requirements recorded by the RequirementsDetector.
+func detectedRequirements() map[string]Requirements {
+	detected.Lock()
+	defer detected.Unlock()
+	return detected.requirements
+}
+
+func (r Requirements) empty() bool {
+	return len(r.Paths) == 0 && len(r.Ports) == 0 && len(r.Capabilities) == 0
+}
+
+// receiverRequirements returns the privilege requirements of the receiver's config.
+func receiverRequirements(receiver string, cfg map[string]any) Requirements {
+	typ, _, _ := strings.Cut(receiver, "/")
+	typ = strings.TrimSpace(typ)
+	var requirements Requirements
+	switch typ {
+	case "filelog":
+		include, _ := cfg["include"].([]any)
+		for _, path := range include {
+			requirements.Paths = append(requirements.Paths, fmt.Sprint(path))
+		}
+	case "journald":
+		directory := "/var/log/journal"
+		if d, ok := cfg["directory"].(string); ok && d != "" {
+			directory = d
+		}
+		requirements.Paths = []string{strings.TrimSuffix(directory, "/") + "/*/*.journal"}
+	case "hostmetrics":
+		scrapers, _ := cfg["scrapers"].(map[string]any)
+		if _, ok := scrapers["process"]; ok {
+			// reading the executables and io of other users' processes
+			requirements.Capabilities = []string{"CAP_SYS_PTRACE", capabilityDACReadSearch}
+		}
+	case "nagios":
+		checks, _ := cfg["checks"].([]any)
+		for _, check := range checks {
+			checkCfg, _ := check.(map[string]any)
+			if command, ok := checkCfg["command"].(string); ok && icmpPlugins[filepath.Base(command)] {
+				requirements.Capabilities = []string{capabilityNetRaw}
+				break
+			}
+		}
+	case "snmp_trap":
+		if _, ok := cfg["endpoint"]; !ok {
+			// the default endpoint is the privileged SNMP trap port
+			requirements.Ports = []int{162}
+		}
+	}
+	if listenerReceivers[typ] {
+		if ports := listenPorts(cfg); len(ports) != 0 {
+			requirements.Ports = ports
+		}
+	}
+	return requirements
+}
+
+// listenPorts returns the ports of the endpoint and listen_address settings of the config.
+func listenPorts(cfg map[string]any) []int {
+	var ports []int
+	for _, key := range sortedKeys(cfg) {
+		switch value := cfg[key].(type) {
+		case map[string]any:
+			ports = append(ports, listenPorts(value)...)
+		case string:
+			if key != "endpoint" && key != "listen_address" {
+				continue
+			}
+			if _, p, err := net.SplitHostPort(value); err == nil {
+				if port, err := strconv.Atoi(p); err == nil && port > 0 && port <= 65535 {
+					ports = append(ports, port)
+				}
+			}
+		}
+	}
+	return ports
+}
+
+// serviceReferences returns the component ids referenced by the service list at the key.
+func serviceReferences(cfgMap *confmap.Conf, key string) []string {
+	refs, _ := cfgMap.Get(key).([]any)
+	ids := make([]string, 0, len(refs))
+	for _, ref := range refs {
+		ids = append(ids, fmt.Sprint(ref))
+	}
+	return ids
+}
+
+func sortedKeys(m map[string]any) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
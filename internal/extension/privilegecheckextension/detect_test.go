@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/confmaptest"
+)
+
+func TestRequirementsDetector(t *testing.T) {
+	cfgMap, err := confmaptest.LoadConf("testdata/detect.yaml")
+	require.NoError(t, err)
+	expected := cfgMap.ToStringMap()
+
+	require.NoError(t, RequirementsDetector{}.Convert(context.Background(), cfgMap))
+	assert.Equal(t, map[string]Requirements{
+		"filelog": {
+			Paths: []string{"/var/log/secure", "/var/log/audit/**/*.log"},
+		},
+		"filelog/custom": {
+			Paths: []string{"/var/log/custom/*.log"},
+		},
+		"journald": {
+			Paths: []string{"/var/log/journal/*/*.journal"},
+		},
+		"hostmetrics": {
+			Capabilities: []string{"CAP_SYS_PTRACE", "CAP_DAC_READ_SEARCH"},
+		},
+		"nagios": {
+			Capabilities: []string{"CAP_NET_RAW"},
+		},
+		"otlp": {
+			Ports: []int{443},
+		},
+		"syslog": {
+			Ports: []int{514},
+		},
+		"snmp_trap": {
+			Ports: []int{162},
+		},
+	}, detectedRequirements())
+	assert.Equal(t, expected, cfgMap.ToStringMap())
+
+	require.NoError(t, RequirementsDetector{}.Convert(context.Background(), confmap.New()))
+	assert.Empty(t, detectedRequirements())
+}
@@ -0,0 +1,196 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/component"
+	"go.uber.org/zap"
+)
+
+// maxCheckedFiles limits the files checked for each path glob, which can match entire log directories.
+const maxCheckedFiles = 100
+
+// missingPrivilege is a privilege a component needs that the collector doesn't have.
+type missingPrivilege struct {
+	component string
+	privilege string
+	reason    string
+	remedy    string
+}
+
+type privilegeCheckExtension struct {
+	logger     *zap.Logger
+	cfg        *Config
+	privileges func() (privileges, error)
+	canRead    func(path string) error
+	detected   func() map[string]Requirements
+}
+
+var _ component.Extension = (*privilegeCheckExtension)(nil)
+
+func newPrivilegeCheckExtension(cfg *Config, logger *zap.Logger) *privilegeCheckExtension {
+	return &privilegeCheckExtension{
+		logger:     logger,
+		cfg:        cfg,
+		privileges: currentPrivileges,
+		canRead:    canRead,
+		detected:   detectedRequirements,
+	}
+}
+
+// Start warns about every missing privilege of the configured components. It never fails, since the
+// components may still work partially, or the requirements may be satisfied by other means, like ACLs.
+func (e *privilegeCheckExtension) Start(context.Context, component.Host) error {
+	privileges, err := e.privileges()
+	if err != nil {
+		e.logger.Info("Skipping privilege checks", zap.Error(err))
+		return nil
+	}
+	components := e.components()
+	missing := e.check(components, privileges)
+	for _, m := range missing {
+		e.logger.Warn(
+			fmt.Sprintf("Component %s is missing privilege %s", m.component, m.privilege),
+			zap.String("component", m.component),
+			zap.String("privilege", m.privilege),
+			zap.String("reason", m.reason),
+			zap.String("remedy", m.remedy),
+		)
+	}
+	if len(missing) == 0 {
+		e.logger.Debug("All privilege requirements of the configured components are met", zap.Int("components", len(components)))
+	}
+	return nil
+}
+
+func (e *privilegeCheckExtension) Shutdown(context.Context) error {
+	return nil
+}
+
+// components returns the requirements of the configured components and, with detect_components, the
+// detected requirements of the receivers that aren't configured.
+func (e *privilegeCheckExtension) components() map[string]Requirements {
+	components := make(map[string]Requirements, len(e.cfg.Components))
+	for id, requirements := range e.cfg.Components {
+		components[id] = requirements
+	}
+	if !e.cfg.DetectComponents {
+		return components
+	}
+	for id, requirements := range e.detected() {
+		if _, ok := components[id]; !ok {
+			components[id] = requirements
+		}
+	}
+	return components
+}
+
+// check returns the missing privileges of the components, in component id order.
+func (e *privilegeCheckExtension) check(components map[string]Requirements, privileges privileges) []missingPrivilege {
+	ids := make([]string, 0, len(components))
+	for id := range components {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	var missing []missingPrivilege
+	for _, id := range ids {
+		requirements := components[id]
+		for _, capability := range requirements.Capabilities {
+			if !privileges.hasCapability(capability) {
+				missing = append(missing, missingPrivilege{
+					component: id,
+					privilege: capability,
+					reason:    "the capability is required by the component's configuration",
+					remedy:    capabilityRemedy(capability),
+				})
+			}
+		}
+		for _, port := range requirements.Ports {
+			if port < privileges.unprivilegedPortStart() && !privileges.hasCapability(capabilityNetBindService) {
+				missing = append(missing, missingPrivilege{
+					component: id,
+					privilege: capabilityNetBindService,
+					reason:    fmt.Sprintf("the component listens on privileged port %d", port),
+					remedy:    capabilityRemedy(capabilityNetBindService) + ", or configure a port of at least " + fmt.Sprint(privileges.unprivilegedPortStart()),
+				})
+			}
+		}
+		for _, path := range requirements.Paths {
+			if m, ok := e.checkPath(id, path, privileges); ok {
+				missing = append(missing, m)
+			}
+		}
+	}
+	return missing
+}
+
+// checkPath returns the missing privilege of the component if any of the files matching the path glob
+// can't be read.
+func (e *privilegeCheckExtension) checkPath(id, path string, privileges privileges) (missingPrivilege, bool) {
+	matches, err := glob(path, maxCheckedFiles)
+	if err != nil {
+		e.logger.Debug("Skipping privilege check of invalid path", zap.String("component", id), zap.String("path", path), zap.Error(err))
+		return missingPrivilege{}, false
+	}
+	var unreadable []string
+	for _, match := range matches {
+		if err := e.canRead(match); errors.Is(err, fs.ErrPermission) {
+			unreadable = append(unreadable, match)
+		}
+	}
+	if len(unreadable) == 0 {
+		return missingPrivilege{}, false
+	}
+
+	reason := fmt.Sprintf("the component can't read %s", unreadable[0])
+	if len(unreadable) > 1 {
+		reason = fmt.Sprintf("the component can't read %d of the %d checked files matching %s, like %s", len(unreadable), len(matches), path, unreadable[0])
+	}
+	remedy := capabilityRemedy(capabilityDACReadSearch) + ", or give the collector's user read access to the files, e.g. with their group"
+	if privileges.hasCapability(capabilityDACReadSearch) {
+		// the capability doesn't bypass mandatory access control like SELinux or AppArmor
+		remedy = "allow the collector to read the files in the SELinux or AppArmor policy"
+	}
+	return missingPrivilege{
+		component: id,
+		privilege: capabilityDACReadSearch,
+		reason:    reason,
+		remedy:    remedy,
+	}, true
+}
+
+func capabilityRemedy(capability string) string {
+	return fmt.Sprintf(
+		"grant %s to the collector, e.g. with `setcap %s=+eip <collector binary>` or the AmbientCapabilities setting of its systemd service",
+		capability, strings.ToLower(capability),
+	)
+}
+
+func canRead(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
@@ -0,0 +1,191 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type fakePrivileges struct {
+	capabilities map[string]bool
+	portStart    int
+}
+
+func (p fakePrivileges) hasCapability(capability string) bool {
+	return p.capabilities[capability]
+}
+
+func (p fakePrivileges) unprivilegedPortStart() int {
+	return p.portStart
+}
+
+func newTestExtension(components map[string]Requirements, privs fakePrivileges, unreadable ...string) (*privilegeCheckExtension, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Components = components
+	ext := newPrivilegeCheckExtension(cfg, zap.New(core))
+	ext.privileges = func() (privileges, error) { return privs, nil }
+	ext.detected = func() map[string]Requirements { return nil }
+	ext.canRead = func(path string) error {
+		for _, u := range unreadable {
+			if path == u {
+				return &fs.PathError{Op: "open", Path: path, Err: fs.ErrPermission}
+			}
+		}
+		return nil
+	}
+	return ext, logs
+}
+
+func writeFiles(t *testing.T, dir string, names ...string) []string {
+	var paths []string
+	for _, name := range names {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, nil, 0600))
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func TestStartWarnsAboutMissingPrivileges(t *testing.T) {
+	dir := t.TempDir()
+	files := writeFiles(t, dir, "a.log", "b.log", "c.log")
+	ext, logs := newTestExtension(map[string]Requirements{
+		"filelog":         {Paths: []string{filepath.Join(dir, "*.log")}},
+		"syslog":          {Ports: []int{514, 5514}},
+		"smartagent/ping": {Capabilities: []string{"CAP_NET_RAW", "CAP_SYS_PTRACE"}},
+	}, fakePrivileges{capabilities: map[string]bool{"CAP_SYS_PTRACE": true}, portStart: 1024}, files[0], files[2])
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	warnings := logs.FilterLevelExact(zapcore.WarnLevel).All()
+	require.Len(t, warnings, 3)
+
+	assert.Equal(t, "Component filelog is missing privilege CAP_DAC_READ_SEARCH", warnings[0].Message)
+	fields := warnings[0].ContextMap()
+	assert.Equal(t, "filelog", fields["component"])
+	assert.Equal(t, "CAP_DAC_READ_SEARCH", fields["privilege"])
+	assert.Equal(t, fmt.Sprintf("the component can't read 2 of the 3 checked files matching %s, like %s", filepath.Join(dir, "*.log"), files[0]), fields["reason"])
+	assert.Contains(t, fields["remedy"], "setcap cap_dac_read_search=+eip")
+
+	assert.Equal(t, "Component smartagent/ping is missing privilege CAP_NET_RAW", warnings[1].Message)
+	assert.Equal(t, "the capability is required by the component's configuration", warnings[1].ContextMap()["reason"])
+
+	assert.Equal(t, "Component syslog is missing privilege CAP_NET_BIND_SERVICE", warnings[2].Message)
+	fields = warnings[2].ContextMap()
+	assert.Equal(t, "the component listens on privileged port 514", fields["reason"])
+	assert.Contains(t, fields["remedy"], "or configure a port of at least 1024")
+}
+
+func TestCheckMetRequirements(t *testing.T) {
+	dir := t.TempDir()
+	files := writeFiles(t, dir, "a.log")
+	ext, logs := newTestExtension(map[string]Requirements{
+		"filelog":         {Paths: []string{files[0], filepath.Join(dir, "missing", "*.log")}},
+		"syslog":          {Ports: []int{514}},
+		"smartagent/ping": {Capabilities: []string{"CAP_NET_RAW"}},
+	}, fakePrivileges{capabilities: map[string]bool{"CAP_NET_RAW": true, "CAP_NET_BIND_SERVICE": true}, portStart: 1024})
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, 0, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+	assert.Equal(t, 1, logs.FilterMessage("All privilege requirements of the configured components are met").Len())
+
+	// lowered unprivileged port start
+	ext, _ = newTestExtension(map[string]Requirements{"syslog": {Ports: []int{514}}}, fakePrivileges{portStart: 0})
+	assert.Empty(t, ext.check(ext.components(), fakePrivileges{portStart: 0}))
+}
+
+func TestCheckPathWithReadSearchCapability(t *testing.T) {
+	dir := t.TempDir()
+	files := writeFiles(t, dir, "secure")
+	privileges := fakePrivileges{capabilities: map[string]bool{"CAP_DAC_READ_SEARCH": true}}
+	ext, _ := newTestExtension(map[string]Requirements{"filelog": {Paths: []string{files[0]}}}, privileges, files[0])
+
+	missing := ext.check(ext.components(), privileges)
+	require.Len(t, missing, 1)
+	assert.Equal(t, "the component can't read "+files[0], missing[0].reason)
+	assert.Contains(t, missing[0].remedy, "SELinux or AppArmor")
+}
+
+func TestCheckLimitsCheckedFiles(t *testing.T) {
+	dir := t.TempDir()
+	var names []string
+	for i := 0; i < maxCheckedFiles+10; i++ {
+		names = append(names, fmt.Sprintf("%03d.log", i))
+	}
+	files := writeFiles(t, dir, names...)
+	ext, _ := newTestExtension(map[string]Requirements{"filelog": {Paths: []string{filepath.Join(dir, "*.log")}}}, fakePrivileges{}, files...)
+
+	missing := ext.check(ext.components(), fakePrivileges{})
+	require.Len(t, missing, 1)
+	assert.Contains(t, missing[0].reason, fmt.Sprintf("can't read %d of the %d checked files", maxCheckedFiles, maxCheckedFiles))
+}
+
+func TestStartWithoutPrivileges(t *testing.T) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Components = map[string]Requirements{"smartagent/ping": {Capabilities: []string{"CAP_NET_RAW"}}}
+	ext := newPrivilegeCheckExtension(cfg, zap.New(core))
+	ext.privileges = func() (privileges, error) { return nil, errors.New("unsupported") }
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, 1, logs.FilterMessage("Skipping privilege checks").Len())
+	assert.Equal(t, 0, logs.FilterLevelExact(zapcore.WarnLevel).Len())
+}
+
+func TestCheckRecursivePaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "nested", "deeper"), 0700))
+	files := writeFiles(t, dir, "top.log", filepath.Join("nested", "a.log"), filepath.Join("nested", "deeper", "b.log"))
+	ext, _ := newTestExtension(map[string]Requirements{
+		"filelog": {Paths: []string{filepath.Join(dir, "**", "*.log")}},
+	}, fakePrivileges{}, files[2])
+
+	missing := ext.check(ext.components(), fakePrivileges{})
+	require.Len(t, missing, 1)
+	assert.Equal(t, "the component can't read "+files[2], missing[0].reason)
+}
+
+func TestStartChecksDetectedRequirements(t *testing.T) {
+	ext, logs := newTestExtension(map[string]Requirements{
+		"syslog": {Ports: []int{5514}},
+	}, fakePrivileges{portStart: 1024})
+	ext.detected = func() map[string]Requirements {
+		return map[string]Requirements{
+			"syslog": {Ports: []int{514}},
+			"nagios": {Capabilities: []string{"CAP_NET_RAW"}},
+		}
+	}
+
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	warnings := logs.FilterLevelExact(zapcore.WarnLevel).All()
+	require.Len(t, warnings, 1)
+	assert.Equal(t, "Component nagios is missing privilege CAP_NET_RAW", warnings[0].Message)
+
+	ext.cfg.DetectComponents = false
+	assert.Empty(t, ext.check(ext.components(), fakePrivileges{portStart: 1024}))
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v3"
+)
+
+var errEnoughMatches = errors.New("enough matches")
+
+// glob returns up to limit files matching the pattern, with the ** recursive wildcard and the other syntax of
+// the filelog receiver's include patterns, by walking the directory before the pattern's first wildcard.
+// Files of directories that can't be read can't be matched, so such directories are returned instead.
+func glob(pattern string, limit int) ([]string, error) {
+	// doublestar only reports malformed patterns when it matches them, while filepath.Match checks all of it
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, err
+	}
+	root := staticPrefix(pattern)
+	if root == pattern {
+		if _, err := os.Lstat(pattern); errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return []string{pattern}, nil
+	}
+
+	// without **, the pattern only matches files at the depth of its separators
+	maxDepth := -1
+	if !strings.Contains(pattern, "**") {
+		maxDepth = strings.Count(pattern, string(filepath.Separator))
+	}
+	var matches []string
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrPermission) {
+				matches = append(matches, path)
+			}
+		} else if d.IsDir() {
+			if path != root && maxDepth >= 0 && strings.Count(path, string(filepath.Separator)) >= maxDepth {
+				return filepath.SkipDir
+			}
+			return nil
+		} else if match, _ := doublestar.PathMatch(pattern, path); match {
+			matches = append(matches, path)
+		}
+		if len(matches) >= limit {
+			return errEnoughMatches
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errEnoughMatches) && !errors.Is(err, fs.ErrNotExist) {
+		return matches, err
+	}
+	return matches, nil
+}
+
+// staticPrefix returns the directory of the pattern before the path element of its first wildcard, or the
+// pattern itself if it has none.
+func staticPrefix(pattern string) string {
+	wildcards := `*?[{`
+	if filepath.Separator != '\\' {
+		// the escape character, unless it's the separator
+		wildcards += `\`
+	}
+	i := strings.IndexAny(pattern, wildcards)
+	if i < 0 {
+		return pattern
+	}
+	separator := strings.LastIndexByte(pattern[:i], filepath.Separator)
+	switch separator {
+	case -1:
+		return "."
+	case 0:
+		return pattern[:1]
+	}
+	return pattern[:separator]
+}
@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package privilegecheckextension
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGlob(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "a", "b"), 0700))
+	writeFiles(t, dir, "top.log", "top.txt", filepath.Join("a", "one.log"), filepath.Join("a", "b", "two.log"))
+
+	for _, tt := range []struct {
+		pattern  string
+		expected []string
+	}{
+		{pattern: "top.log", expected: []string{"top.log"}},
+		{pattern: "missing.log"},
+		{pattern: "*.log", expected: []string{"top.log"}},
+		{pattern: "*/*.log", expected: []string{"a/one.log"}},
+		{pattern: "**/*.log", expected: []string{"a/b/two.log", "a/one.log", "top.log"}},
+		{pattern: "a/**/*.log", expected: []string{"a/b/two.log", "a/one.log"}},
+		{pattern: "top.{log,txt}", expected: []string{"top.log", "top.txt"}},
+		{pattern: "missing/**/*.log"},
+	} {
+		t.Run(tt.pattern, func(t *testing.T) {
+			matches, err := glob(filepath.Join(dir, filepath.FromSlash(tt.pattern)), maxCheckedFiles)
+			require.NoError(t, err)
+			var expected []string
+			for _, path := range tt.expected {
+				expected = append(expected, filepath.Join(dir, filepath.FromSlash(path)))
+			}
+			assert.Equal(t, expected, matches)
+		})
+	}
+
+	matches, err := glob(filepath.Join(dir, "**", "*.log"), 2)
+	require.NoError(t, err)
+	assert.Len(t, matches, 2)
+
+	_, err = glob(filepath.Join(dir, "[.log"), maxCheckedFiles)
+	assert.Error(t, err)
+}
+
+func TestStaticPrefix(t *testing.T) {
+	assert.Equal(t, filepath.FromSlash("/var/log/secure"), staticPrefix(filepath.FromSlash("/var/log/secure")))
+	assert.Equal(t, filepath.FromSlash("/var/log"), staticPrefix(filepath.FromSlash("/var/log/*.log")))
+	assert.Equal(t, filepath.FromSlash("/var/log"), staticPrefix(filepath.FromSlash("/var/log/**/*.log")))
+	assert.Equal(t, filepath.FromSlash("/"), staticPrefix(filepath.FromSlash("/*.log")))
+	assert.Equal(t, filepath.FromSlash("."), staticPrefix(filepath.FromSlash("*.log")))
+	assert.Equal(t, filepath.FromSlash("logs"), staticPrefix(filepath.FromSlash("logs/app-?.log")))
+}
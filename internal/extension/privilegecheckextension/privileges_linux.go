@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package privilegecheckextension
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	procStatusPath            = "/proc/self/status"
+	unprivilegedPortStartPath = "/proc/sys/net/ipv4/ip_unprivileged_port_start"
+	defaultUnprivilegedPort   = 1024
+)
+
+type processPrivileges struct {
+	effective uint64
+	portStart int
+}
+
+// currentPrivileges returns the effective capabilities of the collector process.
+func currentPrivileges() (privileges, error) {
+	status, err := os.ReadFile(procStatusPath)
+	if err != nil {
+		return nil, err
+	}
+	effective, err := parseEffectiveCapabilities(string(status))
+	if err != nil {
+		return nil, err
+	}
+	portStart := defaultUnprivilegedPort
+	if content, err := os.ReadFile(unprivilegedPortStartPath); err == nil {
+		if port, err := strconv.Atoi(strings.TrimSpace(string(content))); err == nil {
+			portStart = port
+		}
+	}
+	return processPrivileges{effective: effective, portStart: portStart}, nil
+}
+
+// parseEffectiveCapabilities returns the CapEff bitmask of the /proc/<pid>/status content.
+func parseEffectiveCapabilities(status string) (uint64, error) {
+	scanner := bufio.NewScanner(strings.NewReader(status))
+	for scanner.Scan() {
+		if key, value, _ := strings.Cut(scanner.Text(), ":"); key == "CapEff" {
+			return strconv.ParseUint(strings.TrimSpace(value), 16, 64)
+		}
+	}
+	return 0, fmt.Errorf("no CapEff entry in %s", procStatusPath)
+}
+
+func (p processPrivileges) hasCapability(capability string) bool {
+	bit, ok := capabilityBits[capability]
+	return ok && p.effective&(1<<bit) != 0
+}
+
+func (p processPrivileges) unprivilegedPortStart() int {
+	return p.portStart
+}
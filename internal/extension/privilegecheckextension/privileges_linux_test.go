@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package privilegecheckextension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseEffectiveCapabilities(t *testing.T) {
+	status := "Name:\totelcol\nUmask:\t0022\nCapInh:\t0000000000000000\nCapPrm:\t0000000000002004\nCapEff:\t0000000000002004\nCapBnd:\t000001ffffffffff\n"
+	effective, err := parseEffectiveCapabilities(status)
+	require.NoError(t, err)
+
+	privileges := processPrivileges{effective: effective, portStart: 1024}
+	assert.True(t, privileges.hasCapability("CAP_DAC_READ_SEARCH"))
+	assert.True(t, privileges.hasCapability("CAP_NET_RAW"))
+	assert.False(t, privileges.hasCapability("CAP_NET_BIND_SERVICE"))
+	assert.False(t, privileges.hasCapability("CAP_UNKNOWN"))
+
+	_, err = parseEffectiveCapabilities("Name:\totelcol\n")
+	require.EqualError(t, err, "no CapEff entry in /proc/self/status")
+	_, err = parseEffectiveCapabilities("CapEff:\tnot hex\n")
+	require.Error(t, err)
+}
+
+func TestCurrentPrivileges(t *testing.T) {
+	privileges, err := currentPrivileges()
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, privileges.unprivilegedPortStart(), 0)
+}
@@ -0,0 +1,70 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the queue health extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Directories are the file_storage extension directories containing the
+	// persisted sending queues to monitor.
+	Directories []string `mapstructure:"directories"`
+	// CheckInterval is the interval at which the directories are checked.
+	CheckInterval time.Duration `mapstructure:"check_interval"`
+	// Storage is the file_storage extension whose clients the extension provides,
+	// as a storage extension itself, to the persistent sending queues of exporters.
+	Storage *config.ComponentID `mapstructure:"storage"`
+	// MaxDiskUsageMiB is the maximum size of the data stored through the Storage
+	// extension's clients. When reached, new persistent queue items are refused
+	// until the queues are drained. 0 disables the limit.
+	MaxDiskUsageMiB int64 `mapstructure:"max_disk_usage_mib"`
+	// MinFreeDiskMiB is the free space of a directory's filesystem below which
+	// the disk is reported as full. 0 disables the check.
+	MinFreeDiskMiB int64 `mapstructure:"min_free_disk_mib"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Directories) == 0 {
+		return errors.New("at least one directory must be provided")
+	}
+	for _, directory := range cfg.Directories {
+		if directory == "" {
+			return errors.New("directories cannot be empty")
+		}
+	}
+	if cfg.CheckInterval <= 0 {
+		return errors.New("check_interval must be positive")
+	}
+	if cfg.MaxDiskUsageMiB < 0 {
+		return errors.New("max_disk_usage_mib cannot be negative")
+	}
+	if cfg.MaxDiskUsageMiB > 0 && cfg.Storage == nil {
+		return errors.New("max_disk_usage_mib requires the storage extension to be set")
+	}
+	if cfg.MinFreeDiskMiB < 0 {
+		return errors.New("min_free_disk_mib cannot be negative")
+	}
+	return nil
+}
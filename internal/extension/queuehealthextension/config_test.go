@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.Directories = []string{"/var/lib/otelcol/file_storage"}
+	assert.Equal(t, expected, e0)
+
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "custom")]
+	storageID := config.NewComponentID("file_storage")
+	assert.Equal(t, &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		Directories:       []string{"/var/lib/otelcol/file_storage/traces", "/var/lib/otelcol/file_storage/logs"},
+		CheckInterval:     time.Minute,
+		Storage:           &storageID,
+		MaxDiskUsageMiB:   2048,
+	}, e1)
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	factories.Extensions[typeStr] = NewFactory()
+
+	_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "invalid_directories.yaml"), factories)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "at least one directory must be provided")
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		modify func(cfg *Config)
+		err    string
+	}{
+		{modify: func(cfg *Config) { cfg.Directories = []string{""} }, err: "directories cannot be empty"},
+		{modify: func(cfg *Config) { cfg.CheckInterval = 0 }, err: "check_interval must be positive"},
+		{modify: func(cfg *Config) { cfg.MaxDiskUsageMiB = -1 }, err: "max_disk_usage_mib cannot be negative"},
+		{modify: func(cfg *Config) { cfg.MaxDiskUsageMiB = 1 }, err: "max_disk_usage_mib requires the storage extension to be set"},
+		{modify: func(cfg *Config) { cfg.MinFreeDiskMiB = -1 }, err: "min_free_disk_mib cannot be negative"},
+	} {
+		t.Run(test.err, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Directories = []string{"/tmp"}
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.err)
+		})
+	}
+}
@@ -0,0 +1,164 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+const mib = 1024 * 1024
+
+type queueHealthExtension struct {
+	logger   *zap.Logger
+	cfg      *Config
+	done     chan struct{}
+	views    []*view.View
+	diskFree func(directory string) (uint64, error)
+	// the file_storage extension whose clients are provided with the max disk usage enforced
+	storage storage.Extension
+	// the files and directories currently in a reported condition, so that only changes are logged
+	corrupted map[string]bool
+	diskFull  map[string]bool
+	wg        sync.WaitGroup
+	// the size of the data stored through the clients, and whether it reached the max disk usage
+	storageLock sync.Mutex
+	storedSize  int64
+	storageFull bool
+}
+
+var _ storage.Extension = (*queueHealthExtension)(nil)
+
+func newQueueHealthExtension(cfg *Config, logger *zap.Logger) *queueHealthExtension {
+	return &queueHealthExtension{
+		logger:    logger,
+		cfg:       cfg,
+		done:      make(chan struct{}),
+		views:     metricViews(),
+		diskFree:  diskFree,
+		corrupted: map[string]bool{},
+		diskFull:  map[string]bool{},
+	}
+}
+
+func (e *queueHealthExtension) Start(_ context.Context, host component.Host) error {
+	if e.cfg.Storage != nil {
+		extension, ok := host.GetExtensions()[*e.cfg.Storage]
+		if !ok {
+			return fmt.Errorf("storage extension %q not found", e.cfg.Storage)
+		}
+		if e.storage, ok = extension.(storage.Extension); !ok {
+			return fmt.Errorf("%q is not a storage extension", e.cfg.Storage)
+		}
+	}
+	if err := view.Register(e.views...); err != nil {
+		return err
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.cfg.CheckInterval)
+		defer ticker.Stop()
+		for {
+			e.check(context.Background())
+			select {
+			case <-ticker.C:
+			case <-e.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *queueHealthExtension) Shutdown(context.Context) error {
+	select {
+	case <-e.done:
+		return nil
+	default:
+	}
+	close(e.done)
+	e.wg.Wait()
+	view.Unregister(e.views...)
+	return nil
+}
+
+// check records the state of all monitored directories and reports changes in their conditions.
+func (e *queueHealthExtension) check(ctx context.Context) {
+	var files []queueFile
+	for _, directory := range e.cfg.Directories {
+		status, err := scanDirectory(directory)
+		if err != nil {
+			e.logger.Warn("Failed checking persistent queue directory", zap.String("directory", directory), zap.Error(err))
+			continue
+		}
+		files = append(files, status.files...)
+
+		measurements := []stats.Measurement{
+			mDiskUsage.M(status.size),
+			mFiles.M(int64(len(status.files))),
+			mCorruptedFiles.M(status.corrupted),
+		}
+		if free, err := e.diskFree(directory); err != nil {
+			e.logger.Debug("Failed determining free disk space", zap.String("directory", directory), zap.Error(err))
+		} else {
+			measurements = append(measurements, mFreeDisk.M(int64(free)))
+			e.reportDiskFull(directory, free)
+		}
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(directoryKey, directory)}, measurements...)
+	}
+
+	e.reportCorrupted(files)
+}
+
+func (e *queueHealthExtension) reportCorrupted(files []queueFile) {
+	current := map[string]bool{}
+	for _, file := range files {
+		if !file.corrupted {
+			continue
+		}
+		current[file.path] = true
+		if !e.corrupted[file.path] {
+			e.logger.Warn("Persistent queue file is corrupted", zap.String("file", file.path))
+		}
+	}
+	e.corrupted = current
+}
+
+func (e *queueHealthExtension) reportDiskFull(directory string, free uint64) {
+	full := e.cfg.MinFreeDiskMiB > 0 && free < uint64(e.cfg.MinFreeDiskMiB)*mib
+	switch {
+	case full && !e.diskFull[directory]:
+		e.logger.Warn(
+			"Free disk space for persistent queue directory is below the minimum",
+			zap.String("directory", directory),
+			zap.Uint64("free_bytes", free),
+			zap.Int64("min_free_disk_mib", e.cfg.MinFreeDiskMiB),
+		)
+	case !full && e.diskFull[directory]:
+		e.logger.Info("Free disk space for persistent queue directory recovered", zap.String("directory", directory))
+	}
+	e.diskFull[directory] = full
+}
@@ -0,0 +1,65 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func newTestExtension(t *testing.T, directories ...string) (*queueHealthExtension, *observer.ObservedLogs) {
+	core, logs := observer.New(zapcore.InfoLevel)
+	cfg := createDefaultConfig().(*Config)
+	cfg.Directories = directories
+	require.NoError(t, cfg.Validate())
+	ext := newQueueHealthExtension(cfg, zap.New(core))
+	return ext, logs
+}
+
+func TestCheckReportsConditionChanges(t *testing.T) {
+	dir := t.TempDir()
+	corrupted := filepath.Join(dir, "corrupted")
+	require.NoError(t, os.WriteFile(corrupted, []byte("not a database"), 0600))
+
+	ext, logs := newTestExtension(t, dir)
+	free := uint64(50 * mib)
+	ext.diskFree = func(string) (uint64, error) { return free, nil }
+
+	ext.check(context.Background())
+	assert.Equal(t, 1, logs.FilterMessage("Persistent queue file is corrupted").Len())
+	assert.Equal(t, 1, logs.FilterMessage("Free disk space for persistent queue directory is below the minimum").Len())
+
+	// unchanged conditions aren't reported again
+	ext.check(context.Background())
+	assert.Equal(t, 1, logs.FilterMessage("Persistent queue file is corrupted").Len())
+	assert.Equal(t, 1, logs.FilterMessage("Free disk space for persistent queue directory is below the minimum").Len())
+
+	free = 500 * mib
+	require.NoError(t, os.Remove(corrupted))
+	ext.check(context.Background())
+	assert.Equal(t, 1, logs.FilterMessage("Free disk space for persistent queue directory recovered").Len())
+	assert.Empty(t, ext.corrupted)
+
+	ext.check(context.Background())
+	assert.Equal(t, 1, logs.FilterMessage("Persistent queue file is corrupted").Len())
+}
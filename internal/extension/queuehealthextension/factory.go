@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"context"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr               = "queue_health"
+	defaultCheckInterval  = 30 * time.Second
+	defaultMinFreeDiskMiB = 100
+)
+
+// NewFactory creates a factory for the queue health extension.
+func NewFactory() component.ExtensionFactory {
+	return component.NewExtensionFactory(
+		typeStr,
+		createDefaultConfig,
+		createExtension,
+	)
+}
+
+func createDefaultConfig() config.Extension {
+	return &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentID(typeStr)),
+		CheckInterval:     defaultCheckInterval,
+		MinFreeDiskMiB:    defaultMinFreeDiskMiB,
+	}
+}
+
+func createExtension(
+	_ context.Context,
+	set component.ExtensionCreateSettings,
+	cfg config.Extension,
+) (component.Extension, error) {
+	return newQueueHealthExtension(cfg.(*Config), set.Logger), nil
+}
@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	directoryKey = tag.MustNewKey("directory")
+
+	mDiskUsage = stats.Int64(
+		typeStr+"/disk_usage", "Size of the persisted queue files in the directory", stats.UnitBytes,
+	)
+	mFiles = stats.Int64(
+		typeStr+"/files", "Number of persisted queue files in the directory", stats.UnitDimensionless,
+	)
+	mCorruptedFiles = stats.Int64(
+		typeStr+"/corrupted_files", "Number of corrupted persisted queue files in the directory", stats.UnitDimensionless,
+	)
+	mFreeDisk = stats.Int64(
+		typeStr+"/free_disk", "Available space of the directory's filesystem", stats.UnitBytes,
+	)
+	mRefusedWrites = stats.Int64(
+		typeStr+"/refused_writes", "Number of persisted queue items refused to enforce the max disk usage", stats.UnitDimensionless,
+	)
+)
+
+// metricViews returns the views of the extension's metrics, which are reported by the collector's own telemetry.
+func metricViews() []*view.View {
+	tagKeys := []tag.Key{directoryKey}
+	lastValue := func(measure stats.Measure) *view.View {
+		return &view.View{
+			Name:        measure.Name(),
+			Description: measure.Description(),
+			Measure:     measure,
+			TagKeys:     tagKeys,
+			Aggregation: view.LastValue(),
+		}
+	}
+	return []*view.View{
+		lastValue(mDiskUsage),
+		lastValue(mFiles),
+		lastValue(mCorruptedFiles),
+		lastValue(mFreeDisk),
+		{
+			Name:        mRefusedWrites.Name(),
+			Description: mRefusedWrites.Description(),
+			Measure:     mRefusedWrites,
+			Aggregation: view.Sum(),
+		},
+	}
+}
@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// file_storage compaction writes to temporary files with this prefix before replacing the original.
+	compactionTempFilePrefix = "tempdb"
+
+	// bbolt meta page layout, see go.etcd.io/bbolt's page and meta types.
+	boltPageHeaderSize  = 16
+	boltMetaPageFlag    = 0x04
+	boltMagic           = 0xED0CDAED
+	boltVersion         = 2
+	boltMetaChecksumEnd = 56
+	boltMetaSize        = boltMetaChecksumEnd + 8
+)
+
+var errCorrupted = errors.New("invalid bbolt meta pages")
+
+// queueFile is a file_storage bbolt database containing a persisted sending queue.
+type queueFile struct {
+	path      string
+	directory string
+	size      int64
+	corrupted bool
+}
+
+// directoryStatus is the state of a monitored directory's persisted queue files.
+type directoryStatus struct {
+	files     []queueFile
+	size      int64
+	corrupted int64
+}
+
+// scanDirectory returns the persisted queue files in the provided directory, validating that
+// each is a bbolt database with at least one valid meta page.
+func scanDirectory(directory string) (directoryStatus, error) {
+	var status directoryStatus
+	entries, err := os.ReadDir(directory)
+	if err != nil {
+		return status, err
+	}
+	for _, entry := range entries {
+		if !entry.Type().IsRegular() || strings.HasPrefix(entry.Name(), compactionTempFilePrefix) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			if errors.Is(err, os.ErrNotExist) {
+				// removed since being listed
+				continue
+			}
+			return status, err
+		}
+		file := queueFile{
+			path:      filepath.Join(directory, entry.Name()),
+			directory: directory,
+			size:      info.Size(),
+		}
+		if err = validateBoltFile(file.path); errors.Is(err, errCorrupted) {
+			file.corrupted = true
+			status.corrupted++
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return status, err
+		}
+		status.files = append(status.files, file)
+		status.size += file.size
+	}
+	return status, nil
+}
+
+// validateBoltFile reads the bbolt meta pages of the provided file without opening the database, which
+// the file_storage extension holds an exclusive lock on while in use. Like bbolt, the database is
+// considered valid if either meta page is.
+func validateBoltFile(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.Size() == 0 {
+		// bbolt initializes empty files when opened
+		return nil
+	}
+
+	page := make([]byte, boltPageHeaderSize+boltMetaSize)
+	if _, err = f.ReadAt(page, 0); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: truncated file", errCorrupted)
+		}
+		return err
+	}
+	if err = validateBoltMetaPage(page); err == nil {
+		return nil
+	}
+
+	// like bbolt, fall back to the second meta page located using the OS page size
+	if _, err = f.ReadAt(page, int64(os.Getpagesize())); err != nil {
+		if errors.Is(err, io.EOF) {
+			return fmt.Errorf("%w: truncated file", errCorrupted)
+		}
+		return err
+	}
+	return validateBoltMetaPage(page)
+}
+
+// validateBoltMetaPage validates a meta page's flags, magic, version, and checksum.
+func validateBoltMetaPage(page []byte) error {
+	if flags := binary.LittleEndian.Uint16(page[8:10]); flags&boltMetaPageFlag == 0 {
+		return fmt.Errorf("%w: not a meta page", errCorrupted)
+	}
+	meta := page[boltPageHeaderSize:]
+	if binary.LittleEndian.Uint32(meta[0:4]) != boltMagic {
+		return fmt.Errorf("%w: invalid magic", errCorrupted)
+	}
+	if binary.LittleEndian.Uint32(meta[4:8]) != boltVersion {
+		return fmt.Errorf("%w: unsupported version", errCorrupted)
+	}
+	h := fnv.New64a()
+	_, _ = h.Write(meta[:boltMetaChecksumEnd])
+	if h.Sum64() != binary.LittleEndian.Uint64(meta[boltMetaChecksumEnd:boltMetaSize]) {
+		return fmt.Errorf("%w: checksum mismatch", errCorrupted)
+	}
+	return nil
+}
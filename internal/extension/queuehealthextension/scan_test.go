@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.etcd.io/bbolt"
+)
+
+func createBoltFile(t *testing.T, path string) {
+	db, err := bbolt.Open(path, 0600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bbolt.Tx) error {
+		bucket, err := tx.CreateBucketIfNotExists([]byte("default"))
+		if err != nil {
+			return err
+		}
+		return bucket.Put([]byte("0"), []byte("batch"))
+	}))
+	require.NoError(t, db.Close())
+}
+
+func corruptFile(t *testing.T, path string, offsets ...int64) {
+	f, err := os.OpenFile(path, os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	defer f.Close()
+	for _, offset := range offsets {
+		_, err = f.WriteAt([]byte{0xFF, 0xFF, 0xFF, 0xFF}, offset)
+		require.NoError(t, err)
+	}
+}
+
+func TestValidateBoltFile(t *testing.T) {
+	dir := t.TempDir()
+
+	valid := filepath.Join(dir, "valid")
+	createBoltFile(t, valid)
+	require.NoError(t, validateBoltFile(valid))
+
+	// a single invalid meta page is recovered from by bbolt
+	oneInvalidMeta := filepath.Join(dir, "one_invalid_meta")
+	createBoltFile(t, oneInvalidMeta)
+	corruptFile(t, oneInvalidMeta, boltPageHeaderSize)
+	require.NoError(t, validateBoltFile(oneInvalidMeta))
+
+	corrupted := filepath.Join(dir, "corrupted")
+	createBoltFile(t, corrupted)
+	corruptFile(t, corrupted, boltPageHeaderSize, int64(os.Getpagesize())+boltPageHeaderSize)
+	require.ErrorIs(t, validateBoltFile(corrupted), errCorrupted)
+
+	truncated := filepath.Join(dir, "truncated")
+	require.NoError(t, os.WriteFile(truncated, []byte("not a database"), 0600))
+	require.ErrorIs(t, validateBoltFile(truncated), errCorrupted)
+
+	empty := filepath.Join(dir, "empty")
+	require.NoError(t, os.WriteFile(empty, nil, 0600))
+	require.NoError(t, validateBoltFile(empty))
+
+	require.ErrorIs(t, validateBoltFile(filepath.Join(dir, "missing")), os.ErrNotExist)
+}
+
+func TestScanDirectory(t *testing.T) {
+	dir := t.TempDir()
+	createBoltFile(t, filepath.Join(dir, "exporter_otlp__sending_queue"))
+	createBoltFile(t, filepath.Join(dir, "exporter_splunk_hec__sending_queue"))
+	corruptFile(t, filepath.Join(dir, "exporter_splunk_hec__sending_queue"),
+		boltPageHeaderSize, int64(os.Getpagesize())+boltPageHeaderSize)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tempdb123"), []byte("compaction"), 0600))
+	require.NoError(t, os.Mkdir(filepath.Join(dir, "subdirectory"), 0700))
+
+	status, err := scanDirectory(dir)
+	require.NoError(t, err)
+	require.Len(t, status.files, 2)
+	assert.EqualValues(t, 1, status.corrupted)
+
+	var size int64
+	for _, file := range status.files {
+		assert.Equal(t, dir, file.directory)
+		assert.Equal(t, filepath.Base(file.path) == "exporter_splunk_hec__sending_queue", file.corrupted)
+		size += file.size
+	}
+	assert.Equal(t, size, status.size)
+
+	_, err = scanDirectory(filepath.Join(dir, "missing"))
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
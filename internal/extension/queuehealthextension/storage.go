@@ -0,0 +1,131 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"context"
+	"errors"
+
+	"go.opencensus.io/stats"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+var errMaxDiskUsageReached = errors.New("persistent queue max disk usage reached")
+
+// GetClient returns a client of the storage extension, whose writes of new keys, like those of enqueued items,
+// are refused while the data stored through the extension's clients exceeds the max disk usage.
+func (e *queueHealthExtension) GetClient(
+	ctx context.Context, kind component.Kind, id config.ComponentID, storageName string,
+) (storage.Client, error) {
+	if e.storage == nil {
+		return nil, errors.New("the storage option must be set to use queue_health as a storage extension")
+	}
+	client, err := e.storage.GetClient(ctx, kind, id, storageName)
+	if err != nil {
+		return nil, err
+	}
+	return &limitedClient{Client: client, ext: e, sizes: map[string]int64{}}, nil
+}
+
+// limitedClient tracks the size of the values it reads and writes, refusing writes of new keys that would
+// increase the data stored through all of the extension's clients beyond the max disk usage.  Updates of
+// known keys, like the queue's indexes, and deletions are always allowed, so that the queue can be drained.
+type limitedClient struct {
+	storage.Client
+	ext   *queueHealthExtension
+	sizes map[string]int64
+}
+
+func (c *limitedClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	if err := c.Batch(ctx, op); err != nil {
+		return nil, err
+	}
+	return op.Value, nil
+}
+
+func (c *limitedClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *limitedClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *limitedClient) Batch(ctx context.Context, ops ...storage.Operation) error {
+	e := c.ext
+	e.storageLock.Lock()
+	defer e.storageLock.Unlock()
+
+	if maxSize := e.cfg.MaxDiskUsageMiB * mib; maxSize > 0 {
+		var growth int64
+		var newKeys bool
+		for _, op := range ops {
+			if op.Type != storage.Set {
+				continue
+			}
+			size, known := c.sizes[op.Key]
+			newKeys = newKeys || !known
+			growth += int64(len(op.Value)) - size
+		}
+		if newKeys && growth > 0 && e.storedSize+growth > maxSize {
+			if !e.storageFull {
+				e.logger.Warn(
+					"Persistent queue max disk usage reached, refusing new items until it's drained",
+					zap.Int64("stored_bytes", e.storedSize),
+					zap.Int64("max_disk_usage_mib", e.cfg.MaxDiskUsageMiB),
+				)
+				e.storageFull = true
+			}
+			stats.Record(ctx, mRefusedWrites.M(1))
+			return errMaxDiskUsageReached
+		}
+	}
+
+	if err := c.Client.Batch(ctx, ops...); err != nil {
+		return err
+	}
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get, storage.Set:
+			// missing keys read as nil are tracked with a size of 0, so that they can be set afterwards
+			e.storedSize += int64(len(op.Value)) - c.sizes[op.Key]
+			c.sizes[op.Key] = int64(len(op.Value))
+		case storage.Delete:
+			e.storedSize -= c.sizes[op.Key]
+			delete(c.sizes, op.Key)
+		}
+	}
+	if e.storageFull && e.storedSize < e.cfg.MaxDiskUsageMiB*mib {
+		e.logger.Info("Persistent queue disk usage is below the maximum again")
+		e.storageFull = false
+	}
+	return nil
+}
+
+// Close closes the wrapped client, no longer counting the values it tracked, which are counted again
+// once read by a new client.
+func (c *limitedClient) Close(ctx context.Context) error {
+	c.ext.storageLock.Lock()
+	for _, size := range c.sizes {
+		c.ext.storedSize -= size
+	}
+	c.sizes = map[string]int64{}
+	c.ext.storageLock.Unlock()
+	return c.Client.Close(ctx)
+}
@@ -0,0 +1,161 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package queuehealthextension
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type storageHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h storageHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+type nopExtension struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+// memoryStorage is a storage extension whose clients store their values in memory.
+type memoryStorage struct {
+	component.StartFunc
+	component.ShutdownFunc
+}
+
+func (memoryStorage) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return &memoryClient{values: map[string][]byte{}}, nil
+}
+
+type memoryClient struct {
+	values map[string][]byte
+}
+
+func (c *memoryClient) Get(ctx context.Context, key string) ([]byte, error) {
+	op := storage.GetOperation(key)
+	err := c.Batch(ctx, op)
+	return op.Value, err
+}
+
+func (c *memoryClient) Set(ctx context.Context, key string, value []byte) error {
+	return c.Batch(ctx, storage.SetOperation(key, value))
+}
+
+func (c *memoryClient) Delete(ctx context.Context, key string) error {
+	return c.Batch(ctx, storage.DeleteOperation(key))
+}
+
+func (c *memoryClient) Batch(_ context.Context, ops ...storage.Operation) error {
+	for _, op := range ops {
+		switch op.Type {
+		case storage.Get:
+			op.Value = c.values[op.Key]
+		case storage.Set:
+			c.values[op.Key] = op.Value
+		case storage.Delete:
+			delete(c.values, op.Key)
+		}
+	}
+	return nil
+}
+
+func (c *memoryClient) Close(context.Context) error {
+	return nil
+}
+
+func startStorageExtension(t *testing.T, maxDiskUsageMiB int64) (*queueHealthExtension, *observer.ObservedLogs) {
+	ext, logs := newTestExtension(t, t.TempDir())
+	storageID := config.NewComponentID("file_storage")
+	ext.cfg.Storage = &storageID
+	ext.cfg.MaxDiskUsageMiB = maxDiskUsageMiB
+	ext.diskFree = func(string) (uint64, error) { return 1 << 40, nil }
+	host := storageHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{storageID: memoryStorage{}},
+	}
+	require.NoError(t, ext.Start(context.Background(), host))
+	t.Cleanup(func() { require.NoError(t, ext.Shutdown(context.Background())) })
+	return ext, logs
+}
+
+func TestStartWithStorage(t *testing.T) {
+	ext, _ := newTestExtension(t, t.TempDir())
+	storageID := config.NewComponentID("file_storage")
+	ext.cfg.Storage = &storageID
+
+	err := ext.Start(context.Background(), componenttest.NewNopHost())
+	require.EqualError(t, err, `storage extension "file_storage" not found`)
+
+	err = ext.Start(context.Background(), storageHost{
+		Host:       componenttest.NewNopHost(),
+		extensions: map[config.ComponentID]component.Extension{storageID: nopExtension{}},
+	})
+	require.EqualError(t, err, `"file_storage" is not a storage extension`)
+}
+
+func TestGetClientWithoutStorage(t *testing.T) {
+	ext, _ := newTestExtension(t, t.TempDir())
+	_, err := ext.GetClient(context.Background(), component.KindExporter, config.NewComponentID("otlp"), "")
+	require.EqualError(t, err, "the storage option must be set to use queue_health as a storage extension")
+}
+
+func TestClientEnforcesMaxDiskUsage(t *testing.T) {
+	ctx := context.Background()
+	ext, logs := startStorageExtension(t, 1)
+	client, err := ext.GetClient(ctx, component.KindExporter, config.NewComponentID("otlp"), "")
+	require.NoError(t, err)
+	other, err := ext.GetClient(ctx, component.KindExporter, config.NewComponentID("splunk_hec"), "")
+	require.NoError(t, err)
+
+	// the missing index is read like by the queue on start
+	index, err := client.Get(ctx, "wi")
+	require.NoError(t, err)
+	assert.Nil(t, index)
+
+	require.NoError(t, client.Batch(ctx, storage.SetOperation("wi", []byte{1}), storage.SetOperation("0", make([]byte, mib-100))))
+	// the usage is shared by all clients
+	err = other.Batch(ctx, storage.SetOperation("wi", []byte{1}), storage.SetOperation("0", make([]byte, 200)))
+	require.ErrorIs(t, err, errMaxDiskUsageReached)
+	err = client.Set(ctx, "1", make([]byte, 200))
+	require.ErrorIs(t, err, errMaxDiskUsageReached)
+	assert.Equal(t, 1, logs.FilterMessage("Persistent queue max disk usage reached, refusing new items until it's drained").Len())
+
+	// updates of known keys and deletions are allowed, so the queue can be drained
+	require.NoError(t, client.Set(ctx, "wi", []byte{2}))
+	require.NoError(t, client.Delete(ctx, "0"))
+	assert.Equal(t, 1, logs.FilterMessage("Persistent queue disk usage is below the maximum again").Len())
+	require.NoError(t, other.Set(ctx, "0", make([]byte, 200)))
+
+	value, err := other.Get(ctx, "0")
+	require.NoError(t, err)
+	assert.Len(t, value, 200)
+
+	// closed clients no longer count toward the usage
+	require.NoError(t, other.Close(ctx))
+	require.NoError(t, client.Set(ctx, "1", make([]byte, mib-100)))
+	assert.EqualValues(t, mib-100+1, ext.storedSize)
+}
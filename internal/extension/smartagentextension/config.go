@@ -32,6 +32,9 @@ type Config struct {
 	// Agent uses yaml, which mapstructure doesn't support.
 	// Custom unmarshaller required for yaml and SFx defaults usage.
 	saconfig.Config `mapstructure:"-,squash"`
+	// HostFSRoot is the path the host's root filesystem is mounted at in the collector's container,
+	// prefixing the default procPath, etcPath, varPath, runPath, and sysPath.
+	HostFSRoot string `mapstructure:"-"`
 }
 
 func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
@@ -46,11 +49,34 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 		}
 	}
 
+	hostFSRoot, err := hostFSRootFromSettingsMap(allSettings)
+	if err != nil {
+		return err
+	}
+	hostPaths := map[string]bool{}
+	for _, key := range []string{"procPath", "etcPath", "varPath", "runPath", "sysPath"} {
+		_, hostPaths[key] = allSettings[key]
+	}
+
 	config, err := smartAgentConfigFromSettingsMap(allSettings)
 	if err != nil {
 		return err
 	}
 
+	if hostFSRoot != "" {
+		for key, path := range map[string]*string{
+			"procPath": &config.ProcPath,
+			"etcPath":  &config.EtcPath,
+			"varPath":  &config.VarPath,
+			"runPath":  &config.RunPath,
+			"sysPath":  &config.SysPath,
+		} {
+			if !hostPaths[key] {
+				*path = filepath.Join(hostFSRoot, *path)
+			}
+		}
+	}
+
 	if config.BundleDir == "" {
 		config.BundleDir = cfg.Config.BundleDir
 	}
@@ -61,9 +87,26 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 	}
 
 	cfg.Config = *config
+	cfg.HostFSRoot = hostFSRoot
 	return nil
 }
 
+func hostFSRootFromSettingsMap(settings map[string]any) (string, error) {
+	value, ok := settings["hostFSRoot"]
+	if !ok {
+		return "", nil
+	}
+	delete(settings, "hostFSRoot")
+	if value == nil {
+		return "", nil
+	}
+	hostFSRoot, ok := value.(string)
+	if !ok || (hostFSRoot != "" && !filepath.IsAbs(hostFSRoot)) {
+		return "", fmt.Errorf("hostFSRoot must be an absolute path but got %v", value)
+	}
+	return hostFSRoot, nil
+}
+
 func smartAgentConfigFromSettingsMap(settings map[string]any) (*saconfig.Config, error) {
 	var config saconfig.Config
 	var collectdSettings map[string]any
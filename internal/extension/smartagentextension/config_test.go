@@ -16,6 +16,7 @@ package smartagentextension
 
 import (
 	"context"
+	"fmt"
 	"path"
 	"path/filepath"
 	"testing"
@@ -49,7 +50,7 @@ func TestLoadConfig(t *testing.T) {
 	require.NoError(t, err)
 	require.NotNil(t, cfg)
 
-	require.Equal(t, len(cfg.Extensions), 3)
+	require.Equal(t, len(cfg.Extensions), 4)
 
 	emptyConfig := cfg.Extensions[config.NewComponentIDWithName(typeStr, "default_settings")]
 	require.NotNil(t, emptyConfig)
@@ -102,6 +103,27 @@ func TestLoadConfig(t *testing.T) {
 		cfg.Collectd.BundleDir = "/opt/"
 		return &cfg
 	}(), partialSettingsConfig)
+
+	hostFSRootConfig := cfg.Extensions[config.NewComponentIDWithName(typeStr, "hostfs_root")]
+	require.NotNil(t, hostFSRootConfig)
+	require.NoError(t, configtest.CheckConfigStruct(hostFSRootConfig))
+	require.Equal(t, func() *Config {
+		cfg := defaultConfig()
+		cfg.ExtensionSettings.SetIDName("hostfs_root")
+		cfg.HostFSRoot = "/hostfs"
+		cfg.ProcPath = "/hostfs/proc"
+		cfg.EtcPath = "/hostfs/etc"
+		cfg.VarPath = "/hostfs/var"
+		cfg.RunPath = "/hostfs/run"
+		return &cfg
+	}(), hostFSRootConfig)
+}
+
+func TestInvalidHostFSRoot(t *testing.T) {
+	for _, value := range []any{"hostfs", 1} {
+		_, err := hostFSRootFromSettingsMap(map[string]any{"hostFSRoot": value})
+		require.EqualError(t, err, fmt.Sprintf("hostFSRoot must be an absolute path but got %v", value))
+	}
 }
 
 func TestSmartAgentConfigProvider(t *testing.T) {
@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenauthextension
+
+import (
+	"errors"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the token auth extension.
+type Config struct {
+	config.ExtensionSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// TokensFile is the path of a yaml file mapping the valid tokens to their identity attributes.
+	TokensFile string `mapstructure:"tokens_file"`
+	// Vault is the Vault secret whose keys are valid tokens, combined with those of the TokensFile.
+	Vault *VaultConfig `mapstructure:"vault"`
+	// ReloadInterval is the interval at which the valid tokens are reloaded from their sources.
+	ReloadInterval time.Duration `mapstructure:"reload_interval"`
+}
+
+// VaultConfig defines the Vault secret to load valid tokens from.
+type VaultConfig struct {
+	// Endpoint is the address of the Vault server, typically it is set via the
+	// VAULT_ADDR environment variable for the Vault CLI.
+	Endpoint string `mapstructure:"endpoint"`
+	// Path is the Vault path of the secret, like "secret/data/otel/tokens" for a KV v2 secrets engine.
+	Path string `mapstructure:"path"`
+	// Token is the token used to access the Vault server.  The VAULT_TOKEN environment
+	// variable is used if not provided.
+	Token string `mapstructure:"token"`
+}
+
+var _ config.Extension = (*Config)(nil)
+
+// Validate checks if the extension configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.TokensFile == "" && cfg.Vault == nil {
+		return errors.New("either tokens_file or vault must be provided")
+	}
+	if cfg.Vault != nil {
+		if cfg.Vault.Endpoint == "" {
+			return errors.New("vault endpoint must be provided")
+		}
+		if cfg.Vault.Path == "" {
+			return errors.New("vault path must be provided")
+		}
+	}
+	if cfg.ReloadInterval <= 0 {
+		return errors.New("reload_interval must be positive")
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenauthextension
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Extensions[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	e0 := cfg.Extensions[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.TokensFile = "/etc/otel/collector/tokens.yaml"
+	assert.Equal(t, expected, e0)
+
+	e1 := cfg.Extensions[config.NewComponentIDWithName(typeStr, "vault")]
+	assert.Equal(t, &Config{
+		ExtensionSettings: config.NewExtensionSettings(config.NewComponentIDWithName(typeStr, "vault")),
+		TokensFile:        "/etc/otel/collector/tokens.yaml",
+		Vault: &VaultConfig{
+			Endpoint: "https://vault.example.com:8200",
+			Path:     "secret/data/otel/tokens",
+			Token:    "some-vault-token",
+		},
+		ReloadInterval: 5 * time.Minute,
+	}, e1)
+}
+
+func TestLoadInvalidConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+	factories.Extensions[typeStr] = NewFactory()
+
+	_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "invalid_no_sources.yaml"), factories)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "either tokens_file or vault must be provided")
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		modify func(cfg *Config)
+		err    string
+	}{
+		{modify: func(cfg *Config) { cfg.Vault = &VaultConfig{Path: "secret/tokens"} }, err: "vault endpoint must be provided"},
+		{modify: func(cfg *Config) { cfg.Vault = &VaultConfig{Endpoint: "http://localhost:8200"} }, err: "vault path must be provided"},
+		{modify: func(cfg *Config) { cfg.ReloadInterval = 0 }, err: "reload_interval must be positive"},
+	} {
+		t.Run(test.err, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.TokensFile = "/tmp/tokens.yaml"
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.err)
+		})
+	}
+}
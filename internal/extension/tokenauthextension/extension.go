@@ -0,0 +1,161 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenauthextension
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config/configauth"
+	"go.uber.org/zap"
+)
+
+var (
+	errMissingToken = errors.New("missing token")
+	errInvalidToken = errors.New("invalid token")
+)
+
+type tokenAuth struct {
+	logger  *zap.Logger
+	cfg     *Config
+	sources []tokenSource
+	// valid tokens mapped to their identity attributes
+	tokens map[string]map[string]string
+	done   chan struct{}
+	wg     sync.WaitGroup
+	lock   sync.RWMutex
+}
+
+var _ configauth.ServerAuthenticator = (*tokenAuth)(nil)
+
+func newTokenAuth(cfg *Config, logger *zap.Logger) *tokenAuth {
+	return &tokenAuth{
+		logger: logger,
+		cfg:    cfg,
+		done:   make(chan struct{}),
+	}
+}
+
+func (e *tokenAuth) Start(context.Context, component.Host) error {
+	if e.cfg.TokensFile != "" {
+		e.sources = append(e.sources, fileTokenSource(e.cfg.TokensFile))
+	}
+	if e.cfg.Vault != nil {
+		source, err := vaultTokenSource(*e.cfg.Vault)
+		if err != nil {
+			return fmt.Errorf("failed creating vault client: %w", err)
+		}
+		e.sources = append(e.sources, source)
+	}
+	if err := e.reload(); err != nil {
+		return err
+	}
+
+	e.wg.Add(1)
+	go func() {
+		defer e.wg.Done()
+		ticker := time.NewTicker(e.cfg.ReloadInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-e.done:
+				return
+			case <-ticker.C:
+				if err := e.reload(); err != nil {
+					e.logger.Warn("Failed reloading tokens, retaining the previous ones", zap.Error(err))
+				}
+			}
+		}
+	}()
+	return nil
+}
+
+func (e *tokenAuth) Shutdown(context.Context) error {
+	close(e.done)
+	e.wg.Wait()
+	return nil
+}
+
+// reload replaces the valid tokens with those of all sources, which take precedence in order for tokens
+// provided by more than one.  The tokens are only replaced if all sources are successfully loaded.
+func (e *tokenAuth) reload() error {
+	tokens := map[string]map[string]string{}
+	for _, source := range e.sources {
+		loaded, err := source()
+		if err != nil {
+			return err
+		}
+		for token, attributes := range loaded {
+			tokens[token] = attributes
+		}
+	}
+
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	if len(tokens) != len(e.tokens) {
+		e.logger.Info("Loaded tokens", zap.Int("count", len(tokens)))
+	}
+	e.tokens = tokens
+	return nil
+}
+
+// Authenticate validates the token provided by the headers, adding its identity attributes
+// to the context's client.Info auth data.
+func (e *tokenAuth) Authenticate(ctx context.Context, headers map[string][]string) (context.Context, error) {
+	token, ok := tokenFromHeaders(headers)
+	if !ok {
+		return ctx, errMissingToken
+	}
+
+	e.lock.RLock()
+	attributes, valid := e.tokens[token]
+	e.lock.RUnlock()
+	if !valid {
+		return ctx, errInvalidToken
+	}
+
+	info := client.FromContext(ctx)
+	info.Auth = &authData{attributes: attributes}
+	return client.NewContext(ctx, info), nil
+}
+
+// authData provides the identity attributes of an authenticated token.
+type authData struct {
+	attributes map[string]string
+}
+
+var _ client.AuthData = (*authData)(nil)
+
+func (a *authData) GetAttribute(name string) any {
+	if value, ok := a.attributes[name]; ok {
+		return value
+	}
+	return nil
+}
+
+func (a *authData) GetAttributeNames() []string {
+	names := make([]string, 0, len(a.attributes))
+	for name := range a.attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,167 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenauthextension
+
+import (
+	"context"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/client"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.uber.org/zap"
+)
+
+func TestTokenFromHeaders(t *testing.T) {
+	basic := base64.StdEncoding.EncodeToString([]byte("x:some-token"))
+	for _, test := range []struct {
+		name    string
+		headers map[string][]string
+		token   string
+	}{
+		{name: "sfx", headers: map[string][]string{"X-Sf-Token": {"some-token"}}, token: "some-token"},
+		{name: "grpc metadata", headers: map[string][]string{"x-sf-token": {"some-token"}}, token: "some-token"},
+		{name: "hec", headers: map[string][]string{"Authorization": {"Splunk some-token"}}, token: "some-token"},
+		{name: "bearer", headers: map[string][]string{"authorization": {"Bearer some-token"}}, token: "some-token"},
+		{name: "basic", headers: map[string][]string{"Authorization": {"Basic " + basic}}, token: "some-token"},
+		{name: "basic without password", headers: map[string][]string{"Authorization": {"Basic " + base64.StdEncoding.EncodeToString([]byte("x"))}}},
+		{name: "invalid basic", headers: map[string][]string{"Authorization": {"Basic not-base64!"}}},
+		{name: "unsupported scheme", headers: map[string][]string{"Authorization": {"Digest some-token"}}},
+		{name: "missing", headers: map[string][]string{"Content-Type": {"application/json"}}},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			token, ok := tokenFromHeaders(test.headers)
+			assert.Equal(t, test.token != "", ok)
+			assert.Equal(t, test.token, token)
+		})
+	}
+}
+
+func TestAuthenticate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TokensFile = path.Join(".", "testdata", "tokens.yaml")
+	ext := newTokenAuth(cfg, zap.NewNop())
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	ctx, err := ext.Authenticate(context.Background(), map[string][]string{"X-Sf-Token": {"token-one"}})
+	require.NoError(t, err)
+	auth := client.FromContext(ctx).Auth
+	require.NotNil(t, auth)
+	assert.Equal(t, []string{"environment", "tenant"}, auth.GetAttributeNames())
+	assert.Equal(t, "acme", auth.GetAttribute("tenant"))
+	assert.Nil(t, auth.GetAttribute("missing"))
+
+	ctx, err = ext.Authenticate(context.Background(), map[string][]string{"Authorization": {"Splunk token-two"}})
+	require.NoError(t, err)
+	assert.Empty(t, client.FromContext(ctx).Auth.GetAttributeNames())
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{"X-Sf-Token": {"token-three"}})
+	assert.Equal(t, errInvalidToken, err)
+
+	_, err = ext.Authenticate(context.Background(), map[string][]string{})
+	assert.Equal(t, errMissingToken, err)
+}
+
+// writeTokensFile replaces the tokens file, as recommended, so that it's never read partly written.
+func writeTokensFile(t *testing.T, path, content string) {
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0600))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func TestReload(t *testing.T) {
+	tokensFile := filepath.Join(t.TempDir(), "tokens.yaml")
+	writeTokensFile(t, tokensFile, "token-one:\n")
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.TokensFile = tokensFile
+	cfg.ReloadInterval = 10 * time.Millisecond
+	ext := newTokenAuth(cfg, zap.NewNop())
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	headers := map[string][]string{"X-Sf-Token": {"token-two"}}
+	_, err := ext.Authenticate(context.Background(), headers)
+	require.Equal(t, errInvalidToken, err)
+
+	writeTokensFile(t, tokensFile, "token-two:\n")
+	require.Eventually(t, func() bool {
+		_, err = ext.Authenticate(context.Background(), headers)
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	// invalid and empty content retain the previous tokens
+	for _, content := range []string{"not: [valid", ""} {
+		writeTokensFile(t, tokensFile, content)
+		time.Sleep(50 * time.Millisecond)
+		_, err = ext.Authenticate(context.Background(), headers)
+		require.NoError(t, err)
+	}
+}
+
+func TestStartWithEmptyTokensFile(t *testing.T) {
+	tokensFile := filepath.Join(t.TempDir(), "tokens.yaml")
+	writeTokensFile(t, tokensFile, "")
+	cfg := createDefaultConfig().(*Config)
+	cfg.TokensFile = tokensFile
+	ext := newTokenAuth(cfg, zap.NewNop())
+	require.EqualError(t, ext.Start(context.Background(), componenttest.NewNopHost()), `tokens file "`+tokensFile+`" has no tokens`)
+}
+
+func TestStartWithMissingTokensFile(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.TokensFile = filepath.Join(t.TempDir(), "missing.yaml")
+	ext := newTokenAuth(cfg, zap.NewNop())
+	require.Error(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+}
+
+func TestVaultTokens(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != "/v1/secret/data/otel/tokens" || request.Header.Get("X-Vault-Token") != "some-vault-token" {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		writer.Header().Set("Content-Type", "application/json")
+		_, _ = writer.Write([]byte(`{"data": {"data": {"token-one": {"tenant": "acme", "tier": 2}, "token-two": "ingest"}, "metadata": {"version": 1}}}`))
+	}))
+	defer server.Close()
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Vault = &VaultConfig{Endpoint: server.URL, Path: "secret/data/otel/tokens", Token: "some-vault-token"}
+	ext := newTokenAuth(cfg, zap.NewNop())
+	require.NoError(t, ext.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, ext.Shutdown(context.Background())) }()
+
+	ctx, err := ext.Authenticate(context.Background(), map[string][]string{"X-Sf-Token": {"token-one"}})
+	require.NoError(t, err)
+	assert.Equal(t, "acme", client.FromContext(ctx).Auth.GetAttribute("tenant"))
+	assert.Equal(t, "2", client.FromContext(ctx).Auth.GetAttribute("tier"))
+
+	ctx, err = ext.Authenticate(context.Background(), map[string][]string{"X-Sf-Token": {"token-two"}})
+	require.NoError(t, err)
+	assert.Equal(t, "ingest", client.FromContext(ctx).Auth.GetAttribute(nameAttribute))
+
+	cfg.Vault.Path = "secret/data/otel/missing"
+	require.Error(t, newTokenAuth(cfg, zap.NewNop()).Start(context.Background(), componenttest.NewNopHost()))
+}
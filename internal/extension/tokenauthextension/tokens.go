@@ -0,0 +1,140 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokenauthextension
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	sfxTokenHeader      = "X-Sf-Token"
+	authorizationHeader = "Authorization"
+	// nameAttribute is the identity attribute of tokens whose Vault secret value is a string.
+	nameAttribute = "name"
+)
+
+// tokenSource provides the valid tokens mapped to their identity attributes.
+type tokenSource func() (map[string]map[string]string, error)
+
+// fileTokenSource loads the tokens of a yaml file mapping each token to its identity attributes, if any:
+//
+//	token-one:
+//	  tenant: acme
+//	token-two:
+func fileTokenSource(path string) tokenSource {
+	return func() (map[string]map[string]string, error) {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		var tokens map[string]map[string]string
+		if err = yaml.UnmarshalStrict(content, &tokens); err != nil {
+			return nil, fmt.Errorf("invalid tokens file %q: %w", path, err)
+		}
+		// an empty file is more likely being written than meant to reject every token
+		if len(tokens) == 0 {
+			return nil, fmt.Errorf("tokens file %q has no tokens", path)
+		}
+		return tokens, nil
+	}
+}
+
+// vaultTokenSource loads the tokens of a Vault secret, each of whose keys is a valid token.  Values that are
+// objects are the token's identity attributes, and string values are used as the token's name attribute.
+func vaultTokenSource(cfg VaultConfig) (tokenSource, error) {
+	// Client doesn't connect on creation and can't be closed.
+	client, err := api.NewClient(&api.Config{Address: cfg.Endpoint})
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Token != "" {
+		client.SetToken(cfg.Token)
+	}
+	return func() (map[string]map[string]string, error) {
+		secret, err := client.Logical().Read(cfg.Path)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading vault secret %q: %w", cfg.Path, err)
+		}
+		if secret == nil || secret.Data == nil {
+			return nil, fmt.Errorf("no vault secret found at %q", cfg.Path)
+		}
+		data := secret.Data
+		// KV v2 secrets nest their content along with its metadata
+		if nested, ok := data["data"].(map[string]any); ok {
+			if _, hasMetadata := data["metadata"]; hasMetadata {
+				data = nested
+			}
+		}
+		tokens := make(map[string]map[string]string, len(data))
+		for token, value := range data {
+			switch v := value.(type) {
+			case string:
+				tokens[token] = map[string]string{nameAttribute: v}
+			case map[string]any:
+				attributes := make(map[string]string, len(v))
+				for k, attribute := range v {
+					attributes[k] = fmt.Sprint(attribute)
+				}
+				tokens[token] = attributes
+			default:
+				return nil, fmt.Errorf("vault secret %q has an unsupported value type %T", cfg.Path, value)
+			}
+		}
+		return tokens, nil
+	}, nil
+}
+
+// tokenFromHeaders returns the token provided by the X-SF-Token header, or by the Authorization header via the
+// "Splunk <token>" HEC scheme, the "Bearer <token>" scheme, or as the password of the "Basic" scheme.  Header names
+// are case-insensitive since they're canonicalized for HTTP and lowercased for gRPC metadata.
+func tokenFromHeaders(headers map[string][]string) (string, bool) {
+	if token := headerValue(headers, sfxTokenHeader); token != "" {
+		return token, true
+	}
+	scheme, credentials, ok := strings.Cut(headerValue(headers, authorizationHeader), " ")
+	if !ok || credentials == "" {
+		return "", false
+	}
+	switch strings.ToLower(scheme) {
+	case "splunk", "bearer":
+		return credentials, true
+	case "basic":
+		decoded, err := base64.StdEncoding.DecodeString(credentials)
+		if err != nil {
+			return "", false
+		}
+		_, password, ok := strings.Cut(string(decoded), ":")
+		if !ok || password == "" {
+			return "", false
+		}
+		return password, true
+	}
+	return "", false
+}
+
+func headerValue(headers map[string][]string, name string) string {
+	for k, values := range headers {
+		if strings.EqualFold(k, name) && len(values) > 0 {
+			return values[0]
+		}
+	}
+	return ""
+}
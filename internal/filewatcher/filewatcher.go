@@ -0,0 +1,155 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package filewatcher notifies of the changes to the content of files, debouncing the bursts of events
+// typical of their replacement by editors, config management tools, and certificate managers.
+package filewatcher
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+)
+
+// Watcher invokes a callback when the content of any of its files changes. Their parent directories are
+// what's actually watched so that files atomically replaced via rename, as certificate managers and
+// Kubernetes volume updates do, remain watched.
+type Watcher struct {
+	watcher  *fsnotify.Watcher
+	onChange func(changed []string)
+	onError  func(error)
+	digests  map[string][sha256.Size]byte
+	dirs     map[string]bool
+	done     chan struct{}
+	debounce time.Duration
+	wg       sync.WaitGroup
+	lock     sync.Mutex
+}
+
+// New returns a Watcher invoking onChange with the files whose content changed once their events have
+// settled for the debounce duration, and onError with the errors watching them. The callbacks are invoked
+// from a single goroutine and must not close the Watcher.
+func New(debounce time.Duration, onChange func(changed []string), onError func(error)) (*Watcher, error) {
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		return nil, err
+	}
+	w := &Watcher{
+		watcher:  watcher,
+		onChange: onChange,
+		onError:  onError,
+		digests:  map[string][sha256.Size]byte{},
+		dirs:     map[string]bool{},
+		done:     make(chan struct{}),
+		debounce: debounce,
+	}
+	w.wg.Add(1)
+	go w.watch()
+	return w, nil
+}
+
+// Add watches the file, if not already watched. Files that can't be read are considered changed once they can.
+func (w *Watcher) Add(file string) error {
+	absFile, err := filepath.Abs(file)
+	if err != nil {
+		return err
+	}
+
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	if _, ok := w.digests[absFile]; ok {
+		return nil
+	}
+	if dir := filepath.Dir(absFile); !w.dirs[dir] {
+		if err = w.watcher.Add(dir); err != nil {
+			return fmt.Errorf("failed watching directory %q: %w", dir, err)
+		}
+		w.dirs[dir] = true
+	}
+	w.digests[absFile], _ = fileDigest(absFile)
+	return nil
+}
+
+func (w *Watcher) watch() {
+	defer w.wg.Done()
+	var debounce <-chan time.Time
+	for {
+		select {
+		case <-w.done:
+			return
+		case event, ok := <-w.watcher.Events:
+			if !ok {
+				return
+			}
+			if event.Op == fsnotify.Chmod {
+				continue
+			}
+			// Any other event in a watched directory may affect a watched file, e.g. the
+			// replacement of the "..data" symlink of Kubernetes volumes.
+			debounce = time.After(w.debounce)
+		case <-debounce:
+			debounce = nil
+			if changed := w.updateDigests(); len(changed) != 0 {
+				w.onChange(changed)
+			}
+		case err, ok := <-w.watcher.Errors:
+			if !ok {
+				return
+			}
+			w.onError(err)
+		}
+	}
+}
+
+// updateDigests records the current digests of the watched files, returning the changed ones. Files that
+// can't be read, e.g. ones removed before being recreated, aren't considered changed until they are.
+func (w *Watcher) updateDigests() []string {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+	var changed []string
+	for file, previous := range w.digests {
+		digest, err := fileDigest(file)
+		if err != nil {
+			continue
+		}
+		if digest != previous {
+			w.digests[file] = digest
+			changed = append(changed, file)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Close stops watching the files, returning once no callback is being invoked.
+func (w *Watcher) Close() error {
+	close(w.done)
+	err := w.watcher.Close()
+	w.wg.Wait()
+	return err
+}
+
+func fileDigest(file string) ([sha256.Size]byte, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return [sha256.Size]byte{}, err
+	}
+	return sha256.Sum256(content), nil
+}
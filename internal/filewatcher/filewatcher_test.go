@@ -0,0 +1,82 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package filewatcher
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWatcher(t *testing.T) {
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("original"), 0600))
+	missingFile := filepath.Join(dir, "key.pem")
+
+	changes := make(chan []string, 10)
+	watcher, err := New(10*time.Millisecond, func(changed []string) { changes <- changed }, func(err error) {
+		assert.NoError(t, err)
+	})
+	require.NoError(t, err)
+	require.NoError(t, watcher.Add(caFile))
+	require.NoError(t, watcher.Add(missingFile))
+	// already watched
+	require.NoError(t, watcher.Add(caFile))
+
+	// unmodified content isn't a change
+	require.NoError(t, os.WriteFile(caFile, []byte("original"), 0600))
+	select {
+	case changed := <-changes:
+		t.Fatalf("unexpected change of %v for unmodified content", changed)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// files replaced via rename remain watched
+	replacement := filepath.Join(dir, "ca.pem.tmp")
+	require.NoError(t, os.WriteFile(replacement, []byte("rotated"), 0600))
+	require.NoError(t, os.Rename(replacement, caFile))
+	select {
+	case changed := <-changes:
+		assert.Equal(t, []string{caFile}, changed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change for rotated file")
+	}
+
+	// unreadable files are changed once they can be read
+	require.NoError(t, os.WriteFile(missingFile, []byte("key"), 0600))
+	select {
+	case changed := <-changes:
+		assert.Equal(t, []string{missingFile}, changed)
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change for created file")
+	}
+
+	require.NoError(t, watcher.Close())
+}
+
+func TestWatcherAddError(t *testing.T) {
+	watcher, err := New(time.Second, func([]string) {}, func(error) {})
+	require.NoError(t, err)
+	defer func() { require.NoError(t, watcher.Close()) }()
+
+	err = watcher.Add(filepath.Join(t.TempDir(), "missing", "ca.pem"))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed watching directory")
+}
@@ -0,0 +1,226 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostmetadata
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+const (
+	defaultEC2Endpoint   = "http://169.254.169.254"
+	defaultAzureEndpoint = "http://169.254.169.254"
+	defaultGCEEndpoint   = "http://metadata.google.internal"
+
+	ec2TokenPath            = "/latest/api/token"
+	ec2TokenHeader          = "X-aws-ec2-metadata-token"
+	ec2TokenTTLHeader       = "X-aws-ec2-metadata-token-ttl-seconds"
+	ec2TokenTTLSeconds      = "21600"
+	ec2IdentityDocumentPath = "/latest/dynamic/instance-identity/document"
+	azureComputePath        = "/metadata/instance/compute?api-version=2020-09-01&format=json"
+	gceInstancePath         = "/computeMetadata/v1/?recursive=true"
+)
+
+type ec2Detector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewEC2Detector creates a Detector for the EC2 instance metadata service at the
+// provided endpoint. IMDSv2 session tokens are used when available, falling back
+// to IMDSv1 requests otherwise.
+func NewEC2Detector(client *http.Client, endpoint string) Detector {
+	return &ec2Detector{client: client, endpoint: endpoint}
+}
+
+func (d *ec2Detector) Name() string {
+	return "ec2"
+}
+
+func (d *ec2Detector) Detect(ctx context.Context) (*Metadata, error) {
+	headers := map[string]string{}
+	if token, err := d.token(ctx); err == nil {
+		headers[ec2TokenHeader] = token
+	}
+
+	var doc struct {
+		AccountID        string `json:"accountId"`
+		AvailabilityZone string `json:"availabilityZone"`
+		ImageID          string `json:"imageId"`
+		InstanceID       string `json:"instanceId"`
+		InstanceType     string `json:"instanceType"`
+		Region           string `json:"region"`
+	}
+	if err := getJSON(ctx, d.client, d.endpoint+ec2IdentityDocumentPath, headers, &doc); err != nil {
+		return nil, err
+	}
+	return &Metadata{
+		CloudProvider:    conventions.AttributeCloudProviderAWS,
+		CloudPlatform:    conventions.AttributeCloudPlatformAWSEC2,
+		Region:           doc.Region,
+		AvailabilityZone: doc.AvailabilityZone,
+		AccountID:        doc.AccountID,
+		InstanceID:       doc.InstanceID,
+		InstanceType:     doc.InstanceType,
+		ImageID:          doc.ImageID,
+	}, nil
+}
+
+// token retrieves an IMDSv2 session token.
+func (d *ec2Detector) token(ctx context.Context) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, d.endpoint+ec2TokenPath, http.NoBody)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set(ec2TokenTTLHeader, ec2TokenTTLSeconds)
+	body, err := do(d.client, req)
+	if err != nil {
+		return "", err
+	}
+	return string(body), nil
+}
+
+type azureDetector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewAzureDetector creates a Detector for the Azure instance metadata service at the provided endpoint.
+func NewAzureDetector(client *http.Client, endpoint string) Detector {
+	return &azureDetector{client: client, endpoint: endpoint}
+}
+
+func (d *azureDetector) Name() string {
+	return "azure"
+}
+
+func (d *azureDetector) Detect(ctx context.Context) (*Metadata, error) {
+	var compute struct {
+		Location          string `json:"location"`
+		Name              string `json:"name"`
+		ResourceGroupName string `json:"resourceGroupName"`
+		SubscriptionID    string `json:"subscriptionId"`
+		VMID              string `json:"vmId"`
+		VMSize            string `json:"vmSize"`
+		Zone              string `json:"zone"`
+	}
+	if err := getJSON(ctx, d.client, d.endpoint+azureComputePath, map[string]string{"Metadata": "true"}, &compute); err != nil {
+		return nil, err
+	}
+	return &Metadata{
+		CloudProvider:    conventions.AttributeCloudProviderAzure,
+		CloudPlatform:    conventions.AttributeCloudPlatformAzureVM,
+		Region:           compute.Location,
+		AvailabilityZone: compute.Zone,
+		AccountID:        compute.SubscriptionID,
+		InstanceID:       compute.VMID,
+		InstanceName:     compute.Name,
+		InstanceType:     compute.VMSize,
+		HostName:         compute.Name,
+		ResourceGroup:    compute.ResourceGroupName,
+	}, nil
+}
+
+type gceDetector struct {
+	client   *http.Client
+	endpoint string
+}
+
+// NewGCEDetector creates a Detector for the GCE metadata server at the provided endpoint.
+func NewGCEDetector(client *http.Client, endpoint string) Detector {
+	return &gceDetector{client: client, endpoint: endpoint}
+}
+
+func (d *gceDetector) Name() string {
+	return "gce"
+}
+
+func (d *gceDetector) Detect(ctx context.Context) (*Metadata, error) {
+	var metadata struct {
+		Instance struct {
+			Hostname    string `json:"hostname"`
+			ID          uint64 `json:"id"`
+			Image       string `json:"image"`
+			MachineType string `json:"machineType"`
+			Name        string `json:"name"`
+			Zone        string `json:"zone"`
+		} `json:"instance"`
+		Project struct {
+			ProjectID string `json:"projectId"`
+		} `json:"project"`
+	}
+	if err := getJSON(ctx, d.client, d.endpoint+gceInstancePath, map[string]string{"Metadata-Flavor": "Google"}, &metadata); err != nil {
+		return nil, err
+	}
+	// zones and machine types are of the form projects/<number>/zones/<zone>
+	zone := path.Base(metadata.Instance.Zone)
+	region := zone
+	if idx := len(zone) - 2; idx > 0 && zone[idx] == '-' {
+		region = zone[:idx]
+	}
+	return &Metadata{
+		CloudProvider:    conventions.AttributeCloudProviderGCP,
+		CloudPlatform:    conventions.AttributeCloudPlatformGCPComputeEngine,
+		Region:           region,
+		AvailabilityZone: zone,
+		AccountID:        metadata.Project.ProjectID,
+		InstanceID:       strconv.FormatUint(metadata.Instance.ID, 10),
+		InstanceName:     metadata.Instance.Name,
+		InstanceType:     path.Base(metadata.Instance.MachineType),
+		ImageID:          metadata.Instance.Image,
+		HostName:         metadata.Instance.Hostname,
+	}, nil
+}
+
+func getJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, into any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, http.NoBody)
+	if err != nil {
+		return err
+	}
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	body, err := do(client, req)
+	if err != nil {
+		return err
+	}
+	if err = json.Unmarshal(body, into); err != nil {
+		return fmt.Errorf("invalid metadata response from %s: %w", url, err)
+	}
+	return nil
+}
+
+func do(client *http.Client, req *http.Request) ([]byte, error) {
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status %q from %s", resp.Status, req.URL)
+	}
+	return body, nil
+}
@@ -0,0 +1,149 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostmetadata
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEC2DetectorUsesIMDSv2Token(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch {
+		case r.Method == http.MethodPut && r.URL.Path == ec2TokenPath:
+			assert.Equal(t, ec2TokenTTLSeconds, r.Header.Get(ec2TokenTTLHeader))
+			_, _ = w.Write([]byte("a-token"))
+		case r.Method == http.MethodGet && r.URL.Path == ec2IdentityDocumentPath:
+			if r.Header.Get(ec2TokenHeader) != "a-token" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			_, _ = w.Write([]byte(`{"accountId": "123456789012", "availabilityZone": "us-west-2b",
+				"imageId": "ami-5fb8c835", "instanceId": "i-1234567890abcdef0", "instanceType": "t2.micro",
+				"region": "us-west-2"}`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	metadata, err := NewEC2Detector(server.Client(), server.URL).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, &Metadata{
+		CloudProvider:    "aws",
+		CloudPlatform:    "aws_ec2",
+		Region:           "us-west-2",
+		AvailabilityZone: "us-west-2b",
+		AccountID:        "123456789012",
+		InstanceID:       "i-1234567890abcdef0",
+		InstanceType:     "t2.micro",
+		ImageID:          "ami-5fb8c835",
+	}, metadata)
+	assert.Equal(t, map[string]string{"AWSUniqueId": "i-1234567890abcdef0_us-west-2_123456789012"}, metadata.Dimensions())
+}
+
+func TestEC2DetectorFallsBackToIMDSv1(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodPut {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		assert.Empty(t, r.Header.Get(ec2TokenHeader))
+		_, _ = w.Write([]byte(`{"accountId": "123456789012", "instanceId": "i-1234567890abcdef0", "region": "us-west-2"}`))
+	}))
+	defer server.Close()
+
+	metadata, err := NewEC2Detector(server.Client(), server.URL).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "i-1234567890abcdef0", metadata.InstanceID)
+}
+
+func TestAzureDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "true", r.Header.Get("Metadata"))
+		assert.Equal(t, "/metadata/instance/compute", r.URL.Path)
+		_, _ = w.Write([]byte(`{"location": "westus", "name": "MyVM", "resourceGroupName": "MyGroup",
+			"subscriptionId": "8d10da13-8125-4ba9-a717-bf7490507b3d", "vmId": "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+			"vmSize": "Standard_A3", "zone": "1"}`))
+	}))
+	defer server.Close()
+
+	metadata, err := NewAzureDetector(server.Client(), server.URL).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"cloud.provider":          "azure",
+		"cloud.platform":          "azure_vm",
+		"cloud.region":            "westus",
+		"cloud.availability_zone": "1",
+		"cloud.account.id":        "8d10da13-8125-4ba9-a717-bf7490507b3d",
+		"host.id":                 "02aab8a4-74ef-476e-8182-f6d2ba4166a6",
+		"host.type":               "Standard_A3",
+		"host.name":               "MyVM",
+	}, metadata.ResourceAttributes())
+	assert.Equal(t, map[string]string{
+		"azure_resource_id": "8d10da13-8125-4ba9-a717-bf7490507b3d/mygroup/microsoft.compute/virtualmachines/myvm",
+	}, metadata.Dimensions())
+}
+
+func TestGCEDetector(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Google", r.Header.Get("Metadata-Flavor"))
+		_, _ = w.Write([]byte(`{"instance": {"hostname": "my-instance.c.my-project.internal", "id": 4520031799277581759,
+			"machineType": "projects/123456789/machineTypes/n1-standard-1", "name": "my-instance",
+			"zone": "projects/123456789/zones/us-central1-a"}, "project": {"projectId": "my-project"}}`))
+	}))
+	defer server.Close()
+
+	metadata, err := NewGCEDetector(server.Client(), server.URL).Detect(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "us-central1", metadata.Region)
+	assert.Equal(t, "us-central1-a", metadata.AvailabilityZone)
+	assert.Equal(t, "n1-standard-1", metadata.InstanceType)
+	assert.Equal(t, "my-instance.c.my-project.internal", metadata.HostName)
+	assert.Equal(t, map[string]string{"gcp_id": "my-project_4520031799277581759"}, metadata.Dimensions())
+}
+
+func TestDetectorErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == ec2IdentityDocumentPath {
+			_, _ = w.Write([]byte("not json"))
+			return
+		}
+		w.WriteHeader(http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	for _, detector := range []Detector{
+		NewEC2Detector(server.Client(), server.URL),
+		NewAzureDetector(server.Client(), server.URL),
+		NewGCEDetector(server.Client(), server.URL),
+	} {
+		metadata, err := detector.Detect(context.Background())
+		require.Error(t, err, detector.Name())
+		assert.Nil(t, metadata)
+	}
+}
+
+func TestDimensionsRequireIdentifiers(t *testing.T) {
+	assert.Empty(t, Metadata{CloudProvider: "aws", InstanceID: "i-123"}.Dimensions())
+	assert.Empty(t, Metadata{CloudProvider: "azure", AccountID: "sub"}.Dimensions())
+	assert.Empty(t, Metadata{CloudProvider: "gcp"}.Dimensions())
+	assert.Empty(t, Metadata{}.Dimensions())
+}
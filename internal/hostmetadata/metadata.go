@@ -0,0 +1,100 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package hostmetadata provides cloud provider host metadata that's shared by
+// components so that instance metadata endpoints are queried once per process.
+package hostmetadata
+
+import (
+	"fmt"
+	"strings"
+
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+const (
+	// AWSUniqueIDDimension is the SignalFx dimension identifying EC2 instances.
+	AWSUniqueIDDimension = "AWSUniqueId"
+	// AzureResourceIDDimension is the SignalFx dimension identifying Azure virtual machines.
+	AzureResourceIDDimension = "azure_resource_id"
+	// GCPIDDimension is the SignalFx dimension identifying GCE instances.
+	GCPIDDimension = "gcp_id"
+)
+
+// Metadata describes the cloud host the collector is running on.
+type Metadata struct {
+	CloudProvider    string
+	CloudPlatform    string
+	Region           string
+	AvailabilityZone string
+	AccountID        string
+	InstanceID       string
+	InstanceName     string
+	InstanceType     string
+	ImageID          string
+	HostName         string
+	// ResourceGroup is only populated for Azure virtual machines.
+	ResourceGroup string
+}
+
+// ResourceAttributes returns the semantic convention resource attributes for the
+// Metadata, omitting those without values.
+func (m Metadata) ResourceAttributes() map[string]string {
+	attrs := map[string]string{}
+	for key, value := range map[string]string{
+		conventions.AttributeCloudProvider:         m.CloudProvider,
+		conventions.AttributeCloudPlatform:         m.CloudPlatform,
+		conventions.AttributeCloudRegion:           m.Region,
+		conventions.AttributeCloudAvailabilityZone: m.AvailabilityZone,
+		conventions.AttributeCloudAccountID:        m.AccountID,
+		conventions.AttributeHostID:                m.InstanceID,
+		conventions.AttributeHostType:              m.InstanceType,
+		conventions.AttributeHostImageID:           m.ImageID,
+		conventions.AttributeHostName:              m.HostName,
+	} {
+		if value != "" {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
+
+// Dimensions returns the SignalFx unique host identifier dimensions used for
+// dimension property syncing, or an empty map for unrecognized providers.
+func (m Metadata) Dimensions() map[string]string {
+	switch m.CloudProvider {
+	case conventions.AttributeCloudProviderAWS:
+		if m.InstanceID == "" || m.Region == "" || m.AccountID == "" {
+			break
+		}
+		return map[string]string{
+			AWSUniqueIDDimension: fmt.Sprintf("%s_%s_%s", m.InstanceID, m.Region, m.AccountID),
+		}
+	case conventions.AttributeCloudProviderAzure:
+		if m.AccountID == "" || m.ResourceGroup == "" || m.InstanceName == "" {
+			break
+		}
+		return map[string]string{
+			AzureResourceIDDimension: strings.ToLower(fmt.Sprintf(
+				"%s/%s/microsoft.compute/virtualmachines/%s", m.AccountID, m.ResourceGroup, m.InstanceName,
+			)),
+		}
+	case conventions.AttributeCloudProviderGCP:
+		if m.AccountID == "" || m.InstanceID == "" {
+			break
+		}
+		return map[string]string{GCPIDDimension: fmt.Sprintf("%s_%s", m.AccountID, m.InstanceID)}
+	}
+	return map[string]string{}
+}
@@ -0,0 +1,183 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostmetadata
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// DefaultTTL is how long detected metadata, or the failure to detect it, is cached.
+	DefaultTTL = time.Hour
+	// DefaultTimeout bounds each detector's metadata endpoint requests.
+	DefaultTimeout = 2 * time.Second
+)
+
+// ErrNotDetected is returned when none of a Provider's detectors recognized the host.
+var ErrNotDetected = errors.New("no cloud provider metadata detected")
+
+// ErrPending is returned by a Lookup that hasn't completed.
+var ErrPending = errors.New("cloud provider metadata detection pending")
+
+// Detector retrieves metadata from a single cloud provider's metadata endpoint.
+type Detector interface {
+	Name() string
+	Detect(ctx context.Context) (*Metadata, error)
+}
+
+// ProviderOption configures optional Provider behavior.
+type ProviderOption func(*Provider)
+
+// WithTTL sets the duration detection results are cached for.
+func WithTTL(ttl time.Duration) ProviderOption {
+	return func(p *Provider) {
+		p.ttl = ttl
+	}
+}
+
+// WithDetectors replaces the default EC2, Azure, and GCE detectors.
+func WithDetectors(detectors ...Detector) ProviderOption {
+	return func(p *Provider) {
+		p.detectors = detectors
+	}
+}
+
+// Provider queries its detectors once per TTL and caches the result for all callers.
+// It's safe for concurrent use.
+type Provider struct {
+	now       func() time.Time
+	cached    *Metadata
+	err       error
+	expires   time.Time
+	detectors []Detector
+	ttl       time.Duration
+	lock      sync.Mutex
+}
+
+// NewProvider creates a Provider using the default detectors and TTL unless otherwise specified.
+func NewProvider(options ...ProviderOption) *Provider {
+	client := &http.Client{Timeout: DefaultTimeout}
+	p := &Provider{
+		now: time.Now,
+		ttl: DefaultTTL,
+		detectors: []Detector{
+			NewEC2Detector(client, defaultEC2Endpoint),
+			NewAzureDetector(client, defaultAzureEndpoint),
+			NewGCEDetector(client, defaultGCEEndpoint),
+		},
+	}
+	for _, option := range options {
+		option(p)
+	}
+	return p
+}
+
+var (
+	shared     *Provider
+	sharedOnce sync.Once
+)
+
+// Shared returns the process-wide Provider so that components don't query
+// metadata endpoints independently.
+func Shared() *Provider {
+	sharedOnce.Do(func() {
+		shared = NewProvider()
+	})
+	return shared
+}
+
+// Get returns the cached Metadata, detecting it if the cache is empty or expired.
+// Detectors are queried concurrently, and the first in order to succeed is used.
+func (p *Provider) Get(ctx context.Context) (*Metadata, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	if !p.expires.IsZero() && p.now().Before(p.expires) {
+		return p.cached, p.err
+	}
+	p.cached, p.err = p.detect(ctx)
+	p.expires = p.now().Add(p.ttl)
+	return p.cached, p.err
+}
+
+func (p *Provider) detect(ctx context.Context) (*Metadata, error) {
+	type result struct {
+		metadata *Metadata
+		err      error
+	}
+	results := make([]result, len(p.detectors))
+	var wg sync.WaitGroup
+	for i, detector := range p.detectors {
+		wg.Add(1)
+		go func(i int, detector Detector) {
+			defer wg.Done()
+			metadata, err := detector.Detect(ctx)
+			results[i] = result{metadata: metadata, err: err}
+		}(i, detector)
+	}
+	wg.Wait()
+
+	var errs []string
+	for i, r := range results {
+		if r.err == nil && r.metadata != nil {
+			return r.metadata, nil
+		}
+		if r.err != nil {
+			errs = append(errs, fmt.Sprintf("%s: %v", p.detectors[i].Name(), r.err))
+		}
+	}
+	if len(errs) == 0 {
+		return nil, ErrNotDetected
+	}
+	return nil, fmt.Errorf("%w (%s)", ErrNotDetected, strings.Join(errs, "; "))
+}
+
+// Lookup is a detection of the Provider's metadata running in the background, so that components
+// don't wait on the metadata endpoints, which time out off the cloud, when starting.
+type Lookup struct {
+	metadata *Metadata
+	err      error
+	done     chan struct{}
+}
+
+// Lookup starts retrieving the metadata in the background, from the cache if not expired.
+func (p *Provider) Lookup() *Lookup {
+	l := &Lookup{done: make(chan struct{})}
+	go func() {
+		defer close(l.done)
+		l.metadata, l.err = p.Get(context.Background())
+	}()
+	return l
+}
+
+// Done is closed once the lookup completes.
+func (l *Lookup) Done() <-chan struct{} {
+	return l.done
+}
+
+// Get returns the metadata once the lookup completes, and ErrPending until then.
+func (l *Lookup) Get() (*Metadata, error) {
+	select {
+	case <-l.done:
+		return l.metadata, l.err
+	default:
+		return nil, ErrPending
+	}
+}
@@ -0,0 +1,130 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostmetadata
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeDetector struct {
+	metadata *Metadata
+	err      error
+	name     string
+	calls    int32
+}
+
+func (d *fakeDetector) Name() string {
+	return d.name
+}
+
+func (d *fakeDetector) Detect(context.Context) (*Metadata, error) {
+	atomic.AddInt32(&d.calls, 1)
+	return d.metadata, d.err
+}
+
+func TestProviderUsesFirstSuccessfulDetectorInOrder(t *testing.T) {
+	failing := &fakeDetector{name: "failing", err: errors.New("unreachable")}
+	first := &fakeDetector{name: "first", metadata: &Metadata{CloudProvider: "first"}}
+	second := &fakeDetector{name: "second", metadata: &Metadata{CloudProvider: "second"}}
+	provider := NewProvider(WithDetectors(failing, first, second))
+
+	metadata, err := provider.Get(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, "first", metadata.CloudProvider)
+}
+
+func TestProviderCachesUntilTTLExpires(t *testing.T) {
+	detector := &fakeDetector{name: "fake", metadata: &Metadata{CloudProvider: "fake"}}
+	provider := NewProvider(WithDetectors(detector), WithTTL(time.Minute))
+	now := time.Now()
+	provider.now = func() time.Time { return now }
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			metadata, err := provider.Get(context.Background())
+			assert.NoError(t, err)
+			assert.Equal(t, "fake", metadata.CloudProvider)
+		}()
+	}
+	wg.Wait()
+	assert.EqualValues(t, 1, atomic.LoadInt32(&detector.calls))
+
+	now = now.Add(time.Minute)
+	_, err := provider.Get(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 2, atomic.LoadInt32(&detector.calls))
+}
+
+func TestProviderCachesDetectionFailures(t *testing.T) {
+	detector := &fakeDetector{name: "fake", err: errors.New("connection refused")}
+	provider := NewProvider(WithDetectors(detector))
+
+	for i := 0; i < 3; i++ {
+		metadata, err := provider.Get(context.Background())
+		require.ErrorIs(t, err, ErrNotDetected)
+		assert.Contains(t, err.Error(), "fake: connection refused")
+		assert.Nil(t, metadata)
+	}
+	assert.EqualValues(t, 1, atomic.LoadInt32(&detector.calls))
+}
+
+func TestProviderWithoutDetectors(t *testing.T) {
+	metadata, err := NewProvider(WithDetectors()).Get(context.Background())
+	require.Equal(t, ErrNotDetected, err)
+	require.Nil(t, metadata)
+}
+
+type blockingDetector struct {
+	fakeDetector
+	unblock chan struct{}
+}
+
+func (d *blockingDetector) Detect(ctx context.Context) (*Metadata, error) {
+	<-d.unblock
+	return d.fakeDetector.Detect(ctx)
+}
+
+func TestProviderLookup(t *testing.T) {
+	detector := &blockingDetector{
+		fakeDetector: fakeDetector{name: "fake", metadata: &Metadata{CloudProvider: "fake"}},
+		unblock:      make(chan struct{}),
+	}
+	lookup := NewProvider(WithDetectors(detector)).Lookup()
+
+	metadata, err := lookup.Get()
+	require.Equal(t, ErrPending, err)
+	require.Nil(t, metadata)
+
+	close(detector.unblock)
+	<-lookup.Done()
+	metadata, err = lookup.Get()
+	require.NoError(t, err)
+	assert.Equal(t, "fake", metadata.CloudProvider)
+}
+
+func TestSharedProvider(t *testing.T) {
+	assert.Same(t, Shared(), Shared())
+}
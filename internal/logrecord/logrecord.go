@@ -0,0 +1,105 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package logrecord provides the severity names, attribute patterns, and attribute lookups shared by the
+// log record processors.
+package logrecord
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+// Severities are the log record severity names, by their lowest severity number.
+var Severities = map[string]plog.SeverityNumber{
+	"TRACE": plog.SeverityNumberTRACE,
+	"DEBUG": plog.SeverityNumberDEBUG,
+	"INFO":  plog.SeverityNumberINFO,
+	"WARN":  plog.SeverityNumberWARN,
+	"ERROR": plog.SeverityNumberERROR,
+	"FATAL": plog.SeverityNumberFATAL,
+}
+
+// SeverityName returns the name of the severity range of the number, or "" if it's unspecified.
+func SeverityName(number plog.SeverityNumber) string {
+	switch {
+	case number >= plog.SeverityNumberFATAL:
+		return "FATAL"
+	case number >= plog.SeverityNumberERROR:
+		return "ERROR"
+	case number >= plog.SeverityNumberWARN:
+		return "WARN"
+	case number >= plog.SeverityNumberINFO:
+		return "INFO"
+	case number >= plog.SeverityNumberDEBUG:
+		return "DEBUG"
+	case number >= plog.SeverityNumberTRACE:
+		return "TRACE"
+	}
+	return ""
+}
+
+// AttributePatterns are the regular expressions the values of attributes must match, by attribute name.
+type AttributePatterns map[string]*regexp.Regexp
+
+// CompileAttributePatterns compiles the regular expressions of the attributes, failing on the first invalid
+// one in attribute name order.
+func CompileAttributePatterns(patterns map[string]string) (AttributePatterns, error) {
+	compiled := make(AttributePatterns, len(patterns))
+	for _, key := range SortedKeys(patterns) {
+		pattern, err := regexp.Compile(patterns[key])
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern for attribute %q: %w", key, err)
+		}
+		compiled[key] = pattern
+	}
+	return compiled, nil
+}
+
+// Match returns whether all the patterns' attributes are set to matching values.
+func (patterns AttributePatterns) Match(attrs pcommon.Map) bool {
+	for key, pattern := range patterns {
+		value, ok := attrs.Get(key)
+		if !ok || !pattern.MatchString(value.AsString()) {
+			return false
+		}
+	}
+	return true
+}
+
+// AttributeValue returns the value of the record's attribute, or of its resource's if the record doesn't
+// have it, or "" if neither does.
+func AttributeValue(resourceAttrs pcommon.Map, lr plog.LogRecord, key string) string {
+	if value, ok := lr.Attributes().Get(key); ok {
+		return value.AsString()
+	}
+	if value, ok := resourceAttrs.Get(key); ok {
+		return value.AsString()
+	}
+	return ""
+}
+
+// SortedKeys returns the sorted keys of the map, for validating its entries in a stable order.
+func SortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logrecord
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestSeverityName(t *testing.T) {
+	for number, expected := range map[plog.SeverityNumber]string{
+		plog.SeverityNumberUNDEFINED: "",
+		plog.SeverityNumberTRACE:     "TRACE",
+		plog.SeverityNumberDEBUG4:    "DEBUG",
+		plog.SeverityNumberINFO2:     "INFO",
+		plog.SeverityNumberWARN:      "WARN",
+		plog.SeverityNumberERROR3:    "ERROR",
+		plog.SeverityNumberFATAL4:    "FATAL",
+	} {
+		assert.Equal(t, expected, SeverityName(number))
+	}
+	for name, number := range Severities {
+		assert.Equal(t, name, SeverityName(number))
+	}
+}
+
+func TestAttributePatterns(t *testing.T) {
+	patterns, err := CompileAttributePatterns(map[string]string{"app": "^web-", "level": "error|fatal"})
+	require.NoError(t, err)
+
+	attrs := pcommon.NewMap()
+	attrs.InsertString("app", "web-frontend")
+	assert.False(t, patterns.Match(attrs))
+
+	attrs.InsertString("level", "error")
+	assert.True(t, patterns.Match(attrs))
+
+	attrs.UpsertString("app", "api")
+	assert.False(t, patterns.Match(attrs))
+
+	assert.True(t, AttributePatterns{}.Match(attrs))
+}
+
+func TestInvalidAttributePatterns(t *testing.T) {
+	patterns, err := CompileAttributePatterns(map[string]string{"b": "(", "a": "["})
+	assert.EqualError(t, err, "invalid pattern for attribute \"a\": error parsing regexp: missing closing ]: `[`")
+	assert.Nil(t, patterns)
+}
+
+func TestAttributeValue(t *testing.T) {
+	resourceAttrs := pcommon.NewMap()
+	resourceAttrs.InsertString("sourcetype", "resource")
+	lr := plog.NewLogRecord()
+	assert.Equal(t, "resource", AttributeValue(resourceAttrs, lr, "sourcetype"))
+
+	lr.Attributes().InsertInt("sourcetype", 1)
+	assert.Equal(t, "1", AttributeValue(resourceAttrs, lr, "sourcetype"))
+
+	assert.Empty(t, AttributeValue(resourceAttrs, lr, "index"))
+}
+
+func TestSortedKeys(t *testing.T) {
+	assert.Equal(t, []string{"a", "b", "c"}, SortedKeys(map[string]string{"c": "", "a": "", "b": ""}))
+	assert.Empty(t, SortedKeys(nil))
+}
@@ -0,0 +1,87 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	// actionDrop drops the datapoints of timeseries exceeding their metric's limit.
+	actionDrop = "drop"
+	// actionAggregate combines the datapoints of timeseries exceeding their metric's limit
+	// into a single overflow timeseries per metric.
+	actionAggregate = "aggregate"
+)
+
+// Config defines configuration for the cardinality limiter processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// DefaultLimit is the maximum number of active timeseries of each metric without its own limit.
+	// 0 disables limiting of those metrics.
+	DefaultLimit int `mapstructure:"default_limit"`
+	// Limits are the maximum numbers of active timeseries of specific metric names, taking
+	// precedence over DefaultLimit. 0 disables limiting of the metric.
+	Limits map[string]int `mapstructure:"limits"`
+	// Action is how the datapoints of timeseries exceeding their metric's limit are handled,
+	// either drop or aggregate.
+	Action string `mapstructure:"action"`
+	// Expiration is the duration after which a timeseries without datapoints is no longer active
+	// and doesn't count towards its metric's limit.
+	Expiration time.Duration `mapstructure:"expiration"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.DefaultLimit < 0 {
+		return fmt.Errorf("default_limit must not be negative, not %d", cfg.DefaultLimit)
+	}
+	metrics := make([]string, 0, len(cfg.Limits))
+	for metric := range cfg.Limits {
+		metrics = append(metrics, metric)
+	}
+	sort.Strings(metrics)
+	for _, metric := range metrics {
+		if metric == "" {
+			return errors.New("limits must not contain an empty metric name")
+		}
+		if limit := cfg.Limits[metric]; limit < 0 {
+			return fmt.Errorf("limit of metric %q must not be negative, not %d", metric, limit)
+		}
+	}
+	if cfg.Action != actionDrop && cfg.Action != actionAggregate {
+		return fmt.Errorf("unsupported action %q, must be %q or %q", cfg.Action, actionDrop, actionAggregate)
+	}
+	if cfg.Expiration <= 0 {
+		return fmt.Errorf("expiration must be positive, not %s", cfg.Expiration)
+	}
+	return nil
+}
+
+// limit returns the maximum number of active timeseries of the metric, 0 if it isn't limited.
+func (cfg *Config) limit(metric string) int {
+	if limit, ok := cfg.Limits[metric]; ok {
+		return limit
+	}
+	return cfg.DefaultLimit
+}
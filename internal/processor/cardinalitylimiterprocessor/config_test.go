@@ -0,0 +1,83 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		DefaultLimit:      500,
+		Limits: map[string]int{
+			"container_cpu_utilization": 5000,
+			"custom.requests":           50,
+			"debug.metric":              0,
+		},
+		Action:     "aggregate",
+		Expiration: 10 * time.Minute,
+	}, p1)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "invalid_default_limit.yaml", err: `default_limit must not be negative, not -1`},
+		{file: "invalid_limit.yaml", err: `limit of metric "custom.requests" must not be negative, not -5`},
+		{file: "invalid_action.yaml", err: `unsupported action "sample", must be "drop" or "aggregate"`},
+		{file: "invalid_expiration.yaml", err: `expiration must be positive, not 0s`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestConfigLimit(t *testing.T) {
+	cfg := &Config{DefaultLimit: 10, Limits: map[string]int{"limited": 2, "unlimited": 0}}
+	assert.Equal(t, 2, cfg.limit("limited"))
+	assert.Equal(t, 0, cfg.limit("unlimited"))
+	assert.Equal(t, 10, cfg.limit("other"))
+}
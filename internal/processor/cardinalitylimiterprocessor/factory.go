@@ -0,0 +1,81 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "cardinality_limiter"
+
+	defaultLimit      = 1000
+	defaultAction     = actionDrop
+	defaultExpiration = 5 * time.Minute
+)
+
+var (
+	processorCapabilities = consumer.Capabilities{MutatesData: true}
+	registerViewsOnce     sync.Once
+)
+
+// NewFactory creates a factory for the cardinality limiter processor.
+func NewFactory() component.ProcessorFactory {
+	return component.NewProcessorFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsProcessor(createMetricsProcessor),
+	)
+}
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		DefaultLimit:      defaultLimit,
+		Action:            defaultAction,
+		Expiration:        defaultExpiration,
+	}
+}
+
+func createMetricsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Metrics,
+) (component.MetricsProcessor, error) {
+	// the views are shared by all instances, whose measurements are distinguished by the processor tag
+	registerViewsOnce.Do(func() {
+		if err := view.Register(metricViews()...); err != nil {
+			params.Logger.Warn("failed registering cardinality limiter metric views", zap.Error(err))
+		}
+	})
+	proc := newLimiterProcessor(cfg.(*Config), params.Logger)
+	return processorhelper.NewMetricsProcessor(
+		cfg,
+		nextConsumer,
+		proc.processMetrics,
+		processorhelper.WithCapabilities(processorCapabilities),
+	)
+}
@@ -0,0 +1,44 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	processorKey = tag.MustNewKey("processor")
+	metricKey    = tag.MustNewKey("metric")
+	actionKey    = tag.MustNewKey("action")
+
+	mOverflowDatapoints = stats.Int64(
+		typeStr+"/overflow_datapoints", "Number of datapoints of timeseries exceeding their metric's cardinality limit", stats.UnitDimensionless,
+	)
+)
+
+// metricViews returns the views of the processor's metrics, which are reported by the collector's own telemetry.
+func metricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mOverflowDatapoints.Name(),
+			Description: mOverflowDatapoints.Description(),
+			Measure:     mOverflowDatapoints,
+			TagKeys:     []tag.Key{processorKey, metricKey, actionKey},
+			Aggregation: view.Sum(),
+		},
+	}
+}
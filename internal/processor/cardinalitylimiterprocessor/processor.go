@@ -0,0 +1,312 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"context"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+const (
+	// overflowAttribute marks the timeseries aggregating the datapoints of those exceeding their metric's limit.
+	overflowAttribute = "otel.metric.overflow"
+	// expirationCheckInterval is the minimum interval between expirations of inactive timeseries and metrics.
+	expirationCheckInterval = time.Second
+)
+
+// trackedMetric holds the last datapoint times of a limited metric's active timeseries.
+type trackedMetric struct {
+	timeseries     map[uint64]time.Time
+	lastSeen       time.Time
+	lastExpiration time.Time
+	overflowed     bool
+}
+
+type limiterProcessor struct {
+	cfg          *Config
+	processorTag string
+	logger       *zap.Logger
+
+	// lock guards the tracked metrics, which are updated by every batch
+	lock           sync.Mutex
+	metrics        map[string]*trackedMetric
+	lastExpiration time.Time
+	now            func() time.Time
+}
+
+func newLimiterProcessor(cfg *Config, logger *zap.Logger) *limiterProcessor {
+	return &limiterProcessor{
+		cfg:          cfg,
+		processorTag: cfg.ID().String(),
+		logger:       logger,
+		metrics:      map[string]*trackedMetric{},
+		now:          time.Now,
+	}
+}
+
+func (proc *limiterProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	proc.lock.Lock()
+	defer proc.lock.Unlock()
+
+	now := proc.now()
+	overflows := map[string]int64{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		resourceKey := attributesKey(rm.Resource().Attributes())
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			sms.At(j).Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				limit := proc.cfg.limit(metric.Name())
+				if limit == 0 {
+					return false
+				}
+				if overflowed := proc.limitMetric(metric, resourceKey, limit, now); overflowed > 0 {
+					overflows[metric.Name()] += overflowed
+				}
+				return dataPointCount(metric) == 0
+			})
+		}
+		sms.RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			return sm.Metrics().Len() == 0
+		})
+	}
+	rms.RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		return rm.ScopeMetrics().Len() == 0
+	})
+
+	proc.recordOverflows(ctx, overflows)
+	proc.expireMetrics(now)
+
+	if rms.Len() == 0 {
+		return md, processorhelper.ErrSkipProcessingData
+	}
+	return md, nil
+}
+
+// limitMetric removes the datapoints of the metric's timeseries that aren't admitted within its limit,
+// aggregating those of gauges and sums if configured, and returns their number.
+func (proc *limiterProcessor) limitMetric(metric pmetric.Metric, resourceKey string, limit int, now time.Time) int64 {
+	tracked, ok := proc.metrics[metric.Name()]
+	if !ok {
+		tracked = &trackedMetric{timeseries: map[uint64]time.Time{}}
+		proc.metrics[metric.Name()] = tracked
+	}
+	tracked.lastSeen = now
+	admit := func(attributes pcommon.Map) bool {
+		return proc.admit(tracked, timeseriesID(resourceKey, attributes), limit, now)
+	}
+
+	var overflowed int64
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		overflowed = proc.limitNumberDataPoints(metric.Gauge().DataPoints(), admit, combineGauge)
+	case pmetric.MetricDataTypeSum:
+		overflowed = proc.limitNumberDataPoints(metric.Sum().DataPoints(), admit, combineSum)
+	case pmetric.MetricDataTypeHistogram:
+		metric.Histogram().DataPoints().RemoveIf(func(dp pmetric.HistogramDataPoint) bool {
+			return !countAdmitted(admit(dp.Attributes()), &overflowed)
+		})
+	case pmetric.MetricDataTypeExponentialHistogram:
+		metric.ExponentialHistogram().DataPoints().RemoveIf(func(dp pmetric.ExponentialHistogramDataPoint) bool {
+			return !countAdmitted(admit(dp.Attributes()), &overflowed)
+		})
+	case pmetric.MetricDataTypeSummary:
+		metric.Summary().DataPoints().RemoveIf(func(dp pmetric.SummaryDataPoint) bool {
+			return !countAdmitted(admit(dp.Attributes()), &overflowed)
+		})
+	}
+
+	if overflowed > 0 && !tracked.overflowed {
+		tracked.overflowed = true
+		proc.logger.Warn(
+			"Metric exceeded its cardinality limit",
+			zap.String("metric", metric.Name()), zap.Int("limit", limit), zap.String("action", proc.cfg.Action),
+		)
+	}
+	return overflowed
+}
+
+// limitNumberDataPoints removes the datapoints that aren't admitted, combining them into a single
+// overflow datapoint if aggregating, and returns their number.
+func (proc *limiterProcessor) limitNumberDataPoints(
+	dps pmetric.NumberDataPointSlice, admit func(pcommon.Map) bool, combine func(overflow, dp pmetric.NumberDataPoint),
+) int64 {
+	var overflowed int64
+	var overflow pmetric.NumberDataPoint
+	dps.RemoveIf(func(dp pmetric.NumberDataPoint) bool {
+		if countAdmitted(admit(dp.Attributes()), &overflowed) {
+			return false
+		}
+		if proc.cfg.Action != actionAggregate {
+			return true
+		}
+		if overflowed == 1 {
+			overflow = pmetric.NewNumberDataPoint()
+			dp.CopyTo(overflow)
+		} else {
+			combine(overflow, dp)
+		}
+		return true
+	})
+	if overflowed > 0 && proc.cfg.Action == actionAggregate {
+		overflow.Attributes().Clear()
+		overflow.Attributes().UpsertBool(overflowAttribute, true)
+		overflow.CopyTo(dps.AppendEmpty())
+	}
+	return overflowed
+}
+
+// admit determines whether the timeseries is within its metric's limit, either by already being active
+// or by there being room for it, and marks it as active if so.
+func (proc *limiterProcessor) admit(tracked *trackedMetric, id uint64, limit int, now time.Time) bool {
+	if _, ok := tracked.timeseries[id]; ok || len(tracked.timeseries) < limit {
+		tracked.timeseries[id] = now
+		return true
+	}
+	if now.Sub(tracked.lastExpiration) < expirationCheckInterval {
+		return false
+	}
+	tracked.lastExpiration = now
+	expired := now.Add(-proc.cfg.Expiration)
+	for ts, lastSeen := range tracked.timeseries {
+		if !lastSeen.After(expired) {
+			delete(tracked.timeseries, ts)
+		}
+	}
+	if len(tracked.timeseries) < limit {
+		tracked.timeseries[id] = now
+		return true
+	}
+	return false
+}
+
+// expireMetrics stops tracking the metrics without datapoints for the expiration, whose timeseries
+// are all inactive, so that the tracked metrics don't grow with every metric name ever received.
+func (proc *limiterProcessor) expireMetrics(now time.Time) {
+	if now.Sub(proc.lastExpiration) < expirationCheckInterval {
+		return
+	}
+	proc.lastExpiration = now
+	expired := now.Add(-proc.cfg.Expiration)
+	for name, tracked := range proc.metrics {
+		if !tracked.lastSeen.After(expired) {
+			delete(proc.metrics, name)
+		}
+	}
+}
+
+func (proc *limiterProcessor) recordOverflows(ctx context.Context, overflows map[string]int64) {
+	for metric, overflowed := range overflows {
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(processorKey, proc.processorTag),
+			tag.Upsert(metricKey, metric),
+			tag.Upsert(actionKey, proc.cfg.Action),
+		}, mOverflowDatapoints.M(overflowed))
+	}
+}
+
+func countAdmitted(admitted bool, overflowed *int64) bool {
+	if !admitted {
+		*overflowed++
+	}
+	return admitted
+}
+
+// combineGauge keeps the latest value of the overflowing gauge datapoints.
+func combineGauge(overflow, dp pmetric.NumberDataPoint) {
+	if dp.Timestamp() >= overflow.Timestamp() {
+		dp.CopyTo(overflow)
+	}
+}
+
+// combineSum adds the values of the overflowing sum datapoints, spanning all of their time ranges.
+func combineSum(overflow, dp pmetric.NumberDataPoint) {
+	if overflow.ValueType() == pmetric.NumberDataPointValueTypeInt && dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		overflow.SetIntVal(overflow.IntVal() + dp.IntVal())
+	} else {
+		overflow.SetDoubleVal(numberValue(overflow) + numberValue(dp))
+	}
+	if dp.StartTimestamp() < overflow.StartTimestamp() {
+		overflow.SetStartTimestamp(dp.StartTimestamp())
+	}
+	if dp.Timestamp() > overflow.Timestamp() {
+		overflow.SetTimestamp(dp.Timestamp())
+	}
+}
+
+func numberValue(dp pmetric.NumberDataPoint) float64 {
+	if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+		return float64(dp.IntVal())
+	}
+	return dp.DoubleVal()
+}
+
+func dataPointCount(metric pmetric.Metric) int {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		return metric.Gauge().DataPoints().Len()
+	case pmetric.MetricDataTypeSum:
+		return metric.Sum().DataPoints().Len()
+	case pmetric.MetricDataTypeHistogram:
+		return metric.Histogram().DataPoints().Len()
+	case pmetric.MetricDataTypeExponentialHistogram:
+		return metric.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricDataTypeSummary:
+		return metric.Summary().DataPoints().Len()
+	}
+	return 0
+}
+
+// timeseriesID identifies a metric's timeseries by its resource and datapoint attributes.
+func timeseriesID(resourceKey string, attributes pcommon.Map) uint64 {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(resourceKey))
+	// separates the resource attributes from identical datapoint ones
+	_, _ = h.Write([]byte{1})
+	_, _ = h.Write([]byte(attributesKey(attributes)))
+	return h.Sum64()
+}
+
+// attributesKey provides a stable identity for a set of attributes.
+func attributesKey(attributes pcommon.Map) string {
+	keys := make([]string, 0, attributes.Len())
+	attributes.Range(func(k string, _ pcommon.Value) bool {
+		keys = append(keys, k)
+		return true
+	})
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		v, _ := attributes.Get(k)
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(v.AsString())
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
@@ -0,0 +1,245 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cardinalitylimiterprocessor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+func newTestProcessor(action string, limits map[string]int, now *time.Time) *limiterProcessor {
+	cfg := &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		DefaultLimit:      2,
+		Limits:            limits,
+		Action:            action,
+		Expiration:        time.Minute,
+	}
+	proc := newLimiterProcessor(cfg, zap.NewNop())
+	proc.now = func() time.Time { return *now }
+	return proc
+}
+
+// newGauges returns a gauge of the provided name with a datapoint of each host value.
+func newGauges(name string, hosts ...string) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("service.name", "my-service")
+	metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	for i, host := range hosts {
+		dp := metric.Gauge().DataPoints().AppendEmpty()
+		dp.Attributes().InsertString("host", host)
+		dp.SetTimestamp(pcommon.Timestamp(i + 1))
+		dp.SetDoubleVal(float64(i + 1))
+	}
+	return md
+}
+
+func newSums(name string, values ...int64) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(name)
+	metric.SetDataType(pmetric.MetricDataTypeSum)
+	metric.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
+	for i, value := range values {
+		dp := metric.Sum().DataPoints().AppendEmpty()
+		dp.Attributes().InsertString("customer", fmt.Sprintf("customer-%d", i))
+		dp.SetStartTimestamp(pcommon.Timestamp(10 - i))
+		dp.SetTimestamp(pcommon.Timestamp(20 + i))
+		dp.SetIntVal(value)
+	}
+	return md
+}
+
+func gaugeHosts(t *testing.T, md pmetric.Metrics) []string {
+	var hosts []string
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	for i := 0; i < dps.Len(); i++ {
+		host, ok := dps.At(i).Attributes().Get("host")
+		require.True(t, ok)
+		hosts = append(hosts, host.StringVal())
+	}
+	return hosts
+}
+
+func TestDropsTimeseriesExceedingLimit(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionDrop, nil, &now)
+
+	md, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "a", "b", "c", "d"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, gaugeHosts(t, md))
+
+	// active timeseries remain admitted in subsequent batches
+	md, err = proc.processMetrics(context.Background(), newGauges("cpu.utilization", "d", "b", "c", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "a"}, gaugeHosts(t, md))
+
+	// limits are per metric
+	md, err = proc.processMetrics(context.Background(), newGauges("memory.utilization", "c", "d"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "d"}, gaugeHosts(t, md))
+}
+
+func TestDropsAllTimeseries(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionDrop, map[string]int{"cpu.utilization": 1}, &now)
+
+	_, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "a"))
+	require.NoError(t, err)
+
+	md, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "b", "c"))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Equal(t, 0, md.ResourceMetrics().Len())
+}
+
+func TestUnlimitedMetrics(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionDrop, map[string]int{"cpu.utilization": 0}, &now)
+
+	md, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "a", "b", "c", "d"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b", "c", "d"}, gaugeHosts(t, md))
+	assert.Empty(t, proc.metrics)
+}
+
+func TestInactiveTimeseriesExpire(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionDrop, nil, &now)
+
+	md, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "a", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"a", "b"}, gaugeHosts(t, md))
+
+	now = now.Add(30 * time.Second)
+	md, err = proc.processMetrics(context.Background(), newGauges("cpu.utilization", "b", "c"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b"}, gaugeHosts(t, md))
+
+	// a hasn't had datapoints for the expiration, making room for c
+	now = now.Add(31 * time.Second)
+	md, err = proc.processMetrics(context.Background(), newGauges("cpu.utilization", "b", "c", "a"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"b", "c"}, gaugeHosts(t, md))
+}
+
+func TestInactiveMetricsExpire(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionDrop, nil, &now)
+
+	_, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "a", "b", "c"))
+	require.NoError(t, err)
+	now = now.Add(30 * time.Second)
+	_, err = proc.processMetrics(context.Background(), newGauges("memory.utilization", "a"))
+	require.NoError(t, err)
+	assert.Len(t, proc.metrics, 2)
+
+	// cpu.utilization hasn't had datapoints for the expiration
+	now = now.Add(31 * time.Second)
+	_, err = proc.processMetrics(context.Background(), newGauges("memory.utilization", "a"))
+	require.NoError(t, err)
+	assert.Len(t, proc.metrics, 1)
+	assert.Contains(t, proc.metrics, "memory.utilization")
+}
+
+func TestAggregatesGaugeTimeseriesExceedingLimit(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionAggregate, nil, &now)
+
+	md, err := proc.processMetrics(context.Background(), newGauges("cpu.utilization", "a", "b", "c", "d", "e"))
+	require.NoError(t, err)
+
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	overflow := dps.At(2)
+	assert.Equal(t, map[string]any{overflowAttribute: true}, overflow.Attributes().AsRaw())
+	// the latest value
+	assert.Equal(t, 5.0, overflow.DoubleVal())
+	assert.Equal(t, pcommon.Timestamp(5), overflow.Timestamp())
+}
+
+func TestAggregatesSumTimeseriesExceedingLimit(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionAggregate, nil, &now)
+
+	md, err := proc.processMetrics(context.Background(), newSums("requests", 1, 2, 3, 4, 5))
+	require.NoError(t, err)
+
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Sum().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	assert.Equal(t, int64(1), dps.At(0).IntVal())
+	assert.Equal(t, int64(2), dps.At(1).IntVal())
+	overflow := dps.At(2)
+	assert.Equal(t, map[string]any{overflowAttribute: true}, overflow.Attributes().AsRaw())
+	assert.Equal(t, pmetric.NumberDataPointValueTypeInt, overflow.ValueType())
+	assert.Equal(t, int64(12), overflow.IntVal())
+	assert.Equal(t, pcommon.Timestamp(6), overflow.StartTimestamp())
+	assert.Equal(t, pcommon.Timestamp(24), overflow.Timestamp())
+}
+
+func TestCombineSumMixedValueTypes(t *testing.T) {
+	overflow := pmetric.NewNumberDataPoint()
+	overflow.SetIntVal(2)
+	dp := pmetric.NewNumberDataPoint()
+	dp.SetDoubleVal(1.5)
+
+	combineSum(overflow, dp)
+	assert.Equal(t, pmetric.NumberDataPointValueTypeDouble, overflow.ValueType())
+	assert.Equal(t, 3.5, overflow.DoubleVal())
+}
+
+func TestDropsHistogramTimeseriesWhenAggregating(t *testing.T) {
+	now := time.Now()
+	proc := newTestProcessor(actionAggregate, map[string]int{"latency": 1}, &now)
+
+	md := pmetric.NewMetrics()
+	metric := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName("latency")
+	metric.SetDataType(pmetric.MetricDataTypeHistogram)
+	for _, route := range []string{"/a", "/b", "/c"} {
+		metric.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("route", route)
+	}
+
+	md, err := proc.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints()
+	require.Equal(t, 1, dps.Len())
+	assert.Equal(t, map[string]any{"route": "/a"}, dps.At(0).Attributes().AsRaw())
+}
+
+func TestTimeseriesIdentity(t *testing.T) {
+	attributes := pcommon.NewMap()
+	attributes.InsertString("b", "2")
+	attributes.InsertString("a", "1")
+	reordered := pcommon.NewMap()
+	reordered.InsertString("a", "1")
+	reordered.InsertString("b", "2")
+
+	assert.Equal(t, timeseriesID("", attributes), timeseriesID("", reordered))
+	assert.NotEqual(t, timeseriesID("", attributes), timeseriesID("host=a\x00", attributes))
+	assert.NotEqual(t, timeseriesID(attributesKey(attributes), pcommon.NewMap()), timeseriesID("", attributes))
+}
@@ -0,0 +1,123 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataclassificationprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	signalLogs    = "logs"
+	signalMetrics = "metrics"
+	signalTraces  = "traces"
+)
+
+// Config defines configuration for the data classification processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Routes maps classifications to the exporters their records are sent to.
+	Routes map[string][]string `mapstructure:"routes"`
+	// ClassificationAttribute is the record attribute set to the record's classification. Empty disables it.
+	ClassificationAttribute string `mapstructure:"classification_attribute"`
+	// DefaultClassification is the classification of the records matching no rule.
+	DefaultClassification string `mapstructure:"default_classification"`
+	// Rules are evaluated in order for every record, which is classified by the first matching rule.
+	Rules []Rule `mapstructure:"rules"`
+	// DefaultExporters are the exporters the records of classifications without routes are sent to.
+	// Those records are dropped without default exporters.
+	DefaultExporters []string `mapstructure:"default_exporters"`
+}
+
+// Rule classifies the records of its signals matching all of its patterns. A Rule without patterns
+// matches every record of its signals.
+type Rule struct {
+	// MatchAttributes maps attribute keys to regular expressions their string form must match. The
+	// attributes are looked up in the record attributes, then in the resource attributes.
+	MatchAttributes map[string]string `mapstructure:"match_attributes"`
+	// Classification is the classification of the matching records.
+	Classification string `mapstructure:"classification"`
+	// MatchBody is a regular expression the string form of the body of log records must match.
+	MatchBody string `mapstructure:"match_body"`
+	// Signals are the signals, logs, metrics, or traces, whose records the rule applies to. Empty is all of them.
+	Signals []string `mapstructure:"signals"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Rules) == 0 {
+		return errors.New("at least one rule must be provided")
+	}
+	if cfg.DefaultClassification == "" {
+		return errors.New("default_classification must not be empty")
+	}
+	if len(cfg.Routes) == 0 && len(cfg.DefaultExporters) == 0 {
+		return errors.New("at least one of routes or default_exporters must be provided")
+	}
+
+	classifications := map[string]bool{cfg.DefaultClassification: true}
+	for i, rule := range cfg.Rules {
+		if rule.Classification == "" {
+			return fmt.Errorf("rules[%d]: classification must not be empty", i)
+		}
+		classifications[rule.Classification] = true
+		for _, signal := range rule.Signals {
+			switch signal {
+			case signalLogs, signalMetrics, signalTraces:
+			default:
+				return fmt.Errorf("rules[%d]: unsupported signal %q, must be %q, %q, or %q", i, signal, signalLogs, signalMetrics, signalTraces)
+			}
+		}
+		for key, pattern := range rule.MatchAttributes {
+			if _, err := regexp.Compile(pattern); err != nil {
+				return fmt.Errorf("rules[%d]: invalid match_attributes pattern of %q: %w", i, key, err)
+			}
+		}
+		if _, err := regexp.Compile(rule.MatchBody); err != nil {
+			return fmt.Errorf("rules[%d]: invalid match_body pattern: %w", i, err)
+		}
+	}
+
+	for classification, exporters := range cfg.Routes {
+		if !classifications[classification] {
+			return fmt.Errorf("routes: %q is neither a rule classification nor the default_classification", classification)
+		}
+		if len(exporters) == 0 {
+			return fmt.Errorf("routes: the exporters of %q must not be empty", classification)
+		}
+		if err := validateExporters(exporters); err != nil {
+			return fmt.Errorf("routes: the exporters of %q: %w", classification, err)
+		}
+	}
+	if err := validateExporters(cfg.DefaultExporters); err != nil {
+		return fmt.Errorf("default_exporters: %w", err)
+	}
+	return nil
+}
+
+func validateExporters(exporters []string) error {
+	for _, exporter := range exporters {
+		if _, err := config.NewComponentIDFromString(exporter); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataclassificationprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.DefaultExporters = []string{"nop"}
+	expected.Rules = []Rule{{Classification: "apm", Signals: []string{"traces"}}}
+	assert.Equal(t, expected, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "security")]
+	assert.Equal(t, &Config{
+		ProcessorSettings:       config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "security")),
+		ClassificationAttribute: "classification",
+		DefaultClassification:   "infrastructure",
+		Rules: []Rule{
+			{
+				Classification:  "security",
+				Signals:         []string{"logs"},
+				MatchAttributes: map[string]string{"log.file.path": "^/var/log/(auth|secure|audit)"},
+			},
+			{
+				Classification: "security",
+				Signals:        []string{"logs"},
+				MatchBody:      "(?i)failed password",
+			},
+			{
+				Classification:  "apm",
+				Signals:         []string{"traces", "metrics"},
+				MatchAttributes: map[string]string{"telemetry.sdk.name": ".+"},
+			},
+		},
+		Routes: map[string][]string{
+			"security": {"nop/enterprise_security", "nop"},
+			"apm":      {"nop"},
+		},
+		DefaultExporters: []string{"nop"},
+	}, p1)
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "no rules",
+			modify: func(cfg *Config) { cfg.Rules = nil },
+			err:    "at least one rule must be provided",
+		},
+		{
+			name:   "no default classification",
+			modify: func(cfg *Config) { cfg.DefaultClassification = "" },
+			err:    "default_classification must not be empty",
+		},
+		{
+			name: "no exporters",
+			modify: func(cfg *Config) {
+				cfg.Routes = nil
+				cfg.DefaultExporters = nil
+			},
+			err: "at least one of routes or default_exporters must be provided",
+		},
+		{
+			name:   "empty classification",
+			modify: func(cfg *Config) { cfg.Rules[0].Classification = "" },
+			err:    "rules[0]: classification must not be empty",
+		},
+		{
+			name:   "unsupported signal",
+			modify: func(cfg *Config) { cfg.Rules[0].Signals = []string{"profiles"} },
+			err:    `rules[0]: unsupported signal "profiles", must be "logs", "metrics", or "traces"`,
+		},
+		{
+			name:   "invalid attribute pattern",
+			modify: func(cfg *Config) { cfg.Rules[0].MatchAttributes = map[string]string{"key": "("} },
+			err:    `rules[0]: invalid match_attributes pattern of "key": error parsing regexp: missing closing ): ` + "`(`",
+		},
+		{
+			name:   "invalid body pattern",
+			modify: func(cfg *Config) { cfg.Rules[0].MatchBody = "[" },
+			err:    "rules[0]: invalid match_body pattern: error parsing regexp: missing closing ]: `[`",
+		},
+		{
+			name:   "unknown route classification",
+			modify: func(cfg *Config) { cfg.Routes["audit"] = []string{"splunk_hec"} },
+			err:    `routes: "audit" is neither a rule classification nor the default_classification`,
+		},
+		{
+			name:   "empty route",
+			modify: func(cfg *Config) { cfg.Routes["security"] = nil },
+			err:    `routes: the exporters of "security" must not be empty`,
+		},
+		{
+			name:   "invalid exporter",
+			modify: func(cfg *Config) { cfg.DefaultExporters = []string{"/hec"} },
+			err:    `default_exporters: in "/hec" id: the part before / should not be empty`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := testConfig()
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			assert.EqualError(t, cfg.Validate(), test.err)
+		})
+	}
+}
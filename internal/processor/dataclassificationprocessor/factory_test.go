@@ -0,0 +1,61 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataclassificationprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+	assert.Equal(t, "com.splunk.classification", cfg.ClassificationAttribute)
+	assert.Equal(t, "unclassified", cfg.DefaultClassification)
+	assert.EqualError(t, cfg.Validate(), "at least one rule must be provided")
+}
+
+func TestCreateProcessors(t *testing.T) {
+	factory := NewFactory()
+	cfg := testConfig()
+	params := componenttest.NewNopProcessorCreateSettings()
+	host := componenttest.NewNopHost()
+
+	lp, err := factory.CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.True(t, lp.Capabilities().MutatesData)
+	require.NoError(t, lp.Start(context.Background(), host))
+	require.NoError(t, lp.Shutdown(context.Background()))
+
+	mp, err := factory.CreateMetricsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.True(t, mp.Capabilities().MutatesData)
+	require.NoError(t, mp.Start(context.Background(), host))
+	require.NoError(t, mp.Shutdown(context.Background()))
+
+	tp, err := factory.CreateTracesProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.True(t, tp.Capabilities().MutatesData)
+	require.NoError(t, tp.Start(context.Background(), host))
+	require.NoError(t, tp.Shutdown(context.Background()))
+}
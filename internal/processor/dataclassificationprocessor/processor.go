@@ -0,0 +1,362 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataclassificationprocessor
+
+import (
+	"context"
+	"regexp"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+type rule struct {
+	matchers       map[string]*regexp.Regexp
+	body           *regexp.Regexp
+	classification string
+}
+
+// classificationProcessor classifies the records of a signal and sends them to the exporters of their
+// classification instead of the next consumer, like the routing processor.
+type classificationProcessor struct {
+	logger *zap.Logger
+	// routes are the exporters of the classifications with routes, resolved on start
+	routes   map[string][]component.Exporter
+	cfg      *Config
+	dataType config.DataType
+	rules    []rule
+	defaults []component.Exporter
+}
+
+func newClassificationProcessor(cfg *Config, dataType config.DataType, logger *zap.Logger) *classificationProcessor {
+	proc := &classificationProcessor{logger: logger, cfg: cfg, dataType: dataType}
+	for _, r := range cfg.Rules {
+		if !appliesTo(r, dataType) {
+			continue
+		}
+		classifier := rule{classification: r.Classification, matchers: map[string]*regexp.Regexp{}}
+		for key, pattern := range r.MatchAttributes {
+			// the patterns are checked by Config.Validate
+			classifier.matchers[key] = regexp.MustCompile(pattern)
+		}
+		if r.MatchBody != "" {
+			classifier.body = regexp.MustCompile(r.MatchBody)
+		}
+		proc.rules = append(proc.rules, classifier)
+	}
+	return proc
+}
+
+func appliesTo(r Rule, dataType config.DataType) bool {
+	if len(r.Signals) == 0 {
+		return true
+	}
+	for _, signal := range r.Signals {
+		if config.DataType(signal) == dataType {
+			return true
+		}
+	}
+	return false
+}
+
+// start looks up the exporters of the routes among those of the processor's signal. Exporters of
+// other signals are skipped with a warning, so that the routes can be shared by the processor's pipelines.
+func (p *classificationProcessor) start(_ context.Context, host component.Host) error {
+	exporters := host.GetExporters()[p.dataType]
+	lookup := func(ids []string) []component.Exporter {
+		var found []component.Exporter
+		for _, id := range ids {
+			// the ids are checked by Config.Validate
+			componentID, _ := config.NewComponentIDFromString(id)
+			exporter, ok := exporters[componentID]
+			if !ok {
+				p.logger.Warn("Exporter not found in the pipelines of the signal, skipping it",
+					zap.String("exporter", id), zap.String("signal", string(p.dataType)))
+				continue
+			}
+			found = append(found, exporter)
+		}
+		return found
+	}
+
+	p.routes = map[string][]component.Exporter{}
+	for classification, ids := range p.cfg.Routes {
+		p.routes[classification] = lookup(ids)
+	}
+	p.defaults = lookup(p.cfg.DefaultExporters)
+	return nil
+}
+
+// classify returns the classification of the first rule matching the record, setting it as the record's
+// classification attribute, if any.
+func (p *classificationProcessor) classify(resourceAttrs, recordAttrs pcommon.Map, body *pcommon.Value) string {
+	classification := p.cfg.DefaultClassification
+	for _, r := range p.rules {
+		if r.matches(resourceAttrs, recordAttrs, body) {
+			classification = r.classification
+			break
+		}
+	}
+	if p.cfg.ClassificationAttribute != "" {
+		recordAttrs.UpsertString(p.cfg.ClassificationAttribute, classification)
+	}
+	return classification
+}
+
+func (r rule) matches(resourceAttrs, recordAttrs pcommon.Map, body *pcommon.Value) bool {
+	if r.body != nil && (body == nil || !r.body.MatchString(body.AsString())) {
+		return false
+	}
+	for key, matcher := range r.matchers {
+		value, ok := recordAttrs.Get(key)
+		if !ok {
+			value, ok = resourceAttrs.Get(key)
+		}
+		if !ok || !matcher.MatchString(value.AsString()) {
+			return false
+		}
+	}
+	return true
+}
+
+// exporters returns the exporters of the classification's route, or the default exporters without one.
+func (p *classificationProcessor) exporters(classification string) []component.Exporter {
+	if exporters, ok := p.routes[classification]; ok {
+		return exporters
+	}
+	return p.defaults
+}
+
+// classifications returns the distinct classifications in the order of their first record.
+func classifications(classes []string) []string {
+	seen := map[string]bool{}
+	var distinct []string
+	for _, classification := range classes {
+		if !seen[classification] {
+			seen[classification] = true
+			distinct = append(distinct, classification)
+		}
+	}
+	return distinct
+}
+
+// result returns the error of the exporters, if any, or ErrSkipProcessingData since the records aren't
+// passed on to the next consumer.
+func result(err error) error {
+	if err != nil {
+		return err
+	}
+	return processorhelper.ErrSkipProcessingData
+}
+
+func (p *classificationProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	var classes []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				body := lr.Body()
+				classes = append(classes, p.classify(rl.Resource().Attributes(), lr.Attributes(), &body))
+			}
+		}
+	}
+
+	distinct := classifications(classes)
+	var errs error
+	for _, classification := range distinct {
+		exporters := p.exporters(classification)
+		if len(exporters) == 0 {
+			p.logger.Debug("Dropping log records of a classification without exporters", zap.String("classification", classification))
+			continue
+		}
+		batch := ld
+		if len(distinct) > 1 {
+			batch = ld.Clone()
+			next := 0
+			batch.ResourceLogs().RemoveIf(func(rl plog.ResourceLogs) bool {
+				rl.ScopeLogs().RemoveIf(func(sl plog.ScopeLogs) bool {
+					sl.LogRecords().RemoveIf(func(plog.LogRecord) bool {
+						next++
+						return classes[next-1] != classification
+					})
+					return sl.LogRecords().Len() == 0
+				})
+				return rl.ScopeLogs().Len() == 0
+			})
+		}
+		for _, exporter := range exporters {
+			errs = multierr.Append(errs, exporter.(consumer.Logs).ConsumeLogs(ctx, batch))
+		}
+	}
+	return ld, result(errs)
+}
+
+func (p *classificationProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	var classes []string
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				classes = append(classes, p.classify(rs.Resource().Attributes(), spans.At(k).Attributes(), nil))
+			}
+		}
+	}
+
+	distinct := classifications(classes)
+	var errs error
+	for _, classification := range distinct {
+		exporters := p.exporters(classification)
+		if len(exporters) == 0 {
+			p.logger.Debug("Dropping spans of a classification without exporters", zap.String("classification", classification))
+			continue
+		}
+		batch := td
+		if len(distinct) > 1 {
+			batch = td.Clone()
+			next := 0
+			batch.ResourceSpans().RemoveIf(func(rs ptrace.ResourceSpans) bool {
+				rs.ScopeSpans().RemoveIf(func(ss ptrace.ScopeSpans) bool {
+					ss.Spans().RemoveIf(func(ptrace.Span) bool {
+						next++
+						return classes[next-1] != classification
+					})
+					return ss.Spans().Len() == 0
+				})
+				return rs.ScopeSpans().Len() == 0
+			})
+		}
+		for _, exporter := range exporters {
+			errs = multierr.Append(errs, exporter.(consumer.Traces).ConsumeTraces(ctx, batch))
+		}
+	}
+	return td, result(errs)
+}
+
+func (p *classificationProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	var classes []string
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				forEachDataPoint(metrics.At(k), func(attrs pcommon.Map) {
+					classes = append(classes, p.classify(rm.Resource().Attributes(), attrs, nil))
+				})
+			}
+		}
+	}
+
+	distinct := classifications(classes)
+	var errs error
+	for _, classification := range distinct {
+		exporters := p.exporters(classification)
+		if len(exporters) == 0 {
+			p.logger.Debug("Dropping datapoints of a classification without exporters", zap.String("classification", classification))
+			continue
+		}
+		batch := md
+		if len(distinct) > 1 {
+			batch = md.Clone()
+			next := 0
+			batch.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+				rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+					sm.Metrics().RemoveIf(func(m pmetric.Metric) bool {
+						return removeDataPoints(m, func() bool {
+							next++
+							return classes[next-1] != classification
+						}) == 0
+					})
+					return sm.Metrics().Len() == 0
+				})
+				return rm.ScopeMetrics().Len() == 0
+			})
+		}
+		for _, exporter := range exporters {
+			errs = multierr.Append(errs, exporter.(consumer.Metrics).ConsumeMetrics(ctx, batch))
+		}
+	}
+	return md, result(errs)
+}
+
+// forEachDataPoint calls the function with the attributes of each datapoint of the metric, in order.
+func forEachDataPoint(metric pmetric.Metric, fn func(attrs pcommon.Map)) {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			fn(dps.At(i).Attributes())
+		}
+	}
+}
+
+// removeDataPoints removes the datapoints of the metric for which remove returns true, called in the order
+// of forEachDataPoint, and returns the number of remaining datapoints.
+func removeDataPoints(metric pmetric.Metric, remove func() bool) int {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		metric.Gauge().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return remove() })
+		return metric.Gauge().DataPoints().Len()
+	case pmetric.MetricDataTypeSum:
+		metric.Sum().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return remove() })
+		return metric.Sum().DataPoints().Len()
+	case pmetric.MetricDataTypeHistogram:
+		metric.Histogram().DataPoints().RemoveIf(func(pmetric.HistogramDataPoint) bool { return remove() })
+		return metric.Histogram().DataPoints().Len()
+	case pmetric.MetricDataTypeExponentialHistogram:
+		metric.ExponentialHistogram().DataPoints().RemoveIf(func(pmetric.ExponentialHistogramDataPoint) bool { return remove() })
+		return metric.ExponentialHistogram().DataPoints().Len()
+	case pmetric.MetricDataTypeSummary:
+		metric.Summary().DataPoints().RemoveIf(func(pmetric.SummaryDataPoint) bool { return remove() })
+		return metric.Summary().DataPoints().Len()
+	}
+	return 0
+}
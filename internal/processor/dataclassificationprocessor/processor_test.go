@@ -0,0 +1,327 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package dataclassificationprocessor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Rules = []Rule{
+		{
+			Classification:  "security",
+			Signals:         []string{"logs"},
+			MatchAttributes: map[string]string{"log.file.path": "^/var/log/(auth|secure)"},
+		},
+		{
+			Classification: "security",
+			Signals:        []string{"logs"},
+			MatchBody:      "(?i)failed password",
+		},
+		{
+			Classification:  "apm",
+			Signals:         []string{"traces", "metrics"},
+			MatchAttributes: map[string]string{"telemetry.sdk.name": ".+"},
+		},
+	}
+	cfg.Routes = map[string][]string{
+		"security": {"splunk_hec/security", "splunk_hec"},
+		"apm":      {"splunk_hec/apm"},
+	}
+	cfg.DefaultExporters = []string{"splunk_hec"}
+	return cfg
+}
+
+type logsExporter struct {
+	consumer.Logs
+}
+
+func (logsExporter) Start(context.Context, component.Host) error { return nil }
+
+func (logsExporter) Shutdown(context.Context) error { return nil }
+
+type metricsExporter struct {
+	consumer.Metrics
+}
+
+func (metricsExporter) Start(context.Context, component.Host) error { return nil }
+
+func (metricsExporter) Shutdown(context.Context) error { return nil }
+
+type tracesExporter struct {
+	consumer.Traces
+}
+
+func (tracesExporter) Start(context.Context, component.Host) error { return nil }
+
+func (tracesExporter) Shutdown(context.Context) error { return nil }
+
+type exportersHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h exportersHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func newHost(dataType config.DataType, exporters map[config.ComponentID]component.Exporter) component.Host {
+	return exportersHost{
+		Host:      componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{dataType: exporters},
+	}
+}
+
+// logBodies returns the bodies of the log records of each batch, with their classification attribute.
+func logBodies(batches []plog.Logs) [][]string {
+	var bodies [][]string
+	for _, ld := range batches {
+		var batch []string
+		rls := ld.ResourceLogs()
+		for i := 0; i < rls.Len(); i++ {
+			sls := rls.At(i).ScopeLogs()
+			for j := 0; j < sls.Len(); j++ {
+				lrs := sls.At(j).LogRecords()
+				for k := 0; k < lrs.Len(); k++ {
+					lr := lrs.At(k)
+					classification, _ := lr.Attributes().Get("com.splunk.classification")
+					batch = append(batch, lr.Body().AsString()+"|"+classification.AsString())
+				}
+			}
+		}
+		bodies = append(bodies, batch)
+	}
+	return bodies
+}
+
+func TestProcessLogs(t *testing.T) {
+	proc := newClassificationProcessor(testConfig(), config.LogsDataType, zap.NewNop())
+	security := new(consumertest.LogsSink)
+	hec := new(consumertest.LogsSink)
+	require.NoError(t, proc.start(context.Background(), newHost(config.LogsDataType, map[config.ComponentID]component.Exporter{
+		config.NewComponentID("splunk_hec"):                     logsExporter{hec},
+		config.NewComponentIDWithName("splunk_hec", "security"): logsExporter{security},
+	})))
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("log.file.path", "/var/log/auth.log")
+	rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Body().SetStringVal("session opened for user root")
+	rl = ld.ResourceLogs().AppendEmpty()
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Body().SetStringVal("GET /cart 200")
+	lr := lrs.AppendEmpty()
+	lr.Body().SetStringVal("Failed password for root")
+	lr.Attributes().InsertString("log.file.path", "/var/log/nginx/access.log")
+
+	_, err := proc.processLogs(context.Background(), ld)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+
+	assert.Equal(t, [][]string{
+		{"session opened for user root|security", "Failed password for root|security"},
+	}, logBodies(security.AllLogs()))
+	assert.Equal(t, [][]string{
+		{"session opened for user root|security", "Failed password for root|security"},
+		{"GET /cart 200|unclassified"},
+	}, logBodies(hec.AllLogs()))
+	// the split batches keep the resources of their records
+	attr, ok := security.AllLogs()[0].ResourceLogs().At(0).Resource().Attributes().Get("log.file.path")
+	require.True(t, ok)
+	assert.Equal(t, "/var/log/auth.log", attr.StringVal())
+	assert.Equal(t, 1, hec.AllLogs()[1].ResourceLogs().Len())
+}
+
+func TestProcessTraces(t *testing.T) {
+	proc := newClassificationProcessor(testConfig(), config.TracesDataType, zap.NewNop())
+	apm := new(consumertest.TracesSink)
+	hec := new(consumertest.TracesSink)
+	require.NoError(t, proc.start(context.Background(), newHost(config.TracesDataType, map[config.ComponentID]component.Exporter{
+		config.NewComponentID("splunk_hec"):                tracesExporter{hec},
+		config.NewComponentIDWithName("splunk_hec", "apm"): tracesExporter{apm},
+	})))
+
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("telemetry.sdk.name", "opentelemetry")
+	spans := rs.ScopeSpans().AppendEmpty().Spans()
+	spans.AppendEmpty().SetName("checkout")
+	spans.AppendEmpty().SetName("payment")
+
+	_, err := proc.processTraces(context.Background(), td)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Empty(t, hec.AllTraces())
+	require.Len(t, apm.AllTraces(), 1)
+	// a single classification is sent as is
+	assert.Equal(t, td, apm.AllTraces()[0])
+	classification, _ := spans.At(1).Attributes().Get("com.splunk.classification")
+	assert.Equal(t, "apm", classification.StringVal())
+
+	// the logs rules don't apply to spans
+	td = ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("log.file.path", "/var/log/auth.log")
+	_, err = proc.processTraces(context.Background(), td)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Len(t, apm.AllTraces(), 1)
+	require.Len(t, hec.AllTraces(), 1)
+	classification, _ = span.Attributes().Get("com.splunk.classification")
+	assert.Equal(t, "unclassified", classification.StringVal())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	proc := newClassificationProcessor(testConfig(), config.MetricsDataType, zap.NewNop())
+	apm := new(consumertest.MetricsSink)
+	hec := new(consumertest.MetricsSink)
+	require.NoError(t, proc.start(context.Background(), newHost(config.MetricsDataType, map[config.ComponentID]component.Exporter{
+		config.NewComponentID("splunk_hec"):                metricsExporter{hec},
+		config.NewComponentIDWithName("splunk_hec", "apm"): metricsExporter{apm},
+	})))
+
+	md := pmetric.NewMetrics()
+	ms := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+	gauge := ms.AppendEmpty()
+	gauge.SetName("requests.active")
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	dp := gauge.Gauge().DataPoints().AppendEmpty()
+	dp.SetIntVal(1)
+	dp.Attributes().InsertString("telemetry.sdk.name", "opentelemetry")
+	gauge.Gauge().DataPoints().AppendEmpty().SetIntVal(2)
+	sum := ms.AppendEmpty()
+	sum.SetName("cpu.time")
+	sum.SetDataType(pmetric.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty().SetDoubleVal(3)
+
+	_, err := proc.processMetrics(context.Background(), md)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+
+	require.Len(t, apm.AllMetrics(), 1)
+	apmMetrics := apm.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, apmMetrics.Len())
+	assert.Equal(t, "requests.active", apmMetrics.At(0).Name())
+	require.Equal(t, 1, apmMetrics.At(0).Gauge().DataPoints().Len())
+	assert.EqualValues(t, 1, apmMetrics.At(0).Gauge().DataPoints().At(0).IntVal())
+
+	require.Len(t, hec.AllMetrics(), 1)
+	assert.Equal(t, 2, hec.AllMetrics()[0].DataPointCount())
+	hecMetrics := hec.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, hecMetrics.Len())
+	assert.EqualValues(t, 2, hecMetrics.At(0).Gauge().DataPoints().At(0).IntVal())
+	classification, _ := hecMetrics.At(1).Sum().DataPoints().At(0).Attributes().Get("com.splunk.classification")
+	assert.Equal(t, "unclassified", classification.StringVal())
+}
+
+func TestDropClassificationWithoutExporters(t *testing.T) {
+	cfg := testConfig()
+	cfg.DefaultExporters = nil
+	cfg.ClassificationAttribute = ""
+	proc := newClassificationProcessor(cfg, config.LogsDataType, zap.NewNop())
+	security := new(consumertest.LogsSink)
+	require.NoError(t, proc.start(context.Background(), newHost(config.LogsDataType, map[config.ComponentID]component.Exporter{
+		config.NewComponentIDWithName("splunk_hec", "security"): logsExporter{security},
+	})))
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Body().SetStringVal("failed password for admin")
+	lrs.AppendEmpty().Body().SetStringVal("GET /cart 200")
+
+	_, err := proc.processLogs(context.Background(), ld)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	require.Len(t, security.AllLogs(), 1)
+	require.Equal(t, 1, security.AllLogs()[0].LogRecordCount())
+	lr := security.AllLogs()[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "failed password for admin", lr.Body().StringVal())
+	// without classification attribute
+	assert.Equal(t, 0, lr.Attributes().Len())
+}
+
+func TestMissingExporter(t *testing.T) {
+	core, logs := observer.New(zapcore.WarnLevel)
+	proc := newClassificationProcessor(testConfig(), config.TracesDataType, zap.New(core))
+	hec := new(consumertest.TracesSink)
+	require.NoError(t, proc.start(context.Background(), newHost(config.TracesDataType, map[config.ComponentID]component.Exporter{
+		config.NewComponentID("splunk_hec"): tracesExporter{hec},
+	})))
+
+	require.Equal(t, 2, logs.Len())
+	for _, entry := range logs.All() {
+		assert.Equal(t, "Exporter not found in the pipelines of the signal, skipping it", entry.Message)
+		assert.Equal(t, "traces", entry.ContextMap()["signal"])
+	}
+	assert.ElementsMatch(t, []any{"splunk_hec/apm", "splunk_hec/security"},
+		[]any{logs.All()[0].ContextMap()["exporter"], logs.All()[1].ContextMap()["exporter"]})
+
+	// the spans of a route without exporters are dropped instead of sent to the default exporters
+	td := ptrace.NewTraces()
+	rs := td.ResourceSpans().AppendEmpty()
+	rs.Resource().Attributes().InsertString("telemetry.sdk.name", "opentelemetry")
+	rs.ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetName("checkout")
+	_, err := proc.processTraces(context.Background(), td)
+	require.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Empty(t, hec.AllTraces())
+}
+
+func TestExporterErrors(t *testing.T) {
+	proc := newClassificationProcessor(testConfig(), config.LogsDataType, zap.NewNop())
+	security := new(consumertest.LogsSink)
+	require.NoError(t, proc.start(context.Background(), newHost(config.LogsDataType, map[config.ComponentID]component.Exporter{
+		config.NewComponentID("splunk_hec"):                     logsExporter{consumertest.NewErr(errors.New("hec unavailable"))},
+		config.NewComponentIDWithName("splunk_hec", "security"): logsExporter{security},
+	})))
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Body().SetStringVal("failed password for admin")
+	lrs.AppendEmpty().Body().SetStringVal("GET /cart 200")
+
+	_, err := proc.processLogs(context.Background(), ld)
+	require.EqualError(t, err, "hec unavailable; hec unavailable")
+	// the other exporters still get their records
+	require.Len(t, security.AllLogs(), 1)
+}
+
+func TestClassifyResourceAttributes(t *testing.T) {
+	proc := newClassificationProcessor(testConfig(), config.LogsDataType, zap.NewNop())
+	resourceAttrs := pcommon.NewMap()
+	resourceAttrs.InsertString("log.file.path", "/var/log/secure")
+	recordAttrs := pcommon.NewMap()
+	body := pcommon.NewValueString("sshd started")
+	assert.Equal(t, "security", proc.classify(resourceAttrs, recordAttrs, &body))
+
+	// record attributes take precedence over resource ones
+	recordAttrs.InsertString("log.file.path", "/var/log/syslog")
+	assert.Equal(t, "unclassified", proc.classify(resourceAttrs, recordAttrs, &body))
+	classification, _ := recordAttrs.Get("com.splunk.classification")
+	assert.Equal(t, "unclassified", classification.StringVal())
+}
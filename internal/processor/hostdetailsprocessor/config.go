@@ -0,0 +1,38 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdetailsprocessor
+
+import (
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the host details processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Override determines whether the detected attributes replace existing resource attributes of the same keys.
+	Override bool `mapstructure:"override"`
+
+	// CloudMetadata determines whether the cloud provider and host attributes of the shared cloud metadata are
+	// added, without replacing existing resource attributes of the same keys.
+	CloudMetadata bool `mapstructure:"cloud_metadata"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	return nil
+}
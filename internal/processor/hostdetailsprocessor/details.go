@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdetailsprocessor
+
+import (
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/smartagentextension"
+)
+
+const (
+	attributeVirtualizationType = "host.virtualization.type"
+	attributeMachineID          = "host.machine.id"
+	attributeHardwareModel      = "host.hardware.model"
+
+	// virtualizationNone is the virtualization type of bare metal hosts.
+	virtualizationNone = "none"
+	// virtualizationOther is the virtualization type of hosts running on an unrecognized hypervisor.
+	virtualizationOther = "other"
+)
+
+// hostDetails are the details of the host the Collector runs on, empty when not detected.
+type hostDetails struct {
+	VirtualizationType string
+	MachineID          string
+	HardwareModel      string
+}
+
+// attributes returns the resource attributes of the detected details.
+func (d hostDetails) attributes() map[string]string {
+	attrs := map[string]string{}
+	for key, value := range map[string]string{
+		attributeVirtualizationType: d.VirtualizationType,
+		attributeMachineID:          d.MachineID,
+		attributeHardwareModel:      d.HardwareModel,
+	} {
+		if value != "" {
+			attrs[key] = value
+		}
+	}
+	return attrs
+}
+
+// hostPaths are the locations of the host's filesystems, which are mounted elsewhere in containers.
+type hostPaths struct {
+	etcPath  string
+	procPath string
+	sysPath  string
+	varPath  string
+}
+
+// hostPathsFromExtensions returns the etcPath, procPath, sysPath, and varPath of the first smartagent
+// extension, which are shared with its receivers, or their defaults when there's none.
+func hostPathsFromExtensions(extensions map[config.ComponentID]component.Extension) hostPaths {
+	factory := smartagentextension.NewFactory()
+	saConfig := &factory.CreateDefaultConfig().(*smartagentextension.Config).Config
+	for id, ext := range extensions {
+		if id.Type() != factory.Type() {
+			continue
+		}
+		if provider, ok := ext.(smartagentextension.SmartAgentConfigProvider); ok {
+			saConfig = provider.SmartAgentConfig()
+			break
+		}
+	}
+	return hostPaths{
+		etcPath: saConfig.EtcPath, procPath: saConfig.ProcPath, sysPath: saConfig.SysPath, varPath: saConfig.VarPath,
+	}
+}
+
+var (
+	// detect is the platform specific detection, replaceable in tests.
+	detect = detectHostDetails
+
+	detectOnce sync.Once
+	detected   hostDetails
+)
+
+// sharedHostDetails detects the host details once per process, since they don't change
+// during its lifetime, sharing them between all processor instances.
+func sharedHostDetails(logger *zap.Logger, paths hostPaths) hostDetails {
+	detectOnce.Do(func() {
+		var err error
+		if detected, err = detect(paths); err != nil {
+			// partial details are still useful, so the error is only logged
+			logger.Warn("failed detecting some host details", zap.Error(err))
+		}
+		logger.Debug("detected host details",
+			zap.String(attributeVirtualizationType, detected.VirtualizationType),
+			zap.String(attributeMachineID, detected.MachineID),
+			zap.String(attributeHardwareModel, detected.HardwareModel),
+		)
+	})
+	return detected
+}
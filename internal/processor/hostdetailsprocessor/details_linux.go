@@ -0,0 +1,182 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package hostdetailsprocessor
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"go.uber.org/multierr"
+)
+
+// The locations of the detected values, relative to the etc, var, sys, and proc host paths.
+var (
+	// machineIDFile is the systemd machine id, with the dbus one as fallback for older distributions.
+	machineIDFile       = "machine-id"
+	dbusMachineIDFile   = filepath.Join("lib", "dbus", "machine-id")
+	dmiDir              = filepath.Join("class", "dmi", "id")
+	deviceTreeModelFile = filepath.Join("device-tree", "model")
+	hypervisorTypeFile  = filepath.Join("hypervisor", "type")
+	cpuinfoFile         = "cpuinfo"
+)
+
+// dmiVendors maps the prefixes of DMI vendor and product values to virtualization types,
+// following the names used by systemd-detect-virt.
+var dmiVendors = []struct {
+	prefix             string
+	virtualizationType string
+}{
+	{prefix: "KVM", virtualizationType: "kvm"},
+	{prefix: "OpenStack", virtualizationType: "kvm"},
+	{prefix: "KubeVirt", virtualizationType: "kvm"},
+	{prefix: "Google Compute Engine", virtualizationType: "kvm"},
+	{prefix: "Amazon EC2", virtualizationType: "amazon"},
+	{prefix: "QEMU", virtualizationType: "qemu"},
+	{prefix: "VMware", virtualizationType: "vmware"},
+	{prefix: "VMW", virtualizationType: "vmware"},
+	{prefix: "innotek GmbH", virtualizationType: "oracle"},
+	{prefix: "VirtualBox", virtualizationType: "oracle"},
+	{prefix: "Xen", virtualizationType: "xen"},
+	{prefix: "Bochs", virtualizationType: "bochs"},
+	{prefix: "Parallels", virtualizationType: "parallels"},
+	{prefix: "BHYVE", virtualizationType: "bhyve"},
+	{prefix: "Hyper-V", virtualizationType: "microsoft"},
+}
+
+// dmiFiles are the DMI values checked for known vendors, in order.
+var dmiFiles = []string{"product_name", "sys_vendor", "board_vendor", "bios_vendor", "product_version"}
+
+func detectHostDetails(paths hostPaths) (hostDetails, error) {
+	var details hostDetails
+	var errs error
+
+	machineID, err := readFirst([]string{
+		filepath.Join(paths.etcPath, machineIDFile), filepath.Join(paths.varPath, dbusMachineIDFile),
+	})
+	errs = multierr.Append(errs, err)
+	details.MachineID = machineID
+
+	dmi := map[string]string{}
+	for _, name := range dmiFiles {
+		value, err := readValue(filepath.Join(paths.sysPath, dmiDir, name))
+		errs = multierr.Append(errs, err)
+		dmi[name] = value
+	}
+
+	details.HardwareModel = dmi["product_name"]
+	if details.HardwareModel == "" {
+		// hosts without DMI, like most ARM boards, describe their model in the device tree
+		model, err := readValue(filepath.Join(paths.procPath, deviceTreeModelFile))
+		errs = multierr.Append(errs, err)
+		details.HardwareModel = strings.TrimRight(model, "\x00")
+	}
+
+	hypervisorType, err := readValue(filepath.Join(paths.sysPath, hypervisorTypeFile))
+	errs = multierr.Append(errs, err)
+	cpuHypervisor, err := hasHypervisorFlag(filepath.Join(paths.procPath, cpuinfoFile))
+	errs = multierr.Append(errs, err)
+	details.VirtualizationType = virtualizationType(dmi, hypervisorType, cpuHypervisor)
+
+	return details, errs
+}
+
+// virtualizationType determines the virtualization type from the DMI values, the hypervisor
+// type exposed by Xen, and whether the CPU reports running on a hypervisor.
+func virtualizationType(dmi map[string]string, hypervisorType string, cpuHypervisor bool) string {
+	for _, name := range dmiFiles {
+		value := dmi[name]
+		if value == "" {
+			continue
+		}
+		for _, vendor := range dmiVendors {
+			if strings.HasPrefix(value, vendor.prefix) {
+				return vendor.virtualizationType
+			}
+		}
+		// Hyper-V guests are only distinguishable from Microsoft hardware by their product name
+		if name == "sys_vendor" && value == "Microsoft Corporation" && dmi["product_name"] == "Virtual Machine" {
+			return "microsoft"
+		}
+	}
+	if hypervisorType == "xen" {
+		return "xen"
+	}
+	if cpuHypervisor {
+		return virtualizationOther
+	}
+	return virtualizationNone
+}
+
+// readFirst reads the value of the first existing file of the provided paths.
+func readFirst(paths []string) (string, error) {
+	for _, path := range paths {
+		content, err := os.ReadFile(filepath.Clean(path))
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return "", fmt.Errorf("failed reading %s: %w", path, err)
+		}
+		if value := strings.TrimSpace(string(content)); value != "" {
+			return value, nil
+		}
+	}
+	return "", nil
+}
+
+// readValue reads the trimmed value of the file, empty if it doesn't exist.
+func readValue(path string) (string, error) {
+	return readFirst([]string{path})
+}
+
+// hasHypervisorFlag determines whether the cpuinfo flags include the hypervisor one,
+// set by the CPU on all x86 guests.
+func hasHypervisorFlag(path string) (bool, error) {
+	file, err := os.Open(filepath.Clean(path))
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed reading %s: %w", path, err)
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		key, value, found := strings.Cut(scanner.Text(), ":")
+		if !found || strings.TrimSpace(key) != "flags" {
+			continue
+		}
+		for _, flag := range strings.Fields(value) {
+			if flag == "hypervisor" {
+				return true, nil
+			}
+		}
+		// all processors report the same flags
+		return false, nil
+	}
+	if err = scanner.Err(); err != nil {
+		return false, fmt.Errorf("failed reading %s: %w", path, err)
+	}
+	return false, nil
+}
@@ -0,0 +1,113 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package hostdetailsprocessor
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVirtualizationType(t *testing.T) {
+	for _, test := range []struct {
+		name           string
+		dmi            map[string]string
+		hypervisorType string
+		cpuHypervisor  bool
+		expected       string
+	}{
+		{name: "bare metal", dmi: map[string]string{"sys_vendor": "Dell Inc.", "product_name": "PowerEdge R640"}, expected: "none"},
+		{name: "kvm", dmi: map[string]string{"sys_vendor": "QEMU", "product_name": "KVM"}, cpuHypervisor: true, expected: "kvm"},
+		{name: "qemu", dmi: map[string]string{"sys_vendor": "QEMU", "product_name": "Standard PC (Q35 + ICH9, 2009)"}, cpuHypervisor: true, expected: "qemu"},
+		{name: "vmware", dmi: map[string]string{"sys_vendor": "VMware, Inc.", "product_name": "VMware Virtual Platform"}, cpuHypervisor: true, expected: "vmware"},
+		{name: "virtualbox", dmi: map[string]string{"sys_vendor": "innotek GmbH", "product_name": "VirtualBox"}, cpuHypervisor: true, expected: "oracle"},
+		{name: "ec2", dmi: map[string]string{"sys_vendor": "Amazon EC2", "product_name": "m5.large"}, cpuHypervisor: true, expected: "amazon"},
+		{name: "gce", dmi: map[string]string{"sys_vendor": "Google", "product_name": "Google Compute Engine"}, cpuHypervisor: true, expected: "kvm"},
+		{name: "hyper-v", dmi: map[string]string{"sys_vendor": "Microsoft Corporation", "product_name": "Virtual Machine"}, cpuHypervisor: true, expected: "microsoft"},
+		{name: "surface", dmi: map[string]string{"sys_vendor": "Microsoft Corporation", "product_name": "Surface Laptop 4"}, expected: "none"},
+		{name: "xen pv", dmi: map[string]string{}, hypervisorType: "xen", cpuHypervisor: true, expected: "xen"},
+		{name: "unknown hypervisor", dmi: map[string]string{"sys_vendor": "ACME"}, cpuHypervisor: true, expected: "other"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.Equal(t, test.expected, virtualizationType(test.dmi, test.hypervisorType, test.cpuHypervisor))
+		})
+	}
+}
+
+func writeFile(t *testing.T, path, content string) string {
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o700))
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+	return path
+}
+
+// testPaths returns host paths within a temporary directory.
+func testPaths(t *testing.T) (string, hostPaths) {
+	dir := t.TempDir()
+	return dir, hostPaths{
+		etcPath:  filepath.Join(dir, "etc"),
+		procPath: filepath.Join(dir, "proc"),
+		sysPath:  filepath.Join(dir, "sys"),
+		varPath:  filepath.Join(dir, "var"),
+	}
+}
+
+func TestDetectHostDetails(t *testing.T) {
+	dir, paths := testPaths(t)
+	writeFile(t, filepath.Join(dir, "etc", "machine-id"), "0123456789abcdef0123456789abcdef\n")
+	writeFile(t, filepath.Join(dir, "sys", "class", "dmi", "id", "sys_vendor"), "VMware, Inc.\n")
+	writeFile(t, filepath.Join(dir, "sys", "class", "dmi", "id", "product_name"), "VMware Virtual Platform\n")
+	writeFile(t, filepath.Join(dir, "proc", "cpuinfo"), "processor\t: 0\nflags\t\t: fpu vme de pse hypervisor lahf_lm\n")
+
+	details, err := detectHostDetails(paths)
+	require.NoError(t, err)
+	assert.Equal(t, hostDetails{
+		VirtualizationType: "vmware",
+		MachineID:          "0123456789abcdef0123456789abcdef",
+		HardwareModel:      "VMware Virtual Platform",
+	}, details)
+}
+
+func TestDetectHostDetailsFallbacks(t *testing.T) {
+	dir, paths := testPaths(t)
+	writeFile(t, filepath.Join(dir, "var", "lib", "dbus", "machine-id"), "fedcba9876543210fedcba9876543210\n")
+	writeFile(t, filepath.Join(dir, "proc", "device-tree", "model"), "Raspberry Pi 4 Model B Rev 1.4\x00")
+	writeFile(t, filepath.Join(dir, "proc", "cpuinfo"), "processor\t: 0\nFeatures\t: fp asimd evtstrm crc32 cpuid\n")
+
+	details, err := detectHostDetails(paths)
+	require.NoError(t, err)
+	assert.Equal(t, hostDetails{
+		VirtualizationType: "none",
+		MachineID:          "fedcba9876543210fedcba9876543210",
+		HardwareModel:      "Raspberry Pi 4 Model B Rev 1.4",
+	}, details)
+}
+
+func TestDetectHostDetailsUnreadable(t *testing.T) {
+	dir, paths := testPaths(t)
+	// a directory in place of the machine id file fails reading it
+	require.NoError(t, os.MkdirAll(filepath.Join(dir, "etc", "machine-id"), 0o700))
+	writeFile(t, filepath.Join(dir, "sys", "class", "dmi", "id", "product_name"), "KVM\n")
+
+	details, err := detectHostDetails(paths)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed reading")
+	assert.Equal(t, hostDetails{VirtualizationType: "kvm", HardwareModel: "KVM"}, details)
+}
@@ -0,0 +1,118 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdetailsprocessor
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/hostmetadata"
+)
+
+// cloudMetadataProvider is the source of cached cloud metadata, shared with other components.
+var cloudMetadataProvider = hostmetadata.Shared
+
+type hostDetailsProcessor struct {
+	logger     *zap.Logger
+	attributes map[string]string
+	// cloudMetadata is retrieved in the background when enabled, so that it doesn't delay the start of the processor
+	cloudMetadata        *hostmetadata.Lookup
+	cloudMetadataEnabled bool
+	override             bool
+}
+
+func newHostDetailsProcessor(cfg *Config, logger *zap.Logger) *hostDetailsProcessor {
+	return &hostDetailsProcessor{
+		logger:               logger,
+		override:             cfg.Override,
+		cloudMetadataEnabled: cfg.CloudMetadata,
+	}
+}
+
+// start detects the host details using the host paths of the smartagent extension, if any, and starts
+// retrieving the cloud metadata if enabled.
+func (p *hostDetailsProcessor) start(_ context.Context, host component.Host) error {
+	p.attributes = sharedHostDetails(p.logger, hostPathsFromExtensions(host.GetExtensions())).attributes()
+	if p.cloudMetadataEnabled {
+		p.cloudMetadata = cloudMetadataProvider().Lookup()
+		go func(lookup *hostmetadata.Lookup) {
+			<-lookup.Done()
+			if _, err := lookup.Get(); err != nil {
+				p.logger.Debug("not adding cloud metadata attributes", zap.Error(err))
+			}
+		}(p.cloudMetadata)
+	}
+	return nil
+}
+
+// cloudAttributes returns the resource attributes of the cloud metadata, once retrieved.
+func (p *hostDetailsProcessor) cloudAttributes() map[string]string {
+	if p.cloudMetadata == nil {
+		return nil
+	}
+	metadata, err := p.cloudMetadata.Get()
+	if err != nil {
+		return nil
+	}
+	return metadata.ResourceAttributes()
+}
+
+func (p *hostDetailsProcessor) processResource(resource pcommon.Resource, cloudAttributes map[string]string) {
+	attrs := resource.Attributes()
+	for key, value := range p.attributes {
+		if p.override {
+			attrs.UpsertString(key, value)
+		} else {
+			attrs.InsertString(key, value)
+		}
+	}
+	// like those of the resourcedetection processor, which take precedence
+	for key, value := range cloudAttributes {
+		attrs.InsertString(key, value)
+	}
+}
+
+func (p *hostDetailsProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	cloudAttributes := p.cloudAttributes()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		p.processResource(rms.At(i).Resource(), cloudAttributes)
+	}
+	return md, nil
+}
+
+func (p *hostDetailsProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	cloudAttributes := p.cloudAttributes()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		p.processResource(rls.At(i).Resource(), cloudAttributes)
+	}
+	return ld, nil
+}
+
+func (p *hostDetailsProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	cloudAttributes := p.cloudAttributes()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		p.processResource(rss.At(i).Resource(), cloudAttributes)
+	}
+	return td, nil
+}
@@ -0,0 +1,196 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package hostdetailsprocessor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/extension/smartagentextension"
+	"github.com/signalfx/splunk-otel-collector/internal/hostmetadata"
+)
+
+var testDetails = hostDetails{
+	VirtualizationType: "kvm",
+	MachineID:          "0123456789abcdef0123456789abcdef",
+	HardwareModel:      "Standard PC (Q35 + ICH9, 2009)",
+}
+
+func newTestProcessor(override bool, details hostDetails) *hostDetailsProcessor {
+	cfg := &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		Override:          override,
+	}
+	p := newHostDetailsProcessor(cfg, zap.NewNop())
+	p.attributes = details.attributes()
+	return p
+}
+
+func assertAttributes(t *testing.T, expected map[string]any, resource pcommon.Resource) {
+	assert.Equal(t, expected, resource.Attributes().AsRaw())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty()
+	md.ResourceMetrics().AppendEmpty().Resource().Attributes().InsertString(attributeVirtualizationType, "xen")
+
+	md, err := newTestProcessor(false, testDetails).processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	expected := map[string]any{
+		attributeVirtualizationType: "kvm",
+		attributeMachineID:          "0123456789abcdef0123456789abcdef",
+		attributeHardwareModel:      "Standard PC (Q35 + ICH9, 2009)",
+	}
+	assertAttributes(t, expected, md.ResourceMetrics().At(0).Resource())
+	expected[attributeVirtualizationType] = "xen"
+	assertAttributes(t, expected, md.ResourceMetrics().At(1).Resource())
+}
+
+func TestProcessLogsOverride(t *testing.T) {
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().Resource().Attributes().InsertString(attributeVirtualizationType, "xen")
+
+	ld, err := newTestProcessor(true, testDetails).processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assertAttributes(t, map[string]any{
+		attributeVirtualizationType: "kvm",
+		attributeMachineID:          "0123456789abcdef0123456789abcdef",
+		attributeHardwareModel:      "Standard PC (Q35 + ICH9, 2009)",
+	}, ld.ResourceLogs().At(0).Resource())
+}
+
+func TestProcessTracesPartialDetails(t *testing.T) {
+	td := ptrace.NewTraces()
+	td.ResourceSpans().AppendEmpty().Resource().Attributes().InsertString("service.name", "checkout")
+
+	td, err := newTestProcessor(false, hostDetails{VirtualizationType: virtualizationNone}).processTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	assertAttributes(t, map[string]any{
+		"service.name":              "checkout",
+		attributeVirtualizationType: "none",
+	}, td.ResourceSpans().At(0).Resource())
+}
+
+type fakeHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *fakeHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+// withFakeDetection replaces the detection for the duration of the test, returning the detected host paths.
+func withFakeDetection(t *testing.T) *hostPaths {
+	origDetect := detect
+	t.Cleanup(func() {
+		detect = origDetect
+		detectOnce, detected = sync.Once{}, hostDetails{}
+	})
+	detectOnce, detected = sync.Once{}, hostDetails{}
+	paths := &hostPaths{}
+	detect = func(p hostPaths) (hostDetails, error) {
+		*paths = p
+		return testDetails, nil
+	}
+	return paths
+}
+
+func TestStartWithDefaultHostPaths(t *testing.T) {
+	paths := withFakeDetection(t)
+
+	p := newTestProcessor(false, hostDetails{})
+	require.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, testDetails.attributes(), p.attributes)
+	assert.Equal(t, hostPaths{etcPath: "/etc", procPath: "/proc", sysPath: "/sys", varPath: "/var"}, *paths)
+}
+
+func TestStartWithSmartAgentExtensionHostPaths(t *testing.T) {
+	paths := withFakeDetection(t)
+
+	factory := smartagentextension.NewFactory()
+	cfg := factory.CreateDefaultConfig().(*smartagentextension.Config)
+	cfg.EtcPath, cfg.ProcPath, cfg.SysPath, cfg.VarPath = "/hostfs/etc", "/hostfs/proc", "/hostfs/sys", "/hostfs/var"
+	ext, err := factory.CreateExtension(context.Background(), component.ExtensionCreateSettings{}, cfg)
+	require.NoError(t, err)
+	host := &fakeHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		cfg.ID(): ext,
+	}}
+
+	p := newTestProcessor(false, hostDetails{})
+	require.NoError(t, p.start(context.Background(), host))
+	assert.Equal(t, testDetails.attributes(), p.attributes)
+	assert.Equal(t, hostPaths{
+		etcPath: "/hostfs/etc", procPath: "/hostfs/proc", sysPath: "/hostfs/sys", varPath: "/hostfs/var",
+	}, *paths)
+}
+
+type fakeCloudDetector struct {
+	metadata *hostmetadata.Metadata
+}
+
+func (d fakeCloudDetector) Name() string {
+	return "fake"
+}
+
+func (d fakeCloudDetector) Detect(context.Context) (*hostmetadata.Metadata, error) {
+	return d.metadata, nil
+}
+
+func TestProcessMetricsWithCloudMetadata(t *testing.T) {
+	withFakeDetection(t)
+	origProvider := cloudMetadataProvider
+	t.Cleanup(func() { cloudMetadataProvider = origProvider })
+	provider := hostmetadata.NewProvider(hostmetadata.WithDetectors(fakeCloudDetector{metadata: &hostmetadata.Metadata{
+		CloudProvider: "aws", InstanceID: "i-123", Region: "us-west-2",
+	}}))
+	cloudMetadataProvider = func() *hostmetadata.Provider { return provider }
+
+	p := newTestProcessor(true, hostDetails{})
+	p.cloudMetadataEnabled = true
+	require.NoError(t, p.start(context.Background(), componenttest.NewNopHost()))
+	<-p.cloudMetadata.Done()
+
+	md := pmetric.NewMetrics()
+	md.ResourceMetrics().AppendEmpty().Resource().Attributes().InsertString("cloud.region", "us-east-1")
+	md, err := p.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	// the cloud metadata attributes don't replace existing ones, even with override
+	assertAttributes(t, map[string]any{
+		attributeVirtualizationType: "kvm",
+		attributeMachineID:          "0123456789abcdef0123456789abcdef",
+		attributeHardwareModel:      "Standard PC (Q35 + ICH9, 2009)",
+		"cloud.provider":            "aws",
+		"cloud.region":              "us-east-1",
+		"host.id":                   "i-123",
+	}, md.ResourceMetrics().At(0).Resource())
+}
@@ -0,0 +1,58 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lagguardprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	// actionDrop drops the telemetry older than the TTL.
+	actionDrop = "drop"
+	// actionFlag keeps the telemetry older than the TTL, setting the flag attribute on it.
+	actionFlag = "flag"
+)
+
+// Config defines configuration for the lag guard processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Action is how telemetry older than the TTL is handled, either drop or flag.
+	Action string `mapstructure:"action"`
+	// FlagAttribute is the boolean attribute set on telemetry older than the TTL by the flag action.
+	FlagAttribute string `mapstructure:"flag_attribute"`
+	// TTL is the maximum age of datapoints, log records, and spans, as of their timestamps.
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.TTL <= 0 {
+		return fmt.Errorf("ttl must be positive, not %s", cfg.TTL)
+	}
+	if cfg.Action != actionDrop && cfg.Action != actionFlag {
+		return fmt.Errorf("unsupported action %q, must be %q or %q", cfg.Action, actionDrop, actionFlag)
+	}
+	if cfg.Action == actionFlag && cfg.FlagAttribute == "" {
+		return errors.New("flag_attribute must not be empty with the flag action")
+	}
+	return nil
+}
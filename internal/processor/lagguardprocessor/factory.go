@@ -0,0 +1,114 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lagguardprocessor
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+const (
+	// The value of "type" key in configuration.
+	typeStr = "lag_guard"
+
+	defaultTTL           = 15 * time.Minute
+	defaultAction        = actionDrop
+	defaultFlagAttribute = "otel.data.stale"
+)
+
+var (
+	processorCapabilities = consumer.Capabilities{MutatesData: true}
+	registerViewsOnce     sync.Once
+)
+
+// NewFactory creates a factory for the lag guard processor.
+func NewFactory() component.ProcessorFactory {
+	return component.NewProcessorFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsProcessor(createMetricsProcessor),
+		component.WithLogsProcessor(createLogsProcessor),
+		component.WithTracesProcessor(createTracesProcessor),
+	)
+}
+
+func createDefaultConfig() config.Processor {
+	return &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		TTL:               defaultTTL,
+		Action:            defaultAction,
+		FlagAttribute:     defaultFlagAttribute,
+	}
+}
+
+func newProcessor(params component.ProcessorCreateSettings, cfg config.Processor) *lagGuardProcessor {
+	// the views are shared by all instances, whose measurements are distinguished by the processor tag
+	registerViewsOnce.Do(func() {
+		if err := view.Register(metricViews()...); err != nil {
+			params.Logger.Warn("failed registering lag guard metric views", zap.Error(err))
+		}
+	})
+	return newLagGuardProcessor(cfg.(*Config), params.Logger)
+}
+
+func createMetricsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Metrics,
+) (component.MetricsProcessor, error) {
+	return processorhelper.NewMetricsProcessor(
+		cfg,
+		nextConsumer,
+		newProcessor(params, cfg).processMetrics,
+		processorhelper.WithCapabilities(processorCapabilities),
+	)
+}
+
+func createLogsProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Logs,
+) (component.LogsProcessor, error) {
+	return processorhelper.NewLogsProcessor(
+		cfg,
+		nextConsumer,
+		newProcessor(params, cfg).processLogs,
+		processorhelper.WithCapabilities(processorCapabilities),
+	)
+}
+
+func createTracesProcessor(
+	_ context.Context,
+	params component.ProcessorCreateSettings,
+	cfg config.Processor,
+	nextConsumer consumer.Traces,
+) (component.TracesProcessor, error) {
+	return processorhelper.NewTracesProcessor(
+		cfg,
+		nextConsumer,
+		newProcessor(params, cfg).processTraces,
+		processorhelper.WithCapabilities(processorCapabilities),
+	)
+}
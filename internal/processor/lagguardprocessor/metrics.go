@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lagguardprocessor
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	processorKey = tag.MustNewKey("processor")
+	signalKey    = tag.MustNewKey("signal")
+	actionKey    = tag.MustNewKey("action")
+
+	mDataAge = stats.Int64(
+		typeStr+"/data_age", "Age of the oldest datapoint, log record, or span of each batch", stats.UnitMilliseconds,
+	)
+	mStaleItems = stats.Int64(
+		typeStr+"/stale_items", "Number of datapoints, log records, and spans older than the TTL", stats.UnitDimensionless,
+	)
+)
+
+// metricViews returns the views of the processor's metrics, which are reported by the collector's own telemetry.
+func metricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mDataAge.Name(),
+			Description: mDataAge.Description(),
+			Measure:     mDataAge,
+			TagKeys:     []tag.Key{processorKey, signalKey},
+			// from a second to a day, covering the backlog of outages
+			Aggregation: view.Distribution(1e3, 5e3, 15e3, 30e3, 60e3, 300e3, 900e3, 1800e3, 3600e3, 7200e3, 21600e3, 86400e3),
+		},
+		{
+			Name:        mStaleItems.Name(),
+			Description: mStaleItems.Description(),
+			Measure:     mStaleItems,
+			TagKeys:     []tag.Key{processorKey, signalKey, actionKey},
+			Aggregation: view.Sum(),
+		},
+	}
+}
@@ -0,0 +1,214 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lagguardprocessor
+
+import (
+	"context"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+const (
+	signalMetrics = "metrics"
+	signalLogs    = "logs"
+	signalTraces  = "traces"
+)
+
+type lagGuardProcessor struct {
+	cfg          *Config
+	logger       *zap.Logger
+	now          func() time.Time
+	processorTag string
+}
+
+func newLagGuardProcessor(cfg *Config, logger *zap.Logger) *lagGuardProcessor {
+	return &lagGuardProcessor{
+		cfg:          cfg,
+		processorTag: cfg.ID().String(),
+		logger:       logger,
+		now:          time.Now,
+	}
+}
+
+// batchGuard judges the items of a batch against the TTL, tracking the age of the oldest and
+// the number of stale ones.
+type batchGuard struct {
+	proc   *lagGuardProcessor
+	now    time.Time
+	oldest time.Duration
+	stale  int64
+	aged   bool
+}
+
+func (proc *lagGuardProcessor) newBatchGuard() *batchGuard {
+	return &batchGuard{proc: proc, now: proc.now()}
+}
+
+// keep returns whether the item of the timestamp and attributes is kept, flagging it if stale and
+// configured to. Items without timestamps are always kept.
+func (bg *batchGuard) keep(ts pcommon.Timestamp, attrs pcommon.Map) bool {
+	if ts == 0 {
+		return true
+	}
+	age := bg.now.Sub(ts.AsTime())
+	if age > bg.oldest || !bg.aged {
+		bg.oldest = age
+		bg.aged = true
+	}
+	if age <= bg.proc.cfg.TTL {
+		return true
+	}
+	bg.stale++
+	if bg.proc.cfg.Action == actionFlag {
+		attrs.UpsertBool(bg.proc.cfg.FlagAttribute, true)
+		return true
+	}
+	return false
+}
+
+// record reports the batch's oldest item age and stale items.
+func (bg *batchGuard) record(ctx context.Context, signal string) {
+	if bg.aged {
+		oldest := bg.oldest
+		if oldest < 0 {
+			// the clocks of the telemetry sources can be ahead
+			oldest = 0
+		}
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(processorKey, bg.proc.processorTag),
+			tag.Upsert(signalKey, signal),
+		}, mDataAge.M(oldest.Milliseconds()))
+	}
+	if bg.stale > 0 {
+		_ = stats.RecordWithTags(ctx, []tag.Mutator{
+			tag.Upsert(processorKey, bg.proc.processorTag),
+			tag.Upsert(signalKey, signal),
+			tag.Upsert(actionKey, bg.proc.cfg.Action),
+		}, mStaleItems.M(bg.stale))
+		bg.proc.logger.Debug("telemetry older than the TTL",
+			zap.String("signal", signal),
+			zap.Int64("count", bg.stale),
+			zap.Duration("oldest", bg.oldest),
+			zap.String("action", bg.proc.cfg.Action),
+		)
+	}
+}
+
+func (proc *lagGuardProcessor) processMetrics(ctx context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	bg := proc.newBatchGuard()
+	rms := md.ResourceMetrics()
+	rms.RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		sms := rm.ScopeMetrics()
+		sms.RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+			sm.Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+				return bg.guardMetric(metric) == 0
+			})
+			return sm.Metrics().Len() == 0
+		})
+		return sms.Len() == 0
+	})
+	bg.record(ctx, signalMetrics)
+
+	if rms.Len() == 0 {
+		return md, processorhelper.ErrSkipProcessingData
+	}
+	return md, nil
+}
+
+// guardMetric removes the stale datapoints of the metric and returns the number remaining.
+func (bg *batchGuard) guardMetric(metric pmetric.Metric) int {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		dps.RemoveIf(func(dp pmetric.NumberDataPoint) bool { return !bg.keep(dp.Timestamp(), dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		dps.RemoveIf(func(dp pmetric.NumberDataPoint) bool { return !bg.keep(dp.Timestamp(), dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		dps.RemoveIf(func(dp pmetric.HistogramDataPoint) bool { return !bg.keep(dp.Timestamp(), dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		dps.RemoveIf(func(dp pmetric.ExponentialHistogramDataPoint) bool { return !bg.keep(dp.Timestamp(), dp.Attributes()) })
+		return dps.Len()
+	case pmetric.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		dps.RemoveIf(func(dp pmetric.SummaryDataPoint) bool { return !bg.keep(dp.Timestamp(), dp.Attributes()) })
+		return dps.Len()
+	}
+	// metrics without datapoints are left to the exporters
+	return 1
+}
+
+func (proc *lagGuardProcessor) processLogs(ctx context.Context, ld plog.Logs) (plog.Logs, error) {
+	bg := proc.newBatchGuard()
+	rls := ld.ResourceLogs()
+	rls.RemoveIf(func(rl plog.ResourceLogs) bool {
+		sls := rl.ScopeLogs()
+		sls.RemoveIf(func(sl plog.ScopeLogs) bool {
+			sl.LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				ts := lr.Timestamp()
+				if ts == 0 {
+					ts = lr.ObservedTimestamp()
+				}
+				return !bg.keep(ts, lr.Attributes())
+			})
+			return sl.LogRecords().Len() == 0
+		})
+		return sls.Len() == 0
+	})
+	bg.record(ctx, signalLogs)
+
+	if rls.Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+func (proc *lagGuardProcessor) processTraces(ctx context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	bg := proc.newBatchGuard()
+	rss := td.ResourceSpans()
+	rss.RemoveIf(func(rs ptrace.ResourceSpans) bool {
+		sss := rs.ScopeSpans()
+		sss.RemoveIf(func(ss ptrace.ScopeSpans) bool {
+			ss.Spans().RemoveIf(func(span ptrace.Span) bool {
+				ts := span.EndTimestamp()
+				if ts == 0 {
+					ts = span.StartTimestamp()
+				}
+				return !bg.keep(ts, span.Attributes())
+			})
+			return ss.Spans().Len() == 0
+		})
+		return sss.Len() == 0
+	})
+	bg.record(ctx, signalTraces)
+
+	if rss.Len() == 0 {
+		return td, processorhelper.ErrSkipProcessingData
+	}
+	return td, nil
+}
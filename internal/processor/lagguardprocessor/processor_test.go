@@ -0,0 +1,238 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package lagguardprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"go.uber.org/zap"
+)
+
+var testNow = time.Date(2022, 7, 1, 12, 0, 0, 0, time.UTC)
+
+func newTestProcessor(action string) *lagGuardProcessor {
+	cfg := &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		TTL:               time.Hour,
+		Action:            action,
+		FlagAttribute:     "stale",
+	}
+	proc := newLagGuardProcessor(cfg, zap.NewNop())
+	proc.now = func() time.Time { return testNow }
+	return proc
+}
+
+func ago(d time.Duration) pcommon.Timestamp {
+	return pcommon.NewTimestampFromTime(testNow.Add(-d))
+}
+
+// newTestMetrics returns a gauge with datapoints of each age and a histogram with a two hour old datapoint,
+// in separate resources.
+func newTestMetrics(ages ...time.Duration) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	gauge := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	gauge.SetName("gauge")
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	for i, age := range ages {
+		dp := gauge.Gauge().DataPoints().AppendEmpty()
+		dp.SetTimestamp(ago(age))
+		dp.SetIntVal(int64(i))
+	}
+
+	histogram := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	histogram.SetName("histogram")
+	histogram.SetDataType(pmetric.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty().SetTimestamp(ago(2 * time.Hour))
+	return md
+}
+
+func TestProcessMetricsDrop(t *testing.T) {
+	proc := newTestProcessor(actionDrop)
+
+	md, err := proc.processMetrics(context.Background(), newTestMetrics(time.Minute, 3*time.Hour, -time.Minute, 0))
+	require.NoError(t, err)
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 3, dps.Len())
+	assert.Equal(t, int64(0), dps.At(0).IntVal())
+	assert.Equal(t, int64(2), dps.At(1).IntVal())
+	assert.Equal(t, int64(3), dps.At(2).IntVal())
+	assert.Zero(t, dps.At(0).Attributes().Len())
+
+	md, err = proc.processMetrics(context.Background(), newTestMetrics(2*time.Hour))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Zero(t, md.ResourceMetrics().Len())
+}
+
+func TestProcessMetricsWithoutTimestamps(t *testing.T) {
+	proc := newTestProcessor(actionDrop)
+
+	md := pmetric.NewMetrics()
+	sum := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	sum.SetName("sum")
+	sum.SetDataType(pmetric.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty().SetIntVal(1)
+
+	md, err := proc.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+	assert.Equal(t, 1, md.DataPointCount())
+}
+
+func TestProcessMetricsFlag(t *testing.T) {
+	proc := newTestProcessor(actionFlag)
+
+	md, err := proc.processMetrics(context.Background(), newTestMetrics(time.Minute, 3*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 2, md.ResourceMetrics().Len())
+	dps := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints()
+	require.Equal(t, 2, dps.Len())
+	assert.Zero(t, dps.At(0).Attributes().Len())
+	assert.Equal(t, map[string]any{"stale": true}, dps.At(1).Attributes().AsRaw())
+	histogramDP := md.ResourceMetrics().At(1).ScopeMetrics().At(0).Metrics().At(0).Histogram().DataPoints().At(0)
+	assert.Equal(t, map[string]any{"stale": true}, histogramDP.Attributes().AsRaw())
+}
+
+func TestProcessLogs(t *testing.T) {
+	newLogs := func() plog.Logs {
+		ld := plog.NewLogs()
+		lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+		lrs.AppendEmpty().SetTimestamp(ago(time.Second))
+		lrs.AppendEmpty().SetTimestamp(ago(2 * time.Hour))
+		// the observed timestamp is used for records without timestamps
+		lrs.AppendEmpty().SetObservedTimestamp(ago(90 * time.Minute))
+		lrs.AppendEmpty().Body().SetStringVal("without timestamps")
+		return ld
+	}
+
+	ld, err := newTestProcessor(actionDrop).processLogs(context.Background(), newLogs())
+	require.NoError(t, err)
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, lrs.Len())
+	assert.Equal(t, ago(time.Second), lrs.At(0).Timestamp())
+	assert.Equal(t, "without timestamps", lrs.At(1).Body().StringVal())
+
+	ld, err = newTestProcessor(actionFlag).processLogs(context.Background(), newLogs())
+	require.NoError(t, err)
+	lrs = ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 4, lrs.Len())
+	var flagged []int
+	for i := 0; i < lrs.Len(); i++ {
+		if _, ok := lrs.At(i).Attributes().Get("stale"); ok {
+			flagged = append(flagged, i)
+		}
+	}
+	assert.Equal(t, []int{1, 2}, flagged)
+
+	ld = plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().SetTimestamp(ago(2 * time.Hour))
+	_, err = newTestProcessor(actionDrop).processLogs(context.Background(), ld)
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+}
+
+func TestProcessTraces(t *testing.T) {
+	td := ptrace.NewTraces()
+	spans := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans()
+	recent := spans.AppendEmpty()
+	recent.SetName("recent")
+	recent.SetStartTimestamp(ago(2 * time.Hour))
+	recent.SetEndTimestamp(ago(time.Minute))
+	stale := spans.AppendEmpty()
+	stale.SetName("stale")
+	stale.SetStartTimestamp(ago(3 * time.Hour))
+	stale.SetEndTimestamp(ago(2 * time.Hour))
+	startOnly := spans.AppendEmpty()
+	startOnly.SetName("start only")
+	startOnly.SetStartTimestamp(ago(2 * time.Hour))
+	td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty().SetEndTimestamp(ago(5 * time.Hour))
+
+	td, err := newTestProcessor(actionDrop).processTraces(context.Background(), td)
+	require.NoError(t, err)
+	require.Equal(t, 1, td.ResourceSpans().Len())
+	spans = td.ResourceSpans().At(0).ScopeSpans().At(0).Spans()
+	require.Equal(t, 1, spans.Len())
+	assert.Equal(t, "recent", spans.At(0).Name())
+}
+
+func TestBatchGuardOldest(t *testing.T) {
+	bg := newTestProcessor(actionDrop).newBatchGuard()
+	assert.True(t, bg.keep(ago(-time.Minute), pcommon.NewMap()))
+	assert.Equal(t, -time.Minute, bg.oldest)
+	assert.True(t, bg.keep(ago(time.Minute), pcommon.NewMap()))
+	assert.False(t, bg.keep(ago(2*time.Hour), pcommon.NewMap()))
+	assert.True(t, bg.keep(ago(30*time.Minute), pcommon.NewMap()))
+	assert.Equal(t, 2*time.Hour, bg.oldest)
+	assert.Equal(t, int64(1), bg.stale)
+}
+
+func TestRecordMetrics(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.ProcessorSettings = config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "record"))
+	cfg.TTL = time.Hour
+	proc := newProcessor(componenttest.NewNopProcessorCreateSettings(), cfg)
+	proc.now = func() time.Time { return testNow }
+
+	_, err := proc.processMetrics(context.Background(), newTestMetrics(time.Minute, 3*time.Hour))
+	require.NoError(t, err)
+	ld := plog.NewLogs()
+	ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().SetTimestamp(ago(-time.Minute))
+	_, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	// the rows of the processor by their signal, for the views shared with other tests
+	rows := func(name string) map[string]*view.Row {
+		data, err := view.RetrieveData(name)
+		require.NoError(t, err)
+		bySignal := map[string]*view.Row{}
+		for _, row := range data {
+			tags := map[tag.Key]string{}
+			for _, t := range row.Tags {
+				tags[t.Key] = t.Value
+			}
+			if tags[processorKey] == "lag_guard/record" {
+				bySignal[tags[signalKey]] = row
+			}
+		}
+		return bySignal
+	}
+
+	ages := rows("lag_guard/data_age")
+	require.Len(t, ages, 2)
+	metricsAge := ages[signalMetrics].Data.(*view.DistributionData)
+	assert.Equal(t, int64(1), metricsAge.Count)
+	assert.Equal(t, float64(3*time.Hour/time.Millisecond), metricsAge.Mean)
+	// the ages of telemetry from ahead clocks are reported as 0
+	logsAge := ages[signalLogs].Data.(*view.DistributionData)
+	assert.Equal(t, int64(1), logsAge.Count)
+	assert.Equal(t, float64(0), logsAge.Mean)
+
+	stale := rows("lag_guard/stale_items")
+	require.Len(t, stale, 1)
+	// the three hour old datapoint, and the two hour old histogram one
+	assert.Equal(t, float64(2), stale[signalMetrics].Data.(*view.SumData).Value)
+	assert.Contains(t, stale[signalMetrics].Tags, tag.Tag{Key: actionKey, Value: actionDrop})
+}
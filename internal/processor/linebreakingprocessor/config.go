@@ -0,0 +1,96 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linebreakingprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// Config defines configuration for the line breaking processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// SourcetypeAttribute is the record attribute, or resource attribute if the record doesn't
+	// have it, whose value is the source type of a record.
+	SourcetypeAttribute string `mapstructure:"sourcetype_attribute"`
+	// Sourcetypes are the line breaking rules of the source types whose lines are merged into events.
+	Sourcetypes map[string]LineBreakingRule `mapstructure:"sourcetypes"`
+}
+
+// LineBreakingRule determines the boundaries of the events formed by merging consecutive lines.
+// At least one of LineBegins and LineEnds must be set.
+type LineBreakingRule struct {
+	// LineBegins is the regular expression matching the first line of an event. Lines
+	// that don't match it are merged into the preceding event.
+	LineBegins string `mapstructure:"line_begins"`
+	// LineEnds is the regular expression matching the last line of an event. The line
+	// following it begins a new event.
+	LineEnds string `mapstructure:"line_ends"`
+	// MaxLines is the maximum number of lines merged into an event, 256 if 0.
+	MaxLines int `mapstructure:"max_lines"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.SourcetypeAttribute == "" {
+		return errors.New("sourcetype_attribute must not be empty")
+	}
+	if len(cfg.Sourcetypes) == 0 {
+		return errors.New("at least one sourcetype must be provided")
+	}
+	sourcetypes := make([]string, 0, len(cfg.Sourcetypes))
+	for sourcetype := range cfg.Sourcetypes {
+		sourcetypes = append(sourcetypes, sourcetype)
+	}
+	sort.Strings(sourcetypes)
+	for _, sourcetype := range sourcetypes {
+		if _, err := cfg.Sourcetypes[sourcetype].compile(); err != nil {
+			return fmt.Errorf("sourcetype %q: %w", sourcetype, err)
+		}
+	}
+	return nil
+}
+
+func (rule LineBreakingRule) compile() (lineBreaker, error) {
+	if rule.LineBegins == "" && rule.LineEnds == "" {
+		return lineBreaker{}, errors.New("at least one of line_begins or line_ends must be set")
+	}
+	if rule.MaxLines < 0 {
+		return lineBreaker{}, fmt.Errorf("max_lines must not be negative, not %d", rule.MaxLines)
+	}
+	breaker := lineBreaker{maxLines: rule.MaxLines}
+	if breaker.maxLines == 0 {
+		breaker.maxLines = defaultMaxLines
+	}
+	var err error
+	if rule.LineBegins != "" {
+		if breaker.begins, err = regexp.Compile(rule.LineBegins); err != nil {
+			return lineBreaker{}, fmt.Errorf("invalid line_begins pattern: %w", err)
+		}
+	}
+	if rule.LineEnds != "" {
+		if breaker.ends, err = regexp.Compile(rule.LineEnds); err != nil {
+			return lineBreaker{}, fmt.Errorf("invalid line_ends pattern: %w", err)
+		}
+	}
+	return breaker, nil
+}
@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linebreakingprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, &Config{
+		ProcessorSettings:   config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		SourcetypeAttribute: "com.splunk.sourcetype",
+		Sourcetypes: map[string]LineBreakingRule{
+			"java:app": {LineBegins: `^\d{4}-\d{2}-\d{2}`},
+		},
+	}, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, &Config{
+		ProcessorSettings:   config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		SourcetypeAttribute: "sourcetype",
+		Sourcetypes: map[string]LineBreakingRule{
+			"java:app":       {LineBegins: `^\d{4}-\d{2}-\d{2}`, MaxLines: 100},
+			"sql:statements": {LineEnds: `;\s*$`},
+		},
+	}, p1)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "no_sourcetypes.yaml", err: `at least one sourcetype must be provided`},
+		{file: "empty_rule.yaml", err: `sourcetype "java:app": at least one of line_begins or line_ends must be set`},
+		{file: "invalid_pattern.yaml", err: `sourcetype "java:app": invalid line_begins pattern`},
+		{file: "invalid_max_lines.yaml", err: `sourcetype "java:app": max_lines must not be negative, not -1`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
@@ -0,0 +1,135 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linebreakingprocessor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+)
+
+// lineBreaker is the compiled LineBreakingRule of a source type.
+type lineBreaker struct {
+	begins   *regexp.Regexp
+	ends     *regexp.Regexp
+	maxLines int
+}
+
+// event is a record whose body is being merged with the following lines of its source type.
+type event struct {
+	record     plog.LogRecord
+	sourcetype string
+	lines      strings.Builder
+	lineCount  int
+}
+
+func newEvent(record plog.LogRecord, sourcetype, line string) *event {
+	e := &event{record: record, sourcetype: sourcetype, lineCount: 1}
+	e.lines.WriteString(line)
+	return e
+}
+
+func (e *event) add(line string) {
+	e.lines.WriteByte('\n')
+	e.lines.WriteString(line)
+	e.lineCount++
+}
+
+// close sets the record's body to its merged lines.
+func (e *event) close() {
+	if e == nil || e.lineCount == 1 {
+		return
+	}
+	e.record.Body().SetStringVal(e.lines.String())
+}
+
+type lineBreakingProcessor struct {
+	sourcetypeAttribute string
+	breakers            map[string]lineBreaker
+}
+
+func newLineBreakingProcessor(cfg *Config) (*lineBreakingProcessor, error) {
+	proc := &lineBreakingProcessor{
+		sourcetypeAttribute: cfg.SourcetypeAttribute,
+		breakers:            make(map[string]lineBreaker, len(cfg.Sourcetypes)),
+	}
+	for sourcetype, rule := range cfg.Sourcetypes {
+		breaker, err := rule.compile()
+		if err != nil {
+			return nil, err
+		}
+		proc.breakers[sourcetype] = breaker
+	}
+	return proc, nil
+}
+
+func (proc *lineBreakingProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceAttrs := rl.Resource().Attributes()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			proc.mergeLines(resourceAttrs, sls.At(j).LogRecords())
+		}
+	}
+	return ld, nil
+}
+
+// mergeLines merges the string bodies of consecutive records of the same source type into the
+// events determined by its line breaking rule, removing the records whose lines were merged.
+// Each event keeps the timestamp and attributes of its first record.
+func (proc *lineBreakingProcessor) mergeLines(resourceAttrs pcommon.Map, records plog.LogRecordSlice) {
+	merged := make([]bool, records.Len())
+	var current *event
+	for i := 0; i < records.Len(); i++ {
+		lr := records.At(i)
+		sourcetype := logrecord.AttributeValue(resourceAttrs, lr, proc.sourcetypeAttribute)
+		breaker, ok := proc.breakers[sourcetype]
+		if !ok || lr.Body().Type() != pcommon.ValueTypeString {
+			current.close()
+			current = nil
+			continue
+		}
+
+		line := lr.Body().StringVal()
+		if current != nil && current.sourcetype == sourcetype && current.lineCount < breaker.maxLines &&
+			(breaker.begins == nil || !breaker.begins.MatchString(line)) {
+			current.add(line)
+			merged[i] = true
+		} else {
+			current.close()
+			current = newEvent(lr, sourcetype, line)
+		}
+
+		if breaker.ends != nil && breaker.ends.MatchString(line) {
+			current.close()
+			current = nil
+		}
+	}
+	current.close()
+
+	i := 0
+	records.RemoveIf(func(plog.LogRecord) bool {
+		removed := merged[i]
+		i++
+		return removed
+	})
+}
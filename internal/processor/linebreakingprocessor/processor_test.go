@@ -0,0 +1,150 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package linebreakingprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func newTestProcessor(t *testing.T, sourcetypes map[string]LineBreakingRule) *lineBreakingProcessor {
+	proc, err := newLineBreakingProcessor(&Config{
+		SourcetypeAttribute: "com.splunk.sourcetype",
+		Sourcetypes:         sourcetypes,
+	})
+	require.NoError(t, err)
+	return proc
+}
+
+// newRawLogs returns logs like those of the splunk_hec receiver's raw endpoint, a record per line
+// with the sourcetype as resource attribute.
+func newRawLogs(sourcetype string, lines ...string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("com.splunk.sourcetype", sourcetype)
+	records := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for i, line := range lines {
+		lr := records.AppendEmpty()
+		lr.SetTimestamp(pcommon.Timestamp(i + 1))
+		lr.Body().SetStringVal(line)
+	}
+	return ld
+}
+
+func bodies(ld plog.Logs) []string {
+	var result []string
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	for i := 0; i < records.Len(); i++ {
+		result = append(result, records.At(i).Body().AsString())
+	}
+	return result
+}
+
+func TestMergesLinesUntilLineBegins(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{"java:app": {LineBegins: `^\d{4}-\d{2}-\d{2}`}})
+
+	ld, err := proc.processLogs(context.Background(), newRawLogs("java:app",
+		"2022-07-01 12:00:00 ERROR request failed",
+		"java.lang.IllegalStateException: closed",
+		"\tat com.example.Handler.handle(Handler.java:42)",
+		"\tat com.example.Server.run(Server.java:7)",
+		"2022-07-01 12:00:01 INFO request served",
+		"2022-07-01 12:00:02 WARN slow request",
+		"\tduration: 5s",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"2022-07-01 12:00:00 ERROR request failed\njava.lang.IllegalStateException: closed\n" +
+			"\tat com.example.Handler.handle(Handler.java:42)\n\tat com.example.Server.run(Server.java:7)",
+		"2022-07-01 12:00:01 INFO request served",
+		"2022-07-01 12:00:02 WARN slow request\n\tduration: 5s",
+	}, bodies(ld))
+
+	// events keep the timestamps of their first lines
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, pcommon.Timestamp(1), records.At(0).Timestamp())
+	assert.Equal(t, pcommon.Timestamp(5), records.At(1).Timestamp())
+	assert.Equal(t, pcommon.Timestamp(6), records.At(2).Timestamp())
+}
+
+func TestMergesLinesUntilLineEnds(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{"sql:statements": {LineEnds: `;\s*$`}})
+
+	ld, err := proc.processLogs(context.Background(), newRawLogs("sql:statements",
+		"SELECT *", "FROM users", "WHERE id = 1;", "COMMIT;", "SELECT 1",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"SELECT *\nFROM users\nWHERE id = 1;", "COMMIT;", "SELECT 1"}, bodies(ld))
+}
+
+func TestMergesLinesBetweenLineBeginsAndEnds(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{"xml": {LineBegins: `^<event>`, LineEnds: `</event>$`}})
+
+	ld, err := proc.processLogs(context.Background(), newRawLogs("xml",
+		"<event>", "<id>1</id>", "</event>", "trailing", "<event>", "<id>2</id>",
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"<event>\n<id>1</id>\n</event>", "trailing", "<event>\n<id>2</id>"}, bodies(ld))
+}
+
+func TestMaxLines(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{"java:app": {LineBegins: `^start`, MaxLines: 2}})
+
+	ld, err := proc.processLogs(context.Background(), newRawLogs("java:app", "start", "1", "2", "3", "4"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start\n1", "2\n3", "4"}, bodies(ld))
+}
+
+func TestUnconfiguredSourcetypesArentMerged(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{"java:app": {LineBegins: `^start`}})
+
+	ld, err := proc.processLogs(context.Background(), newRawLogs("syslog", "start", "1", "2"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start", "1", "2"}, bodies(ld))
+}
+
+func TestSourcetypeChangesEndEvents(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{
+		"java:app":   {LineBegins: `^start`},
+		"java:other": {LineBegins: `^start`},
+	})
+
+	ld := newRawLogs("java:app", "start", "1", "2", "3", "4", "5")
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	// record attributes take precedence over resource ones
+	records.At(2).Attributes().InsertString("com.splunk.sourcetype", "java:other")
+	records.At(3).Attributes().InsertString("com.splunk.sourcetype", "java:other")
+	records.At(4).Attributes().InsertString("com.splunk.sourcetype", "syslog")
+
+	ld, err := proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start\n1", "2\n3", "4", "5"}, bodies(ld))
+}
+
+func TestNonStringBodiesArentMerged(t *testing.T) {
+	proc := newTestProcessor(t, map[string]LineBreakingRule{"java:app": {LineBegins: `^start`}})
+
+	ld := newRawLogs("java:app", "start", "1", "2", "3")
+	ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(2).Body().SetIntVal(2)
+
+	ld, err := proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"start\n1", "2", "3"}, bodies(ld))
+}
@@ -0,0 +1,125 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+)
+
+const (
+	// metricTypeCounter is a cumulative sum of the matching records, or of their value attribute.
+	metricTypeCounter = "counter"
+	// metricTypeGauge is the last value attribute of the matching records of each flush interval.
+	metricTypeGauge = "gauge"
+)
+
+// Config defines configuration for the log metrics processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// MetricsExporter is the exporter the derived metrics are sent to, which must be part of a metrics pipeline.
+	MetricsExporter string `mapstructure:"metrics_exporter"`
+	// Metrics are the metrics derived from the log records.
+	Metrics []MetricConfig `mapstructure:"metrics"`
+	// Events configures the count of the SignalFx events among the log records.
+	Events EventsConfig `mapstructure:"events"`
+	// FlushInterval is the interval at which the derived metrics are sent.
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+	// MaxSeries is the maximum number of dimension value combinations of each metric, beyond which
+	// records with new combinations aren't counted. 0 disables the limit.
+	MaxSeries int `mapstructure:"max_series"`
+}
+
+// MetricConfig derives a metric from the log records matching all of its attribute patterns.
+type MetricConfig struct {
+	// MatchAttributes maps attribute keys to regular expressions their string form must match.
+	MatchAttributes map[string]string `mapstructure:"match_attributes"`
+	// Name is the name of the derived metric.
+	Name string `mapstructure:"name"`
+	// Description is the description of the derived metric.
+	Description string `mapstructure:"description"`
+	// Type is the type of the derived metric, counter (the default) or gauge.
+	Type string `mapstructure:"type"`
+	// ValueAttribute is the attribute whose numeric value is added to the counter, or set as the gauge value.
+	// Counters without it count the matching records.
+	ValueAttribute string `mapstructure:"value_attribute"`
+	// Dimensions are the attributes whose values are the attributes of the derived datapoints.
+	Dimensions []string `mapstructure:"dimensions"`
+}
+
+// EventsConfig configures the count of SignalFx events, the log records with an event category
+// like those converted by the smartagent receiver or the signalfx_event processor.
+type EventsConfig struct {
+	// MetricName is the name of the counter of the events by their type and category.
+	MetricName string `mapstructure:"metric_name"`
+	// Dimensions are the attributes the events are counted by, in addition to their type and category.
+	Dimensions []string `mapstructure:"dimensions"`
+	// Enabled determines whether the events are counted.
+	Enabled bool `mapstructure:"enabled"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.MetricsExporter == "" {
+		return errors.New("metrics_exporter must not be empty")
+	}
+	if len(cfg.Metrics) == 0 && !cfg.Events.Enabled {
+		return errors.New("at least one of metrics or events must be configured")
+	}
+	if cfg.FlushInterval <= 0 {
+		return fmt.Errorf("flush_interval must be positive, not %s", cfg.FlushInterval)
+	}
+	if cfg.MaxSeries < 0 {
+		return fmt.Errorf("max_series must not be negative, not %d", cfg.MaxSeries)
+	}
+
+	names := map[string]bool{}
+	if cfg.Events.Enabled {
+		if cfg.Events.MetricName == "" {
+			return errors.New("events: metric_name must not be empty")
+		}
+		names[cfg.Events.MetricName] = true
+	}
+	for i, metric := range cfg.Metrics {
+		if metric.Name == "" {
+			return fmt.Errorf("metrics[%d]: name must not be empty", i)
+		}
+		if names[metric.Name] {
+			return fmt.Errorf("metrics[%d]: duplicate metric name %q", i, metric.Name)
+		}
+		names[metric.Name] = true
+		switch metric.Type {
+		case "", metricTypeCounter:
+		case metricTypeGauge:
+			if metric.ValueAttribute == "" {
+				return fmt.Errorf("metrics[%d]: value_attribute must not be empty for gauges", i)
+			}
+		default:
+			return fmt.Errorf("metrics[%d]: unsupported type %q, must be %q or %q", i, metric.Type, metricTypeCounter, metricTypeGauge)
+		}
+		if _, err := logrecord.CompileAttributePatterns(metric.MatchAttributes); err != nil {
+			return fmt.Errorf("metrics[%d]: match_attributes: %w", i, err)
+		}
+	}
+	return nil
+}
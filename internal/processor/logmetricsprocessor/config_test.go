@@ -0,0 +1,140 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.MetricsExporter = "nop"
+	expected.Events.Enabled = true
+	assert.Equal(t, expected, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "all")]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "all")),
+		MetricsExporter:   "nop/derived",
+		Metrics: []MetricConfig{
+			{
+				Name:            "http.requests",
+				Description:     "The number of HTTP requests by status code",
+				MatchAttributes: map[string]string{"http.method": "^(GET|POST)$"},
+				Dimensions:      []string{"http.status_code"},
+			},
+			{
+				Name:           "http.request.duration",
+				Type:           "gauge",
+				ValueAttribute: "duration_ms",
+			},
+		},
+		Events: EventsConfig{
+			Enabled:    true,
+			MetricName: "k8s.events",
+			Dimensions: []string{"k8s.namespace.name"},
+		},
+		FlushInterval: 30 * time.Second,
+		MaxSeries:     100,
+	}, p1)
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, test := range []struct {
+		name   string
+		modify func(cfg *Config)
+		err    string
+	}{
+		{
+			name:   "missing exporter",
+			modify: func(cfg *Config) { cfg.MetricsExporter = "" },
+			err:    "metrics_exporter must not be empty",
+		},
+		{
+			name:   "nothing derived",
+			modify: func(cfg *Config) { cfg.Metrics = nil; cfg.Events.Enabled = false },
+			err:    "at least one of metrics or events must be configured",
+		},
+		{
+			name:   "flush interval",
+			modify: func(cfg *Config) { cfg.FlushInterval = 0 },
+			err:    "flush_interval must be positive, not 0s",
+		},
+		{
+			name:   "max series",
+			modify: func(cfg *Config) { cfg.MaxSeries = -1 },
+			err:    "max_series must not be negative, not -1",
+		},
+		{
+			name:   "events metric name",
+			modify: func(cfg *Config) { cfg.Events.MetricName = "" },
+			err:    "events: metric_name must not be empty",
+		},
+		{
+			name:   "missing name",
+			modify: func(cfg *Config) { cfg.Metrics[0].Name = "" },
+			err:    "metrics[0]: name must not be empty",
+		},
+		{
+			name:   "duplicate name",
+			modify: func(cfg *Config) { cfg.Metrics[0].Name = "signalfx.events" },
+			err:    `metrics[0]: duplicate metric name "signalfx.events"`,
+		},
+		{
+			name:   "unsupported type",
+			modify: func(cfg *Config) { cfg.Metrics[0].Type = "histogram" },
+			err:    `metrics[0]: unsupported type "histogram", must be "counter" or "gauge"`,
+		},
+		{
+			name:   "gauge without value",
+			modify: func(cfg *Config) { cfg.Metrics[0].Type = "gauge" },
+			err:    "metrics[0]: value_attribute must not be empty for gauges",
+		},
+		{
+			name:   "invalid pattern",
+			modify: func(cfg *Config) { cfg.Metrics[0].MatchAttributes = map[string]string{"level": "(error"} },
+			err:    `metrics[0]: match_attributes: invalid pattern for attribute "level": error parsing regexp: missing closing ): ` + "`(error`",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.MetricsExporter = "signalfx"
+			cfg.Events.Enabled = true
+			cfg.Metrics = []MetricConfig{{Name: "errors"}}
+			require.NoError(t, cfg.Validate())
+
+			test.modify(cfg)
+			require.EqualError(t, cfg.Validate(), test.err)
+		})
+	}
+}
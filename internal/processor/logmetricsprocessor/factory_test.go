@@ -0,0 +1,55 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestCreateDefaultConfig(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	assert.NotNil(t, cfg, "failed to create default config")
+	assert.NoError(t, configtest.CheckConfigStruct(cfg))
+	assert.Equal(t, 10*time.Second, cfg.FlushInterval)
+	assert.Equal(t, 1000, cfg.MaxSeries)
+	assert.Equal(t, "signalfx.events", cfg.Events.MetricName)
+	assert.False(t, cfg.Events.Enabled)
+	assert.EqualError(t, cfg.Validate(), "metrics_exporter must not be empty")
+}
+
+func TestCreateLogsProcessor(t *testing.T) {
+	factory := NewFactory()
+	cfg := factory.CreateDefaultConfig().(*Config)
+	cfg.MetricsExporter = "signalfx"
+	cfg.Events.Enabled = true
+	params := componenttest.NewNopProcessorCreateSettings()
+
+	lp, err := factory.CreateLogsProcessor(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.False(t, lp.Capabilities().MutatesData)
+
+	err = lp.Start(context.Background(), componenttest.NewNopHost())
+	require.EqualError(t, err, `metrics_exporter "signalfx" not found in the metrics pipelines, available exporters: []`)
+	require.NoError(t, lp.Shutdown(context.Background()))
+}
@@ -0,0 +1,415 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+const (
+	// eventTypeAttribute and eventCategoryAttribute are the datapoint attributes of the events metric.
+	eventTypeAttribute     = "event_type"
+	eventCategoryAttribute = "event_category"
+	// defaultEventCategory is the category SignalFx assigns to events without one.
+	defaultEventCategory = "USER_DEFINED"
+)
+
+// categoryNames are the SignalFx event category names in the SignalFx API by their value.
+var categoryNames = func() map[int64]string {
+	names := make(map[int64]string, len(converter.SFxEventCategories))
+	for name, category := range converter.SFxEventCategories {
+		names[int64(category)] = name
+	}
+	return names
+}()
+
+// now is the source of the datapoint timestamps, replaced in tests.
+var now = time.Now
+
+type logMetricsProcessor struct {
+	logger   *zap.Logger
+	exporter consumer.Metrics
+	done     chan struct{}
+	metrics  []*derivedMetric
+	events   *derivedMetric
+	cfg      Config
+	wg       sync.WaitGroup
+	// lock guards the series of all the metrics
+	lock sync.Mutex
+}
+
+// derivedMetric is a metric derived from the log records, and its series by dimension values.
+type derivedMetric struct {
+	matchers logrecord.AttributePatterns
+	series   map[string]*series
+	cfg      MetricConfig
+	// limitLogged is whether reaching max_series has been logged
+	limitLogged bool
+}
+
+// series is the aggregated value of a combination of dimension values.
+type series struct {
+	attributes map[string]string
+	start      pcommon.Timestamp
+	value      float64
+	// updated is whether a gauge was set since the last flush
+	updated bool
+}
+
+func newLogMetricsProcessor(cfg *Config, logger *zap.Logger) *logMetricsProcessor {
+	proc := &logMetricsProcessor{
+		cfg:    *cfg,
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+	for _, metricCfg := range cfg.Metrics {
+		metric := newDerivedMetric(metricCfg)
+		// the patterns are checked by Config.Validate
+		metric.matchers, _ = logrecord.CompileAttributePatterns(metricCfg.MatchAttributes)
+		proc.metrics = append(proc.metrics, metric)
+	}
+	if cfg.Events.Enabled {
+		proc.events = newDerivedMetric(MetricConfig{
+			Name:        cfg.Events.MetricName,
+			Description: "The number of SignalFx events by their type and category",
+			Type:        metricTypeCounter,
+			Dimensions:  cfg.Events.Dimensions,
+		})
+	}
+	return proc
+}
+
+func newDerivedMetric(cfg MetricConfig) *derivedMetric {
+	return &derivedMetric{
+		cfg:    cfg,
+		series: map[string]*series{},
+	}
+}
+
+// start looks up the metrics exporter and starts flushing the derived metrics to it.
+func (p *logMetricsProcessor) start(_ context.Context, host component.Host) error {
+	exporters := host.GetExporters()[config.MetricsDataType]
+	var available []string
+	for id, exp := range exporters {
+		if id.String() == p.cfg.MetricsExporter {
+			metricsExporter, ok := exp.(consumer.Metrics)
+			if !ok {
+				return fmt.Errorf("exporter %q is not a metrics exporter", p.cfg.MetricsExporter)
+			}
+			p.exporter = metricsExporter
+			break
+		}
+		available = append(available, id.String())
+	}
+	if p.exporter == nil {
+		sort.Strings(available)
+		return fmt.Errorf("metrics_exporter %q not found in the metrics pipelines, available exporters: %v", p.cfg.MetricsExporter, available)
+	}
+
+	p.wg.Add(1)
+	go func() {
+		defer p.wg.Done()
+		ticker := time.NewTicker(p.cfg.FlushInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				p.flush(context.Background())
+			case <-p.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+// shutdown stops the periodic flushes, and flushes the metrics derived since the last one.
+func (p *logMetricsProcessor) shutdown(ctx context.Context) error {
+	if p.exporter == nil {
+		return nil
+	}
+	close(p.done)
+	p.wg.Wait()
+	p.flush(ctx)
+	return nil
+}
+
+func (p *logMetricsProcessor) processLogs(_ context.Context, logs plog.Logs) (plog.Logs, error) {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	timestamp := pcommon.NewTimestampFromTime(now())
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				attrs := recordAttributes{
+					record:   lr.Attributes(),
+					resource: rl.Resource().Attributes(),
+				}
+				for _, metric := range p.metrics {
+					p.derive(metric, attrs, timestamp)
+				}
+				if p.events != nil {
+					p.countEvent(attrs, timestamp)
+				}
+			}
+		}
+	}
+	return logs, nil
+}
+
+// derive updates the metric with the log record if it matches all the metric's patterns.
+func (p *logMetricsProcessor) derive(metric *derivedMetric, attrs recordAttributes, timestamp pcommon.Timestamp) {
+	for key, matcher := range metric.matchers {
+		value, ok := attrs.get(key)
+		if !ok || !matcher.MatchString(value.AsString()) {
+			return
+		}
+	}
+
+	increment := 1.0
+	if metric.cfg.ValueAttribute != "" {
+		value, ok := attrs.get(metric.cfg.ValueAttribute)
+		if !ok {
+			return
+		}
+		if increment, ok = numericValue(value); !ok {
+			p.logger.Debug("ignoring non-numeric value attribute",
+				zap.String("metric", metric.cfg.Name),
+				zap.String("attribute", metric.cfg.ValueAttribute),
+				zap.String("value", value.AsString()))
+			return
+		}
+		if increment < 0 && metric.cfg.Type != metricTypeGauge {
+			// counters are sent as monotonic sums
+			p.logger.Debug("ignoring negative value attribute of counter",
+				zap.String("metric", metric.cfg.Name),
+				zap.String("attribute", metric.cfg.ValueAttribute),
+				zap.Float64("value", increment))
+			return
+		}
+	}
+
+	dimensions := map[string]string{}
+	for _, key := range metric.cfg.Dimensions {
+		if value, ok := attrs.get(key); ok {
+			dimensions[key] = value.AsString()
+		}
+	}
+	p.update(metric, dimensions, increment, timestamp)
+}
+
+// countEvent counts the log record if it's a SignalFx event, whose category attribute is present even if null.
+func (p *logMetricsProcessor) countEvent(attrs recordAttributes, timestamp pcommon.Timestamp) {
+	category, ok := attrs.record.Get(converter.SFxEventCategoryKey)
+	if !ok {
+		return
+	}
+
+	dimensions := map[string]string{}
+	for _, key := range p.events.cfg.Dimensions {
+		if value, ok := attrs.get(key); ok {
+			dimensions[key] = value.AsString()
+		}
+	}
+	dimensions[eventCategoryAttribute] = categoryName(category)
+	if eventType, ok := attrs.record.Get(converter.SFxEventType); ok {
+		dimensions[eventTypeAttribute] = eventType.AsString()
+	}
+	p.update(p.events, dimensions, 1, timestamp)
+}
+
+// update adds the value to the series of the dimensions for counters, or sets it for gauges.
+func (p *logMetricsProcessor) update(metric *derivedMetric, dimensions map[string]string, value float64, timestamp pcommon.Timestamp) {
+	key := seriesKey(dimensions)
+	s, ok := metric.series[key]
+	if !ok {
+		if p.cfg.MaxSeries > 0 && len(metric.series) >= p.cfg.MaxSeries {
+			if !metric.limitLogged {
+				p.logger.Warn("max_series reached, ignoring log records with new dimension values",
+					zap.String("metric", metric.cfg.Name),
+					zap.Int("max_series", p.cfg.MaxSeries))
+				metric.limitLogged = true
+			}
+			return
+		}
+		s = &series{attributes: dimensions, start: timestamp}
+		metric.series[key] = s
+	}
+
+	if metric.cfg.Type == metricTypeGauge {
+		s.value = value
+	} else {
+		s.value += value
+	}
+	s.updated = true
+}
+
+// flush sends the cumulative counters, and the gauges set since the last flush, to the metrics exporter.
+func (p *logMetricsProcessor) flush(ctx context.Context) {
+	md := p.buildMetrics()
+	if md.DataPointCount() == 0 {
+		return
+	}
+	if err := p.exporter.ConsumeMetrics(ctx, md); err != nil {
+		p.logger.Error("failed exporting the metrics derived from logs", zap.Error(err))
+	}
+}
+
+func (p *logMetricsProcessor) buildMetrics() pmetric.Metrics {
+	p.lock.Lock()
+	defer p.lock.Unlock()
+
+	md := pmetric.NewMetrics()
+	sm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(typeStr)
+
+	timestamp := pcommon.NewTimestampFromTime(now())
+	metrics := p.metrics
+	if p.events != nil {
+		metrics = append(metrics[:len(metrics):len(metrics)], p.events)
+	}
+	for _, metric := range metrics {
+		if len(metric.series) == 0 {
+			continue
+		}
+		m := pmetric.NewMetric()
+		m.SetName(metric.cfg.Name)
+		m.SetDescription(metric.cfg.Description)
+
+		var dps pmetric.NumberDataPointSlice
+		if metric.cfg.Type == metricTypeGauge {
+			m.SetDataType(pmetric.MetricDataTypeGauge)
+			dps = m.Gauge().DataPoints()
+		} else {
+			m.SetDataType(pmetric.MetricDataTypeSum)
+			m.Sum().SetIsMonotonic(true)
+			m.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+			dps = m.Sum().DataPoints()
+		}
+
+		keys := make([]string, 0, len(metric.series))
+		for key := range metric.series {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			s := metric.series[key]
+			if metric.cfg.Type == metricTypeGauge && !s.updated {
+				continue
+			}
+			s.updated = false
+
+			dp := dps.AppendEmpty()
+			dp.SetTimestamp(timestamp)
+			if metric.cfg.Type == metricTypeGauge {
+				dp.SetDoubleVal(s.value)
+			} else {
+				dp.SetStartTimestamp(s.start)
+				if metric.cfg.ValueAttribute == "" {
+					dp.SetIntVal(int64(s.value))
+				} else {
+					dp.SetDoubleVal(s.value)
+				}
+			}
+			for _, attr := range logrecord.SortedKeys(s.attributes) {
+				dp.Attributes().InsertString(attr, s.attributes[attr])
+			}
+		}
+		if dps.Len() > 0 {
+			m.MoveTo(sm.Metrics().AppendEmpty())
+		}
+	}
+	return md
+}
+
+// recordAttributes are the attributes applying to a log record, looked up in order of precedence.
+type recordAttributes struct {
+	record   pcommon.Map
+	resource pcommon.Map
+}
+
+// get returns the value of the key in the record attributes, the SignalFx event properties,
+// or the resource attributes, in this order.
+func (a recordAttributes) get(key string) (pcommon.Value, bool) {
+	if value, ok := a.record.Get(key); ok {
+		return value, true
+	}
+	if properties, ok := a.record.Get(converter.SFxEventPropertiesKey); ok && properties.Type() == pcommon.ValueTypeMap {
+		if value, ok := properties.MapVal().Get(key); ok {
+			return value, true
+		}
+	}
+	return a.resource.Get(key)
+}
+
+// numericValue returns the value of numeric attributes, and of strings parsing as numbers.
+func numericValue(value pcommon.Value) (float64, bool) {
+	switch value.Type() {
+	case pcommon.ValueTypeInt:
+		return float64(value.IntVal()), true
+	case pcommon.ValueTypeDouble:
+		return value.DoubleVal(), true
+	case pcommon.ValueTypeString:
+		f, err := strconv.ParseFloat(strings.TrimSpace(value.StringVal()), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// categoryName returns the SignalFx API name of the event category attribute value.
+func categoryName(category pcommon.Value) string {
+	if category.Type() != pcommon.ValueTypeInt {
+		return defaultEventCategory
+	}
+	if name, ok := categoryNames[category.IntVal()]; ok {
+		return name
+	}
+	return strconv.FormatInt(category.IntVal(), 10)
+}
+
+// seriesKey identifies the combination of the dimension values.
+func seriesKey(dimensions map[string]string) string {
+	var b strings.Builder
+	for _, key := range logrecord.SortedKeys(dimensions) {
+		b.WriteString(key)
+		b.WriteByte(0)
+		b.WriteString(dimensions[key])
+		b.WriteByte(0)
+	}
+	return b.String()
+}
@@ -0,0 +1,351 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logmetricsprocessor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.MetricsExporter = "nop/derived"
+	cfg.Metrics = []MetricConfig{
+		{
+			Name:            "http.requests",
+			MatchAttributes: map[string]string{"http.method": "^(GET|POST)$"},
+			Dimensions:      []string{"http.status_code", "service.name"},
+		},
+		{
+			Name:           "http.response.bytes",
+			ValueAttribute: "http.response_content_length",
+		},
+		{
+			Name:           "queue.depth",
+			Type:           metricTypeGauge,
+			ValueAttribute: "depth",
+			Dimensions:     []string{"queue"},
+		},
+	}
+	cfg.Events = EventsConfig{
+		Enabled:    true,
+		MetricName: "signalfx.events",
+		Dimensions: []string{"k8s.namespace.name"},
+	}
+	return cfg
+}
+
+func newTestLogs(resourceAttrs map[string]string, records ...func(lr plog.LogRecord)) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	for k, v := range resourceAttrs {
+		rl.Resource().Attributes().InsertString(k, v)
+	}
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, record := range records {
+		record(lrs.AppendEmpty())
+	}
+	return ld
+}
+
+// datapoints returns the values of the datapoints by their metric name and sorted attributes.
+func datapoints(md pmetric.Metrics) map[string]any {
+	values := map[string]any{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		sms := rms.At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				var dps pmetric.NumberDataPointSlice
+				if m.DataType() == pmetric.MetricDataTypeSum {
+					dps = m.Sum().DataPoints()
+				} else {
+					dps = m.Gauge().DataPoints()
+				}
+				for l := 0; l < dps.Len(); l++ {
+					dp := dps.At(l)
+					var attrs []string
+					dp.Attributes().Range(func(k string, v pcommon.Value) bool {
+						attrs = append(attrs, k+"="+v.AsString())
+						return true
+					})
+					sort.Strings(attrs)
+					key := fmt.Sprintf("%s{%s}", m.Name(), strings.Join(attrs, ","))
+					if dp.ValueType() == pmetric.NumberDataPointValueTypeInt {
+						values[key] = dp.IntVal()
+					} else {
+						values[key] = dp.DoubleVal()
+					}
+				}
+			}
+		}
+	}
+	return values
+}
+
+func TestDeriveMetrics(t *testing.T) {
+	proc := newLogMetricsProcessor(testConfig(), zap.NewNop())
+
+	ld := newTestLogs(
+		map[string]string{"service.name": "checkout"},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("http.method", "GET")
+			lr.Attributes().InsertInt("http.status_code", 200)
+			lr.Attributes().InsertInt("http.response_content_length", 512)
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("http.method", "POST")
+			lr.Attributes().InsertInt("http.status_code", 200)
+			lr.Attributes().InsertString("http.response_content_length", "256.5")
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("http.method", "GET")
+			lr.Attributes().InsertInt("http.status_code", 500)
+			lr.Attributes().InsertString("http.response_content_length", "unknown")
+		},
+		func(lr plog.LogRecord) {
+			// negative values are ignored by counters
+			lr.Attributes().InsertString("http.method", "GET")
+			lr.Attributes().InsertInt("http.status_code", 500)
+			lr.Attributes().InsertInt("http.response_content_length", -100)
+		},
+		func(lr plog.LogRecord) {
+			// not matching http.method
+			lr.Attributes().InsertString("http.method", "DELETE")
+			lr.Attributes().InsertInt("http.status_code", 200)
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("queue", "orders")
+			lr.Attributes().InsertInt("depth", 12)
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("queue", "orders")
+			lr.Attributes().InsertDouble("depth", -7)
+		},
+	)
+
+	out, err := proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	assert.Equal(t, ld, out)
+
+	assert.Equal(t, map[string]any{
+		"http.requests{http.status_code=200,service.name=checkout}": int64(2),
+		"http.requests{http.status_code=500,service.name=checkout}": int64(2),
+		"http.response.bytes{}":     768.5,
+		"queue.depth{queue=orders}": -7.0,
+	}, datapoints(proc.buildMetrics()))
+}
+
+func TestCountEvents(t *testing.T) {
+	proc := newLogMetricsProcessor(testConfig(), zap.NewNop())
+
+	properties := func(lr plog.LogRecord, namespace string) {
+		props := pcommon.NewValueMap()
+		props.MapVal().InsertString("k8s.namespace.name", namespace)
+		lr.Attributes().Insert(converter.SFxEventPropertiesKey, props)
+	}
+	ld := newTestLogs(
+		map[string]string{"k8s.namespace.name": "payments"},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertInt(converter.SFxEventCategoryKey, int64(event.ALERT))
+			lr.Attributes().InsertString(converter.SFxEventType, "deploy.failed")
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertInt(converter.SFxEventCategoryKey, int64(event.ALERT))
+			lr.Attributes().InsertString(converter.SFxEventType, "deploy.failed")
+			// the event properties take precedence over the resource attributes
+			properties(lr, "default")
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertNull(converter.SFxEventCategoryKey)
+			lr.Attributes().InsertString(converter.SFxEventType, "backup")
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertInt(converter.SFxEventCategoryKey, 42)
+			lr.Attributes().InsertString(converter.SFxEventType, "backup")
+		},
+		func(lr plog.LogRecord) {
+			// not an event
+			lr.Attributes().InsertString(converter.SFxEventType, "backup")
+		},
+	)
+
+	_, err := proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"signalfx.events{event_category=ALERT,event_type=deploy.failed,k8s.namespace.name=payments}": int64(1),
+		"signalfx.events{event_category=ALERT,event_type=deploy.failed,k8s.namespace.name=default}":  int64(1),
+		"signalfx.events{event_category=USER_DEFINED,event_type=backup,k8s.namespace.name=payments}": int64(1),
+		"signalfx.events{event_category=42,event_type=backup,k8s.namespace.name=payments}":           int64(1),
+	}, datapoints(proc.buildMetrics()))
+}
+
+func TestFlushes(t *testing.T) {
+	defer func(n func() time.Time) { now = n }(now)
+	start := time.Unix(1000, 0)
+	now = func() time.Time { return start }
+
+	proc := newLogMetricsProcessor(testConfig(), zap.NewNop())
+	ld := newTestLogs(nil,
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("http.method", "GET")
+			lr.Attributes().InsertString("queue", "orders")
+			lr.Attributes().InsertInt("depth", 12)
+		},
+	)
+	_, err := proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	md := proc.buildMetrics()
+	assert.Equal(t, map[string]any{
+		"http.requests{}":           int64(1),
+		"queue.depth{queue=orders}": 12.0,
+	}, datapoints(md))
+	assert.Equal(t, 0, md.ResourceMetrics().At(0).Resource().Attributes().Len())
+	assert.Equal(t, typeStr, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Scope().Name())
+
+	now = func() time.Time { return start.Add(time.Minute) }
+	_, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	// the counters are cumulative
+	md = proc.buildMetrics()
+	assert.Equal(t, map[string]any{
+		"http.requests{}":           int64(2),
+		"queue.depth{queue=orders}": 12.0,
+	}, datapoints(md))
+	counter := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "http.requests", counter.Name())
+	assert.Equal(t, pmetric.MetricAggregationTemporalityCumulative, counter.Sum().AggregationTemporality())
+	assert.True(t, counter.Sum().IsMonotonic())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start), counter.Sum().DataPoints().At(0).StartTimestamp())
+	assert.Equal(t, pcommon.NewTimestampFromTime(start.Add(time.Minute)), counter.Sum().DataPoints().At(0).Timestamp())
+
+	// the gauges are only sent when updated since the last flush
+	assert.Equal(t, map[string]any{
+		"http.requests{}": int64(2),
+	}, datapoints(proc.buildMetrics()))
+}
+
+func TestMaxSeries(t *testing.T) {
+	cfg := testConfig()
+	cfg.MaxSeries = 2
+	proc := newLogMetricsProcessor(cfg, zap.NewNop())
+
+	var records []func(lr plog.LogRecord)
+	for _, code := range []int64{200, 404, 500, 200} {
+		code := code
+		records = append(records, func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("http.method", "GET")
+			lr.Attributes().InsertInt("http.status_code", code)
+		})
+	}
+	_, err := proc.processLogs(context.Background(), newTestLogs(nil, records...))
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"http.requests{http.status_code=200}": int64(2),
+		"http.requests{http.status_code=404}": int64(1),
+	}, datapoints(proc.buildMetrics()))
+}
+
+type metricsExporter struct {
+	consumertest.MetricsSink
+}
+
+func (*metricsExporter) Start(context.Context, component.Host) error { return nil }
+
+func (*metricsExporter) Shutdown(context.Context) error { return nil }
+
+type exportersHost struct {
+	component.Host
+	exporters map[config.DataType]map[config.ComponentID]component.Exporter
+}
+
+func (h exportersHost) GetExporters() map[config.DataType]map[config.ComponentID]component.Exporter {
+	return h.exporters
+}
+
+func TestExportMetrics(t *testing.T) {
+	cfg := testConfig()
+	cfg.FlushInterval = 10 * time.Millisecond
+	exporter := &metricsExporter{}
+	host := exportersHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.MetricsDataType: {
+				config.NewComponentID("nop"):                    &metricsExporter{},
+				config.NewComponentIDWithName("nop", "derived"): exporter,
+			},
+		},
+	}
+
+	lp, err := NewFactory().CreateLogsProcessor(context.Background(), componenttest.NewNopProcessorCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, lp.Start(context.Background(), host))
+
+	ld := newTestLogs(nil, func(lr plog.LogRecord) {
+		lr.Attributes().InsertString("http.method", "GET")
+	})
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+	require.Eventually(t, func() bool {
+		return len(exporter.AllMetrics()) > 0
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, map[string]any{"http.requests{}": int64(1)}, datapoints(exporter.AllMetrics()[0]))
+
+	// shutting down flushes the metrics once more
+	require.NoError(t, lp.ConsumeLogs(context.Background(), ld))
+	require.NoError(t, lp.Shutdown(context.Background()))
+	all := exporter.AllMetrics()
+	assert.Equal(t, map[string]any{"http.requests{}": int64(2)}, datapoints(all[len(all)-1]))
+}
+
+func TestMissingMetricsExporter(t *testing.T) {
+	host := exportersHost{
+		Host: componenttest.NewNopHost(),
+		exporters: map[config.DataType]map[config.ComponentID]component.Exporter{
+			config.MetricsDataType: {
+				config.NewComponentID("nop"):      &metricsExporter{},
+				config.NewComponentID("signalfx"): &metricsExporter{},
+			},
+		},
+	}
+
+	proc := newLogMetricsProcessor(testConfig(), zap.NewNop())
+	err := proc.start(context.Background(), host)
+	require.EqualError(t, err, `metrics_exporter "nop/derived" not found in the metrics pipelines, available exporters: [nop signalfx]`)
+	require.NoError(t, proc.shutdown(context.Background()))
+}
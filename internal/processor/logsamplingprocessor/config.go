@@ -0,0 +1,118 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	"go.opentelemetry.io/collector/config"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+)
+
+// Config defines configuration for the log sampling processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// SamplingSettings apply to the records of source types without their own.
+	SamplingSettings `mapstructure:",squash"`
+	// SourcetypeAttribute is the record attribute, or resource attribute if the record doesn't
+	// have it, whose value is the source type of a record.
+	SourcetypeAttribute string `mapstructure:"sourcetype_attribute"`
+	// Sourcetypes are the sampling settings of specific source types.
+	Sourcetypes map[string]SamplingSettings `mapstructure:"sourcetypes"`
+	// KeepSeverity is the severity (TRACE, DEBUG, INFO, WARN, ERROR, FATAL) at or above which
+	// records are always kept. Severity isn't considered if empty.
+	KeepSeverity string `mapstructure:"keep_severity"`
+	// KeepRules are the attribute patterns of records that are always kept.
+	KeepRules []KeepRule `mapstructure:"keep_rules"`
+}
+
+// SamplingSettings determine the records of each (source type, severity) key that are kept.
+// Settings of source types that aren't set are those of the processor.
+type SamplingSettings struct {
+	// SamplingPercentage is the percentage of records that are kept, from 0 to 100.
+	SamplingPercentage *float64 `mapstructure:"sampling_percentage"`
+	// MaxRecordsPerSecond is the maximum rate of sampled records that are kept. 0 disables rate limiting.
+	MaxRecordsPerSecond *float64 `mapstructure:"max_records_per_second"`
+}
+
+// KeepRule keeps records satisfying all of its attribute patterns.
+type KeepRule struct {
+	// Attributes maps record attribute keys to regular expressions their string form must match.
+	Attributes map[string]string `mapstructure:"attributes"`
+	// ResourceAttributes maps resource attribute keys to regular expressions their string form must match.
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.SourcetypeAttribute == "" {
+		return errors.New("sourcetype_attribute must not be empty")
+	}
+	if err := cfg.SamplingSettings.validate(); err != nil {
+		return err
+	}
+	sourcetypes := make([]string, 0, len(cfg.Sourcetypes))
+	for sourcetype := range cfg.Sourcetypes {
+		sourcetypes = append(sourcetypes, sourcetype)
+	}
+	sort.Strings(sourcetypes)
+	for _, sourcetype := range sourcetypes {
+		if err := cfg.Sourcetypes[sourcetype].validate(); err != nil {
+			return fmt.Errorf("sourcetype %q: %w", sourcetype, err)
+		}
+	}
+	if _, ok := logrecord.Severities[cfg.KeepSeverity]; cfg.KeepSeverity != "" && !ok {
+		return fmt.Errorf("unsupported keep_severity %q", cfg.KeepSeverity)
+	}
+	for i, rule := range cfg.KeepRules {
+		if len(rule.Attributes) == 0 && len(rule.ResourceAttributes) == 0 {
+			return fmt.Errorf("keep rule %d must set at least one of attributes or resource_attributes", i)
+		}
+		if _, err := logrecord.CompileAttributePatterns(rule.Attributes); err != nil {
+			return fmt.Errorf("keep rule %d: %w", i, err)
+		}
+		if _, err := logrecord.CompileAttributePatterns(rule.ResourceAttributes); err != nil {
+			return fmt.Errorf("keep rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (settings SamplingSettings) validate() error {
+	if p := settings.SamplingPercentage; p != nil && (*p < 0 || *p > 100) {
+		return fmt.Errorf("sampling_percentage must be between 0 and 100, not %v", *p)
+	}
+	if r := settings.MaxRecordsPerSecond; r != nil && *r < 0 {
+		return fmt.Errorf("max_records_per_second must not be negative, not %v", *r)
+	}
+	return nil
+}
+
+// merge returns the settings with those that aren't set taken from the provided defaults.
+func (settings SamplingSettings) merge(defaults SamplingSettings) SamplingSettings {
+	if settings.SamplingPercentage == nil {
+		settings.SamplingPercentage = defaults.SamplingPercentage
+	}
+	if settings.MaxRecordsPerSecond == nil {
+		settings.MaxRecordsPerSecond = defaults.MaxRecordsPerSecond
+	}
+	return settings
+}
@@ -0,0 +1,89 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func float64Ptr(v float64) *float64 {
+	return &v
+}
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		SamplingSettings: SamplingSettings{
+			SamplingPercentage:  float64Ptr(50),
+			MaxRecordsPerSecond: float64Ptr(100),
+		},
+		SourcetypeAttribute: "sourcetype",
+		Sourcetypes: map[string]SamplingSettings{
+			"kube:container:chatty": {SamplingPercentage: float64Ptr(10), MaxRecordsPerSecond: float64Ptr(5.5)},
+			"kube:container:audit":  {SamplingPercentage: float64Ptr(100)},
+		},
+		KeepSeverity: "WARN",
+		KeepRules: []KeepRule{
+			{Attributes: map[string]string{"http.status_code": `^5\d\d$`}},
+			{ResourceAttributes: map[string]string{"k8s.namespace.name": "^payments$"}},
+		},
+	}, p1)
+
+	// the default config's settings aren't shared with those of loaded ones
+	assert.Equal(t, float64Ptr(100), factory.CreateDefaultConfig().(*Config).SamplingPercentage)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "invalid_percentage.yaml", err: `sampling_percentage must be between 0 and 100, not 101`},
+		{file: "invalid_sourcetype.yaml", err: `sourcetype "chatty": max_records_per_second must not be negative, not -1`},
+		{file: "invalid_severity.yaml", err: `unsupported keep_severity "CRITICAL"`},
+		{file: "empty_keep_rule.yaml", err: `keep rule 0 must set at least one of attributes or resource_attributes`},
+		{file: "invalid_pattern.yaml", err: `keep rule 0: invalid pattern for attribute "service"`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+	"golang.org/x/time/rate"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+)
+
+type keepRule struct {
+	attributes         logrecord.AttributePatterns
+	resourceAttributes logrecord.AttributePatterns
+}
+
+// sampling is the resolved SamplingSettings of a source type.
+type sampling struct {
+	percentage          float64
+	maxRecordsPerSecond float64
+}
+
+func newSampling(settings SamplingSettings) sampling {
+	resolved := sampling{percentage: 100}
+	if settings.SamplingPercentage != nil {
+		resolved.percentage = *settings.SamplingPercentage
+	}
+	if settings.MaxRecordsPerSecond != nil {
+		resolved.maxRecordsPerSecond = *settings.MaxRecordsPerSecond
+	}
+	return resolved
+}
+
+// limiterSweepInterval is how often the limiters of keys without recent records are removed.
+const limiterSweepInterval = time.Minute
+
+// samplingKey identifies the records sampled and rate limited together.
+type samplingKey struct {
+	sourcetype string
+	severity   string
+}
+
+// limiter rate limits the records of a key.
+type limiter struct {
+	*rate.Limiter
+	lastUsed time.Time
+	// refill is how long the limiter takes to refill its burst, after which it's in the same state as a new one
+	refill time.Duration
+}
+
+type samplingProcessor struct {
+	sourcetypeAttribute string
+	defaults            sampling
+	sourcetypes         map[string]sampling
+	keepSeverity        plog.SeverityNumber
+	keepRules           []keepRule
+
+	// lock guards the limiters and random source, which aren't safe for concurrent use
+	lock      sync.Mutex
+	limiters  map[samplingKey]*limiter
+	lastSweep time.Time
+	random    func() float64
+	now       func() time.Time
+}
+
+func newSamplingProcessor(cfg *Config) (*samplingProcessor, error) {
+	proc := &samplingProcessor{
+		sourcetypeAttribute: cfg.SourcetypeAttribute,
+		defaults:            newSampling(cfg.SamplingSettings),
+		sourcetypes:         map[string]sampling{},
+		keepSeverity:        logrecord.Severities[cfg.KeepSeverity],
+		limiters:            map[samplingKey]*limiter{},
+		random:              rand.New(rand.NewSource(time.Now().UnixNano())).Float64,
+		now:                 time.Now,
+	}
+	for sourcetype, settings := range cfg.Sourcetypes {
+		proc.sourcetypes[sourcetype] = newSampling(settings.merge(cfg.SamplingSettings))
+	}
+	for _, r := range cfg.KeepRules {
+		attributes, err := logrecord.CompileAttributePatterns(r.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		resourceAttributes, err := logrecord.CompileAttributePatterns(r.ResourceAttributes)
+		if err != nil {
+			return nil, err
+		}
+		proc.keepRules = append(proc.keepRules, keepRule{attributes: attributes, resourceAttributes: resourceAttributes})
+	}
+	return proc, nil
+}
+
+func (proc *samplingProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	proc.lock.Lock()
+	defer proc.lock.Unlock()
+	proc.sweepLimiters()
+
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceAttrs := rl.Resource().Attributes()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			sls.At(j).LogRecords().RemoveIf(func(lr plog.LogRecord) bool {
+				return !proc.keep(resourceAttrs, lr)
+			})
+		}
+		sls.RemoveIf(func(sl plog.ScopeLogs) bool {
+			return sl.LogRecords().Len() == 0
+		})
+	}
+	rls.RemoveIf(func(rl plog.ResourceLogs) bool {
+		return rl.ScopeLogs().Len() == 0
+	})
+
+	if rls.Len() == 0 {
+		return ld, processorhelper.ErrSkipProcessingData
+	}
+	return ld, nil
+}
+
+// keep determines whether the record is kept, either by satisfying the keep severity or rules
+// or by being sampled within the rate limit of its (source type, severity) key.
+func (proc *samplingProcessor) keep(resourceAttrs pcommon.Map, lr plog.LogRecord) bool {
+	severity := recordSeverity(lr)
+	if proc.keepSeverity != plog.SeverityNumberUNDEFINED && severity >= proc.keepSeverity {
+		return true
+	}
+	for _, r := range proc.keepRules {
+		if r.resourceAttributes.Match(resourceAttrs) && r.attributes.Match(lr.Attributes()) {
+			return true
+		}
+	}
+
+	key := samplingKey{
+		sourcetype: logrecord.AttributeValue(resourceAttrs, lr, proc.sourcetypeAttribute),
+		severity:   logrecord.SeverityName(severity),
+	}
+	settings, ok := proc.sourcetypes[key.sourcetype]
+	if !ok {
+		settings = proc.defaults
+	}
+
+	if settings.percentage < 100 && proc.random()*100 >= settings.percentage {
+		return false
+	}
+	if settings.maxRecordsPerSecond == 0 {
+		return true
+	}
+	now := proc.now()
+	l, ok := proc.limiters[key]
+	if !ok {
+		burst := math.Max(1, math.Ceil(settings.maxRecordsPerSecond))
+		l = &limiter{
+			Limiter: rate.NewLimiter(rate.Limit(settings.maxRecordsPerSecond), int(burst)),
+			refill:  time.Duration(burst / settings.maxRecordsPerSecond * float64(time.Second)),
+		}
+		proc.limiters[key] = l
+	}
+	l.lastUsed = now
+	return l.AllowN(now, 1)
+}
+
+// sweepLimiters removes the limiters that weren't used for long enough to refill their burst every
+// limiterSweepInterval, so that the limiters of high cardinality keys don't accumulate.  Since they'd
+// allow the same records as new limiters, removing them doesn't change which records are kept.
+func (proc *samplingProcessor) sweepLimiters() {
+	now := proc.now()
+	if now.Sub(proc.lastSweep) < limiterSweepInterval {
+		return
+	}
+	proc.lastSweep = now
+	for key, l := range proc.limiters {
+		if now.Sub(l.lastUsed) >= l.refill {
+			delete(proc.limiters, key)
+		}
+	}
+}
+
+// recordSeverity returns the record's severity number, or that of its severity
+// text if the number is unspecified.
+func recordSeverity(lr plog.LogRecord) plog.SeverityNumber {
+	if number := lr.SeverityNumber(); number != plog.SeverityNumberUNDEFINED {
+		return number
+	}
+	return logrecord.Severities[strings.ToUpper(lr.SeverityText())]
+}
@@ -0,0 +1,259 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package logsamplingprocessor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/processor/processorhelper"
+)
+
+type testRecord struct {
+	attributes   map[string]string
+	name         string
+	sourcetype   string
+	severityText string
+	severity     plog.SeverityNumber
+}
+
+func newLogs(resourceAttrs map[string]string, records ...testRecord) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	for k, v := range resourceAttrs {
+		rl.Resource().Attributes().InsertString(k, v)
+	}
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, r := range records {
+		lr := lrs.AppendEmpty()
+		lr.Body().SetStringVal(r.name)
+		lr.SetSeverityNumber(r.severity)
+		lr.SetSeverityText(r.severityText)
+		if r.sourcetype != "" {
+			lr.Attributes().InsertString("com.splunk.sourcetype", r.sourcetype)
+		}
+		for k, v := range r.attributes {
+			lr.Attributes().InsertString(k, v)
+		}
+	}
+	return ld
+}
+
+func keptNames(ld plog.Logs) []string {
+	var names []string
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				names = append(names, lrs.At(k).Body().StringVal())
+			}
+		}
+	}
+	return names
+}
+
+func newTestProcessor(t *testing.T, modify func(*Config)) *samplingProcessor {
+	cfg := createDefaultConfig().(*Config)
+	modify(cfg)
+	require.NoError(t, cfg.Validate())
+	proc, err := newSamplingProcessor(cfg)
+	require.NoError(t, err)
+	return proc
+}
+
+func TestDefaultConfigKeepsAllRecords(t *testing.T) {
+	proc := newTestProcessor(t, func(*Config) {})
+	ld, err := proc.processLogs(context.Background(), newLogs(nil,
+		testRecord{name: "one", sourcetype: "app", severity: plog.SeverityNumberDEBUG},
+		testRecord{name: "two", sourcetype: "app", severity: plog.SeverityNumberINFO},
+		testRecord{name: "three"},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "two", "three"}, keptNames(ld))
+}
+
+func TestKeepSeverityAndRules(t *testing.T) {
+	proc := newTestProcessor(t, func(cfg *Config) {
+		*cfg.SamplingPercentage = 0
+		cfg.KeepRules = []KeepRule{
+			{Attributes: map[string]string{"http.status_code": `^5\d\d$`}},
+			{ResourceAttributes: map[string]string{"k8s.namespace.name": "^payments$"}},
+		}
+	})
+
+	ld, err := proc.processLogs(context.Background(), newLogs(nil,
+		testRecord{name: "info", severity: plog.SeverityNumberINFO},
+		testRecord{name: "error", severity: plog.SeverityNumberERROR},
+		testRecord{name: "fatal", severity: plog.SeverityNumberFATAL2},
+		testRecord{name: "error text", severityText: "error"},
+		testRecord{name: "warn text", severityText: "WARN"},
+		testRecord{name: "server error", severity: plog.SeverityNumberINFO, attributes: map[string]string{"http.status_code": "503"}},
+		testRecord{name: "client error", severity: plog.SeverityNumberINFO, attributes: map[string]string{"http.status_code": "404"}},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"error", "fatal", "error text", "server error"}, keptNames(ld))
+
+	ld, err = proc.processLogs(context.Background(), newLogs(map[string]string{"k8s.namespace.name": "payments"},
+		testRecord{name: "payments", severity: plog.SeverityNumberDEBUG},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"payments"}, keptNames(ld))
+}
+
+func TestKeepSeverityDisabled(t *testing.T) {
+	proc := newTestProcessor(t, func(cfg *Config) {
+		*cfg.SamplingPercentage = 0
+		cfg.KeepSeverity = ""
+	})
+
+	ld, err := proc.processLogs(context.Background(), newLogs(nil,
+		testRecord{name: "error", severity: plog.SeverityNumberERROR},
+	))
+	assert.ErrorIs(t, err, processorhelper.ErrSkipProcessingData)
+	assert.Equal(t, 0, ld.ResourceLogs().Len())
+}
+
+func TestSamplingPercentage(t *testing.T) {
+	proc := newTestProcessor(t, func(cfg *Config) {
+		*cfg.SamplingPercentage = 25
+		cfg.Sourcetypes = map[string]SamplingSettings{
+			"audit": {SamplingPercentage: float64Ptr(100)},
+		}
+	})
+	randoms := []float64{0.1, 0.3, 0.2, 0.9}
+	proc.random = func() float64 {
+		r := randoms[0]
+		randoms = randoms[1:]
+		return r
+	}
+
+	ld, err := proc.processLogs(context.Background(), newLogs(nil,
+		testRecord{name: "one", sourcetype: "app", severity: plog.SeverityNumberINFO},
+		testRecord{name: "two", sourcetype: "app", severity: plog.SeverityNumberINFO},
+		testRecord{name: "audit", sourcetype: "audit", severity: plog.SeverityNumberINFO},
+		testRecord{name: "three", sourcetype: "app", severity: plog.SeverityNumberINFO},
+		testRecord{name: "four", sourcetype: "app", severity: plog.SeverityNumberINFO},
+	))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"one", "audit", "three"}, keptNames(ld))
+	assert.Empty(t, randoms)
+}
+
+func TestRateLimitPerKey(t *testing.T) {
+	proc := newTestProcessor(t, func(cfg *Config) {
+		*cfg.MaxRecordsPerSecond = 2
+		cfg.Sourcetypes = map[string]SamplingSettings{
+			"chatty": {MaxRecordsPerSecond: float64Ptr(1)},
+		}
+	})
+	now := time.Unix(1000, 0)
+	proc.now = func() time.Time { return now }
+
+	batch := func() plog.Logs {
+		return newLogs(map[string]string{"com.splunk.sourcetype": "app"},
+			testRecord{name: "info 1", severity: plog.SeverityNumberINFO},
+			testRecord{name: "info 2", severity: plog.SeverityNumberINFO2},
+			testRecord{name: "info 3", severity: plog.SeverityNumberINFO},
+			testRecord{name: "warn 1", severity: plog.SeverityNumberWARN},
+			testRecord{name: "chatty 1", sourcetype: "chatty", severity: plog.SeverityNumberINFO},
+			testRecord{name: "chatty 2", sourcetype: "chatty", severity: plog.SeverityNumberINFO},
+			testRecord{name: "error", severity: plog.SeverityNumberERROR},
+		)
+	}
+
+	ld, err := proc.processLogs(context.Background(), batch())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info 1", "info 2", "warn 1", "chatty 1", "error"}, keptNames(ld))
+
+	// the second warn record is still within the burst of its key
+	ld, err = proc.processLogs(context.Background(), batch())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"warn 1", "error"}, keptNames(ld))
+
+	now = now.Add(time.Second)
+	ld, err = proc.processLogs(context.Background(), batch())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"info 1", "info 2", "warn 1", "chatty 1", "error"}, keptNames(ld))
+}
+
+func TestIdleLimitersRemoved(t *testing.T) {
+	proc := newTestProcessor(t, func(cfg *Config) {
+		*cfg.MaxRecordsPerSecond = 0.5
+	})
+	now := time.Unix(1000, 0)
+	proc.now = func() time.Time { return now }
+
+	batch := func(sourcetype string) plog.Logs {
+		return newLogs(nil,
+			testRecord{name: sourcetype + " 1", sourcetype: sourcetype, severity: plog.SeverityNumberINFO},
+			testRecord{name: sourcetype + " 2", sourcetype: sourcetype, severity: plog.SeverityNumberINFO},
+		)
+	}
+
+	ld, err := proc.processLogs(context.Background(), batch("app"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"app 1"}, keptNames(ld))
+	for i := 0; i < 10; i++ {
+		ld, err = proc.processLogs(context.Background(), batch(fmt.Sprintf("batch-%d", i)))
+		require.NoError(t, err)
+		assert.Equal(t, []string{fmt.Sprintf("batch-%d 1", i)}, keptNames(ld))
+	}
+	assert.Len(t, proc.limiters, 11)
+
+	// the sweep removes the idle limiters before the batch's records are rate limited
+	now = now.Add(limiterSweepInterval)
+	_, err = proc.processLogs(context.Background(), batch("app"))
+	require.NoError(t, err)
+	assert.Len(t, proc.limiters, 1)
+
+	_, err = proc.processLogs(context.Background(), batch("recent"))
+	require.NoError(t, err)
+	now = now.Add(limiterSweepInterval - time.Second)
+	_, err = proc.processLogs(context.Background(), batch("other"))
+	require.NoError(t, err)
+	assert.Len(t, proc.limiters, 3)
+
+	// the limiters used within the time to refill their burst are kept
+	now = now.Add(time.Second)
+	ld, err = proc.processLogs(context.Background(), batch("recent"))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"recent 1"}, keptNames(ld))
+	assert.Len(t, proc.limiters, 2)
+}
+
+func TestEmptyContainersRemoved(t *testing.T) {
+	proc := newTestProcessor(t, func(cfg *Config) {
+		cfg.Sourcetypes = map[string]SamplingSettings{
+			"noisy": {SamplingPercentage: float64Ptr(0)},
+		}
+	})
+
+	ld := newLogs(nil, testRecord{name: "noisy", sourcetype: "noisy", severity: plog.SeverityNumberINFO})
+	kept := newLogs(nil, testRecord{name: "kept", sourcetype: "app", severity: plog.SeverityNumberINFO})
+	kept.ResourceLogs().At(0).CopyTo(ld.ResourceLogs().AppendEmpty())
+	ld.ResourceLogs().At(0).ScopeLogs().AppendEmpty()
+
+	ld, err := proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+	require.Equal(t, 1, ld.ResourceLogs().Len())
+	assert.Equal(t, []string{"kept"}, keptNames(ld))
+}
@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pseudonymizationprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	// MethodHash replaces values with the hex encoded HMAC-SHA256 of the salt and value.
+	MethodHash = "hash"
+	// MethodTokenize replaces values with the token prefix and the first token_length hex characters of their hash.
+	MethodTokenize = "tokenize"
+
+	defaultTokenPrefix = "pii_"
+	defaultTokenLength = 16
+	minTokenLength     = 8
+	maxTokenLength     = 64
+	minSaltLength      = 16
+)
+
+// Config defines configuration for the pseudonymization processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Attributes are the attribute and map keys whose values are pseudonymized.
+	Attributes []string `mapstructure:"attributes"`
+	// AttributePatterns are regular expressions matching the attribute and map keys
+	// whose values are pseudonymized.
+	AttributePatterns []string `mapstructure:"attribute_patterns"`
+	// Salt is the secret key of the hashes, like a value retrieved from a config source.
+	Salt string `mapstructure:"salt"`
+	// Method is how values are pseudonymized, either "hash" or "tokenize".
+	Method string `mapstructure:"method"`
+	// TokenPrefix is the prefix of the tokens of the "tokenize" method.
+	TokenPrefix string `mapstructure:"token_prefix"`
+	// TokenLength is the number of hash characters of the tokens of the "tokenize" method.
+	TokenLength int `mapstructure:"token_length"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Attributes) == 0 && len(cfg.AttributePatterns) == 0 {
+		return errors.New("at least one of attributes or attribute_patterns must be provided")
+	}
+	for i, attribute := range cfg.Attributes {
+		if attribute == "" {
+			return fmt.Errorf("attribute %d must not be empty", i)
+		}
+	}
+	if _, err := compilePatterns(cfg.AttributePatterns); err != nil {
+		return fmt.Errorf("invalid attribute_patterns: %w", err)
+	}
+	if len(cfg.Salt) < minSaltLength {
+		return fmt.Errorf("salt must be at least %d bytes", minSaltLength)
+	}
+	switch cfg.Method {
+	case MethodHash:
+	case MethodTokenize:
+		if cfg.TokenLength < minTokenLength || cfg.TokenLength > maxTokenLength {
+			return fmt.Errorf("token_length must be between %d and %d", minTokenLength, maxTokenLength)
+		}
+	default:
+		return fmt.Errorf("method must be %q or %q, not %q", MethodHash, MethodTokenize, cfg.Method)
+	}
+	return nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pseudonymizationprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentID(typeStr)),
+		Attributes:        []string{"user.email"},
+		Salt:              "0123456789abcdef",
+		Method:            MethodHash,
+		TokenPrefix:       "pii_",
+		TokenLength:       16,
+	}, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "tokenize")]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "tokenize")),
+		Attributes:        []string{"user.email", "client.address"},
+		AttributePatterns: []string{`(?i)(^|\.)ssn$`},
+		Salt:              "fedcba9876543210",
+		Method:            MethodTokenize,
+		TokenPrefix:       "user-",
+		TokenLength:       12,
+	}, p1)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "short_salt.yaml", err: "salt must be at least 16 bytes"},
+		{file: "invalid_pattern.yaml", err: "invalid attribute_patterns: error parsing regexp"},
+		{file: "invalid_method.yaml", err: `method must be "hash" or "tokenize", not "encrypt"`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Salt = "0123456789abcdef"
+	require.EqualError(t, cfg.Validate(), "at least one of attributes or attribute_patterns must be provided")
+
+	cfg.Attributes = []string{""}
+	require.EqualError(t, cfg.Validate(), "attribute 0 must not be empty")
+
+	cfg.Attributes = []string{"user.email"}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Method = MethodTokenize
+	cfg.TokenLength = 4
+	require.EqualError(t, cfg.Validate(), "token_length must be between 8 and 64")
+}
@@ -0,0 +1,224 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pseudonymizationprocessor
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"regexp"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type pseudonymizationProcessor struct {
+	attributes  map[string]struct{}
+	patterns    []*regexp.Regexp
+	salt        []byte
+	tokenize    bool
+	tokenPrefix string
+	tokenLength int
+}
+
+func newPseudonymizationProcessor(cfg *Config) (*pseudonymizationProcessor, error) {
+	patterns, err := compilePatterns(cfg.AttributePatterns)
+	if err != nil {
+		return nil, err
+	}
+	attributes := make(map[string]struct{}, len(cfg.Attributes))
+	for _, attribute := range cfg.Attributes {
+		attributes[attribute] = struct{}{}
+	}
+	return &pseudonymizationProcessor{
+		attributes:  attributes,
+		patterns:    patterns,
+		salt:        []byte(cfg.Salt),
+		tokenize:    cfg.Method == MethodTokenize,
+		tokenPrefix: cfg.TokenPrefix,
+		tokenLength: cfg.TokenLength,
+	}, nil
+}
+
+func (proc *pseudonymizationProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	mac := proc.newMAC()
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		proc.processMap(mac, rs.Resource().Attributes())
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				proc.processMap(mac, span.Attributes())
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					proc.processMap(mac, events.At(l).Attributes())
+				}
+				links := span.Links()
+				for l := 0; l < links.Len(); l++ {
+					proc.processMap(mac, links.At(l).Attributes())
+				}
+			}
+		}
+	}
+	return td, nil
+}
+
+func (proc *pseudonymizationProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	mac := proc.newMAC()
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		proc.processMap(mac, rm.Resource().Attributes())
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				proc.processMetric(mac, metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (proc *pseudonymizationProcessor) processMetric(mac hash.Hash, metric pmetric.Metric) {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.processMap(mac, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.processMap(mac, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.processMap(mac, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.processMap(mac, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.processMap(mac, dps.At(i).Attributes())
+		}
+	}
+}
+
+func (proc *pseudonymizationProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	mac := proc.newMAC()
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		proc.processMap(mac, rl.Resource().Attributes())
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				// SignalFx event properties are a map attribute and processed with the others.
+				proc.processMap(mac, lr.Attributes())
+				proc.processNested(mac, lr.Body())
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (proc *pseudonymizationProcessor) newMAC() hash.Hash {
+	return hmac.New(sha256.New, proc.salt)
+}
+
+// processMap pseudonymizes the values of the configured keys, including those of nested maps and slices.
+func (proc *pseudonymizationProcessor) processMap(mac hash.Hash, m pcommon.Map) {
+	m.Range(func(k string, v pcommon.Value) bool {
+		if proc.isPseudonymizedKey(k) {
+			proc.pseudonymizeValue(mac, v)
+			return true
+		}
+		proc.processNested(mac, v)
+		return true
+	})
+}
+
+func (proc *pseudonymizationProcessor) processNested(mac hash.Hash, v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		proc.processMap(mac, v.MapVal())
+	case pcommon.ValueTypeSlice:
+		values := v.SliceVal()
+		for i := 0; i < values.Len(); i++ {
+			proc.processNested(mac, values.At(i))
+		}
+	}
+}
+
+// pseudonymizeValue replaces the value, or all the values nested in it, with their pseudonyms.  Values are
+// pseudonymized by their string representation and empty values are kept.
+func (proc *pseudonymizationProcessor) pseudonymizeValue(mac hash.Hash, v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeEmpty:
+	case pcommon.ValueTypeMap:
+		v.MapVal().Range(func(_ string, nested pcommon.Value) bool {
+			proc.pseudonymizeValue(mac, nested)
+			return true
+		})
+	case pcommon.ValueTypeSlice:
+		values := v.SliceVal()
+		for i := 0; i < values.Len(); i++ {
+			proc.pseudonymizeValue(mac, values.At(i))
+		}
+	default:
+		if value := v.AsString(); value != "" {
+			v.SetStringVal(proc.pseudonym(mac, value))
+		}
+	}
+}
+
+// pseudonym returns the hex encoded HMAC-SHA256 of the value, or its token when tokenizing.  The same
+// value always has the same pseudonym for a salt, so pseudonymized values can still be grouped and correlated.
+func (proc *pseudonymizationProcessor) pseudonym(mac hash.Hash, value string) string {
+	mac.Reset()
+	mac.Write([]byte(value))
+	sum := hex.EncodeToString(mac.Sum(nil))
+	if proc.tokenize {
+		return proc.tokenPrefix + sum[:proc.tokenLength]
+	}
+	return sum
+}
+
+func (proc *pseudonymizationProcessor) isPseudonymizedKey(key string) bool {
+	if _, ok := proc.attributes[key]; ok {
+		return true
+	}
+	for _, re := range proc.patterns {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,180 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pseudonymizationprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	// HMAC-SHA256 of the values with the "0123456789abcdef" salt
+	emailHash = "a7a54661c75bce906717ca553fdff1b9e2c578d8c764c8e7d23ff39b7b5096b1"
+	idHash    = "0a701f96e5731551f6635af638b2b489461bde5532c65ac899e88e7ae5f8f616"
+	ssnHash   = "249d675a58148e16b879e0d05cd870964af6b58b30a25dd533e0724995bdda65"
+)
+
+func newTestProcessor(t *testing.T, method string) *pseudonymizationProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Attributes = []string{"user.email", "user.id", "user"}
+	cfg.AttributePatterns = []string{`(?i)(^|\.)ssn$`}
+	cfg.Salt = "0123456789abcdef"
+	cfg.Method = method
+	require.NoError(t, cfg.Validate())
+	proc, err := newPseudonymizationProcessor(cfg)
+	require.NoError(t, err)
+	return proc
+}
+
+func TestPseudonym(t *testing.T) {
+	proc := newTestProcessor(t, MethodHash)
+	mac := proc.newMAC()
+	assert.Equal(t, emailHash, proc.pseudonym(mac, "jane@example.com"))
+	// the hash is reset between values
+	assert.Equal(t, emailHash, proc.pseudonym(mac, "jane@example.com"))
+	assert.Equal(t, idHash, proc.pseudonym(mac, "12345"))
+
+	tokenizer := newTestProcessor(t, MethodTokenize)
+	assert.Equal(t, "pii_"+emailHash[:16], tokenizer.pseudonym(tokenizer.newMAC(), "jane@example.com"))
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Salt = "another salt value"
+	other, err := newPseudonymizationProcessor(cfg)
+	require.NoError(t, err)
+	assert.NotEqual(t, emailHash, other.pseudonym(other.newMAC(), "jane@example.com"))
+}
+
+func TestPseudonymizedKeys(t *testing.T) {
+	proc := newTestProcessor(t, MethodHash)
+	for key, expected := range map[string]bool{
+		"user.email":    true,
+		"user":          true,
+		"customer.SSN":  true,
+		"ssn":           true,
+		"user.name":     false,
+		"ssn.verified":  false,
+		"user.email.ok": false,
+	} {
+		assert.Equal(t, expected, proc.isPseudonymizedKey(key), key)
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("user.email", "jane@example.com")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStringVal("user.email=jane@example.com")
+	lr.Attributes().InsertInt("user.id", 12345)
+	lr.Attributes().InsertString("user.name", "Jane")
+	lr.Attributes().InsertString("customer.ssn", "")
+	properties := pcommon.NewValueMap()
+	properties.MapVal().InsertString("ssn", "123-45-6789")
+	properties.MapVal().InsertString("detail", "jane@example.com")
+	lr.Attributes().Insert("com.splunk.signalfx.event_properties", properties)
+
+	_, err := newTestProcessor(t, MethodHash).processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"user.email": emailHash}, rl.Resource().Attributes().AsRaw())
+	// string bodies aren't searched for values
+	assert.Equal(t, "user.email=jane@example.com", lr.Body().StringVal())
+	assert.Equal(t, map[string]any{
+		"user.id":      idHash,
+		"user.name":    "Jane",
+		"customer.ssn": "",
+		"com.splunk.signalfx.event_properties": map[string]any{
+			"ssn":    ssnHash,
+			"detail": "jane@example.com",
+		},
+	}, lr.Attributes().AsRaw())
+}
+
+func TestProcessLogsNestedBody(t *testing.T) {
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	body := pcommon.NewValueMap()
+	user := pcommon.NewValueMap()
+	user.MapVal().InsertString("email", "jane@example.com")
+	ids := pcommon.NewValueSlice()
+	ids.SliceVal().AppendEmpty().SetIntVal(12345)
+	user.MapVal().Insert("ids", ids)
+	body.MapVal().Insert("user", user)
+	entries := pcommon.NewValueSlice()
+	entry := pcommon.NewValueMap()
+	entry.MapVal().InsertString("ssn", "123-45-6789")
+	entry.CopyTo(entries.SliceVal().AppendEmpty())
+	body.MapVal().Insert("entries", entries)
+	body.CopyTo(lr.Body())
+
+	_, err := newTestProcessor(t, MethodHash).processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"user": map[string]any{
+			"email": emailHash,
+			"ids":   []any{idHash},
+		},
+		"entries": []any{map[string]any{"ssn": ssnHash}},
+	}, lr.Body().MapVal().AsRaw())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("user.email", "jane@example.com")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("user.id", "12345")
+	histogram := metrics.AppendEmpty()
+	histogram.SetDataType(pmetric.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("ssn", "123-45-6789")
+
+	_, err := newTestProcessor(t, MethodTokenize).processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"user.email": "pii_" + emailHash[:16]}, rm.Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"user.id": "pii_" + idHash[:16]},
+		gauge.Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"ssn": "pii_" + ssnHash[:16]},
+		histogram.Histogram().DataPoints().At(0).Attributes().AsRaw())
+}
+
+func TestProcessTraces(t *testing.T) {
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("user.email", "jane@example.com")
+	span.Attributes().InsertString("http.url", "https://example.com/users/12345")
+	span.Events().AppendEmpty().Attributes().InsertString("user.id", "12345")
+	span.Links().AppendEmpty().Attributes().InsertString("customer.ssn", "123-45-6789")
+
+	_, err := newTestProcessor(t, MethodHash).processTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"user.email": emailHash,
+		"http.url":   "https://example.com/users/12345",
+	}, span.Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"user.id": idHash}, span.Events().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"customer.ssn": ssnHash}, span.Links().At(0).Attributes().AsRaw())
+}
@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxeventprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+const defaultCategory = "USER_DEFINED"
+
+// Config defines configuration for the SignalFx event processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// SeverityCategories maps log record severities (TRACE, DEBUG, INFO, WARN, ERROR, FATAL)
+	// to the category of events from rules without their own. USER_DEFINED is used otherwise.
+	SeverityCategories map[string]string `mapstructure:"severity_categories"`
+	// BodyProperty is the event property set to a record's string body. Bodies are
+	// not made properties if empty.
+	BodyProperty string `mapstructure:"body_property"`
+	// Rules are evaluated in order for every record, which is converted to an event
+	// by the first matching one. Unmatched records are left unchanged.
+	Rules []Rule `mapstructure:"rules"`
+}
+
+// Rule converts records satisfying all of its attribute patterns to events.
+// A Rule without patterns matches every record.
+type Rule struct {
+	// Attributes maps record attribute keys to regular expressions their string form must match.
+	Attributes map[string]string `mapstructure:"attributes"`
+	// ResourceAttributes maps resource attribute keys to regular expressions their string form must match.
+	ResourceAttributes map[string]string `mapstructure:"resource_attributes"`
+	// EventType is the event type, or the fallback for records without the EventTypeAttribute.
+	EventType string `mapstructure:"event_type"`
+	// EventTypeAttribute is the record attribute whose value is the event type.
+	EventTypeAttribute string `mapstructure:"event_type_attribute"`
+	// Category is the event category, overriding the severity_categories mapping.
+	Category string `mapstructure:"category"`
+	// Properties are the record attributes moved to event properties instead of being dimensions.
+	Properties []string `mapstructure:"properties"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Rules) == 0 {
+		return errors.New("at least one rule must be provided")
+	}
+	for _, severity := range logrecord.SortedKeys(cfg.SeverityCategories) {
+		if _, ok := logrecord.Severities[severity]; !ok {
+			return fmt.Errorf("unsupported severity %q in severity_categories", severity)
+		}
+		if _, ok := converter.SFxEventCategories[cfg.SeverityCategories[severity]]; !ok {
+			return fmt.Errorf("unsupported category %q for severity %q", cfg.SeverityCategories[severity], severity)
+		}
+	}
+	for i, rule := range cfg.Rules {
+		if rule.EventType == "" && rule.EventTypeAttribute == "" {
+			return fmt.Errorf("rule %d must set at least one of event_type or event_type_attribute", i)
+		}
+		if _, ok := converter.SFxEventCategories[rule.Category]; rule.Category != "" && !ok {
+			return fmt.Errorf("rule %d: unsupported category %q", i, rule.Category)
+		}
+		if _, err := logrecord.CompileAttributePatterns(rule.Attributes); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+		if _, err := logrecord.CompileAttributePatterns(rule.ResourceAttributes); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxeventprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.Rules = []Rule{{EventType: "otel.log"}}
+	assert.Equal(t, expected, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		BodyProperty:      "message",
+		SeverityCategories: map[string]string{
+			"ERROR": "ALERT",
+			"FATAL": "ALERT",
+		},
+		Rules: []Rule{
+			{
+				ResourceAttributes: map[string]string{"k8s.namespace.name": "^payments$"},
+				Attributes:         map[string]string{"k8s.event.reason": "^(BackOff|Failed)$"},
+				EventTypeAttribute: "k8s.event.reason",
+				Category:           "EXCEPTION",
+				Properties:         []string{"k8s.event.uid"},
+			},
+			{EventTypeAttribute: "event.name", EventType: "otel.log"},
+		},
+	}, p1)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "empty_rule.yaml", err: `rule 0 must set at least one of event_type or event_type_attribute`},
+		{file: "invalid_category.yaml", err: `unsupported category "CRITICAL" for severity "ERROR"`},
+		{file: "invalid_pattern.yaml", err: `rule 0: invalid pattern for attribute "service"`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.EqualError(t, cfg.Validate(), "at least one rule must be provided")
+
+	cfg.Rules = []Rule{{EventType: "otel.log", Category: "UNKNOWN"}}
+	require.EqualError(t, cfg.Validate(), `rule 0: unsupported category "UNKNOWN"`)
+
+	cfg.Rules = []Rule{{EventType: "otel.log"}}
+	cfg.SeverityCategories = map[string]string{"WARNING": "ALERT"}
+	require.EqualError(t, cfg.Validate(), `unsupported severity "WARNING" in severity_categories`)
+}
@@ -0,0 +1,158 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxeventprocessor
+
+import (
+	"context"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/internal/logrecord"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+type rule struct {
+	attributes         logrecord.AttributePatterns
+	resourceAttributes logrecord.AttributePatterns
+	category           *int64
+	eventType          string
+	eventTypeAttribute string
+	properties         []string
+}
+
+type eventProcessor struct {
+	severityCategories map[string]int64
+	bodyProperty       string
+	rules              []rule
+}
+
+func newEventProcessor(cfg *Config) (*eventProcessor, error) {
+	proc := &eventProcessor{
+		severityCategories: map[string]int64{},
+		bodyProperty:       cfg.BodyProperty,
+	}
+	for severity, category := range cfg.SeverityCategories {
+		proc.severityCategories[severity] = int64(converter.SFxEventCategories[category])
+	}
+	for _, r := range cfg.Rules {
+		attributes, err := logrecord.CompileAttributePatterns(r.Attributes)
+		if err != nil {
+			return nil, err
+		}
+		resourceAttributes, err := logrecord.CompileAttributePatterns(r.ResourceAttributes)
+		if err != nil {
+			return nil, err
+		}
+		converted := rule{
+			attributes:         attributes,
+			resourceAttributes: resourceAttributes,
+			eventType:          r.EventType,
+			eventTypeAttribute: r.EventTypeAttribute,
+			properties:         r.Properties,
+		}
+		if r.Category != "" {
+			category := int64(converter.SFxEventCategories[r.Category])
+			converted.category = &category
+		}
+		proc.rules = append(proc.rules, converted)
+	}
+	return proc, nil
+}
+
+func (proc *eventProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceAttrs := rl.Resource().Attributes()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				proc.convert(resourceAttrs, lrs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// convert sets the SignalFx event attributes of the record from the first matching rule
+// that can determine its event type.
+func (proc *eventProcessor) convert(resourceAttrs pcommon.Map, lr plog.LogRecord) {
+	attrs := lr.Attributes()
+	for _, r := range proc.rules {
+		if !r.matches(resourceAttrs, attrs) {
+			continue
+		}
+		eventType, ok := r.resolveEventType(attrs)
+		if !ok {
+			continue
+		}
+
+		category := proc.severityCategory(lr)
+		if r.category != nil {
+			category = *r.category
+		}
+
+		properties := pcommon.NewValueMap()
+		if existing, found := attrs.Get(converter.SFxEventPropertiesKey); found && existing.Type() == pcommon.ValueTypeMap {
+			existing.CopyTo(properties)
+		}
+		propertiesMap := properties.MapVal()
+		for _, property := range r.properties {
+			if value, found := attrs.Get(property); found {
+				propertiesMap.Upsert(property, value)
+				attrs.Remove(property)
+			}
+		}
+		if proc.bodyProperty != "" && lr.Body().Type() == pcommon.ValueTypeString && lr.Body().StringVal() != "" {
+			propertiesMap.UpsertString(proc.bodyProperty, lr.Body().StringVal())
+		}
+
+		attrs.UpsertInt(converter.SFxEventCategoryKey, category)
+		attrs.UpsertString(converter.SFxEventType, eventType)
+		if propertiesMap.Len() > 0 {
+			attrs.Upsert(converter.SFxEventPropertiesKey, properties)
+		}
+		return
+	}
+}
+
+// severityCategory returns the category mapped to the record's severity number, or its severity
+// text if the number is unspecified.
+func (proc *eventProcessor) severityCategory(lr plog.LogRecord) int64 {
+	severity := logrecord.SeverityName(lr.SeverityNumber())
+	if severity == "" {
+		severity = strings.ToUpper(lr.SeverityText())
+	}
+	if category, ok := proc.severityCategories[severity]; ok {
+		return category
+	}
+	return int64(converter.SFxEventCategories[defaultCategory])
+}
+
+func (r rule) matches(resourceAttrs, recordAttrs pcommon.Map) bool {
+	return r.resourceAttributes.Match(resourceAttrs) && r.attributes.Match(recordAttrs)
+}
+
+func (r rule) resolveEventType(recordAttrs pcommon.Map) (string, bool) {
+	if r.eventTypeAttribute != "" {
+		if value, ok := recordAttrs.Get(r.eventTypeAttribute); ok && value.AsString() != "" {
+			return value.AsString(), true
+		}
+	}
+	return r.eventType, r.eventType != ""
+}
@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxeventprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.BodyProperty = "message"
+	cfg.SeverityCategories = map[string]string{"ERROR": "ALERT", "FATAL": "ALERT"}
+	cfg.Rules = []Rule{
+		{
+			ResourceAttributes: map[string]string{"k8s.namespace.name": "^payments$"},
+			Attributes:         map[string]string{"k8s.event.reason": "^(BackOff|Failed)$"},
+			EventTypeAttribute: "k8s.event.reason",
+			Category:           "EXCEPTION",
+			Properties:         []string{"k8s.event.uid"},
+		},
+		{EventTypeAttribute: "event.name"},
+		{
+			Attributes: map[string]string{"service": "^api$"},
+			EventType:  "api.log",
+		},
+	}
+	return cfg
+}
+
+func newTestLogs(resourceAttrs map[string]string, records ...func(lr plog.LogRecord)) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	for k, v := range resourceAttrs {
+		rl.Resource().Attributes().InsertString(k, v)
+	}
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	for _, record := range records {
+		record(lrs.AppendEmpty())
+	}
+	return ld
+}
+
+func TestProcessLogs(t *testing.T) {
+	proc, err := newEventProcessor(testConfig())
+	require.NoError(t, err)
+
+	ld := newTestLogs(
+		map[string]string{"k8s.namespace.name": "payments"},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("k8s.event.reason", "BackOff")
+			lr.Attributes().InsertString("k8s.event.uid", "1234")
+			lr.Body().SetStringVal("Back-off restarting failed container")
+		},
+		func(lr plog.LogRecord) {
+			lr.SetSeverityNumber(plog.SeverityNumberERROR2)
+			lr.Attributes().InsertString("event.name", "deploy.failed")
+		},
+		func(lr plog.LogRecord) {
+			lr.SetSeverityText("fatal")
+			lr.Attributes().InsertString("service", "api")
+		},
+		func(lr plog.LogRecord) {
+			lr.SetSeverityNumber(plog.SeverityNumberINFO)
+			lr.Attributes().InsertString("service", "api")
+			lr.Attributes().Insert(converter.SFxEventPropertiesKey, func() pcommon.Value {
+				properties := pcommon.NewValueMap()
+				properties.MapVal().InsertString("existing", "property")
+				return properties
+			}())
+		},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("service", "web")
+		},
+	)
+
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	lrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 5, lrs.Len())
+
+	assert.Equal(t, map[string]any{
+		"k8s.event.reason":            "BackOff",
+		converter.SFxEventCategoryKey: int64(event.EXCEPTION),
+		converter.SFxEventType:        "BackOff",
+		converter.SFxEventPropertiesKey: map[string]any{
+			"k8s.event.uid": "1234",
+			"message":       "Back-off restarting failed container",
+		},
+	}, lrs.At(0).Attributes().AsRaw())
+
+	assert.Equal(t, map[string]any{
+		"event.name":                  "deploy.failed",
+		converter.SFxEventCategoryKey: int64(event.ALERT),
+		converter.SFxEventType:        "deploy.failed",
+	}, lrs.At(1).Attributes().AsRaw())
+
+	assert.Equal(t, map[string]any{
+		"service":                     "api",
+		converter.SFxEventCategoryKey: int64(event.ALERT),
+		converter.SFxEventType:        "api.log",
+	}, lrs.At(2).Attributes().AsRaw())
+
+	assert.Equal(t, map[string]any{
+		"service":                     "api",
+		converter.SFxEventCategoryKey: int64(event.USERDEFINED),
+		converter.SFxEventType:        "api.log",
+		converter.SFxEventPropertiesKey: map[string]any{
+			"existing": "property",
+		},
+	}, lrs.At(3).Attributes().AsRaw())
+
+	// unmatched records aren't events
+	assert.Equal(t, map[string]any{"service": "web"}, lrs.At(4).Attributes().AsRaw())
+}
+
+func TestResourceAttributesMustMatch(t *testing.T) {
+	proc, err := newEventProcessor(testConfig())
+	require.NoError(t, err)
+
+	ld := newTestLogs(
+		map[string]string{"k8s.namespace.name": "checkout"},
+		func(lr plog.LogRecord) {
+			lr.Attributes().InsertString("k8s.event.reason", "BackOff")
+		},
+	)
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	assert.Equal(t, map[string]any{"k8s.event.reason": "BackOff"}, attrs.AsRaw())
+}
@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkroutingprocessor
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+)
+
+const resourceAttributes = "resource.attributes"
+
+var (
+	// resource.attributes["key"] == "value", attributes["key"] != nil
+	comparisonCondition = regexp.MustCompile(
+		`^\s*(resource\.attributes|attributes)\["([^"]+)"\]\s*(==|!=)\s*(nil|"(?:[^"\\]|\\.)*")\s*$`,
+	)
+	// IsMatch(attributes["key"], "pattern")
+	isMatchCondition = regexp.MustCompile(
+		`^\s*IsMatch\(\s*(resource\.attributes|attributes)\["([^"]+)"\]\s*,\s*("(?:[^"\\]|\\.)*")\s*\)\s*$`,
+	)
+)
+
+// condition is a parsed rule condition evaluated against the attributes
+// of a record and its resource.
+type condition struct {
+	pattern *regexp.Regexp
+	path    string
+	key     string
+	value   string
+	negate  bool
+	isNil   bool
+}
+
+func parseConditions(statements []string) ([]condition, error) {
+	conditions := make([]condition, 0, len(statements))
+	for _, statement := range statements {
+		cond, err := parseCondition(statement)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, cond)
+	}
+	return conditions, nil
+}
+
+func parseCondition(statement string) (condition, error) {
+	if match := comparisonCondition.FindStringSubmatch(statement); match != nil {
+		cond := condition{path: match[1], key: match[2], negate: match[3] == "!="}
+		if match[4] == "nil" {
+			cond.isNil = true
+			return cond, nil
+		}
+		value, err := strconv.Unquote(match[4])
+		if err != nil {
+			return condition{}, fmt.Errorf("invalid string literal in condition %q: %w", statement, err)
+		}
+		cond.value = value
+		return cond, nil
+	}
+	if match := isMatchCondition.FindStringSubmatch(statement); match != nil {
+		expr, err := strconv.Unquote(match[3])
+		if err != nil {
+			return condition{}, fmt.Errorf("invalid string literal in condition %q: %w", statement, err)
+		}
+		pattern, err := regexp.Compile(expr)
+		if err != nil {
+			return condition{}, fmt.Errorf("invalid pattern in condition %q: %w", statement, err)
+		}
+		return condition{path: match[1], key: match[2], pattern: pattern}, nil
+	}
+	return condition{}, fmt.Errorf("unsupported condition %q", statement)
+}
+
+func (cond condition) matches(resource, record pcommon.Map) bool {
+	attrs := record
+	if cond.path == resourceAttributes {
+		attrs = resource
+	}
+	value, ok := attrs.Get(cond.key)
+	switch {
+	case cond.pattern != nil:
+		return ok && cond.pattern.MatchString(value.AsString())
+	case cond.isNil:
+		return ok == cond.negate
+	default:
+		return (ok && value.AsString() == cond.value) != cond.negate
+	}
+}
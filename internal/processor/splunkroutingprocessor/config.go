@@ -0,0 +1,76 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkroutingprocessor
+
+import (
+	"errors"
+	"fmt"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	defaultIndexAttribute      = "com.splunk.index"
+	defaultSourceAttribute     = "com.splunk.source"
+	defaultSourcetypeAttribute = "com.splunk.sourcetype"
+)
+
+// Config defines configuration for the Splunk routing processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// IndexAttribute is the record attribute set to a matching rule's index.
+	IndexAttribute string `mapstructure:"index_attribute"`
+	// SourceAttribute is the record attribute set to a matching rule's source.
+	SourceAttribute string `mapstructure:"source_attribute"`
+	// SourcetypeAttribute is the record attribute set to a matching rule's sourcetype.
+	SourcetypeAttribute string `mapstructure:"sourcetype_attribute"`
+	// Rules are evaluated in order for every record. Each routing attribute is
+	// taken from the first matching rule that specifies it.
+	Rules []Rule `mapstructure:"rules"`
+	// Override determines whether routing attributes already set on a record
+	// are replaced by rule values.
+	Override bool `mapstructure:"override"`
+}
+
+// Rule assigns routing values to records satisfying all of its Conditions.
+// A Rule without Conditions matches every record.
+type Rule struct {
+	Index      string   `mapstructure:"index"`
+	Source     string   `mapstructure:"source"`
+	Sourcetype string   `mapstructure:"sourcetype"`
+	Conditions []string `mapstructure:"conditions"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if cfg.IndexAttribute == "" || cfg.SourceAttribute == "" || cfg.SourcetypeAttribute == "" {
+		return errors.New("index_attribute, source_attribute, and sourcetype_attribute must not be empty")
+	}
+	if len(cfg.Rules) == 0 {
+		return errors.New("at least one rule must be provided")
+	}
+	for i, rule := range cfg.Rules {
+		if rule.Index == "" && rule.Source == "" && rule.Sourcetype == "" {
+			return fmt.Errorf("rule %d must set at least one of index, source, or sourcetype", i)
+		}
+		if _, err := parseConditions(rule.Conditions); err != nil {
+			return fmt.Errorf("rule %d: %w", i, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkroutingprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.Rules = []Rule{{Sourcetype: "otel"}}
+	assert.Equal(t, expected, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "custom")]
+	assert.Equal(t, &Config{
+		ProcessorSettings:   config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "custom")),
+		IndexAttribute:      "index",
+		SourceAttribute:     "source",
+		SourcetypeAttribute: "sourcetype",
+		Override:            true,
+		Rules: []Rule{
+			{
+				Conditions: []string{
+					`resource.attributes["k8s.namespace.name"] == "payments"`,
+					`IsMatch(attributes["log.file.path"], "^/var/log/pods/.*")`,
+				},
+				Index:      "payments",
+				Sourcetype: "kube:container:payments",
+			},
+			{
+				Conditions: []string{`resource.attributes["k8s.namespace.name"] != nil`},
+				Index:      "kubernetes",
+			},
+			{Source: "otel", Sourcetype: "otel"},
+		},
+	}, p1)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "invalid_condition.yaml", err: `rule 0: unsupported condition "body == \"unsupported\""`},
+		{file: "empty_rule.yaml", err: `rule 0 must set at least one of index, source, or sourcetype`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestValidateRequiresRules(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.EqualError(t, cfg.Validate(), "at least one rule must be provided")
+
+	cfg.Rules = []Rule{{Index: "main"}}
+	cfg.IndexAttribute = ""
+	require.EqualError(t, cfg.Validate(), "index_attribute, source_attribute, and sourcetype_attribute must not be empty")
+}
@@ -0,0 +1,234 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkroutingprocessor
+
+import (
+	"context"
+	"sort"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+type rule struct {
+	routes     map[string]string
+	conditions []condition
+}
+
+type routingProcessor struct {
+	rules    []rule
+	override bool
+}
+
+func newRoutingProcessor(cfg *Config) (*routingProcessor, error) {
+	proc := &routingProcessor{override: cfg.Override}
+	for _, r := range cfg.Rules {
+		conditions, err := parseConditions(r.Conditions)
+		if err != nil {
+			return nil, err
+		}
+		routes := map[string]string{}
+		for attribute, value := range map[string]string{
+			cfg.IndexAttribute:      r.Index,
+			cfg.SourceAttribute:     r.Source,
+			cfg.SourcetypeAttribute: r.Sourcetype,
+		} {
+			if value != "" {
+				routes[attribute] = value
+			}
+		}
+		proc.rules = append(proc.rules, rule{conditions: conditions, routes: routes})
+	}
+	return proc, nil
+}
+
+func (proc *routingProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		resourceAttrs := rl.Resource().Attributes()
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				recordAttrs := lrs.At(k).Attributes()
+				proc.setRoutes(recordAttrs, proc.routes(resourceAttrs, recordAttrs))
+			}
+		}
+	}
+	return ld, nil
+}
+
+// processMetrics sets the routing attributes of the data points on their resource, since the splunk_hec
+// exporter only routes metrics by their resource attributes.  The resources of data points routed
+// differently are split into a resource for each of their routes.
+func (proc *routingProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	splits := pmetric.NewResourceMetricsSlice()
+	rms.RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+		resourceAttrs := rm.Resource().Attributes()
+		// the route key of each data point, in order, and the routes by key in their order of appearance
+		var keys, order []string
+		routes := map[string]map[string]string{}
+		sms := rm.ScopeMetrics()
+		for i := 0; i < sms.Len(); i++ {
+			metrics := sms.At(i).Metrics()
+			for j := 0; j < metrics.Len(); j++ {
+				for _, attrs := range dataPointAttributes(metrics.At(j)) {
+					route := proc.routes(resourceAttrs, attrs)
+					if !proc.override {
+						for attribute := range route {
+							if _, ok := resourceAttrs.Get(attribute); ok {
+								delete(route, attribute)
+							}
+						}
+					}
+					key := routeKey(route)
+					if _, ok := routes[key]; !ok {
+						routes[key] = route
+						order = append(order, key)
+					}
+					keys = append(keys, key)
+				}
+			}
+		}
+
+		switch len(order) {
+		case 0:
+			return false
+		case 1:
+			proc.setRoutes(resourceAttrs, routes[order[0]])
+			return false
+		}
+		for _, key := range order {
+			split := splits.AppendEmpty()
+			rm.CopyTo(split)
+			i := 0
+			removeDataPointsIf(split, func() bool {
+				remove := keys[i] != key
+				i++
+				return remove
+			})
+			proc.setRoutes(split.Resource().Attributes(), routes[key])
+		}
+		return true
+	})
+	splits.MoveAndAppendTo(rms)
+	return md, nil
+}
+
+// routes returns each routing attribute from the first matching rule that specifies it.
+func (proc *routingProcessor) routes(resourceAttrs, recordAttrs pcommon.Map) map[string]string {
+	routes := map[string]string{}
+	for _, r := range proc.rules {
+		if !r.matches(resourceAttrs, recordAttrs) {
+			continue
+		}
+		for attribute, value := range r.routes {
+			if _, ok := routes[attribute]; !ok {
+				routes[attribute] = value
+			}
+		}
+	}
+	return routes
+}
+
+// setRoutes sets the routing attributes, replacing those already set only if configured to override them.
+func (proc *routingProcessor) setRoutes(attrs pcommon.Map, routes map[string]string) {
+	for attribute, value := range routes {
+		if proc.override {
+			attrs.UpsertString(attribute, value)
+		} else {
+			attrs.InsertString(attribute, value)
+		}
+	}
+}
+
+// routeKey identifies the routes regardless of their order.
+func routeKey(routes map[string]string) string {
+	pairs := make([]string, 0, len(routes))
+	for attribute, value := range routes {
+		pairs = append(pairs, attribute+"="+value)
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "\x00")
+}
+
+// dataPointAttributes returns the attributes of each data point of the metric, in order.
+func dataPointAttributes(metric pmetric.Metric) []pcommon.Map {
+	var attrs []pcommon.Map
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			attrs = append(attrs, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			attrs = append(attrs, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			attrs = append(attrs, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			attrs = append(attrs, dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			attrs = append(attrs, dps.At(i).Attributes())
+		}
+	}
+	return attrs
+}
+
+// removeDataPointsIf removes the data points of the resource, in the order of dataPointAttributes, for which
+// remove returns true, along with the metrics and scopes left without any.
+func removeDataPointsIf(rm pmetric.ResourceMetrics, remove func() bool) {
+	rm.ScopeMetrics().RemoveIf(func(sm pmetric.ScopeMetrics) bool {
+		sm.Metrics().RemoveIf(func(metric pmetric.Metric) bool {
+			switch metric.DataType() {
+			case pmetric.MetricDataTypeGauge:
+				metric.Gauge().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return remove() })
+			case pmetric.MetricDataTypeSum:
+				metric.Sum().DataPoints().RemoveIf(func(pmetric.NumberDataPoint) bool { return remove() })
+			case pmetric.MetricDataTypeHistogram:
+				metric.Histogram().DataPoints().RemoveIf(func(pmetric.HistogramDataPoint) bool { return remove() })
+			case pmetric.MetricDataTypeExponentialHistogram:
+				metric.ExponentialHistogram().DataPoints().RemoveIf(func(pmetric.ExponentialHistogramDataPoint) bool { return remove() })
+			case pmetric.MetricDataTypeSummary:
+				metric.Summary().DataPoints().RemoveIf(func(pmetric.SummaryDataPoint) bool { return remove() })
+			}
+			return len(dataPointAttributes(metric)) == 0
+		})
+		return sm.Metrics().Len() == 0
+	})
+}
+
+func (r rule) matches(resourceAttrs, recordAttrs pcommon.Map) bool {
+	for _, cond := range r.conditions {
+		if !cond.matches(resourceAttrs, recordAttrs) {
+			return false
+		}
+	}
+	return true
+}
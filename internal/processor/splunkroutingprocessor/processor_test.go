@@ -0,0 +1,271 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package splunkroutingprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func testConfig() *Config {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Rules = []Rule{
+		{
+			Conditions: []string{
+				`resource.attributes["k8s.namespace.name"] == "payments"`,
+				`IsMatch(attributes["log.file.path"], "^/var/log/pods/.*")`,
+			},
+			Index:      "payments",
+			Sourcetype: "kube:container:payments",
+		},
+		{
+			Conditions: []string{`resource.attributes["k8s.namespace.name"] != nil`},
+			Index:      "kubernetes",
+		},
+		{Source: "otel", Sourcetype: "otel"},
+	}
+	return cfg
+}
+
+func TestParseCondition(t *testing.T) {
+	for _, test := range []struct {
+		statement string
+		err       string
+	}{
+		{statement: `attributes["key"] == "value"`},
+		{statement: `resource.attributes["key"] != "escaped \"value\""`},
+		{statement: `attributes["key"] == nil`},
+		{statement: `IsMatch(resource.attributes["key"], "^v.*")`},
+		{statement: `body == "value"`, err: `unsupported condition "body == \"value\""`},
+		{statement: `attributes["key"] == value`, err: `unsupported condition "attributes[\"key\"] == value"`},
+		{statement: `IsMatch(attributes["key"], "[")`, err: "invalid pattern in condition"},
+	} {
+		t.Run(test.statement, func(t *testing.T) {
+			_, err := parseCondition(test.statement)
+			if test.err == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestConditionMatches(t *testing.T) {
+	resource := pcommon.NewMap()
+	resource.InsertString("k8s.namespace.name", "payments")
+	record := pcommon.NewMap()
+	record.InsertString("log.file.path", "/var/log/pods/payments.log")
+	record.InsertInt("status", 500)
+
+	for statement, expected := range map[string]bool{
+		`resource.attributes["k8s.namespace.name"] == "payments"`:   true,
+		`resource.attributes["k8s.namespace.name"] != "payments"`:   false,
+		`attributes["k8s.namespace.name"] == "payments"`:            false,
+		`attributes["k8s.namespace.name"] != "payments"`:            true,
+		`attributes["status"] == "500"`:                             true,
+		`attributes["missing"] == nil`:                              true,
+		`attributes["missing"] != nil`:                              false,
+		`resource.attributes["k8s.namespace.name"] != nil`:          true,
+		`IsMatch(attributes["log.file.path"], "^/var/log/pods/")`:   true,
+		`IsMatch(attributes["missing"], ".*")`:                      false,
+		`IsMatch(resource.attributes["k8s.namespace.name"], "^p")`:  true,
+		`IsMatch(resource.attributes["k8s.namespace.name"], "^pp")`: false,
+	} {
+		cond, err := parseCondition(statement)
+		require.NoError(t, err)
+		assert.Equal(t, expected, cond.matches(resource, record), statement)
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	proc, err := newRoutingProcessor(testConfig())
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("k8s.namespace.name", "payments")
+	lrs := rl.ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Attributes().InsertString("log.file.path", "/var/log/pods/payments.log")
+	lrs.AppendEmpty().Attributes().InsertString("log.file.path", "/tmp/other.log")
+	preset := lrs.AppendEmpty().Attributes()
+	preset.InsertString("com.splunk.index", "preset")
+
+	lrs = ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty()
+
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assertAttributes(t, map[string]string{
+		"log.file.path":         "/var/log/pods/payments.log",
+		"com.splunk.index":      "payments",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "kube:container:payments",
+	}, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes())
+	assertAttributes(t, map[string]string{
+		"log.file.path":         "/tmp/other.log",
+		"com.splunk.index":      "kubernetes",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(1).Attributes())
+	assertAttributes(t, map[string]string{
+		"com.splunk.index":      "preset",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(2).Attributes())
+	assertAttributes(t, map[string]string{
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, ld.ResourceLogs().At(1).ScopeLogs().At(0).LogRecords().At(0).Attributes())
+}
+
+func TestProcessLogsOverride(t *testing.T) {
+	cfg := testConfig()
+	cfg.Override = true
+	cfg.IndexAttribute = "index"
+	proc, err := newRoutingProcessor(cfg)
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("k8s.namespace.name", "payments")
+	rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty().Attributes().InsertString("index", "preset")
+
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assertAttributes(t, map[string]string{
+		"index":                 "kubernetes",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	proc, err := newRoutingProcessor(testConfig())
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("k8s.namespace.name", "default")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+
+	gauge := metrics.AppendEmpty()
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty()
+	sum := metrics.AppendEmpty()
+	sum.SetDataType(pmetric.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty()
+	histogram := metrics.AppendEmpty()
+	histogram.SetDataType(pmetric.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty()
+	summary := metrics.AppendEmpty()
+	summary.SetDataType(pmetric.MetricDataTypeSummary)
+	summary.Summary().DataPoints().AppendEmpty()
+
+	md, err = proc.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	// the splunk_hec exporter routes metrics by their resource attributes
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	assertAttributes(t, map[string]string{
+		"k8s.namespace.name":    "default",
+		"com.splunk.index":      "kubernetes",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, md.ResourceMetrics().At(0).Resource().Attributes())
+	metrics = md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 4, metrics.Len())
+	assertAttributes(t, map[string]string{}, metrics.At(0).Gauge().DataPoints().At(0).Attributes())
+	assertAttributes(t, map[string]string{}, metrics.At(3).Summary().DataPoints().At(0).Attributes())
+}
+
+func TestProcessMetricsSplitsResources(t *testing.T) {
+	proc, err := newRoutingProcessor(testConfig())
+	require.NoError(t, err)
+
+	md := pmetric.NewMetrics()
+	preset := md.ResourceMetrics().AppendEmpty()
+	preset.Resource().Attributes().InsertString("com.splunk.index", "preset")
+	preset.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty().SetDataType(pmetric.MetricDataTypeGauge)
+	preset.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().AppendEmpty()
+
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("k8s.namespace.name", "payments")
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetName("gauge")
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("log.file.path", "/var/log/pods/payments.log")
+	gauge.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("log.file.path", "/tmp/other.log")
+	sum := metrics.AppendEmpty()
+	sum.SetName("sum")
+	sum.SetDataType(pmetric.MetricDataTypeSum)
+	sum.Sum().DataPoints().AppendEmpty()
+
+	md, err = proc.processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	rms := md.ResourceMetrics()
+	require.Equal(t, 3, rms.Len())
+	// routing attributes already set on the resource are kept
+	assertAttributes(t, map[string]string{
+		"com.splunk.index":      "preset",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, rms.At(0).Resource().Attributes())
+
+	assertAttributes(t, map[string]string{
+		"k8s.namespace.name":    "payments",
+		"com.splunk.index":      "payments",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "kube:container:payments",
+	}, rms.At(1).Resource().Attributes())
+	metrics = rms.At(1).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 1, metrics.Len())
+	require.Equal(t, 1, metrics.At(0).Gauge().DataPoints().Len())
+	assertAttributes(t, map[string]string{"log.file.path": "/var/log/pods/payments.log"}, metrics.At(0).Gauge().DataPoints().At(0).Attributes())
+
+	assertAttributes(t, map[string]string{
+		"k8s.namespace.name":    "payments",
+		"com.splunk.index":      "kubernetes",
+		"com.splunk.source":     "otel",
+		"com.splunk.sourcetype": "otel",
+	}, rms.At(2).Resource().Attributes())
+	metrics = rms.At(2).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	assert.Equal(t, "gauge", metrics.At(0).Name())
+	require.Equal(t, 1, metrics.At(0).Gauge().DataPoints().Len())
+	assertAttributes(t, map[string]string{"log.file.path": "/tmp/other.log"}, metrics.At(0).Gauge().DataPoints().At(0).Attributes())
+	assert.Equal(t, "sum", metrics.At(1).Name())
+	assert.Equal(t, 1, metrics.At(1).Sum().DataPoints().Len())
+}
+
+func assertAttributes(t *testing.T, expected map[string]string, attrs pcommon.Map) {
+	actual := map[string]string{}
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		actual[k] = v.AsString()
+		return true
+	})
+	assert.Equal(t, expected, actual)
+}
@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+const (
+	// SourceBody parses the timestamp from the log body, or from the Key entry of a map body.
+	SourceBody = "body"
+	// SourceAttributes parses the timestamp from the Key log record attribute.
+	SourceAttributes = "attributes"
+
+	// LayoutTypeGotime denotes Go reference time layouts (e.g. 2006-01-02T15:04:05Z07:00).
+	LayoutTypeGotime = "gotime"
+	// LayoutTypeStrptime denotes strptime directives (e.g. %Y-%m-%dT%H:%M:%S%z) as used by fluentd and the Smart Agent.
+	LayoutTypeStrptime = "strptime"
+
+	// The epoch layouts parse numeric timestamps with the specified precision.
+	LayoutEpochSeconds      = "epoch_s"
+	LayoutEpochMilliseconds = "epoch_ms"
+	LayoutEpochMicroseconds = "epoch_us"
+	LayoutEpochNanoseconds  = "epoch_ns"
+
+	defaultFailureAttribute = "timestamp.parse_failed"
+)
+
+// Config defines configuration for the timestamp processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Source is where the timestamp is parsed from, either "body" or "attributes".
+	Source string `mapstructure:"source"`
+	// Key is the attribute or map body entry containing the timestamp. It's
+	// required for the attributes source and optional for the body source.
+	Key string `mapstructure:"key"`
+	// LayoutType is the syntax of Layouts, either "gotime" or "strptime".
+	LayoutType string `mapstructure:"layout_type"`
+	// Layouts are attempted in order until one parses successfully.
+	Layouts []string `mapstructure:"layouts"`
+	// Location is the IANA timezone used for layouts without zone
+	// information. Its DST rules apply to the parsed date.
+	Location string `mapstructure:"location"`
+	// FailureAttribute is set to true on records whose timestamp couldn't be
+	// parsed. Failures aren't flagged if empty.
+	FailureAttribute string `mapstructure:"failure_attribute"`
+	// RemoveSource determines whether the parsed attribute or map body entry
+	// is removed after successful parsing.
+	RemoveSource bool `mapstructure:"remove_source"`
+}
+
+var _ config.Processor = (*Config)(nil)
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	switch cfg.Source {
+	case SourceBody:
+	case SourceAttributes:
+		if cfg.Key == "" {
+			return errors.New("key must be provided for the attributes source")
+		}
+	default:
+		return fmt.Errorf("unsupported source %q, must be %q or %q", cfg.Source, SourceBody, SourceAttributes)
+	}
+	if cfg.LayoutType != LayoutTypeGotime && cfg.LayoutType != LayoutTypeStrptime {
+		return fmt.Errorf("unsupported layout_type %q, must be %q or %q", cfg.LayoutType, LayoutTypeGotime, LayoutTypeStrptime)
+	}
+	if len(cfg.Layouts) == 0 {
+		return errors.New("at least one layout must be provided")
+	}
+	if _, err := cfg.parsers(); err != nil {
+		return err
+	}
+	return nil
+}
+
+func (cfg *Config) parsers() ([]timestampParser, error) {
+	location, err := time.LoadLocation(cfg.Location)
+	if err != nil {
+		return nil, fmt.Errorf("invalid location %q: %w", cfg.Location, err)
+	}
+	parsers := make([]timestampParser, 0, len(cfg.Layouts))
+	for _, layout := range cfg.Layouts {
+		parser, err := newTimestampParser(cfg.LayoutType, layout, location)
+		if err != nil {
+			return nil, err
+		}
+		parsers = append(parsers, parser)
+	}
+	return parsers, nil
+}
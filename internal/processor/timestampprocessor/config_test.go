@@ -0,0 +1,91 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Processors[typeStr] = factory
+	cfg, err := servicetest.LoadConfigAndValidate(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	p0 := cfg.Processors[config.NewComponentID(typeStr)]
+	expected := factory.CreateDefaultConfig().(*Config)
+	expected.Layouts = []string{"2006-01-02T15:04:05Z07:00"}
+	assert.Equal(t, expected, p0)
+
+	p1 := cfg.Processors[config.NewComponentIDWithName(typeStr, "strptime")]
+	assert.Equal(t, &Config{
+		ProcessorSettings: config.NewProcessorSettings(config.NewComponentIDWithName(typeStr, "strptime")),
+		Source:            SourceAttributes,
+		Key:               "time",
+		LayoutType:        LayoutTypeStrptime,
+		Layouts:           []string{"%Y-%m-%d %H:%M:%S.%L", "%d/%b/%Y:%H:%M:%S %z", "epoch_ms"},
+		Location:          "America/New_York",
+		RemoveSource:      true,
+	}, p1)
+}
+
+func TestLoadInvalidConfigs(t *testing.T) {
+	for _, test := range []struct {
+		file string
+		err  string
+	}{
+		{file: "invalid_location.yaml", err: `invalid location "Not/AZone"`},
+		{file: "invalid_strptime.yaml", err: `invalid strptime layout "%Y-%m-%d %Q": unsupported directive %Q`},
+	} {
+		t.Run(test.file, func(t *testing.T) {
+			factories, err := componenttest.NopFactories()
+			require.NoError(t, err)
+			factories.Processors[typeStr] = NewFactory()
+
+			_, err = servicetest.LoadConfigAndValidate(path.Join(".", "testdata", test.file), factories)
+			require.Error(t, err)
+			require.Contains(t, err.Error(), test.err)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.EqualError(t, cfg.Validate(), "at least one layout must be provided")
+
+	cfg.Layouts = []string{LayoutEpochSeconds}
+	require.NoError(t, cfg.Validate())
+
+	cfg.Source = SourceAttributes
+	require.EqualError(t, cfg.Validate(), "key must be provided for the attributes source")
+
+	cfg.Source = "resource"
+	require.EqualError(t, cfg.Validate(), `unsupported source "resource", must be "body" or "attributes"`)
+
+	cfg.Source = SourceBody
+	cfg.LayoutType = "java"
+	require.EqualError(t, cfg.Validate(), `unsupported layout_type "java", must be "gotime" or "strptime"`)
+}
@@ -0,0 +1,116 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// strptimeDirectives maps supported strptime directives to their Go layout equivalent.
+var strptimeDirectives = map[byte]string{
+	'Y': "2006",
+	'y': "06",
+	'm': "01",
+	'b': "Jan",
+	'h': "Jan",
+	'B': "January",
+	'd': "02",
+	'e': "_2",
+	'a': "Mon",
+	'A': "Monday",
+	'H': "15",
+	'I': "03",
+	'p': "PM",
+	'M': "04",
+	'S': "05",
+	'L': "000",
+	'f': "000000",
+	'N': "000000000",
+	'z': "-0700",
+	'Z': "MST",
+	'j': "002",
+	'%': "%",
+}
+
+// timestampParser parses a single layout in a default location.
+type timestampParser struct {
+	location *time.Location
+	layout   string
+	epoch    time.Duration
+}
+
+func newTimestampParser(layoutType, layout string, location *time.Location) (timestampParser, error) {
+	switch layout {
+	case LayoutEpochSeconds:
+		return timestampParser{epoch: time.Second}, nil
+	case LayoutEpochMilliseconds:
+		return timestampParser{epoch: time.Millisecond}, nil
+	case LayoutEpochMicroseconds:
+		return timestampParser{epoch: time.Microsecond}, nil
+	case LayoutEpochNanoseconds:
+		return timestampParser{epoch: time.Nanosecond}, nil
+	}
+	if layoutType == LayoutTypeStrptime {
+		var err error
+		if layout, err = strptimeToGotime(layout); err != nil {
+			return timestampParser{}, err
+		}
+	}
+	return timestampParser{layout: layout, location: location}, nil
+}
+
+func (p timestampParser) parse(value string) (time.Time, error) {
+	if p.epoch == 0 {
+		return time.ParseInLocation(p.layout, value, p.location)
+	}
+	// epoch values may have a fractional component (e.g. 1136214245.123)
+	whole, fraction, _ := strings.Cut(strings.TrimSpace(value), ".")
+	units, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid epoch timestamp %q: %w", value, err)
+	}
+	ts := time.Unix(0, 0).Add(time.Duration(units) * p.epoch)
+	if fraction != "" {
+		frac, err := strconv.ParseFloat("0."+fraction, 64)
+		if err != nil {
+			return time.Time{}, fmt.Errorf("invalid epoch timestamp %q: %w", value, err)
+		}
+		ts = ts.Add(time.Duration(frac * float64(p.epoch)))
+	}
+	return ts, nil
+}
+
+func strptimeToGotime(layout string) (string, error) {
+	var converted strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			converted.WriteByte(layout[i])
+			continue
+		}
+		if i+1 == len(layout) {
+			return "", fmt.Errorf("invalid strptime layout %q: trailing %%", layout)
+		}
+		i++
+		directive, ok := strptimeDirectives[layout[i]]
+		if !ok {
+			return "", fmt.Errorf("invalid strptime layout %q: unsupported directive %%%c", layout, layout[i])
+		}
+		converted.WriteString(directive)
+	}
+	return converted.String(), nil
+}
@@ -0,0 +1,115 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+var errNoTimestamp = errors.New("timestamp field not found")
+
+type timestampProcessor struct {
+	logger  *zap.Logger
+	cfg     *Config
+	parsers []timestampParser
+}
+
+func newTimestampProcessor(cfg *Config, logger *zap.Logger) (*timestampProcessor, error) {
+	parsers, err := cfg.parsers()
+	if err != nil {
+		return nil, err
+	}
+	return &timestampProcessor{cfg: cfg, logger: logger, parsers: parsers}, nil
+}
+
+func (proc *timestampProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				proc.processLogRecord(lrs.At(k))
+			}
+		}
+	}
+	return ld, nil
+}
+
+func (proc *timestampProcessor) processLogRecord(lr plog.LogRecord) {
+	ts, err := proc.extract(lr)
+	if err != nil {
+		if errors.Is(err, errNoTimestamp) {
+			return
+		}
+		proc.logger.Debug("failed parsing log record timestamp", zap.Error(err))
+		if proc.cfg.FailureAttribute != "" {
+			lr.Attributes().UpsertBool(proc.cfg.FailureAttribute, true)
+		}
+		return
+	}
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(ts))
+	if proc.cfg.RemoveSource {
+		proc.remove(lr)
+	}
+}
+
+func (proc *timestampProcessor) extract(lr plog.LogRecord) (time.Time, error) {
+	value, ok := proc.field(lr)
+	if !ok {
+		return time.Time{}, errNoTimestamp
+	}
+	raw := value.AsString()
+	var errs []string
+	for _, parser := range proc.parsers {
+		ts, err := parser.parse(raw)
+		if err == nil {
+			return ts, nil
+		}
+		errs = append(errs, err.Error())
+	}
+	return time.Time{}, fmt.Errorf("no layout matched %q: %s", raw, strings.Join(errs, "; "))
+}
+
+func (proc *timestampProcessor) field(lr plog.LogRecord) (pcommon.Value, bool) {
+	if proc.cfg.Source == SourceAttributes {
+		return lr.Attributes().Get(proc.cfg.Key)
+	}
+	body := lr.Body()
+	if proc.cfg.Key == "" {
+		return body, body.Type() != pcommon.ValueTypeEmpty
+	}
+	if body.Type() != pcommon.ValueTypeMap {
+		return pcommon.NewValueEmpty(), false
+	}
+	return body.MapVal().Get(proc.cfg.Key)
+}
+
+func (proc *timestampProcessor) remove(lr plog.LogRecord) {
+	switch {
+	case proc.cfg.Source == SourceAttributes:
+		lr.Attributes().Remove(proc.cfg.Key)
+	case proc.cfg.Key != "":
+		lr.Body().MapVal().Remove(proc.cfg.Key)
+	}
+}
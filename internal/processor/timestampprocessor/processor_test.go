@@ -0,0 +1,178 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timestampprocessor
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+func TestStrptimeToGotime(t *testing.T) {
+	for layout, expected := range map[string]string{
+		"%Y-%m-%dT%H:%M:%S%z":   "2006-01-02T15:04:05-0700",
+		"%d/%b/%Y:%H:%M:%S %Z":  "02/Jan/2006:15:04:05 MST",
+		"%a %e %I:%M %p 100%%":  "Mon _2 03:04 PM 100%",
+		"%Y-%m-%d %H:%M:%S.%L":  "2006-01-02 15:04:05.000",
+		"%y%j":                  "06002",
+		"literal":               "literal",
+		"%A, %B %d %H:%M:%S.%N": "Monday, January 02 15:04:05.000000000",
+	} {
+		converted, err := strptimeToGotime(layout)
+		require.NoError(t, err, layout)
+		assert.Equal(t, expected, converted, layout)
+	}
+
+	_, err := strptimeToGotime("%Y-%m-%d %")
+	require.EqualError(t, err, `invalid strptime layout "%Y-%m-%d %": trailing %`)
+	_, err = strptimeToGotime("%Q")
+	require.EqualError(t, err, `invalid strptime layout "%Q": unsupported directive %Q`)
+}
+
+func TestParserLocationIsDSTAware(t *testing.T) {
+	location, err := time.LoadLocation("America/New_York")
+	require.NoError(t, err)
+	parser, err := newTimestampParser(LayoutTypeStrptime, "%Y-%m-%d %H:%M:%S", location)
+	require.NoError(t, err)
+
+	winter, err := parser.parse("2022-01-15 12:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 1, 15, 17, 0, 0, 0, time.UTC), winter.UTC())
+
+	summer, err := parser.parse("2022-07-15 12:00:00")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 7, 15, 16, 0, 0, 0, time.UTC), summer.UTC())
+
+	// explicit offsets take precedence over the location
+	parser, err = newTimestampParser(LayoutTypeGotime, time.RFC3339, location)
+	require.NoError(t, err)
+	explicit, err := parser.parse("2022-07-15T12:00:00Z")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2022, 7, 15, 12, 0, 0, 0, time.UTC), explicit.UTC())
+}
+
+func TestParserEpochLayouts(t *testing.T) {
+	expected := time.Date(2022, 7, 15, 12, 0, 0, 500_000_000, time.UTC)
+	for layout, value := range map[string]string{
+		LayoutEpochSeconds:      "1657886400.5",
+		LayoutEpochMilliseconds: "1657886400500",
+		LayoutEpochMicroseconds: "1657886400500000",
+		LayoutEpochNanoseconds:  "1657886400500000000",
+	} {
+		parser, err := newTimestampParser(LayoutTypeGotime, layout, time.UTC)
+		require.NoError(t, err)
+		ts, err := parser.parse(value)
+		require.NoError(t, err, layout)
+		assert.Equal(t, expected, ts.UTC(), layout)
+	}
+
+	parser, err := newTimestampParser(LayoutTypeGotime, LayoutEpochSeconds, time.UTC)
+	require.NoError(t, err)
+	_, err = parser.parse("not a number")
+	require.Error(t, err)
+}
+
+func TestProcessLogsFromBody(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Layouts = []string{"2006-01-02 15:04:05", time.RFC3339}
+	proc, err := newTimestampProcessor(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Body().SetStringVal("2022-07-15T12:00:00-07:00")
+	lrs.AppendEmpty().Body().SetStringVal("not a timestamp")
+	lrs.AppendEmpty()
+
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	lrs = ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, time.Date(2022, 7, 15, 19, 0, 0, 0, time.UTC), lrs.At(0).Timestamp().AsTime())
+	assert.Equal(t, 0, lrs.At(0).Attributes().Len())
+
+	assert.Zero(t, lrs.At(1).Timestamp())
+	failed, ok := lrs.At(1).Attributes().Get(defaultFailureAttribute)
+	require.True(t, ok)
+	assert.True(t, failed.BoolVal())
+
+	assert.Zero(t, lrs.At(2).Timestamp())
+	assert.Equal(t, 0, lrs.At(2).Attributes().Len())
+}
+
+func TestProcessLogsFromMapBody(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Key = "time"
+	cfg.Layouts = []string{LayoutEpochSeconds}
+	cfg.RemoveSource = true
+	proc, err := newTimestampProcessor(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	body := pcommon.NewValueMap()
+	body.MapVal().InsertString("time", "1657886400")
+	body.MapVal().InsertString("message", "hello")
+	body.CopyTo(lrs.AppendEmpty().Body())
+	lrs.AppendEmpty().Body().SetStringVal("1657886400")
+
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	lrs = ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, time.Date(2022, 7, 15, 12, 0, 0, 0, time.UTC), lrs.At(0).Timestamp().AsTime())
+	_, ok := lrs.At(0).Body().MapVal().Get("time")
+	assert.False(t, ok)
+	_, ok = lrs.At(0).Body().MapVal().Get("message")
+	assert.True(t, ok)
+
+	// string bodies don't have a keyed entry
+	assert.Zero(t, lrs.At(1).Timestamp())
+	assert.Equal(t, 0, lrs.At(1).Attributes().Len())
+}
+
+func TestProcessLogsFromAttributes(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Source = SourceAttributes
+	cfg.Key = "time"
+	cfg.LayoutType = LayoutTypeStrptime
+	cfg.Layouts = []string{"%d/%b/%Y:%H:%M:%S %z"}
+	cfg.FailureAttribute = ""
+	proc, err := newTimestampProcessor(cfg, zap.NewNop())
+	require.NoError(t, err)
+
+	ld := plog.NewLogs()
+	lrs := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords()
+	lrs.AppendEmpty().Attributes().InsertString("time", "15/Jul/2022:12:00:00 +0200")
+	lrs.AppendEmpty().Attributes().InsertString("time", "2022-07-15")
+
+	ld, err = proc.processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	lrs = ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, time.Date(2022, 7, 15, 10, 0, 0, 0, time.UTC), lrs.At(0).Timestamp().AsTime())
+	_, ok := lrs.At(0).Attributes().Get("time")
+	assert.True(t, ok)
+
+	// failures aren't flagged without a failure_attribute
+	assert.Zero(t, lrs.At(1).Timestamp())
+	assert.Equal(t, 1, lrs.At(1).Attributes().Len())
+}
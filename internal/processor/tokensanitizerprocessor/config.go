@@ -0,0 +1,103 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokensanitizerprocessor
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/confmap"
+)
+
+const defaultReplacement = "[REDACTED]"
+
+var (
+	// defaultPatterns match HEC tokens in Authorization headers, bearer tokens, and
+	// SignalFx access and other tokens provided as headers or parameters.
+	defaultPatterns = []string{
+		`(?i)\bSplunk\s+([0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12})\b`,
+		`(?i)\bBearer\s+([A-Za-z0-9._~+/-]+=*)`,
+		`(?i)\b(?:x-sf|sfx?|access|hec|api|auth)[_-]?token["']?\s*[:=]\s*["']?([A-Za-z0-9_-]{16,})`,
+	}
+	// defaultSensitiveKeys match the attribute keys of Authorization headers and tokens.
+	defaultSensitiveKeys = []string{
+		`(?i)^authorization$`,
+		`(?i)(^|[._-])((sfx?|access|hec|api|auth)[._-]?)?token$`,
+	}
+)
+
+// Config defines configuration for the token sanitizer processor.
+type Config struct {
+	config.ProcessorSettings `mapstructure:",squash"` // squash ensures fields are correctly decoded in embedded struct
+
+	// Tokens are literal token values redacted wherever they occur in string values.
+	Tokens []string `mapstructure:"tokens"`
+	// Patterns are regular expressions matching tokens in string values. The first
+	// capturing group of a match is redacted if the pattern has one, otherwise the whole match.
+	Patterns []string `mapstructure:"patterns"`
+	// SensitiveKeys are regular expressions matching the attribute and map keys
+	// whose string values are redacted entirely.
+	SensitiveKeys []string `mapstructure:"sensitive_keys"`
+	// Replacement is the text redacted tokens are replaced with.
+	Replacement string `mapstructure:"replacement"`
+}
+
+var _ config.Processor = (*Config)(nil)
+var _ config.Unmarshallable = (*Config)(nil)
+
+// Unmarshal replaces the default patterns and sensitive keys with those that are set, rather than
+// decoding them into the defaults element by element.
+func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
+	if componentParser.IsSet("patterns") {
+		cfg.Patterns = nil
+	}
+	if componentParser.IsSet("sensitive_keys") {
+		cfg.SensitiveKeys = nil
+	}
+	return componentParser.UnmarshalExact(cfg)
+}
+
+// Validate checks if the processor configuration is valid
+func (cfg *Config) Validate() error {
+	if len(cfg.Tokens) == 0 && len(cfg.Patterns) == 0 && len(cfg.SensitiveKeys) == 0 {
+		return errors.New("at least one of tokens, patterns, or sensitive_keys must be provided")
+	}
+	for i, token := range cfg.Tokens {
+		if token == "" {
+			return fmt.Errorf("token %d must not be empty", i)
+		}
+	}
+	if _, err := compilePatterns(cfg.Patterns); err != nil {
+		return fmt.Errorf("invalid patterns: %w", err)
+	}
+	if _, err := compilePatterns(cfg.SensitiveKeys); err != nil {
+		return fmt.Errorf("invalid sensitive_keys: %w", err)
+	}
+	return nil
+}
+
+func compilePatterns(patterns []string) ([]*regexp.Regexp, error) {
+	compiled := make([]*regexp.Regexp, 0, len(patterns))
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, err
+		}
+		compiled = append(compiled, re)
+	}
+	return compiled, nil
+}
@@ -0,0 +1,216 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokensanitizerprocessor
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+type sanitizerProcessor struct {
+	tokens        []string
+	patterns      []*regexp.Regexp
+	sensitiveKeys []*regexp.Regexp
+	replacement   string
+}
+
+func newSanitizerProcessor(cfg *Config) (*sanitizerProcessor, error) {
+	patterns, err := compilePatterns(cfg.Patterns)
+	if err != nil {
+		return nil, err
+	}
+	sensitiveKeys, err := compilePatterns(cfg.SensitiveKeys)
+	if err != nil {
+		return nil, err
+	}
+	return &sanitizerProcessor{
+		tokens:        cfg.Tokens,
+		patterns:      patterns,
+		sensitiveKeys: sensitiveKeys,
+		replacement:   cfg.Replacement,
+	}, nil
+}
+
+func (proc *sanitizerProcessor) processTraces(_ context.Context, td ptrace.Traces) (ptrace.Traces, error) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		proc.sanitizeMap(rs.Resource().Attributes())
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				proc.sanitizeMap(span.Attributes())
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					proc.sanitizeMap(events.At(l).Attributes())
+				}
+				links := span.Links()
+				for l := 0; l < links.Len(); l++ {
+					proc.sanitizeMap(links.At(l).Attributes())
+				}
+			}
+		}
+	}
+	return td, nil
+}
+
+func (proc *sanitizerProcessor) processMetrics(_ context.Context, md pmetric.Metrics) (pmetric.Metrics, error) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		proc.sanitizeMap(rm.Resource().Attributes())
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			metrics := sms.At(j).Metrics()
+			for k := 0; k < metrics.Len(); k++ {
+				proc.sanitizeMetric(metrics.At(k))
+			}
+		}
+	}
+	return md, nil
+}
+
+func (proc *sanitizerProcessor) sanitizeMetric(metric pmetric.Metric) {
+	switch metric.DataType() {
+	case pmetric.MetricDataTypeGauge:
+		dps := metric.Gauge().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.sanitizeMap(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSum:
+		dps := metric.Sum().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.sanitizeMap(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeHistogram:
+		dps := metric.Histogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.sanitizeMap(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeExponentialHistogram:
+		dps := metric.ExponentialHistogram().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.sanitizeMap(dps.At(i).Attributes())
+		}
+	case pmetric.MetricDataTypeSummary:
+		dps := metric.Summary().DataPoints()
+		for i := 0; i < dps.Len(); i++ {
+			proc.sanitizeMap(dps.At(i).Attributes())
+		}
+	}
+}
+
+func (proc *sanitizerProcessor) processLogs(_ context.Context, ld plog.Logs) (plog.Logs, error) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		proc.sanitizeMap(rl.Resource().Attributes())
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				// SignalFx event properties are a map attribute and sanitized with the others.
+				proc.sanitizeMap(lr.Attributes())
+				proc.sanitizeValue(lr.Body())
+			}
+		}
+	}
+	return ld, nil
+}
+
+// sanitizeMap redacts the string values of sensitive keys and the tokens
+// in all other values, including those of nested maps and slices.
+func (proc *sanitizerProcessor) sanitizeMap(m pcommon.Map) {
+	m.Range(func(k string, v pcommon.Value) bool {
+		if v.Type() == pcommon.ValueTypeString && proc.isSensitiveKey(k) {
+			v.SetStringVal(proc.replacement)
+			return true
+		}
+		proc.sanitizeValue(v)
+		return true
+	})
+}
+
+func (proc *sanitizerProcessor) sanitizeValue(v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeString:
+		if redacted, ok := proc.redact(v.StringVal()); ok {
+			v.SetStringVal(redacted)
+		}
+	case pcommon.ValueTypeMap:
+		proc.sanitizeMap(v.MapVal())
+	case pcommon.ValueTypeSlice:
+		values := v.SliceVal()
+		for i := 0; i < values.Len(); i++ {
+			proc.sanitizeValue(values.At(i))
+		}
+	}
+}
+
+func (proc *sanitizerProcessor) isSensitiveKey(key string) bool {
+	for _, re := range proc.sensitiveKeys {
+		if re.MatchString(key) {
+			return true
+		}
+	}
+	return false
+}
+
+// redact returns the value with its configured tokens and pattern matches
+// replaced, and whether anything was redacted.
+func (proc *sanitizerProcessor) redact(value string) (string, bool) {
+	redacted := value
+	for _, token := range proc.tokens {
+		redacted = strings.ReplaceAll(redacted, token, proc.replacement)
+	}
+	for _, re := range proc.patterns {
+		redacted = redactMatches(redacted, re, proc.replacement)
+	}
+	return redacted, redacted != value
+}
+
+// redactMatches replaces the first capturing group of each match of re,
+// or the whole match if re has no capturing groups.
+func redactMatches(value string, re *regexp.Regexp, replacement string) string {
+	matches := re.FindAllStringSubmatchIndex(value, -1)
+	if matches == nil {
+		return value
+	}
+	var sb strings.Builder
+	last := 0
+	for _, match := range matches {
+		start, end := match[0], match[1]
+		if len(match) > 2 {
+			if match[2] < 0 {
+				continue
+			}
+			start, end = match[2], match[3]
+		}
+		sb.WriteString(value[last:start])
+		sb.WriteString(replacement)
+		last = end
+	}
+	sb.WriteString(value[last:])
+	return sb.String()
+}
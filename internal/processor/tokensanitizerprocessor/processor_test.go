@@ -0,0 +1,178 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tokensanitizerprocessor
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+const (
+	hecToken = "01234567-89ab-cdef-0123-456789abcdef"
+	sfxToken = "AbCdEfGhIjKlMnOpQrStUv"
+)
+
+func newTestProcessor(t *testing.T) *sanitizerProcessor {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Tokens = []string{"my-literal-token"}
+	proc, err := newSanitizerProcessor(cfg)
+	require.NoError(t, err)
+	return proc
+}
+
+func TestRedact(t *testing.T) {
+	proc := newTestProcessor(t)
+	for value, expected := range map[string]string{
+		"Authorization: Splunk " + hecToken:        "Authorization: Splunk [REDACTED]",
+		"curl -H 'X-SF-Token: " + sfxToken + "'":   "curl -H 'X-SF-Token: [REDACTED]'",
+		"/v2/datapoint?access_token=" + sfxToken:   "/v2/datapoint?access_token=[REDACTED]",
+		`{"hec_token": "` + hecToken + `"}`:        `{"hec_token": "[REDACTED]"}`,
+		"Authorization: Bearer eyJhbGciOi.abc.def": "Authorization: Bearer [REDACTED]",
+		"configured my-literal-token value":        "configured [REDACTED] value",
+		"pod " + hecToken + " restarted":           "pod " + hecToken + " restarted",
+		"nothing to redact":                        "nothing to redact",
+	} {
+		t.Run(value, func(t *testing.T) {
+			redacted, ok := proc.redact(value)
+			assert.Equal(t, expected, redacted)
+			assert.Equal(t, expected != value, ok)
+		})
+	}
+}
+
+func TestRedactWholeMatch(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Patterns = []string{`sk_[a-z]+`, `(?:pw|pass)=(\w+)|pin=\d+`}
+	cfg.Replacement = "***"
+	proc, err := newSanitizerProcessor(cfg)
+	require.NoError(t, err)
+
+	redacted, ok := proc.redact("key sk_abc pw=hunter2 pin=1234")
+	assert.True(t, ok)
+	assert.Equal(t, "key *** pw=*** pin=1234", redacted)
+}
+
+func TestSensitiveKeys(t *testing.T) {
+	proc := newTestProcessor(t)
+	for key, expected := range map[string]bool{
+		"Authorization":        true,
+		"X-SF-Token":           true,
+		"token":                true,
+		"com.splunk.hec.token": true,
+		"sfx_access_token":     true,
+		"num_tokens":           false,
+		"broken":               false,
+	} {
+		assert.Equal(t, expected, proc.isSensitiveKey(key), key)
+	}
+}
+
+func TestProcessLogs(t *testing.T) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("com.splunk.hec.token", hecToken)
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStringVal("POST /services/collector Authorization: Splunk " + hecToken)
+	lr.Attributes().InsertString("host", "my-literal-token.example.com")
+	lr.Attributes().InsertInt("token", 1)
+	properties := pcommon.NewValueMap()
+	properties.MapVal().InsertString("access_token", sfxToken)
+	properties.MapVal().InsertString("detail", "X-SF-Token="+sfxToken)
+	lr.Attributes().Insert("com.splunk.signalfx.event_properties", properties)
+
+	_, err := newTestProcessor(t).processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	resourceToken, _ := rl.Resource().Attributes().Get("com.splunk.hec.token")
+	assert.Equal(t, "[REDACTED]", resourceToken.StringVal())
+	assert.Equal(t, "POST /services/collector Authorization: Splunk [REDACTED]", lr.Body().StringVal())
+	host, _ := lr.Attributes().Get("host")
+	assert.Equal(t, "[REDACTED].example.com", host.StringVal())
+	count, _ := lr.Attributes().Get("token")
+	assert.Equal(t, int64(1), count.IntVal())
+	props, _ := lr.Attributes().Get("com.splunk.signalfx.event_properties")
+	assert.Equal(t, map[string]any{
+		"access_token": "[REDACTED]",
+		"detail":       "X-SF-Token=[REDACTED]",
+	}, props.MapVal().AsRaw())
+}
+
+func TestProcessLogsNestedBody(t *testing.T) {
+	ld := plog.NewLogs()
+	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	body := pcommon.NewValueMap()
+	body.MapVal().InsertString("authorization", "Splunk "+hecToken)
+	headers := pcommon.NewValueSlice()
+	headers.SliceVal().AppendEmpty().SetStringVal("X-SF-Token: " + sfxToken)
+	body.MapVal().Insert("headers", headers)
+	body.CopyTo(lr.Body())
+
+	_, err := newTestProcessor(t).processLogs(context.Background(), ld)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"authorization": "[REDACTED]",
+		"headers":       []any{"X-SF-Token: [REDACTED]"},
+	}, lr.Body().MapVal().AsRaw())
+}
+
+func TestProcessMetrics(t *testing.T) {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("sfx_token", sfxToken)
+	metrics := rm.ScopeMetrics().AppendEmpty().Metrics()
+	gauge := metrics.AppendEmpty()
+	gauge.SetDataType(pmetric.MetricDataTypeGauge)
+	gauge.Gauge().DataPoints().AppendEmpty().Attributes().InsertString("url", "/v2?access_token="+sfxToken)
+	histogram := metrics.AppendEmpty()
+	histogram.SetDataType(pmetric.MetricDataTypeHistogram)
+	histogram.Histogram().DataPoints().AppendEmpty().Attributes().InsertString("api_token", "value")
+
+	_, err := newTestProcessor(t).processMetrics(context.Background(), md)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{"sfx_token": "[REDACTED]"}, rm.Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"url": "/v2?access_token=[REDACTED]"},
+		gauge.Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"api_token": "[REDACTED]"},
+		histogram.Histogram().DataPoints().At(0).Attributes().AsRaw())
+}
+
+func TestProcessTraces(t *testing.T) {
+	td := ptrace.NewTraces()
+	span := td.ResourceSpans().AppendEmpty().ScopeSpans().AppendEmpty().Spans().AppendEmpty()
+	span.Attributes().InsertString("http.request.header.authorization", "Bearer abc.def")
+	span.Attributes().InsertString("http.url", "https://ingest/v2?access_token="+sfxToken)
+	span.Events().AppendEmpty().Attributes().InsertString("exception.message", "invalid token my-literal-token")
+	span.Links().AppendEmpty().Attributes().InsertString("token", sfxToken)
+
+	_, err := newTestProcessor(t).processTraces(context.Background(), td)
+	require.NoError(t, err)
+
+	assert.Equal(t, map[string]any{
+		"http.request.header.authorization": "Bearer [REDACTED]",
+		"http.url":                          "https://ingest/v2?access_token=[REDACTED]",
+	}, span.Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"exception.message": "invalid token [REDACTED]"},
+		span.Events().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"token": "[REDACTED]"}, span.Links().At(0).Attributes().AsRaw())
+}
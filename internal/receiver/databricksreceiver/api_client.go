@@ -17,6 +17,7 @@ package databricksreceiver
 import (
 	"fmt"
 	"net/http"
+	"net/url"
 
 	"go.uber.org/zap"
 )
@@ -25,6 +26,13 @@ const (
 	jobsListPath         = "/api/2.1/jobs/list?expand_tasks=true&limit=%d&offset=%d"
 	activeJobRunsPath    = "/api/2.1/jobs/runs/list?active_only=true&limit=%d&offset=%d"
 	completedJobRunsPath = "/api/2.1/jobs/runs/list?completed_only=true&expand_tasks=true&job_id=%d&limit=%d&offset=%d"
+	// startTimeFromParam limits completed job runs to those started at or after the given time in milliseconds
+	startTimeFromParam = "&start_time_from=%d"
+	// activeJobRunTasksPath lists active job runs with the tasks, and so the clusters, they are running
+	activeJobRunTasksPath = "/api/2.1/jobs/runs/list?active_only=true&expand_tasks=true&limit=%d&offset=%d"
+	clusterGetPath        = "/api/2.0/clusters/get?cluster_id=%s"
+	dbfsListPath          = "/api/2.0/dbfs/list?path=%s"
+	dbfsReadPath          = "/api/2.0/dbfs/read?path=%s&offset=%d&length=%d"
 )
 
 // apiClientInterface is extracted from apiClient so that it can be swapped for
@@ -32,7 +40,11 @@ const (
 type apiClientInterface interface {
 	jobsList(limit int, offset int) ([]byte, error)
 	activeJobRuns(limit int, offset int) ([]byte, error)
-	completedJobRuns(id int, limit int, offset int) ([]byte, error)
+	completedJobRuns(id int, limit int, offset int, startTimeFrom int64) ([]byte, error)
+	activeJobRunTasks(limit int, offset int) ([]byte, error)
+	clusterInfo(clusterID string) ([]byte, error)
+	dbfsList(path string) ([]byte, error)
+	dbfsRead(path string, offset int64, length int) ([]byte, error)
 }
 
 // apiClient wraps an authClient, encapsulates calls to the databricks API, and
@@ -66,8 +78,35 @@ func (c apiClient) activeJobRuns(limit int, offset int) ([]byte, error) {
 	return c.authClient.get(path)
 }
 
-func (c apiClient) completedJobRuns(jobID int, limit int, offset int) ([]byte, error) {
+func (c apiClient) completedJobRuns(jobID int, limit int, offset int, startTimeFrom int64) ([]byte, error) {
 	path := fmt.Sprintf(completedJobRunsPath, jobID, limit, offset)
+	if startTimeFrom > 0 {
+		path += fmt.Sprintf(startTimeFromParam, startTimeFrom)
+	}
 	c.logger.Debug("apiClient.completedJobRuns", zap.String("path", path))
 	return c.authClient.get(path)
 }
+
+func (c apiClient) activeJobRunTasks(limit int, offset int) ([]byte, error) {
+	path := fmt.Sprintf(activeJobRunTasksPath, limit, offset)
+	c.logger.Debug("apiClient.activeJobRunTasks", zap.String("path", path))
+	return c.authClient.get(path)
+}
+
+func (c apiClient) clusterInfo(clusterID string) ([]byte, error) {
+	path := fmt.Sprintf(clusterGetPath, url.QueryEscape(clusterID))
+	c.logger.Debug("apiClient.clusterInfo", zap.String("path", path))
+	return c.authClient.get(path)
+}
+
+func (c apiClient) dbfsList(dbfsPath string) ([]byte, error) {
+	path := fmt.Sprintf(dbfsListPath, url.QueryEscape(dbfsPath))
+	c.logger.Debug("apiClient.dbfsList", zap.String("path", path))
+	return c.authClient.get(path)
+}
+
+func (c apiClient) dbfsRead(dbfsPath string, offset int64, length int) ([]byte, error) {
+	path := fmt.Sprintf(dbfsReadPath, url.QueryEscape(dbfsPath), offset, length)
+	c.logger.Debug("apiClient.dbfsRead", zap.String("path", path))
+	return c.authClient.get(path)
+}
@@ -43,9 +43,24 @@ func TestAPIClient(t *testing.T) {
 	_, _ = c.activeJobRuns(2, 3)
 	path = "/api/2.1/jobs/runs/list?active_only=true&limit=2&offset=3"
 	assert.Equal(t, path, h.reqs[1].RequestURI)
-	_, _ = c.completedJobRuns(42, 2, 3)
+	_, _ = c.completedJobRuns(42, 2, 3, 0)
 	path = "/api/2.1/jobs/runs/list?completed_only=true&expand_tasks=true&job_id=42&limit=2&offset=3"
 	assert.Equal(t, path, h.reqs[2].RequestURI)
+	_, _ = c.completedJobRuns(42, 2, 3, 1642777677522)
+	path = "/api/2.1/jobs/runs/list?completed_only=true&expand_tasks=true&job_id=42&limit=2&offset=3&start_time_from=1642777677522"
+	assert.Equal(t, path, h.reqs[3].RequestURI)
+	_, _ = c.activeJobRunTasks(2, 3)
+	path = "/api/2.1/jobs/runs/list?active_only=true&expand_tasks=true&limit=2&offset=3"
+	assert.Equal(t, path, h.reqs[4].RequestURI)
+	_, _ = c.clusterInfo("0125-162513-ueqw4u1i")
+	path = "/api/2.0/clusters/get?cluster_id=0125-162513-ueqw4u1i"
+	assert.Equal(t, path, h.reqs[5].RequestURI)
+	_, _ = c.dbfsList("/cluster-logs/0125-162513-ueqw4u1i/driver")
+	path = "/api/2.0/dbfs/list?path=%2Fcluster-logs%2F0125-162513-ueqw4u1i%2Fdriver"
+	assert.Equal(t, path, h.reqs[6].RequestURI)
+	_, _ = c.dbfsRead("/cluster-logs/0125-162513-ueqw4u1i/driver/stdout", 10, 100)
+	path = "/api/2.0/dbfs/read?path=%2Fcluster-logs%2F0125-162513-ueqw4u1i%2Fdriver%2Fstdout&offset=10&length=100"
+	assert.Equal(t, path, h.reqs[7].RequestURI)
 }
 
 // testdataClient implements apiClientInterface but is backed by json files in testdata.
@@ -61,7 +76,7 @@ func (*testdataClient) activeJobRuns(limit int, offset int) ([]byte, error) {
 	return os.ReadFile(fmt.Sprintf("testdata/active-job-runs-%d.json", offset/limit))
 }
 
-func (c *testdataClient) completedJobRuns(jobID int, limit int, offset int) ([]byte, error) {
+func (c *testdataClient) completedJobRuns(jobID int, limit int, offset int, _ int64) ([]byte, error) {
 	if jobID != 288 {
 		return []byte("{}"), nil
 	}
@@ -71,3 +86,19 @@ func (c *testdataClient) completedJobRuns(jobID int, limit int, offset int) ([]b
 	file, err := os.ReadFile(fmt.Sprintf("testdata/completed-job-runs-%d-%d.json", c.i-1, offset/limit))
 	return file, err
 }
+
+func (*testdataClient) activeJobRunTasks(limit int, offset int) ([]byte, error) {
+	return os.ReadFile(fmt.Sprintf("testdata/active-job-run-tasks-%d.json", offset/limit))
+}
+
+func (*testdataClient) clusterInfo(string) ([]byte, error) {
+	return os.ReadFile("testdata/cluster-info.json")
+}
+
+func (*testdataClient) dbfsList(string) ([]byte, error) {
+	return []byte("{}"), nil
+}
+
+func (*testdataClient) dbfsRead(string, int64, int) ([]byte, error) {
+	return []byte(`{"bytes_read": 0, "data": ""}`), nil
+}
@@ -0,0 +1,117 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+// checkpointKey is the storage key of the checkpointed job run start times.
+const checkpointKey = "job_run_start_times"
+
+// runCheckpointer persists the start times tracked by a runTracker to a storage
+// extension, so that runs completed while the collector isn't running are
+// reported after restarts instead of being discarded as preexisting.
+type runCheckpointer struct {
+	client     storage.Client
+	storageID  *config.ComponentID
+	tracker    *runTracker
+	logger     *zap.Logger
+	saved      map[int]int64
+	receiverID config.ComponentID
+}
+
+func newRunCheckpointer(storageID *config.ComponentID, receiverID config.ComponentID, tracker *runTracker, logger *zap.Logger) *runCheckpointer {
+	return &runCheckpointer{
+		storageID:  storageID,
+		receiverID: receiverID,
+		tracker:    tracker,
+		logger:     logger,
+		saved:      map[int]int64{},
+	}
+}
+
+// start obtains a storage client and restores the tracker's start times from it.
+// It's a noop if no storage extension is configured.
+func (c *runCheckpointer) start(ctx context.Context, host component.Host) error {
+	if c.storageID == nil {
+		return nil
+	}
+	ext, ok := host.GetExtensions()[*c.storageID]
+	if !ok {
+		return fmt.Errorf("runCheckpointer.start(): storage extension %q not found", c.storageID)
+	}
+	storageExt, ok := ext.(storage.Extension)
+	if !ok {
+		return fmt.Errorf("runCheckpointer.start(): extension %q is not a storage extension", c.storageID)
+	}
+	client, err := storageExt.GetClient(ctx, component.KindReceiver, c.receiverID, "")
+	if err != nil {
+		return fmt.Errorf("runCheckpointer.start(): %w", err)
+	}
+	c.client = client
+
+	content, err := client.Get(ctx, checkpointKey)
+	if err != nil {
+		return fmt.Errorf("runCheckpointer.start(): %w", err)
+	}
+	if content == nil {
+		return nil
+	}
+	startTimes := map[int]int64{}
+	if err = json.Unmarshal(content, &startTimes); err != nil {
+		// a corrupt checkpoint shouldn't prevent startup, only the reporting of runs completed since it
+		c.logger.Warn("discarding invalid job run checkpoint", zap.Error(err))
+		return nil
+	}
+	c.tracker.restore(startTimes)
+	c.saved = startTimes
+	return nil
+}
+
+// save persists the tracker's start times if they've changed since the last save.
+func (c *runCheckpointer) save(ctx context.Context) {
+	if c.client == nil {
+		return
+	}
+	startTimes := c.tracker.startTimes()
+	if reflect.DeepEqual(startTimes, c.saved) {
+		return
+	}
+	content, err := json.Marshal(startTimes)
+	if err == nil {
+		err = c.client.Set(ctx, checkpointKey, content)
+	}
+	if err != nil {
+		c.logger.Warn("failed saving job run checkpoint", zap.Error(err))
+		return
+	}
+	c.saved = startTimes
+}
+
+func (c *runCheckpointer) shutdown(ctx context.Context) error {
+	if c.client == nil {
+		return nil
+	}
+	return c.client.Close(ctx)
+}
@@ -0,0 +1,162 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/extension/experimental/storage"
+	"go.uber.org/zap"
+)
+
+func TestRunCheckpointer(t *testing.T) {
+	ctx := context.Background()
+	storageID := config.NewComponentID("fake_storage")
+	client := &fakeStorageClient{values: map[string][]byte{}}
+	host := &fakeHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		storageID: &fakeStorageExtension{client: client},
+	}}
+
+	tracker := newRunTracker()
+	c := newRunCheckpointer(&storageID, config.NewComponentID(typeStr), tracker, zap.NewNop())
+	require.NoError(t, c.start(ctx, host))
+	assert.Empty(t, tracker.startTimes())
+
+	// nothing to save until runs are tracked
+	c.save(ctx)
+	assert.Equal(t, 0, client.sets)
+
+	runs, _ := (&fakeCompletedJobRunClient{}).completedJobRuns(42, 0)
+	tracker.extractNewRuns(runs)
+	c.save(ctx)
+	assert.Equal(t, 1, client.sets)
+	assert.JSONEq(t, `{"42": 1600000000000}`, string(client.values[checkpointKey]))
+
+	// unchanged start times aren't saved again
+	c.save(ctx)
+	assert.Equal(t, 1, client.sets)
+	require.NoError(t, c.shutdown(ctx))
+	assert.True(t, client.closed)
+
+	// a new instance restores the checkpoint so that runs since then aren't discarded
+	restored := newRunTracker()
+	c = newRunCheckpointer(&storageID, config.NewComponentID(typeStr), restored, zap.NewNop())
+	require.NoError(t, c.start(ctx, host))
+	assert.Equal(t, map[int]int64{42: 1_600_000_000_000}, restored.startTimes())
+	newRuns := restored.extractNewRuns([]jobRun{
+		{JobID: 42, StartTime: 1_600_001_000_000},
+		{JobID: 42, StartTime: 1_600_000_000_000},
+	})
+	assert.Equal(t, 1, len(newRuns))
+}
+
+func TestRunCheckpointerWithoutStorage(t *testing.T) {
+	ctx := context.Background()
+	c := newRunCheckpointer(nil, config.NewComponentID(typeStr), newRunTracker(), zap.NewNop())
+	require.NoError(t, c.start(ctx, componenttest.NewNopHost()))
+	c.save(ctx)
+	require.NoError(t, c.shutdown(ctx))
+}
+
+func TestRunCheckpointerInvalidStorage(t *testing.T) {
+	ctx := context.Background()
+	storageID := config.NewComponentID("fake_storage")
+	c := newRunCheckpointer(&storageID, config.NewComponentID(typeStr), newRunTracker(), zap.NewNop())
+	err := c.start(ctx, componenttest.NewNopHost())
+	require.EqualError(t, err, `runCheckpointer.start(): storage extension "fake_storage" not found`)
+
+	host := &fakeHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		storageID: &fakeExtension{},
+	}}
+	err = c.start(ctx, host)
+	require.EqualError(t, err, `runCheckpointer.start(): extension "fake_storage" is not a storage extension`)
+}
+
+func TestRunCheckpointerCorruptCheckpoint(t *testing.T) {
+	ctx := context.Background()
+	storageID := config.NewComponentID("fake_storage")
+	client := &fakeStorageClient{values: map[string][]byte{checkpointKey: []byte("not json")}}
+	host := &fakeHost{Host: componenttest.NewNopHost(), extensions: map[config.ComponentID]component.Extension{
+		storageID: &fakeStorageExtension{client: client},
+	}}
+	tracker := newRunTracker()
+	c := newRunCheckpointer(&storageID, config.NewComponentID(typeStr), tracker, zap.NewNop())
+	require.NoError(t, c.start(ctx, host))
+	assert.Empty(t, tracker.startTimes())
+}
+
+type fakeHost struct {
+	component.Host
+	extensions map[config.ComponentID]component.Extension
+}
+
+func (h *fakeHost) GetExtensions() map[config.ComponentID]component.Extension {
+	return h.extensions
+}
+
+type fakeExtension struct{}
+
+func (*fakeExtension) Start(context.Context, component.Host) error {
+	return nil
+}
+
+func (*fakeExtension) Shutdown(context.Context) error {
+	return nil
+}
+
+type fakeStorageExtension struct {
+	fakeExtension
+	client storage.Client
+}
+
+func (e *fakeStorageExtension) GetClient(context.Context, component.Kind, config.ComponentID, string) (storage.Client, error) {
+	return e.client, nil
+}
+
+type fakeStorageClient struct {
+	values map[string][]byte
+	sets   int
+	closed bool
+}
+
+func (c *fakeStorageClient) Get(_ context.Context, key string) ([]byte, error) {
+	return c.values[key], nil
+}
+
+func (c *fakeStorageClient) Set(_ context.Context, key string, value []byte) error {
+	c.sets++
+	c.values[key] = value
+	return nil
+}
+
+func (c *fakeStorageClient) Delete(_ context.Context, key string) error {
+	delete(c.values, key)
+	return nil
+}
+
+func (c *fakeStorageClient) Batch(context.Context, ...storage.Operation) error {
+	return nil
+}
+
+func (c *fakeStorageClient) Close(context.Context) error {
+	c.closed = true
+	return nil
+}
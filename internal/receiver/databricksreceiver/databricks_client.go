@@ -14,13 +14,20 @@
 
 package databricksreceiver
 
-import "fmt"
+import (
+	"encoding/base64"
+	"fmt"
+)
 
 // databricksClientInterface is extracted from databricksClient for swapping out in unit tests
 type databricksClientInterface interface {
 	jobs() (out []job, err error)
 	activeJobRuns() (out []jobRun, err error)
 	completedJobRuns(jobID int, time int64) (out []jobRun, err error)
+	activeJobRunTasks() (out []jobRun, err error)
+	clusterLogDestination(clusterID string) (string, error)
+	listFiles(path string) ([]dbfsFile, error)
+	readFile(path string, offset int64, length int) ([]byte, error)
 }
 
 // databricksClient handles pagination (responses specify hasMore=true/false) and
@@ -63,10 +70,12 @@ func (c databricksClient) activeJobRuns() (out []jobRun, err error) {
 	return out, nil
 }
 
+// completedJobRuns only requests the runs started since prevStartTime, if known, so that large workspaces
+// aren't re-listed every interval.
 func (c databricksClient) completedJobRuns(jobID int, prevStartTime int64) (out []jobRun, err error) {
 	hasMore := true
 	for i := 0; hasMore; i++ {
-		resp, err := c.unmarshaller.completedJobRuns(jobID, c.limit, c.limit*i)
+		resp, err := c.unmarshaller.completedJobRuns(jobID, c.limit, c.limit*i, prevStartTime)
 		if err != nil {
 			return nil, fmt.Errorf("databricksClient.completedJobRuns(): %w", err)
 		}
@@ -81,3 +90,46 @@ func (c databricksClient) completedJobRuns(jobID int, prevStartTime int64) (out
 	}
 	return out, nil
 }
+
+func (c databricksClient) activeJobRunTasks() (out []jobRun, err error) {
+	hasMore := true
+	for i := 0; hasMore; i++ {
+		resp, err := c.unmarshaller.activeJobRunTasks(c.limit, c.limit*i)
+		if err != nil {
+			return nil, fmt.Errorf("databricksClient.activeJobRunTasks(): %w", err)
+		}
+		out = append(out, resp.Runs...)
+		hasMore = resp.HasMore
+	}
+	return out, nil
+}
+
+// clusterLogDestination returns the DBFS destination the cluster delivers its logs to, or an
+// empty string if cluster log delivery isn't configured or isn't to DBFS.
+func (c databricksClient) clusterLogDestination(clusterID string) (string, error) {
+	info, err := c.unmarshaller.clusterInfo(clusterID)
+	if err != nil {
+		return "", fmt.Errorf("databricksClient.clusterLogDestination(): %w", err)
+	}
+	return info.ClusterLogConf.DBFS.Destination, nil
+}
+
+func (c databricksClient) listFiles(path string) ([]dbfsFile, error) {
+	resp, err := c.unmarshaller.dbfsList(path)
+	if err != nil {
+		return nil, fmt.Errorf("databricksClient.listFiles(): %w", err)
+	}
+	return resp.Files, nil
+}
+
+func (c databricksClient) readFile(path string, offset int64, length int) ([]byte, error) {
+	resp, err := c.unmarshaller.dbfsRead(path, offset, length)
+	if err != nil {
+		return nil, fmt.Errorf("databricksClient.readFile(): %w", err)
+	}
+	data, err := base64.StdEncoding.DecodeString(resp.Data)
+	if err != nil {
+		return nil, fmt.Errorf("databricksClient.readFile(): %w", err)
+	}
+	return data, nil
+}
@@ -49,3 +49,26 @@ func TestDatabricksClient_CompletedRuns(t *testing.T) {
 	require.NoError(t, err)
 	assert.Equal(t, 67, len(runs))
 }
+
+func TestDatabricksClient_ClusterLogs(t *testing.T) {
+	const ignored = 25
+	c := newDatabricksClient(&testdataClient{}, ignored)
+
+	runs, err := c.activeJobRunTasks()
+	require.NoError(t, err)
+	require.Equal(t, 1, len(runs))
+	require.Equal(t, 1, len(runs[0].Tasks))
+	assert.Equal(t, "0125-162513-ueqw4u1i", runs[0].Tasks[0].ClusterInstance.ClusterID)
+
+	destination, err := c.clusterLogDestination("0125-162513-ueqw4u1i")
+	require.NoError(t, err)
+	assert.Equal(t, "dbfs:/cluster-logs", destination)
+
+	files, err := c.listFiles("/cluster-logs")
+	require.NoError(t, err)
+	assert.Empty(t, files)
+
+	data, err := c.readFile("/cluster-logs/0125-162513-ueqw4u1i/driver/stdout", 0, 100)
+	require.NoError(t, err)
+	assert.Empty(t, data)
+}
@@ -24,26 +24,66 @@ import (
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/config/confighttp"
 	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
 	"go.opentelemetry.io/collector/receiver/scraperhelper"
 	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
 )
 
 const typeStr = "databricks"
 
-func NewFactory() component.ReceiverFactory {
+// FactoryOption applies changes to databricksReceiverFactory.
+type FactoryOption func(factory *databricksReceiverFactory)
+
+// WithClock sets the clock of the collection intervals, so that tests can advance them with a
+// clock.Fake instead of waiting for them.
+func WithClock(c clock.Clock) FactoryOption {
+	return func(factory *databricksReceiverFactory) {
+		factory.clock = c
+	}
+}
+
+type databricksReceiverFactory struct {
+	// clock is nil for the real time, whose metrics collection intervals are ticked by the scraper controller.
+	clock clock.Clock
+}
+
+func NewFactory(options ...FactoryOption) component.ReceiverFactory {
+	f := &databricksReceiverFactory{}
+	for _, option := range options {
+		option(f)
+	}
 	return component.NewReceiverFactory(
 		typeStr,
 		createDefaultConfig,
-		component.WithMetricsReceiver(createReceiverFunc(newAPIClient)),
+		component.WithMetricsReceiver(createReceiverFunc(newAPIClient, f.clock)),
+		component.WithLogsReceiver(createLogsReceiverFunc(newAPIClient, f.clock)),
 	)
 }
 
 type Config struct {
+	// StorageID is the storage extension used to checkpoint the latest seen job
+	// runs. Without it, runs completed while the collector isn't running aren't reported.
+	StorageID                               *config.ComponentID `mapstructure:"storage"`
 	confighttp.HTTPClientSettings           `mapstructure:",squash"`
 	InstanceName                            string `mapstructure:"instance_name"`
 	Token                                   string
 	scraperhelper.ScraperControllerSettings `mapstructure:",squash"`
-	MaxResults                              int `mapstructure:"max_results"`
+	RateLimit                               RateLimitSettings `mapstructure:"rate_limit"`
+	MaxResults                              int               `mapstructure:"max_results"`
+}
+
+var _ config.Receiver = (*Config)(nil)
+
+func (c *Config) Validate() error {
+	if c.RateLimit.RequestsPerSecond < 0 {
+		return fmt.Errorf("rate_limit.requests_per_second must be non-negative: %v", c.RateLimit.RequestsPerSecond)
+	}
+	if c.RateLimit.RequestsPerSecond > 0 && c.RateLimit.Burst < 1 {
+		return fmt.Errorf("rate_limit.burst must be at least 1: %d", c.RateLimit.Burst)
+	}
+	return nil
 }
 
 func createDefaultConfig() config.Receiver {
@@ -54,10 +94,11 @@ func createDefaultConfig() config.Receiver {
 	return &Config{
 		MaxResults:                25, // 25 is the max the API supports
 		ScraperControllerSettings: scs,
+		RateLimit:                 RateLimitSettings{Burst: 1},
 	}
 }
 
-func createReceiverFunc(createAPIClient func(baseURL string, tok string, httpClient *http.Client, logger *zap.Logger) apiClientInterface) func(
+func createReceiverFunc(createAPIClient func(baseURL string, tok string, httpClient *http.Client, logger *zap.Logger) apiClientInterface, clk clock.Clock) func(
 	_ context.Context,
 	settings component.ReceiverCreateSettings,
 	cfg config.Receiver,
@@ -74,13 +115,31 @@ func createReceiverFunc(createAPIClient func(baseURL string, tok string, httpCli
 		if err != nil {
 			return nil, fmt.Errorf("%s: createReceiverFunc closure: %w", typeStr, err)
 		}
+		httpClient = withRateLimit(httpClient, dbcfg.RateLimit)
 		c := newDatabricksClient(createAPIClient(dbcfg.Endpoint, dbcfg.Token, httpClient, settings.Logger), dbcfg.MaxResults)
+		rmp := newRunMetricsProvider(c)
 		s := scraper{
 			instanceName: dbcfg.InstanceName,
-			rmp:          newRunMetricsProvider(c),
+			rmp:          rmp,
 			mp:           newMetricsProvider(c),
+			checkpointer: newRunCheckpointer(dbcfg.StorageID, dbcfg.ID(), rmp.tracker, settings.Logger),
+		}
+		shutdown := s.checkpointer.shutdown
+		var options []scraperhelper.ScraperControllerOption
+		if clk != nil {
+			ticker := clk.NewTicker(dbcfg.CollectionInterval)
+			options = append(options, scraperhelper.WithTickerChannel(ticker.Chan()))
+			shutdown = func(ctx context.Context) error {
+				ticker.Stop()
+				return s.checkpointer.shutdown(ctx)
+			}
 		}
-		scrpr, err := scraperhelper.NewScraper(typeStr, s.scrape)
+		scrpr, err := scraperhelper.NewScraper(
+			typeStr,
+			s.scrape,
+			scraperhelper.WithStart(s.checkpointer.start),
+			scraperhelper.WithShutdown(shutdown),
+		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: createReceiverFunc closure: %w", typeStr, err)
 		}
@@ -88,7 +147,47 @@ func createReceiverFunc(createAPIClient func(baseURL string, tok string, httpCli
 			&dbcfg.ScraperControllerSettings,
 			settings,
 			consumer,
-			scraperhelper.AddScraper(scrpr),
+			append(options, scraperhelper.AddScraper(scrpr))...,
 		)
 	}
 }
+
+func createLogsReceiverFunc(createAPIClient func(baseURL string, tok string, httpClient *http.Client, logger *zap.Logger) apiClientInterface, clk clock.Clock) func(
+	_ context.Context,
+	settings component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	consumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	return func(
+		_ context.Context,
+		settings component.ReceiverCreateSettings,
+		cfg config.Receiver,
+		consumer consumer.Logs,
+	) (component.LogsReceiver, error) {
+		dbcfg := cfg.(*Config)
+		httpClient, err := dbcfg.ToClient(nil, settings.TelemetrySettings)
+		if err != nil {
+			return nil, fmt.Errorf("%s: createLogsReceiverFunc closure: %w", typeStr, err)
+		}
+		httpClient = withRateLimit(httpClient, dbcfg.RateLimit)
+		c := newDatabricksClient(createAPIClient(dbcfg.Endpoint, dbcfg.Token, httpClient, settings.Logger), dbcfg.MaxResults)
+		if clk == nil {
+			clk = clock.New()
+		}
+		provider := newLogsProvider(c, dbcfg.InstanceName, settings.Logger)
+		provider.now = clk.Now
+		return &logsReceiver{
+			nextConsumer: consumer,
+			provider:     provider,
+			clock:        clk,
+			obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+				ReceiverID:             dbcfg.ID(),
+				Transport:              "http",
+				ReceiverCreateSettings: settings,
+			}),
+			logger:   settings.Logger,
+			done:     make(chan struct{}),
+			interval: dbcfg.CollectionInterval,
+		}, nil
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"context"
 	"net/http"
 	"path"
+	"sync"
 	"testing"
 	"time"
 
@@ -30,6 +31,8 @@ import (
 	"go.opentelemetry.io/collector/service/servicetest"
 	"go.opentelemetry.io/otel/trace"
 	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
 )
 
 func TestFactory(t *testing.T) {
@@ -43,7 +46,7 @@ func TestFactory(t *testing.T) {
 
 func TestCreateReceiver(t *testing.T) {
 	ctx := context.Background()
-	f := createReceiverFunc(func(string, string, *http.Client, *zap.Logger) apiClientInterface { return &testdataClient{} })
+	f := createReceiverFunc(func(string, string, *http.Client, *zap.Logger) apiClientInterface { return &testdataClient{} }, nil)
 	receiver, err := f(
 		ctx,
 		component.ReceiverCreateSettings{
@@ -61,6 +64,69 @@ func TestCreateReceiver(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCreateLogsReceiver(t *testing.T) {
+	ctx := context.Background()
+	f := createLogsReceiverFunc(func(string, string, *http.Client, *zap.Logger) apiClientInterface { return &testdataClient{} }, nil)
+	receiver, err := f(
+		ctx,
+		componenttest.NewNopReceiverCreateSettings(),
+		createDefaultConfig(),
+		consumertest.NewNop(),
+	)
+	require.NoError(t, err)
+	err = receiver.Start(ctx, componenttest.NewNopHost())
+	require.NoError(t, err)
+	err = receiver.Shutdown(ctx)
+	require.NoError(t, err)
+}
+
+func TestCreateReceiversWithClock(t *testing.T) {
+	ctx := context.Background()
+	fake := clock.NewFake(time.Now())
+	client := &countingClient{}
+	newClient := func(string, string, *http.Client, *zap.Logger) apiClientInterface { return client }
+	cfg := createDefaultConfig()
+
+	sink := &consumertest.MetricsSink{}
+	metricsReceiver, err := createReceiverFunc(newClient, fake)(ctx, componenttest.NewNopReceiverCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, metricsReceiver.Start(ctx, componenttest.NewNopHost()))
+
+	logsReceiver, err := createLogsReceiverFunc(newClient, fake)(ctx, componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NoError(t, logsReceiver.Start(ctx, componenttest.NewNopHost()))
+	require.Eventually(t, func() bool { return client.collections() == 1 && fake.Tickers() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	// the intervals only elapse when the clock is advanced
+	fake.Advance(cfg.(*Config).CollectionInterval)
+	require.Eventually(t, func() bool { return len(sink.AllMetrics()) >= 1 }, 5*time.Second, 10*time.Millisecond)
+	require.Eventually(t, func() bool { return client.collections() == 2 }, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, metricsReceiver.Shutdown(ctx))
+	require.NoError(t, logsReceiver.Shutdown(ctx))
+	assert.Equal(t, 0, fake.Tickers())
+}
+
+// countingClient counts the log collections of a logs receiver, which get the active job run tasks first.
+type countingClient struct {
+	testdataClient
+	logCollections int
+	lock           sync.Mutex
+}
+
+func (c *countingClient) activeJobRunTasks(limit int, offset int) ([]byte, error) {
+	c.lock.Lock()
+	c.logCollections++
+	c.lock.Unlock()
+	return c.testdataClient.activeJobRunTasks(limit, offset)
+}
+
+func (c *countingClient) collections() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.logCollections
+}
+
 func TestParseConfig(t *testing.T) {
 	factories, err := componenttest.NopFactories()
 	require.NoError(t, err)
@@ -73,4 +139,18 @@ func TestParseConfig(t *testing.T) {
 	assert.Equal(t, "https://my.databricks.instance", rcfg.Endpoint)
 	duration, _ := time.ParseDuration("10s")
 	assert.Equal(t, duration, rcfg.CollectionInterval)
+	storageID := config.NewComponentID("file_storage")
+	assert.Equal(t, &storageID, rcfg.StorageID)
+	assert.Equal(t, RateLimitSettings{RequestsPerSecond: 5, Burst: 10}, rcfg.RateLimit)
+}
+
+func TestValidateConfig(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	require.NoError(t, cfg.Validate())
+
+	cfg.RateLimit = RateLimitSettings{RequestsPerSecond: -1}
+	require.EqualError(t, cfg.Validate(), "rate_limit.requests_per_second must be non-negative: -1")
+
+	cfg.RateLimit = RateLimitSettings{RequestsPerSecond: 1}
+	require.EqualError(t, cfg.Validate(), "rate_limit.burst must be at least 1: 0")
 }
@@ -153,3 +153,33 @@ type state struct {
 	ResultState             string `json:"result_state,omitempty"`
 	UserCancelledOrTimedout bool   `json:"user_cancelled_or_timedout"`
 }
+
+type clusterInfo struct {
+	ClusterLogConf clusterLogConf `json:"cluster_log_conf"`
+	ClusterID      string         `json:"cluster_id"`
+	ClusterName    string         `json:"cluster_name"`
+}
+
+type clusterLogConf struct {
+	DBFS dbfsStorageInfo `json:"dbfs"`
+}
+
+type dbfsStorageInfo struct {
+	Destination string `json:"destination"`
+}
+
+type dbfsFiles struct {
+	Files []dbfsFile `json:"files"`
+}
+
+type dbfsFile struct {
+	Path             string `json:"path"`
+	FileSize         int64  `json:"file_size"`
+	ModificationTime int64  `json:"modification_time"`
+	IsDir            bool   `json:"is_dir"`
+}
+
+type dbfsReadResponse struct {
+	Data      string `json:"data"`
+	BytesRead int64  `json:"bytes_read"`
+}
@@ -0,0 +1,305 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"bytes"
+	"path"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/databricksreceiver/internal/metadata"
+)
+
+const (
+	clusterIDAttr  = "databricks.cluster.id"
+	jobIDAttr      = "databricks.job.id"
+	runIDAttr      = "databricks.run.id"
+	taskKeyAttr    = "databricks.task.key"
+	logSourceAttr  = "databricks.log.source"
+	executorIDAttr = "databricks.executor.id"
+	fileNameAttr   = "log.file.name"
+	filePathAttr   = "log.file.path"
+
+	driverLogSource   = "driver"
+	executorLogSource = "executor"
+
+	// maxReadLength is the maximum length of a DBFS API read
+	maxReadLength = 1024 * 1024
+	// completedRunGracePeriod is how long the logs of clusters whose runs are no longer active are still
+	// collected, since clusters deliver their logs every five minutes and when terminated.
+	completedRunGracePeriod = 10 * time.Minute
+)
+
+// collectedLogFiles are the names of the delivered driver and executor log files that are collected.
+// Rolled over files are compressed or only contain already collected content.
+var collectedLogFiles = map[string]bool{
+	"stdout":           true,
+	"stderr":           true,
+	"log4j-active.log": true,
+}
+
+// logsProvider wraps a databricksClientInterface and provides the delivered driver and executor
+// logs of the clusters running active job runs as log records.
+type logsProvider struct {
+	dbClient     databricksClientInterface
+	logger       *zap.Logger
+	now          func() time.Time
+	clusters     map[string]*runCluster
+	offsets      map[string]int64
+	instanceName string
+	started      bool
+}
+
+// runCluster is a cluster running a job run's task, whose logs are attributed to that run.
+type runCluster struct {
+	lastActive  time.Time
+	destination *string
+	taskKey     string
+	jobID       int
+	runID       int
+}
+
+type logFile struct {
+	path       string
+	source     string
+	executorID string
+	size       int64
+}
+
+func newLogsProvider(dbClient databricksClientInterface, instanceName string, logger *zap.Logger) *logsProvider {
+	return &logsProvider{
+		dbClient:     dbClient,
+		logger:       logger,
+		now:          time.Now,
+		clusters:     map[string]*runCluster{},
+		offsets:      map[string]int64{},
+		instanceName: instanceName,
+	}
+}
+
+// logs returns the log lines delivered since the previous call by the clusters of active job runs
+// and of runs completed within the grace period.  Logs already delivered when first called are skipped.
+func (p *logsProvider) logs() (plog.Logs, error) {
+	out := plog.NewLogs()
+	if err := p.trackClusters(); err != nil {
+		return out, err
+	}
+
+	for clusterID, cluster := range p.clusters {
+		if cluster.destination == nil {
+			destination, err := p.dbClient.clusterLogDestination(clusterID)
+			if err != nil {
+				p.logger.Debug("Failed getting cluster log destination", zap.String("cluster_id", clusterID), zap.Error(err))
+				continue
+			}
+			if destination == "" {
+				p.logger.Debug("Cluster doesn't deliver logs to DBFS", zap.String("cluster_id", clusterID))
+			}
+			cluster.destination = &destination
+		}
+		if *cluster.destination == "" {
+			continue
+		}
+		p.addClusterLogs(out, clusterID, cluster)
+	}
+	p.started = true
+	return out, nil
+}
+
+// trackClusters records the clusters of active job runs' tasks and stops tracking those
+// not running an active job run within the grace period.
+func (p *logsProvider) trackClusters() error {
+	runs, err := p.dbClient.activeJobRunTasks()
+	if err != nil {
+		return err
+	}
+	now := p.now()
+	for _, run := range runs {
+		for _, task := range run.Tasks {
+			clusterID := task.ClusterInstance.ClusterID
+			if clusterID == "" {
+				continue
+			}
+			cluster, ok := p.clusters[clusterID]
+			if !ok {
+				cluster = &runCluster{}
+				p.clusters[clusterID] = cluster
+			}
+			cluster.jobID, cluster.runID, cluster.taskKey = run.JobID, run.RunID, task.TaskKey
+			cluster.lastActive = now
+		}
+	}
+	for clusterID, cluster := range p.clusters {
+		if now.Sub(cluster.lastActive) > completedRunGracePeriod {
+			delete(p.clusters, clusterID)
+			p.forgetOffsets(clusterID, cluster)
+		}
+	}
+	return nil
+}
+
+func (p *logsProvider) forgetOffsets(clusterID string, cluster *runCluster) {
+	if cluster.destination == nil || *cluster.destination == "" {
+		return
+	}
+	prefix := clusterLogPath(*cluster.destination, clusterID) + "/"
+	for filePath := range p.offsets {
+		if strings.HasPrefix(filePath, prefix) {
+			delete(p.offsets, filePath)
+		}
+	}
+}
+
+func (p *logsProvider) addClusterLogs(out plog.Logs, clusterID string, cluster *runCluster) {
+	var lrs *plog.LogRecordSlice
+	observed := pcommon.NewTimestampFromTime(p.now())
+	for _, file := range p.clusterLogFiles(clusterLogPath(*cluster.destination, clusterID)) {
+		lines := p.readNewLines(file)
+		if len(lines) == 0 {
+			continue
+		}
+		if lrs == nil {
+			clusterRecords := appendClusterResourceLogs(out, p.instanceName, clusterID, cluster)
+			lrs = &clusterRecords
+		}
+		for _, line := range lines {
+			lr := lrs.AppendEmpty()
+			lr.SetObservedTimestamp(observed)
+			lr.Body().SetStringVal(line)
+			attrs := lr.Attributes()
+			attrs.InsertString(logSourceAttr, file.source)
+			attrs.InsertString(fileNameAttr, path.Base(file.path))
+			attrs.InsertString(filePathAttr, file.path)
+			if file.executorID != "" {
+				attrs.InsertString(executorIDAttr, file.executorID)
+			}
+		}
+	}
+}
+
+func appendClusterResourceLogs(out plog.Logs, instanceName string, clusterID string, cluster *runCluster) plog.LogRecordSlice {
+	rl := out.ResourceLogs().AppendEmpty()
+	attrs := rl.Resource().Attributes()
+	attrs.InsertString(metadata.A.DatabricksInstanceName, instanceName)
+	attrs.InsertString(clusterIDAttr, clusterID)
+	attrs.InsertInt(jobIDAttr, int64(cluster.jobID))
+	attrs.InsertInt(runIDAttr, int64(cluster.runID))
+	attrs.InsertString(taskKeyAttr, cluster.taskKey)
+	return rl.ScopeLogs().AppendEmpty().LogRecords()
+}
+
+// clusterLogFiles lists the collected driver log files, in <destination>/<cluster ID>/driver, and
+// executor log files, in <destination>/<cluster ID>/executor/<application ID>/<executor ID>.
+func (p *logsProvider) clusterLogFiles(clusterPath string) []logFile {
+	var files []logFile
+	for _, file := range p.list(clusterPath + "/driver") {
+		if !file.IsDir && collectedLogFiles[path.Base(file.Path)] {
+			files = append(files, logFile{path: file.Path, source: driverLogSource, size: file.FileSize})
+		}
+	}
+	for _, app := range p.list(clusterPath + "/executor") {
+		if !app.IsDir {
+			continue
+		}
+		for _, executor := range p.list(app.Path) {
+			if !executor.IsDir {
+				continue
+			}
+			for _, file := range p.list(executor.Path) {
+				if !file.IsDir && collectedLogFiles[path.Base(file.Path)] {
+					files = append(files, logFile{
+						path:       file.Path,
+						source:     executorLogSource,
+						executorID: path.Base(executor.Path),
+						size:       file.FileSize,
+					})
+				}
+			}
+		}
+	}
+	return files
+}
+
+func (p *logsProvider) list(dbfsPath string) []dbfsFile {
+	files, err := p.dbClient.listFiles(dbfsPath)
+	if err != nil {
+		// directories don't exist until logs are first delivered
+		p.logger.Debug("Failed listing cluster log files", zap.String("path", dbfsPath), zap.Error(err))
+	}
+	return files
+}
+
+// readNewLines returns the complete lines appended to the file since it was last read, starting
+// over if it was truncated.  An incomplete last line is returned once completed, or once it's
+// as long as the maximum read length.
+func (p *logsProvider) readNewLines(file logFile) []string {
+	offset, seen := p.offsets[file.path]
+	if !seen && !p.started {
+		// skip the logs delivered before the receiver was started
+		p.offsets[file.path] = file.size
+		return nil
+	}
+	if offset > file.size {
+		offset = 0
+	}
+
+	var lines []string
+	for offset < file.size {
+		length := file.size - offset
+		if length > maxReadLength {
+			length = maxReadLength
+		}
+		data, err := p.dbClient.readFile(file.path, offset, int(length))
+		if err != nil {
+			p.logger.Debug("Failed reading cluster log file", zap.String("path", file.path), zap.Error(err))
+			break
+		}
+		if len(data) == 0 {
+			break
+		}
+		end := bytes.LastIndexByte(data, '\n') + 1
+		if end == 0 {
+			if len(data) < maxReadLength {
+				break
+			}
+			end = len(data)
+		}
+		lines = append(lines, splitLines(data[:end])...)
+		offset += int64(end)
+	}
+	p.offsets[file.path] = offset
+	return lines
+}
+
+func splitLines(data []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimRight(line, "\r")
+		if line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// clusterLogPath returns the absolute DBFS path of a cluster's logs delivered to a destination like dbfs:/cluster-logs.
+func clusterLogPath(destination string, clusterID string) string {
+	return strings.TrimSuffix(strings.TrimPrefix(destination, "dbfs:"), "/") + "/" + clusterID
+}
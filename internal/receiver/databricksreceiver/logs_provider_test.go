@@ -0,0 +1,221 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+)
+
+// fakeLogsClient implements the databricksClientInterface methods used by the logsProvider
+// with in-memory runs, cluster log destinations, and DBFS files.
+type fakeLogsClient struct {
+	databricksClientInterface
+	destinations map[string]string
+	files        map[string]string
+	reads        map[string]int
+	runs         []jobRun
+}
+
+func (c *fakeLogsClient) activeJobRunTasks() ([]jobRun, error) {
+	return c.runs, nil
+}
+
+func (c *fakeLogsClient) clusterLogDestination(clusterID string) (string, error) {
+	return c.destinations[clusterID], nil
+}
+
+func (c *fakeLogsClient) listFiles(dir string) ([]dbfsFile, error) {
+	entries := map[string]dbfsFile{}
+	for filePath, content := range c.files {
+		rest := strings.TrimPrefix(filePath, dir+"/")
+		if rest == filePath {
+			continue
+		}
+		name, _, isDir := strings.Cut(rest, "/")
+		entry := dbfsFile{Path: dir + "/" + name, IsDir: isDir}
+		if !isDir {
+			entry.FileSize = int64(len(content))
+		}
+		entries[name] = entry
+	}
+	if len(entries) == 0 {
+		return nil, errors.New("RESOURCE_DOES_NOT_EXIST")
+	}
+	var files []dbfsFile
+	for _, entry := range entries {
+		files = append(files, entry)
+	}
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files, nil
+}
+
+func (c *fakeLogsClient) readFile(filePath string, offset int64, length int) ([]byte, error) {
+	c.reads[filePath]++
+	content := c.files[filePath]
+	end := int(offset) + length
+	if end > len(content) {
+		end = len(content)
+	}
+	return []byte(content[offset:end]), nil
+}
+
+const (
+	testClusterID   = "0125-162513-ueqw4u1i"
+	testDriverLog   = "/cluster-logs/" + testClusterID + "/driver/stdout"
+	testExecutorLog = "/cluster-logs/" + testClusterID + "/executor/app-20220125162610-0000/0/stderr"
+)
+
+func newTestLogsProvider() (*logsProvider, *fakeLogsClient, *time.Time) {
+	client := &fakeLogsClient{
+		destinations: map[string]string{testClusterID: "dbfs:/cluster-logs/"},
+		files: map[string]string{
+			testDriverLog: "delivered before startup\n",
+			"/cluster-logs/" + testClusterID + "/driver/log4j-2022-01-25-16.log.gz": "compressed",
+			testExecutorLog: "",
+		},
+		reads: map[string]int{},
+		runs: []jobRun{{
+			JobID: 288,
+			RunID: 317357,
+			Tasks: []jobRunTask{{TaskKey: "user-task", ClusterInstance: clusterInstance{ClusterID: testClusterID}}},
+		}},
+	}
+	now := time.Date(2022, 1, 25, 16, 30, 0, 0, time.UTC)
+	p := newLogsProvider(client, "my-instance", zap.NewNop())
+	p.now = func() time.Time { return now }
+	return p, client, &now
+}
+
+func logBodies(logs plog.Logs) []string {
+	var bodies []string
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		lrs := rls.At(i).ScopeLogs().At(0).LogRecords()
+		for j := 0; j < lrs.Len(); j++ {
+			bodies = append(bodies, lrs.At(j).Body().StringVal())
+		}
+	}
+	return bodies
+}
+
+func TestLogsProvider(t *testing.T) {
+	p, client, _ := newTestLogsProvider()
+
+	logs, err := p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.ResourceLogs().Len())
+
+	client.files[testDriverLog] += "line 1\r\nline 2\npartial"
+	client.files[testExecutorLog] += "executor line\n"
+	logs, err = p.logs()
+	require.NoError(t, err)
+	require.Equal(t, 1, logs.ResourceLogs().Len())
+	assert.Equal(t, map[string]any{
+		"databricks.instance.name": "my-instance",
+		"databricks.cluster.id":    testClusterID,
+		"databricks.job.id":        int64(288),
+		"databricks.run.id":        int64(317357),
+		"databricks.task.key":      "user-task",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	assert.Equal(t, []string{"line 1", "line 2", "executor line"}, logBodies(logs))
+	lrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	assert.Equal(t, map[string]any{
+		"databricks.log.source": "driver",
+		"log.file.name":         "stdout",
+		"log.file.path":         testDriverLog,
+	}, lrs.At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{
+		"databricks.log.source":  "executor",
+		"databricks.executor.id": "0",
+		"log.file.name":          "stderr",
+		"log.file.path":          testExecutorLog,
+	}, lrs.At(2).Attributes().AsRaw())
+	assert.NotZero(t, lrs.At(0).ObservedTimestamp())
+
+	client.files[testDriverLog] += " line\n"
+	logs, err = p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"partial line"}, logBodies(logs))
+
+	// rolled over files are read from their start
+	client.files[testDriverLog] = "rolled over\n"
+	logs, err = p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"rolled over"}, logBodies(logs))
+}
+
+func TestLogsProviderCompletedRuns(t *testing.T) {
+	p, client, now := newTestLogsProvider()
+	_, err := p.logs()
+	require.NoError(t, err)
+
+	// logs delivered after the run completed are collected within the grace period
+	client.runs = nil
+	*now = now.Add(completedRunGracePeriod)
+	client.files[testDriverLog] += "final delivery\n"
+	logs, err := p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"final delivery"}, logBodies(logs))
+	assert.Len(t, p.clusters, 1)
+
+	*now = now.Add(time.Second)
+	client.files[testDriverLog] += "after the grace period\n"
+	logs, err = p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.ResourceLogs().Len())
+	assert.Empty(t, p.clusters)
+	assert.Empty(t, p.offsets)
+}
+
+func TestLogsProviderWithoutLogDelivery(t *testing.T) {
+	p, client, _ := newTestLogsProvider()
+	client.destinations = map[string]string{}
+	_, err := p.logs()
+	require.NoError(t, err)
+
+	client.files[testDriverLog] += "not delivered\n"
+	logs, err := p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, 0, logs.ResourceLogs().Len())
+	assert.Empty(t, client.reads)
+}
+
+func TestLogsProviderLargeFiles(t *testing.T) {
+	p, client, _ := newTestLogsProvider()
+	_, err := p.logs()
+	require.NoError(t, err)
+
+	line := strings.Repeat("x", 99)
+	client.files[testExecutorLog] = strings.Repeat(line+"\n", 15000)
+	logs, err := p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, 15000, logs.LogRecordCount())
+	assert.Equal(t, 2, client.reads[testExecutorLog])
+
+	// lines as long as the maximum read length are split
+	client.files[testExecutorLog] += strings.Repeat("y", maxReadLength+10)
+	logs, err = p.logs()
+	require.NoError(t, err)
+	assert.Equal(t, []string{strings.Repeat("y", maxReadLength)}, logBodies(logs))
+}
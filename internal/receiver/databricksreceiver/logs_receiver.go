@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+var _ component.LogsReceiver = (*logsReceiver)(nil)
+
+// logsReceiver periodically provides the driver and executor logs of job run clusters
+// to the next consumer.
+type logsReceiver struct {
+	nextConsumer consumer.Logs
+	provider     *logsProvider
+	clock        clock.Clock
+	obsrecv      *obsreport.Receiver
+	logger       *zap.Logger
+	done         chan struct{}
+	wg           sync.WaitGroup
+	interval     time.Duration
+}
+
+func (r *logsReceiver) Start(context.Context, component.Host) error {
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		ticker := r.clock.NewTicker(r.interval)
+		defer ticker.Stop()
+		for {
+			r.collect(context.Background())
+			select {
+			case <-ticker.Chan():
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return nil
+}
+
+func (r *logsReceiver) Shutdown(context.Context) error {
+	select {
+	case <-r.done:
+		return nil
+	default:
+	}
+	close(r.done)
+	r.wg.Wait()
+	return nil
+}
+
+func (r *logsReceiver) collect(ctx context.Context) {
+	ctx = r.obsrecv.StartLogsOp(ctx)
+	logs, err := r.provider.logs()
+	count := logs.LogRecordCount()
+	if err == nil && count > 0 {
+		err = r.nextConsumer.ConsumeLogs(ctx, logs)
+	}
+	r.obsrecv.EndLogsOp(ctx, typeStr, count, err)
+	if err != nil {
+		r.logger.Warn("Failed collecting cluster logs", zap.Error(err))
+	}
+}
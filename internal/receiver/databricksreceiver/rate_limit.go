@@ -0,0 +1,60 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"net/http"
+
+	"golang.org/x/time/rate"
+)
+
+// RateLimitSettings limit the rate of requests to the Databricks API, which
+// enforces per-workspace rate limits shared with all other API clients.
+type RateLimitSettings struct {
+	// RequestsPerSecond is the sustained request rate. 0 disables rate limiting.
+	RequestsPerSecond float64 `mapstructure:"requests_per_second"`
+	// Burst is the number of requests that can be made at once before being
+	// limited to RequestsPerSecond.
+	Burst int `mapstructure:"burst"`
+}
+
+// rateLimitedTransport delays requests to stay within the limiter's budget.
+type rateLimitedTransport struct {
+	limiter *rate.Limiter
+	next    http.RoundTripper
+}
+
+func (t rateLimitedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if err := t.limiter.Wait(req.Context()); err != nil {
+		return nil, err
+	}
+	return t.next.RoundTrip(req)
+}
+
+// withRateLimit wraps the client's transport with a rate limiter, if enabled.
+func withRateLimit(httpClient *http.Client, settings RateLimitSettings) *http.Client {
+	if settings.RequestsPerSecond == 0 {
+		return httpClient
+	}
+	next := httpClient.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	httpClient.Transport = rateLimitedTransport{
+		limiter: rate.NewLimiter(rate.Limit(settings.RequestsPerSecond), settings.Burst),
+		next:    next,
+	}
+	return httpClient
+}
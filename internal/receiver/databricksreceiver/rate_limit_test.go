@@ -0,0 +1,49 @@
+// Copyright The OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//       http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package databricksreceiver
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWithRateLimitDisabled(t *testing.T) {
+	httpClient := &http.Client{}
+	assert.Nil(t, withRateLimit(httpClient, RateLimitSettings{Burst: 1}).Transport)
+}
+
+func TestWithRateLimit(t *testing.T) {
+	h := &fakeHandler{}
+	svr := httptest.NewServer(h)
+	defer svr.Close()
+
+	httpClient := withRateLimit(&http.Client{}, RateLimitSettings{RequestsPerSecond: 20, Burst: 2})
+	require.IsType(t, rateLimitedTransport{}, httpClient.Transport)
+
+	start := time.Now()
+	for i := 0; i < 4; i++ {
+		resp, err := httpClient.Get(svr.URL)
+		require.NoError(t, err)
+		require.NoError(t, resp.Body.Close())
+	}
+	// the burst is immediate and the remaining two requests wait 50ms each
+	assert.GreaterOrEqual(t, time.Since(start), 90*time.Millisecond)
+	assert.Equal(t, 4, len(h.reqs))
+}
@@ -99,6 +99,22 @@ func (c *fakeCompletedJobRunClient) completedJobRuns(jobID int, _ int64) ([]jobR
 	return c.runs, nil
 }
 
+func (c *fakeCompletedJobRunClient) activeJobRunTasks() (out []jobRun, err error) {
+	return nil, nil
+}
+
+func (c *fakeCompletedJobRunClient) clusterLogDestination(string) (string, error) {
+	return "", nil
+}
+
+func (c *fakeCompletedJobRunClient) listFiles(string) ([]dbfsFile, error) {
+	return nil, nil
+}
+
+func (c *fakeCompletedJobRunClient) readFile(string, int64, int) ([]byte, error) {
+	return nil, nil
+}
+
 func (c *fakeCompletedJobRunClient) addCompletedRun(jobID int) {
 	c.runs = append([]jobRun{{
 		JobID:             jobID,
@@ -57,3 +57,21 @@ func collectNewRuns(runs []jobRun, prev int64) (out []jobRun) {
 	}
 	return out
 }
+
+// startTimes returns a copy of the tracked start times by job ID.
+func (t *runTracker) startTimes() map[int]int64 {
+	out := make(map[int]int64, len(t.startTimesByJobID))
+	for jobID, startTime := range t.startTimesByJobID {
+		out[jobID] = startTime
+	}
+	return out
+}
+
+// restore replaces the tracked start times with checkpointed ones, so that runs started
+// after them are considered new instead of being discarded at startup.
+func (t *runTracker) restore(startTimes map[int]int64) {
+	t.startTimesByJobID = make(map[int]int64, len(startTimes))
+	for jobID, startTime := range startTimes {
+		t.startTimesByJobID[jobID] = startTime
+	}
+}
@@ -44,3 +44,17 @@ func TestRunTracker(t *testing.T) {
 	latest = tracker.extractNewRuns(runs)
 	assert.Equal(t, 2, len(latest))
 }
+
+func TestRunTrackerRestore(t *testing.T) {
+	tracker := newRunTracker()
+	tracker.restore(map[int]int64{42: 1_600_000_000_000})
+	assert.Equal(t, int64(1_600_000_000_000), tracker.getPrevStartTime(42))
+
+	startTimes := tracker.startTimes()
+	startTimes[42] = 0
+	assert.Equal(t, int64(1_600_000_000_000), tracker.getPrevStartTime(42))
+
+	// runs newer than the restored start time aren't discarded
+	latest := tracker.extractNewRuns([]jobRun{{JobID: 42, StartTime: 1_600_001_000_000}})
+	assert.Equal(t, 1, len(latest))
+}
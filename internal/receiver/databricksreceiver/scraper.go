@@ -28,12 +28,13 @@ import (
 // method is the entry point into this receiver's functionality, running on a
 // timer, and building metrics from metrics providers.
 type scraper struct {
+	checkpointer *runCheckpointer
 	rmp          runMetricsProvider
 	mp           metricsProvider
 	instanceName string
 }
 
-func (s scraper) scrape(_ context.Context) (pmetric.Metrics, error) {
+func (s scraper) scrape(ctx context.Context) (pmetric.Metrics, error) {
 	out := pmetric.NewMetrics()
 	rms := out.ResourceMetrics()
 	rm := rms.AppendEmpty()
@@ -63,5 +64,9 @@ func (s scraper) scrape(_ context.Context) (pmetric.Metrics, error) {
 		return out, fmt.Errorf(errfmt, err)
 	}
 
+	if s.checkpointer != nil {
+		s.checkpointer.save(ctx)
+	}
+
 	return out, err
 }
@@ -46,8 +46,8 @@ func (u unmarshaller) activeJobRuns(limit int, offset int) (jobRuns, error) {
 	return out, err
 }
 
-func (u unmarshaller) completedJobRuns(jobID int, limit int, offset int) (jobRuns, error) {
-	bytes, err := u.api.completedJobRuns(jobID, limit, offset)
+func (u unmarshaller) completedJobRuns(jobID int, limit int, offset int, startTimeFrom int64) (jobRuns, error) {
+	bytes, err := u.api.completedJobRuns(jobID, limit, offset, startTimeFrom)
 	out := jobRuns{}
 	if err != nil {
 		return out, fmt.Errorf("unmarshaller.completedJobRuns(): %w", err)
@@ -55,3 +55,43 @@ func (u unmarshaller) completedJobRuns(jobID int, limit int, offset int) (jobRun
 	err = json.Unmarshal(bytes, &out)
 	return out, err
 }
+
+func (u unmarshaller) activeJobRunTasks(limit int, offset int) (jobRuns, error) {
+	bytes, err := u.api.activeJobRunTasks(limit, offset)
+	out := jobRuns{}
+	if err != nil {
+		return out, fmt.Errorf("unmarshaller.activeJobRunTasks(): %w", err)
+	}
+	err = json.Unmarshal(bytes, &out)
+	return out, err
+}
+
+func (u unmarshaller) clusterInfo(clusterID string) (clusterInfo, error) {
+	bytes, err := u.api.clusterInfo(clusterID)
+	out := clusterInfo{}
+	if err != nil {
+		return out, fmt.Errorf("unmarshaller.clusterInfo(): %w", err)
+	}
+	err = json.Unmarshal(bytes, &out)
+	return out, err
+}
+
+func (u unmarshaller) dbfsList(path string) (dbfsFiles, error) {
+	bytes, err := u.api.dbfsList(path)
+	out := dbfsFiles{}
+	if err != nil {
+		return out, fmt.Errorf("unmarshaller.dbfsList(): %w", err)
+	}
+	err = json.Unmarshal(bytes, &out)
+	return out, err
+}
+
+func (u unmarshaller) dbfsRead(path string, offset int64, length int) (dbfsReadResponse, error) {
+	bytes, err := u.api.dbfsRead(path, offset, length)
+	out := dbfsReadResponse{}
+	if err != nil {
+		return out, fmt.Errorf("unmarshaller.dbfsRead(): %w", err)
+	}
+	err = json.Unmarshal(bytes, &out)
+	return out, err
+}
@@ -0,0 +1,72 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+)
+
+var _ config.Receiver = (*Config)(nil)
+
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+	// HTTPClientSettings are those of the requests to the administrative REST API of the mqweb server,
+	// whose endpoint is e.g. https://mq.example.com:9443.
+	confighttp.HTTPClientSettings `mapstructure:",squash"`
+	// QueueManager is the name of the queue manager whose queues, channels, and listeners are monitored.
+	QueueManager string `mapstructure:"queue_manager"`
+	// Username and Password authenticate the requests of a user with the MQWebAdmin or MQWebAdminRO role.
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+	// Queues are the names, or generic names ending with *, of the local queues whose status is collected.
+	Queues []string `mapstructure:"queues"`
+	// Channels are the names, or generic names ending with *, of the channels whose status is collected.
+	Channels []string `mapstructure:"channels"`
+	// IncludeSystemObjects also collects the status of the SYSTEM.* queues, channels, and listeners.
+	IncludeSystemObjects bool          `mapstructure:"include_system_objects"`
+	CollectionInterval   time.Duration `mapstructure:"collection_interval"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	if u, err := url.Parse(cfg.Endpoint); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("endpoint must be an http or https URL: %q", cfg.Endpoint)
+	}
+	if cfg.QueueManager == "" {
+		return errors.New("queue_manager must not be empty")
+	}
+	if cfg.CollectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be positive: %v", cfg.CollectionInterval)
+	}
+	for i, queue := range cfg.Queues {
+		if queue == "" {
+			return fmt.Errorf("queues[%d]: name must not be empty", i)
+		}
+	}
+	for i, channel := range cfg.Channels {
+		if channel == "" {
+			return fmt.Errorf("channels[%d]: name must not be empty", i)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, len(cfg.Receivers), 3)
+
+	defaultCfg := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), defaultCfg)
+	assert.EqualError(t, defaultCfg.Validate(), "queue_manager must not be empty")
+
+	allSettings := cfg.Receivers[config.NewComponentIDWithName(typeStr, "allsettings")].(*Config)
+	assert.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "allsettings")),
+		HTTPClientSettings: confighttp.HTTPClientSettings{
+			Endpoint: "https://mq.example.com:9443",
+			TLSSetting: configtls.TLSClientSetting{
+				TLSSetting: configtls.TLSSetting{CAFile: "/etc/ssl/mqweb.pem"},
+			},
+			Timeout: 5 * time.Second,
+		},
+		QueueManager:         "QM1",
+		Username:             "monitor",
+		Password:             "secret",
+		Queues:               []string{"APP.*", "ORDERS"},
+		Channels:             []string{"TO.QM2"},
+		IncludeSystemObjects: true,
+		CollectionInterval:   30 * time.Second,
+	}, allSettings)
+	require.NoError(t, allSettings.Validate())
+
+	invalidEndpoint := cfg.Receivers[config.NewComponentIDWithName(typeStr, "invalidendpoint")]
+	assert.EqualError(t, invalidEndpoint.Validate(), `endpoint must be an http or https URL: "mq.example.com:9443"`)
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		modify      func(cfg *Config)
+		expectedErr string
+	}{
+		{
+			name:        "missing endpoint",
+			modify:      func(cfg *Config) { cfg.Endpoint = "" },
+			expectedErr: "endpoint must not be empty",
+		},
+		{
+			name:        "non-positive collection interval",
+			modify:      func(cfg *Config) { cfg.CollectionInterval = 0 },
+			expectedErr: "collection_interval must be positive: 0s",
+		},
+		{
+			name:        "empty queue name",
+			modify:      func(cfg *Config) { cfg.Queues = []string{"APP.*", ""} },
+			expectedErr: "queues[1]: name must not be empty",
+		},
+		{
+			name:        "empty channel name",
+			modify:      func(cfg *Config) { cfg.Channels = []string{""} },
+			expectedErr: "channels[0]: name must not be empty",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.QueueManager = "QM1"
+			require.NoError(t, cfg.Validate())
+			test.modify(cfg)
+			assert.EqualError(t, cfg.Validate(), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+const (
+	typeStr = "ibmmq"
+
+	defaultEndpoint           = "https://localhost:9443"
+	defaultTimeout            = 10 * time.Second
+	defaultCollectionInterval = time.Minute
+)
+
+var (
+	// receivers are shared by the metrics and logs receivers of the same config
+	// so that the queue manager is only queried once per collection interval.
+	receivers     = map[*Config]*ibmmqReceiver{}
+	receiversLock sync.Mutex
+)
+
+func NewFactory() component.ReceiverFactory {
+	return component.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithMetricsReceiver(createMetricsReceiver),
+		component.WithLogsReceiver(createLogsReceiver),
+	)
+}
+
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
+		HTTPClientSettings: confighttp.HTTPClientSettings{
+			Endpoint: defaultEndpoint,
+			Timeout:  defaultTimeout,
+		},
+		Queues:             []string{"*"},
+		Channels:           []string{"*"},
+		CollectionInterval: defaultCollectionInterval,
+	}
+}
+
+func createMetricsReceiver(
+	_ context.Context,
+	settings component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Metrics,
+) (component.MetricsReceiver, error) {
+	if nextConsumer == nil {
+		return nil, component.ErrNilNextConsumer
+	}
+	r := getOrCreateReceiver(settings, cfg.(*Config))
+	r.nextMetrics = nextConsumer
+	return r, nil
+}
+
+func createLogsReceiver(
+	_ context.Context,
+	settings component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	if nextConsumer == nil {
+		return nil, component.ErrNilNextConsumer
+	}
+	r := getOrCreateReceiver(settings, cfg.(*Config))
+	r.nextLogs = nextConsumer
+	return r, nil
+}
+
+func getOrCreateReceiver(settings component.ReceiverCreateSettings, cfg *Config) *ibmmqReceiver {
+	receiversLock.Lock()
+	defer receiversLock.Unlock()
+	r, ok := receivers[cfg]
+	if !ok {
+		r = newReceiver(settings, cfg)
+		receivers[cfg] = r
+	}
+	return r
+}
+
+func removeReceiver(cfg *Config) {
+	receiversLock.Lock()
+	defer receiversLock.Unlock()
+	delete(receivers, cfg)
+}
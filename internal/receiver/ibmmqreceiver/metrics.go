@@ -0,0 +1,240 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"fmt"
+
+	"github.com/signalfx/golib/v3/event"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+const (
+	scopeName = "github.com/signalfx/splunk-otel-collector/internal/receiver/ibmmqreceiver"
+
+	queueManagerAttr          = "ibmmq.queue_manager.name"
+	queueNameAttr             = "ibmmq.queue.name"
+	channelNameAttr           = "ibmmq.channel.name"
+	channelTypeAttr           = "ibmmq.channel.type"
+	channelConnectionNameAttr = "ibmmq.channel.connection_name"
+	listenerNameAttr          = "ibmmq.listener.name"
+	listenerPortAttr          = "ibmmq.listener.port"
+
+	queueDepthMetric            = "ibmmq.queue.depth"
+	queueMaxDepthMetric         = "ibmmq.queue.max_depth"
+	queueOpenInputCountMetric   = "ibmmq.queue.open_input_count"
+	queueOpenOutputCountMetric  = "ibmmq.queue.open_output_count"
+	queueOldestMessageAgeMetric = "ibmmq.queue.oldest_message_age"
+	channelStatusMetric         = "ibmmq.channel.status"
+	channelMessagesMetric       = "ibmmq.channel.messages"
+	channelBytesSentMetric      = "ibmmq.channel.bytes_sent"
+	channelBytesReceivedMetric  = "ibmmq.channel.bytes_received"
+	listenerStatusMetric        = "ibmmq.listener.status"
+
+	channelStoppedEventType = "ibmmq.channel.stopped"
+)
+
+// channelStatuses are the PCF MQCHS values of the channel statuses reported by chstatus.
+var channelStatuses = map[string]int64{
+	"INACTIVE":     0,
+	"BINDING":      1,
+	"STARTING":     2,
+	"RUNNING":      3,
+	"STOPPING":     4,
+	"RETRYING":     5,
+	"STOPPED":      6,
+	"REQUESTING":   7,
+	"PAUSED":       8,
+	"DISCONNECTED": 9,
+	"INITIALIZING": 13,
+	"SWITCHING":    14,
+}
+
+// channelStatus is the status of a channel instance, identified by its name and connection name.
+type channelStatus struct {
+	name           string
+	channelType    string
+	connectionName string
+	status         string
+	messages       float64
+	bytesSent      float64
+	bytesReceived  float64
+	hasMessages    bool
+	hasBytes       bool
+}
+
+// channelKey identifies a channel instance across collections.
+type channelKey struct {
+	name           string
+	connectionName string
+}
+
+// queueStatus is the status of a local queue.
+type queueStatus struct {
+	name          string
+	depth         float64
+	maxDepth      float64
+	openInput     float64
+	openOutput    float64
+	oldestMessage float64
+	hasMaxDepth   bool
+	hasMessageAge bool
+}
+
+// listenerStatus is whether a defined listener is running.
+type listenerStatus struct {
+	name    string
+	port    int64
+	running bool
+}
+
+// metricsBuilder adds the metrics of a collection to the resource of the queue manager.
+type metricsBuilder struct {
+	sm      pmetric.ScopeMetrics
+	metrics map[string]pmetric.Metric
+	md      pmetric.Metrics
+	ts      pcommon.Timestamp
+}
+
+func newMetricsBuilder(queueManager string, ts pcommon.Timestamp) *metricsBuilder {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString(queueManagerAttr, queueManager)
+	sm := rm.ScopeMetrics().AppendEmpty()
+	sm.Scope().SetName(scopeName)
+	return &metricsBuilder{md: md, sm: sm, metrics: map[string]pmetric.Metric{}, ts: ts}
+}
+
+func (mb *metricsBuilder) dataPoint(name, unit, description string, sum bool) pmetric.NumberDataPoint {
+	m, ok := mb.metrics[name]
+	if !ok {
+		m = mb.sm.Metrics().AppendEmpty()
+		m.SetName(name)
+		m.SetUnit(unit)
+		m.SetDescription(description)
+		if sum {
+			m.SetDataType(pmetric.MetricDataTypeSum)
+			m.Sum().SetIsMonotonic(true)
+			m.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
+		} else {
+			m.SetDataType(pmetric.MetricDataTypeGauge)
+		}
+		mb.metrics[name] = m
+	}
+	var dp pmetric.NumberDataPoint
+	if sum {
+		dp = m.Sum().DataPoints().AppendEmpty()
+	} else {
+		dp = m.Gauge().DataPoints().AppendEmpty()
+	}
+	dp.SetTimestamp(mb.ts)
+	return dp
+}
+
+func (mb *metricsBuilder) addQueue(q queueStatus) {
+	newDataPoint := func(name, unit, description string) pmetric.NumberDataPoint {
+		dp := mb.dataPoint(name, unit, description, false)
+		dp.Attributes().InsertString(queueNameAttr, q.name)
+		return dp
+	}
+	newDataPoint(queueDepthMetric, "{message}", "The number of messages on the queue.").SetIntVal(int64(q.depth))
+	if q.hasMaxDepth {
+		newDataPoint(queueMaxDepthMetric, "{message}", "The maximum number of messages allowed on the queue.").SetIntVal(int64(q.maxDepth))
+	}
+	newDataPoint(queueOpenInputCountMetric, "{handle}", "The number of handles open for getting messages from the queue.").SetIntVal(int64(q.openInput))
+	newDataPoint(queueOpenOutputCountMetric, "{handle}", "The number of handles open for putting messages on the queue.").SetIntVal(int64(q.openOutput))
+	if q.hasMessageAge {
+		newDataPoint(queueOldestMessageAgeMetric, "s", "The age of the oldest message on the queue, with real-time queue monitoring enabled.").SetIntVal(int64(q.oldestMessage))
+	}
+}
+
+func (mb *metricsBuilder) addChannel(c channelStatus) {
+	newDataPoint := func(name, unit, description string, sum bool) pmetric.NumberDataPoint {
+		dp := mb.dataPoint(name, unit, description, sum)
+		insertChannelAttributes(dp.Attributes(), c)
+		return dp
+	}
+	newDataPoint(channelStatusMetric, "{status}",
+		"The channel status, as the PCF MQCHS value: 3 for RUNNING, 5 for RETRYING, and 6 for STOPPED, among others.",
+		false).SetIntVal(channelStatuses[c.status])
+	if c.hasMessages {
+		newDataPoint(channelMessagesMetric, "{message}", "The number of messages sent or received since the channel started.", true).SetIntVal(int64(c.messages))
+	}
+	if c.hasBytes {
+		newDataPoint(channelBytesSentMetric, "By", "The number of bytes sent since the channel started.", true).SetIntVal(int64(c.bytesSent))
+		newDataPoint(channelBytesReceivedMetric, "By", "The number of bytes received since the channel started.", true).SetIntVal(int64(c.bytesReceived))
+	}
+}
+
+func (mb *metricsBuilder) addListener(l listenerStatus) {
+	dp := mb.dataPoint(listenerStatusMetric, "{status}", "Whether the listener is running: 1 if it is, 0 otherwise.", false)
+	dp.Attributes().InsertString(listenerNameAttr, l.name)
+	if l.port != 0 {
+		dp.Attributes().InsertInt(listenerPortAttr, l.port)
+	}
+	if l.running {
+		dp.SetIntVal(1)
+	} else {
+		dp.SetIntVal(0)
+	}
+}
+
+// newLogs returns the logs for the events of the queue manager.
+func newLogs(queueManager string) (plog.Logs, plog.LogRecordSlice) {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString(queueManagerAttr, queueManager)
+	sl := rl.ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(scopeName)
+	return ld, sl.LogRecords()
+}
+
+// addChannelStopped adds a SignalFx alert event log record for the channel instance that stopped.
+func addChannelStopped(records plog.LogRecordSlice, ts pcommon.Timestamp, c channelStatus, previous string) {
+	lr := records.AppendEmpty()
+	lr.SetTimestamp(ts)
+	lr.SetSeverityNumber(plog.SeverityNumberWARN)
+	lr.SetSeverityText("WARN")
+	if c.connectionName != "" {
+		lr.Body().SetStringVal(fmt.Sprintf("Channel %s to %s stopped", c.name, c.connectionName))
+	} else {
+		lr.Body().SetStringVal(fmt.Sprintf("Channel %s stopped", c.name))
+	}
+
+	attrs := lr.Attributes()
+	insertChannelAttributes(attrs, c)
+	attrs.InsertInt(converter.SFxEventCategoryKey, int64(event.ALERT))
+	attrs.InsertString(converter.SFxEventType, channelStoppedEventType)
+
+	propMapVal := pcommon.NewValueMap()
+	props := propMapVal.MapVal()
+	props.InsertString("status", c.status)
+	props.InsertString("previous_status", previous)
+	attrs.Insert(converter.SFxEventPropertiesKey, propMapVal)
+}
+
+func insertChannelAttributes(attrs pcommon.Map, c channelStatus) {
+	attrs.InsertString(channelNameAttr, c.name)
+	if c.channelType != "" {
+		attrs.InsertString(channelTypeAttr, c.channelType)
+	}
+	if c.connectionName != "" {
+		attrs.InsertString(channelConnectionNameAttr, c.connectionName)
+	}
+}
@@ -0,0 +1,148 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// csrfTokenHeader must be set, to any value, on the POST requests of the REST API.
+const csrfTokenHeader = "ibm-mq-rest-csrf-token"
+
+// mqscClient runs MQSC DISPLAY commands, the equivalents of the PCF inquiries, with the runCommandJSON
+// action of the mqweb administrative REST API, whose responses are the PCF parameters of each object.
+type mqscClient struct {
+	client   *http.Client
+	url      string
+	username string
+	password string
+}
+
+func newMQSCClient(client *http.Client, cfg *Config) *mqscClient {
+	return &mqscClient{
+		client: client,
+		url: fmt.Sprintf("%s/ibmmq/rest/v2/admin/action/qmgr/%s/mqsc",
+			strings.TrimSuffix(cfg.Endpoint, "/"), url.PathEscape(cfg.QueueManager)),
+		username: cfg.Username,
+		password: cfg.Password,
+	}
+}
+
+type mqscCommand struct {
+	Type               string         `json:"type"`
+	Command            string         `json:"command"`
+	Qualifier          string         `json:"qualifier"`
+	Name               string         `json:"name"`
+	Parameters         map[string]any `json:"parameters,omitempty"`
+	ResponseParameters []string       `json:"responseParameters"`
+}
+
+type mqscResponse struct {
+	CommandResponse []struct {
+		Parameters     map[string]any `json:"parameters"`
+		Message        []string       `json:"message"`
+		CompletionCode int            `json:"completionCode"`
+		ReasonCode     int            `json:"reasonCode"`
+	} `json:"commandResponse"`
+	Error []struct {
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// display returns the parameters of the objects of the qualifier, like qstatus or chstatus, matching the
+// name. Objects that don't exist or have no status, like inactive channels, aren't returned.
+func (c *mqscClient) display(
+	ctx context.Context, qualifier, name string, parameters map[string]any, responseParameters []string,
+) ([]map[string]any, error) {
+	body, err := json.Marshal(mqscCommand{
+		Type:               "runCommandJSON",
+		Command:            "display",
+		Qualifier:          qualifier,
+		Name:               name,
+		Parameters:         parameters,
+		ResponseParameters: responseParameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(csrfTokenHeader, "otel")
+	if c.username != "" {
+		req.SetBasicAuth(c.username, c.password)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var response mqscResponse
+	if err = json.Unmarshal(respBody, &response); err != nil && resp.StatusCode == http.StatusOK {
+		return nil, fmt.Errorf("invalid display %s response: %w", qualifier, err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if len(response.Error) != 0 {
+			return nil, fmt.Errorf("display %s failed with status %d: %s", qualifier, resp.StatusCode, response.Error[0].Message)
+		}
+		return nil, fmt.Errorf("display %s failed with status %d", qualifier, resp.StatusCode)
+	}
+
+	var objects []map[string]any
+	for _, r := range response.CommandResponse {
+		// a command for objects without a status, like stopped listeners, fails with a not found reason
+		if r.CompletionCode == 0 && r.Parameters != nil {
+			objects = append(objects, r.Parameters)
+		}
+	}
+	return objects, nil
+}
+
+// stringParam returns the string value of the PCF parameter.
+func stringParam(parameters map[string]any, key string) string {
+	if s, ok := parameters[key].(string); ok {
+		return strings.TrimSpace(s)
+	}
+	return ""
+}
+
+// numberParam returns the numeric value of the PCF parameter, false if it has none, like the msgage of
+// queues without real-time monitoring.
+func numberParam(parameters map[string]any, key string) (float64, bool) {
+	switch v := parameters[key].(type) {
+	case float64:
+		return v, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+		return f, err == nil
+	}
+	return 0, false
+}
@@ -0,0 +1,318 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+)
+
+const (
+	httpTransport = "http"
+
+	systemObjectPrefix = "SYSTEM."
+	channelStopped     = "STOPPED"
+	channelInactive    = "INACTIVE"
+)
+
+// ibmmqReceiver collects the status of the queues, channels, and listeners of a queue manager every
+// collection interval, providing their metrics and the events of stopped channels to the next metrics
+// and logs consumers respectively. It's shared by the metrics and logs receivers of the same config so
+// the queue manager is only queried once.
+type ibmmqReceiver struct {
+	nextMetrics consumer.Metrics
+	nextLogs    consumer.Logs
+	obsrecv     *obsreport.Receiver
+	config      *Config
+	client      *mqscClient
+	cancel      context.CancelFunc
+	now         func() time.Time
+	// channels are the statuses of the channel instances of the last collection, nil before the first
+	channels     map[channelKey]string
+	settings     component.ReceiverCreateSettings
+	shutdownWG   sync.WaitGroup
+	startOnce    sync.Once
+	shutdownOnce sync.Once
+}
+
+var _ component.MetricsReceiver = (*ibmmqReceiver)(nil)
+var _ component.LogsReceiver = (*ibmmqReceiver)(nil)
+
+func newReceiver(settings component.ReceiverCreateSettings, config *Config) *ibmmqReceiver {
+	return &ibmmqReceiver{
+		config:   config,
+		settings: settings,
+		now:      time.Now,
+		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+			ReceiverID:             config.ID(),
+			Transport:              httpTransport,
+			ReceiverCreateSettings: settings,
+		}),
+	}
+}
+
+func (r *ibmmqReceiver) Start(_ context.Context, host component.Host) error {
+	var err error
+	r.startOnce.Do(func() {
+		err = r.start(host)
+	})
+	return err
+}
+
+func (r *ibmmqReceiver) start(host component.Host) error {
+	httpClient, err := r.config.HTTPClientSettings.ToClient(host.GetExtensions(), r.settings.TelemetrySettings)
+	if err != nil {
+		return fmt.Errorf("failed creating the mqweb client: %w", err)
+	}
+	r.client = newMQSCClient(httpClient, r.config)
+
+	var ctx context.Context
+	ctx, r.cancel = context.WithCancel(context.Background())
+	r.shutdownWG.Add(1)
+	go func() {
+		defer r.shutdownWG.Done()
+		r.run(ctx)
+	}()
+	return nil
+}
+
+func (r *ibmmqReceiver) Shutdown(context.Context) error {
+	r.shutdownOnce.Do(func() {
+		removeReceiver(r.config)
+		if r.cancel != nil {
+			r.cancel()
+		}
+		r.shutdownWG.Wait()
+	})
+	return nil
+}
+
+// run collects the statuses every collection interval until the context is done.
+func (r *ibmmqReceiver) run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+	for {
+		if err := r.collect(ctx); err != nil && ctx.Err() == nil {
+			r.settings.Logger.Error("failed collecting queue manager statuses",
+				zap.String("queue_manager", r.config.QueueManager), zap.Error(err))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// collect provides the metrics of the statuses of the queues, channels, and listeners, and the events of
+// the channels that stopped since the last collection, to the next consumers.
+func (r *ibmmqReceiver) collect(ctx context.Context) error {
+	ts := pcommon.NewTimestampFromTime(r.now())
+	mb := newMetricsBuilder(r.config.QueueManager, ts)
+	ld, records := newLogs(r.config.QueueManager)
+
+	var errs error
+	queues, err := r.queues(ctx)
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("failed collecting queue statuses: %w", err))
+	}
+	for _, q := range queues {
+		mb.addQueue(q)
+	}
+
+	channels, err := r.channelStatuses(ctx)
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("failed collecting channel statuses: %w", err))
+	} else {
+		r.trackChannels(channels, ts, records)
+	}
+	for _, c := range channels {
+		mb.addChannel(c)
+	}
+
+	listeners, err := r.listeners(ctx)
+	if err != nil {
+		errs = multierr.Append(errs, fmt.Errorf("failed collecting listener statuses: %w", err))
+	}
+	for _, l := range listeners {
+		mb.addListener(l)
+	}
+
+	if r.nextMetrics != nil && mb.md.DataPointCount() > 0 {
+		obsCtx := r.obsrecv.StartMetricsOp(ctx)
+		err = r.nextMetrics.ConsumeMetrics(obsCtx, mb.md)
+		r.obsrecv.EndMetricsOp(obsCtx, typeStr, mb.md.DataPointCount(), err)
+		errs = multierr.Append(errs, err)
+	}
+	if r.nextLogs != nil && records.Len() > 0 {
+		obsCtx := r.obsrecv.StartLogsOp(ctx)
+		err = r.nextLogs.ConsumeLogs(obsCtx, ld)
+		r.obsrecv.EndLogsOp(obsCtx, typeStr, records.Len(), err)
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
+
+// trackChannels adds the events of the channel instances that stopped since the last collection, whose
+// previous status is INACTIVE if they had none. There are none for the first collection.
+func (r *ibmmqReceiver) trackChannels(channels []channelStatus, ts pcommon.Timestamp, records plog.LogRecordSlice) {
+	statuses := make(map[channelKey]string, len(channels))
+	for _, c := range channels {
+		key := channelKey{name: c.name, connectionName: c.connectionName}
+		statuses[key] = c.status
+		if r.channels == nil || c.status != channelStopped {
+			continue
+		}
+		previous, ok := r.channels[key]
+		if !ok {
+			previous = channelInactive
+		}
+		if previous != channelStopped {
+			addChannelStopped(records, ts, c, previous)
+		}
+	}
+	r.channels = statuses
+}
+
+// queues returns the statuses of the local queues matching the queues names, sorted by name.
+func (r *ibmmqReceiver) queues(ctx context.Context) ([]queueStatus, error) {
+	byName := map[string]*queueStatus{}
+	for _, name := range r.config.Queues {
+		statuses, err := r.client.display(ctx, "qstatus", name,
+			map[string]any{"type": "queue"}, []string{"curdepth", "ipprocs", "opprocs", "msgage"})
+		if err != nil {
+			return nil, err
+		}
+		for _, params := range statuses {
+			queue := stringParam(params, "queue")
+			if !r.included(queue) {
+				continue
+			}
+			q := &queueStatus{name: queue}
+			q.depth, _ = numberParam(params, "curdepth")
+			q.openInput, _ = numberParam(params, "ipprocs")
+			q.openOutput, _ = numberParam(params, "opprocs")
+			q.oldestMessage, q.hasMessageAge = numberParam(params, "msgage")
+			byName[queue] = q
+		}
+
+		definitions, err := r.client.display(ctx, "qlocal", name, nil, []string{"maxdepth"})
+		if err != nil {
+			return nil, err
+		}
+		for _, params := range definitions {
+			if q, ok := byName[stringParam(params, "queue")]; ok {
+				q.maxDepth, q.hasMaxDepth = numberParam(params, "maxdepth")
+			}
+		}
+	}
+
+	queues := make([]queueStatus, 0, len(byName))
+	for _, q := range byName {
+		queues = append(queues, *q)
+	}
+	sort.Slice(queues, func(i, j int) bool { return queues[i].name < queues[j].name })
+	return queues, nil
+}
+
+// channelStatuses returns the statuses of the channel instances matching the channels names, sorted by
+// name and connection name. Inactive channels have none.
+func (r *ibmmqReceiver) channelStatuses(ctx context.Context) ([]channelStatus, error) {
+	byKey := map[channelKey]channelStatus{}
+	for _, name := range r.config.Channels {
+		statuses, err := r.client.display(ctx, "chstatus", name, nil,
+			[]string{"status", "chltype", "conname", "msgs", "bytssent", "bytsrcvd"})
+		if err != nil {
+			return nil, err
+		}
+		for _, params := range statuses {
+			c := channelStatus{
+				name:           stringParam(params, "channel"),
+				channelType:    stringParam(params, "chltype"),
+				connectionName: stringParam(params, "conname"),
+				status:         strings.ToUpper(stringParam(params, "status")),
+			}
+			if !r.included(c.name) {
+				continue
+			}
+			c.messages, c.hasMessages = numberParam(params, "msgs")
+			var hasSent, hasReceived bool
+			c.bytesSent, hasSent = numberParam(params, "bytssent")
+			c.bytesReceived, hasReceived = numberParam(params, "bytsrcvd")
+			c.hasBytes = hasSent && hasReceived
+			byKey[channelKey{name: c.name, connectionName: c.connectionName}] = c
+		}
+	}
+
+	channels := make([]channelStatus, 0, len(byKey))
+	for _, c := range byKey {
+		channels = append(channels, c)
+	}
+	sort.Slice(channels, func(i, j int) bool {
+		if channels[i].name != channels[j].name {
+			return channels[i].name < channels[j].name
+		}
+		return channels[i].connectionName < channels[j].connectionName
+	})
+	return channels, nil
+}
+
+// listeners returns whether each defined listener is running, sorted by name.
+func (r *ibmmqReceiver) listeners(ctx context.Context) ([]listenerStatus, error) {
+	definitions, err := r.client.display(ctx, "listener", "*", nil, []string{"port"})
+	if err != nil {
+		return nil, err
+	}
+	// stopped listeners have no status
+	statuses, err := r.client.display(ctx, "lsstatus", "*", nil, []string{"status"})
+	if err != nil {
+		return nil, err
+	}
+	running := map[string]bool{}
+	for _, params := range statuses {
+		if strings.EqualFold(stringParam(params, "status"), "RUNNING") {
+			running[stringParam(params, "listener")] = true
+		}
+	}
+
+	var listeners []listenerStatus
+	for _, params := range definitions {
+		name := stringParam(params, "listener")
+		if !r.included(name) {
+			continue
+		}
+		port, _ := numberParam(params, "port")
+		listeners = append(listeners, listenerStatus{name: name, port: int64(port), running: running[name]})
+	}
+	sort.Slice(listeners, func(i, j int) bool { return listeners[i].name < listeners[j].name })
+	return listeners, nil
+}
+
+// included determines whether the status of the object is collected.
+func (r *ibmmqReceiver) included(name string) bool {
+	return name != "" && (r.config.IncludeSystemObjects || !strings.HasPrefix(name, systemObjectPrefix))
+}
@@ -0,0 +1,277 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package ibmmqreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+// fakeMQWeb responds to the runCommandJSON display commands with the parameters of the objects of
+// their qualifier, or a not found response if there are none.
+type fakeMQWeb struct {
+	objects  map[string][]map[string]any
+	commands []mqscCommand
+	lock     sync.Mutex
+}
+
+func (f *fakeMQWeb) setObjects(qualifier string, objects ...map[string]any) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	f.objects[qualifier] = objects
+}
+
+func (f *fakeMQWeb) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != "/ibmmq/rest/v2/admin/action/qmgr/QM1/mqsc" {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error": [{"msgId": "MQWB0009E", "message": "MQWB0009E: Could not find the queue manager 'QM2'."}]}`))
+		return
+	}
+	if user, password, _ := req.BasicAuth(); user != "monitor" || password != "secret" || req.Header.Get(csrfTokenHeader) == "" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	var command mqscCommand
+	if err := json.NewDecoder(req.Body).Decode(&command); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	f.lock.Lock()
+	f.commands = append(f.commands, command)
+	objects := f.objects[command.Qualifier]
+	f.lock.Unlock()
+
+	var responses []map[string]any
+	for _, object := range objects {
+		responses = append(responses, map[string]any{"completionCode": 0, "reasonCode": 0, "parameters": object})
+	}
+	if len(responses) == 0 {
+		responses = append(responses, map[string]any{
+			"completionCode": 2, "reasonCode": 3065, "message": []string{"AMQ8420I: Channel Status not found."},
+		})
+	}
+	_ = json.NewEncoder(w).Encode(map[string]any{"commandResponse": responses})
+}
+
+func newTestReceiver(t *testing.T, mqweb *fakeMQWeb) (*ibmmqReceiver, *consumertest.MetricsSink, *consumertest.LogsSink) {
+	server := httptest.NewServer(mqweb)
+	t.Cleanup(server.Close)
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = server.URL
+	cfg.QueueManager = "QM1"
+	cfg.Username = "monitor"
+	cfg.Password = "secret"
+	metricsSink := new(consumertest.MetricsSink)
+	logsSink := new(consumertest.LogsSink)
+	r := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg)
+	r.nextMetrics = metricsSink
+	r.nextLogs = logsSink
+	r.client = newMQSCClient(server.Client(), cfg)
+	r.now = func() time.Time { return time.Unix(1000, 0) }
+	return r, metricsSink, logsSink
+}
+
+func newFakeMQWeb() *fakeMQWeb {
+	return &fakeMQWeb{objects: map[string][]map[string]any{
+		"qstatus": {
+			{"queue": "APP.ORDERS", "type": "QUEUE", "curdepth": 42, "ipprocs": 2, "opprocs": 1, "msgage": 30},
+			{"queue": "APP.EVENTS", "type": "QUEUE", "curdepth": 0, "ipprocs": 0, "opprocs": 0, "msgage": ""},
+			{"queue": "SYSTEM.ADMIN.COMMAND.QUEUE", "type": "QUEUE", "curdepth": 0, "ipprocs": 1, "opprocs": 0},
+		},
+		"qlocal": {
+			{"queue": "APP.ORDERS", "maxdepth": 5000},
+			{"queue": "APP.EVENTS", "maxdepth": 5000},
+			{"queue": "SYSTEM.ADMIN.COMMAND.QUEUE", "maxdepth": 3000},
+		},
+		"chstatus": {
+			{"channel": "TO.QM2", "chltype": "SDR", "conname": "qm2.example.com(1414)", "status": "RUNNING", "msgs": 100, "bytssent": 2048, "bytsrcvd": 512},
+			{"channel": "APP.SVRCONN", "chltype": "SVRCONN", "conname": "10.0.0.1", "status": "RUNNING", "msgs": 5, "bytssent": 64, "bytsrcvd": 128},
+		},
+		"listener": {
+			{"listener": "LISTENER.TCP", "port": 1414},
+			{"listener": "LISTENER.BACKUP", "port": 1415},
+			{"listener": "SYSTEM.DEFAULT.LISTENER.TCP", "port": 0},
+		},
+		"lsstatus": {
+			{"listener": "LISTENER.TCP", "status": "RUNNING"},
+		},
+	}}
+}
+
+type dataPoint struct {
+	attributes map[string]any
+	value      int64
+}
+
+func dataPointsByMetric(t *testing.T, md pmetric.Metrics) map[string][]dataPoint {
+	require.Equal(t, 1, md.ResourceMetrics().Len())
+	resource := md.ResourceMetrics().At(0).Resource().Attributes().AsRaw()
+	assert.Equal(t, map[string]any{"ibmmq.queue_manager.name": "QM1"}, resource)
+
+	points := map[string][]dataPoint{}
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		m := ms.At(i)
+		dps := pmetric.NewNumberDataPointSlice()
+		switch m.DataType() {
+		case pmetric.MetricDataTypeGauge:
+			dps = m.Gauge().DataPoints()
+		case pmetric.MetricDataTypeSum:
+			assert.True(t, m.Sum().IsMonotonic())
+			dps = m.Sum().DataPoints()
+		}
+		for j := 0; j < dps.Len(); j++ {
+			assert.Equal(t, pcommon.NewTimestampFromTime(time.Unix(1000, 0)), dps.At(j).Timestamp())
+			points[m.Name()] = append(points[m.Name()], dataPoint{attributes: dps.At(j).Attributes().AsRaw(), value: dps.At(j).IntVal()})
+		}
+	}
+	return points
+}
+
+func TestCollectMetrics(t *testing.T) {
+	mqweb := newFakeMQWeb()
+	r, metricsSink, logsSink := newTestReceiver(t, mqweb)
+
+	require.NoError(t, r.collect(context.Background()))
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	points := dataPointsByMetric(t, metricsSink.AllMetrics()[0])
+
+	events := map[string]any{"ibmmq.queue.name": "APP.EVENTS"}
+	orders := map[string]any{"ibmmq.queue.name": "APP.ORDERS"}
+	assert.Equal(t, []dataPoint{{events, 0}, {orders, 42}}, points[queueDepthMetric])
+	assert.Equal(t, []dataPoint{{events, 5000}, {orders, 5000}}, points[queueMaxDepthMetric])
+	assert.Equal(t, []dataPoint{{events, 0}, {orders, 2}}, points[queueOpenInputCountMetric])
+	assert.Equal(t, []dataPoint{{events, 0}, {orders, 1}}, points[queueOpenOutputCountMetric])
+	// without real-time monitoring, the age of the oldest message is unknown
+	assert.Equal(t, []dataPoint{{orders, 30}}, points[queueOldestMessageAgeMetric])
+
+	svrconn := map[string]any{
+		"ibmmq.channel.name": "APP.SVRCONN", "ibmmq.channel.type": "SVRCONN", "ibmmq.channel.connection_name": "10.0.0.1",
+	}
+	sender := map[string]any{
+		"ibmmq.channel.name": "TO.QM2", "ibmmq.channel.type": "SDR", "ibmmq.channel.connection_name": "qm2.example.com(1414)",
+	}
+	assert.Equal(t, []dataPoint{{svrconn, 3}, {sender, 3}}, points[channelStatusMetric])
+	assert.Equal(t, []dataPoint{{svrconn, 5}, {sender, 100}}, points[channelMessagesMetric])
+	assert.Equal(t, []dataPoint{{svrconn, 64}, {sender, 2048}}, points[channelBytesSentMetric])
+	assert.Equal(t, []dataPoint{{svrconn, 128}, {sender, 512}}, points[channelBytesReceivedMetric])
+
+	assert.Equal(t, []dataPoint{
+		{map[string]any{"ibmmq.listener.name": "LISTENER.BACKUP", "ibmmq.listener.port": int64(1415)}, 0},
+		{map[string]any{"ibmmq.listener.name": "LISTENER.TCP", "ibmmq.listener.port": int64(1414)}, 1},
+	}, points[listenerStatusMetric])
+
+	assert.Empty(t, logsSink.AllLogs())
+
+	mqweb.lock.Lock()
+	assert.Contains(t, mqweb.commands, mqscCommand{
+		Type: "runCommandJSON", Command: "display", Qualifier: "qstatus", Name: "*",
+		Parameters:         map[string]any{"type": "queue"},
+		ResponseParameters: []string{"curdepth", "ipprocs", "opprocs", "msgage"},
+	})
+	mqweb.lock.Unlock()
+}
+
+func TestCollectSystemObjects(t *testing.T) {
+	mqweb := newFakeMQWeb()
+	r, metricsSink, _ := newTestReceiver(t, mqweb)
+	r.config.IncludeSystemObjects = true
+
+	require.NoError(t, r.collect(context.Background()))
+	points := dataPointsByMetric(t, metricsSink.AllMetrics()[0])
+	assert.Contains(t, points[queueMaxDepthMetric], dataPoint{map[string]any{"ibmmq.queue.name": "SYSTEM.ADMIN.COMMAND.QUEUE"}, 3000})
+	assert.Contains(t, points[listenerStatusMetric], dataPoint{map[string]any{"ibmmq.listener.name": "SYSTEM.DEFAULT.LISTENER.TCP"}, 0})
+}
+
+func TestChannelStoppedEvents(t *testing.T) {
+	mqweb := newFakeMQWeb()
+	r, _, logsSink := newTestReceiver(t, mqweb)
+
+	// channels already stopped at the first collection aren't reported
+	mqweb.setObjects("chstatus",
+		map[string]any{"channel": "TO.QM2", "chltype": "SDR", "conname": "qm2.example.com(1414)", "status": "RUNNING"},
+		map[string]any{"channel": "TO.QM3", "chltype": "SDR", "conname": "qm3.example.com(1414)", "status": "STOPPED"},
+	)
+	require.NoError(t, r.collect(context.Background()))
+	assert.Empty(t, logsSink.AllLogs())
+
+	mqweb.setObjects("chstatus",
+		map[string]any{"channel": "TO.QM2", "chltype": "SDR", "conname": "qm2.example.com(1414)", "status": "STOPPED"},
+		map[string]any{"channel": "TO.QM3", "chltype": "SDR", "conname": "qm3.example.com(1414)", "status": "STOPPED"},
+		map[string]any{"channel": "TO.QM4", "chltype": "SDR", "conname": "qm4.example.com(1414)", "status": "STOPPED"},
+	)
+	require.NoError(t, r.collect(context.Background()))
+	require.Len(t, logsSink.AllLogs(), 1)
+	ld := logsSink.AllLogs()[0]
+	assert.Equal(t, map[string]any{"ibmmq.queue_manager.name": "QM1"}, ld.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	records := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+	require.Equal(t, 2, records.Len())
+
+	stopped := records.At(0)
+	assert.Equal(t, "Channel TO.QM2 to qm2.example.com(1414) stopped", stopped.Body().StringVal())
+	assert.Equal(t, plog.SeverityNumberWARN, stopped.SeverityNumber())
+	assert.Equal(t, map[string]any{
+		"ibmmq.channel.name":            "TO.QM2",
+		"ibmmq.channel.type":            "SDR",
+		"ibmmq.channel.connection_name": "qm2.example.com(1414)",
+		converter.SFxEventCategoryKey:   int64(event.ALERT),
+		converter.SFxEventType:          channelStoppedEventType,
+		converter.SFxEventPropertiesKey: map[string]any{"status": "STOPPED", "previous_status": "RUNNING"},
+	}, stopped.Attributes().AsRaw())
+	// channels without a status were inactive
+	props, _ := records.At(1).Attributes().Get(converter.SFxEventPropertiesKey)
+	assert.Equal(t, map[string]any{"status": "STOPPED", "previous_status": "INACTIVE"}, props.MapVal().AsRaw())
+
+	// channels still stopped aren't reported again
+	require.NoError(t, r.collect(context.Background()))
+	assert.Len(t, logsSink.AllLogs(), 1)
+}
+
+func TestCollectErrors(t *testing.T) {
+	mqweb := newFakeMQWeb()
+	r, metricsSink, _ := newTestReceiver(t, mqweb)
+	r.client.url = r.client.url[:len(r.client.url)-len("/QM1/mqsc")] + "/QM2/mqsc"
+
+	err := r.collect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed collecting queue statuses: display qstatus failed with status 404: MQWB0009E: Could not find the queue manager 'QM2'.")
+	assert.Contains(t, err.Error(), "failed collecting channel statuses")
+	assert.Contains(t, err.Error(), "failed collecting listener statuses")
+	assert.Empty(t, metricsSink.AllMetrics())
+
+	r.client.password = "wrong"
+	r.client.url = newMQSCClient(nil, r.config).url
+	err = r.collect(context.Background())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "display qstatus failed with status 401")
+}
@@ -0,0 +1,54 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbatlasalertsreceiver
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
+)
+
+var _ config.Receiver = (*Config)(nil)
+
+// Config defines configuration for the MongoDB Atlas alerts receiver.
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+	// TLS is the optional server TLS configuration of the alert webhook.
+	TLS *configtls.TLSServerSetting `mapstructure:"tls"`
+	// Endpoint is the host:port the Atlas alert webhook is served on.
+	Endpoint string `mapstructure:"endpoint"`
+	// Secret is the shared secret Atlas signs each alert request with.
+	Secret string `mapstructure:"secret"`
+	// EventTypeAttribute is the alert attribute whose value is used as the event type.
+	EventTypeAttribute string `mapstructure:"event_type_attribute"`
+	// DefaultEventType is the event type of alerts without the EventTypeAttribute.
+	DefaultEventType string `mapstructure:"default_event_type"`
+	// Properties are the alert attributes provided as event properties instead of dimensions.
+	Properties []string `mapstructure:"properties"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	if cfg.Secret == "" {
+		return errors.New("secret must not be empty")
+	}
+	if cfg.EventTypeAttribute == "" && cfg.DefaultEventType == "" {
+		return errors.New("one of event_type_attribute or default_event_type must be set")
+	}
+	return nil
+}
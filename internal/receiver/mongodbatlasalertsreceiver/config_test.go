@@ -0,0 +1,104 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbatlasalertsreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, len(cfg.Receivers), 3)
+
+	defaultCfg := cfg.Receivers[config.NewComponentID(typeStr)].(*Config)
+	expectedDefaultCfg := factory.CreateDefaultConfig().(*Config)
+	expectedDefaultCfg.Secret = "some_secret"
+	assert.Equal(t, expectedDefaultCfg, defaultCfg)
+	require.NoError(t, defaultCfg.Validate())
+
+	allSettings := cfg.Receivers[config.NewComponentIDWithName(typeStr, "allsettings")].(*Config)
+	assert.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "allsettings")),
+		TLS: &configtls.TLSServerSetting{
+			TLSSetting: configtls.TLSSetting{
+				CertFile: "/some/cert.pem",
+				KeyFile:  "/some/key.pem",
+			},
+		},
+		Endpoint:         "localhost:7707",
+		Secret:           "some_secret",
+		DefaultEventType: "atlas_alert",
+		Properties:       []string{"message", "status", "id"},
+	}, allSettings)
+	require.NoError(t, allSettings.Validate())
+
+	invalid := cfg.Receivers[config.NewComponentIDWithName(typeStr, "invalid")]
+	assert.EqualError(t, invalid.Validate(), "secret must not be empty")
+}
+
+func TestValidateConfig(t *testing.T) {
+	for _, tt := range []struct {
+		name        string
+		modify      func(*Config)
+		expectedErr string
+	}{
+		{name: "valid", modify: func(*Config) {}},
+		{
+			name:        "missing endpoint",
+			modify:      func(cfg *Config) { cfg.Endpoint = "" },
+			expectedErr: "endpoint must not be empty",
+		},
+		{
+			name:        "missing secret",
+			modify:      func(cfg *Config) { cfg.Secret = "" },
+			expectedErr: "secret must not be empty",
+		},
+		{
+			name: "missing event type",
+			modify: func(cfg *Config) {
+				cfg.EventTypeAttribute = ""
+				cfg.DefaultEventType = ""
+			},
+			expectedErr: "one of event_type_attribute or default_event_type must be set",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := createDefaultConfig().(*Config)
+			cfg.Secret = "some_secret"
+			tt.modify(cfg)
+			err := cfg.Validate()
+			if tt.expectedErr == "" {
+				require.NoError(t, err)
+				return
+			}
+			require.EqualError(t, err, tt.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbatlasalertsreceiver
+
+import (
+	"context"
+
+	"github.com/signalfx/golib/v3/event"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+var _ consumer.Logs = (*eventConsumer)(nil)
+
+// eventConsumer translates the mongodbatlas receiver's alert log records into
+// SignalFx events, as would be created by the smartagent receiver, before
+// providing them to the next consumer.
+type eventConsumer struct {
+	next               consumer.Logs
+	eventTypeAttribute string
+	defaultEventType   string
+	properties         []string
+}
+
+func newEventConsumer(cfg *Config, next consumer.Logs) *eventConsumer {
+	return &eventConsumer{
+		next:               next,
+		eventTypeAttribute: cfg.EventTypeAttribute,
+		defaultEventType:   cfg.DefaultEventType,
+		properties:         cfg.Properties,
+	}
+}
+
+func (ec *eventConsumer) Capabilities() consumer.Capabilities {
+	return consumer.Capabilities{MutatesData: true}
+}
+
+func (ec *eventConsumer) ConsumeLogs(ctx context.Context, ld plog.Logs) error {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				ec.toEvent(lrs.At(k).Attributes())
+			}
+		}
+	}
+	return ec.next.ConsumeLogs(ctx, ld)
+}
+
+// toEvent sets the SignalFx event attributes of an alert log record.  The event type
+// and property attributes are moved so they aren't also exported as dimensions.
+func (ec *eventConsumer) toEvent(attrs pcommon.Map) {
+	eventType := ec.defaultEventType
+	if ec.eventTypeAttribute != "" {
+		if v, ok := attrs.Get(ec.eventTypeAttribute); ok {
+			if s := v.AsString(); s != "" {
+				eventType = s
+			}
+			attrs.Remove(ec.eventTypeAttribute)
+		}
+	}
+
+	propMapVal := pcommon.NewValueMap()
+	props := propMapVal.MapVal()
+	for _, property := range ec.properties {
+		if v, ok := attrs.Get(property); ok {
+			props.Upsert(property, v)
+			attrs.Remove(property)
+		}
+	}
+
+	attrs.UpsertInt(converter.SFxEventCategoryKey, int64(event.ALERT))
+	attrs.UpsertString(converter.SFxEventType, eventType)
+	if props.Len() > 0 {
+		attrs.Upsert(converter.SFxEventPropertiesKey, propMapVal)
+	}
+}
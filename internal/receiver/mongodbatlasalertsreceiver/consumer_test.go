@@ -0,0 +1,102 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbatlasalertsreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+func newAlertLogs(attrs map[string]string) plog.Logs {
+	ld := plog.NewLogs()
+	rl := ld.ResourceLogs().AppendEmpty()
+	rl.Resource().Attributes().InsertString("mongodbatlas.group.id", "some_group")
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.Body().SetStringVal(`{"id": "some_alert"}`)
+	for k, v := range attrs {
+		lr.Attributes().InsertString(k, v)
+	}
+	return ld
+}
+
+func consumeAlert(t *testing.T, cfg *Config, attrs map[string]string) pcommon.Map {
+	sink := new(consumertest.LogsSink)
+	ec := newEventConsumer(cfg, sink)
+	assert.True(t, ec.Capabilities().MutatesData)
+	require.NoError(t, ec.ConsumeLogs(context.Background(), newAlertLogs(attrs)))
+
+	received := sink.AllLogs()
+	require.Len(t, received, 1)
+	rl := received[0].ResourceLogs().At(0)
+	v, ok := rl.Resource().Attributes().Get("mongodbatlas.group.id")
+	require.True(t, ok)
+	assert.Equal(t, "some_group", v.StringVal())
+	return rl.ScopeLogs().At(0).LogRecords().At(0).Attributes()
+}
+
+func TestAlertTranslatedToEvent(t *testing.T) {
+	attrs := consumeAlert(t, createDefaultConfig().(*Config), map[string]string{
+		"event.domain": "mongodbatlas",
+		"type":         "OUTSIDE_METRIC_THRESHOLD",
+		"message":      "Connections went above 100",
+		"status":       "OPEN",
+	})
+
+	assert.Equal(t, map[string]any{
+		"event.domain":                  "mongodbatlas",
+		converter.SFxEventCategoryKey:   int64(event.ALERT),
+		converter.SFxEventType:          "OUTSIDE_METRIC_THRESHOLD",
+		converter.SFxEventPropertiesKey: map[string]any{"message": "Connections went above 100", "status": "OPEN"},
+	}, attrs.AsRaw())
+}
+
+func TestAlertWithoutEventTypeAttribute(t *testing.T) {
+	attrs := consumeAlert(t, createDefaultConfig().(*Config), map[string]string{
+		"event.domain": "mongodbatlas",
+	})
+
+	assert.Equal(t, map[string]any{
+		"event.domain":                "mongodbatlas",
+		converter.SFxEventCategoryKey: int64(event.ALERT),
+		converter.SFxEventType:        "mongodbatlas.alert",
+	}, attrs.AsRaw())
+}
+
+func TestAlertWithDefaultEventTypeOnly(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.EventTypeAttribute = ""
+	cfg.DefaultEventType = "atlas_alert"
+	cfg.Properties = nil
+	attrs := consumeAlert(t, cfg, map[string]string{
+		"type":    "OUTSIDE_METRIC_THRESHOLD",
+		"message": "Connections went above 100",
+	})
+
+	assert.Equal(t, map[string]any{
+		"type":                        "OUTSIDE_METRIC_THRESHOLD",
+		"message":                     "Connections went above 100",
+		converter.SFxEventCategoryKey: int64(event.ALERT),
+		converter.SFxEventType:        "atlas_alert",
+	}, attrs.AsRaw())
+}
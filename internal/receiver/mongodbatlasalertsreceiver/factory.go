@@ -0,0 +1,75 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbatlasalertsreceiver
+
+import (
+	"context"
+
+	"github.com/open-telemetry/opentelemetry-collector-contrib/receiver/mongodbatlasreceiver"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer"
+)
+
+const (
+	typeStr = "mongodbatlas_alerts"
+
+	defaultEndpoint  = "0.0.0.0:7706"
+	defaultEventType = "mongodbatlas.alert"
+)
+
+var atlasFactory = mongodbatlasreceiver.NewFactory()
+
+func NewFactory() component.ReceiverFactory {
+	return component.NewReceiverFactory(
+		typeStr,
+		createDefaultConfig,
+		component.WithLogsReceiver(createLogsReceiver),
+	)
+}
+
+func createDefaultConfig() config.Receiver {
+	return &Config{
+		ReceiverSettings:   config.NewReceiverSettings(config.NewComponentID(typeStr)),
+		Endpoint:           defaultEndpoint,
+		EventTypeAttribute: "type",
+		DefaultEventType:   defaultEventType,
+		Properties:         []string{"message", "status"},
+	}
+}
+
+func createLogsReceiver(
+	ctx context.Context,
+	settings component.ReceiverCreateSettings,
+	cfg config.Receiver,
+	nextConsumer consumer.Logs,
+) (component.LogsReceiver, error) {
+	if nextConsumer == nil {
+		return nil, component.ErrNilNextConsumer
+	}
+	rCfg := cfg.(*Config)
+
+	// The alerts are received by the mongodbatlas receiver's webhook mode,
+	// whose log records are translated to SignalFx events before being provided.
+	atlasCfg := atlasFactory.CreateDefaultConfig().(*mongodbatlasreceiver.Config)
+	atlasCfg.SetIDName(rCfg.ID().Name())
+	atlasCfg.Alerts = mongodbatlasreceiver.AlertConfig{
+		Enabled:  true,
+		Endpoint: rCfg.Endpoint,
+		Secret:   rCfg.Secret,
+		TLS:      rCfg.TLS,
+	}
+	return atlasFactory.CreateLogsReceiver(ctx, settings, atlasCfg, newEventConsumer(rCfg, nextConsumer))
+}
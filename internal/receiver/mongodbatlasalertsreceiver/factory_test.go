@@ -0,0 +1,59 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package mongodbatlasalertsreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	assert.EqualValues(t, "mongodbatlas_alerts", f.Type())
+
+	cfg := f.CreateDefaultConfig().(*Config)
+	assert.Equal(t, config.NewComponentID(typeStr), cfg.ID())
+	assert.Equal(t, "0.0.0.0:7706", cfg.Endpoint)
+	assert.Equal(t, "type", cfg.EventTypeAttribute)
+	assert.Equal(t, "mongodbatlas.alert", cfg.DefaultEventType)
+	assert.Equal(t, []string{"message", "status"}, cfg.Properties)
+	require.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestCreateLogsReceiver(t *testing.T) {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig().(*Config)
+	cfg.Secret = "some_secret"
+	params := componenttest.NewNopReceiverCreateSettings()
+
+	r, err := f.CreateLogsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	require.NotNil(t, r)
+
+	r, err = f.CreateLogsReceiver(context.Background(), params, cfg, nil)
+	assert.ErrorIs(t, err, component.ErrNilNextConsumer)
+	assert.Nil(t, r)
+
+	_, err = f.CreateMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	assert.ErrorIs(t, err, component.ErrDataTypeIsNotSupported)
+}
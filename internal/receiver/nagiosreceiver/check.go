@@ -0,0 +1,190 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// defaultCheckTimeout is the Nagios service_check_timeout default.
+const defaultCheckTimeout = 60 * time.Second
+
+// status is the state of a check as determined by the plugin exit code.
+type status int
+
+const (
+	statusOK status = iota
+	statusWarning
+	statusCritical
+	statusUnknown
+)
+
+const (
+	checkTypeActive  = "active"
+	checkTypePassive = "passive"
+)
+
+func (s status) String() string {
+	switch s {
+	case statusOK:
+		return "OK"
+	case statusWarning:
+		return "WARNING"
+	case statusCritical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// hostStatusString returns the name of the host check state, where
+// WARNING is treated as UP like Nagios does when not using aggressive host checking.
+func (s status) hostStatusString() string {
+	switch s {
+	case statusOK, statusWarning:
+		return "UP"
+	case statusCritical:
+		return "DOWN"
+	default:
+		return "UNREACHABLE"
+	}
+}
+
+// statusFromExitCode returns the status of a plugin exit code, which is UNKNOWN when out of range.
+func statusFromExitCode(code int) status {
+	if code < int(statusOK) || code > int(statusUnknown) {
+		return statusUnknown
+	}
+	return status(code)
+}
+
+// checkResult is the result of an active or passive check.
+type checkResult struct {
+	timestamp time.Time
+	// host is the host name of passive results, empty for active checks and host checks.
+	host      string
+	checkName string
+	checkType string
+	output    pluginOutput
+	// duration is the plugin execution time of active checks.
+	duration time.Duration
+	status   status
+	// hostCheck is whether the passive result is of a host rather than a service check.
+	hostCheck bool
+}
+
+// statusName returns the status name of the result, using host state names for host checks.
+func (r checkResult) statusName() string {
+	if r.hostCheck {
+		return r.status.hostStatusString()
+	}
+	return r.status.String()
+}
+
+// runCheck executes the check plugin and returns its result, which is UNKNOWN
+// if the plugin couldn't be run or didn't exit before the check timeout.
+func runCheck(ctx context.Context, check CheckConfig) checkResult {
+	timeout := check.Timeout
+	if timeout == 0 {
+		timeout = defaultCheckTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, check.Command, check.Args...) // #nosec
+	if len(check.Env) != 0 {
+		cmd.Env = os.Environ()
+		for k, v := range check.Env {
+			cmd.Env = append(cmd.Env, k+"="+v)
+		}
+	}
+	setProcessGroup(cmd)
+	// stdout is read from a pipe rather than a buffer so that plugin child processes inheriting it
+	// can't block the check beyond its timeout.
+	stdout, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return checkResult{
+			timestamp: time.Now(),
+			checkName: check.Name,
+			checkType: checkTypeActive,
+			status:    statusUnknown,
+			output:    pluginOutput{text: fmt.Sprintf("(Failed to create plugin output pipe: %v)", err)},
+		}
+	}
+	defer stdout.Close()
+	cmd.Stdout = stdoutWriter
+	output := make(chan string, 1)
+	go func() {
+		var buf bytes.Buffer
+		_, _ = buf.ReadFrom(stdout)
+		output <- buf.String()
+	}()
+
+	start := time.Now()
+	err = cmd.Start()
+	stdoutWriter.Close()
+	if err == nil {
+		err = cmd.Wait()
+	}
+	// the plugin timed out only if it didn't exit in time, regardless of child processes still holding its stdout
+	timedOut := ctx.Err() == context.DeadlineExceeded
+	if timedOut {
+		killProcessGroup(cmd)
+	}
+	result := checkResult{
+		timestamp: start,
+		checkName: check.Name,
+		checkType: checkTypeActive,
+		duration:  time.Since(start),
+	}
+
+	var text string
+	select {
+	case text = <-output:
+	case <-ctx.Done():
+		// kill the child processes still holding stdout and stop reading the output they may not close
+		killProcessGroup(cmd)
+		stdout.Close()
+		text = <-output
+	}
+
+	var exitErr *exec.ExitError
+	switch {
+	case timedOut:
+		result.status = statusUnknown
+		result.output = pluginOutput{text: fmt.Sprintf("(Service check timed out after %v)", timeout)}
+		return result
+	case errors.As(err, &exitErr):
+		result.status = statusFromExitCode(exitErr.ExitCode())
+	case err != nil:
+		result.status = statusUnknown
+		result.output = pluginOutput{text: fmt.Sprintf("(Failed to execute check command: %v)", err)}
+		return result
+	default:
+		result.status = statusOK
+	}
+
+	result.output = parsePluginOutput(text)
+	if result.output.text == "" {
+		result.output.text = "(No output returned from plugin)"
+	}
+	return result
+}
@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"context"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunCheck(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("test plugins are shell scripts")
+	}
+	for _, test := range []struct {
+		name           string
+		check          CheckConfig
+		expectedOutput string
+		expectedStatus status
+		perfdata       int
+	}{
+		{
+			name:           "ok",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "echo 'OK - fine | value=1'"}},
+			expectedStatus: statusOK,
+			expectedOutput: "OK - fine",
+			perfdata:       1,
+		},
+		{
+			name:           "warning",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "echo \"WARNING - $LEVEL | value=2;1;3\"; exit 1"}, Env: map[string]string{"LEVEL": "high"}},
+			expectedStatus: statusWarning,
+			expectedOutput: "WARNING - high",
+			perfdata:       1,
+		},
+		{
+			name:           "critical",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "echo CRITICAL; exit 2"}},
+			expectedStatus: statusCritical,
+			expectedOutput: "CRITICAL",
+		},
+		{
+			name:           "out of range exit code",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "echo error; exit 127"}},
+			expectedStatus: statusUnknown,
+			expectedOutput: "error",
+		},
+		{
+			name:           "no output",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "exit 0"}},
+			expectedStatus: statusOK,
+			expectedOutput: "(No output returned from plugin)",
+		},
+		{
+			name:           "timeout",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "echo started; sleep 10"}, Timeout: 100 * time.Millisecond},
+			expectedStatus: statusUnknown,
+			expectedOutput: "(Service check timed out after 100ms)",
+		},
+		{
+			name:           "lingering child process",
+			check:          CheckConfig{Command: "sh", Args: []string{"-c", "echo 'OK - forked'; sleep 10 &"}, Timeout: 100 * time.Millisecond},
+			expectedStatus: statusOK,
+			expectedOutput: "OK - forked",
+		},
+		{
+			name:           "missing plugin",
+			check:          CheckConfig{Command: "/nonexistent/check_missing"},
+			expectedStatus: statusUnknown,
+			expectedOutput: "(Failed to execute check command: fork/exec /nonexistent/check_missing: no such file or directory)",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			test.check.Name = test.name
+			result := runCheck(context.Background(), test.check)
+			assert.Equal(t, test.name, result.checkName)
+			assert.Equal(t, checkTypeActive, result.checkType)
+			assert.Equal(t, test.expectedStatus, result.status)
+			assert.Equal(t, test.expectedOutput, result.output.text)
+			assert.Len(t, result.output.perfdata, test.perfdata)
+			assert.False(t, result.timestamp.IsZero())
+			assert.Less(t, result.duration, 5*time.Second)
+		})
+	}
+}
+
+func TestStatusNames(t *testing.T) {
+	assert.Equal(t, []string{"OK", "WARNING", "CRITICAL", "UNKNOWN"}, []string{
+		statusOK.String(), statusWarning.String(), statusCritical.String(), statusUnknown.String(),
+	})
+	assert.Equal(t, []string{"UP", "UP", "DOWN", "UNREACHABLE"}, []string{
+		statusOK.hostStatusString(), statusWarning.hostStatusString(), statusCritical.hostStatusString(), statusUnknown.hostStatusString(),
+	})
+	assert.Equal(t, statusUnknown, statusFromExitCode(-1))
+	assert.Equal(t, statusCritical, statusFromExitCode(2))
+	assert.Equal(t, statusUnknown, statusFromExitCode(4))
+}
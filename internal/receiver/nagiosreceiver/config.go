@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+
+	"github.com/signalfx/splunk-otel-collector/internal/requestlimit"
+)
+
+var _ config.Receiver = (*Config)(nil)
+
+type Config struct {
+	// NRDP enables accepting passive check results submitted by send_nrdp or NRDP forwarders.
+	NRDP                    *NRDPConfig `mapstructure:"nrdp"`
+	config.ReceiverSettings `mapstructure:",squash"`
+	// Checks are the Nagios plugins to execute every collection interval.
+	Checks             []CheckConfig `mapstructure:"checks"`
+	CollectionInterval time.Duration `mapstructure:"collection_interval"`
+}
+
+// CheckConfig is an active Nagios check, whose plugin exit code and output are converted
+// to the check status and perfdata metrics.
+type CheckConfig struct {
+	// Env holds additional environment variables of the plugin process.
+	Env map[string]string `mapstructure:"env"`
+	// Name is the check (service description) name.
+	Name string `mapstructure:"name"`
+	// Command is the plugin executable, e.g. /usr/lib/nagios/plugins/check_disk.
+	Command string   `mapstructure:"command"`
+	Args    []string `mapstructure:"args"`
+	// Timeout after which the plugin is killed and the check is UNKNOWN.
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// NRDPConfig is the Nagios Remote Data Processor compatible server accepting passive check results.
+type NRDPConfig struct {
+	confighttp.HTTPServerSettings `mapstructure:",squash"`
+	// Token is the NRDP token that submissions must provide.
+	Token string `mapstructure:"token"`
+	// Settings limit the decompressed size of submissions, 10 MiB by default.
+	requestlimit.Settings `mapstructure:",squash"`
+}
+
+func (cfg *Config) Validate() error {
+	if len(cfg.Checks) == 0 && cfg.NRDP == nil {
+		return errors.New("at least one of checks or nrdp must be configured")
+	}
+	if cfg.CollectionInterval <= 0 {
+		return fmt.Errorf("collection_interval must be positive: %v", cfg.CollectionInterval)
+	}
+	names := map[string]bool{}
+	for i, check := range cfg.Checks {
+		if check.Name == "" {
+			return fmt.Errorf("checks[%d]: name must not be empty", i)
+		}
+		if names[check.Name] {
+			return fmt.Errorf("checks[%d]: duplicate check name %q", i, check.Name)
+		}
+		names[check.Name] = true
+		if check.Command == "" {
+			return fmt.Errorf("checks[%d]: command must not be empty", i)
+		}
+		if check.Timeout < 0 {
+			return fmt.Errorf("checks[%d]: timeout must be non-negative: %v", i, check.Timeout)
+		}
+	}
+	if cfg.NRDP != nil {
+		if cfg.NRDP.Endpoint == "" {
+			return errors.New("nrdp: endpoint must not be empty")
+		}
+		if cfg.NRDP.Token == "" {
+			return errors.New("nrdp: token must not be empty")
+		}
+		if err := cfg.NRDP.Settings.Validate(); err != nil {
+			return fmt.Errorf("nrdp: %w", err)
+		}
+	}
+	return nil
+}
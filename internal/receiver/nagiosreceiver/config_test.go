@@ -0,0 +1,125 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/service/servicetest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/requestlimit"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, len(cfg.Receivers), 4)
+
+	defaultCfg := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), defaultCfg)
+	assert.EqualError(t, defaultCfg.Validate(), "at least one of checks or nrdp must be configured")
+
+	allSettings := cfg.Receivers[config.NewComponentIDWithName(typeStr, "allsettings")].(*Config)
+	assert.Equal(t, &Config{
+		ReceiverSettings:   config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "allsettings")),
+		CollectionInterval: 30 * time.Second,
+		Checks: []CheckConfig{
+			{
+				Name:    "disk",
+				Command: "/usr/lib/nagios/plugins/check_disk",
+				Args:    []string{"-w", "20%", "-c", "10%", "-p", "/"},
+				Timeout: 10 * time.Second,
+			},
+			{
+				Name:    "load",
+				Command: "/usr/lib/nagios/plugins/check_load",
+				Args:    []string{"-w", "5,4,3", "-c", "10,8,6"},
+				Env:     map[string]string{"LC_ALL": "C"},
+			},
+		},
+		NRDP: &NRDPConfig{
+			HTTPServerSettings: confighttp.HTTPServerSettings{Endpoint: "localhost:5668"},
+			Token:              "mytoken",
+			Settings:           requestlimit.Settings{MaxDecompressedSize: 1 << 20},
+		},
+	}, allSettings)
+	require.NoError(t, allSettings.Validate())
+
+	duplicateCheck := cfg.Receivers[config.NewComponentIDWithName(typeStr, "duplicatecheck")]
+	assert.EqualError(t, duplicateCheck.Validate(), `checks[1]: duplicate check name "disk"`)
+
+	missingToken := cfg.Receivers[config.NewComponentIDWithName(typeStr, "missingtoken")]
+	assert.EqualError(t, missingToken.Validate(), "nrdp: token must not be empty")
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		config      Config
+		expectedErr string
+	}{
+		{
+			name:        "non-positive collection interval",
+			config:      Config{Checks: []CheckConfig{{Name: "a", Command: "a"}}},
+			expectedErr: "collection_interval must be positive: 0s",
+		},
+		{
+			name:        "missing check name",
+			config:      Config{CollectionInterval: time.Minute, Checks: []CheckConfig{{Command: "a"}}},
+			expectedErr: "checks[0]: name must not be empty",
+		},
+		{
+			name:        "missing check command",
+			config:      Config{CollectionInterval: time.Minute, Checks: []CheckConfig{{Name: "a"}}},
+			expectedErr: "checks[0]: command must not be empty",
+		},
+		{
+			name:        "negative check timeout",
+			config:      Config{CollectionInterval: time.Minute, Checks: []CheckConfig{{Name: "a", Command: "a", Timeout: -time.Second}}},
+			expectedErr: "checks[0]: timeout must be non-negative: -1s",
+		},
+		{
+			name:        "missing nrdp endpoint",
+			config:      Config{CollectionInterval: time.Minute, NRDP: &NRDPConfig{Token: "token"}},
+			expectedErr: "nrdp: endpoint must not be empty",
+		},
+		{
+			name: "negative nrdp max decompressed size",
+			config: Config{CollectionInterval: time.Minute, NRDP: &NRDPConfig{
+				HTTPServerSettings: confighttp.HTTPServerSettings{Endpoint: "localhost:5668"},
+				Token:              "token",
+				Settings:           requestlimit.Settings{MaxDecompressedSize: -1},
+			}},
+			expectedErr: "nrdp: max_decompressed_size must not be negative, not -1",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, test.config.Validate(), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,71 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestFactory(t *testing.T) {
+	f := NewFactory()
+	assert.EqualValues(t, "nagios", f.Type())
+
+	cfg := f.CreateDefaultConfig().(*Config)
+	assert.Equal(t, config.NewComponentID(typeStr), cfg.ID())
+	assert.Equal(t, time.Minute, cfg.CollectionInterval)
+	assert.Nil(t, cfg.NRDP)
+	assert.Empty(t, cfg.Checks)
+	require.NoError(t, configtest.CheckConfigStruct(cfg))
+}
+
+func TestCreateReceiversShareConfig(t *testing.T) {
+	f := NewFactory()
+	cfg := f.CreateDefaultConfig()
+	params := componenttest.NewNopReceiverCreateSettings()
+
+	mr, err := f.CreateMetricsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	lr, err := f.CreateLogsReceiver(context.Background(), params, cfg, consumertest.NewNop())
+	require.NoError(t, err)
+	assert.Same(t, mr, lr)
+
+	other, err := f.CreateLogsReceiver(context.Background(), params, f.CreateDefaultConfig(), consumertest.NewNop())
+	require.NoError(t, err)
+	assert.NotSame(t, mr, other)
+
+	require.NoError(t, mr.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, lr.Start(context.Background(), componenttest.NewNopHost()))
+	require.NoError(t, mr.Shutdown(context.Background()))
+	require.NoError(t, lr.Shutdown(context.Background()))
+	require.NoError(t, other.Shutdown(context.Background()))
+
+	receiversLock.Lock()
+	assert.Empty(t, receivers)
+	receiversLock.Unlock()
+
+	r, err := f.CreateMetricsReceiver(context.Background(), params, cfg, nil)
+	assert.ErrorIs(t, err, component.ErrNilNextConsumer)
+	assert.Nil(t, r)
+}
@@ -0,0 +1,207 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	nrdpPath         = "/nrdp/"
+	nrdpSubmitCheck  = "submitcheck"
+	nrdpHostType     = "host"
+	nrdpServiceType  = "service"
+	nrdpStatusOK     = 0
+	nrdpStatusFailed = -1
+	// nrdpMaxBodySize is the default maximum decompressed size of submissions
+	nrdpMaxBodySize = 10 << 20
+)
+
+// nrdpCheckResults is the XMLDATA of an NRDP submitcheck command.
+type nrdpCheckResults struct {
+	XMLName xml.Name          `xml:"checkresults"`
+	Results []nrdpCheckResult `xml:"checkresult"`
+}
+
+type nrdpCheckResult struct {
+	Type        string `xml:"type,attr"`
+	Hostname    string `xml:"hostname"`
+	Servicename string `xml:"servicename"`
+	State       string `xml:"state"`
+	Output      string `xml:"output"`
+}
+
+// nrdpJSONCheckResults is the JSONDATA of an NRDP submitcheck command.
+type nrdpJSONCheckResults struct {
+	CheckResults []struct {
+		CheckResult struct {
+			Type string `json:"type"`
+		} `json:"checkresult"`
+		Hostname    string      `json:"hostname"`
+		Servicename string      `json:"servicename"`
+		State       json.Number `json:"state"`
+		Output      string      `json:"output"`
+	} `json:"checkresults"`
+}
+
+// nrdpResult is the NRDP response, encoded as XML or, for JSONDATA submissions, JSON.
+type nrdpResult struct {
+	XMLName xml.Name  `xml:"result" json:"-"`
+	Meta    *nrdpMeta `xml:"meta,omitempty" json:"meta,omitempty"`
+	Message string    `xml:"message" json:"message"`
+	Status  int       `xml:"status" json:"status"`
+}
+
+type nrdpMeta struct {
+	Output string `xml:"output" json:"output"`
+}
+
+// nrdpHandler accepts passive check results submitted with the NRDP submitcheck command.
+type nrdpHandler struct {
+	consume func(*http.Request, []checkResult) error
+	token   string
+}
+
+func (h *nrdpHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodPost && req.Method != http.MethodGet {
+		http.Error(w, fmt.Sprintf("method %s not allowed", req.Method), http.StatusMethodNotAllowed)
+		return
+	}
+	if err := req.ParseForm(); err != nil {
+		writeNRDPResult(w, false, http.StatusBadRequest, nrdpResult{Status: nrdpStatusFailed, Message: "BAD REQUEST"})
+		return
+	}
+	jsonData := req.Form.Get("JSONDATA")
+	isJSON := jsonData != ""
+
+	if subtle.ConstantTimeCompare([]byte(req.Form.Get("token")), []byte(h.token)) != 1 {
+		writeNRDPResult(w, isJSON, http.StatusForbidden, nrdpResult{Status: nrdpStatusFailed, Message: "BAD TOKEN"})
+		return
+	}
+	if cmd := req.Form.Get("cmd"); cmd != nrdpSubmitCheck {
+		writeNRDPResult(w, isJSON, http.StatusBadRequest, nrdpResult{Status: nrdpStatusFailed, Message: "NO COMMAND SPECIFIED"})
+		return
+	}
+
+	var results []checkResult
+	var err error
+	if isJSON {
+		results, err = parseNRDPJSON(jsonData, time.Now())
+	} else {
+		results, err = parseNRDPXML(req.Form.Get("XMLDATA"), time.Now())
+	}
+	if err != nil {
+		writeNRDPResult(w, isJSON, http.StatusBadRequest, nrdpResult{Status: nrdpStatusFailed, Message: "BAD DATA: " + err.Error()})
+		return
+	}
+
+	if err = h.consume(req, results); err != nil {
+		writeNRDPResult(w, isJSON, http.StatusServiceUnavailable, nrdpResult{Status: nrdpStatusFailed, Message: "FAILED TO PROCESS CHECK RESULTS"})
+		return
+	}
+	writeNRDPResult(w, isJSON, http.StatusOK, nrdpResult{
+		Status:  nrdpStatusOK,
+		Message: "OK",
+		Meta:    &nrdpMeta{Output: fmt.Sprintf("%d checks processed.", len(results))},
+	})
+}
+
+func writeNRDPResult(w http.ResponseWriter, isJSON bool, statusCode int, result nrdpResult) {
+	if isJSON {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(statusCode)
+		_ = json.NewEncoder(w).Encode(struct {
+			Result nrdpResult `json:"result"`
+		}{result})
+		return
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(statusCode)
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+func parseNRDPXML(data string, timestamp time.Time) ([]checkResult, error) {
+	if data == "" {
+		return nil, errors.New("missing XMLDATA")
+	}
+	var checkResults nrdpCheckResults
+	if err := xml.Unmarshal([]byte(data), &checkResults); err != nil {
+		return nil, fmt.Errorf("invalid XMLDATA: %w", err)
+	}
+	results := make([]checkResult, 0, len(checkResults.Results))
+	for _, r := range checkResults.Results {
+		result, err := newPassiveCheckResult(r.Type, r.Hostname, r.Servicename, r.State, r.Output, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func parseNRDPJSON(data string, timestamp time.Time) ([]checkResult, error) {
+	var checkResults nrdpJSONCheckResults
+	decoder := json.NewDecoder(strings.NewReader(data))
+	decoder.UseNumber()
+	if err := decoder.Decode(&checkResults); err != nil {
+		return nil, fmt.Errorf("invalid JSONDATA: %w", err)
+	}
+	results := make([]checkResult, 0, len(checkResults.CheckResults))
+	for _, r := range checkResults.CheckResults {
+		result, err := newPassiveCheckResult(r.CheckResult.Type, r.Hostname, r.Servicename, r.State.String(), r.Output, timestamp)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+func newPassiveCheckResult(checkType, hostname, servicename, state, output string, timestamp time.Time) (checkResult, error) {
+	if hostname == "" {
+		return checkResult{}, errors.New("check result hostname must not be empty")
+	}
+	code, err := strconv.Atoi(strings.TrimSpace(state))
+	if err != nil {
+		return checkResult{}, fmt.Errorf("invalid state %q of host %q: %w", state, hostname, err)
+	}
+	result := checkResult{
+		timestamp: timestamp,
+		host:      hostname,
+		checkType: checkTypePassive,
+		status:    statusFromExitCode(code),
+		output:    parsePluginOutput(output),
+	}
+	switch strings.ToLower(checkType) {
+	case nrdpHostType:
+		result.hostCheck = true
+	case nrdpServiceType, "":
+		if servicename == "" {
+			return checkResult{}, fmt.Errorf("service check result of host %q must have a servicename", hostname)
+		}
+		result.checkName = servicename
+	default:
+		return checkResult{}, fmt.Errorf("invalid check result type %q", checkType)
+	}
+	return result, nil
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"encoding/xml"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const nrdpXMLData = `<?xml version='1.0'?>
+<checkresults>
+  <checkresult type='host' checktype='1'>
+    <hostname>web01</hostname>
+    <state>2</state>
+    <output>PING CRITICAL - Packet loss = 100%</output>
+  </checkresult>
+  <checkresult type='service' checktype='1'>
+    <hostname>web01</hostname>
+    <servicename>Disk</servicename>
+    <state>1</state>
+    <output>DISK WARNING | /=2643MB;2000;3000;0;5968</output>
+  </checkresult>
+</checkresults>`
+
+func submitNRDP(t *testing.T, handler http.Handler, form url.Values) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, nrdpPath, strings.NewReader(form.Encode()))
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, req)
+	return recorder
+}
+
+func TestNRDPHandlerXML(t *testing.T) {
+	var consumed []checkResult
+	handler := &nrdpHandler{token: "token", consume: func(_ *http.Request, results []checkResult) error {
+		consumed = append(consumed, results...)
+		return nil
+	}}
+
+	recorder := submitNRDP(t, handler, url.Values{"token": {"token"}, "cmd": {"submitcheck"}, "XMLDATA": {nrdpXMLData}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "<result><meta><output>2 checks processed.</output></meta><message>OK</message><status>0</status></result>", recorder.Body.String())
+
+	require.Len(t, consumed, 2)
+	assert.Equal(t, "web01", consumed[0].host)
+	assert.True(t, consumed[0].hostCheck)
+	assert.Equal(t, "DOWN", consumed[0].statusName())
+	assert.Equal(t, checkTypePassive, consumed[0].checkType)
+	assert.Equal(t, "PING CRITICAL - Packet loss = 100%", consumed[0].output.text)
+
+	assert.Equal(t, "web01", consumed[1].host)
+	assert.False(t, consumed[1].hostCheck)
+	assert.Equal(t, "Disk", consumed[1].checkName)
+	assert.Equal(t, "WARNING", consumed[1].statusName())
+	assert.Equal(t, "DISK WARNING", consumed[1].output.text)
+	require.Len(t, consumed[1].output.perfdata, 1)
+	assert.Equal(t, 2643.0, consumed[1].output.perfdata[0].value)
+}
+
+func TestNRDPHandlerJSON(t *testing.T) {
+	var consumed []checkResult
+	handler := &nrdpHandler{token: "token", consume: func(_ *http.Request, results []checkResult) error {
+		consumed = append(consumed, results...)
+		return nil
+	}}
+
+	jsonData := `{"checkresults":[
+		{"checkresult":{"type":"service","checktype":"1"},"hostname":"db01","servicename":"Load","state":"0","output":"OK - load average: 0.1"},
+		{"checkresult":{"type":"service"},"hostname":"db01","servicename":"Swap","state":3,"output":"UNKNOWN"}
+	]}`
+	recorder := submitNRDP(t, handler, url.Values{"token": {"token"}, "cmd": {"submitcheck"}, "JSONDATA": {jsonData}})
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "application/json", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, `{"result":{"meta":{"output":"2 checks processed."},"message":"OK","status":0}}`+"\n", recorder.Body.String())
+
+	require.Len(t, consumed, 2)
+	assert.Equal(t, "Load", consumed[0].checkName)
+	assert.Equal(t, statusOK, consumed[0].status)
+	assert.Equal(t, "Swap", consumed[1].checkName)
+	assert.Equal(t, statusUnknown, consumed[1].status)
+}
+
+func TestNRDPHandlerErrors(t *testing.T) {
+	consumeErr := errors.New("consumer error")
+	handler := &nrdpHandler{token: "token", consume: func(_ *http.Request, results []checkResult) error {
+		if results[0].checkName == "failing" {
+			return consumeErr
+		}
+		return nil
+	}}
+
+	for _, test := range []struct {
+		form            url.Values
+		name            string
+		expectedMessage string
+		expectedCode    int
+	}{
+		{
+			name:            "bad token",
+			form:            url.Values{"token": {"wrong"}, "cmd": {"submitcheck"}, "XMLDATA": {nrdpXMLData}},
+			expectedCode:    http.StatusForbidden,
+			expectedMessage: "BAD TOKEN",
+		},
+		{
+			name:            "missing command",
+			form:            url.Values{"token": {"token"}, "XMLDATA": {nrdpXMLData}},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "NO COMMAND SPECIFIED",
+		},
+		{
+			name:            "missing data",
+			form:            url.Values{"token": {"token"}, "cmd": {"submitcheck"}},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: "BAD DATA: missing XMLDATA",
+		},
+		{
+			name: "invalid state",
+			form: url.Values{"token": {"token"}, "cmd": {"submitcheck"},
+				"XMLDATA": {"<checkresults><checkresult type='host'><hostname>h</hostname><state>x</state></checkresult></checkresults>"}},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: `BAD DATA: invalid state "x" of host "h": strconv.Atoi: parsing "x": invalid syntax`,
+		},
+		{
+			name: "missing servicename",
+			form: url.Values{"token": {"token"}, "cmd": {"submitcheck"},
+				"XMLDATA": {"<checkresults><checkresult type='service'><hostname>h</hostname><state>0</state></checkresult></checkresults>"}},
+			expectedCode:    http.StatusBadRequest,
+			expectedMessage: `BAD DATA: service check result of host "h" must have a servicename`,
+		},
+		{
+			name: "consumer error",
+			form: url.Values{"token": {"token"}, "cmd": {"submitcheck"},
+				"XMLDATA": {"<checkresults><checkresult type='service'><hostname>h</hostname><servicename>failing</servicename><state>0</state></checkresult></checkresults>"}},
+			expectedCode:    http.StatusServiceUnavailable,
+			expectedMessage: "FAILED TO PROCESS CHECK RESULTS",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			recorder := submitNRDP(t, handler, test.form)
+			assert.Equal(t, test.expectedCode, recorder.Code)
+			var result nrdpResult
+			require.NoError(t, xml.Unmarshal(recorder.Body.Bytes(), &result))
+			assert.Equal(t, nrdpStatusFailed, result.Status)
+			assert.Equal(t, test.expectedMessage, result.Message)
+			assert.Nil(t, result.Meta)
+		})
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// perfdataValue matches the value of a perfdata entry, with an optional unit of measurement, e.g. 0.5s or 80%.
+var perfdataValue = regexp.MustCompile(`^([-+]?(?:\d+(?:[.,]\d*)?|[.,]\d+)(?:[eE][-+]?\d+)?)([a-zA-Z%]*)$`)
+
+// perfdatum is a performance data entry of a plugin's output:
+// 'label'=value[UOM];[warn];[crit];[min];[max]
+// Thresholds are only set when they are plain numbers, not ranges.
+type perfdatum struct {
+	warning  *float64
+	critical *float64
+	min      *float64
+	max      *float64
+	label    string
+	uom      string
+	value    float64
+}
+
+// pluginOutput is the parsed output of a Nagios plugin.
+type pluginOutput struct {
+	text       string
+	longText   string
+	perfdata   []perfdatum
+	parseError error
+}
+
+// parsePluginOutput parses plugin output as described by https://nagios-plugins.org/doc/guidelines.html#AEN200:
+// the first line's text and optional perfdata, followed by optional long text lines, the first of which containing
+// a "|" starts perfdata that continues to the end of the output.  Invalid perfdata entries are skipped, with the first
+// error being reported.
+func parsePluginOutput(output string) pluginOutput {
+	lines := strings.Split(strings.TrimRight(strings.ReplaceAll(output, "\r\n", "\n"), "\n"), "\n")
+
+	text, perfdata, _ := strings.Cut(lines[0], "|")
+	var longText []string
+	inPerfdata := false
+	for _, line := range lines[1:] {
+		if inPerfdata {
+			perfdata += " " + line
+			continue
+		}
+		var found bool
+		var linePerfdata string
+		line, linePerfdata, found = strings.Cut(line, "|")
+		longText = append(longText, line)
+		if found {
+			perfdata += " " + linePerfdata
+			inPerfdata = true
+		}
+	}
+
+	parsed := pluginOutput{
+		text:     strings.TrimSpace(text),
+		longText: strings.TrimSpace(strings.Join(longText, "\n")),
+	}
+	parsed.perfdata, parsed.parseError = parsePerfdata(perfdata)
+	return parsed
+}
+
+// parsePerfdata parses the space separated perfdata entries, whose labels can be single quoted to contain spaces,
+// with quotes escaped by doubling them.  Entries with undetermined ("U") values are omitted.
+func parsePerfdata(perfdata string) ([]perfdatum, error) {
+	var entries []perfdatum
+	var firstErr error
+	rest := strings.TrimSpace(perfdata)
+	for rest != "" {
+		var entry string
+		var label string
+		var err error
+		label, entry, rest, err = nextPerfdataEntry(rest)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		var datum perfdatum
+		var ok bool
+		if datum, ok, err = parsePerfdatum(label, entry); err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		if ok {
+			entries = append(entries, datum)
+		}
+	}
+	return entries, firstErr
+}
+
+// nextPerfdataEntry returns the label and data of the first entry of perfdata, and the remaining entries.
+func nextPerfdataEntry(perfdata string) (label, data, rest string, err error) {
+	if strings.HasPrefix(perfdata, "'") {
+		var sb strings.Builder
+		i := 1
+		for ; i < len(perfdata); i++ {
+			if perfdata[i] != '\'' {
+				sb.WriteByte(perfdata[i])
+				continue
+			}
+			if i+1 < len(perfdata) && perfdata[i+1] == '\'' {
+				sb.WriteByte('\'')
+				i++
+				continue
+			}
+			break
+		}
+		if i >= len(perfdata) {
+			return "", "", "", fmt.Errorf("unterminated perfdata label quote in %q", perfdata)
+		}
+		label = sb.String()
+		perfdata = perfdata[i+1:]
+		if !strings.HasPrefix(perfdata, "=") {
+			data, rest = cutField(perfdata)
+			return "", "", rest, fmt.Errorf("missing \"=\" after perfdata label %q", label)
+		}
+		data, rest = cutField(perfdata[1:])
+		return label, data, rest, nil
+	}
+
+	var entry string
+	entry, rest = cutField(perfdata)
+	var found bool
+	if label, data, found = strings.Cut(entry, "="); !found || label == "" {
+		return "", "", rest, fmt.Errorf("invalid perfdata entry %q", entry)
+	}
+	return label, data, rest, nil
+}
+
+func cutField(s string) (field, rest string) {
+	if i := strings.IndexAny(s, " \t"); i >= 0 {
+		return s[:i], strings.TrimLeft(s[i:], " \t")
+	}
+	return s, ""
+}
+
+// parsePerfdatum parses the value[UOM];[warn];[crit];[min];[max] data of a perfdata entry,
+// returning false for undetermined values.
+func parsePerfdatum(label, data string) (perfdatum, bool, error) {
+	fields := strings.Split(data, ";")
+	if fields[0] == "U" {
+		return perfdatum{}, false, nil
+	}
+	match := perfdataValue.FindStringSubmatch(fields[0])
+	if match == nil {
+		return perfdatum{}, false, fmt.Errorf("invalid value %q of perfdata label %q", fields[0], label)
+	}
+	value, err := parseNumber(match[1])
+	if err != nil {
+		return perfdatum{}, false, fmt.Errorf("invalid value %q of perfdata label %q: %w", fields[0], label, err)
+	}
+
+	datum := perfdatum{label: label, value: value, uom: match[2]}
+	thresholds := []**float64{&datum.warning, &datum.critical, &datum.min, &datum.max}
+	for i, field := range fields[1:] {
+		if i >= len(thresholds) {
+			break
+		}
+		if threshold, err := parseNumber(field); err == nil {
+			*thresholds[i] = &threshold
+		}
+	}
+	return datum, true, nil
+}
+
+// parseNumber parses a float, allowing a decimal comma used by some locales.
+func parseNumber(s string) (float64, error) {
+	return strconv.ParseFloat(strings.Replace(s, ",", ".", 1), 64)
+}
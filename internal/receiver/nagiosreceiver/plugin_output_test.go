@@ -0,0 +1,101 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func float64Ptr(f float64) *float64 {
+	return &f
+}
+
+func TestParsePluginOutput(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		output        string
+		expected      pluginOutput
+		expectedError string
+	}{
+		{
+			name:     "text only",
+			output:   "OK - all good\n",
+			expected: pluginOutput{text: "OK - all good"},
+		},
+		{
+			name:   "perfdata",
+			output: "DISK OK - free space: / 3326 MB (56%); | /=2643MB;5948;5958;0;5968",
+			expected: pluginOutput{
+				text: "DISK OK - free space: / 3326 MB (56%);",
+				perfdata: []perfdatum{{
+					label: "/", value: 2643, uom: "MB",
+					warning: float64Ptr(5948), critical: float64Ptr(5958), min: float64Ptr(0), max: float64Ptr(5968),
+				}},
+			},
+		},
+		{
+			name:   "long text and multiline perfdata",
+			output: "DISK OK\n/ 15272 MB (77%);\n/boot 68 MB (69%); | /=2643MB;5948;5958;0;5968\n/boot=68MB;88;93;0;98\n",
+			expected: pluginOutput{
+				text:     "DISK OK",
+				longText: "/ 15272 MB (77%);\n/boot 68 MB (69%);",
+				perfdata: []perfdatum{
+					{label: "/", value: 2643, uom: "MB", warning: float64Ptr(5948), critical: float64Ptr(5958), min: float64Ptr(0), max: float64Ptr(5968)},
+					{label: "/boot", value: 68, uom: "MB", warning: float64Ptr(88), critical: float64Ptr(93), min: float64Ptr(0), max: float64Ptr(98)},
+				},
+			},
+		},
+		{
+			name:   "quoted labels, ranges, and undetermined values",
+			output: "OK | 'free space'=1.5GB;2:;1:;; 'it''s'=0,5s unknown=U",
+			expected: pluginOutput{
+				text: "OK",
+				perfdata: []perfdatum{
+					{label: "free space", value: 1.5, uom: "GB"},
+					{label: "it's", value: 0.5, uom: "s"},
+				},
+			},
+		},
+		{
+			name:   "invalid entries",
+			output: "OK | bad=x good=1 novalue",
+			expected: pluginOutput{
+				text:     "OK",
+				perfdata: []perfdatum{{label: "good", value: 1}},
+			},
+			expectedError: `invalid value "x" of perfdata label "bad"`,
+		},
+		{
+			name:          "unterminated quote",
+			output:        "OK | 'label=1",
+			expected:      pluginOutput{text: "OK"},
+			expectedError: `unterminated perfdata label quote in "'label=1"`,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			parsed := parsePluginOutput(test.output)
+			if test.expectedError == "" {
+				require.NoError(t, parsed.parseError)
+			} else {
+				require.EqualError(t, parsed.parseError, test.expectedError)
+			}
+			parsed.parseError = nil
+			assert.Equal(t, test.expected, parsed)
+		})
+	}
+}
@@ -0,0 +1,237 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/requestlimit"
+)
+
+const (
+	execTransport = "exec"
+	httpTransport = "http"
+)
+
+// statusKey identifies a check whose status changes are reported as events.
+type statusKey struct {
+	host      string
+	checkName string
+	hostCheck bool
+}
+
+// nagiosReceiver runs the configured checks and accepts NRDP check results, providing their
+// metrics and status change events to the next metrics and logs consumers respectively.
+// It's shared by the metrics and logs receivers of the same config so checks only run once.
+type nagiosReceiver struct {
+	nextMetrics  consumer.Metrics
+	nextLogs     consumer.Logs
+	server       *http.Server
+	execObsrecv  *obsreport.Receiver
+	httpObsrecv  *obsreport.Receiver
+	config       *Config
+	statuses     map[statusKey]status
+	cancel       context.CancelFunc
+	runCheck     func(context.Context, CheckConfig) checkResult
+	settings     component.ReceiverCreateSettings
+	shutdownWG   sync.WaitGroup
+	statusesLock sync.Mutex
+	startOnce    sync.Once
+	shutdownOnce sync.Once
+}
+
+var _ component.MetricsReceiver = (*nagiosReceiver)(nil)
+var _ component.LogsReceiver = (*nagiosReceiver)(nil)
+
+func newReceiver(settings component.ReceiverCreateSettings, config *Config) *nagiosReceiver {
+	return &nagiosReceiver{
+		config:   config,
+		settings: settings,
+		statuses: map[statusKey]status{},
+		runCheck: runCheck,
+		execObsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+			ReceiverID:             config.ID(),
+			Transport:              execTransport,
+			ReceiverCreateSettings: settings,
+		}),
+		httpObsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+			ReceiverID:             config.ID(),
+			Transport:              httpTransport,
+			ReceiverCreateSettings: settings,
+		}),
+	}
+}
+
+func (r *nagiosReceiver) Start(_ context.Context, host component.Host) error {
+	var err error
+	r.startOnce.Do(func() {
+		err = r.start(host)
+	})
+	return err
+}
+
+func (r *nagiosReceiver) start(host component.Host) error {
+	if r.config.NRDP != nil {
+		listener, err := r.config.NRDP.ToListener()
+		if err != nil {
+			return fmt.Errorf("failed to bind to address %s: %w", r.config.NRDP.Endpoint, err)
+		}
+
+		mux := http.NewServeMux()
+		handler := requestlimit.NewHandler(
+			&nrdpHandler{token: r.config.NRDP.Token, consume: r.consumeNRDP},
+			r.config.ID(), r.settings.Logger, r.config.NRDP.Limit(nrdpMaxBodySize),
+		)
+		mux.Handle(nrdpPath, handler)
+		mux.Handle("/nrdp", handler)
+		r.server, err = r.config.NRDP.ToServer(host, r.settings.TelemetrySettings, mux)
+		if err != nil {
+			listener.Close()
+			return err
+		}
+
+		r.shutdownWG.Add(1)
+		go func() {
+			defer r.shutdownWG.Done()
+			if errHTTP := r.server.Serve(listener); !errors.Is(errHTTP, http.ErrServerClosed) {
+				host.ReportFatalError(errHTTP)
+			}
+		}()
+	}
+
+	if len(r.config.Checks) != 0 {
+		var ctx context.Context
+		ctx, r.cancel = context.WithCancel(context.Background())
+		r.shutdownWG.Add(1)
+		go func() {
+			defer r.shutdownWG.Done()
+			r.runChecks(ctx)
+		}()
+	}
+	return nil
+}
+
+func (r *nagiosReceiver) Shutdown(context.Context) error {
+	var err error
+	r.shutdownOnce.Do(func() {
+		removeReceiver(r.config)
+		if r.cancel != nil {
+			r.cancel()
+		}
+		if r.server != nil {
+			err = r.server.Close()
+		}
+		r.shutdownWG.Wait()
+	})
+	return err
+}
+
+// runChecks runs the checks every collection interval until the context is done.
+func (r *nagiosReceiver) runChecks(ctx context.Context) {
+	ticker := time.NewTicker(r.config.CollectionInterval)
+	defer ticker.Stop()
+	for {
+		r.runChecksOnce(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (r *nagiosReceiver) runChecksOnce(ctx context.Context) {
+	results := make([]checkResult, len(r.config.Checks))
+	var wg sync.WaitGroup
+	for i, check := range r.config.Checks {
+		wg.Add(1)
+		go func(i int, check CheckConfig) {
+			defer wg.Done()
+			results[i] = r.runCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+	if ctx.Err() != nil {
+		return
+	}
+	if err := r.consume(ctx, r.execObsrecv, results); err != nil {
+		r.settings.Logger.Error("failed to consume check results", zap.Error(err))
+	}
+}
+
+func (r *nagiosReceiver) consumeNRDP(req *http.Request, results []checkResult) error {
+	err := r.consume(req.Context(), r.httpObsrecv, results)
+	if err != nil {
+		r.settings.Logger.Debug("failed to consume NRDP check results", zap.Error(err))
+	}
+	return err
+}
+
+// consume provides the metrics of the check results, and the events of their status changes,
+// to the next consumers.
+func (r *nagiosReceiver) consume(ctx context.Context, obsrecv *obsreport.Receiver, results []checkResult) error {
+	mb := newMetricsBuilder()
+	lb := newLogsBuilder()
+	events := 0
+
+	r.statusesLock.Lock()
+	for _, result := range results {
+		if result.output.parseError != nil {
+			r.settings.Logger.Debug("invalid check perfdata",
+				zap.String("host", result.host), zap.String("check", result.checkName), zap.Error(result.output.parseError))
+		}
+		mb.addResult(result)
+
+		key := statusKey{host: result.host, checkName: result.checkName, hostCheck: result.hostCheck}
+		previous, seen := r.statuses[key]
+		if seen && previous == result.status {
+			continue
+		}
+		r.statuses[key] = result.status
+		previousName := ""
+		if seen {
+			previousName = checkResult{status: previous, hostCheck: result.hostCheck}.statusName()
+		}
+		lb.addStatusChange(result, previousName)
+		events++
+	}
+	r.statusesLock.Unlock()
+
+	var errs error
+	if r.nextMetrics != nil {
+		obsCtx := obsrecv.StartMetricsOp(ctx)
+		err := r.nextMetrics.ConsumeMetrics(obsCtx, mb.md)
+		obsrecv.EndMetricsOp(obsCtx, typeStr, mb.md.DataPointCount(), err)
+		errs = multierr.Append(errs, err)
+	}
+	if r.nextLogs != nil && events > 0 {
+		obsCtx := obsrecv.StartLogsOp(ctx)
+		err := r.nextLogs.ConsumeLogs(obsCtx, lb.ld)
+		obsrecv.EndLogsOp(obsCtx, typeStr, events, err)
+		errs = multierr.Append(errs, err)
+	}
+	return errs
+}
@@ -0,0 +1,218 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+func newTestReceiver(cfg *Config) (*nagiosReceiver, *consumertest.MetricsSink, *consumertest.LogsSink) {
+	metricsSink := new(consumertest.MetricsSink)
+	logsSink := new(consumertest.LogsSink)
+	r := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg)
+	r.nextMetrics = metricsSink
+	r.nextLogs = logsSink
+	return r, metricsSink, logsSink
+}
+
+// fakeChecks returns check results of the provided statuses for each run.
+type fakeChecks struct {
+	statuses map[string][]status
+	lock     sync.Mutex
+}
+
+func (f *fakeChecks) run(_ context.Context, check CheckConfig) checkResult {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	s := f.statuses[check.Name][0]
+	if len(f.statuses[check.Name]) > 1 {
+		f.statuses[check.Name] = f.statuses[check.Name][1:]
+	}
+	return checkResult{
+		timestamp: time.Unix(1000, 0),
+		checkName: check.Name,
+		checkType: checkTypeActive,
+		duration:  250 * time.Millisecond,
+		status:    s,
+		output:    parsePluginOutput(fmt.Sprintf("%s - %s | used=75%%;80;90;0;100", s, check.Name)),
+	}
+}
+
+func metricsByName(md pmetric.Metrics) map[string]pmetric.Metric {
+	metrics := map[string]pmetric.Metric{}
+	ms := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < ms.Len(); i++ {
+		metrics[ms.At(i).Name()] = ms.At(i)
+	}
+	return metrics
+}
+
+func logRecords(ld plog.Logs) []plog.LogRecord {
+	var records []plog.LogRecord
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			for k := 0; k < sls.At(j).LogRecords().Len(); k++ {
+				records = append(records, sls.At(j).LogRecords().At(k))
+			}
+		}
+	}
+	return records
+}
+
+func TestReceiverActiveChecks(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.Checks = []CheckConfig{{Name: "disk", Command: "check_disk"}, {Name: "load", Command: "check_load"}}
+	r, metricsSink, logsSink := newTestReceiver(cfg)
+	checks := &fakeChecks{statuses: map[string][]status{
+		"disk": {statusOK, statusOK, statusCritical},
+		"load": {statusWarning},
+	}}
+	r.runCheck = checks.run
+
+	r.runChecksOnce(context.Background())
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	md := metricsSink.AllMetrics()[0]
+	assert.Equal(t, 1, md.ResourceMetrics().Len())
+	assert.Zero(t, md.ResourceMetrics().At(0).Resource().Attributes().Len())
+	assert.Equal(t, scopeName, md.ResourceMetrics().At(0).ScopeMetrics().At(0).Scope().Name())
+
+	metrics := metricsByName(md)
+	require.Len(t, metrics, 7)
+	statuses := metrics[statusMetric].Gauge().DataPoints()
+	require.Equal(t, 2, statuses.Len())
+	assert.Equal(t, int64(statusOK), statuses.At(0).IntVal())
+	assert.Equal(t, map[string]any{checkNameAttr: "disk", checkTypeAttr: checkTypeActive}, statuses.At(0).Attributes().AsRaw())
+	assert.Equal(t, int64(statusWarning), statuses.At(1).IntVal())
+	assert.Equal(t, map[string]any{checkNameAttr: "load", checkTypeAttr: checkTypeActive}, statuses.At(1).Attributes().AsRaw())
+	assert.Equal(t, time.Unix(1000, 0).UTC(), statuses.At(0).Timestamp().AsTime())
+
+	assert.Equal(t, "s", metrics[durationMetric].Unit())
+	assert.Equal(t, 0.25, metrics[durationMetric].Gauge().DataPoints().At(0).DoubleVal())
+
+	perfdata := metrics[perfdataMetric]
+	assert.Equal(t, "%", perfdata.Unit())
+	require.Equal(t, 2, perfdata.Gauge().DataPoints().Len())
+	assert.Equal(t, 75.0, perfdata.Gauge().DataPoints().At(0).DoubleVal())
+	assert.Equal(t, map[string]any{
+		checkNameAttr: "disk", checkTypeAttr: checkTypeActive, perfdataLabelAttr: "used", perfdataUOMAttr: "%",
+	}, perfdata.Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, 80.0, metrics[perfdataWarningMetric].Gauge().DataPoints().At(0).DoubleVal())
+	assert.Equal(t, 90.0, metrics[perfdataCriticalMetric].Gauge().DataPoints().At(0).DoubleVal())
+	assert.Equal(t, 0.0, metrics[perfdataMinMetric].Gauge().DataPoints().At(0).DoubleVal())
+	assert.Equal(t, 100.0, metrics[perfdataMaxMetric].Gauge().DataPoints().At(0).DoubleVal())
+
+	// the first result of each check is a status change
+	require.Len(t, logsSink.AllLogs(), 1)
+	records := logRecords(logsSink.AllLogs()[0])
+	require.Len(t, records, 2)
+	assert.Equal(t, "OK - disk", records[0].Body().StringVal())
+	assert.Equal(t, plog.SeverityNumberINFO, records[0].SeverityNumber())
+	assert.Equal(t, "OK", records[0].SeverityText())
+	assert.Equal(t, map[string]any{
+		checkNameAttr:                        "disk",
+		checkTypeAttr:                        checkTypeActive,
+		"com.splunk.signalfx.event_category": int64(event.ALERT),
+		"com.splunk.signalfx.event_type":     statusEventType,
+		"com.splunk.signalfx.event_properties": map[string]any{
+			"status": "OK",
+			"output": "OK - disk",
+		},
+	}, records[0].Attributes().AsRaw())
+	assert.Equal(t, plog.SeverityNumberWARN, records[1].SeverityNumber())
+
+	// unchanged statuses aren't reported again
+	r.runChecksOnce(context.Background())
+	require.Len(t, metricsSink.AllMetrics(), 2)
+	require.Len(t, logsSink.AllLogs(), 1)
+
+	r.runChecksOnce(context.Background())
+	require.Len(t, metricsSink.AllMetrics(), 3)
+	require.Len(t, logsSink.AllLogs(), 2)
+	records = logRecords(logsSink.AllLogs()[1])
+	require.Len(t, records, 1)
+	assert.Equal(t, plog.SeverityNumberERROR, records[0].SeverityNumber())
+	props, ok := records[0].Attributes().Get("com.splunk.signalfx.event_properties")
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"status":          "CRITICAL",
+		"previous_status": "OK",
+		"output":          "CRITICAL - disk",
+	}, props.MapVal().AsRaw())
+}
+
+func TestReceiverStartAndShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	endpoint := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.CollectionInterval = 10 * time.Millisecond
+	cfg.Checks = []CheckConfig{{Name: "disk", Command: "check_disk"}}
+	cfg.NRDP = &NRDPConfig{HTTPServerSettings: confighttp.HTTPServerSettings{Endpoint: endpoint}, Token: "token"}
+	r, metricsSink, logsSink := newTestReceiver(cfg)
+	r.runCheck = (&fakeChecks{statuses: map[string][]status{"disk": {statusOK}}}).run
+
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	}()
+	require.Eventually(t, func() bool {
+		return len(metricsSink.AllMetrics()) > 1
+	}, 5*time.Second, 10*time.Millisecond)
+
+	resp, err := http.PostForm(fmt.Sprintf("http://%s/nrdp/", endpoint), url.Values{
+		"token":   {"token"},
+		"cmd":     {"submitcheck"},
+		"XMLDATA": {nrdpXMLData},
+	})
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Eventually(t, func() bool {
+		for _, ld := range logsSink.AllLogs() {
+			rl := ld.ResourceLogs().At(0)
+			if host, ok := rl.Resource().Attributes().Get("host.name"); ok {
+				assert.Equal(t, "web01", host.StringVal())
+				records := logRecords(ld)
+				require.Len(t, records, 2)
+				_, hasCheckName := records[0].Attributes().Get(checkNameAttr)
+				assert.False(t, hasCheckName)
+				assert.True(t, strings.HasPrefix(records[1].Body().StringVal(), "DISK WARNING"))
+				return true
+			}
+		}
+		return false
+	}, 5*time.Second, 10*time.Millisecond)
+}
@@ -0,0 +1,191 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nagiosreceiver
+
+import (
+	"github.com/signalfx/golib/v3/event"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+const (
+	scopeName = "github.com/signalfx/splunk-otel-collector/internal/receiver/nagiosreceiver"
+
+	checkNameAttr     = "nagios.check.name"
+	checkTypeAttr     = "nagios.check.type"
+	perfdataLabelAttr = "nagios.perfdata.label"
+	perfdataUOMAttr   = "nagios.perfdata.uom"
+
+	statusMetric           = "nagios.check.status"
+	durationMetric         = "nagios.check.duration"
+	perfdataMetric         = "nagios.check.perfdata"
+	perfdataWarningMetric  = "nagios.check.perfdata.warning"
+	perfdataCriticalMetric = "nagios.check.perfdata.critical"
+	perfdataMinMetric      = "nagios.check.perfdata.min"
+	perfdataMaxMetric      = "nagios.check.perfdata.max"
+
+	statusEventType = "nagios.check.status"
+)
+
+// metricsBuilder groups the metrics of check results by host resource and metric name.
+type metricsBuilder struct {
+	scopes  map[string]pmetric.ScopeMetrics
+	metrics map[string]map[string]pmetric.Metric
+	md      pmetric.Metrics
+}
+
+func newMetricsBuilder() *metricsBuilder {
+	return &metricsBuilder{
+		md:      pmetric.NewMetrics(),
+		scopes:  map[string]pmetric.ScopeMetrics{},
+		metrics: map[string]map[string]pmetric.Metric{},
+	}
+}
+
+func (mb *metricsBuilder) gauge(host, name, unit, description string) pmetric.NumberDataPointSlice {
+	sm, ok := mb.scopes[host]
+	if !ok {
+		rm := mb.md.ResourceMetrics().AppendEmpty()
+		if host != "" {
+			rm.Resource().Attributes().InsertString(conventions.AttributeHostName, host)
+		}
+		sm = rm.ScopeMetrics().AppendEmpty()
+		sm.Scope().SetName(scopeName)
+		mb.scopes[host] = sm
+		mb.metrics[host] = map[string]pmetric.Metric{}
+	}
+	m, ok := mb.metrics[host][name]
+	if !ok {
+		m = sm.Metrics().AppendEmpty()
+		m.SetName(name)
+		m.SetUnit(unit)
+		m.SetDescription(description)
+		m.SetDataType(pmetric.MetricDataTypeGauge)
+		mb.metrics[host][name] = m
+	}
+	return m.Gauge().DataPoints()
+}
+
+// addResult adds the status, duration, and perfdata metrics of the check result.
+func (mb *metricsBuilder) addResult(result checkResult) {
+	ts := pcommon.NewTimestampFromTime(result.timestamp)
+	newDataPoint := func(name, unit, description string) pmetric.NumberDataPoint {
+		dp := mb.gauge(result.host, name, unit, description).AppendEmpty()
+		dp.SetTimestamp(ts)
+		insertCheckAttributes(dp.Attributes(), result)
+		return dp
+	}
+
+	newDataPoint(statusMetric, "{status}",
+		"The check status: 0 for OK (UP), 1 for WARNING, 2 for CRITICAL (DOWN), and 3 for UNKNOWN (UNREACHABLE).",
+	).SetIntVal(int64(result.status))
+
+	if result.checkType == checkTypeActive {
+		newDataPoint(durationMetric, "s", "The check plugin execution time.").SetDoubleVal(result.duration.Seconds())
+	}
+
+	for _, datum := range result.output.perfdata {
+		newPerfdataPoint := func(name, description string, value float64) {
+			dp := newDataPoint(name, datum.uom, description)
+			dp.Attributes().InsertString(perfdataLabelAttr, datum.label)
+			if datum.uom != "" {
+				dp.Attributes().InsertString(perfdataUOMAttr, datum.uom)
+			}
+			dp.SetDoubleVal(value)
+		}
+		newPerfdataPoint(perfdataMetric, "The value of a check perfdata label.", datum.value)
+		if datum.warning != nil {
+			newPerfdataPoint(perfdataWarningMetric, "The warning threshold of a check perfdata label.", *datum.warning)
+		}
+		if datum.critical != nil {
+			newPerfdataPoint(perfdataCriticalMetric, "The critical threshold of a check perfdata label.", *datum.critical)
+		}
+		if datum.min != nil {
+			newPerfdataPoint(perfdataMinMetric, "The minimum value of a check perfdata label.", *datum.min)
+		}
+		if datum.max != nil {
+			newPerfdataPoint(perfdataMaxMetric, "The maximum value of a check perfdata label.", *datum.max)
+		}
+	}
+}
+
+// logsBuilder groups the status change events of check results by host resource.
+type logsBuilder struct {
+	scopes map[string]plog.ScopeLogs
+	ld     plog.Logs
+}
+
+func newLogsBuilder() *logsBuilder {
+	return &logsBuilder{
+		ld:     plog.NewLogs(),
+		scopes: map[string]plog.ScopeLogs{},
+	}
+}
+
+// addStatusChange adds a SignalFx alert event log record for the changed status of the check result.
+// previous is empty for the first result of a check.
+func (lb *logsBuilder) addStatusChange(result checkResult, previous string) {
+	sl, ok := lb.scopes[result.host]
+	if !ok {
+		rl := lb.ld.ResourceLogs().AppendEmpty()
+		if result.host != "" {
+			rl.Resource().Attributes().InsertString(conventions.AttributeHostName, result.host)
+		}
+		sl = rl.ScopeLogs().AppendEmpty()
+		sl.Scope().SetName(scopeName)
+		lb.scopes[result.host] = sl
+	}
+
+	lr := sl.LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(result.timestamp))
+	lr.Body().SetStringVal(result.output.text)
+	switch result.status {
+	case statusOK:
+		lr.SetSeverityNumber(plog.SeverityNumberINFO)
+	case statusWarning:
+		lr.SetSeverityNumber(plog.SeverityNumberWARN)
+	default:
+		lr.SetSeverityNumber(plog.SeverityNumberERROR)
+	}
+	lr.SetSeverityText(result.statusName())
+
+	attrs := lr.Attributes()
+	insertCheckAttributes(attrs, result)
+	attrs.InsertInt(converter.SFxEventCategoryKey, int64(event.ALERT))
+	attrs.InsertString(converter.SFxEventType, statusEventType)
+
+	propMapVal := pcommon.NewValueMap()
+	props := propMapVal.MapVal()
+	props.InsertString("status", result.statusName())
+	if previous != "" {
+		props.InsertString("previous_status", previous)
+	}
+	props.InsertString("output", result.output.text)
+	if result.output.longText != "" {
+		props.InsertString("long_output", result.output.longText)
+	}
+	attrs.Insert(converter.SFxEventPropertiesKey, propMapVal)
+}
+
+func insertCheckAttributes(attrs pcommon.Map, result checkResult) {
+	if !result.hostCheck {
+		attrs.InsertString(checkNameAttr, result.checkName)
+	}
+	attrs.InsertString(checkTypeAttr, result.checkType)
+}
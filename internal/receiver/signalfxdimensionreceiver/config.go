@@ -0,0 +1,44 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionreceiver
+
+import (
+	"errors"
+
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+
+	"github.com/signalfx/splunk-otel-collector/internal/requestlimit"
+)
+
+var _ config.Receiver = (*Config)(nil)
+
+// Config defines configuration for the SignalFx dimension update receiver.
+type Config struct {
+	config.ReceiverSettings       `mapstructure:",squash"`
+	confighttp.HTTPServerSettings `mapstructure:",squash"`
+	// Settings limit the decompressed size of dimension updates, 1 MiB by default.
+	requestlimit.Settings `mapstructure:",squash"`
+	// AccessTokenPassthrough determines whether to add the X-SF-Token header of each
+	// request as the "com.splunk.signalfx.access_token" resource attribute.
+	AccessTokenPassthrough bool `mapstructure:"access_token_passthrough"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	return cfg.Settings.Validate()
+}
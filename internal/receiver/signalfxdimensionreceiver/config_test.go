@@ -0,0 +1,57 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/confighttp"
+	"go.opentelemetry.io/collector/service/servicetest"
+
+	"github.com/signalfx/splunk-otel-collector/internal/requestlimit"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, len(cfg.Receivers), 3)
+
+	defaultCfg := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), defaultCfg)
+	require.NoError(t, defaultCfg.Validate())
+
+	allSettings := cfg.Receivers[config.NewComponentIDWithName(typeStr, "allsettings")].(*Config)
+	assert.Equal(t, &Config{
+		ReceiverSettings:       config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "allsettings")),
+		HTTPServerSettings:     confighttp.HTTPServerSettings{Endpoint: "localhost:8080"},
+		AccessTokenPassthrough: true,
+		Settings:               requestlimit.Settings{MaxDecompressedSize: 2 << 20},
+	}, allSettings)
+	require.NoError(t, allSettings.Validate())
+
+	invalid := cfg.Receivers[config.NewComponentIDWithName(typeStr, "invalid")]
+	assert.EqualError(t, invalid.Validate(), "endpoint must not be empty")
+}
@@ -0,0 +1,198 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/requestlimit"
+	"github.com/signalfx/splunk-otel-collector/internal/signalfxdimension"
+)
+
+const (
+	dimensionPathPrefix = "/v2/dimension/"
+	// the suffix used by the Smart Agent for merging updates
+	sfxAgentPathSuffix = "/_/sfxagent"
+	accessTokenHeader  = "X-Sf-Token"
+	transport          = "http"
+	// maxBodySize is the default maximum decompressed size of dimension updates
+	maxBodySize = 1 << 20
+)
+
+var _ component.LogsReceiver = (*receiver)(nil)
+
+// receiver accepts SignalFx dimension API property and tag updates and provides them to
+// the next consumer as entity state log records.
+type receiver struct {
+	nextConsumer consumer.Logs
+	server       *http.Server
+	obsrecv      *obsreport.Receiver
+	config       *Config
+	settings     component.ReceiverCreateSettings
+	shutdownWG   sync.WaitGroup
+}
+
+func newReceiver(settings component.ReceiverCreateSettings, config *Config, nextConsumer consumer.Logs) (*receiver, error) {
+	if nextConsumer == nil {
+		return nil, component.ErrNilNextConsumer
+	}
+	return &receiver{
+		nextConsumer: nextConsumer,
+		config:       config,
+		settings:     settings,
+		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+			ReceiverID:             config.ID(),
+			Transport:              transport,
+			ReceiverCreateSettings: settings,
+		}),
+	}, nil
+}
+
+func (r *receiver) Start(_ context.Context, host component.Host) error {
+	listener, err := r.config.HTTPServerSettings.ToListener()
+	if err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", r.config.Endpoint, err)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(dimensionPathPrefix, requestlimit.NewHandler(r, r.config.ID(), r.settings.Logger, r.config.Limit(maxBodySize)))
+	r.server, err = r.config.HTTPServerSettings.ToServer(host, r.settings.TelemetrySettings, mux)
+	if err != nil {
+		listener.Close()
+		return err
+	}
+
+	r.shutdownWG.Add(1)
+	go func() {
+		defer r.shutdownWG.Done()
+		if errHTTP := r.server.Serve(listener); !errors.Is(errHTTP, http.ErrServerClosed) {
+			host.ReportFatalError(errHTTP)
+		}
+	}()
+	return nil
+}
+
+func (r *receiver) Shutdown(context.Context) error {
+	if r.server == nil {
+		return nil
+	}
+	err := r.server.Close()
+	r.shutdownWG.Wait()
+	return err
+}
+
+// ServeHTTP handles PUT /v2/dimension/{key}/{value} and PATCH /v2/dimension/{key}/{value}[/_/sfxagent].
+func (r *receiver) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	ctx := r.obsrecv.StartLogsOp(req.Context())
+
+	update, status, err := r.parseUpdate(req)
+	if err != nil {
+		r.obsrecv.EndLogsOp(ctx, typeStr, 0, err)
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	var accessToken string
+	if r.config.AccessTokenPassthrough {
+		accessToken = req.Header.Get(accessTokenHeader)
+	}
+	err = r.nextConsumer.ConsumeLogs(ctx, update.ToLogs(accessToken))
+	r.obsrecv.EndLogsOp(ctx, typeStr, 1, err)
+	if err != nil {
+		r.settings.Logger.Debug("failed to consume dimension update", zap.Error(err))
+		http.Error(w, "failed to process the dimension update", http.StatusServiceUnavailable)
+		return
+	}
+
+	// like the SignalFx API, respond with the updated dimension
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(update)
+}
+
+func (r *receiver) parseUpdate(req *http.Request) (signalfxdimension.Update, int, error) {
+	var update signalfxdimension.Update
+	switch req.Method {
+	case http.MethodPut:
+	case http.MethodPatch:
+		update.Merge = true
+	default:
+		return update, http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", req.Method)
+	}
+
+	key, value, err := parseDimensionPath(req.URL.EscapedPath(), update.Merge)
+	if err != nil {
+		return update, http.StatusNotFound, err
+	}
+
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		return update, http.StatusBadRequest, fmt.Errorf("failed to read request body: %w", err)
+	}
+	if err = json.Unmarshal(body, &update); err != nil {
+		return update, http.StatusBadRequest, fmt.Errorf("invalid dimension update: %w", err)
+	}
+
+	if (update.Key != "" && update.Key != key) || (update.Value != "" && update.Value != value) {
+		return update, http.StatusBadRequest, fmt.Errorf(
+			"dimension %s=%s in the body doesn't match %s=%s in the path", update.Key, update.Value, key, value,
+		)
+	}
+	update.Key, update.Value = key, value
+	if !update.Merge {
+		if len(update.TagsToRemove) != 0 {
+			return update, http.StatusBadRequest, errors.New("tagsToRemove is only supported by PATCH requests")
+		}
+		for property, propertyValue := range update.CustomProperties {
+			if propertyValue == nil {
+				return update, http.StatusBadRequest, fmt.Errorf("property %q has a null value, which is only supported by PATCH requests", property)
+			}
+		}
+	}
+	return update, http.StatusOK, nil
+}
+
+// parseDimensionPath returns the unescaped dimension key and value of the provided escaped path.
+func parseDimensionPath(path string, merge bool) (string, string, error) {
+	trimmed := strings.TrimPrefix(path, dimensionPathPrefix)
+	if merge {
+		trimmed = strings.TrimSuffix(trimmed, sfxAgentPathSuffix)
+	}
+	parts := strings.Split(strings.TrimSuffix(trimmed, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+		return "", "", fmt.Errorf("%q isn't a dimension path", path)
+	}
+	key, err := url.PathUnescape(parts[0])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid dimension key: %w", err)
+	}
+	value, err := url.PathUnescape(parts[1])
+	if err != nil {
+		return "", "", fmt.Errorf("invalid dimension value: %w", err)
+	}
+	return key, value, nil
+}
@@ -0,0 +1,248 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimensionreceiver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func newTestReceiver(t *testing.T, passthrough bool) (*receiver, *consumertest.LogsSink) {
+	cfg := createDefaultConfig().(*Config)
+	cfg.AccessTokenPassthrough = passthrough
+	sink := new(consumertest.LogsSink)
+	r, err := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	return r, sink
+}
+
+func TestReceiverPatch(t *testing.T) {
+	r, sink := newTestReceiver(t, true)
+
+	req := httptest.NewRequest(
+		http.MethodPatch, "/v2/dimension/host/my%2Fhost/_/sfxagent",
+		strings.NewReader(`{"customProperties": {"role": "db", "obsolete": null}, "tags": ["tag1"], "tagsToRemove": ["tag2"]}`),
+	)
+	req.Header.Set("X-SF-Token", "token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	assert.JSONEq(t,
+		`{"key": "host", "value": "my/host", "customProperties": {"role": "db", "obsolete": null}, "tags": ["tag1"], "tagsToRemove": ["tag2"]}`,
+		w.Body.String(),
+	)
+
+	require.Len(t, sink.AllLogs(), 1)
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	token, ok := rl.Resource().Attributes().Get("com.splunk.signalfx.access_token")
+	require.True(t, ok)
+	assert.Equal(t, "token", token.StringVal())
+	attrs := rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw()
+	assert.Equal(t, map[string]any{"host": "my/host"}, attrs["otel.entity.id"])
+	assert.Equal(t, map[string]any{"role": "db"}, attrs["otel.entity.attributes"])
+	assert.Equal(t, true, attrs["com.splunk.signalfx.dimension.merge"])
+	assert.Equal(t, []any{"tag1"}, attrs["com.splunk.signalfx.dimension.tags"])
+	assert.Equal(t, []any{"tag2"}, attrs["com.splunk.signalfx.dimension.tags_to_remove"])
+	assert.Equal(t, []any{"obsolete"}, attrs["com.splunk.signalfx.dimension.properties_to_remove"])
+}
+
+func TestReceiverPut(t *testing.T) {
+	r, sink := newTestReceiver(t, false)
+
+	req := httptest.NewRequest(
+		http.MethodPut, "/v2/dimension/host/my-host",
+		strings.NewReader(`{"key": "host", "value": "my-host", "customProperties": {"role": "db"}, "tags": ["tag1"]}`),
+	)
+	req.Header.Set("X-SF-Token", "token")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	require.Len(t, sink.AllLogs(), 1)
+	rl := sink.AllLogs()[0].ResourceLogs().At(0)
+	assert.Zero(t, rl.Resource().Attributes().Len())
+	attrs := rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw()
+	assert.Equal(t, map[string]any{"host": "my-host"}, attrs["otel.entity.id"])
+	assert.Equal(t, map[string]any{"role": "db"}, attrs["otel.entity.attributes"])
+	assert.Equal(t, false, attrs["com.splunk.signalfx.dimension.merge"])
+}
+
+func TestReceiverInvalidRequests(t *testing.T) {
+	for _, tt := range []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedStatus int
+		expectedError  string
+	}{
+		{
+			name:           "unsupported method",
+			method:         http.MethodGet,
+			path:           "/v2/dimension/host/my-host",
+			expectedStatus: http.StatusMethodNotAllowed,
+			expectedError:  "method GET not allowed",
+		},
+		{
+			name:           "missing value",
+			method:         http.MethodPut,
+			path:           "/v2/dimension/host",
+			body:           `{}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  `"/v2/dimension/host" isn't a dimension path`,
+		},
+		{
+			name:           "sfxagent suffix for put",
+			method:         http.MethodPut,
+			path:           "/v2/dimension/host/my-host/_/sfxagent",
+			body:           `{}`,
+			expectedStatus: http.StatusNotFound,
+			expectedError:  `"/v2/dimension/host/my-host/_/sfxagent" isn't a dimension path`,
+		},
+		{
+			name:           "invalid json",
+			method:         http.MethodPatch,
+			path:           "/v2/dimension/host/my-host",
+			body:           `{`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "invalid dimension update: unexpected end of JSON input",
+		},
+		{
+			name:           "mismatched dimension",
+			method:         http.MethodPut,
+			path:           "/v2/dimension/host/my-host",
+			body:           `{"key": "host", "value": "other-host"}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "dimension host=other-host in the body doesn't match host=my-host in the path",
+		},
+		{
+			name:           "put tags to remove",
+			method:         http.MethodPut,
+			path:           "/v2/dimension/host/my-host",
+			body:           `{"tagsToRemove": ["tag"]}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  "tagsToRemove is only supported by PATCH requests",
+		},
+		{
+			name:           "put null property",
+			method:         http.MethodPut,
+			path:           "/v2/dimension/host/my-host",
+			body:           `{"customProperties": {"role": null}}`,
+			expectedStatus: http.StatusBadRequest,
+			expectedError:  `property "role" has a null value, which is only supported by PATCH requests`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			r, sink := newTestReceiver(t, false)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(tt.method, tt.path, strings.NewReader(tt.body)))
+			assert.Equal(t, tt.expectedStatus, w.Code)
+			assert.Equal(t, tt.expectedError, strings.TrimSpace(w.Body.String()))
+			assert.Empty(t, sink.AllLogs())
+		})
+	}
+}
+
+func TestReceiverConsumerError(t *testing.T) {
+	cfg := createDefaultConfig().(*Config)
+	r, err := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewErr(errors.New("consumer error")))
+	require.NoError(t, err)
+
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodPatch, "/v2/dimension/host/my-host", strings.NewReader(`{"tags": ["tag"]}`)))
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+}
+
+func TestReceiverStartAndShutdown(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	endpoint := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	sink := new(consumertest.LogsSink)
+	r, err := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	}()
+
+	req, err := http.NewRequest(
+		http.MethodPatch, fmt.Sprintf("http://%s/v2/dimension/host/my-host/_/sfxagent", endpoint), strings.NewReader(`{"tags": ["tag"]}`),
+	)
+	require.NoError(t, err)
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.Len(t, sink.AllLogs(), 1)
+	assert.Equal(t, 1, sink.AllLogs()[0].LogRecordCount())
+}
+
+func TestReceiverShutdownWithoutStart(t *testing.T) {
+	r, _ := newTestReceiver(t, false)
+	assert.NoError(t, r.Shutdown(context.Background()))
+}
+
+func TestReceiverDecompressedSizeLimit(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	endpoint := listener.Addr().String()
+	require.NoError(t, listener.Close())
+
+	cfg := createDefaultConfig().(*Config)
+	cfg.Endpoint = endpoint
+	cfg.MaxDecompressedSize = 1024
+	sink := new(consumertest.LogsSink)
+	r, err := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, sink)
+	require.NoError(t, err)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	}()
+
+	// a property value compressing to far less than the limit
+	var body bytes.Buffer
+	gz := gzip.NewWriter(&body)
+	_, err = fmt.Fprintf(gz, `{"customProperties": {"property": %q}}`, strings.Repeat("a", 4096))
+	require.NoError(t, err)
+	require.NoError(t, gz.Close())
+	require.Less(t, body.Len(), 1024)
+
+	req, err := http.NewRequest(http.MethodPatch, fmt.Sprintf("http://%s/v2/dimension/host/my-host", endpoint), &body)
+	require.NoError(t, err)
+	req.Header.Set("Content-Encoding", "gzip")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	assert.Empty(t, sink.AllLogs())
+}
@@ -0,0 +1,257 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+const (
+	defaultCardinalityReportTopK = 10
+	// maxTrackedCardinality bounds the distinct values tracked for each dimension, and the distinct series,
+	// during a report interval so that the tracking of a cardinality explosion doesn't exhaust memory.
+	maxTrackedCardinality = 10000
+)
+
+var (
+	dimensionKey = tag.MustNewKey("dimension")
+
+	mDatapointRate = stats.Float64(
+		typeStr+"/datapoint_rate", "Datapoints per second sent by a monitor during the last cardinality report interval", "1/s",
+	)
+	mDimensionCardinality = stats.Int64(
+		typeStr+"/dimension_cardinality", "Distinct values of a monitor's top dimensions during the last cardinality report interval", stats.UnitDimensionless,
+	)
+)
+
+// cardinalityTracker counts the datapoints of a monitor and the distinct values of their dimensions, reporting
+// them every interval as the receiver's datapoint_rate and dimension_cardinality metrics along with a log
+// statement of the top dimensions by distinct values, so that cardinality explosions can be traced to the
+// responsible monitor.
+type cardinalityTracker struct {
+	now         func() time.Time
+	clock       clock.Clock
+	logger      *zap.Logger
+	values      map[string]map[string]struct{}
+	capped      map[string]bool
+	series      map[string]struct{}
+	done        chan struct{}
+	windowStart time.Time
+	receiverID  config.ComponentID
+	monitorType string
+	wg          sync.WaitGroup
+	datapoints  int64
+	interval    time.Duration
+	topK        int
+	lock        sync.Mutex
+	// whether distinct series weren't tracked after reaching maxTrackedCardinality
+	seriesCapped bool
+}
+
+// cardinalityReport is the content of a report interval.
+type cardinalityReport struct {
+	topDimensions       []dimensionCardinality
+	datapointsPerSecond float64
+	series              int
+	seriesCapped        bool
+}
+
+type dimensionCardinality struct {
+	dimension string
+	values    int
+	capped    bool
+}
+
+func (d dimensionCardinality) String() string {
+	if d.capped {
+		return fmt.Sprintf("%s:%d+", d.dimension, d.values)
+	}
+	return fmt.Sprintf("%s:%d", d.dimension, d.values)
+}
+
+func newCardinalityTracker(
+	intervalSeconds, topK int, receiverID config.ComponentID, monitorType string, logger *zap.Logger, clk clock.Clock,
+) *cardinalityTracker {
+	if topK == 0 {
+		topK = defaultCardinalityReportTopK
+	}
+	tracker := &cardinalityTracker{
+		now:         clk.Now,
+		clock:       clk,
+		logger:      logger,
+		done:        make(chan struct{}),
+		receiverID:  receiverID,
+		monitorType: monitorType,
+		interval:    time.Duration(intervalSeconds) * time.Second,
+		topK:        topK,
+	}
+	tracker.reset(tracker.now())
+	return tracker
+}
+
+func (t *cardinalityTracker) reset(windowStart time.Time) {
+	t.values = map[string]map[string]struct{}{}
+	t.capped = map[string]bool{}
+	t.series = map[string]struct{}{}
+	t.seriesCapped = false
+	t.datapoints = 0
+	t.windowStart = windowStart
+}
+
+// track counts the datapoints and their dimension values.  It's a noop for a nil instance.
+func (t *cardinalityTracker) track(datapoints []*datapoint.Datapoint) {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	for _, dp := range datapoints {
+		t.datapoints++
+		key := seriesKey(dp)
+		if _, ok := t.series[key]; !ok {
+			if len(t.series) < maxTrackedCardinality {
+				t.series[key] = struct{}{}
+			} else {
+				t.seriesCapped = true
+			}
+		}
+		for dimension, value := range dp.Dimensions {
+			values, ok := t.values[dimension]
+			if !ok {
+				values = map[string]struct{}{}
+				t.values[dimension] = values
+			}
+			if _, ok = values[value]; ok {
+				continue
+			}
+			if len(values) >= maxTrackedCardinality {
+				t.capped[dimension] = true
+				continue
+			}
+			values[value] = struct{}{}
+		}
+	}
+}
+
+// seriesKey identifies the series of the datapoint by its metric and dimensions.
+func seriesKey(dp *datapoint.Datapoint) string {
+	dimensions := make([]string, 0, len(dp.Dimensions))
+	for dimension, value := range dp.Dimensions {
+		dimensions = append(dimensions, dimension+"="+value)
+	}
+	sort.Strings(dimensions)
+	return dp.Metric + "\x00" + strings.Join(dimensions, "\x00")
+}
+
+// collect returns the report of the current interval and starts the next one.
+func (t *cardinalityTracker) collect() cardinalityReport {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	now := t.now()
+	report := cardinalityReport{
+		series:       len(t.series),
+		seriesCapped: t.seriesCapped,
+	}
+	if elapsed := now.Sub(t.windowStart).Seconds(); elapsed > 0 {
+		report.datapointsPerSecond = float64(t.datapoints) / elapsed
+	}
+	for dimension, values := range t.values {
+		report.topDimensions = append(report.topDimensions, dimensionCardinality{
+			dimension: dimension, values: len(values), capped: t.capped[dimension],
+		})
+	}
+	sort.Slice(report.topDimensions, func(i, j int) bool {
+		if report.topDimensions[i].values != report.topDimensions[j].values {
+			return report.topDimensions[i].values > report.topDimensions[j].values
+		}
+		return report.topDimensions[i].dimension < report.topDimensions[j].dimension
+	})
+	if len(report.topDimensions) > t.topK {
+		report.topDimensions = report.topDimensions[:t.topK]
+	}
+	t.reset(now)
+	return report
+}
+
+// report records the metrics and logs the top dimensions of the current interval.
+func (t *cardinalityTracker) report() {
+	report := t.collect()
+	mutators := []tag.Mutator{tag.Upsert(receiverKey, t.receiverID.String()), tag.Upsert(monitorTypeKey, t.monitorType)}
+	_ = stats.RecordWithTags(context.Background(), mutators, mDatapointRate.M(report.datapointsPerSecond))
+
+	topDimensions := make([]string, 0, len(report.topDimensions))
+	for _, d := range report.topDimensions {
+		_ = stats.RecordWithTags(
+			context.Background(), append(mutators, tag.Upsert(dimensionKey, d.dimension)), mDimensionCardinality.M(int64(d.values)),
+		)
+		topDimensions = append(topDimensions, d.String())
+	}
+	t.logger.Info(
+		"Smart Agent monitor cardinality report",
+		zap.String("event", "smartagent_cardinality_report"),
+		zap.String("monitor_type", t.monitorType),
+		zap.Stringer("receiver", t.receiverID),
+		zap.Float64("datapoints_per_second", report.datapointsPerSecond),
+		zap.Int("distinct_series", report.series),
+		zap.Bool("distinct_series_capped", report.seriesCapped),
+		zap.Strings("top_dimensions", topDimensions),
+	)
+}
+
+// start reports every interval until shutdown.  It's a noop for a nil instance.
+func (t *cardinalityTracker) start() {
+	if t == nil {
+		return
+	}
+	t.lock.Lock()
+	t.reset(t.now())
+	t.lock.Unlock()
+	t.wg.Add(1)
+	go func() {
+		defer t.wg.Done()
+		ticker := t.clock.NewTicker(t.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-t.done:
+				return
+			case <-ticker.Chan():
+				t.report()
+			}
+		}
+	}()
+}
+
+// shutdown stops the reporting.  It's a noop for a nil instance.
+func (t *cardinalityTracker) shutdown() {
+	if t == nil {
+		return
+	}
+	close(t.done)
+	t.wg.Wait()
+}
@@ -0,0 +1,127 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+func newTestCardinalityTracker(topK int, logger *zap.Logger) (*cardinalityTracker, *time.Time) {
+	now := time.Unix(1000, 0)
+	tracker := newCardinalityTracker(10, topK, config.NewComponentIDWithName(typeStr, "redis"), "collectd/redis", logger, clock.New())
+	tracker.now = func() time.Time { return now }
+	tracker.reset(now)
+	return tracker, &now
+}
+
+func gauge(metric string, dimensions map[string]string) *datapoint.Datapoint {
+	return datapoint.New(metric, dimensions, datapoint.NewIntValue(1), datapoint.Gauge, time.Now())
+}
+
+func TestCardinalityTrackerCollect(t *testing.T) {
+	tracker, now := newTestCardinalityTracker(2, zap.NewNop())
+
+	for i := 0; i < 10; i++ {
+		tracker.track([]*datapoint.Datapoint{
+			gauge("bytes.used_memory", map[string]string{"host": "a", "key": fmt.Sprintf("key-%d", i), "db": fmt.Sprintf("%d", i%2)}),
+			gauge("bytes.used_memory_rss", map[string]string{"host": "a"}),
+		})
+	}
+	*now = now.Add(10 * time.Second)
+
+	report := tracker.collect()
+	assert.Equal(t, cardinalityReport{
+		topDimensions: []dimensionCardinality{
+			{dimension: "key", values: 10},
+			{dimension: "db", values: 2},
+		},
+		datapointsPerSecond: 2,
+		series:              11,
+	}, report)
+
+	// the next interval starts empty
+	*now = now.Add(10 * time.Second)
+	assert.Equal(t, cardinalityReport{}, tracker.collect())
+}
+
+func TestCardinalityTrackerCapsTrackedValues(t *testing.T) {
+	tracker, now := newTestCardinalityTracker(0, zap.NewNop())
+	assert.Equal(t, defaultCardinalityReportTopK, tracker.topK)
+
+	for i := 0; i < maxTrackedCardinality+5; i++ {
+		tracker.track([]*datapoint.Datapoint{gauge("requests", map[string]string{"request_id": fmt.Sprintf("%d", i)})})
+	}
+	*now = now.Add(time.Second)
+
+	report := tracker.collect()
+	assert.Equal(t, []dimensionCardinality{{dimension: "request_id", values: maxTrackedCardinality, capped: true}}, report.topDimensions)
+	assert.Equal(t, "request_id:10000+", report.topDimensions[0].String())
+	assert.Equal(t, maxTrackedCardinality, report.series)
+	assert.True(t, report.seriesCapped)
+	assert.Equal(t, float64(maxTrackedCardinality+5), report.datapointsPerSecond)
+}
+
+func TestCardinalityTrackerReport(t *testing.T) {
+	logCore, logs := observer.New(zap.InfoLevel)
+	tracker, now := newTestCardinalityTracker(5, zap.New(logCore))
+
+	tracker.track([]*datapoint.Datapoint{
+		gauge("bytes.used_memory", map[string]string{"host": "a", "plugin_instance": "6379"}),
+		gauge("bytes.used_memory", map[string]string{"host": "a", "plugin_instance": "6380"}),
+	})
+	*now = now.Add(4 * time.Second)
+	tracker.report()
+
+	entries := logs.FilterField(zap.String("event", "smartagent_cardinality_report")).All()
+	require.Len(t, entries, 1)
+	fields := entries[0].ContextMap()
+	assert.Equal(t, "collectd/redis", fields["monitor_type"])
+	assert.Equal(t, "smartagent/redis", fields["receiver"])
+	assert.Equal(t, 0.5, fields["datapoints_per_second"])
+	assert.EqualValues(t, 2, fields["distinct_series"])
+	assert.Equal(t, false, fields["distinct_series_capped"])
+	assert.Equal(t, []any{"plugin_instance:2", "host:1"}, fields["top_dimensions"])
+}
+
+func TestCardinalityTrackerStartAndShutdown(t *testing.T) {
+	logCore, logs := observer.New(zap.InfoLevel)
+	fake := clock.NewFake(time.Now())
+	tracker := newCardinalityTracker(1, 0, config.NewComponentIDWithName(typeStr, "cpu"), "cpu", zap.New(logCore), fake)
+	tracker.start()
+	tracker.track([]*datapoint.Datapoint{gauge("cpu.utilization", nil)})
+	require.Eventually(t, func() bool { return fake.Tickers() == 1 }, 5*time.Second, 5*time.Millisecond)
+	assert.Equal(t, 0, logs.Len())
+	fake.Advance(time.Second)
+	require.Eventually(t, func() bool {
+		return logs.FilterField(zap.String("event", "smartagent_cardinality_report")).Len() > 0
+	}, 5*time.Second, 5*time.Millisecond)
+	tracker.shutdown()
+
+	var nilTracker *cardinalityTracker
+	nilTracker.track([]*datapoint.Datapoint{gauge("cpu.utilization", nil)})
+	nilTracker.start()
+	nilTracker.shutdown()
+}
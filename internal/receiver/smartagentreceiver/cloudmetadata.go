@@ -0,0 +1,51 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/hostmetadata"
+)
+
+// hostMetadataMonitors report host properties whose dimension updates should be keyed
+// by the cloud provider's unique host identifier when one is available.
+var hostMetadataMonitors = map[string]bool{
+	"collectd/signalfx-metadata": true,
+	"host-metadata":              true,
+}
+
+// cloudMetadataProvider is the source of cached cloud metadata, shared with other components.
+var cloudMetadataProvider = hostmetadata.Shared
+
+// lookupCloudMetadata retrieves the cloud metadata in the background, so that it doesn't delay the start of
+// the receiver. Once retrieved, its unique cloud host identifier dimensions (e.g. AWSUniqueId) are added to the
+// datapoints of the output like its extra dimensions, and its cloud and host semantic convention attributes
+// (e.g. cloud.provider, host.id) to the resources of its datapoints and events.
+func lookupCloudMetadata(output *Output, provider *hostmetadata.Provider, logger *zap.Logger) {
+	output.cloudMetadata = provider.Lookup()
+	go func(lookup *hostmetadata.Lookup) {
+		<-lookup.Done()
+		if _, err := lookup.Get(); err != nil {
+			if errors.Is(err, hostmetadata.ErrNotDetected) {
+				logger.Debug("not adding cloud metadata dimensions", zap.Error(err))
+			} else {
+				logger.Info("failed retrieving cloud metadata", zap.Error(err))
+			}
+		}
+	}(output.cloudMetadata)
+}
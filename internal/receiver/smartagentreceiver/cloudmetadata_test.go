@@ -0,0 +1,114 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/hostmetadata"
+)
+
+type fakeCloudDetector struct {
+	metadata *hostmetadata.Metadata
+	err      error
+}
+
+func (d fakeCloudDetector) Name() string {
+	return "fake"
+}
+
+func (d fakeCloudDetector) Detect(context.Context) (*hostmetadata.Metadata, error) {
+	return d.metadata, d.err
+}
+
+// blockingCloudDetector detects its metadata once unblocked, like metadata endpoints timing out.
+type blockingCloudDetector struct {
+	fakeCloudDetector
+	unblock chan struct{}
+}
+
+func (d blockingCloudDetector) Detect(ctx context.Context) (*hostmetadata.Metadata, error) {
+	<-d.unblock
+	return d.fakeCloudDetector.Detect(ctx)
+}
+
+func sendCloudMetadataDatapoint(output *Output) {
+	output.SendDatapoints(datapoint.New("cpu.utilization", map[string]string{"host": "a.host"},
+		datapoint.NewFloatValue(12.5), datapoint.Gauge, time.Now()))
+}
+
+func TestLookupCloudMetadata(t *testing.T) {
+	metricsSink := new(consumertest.MetricsSink)
+	output := NewOutput(
+		Config{}, fakeMonitorFiltering(), metricsSink, consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+	detector := blockingCloudDetector{
+		fakeCloudDetector: fakeCloudDetector{metadata: &hostmetadata.Metadata{
+			CloudProvider: "aws", InstanceID: "i-123", Region: "us-west-2", AccountID: "123456789012",
+		}},
+		unblock: make(chan struct{}),
+	}
+
+	// doesn't wait for the metadata
+	lookupCloudMetadata(output, hostmetadata.NewProvider(hostmetadata.WithDetectors(detector)), zap.NewNop())
+	sendCloudMetadataDatapoint(output)
+	close(detector.unblock)
+	<-output.cloudMetadata.Done()
+	sendCloudMetadataDatapoint(output)
+
+	require.Len(t, metricsSink.AllMetrics(), 2)
+	pending := metricsSink.AllMetrics()[0].ResourceMetrics().At(0)
+	assert.Zero(t, pending.Resource().Attributes().Len())
+	_, ok := pending.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes().Get("AWSUniqueId")
+	assert.False(t, ok)
+
+	retrieved := metricsSink.AllMetrics()[1].ResourceMetrics().At(0)
+	assert.Equal(t, pcommon.NewMapFromRaw(map[string]interface{}{
+		"cloud.provider": "aws", "cloud.region": "us-west-2", "cloud.account.id": "123456789012", "host.id": "i-123",
+	}).Sort(), retrieved.Resource().Attributes().Sort())
+	uniqueID, ok := retrieved.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes().Get("AWSUniqueId")
+	require.True(t, ok)
+	assert.Equal(t, "i-123_us-west-2_123456789012", uniqueID.StringVal())
+}
+
+func TestLookupCloudMetadataNotDetected(t *testing.T) {
+	metricsSink := new(consumertest.MetricsSink)
+	output := NewOutput(
+		Config{}, fakeMonitorFiltering(), metricsSink, consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+	provider := hostmetadata.NewProvider(hostmetadata.WithDetectors(fakeCloudDetector{err: errors.New("unreachable")}))
+
+	lookupCloudMetadata(output, provider, zap.NewNop())
+	<-output.cloudMetadata.Done()
+	sendCloudMetadataDatapoint(output)
+
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	rm := metricsSink.AllMetrics()[0].ResourceMetrics().At(0)
+	assert.Zero(t, rm.Resource().Attributes().Len())
+	assert.Equal(t, 1, rm.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes().Len())
+}
@@ -0,0 +1,171 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+var (
+	receiverKey    = tag.MustNewKey("receiver")
+	monitorTypeKey = tag.MustNewKey("monitor_type")
+
+	mCollectionTimeouts = stats.Int64(
+		typeStr+"/collection_timeouts", "Number of times a monitor was restarted for exceeding its collection timeout", stats.UnitDimensionless,
+	)
+
+	registerMetricViewsOnce sync.Once
+)
+
+// maxCollectionTimeoutRestarts bounds the consecutive restarts of a monitor that doesn't send any telemetry
+// after being restarted.  Monitors that don't cancel their in-flight collection on shutdown leave it running
+// in the background, so each restart can leave behind goroutines blocked on the unresponsive target.
+const maxCollectionTimeoutRestarts = 3
+
+// metricViews returns the views of the receiver's metrics, which are reported by the collector's own telemetry.
+func metricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mCollectionTimeouts.Name(),
+			Description: mCollectionTimeouts.Description(),
+			Measure:     mCollectionTimeouts,
+			TagKeys:     []tag.Key{receiverKey, monitorTypeKey},
+			Aggregation: view.Sum(),
+		},
+		{
+			Name:        mDatapointRate.Name(),
+			Description: mDatapointRate.Description(),
+			Measure:     mDatapointRate,
+			TagKeys:     []tag.Key{receiverKey, monitorTypeKey},
+			Aggregation: view.LastValue(),
+		},
+		{
+			Name:        mDimensionCardinality.Name(),
+			Description: mDimensionCardinality.Description(),
+			Measure:     mDimensionCardinality,
+			TagKeys:     []tag.Key{receiverKey, monitorTypeKey, dimensionKey},
+			Aggregation: view.LastValue(),
+		},
+	}
+}
+
+// applyCollectionTimeout sets the monitor's own request timeout options, used by the clients of its
+// collection requests, to the collection timeout unless they are explicitly configured.
+func applyCollectionTimeout(timeoutSeconds int, monitorConfigType reflect.Type, allSettings map[string]any) {
+	if timeoutSeconds == 0 {
+		return
+	}
+	monitorOptions := monitorConfigOptions(monitorConfigType)
+	timeoutOptions := map[string]any{
+		// used by the monitors' own clients
+		"timeoutSeconds": timeoutSeconds,
+		// used by the monitors embedding the common httpclient.HTTPConfig
+		"httpTimeout": fmt.Sprintf("%ds", timeoutSeconds),
+	}
+	for option, value := range timeoutOptions {
+		if _, ok := allSettings[option]; monitorOptions[option] && !ok {
+			allSettings[option] = value
+		}
+	}
+}
+
+// collectionWatchdog invokes onTimeout whenever the monitor hasn't sent any telemetry for the duration
+// of its interval and collection timeout, which is reset by each collection.  After maxCollectionTimeoutRestarts
+// consecutive timeouts, onTimeout is invoked once more with exhausted set, and then not again until the next
+// collection.
+type collectionWatchdog struct {
+	clock     clock.Clock
+	onTimeout func(exhausted bool)
+	collected chan struct{}
+	done      chan struct{}
+	wg        sync.WaitGroup
+	timeout   time.Duration
+}
+
+func newCollectionWatchdog(timeout time.Duration, clk clock.Clock, onTimeout func(exhausted bool)) *collectionWatchdog {
+	return &collectionWatchdog{
+		clock:     clk,
+		onTimeout: onTimeout,
+		collected: make(chan struct{}, 1),
+		done:      make(chan struct{}),
+		timeout:   timeout,
+	}
+}
+
+func (w *collectionWatchdog) start() {
+	w.wg.Add(1)
+	go func() {
+		defer w.wg.Done()
+		ticker := w.clock.NewTicker(w.timeout)
+		ticks := ticker.Chan()
+		defer func() {
+			ticker.Stop()
+		}()
+		// resetTimeout counts the timeout from now on, or stops counting it for an exhausted watchdog.  Ticks
+		// of the previous ticker that weren't received are dropped with it.
+		resetTimeout := func(exhausted bool) {
+			ticker.Stop()
+			ticks = nil
+			if !exhausted {
+				ticker = w.clock.NewTicker(w.timeout)
+				ticks = ticker.Chan()
+			}
+		}
+		var timeouts int
+		for {
+			select {
+			case <-w.done:
+				return
+			case <-w.collected:
+				timeouts = 0
+				resetTimeout(false)
+			case <-ticks:
+				timeouts++
+				exhausted := timeouts > maxCollectionTimeoutRestarts
+				resetTimeout(exhausted)
+				w.onTimeout(exhausted)
+			}
+		}
+	}()
+}
+
+// collection resets the watchdog's timeout.  It's a noop for a nil instance.
+func (w *collectionWatchdog) collection() {
+	if w == nil {
+		return
+	}
+	select {
+	case w.collected <- struct{}{}:
+	default:
+	}
+}
+
+// shutdown stops the watchdog, waiting for any in-progress onTimeout invocation to return.
+func (w *collectionWatchdog) shutdown() {
+	if w == nil {
+		return
+	}
+	close(w.done)
+	w.wg.Wait()
+}
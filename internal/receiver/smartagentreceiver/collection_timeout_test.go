@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors/prometheusexporter"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+type timeoutSecondsConfig struct {
+	TimeoutSeconds int `yaml:"timeoutSeconds"`
+}
+
+type noTimeoutConfig struct {
+	Host string `yaml:"host"`
+}
+
+func TestApplyCollectionTimeout(t *testing.T) {
+	for _, test := range []struct {
+		monitorConfigType reflect.Type
+		allSettings       map[string]any
+		expected          map[string]any
+		name              string
+		timeoutSeconds    int
+	}{
+		{
+			name:              "disabled",
+			monitorConfigType: reflect.TypeOf(timeoutSecondsConfig{}),
+			allSettings:       map[string]any{},
+			expected:          map[string]any{},
+		},
+		{
+			name:              "timeout seconds",
+			monitorConfigType: reflect.TypeOf(timeoutSecondsConfig{}),
+			timeoutSeconds:    5,
+			allSettings:       map[string]any{},
+			expected:          map[string]any{"timeoutSeconds": 5},
+		},
+		{
+			name:              "explicit timeout seconds",
+			monitorConfigType: reflect.TypeOf(timeoutSecondsConfig{}),
+			timeoutSeconds:    5,
+			allSettings:       map[string]any{"timeoutSeconds": 20},
+			expected:          map[string]any{"timeoutSeconds": 20},
+		},
+		{
+			name:              "inlined http timeout",
+			monitorConfigType: reflect.TypeOf(&prometheusexporter.Config{}),
+			timeoutSeconds:    5,
+			allSettings:       map[string]any{},
+			expected:          map[string]any{"httpTimeout": "5s"},
+		},
+		{
+			name:              "unsupported",
+			monitorConfigType: reflect.TypeOf(noTimeoutConfig{}),
+			timeoutSeconds:    5,
+			allSettings:       map[string]any{"host": "localhost"},
+			expected:          map[string]any{"host": "localhost"},
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			applyCollectionTimeout(test.timeoutSeconds, test.monitorConfigType, test.allSettings)
+			assert.Equal(t, test.expected, test.allSettings)
+		})
+	}
+}
+
+func TestCollectionWatchdog(t *testing.T) {
+	fake := clock.NewFake(time.Now())
+	timeout := time.Minute
+	timeouts := make(chan bool, 10)
+	watchdog := newCollectionWatchdog(timeout, fake, func(exhausted bool) { timeouts <- exhausted })
+	watchdog.start()
+	require.Eventually(t, func() bool { return fake.Tickers() == 1 }, 5*time.Second, time.Millisecond)
+
+	// collections within the timeout reset it
+	for i := 0; i < 5; i++ {
+		fake.Advance(timeout / 2)
+		watchdog.collection()
+		require.Eventually(t, func() bool { return len(watchdog.collected) == 0 }, 5*time.Second, time.Millisecond)
+	}
+	assert.Empty(t, timeouts)
+
+	// without collections the timeout is repeatedly reached, until the restarts are exhausted
+	for i := 0; i <= maxCollectionTimeoutRestarts; i++ {
+		fake.Advance(timeout)
+		require.Eventually(t, func() bool { return len(timeouts) == i+1 }, 5*time.Second, time.Millisecond)
+	}
+	assert.Zero(t, fake.Tickers())
+	fake.Advance(10 * timeout)
+	require.Len(t, timeouts, maxCollectionTimeoutRestarts+1)
+	for i := 0; i < maxCollectionTimeoutRestarts; i++ {
+		assert.False(t, <-timeouts)
+	}
+	assert.True(t, <-timeouts)
+
+	// a collection resets the exhausted watchdog
+	watchdog.collection()
+	require.Eventually(t, func() bool { return fake.Tickers() == 1 }, 5*time.Second, time.Millisecond)
+	fake.Advance(timeout)
+	require.Eventually(t, func() bool { return len(timeouts) == 1 }, 5*time.Second, time.Millisecond)
+	assert.False(t, <-timeouts)
+
+	watchdog.shutdown()
+	assert.Zero(t, fake.Tickers())
+}
+
+func TestNilCollectionWatchdog(t *testing.T) {
+	var watchdog *collectionWatchdog
+	assert.NotPanics(t, func() {
+		watchdog.collection()
+		watchdog.shutdown()
+	})
+}
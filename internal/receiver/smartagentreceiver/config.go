@@ -27,8 +27,14 @@ import (
 	"github.com/signalfx/signalfx-agent/pkg/core/config/validation"
 	"github.com/signalfx/signalfx-agent/pkg/monitors"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/confmap"
 	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/correlation"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+	_ "github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/nvidiadcgm" // registers the nvidia-dcgm monitor
+	_ "github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/oracledb"   // registers the oracledb monitor
 )
 
 const defaultIntervalSeconds = 10
@@ -36,8 +42,34 @@ const defaultIntervalSeconds = 10
 var (
 	_ config.Unmarshallable = (*Config)(nil)
 
-	errDimensionClientValue = fmt.Errorf("dimensionClients must be an array of compatible exporter names")
-	nonWindowsMonitors      = map[string]bool{
+	errDimensionClientValue        = fmt.Errorf("dimensionClients must be an array of compatible exporter names")
+	errTranslateDimensionsValue    = fmt.Errorf("translateDimensions must be a boolean")
+	errConfigEndpointMappingsValue = fmt.Errorf("configEndpointMappings must be a map of monitor config options to scalar values")
+	errMaxAttributeCountValue      = fmt.Errorf("maxAttributeCount must be a non-negative integer")
+	errMaxAttributeValueLength     = fmt.Errorf("maxAttributeValueLength must be a non-negative integer")
+	errIsolatedCollectdValue       = fmt.Errorf("isolatedCollectd must be a boolean")
+	errExtraDimensionsFromEnvValue = fmt.Errorf("extraDimensionsFromEnv must be a map of dimension names to environment variable names")
+	errDatapointMetaAttributes     = fmt.Errorf("datapointMetaAttributes must be a map of datapoint Meta keys to attribute names")
+	errDebugOutputValue            = fmt.Errorf("debugOutput must be a boolean")
+	errInstanceIndexesValue        = fmt.Errorf("instanceIndexes must be a boolean")
+	errMetricNamesValue            = fmt.Errorf("metricNames must be a map of Smart Agent metric names to unique metric names that aren't also renamed")
+	errCollectionTimeoutValue      = fmt.Errorf("collectionTimeoutSeconds must be a non-negative integer")
+	errCardinalityReportInterval   = fmt.Errorf("cardinalityReportIntervalSeconds must be a non-negative integer")
+	errCardinalityReportTopK       = fmt.Errorf("cardinalityReportTopK must be a non-negative integer")
+	errEventDimensionsTargetValue  = fmt.Errorf("eventDimensionsTarget must be one of record or resource")
+	errCustomQueriesValue          = fmt.Errorf("customQueries must be a list of queries with a statement and metrics")
+	errHTTPTransactionsValue       = fmt.Errorf("transactions must be a list of transactions with a name and steps")
+	errMBeanMappingsValue          = fmt.Errorf("mbeanMappings must be a list of mappings with an objectName and metrics")
+	errHAProxyRuntimeAPIValue      = fmt.Errorf("runtimeAPI must be a map with the host:port endpoint of a HAProxy runtime API socket")
+	errCorrelationValue            = fmt.Errorf("correlation must be a map with the endpoint and access_token of the SignalFx API")
+	errCollectdTypesDBValue        = fmt.Errorf("collectdTypesDB must be a list of file or directory paths")
+	errCollectdPluginConfigDirs    = fmt.Errorf("collectdPluginConfigDirs must be a list of directory paths")
+	errVSphereInventoryEventsValue = fmt.Errorf("vsphereInventoryEvents must be a boolean")
+	errVSphereTagsValue            = fmt.Errorf("vsphereTags must be a boolean")
+	errEventIngestionLatencyValue  = fmt.Errorf("eventIngestionLatency must be a boolean")
+	errLifecycleEventsValue        = fmt.Errorf("lifecycleEvents must be a boolean")
+	errTranslationRulesFileValue   = fmt.Errorf("translationRulesFile must be a file path")
+	nonWindowsMonitors             = map[string]bool{
 		"collectd/activemq": true, "collectd/apache": true, "collectd/cassandra": true, "collectd/chrony": true,
 		"collectd/cpu": true, "collectd/cpufreq": true, "collectd/custom": true, "collectd/df": true, "collectd/disk": true,
 		"collectd/genericjmx": true, "collectd/hadoopjmx": true, "collectd/kafka": true, "collectd/kafka_consumer": true,
@@ -48,6 +80,8 @@ var (
 	}
 )
 
+// Config is the smartagent receiver config, whose options are read by Unmarshal.  Options with camelCase names,
+// like the Smart Agent's, only have mapstructure tags where the collector's config checks accept them.
 type Config struct {
 	monitorConfig           saconfig.MonitorCustomConfig
 	config.ReceiverSettings `mapstructure:",squash"`
@@ -55,7 +89,105 @@ type Config struct {
 	// Will expand to MonitorCustomConfig Host and Port values if unset.
 	Endpoint         string   `mapstructure:"endpoint"`
 	DimensionClients []string `mapstructure:"dimensionClients"`
-	acceptsEndpoints bool
+	// Where event dimensions are added as attributes: the log record (default) or resource.
+	EventDimensionsTarget string `mapstructure:"-"`
+	// Whether events get an attribute with the nanoseconds between their timestamp and when they were
+	// translated, for debugging late-arriving events of monitors whose source clock is skewed.
+	EventIngestionLatency bool `mapstructure:"-"`
+	// Whether to convert well-known SFx dimensions like host and kubernetes_pod_name to their
+	// semantic convention resource attributes (host.name and k8s.pod.name).
+	TranslateDimensions bool `mapstructure:"-"`
+	// Monitor config options to set from observer endpoint values, generally receivercreator
+	// endpoint expressions like `port` or `labels["app"]`.  These take precedence over
+	// the respective monitor config options, if also provided.
+	ConfigEndpointMappings map[string]string `mapstructure:"configEndpointMappings"`
+	// The annotations of the observed pod, generally the receivercreator `pod.annotations` endpoint expression,
+	// whose io.opentelemetry.discovery.smartagent hints declare the monitor type and options.  Hinted options
+	// take precedence over the receiver's own monitor config options.
+	DiscoveryHints map[string]string `mapstructure:"discoveryHints"`
+	// The monitor config options discovery hints are allowed to set.  Hints setting any other option are
+	// rejected, and options running scripts, loading files, or setting the monitored host can't be allowed.
+	DiscoveryHintsAllowedOptions []string `mapstructure:"discoveryHintsAllowedOptions"`
+	// The maximum number of attributes and length of string attribute values of translated datapoints
+	// and events.  Exceeding content is truncated and marked with an indicator attribute.  0 disables the limit.
+	MaxAttributeCount       int `mapstructure:"-"`
+	MaxAttributeValueLength int `mapstructure:"-"`
+	// The number of seconds, in addition to intervalSeconds, the monitor can go without sending any telemetry
+	// before it's considered hung and restarted, up to 3 consecutive times.  It's also the default of the
+	// monitor's own request timeout options like timeoutSeconds and httpTimeout.  0 disables the timeout.
+	CollectionTimeoutSeconds int `mapstructure:"-"`
+	// The number of seconds between reports of the monitor's datapoint rate and the distinct values of its top
+	// dimensions, as the receiver's own metrics and a log statement, for tracing cardinality explosions to their
+	// monitor.  0 disables the reports.
+	CardinalityReportIntervalSeconds int `mapstructure:"-"`
+	// The number of dimensions with the most distinct values in each cardinality report.  0 defaults to 10.
+	CardinalityReportTopK int `mapstructure:"-"`
+	// Whether a collectd based monitor should run in its own collectd instance, with separate config
+	// files, write server, and lifecycle, instead of the one shared by all collectd based monitors.
+	IsolatedCollectd bool `mapstructure:"-"`
+	// Whether the telegraf/win_perf_counters monitor's datapoints get an instance_index dimension with a stable
+	// index for each instance of their object, with events reporting the instances appearing and disappearing.
+	InstanceIndexes bool `mapstructure:"-"`
+	// Whether the vsphere monitor reports the virtual machines and hosts appearing, disappearing, and moving
+	// to another host, cluster, or datacenter as events.
+	VSphereInventoryEvents bool `mapstructure:"-"`
+	// Whether the vsphere monitor syncs the vCenter tags of virtual machines and hosts as properties of their
+	// vm_name and esx_ip dimensions with the dimension clients, every inventoryRefreshInterval.
+	VSphereTags bool `mapstructure:"-"`
+	// Standard collector tls client settings, translated to the monitor's own TLS options like caCertPath
+	// and clientCertPath.  The monitor is restarted when the content of any referenced file changes.
+	TLS *configtls.TLSClientSetting `mapstructure:"tls"`
+	// Dimension names to the environment variables whose values are added as extra dimensions
+	// of the monitor's datapoints, resolved when the receiver is started.  The monitor's own
+	// extraDimensions take precedence, and unset or empty environment variables are omitted.
+	ExtraDimensionsFromEnv map[string]string `mapstructure:"extraDimensionsFromEnv"`
+	// Datapoint Meta keys to the attribute names their values are added as, for monitors that provide
+	// information like endpoint identity via Meta entries.  Meta entries are otherwise dropped.
+	DatapointMetaAttributes map[string]string `mapstructure:"datapointMetaAttributes"`
+	// Smart Agent metric names to the names, like OpenTelemetry semantic convention ones, translated metrics are
+	// renamed to.  The mapped names can also be used in the monitor's extraMetrics and datapointsToExclude options.
+	MetricNames map[string]string `mapstructure:"metricNames"`
+	// A YAML file with a list of signalfx exporter style translation rules, like rename_metrics, copy_metrics,
+	// multiply_int, and drop_dimensions, applied to the Smart Agent datapoints before their conversion.  It's
+	// loaded when the receiver is started, so the rules can be changed without rebuilding the collector.
+	TranslationRulesFile string `mapstructure:"-"`
+	// Whether the receiver emits entity state log records, of the monitor's type, endpoint, and config hash,
+	// when its monitor starts, stops, or fails, for tracing gaps in its metrics back to the receiver's lifecycle.
+	LifecycleEvents bool `mapstructure:"-"`
+	// Whether to also log the converted telemetry with a logging exporter, in addition to providing it
+	// to the next consumer, for troubleshooting metric naming and dimension issues.
+	DebugOutput bool `mapstructure:"-"`
+	// Secret monitor options provided as `valueFrom: {secretKeyRef: ...}` references to Kubernetes secret keys,
+	// resolved with the collector's service account when the receiver is started.  The monitor is restarted
+	// when the content of any referenced key changes.
+	SecretKeyRefs map[string]SecretKeyRef `mapstructure:"-"`
+	// User-defined SQL queries run against the database server of the postgresql, collectd/postgresql,
+	// collectd/mysql, and oracledb monitors, whose result rows are sent as datapoints of the monitor.
+	CustomQueries []CustomQuery `mapstructure:"-"`
+	// Scripted multi-step checks of the http monitor, whose steps' response times, status codes, and assertion
+	// results are sent as datapoints of the monitor, with an event for each failed run.
+	Transactions []HTTPTransaction `mapstructure:"-"`
+	// Declarative mappings of the attributes of the MBeans matching object name patterns to datapoints, with
+	// dimensions from their key properties, from which the jmx monitor's Groovy script is generated.
+	MBeanMappings []MBeanMapping `mapstructure:"-"`
+	// The HAProxy 2.x runtime API TCP socket, optionally with TLS, the haproxy monitor collects from, with events
+	// for the backend servers changing status.
+	HAProxyRuntimeAPI *HAProxyRuntimeAPI `mapstructure:"-"`
+	// The correlation of the services and environments of the signalfx-forwarder and trace-forwarder monitors'
+	// spans with the host and other infrastructure dimensions of their resources, by the SignalFx API.
+	Correlation *correlation.Config `mapstructure:"correlation"`
+	// types.db files, or directories of them, defining the types of the collectd/custom monitor's plugins in
+	// addition to the bundled ones.
+	CollectdTypesDB []string `mapstructure:"collectdTypesDB"`
+	// Directories whose *.conf collectd plugin config files are added to the collectd/custom monitor's templates.
+	CollectdPluginConfigDirs []string `mapstructure:"collectdPluginConfigDirs"`
+	// The collectd/custom monitor's own templates, before adding those of the collectd files.
+	monitorTemplates []string
+	// The jmx monitor's own groovyScript, before adding the one generated from the mbeanMappings.
+	monitorGroovyScript *string
+	translationRules    []converter.TranslationRule
+	metricNames         converter.Renames
+	acceptsEndpoints    bool
 }
 
 func (cfg *Config) validate() error {
@@ -70,6 +202,54 @@ func (cfg *Config) validate() error {
 		return fmt.Errorf("intervalSeconds must be greater than 0s (%d provided)", monitorConfigCore.IntervalSeconds)
 	}
 
+	if cfg.IsolatedCollectd && !monitorConfigCore.IsCollectdBased() {
+		return fmt.Errorf("isolatedCollectd is only supported by collectd based monitors, not %q", monitorConfigCore.Type)
+	}
+
+	if cfg.InstanceIndexes && monitorConfigCore.Type != winPerfCountersMonitorType {
+		return fmt.Errorf("instanceIndexes is only supported by the %s monitor, not %q", winPerfCountersMonitorType, monitorConfigCore.Type)
+	}
+
+	if (cfg.VSphereInventoryEvents || cfg.VSphereTags) && monitorConfigCore.Type != vsphereMonitorType {
+		return fmt.Errorf("vsphereInventoryEvents and vsphereTags are only supported by the %s monitor, not %q", vsphereMonitorType, monitorConfigCore.Type)
+	}
+
+	if _, ok := customQueryDrivers[monitorConfigCore.Type]; len(cfg.CustomQueries) != 0 && !ok {
+		return fmt.Errorf("customQueries is only supported by the postgresql, collectd/postgresql, collectd/mysql, and oracledb monitors, not %q", monitorConfigCore.Type)
+	}
+
+	if len(cfg.Transactions) != 0 && monitorConfigCore.Type != httpMonitorType {
+		return fmt.Errorf("transactions is only supported by the %s monitor, not %q", httpMonitorType, monitorConfigCore.Type)
+	}
+
+	if len(cfg.MBeanMappings) != 0 {
+		if monitorConfigCore.Type != jmxMonitorType {
+			return fmt.Errorf("mbeanMappings is only supported by the %s monitor, not %q", jmxMonitorType, monitorConfigCore.Type)
+		}
+		// set so that the monitor config validation of its required groovyScript passes
+		if err := cfg.setMBeanMappingsScript(); err != nil {
+			return err
+		}
+	}
+
+	if cfg.HAProxyRuntimeAPI != nil && monitorConfigCore.Type != haproxyMonitorType {
+		return fmt.Errorf("runtimeAPI is only supported by the %s monitor, not %q", haproxyMonitorType, monitorConfigCore.Type)
+	}
+
+	if cfg.Correlation != nil && !traceMonitorTypes[monitorConfigCore.Type] {
+		return fmt.Errorf("correlation is only supported by the signalfx-forwarder and trace-forwarder monitors, not %q", monitorConfigCore.Type)
+	}
+
+	if len(cfg.CollectdTypesDB) != 0 || len(cfg.CollectdPluginConfigDirs) != 0 {
+		if monitorConfigCore.Type != customCollectdMonitorType {
+			return fmt.Errorf("collectdTypesDB and collectdPluginConfigDirs are only supported by the %s monitor, not %q", customCollectdMonitorType, monitorConfigCore.Type)
+		}
+		// loaded so that the monitor config validation includes their templates
+		if err := cfg.setCustomCollectdTemplates(); err != nil {
+			return fmt.Errorf("failed loading custom collectd files: %w", err)
+		}
+	}
+
 	if err := validation.ValidateStruct(cfg.monitorConfig); err != nil {
 		return err
 	}
@@ -83,23 +263,201 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 	// form the final desired version. To do so, we manually obtain all Config items, leaving only Smart Agent
 	// monitor config settings to be unmarshalled to their respective custom monitor config types.
 	allSettings := componentParser.ToStringMap()
+	if endpoint, ok := allSettings["endpoint"]; ok {
+		cfg.Endpoint = fmt.Sprintf("%s", endpoint)
+		delete(allSettings, "endpoint")
+	}
+
+	var err error
+	cfg.DiscoveryHints, err = getDiscoveryHintsFromAllSettings(allSettings)
+	if err != nil {
+		return err
+	}
+	hints, err := resolveDiscoveryHints(cfg.DiscoveryHints, cfg.Endpoint)
+	if err != nil {
+		return err
+	}
+	cfg.DiscoveryHintsAllowedOptions, err = getStringSliceFromAllSettings(
+		allSettings, "discoveryHintsAllowedOptions", errDiscoveryHintsAllowedOptionsValue,
+	)
+	if err != nil {
+		return err
+	}
+	if err = validateDiscoveryHintsAllowedOptions(cfg.DiscoveryHintsAllowedOptions); err != nil {
+		return err
+	}
+	if hints.monitorType != "" {
+		allSettings["type"] = hints.monitorType
+	}
+
 	monitorType, ok := allSettings["type"].(string)
 	if !ok || monitorType == "" {
 		return fmt.Errorf("you must specify a \"type\" for a smartagent receiver")
 	}
 
-	var endpoint any
-	if endpoint, ok = allSettings["endpoint"]; ok {
-		cfg.Endpoint = fmt.Sprintf("%s", endpoint)
-		delete(allSettings, "endpoint")
+	cfg.DimensionClients, err = getStringSliceFromAllSettings(allSettings, "dimensionClients", errDimensionClientValue)
+	if err != nil {
+		return err
 	}
 
-	var err error
-	cfg.DimensionClients, err = getStringSliceFromAllSettings(allSettings, "dimensionClients", errDimensionClientValue)
+	cfg.TranslateDimensions, err = getBoolFromAllSettings(allSettings, "translateDimensions", errTranslateDimensionsValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.EventDimensionsTarget, err = getStringFromAllSettings(allSettings, "eventDimensionsTarget", errEventDimensionsTargetValue)
+	if err != nil {
+		return err
+	}
+	switch converter.EventDimensionsTarget(cfg.EventDimensionsTarget) {
+	case "", converter.EventDimensionsToRecord, converter.EventDimensionsToResource:
+	default:
+		return errEventDimensionsTargetValue
+	}
+
+	cfg.EventIngestionLatency, err = getBoolFromAllSettings(allSettings, "eventIngestionLatency", errEventIngestionLatencyValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.IsolatedCollectd, err = getBoolFromAllSettings(allSettings, "isolatedCollectd", errIsolatedCollectdValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.DebugOutput, err = getBoolFromAllSettings(allSettings, "debugOutput", errDebugOutputValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.LifecycleEvents, err = getBoolFromAllSettings(allSettings, "lifecycleEvents", errLifecycleEventsValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.InstanceIndexes, err = getBoolFromAllSettings(allSettings, "instanceIndexes", errInstanceIndexesValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.VSphereInventoryEvents, err = getBoolFromAllSettings(allSettings, "vsphereInventoryEvents", errVSphereInventoryEventsValue)
 	if err != nil {
 		return err
 	}
 
+	cfg.VSphereTags, err = getBoolFromAllSettings(allSettings, "vsphereTags", errVSphereTagsValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.MaxAttributeCount, err = getNonNegativeIntFromAllSettings(allSettings, "maxAttributeCount", errMaxAttributeCountValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.MaxAttributeValueLength, err = getNonNegativeIntFromAllSettings(allSettings, "maxAttributeValueLength", errMaxAttributeValueLength)
+	if err != nil {
+		return err
+	}
+
+	cfg.CollectionTimeoutSeconds, err = getNonNegativeIntFromAllSettings(allSettings, "collectionTimeoutSeconds", errCollectionTimeoutValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.CardinalityReportIntervalSeconds, err = getNonNegativeIntFromAllSettings(allSettings, "cardinalityReportIntervalSeconds", errCardinalityReportInterval)
+	if err != nil {
+		return err
+	}
+
+	cfg.CardinalityReportTopK, err = getNonNegativeIntFromAllSettings(allSettings, "cardinalityReportTopK", errCardinalityReportTopK)
+	if err != nil {
+		return err
+	}
+
+	cfg.CustomQueries, err = getCustomQueriesFromAllSettings(allSettings)
+	if err != nil {
+		return err
+	}
+
+	cfg.Transactions, err = getHTTPTransactionsFromAllSettings(allSettings)
+	if err != nil {
+		return err
+	}
+
+	cfg.MBeanMappings, err = getMBeanMappingsFromAllSettings(allSettings)
+	if err != nil {
+		return err
+	}
+
+	cfg.HAProxyRuntimeAPI, err = getHAProxyRuntimeAPIFromAllSettings(allSettings)
+	if err != nil {
+		return err
+	}
+
+	cfg.Correlation, err = getCorrelationFromAllSettings(allSettings)
+	if err != nil {
+		return err
+	}
+
+	cfg.CollectdTypesDB, err = getStringSliceFromAllSettings(allSettings, "collectdTypesDB", errCollectdTypesDBValue)
+	if err != nil {
+		return err
+	}
+	cfg.CollectdPluginConfigDirs, err = getStringSliceFromAllSettings(allSettings, "collectdPluginConfigDirs", errCollectdPluginConfigDirs)
+	if err != nil {
+		return err
+	}
+	for _, path := range cfg.CollectdTypesDB {
+		if path == "" {
+			return errCollectdTypesDBValue
+		}
+	}
+	for _, dir := range cfg.CollectdPluginConfigDirs {
+		if dir == "" {
+			return errCollectdPluginConfigDirs
+		}
+	}
+
+	cfg.TranslationRulesFile, err = getStringFromAllSettings(allSettings, "translationRulesFile", errTranslationRulesFileValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.ConfigEndpointMappings, err = getScalarMapFromAllSettings(allSettings, "configEndpointMappings", errConfigEndpointMappingsValue)
+	if err != nil {
+		return err
+	}
+
+	cfg.ExtraDimensionsFromEnv, err = getScalarMapFromAllSettings(allSettings, "extraDimensionsFromEnv", errExtraDimensionsFromEnvValue)
+	if err != nil {
+		return err
+	}
+	for dimension, envVar := range cfg.ExtraDimensionsFromEnv {
+		if dimension == "" || envVar == "" {
+			return errExtraDimensionsFromEnvValue
+		}
+	}
+
+	cfg.DatapointMetaAttributes, err = getScalarMapFromAllSettings(allSettings, "datapointMetaAttributes", errDatapointMetaAttributes)
+	if err != nil {
+		return err
+	}
+	for key, attribute := range cfg.DatapointMetaAttributes {
+		if key == "" || attribute == "" {
+			return errDatapointMetaAttributes
+		}
+	}
+
+	cfg.MetricNames, err = getScalarMapFromAllSettings(allSettings, "metricNames", errMetricNamesValue)
+	if err != nil {
+		return err
+	}
+	if cfg.metricNames, err = newMetricNames(cfg.MetricNames); err != nil {
+		return err
+	}
+	applyMetricNamesToFilters(cfg.metricNames, allSettings)
+
 	// monitors.ConfigTemplates is a map that all monitors use to register their custom configs in the Smart Agent.
 	// The values are always pointers to an actual custom config.
 	var customMonitorConfig saconfig.MonitorCustomConfig
@@ -112,6 +470,31 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 	monitorConfigType := reflect.TypeOf(customMonitorConfig).Elem()
 	monitorConfig := reflect.New(monitorConfigType).Interface()
 
+	if err = applyConfigEndpointMappings(cfg.ConfigEndpointMappings, monitorConfigType, allSettings); err != nil {
+		return err
+	}
+	if err = applyDiscoveryHintOptions(hints.options, cfg.DiscoveryHintsAllowedOptions, monitorConfigType, allSettings); err != nil {
+		return fmt.Errorf("invalid discovery hints for monitor type %q: %w", monitorType, err)
+	}
+
+	// monitors with their own tls option are left to unmarshal it themselves
+	if !monitorConfigOptions(monitorConfigType)["tls"] {
+		if cfg.TLS, err = getTLSSettingFromAllSettings(allSettings); err != nil {
+			return err
+		}
+		if cfg.TLS != nil {
+			if err = applyTLSSetting(*cfg.TLS, monitorConfigType, allSettings); err != nil {
+				return fmt.Errorf("invalid tls settings for monitor type %q: %w", monitorType, err)
+			}
+		}
+	}
+
+	applyCollectionTimeout(cfg.CollectionTimeoutSeconds, monitorConfigType, allSettings)
+
+	if cfg.SecretKeyRefs, err = getSecretKeyRefsFromAllSettings(allSettings, monitorConfigType); err != nil {
+		return fmt.Errorf("invalid secret reference for monitor type %q: %w", monitorType, err)
+	}
+
 	asBytes, err := yaml.Marshal(allSettings)
 	if err != nil {
 		return fmt.Errorf("failed constructing raw Smart Agent Monitor config block: %w", err)
@@ -137,6 +520,8 @@ func (cfg *Config) Unmarshal(componentParser *confmap.Conf) error {
 		if err != nil {
 			return err
 		}
+	} else if hints.monitorType != "" {
+		return fmt.Errorf("discovery hints can only declare monitor types that accept endpoints, not %q", monitorType)
 	}
 
 	cfg.monitorConfig = monitorConfig.(saconfig.MonitorCustomConfig)
@@ -163,6 +548,107 @@ func getStringSliceFromAllSettings(allSettings map[string]any, key string, errTo
 	return items, nil
 }
 
+func getBoolFromAllSettings(allSettings map[string]any, key string, errToReturn error) (bool, error) {
+	value, ok := allSettings[key]
+	if !ok {
+		return false, nil
+	}
+	delete(allSettings, key)
+	switch v := value.(type) {
+	case bool:
+		return v, nil
+	case string:
+		// env var and config source expansion results are strings
+		b, err := strconv.ParseBool(v)
+		if err != nil {
+			return false, errToReturn
+		}
+		return b, nil
+	}
+	return false, errToReturn
+}
+
+func getStringFromAllSettings(allSettings map[string]any, key string, errToReturn error) (string, error) {
+	value, ok := allSettings[key]
+	if !ok {
+		return "", nil
+	}
+	delete(allSettings, key)
+	s, isString := value.(string)
+	if !isString {
+		return "", errToReturn
+	}
+	return s, nil
+}
+
+func getNonNegativeIntFromAllSettings(allSettings map[string]any, key string, errToReturn error) (int, error) {
+	value, ok := allSettings[key]
+	if !ok {
+		return 0, nil
+	}
+	delete(allSettings, key)
+	var i int
+	switch v := value.(type) {
+	case int:
+		i = v
+	case string:
+		// env var and config source expansion results are strings
+		var err error
+		if i, err = strconv.Atoi(v); err != nil {
+			return 0, errToReturn
+		}
+	default:
+		return 0, errToReturn
+	}
+	if i < 0 {
+		return 0, errToReturn
+	}
+	return i, nil
+}
+
+func getScalarMapFromAllSettings(allSettings map[string]any, key string, errToReturn error) (map[string]string, error) {
+	value, ok := allSettings[key]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, key)
+	valueAsMap, isMap := value.(map[string]any)
+	if !isMap {
+		return nil, errToReturn
+	}
+	items := make(map[string]string, len(valueAsMap))
+	for k, v := range valueAsMap {
+		switch v.(type) {
+		case map[string]any, []any, nil:
+			return nil, errToReturn
+		}
+		// receivercreator endpoint expressions can evaluate to non-string values like ports
+		items[k] = fmt.Sprintf("%v", v)
+	}
+	return items, nil
+}
+
+// applyConfigEndpointMappings sets the mapped monitor config options to their endpoint-derived values, converted
+// only to the boolean and numeric types of their fields so that values of string options, like "0123" or "yes",
+// are kept as is.
+func applyConfigEndpointMappings(mappings map[string]string, monitorConfigType reflect.Type, allSettings map[string]any) error {
+	fields := monitorConfigFields(monitorConfigType)
+	for option, value := range mappings {
+		if option == "" || option == "type" {
+			return fmt.Errorf("configEndpointMappings cannot set the %q option", option)
+		}
+		var mapped any = value
+		if field, ok := fields[option]; ok {
+			var err error
+			if mapped, err = scalarOptionValue(field, value); err != nil {
+				return fmt.Errorf("invalid configEndpointMappings value for the %q option: %w", option, err)
+			}
+		}
+		allSettings[option] = mapped
+	}
+	return nil
+}
+
 // If using the receivercreator, observer-provided endpoints should be used to set
 // the Host and Port fields of monitor config structs.  This can only be done by reflection without
 // making type assertions over all possible monitor types.
@@ -15,7 +15,10 @@
 package smartagentreceiver
 
 import (
+	"fmt"
 	"path"
+	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -40,6 +43,7 @@ import (
 	"github.com/stretchr/testify/require"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/service/servicetest"
 )
 
@@ -231,7 +235,6 @@ func TestLoadInvalidConfigs(t *testing.T) {
 		monitorConfig: &consul.Config{
 			MonitorConfig: saconfig.MonitorConfig{
 				Type:                "collectd/consul",
-				IntervalSeconds:     0,
 				DatapointsToExclude: []saconfig.MetricFilter{},
 			},
 			Port:          5309,
@@ -540,3 +543,885 @@ func TestLoadConfigWithNestedMonitorConfig(t *testing.T) {
 	}, k8sVolumesCfg)
 	require.NoError(t, k8sVolumesCfg.validate())
 }
+
+func TestLoadConfigWithTranslateDimensions(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "translate_dimensions.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	cpuCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "cpu")].(*Config)
+	assert.True(t, cpuCfg.TranslateDimensions)
+	require.NoError(t, cpuCfg.validate())
+
+	memoryCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "memory")].(*Config)
+	assert.False(t, memoryCfg.TranslateDimensions)
+	require.NoError(t, memoryCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNonBoolTranslateDimensions(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_translate_dimensions.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/cpu": translateDimensions must be a boolean`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithConfigEndpointMappings(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "config_endpoint_mappings.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	require.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "redis")),
+		Endpoint:         "redishost:6379",
+		ConfigEndpointMappings: map[string]string{
+			"name": "redis-primary",
+			"port": "7379",
+			"auth": "1234",
+		},
+		monitorConfig: &redis.Config{
+			MonitorConfig: saconfig.MonitorConfig{
+				Type:                "collectd/redis",
+				DatapointsToExclude: []saconfig.MetricFilter{},
+			},
+			Host: "redishost",
+			Port: 7379,
+			Name: "redis-primary",
+			Auth: "1234",
+		},
+		acceptsEndpoints: true,
+	}, redisCfg)
+	require.NoError(t, redisCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNonMapConfigEndpointMappings(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_config_endpoint_mappings.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/redis": configEndpointMappings must be a map of monitor config options to scalar values`)
+	require.Nil(t, cfg)
+}
+
+// TestMappedInlined is exported since yaml only inlines exported embedded structs.
+type TestMappedInlined struct {
+	UseTLS bool `yaml:"useTLS"`
+}
+
+type testMappedConfig struct {
+	TestMappedInlined `yaml:",inline"`
+	Host              string            `yaml:"host"`
+	Port              uint16            `yaml:"port"`
+	Threshold         float64           `yaml:"threshold"`
+	Password          string            `yaml:"password"`
+	Timeout           timeutil.Duration `yaml:"timeout"`
+}
+
+func TestApplyConfigEndpointMappings(t *testing.T) {
+	configType := reflect.TypeOf(testMappedConfig{})
+	allSettings := map[string]any{"port": 1234, "host": "unmapped"}
+	require.NoError(t, applyConfigEndpointMappings(map[string]string{
+		"port":      "5678",
+		"host":      "mapped",
+		"useTLS":    "true",
+		"threshold": "0.5",
+		"password":  "0123",
+		"timeout":   "10s",
+		"unknown":   "true",
+	}, configType, allSettings))
+	assert.Equal(t, map[string]any{
+		"port":      uint64(5678),
+		"host":      "mapped",
+		"useTLS":    true,
+		"threshold": 0.5,
+		"password":  "0123",
+		"timeout":   "10s",
+		"unknown":   "true",
+	}, allSettings)
+
+	err := applyConfigEndpointMappings(map[string]string{"type": "collectd/redis"}, configType, map[string]any{})
+	require.EqualError(t, err, `configEndpointMappings cannot set the "type" option`)
+
+	err = applyConfigEndpointMappings(map[string]string{"port": "http"}, configType, map[string]any{})
+	require.EqualError(t, err, `invalid configEndpointMappings value for the "port" option: "http" isn't a valid uint16`)
+}
+
+func TestLoadConfigWithAttributeLimits(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "attribute_limits.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	cpuCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "cpu")].(*Config)
+	assert.Equal(t, 32, cpuCfg.MaxAttributeCount)
+	assert.Equal(t, 256, cpuCfg.MaxAttributeValueLength)
+	require.NoError(t, cpuCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNegativeAttributeLimit(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_attribute_limits.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/cpu": maxAttributeValueLength must be a non-negative integer`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithCollectionTimeout(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "collection_timeout.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	etcdCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "etcd")].(*Config)
+	assert.Equal(t, 5, etcdCfg.CollectionTimeoutSeconds)
+	assert.Equal(t, timeutil.Duration(5*time.Second), etcdCfg.monitorConfig.(*prometheusexporter.Config).HTTPTimeout)
+	require.NoError(t, etcdCfg.validate())
+
+	explicitCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "etcd_with_http_timeout")].(*Config)
+	assert.Equal(t, 5, explicitCfg.CollectionTimeoutSeconds)
+	assert.Equal(t, timeutil.Duration(20*time.Second), explicitCfg.monitorConfig.(*prometheusexporter.Config).HTTPTimeout)
+	require.NoError(t, explicitCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNegativeCollectionTimeout(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_collection_timeout.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/etcd": collectionTimeoutSeconds must be a non-negative integer`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithCardinalityReport(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "cardinality_report.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	cpuCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "cpu")].(*Config)
+	assert.Equal(t, 60, cpuCfg.CardinalityReportIntervalSeconds)
+	assert.Equal(t, 0, cpuCfg.CardinalityReportTopK)
+	require.NoError(t, cpuCfg.validate())
+
+	memoryCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "memory")].(*Config)
+	assert.Equal(t, 300, memoryCfg.CardinalityReportIntervalSeconds)
+	assert.Equal(t, 3, memoryCfg.CardinalityReportTopK)
+	require.NoError(t, memoryCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNegativeCardinalityReportTopK(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_cardinality_report.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/etcd": cardinalityReportTopK must be a non-negative integer`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithEventDimensionsTarget(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "event_dimensions_target.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	processlistCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "processlist")].(*Config)
+	assert.Equal(t, "resource", processlistCfg.EventDimensionsTarget)
+	assert.True(t, processlistCfg.EventIngestionLatency)
+	require.NoError(t, processlistCfg.validate())
+}
+
+func TestLoadInvalidConfigWithUnknownEventDimensionsTarget(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_event_dimensions_target.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/processlist": eventDimensionsTarget must be one of record or resource`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithExtraDimensionsFromEnv(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "extra_dimensions_from_env.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	assert.Equal(t, map[string]string{
+		"app":          "APP_NAME",
+		"k8s_pod_name": "POD_NAME",
+	}, redisCfg.ExtraDimensionsFromEnv)
+	assert.Equal(t, map[string]string{"app": "redis"}, redisCfg.monitorConfig.MonitorConfigCore().ExtraDimensions)
+	require.NoError(t, redisCfg.validate())
+}
+
+func TestLoadInvalidConfigWithEmptyExtraDimensionsFromEnv(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_extra_dimensions_from_env.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/redis": extraDimensionsFromEnv must be a map of dimension names to environment variable names`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithDatapointMetaAttributes(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "datapoint_meta_attributes.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	assert.Equal(t, map[string]string{"endpoint_id": "sfx.endpoint.id"}, redisCfg.DatapointMetaAttributes)
+	require.NoError(t, redisCfg.validate())
+}
+
+func TestLoadConfigWithMetricNames(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "metric_names.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	assert.Equal(t, map[string]string{
+		"bytes.used_memory":          "redis.memory.used",
+		"counter.commands_processed": "redis.commands.processed",
+	}, redisCfg.MetricNames)
+
+	monitorConfig := redisCfg.monitorConfig.MonitorConfigCore()
+	assert.Equal(t, []string{"counter.commands_processed"}, monitorConfig.ExtraMetrics)
+	require.Len(t, monitorConfig.DatapointsToExclude, 1)
+	assert.Equal(t, []string{"bytes.used_memory", "gauge.connected_clients"}, monitorConfig.DatapointsToExclude[0].MetricNames)
+	require.NoError(t, redisCfg.validate())
+}
+
+func TestLoadInvalidConfigWithDuplicateMetricNames(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_metric_names.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/redis": metricNames must be a map of Smart Agent metric names to unique metric names that aren't also renamed`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithIsolatedCollectd(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "isolated_collectd.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	assert.True(t, redisCfg.IsolatedCollectd)
+	require.NoError(t, redisCfg.validate())
+}
+
+func TestLoadConfigWithCustomCollectdFiles(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "custom_collectd.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	customCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "custom")].(*Config)
+	assert.Equal(t, []string{"./testdata/collectd/types.db"}, customCfg.CollectdTypesDB)
+	assert.Equal(t, []string{"./testdata/collectd/conf.d"}, customCfg.CollectdPluginConfigDirs)
+	require.NoError(t, customCfg.validate())
+
+	typesDB, err := filepath.Abs(filepath.Join("testdata", "collectd", "types.db"))
+	require.NoError(t, err)
+	templates, err := GetSettableStructFieldValue(customCfg.monitorConfig, "Templates", reflect.TypeOf([]string(nil)))
+	require.NoError(t, err)
+	require.NotNil(t, templates)
+	assert.Equal(t, []string{
+		fmt.Sprintf("TypesDB %q\n", typesDB),
+		"LoadPlugin exec\n<Plugin exec>\n  Exec \"nobody\" \"/opt/myapp/bin/collectd-myapp\"\n</Plugin>\n",
+	}, templates.Interface())
+
+	// validating again doesn't add the templates twice
+	require.NoError(t, customCfg.validate())
+	assert.Len(t, templates.Interface(), 2)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	require.EqualError(t, redisCfg.validate(),
+		`collectdTypesDB and collectdPluginConfigDirs are only supported by the collectd/custom monitor, not "collectd/redis"`)
+
+	missingCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "missing")].(*Config)
+	err = missingCfg.validate()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "failed loading custom collectd files: failed reading collectd plugin config dir")
+}
+
+func TestLoadConfigWithTranslationRulesFile(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "translation_rules.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	memoryCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "memory")].(*Config)
+	assert.Equal(t, "./testdata/translation_rules/rules.yaml", memoryCfg.TranslationRulesFile)
+	// the rules are loaded when the receiver is started
+	require.NoError(t, memoryCfg.validate())
+	assert.Nil(t, memoryCfg.translationRules)
+
+	invalidCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "invalid")].(*Config)
+	assert.Equal(t, "./testdata/translation_rules/invalid_rules.yaml", invalidCfg.TranslationRulesFile)
+	require.NoError(t, invalidCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNonStringTranslationRulesFile(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_translation_rules.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/memory": translationRulesFile must be a file path`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithVSphereOptions(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "vsphere.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	vsphereCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "vsphere")].(*Config)
+	assert.True(t, vsphereCfg.VSphereInventoryEvents)
+	assert.True(t, vsphereCfg.VSphereTags)
+	require.NoError(t, vsphereCfg.validate())
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	require.EqualError(t, redisCfg.validate(),
+		`vsphereInventoryEvents and vsphereTags are only supported by the vsphere monitor, not "collectd/redis"`)
+}
+
+func TestLoadInvalidConfigWithNonBoolVSphereTags(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_vsphere.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/vsphere": vsphereTags must be a boolean`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithDebugOutput(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "debug_output.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	cpuCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "cpu")].(*Config)
+	assert.True(t, cpuCfg.DebugOutput)
+	require.NoError(t, cpuCfg.validate())
+
+	memoryCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "memory")].(*Config)
+	assert.False(t, memoryCfg.DebugOutput)
+	require.NoError(t, memoryCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNonBoolDebugOutput(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_debug_output.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/cpu": debugOutput must be a boolean`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithTLS(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "tls_config.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	etcdCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "etcd")].(*Config)
+	require.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "etcd")),
+		TLS: &configtls.TLSClientSetting{
+			TLSSetting: configtls.TLSSetting{
+				CAFile:   "/etc/ssl/etcd/ca.pem",
+				CertFile: "/etc/ssl/etcd/client.pem",
+				KeyFile:  "/etc/ssl/etcd/client-key.pem",
+			},
+			InsecureSkipVerify: true,
+		},
+		monitorConfig: &prometheusexporter.Config{
+			MonitorConfig: saconfig.MonitorConfig{
+				Type:                "etcd",
+				DatapointsToExclude: []saconfig.MetricFilter{},
+			},
+			HTTPConfig: httpclient.HTTPConfig{
+				HTTPTimeout:    timeutil.Duration(10 * time.Second),
+				UseHTTPS:       true,
+				SkipVerify:     true,
+				CACertPath:     "/etc/ssl/etcd/ca.pem",
+				ClientCertPath: "/etc/ssl/etcd/client.pem",
+				ClientKeyPath:  "/etc/ssl/etcd/client-key.pem",
+			},
+			Host:       "localhost",
+			Port:       2379,
+			MetricPath: "/metrics",
+		},
+		acceptsEndpoints: true,
+	}, etcdCfg)
+	require.NoError(t, etcdCfg.validate())
+}
+
+func TestLoadInvalidConfigWithUnsupportedTLS(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_tls_config.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/redis": invalid tls settings for monitor type "collectd/redis": the tls "ca_file" setting isn't supported by this monitor type`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithSecretKeyRefs(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "secret_key_refs.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	require.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "redis")),
+		SecretKeyRefs: map[string]SecretKeyRef{
+			"auth": {Name: "redis", Key: "password", Namespace: "databases"},
+		},
+		monitorConfig: &redis.Config{
+			MonitorConfig: saconfig.MonitorConfig{
+				Type:                "collectd/redis",
+				DatapointsToExclude: []saconfig.MetricFilter{},
+			},
+			Host: "localhost",
+			Port: 6379,
+		},
+		acceptsEndpoints: true,
+	}, redisCfg)
+	require.NoError(t, redisCfg.validate())
+}
+
+func TestLoadInvalidConfigWithNonSecretKeyRef(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_secret_key_refs.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/redis": invalid secret reference for monitor type "collectd/redis": host: valueFrom is only supported by secret string options of this monitor type`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithCustomQueries(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "custom_queries.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	postgresqlCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "postgresql")].(*Config)
+	require.NoError(t, postgresqlCfg.validate())
+	require.Len(t, postgresqlCfg.CustomQueries, 2)
+
+	tablesQuery := postgresqlCfg.CustomQueries[0]
+	assert.Equal(t, "SELECT relname, n_live_tup, n_tup_ins FROM pg_stat_user_tables WHERE schemaname = $1", tablesQuery.Statement)
+	assert.Equal(t, []any{"public"}, tablesQuery.Params)
+	assert.Equal(t, "app", tablesQuery.Database)
+	assert.Equal(t, 30, tablesQuery.IntervalSeconds)
+	assert.Equal(t, 5, tablesQuery.TimeoutSeconds)
+	assert.Equal(t, 100, tablesQuery.MaxRows)
+	require.Len(t, tablesQuery.Metrics, 2)
+	assert.Equal(t, "postgres.table.{{.relname}}.live_rows", tablesQuery.Metrics[0].MetricName)
+	assert.NotNil(t, tablesQuery.Metrics[0].nameTemplate)
+	assert.Equal(t, "n_live_tup", tablesQuery.Metrics[0].ValueColumn)
+	assert.Equal(t, []string{"relname"}, tablesQuery.Metrics[1].DimensionColumns)
+	assert.Nil(t, tablesQuery.Metrics[1].nameTemplate)
+	assert.True(t, tablesQuery.Metrics[1].IsCumulative)
+
+	driverName, dsn, err := customQueryDataSource(postgresqlCfg.monitorConfig, tablesQuery.Database)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", driverName)
+	assert.Equal(t, "sslmode=disable user=monitor password=s3cr3t host='localhost' port='5432' dbname='app'", dsn)
+
+	driverName, dsn, err = customQueryDataSource(postgresqlCfg.monitorConfig, postgresqlCfg.CustomQueries[1].Database)
+	require.NoError(t, err)
+	assert.Equal(t, "postgres", driverName)
+	assert.Equal(t, "sslmode=disable user=monitor password=s3cr3t host='localhost' port='5432' dbname='postgres'", dsn)
+}
+
+func TestLoadInvalidConfigWithCustomQueries(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_custom_queries.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/postgresql": customQueries[0].metrics[0]: valueColumn must not be empty`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithHTTPTransactions(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "http_transactions.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	httpCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "http")].(*Config)
+	require.NoError(t, httpCfg.validate())
+	require.Len(t, httpCfg.Transactions, 1)
+
+	transaction := httpCfg.Transactions[0]
+	assert.Equal(t, "checkout", transaction.Name)
+	assert.Equal(t, 60, transaction.IntervalSeconds)
+	assert.Equal(t, 20, transaction.TimeoutSeconds)
+	require.Len(t, transaction.Steps, 2)
+
+	login := transaction.Steps[0]
+	assert.Equal(t, "login", login.Name)
+	assert.Equal(t, "POST", login.Method)
+	assert.Equal(t, "/api/login", login.URL)
+	assert.Equal(t, map[string]string{"Content-Type": "application/json"}, login.Headers)
+	assert.Equal(t, `{"user": "synthetic", "password": "s3cr3t"}`, login.Body)
+	assert.Equal(t, "data.token", login.Extract["token"].JSONPath)
+	assert.Equal(t, []int{200}, login.Assertions.StatusCodes)
+	assert.Nil(t, login.urlTemplate)
+
+	cart := transaction.Steps[1]
+	assert.Equal(t, "step2", cart.Name)
+	assert.Equal(t, "GET", cart.Method)
+	assert.NotNil(t, cart.urlTemplate)
+	assert.NotNil(t, cart.headerTemplates["Authorization"])
+	assert.Equal(t, "items", cart.Assertions.BodyContains)
+	assert.Equal(t, 500, cart.Assertions.MaxLatencyMs)
+
+	assert.Equal(t, "https://shop.example.com:8443", httpTransactionBaseURL(httpCfg.monitorConfig).String())
+}
+
+func TestLoadInvalidConfigWithHTTPTransactions(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_http_transactions.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/http": transactions[0].steps[0]: extract::token: exactly one of header, jsonPath, and regex must be set`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithMBeanMappings(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "mbean_mappings.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	jmxCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "jmx")].(*Config)
+	assert.Equal(t, []MBeanMapping{
+		{
+			ObjectName: "com.example:type=Cache,*",
+			Dimensions: map[string]string{"cache": "name"},
+			Metrics: []MBeanMetric{
+				{Attribute: "HitCount", MetricName: "cache.hits", IsCumulative: true},
+				{Attribute: "Size", MetricName: "cache.size"},
+			},
+		},
+	}, jmxCfg.MBeanMappings)
+	require.NoError(t, jmxCfg.validate())
+	script, err := GetSettableStructFieldValue(jmxCfg.monitorConfig, "GroovyScript", reflect.TypeOf(""))
+	require.NoError(t, err)
+	require.NotNil(t, script)
+	assert.Equal(t, mbeanMappingsScript(jmxCfg.MBeanMappings), script.String())
+
+	withScriptCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "jmx_with_script")].(*Config)
+	require.NoError(t, withScriptCfg.validate())
+	// validating again doesn't add the generated script twice
+	require.NoError(t, withScriptCfg.validate())
+	script, err = GetSettableStructFieldValue(withScriptCfg.monitorConfig, "GroovyScript", reflect.TypeOf(""))
+	require.NoError(t, err)
+	require.NotNil(t, script)
+	assert.Equal(t,
+		"output.sendDatapoint(util.makeGauge(\"app.up\", 1, [:]))\n\n"+mbeanMappingsScript(withScriptCfg.MBeanMappings),
+		script.String())
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	require.EqualError(t, redisCfg.validate(), `mbeanMappings is only supported by the jmx monitor, not "collectd/redis"`)
+}
+
+func TestLoadInvalidConfigWithMBeanMappings(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_mbean_mappings.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/jmx": mbeanMappings[0].metrics[0]: metricName must not be empty`)
+	require.Nil(t, cfg)
+}
+
+func TestLoadConfigWithHAProxyRuntimeAPI(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "haproxy_runtime_api.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	haproxyCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "haproxy")].(*Config)
+	assert.Equal(t, &HAProxyRuntimeAPI{
+		Endpoint: "haproxy:9999",
+		TLS: &configtls.TLSClientSetting{
+			TLSSetting: configtls.TLSSetting{
+				CAFile:   "/etc/haproxy/ca.pem",
+				CertFile: "/etc/otel/haproxy-client.pem",
+				KeyFile:  "/etc/otel/haproxy-client.key",
+			},
+		},
+	}, haproxyCfg.HAProxyRuntimeAPI)
+	require.NoError(t, haproxyCfg.validate())
+
+	plainCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "haproxy_plain")].(*Config)
+	assert.Equal(t, &HAProxyRuntimeAPI{Endpoint: "127.0.0.1:9999", DisableServerStateEvents: true}, plainCfg.HAProxyRuntimeAPI)
+	require.NoError(t, plainCfg.validate())
+
+	redisCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "redis")].(*Config)
+	require.EqualError(t, redisCfg.validate(), `runtimeAPI is only supported by the haproxy monitor, not "collectd/redis"`)
+}
+
+func TestLoadInvalidConfigWithHAProxyRuntimeAPI(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_haproxy_runtime_api.yaml"), factories,
+	)
+	require.Error(t, err)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/haproxy": runtimeAPI conflicts with the monitor's "url" option`)
+	require.Nil(t, cfg)
+}
@@ -0,0 +1,260 @@
+// Copyright OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"testing"
+
+	sfx "github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/signalfx/golib/v3/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// The allocation budgets are the maximum allocations of translating each datapoint, event, and span of the
+// benchmark content.  They leave headroom above the current cost so that only changes adding allocations for
+// each of them, like copying their attributes or formatting keys, fail TestTranslatorAllocationBudgets.  Lower
+// them along with changes that reduce the cost instead of raising them to accommodate regressions.
+const (
+	datapointAllocsBudget           = 25
+	translatedDatapointAllocsBudget = 40
+	eventAllocsBudget               = 50
+	spanAllocsBudget                = 250
+)
+
+var benchmarkTranslators = []struct {
+	name       string
+	translator Translator
+}{
+	{name: "default", translator: NewTranslator(zap.NewNop())},
+	{name: "dimension translation", translator: NewTranslator(zap.NewNop(), WithDimensionTranslation())},
+	{name: "attribute limits", translator: NewTranslator(zap.NewNop(), WithAttributeLimits(AttributeLimits{MaxCount: 4, MaxValueLength: 8}))},
+	{name: "sorted attributes", translator: NewTranslator(zap.NewNop(), WithSortedAttributes())},
+	{
+		name: "all options",
+		translator: NewTranslator(zap.NewNop(),
+			WithDimensionTranslation(),
+			WithAttributeLimits(AttributeLimits{MaxCount: 4, MaxValueLength: 8}),
+			WithDatapointMetaAttributes(map[string]string{"source": "source"}),
+			WithMetricNames(mustNewRenames(map[string]string{"cpu.utilization": "system.cpu.utilization"})),
+			WithEventDimensionsTarget(EventDimensionsToResource),
+			WithSortedAttributes(),
+			WithEventIngestionLatency(),
+		),
+	},
+}
+
+// benchmarkDatapoints returns n datapoints of each metric and value type with the dimensions of a typical
+// Kubernetes monitor, sharing their resource dimensions.
+func benchmarkDatapoints(n int) []*sfx.Datapoint {
+	metricTypes := []sfx.MetricType{sfx.Gauge, sfx.Counter, sfx.Count}
+	datapoints := make([]*sfx.Datapoint, 0, n)
+	for i := 0; i < n; i++ {
+		var value sfx.Value = sfx.NewIntValue(int64(i))
+		if i%2 == 1 {
+			value = sfx.NewFloatValue(float64(i) / 3)
+		}
+		datapoints = append(datapoints, &sfx.Datapoint{
+			Metric:     "cpu.utilization",
+			Timestamp:  now,
+			Value:      value,
+			MetricType: metricTypes[i%len(metricTypes)],
+			Dimensions: map[string]string{
+				"host":                "node-1.example.com",
+				"kubernetes_pod_name": "redis-6b8f7c9d4-x2x7p",
+				"plugin":              "redis",
+				"plugin_instance":     "redis-6b8f7c9d4-x2x7p:6379",
+				"dsname":              fmt.Sprintf("value-%d", i%10),
+			},
+			Meta: map[any]any{"source": "monitor"},
+		})
+	}
+	return datapoints
+}
+
+func benchmarkEvent() *event.Event {
+	return &event.Event{
+		EventType: "nvidia.gpu.xid_error",
+		Category:  event.AGENT,
+		Timestamp: now,
+		Dimensions: map[string]string{
+			"host":                "node-1.example.com",
+			"kubernetes_pod_name": "dcgm-exporter-4xk2p",
+			"gpu_uuid":            "GPU-8b9d5c6e-1f2a-4b3c-9d8e-7f6a5b4c3d2e",
+		},
+		Properties: map[string]any{
+			"xid":         int64(79),
+			"description": "GPU has fallen off the bus",
+			"recoverable": false,
+		},
+	}
+}
+
+// benchmarkSpans returns n client spans of a trace with tags and an annotation.
+func benchmarkSpans(n int) []*trace.Span {
+	name, kind, service, ip := "GET /api/v1/items", "CLIENT", "frontend", "10.0.0.1"
+	timestamp, duration, annotationTimestamp := now.UnixMicro(), int64(1500), now.UnixMicro()+10
+	annotation := "retry"
+	spans := make([]*trace.Span, 0, n)
+	for i := 0; i < n; i++ {
+		parentID := fmt.Sprintf("%016x", i+n+1)
+		spans = append(spans, &trace.Span{
+			Name:          &name,
+			Kind:          &kind,
+			TraceID:       "0123456789abcdef0123456789abcdef",
+			ID:            fmt.Sprintf("%016x", i+1),
+			ParentID:      &parentID,
+			Timestamp:     &timestamp,
+			Duration:      &duration,
+			LocalEndpoint: &trace.Endpoint{ServiceName: &service, Ipv4: &ip},
+			Tags: map[string]string{
+				"http.method":      "GET",
+				"http.status_code": "200",
+				"http.url":         "http://backend:8080/api/v1/items",
+			},
+			Annotations: []*trace.Annotation{{Timestamp: &annotationTimestamp, Value: &annotation}},
+		})
+	}
+	return spans
+}
+
+func BenchmarkTranslatorToMetrics(b *testing.B) {
+	for _, size := range []int{1, 100, 1000} {
+		datapoints := benchmarkDatapoints(size)
+		for _, tt := range benchmarkTranslators {
+			b.Run(fmt.Sprintf("%s/%d datapoints", tt.name, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := tt.translator.ToMetrics(datapoints); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func BenchmarkTranslatorToLogs(b *testing.B) {
+	evt := benchmarkEvent()
+	for _, tt := range benchmarkTranslators {
+		b.Run(tt.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				if _, err := tt.translator.ToLogs(evt); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
+
+func BenchmarkTranslatorToTraces(b *testing.B) {
+	for _, size := range []int{1, 100} {
+		spans := benchmarkSpans(size)
+		for _, tt := range []string{"default", "sorted attributes"} {
+			translator := NewTranslator(zap.NewNop())
+			if tt == "sorted attributes" {
+				translator = NewTranslator(zap.NewNop(), WithSortedAttributes())
+			}
+			b.Run(fmt.Sprintf("%s/%d spans", tt, size), func(b *testing.B) {
+				b.ReportAllocs()
+				for i := 0; i < b.N; i++ {
+					if _, err := translator.ToTraces(spans); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// allocsPerItem returns the average allocations of translating each item beyond the first n, excluding the
+// fixed cost of the translated content's resource and scope.
+func allocsPerItem(t *testing.T, n int, translate func(n int) error) float64 {
+	var err error
+	base := testing.AllocsPerRun(20, func() { err = translate(n) })
+	require.NoError(t, err)
+	doubled := testing.AllocsPerRun(20, func() { err = translate(2 * n) })
+	require.NoError(t, err)
+	return (doubled - base) / float64(n)
+}
+
+func TestTranslatorAllocationBudgets(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping allocation budgets in short mode")
+	}
+
+	const n = 100
+	datapoints := map[int][]*sfx.Datapoint{n: benchmarkDatapoints(n), 2 * n: benchmarkDatapoints(2 * n)}
+	spans := map[int][]*trace.Span{n: benchmarkSpans(n), 2 * n: benchmarkSpans(2 * n)}
+
+	t.Run("datapoints", func(t *testing.T) {
+		translator := NewTranslator(zap.NewNop())
+		allocs := allocsPerItem(t, n, func(n int) error {
+			_, err := translator.ToMetrics(datapoints[n])
+			return err
+		})
+		assert.LessOrEqualf(t, allocs, float64(datapointAllocsBudget), "allocations per datapoint exceed budget")
+	})
+
+	t.Run("datapoints with dimension translation", func(t *testing.T) {
+		translator := NewTranslator(zap.NewNop(), WithDimensionTranslation())
+		allocs := allocsPerItem(t, n, func(n int) error {
+			_, err := translator.ToMetrics(datapoints[n])
+			return err
+		})
+		assert.LessOrEqualf(t, allocs, float64(translatedDatapointAllocsBudget), "allocations per datapoint exceed budget")
+	})
+
+	t.Run("event", func(t *testing.T) {
+		translator := NewTranslator(zap.NewNop())
+		evt := benchmarkEvent()
+		var err error
+		allocs := testing.AllocsPerRun(20, func() { _, err = translator.ToLogs(evt) })
+		require.NoError(t, err)
+		assert.LessOrEqualf(t, allocs, float64(eventAllocsBudget), "allocations per event exceed budget")
+	})
+
+	t.Run("spans", func(t *testing.T) {
+		translator := NewTranslator(zap.NewNop())
+		allocs := allocsPerItem(t, n, func(n int) error {
+			_, err := translator.ToTraces(spans[n])
+			return err
+		})
+		assert.LessOrEqualf(t, allocs, float64(spanAllocsBudget), "allocations per span exceed budget")
+	})
+}
+
+func TestBenchmarkContentTranslation(t *testing.T) {
+	// the benchmarks are only meaningful if their content is translated rather than dropped
+	for _, tt := range benchmarkTranslators {
+		t.Run(tt.name, func(t *testing.T) {
+			md, err := tt.translator.ToMetrics(benchmarkDatapoints(10))
+			require.NoError(t, err)
+			assert.Equal(t, 10, md.DataPointCount())
+
+			ld, err := tt.translator.ToLogs(benchmarkEvent())
+			require.NoError(t, err)
+			assert.Equal(t, 1, ld.LogRecordCount())
+		})
+	}
+
+	td, err := NewTranslator(zap.NewNop()).ToTraces(benchmarkSpans(10))
+	require.NoError(t, err)
+	assert.Equal(t, 10, td.SpanCount())
+}
@@ -16,6 +16,7 @@ package converter
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/signalfx/golib/v3/event"
 	"go.opentelemetry.io/collector/pdata/pcommon"
@@ -30,11 +31,40 @@ const (
 	SFxEventPropertiesKey = "com.splunk.signalfx.event_properties"
 	// SFxEventType key for splunk event type
 	SFxEventType = "com.splunk.signalfx.event_type"
+	// SFxEventIngestionLatencyKey key for the nanoseconds between the event timestamp and its observed timestamp.
+	SFxEventIngestionLatencyKey = "com.splunk.signalfx.event_ingestion_latency_ns"
 )
 
-// eventToLog converts a SFx event to a plog.Logs entry suitable for consumption by LogConsumer.
+// SFxEventCategories are the SignalFx event categories by their name in the SignalFx API.
+var SFxEventCategories = map[string]event.Category{
+	"USER_DEFINED":      event.USERDEFINED,
+	"ALERT":             event.ALERT,
+	"AUDIT":             event.AUDIT,
+	"JOB":               event.JOB,
+	"COLLECTD":          event.COLLECTD,
+	"SERVICE_DISCOVERY": event.SERVICEDISCOVERY,
+	"EXCEPTION":         event.EXCEPTION,
+	"AGENT":             event.AGENT,
+}
+
+// EventDimensionsTarget is where the dimensions of translated events are added as attributes.
+type EventDimensionsTarget string
+
+const (
+	// EventDimensionsToRecord adds event dimensions as log record attributes, the default.
+	EventDimensionsToRecord EventDimensionsTarget = "record"
+	// EventDimensionsToResource adds event dimensions as resource attributes.
+	EventDimensionsToResource EventDimensionsTarget = "resource"
+)
+
+// eventToLog converts a SFx event to a plog.Logs entry suitable for consumption by LogConsumer, observed at the
+// provided time.
+// If translateDimensions is set, well-known dimensions are converted to semantic convention resource attributes.
+// The other dimensions are added as attributes of the dimensionsTarget, the log record by default.
 // based on https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/5de076e9773bdb7617b544a57fa0a4b848cec92c/receiver/signalfxreceiver/signalfxv2_event_to_logdata.go#L27
-func sfxEventToPDataLogs(event *event.Event, logger *zap.Logger) plog.Logs {
+func sfxEventToPDataLogs(
+	event *event.Event, observed time.Time, translateDimensions bool, dimensionsTarget EventDimensionsTarget, logger *zap.Logger,
+) plog.Logs {
 	logs, lr := newLogs()
 
 	var unixNano int64
@@ -42,9 +72,13 @@ func sfxEventToPDataLogs(event *event.Event, logger *zap.Logger) plog.Logs {
 		unixNano = event.Timestamp.UnixNano()
 	}
 	lr.SetTimestamp(pcommon.Timestamp(unixNano))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(observed))
 
 	// size for event category and dimension attributes
-	attrsCapacity := 2 + len(event.Dimensions)
+	attrsCapacity := 2
+	if dimensionsTarget == "" || dimensionsTarget == EventDimensionsToRecord {
+		attrsCapacity += len(event.Dimensions)
+	}
 	if len(event.Properties) > 0 {
 		attrsCapacity++
 	}
@@ -63,8 +97,25 @@ func sfxEventToPDataLogs(event *event.Event, logger *zap.Logger) plog.Logs {
 		attrs.InsertString(SFxEventType, event.EventType)
 	}
 
-	for k, v := range event.Dimensions {
-		attrs.InsertString(k, v)
+	resourceAttrs := logs.ResourceLogs().At(0).Resource().Attributes()
+	dimensions := event.Dimensions
+	if translateDimensions {
+		var resourceAttributes map[string]string
+		resourceAttributes, dimensions = splitResourceDimensions(event.Dimensions)
+		resourceAttrs.EnsureCapacity(len(resourceAttributes))
+		for k, v := range resourceAttributes {
+			resourceAttrs.InsertString(k, v)
+		}
+	}
+
+	dimensionAttrs := attrs
+	if dimensionsTarget == EventDimensionsToResource {
+		dimensionAttrs = resourceAttrs
+	}
+	// the record attributes are already sized for the dimensions, and EnsureCapacity would drop the
+	// existing resource attributes
+	for k, v := range dimensions {
+		dimensionAttrs.InsertString(k, v)
 	}
 
 	if len(event.Properties) > 0 {
@@ -112,6 +163,26 @@ func sfxEventToPDataLogs(event *event.Event, logger *zap.Logger) plog.Logs {
 	return logs
 }
 
+// setIngestionLatency adds the nanoseconds between the timestamp and observed timestamp of the translated events
+// as the SFxEventIngestionLatencyKey attribute, which is negative when the event's source clock is ahead.  Events
+// without a timestamp are left as is.
+func setIngestionLatency(ld plog.Logs) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		sls := rls.At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				lr := lrs.At(k)
+				if lr.Timestamp() == 0 || lr.ObservedTimestamp() == 0 {
+					continue
+				}
+				lr.Attributes().UpsertInt(SFxEventIngestionLatencyKey, int64(lr.ObservedTimestamp())-int64(lr.Timestamp()))
+			}
+		}
+	}
+}
+
 func newLogs() (plog.Logs, plog.LogRecord) {
 	ld := plog.NewLogs()
 	lr := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
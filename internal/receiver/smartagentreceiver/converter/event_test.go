@@ -114,7 +114,7 @@ func TestEventToPDataLogs(tt *testing.T) {
 		},
 	} {
 		tt.Run(test.name, func(t *testing.T) {
-			log := sfxEventToPDataLogs(&test.event, zap.NewNop())
+			log := sfxEventToPDataLogs(&test.event, time.Now(), false, EventDimensionsToRecord, zap.NewNop())
 			assertLogsEqual(t, test.expectedLog, log)
 		})
 	}
@@ -165,3 +165,118 @@ func assertAttributeMapContainsAll(t *testing.T, first, second pcommon.Map) {
 		return true
 	})
 }
+
+func TestEventToPDataLogsWithDimensionsTarget(t *testing.T) {
+	ev := event.Event{
+		EventType: "some_event_type",
+		Category:  1,
+		Dimensions: map[string]string{
+			"host": "a.host", "dimension_name": "dimension_value",
+		},
+	}
+	eventAttrs := map[string]any{
+		"com.splunk.signalfx.event_category": int64(1),
+		"com.splunk.signalfx.event_type":     "some_event_type",
+	}
+
+	for _, test := range []struct {
+		expectedResource map[string]any
+		expectedRecord   map[string]any
+		name             string
+		target           EventDimensionsTarget
+	}{
+		{
+			name:             "default",
+			expectedResource: map[string]any{},
+			expectedRecord: map[string]any{
+				"com.splunk.signalfx.event_category": int64(1),
+				"com.splunk.signalfx.event_type":     "some_event_type",
+				"host":                               "a.host",
+				"dimension_name":                     "dimension_value",
+			},
+		},
+		{
+			name:             "record",
+			target:           EventDimensionsToRecord,
+			expectedResource: map[string]any{},
+			expectedRecord: map[string]any{
+				"com.splunk.signalfx.event_category": int64(1),
+				"com.splunk.signalfx.event_type":     "some_event_type",
+				"host":                               "a.host",
+				"dimension_name":                     "dimension_value",
+			},
+		},
+		{
+			name:             "resource",
+			target:           EventDimensionsToResource,
+			expectedResource: map[string]any{"host": "a.host", "dimension_name": "dimension_value"},
+			expectedRecord:   eventAttrs,
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			logs := sfxEventToPDataLogs(&ev, time.Now(), false, test.target, zap.NewNop())
+			rl := logs.ResourceLogs().At(0)
+			assert.Equal(t, test.expectedResource, rl.Resource().Attributes().AsRaw())
+			assert.Equal(t, test.expectedRecord, rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+		})
+	}
+}
+
+func TestEventToPDataLogsWithDimensionTranslationAndResourceTarget(t *testing.T) {
+	ev := event.Event{
+		Category: 1,
+		Dimensions: map[string]string{
+			"host": "a.host", "dimension_name": "dimension_value",
+		},
+	}
+	logs := sfxEventToPDataLogs(&ev, time.Now(), true, EventDimensionsToResource, zap.NewNop())
+	assert.Equal(t, map[string]any{
+		"host.name": "a.host", "dimension_name": "dimension_value",
+	}, logs.ResourceLogs().At(0).Resource().Attributes().AsRaw())
+	assert.Equal(t, map[string]any{
+		"com.splunk.signalfx.event_category": int64(1),
+	}, logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+}
+
+func TestEventToPDataLogsObservedTimestamp(t *testing.T) {
+	observed := time.Unix(10, 123456789)
+	ev := event.Event{EventType: "some_event_type", Timestamp: time.Unix(5, 1)}
+	logs := sfxEventToPDataLogs(&ev, observed, false, EventDimensionsToRecord, zap.NewNop())
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, pcommon.Timestamp(5000000001), lr.Timestamp())
+	assert.Equal(t, pcommon.Timestamp(10123456789), lr.ObservedTimestamp())
+}
+
+func TestTranslatorWithEventIngestionLatency(t *testing.T) {
+	before := time.Now()
+	ev := event.Event{EventType: "some_event_type", Timestamp: before.Add(-time.Minute)}
+	logs, err := NewTranslator(zap.NewNop(), WithEventIngestionLatency()).ToLogs(&ev)
+	require.NoError(t, err)
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	latency, ok := lr.Attributes().Get(SFxEventIngestionLatencyKey)
+	require.True(t, ok)
+	assert.Equal(t, int64(lr.ObservedTimestamp())-int64(lr.Timestamp()), latency.IntVal())
+	assert.GreaterOrEqual(t, latency.IntVal(), time.Minute.Nanoseconds())
+
+	// events ahead of the collector's clock have a negative latency
+	ev.Timestamp = time.Now().Add(time.Hour)
+	logs, err = NewTranslator(zap.NewNop(), WithEventIngestionLatency()).ToLogs(&ev)
+	require.NoError(t, err)
+	latency, ok = logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get(SFxEventIngestionLatencyKey)
+	require.True(t, ok)
+	assert.Less(t, latency.IntVal(), int64(0))
+
+	// events without a timestamp have no latency
+	logs, err = NewTranslator(zap.NewNop(), WithEventIngestionLatency()).ToLogs(&event.Event{EventType: "some_event_type"})
+	require.NoError(t, err)
+	lr = logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.NotZero(t, lr.ObservedTimestamp())
+	_, ok = lr.Attributes().Get(SFxEventIngestionLatencyKey)
+	assert.False(t, ok)
+
+	// the attribute is opt-in
+	logs, err = NewTranslator(zap.NewNop()).ToLogs(&ev)
+	require.NoError(t, err)
+	_, ok = logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes().Get(SFxEventIngestionLatencyKey)
+	assert.False(t, ok)
+}
@@ -0,0 +1,168 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+// AttributesTruncatedKey is set on datapoints and events whose attributes were truncated to
+// satisfy the Translator's AttributeLimits.
+const AttributesTruncatedKey = "com.splunk.signalfx.attributes_truncated"
+
+// reservedAttributes are never removed when limiting the attribute count.
+var reservedAttributes = map[string]bool{
+	SFxEventCategoryKey:         true,
+	SFxEventPropertiesKey:       true,
+	SFxEventType:                true,
+	SFxEventIngestionLatencyKey: true,
+}
+
+// AttributeLimits bounds the attributes of translated datapoints and events, so that oversized content is
+// truncated instead of being rejected by ingest.  A zero value disables the respective limit.
+type AttributeLimits struct {
+	// MaxCount is the maximum number of attributes, including the AttributesTruncatedKey indicator.
+	// Attributes are retained in key order, though event category, type, and properties are always retained.
+	MaxCount int
+	// MaxValueLength is the maximum length in bytes of string attribute values, including those of
+	// event properties but excluding the event type.  Values are truncated at UTF-8 character boundaries.
+	MaxValueLength int
+}
+
+func (l AttributeLimits) enabled() bool {
+	return l.MaxCount > 0 || l.MaxValueLength > 0
+}
+
+// applyToMetrics limits the attributes of all datapoints, returning the number of truncated datapoints.
+func (l AttributeLimits) applyToMetrics(md pmetric.Metrics) int {
+	truncated := 0
+	applyToPoints := func(dps pmetric.NumberDataPointSlice) {
+		for i := 0; i < dps.Len(); i++ {
+			if l.apply(dps.At(i).Attributes()) {
+				truncated++
+			}
+		}
+	}
+	for i := 0; i < md.ResourceMetrics().Len(); i++ {
+		sms := md.ResourceMetrics().At(i).ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				switch m.DataType() {
+				case pmetric.MetricDataTypeGauge:
+					applyToPoints(m.Gauge().DataPoints())
+				case pmetric.MetricDataTypeSum:
+					applyToPoints(m.Sum().DataPoints())
+				}
+			}
+		}
+	}
+	return truncated
+}
+
+// applyToLogs limits the attributes of all log records, returning the number of truncated records.
+func (l AttributeLimits) applyToLogs(ld plog.Logs) int {
+	truncated := 0
+	for i := 0; i < ld.ResourceLogs().Len(); i++ {
+		sls := ld.ResourceLogs().At(i).ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				if l.apply(lrs.At(k).Attributes()) {
+					truncated++
+				}
+			}
+		}
+	}
+	return truncated
+}
+
+// apply truncates the provided attributes to the limits, adding the AttributesTruncatedKey indicator
+// if any were removed or shortened.
+func (l AttributeLimits) apply(attrs pcommon.Map) bool {
+	truncated := l.truncateValues(attrs)
+
+	// the indicator counts toward MaxCount, so truncated values also require room for it
+	if l.MaxCount > 0 && (attrs.Len() > l.MaxCount || truncated && attrs.Len() >= l.MaxCount) {
+		// leave room for the indicator and the attributes required to identify events
+		retained := l.MaxCount - 1
+		keys := make([]string, 0, attrs.Len())
+		attrs.Range(func(k string, _ pcommon.Value) bool {
+			if reservedAttributes[k] {
+				retained--
+			} else {
+				keys = append(keys, k)
+			}
+			return true
+		})
+		if retained < 0 {
+			retained = 0
+		}
+		sort.Strings(keys)
+		for _, k := range keys[retained:] {
+			attrs.Remove(k)
+		}
+		truncated = true
+	}
+
+	if truncated {
+		attrs.UpsertBool(AttributesTruncatedKey, true)
+	}
+	return truncated
+}
+
+func (l AttributeLimits) truncateValues(attrs pcommon.Map) bool {
+	if l.MaxValueLength <= 0 {
+		return false
+	}
+	truncated := false
+	attrs.Range(func(k string, v pcommon.Value) bool {
+		switch v.Type() {
+		case pcommon.ValueTypeString:
+			if reservedAttributes[k] {
+				// the event type identifies the event and isn't truncated
+				return true
+			}
+			if s := v.StringVal(); len(s) > l.MaxValueLength {
+				v.SetStringVal(truncateString(s, l.MaxValueLength))
+				truncated = true
+			}
+		case pcommon.ValueTypeMap:
+			if l.truncateValues(v.MapVal()) {
+				truncated = true
+			}
+		}
+		return true
+	})
+	return truncated
+}
+
+// truncateString returns the longest prefix of s no longer than maxLength bytes that doesn't split a character.
+func truncateString(s string, maxLength int) string {
+	if len(s) <= maxLength {
+		return s
+	}
+	end := maxLength
+	for end > 0 && !utf8.RuneStart(s[end]) {
+		end--
+	}
+	return s[:end]
+}
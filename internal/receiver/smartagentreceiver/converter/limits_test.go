@@ -0,0 +1,165 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	sfx "github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestTruncateString(t *testing.T) {
+	assert.Equal(t, "short", truncateString("short", 10))
+	assert.Equal(t, "abc", truncateString("abcdef", 3))
+	assert.Equal(t, "", truncateString("abc", 0))
+	// "é" is two bytes and must not be split
+	assert.Equal(t, "a", truncateString("aéb", 2))
+	assert.Equal(t, "aé", truncateString("aéb", 3))
+}
+
+func TestAttributeLimitsApply(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		limits    AttributeLimits
+		attrs     map[string]any
+		expected  map[string]any
+		truncated bool
+	}{
+		{
+			name:     "within limits",
+			limits:   AttributeLimits{MaxCount: 3, MaxValueLength: 5},
+			attrs:    map[string]any{"a": "one", "b": "two", "c": "three"},
+			expected: map[string]any{"a": "one", "b": "two", "c": "three"},
+		},
+		{
+			name:      "value length",
+			limits:    AttributeLimits{MaxValueLength: 3},
+			attrs:     map[string]any{"a": "one", "b": "three", "c": int64(12345)},
+			expected:  map[string]any{"a": "one", "b": "thr", "c": int64(12345), AttributesTruncatedKey: true},
+			truncated: true,
+		},
+		{
+			name:      "count",
+			limits:    AttributeLimits{MaxCount: 3},
+			attrs:     map[string]any{"d": "four", "b": "two", "a": "one", "c": "three"},
+			expected:  map[string]any{"a": "one", "b": "two", AttributesTruncatedKey: true},
+			truncated: true,
+		},
+		{
+			name:     "count at limit",
+			limits:   AttributeLimits{MaxCount: 3},
+			attrs:    map[string]any{"a": "one", "b": "two", "c": "three"},
+			expected: map[string]any{"a": "one", "b": "two", "c": "three"},
+		},
+		{
+			name:      "count at limit with truncated value",
+			limits:    AttributeLimits{MaxCount: 3, MaxValueLength: 3},
+			attrs:     map[string]any{"a": "one", "b": "two", "c": "three"},
+			expected:  map[string]any{"a": "one", "b": "two", AttributesTruncatedKey: true},
+			truncated: true,
+		},
+		{
+			name:      "count below limit with truncated value",
+			limits:    AttributeLimits{MaxCount: 3, MaxValueLength: 3},
+			attrs:     map[string]any{"a": "one", "c": "three"},
+			expected:  map[string]any{"a": "one", "c": "thr", AttributesTruncatedKey: true},
+			truncated: true,
+		},
+		{
+			name:   "count retains event attributes",
+			limits: AttributeLimits{MaxCount: 3},
+			attrs: map[string]any{
+				"a": "one", "b": "two", SFxEventCategoryKey: int64(1), SFxEventType: "type",
+			},
+			expected: map[string]any{
+				SFxEventCategoryKey: int64(1), SFxEventType: "type", AttributesTruncatedKey: true,
+			},
+			truncated: true,
+		},
+		{
+			name:   "nested values",
+			limits: AttributeLimits{MaxValueLength: 2},
+			attrs: map[string]any{
+				SFxEventPropertiesKey: map[string]any{"prop": "value", "number": 1.5},
+			},
+			expected: map[string]any{
+				SFxEventPropertiesKey:  map[string]any{"prop": "va", "number": 1.5},
+				AttributesTruncatedKey: true,
+			},
+			truncated: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			attrs := newAttributeMap(tt.attrs)
+			assert.Equal(t, tt.truncated, tt.limits.apply(attrs))
+			assert.Equal(t, tt.expected, attrs.AsRaw())
+		})
+	}
+}
+
+func TestTranslatorAttributeLimits(t *testing.T) {
+	translator := NewTranslator(zap.NewNop(), WithAttributeLimits(AttributeLimits{MaxCount: 2, MaxValueLength: 4}))
+
+	md, err := translator.ToMetrics([]*sfx.Datapoint{
+		sfx.New("gauge", map[string]string{"a": "value", "b": "b"}, sfx.NewIntValue(1), sfx.Gauge, time.Now()),
+		sfx.New("counter", map[string]string{"a": "a"}, sfx.NewIntValue(1), sfx.Counter, time.Now()),
+	})
+	require.NoError(t, err)
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	require.Equal(t, 2, metrics.Len())
+	assert.Equal(t, map[string]any{"a": "valu", AttributesTruncatedKey: true},
+		metrics.At(0).Gauge().DataPoints().At(0).Attributes().AsRaw())
+	assert.Equal(t, map[string]any{"a": "a"}, metrics.At(1).Sum().DataPoints().At(0).Attributes().AsRaw())
+
+	ld, err := translator.ToLogs(&event.Event{
+		EventType:  "event",
+		Category:   1,
+		Dimensions: map[string]string{"dim": strings.Repeat("x", 10)},
+		Timestamp:  time.Now(),
+	})
+	require.NoError(t, err)
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	assert.Equal(t, map[string]any{
+		SFxEventCategoryKey:    int64(1),
+		SFxEventType:           "event",
+		AttributesTruncatedKey: true,
+	}, attrs.AsRaw())
+}
+
+func newAttributeMap(raw map[string]any) pcommon.Map {
+	attrs := pcommon.NewMap()
+	for k, v := range raw {
+		switch val := v.(type) {
+		case string:
+			attrs.InsertString(k, val)
+		case int64:
+			attrs.InsertInt(k, val)
+		case float64:
+			attrs.InsertDouble(k, val)
+		case map[string]any:
+			mapVal := pcommon.NewValueMap()
+			newAttributeMap(val).CopyTo(mapVal.MapVal())
+			attrs.Insert(k, mapVal)
+		}
+	}
+	return attrs
+}
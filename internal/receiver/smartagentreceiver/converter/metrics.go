@@ -30,13 +30,20 @@ var (
 
 // Based on https://github.com/open-telemetry/opentelemetry-collector-contrib/blob/v0.15.0/receiver/signalfxreceiver/signalfxv2_to_metricdata.go
 // toMetrics() will respect the timestamp of any datapoint that isn't the zero value for time.Time,
-// using timeReceived otherwise.
-func sfxDatapointsToPDataMetrics(datapoints []*sfx.Datapoint, timeReceived time.Time, logger *zap.Logger) pmetric.Metrics {
+// using timeReceived otherwise.  If translateDimensions is set, well-known dimensions are converted to
+// semantic convention resource attributes, with datapoints grouped by their resulting Resource.
+// Datapoint Meta entries whose keys are in metaAttributes are added as the mapped datapoint attributes.
+func sfxDatapointsToPDataMetrics(
+	datapoints []*sfx.Datapoint, timeReceived time.Time, translateDimensions bool, metaAttributes map[string]string, logger *zap.Logger,
+) pmetric.Metrics {
 	md := pmetric.NewMetrics()
-	ilm := md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty()
 
-	metrics := ilm.Metrics()
-	metrics.EnsureCapacity(len(datapoints))
+	var metrics pmetric.MetricSlice
+	resourceMetrics := map[string]pmetric.MetricSlice{}
+	if !translateDimensions {
+		metrics = md.ResourceMetrics().AppendEmpty().ScopeMetrics().AppendEmpty().Metrics()
+		metrics.EnsureCapacity(len(datapoints))
+	}
 
 	numDropped := 0
 	for _, datapoint := range datapoints {
@@ -44,7 +51,26 @@ func sfxDatapointsToPDataMetrics(datapoints []*sfx.Datapoint, timeReceived time.
 			continue
 		}
 
-		if err := setDataTypeAndPoints(datapoint, metrics, timeReceived); err != nil {
+		dimensions := datapoint.Dimensions
+		if translateDimensions {
+			var resourceAttributes map[string]string
+			resourceAttributes, dimensions = splitResourceDimensions(datapoint.Dimensions)
+			key := resourceKey(resourceAttributes)
+			var ok bool
+			if metrics, ok = resourceMetrics[key]; !ok {
+				rm := md.ResourceMetrics().AppendEmpty()
+				attrs := rm.Resource().Attributes()
+				attrs.EnsureCapacity(len(resourceAttributes))
+				for k, v := range resourceAttributes {
+					attrs.InsertString(k, v)
+				}
+				metrics = rm.ScopeMetrics().AppendEmpty().Metrics()
+				resourceMetrics[key] = metrics
+			}
+		}
+
+		dimensions = withMetaAttributes(dimensions, datapoint.Meta, metaAttributes)
+		if err := setDataTypeAndPoints(datapoint, dimensions, metrics, timeReceived); err != nil {
 			numDropped++
 			logger.Debug("SignalFx datapoint type conversion error",
 				zap.Error(err),
@@ -54,12 +80,47 @@ func sfxDatapointsToPDataMetrics(datapoints []*sfx.Datapoint, timeReceived time.
 
 	if numDropped > 0 {
 		logger.Debug("SendDatapoints has dropped points", zap.Int("numDropped", numDropped))
+		if translateDimensions {
+			// don't report Resources whose datapoints were all dropped
+			md.ResourceMetrics().RemoveIf(func(rm pmetric.ResourceMetrics) bool {
+				return rm.ScopeMetrics().At(0).Metrics().Len() == 0
+			})
+		}
 	}
 
 	return md
 }
 
-func setDataTypeAndPoints(datapoint *sfx.Datapoint, ms pmetric.MetricSlice, timeReceived time.Time) error {
+// withMetaAttributes returns the dimensions with the values of the Meta entries whose keys, in string form,
+// are mapped to attribute names.  Existing dimensions take precedence and the provided ones are not modified.
+func withMetaAttributes(dimensions map[string]string, meta map[any]any, metaAttributes map[string]string) map[string]string {
+	if len(metaAttributes) == 0 || len(meta) == 0 {
+		return dimensions
+	}
+	var merged map[string]string
+	for k, v := range meta {
+		attribute, ok := metaAttributes[fmt.Sprint(k)]
+		if !ok || v == nil {
+			continue
+		}
+		if _, exists := dimensions[attribute]; exists {
+			continue
+		}
+		if merged == nil {
+			merged = make(map[string]string, len(dimensions)+len(metaAttributes))
+			for dk, dv := range dimensions {
+				merged[dk] = dv
+			}
+		}
+		merged[attribute] = fmt.Sprint(v)
+	}
+	if merged == nil {
+		return dimensions
+	}
+	return merged
+}
+
+func setDataTypeAndPoints(datapoint *sfx.Datapoint, dimensions map[string]string, ms pmetric.MetricSlice, timeReceived time.Time) error {
 	var m pmetric.Metric
 	sfxMetricType := datapoint.MetricType
 	if sfxMetricType == sfx.Timestamp {
@@ -76,19 +137,19 @@ func setDataTypeAndPoints(datapoint *sfx.Datapoint, ms pmetric.MetricSlice, time
 	case sfx.Gauge, sfx.Enum, sfx.Rate:
 		m = ms.AppendEmpty()
 		m.SetDataType(pmetric.MetricDataTypeGauge)
-		fillNumberDatapoint(datapoint.Value, datapoint.Timestamp, datapoint.Dimensions, m.Gauge().DataPoints(), timeReceived)
+		fillNumberDatapoint(datapoint.Value, datapoint.Timestamp, dimensions, m.Gauge().DataPoints(), timeReceived)
 	case sfx.Count:
 		m = ms.AppendEmpty()
 		m.SetDataType(pmetric.MetricDataTypeSum)
 		m.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityDelta)
 		m.Sum().SetIsMonotonic(true)
-		fillNumberDatapoint(datapoint.Value, datapoint.Timestamp, datapoint.Dimensions, m.Sum().DataPoints(), timeReceived)
+		fillNumberDatapoint(datapoint.Value, datapoint.Timestamp, dimensions, m.Sum().DataPoints(), timeReceived)
 	case sfx.Counter:
 		m = ms.AppendEmpty()
 		m.SetDataType(pmetric.MetricDataTypeSum)
 		m.Sum().SetAggregationTemporality(pmetric.MetricAggregationTemporalityCumulative)
 		m.Sum().SetIsMonotonic(true)
-		fillNumberDatapoint(datapoint.Value, datapoint.Timestamp, datapoint.Dimensions, m.Sum().DataPoints(), timeReceived)
+		fillNumberDatapoint(datapoint.Value, datapoint.Timestamp, dimensions, m.Sum().DataPoints(), timeReceived)
 	default:
 		return fmt.Errorf("unsupported metric type %T: %v", sfxMetricType, sfxMetricType)
 	}
@@ -240,7 +240,7 @@ func TestDatapointsToPDataMetrics(t *testing.T) {
 
 	for _, test := range tests {
 		t.Run(test.name, func(tt *testing.T) {
-			md := sfxDatapointsToPDataMetrics(test.datapoints, test.timeReceived, zap.NewNop())
+			md := sfxDatapointsToPDataMetrics(test.datapoints, test.timeReceived, false, nil, zap.NewNop())
 			sortLabels(tt, md)
 
 			assert.Equal(tt, test.expectedMetrics, md)
@@ -284,13 +284,62 @@ func TestSetDataTypeWithInvalidDatapoints(t *testing.T) {
 	}
 	for _, test := range tests {
 		t.Run(test.name, func(tt *testing.T) {
-			err := setDataTypeAndPoints(test.datapoint, pmetric.NewMetricSlice(), time.Now())
+			err := setDataTypeAndPoints(test.datapoint, test.datapoint.Dimensions, pmetric.NewMetricSlice(), time.Now())
 			require.Error(t, err)
 			assert.Contains(t, err.Error(), test.expectedError)
 		})
 	}
 }
 
+type metaKey int
+
+func (k metaKey) String() string {
+	return "a_typed_key"
+}
+
+func TestDatapointsToPDataMetricsWithMetaAttributes(t *testing.T) {
+	dp := sfxDatapoint()
+	dp.Meta = map[any]any{
+		"endpoint_id": "some-endpoint",
+		metaKey(0):    12345,
+		"k0":          "not a dimension override",
+		"unmapped":    "unmapped value",
+		"nil_value":   nil,
+	}
+	dp.Dimensions["k0_attr"] = "dimension value"
+	dims := dp.Dimensions
+
+	md := sfxDatapointsToPDataMetrics([]*sfx.Datapoint{dp}, now, false, map[string]string{
+		"endpoint_id": "sfx.endpoint.id",
+		"a_typed_key": "typed",
+		"k0":          "k0_attr",
+		"nil_value":   "nil",
+		"missing":     "missing",
+	}, zap.NewNop())
+
+	require.Equal(t, 1, md.MetricCount())
+	attrs := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes()
+	assert.Equal(t, map[string]any{
+		"k0":              "v0",
+		"k1":              "v1",
+		"k2":              "v2",
+		"k0_attr":         "dimension value",
+		"sfx.endpoint.id": "some-endpoint",
+		"typed":           "12345",
+	}, attrs.AsRaw())
+	// the datapoint's dimensions aren't modified
+	assert.Len(t, dims, 4)
+}
+
+func TestWithMetaAttributesWithoutMatches(t *testing.T) {
+	dimensions := map[string]string{"k0": "v0"}
+	meta := map[any]any{"unmapped": "value"}
+	assert.Equal(t, dimensions, withMetaAttributes(dimensions, meta, nil))
+	assert.Equal(t, dimensions, withMetaAttributes(dimensions, nil, map[string]string{"unmapped": "attr"}))
+	assert.Equal(t, dimensions, withMetaAttributes(dimensions, meta, map[string]string{"other": "attr"}))
+	assert.Equal(t, map[string]string{"attr": "value"}, withMetaAttributes(nil, meta, map[string]string{"unmapped": "attr"}))
+}
+
 func sortLabels(t *testing.T, metrics pmetric.Metrics) {
 	for i := 0; i < metrics.ResourceMetrics().Len(); i++ {
 		rm := metrics.ResourceMetrics().At(i)
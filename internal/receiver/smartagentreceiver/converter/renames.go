@@ -0,0 +1,90 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+)
+
+// Renames is a bidirectional mapping of names, like those of metrics or dimensions, to their new names.  The new
+// names are unique and aren't renamed themselves, so that renamed content can be renamed back to its original names.
+type Renames struct {
+	renamed  map[string]string
+	original map[string]string
+}
+
+// NewRenames returns the Renames of the mapping of names to their new names.
+func NewRenames(mapping map[string]string) (Renames, error) {
+	renames := Renames{
+		renamed:  make(map[string]string, len(mapping)),
+		original: make(map[string]string, len(mapping)),
+	}
+	for name, newName := range mapping {
+		if name == "" || newName == "" {
+			return Renames{}, fmt.Errorf("names can't be empty")
+		}
+		if other, ok := renames.original[newName]; ok {
+			return Renames{}, fmt.Errorf("%q and %q are both renamed to %q", other, name, newName)
+		}
+		renames.renamed[name] = newName
+		renames.original[newName] = name
+	}
+	for name := range renames.renamed {
+		if original, ok := renames.original[name]; ok {
+			return Renames{}, fmt.Errorf("%q is renamed to %q and %q is renamed to it", name, renames.renamed[name], original)
+		}
+	}
+	return renames, nil
+}
+
+func mustNewRenames(mapping map[string]string) Renames {
+	renames, err := NewRenames(mapping)
+	if err != nil {
+		panic(err)
+	}
+	return renames
+}
+
+// Len returns the number of renamed names.
+func (r Renames) Len() int {
+	return len(r.renamed)
+}
+
+// Renamed returns the new name of the name, if it's renamed.
+func (r Renames) Renamed(name string) (string, bool) {
+	newName, ok := r.renamed[name]
+	return newName, ok
+}
+
+// Original returns the original name of the new name, if it's one.
+func (r Renames) Original(newName string) (string, bool) {
+	name, ok := r.original[newName]
+	return name, ok
+}
+
+// Reversed returns the Renames of the new names back to their original names.
+func (r Renames) Reversed() Renames {
+	return Renames{renamed: r.original, original: r.renamed}
+}
+
+// TranslationRule returns the translation rule of the action, ActionRenameMetrics or ActionRenameDimensionKeys,
+// applying the renames.
+func (r Renames) TranslationRule(action TranslationAction) TranslationRule {
+	mapping := make(map[string]string, len(r.renamed))
+	for name, newName := range r.renamed {
+		mapping[name] = newName
+	}
+	return TranslationRule{Action: action, Mapping: mapping}
+}
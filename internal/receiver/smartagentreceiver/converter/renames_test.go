@@ -0,0 +1,85 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenames(t *testing.T) {
+	renames, err := NewRenames(map[string]string{"bytes.used_memory": "redis.memory.used", "cpu.utilization": "system.cpu.utilization"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, renames.Len())
+
+	newName, ok := renames.Renamed("bytes.used_memory")
+	assert.True(t, ok)
+	assert.Equal(t, "redis.memory.used", newName)
+	_, ok = renames.Renamed("redis.memory.used")
+	assert.False(t, ok)
+
+	name, ok := renames.Original("redis.memory.used")
+	assert.True(t, ok)
+	assert.Equal(t, "bytes.used_memory", name)
+	_, ok = renames.Original("bytes.used_memory")
+	assert.False(t, ok)
+
+	reversed := renames.Reversed()
+	name, ok = reversed.Renamed("system.cpu.utilization")
+	assert.True(t, ok)
+	assert.Equal(t, "cpu.utilization", name)
+
+	assert.Equal(t, TranslationRule{
+		Action:  ActionRenameMetrics,
+		Mapping: map[string]string{"redis.memory.used": "bytes.used_memory", "system.cpu.utilization": "cpu.utilization"},
+	}, reversed.TranslationRule(ActionRenameMetrics))
+}
+
+func TestInvalidRenames(t *testing.T) {
+	for _, tt := range []struct {
+		mapping       map[string]string
+		name          string
+		expectedError string
+	}{
+		{
+			name:          "empty name",
+			mapping:       map[string]string{"": "name"},
+			expectedError: "names can't be empty",
+		},
+		{
+			name:          "empty new name",
+			mapping:       map[string]string{"name": ""},
+			expectedError: "names can't be empty",
+		},
+		{
+			name:          "duplicate new names",
+			mapping:       map[string]string{"a": "c", "b": "c"},
+			expectedError: `are both renamed to "c"`,
+		},
+		{
+			name:          "renamed new name",
+			mapping:       map[string]string{"a": "b", "b": "c"},
+			expectedError: `"b" is renamed to "c" and "a" is renamed to it`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := NewRenames(tt.mapping)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+		})
+	}
+}
@@ -0,0 +1,69 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"sort"
+	"strings"
+)
+
+// resourceDimensions renames well-known SignalFx dimensions to their OpenTelemetry semantic convention resource
+// attributes.  The signalfx exporter's default translation rules don't rename them back: it exports these resource
+// attributes as dimensions with their semantic convention names.  Restoring the Smart Agent dimensions on export
+// requires adding a rename_dimension_keys rule with the reversed mapping to its translation rules.
+var resourceDimensions = mustNewRenames(map[string]string{
+	"host":                 "host.name",
+	"container_id":         "container.id",
+	"container_image":      "container.image.name",
+	"container_spec_name":  "k8s.container.name",
+	"kubernetes_cluster":   "k8s.cluster.name",
+	"kubernetes_namespace": "k8s.namespace.name",
+	"kubernetes_node":      "k8s.node.name",
+	"kubernetes_pod_name":  "k8s.pod.name",
+	"kubernetes_pod_uid":   "k8s.pod.uid",
+})
+
+// splitResourceDimensions separates the well-known dimensions, keyed by their resource attribute equivalents,
+// from the remaining ones.  The provided dimensions are not modified.
+func splitResourceDimensions(dimensions map[string]string) (resourceAttributes, remaining map[string]string) {
+	resourceAttributes = map[string]string{}
+	remaining = make(map[string]string, len(dimensions))
+	for k, v := range dimensions {
+		if attribute, ok := resourceDimensions.Renamed(k); ok {
+			resourceAttributes[attribute] = v
+			continue
+		}
+		remaining[k] = v
+	}
+	return resourceAttributes, remaining
+}
+
+// resourceKey provides a stable identity for a set of resource attributes.
+func resourceKey(resourceAttributes map[string]string) string {
+	keys := make([]string, 0, len(resourceAttributes))
+	for k := range resourceAttributes {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	for _, k := range keys {
+		sb.WriteString(k)
+		sb.WriteByte('=')
+		sb.WriteString(resourceAttributes[k])
+		sb.WriteByte(0)
+	}
+	return sb.String()
+}
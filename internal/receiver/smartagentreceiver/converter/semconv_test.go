@@ -0,0 +1,137 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"testing"
+	"time"
+
+	sfx "github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func TestDimensionResourceAttributeMappingIsBidirectional(t *testing.T) {
+	require.Equal(t, 9, resourceDimensions.Len())
+	for dimension, attribute := range resourceDimensions.renamed {
+		original, ok := resourceDimensions.Original(attribute)
+		require.True(t, ok)
+		assert.Equal(t, dimension, original)
+	}
+
+	attribute, ok := resourceDimensions.Renamed("host")
+	assert.True(t, ok)
+	assert.Equal(t, "host.name", attribute)
+
+	_, ok = resourceDimensions.Renamed("not_well_known")
+	assert.False(t, ok)
+	_, ok = resourceDimensions.Original("not.well.known")
+	assert.False(t, ok)
+}
+
+func TestSplitResourceDimensions(t *testing.T) {
+	dimensions := map[string]string{
+		"host": "a.host", "kubernetes_pod_name": "a-pod", "container_id": "abc123", "plugin": "cpu",
+	}
+	resourceAttributes, remaining := splitResourceDimensions(dimensions)
+	assert.Equal(t, map[string]string{
+		"host.name": "a.host", "k8s.pod.name": "a-pod", "container.id": "abc123",
+	}, resourceAttributes)
+	assert.Equal(t, map[string]string{"plugin": "cpu"}, remaining)
+	// unmodified
+	assert.Len(t, dimensions, 4)
+}
+
+func TestResourceKey(t *testing.T) {
+	assert.Equal(t, "", resourceKey(map[string]string{}))
+	assert.Equal(t,
+		resourceKey(map[string]string{"a": "1", "b": "2"}),
+		resourceKey(map[string]string{"b": "2", "a": "1"}),
+	)
+	assert.NotEqual(t,
+		resourceKey(map[string]string{"a": "1", "b": "2"}),
+		resourceKey(map[string]string{"a": "1", "b": "3"}),
+	)
+}
+
+func TestDatapointsToPDataMetricsWithDimensionTranslation(t *testing.T) {
+	onHost := func(host string) *sfx.Datapoint {
+		dp := sfxDatapoint()
+		dp.Dimensions["host"] = host
+		dp.Dimensions["kubernetes_pod_name"] = "a-pod"
+		return dp
+	}
+	invalid := onHost("an.invalid.host")
+	invalid.MetricType = sfx.Timestamp
+
+	md := sfxDatapointsToPDataMetrics(
+		[]*sfx.Datapoint{onHost("a.host"), sfxDatapoint(), onHost("a.host"), invalid, onHost("another.host")},
+		now, true, nil, zap.NewNop(),
+	)
+
+	rms := md.ResourceMetrics()
+	require.Equal(t, 3, rms.Len())
+
+	expectedResources := []map[string]any{
+		{"host.name": "a.host", "k8s.pod.name": "a-pod"},
+		{},
+		{"host.name": "another.host", "k8s.pod.name": "a-pod"},
+	}
+	expectedMetricCounts := []int{2, 1, 1}
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		rm.Resource().Attributes().Sort()
+		assert.Equal(t, pcommon.NewMapFromRaw(expectedResources[i]).Sort(), rm.Resource().Attributes())
+
+		metrics := rm.ScopeMetrics().At(0).Metrics()
+		require.Equal(t, expectedMetricCounts[i], metrics.Len())
+		for j := 0; j < metrics.Len(); j++ {
+			attrs := metrics.At(j).Gauge().DataPoints().At(0).Attributes()
+			attrs.Sort()
+			assert.Equal(t, pcommon.NewMapFromRaw(map[string]any{
+				"k0": "v0", "k1": "v1", "k2": "v2",
+			}).Sort(), attrs)
+		}
+	}
+}
+
+func TestEventToPDataLogsWithDimensionTranslation(t *testing.T) {
+	ev := event.Event{
+		EventType: "some_event_type",
+		Category:  1,
+		Dimensions: map[string]string{
+			"host": "a.host", "kubernetes_namespace": "a-namespace", "dimension_name": "dimension_value",
+		},
+	}
+	logs := sfxEventToPDataLogs(&ev, time.Now(), true, EventDimensionsToRecord, zap.NewNop())
+
+	resourceAttrs := logs.ResourceLogs().At(0).Resource().Attributes()
+	resourceAttrs.Sort()
+	assert.Equal(t, pcommon.NewMapFromRaw(map[string]any{
+		"host.name": "a.host", "k8s.namespace.name": "a-namespace",
+	}).Sort(), resourceAttrs)
+
+	attrs := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	value, ok := attrs.Get("dimension_name")
+	require.True(t, ok)
+	assert.Equal(t, "dimension_value", value.StringVal())
+	_, ok = attrs.Get("host")
+	assert.False(t, ok)
+	_, ok = attrs.Get("host.name")
+	assert.False(t, ok)
+}
@@ -0,0 +1,108 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+// Translated attributes are populated from Go maps, so their order differs between runs.  These functions sort
+// them, including nested ones like event properties, by key so translated content can be compared and hashed.
+
+func sortMetricsAttributes(md pmetric.Metrics) {
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		rm := rms.At(i)
+		sortAttributes(rm.Resource().Attributes())
+		sms := rm.ScopeMetrics()
+		for j := 0; j < sms.Len(); j++ {
+			ms := sms.At(j).Metrics()
+			for k := 0; k < ms.Len(); k++ {
+				m := ms.At(k)
+				switch m.DataType() {
+				case pmetric.MetricDataTypeGauge:
+					sortNumberDataPointsAttributes(m.Gauge().DataPoints())
+				case pmetric.MetricDataTypeSum:
+					sortNumberDataPointsAttributes(m.Sum().DataPoints())
+				}
+			}
+		}
+	}
+}
+
+func sortNumberDataPointsAttributes(dps pmetric.NumberDataPointSlice) {
+	for i := 0; i < dps.Len(); i++ {
+		sortAttributes(dps.At(i).Attributes())
+	}
+}
+
+func sortLogsAttributes(ld plog.Logs) {
+	rls := ld.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		rl := rls.At(i)
+		sortAttributes(rl.Resource().Attributes())
+		sls := rl.ScopeLogs()
+		for j := 0; j < sls.Len(); j++ {
+			lrs := sls.At(j).LogRecords()
+			for k := 0; k < lrs.Len(); k++ {
+				sortAttributes(lrs.At(k).Attributes())
+			}
+		}
+	}
+}
+
+func sortTracesAttributes(td ptrace.Traces) {
+	rss := td.ResourceSpans()
+	for i := 0; i < rss.Len(); i++ {
+		rs := rss.At(i)
+		sortAttributes(rs.Resource().Attributes())
+		sss := rs.ScopeSpans()
+		for j := 0; j < sss.Len(); j++ {
+			spans := sss.At(j).Spans()
+			for k := 0; k < spans.Len(); k++ {
+				span := spans.At(k)
+				sortAttributes(span.Attributes())
+				events := span.Events()
+				for l := 0; l < events.Len(); l++ {
+					sortAttributes(events.At(l).Attributes())
+				}
+			}
+		}
+	}
+}
+
+// sortAttributes sorts the attributes and those of any nested maps by key.
+func sortAttributes(attrs pcommon.Map) {
+	attrs.Sort()
+	attrs.Range(func(_ string, v pcommon.Value) bool {
+		sortNestedAttributes(v)
+		return true
+	})
+}
+
+func sortNestedAttributes(v pcommon.Value) {
+	switch v.Type() {
+	case pcommon.ValueTypeMap:
+		sortAttributes(v.MapVal())
+	case pcommon.ValueTypeSlice:
+		values := v.SliceVal()
+		for i := 0; i < values.Len(); i++ {
+			sortNestedAttributes(values.At(i))
+		}
+	}
+}
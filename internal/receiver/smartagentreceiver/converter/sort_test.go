@@ -0,0 +1,123 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	sfx "github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/signalfx/golib/v3/trace"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+func keys(attrs pcommon.Map) []string {
+	var result []string
+	attrs.Range(func(k string, _ pcommon.Value) bool {
+		result = append(result, k)
+		return true
+	})
+	return result
+}
+
+// manyKeys returns a map whose iteration order is very unlikely to be sorted.
+func manyKeys(prefix string) (map[string]string, []string) {
+	m := map[string]string{}
+	var sorted []string
+	for i := 0; i < 20; i++ {
+		k := fmt.Sprintf("%s%02d", prefix, i)
+		m[k] = "value"
+		sorted = append(sorted, k)
+	}
+	return m, sorted
+}
+
+func TestSortedMetricsAttributes(t *testing.T) {
+	dimensions, sorted := manyKeys("dim")
+	dimensions["host"] = "my-host"
+
+	md, err := NewTranslator(zap.NewNop(), WithDimensionTranslation(), WithSortedAttributes()).ToMetrics([]*sfx.Datapoint{
+		sfx.New("gauge", dimensions, sfx.NewIntValue(1), sfx.Gauge, time.Now()),
+	})
+	require.NoError(t, err)
+	rm := md.ResourceMetrics().At(0)
+	assert.Equal(t, []string{"host.name"}, keys(rm.Resource().Attributes()))
+	assert.Equal(t, sorted, keys(rm.ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0).Attributes()))
+}
+
+func TestSortedLogsAttributes(t *testing.T) {
+	dimensions, sortedDimensions := manyKeys("dim")
+	properties := map[string]any{}
+	var sortedProperties []string
+	for i := 0; i < 20; i++ {
+		property := fmt.Sprintf("property%02d", i)
+		properties[property] = "value"
+		sortedProperties = append(sortedProperties, property)
+	}
+
+	ld, err := NewTranslator(zap.NewNop(), WithSortedAttributes()).ToLogs(&event.Event{
+		EventType:  "event",
+		Category:   event.USERDEFINED,
+		Dimensions: dimensions,
+		Properties: properties,
+		Timestamp:  time.Now(),
+	})
+	require.NoError(t, err)
+	attrs := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+
+	expected := append([]string{
+		SFxEventCategoryKey, SFxEventPropertiesKey, SFxEventType,
+	}, sortedDimensions...)
+	assert.ElementsMatch(t, expected, keys(attrs))
+	assert.IsIncreasing(t, keys(attrs))
+
+	propertiesValue, ok := attrs.Get(SFxEventPropertiesKey)
+	require.True(t, ok)
+	assert.Equal(t, sortedProperties, keys(propertiesValue.MapVal()))
+}
+
+func TestSortedTracesAttributes(t *testing.T) {
+	tags, sorted := manyKeys("tag")
+	traceID, spanID := "0123456789abcdef", "0123456789abcdef"
+
+	td, err := NewTranslator(zap.NewNop(), WithSortedAttributes()).ToTraces([]*trace.Span{
+		{TraceID: traceID, ID: spanID, Tags: tags},
+	})
+	require.NoError(t, err)
+	attrs := td.ResourceSpans().At(0).ScopeSpans().At(0).Spans().At(0).Attributes()
+	for _, k := range sorted {
+		_, ok := attrs.Get(k)
+		assert.True(t, ok, k)
+	}
+	assert.IsIncreasing(t, keys(attrs))
+}
+
+func TestSortAttributesNested(t *testing.T) {
+	attrs := newAttributeMap(map[string]any{
+		"b": "b",
+		"a": map[string]any{"z": "z", "y": "y", "x": map[string]any{"2": "2", "1": "1"}},
+	})
+	sortAttributes(attrs)
+	assert.Equal(t, []string{"a", "b"}, keys(attrs))
+	a, _ := attrs.Get("a")
+	assert.Equal(t, []string{"x", "y", "z"}, keys(a.MapVal()))
+	x, _ := a.MapVal().Get("x")
+	assert.Equal(t, []string{"1", "2"}, keys(x.MapVal()))
+}
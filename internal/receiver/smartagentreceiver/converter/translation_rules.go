@@ -0,0 +1,245 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"gopkg.in/yaml.v2"
+)
+
+// TranslationAction is the action of a TranslationRule.
+type TranslationAction string
+
+const (
+	// ActionRenameDimensionKeys renames the dimension keys of the Mapping, of the MetricNames datapoints if set.
+	ActionRenameDimensionKeys TranslationAction = "rename_dimension_keys"
+	// ActionRenameMetrics renames the metrics of the Mapping.
+	ActionRenameMetrics TranslationAction = "rename_metrics"
+	// ActionCopyMetrics copies the datapoints of the Mapping metrics to their new names, only those whose
+	// DimensionKey value is one of the DimensionValues if set.
+	ActionCopyMetrics TranslationAction = "copy_metrics"
+	// ActionMultiplyInt multiplies the integer values of the ScaleFactorsInt metrics.
+	ActionMultiplyInt TranslationAction = "multiply_int"
+	// ActionDivideInt divides the integer values of the ScaleFactorsInt metrics.
+	ActionDivideInt TranslationAction = "divide_int"
+	// ActionMultiplyFloat multiplies the float values of the ScaleFactorsFloat metrics.
+	ActionMultiplyFloat TranslationAction = "multiply_float"
+	// ActionDropMetrics drops the datapoints of the MetricNames metrics.
+	ActionDropMetrics TranslationAction = "drop_metrics"
+	// ActionDropDimensions drops the DimensionPairs dimensions, of any value if their value set is empty,
+	// of the MetricNames datapoints if set.
+	ActionDropDimensions TranslationAction = "drop_dimensions"
+)
+
+// TranslationRule is a metric or dimension translation rule applied to Smart Agent datapoints before their
+// conversion, with the keys and semantics of the signalfx exporter's translation_rules so that the rules of
+// either can be reused by the other.
+type TranslationRule struct {
+	Action            TranslationAction          `yaml:"action"`
+	Mapping           map[string]string          `yaml:"mapping"`
+	ScaleFactorsInt   map[string]int64           `yaml:"scale_factors_int"`
+	ScaleFactorsFloat map[string]float64         `yaml:"scale_factors_float"`
+	MetricNames       map[string]bool            `yaml:"metric_names"`
+	DimensionKey      string                     `yaml:"dimension_key"`
+	DimensionValues   map[string]bool            `yaml:"dimension_values"`
+	DimensionPairs    map[string]map[string]bool `yaml:"dimension_pairs"`
+}
+
+// LoadTranslationRules reads and validates the YAML list of translation rules of the file.
+func LoadTranslationRules(path string) ([]TranslationRule, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var rules []TranslationRule
+	if err = yaml.UnmarshalStrict(content, &rules); err != nil {
+		return nil, fmt.Errorf("failed parsing translation rules of %s: %w", path, err)
+	}
+	for i, rule := range rules {
+		if err = rule.validate(); err != nil {
+			return nil, fmt.Errorf("invalid translation rule %d of %s: %w", i, path, err)
+		}
+	}
+	return rules, nil
+}
+
+func (r TranslationRule) validate() error {
+	switch r.Action {
+	case ActionRenameDimensionKeys, ActionRenameMetrics:
+		if len(r.Mapping) == 0 {
+			return fmt.Errorf("field \"mapping\" is required for %q translation rules", r.Action)
+		}
+	case ActionCopyMetrics:
+		if len(r.Mapping) == 0 {
+			return fmt.Errorf("field \"mapping\" is required for %q translation rules", r.Action)
+		}
+		if r.DimensionKey == "" && len(r.DimensionValues) != 0 {
+			return fmt.Errorf("field \"dimension_values\" requires \"dimension_key\" for %q translation rules", r.Action)
+		}
+	case ActionMultiplyInt, ActionDivideInt:
+		if len(r.ScaleFactorsInt) == 0 {
+			return fmt.Errorf("field \"scale_factors_int\" is required for %q translation rules", r.Action)
+		}
+		if r.Action == ActionDivideInt {
+			for metric, factor := range r.ScaleFactorsInt {
+				if factor == 0 {
+					return fmt.Errorf("the %q scale factor of %q translation rules can't be 0", metric, r.Action)
+				}
+			}
+		}
+	case ActionMultiplyFloat:
+		if len(r.ScaleFactorsFloat) == 0 {
+			return fmt.Errorf("field \"scale_factors_float\" is required for %q translation rules", r.Action)
+		}
+	case ActionDropMetrics:
+		if len(r.MetricNames) == 0 {
+			return fmt.Errorf("field \"metric_names\" is required for %q translation rules", r.Action)
+		}
+	case ActionDropDimensions:
+		if len(r.DimensionPairs) == 0 {
+			return fmt.Errorf("field \"dimension_pairs\" is required for %q translation rules", r.Action)
+		}
+	default:
+		return fmt.Errorf("unsupported translation rule action %q", r.Action)
+	}
+	return nil
+}
+
+// applyTranslationRules returns copies of the datapoints translated by the rules, in order.  The provided datapoints
+// aren't changed since monitors can share them, and their dimensions, between datapoints and outputs.
+func applyTranslationRules(rules []TranslationRule, datapoints []*datapoint.Datapoint) []*datapoint.Datapoint {
+	translated := make([]*datapoint.Datapoint, len(datapoints))
+	for i, dp := range datapoints {
+		// the dimensions are copied once changed
+		copied := *dp
+		translated[i] = &copied
+	}
+	datapoints = translated
+
+	for _, rule := range rules {
+		switch rule.Action {
+		case ActionRenameDimensionKeys:
+			for _, dp := range datapoints {
+				if len(rule.MetricNames) != 0 && !rule.MetricNames[dp.Metric] {
+					continue
+				}
+				var dimensions map[string]string
+				for key, value := range dp.Dimensions {
+					newKey, ok := rule.Mapping[key]
+					if !ok {
+						continue
+					}
+					if dimensions == nil {
+						dimensions = copyDimensions(dp.Dimensions)
+					}
+					delete(dimensions, key)
+					dimensions[newKey] = value
+				}
+				if dimensions != nil {
+					dp.Dimensions = dimensions
+				}
+			}
+		case ActionRenameMetrics:
+			for _, dp := range datapoints {
+				if name, ok := rule.Mapping[dp.Metric]; ok {
+					dp.Metric = name
+				}
+			}
+		case ActionCopyMetrics:
+			var copies []*datapoint.Datapoint
+			for _, dp := range datapoints {
+				name, ok := rule.Mapping[dp.Metric]
+				if !ok {
+					continue
+				}
+				if rule.DimensionKey != "" {
+					value, ok := dp.Dimensions[rule.DimensionKey]
+					if !ok || (len(rule.DimensionValues) != 0 && !rule.DimensionValues[value]) {
+						continue
+					}
+				}
+				copied := *dp
+				copied.Metric = name
+				copied.Dimensions = copyDimensions(dp.Dimensions)
+				copies = append(copies, &copied)
+			}
+			datapoints = append(datapoints, copies...)
+		case ActionMultiplyInt, ActionDivideInt:
+			for _, dp := range datapoints {
+				factor, ok := rule.ScaleFactorsInt[dp.Metric]
+				if !ok {
+					continue
+				}
+				if value, ok := dp.Value.(datapoint.IntValue); ok {
+					if rule.Action == ActionMultiplyInt {
+						dp.Value = datapoint.NewIntValue(value.Int() * factor)
+					} else {
+						dp.Value = datapoint.NewIntValue(value.Int() / factor)
+					}
+				}
+			}
+		case ActionMultiplyFloat:
+			for _, dp := range datapoints {
+				factor, ok := rule.ScaleFactorsFloat[dp.Metric]
+				if !ok {
+					continue
+				}
+				if value, ok := dp.Value.(datapoint.FloatValue); ok {
+					dp.Value = datapoint.NewFloatValue(value.Float() * factor)
+				}
+			}
+		case ActionDropMetrics:
+			kept := make([]*datapoint.Datapoint, 0, len(datapoints))
+			for _, dp := range datapoints {
+				if !rule.MetricNames[dp.Metric] {
+					kept = append(kept, dp)
+				}
+			}
+			datapoints = kept
+		case ActionDropDimensions:
+			for _, dp := range datapoints {
+				if len(rule.MetricNames) != 0 && !rule.MetricNames[dp.Metric] {
+					continue
+				}
+				var dimensions map[string]string
+				for key, values := range rule.DimensionPairs {
+					value, ok := dp.Dimensions[key]
+					if !ok || (len(values) != 0 && !values[value]) {
+						continue
+					}
+					if dimensions == nil {
+						dimensions = copyDimensions(dp.Dimensions)
+					}
+					delete(dimensions, key)
+				}
+				if dimensions != nil {
+					dp.Dimensions = dimensions
+				}
+			}
+		}
+	}
+	return datapoints
+}
+
+func copyDimensions(dimensions map[string]string) map[string]string {
+	copied := make(map[string]string, len(dimensions))
+	for key, value := range dimensions {
+		copied[key] = value
+	}
+	return copied
+}
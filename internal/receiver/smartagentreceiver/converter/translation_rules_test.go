@@ -0,0 +1,169 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package converter
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	sfx "github.com/signalfx/golib/v3/datapoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.uber.org/zap"
+)
+
+func TestLoadTranslationRules(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte(`
+- action: copy_metrics
+  mapping:
+    cpu.idle: cpu.idle.total
+  dimension_key: cpu
+  dimension_values:
+    "0": true
+- action: drop_dimensions
+  metric_names:
+    cpu.idle: true
+  dimension_pairs:
+    plugin: {}
+`), 0600))
+
+	rules, err := LoadTranslationRules(path)
+	require.NoError(t, err)
+	assert.Equal(t, []TranslationRule{
+		{
+			Action:          ActionCopyMetrics,
+			Mapping:         map[string]string{"cpu.idle": "cpu.idle.total"},
+			DimensionKey:    "cpu",
+			DimensionValues: map[string]bool{"0": true},
+		},
+		{
+			Action:         ActionDropDimensions,
+			MetricNames:    map[string]bool{"cpu.idle": true},
+			DimensionPairs: map[string]map[string]bool{"plugin": {}},
+		},
+	}, rules)
+}
+
+func TestLoadInvalidTranslationRules(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		rules         string
+		expectedError string
+	}{
+		{
+			name:          "unknown field",
+			rules:         "- action: rename_metrics\n  mappings: {a: b}\n",
+			expectedError: "failed parsing translation rules",
+		},
+		{
+			name:          "unsupported action",
+			rules:         "- action: aggregate_metric\n",
+			expectedError: `invalid translation rule 0 of`,
+		},
+		{
+			name:          "missing mapping",
+			rules:         "- action: rename_metrics\n  mapping: {a: b}\n- action: copy_metrics\n",
+			expectedError: `field "mapping" is required for "copy_metrics" translation rules`,
+		},
+		{
+			name:          "dimension values without key",
+			rules:         "- action: copy_metrics\n  mapping: {a: b}\n  dimension_values: {c: true}\n",
+			expectedError: `field "dimension_values" requires "dimension_key"`,
+		},
+		{
+			name:          "zero divisor",
+			rules:         "- action: divide_int\n  scale_factors_int: {a: 0}\n",
+			expectedError: `the "a" scale factor of "divide_int" translation rules can't be 0`,
+		},
+		{
+			name:          "missing metric names",
+			rules:         "- action: drop_metrics\n",
+			expectedError: `field "metric_names" is required for "drop_metrics" translation rules`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "rules.yaml")
+			require.NoError(t, os.WriteFile(path, []byte(tt.rules), 0600))
+			rules, err := LoadTranslationRules(path)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Nil(t, rules)
+		})
+	}
+}
+
+func TestTranslationRules(t *testing.T) {
+	shared := map[string]string{"host": "one", "plugin": "cpu", "cpu": "0"}
+	now := time.Now()
+	datapoints := []*sfx.Datapoint{
+		sfx.New("cpu.idle", shared, sfx.NewIntValue(90), sfx.Counter, now),
+		sfx.New("cpu.user", shared, sfx.NewIntValue(10), sfx.Counter, now),
+		sfx.New("load.shortterm", map[string]string{"host": "one", "plugin": "load"}, sfx.NewFloatValue(0.5), sfx.Gauge, now),
+		sfx.New("memory.used", map[string]string{"host": "one", "plugin": "memory"}, sfx.NewIntValue(2048), sfx.Gauge, now),
+	}
+
+	md, err := NewTranslator(zap.NewNop(), WithTranslationRules([]TranslationRule{
+		{Action: ActionCopyMetrics, Mapping: map[string]string{"cpu.idle": "cpu.idle.0"}, DimensionKey: "cpu", DimensionValues: map[string]bool{"0": true}},
+		{Action: ActionCopyMetrics, Mapping: map[string]string{"cpu.user": "cpu.user.1"}, DimensionKey: "cpu", DimensionValues: map[string]bool{"1": true}},
+		{Action: ActionRenameMetrics, Mapping: map[string]string{"memory.used": "memory.used_kb"}},
+		{Action: ActionDivideInt, ScaleFactorsInt: map[string]int64{"memory.used_kb": 1024}},
+		{Action: ActionMultiplyInt, ScaleFactorsInt: map[string]int64{"cpu.idle.0": 100}},
+		{Action: ActionMultiplyFloat, ScaleFactorsFloat: map[string]float64{"load.shortterm": 4}},
+		{Action: ActionRenameDimensionKeys, Mapping: map[string]string{"cpu": "cpu.id"}, MetricNames: map[string]bool{"cpu.idle.0": true}},
+		{Action: ActionDropDimensions, DimensionPairs: map[string]map[string]bool{"plugin": {"cpu": true, "memory": true}}},
+		{Action: ActionDropMetrics, MetricNames: map[string]bool{"cpu.user": true}},
+	})).ToMetrics(datapoints)
+	require.NoError(t, err)
+
+	metrics := map[string]pmetric.NumberDataPoint{}
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ms := rms.At(i).ScopeMetrics().At(0).Metrics()
+		for j := 0; j < ms.Len(); j++ {
+			m := ms.At(j)
+			if m.DataType() == pmetric.MetricDataTypeGauge {
+				metrics[m.Name()] = m.Gauge().DataPoints().At(0)
+			} else {
+				metrics[m.Name()] = m.Sum().DataPoints().At(0)
+			}
+		}
+	}
+	require.Len(t, metrics, 4)
+
+	assert.EqualValues(t, 90, metrics["cpu.idle"].IntVal())
+	assert.Equal(t, map[string]any{"host": "one", "cpu": "0"}, metrics["cpu.idle"].Attributes().AsRaw())
+
+	assert.EqualValues(t, 9000, metrics["cpu.idle.0"].IntVal())
+	assert.Equal(t, map[string]any{"host": "one", "cpu.id": "0"}, metrics["cpu.idle.0"].Attributes().AsRaw())
+
+	assert.Equal(t, 2.0, metrics["load.shortterm"].DoubleVal())
+	assert.Equal(t, map[string]any{"host": "one", "plugin": "load"}, metrics["load.shortterm"].Attributes().AsRaw())
+
+	assert.EqualValues(t, 2, metrics["memory.used_kb"].IntVal())
+	assert.Equal(t, map[string]any{"host": "one"}, metrics["memory.used_kb"].Attributes().AsRaw())
+
+	// the monitor's datapoints and shared dimensions are left as is
+	assert.Equal(t, map[string]string{"host": "one", "plugin": "cpu", "cpu": "0"}, shared)
+	require.Len(t, datapoints, 4)
+	assert.Equal(t, "load.shortterm", datapoints[2].Metric)
+	assert.Equal(t, sfx.NewFloatValue(0.5), datapoints[2].Value)
+	assert.Equal(t, "memory.used", datapoints[3].Metric)
+	assert.Equal(t, sfx.NewIntValue(2048), datapoints[3].Value)
+	assert.Equal(t, map[string]string{"host": "one", "plugin": "memory"}, datapoints[3].Dimensions)
+}
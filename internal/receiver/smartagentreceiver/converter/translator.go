@@ -27,21 +27,155 @@ import (
 )
 
 type Translator struct {
-	logger *zap.Logger
+	logger              *zap.Logger
+	translateDimensions bool
+	attributeLimits     AttributeLimits
+	metaAttributes      map[string]string
+	metricNames         Renames
+	eventDimensions     EventDimensionsTarget
+	sortAttributes      bool
+	ingestionLatency    bool
+	translationRules    []TranslationRule
+	// the translationRules followed by the metricNames renaming, and the rule renaming them back
+	metricsRules        []TranslationRule
+	restoreMetricsRules []TranslationRule
 }
 
-func NewTranslator(logger *zap.Logger) Translator {
-	return Translator{logger: logger}
+// TranslatorOption configures optional Translator behavior.
+type TranslatorOption func(*Translator)
+
+// WithDimensionTranslation converts well-known SignalFx dimensions (e.g. host, kubernetes_pod_name, container_id)
+// to their OpenTelemetry semantic convention resource attributes (e.g. host.name, k8s.pod.name, container.id)
+// for metrics and logs.
+func WithDimensionTranslation() TranslatorOption {
+	return func(t *Translator) {
+		t.translateDimensions = true
+	}
+}
+
+// WithAttributeLimits truncates the attributes of translated datapoints and events to the provided limits,
+// marking those that were truncated with the AttributesTruncatedKey attribute.
+func WithAttributeLimits(limits AttributeLimits) TranslatorOption {
+	return func(t *Translator) {
+		t.attributeLimits = limits
+	}
+}
+
+// WithDatapointMetaAttributes adds the values of datapoint Meta entries, keyed by the string form of their keys,
+// as the mapped datapoint attributes.  Meta entries are otherwise dropped, and datapoint dimensions take precedence.
+func WithDatapointMetaAttributes(metaAttributes map[string]string) TranslatorOption {
+	return func(t *Translator) {
+		t.metaAttributes = metaAttributes
+	}
+}
+
+// WithMetricNames renames translated metrics from their Smart Agent names to the mapped ones, like
+// OpenTelemetry semantic convention names, after any WithTranslationRules.  Unmapped metrics keep their Smart Agent
+// names.  RestoreMetricNames renames them back.
+func WithMetricNames(metricNames Renames) TranslatorOption {
+	return func(t *Translator) {
+		t.metricNames = metricNames
+	}
+}
+
+// WithTranslationRules applies the translation rules to the Smart Agent datapoints before their conversion, and
+// before any WithMetricNames renaming, which is applied as a rename_metrics rule of the same translation.
+func WithTranslationRules(rules []TranslationRule) TranslatorOption {
+	return func(t *Translator) {
+		t.translationRules = rules
+	}
+}
+
+// WithEventDimensionsTarget adds the dimensions of translated events as attributes of the provided target instead
+// of the log record, like the resource for pipelines routing by resource attributes.
+func WithEventDimensionsTarget(target EventDimensionsTarget) TranslatorOption {
+	return func(t *Translator) {
+		t.eventDimensions = target
+	}
+}
+
+// WithSortedAttributes sorts the resource, datapoint, event, and span attributes of translated content,
+// including event properties, by key.  Their order otherwise differs between runs, which prevents golden file
+// comparisons and hashing of the translated content.
+func WithSortedAttributes() TranslatorOption {
+	return func(t *Translator) {
+		t.sortAttributes = true
+	}
+}
+
+// WithEventIngestionLatency adds the nanoseconds between the timestamp of translated events and their observed
+// timestamp, when they were translated, as the SFxEventIngestionLatencyKey attribute for debugging late-arriving
+// events, like those of monitors whose source clock is skewed.
+func WithEventIngestionLatency() TranslatorOption {
+	return func(t *Translator) {
+		t.ingestionLatency = true
+	}
+}
+
+func NewTranslator(logger *zap.Logger, options ...TranslatorOption) Translator {
+	translator := Translator{logger: logger}
+	for _, option := range options {
+		option(&translator)
+	}
+	translator.metricsRules = translator.translationRules
+	if translator.metricNames.Len() > 0 {
+		translator.metricsRules = append(
+			translator.metricsRules[:len(translator.metricsRules):len(translator.metricsRules)],
+			translator.metricNames.TranslationRule(ActionRenameMetrics),
+		)
+		translator.restoreMetricsRules = []TranslationRule{translator.metricNames.Reversed().TranslationRule(ActionRenameMetrics)}
+	}
+	return translator
+}
+
+// RestoreMetricNames returns the datapoints with the names mapped by WithMetricNames, like those of content renamed
+// by another collector, renamed back to their Smart Agent names, which monitor filtering and translation rules use.
+// The provided datapoints aren't changed.
+func (c Translator) RestoreMetricNames(datapoints []*datapoint.Datapoint) []*datapoint.Datapoint {
+	for _, dp := range datapoints {
+		if _, ok := c.metricNames.Original(dp.Metric); ok {
+			return applyTranslationRules(c.restoreMetricsRules, datapoints)
+		}
+	}
+	return datapoints
 }
 
 func (c Translator) ToMetrics(datapoints []*datapoint.Datapoint) (pmetric.Metrics, error) {
-	return sfxDatapointsToPDataMetrics(datapoints, time.Now(), c.logger), nil
+	if len(c.metricsRules) > 0 {
+		datapoints = applyTranslationRules(c.metricsRules, datapoints)
+	}
+	md := sfxDatapointsToPDataMetrics(datapoints, time.Now(), c.translateDimensions, c.metaAttributes, c.logger)
+	if c.attributeLimits.enabled() {
+		if truncated := c.attributeLimits.applyToMetrics(md); truncated > 0 {
+			c.logger.Debug("Truncated datapoint attributes exceeding limits", zap.Int("numTruncated", truncated))
+		}
+	}
+	if c.sortAttributes {
+		sortMetricsAttributes(md)
+	}
+	return md, nil
 }
 
 func (c Translator) ToLogs(event *event.Event) (plog.Logs, error) {
-	return sfxEventToPDataLogs(event, c.logger), nil
+	ld := sfxEventToPDataLogs(event, time.Now(), c.translateDimensions, c.eventDimensions, c.logger)
+	if c.ingestionLatency {
+		setIngestionLatency(ld)
+	}
+	if c.attributeLimits.enabled() {
+		if truncated := c.attributeLimits.applyToLogs(ld); truncated > 0 {
+			c.logger.Debug("Truncated event attributes exceeding limits", zap.Int("numTruncated", truncated))
+		}
+	}
+	if c.sortAttributes {
+		sortLogsAttributes(ld)
+	}
+	return ld, nil
 }
 
 func (c Translator) ToTraces(spans []*trace.Span) (ptrace.Traces, error) {
-	return sfxSpansToPDataTraces(spans, c.logger)
+	td, err := sfxSpansToPDataTraces(spans, c.logger)
+	if c.sortAttributes {
+		sortTracesAttributes(td)
+	}
+	return td, err
 }
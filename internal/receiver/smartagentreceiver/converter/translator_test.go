@@ -16,8 +16,12 @@ package converter
 
 import (
 	"testing"
+	"time"
 
+	sfx "github.com/signalfx/golib/v3/datapoint"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pmetric"
 	"go.uber.org/zap"
 )
 
@@ -27,3 +31,96 @@ func TestNewConverter(t *testing.T) {
 	assert.NotNil(t, c)
 	assert.Same(t, logger, c.logger)
 }
+
+func TestNewConverterWithDimensionTranslation(t *testing.T) {
+	assert.False(t, NewTranslator(zap.NewNop()).translateDimensions)
+	assert.True(t, NewTranslator(zap.NewNop(), WithDimensionTranslation()).translateDimensions)
+}
+
+func TestNewConverterWithAttributeLimits(t *testing.T) {
+	assert.False(t, NewTranslator(zap.NewNop()).attributeLimits.enabled())
+	limits := AttributeLimits{MaxCount: 10, MaxValueLength: 100}
+	assert.Equal(t, limits, NewTranslator(zap.NewNop(), WithAttributeLimits(limits)).attributeLimits)
+}
+
+func TestNewConverterWithDatapointMetaAttributes(t *testing.T) {
+	assert.Nil(t, NewTranslator(zap.NewNop()).metaAttributes)
+	metaAttributes := map[string]string{"endpoint_id": "sfx.endpoint.id"}
+	assert.Equal(t, metaAttributes, NewTranslator(zap.NewNop(), WithDatapointMetaAttributes(metaAttributes)).metaAttributes)
+}
+
+func TestNewConverterWithSortedAttributes(t *testing.T) {
+	assert.False(t, NewTranslator(zap.NewNop()).sortAttributes)
+	assert.True(t, NewTranslator(zap.NewNop(), WithSortedAttributes()).sortAttributes)
+}
+
+func TestNewConverterWithMetricNames(t *testing.T) {
+	assert.Zero(t, NewTranslator(zap.NewNop()).metricNames.Len())
+	assert.Empty(t, NewTranslator(zap.NewNop()).metricsRules)
+
+	metricNames := mustNewRenames(map[string]string{"cpu.utilization": "system.cpu.utilization"})
+	rules := []TranslationRule{{Action: ActionDropMetrics, MetricNames: map[string]bool{"cpu.idle": true}}}
+	translator := NewTranslator(zap.NewNop(), WithMetricNames(metricNames), WithTranslationRules(rules))
+	assert.Equal(t, metricNames, translator.metricNames)
+	assert.Equal(t, []TranslationRule{
+		rules[0],
+		{Action: ActionRenameMetrics, Mapping: map[string]string{"cpu.utilization": "system.cpu.utilization"}},
+	}, translator.metricsRules)
+	assert.Equal(t, []TranslationRule{
+		{Action: ActionRenameMetrics, Mapping: map[string]string{"system.cpu.utilization": "cpu.utilization"}},
+	}, translator.restoreMetricsRules)
+	// the translation rules aren't appended to
+	assert.Len(t, rules, 1)
+}
+
+func TestRenamedMetrics(t *testing.T) {
+	translator := NewTranslator(zap.NewNop(), WithDimensionTranslation(), WithMetricNames(mustNewRenames(map[string]string{
+		"cpu.utilization": "system.cpu.utilization",
+		"memory.used":     "system.memory.usage",
+	})))
+	datapoints := []*sfx.Datapoint{
+		sfx.New("cpu.utilization", map[string]string{"host": "one"}, sfx.NewFloatValue(12.5), sfx.Gauge, time.Now()),
+		sfx.New("memory.used", map[string]string{"host": "two"}, sfx.NewIntValue(1024), sfx.Gauge, time.Now()),
+		sfx.New("disk_ops.read", map[string]string{"host": "two"}, sfx.NewIntValue(1), sfx.Counter, time.Now()),
+	}
+	md, err := translator.ToMetrics(datapoints)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"system.cpu.utilization", "system.memory.usage", "disk_ops.read"}, metricNamesOf(md))
+	assert.Equal(t, "cpu.utilization", datapoints[0].Metric)
+
+	// datapoints already renamed, like by another collector, are renamed back, and so round trip
+	renamed := []*sfx.Datapoint{
+		sfx.New("system.cpu.utilization", map[string]string{"host": "one"}, sfx.NewFloatValue(12.5), sfx.Gauge, time.Now()),
+		sfx.New("disk_ops.read", map[string]string{"host": "two"}, sfx.NewIntValue(1), sfx.Counter, time.Now()),
+	}
+	restored := translator.RestoreMetricNames(renamed)
+	require.Len(t, restored, 2)
+	assert.Equal(t, "cpu.utilization", restored[0].Metric)
+	assert.Equal(t, "disk_ops.read", restored[1].Metric)
+	assert.Equal(t, "system.cpu.utilization", renamed[0].Metric)
+
+	md, err = translator.ToMetrics(restored)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"system.cpu.utilization", "disk_ops.read"}, metricNamesOf(md))
+
+	// datapoints without renamed names are returned as is
+	assert.Equal(t, datapoints[2:], translator.RestoreMetricNames(datapoints[2:]))
+	assert.Same(t, datapoints[2], translator.RestoreMetricNames(datapoints[2:])[0])
+}
+
+func metricNamesOf(md pmetric.Metrics) []string {
+	var names []string
+	rms := md.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		ms := rms.At(i).ScopeMetrics().At(0).Metrics()
+		for j := 0; j < ms.Len(); j++ {
+			names = append(names, ms.At(j).Name())
+		}
+	}
+	return names
+}
+
+func TestNewConverterWithEventDimensionsTarget(t *testing.T) {
+	assert.Empty(t, NewTranslator(zap.NewNop()).eventDimensions)
+	assert.Equal(t, EventDimensionsToResource, NewTranslator(zap.NewNop(), WithEventDimensionsTarget(EventDimensionsToResource)).eventDimensions)
+}
@@ -0,0 +1,51 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+
+	"go.opentelemetry.io/collector/confmap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/correlation"
+)
+
+// traceMonitorTypes are the monitor types sending spans, whose services and environments can be correlated.
+var traceMonitorTypes = map[string]bool{
+	"signalfx-forwarder": true,
+	"trace-forwarder":    true,
+}
+
+// getCorrelationFromAllSettings returns the correlation config of the correlation option, with the defaults
+// of the options it doesn't set, or nil without it.
+func getCorrelationFromAllSettings(allSettings map[string]any) (*correlation.Config, error) {
+	value, ok := allSettings["correlation"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "correlation")
+	valueAsMap, isMap := value.(map[string]any)
+	if !isMap {
+		return nil, errCorrelationValue
+	}
+	cfg := correlation.DefaultConfig()
+	if err := confmap.NewFromStringMap(valueAsMap).UnmarshalExact(&cfg); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorrelationValue, err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCorrelationValue, err)
+	}
+	return &cfg, nil
+}
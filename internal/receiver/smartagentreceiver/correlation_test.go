@@ -0,0 +1,154 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/trace"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/cpu"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/correlation"
+)
+
+type fakeCorrelator struct {
+	correlations []correlation.Correlation
+	lock         sync.Mutex
+}
+
+func (c *fakeCorrelator) Correlate(_ context.Context, value correlation.Correlation) error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.correlations = append(c.correlations, value)
+	return nil
+}
+
+func (c *fakeCorrelator) Delete(context.Context, correlation.Correlation) error {
+	return nil
+}
+
+func (c *fakeCorrelator) created() []correlation.Correlation {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return append([]correlation.Correlation(nil), c.correlations...)
+}
+
+func TestGetCorrelationFromAllSettings(t *testing.T) {
+	cfg, err := getCorrelationFromAllSettings(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, cfg)
+
+	allSettings := map[string]any{
+		"correlation": map[string]any{
+			"endpoint":        "https://api.us0.signalfx.com",
+			"access_token":    "token",
+			"sync_attributes": map[string]any{"k8s.pod.name": "kubernetes_pod_name"},
+			"flush_interval":  "10s",
+		},
+	}
+	cfg, err = getCorrelationFromAllSettings(allSettings)
+	require.NoError(t, err)
+	expected := correlation.DefaultConfig()
+	expected.Endpoint = "https://api.us0.signalfx.com"
+	expected.AccessToken = "token"
+	// added to the default ones
+	expected.SyncAttributes["k8s.pod.name"] = "kubernetes_pod_name"
+	expected.FlushInterval = 10 * time.Second
+	assert.Equal(t, &expected, cfg)
+	assert.Empty(t, allSettings)
+}
+
+func TestInvalidCorrelation(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		value         any
+		expectedError string
+	}{
+		{
+			name:          "not a map",
+			value:         "https://api.us0.signalfx.com",
+			expectedError: "correlation must be a map with the endpoint and access_token of the SignalFx API",
+		},
+		{
+			name:          "unknown field",
+			value:         map[string]any{"endpoint": "https://api.us0.signalfx.com", "access_token": "token", "realm": "us0"},
+			expectedError: "correlation must be a map with the endpoint and access_token of the SignalFx API: ",
+		},
+		{
+			name:          "no access token",
+			value:         map[string]any{"endpoint": "https://api.us0.signalfx.com"},
+			expectedError: "correlation must be a map with the endpoint and access_token of the SignalFx API: access_token must not be empty",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := getCorrelationFromAllSettings(map[string]any{"correlation": tt.value})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Nil(t, cfg)
+		})
+	}
+}
+
+func TestCorrelationRequiresTraceMonitor(t *testing.T) {
+	cfg := Config{
+		monitorConfig: &cpu.Config{},
+		Correlation:   &correlation.Config{},
+	}
+	cfg.monitorConfig.MonitorConfigCore().Type = "signalfx-forwarder"
+	assert.NoError(t, cfg.validate())
+
+	cfg.monitorConfig.MonitorConfigCore().Type = "trace-forwarder"
+	assert.NoError(t, cfg.validate())
+
+	cfg.monitorConfig.MonitorConfigCore().Type = "cpu"
+	assert.EqualError(t, cfg.validate(), `correlation is only supported by the signalfx-forwarder and trace-forwarder monitors, not "cpu"`)
+}
+
+func TestSendSpansCorrelatesServices(t *testing.T) {
+	cfg := correlation.DefaultConfig()
+	cfg.FlushInterval = 10 * time.Millisecond
+	correlator := &fakeCorrelator{}
+	tracker := correlation.NewTracker(cfg, correlator, zap.NewNop())
+	tracker.Start()
+	defer func() { require.NoError(t, tracker.Shutdown(context.Background())) }()
+
+	output := NewOutput(
+		Config{}, fakeMonitorFiltering(), consumertest.NewNop(), consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+	output.correlation = tracker
+
+	serviceName := "checkout"
+	output.SendSpans(&trace.Span{
+		TraceID:       "12345678",
+		ID:            "23456789",
+		LocalEndpoint: &trace.Endpoint{ServiceName: &serviceName},
+		Tags:          map[string]string{"host.name": "a.host", "deployment.environment": "production"},
+	})
+
+	require.Eventually(t, func() bool { return len(correlator.created()) == 2 }, 5*time.Second, 10*time.Millisecond)
+	assert.ElementsMatch(t, []correlation.Correlation{
+		{Type: correlation.Service, Value: "checkout", DimensionName: "host", DimensionValue: "a.host"},
+		{Type: correlation.Environment, Value: "production", DimensionName: "host", DimensionValue: "a.host"},
+	}, correlator.created())
+}
@@ -0,0 +1,119 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+const customCollectdMonitorType = "collectd/custom"
+
+// customCollectdTemplates returns the collectd config templates loading the configured types.db files and
+// plugin config files, whose content is read each time the monitor is created so that restarts pick up changes.
+func customCollectdTemplates(typesDB, pluginConfigDirs []string) ([]string, error) {
+	var templates []string
+
+	var typesDBFiles []string
+	for _, path := range typesDB {
+		files, err := typesDBPaths(path)
+		if err != nil {
+			return nil, err
+		}
+		typesDBFiles = append(typesDBFiles, files...)
+	}
+	if len(typesDBFiles) != 0 {
+		// TypesDB statements accumulate, so these are loaded in addition to the agent bundle's types.db
+		quoted := make([]string, len(typesDBFiles))
+		for i, file := range typesDBFiles {
+			quoted[i] = quoteCollectdString(file)
+		}
+		templates = append(templates, "TypesDB "+strings.Join(quoted, " ")+"\n")
+	}
+
+	for _, dir := range pluginConfigDirs {
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			return nil, fmt.Errorf("failed reading collectd plugin config dir: %w", err)
+		}
+		for _, entry := range entries {
+			if entry.IsDir() || filepath.Ext(entry.Name()) != ".conf" {
+				continue
+			}
+			path := filepath.Join(dir, entry.Name())
+			content, err := os.ReadFile(filepath.Clean(path))
+			if err != nil {
+				return nil, fmt.Errorf("failed reading collectd plugin config: %w", err)
+			}
+			templates = append(templates, string(content))
+		}
+	}
+	return templates, nil
+}
+
+// typesDBPaths returns the absolute path of the provided types.db file, or of the files of the provided
+// directory, since collectd resolves relative paths against its own base dir.
+func typesDBPaths(path string) ([]string, error) {
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed resolving collectd types.db path: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading collectd types.db: %w", err)
+	}
+	if !info.IsDir() {
+		return []string{path}, nil
+	}
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed reading collectd types.db dir: %w", err)
+	}
+	var files []string
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			files = append(files, filepath.Join(path, entry.Name()))
+		}
+	}
+	return files, nil
+}
+
+// quoteCollectdString quotes the value as a collectd config string, escaping backslashes and double quotes.
+func quoteCollectdString(value string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value) + `"`
+}
+
+// setCustomCollectdTemplates adds the templates loading the configured types.db and plugin config files to the
+// collectd/custom monitor's own templates.
+func (cfg *Config) setCustomCollectdTemplates() error {
+	templatesField, err := GetSettableStructFieldValue(cfg.monitorConfig, "Templates", reflect.TypeOf([]string(nil)))
+	if err != nil || templatesField == nil {
+		return fmt.Errorf("monitor config of type %q has no templates", cfg.monitorConfig.MonitorConfigCore().Type)
+	}
+	if cfg.monitorTemplates == nil {
+		// the monitor's own templates, to which the loaded ones are added each time the monitor is created
+		cfg.monitorTemplates = append([]string{}, templatesField.Interface().([]string)...)
+	}
+
+	templates, err := customCollectdTemplates(cfg.CollectdTypesDB, cfg.CollectdPluginConfigDirs)
+	if err != nil {
+		return err
+	}
+	templatesField.Set(reflect.ValueOf(append(append([]string{}, cfg.monitorTemplates...), templates...)))
+	return nil
+}
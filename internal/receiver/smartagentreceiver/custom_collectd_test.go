@@ -0,0 +1,75 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomCollectdTemplates(t *testing.T) {
+	dir := t.TempDir()
+	typesDir := filepath.Join(dir, "types")
+	confDir := filepath.Join(dir, "conf.d")
+	for _, d := range []string{typesDir, confDir, filepath.Join(confDir, "disabled")} {
+		require.NoError(t, os.MkdirAll(d, 0o700))
+	}
+	for file, content := range map[string]string{
+		filepath.Join(dir, `my "app" types.db`):      "myapp value:GAUGE:0:U\n",
+		filepath.Join(typesDir, "b.db"):              "b value:GAUGE:0:U\n",
+		filepath.Join(typesDir, "a.db"):              "a value:GAUGE:0:U\n",
+		filepath.Join(confDir, "b.conf"):             "LoadPlugin b\n",
+		filepath.Join(confDir, "a.conf"):             "LoadPlugin a\n",
+		filepath.Join(confDir, "a.conf.disabled"):    "LoadPlugin disabled\n",
+		filepath.Join(confDir, "disabled", "c.conf"): "LoadPlugin c\n",
+	} {
+		require.NoError(t, os.WriteFile(file, []byte(content), 0o600))
+	}
+
+	templates, err := customCollectdTemplates(
+		[]string{filepath.Join(dir, `my "app" types.db`), typesDir}, []string{confDir},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		`TypesDB "` + filepath.Join(dir, `my \"app\" types.db`) + `" "` + filepath.Join(typesDir, "a.db") + `" "` + filepath.Join(typesDir, "b.db") + "\"\n",
+		"LoadPlugin a\n",
+		"LoadPlugin b\n",
+	}, templates)
+
+	templates, err = customCollectdTemplates(nil, []string{confDir})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"LoadPlugin a\n", "LoadPlugin b\n"}, templates)
+}
+
+func TestCustomCollectdTemplatesErrors(t *testing.T) {
+	dir := t.TempDir()
+
+	_, err := customCollectdTemplates([]string{filepath.Join(dir, "missing.db")}, nil)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed reading collectd types.db")
+
+	_, err = customCollectdTemplates(nil, []string{filepath.Join(dir, "missing.d")})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed reading collectd plugin config dir")
+}
+
+func TestQuoteCollectdString(t *testing.T) {
+	assert.Equal(t, `"/opt/types.db"`, quoteCollectdString("/opt/types.db"))
+	assert.Equal(t, `"C:\\types \"custom\".db"`, quoteCollectdString(`C:\types "custom".db`))
+}
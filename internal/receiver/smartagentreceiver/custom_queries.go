@@ -0,0 +1,472 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/go-sql-driver/mysql"
+	_ "github.com/lib/pq" // registers the postgres database/sql driver
+	"github.com/signalfx/golib/v3/datapoint"
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/oracledb"
+)
+
+const defaultCustomQueryMaxRows = 1000
+
+// customQueryDrivers are the database/sql driver names of the monitor types supporting custom queries.
+var customQueryDrivers = map[string]string{
+	"collectd/mysql":      "mysql",
+	"collectd/postgresql": "postgres",
+	"oracledb":            oracledb.DriverName,
+	"postgresql":          "postgres",
+}
+
+// CustomQuery is a user-defined SQL query run against the monitor's database server, whose
+// result rows are sent as datapoints of the monitor.
+type CustomQuery struct {
+	// Bind parameters of the statement's placeholders, like $1 for postgres, ? for mysql, and :1 for oracledb.
+	Params  []any               `mapstructure:"params" yaml:"params"`
+	Metrics []CustomQueryMetric `mapstructure:"metrics" yaml:"metrics"`
+	// The database to query, defaulting to the monitor's first configured database, or the service name for oracledb.
+	Database  string `mapstructure:"database" yaml:"database"`
+	Statement string `mapstructure:"statement" yaml:"statement"`
+	// Defaults to the monitor's intervalSeconds.
+	IntervalSeconds int `mapstructure:"intervalSeconds" yaml:"intervalSeconds"`
+	// The statement timeout, defaulting to the query's interval.
+	TimeoutSeconds int `mapstructure:"timeoutSeconds" yaml:"timeoutSeconds"`
+	// The maximum number of result rows converted to datapoints, defaulting to 1000.
+	MaxRows int `mapstructure:"maxRows" yaml:"maxRows"`
+}
+
+// CustomQueryMetric describes a datapoint of each custom query result row.
+type CustomQueryMetric struct {
+	nameTemplate     *template.Template
+	DimensionColumns []string `mapstructure:"dimensionColumns" yaml:"dimensionColumns"`
+	// A literal metric name or a text/template rendered with the row's column values, like `db.{{.table}}.rows`.
+	MetricName   string `mapstructure:"metricName" yaml:"metricName"`
+	ValueColumn  string `mapstructure:"valueColumn" yaml:"valueColumn"`
+	IsCumulative bool   `mapstructure:"isCumulative" yaml:"isCumulative"`
+}
+
+func getCustomQueriesFromAllSettings(allSettings map[string]any) ([]CustomQuery, error) {
+	value, ok := allSettings["customQueries"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "customQueries")
+	if _, isSlice := value.([]any); !isSlice {
+		return nil, errCustomQueriesValue
+	}
+	asBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errCustomQueriesValue, err)
+	}
+	var queries []CustomQuery
+	if err = yaml.UnmarshalStrict(asBytes, &queries); err != nil {
+		return nil, fmt.Errorf("%w: %v", errCustomQueriesValue, err)
+	}
+	if err = parseCustomQueries(queries); err != nil {
+		return nil, err
+	}
+	return queries, nil
+}
+
+// parseCustomQueries validates the queries and parses their metric name templates.
+func parseCustomQueries(queries []CustomQuery) error {
+	for i := range queries {
+		query := &queries[i]
+		if strings.TrimSpace(query.Statement) == "" {
+			return fmt.Errorf("customQueries[%d]: statement must not be empty", i)
+		}
+		if query.IntervalSeconds < 0 || query.TimeoutSeconds < 0 || query.MaxRows < 0 {
+			return fmt.Errorf("customQueries[%d]: intervalSeconds, timeoutSeconds, and maxRows must be non-negative", i)
+		}
+		if len(query.Metrics) == 0 {
+			return fmt.Errorf("customQueries[%d]: metrics must not be empty", i)
+		}
+		for j := range query.Metrics {
+			metric := &query.Metrics[j]
+			if metric.MetricName == "" {
+				return fmt.Errorf("customQueries[%d].metrics[%d]: metricName must not be empty", i, j)
+			}
+			if metric.ValueColumn == "" {
+				return fmt.Errorf("customQueries[%d].metrics[%d]: valueColumn must not be empty", i, j)
+			}
+			if !strings.Contains(metric.MetricName, "{{") {
+				continue
+			}
+			nameTemplate, err := template.New(metric.MetricName).Option("missingkey=error").Parse(metric.MetricName)
+			if err != nil {
+				return fmt.Errorf("customQueries[%d].metrics[%d]: invalid metricName template: %w", i, j, err)
+			}
+			metric.nameTemplate = nameTemplate
+		}
+	}
+	return nil
+}
+
+// customQueryDataSource returns the driver name and data source name for querying the database of
+// the monitor's server, using the monitor's connection and credential options.
+func customQueryDataSource(monitorConfig saconfig.MonitorCustomConfig, database string) (string, string, error) {
+	monitorType := monitorConfig.MonitorConfigCore().Type
+	driverName, ok := customQueryDrivers[monitorType]
+	if !ok {
+		return "", "", fmt.Errorf("customQueries aren't supported by the %q monitor", monitorType)
+	}
+
+	if oracleConfig, isOracle := monitorConfig.(*oracledb.Config); isOracle {
+		conf := *oracleConfig
+		if database != "" {
+			conf.ServiceName = database
+		}
+		return driverName, oracledb.DataSourceName(&conf), nil
+	}
+
+	options := reflect.Indirect(reflect.ValueOf(monitorConfig))
+	host := stringOption(options, "Host")
+	var port string
+	if portOption := optionField(options, "Port"); portOption.IsValid() {
+		port = fmt.Sprintf("%v", portOption.Interface())
+	}
+	username := stringOption(options, "Username")
+	password := stringOption(options, "Password")
+	var sslMode string
+
+	// collectd based monitors' databases can have their own credentials
+	if databases := optionField(options, "Databases"); databases.Kind() == reflect.Slice {
+		for i := 0; i < databases.Len(); i++ {
+			db := reflect.Indirect(databases.Index(i))
+			if name := stringOption(db, "Name"); database == "" || database == name {
+				database = name
+				if dbUsername := stringOption(db, "Username"); dbUsername != "" {
+					username, password = dbUsername, stringOption(db, "Password")
+				}
+				sslMode = stringOption(db, "SSLMode")
+				break
+			}
+		}
+	}
+
+	if driverName == "mysql" {
+		mysqlConfig := mysql.NewConfig()
+		mysqlConfig.Net = "tcp"
+		mysqlConfig.Addr = net.JoinHostPort(host, port)
+		mysqlConfig.User = username
+		mysqlConfig.Passwd = password
+		mysqlConfig.DBName = database
+		return driverName, mysqlConfig.FormatDSN(), nil
+	}
+
+	var dsn []string
+	if connectionString := stringOption(options, "ConnectionString"); connectionString != "" {
+		// the postgresql monitor's connectionString is a template of its params
+		rendered, err := renderConnectionString(connectionString, optionField(options, "Params"))
+		if err != nil {
+			return "", "", err
+		}
+		dsn = append(dsn, rendered)
+	}
+	if database == "" {
+		database = stringOption(options, "MasterDBName")
+	}
+	for _, option := range [][2]string{
+		{"host", host}, {"port", port}, {"dbname", database}, {"user", username}, {"password", password}, {"sslmode", sslMode},
+	} {
+		if option[1] != "" {
+			dsn = append(dsn, fmt.Sprintf("%s=%s", option[0], quotePostgresOption(option[1])))
+		}
+	}
+	return driverName, strings.Join(dsn, " "), nil
+}
+
+func renderConnectionString(connectionString string, params reflect.Value) (string, error) {
+	tmpl, err := template.New("connectionString").Parse(connectionString)
+	if err != nil {
+		return "", fmt.Errorf("invalid connectionString template: %w", err)
+	}
+	var data any
+	if params.IsValid() {
+		data = params.Interface()
+	}
+	var rendered strings.Builder
+	if err = tmpl.Execute(&rendered, data); err != nil {
+		return "", fmt.Errorf("failed rendering connectionString: %w", err)
+	}
+	return rendered.String(), nil
+}
+
+// quotePostgresOption quotes a libpq key/value connection string value.
+func quotePostgresOption(value string) string {
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
+
+// customQueryRunner periodically runs the custom queries, sending their datapoints to the output.
+type customQueryRunner struct {
+	ctx     context.Context
+	output  types.Output
+	cancel  context.CancelFunc
+	logger  *zap.Logger
+	clock   clock.Clock
+	dbs     map[string]*sql.DB
+	queries []*scheduledCustomQuery
+	wg      sync.WaitGroup
+}
+
+type scheduledCustomQuery struct {
+	db       *sql.DB
+	query    CustomQuery
+	index    int
+	interval time.Duration
+	timeout  time.Duration
+	maxRows  int
+}
+
+func newCustomQueryRunner(
+	queries []CustomQuery, monitorConfig saconfig.MonitorCustomConfig, output types.Output, logger *zap.Logger, clk clock.Clock,
+) (*customQueryRunner, error) {
+	runner := &customQueryRunner{output: output, logger: logger, clock: clk, dbs: map[string]*sql.DB{}}
+	intervalSeconds := monitorConfig.MonitorConfigCore().IntervalSeconds
+	for i, query := range queries {
+		driverName, dsn, err := customQueryDataSource(monitorConfig, query.Database)
+		if err != nil {
+			runner.closeDBs()
+			return nil, err
+		}
+		db, ok := runner.dbs[dsn]
+		if !ok {
+			if db, err = sql.Open(driverName, dsn); err != nil {
+				runner.closeDBs()
+				return nil, fmt.Errorf("failed opening %s database %q: %w", driverName, query.Database, err)
+			}
+			runner.dbs[dsn] = db
+		}
+		runner.queries = append(runner.queries, newScheduledCustomQuery(db, query, i, intervalSeconds))
+	}
+	return runner, nil
+}
+
+func newScheduledCustomQuery(db *sql.DB, query CustomQuery, index, monitorIntervalSeconds int) *scheduledCustomQuery {
+	scheduled := &scheduledCustomQuery{
+		db:       db,
+		query:    query,
+		index:    index,
+		interval: time.Duration(query.IntervalSeconds) * time.Second,
+		timeout:  time.Duration(query.TimeoutSeconds) * time.Second,
+		maxRows:  query.MaxRows,
+	}
+	if scheduled.interval == 0 {
+		scheduled.interval = time.Duration(monitorIntervalSeconds) * time.Second
+	}
+	if scheduled.timeout == 0 {
+		scheduled.timeout = scheduled.interval
+	}
+	if scheduled.maxRows == 0 {
+		scheduled.maxRows = defaultCustomQueryMaxRows
+	}
+	return scheduled
+}
+
+// start runs each query every interval until shutdown.  It's a noop for a nil instance.
+func (r *customQueryRunner) start() {
+	if r == nil {
+		return
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	for _, query := range r.queries {
+		r.wg.Add(1)
+		go r.run(query)
+	}
+}
+
+func (r *customQueryRunner) run(query *scheduledCustomQuery) {
+	defer r.wg.Done()
+	ticker := r.clock.NewTicker(query.interval)
+	defer ticker.Stop()
+	for {
+		r.collect(query)
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.Chan():
+		}
+	}
+}
+
+func (r *customQueryRunner) collect(query *scheduledCustomQuery) {
+	datapoints, truncated, err := query.collect(r.ctx)
+	if err != nil && r.ctx.Err() == nil {
+		r.logger.Error("failed running custom query", zap.Int("query", query.index), zap.Error(err))
+	}
+	if truncated {
+		r.logger.Warn(
+			"Custom query returned more rows than its maxRows, ignoring the remaining rows",
+			zap.Int("query", query.index), zap.Int("maxRows", query.maxRows),
+		)
+	}
+	if len(datapoints) != 0 {
+		r.output.SendDatapoints(datapoints...)
+	}
+}
+
+// shutdown cancels the in-flight queries and closes the database handles.  It's a noop for a nil instance.
+func (r *customQueryRunner) shutdown() {
+	if r == nil {
+		return
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	r.closeDBs()
+}
+
+func (r *customQueryRunner) closeDBs() {
+	for dsn, db := range r.dbs {
+		if err := db.Close(); err != nil {
+			r.logger.Warn("failed closing custom query database", zap.Error(err))
+		}
+		delete(r.dbs, dsn)
+	}
+}
+
+// collect runs the query within its timeout, converting up to maxRows result rows to datapoints.  Rows
+// without a value are skipped, and errors converting a row's datapoint don't prevent converting the others.
+func (q *scheduledCustomQuery) collect(ctx context.Context) ([]*datapoint.Datapoint, bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	rows, err := q.db.QueryContext(ctx, q.query.Statement, q.query.Params...)
+	if err != nil {
+		return nil, false, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, false, err
+	}
+
+	now := time.Now()
+	var datapoints []*datapoint.Datapoint
+	var errs error
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+	for numRows := 0; rows.Next(); numRows++ {
+		if numRows == q.maxRows {
+			return datapoints, true, errs
+		}
+		if err = rows.Scan(dest...); err != nil {
+			return datapoints, false, multierr.Append(errs, err)
+		}
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[column] = values[i]
+			if b, isBytes := values[i].([]byte); isBytes {
+				row[column] = string(b)
+			}
+		}
+		for _, metric := range q.query.Metrics {
+			dp, dpErr := metric.datapoint(row, now)
+			if dpErr != nil {
+				errs = multierr.Append(errs, dpErr)
+			} else if dp != nil {
+				datapoints = append(datapoints, dp)
+			}
+		}
+	}
+	return datapoints, false, multierr.Append(errs, rows.Err())
+}
+
+// datapoint converts the metric of the result row, returning nil if its value column is NULL.
+func (m CustomQueryMetric) datapoint(row map[string]any, timestamp time.Time) (*datapoint.Datapoint, error) {
+	rawValue, ok := row[m.ValueColumn]
+	if !ok {
+		return nil, fmt.Errorf("value column %q isn't in the query results", m.ValueColumn)
+	}
+	if rawValue == nil {
+		return nil, nil
+	}
+	value, err := customQueryValue(rawValue)
+	if err != nil {
+		return nil, fmt.Errorf("value column %q: %w", m.ValueColumn, err)
+	}
+
+	dimensions := make(map[string]string, len(m.DimensionColumns))
+	for _, column := range m.DimensionColumns {
+		dimension, ok := row[column]
+		if !ok {
+			return nil, fmt.Errorf("dimension column %q isn't in the query results", column)
+		}
+		if dimension != nil {
+			dimensions[column] = fmt.Sprintf("%v", dimension)
+		}
+	}
+
+	metricName := m.MetricName
+	if m.nameTemplate != nil {
+		var rendered strings.Builder
+		if err = m.nameTemplate.Execute(&rendered, row); err != nil {
+			return nil, fmt.Errorf("failed rendering metricName: %w", err)
+		}
+		metricName = rendered.String()
+	}
+
+	metricType := datapoint.Gauge
+	if m.IsCumulative {
+		metricType = datapoint.Counter
+	}
+	return datapoint.New(metricName, dimensions, value, metricType, timestamp), nil
+}
+
+func customQueryValue(value any) (datapoint.Value, error) {
+	switch v := value.(type) {
+	case int64:
+		return datapoint.NewIntValue(v), nil
+	case float64:
+		return datapoint.NewFloatValue(v), nil
+	case bool:
+		if v {
+			return datapoint.NewIntValue(1), nil
+		}
+		return datapoint.NewIntValue(0), nil
+	case string:
+		if i, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return datapoint.NewIntValue(i), nil
+		}
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return datapoint.NewFloatValue(f), nil
+		}
+	}
+	return nil, fmt.Errorf("non-numeric value %v", value)
+}
@@ -0,0 +1,294 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/oracledb"
+)
+
+const testQueryDriver = "customqueriestest"
+
+// testQueryResults are the results of the test driver's queries, by data source name.
+var testQueryResults = map[string]testQueryResult{
+	"tables": {
+		columns: []string{"relname", "n_live_tup", "n_tup_ins"},
+		rows: [][]driver.Value{
+			{[]byte("orders"), int64(10), []byte("1.5")},
+			{"customers", nil, float64(3)},
+			{"invoices", int64(2), true},
+		},
+	},
+	"invalid": {
+		columns: []string{"relname", "n_live_tup", "n_tup_ins"},
+		rows:    [][]driver.Value{{"orders", "many", int64(1)}},
+	},
+	"failing": {err: errors.New("relation \"orders\" does not exist")},
+}
+
+func init() {
+	sql.Register(testQueryDriver, testDriver{})
+}
+
+type testQueryResult struct {
+	err     error
+	columns []string
+	rows    [][]driver.Value
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(dsn string) (driver.Conn, error) {
+	return testConn{result: testQueryResults[dsn]}, nil
+}
+
+type testConn struct {
+	result testQueryResult
+}
+
+func (c testConn) Prepare(string) (driver.Stmt, error) { return testStmt(c), nil }
+func (testConn) Close() error                          { return nil }
+func (testConn) Begin() (driver.Tx, error)             { return nil, errors.New("unsupported") }
+
+type testStmt testConn
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return -1 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("unsupported") }
+func (s testStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.result.err != nil {
+		return nil, s.result.err
+	}
+	return &testRows{result: s.result}, nil
+}
+
+type testRows struct {
+	result testQueryResult
+	next   int
+}
+
+func (r *testRows) Columns() []string { return r.result.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func newTestScheduledQuery(t *testing.T, dsn string, maxRows int) *scheduledCustomQuery {
+	db, err := sql.Open(testQueryDriver, dsn)
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, db.Close()) })
+	queries := []CustomQuery{{
+		Statement: "SELECT relname, n_live_tup, n_tup_ins FROM pg_stat_user_tables",
+		MaxRows:   maxRows,
+		Metrics: []CustomQueryMetric{
+			{MetricName: "postgres.table.{{.relname}}.live_rows", ValueColumn: "n_live_tup"},
+			{MetricName: "postgres.table.inserts", ValueColumn: "n_tup_ins", DimensionColumns: []string{"relname"}, IsCumulative: true},
+		},
+	}}
+	require.NoError(t, parseCustomQueries(queries))
+	return newScheduledCustomQuery(db, queries[0], 0, 10)
+}
+
+func TestParseCustomQueries(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		expectedErr string
+		query       CustomQuery
+	}{
+		{
+			name:        "empty statement",
+			query:       CustomQuery{Statement: " ", Metrics: []CustomQueryMetric{{MetricName: "m", ValueColumn: "v"}}},
+			expectedErr: "customQueries[0]: statement must not be empty",
+		},
+		{
+			name:        "negative max rows",
+			query:       CustomQuery{Statement: "SELECT 1", MaxRows: -1, Metrics: []CustomQueryMetric{{MetricName: "m", ValueColumn: "v"}}},
+			expectedErr: "customQueries[0]: intervalSeconds, timeoutSeconds, and maxRows must be non-negative",
+		},
+		{
+			name:        "no metrics",
+			query:       CustomQuery{Statement: "SELECT 1"},
+			expectedErr: "customQueries[0]: metrics must not be empty",
+		},
+		{
+			name:        "empty metric name",
+			query:       CustomQuery{Statement: "SELECT 1", Metrics: []CustomQueryMetric{{ValueColumn: "v"}}},
+			expectedErr: "customQueries[0].metrics[0]: metricName must not be empty",
+		},
+		{
+			name:        "invalid metric name template",
+			query:       CustomQuery{Statement: "SELECT 1", Metrics: []CustomQueryMetric{{MetricName: "m.{{.name", ValueColumn: "v"}}},
+			expectedErr: "customQueries[0].metrics[0]: invalid metricName template: template: m.{{.name:1: unclosed action",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, parseCustomQueries([]CustomQuery{test.query}), test.expectedErr)
+		})
+	}
+}
+
+func TestCustomQueryDataSourceForUnsupportedMonitor(t *testing.T) {
+	cfg := newConfig("cpu", "cpu", 1)
+	_, _, err := customQueryDataSource(cfg.monitorConfig, "")
+	assert.EqualError(t, err, `customQueries aren't supported by the "cpu" monitor`)
+}
+
+func TestCustomQueryDataSourceForOracle(t *testing.T) {
+	monitorConfig := &oracledb.Config{
+		MonitorConfig: saconfig.MonitorConfig{Type: "oracledb"},
+		Host:          "db.example.com",
+		Port:          2484,
+		ServiceName:   "ORCLPDB1",
+		WalletPath:    "/etc/otel/wallet",
+		TLS:           true,
+	}
+	driverName, dsn, err := customQueryDataSource(monitorConfig, "")
+	require.NoError(t, err)
+	assert.Equal(t, oracledb.DriverName, driverName)
+	// the driver orders the connection options randomly
+	expected, err := url.Parse(oracledb.DataSourceName(monitorConfig))
+	require.NoError(t, err)
+	actual, err := url.Parse(dsn)
+	require.NoError(t, err)
+	assert.Equal(t, expected.Host+expected.Path, actual.Host+actual.Path)
+	assert.Equal(t, expected.Query(), actual.Query())
+
+	_, dsn, err = customQueryDataSource(monitorConfig, "SALESPDB")
+	require.NoError(t, err)
+	assert.Contains(t, dsn, "db.example.com:2484/SALESPDB?")
+	assert.Equal(t, "ORCLPDB1", monitorConfig.ServiceName)
+}
+
+func TestQuotePostgresOption(t *testing.T) {
+	assert.Equal(t, `'pass word'`, quotePostgresOption("pass word"))
+	assert.Equal(t, `'it\'s \\ secret'`, quotePostgresOption(`it's \ secret`))
+}
+
+func TestCustomQueryCollect(t *testing.T) {
+	query := newTestScheduledQuery(t, "tables", 0)
+	datapoints, truncated, err := query.collect(context.Background())
+	require.NoError(t, err)
+	assert.False(t, truncated)
+	require.Len(t, datapoints, 5)
+
+	expected := []struct {
+		value      datapoint.Value
+		dimensions map[string]string
+		metric     string
+		metricType datapoint.MetricType
+	}{
+		{metric: "postgres.table.orders.live_rows", value: datapoint.NewIntValue(10), dimensions: map[string]string{}, metricType: datapoint.Gauge},
+		{metric: "postgres.table.inserts", value: datapoint.NewFloatValue(1.5), dimensions: map[string]string{"relname": "orders"}, metricType: datapoint.Counter},
+		{metric: "postgres.table.inserts", value: datapoint.NewFloatValue(3), dimensions: map[string]string{"relname": "customers"}, metricType: datapoint.Counter},
+		{metric: "postgres.table.invoices.live_rows", value: datapoint.NewIntValue(2), dimensions: map[string]string{}, metricType: datapoint.Gauge},
+		{metric: "postgres.table.inserts", value: datapoint.NewIntValue(1), dimensions: map[string]string{"relname": "invoices"}, metricType: datapoint.Counter},
+	}
+	for i, dp := range datapoints {
+		assert.Equal(t, expected[i].metric, dp.Metric)
+		assert.Equal(t, expected[i].value, dp.Value)
+		assert.Equal(t, expected[i].dimensions, dp.Dimensions)
+		assert.Equal(t, expected[i].metricType, dp.MetricType)
+		assert.False(t, dp.Timestamp.IsZero())
+	}
+}
+
+func TestCustomQueryCollectMaxRows(t *testing.T) {
+	query := newTestScheduledQuery(t, "tables", 1)
+	datapoints, truncated, err := query.collect(context.Background())
+	require.NoError(t, err)
+	assert.True(t, truncated)
+	require.Len(t, datapoints, 2)
+	assert.Equal(t, "postgres.table.orders.live_rows", datapoints[0].Metric)
+	assert.Equal(t, "postgres.table.inserts", datapoints[1].Metric)
+}
+
+func TestCustomQueryCollectErrors(t *testing.T) {
+	query := newTestScheduledQuery(t, "invalid", 0)
+	datapoints, truncated, err := query.collect(context.Background())
+	assert.EqualError(t, err, `value column "n_live_tup": non-numeric value many`)
+	assert.False(t, truncated)
+	require.Len(t, datapoints, 1)
+	assert.Equal(t, "postgres.table.inserts", datapoints[0].Metric)
+
+	query = newTestScheduledQuery(t, "failing", 0)
+	datapoints, _, err = query.collect(context.Background())
+	assert.EqualError(t, err, `relation "orders" does not exist`)
+	assert.Empty(t, datapoints)
+}
+
+func TestCustomQueryMetricMissingColumns(t *testing.T) {
+	row := map[string]any{"value": int64(1)}
+	_, err := CustomQueryMetric{MetricName: "m", ValueColumn: "missing"}.datapoint(row, time.Now())
+	assert.EqualError(t, err, `value column "missing" isn't in the query results`)
+	_, err = CustomQueryMetric{MetricName: "m", ValueColumn: "value", DimensionColumns: []string{"missing"}}.datapoint(row, time.Now())
+	assert.EqualError(t, err, `dimension column "missing" isn't in the query results`)
+}
+
+type datapointsOutput struct {
+	types.Output
+	datapoints chan []*datapoint.Datapoint
+}
+
+func (o *datapointsOutput) SendDatapoints(datapoints ...*datapoint.Datapoint) {
+	o.datapoints <- datapoints
+}
+
+func TestCustomQueryRunner(t *testing.T) {
+	output := &datapointsOutput{datapoints: make(chan []*datapoint.Datapoint, 10)}
+	query := newTestScheduledQuery(t, "tables", 0)
+	query.interval = time.Minute
+	fake := clock.NewFake(time.Now())
+	runner := &customQueryRunner{output: output, logger: zap.NewNop(), clock: fake, queries: []*scheduledCustomQuery{query}}
+	runner.start()
+
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			assert.Empty(t, output.datapoints)
+			fake.Advance(time.Minute)
+		}
+		select {
+		case datapoints := <-output.datapoints:
+			assert.Len(t, datapoints, 5)
+		case <-time.After(5 * time.Second):
+			t.Fatal("custom query datapoints weren't sent")
+		}
+	}
+	runner.shutdown()
+
+	var nilRunner *customQueryRunner
+	nilRunner.start()
+	nilRunner.shutdown()
+}
@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/exporter/loggingexporter"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+	"go.uber.org/multierr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// debugOutput tees the converted telemetry of a receiver with debugOutput enabled to logging exporters
+// for the signals it has next consumers for.  Its methods are noops for a nil instance.
+type debugOutput struct {
+	metrics component.MetricsExporter
+	logs    component.LogsExporter
+	traces  component.TracesExporter
+	logger  *zap.Logger
+}
+
+func newDebugOutput(
+	cfg Config, params component.ReceiverCreateSettings, withMetrics, withLogs, withTraces bool,
+) (*debugOutput, error) {
+	factory := loggingexporter.NewFactory()
+	exporterCfg := factory.CreateDefaultConfig().(*loggingexporter.Config)
+	exporterCfg.SetIDName(cfg.ID().String())
+	exporterCfg.LogLevel = zapcore.DebugLevel
+
+	set := component.ExporterCreateSettings{
+		TelemetrySettings: params.TelemetrySettings,
+		BuildInfo:         params.BuildInfo,
+	}
+
+	debug := &debugOutput{logger: params.Logger}
+	var err error
+	ctx := context.Background()
+	if withMetrics {
+		if debug.metrics, err = factory.CreateMetricsExporter(ctx, set, exporterCfg); err != nil {
+			return nil, err
+		}
+	}
+	if withLogs {
+		if debug.logs, err = factory.CreateLogsExporter(ctx, set, exporterCfg); err != nil {
+			return nil, err
+		}
+	}
+	if withTraces {
+		if debug.traces, err = factory.CreateTracesExporter(ctx, set, exporterCfg); err != nil {
+			return nil, err
+		}
+	}
+	return debug, nil
+}
+
+func (debug *debugOutput) components() []component.Component {
+	var components []component.Component
+	if debug.metrics != nil {
+		components = append(components, debug.metrics)
+	}
+	if debug.logs != nil {
+		components = append(components, debug.logs)
+	}
+	if debug.traces != nil {
+		components = append(components, debug.traces)
+	}
+	return components
+}
+
+func (debug *debugOutput) start(ctx context.Context, host component.Host) error {
+	if debug == nil {
+		return nil
+	}
+	for _, c := range debug.components() {
+		if err := c.Start(ctx, host); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (debug *debugOutput) shutdown(ctx context.Context) error {
+	if debug == nil {
+		return nil
+	}
+	var err error
+	for _, c := range debug.components() {
+		err = multierr.Append(err, c.Shutdown(ctx))
+	}
+	return err
+}
+
+// The logging exporters don't mutate the provided telemetry and are synchronous, so they are
+// provided it before the next consumers, which may.
+
+func (debug *debugOutput) consumeMetrics(metrics pmetric.Metrics) {
+	if debug == nil || debug.metrics == nil {
+		return
+	}
+	if err := debug.metrics.ConsumeMetrics(context.Background(), metrics); err != nil {
+		debug.logger.Debug("failed logging debug output metrics", zap.Error(err))
+	}
+}
+
+func (debug *debugOutput) consumeLogs(logs plog.Logs) {
+	if debug == nil || debug.logs == nil {
+		return
+	}
+	if err := debug.logs.ConsumeLogs(context.Background(), logs); err != nil {
+		debug.logger.Debug("failed logging debug output logs", zap.Error(err))
+	}
+}
+
+func (debug *debugOutput) consumeTraces(traces ptrace.Traces) {
+	if debug == nil || debug.traces == nil {
+		return
+	}
+	if err := debug.traces.ConsumeTraces(context.Background(), traces); err != nil {
+		debug.logger.Debug("failed logging debug output traces", zap.Error(err))
+	}
+}
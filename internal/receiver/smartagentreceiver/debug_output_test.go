@@ -0,0 +1,88 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+	"go.opentelemetry.io/collector/pdata/ptrace"
+)
+
+func TestNewDebugOutput(t *testing.T) {
+	cfg := Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "cpu")),
+		DebugOutput:      true,
+	}
+	debug, err := newDebugOutput(cfg, newReceiverCreateSettings(), true, false, true)
+	require.NoError(t, err)
+	require.NotNil(t, debug)
+	assert.NotNil(t, debug.metrics)
+	assert.Nil(t, debug.logs)
+	assert.NotNil(t, debug.traces)
+	assert.Len(t, debug.components(), 2)
+
+	require.NoError(t, debug.start(context.Background(), componenttest.NewNopHost()))
+	debug.consumeMetrics(pmetric.NewMetrics())
+	debug.consumeLogs(plog.NewLogs())
+	debug.consumeTraces(ptrace.NewTraces())
+	require.NoError(t, debug.shutdown(context.Background()))
+}
+
+func TestNilDebugOutputIsNoop(t *testing.T) {
+	var debug *debugOutput
+	require.NoError(t, debug.start(context.Background(), componenttest.NewNopHost()))
+	debug.consumeMetrics(pmetric.NewMetrics())
+	debug.consumeLogs(plog.NewLogs())
+	debug.consumeTraces(ptrace.NewTraces())
+	require.NoError(t, debug.shutdown(context.Background()))
+}
+
+func TestSendDatapointsWithDebugOutput(t *testing.T) {
+	cfg := Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "cpu")),
+		DebugOutput:      true,
+	}
+	debug, err := newDebugOutput(cfg, newReceiverCreateSettings(), true, true, true)
+	require.NoError(t, err)
+	require.NoError(t, debug.start(context.Background(), componenttest.NewNopHost()))
+	defer func() { require.NoError(t, debug.shutdown(context.Background())) }()
+
+	sink := new(consumertest.MetricsSink)
+	output := NewOutput(
+		cfg, fakeMonitorFiltering(), sink, consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+	output.debugOutput = debug
+
+	output.SendDatapoints(datapoint.New(
+		"cpu.utilization", map[string]string{"host": "my-host"}, datapoint.NewFloatValue(1.5), datapoint.Gauge, time.Now(),
+	))
+
+	// the next consumer is still provided the converted datapoints
+	require.Len(t, sink.AllMetrics(), 1)
+	assert.Equal(t, 1, sink.AllMetrics()[0].DataPointCount())
+	metric := sink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "cpu.utilization", metric.Name())
+}
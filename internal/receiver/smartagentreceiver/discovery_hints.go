@@ -0,0 +1,187 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"net"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// discoveryHintsPrefix prefixes the pod annotations declaring the monitor of a receivercreator-created
+// receiver, like io.opentelemetry.discovery.smartagent/type, or io.opentelemetry.discovery.smartagent.6379/type
+// for a single port.
+const discoveryHintsPrefix = "io.opentelemetry.discovery.smartagent"
+
+var (
+	errDiscoveryHintsValue               = fmt.Errorf("discoveryHints must be a map of annotation names to string values")
+	errDiscoveryHintsAllowedOptionsValue = fmt.Errorf("discoveryHintsAllowedOptions must be an array of monitor config option names")
+
+	// deniedDiscoveryHintOptions are the lowercased monitor options hints can never set, even when allowed,
+	// since they run scripts, load templates or modules from paths, or direct the monitor, along with the
+	// credentials of the receiver's own config, to another host.
+	deniedDiscoveryHintOptions = map[string]bool{
+		"command":          true,
+		"commands":         true,
+		"groovyscript":     true,
+		"host":             true,
+		"mbeandefinitions": true,
+		"mbeanmappings":    true,
+		"modulepaths":      true,
+		"pythonbinary":     true,
+		"pythonpath":       true,
+		"scriptfilepath":   true,
+		"templates":        true,
+		"typesdbpaths":     true,
+		"url":              true,
+	}
+)
+
+// discoveryHints are the monitor type and options declared by annotations for an endpoint.
+type discoveryHints struct {
+	monitorType string
+	options     map[string]any
+}
+
+// getDiscoveryHintsFromAllSettings returns the discovery hint annotations, ignoring unrelated ones.  The
+// receivercreator evaluates an annotations expression like `pod.annotations` to a map of strings.
+func getDiscoveryHintsFromAllSettings(allSettings map[string]any) (map[string]string, error) {
+	value, ok := allSettings["discoveryHints"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "discoveryHints")
+	annotations := map[string]string{}
+	switch valueAsMap := value.(type) {
+	case map[string]string:
+		for k, v := range valueAsMap {
+			annotations[k] = v
+		}
+	case map[string]any:
+		for k, v := range valueAsMap {
+			s, isString := v.(string)
+			if !isString {
+				return nil, errDiscoveryHintsValue
+			}
+			annotations[k] = s
+		}
+	case nil:
+	default:
+		return nil, errDiscoveryHintsValue
+	}
+	hints := map[string]string{}
+	for annotation, v := range annotations {
+		if strings.HasPrefix(annotation, discoveryHintsPrefix+"/") || strings.HasPrefix(annotation, discoveryHintsPrefix+".") {
+			hints[annotation] = v
+		}
+	}
+	return hints, nil
+}
+
+// resolveDiscoveryHints returns the monitor type and options the hint annotations declare for the endpoint,
+// with the annotations of the endpoint's port taking precedence over the pod-wide ones.
+func resolveDiscoveryHints(annotations map[string]string, endpoint string) (discoveryHints, error) {
+	hints := discoveryHints{options: map[string]any{}}
+	prefixes := []string{discoveryHintsPrefix}
+	if _, port, err := net.SplitHostPort(endpoint); err == nil && port != "" {
+		prefixes = append(prefixes, discoveryHintsPrefix+"."+port)
+	}
+	for _, prefix := range prefixes {
+		if monitorType, ok := annotations[prefix+"/type"]; ok {
+			hints.monitorType = strings.TrimSpace(monitorType)
+		}
+		rawConfig, ok := annotations[prefix+"/config"]
+		if !ok {
+			continue
+		}
+		var options map[string]any
+		if err := yaml.Unmarshal([]byte(rawConfig), &options); err != nil {
+			return discoveryHints{}, fmt.Errorf("invalid %s/config annotation: %w", prefix, err)
+		}
+		for option, value := range options {
+			hints.options[option] = stringKeyed(value)
+		}
+	}
+	return hints, nil
+}
+
+// validateDiscoveryHintsAllowedOptions verifies that none of the options allowed to be set by hints
+// are denied outright.
+func validateDiscoveryHintsAllowedOptions(allowed []string) error {
+	for _, option := range allowed {
+		if deniedDiscoveryHintOptions[strings.ToLower(option)] {
+			return fmt.Errorf("discoveryHintsAllowedOptions cannot include the %q option, which discovery hints can never set", option)
+		}
+	}
+	return nil
+}
+
+// applyDiscoveryHintOptions sets the monitor config options declared by the hints, which take precedence
+// over the receiver's own.  Since hints are declared by the workloads being monitored, they can only set
+// the monitor options the operator allowed, never those running scripts or loading files and templates,
+// nor the monitored host, and can't reference secrets.
+func applyDiscoveryHintOptions(
+	options map[string]any, allowed []string, monitorConfigType reflect.Type, allSettings map[string]any,
+) error {
+	monitorOptions := monitorConfigOptions(monitorConfigType)
+	allowedOptions := map[string]bool{}
+	for _, option := range allowed {
+		allowedOptions[option] = true
+	}
+	var names []string
+	for option := range options {
+		names = append(names, option)
+	}
+	sort.Strings(names)
+	for _, option := range names {
+		if option == "type" || !monitorOptions[option] || deniedDiscoveryHintOptions[strings.ToLower(option)] {
+			return fmt.Errorf("discovery hints cannot set the %q option", option)
+		}
+		if !allowedOptions[option] {
+			return fmt.Errorf("discovery hints cannot set the %q option, which isn't in discoveryHintsAllowedOptions", option)
+		}
+		if valueAsMap, ok := options[option].(map[string]any); ok {
+			if _, ok = valueAsMap["valueFrom"]; ok {
+				return fmt.Errorf("discovery hints cannot reference secrets for the %q option", option)
+			}
+		}
+		allSettings[option] = options[option]
+	}
+	return nil
+}
+
+// stringKeyed converts the map[any]any values unmarshalled by yaml.v2 to map[string]any, like the
+// rest of the receiver config.
+func stringKeyed(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		converted := make(map[string]any, len(v))
+		for k, item := range v {
+			converted[fmt.Sprintf("%v", k)] = stringKeyed(item)
+		}
+		return converted
+	case []any:
+		converted := make([]any, len(v))
+		for i, item := range v {
+			converted[i] = stringKeyed(item)
+		}
+		return converted
+	}
+	return value
+}
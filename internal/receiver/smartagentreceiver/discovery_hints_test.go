@@ -0,0 +1,187 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"path"
+	"reflect"
+	"testing"
+
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/collectd/redis"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfigWithDiscoveryHints(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "discovery_hints.yaml"), factories,
+	)
+
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+
+	hintedCfg := cfg.Receivers[config.NewComponentIDWithName(typeStr, "hinted")].(*Config)
+	require.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "hinted")),
+		Endpoint:         "redishost:6379",
+		DiscoveryHints: map[string]string{
+			"io.opentelemetry.discovery.smartagent/type":        "collectd/redis",
+			"io.opentelemetry.discovery.smartagent/config":      "name: pod-wide\nauth: \"1234\"\n",
+			"io.opentelemetry.discovery.smartagent.6379/config": "name: redis-primary\n",
+			"io.opentelemetry.discovery.smartagent.7379/config": "name: redis-replica\n",
+		},
+		monitorConfig: &redis.Config{
+			MonitorConfig: saconfig.MonitorConfig{
+				Type:                "collectd/redis",
+				IntervalSeconds:     30,
+				DatapointsToExclude: []saconfig.MetricFilter{},
+			},
+			Host: "redishost",
+			Port: 6379,
+			Name: "redis-primary",
+			Auth: "1234",
+		},
+		DiscoveryHintsAllowedOptions: []string{"name", "auth"},
+		acceptsEndpoints:             true,
+	}, hintedCfg)
+	require.NoError(t, hintedCfg.validate())
+}
+
+func TestLoadInvalidConfigWithDiscoveryHints(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	assert.Nil(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(
+		path.Join(".", "testdata", "invalid_discovery_hints.yaml"), factories,
+	)
+	require.EqualError(t, err,
+		`error reading receivers configuration for "smartagent/hinted": discovery hints can only declare monitor types that accept endpoints, not "cpu"`)
+	require.Nil(t, cfg)
+}
+
+func TestGetDiscoveryHintsFromAllSettings(t *testing.T) {
+	allSettings := map[string]any{
+		"discoveryHints": map[string]string{
+			"io.opentelemetry.discovery.smartagent/type":  "collectd/redis",
+			"io.opentelemetry.discovery.metrics/scraper":  "redis",
+			"io.opentelemetry.discovery.smartagentx/type": "collectd/redis",
+			"prometheus.io/scrape":                        "true",
+		},
+	}
+	hints, err := getDiscoveryHintsFromAllSettings(allSettings)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"io.opentelemetry.discovery.smartagent/type": "collectd/redis"}, hints)
+	assert.Empty(t, allSettings)
+
+	hints, err = getDiscoveryHintsFromAllSettings(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, hints)
+
+	_, err = getDiscoveryHintsFromAllSettings(map[string]any{"discoveryHints": map[string]any{"a": 1}})
+	require.EqualError(t, err, "discoveryHints must be a map of annotation names to string values")
+
+	_, err = getDiscoveryHintsFromAllSettings(map[string]any{"discoveryHints": "annotations"})
+	require.EqualError(t, err, "discoveryHints must be a map of annotation names to string values")
+}
+
+func TestResolveDiscoveryHints(t *testing.T) {
+	annotations := map[string]string{
+		"io.opentelemetry.discovery.smartagent/type":        "collectd/redis",
+		"io.opentelemetry.discovery.smartagent/config":      "name: pod-wide\nauth: secret\n",
+		"io.opentelemetry.discovery.smartagent.8080/type":   "collectd/apache",
+		"io.opentelemetry.discovery.smartagent.8080/config": "url: http://{{.Host}}:{{.Port}}/status\nextraDimensions:\n  app: web\n",
+	}
+
+	hints, err := resolveDiscoveryHints(annotations, "10.0.0.1:6379")
+	require.NoError(t, err)
+	assert.Equal(t, discoveryHints{
+		monitorType: "collectd/redis",
+		options:     map[string]any{"name": "pod-wide", "auth": "secret"},
+	}, hints)
+
+	hints, err = resolveDiscoveryHints(annotations, "10.0.0.1:8080")
+	require.NoError(t, err)
+	assert.Equal(t, discoveryHints{
+		monitorType: "collectd/apache",
+		options: map[string]any{
+			"name":            "pod-wide",
+			"auth":            "secret",
+			"url":             "http://{{.Host}}:{{.Port}}/status",
+			"extraDimensions": map[string]any{"app": "web"},
+		},
+	}, hints)
+
+	hints, err = resolveDiscoveryHints(nil, "")
+	require.NoError(t, err)
+	assert.Equal(t, discoveryHints{options: map[string]any{}}, hints)
+
+	_, err = resolveDiscoveryHints(map[string]string{"io.opentelemetry.discovery.smartagent/config": "- not a map"}, "")
+	require.ErrorContains(t, err, "invalid io.opentelemetry.discovery.smartagent/config annotation")
+}
+
+func TestApplyDiscoveryHintOptions(t *testing.T) {
+	redisConfigType := reflect.TypeOf(redis.Config{})
+	allowed := []string{"name", "intervalSeconds", "auth", "host"}
+
+	allSettings := map[string]any{"type": "collectd/redis", "name": "unhinted", "dimensionClients": []any{"signalfx"}}
+	require.NoError(t, applyDiscoveryHintOptions(map[string]any{"name": "hinted", "intervalSeconds": 5}, allowed, redisConfigType, allSettings))
+	assert.Equal(t, map[string]any{
+		"type":             "collectd/redis",
+		"name":             "hinted",
+		"intervalSeconds":  5,
+		"dimensionClients": []any{"signalfx"},
+	}, allSettings)
+
+	err := applyDiscoveryHintOptions(map[string]any{"type": "cpu"}, allowed, redisConfigType, map[string]any{})
+	require.EqualError(t, err, `discovery hints cannot set the "type" option`)
+
+	err = applyDiscoveryHintOptions(map[string]any{"collectdTypesDB": []any{"/etc/types.db"}}, allowed, redisConfigType, map[string]any{})
+	require.EqualError(t, err, `discovery hints cannot set the "collectdTypesDB" option`)
+
+	// denied regardless of the allowed options
+	err = applyDiscoveryHintOptions(map[string]any{"host": "attacker.example.com"}, allowed, redisConfigType, map[string]any{})
+	require.EqualError(t, err, `discovery hints cannot set the "host" option`)
+
+	// denied by default
+	err = applyDiscoveryHintOptions(map[string]any{"name": "hinted"}, nil, redisConfigType, map[string]any{})
+	require.EqualError(t, err, `discovery hints cannot set the "name" option, which isn't in discoveryHintsAllowedOptions`)
+
+	err = applyDiscoveryHintOptions(map[string]any{
+		"auth": map[string]any{"valueFrom": map[string]any{"secretKeyRef": map[string]any{"name": "redis", "key": "auth"}}},
+	}, allowed, redisConfigType, map[string]any{})
+	require.EqualError(t, err, `discovery hints cannot reference secrets for the "auth" option`)
+}
+
+func TestValidateDiscoveryHintsAllowedOptions(t *testing.T) {
+	require.NoError(t, validateDiscoveryHintsAllowedOptions(nil))
+	require.NoError(t, validateDiscoveryHintsAllowedOptions([]string{"name", "sendListLengths"}))
+	for _, option := range []string{"templates", "groovyScript", "mbeanMappings", "pythonBinary", "modulePaths", "host", "url"} {
+		require.EqualError(t, validateDiscoveryHintsAllowedOptions([]string{"name", option}), fmt.Sprintf(
+			"discoveryHintsAllowedOptions cannot include the %q option, which discovery hints can never set", option,
+		))
+	}
+}
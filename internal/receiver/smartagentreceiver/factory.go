@@ -21,6 +21,8 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
 )
 
 const (
@@ -34,7 +36,25 @@ var (
 	receiverStore     = map[*Config]*Receiver{}
 )
 
-func getOrCreateReceiver(cfg config.Receiver, params component.ReceiverCreateSettings) (*Receiver, error) {
+// FactoryOption applies changes to smartAgentReceiverFactory.
+type FactoryOption func(factory *smartAgentReceiverFactory)
+
+// WithClock sets the clock of the intervals of the receivers' custom queries, transactions, vSphere tag
+// syncs, and cardinality reports, and of the collection timeouts, instance and vSphere object expiries, and
+// lifecycle events derived from the monitors' intervals, so that tests can advance them with a clock.Fake
+// instead of waiting for them.  The monitors themselves are scheduled by the Smart Agent on the real clock,
+// so tests still wait for their collections.
+func WithClock(c clock.Clock) FactoryOption {
+	return func(factory *smartAgentReceiverFactory) {
+		factory.clock = c
+	}
+}
+
+type smartAgentReceiverFactory struct {
+	clock clock.Clock
+}
+
+func getOrCreateReceiver(cfg config.Receiver, params component.ReceiverCreateSettings, clk clock.Clock) (*Receiver, error) {
 	receiverStoreLock.Lock()
 	defer receiverStoreLock.Unlock()
 	receiverConfig := cfg.(*Config)
@@ -47,19 +67,26 @@ func getOrCreateReceiver(cfg config.Receiver, params component.ReceiverCreateSet
 	receiver, ok := receiverStore[receiverConfig]
 	if !ok {
 		receiver = NewReceiver(params, *receiverConfig)
+		if clk != nil {
+			receiver.clock = clk
+		}
 		receiverStore[receiverConfig] = receiver
 	}
 
 	return receiver, nil
 }
 
-func NewFactory() component.ReceiverFactory {
+func NewFactory(options ...FactoryOption) component.ReceiverFactory {
+	f := &smartAgentReceiverFactory{}
+	for _, option := range options {
+		option(f)
+	}
 	return component.NewReceiverFactory(
 		typeStr,
 		CreateDefaultConfig,
-		component.WithMetricsReceiver(createMetricsReceiver),
-		component.WithLogsReceiver(createLogsReceiver),
-		component.WithTracesReceiver(createTracesReceiver),
+		component.WithMetricsReceiver(f.createMetricsReceiver),
+		component.WithLogsReceiver(f.createLogsReceiver),
+		component.WithTracesReceiver(f.createTracesReceiver),
 	)
 }
 
@@ -69,13 +96,13 @@ func CreateDefaultConfig() config.Receiver {
 	}
 }
 
-func createMetricsReceiver(
+func (f *smartAgentReceiverFactory) createMetricsReceiver(
 	_ context.Context,
 	params component.ReceiverCreateSettings,
 	cfg config.Receiver,
 	metricsConsumer consumer.Metrics,
 ) (component.MetricsReceiver, error) {
-	receiver, err := getOrCreateReceiver(cfg, params)
+	receiver, err := getOrCreateReceiver(cfg, params, f.clock)
 	if err != nil {
 		return nil, err
 	}
@@ -84,13 +111,13 @@ func createMetricsReceiver(
 	return receiver, nil
 }
 
-func createLogsReceiver(
+func (f *smartAgentReceiverFactory) createLogsReceiver(
 	_ context.Context,
 	params component.ReceiverCreateSettings,
 	cfg config.Receiver,
 	logsConsumer consumer.Logs,
 ) (component.LogsReceiver, error) {
-	receiver, err := getOrCreateReceiver(cfg, params)
+	receiver, err := getOrCreateReceiver(cfg, params, f.clock)
 	if err != nil {
 		return nil, err
 	}
@@ -99,13 +126,13 @@ func createLogsReceiver(
 	return receiver, nil
 }
 
-func createTracesReceiver(
+func (f *smartAgentReceiverFactory) createTracesReceiver(
 	_ context.Context,
 	params component.ReceiverCreateSettings,
 	cfg config.Receiver,
 	tracesConsumer consumer.Traces,
 ) (component.TracesReceiver, error) {
-	receiver, err := getOrCreateReceiver(cfg, params)
+	receiver, err := getOrCreateReceiver(cfg, params, f.clock)
 	if err != nil {
 		return nil, err
 	}
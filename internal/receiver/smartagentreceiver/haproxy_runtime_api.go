@@ -0,0 +1,438 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/signalfx/golib/v3/event"
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+const (
+	haproxyMonitorType = "haproxy"
+
+	haproxyServerStateChangeEventType = "haproxy.server.state_change"
+
+	// haproxyServerType is the type of the server rows of the show stat output, as opposed to those of
+	// frontends (0), backends (1), and listeners (3).
+	haproxyServerType = "2"
+
+	// maxHAProxyStatSize limits the show stat output read for the server states.
+	maxHAProxyStatSize = 8 << 20
+
+	defaultHAProxyRuntimeAPITimeout = 5 * time.Second
+)
+
+// HAProxyRuntimeAPI is the HAProxy 2.x Runtime API of a TCP stats socket, like
+// `stats socket ipv4@0.0.0.0:9999 level operator`, from which the haproxy monitor collects instead of the stats
+// page or a local unix socket.  Sockets bound with `ssl crt ... ca-file ... verify required` authenticate the
+// collector by its TLS client certificate.
+type HAProxyRuntimeAPI struct {
+	// Standard collector tls client settings for sockets bound with ssl.  Plain TCP is used without them.
+	TLS *configtls.TLSClientSetting `mapstructure:"tls"`
+	// The host:port of the runtime API socket.
+	Endpoint string `mapstructure:"endpoint"`
+	// Whether to not send the haproxy.server.state_change events of the backend servers changing status.
+	DisableServerStateEvents bool `mapstructure:"disableServerStateEvents"`
+}
+
+func getHAProxyRuntimeAPIFromAllSettings(allSettings map[string]any) (*HAProxyRuntimeAPI, error) {
+	value, ok := allSettings["runtimeAPI"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "runtimeAPI")
+	valueAsMap, isMap := value.(map[string]any)
+	if !isMap {
+		return nil, errHAProxyRuntimeAPIValue
+	}
+	if tlsValue, hasTLS := valueAsMap["tls"]; hasTLS && tlsValue == nil {
+		// an empty tls block is TLS with the system cert pool, like the receiver's own tls block
+		valueAsMap["tls"] = map[string]any{}
+	}
+	runtimeAPI := &HAProxyRuntimeAPI{}
+	if err := confmap.NewFromStringMap(valueAsMap).UnmarshalExact(runtimeAPI); err != nil {
+		return nil, fmt.Errorf("%w: %v", errHAProxyRuntimeAPIValue, err)
+	}
+	if _, _, err := net.SplitHostPort(runtimeAPI.Endpoint); err != nil {
+		return nil, fmt.Errorf("%w: %v", errHAProxyRuntimeAPIValue, err)
+	}
+	if _, ok = allSettings["url"]; ok {
+		return nil, fmt.Errorf("runtimeAPI conflicts with the monitor's \"url\" option")
+	}
+	return runtimeAPI, nil
+}
+
+// haproxyRuntimeAPIProxy bridges the haproxy monitor, which only speaks the runtime API over unix sockets, to a
+// runtime API TCP socket: the monitor's url is set to a unix socket in a temporary directory whose connections
+// are forwarded to the endpoint, over TLS if configured.  Every interval, it also sends an event for each backend
+// server whose status changed since the previous show stat output.
+type haproxyRuntimeAPIProxy struct {
+	ctx      context.Context
+	output   types.Output
+	cancel   context.CancelFunc
+	listener net.Listener
+	logger   *zap.Logger
+	clock    clock.Clock
+	dial     func(ctx context.Context) (net.Conn, error)
+	// conns are the open connections, closed on shutdown, or nil once shut down
+	conns map[net.Conn]struct{}
+	// serverStatuses are the statuses of the backend servers in the last show stat output
+	serverStatuses map[haproxyServer]string
+	dir            string
+	interval       time.Duration
+	timeout        time.Duration
+	stateEvents    bool
+	connsLock      sync.Mutex
+	wg             sync.WaitGroup
+}
+
+type haproxyServer struct {
+	proxy  string
+	server string
+}
+
+// haproxyServerStat is a server row of the show stat output.
+type haproxyServerStat struct {
+	haproxyServer
+	status      string
+	checkStatus string
+}
+
+func newHAProxyRuntimeAPIProxy(
+	runtimeAPI HAProxyRuntimeAPI, monitorConfig saconfig.MonitorCustomConfig, output types.Output, logger *zap.Logger, clk clock.Clock,
+) (*haproxyRuntimeAPIProxy, error) {
+	urlField, err := GetSettableStructFieldValue(monitorConfig, "URL", reflect.TypeOf(""))
+	if err != nil || urlField == nil {
+		return nil, fmt.Errorf("monitor config of type %q has no url", monitorConfig.MonitorConfigCore().Type)
+	}
+
+	var tlsConfig *tls.Config
+	if runtimeAPI.TLS != nil {
+		// nil for insecure settings
+		if tlsConfig, err = runtimeAPI.TLS.LoadTLSConfig(); err != nil {
+			return nil, fmt.Errorf("failed loading runtimeAPI tls settings: %w", err)
+		}
+	}
+
+	timeout := defaultHAProxyRuntimeAPITimeout
+	// the monitor's timeout option
+	if monitorTimeout := intOption(reflect.Indirect(reflect.ValueOf(monitorConfig)), "Timeout"); monitorTimeout > 0 {
+		timeout = time.Duration(monitorTimeout)
+	}
+	dialer := &net.Dialer{Timeout: timeout}
+	dial := func(ctx context.Context) (net.Conn, error) {
+		if tlsConfig != nil {
+			return (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", runtimeAPI.Endpoint)
+		}
+		return dialer.DialContext(ctx, "tcp", runtimeAPI.Endpoint)
+	}
+
+	dir, err := os.MkdirTemp("", "haproxy-runtime-api-")
+	if err != nil {
+		return nil, fmt.Errorf("failed creating runtime API socket directory: %w", err)
+	}
+	socket := filepath.Join(dir, "runtime-api.sock")
+	listener, err := net.Listen("unix", socket)
+	if err != nil {
+		_ = os.RemoveAll(dir)
+		return nil, fmt.Errorf("failed creating runtime API socket: %w", err)
+	}
+	urlField.SetString("unix://" + socket)
+
+	return &haproxyRuntimeAPIProxy{
+		output:      output,
+		listener:    listener,
+		logger:      logger,
+		clock:       clk,
+		dial:        dial,
+		conns:       map[net.Conn]struct{}{},
+		dir:         dir,
+		interval:    time.Duration(monitorConfig.MonitorConfigCore().IntervalSeconds) * time.Second,
+		timeout:     timeout,
+		stateEvents: !runtimeAPI.DisableServerStateEvents,
+	}, nil
+}
+
+// start forwards the monitor's connections, and sends the server state change events every interval, until
+// shutdown.  It's a noop for a nil instance.
+func (p *haproxyRuntimeAPIProxy) start() {
+	if p == nil {
+		return
+	}
+	p.ctx, p.cancel = context.WithCancel(context.Background())
+	p.wg.Add(1)
+	go p.serve()
+	if p.stateEvents {
+		p.wg.Add(1)
+		go p.run()
+	}
+}
+
+func (p *haproxyRuntimeAPIProxy) serve() {
+	defer p.wg.Done()
+	for {
+		conn, err := p.listener.Accept()
+		if err != nil {
+			if p.ctx.Err() == nil {
+				p.logger.Error("failed accepting haproxy monitor connection", zap.Error(err))
+			}
+			return
+		}
+		p.wg.Add(1)
+		go p.forward(conn)
+	}
+}
+
+// forward copies the monitor's commands to the runtime API and its responses back to the monitor until the runtime
+// API closes the connection, as it does once it responded to non-interactive commands.
+func (p *haproxyRuntimeAPIProxy) forward(conn net.Conn) {
+	defer p.wg.Done()
+	defer conn.Close()
+	if !p.track(conn) {
+		return
+	}
+	defer p.untrack(conn)
+
+	ctx, cancel := context.WithTimeout(p.ctx, p.timeout)
+	remote, err := p.dial(ctx)
+	cancel()
+	if err != nil {
+		p.logger.Warn("failed connecting to the HAProxy runtime API", zap.Error(err))
+		return
+	}
+	defer remote.Close()
+	if !p.track(remote) {
+		return
+	}
+	defer p.untrack(remote)
+
+	sent := make(chan struct{})
+	go func() {
+		defer close(sent)
+		_, _ = io.Copy(remote, conn)
+		if closer, ok := remote.(interface{ CloseWrite() error }); ok {
+			_ = closer.CloseWrite()
+		}
+	}()
+	_, _ = io.Copy(conn, remote)
+	conn.Close()
+	<-sent
+}
+
+func (p *haproxyRuntimeAPIProxy) track(conn net.Conn) bool {
+	p.connsLock.Lock()
+	defer p.connsLock.Unlock()
+	if p.conns == nil {
+		return false
+	}
+	p.conns[conn] = struct{}{}
+	return true
+}
+
+func (p *haproxyRuntimeAPIProxy) untrack(conn net.Conn) {
+	p.connsLock.Lock()
+	defer p.connsLock.Unlock()
+	delete(p.conns, conn)
+}
+
+func (p *haproxyRuntimeAPIProxy) run() {
+	defer p.wg.Done()
+	ticker := p.clock.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		p.collectServerStates()
+		select {
+		case <-p.ctx.Done():
+			return
+		case <-ticker.Chan():
+		}
+	}
+}
+
+func (p *haproxyRuntimeAPIProxy) collectServerStates() {
+	ctx, cancel := context.WithTimeout(p.ctx, p.timeout)
+	defer cancel()
+	stats, err := p.showStat(ctx)
+	if err != nil {
+		if p.ctx.Err() == nil {
+			p.logger.Debug("failed getting HAProxy server states from the runtime API", zap.Error(err))
+		}
+		return
+	}
+	for _, ev := range p.serverStateChanges(stats, p.clock.Now()) {
+		p.output.SendEvent(ev)
+	}
+}
+
+// showStat returns the server rows of the runtime API's show stat output.
+func (p *haproxyRuntimeAPIProxy) showStat(ctx context.Context) ([]haproxyServerStat, error) {
+	conn, err := p.dial(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.Close()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	if _, err = io.WriteString(conn, "show stat\n"); err != nil {
+		return nil, err
+	}
+	return parseHAProxyStat(io.LimitReader(conn, maxHAProxyStatSize))
+}
+
+// parseHAProxyStat returns the server rows of the show stat CSV output, whose header line starts with "# ".
+func parseHAProxyStat(r io.Reader) ([]haproxyServerStat, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	header, err := reader.Read()
+	if errors.Is(err, io.EOF) {
+		return nil, fmt.Errorf("empty show stat response")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("unexpected show stat response: %w", err)
+	}
+	if !strings.HasPrefix(header[0], "# ") {
+		// like "Permission denied" for sockets of a level not allowing it
+		return nil, fmt.Errorf("unexpected show stat response: %q", strings.Join(header, ","))
+	}
+	header[0] = strings.TrimPrefix(header[0], "# ")
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[name] = i
+	}
+	for _, required := range []string{"pxname", "svname", "status", "type"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("show stat response has no %s column", required)
+		}
+	}
+	field := func(record []string, name string) string {
+		if i, ok := columns[name]; ok && i < len(record) {
+			return record[i]
+		}
+		return ""
+	}
+
+	var stats []haproxyServerStat
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			return stats, nil
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed parsing show stat response: %w", err)
+		}
+		if field(record, "type") != haproxyServerType {
+			continue
+		}
+		stats = append(stats, haproxyServerStat{
+			haproxyServer: haproxyServer{proxy: field(record, "pxname"), server: field(record, "svname")},
+			status:        field(record, "status"),
+			checkStatus:   field(record, "check_status"),
+		})
+	}
+}
+
+// serverStateChanges returns the events of the servers whose state changed since the previous stats.  Servers
+// without previous stats, like those of the first stats, have no events.
+func (p *haproxyRuntimeAPIProxy) serverStateChanges(stats []haproxyServerStat, now time.Time) []*event.Event {
+	var events []*event.Event
+	statuses := make(map[haproxyServer]string, len(stats))
+	for _, stat := range stats {
+		statuses[stat.haproxyServer] = stat.status
+		previous, seen := p.serverStatuses[stat.haproxyServer]
+		if !seen || haproxyServerState(previous) == haproxyServerState(stat.status) {
+			continue
+		}
+		properties := map[string]any{
+			"old_status": previous,
+			"new_status": stat.status,
+		}
+		if stat.checkStatus != "" {
+			properties["check_status"] = stat.checkStatus
+		}
+		events = append(events, &event.Event{
+			EventType: haproxyServerStateChangeEventType,
+			Category:  event.AGENT,
+			// the dimensions of the monitor's datapoints of the server
+			Dimensions: map[string]string{"proxy_name": stat.proxy, "service_name": stat.server},
+			Properties: properties,
+			Timestamp:  now,
+		})
+	}
+	p.serverStatuses = statuses
+	return events
+}
+
+// haproxyServerState returns the state of the server status without the health check progress of transitional
+// statuses, like UP for "UP 1/3" going down, so that health checks only have events once they change the state.
+func haproxyServerState(status string) string {
+	i := strings.LastIndexByte(status, ' ')
+	if i < 0 {
+		return status
+	}
+	checks, total, ok := strings.Cut(status[i+1:], "/")
+	if !ok {
+		return status
+	}
+	if _, err := strconv.Atoi(checks); err != nil {
+		return status
+	}
+	if _, err := strconv.Atoi(total); err != nil {
+		return status
+	}
+	return status[:i]
+}
+
+// shutdown stops forwarding and closes the open connections.  It's a noop for a nil instance.
+func (p *haproxyRuntimeAPIProxy) shutdown() {
+	if p == nil {
+		return
+	}
+	if p.cancel != nil {
+		p.cancel()
+	}
+	_ = p.listener.Close()
+	p.connsLock.Lock()
+	for conn := range p.conns {
+		_ = conn.Close()
+	}
+	p.conns = nil
+	p.connsLock.Unlock()
+	p.wg.Wait()
+	if err := os.RemoveAll(p.dir); err != nil {
+		p.logger.Warn("failed removing runtime API socket directory", zap.String("dir", p.dir), zap.Error(err))
+	}
+}
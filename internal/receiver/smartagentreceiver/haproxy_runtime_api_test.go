@@ -0,0 +1,367 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"bufio"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/utils/timeutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+const testHAProxyStatHeader = "# pxname,svname,status,weight,type,check_status,\n"
+
+type runtimeAPIMonitorConfig struct {
+	saconfig.MonitorConfig `yaml:",inline"`
+	URL                    string            `yaml:"url"`
+	Timeout                timeutil.Duration `yaml:"timeout"`
+}
+
+// fakeRuntimeAPI is a HAProxy runtime API TLS socket requiring client certificates.
+type fakeRuntimeAPI struct {
+	listener  net.Listener
+	web2      string
+	showStats int64
+	lock      sync.Mutex
+}
+
+func newFakeRuntimeAPI(t *testing.T, cert tls.Certificate, pool *x509.CertPool) *fakeRuntimeAPI {
+	listener, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		ClientCAs:    pool,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS12,
+	})
+	require.NoError(t, err)
+	api := &fakeRuntimeAPI{listener: listener, web2: "UP"}
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go api.handle(conn)
+		}
+	}()
+	t.Cleanup(func() { listener.Close() })
+	return api
+}
+
+func (api *fakeRuntimeAPI) handle(conn net.Conn) {
+	defer conn.Close()
+	command, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		return
+	}
+	switch strings.TrimSpace(command) {
+	case "show stat":
+		api.lock.Lock()
+		web2 := api.web2
+		api.lock.Unlock()
+		atomic.AddInt64(&api.showStats, 1)
+		_, _ = io.WriteString(conn, testHAProxyStatHeader+
+			"http-in,FRONTEND,OPEN,,0,,\n"+
+			"web,web1,UP,1,2,L7OK,\n"+
+			"web,web2,"+web2+",1,2,L4TOUT,\n"+
+			"web,BACKEND,UP,2,1,,\n\n")
+	case "show info":
+		_, _ = io.WriteString(conn, "Name: HAProxy\nVersion: 2.6.0\n\n")
+	default:
+		_, _ = io.WriteString(conn, "Unknown command.\n\n")
+	}
+}
+
+func (api *fakeRuntimeAPI) setWeb2Status(status string) {
+	api.lock.Lock()
+	defer api.lock.Unlock()
+	api.web2 = status
+}
+
+// writeRuntimeAPICert writes a self-signed certificate for both the runtime API and its clients.
+func writeRuntimeAPICert(t *testing.T, dir string) (tls.Certificate, *x509.CertPool, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "haproxy"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IPAddresses:           []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	certFile := filepath.Join(dir, "cert.pem")
+	keyFile := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(certFile, certPEM, 0600))
+	require.NoError(t, os.WriteFile(keyFile, keyPEM, 0600))
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+	pool := x509.NewCertPool()
+	require.True(t, pool.AppendCertsFromPEM(certPEM))
+	return cert, pool, certFile, keyFile
+}
+
+func TestGetHAProxyRuntimeAPIFromAllSettings(t *testing.T) {
+	runtimeAPI, err := getHAProxyRuntimeAPIFromAllSettings(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, runtimeAPI)
+
+	allSettings := map[string]any{
+		"runtimeAPI": map[string]any{"endpoint": "haproxy:9999", "tls": nil, "disableServerStateEvents": true},
+	}
+	runtimeAPI, err = getHAProxyRuntimeAPIFromAllSettings(allSettings)
+	require.NoError(t, err)
+	assert.Equal(t, &HAProxyRuntimeAPI{
+		Endpoint:                 "haproxy:9999",
+		TLS:                      &configtls.TLSClientSetting{},
+		DisableServerStateEvents: true,
+	}, runtimeAPI)
+	assert.Empty(t, allSettings)
+}
+
+func TestInvalidHAProxyRuntimeAPI(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		allSettings   map[string]any
+		expectedError string
+	}{
+		{
+			name:          "not a map",
+			allSettings:   map[string]any{"runtimeAPI": "haproxy:9999"},
+			expectedError: "runtimeAPI must be a map with the host:port endpoint of a HAProxy runtime API socket",
+		},
+		{
+			name:          "unknown field",
+			allSettings:   map[string]any{"runtimeAPI": map[string]any{"endpoint": "haproxy:9999", "password": "secret"}},
+			expectedError: "runtimeAPI must be a map with the host:port endpoint of a HAProxy runtime API socket: ",
+		},
+		{
+			name:          "no port",
+			allSettings:   map[string]any{"runtimeAPI": map[string]any{"endpoint": "haproxy"}},
+			expectedError: "runtimeAPI must be a map with the host:port endpoint of a HAProxy runtime API socket: address haproxy: missing port in address",
+		},
+		{
+			name: "url",
+			allSettings: map[string]any{
+				"url": "unix:///var/run/haproxy.sock", "runtimeAPI": map[string]any{"endpoint": "haproxy:9999"},
+			},
+			expectedError: `runtimeAPI conflicts with the monitor's "url" option`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			runtimeAPI, err := getHAProxyRuntimeAPIFromAllSettings(tt.allSettings)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Nil(t, runtimeAPI)
+		})
+	}
+}
+
+func TestParseHAProxyStat(t *testing.T) {
+	stats, err := parseHAProxyStat(strings.NewReader(testHAProxyStatHeader +
+		"http-in,FRONTEND,OPEN,,0,,\n" +
+		"web,web1,UP,1,2,L7OK,\n" +
+		"web,web2,DOWN 1/2,1,2,L4CON,\n" +
+		"web,BACKEND,UP,1,1,,\n" +
+		"api,api1,MAINT,0,2\n\n"))
+	require.NoError(t, err)
+	assert.Equal(t, []haproxyServerStat{
+		{haproxyServer: haproxyServer{proxy: "web", server: "web1"}, status: "UP", checkStatus: "L7OK"},
+		{haproxyServer: haproxyServer{proxy: "web", server: "web2"}, status: "DOWN 1/2", checkStatus: "L4CON"},
+		{haproxyServer: haproxyServer{proxy: "api", server: "api1"}, status: "MAINT"},
+	}, stats)
+
+	for response, expectedError := range map[string]string{
+		"":                                  "empty show stat response",
+		"Permission denied\n\n":             `unexpected show stat response: "Permission denied"`,
+		"# pxname,svname,status,weight\n\n": "show stat response has no type column",
+	} {
+		_, err = parseHAProxyStat(strings.NewReader(response))
+		assert.EqualError(t, err, expectedError)
+	}
+}
+
+func TestHAProxyServerState(t *testing.T) {
+	for status, state := range map[string]string{
+		"UP":                   "UP",
+		"UP 1/3":               "UP",
+		"DOWN 1/2":             "DOWN",
+		"no check":             "no check",
+		"MAINT (via web/web1)": "MAINT (via web/web1)",
+		"NOLB 2/3":             "NOLB",
+		"DRAIN (agent)":        "DRAIN (agent)",
+		"UP 1/x":               "UP 1/x",
+		"":                     "",
+	} {
+		assert.Equal(t, state, haproxyServerState(status), status)
+	}
+}
+
+func TestHAProxyServerStateChanges(t *testing.T) {
+	proxy := &haproxyRuntimeAPIProxy{}
+	now := time.Now()
+	web1 := haproxyServer{proxy: "web", server: "web1"}
+	web2 := haproxyServer{proxy: "web", server: "web2"}
+
+	assert.Empty(t, proxy.serverStateChanges([]haproxyServerStat{
+		{haproxyServer: web1, status: "UP", checkStatus: "L7OK"},
+	}, now))
+	// transitional statuses and new servers have no events
+	assert.Empty(t, proxy.serverStateChanges([]haproxyServerStat{
+		{haproxyServer: web1, status: "UP 1/3", checkStatus: "L7STS"},
+		{haproxyServer: web2, status: "DOWN", checkStatus: "L4CON"},
+	}, now))
+
+	events := proxy.serverStateChanges([]haproxyServerStat{
+		{haproxyServer: web1, status: "DOWN", checkStatus: "L7STS"},
+		{haproxyServer: web2, status: "MAINT"},
+	}, now)
+	assert.Equal(t, []*event.Event{
+		{
+			EventType:  "haproxy.server.state_change",
+			Category:   event.AGENT,
+			Dimensions: map[string]string{"proxy_name": "web", "service_name": "web1"},
+			Properties: map[string]any{"old_status": "UP 1/3", "new_status": "DOWN", "check_status": "L7STS"},
+			Timestamp:  now,
+		},
+		{
+			EventType:  "haproxy.server.state_change",
+			Category:   event.AGENT,
+			Dimensions: map[string]string{"proxy_name": "web", "service_name": "web2"},
+			Properties: map[string]any{"old_status": "DOWN", "new_status": "MAINT"},
+			Timestamp:  now,
+		},
+	}, events)
+}
+
+func TestHAProxyRuntimeAPIProxy(t *testing.T) {
+	cert, pool, certFile, keyFile := writeRuntimeAPICert(t, t.TempDir())
+	api := newFakeRuntimeAPI(t, cert, pool)
+	runtimeAPI := HAProxyRuntimeAPI{
+		Endpoint: api.listener.Addr().String(),
+		TLS: &configtls.TLSClientSetting{
+			TLSSetting: configtls.TLSSetting{CAFile: certFile, CertFile: certFile, KeyFile: keyFile},
+		},
+	}
+	monitorConfig := &runtimeAPIMonitorConfig{
+		MonitorConfig: saconfig.MonitorConfig{Type: "haproxy", IntervalSeconds: 10},
+		Timeout:       timeutil.Duration(5 * time.Second),
+	}
+	output := &transactionOutput{datapoints: make(chan []*datapoint.Datapoint, 100), events: make(chan *event.Event, 100)}
+	fake := clock.NewFake(time.Now())
+
+	proxy, err := newHAProxyRuntimeAPIProxy(runtimeAPI, monitorConfig, output, zap.NewNop(), fake)
+	require.NoError(t, err)
+	socket := strings.TrimPrefix(monitorConfig.URL, "unix://")
+	require.NotEqual(t, monitorConfig.URL, socket)
+	proxy.start()
+
+	// the monitor's commands are forwarded over TLS
+	conn, err := net.Dial("unix", socket)
+	require.NoError(t, err)
+	_, err = io.WriteString(conn, "show info\n")
+	require.NoError(t, err)
+	response, err := io.ReadAll(conn)
+	require.NoError(t, err)
+	assert.Equal(t, "Name: HAProxy\nVersion: 2.6.0\n\n", string(response))
+	conn.Close()
+
+	require.Eventually(t, func() bool {
+		return atomic.LoadInt64(&api.showStats) == 1 && fake.Tickers() == 1
+	}, 5*time.Second, 10*time.Millisecond)
+	api.setWeb2Status("DOWN")
+	fake.Advance(10 * time.Second)
+	select {
+	case ev := <-output.events:
+		assert.Equal(t, "haproxy.server.state_change", ev.EventType)
+		assert.Equal(t, map[string]string{"proxy_name": "web", "service_name": "web2"}, ev.Dimensions)
+		assert.Equal(t, map[string]any{"old_status": "UP", "new_status": "DOWN", "check_status": "L4TOUT"}, ev.Properties)
+	case <-time.After(5 * time.Second):
+		t.Fatal("server state change event wasn't sent")
+	}
+	assert.Empty(t, output.events)
+
+	proxy.shutdown()
+	_, err = os.Stat(filepath.Dir(socket))
+	assert.True(t, os.IsNotExist(err))
+
+	var nilProxy *haproxyRuntimeAPIProxy
+	nilProxy.start()
+	nilProxy.shutdown()
+}
+
+func TestHAProxyRuntimeAPIProxyWithoutClientCert(t *testing.T) {
+	cert, pool, certFile, _ := writeRuntimeAPICert(t, t.TempDir())
+	api := newFakeRuntimeAPI(t, cert, pool)
+	runtimeAPI := HAProxyRuntimeAPI{
+		Endpoint: api.listener.Addr().String(),
+		TLS:      &configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{CAFile: certFile}},
+	}
+	monitorConfig := &runtimeAPIMonitorConfig{MonitorConfig: saconfig.MonitorConfig{Type: "haproxy", IntervalSeconds: 10}}
+
+	proxy, err := newHAProxyRuntimeAPIProxy(runtimeAPI, monitorConfig, nil, zap.NewNop(), clock.New())
+	require.NoError(t, err)
+	defer proxy.shutdown()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	_, err = proxy.showStat(ctx)
+	require.Error(t, err)
+	assert.Zero(t, atomic.LoadInt64(&api.showStats))
+}
+
+func TestHAProxyRuntimeAPIProxyWithoutURL(t *testing.T) {
+	monitorConfig := &struct {
+		saconfig.MonitorConfig `yaml:",inline"`
+	}{MonitorConfig: saconfig.MonitorConfig{Type: "collectd/redis"}}
+	proxy, err := newHAProxyRuntimeAPIProxy(HAProxyRuntimeAPI{Endpoint: "haproxy:9999"}, monitorConfig, nil, zap.NewNop(), clock.New())
+	require.EqualError(t, err, `monitor config of type "collectd/redis" has no url`)
+	assert.Nil(t, proxy)
+}
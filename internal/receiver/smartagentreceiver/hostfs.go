@@ -0,0 +1,131 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	filesystemsMonitorType = "filesystems"
+	netIOMonitorType       = "net-io"
+)
+
+var (
+	// containerEnvFiles are the files container runtimes create in their containers.
+	containerEnvFiles = []string{"/.dockerenv", "/run/.containerenv"}
+	// selfCgroupFile lists the cgroups of the collector, whose paths include the container runtime in
+	// cgroup v1 hierarchies.
+	selfCgroupFile = "/proc/self/cgroup"
+	// selfNetNamespace is the network namespace of the collector.
+	selfNetNamespace = "/proc/self/ns/net"
+	containerCgroups = []string{"docker", "kubepods", "containerd", "libpod", "lxc"}
+)
+
+// configureHostFS makes the monitors reporting the host's filesystems and network interfaces report those of the
+// host when the collector runs in a container: the filesystems monitor's hostFSPath defaults to the host's root
+// filesystem the procPath is mounted from, like /hostfs for /hostfs/proc.  Monitors that would report the values
+// of the collector's container instead, without the host's root filesystem or network namespace, are warned about.
+func (r *Receiver) configureHostFS(monitorType string) {
+	if monitorType != filesystemsMonitorType && monitorType != netIOMonitorType {
+		return
+	}
+
+	root := hostFSRoot(saConfig.ProcPath)
+	if monitorType == filesystemsMonitorType && root != "" {
+		if set, err := setStructField(r.config.monitorConfig, "HostFSPath", root, reflect.TypeOf(""), true); err != nil {
+			r.logger.Debug("failed setting the filesystems monitor's hostFSPath", zap.Error(err))
+		} else if set {
+			r.logger.Info("Reporting the filesystems of the host's root filesystem", zap.String("hostFSPath", root))
+		}
+	}
+
+	if !inContainer() {
+		return
+	}
+	if root == "" {
+		r.logger.Warn(
+			"The collector runs in a container without the host's procfs, so the monitor reports the values of the "+
+				"container instead of the host's. Mount the host's root filesystem in the container and set the smartagent "+
+				"extension's hostFSRoot to its path.",
+			zap.String("monitor_type", monitorType),
+		)
+		return
+	}
+	if monitorType == netIOMonitorType && !inHostNetNamespace(saConfig.ProcPath) {
+		r.logger.Warn(
+			"The collector runs in a container with its own network namespace, so the monitor reports the network "+
+				"interfaces of the container instead of the host's. Run the container in the host's network namespace, "+
+				"like with hostNetwork: true on Kubernetes or --network host with Docker.",
+			zap.String("monitor_type", monitorType),
+		)
+	}
+}
+
+// hostFSRoot returns the root filesystem the procPath is mounted from, like /hostfs for /hostfs/proc, or "" for
+// the collector's own /proc.
+func hostFSRoot(procPath string) string {
+	procPath = filepath.Clean(procPath)
+	if procPath == "/proc" || filepath.Base(procPath) != "proc" {
+		return ""
+	}
+	return filepath.Dir(procPath)
+}
+
+// inContainer returns whether the collector runs in a container, identified by the files of container runtimes
+// or the collector's cgroups.
+func inContainer() bool {
+	for _, file := range containerEnvFiles {
+		if _, err := os.Stat(file); err == nil {
+			return true
+		}
+	}
+	content, err := os.ReadFile(selfCgroupFile)
+	if err != nil {
+		return false
+	}
+	for _, line := range strings.Split(string(content), "\n") {
+		// hierarchy-ID:controllers:path
+		parts := strings.SplitN(line, ":", 3)
+		if len(parts) != 3 {
+			continue
+		}
+		for _, cgroup := range containerCgroups {
+			if strings.Contains(parts[2], cgroup) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// inHostNetNamespace returns whether the collector is in the network namespace of the host's init process, or
+// whether it can't be determined.
+func inHostNetNamespace(procPath string) bool {
+	self, err := os.Readlink(selfNetNamespace)
+	if err != nil {
+		return true
+	}
+	host, err := os.Readlink(filepath.Join(procPath, "1", "ns", "net"))
+	if err != nil {
+		return true
+	}
+	return self == host
+}
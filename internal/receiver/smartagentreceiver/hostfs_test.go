@@ -0,0 +1,126 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/filesystems"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/netio"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestHostFSRoot(t *testing.T) {
+	assert.Equal(t, "", hostFSRoot("/proc"))
+	assert.Equal(t, "", hostFSRoot("/proc/"))
+	assert.Equal(t, "", hostFSRoot("/custom/procfs"))
+	assert.Equal(t, "/hostfs", hostFSRoot("/hostfs/proc"))
+	assert.Equal(t, "/host", hostFSRoot("/host/proc/"))
+}
+
+func TestInContainer(t *testing.T) {
+	dir := t.TempDir()
+	cgroupFile := filepath.Join(dir, "cgroup")
+	defer func(files []string, cgroup string) {
+		containerEnvFiles, selfCgroupFile = files, cgroup
+	}(containerEnvFiles, selfCgroupFile)
+	containerEnvFiles = []string{filepath.Join(dir, ".dockerenv")}
+	selfCgroupFile = cgroupFile
+
+	assert.False(t, inContainer())
+
+	require.NoError(t, os.WriteFile(cgroupFile, []byte("12:cpuset:/\n0::/init.scope\n"), 0600))
+	assert.False(t, inContainer())
+
+	require.NoError(t, os.WriteFile(cgroupFile, []byte("12:cpuset:/kubepods/burstable/pod1234/abcd\n"), 0600))
+	assert.True(t, inContainer())
+
+	require.NoError(t, os.WriteFile(cgroupFile, []byte("0::/\n"), 0600))
+	require.NoError(t, os.WriteFile(containerEnvFiles[0], nil, 0600))
+	assert.True(t, inContainer())
+}
+
+func TestConfigureHostFS(t *testing.T) {
+	dir := t.TempDir()
+	defer func(files []string, cgroup, netNamespace string, cfg *saconfig.Config) {
+		containerEnvFiles, selfCgroupFile, selfNetNamespace, saConfig = files, cgroup, netNamespace, cfg
+	}(containerEnvFiles, selfCgroupFile, selfNetNamespace, saConfig)
+	dockerEnv := filepath.Join(dir, ".dockerenv")
+	require.NoError(t, os.WriteFile(dockerEnv, nil, 0600))
+	containerEnvFiles = []string{dockerEnv}
+	selfCgroupFile = filepath.Join(dir, "cgroup")
+
+	// the host's procfs mounted at <dir>/hostfs/proc, with the network namespaces of the collector and host init
+	procPath := filepath.Join(dir, "hostfs", "proc")
+	require.NoError(t, os.MkdirAll(filepath.Join(procPath, "1", "ns"), 0700))
+	require.NoError(t, os.Symlink("net:[4026531992]", filepath.Join(procPath, "1", "ns", "net")))
+	selfNetNamespace = filepath.Join(dir, "net")
+	require.NoError(t, os.Symlink("net:[4026532301]", selfNetNamespace))
+
+	newReceiver := func(monitorConfig saconfig.MonitorCustomConfig) (*Receiver, *observer.ObservedLogs) {
+		logCore, logs := observer.New(zap.InfoLevel)
+		r := NewReceiver(newReceiverCreateSettings(), Config{monitorConfig: monitorConfig})
+		r.logger = zap.New(logCore)
+		return r, logs
+	}
+
+	saConfig = &saconfig.Config{ProcPath: procPath}
+	fsConfig := &filesystems.Config{MonitorConfig: saconfig.MonitorConfig{Type: "filesystems"}}
+	r, logs := newReceiver(fsConfig)
+	r.configureHostFS("filesystems")
+	assert.Equal(t, filepath.Join(dir, "hostfs"), fsConfig.HostFSPath)
+	require.Equal(t, 1, logs.Len())
+	assert.Equal(t, "Reporting the filesystems of the host's root filesystem", logs.All()[0].Message)
+
+	// a configured hostFSPath is kept
+	fsConfig = &filesystems.Config{MonitorConfig: saconfig.MonitorConfig{Type: "filesystems"}, HostFSPath: "/rootfs"}
+	r, logs = newReceiver(fsConfig)
+	r.configureHostFS("filesystems")
+	assert.Equal(t, "/rootfs", fsConfig.HostFSPath)
+	assert.Equal(t, 0, logs.Len())
+
+	r, logs = newReceiver(&netio.Config{MonitorConfig: saconfig.MonitorConfig{Type: "net-io"}})
+	r.configureHostFS("net-io")
+	require.Equal(t, 1, logs.Len())
+	assert.Contains(t, logs.All()[0].Message, "reports the network interfaces of the container instead of the host's")
+
+	// in the host's network namespace
+	require.NoError(t, os.Remove(selfNetNamespace))
+	require.NoError(t, os.Symlink("net:[4026531992]", selfNetNamespace))
+	r, logs = newReceiver(&netio.Config{MonitorConfig: saconfig.MonitorConfig{Type: "net-io"}})
+	r.configureHostFS("net-io")
+	assert.Equal(t, 0, logs.Len())
+
+	// without the host's procfs
+	saConfig = &saconfig.Config{ProcPath: "/proc"}
+	for _, monitorType := range []string{"filesystems", "net-io"} {
+		r, logs = newReceiver(&netio.Config{MonitorConfig: saconfig.MonitorConfig{Type: monitorType}})
+		r.configureHostFS(monitorType)
+		require.Equal(t, 1, logs.Len())
+		assert.Contains(t, logs.All()[0].Message, "The collector runs in a container without the host's procfs")
+	}
+
+	// outside of containers
+	require.NoError(t, os.Remove(dockerEnv))
+	r, logs = newReceiver(&netio.Config{MonitorConfig: saconfig.MonitorConfig{Type: "net-io"}})
+	r.configureHostFS("net-io")
+	assert.Equal(t, 0, logs.Len())
+}
@@ -0,0 +1,608 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+const (
+	httpMonitorType = "http"
+
+	// maxHTTPTransactionBodySize limits the response body read for assertions and extractions.
+	maxHTTPTransactionBodySize = 1 << 20
+
+	httpTransactionFailedEventType = "http.transaction.failed"
+
+	transactionDimension = "transaction"
+	stepDimension        = "step"
+)
+
+// HTTPTransaction is a scripted check of the http monitor: a sequence of requests whose steps can use
+// values extracted from the responses of the previous ones, like a session token from a login request.
+type HTTPTransaction struct {
+	Name  string                `mapstructure:"name" yaml:"name"`
+	Steps []HTTPTransactionStep `mapstructure:"steps" yaml:"steps"`
+	// Defaults to the monitor's intervalSeconds.
+	IntervalSeconds int `mapstructure:"intervalSeconds" yaml:"intervalSeconds"`
+	// The timeout of the whole transaction, defaulting to the transaction's interval.
+	TimeoutSeconds int `mapstructure:"timeoutSeconds" yaml:"timeoutSeconds"`
+}
+
+// HTTPTransactionStep is a request of a transaction.  Its url, headers, and body are text/templates
+// rendered with the values extracted by the previous steps, like `Bearer {{.token}}`.
+type HTTPTransactionStep struct {
+	urlTemplate     *template.Template
+	bodyTemplate    *template.Template
+	headerTemplates map[string]*template.Template
+	Headers         map[string]string `mapstructure:"headers" yaml:"headers"`
+	// Values extracted from the response for the following steps, by their name.
+	Extract map[string]HTTPExtraction `mapstructure:"extract" yaml:"extract"`
+	// Defaults to step<n>, the step's position in the transaction.
+	Name   string `mapstructure:"name" yaml:"name"`
+	Method string `mapstructure:"method" yaml:"method"`
+	// An absolute URL, or a path relative to the monitor's host, port, and useHTTPS options.
+	URL        string         `mapstructure:"url" yaml:"url"`
+	Body       string         `mapstructure:"body" yaml:"body"`
+	Assertions HTTPAssertions `mapstructure:"assertions" yaml:"assertions"`
+}
+
+// HTTPExtraction extracts a value from a response, with exactly one of its fields.
+type HTTPExtraction struct {
+	regexp *regexp.Regexp
+	// The name of a response header.
+	Header string `mapstructure:"header" yaml:"header"`
+	// The dot-separated path of a JSON response body value, like `data.items.0.id`.
+	JSONPath string `mapstructure:"jsonPath" yaml:"jsonPath"`
+	// A regular expression matching the response body, extracting its first capture group.
+	Regex string `mapstructure:"regex" yaml:"regex"`
+}
+
+// HTTPAssertions are the conditions a step's response must meet for the step to succeed.
+type HTTPAssertions struct {
+	bodyRegexp *regexp.Regexp
+	// Defaults to any status code below 400.
+	StatusCodes  []int  `mapstructure:"statusCodes" yaml:"statusCodes"`
+	BodyContains string `mapstructure:"bodyContains" yaml:"bodyContains"`
+	BodyRegex    string `mapstructure:"bodyRegex" yaml:"bodyRegex"`
+	// The maximum response time of the step.  0 disables the assertion.
+	MaxLatencyMs int `mapstructure:"maxLatencyMs" yaml:"maxLatencyMs"`
+}
+
+func getHTTPTransactionsFromAllSettings(allSettings map[string]any) ([]HTTPTransaction, error) {
+	value, ok := allSettings["transactions"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "transactions")
+	if _, isSlice := value.([]any); !isSlice {
+		return nil, errHTTPTransactionsValue
+	}
+	asBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errHTTPTransactionsValue, err)
+	}
+	var transactions []HTTPTransaction
+	if err = yaml.UnmarshalStrict(asBytes, &transactions); err != nil {
+		return nil, fmt.Errorf("%w: %v", errHTTPTransactionsValue, err)
+	}
+	if err = parseHTTPTransactions(transactions); err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// parseHTTPTransactions validates the transactions, sets the default step names and methods, and parses
+// their templates and regular expressions.
+func parseHTTPTransactions(transactions []HTTPTransaction) error {
+	names := map[string]bool{}
+	for i := range transactions {
+		transaction := &transactions[i]
+		if transaction.Name == "" {
+			return fmt.Errorf("transactions[%d]: name must not be empty", i)
+		}
+		if names[transaction.Name] {
+			return fmt.Errorf("transactions[%d]: duplicate name %q", i, transaction.Name)
+		}
+		names[transaction.Name] = true
+		if transaction.IntervalSeconds < 0 || transaction.TimeoutSeconds < 0 {
+			return fmt.Errorf("transactions[%d]: intervalSeconds and timeoutSeconds must be non-negative", i)
+		}
+		if len(transaction.Steps) == 0 {
+			return fmt.Errorf("transactions[%d]: steps must not be empty", i)
+		}
+		for j := range transaction.Steps {
+			if err := parseHTTPTransactionStep(&transaction.Steps[j], j); err != nil {
+				return fmt.Errorf("transactions[%d].steps[%d]: %w", i, j, err)
+			}
+		}
+	}
+	return nil
+}
+
+func parseHTTPTransactionStep(step *HTTPTransactionStep, index int) error {
+	if step.Name == "" {
+		step.Name = fmt.Sprintf("step%d", index+1)
+	}
+	if step.Method == "" {
+		step.Method = http.MethodGet
+	}
+	step.Method = strings.ToUpper(step.Method)
+	if step.URL == "" {
+		return fmt.Errorf("url must not be empty")
+	}
+
+	var err error
+	if step.urlTemplate, err = parseStepTemplate("url", step.URL); err != nil {
+		return err
+	}
+	if step.bodyTemplate, err = parseStepTemplate("body", step.Body); err != nil {
+		return err
+	}
+	step.headerTemplates = make(map[string]*template.Template, len(step.Headers))
+	for name, value := range step.Headers {
+		if step.headerTemplates[name], err = parseStepTemplate("headers::"+name, value); err != nil {
+			return err
+		}
+	}
+
+	for name, extraction := range step.Extract {
+		set := 0
+		for _, field := range []string{extraction.Header, extraction.JSONPath, extraction.Regex} {
+			if field != "" {
+				set++
+			}
+		}
+		if set != 1 {
+			return fmt.Errorf("extract::%s: exactly one of header, jsonPath, and regex must be set", name)
+		}
+		if extraction.Regex != "" {
+			if extraction.regexp, err = regexp.Compile(extraction.Regex); err != nil {
+				return fmt.Errorf("extract::%s: invalid regex: %w", name, err)
+			}
+			if extraction.regexp.NumSubexp() == 0 {
+				return fmt.Errorf("extract::%s: regex must have a capture group", name)
+			}
+		}
+		step.Extract[name] = extraction
+	}
+
+	for _, code := range step.Assertions.StatusCodes {
+		if code < 100 || code > 599 {
+			return fmt.Errorf("assertions: invalid status code %d", code)
+		}
+	}
+	if step.Assertions.MaxLatencyMs < 0 {
+		return fmt.Errorf("assertions: maxLatencyMs must be non-negative")
+	}
+	if step.Assertions.BodyRegex != "" {
+		if step.Assertions.bodyRegexp, err = regexp.Compile(step.Assertions.BodyRegex); err != nil {
+			return fmt.Errorf("assertions: invalid bodyRegex: %w", err)
+		}
+	}
+	return nil
+}
+
+func parseStepTemplate(name, text string) (*template.Template, error) {
+	if !strings.Contains(text, "{{") {
+		return nil, nil
+	}
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s template: %w", name, err)
+	}
+	return tmpl, nil
+}
+
+func renderStepTemplate(tmpl *template.Template, text string, values map[string]string) (string, error) {
+	if tmpl == nil {
+		return text, nil
+	}
+	var rendered strings.Builder
+	if err := tmpl.Execute(&rendered, values); err != nil {
+		return "", fmt.Errorf("failed rendering %s: %w", tmpl.Name(), err)
+	}
+	return rendered.String(), nil
+}
+
+// httpTransactionBaseURL returns the URL the relative step URLs are resolved against, from the host,
+// port, and useHTTPS options of the http monitor.
+func httpTransactionBaseURL(monitorConfig saconfig.MonitorCustomConfig) *url.URL {
+	options := reflect.Indirect(reflect.ValueOf(monitorConfig))
+	base := &url.URL{Scheme: "http", Host: stringOption(options, "Host")}
+	if boolOption(options, "UseHTTPS") {
+		base.Scheme = "https"
+	}
+	if port := optionField(options, "Port"); port.IsValid() && !port.IsZero() {
+		base.Host = net.JoinHostPort(base.Host, fmt.Sprintf("%v", port.Interface()))
+	}
+	return base
+}
+
+// httpTransactionRunner periodically runs the transactions, sending their datapoints and failure events
+// to the output.
+type httpTransactionRunner struct {
+	ctx          context.Context
+	output       types.Output
+	cancel       context.CancelFunc
+	logger       *zap.Logger
+	clock        clock.Clock
+	baseURL      *url.URL
+	transport    http.RoundTripper
+	transactions []*scheduledHTTPTransaction
+	noRedirects  bool
+	wg           sync.WaitGroup
+}
+
+type scheduledHTTPTransaction struct {
+	transaction HTTPTransaction
+	interval    time.Duration
+	timeout     time.Duration
+}
+
+func newHTTPTransactionRunner(
+	transactions []HTTPTransaction, monitorConfig saconfig.MonitorCustomConfig, output types.Output, logger *zap.Logger, clk clock.Clock,
+) *httpTransactionRunner {
+	options := reflect.Indirect(reflect.ValueOf(monitorConfig))
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if boolOption(options, "SkipVerify") {
+		transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: true} // #nosec G402 -- the monitor's own skipVerify option
+	}
+	runner := &httpTransactionRunner{
+		output:      output,
+		logger:      logger,
+		clock:       clk,
+		baseURL:     httpTransactionBaseURL(monitorConfig),
+		transport:   transport,
+		noRedirects: boolOption(options, "NoRedirects"),
+	}
+	intervalSeconds := monitorConfig.MonitorConfigCore().IntervalSeconds
+	for _, transaction := range transactions {
+		scheduled := &scheduledHTTPTransaction{
+			transaction: transaction,
+			interval:    time.Duration(transaction.IntervalSeconds) * time.Second,
+			timeout:     time.Duration(transaction.TimeoutSeconds) * time.Second,
+		}
+		if scheduled.interval == 0 {
+			scheduled.interval = time.Duration(intervalSeconds) * time.Second
+		}
+		if scheduled.timeout == 0 {
+			scheduled.timeout = scheduled.interval
+		}
+		runner.transactions = append(runner.transactions, scheduled)
+	}
+	return runner
+}
+
+// start runs each transaction every interval until shutdown.  It's a noop for a nil instance.
+func (r *httpTransactionRunner) start() {
+	if r == nil {
+		return
+	}
+	r.ctx, r.cancel = context.WithCancel(context.Background())
+	for _, transaction := range r.transactions {
+		r.wg.Add(1)
+		go r.run(transaction)
+	}
+}
+
+func (r *httpTransactionRunner) run(transaction *scheduledHTTPTransaction) {
+	defer r.wg.Done()
+	ticker := r.clock.NewTicker(transaction.interval)
+	defer ticker.Stop()
+	for {
+		r.collect(transaction)
+		select {
+		case <-r.ctx.Done():
+			return
+		case <-ticker.Chan():
+		}
+	}
+}
+
+// shutdown cancels the in-flight transactions.  It's a noop for a nil instance.
+func (r *httpTransactionRunner) shutdown() {
+	if r == nil {
+		return
+	}
+	if r.cancel != nil {
+		r.cancel()
+	}
+	r.wg.Wait()
+	r.transport.(*http.Transport).CloseIdleConnections()
+}
+
+func (r *httpTransactionRunner) collect(scheduled *scheduledHTTPTransaction) {
+	ctx, cancel := context.WithTimeout(r.ctx, scheduled.timeout)
+	defer cancel()
+	result := r.runTransaction(ctx, scheduled.transaction)
+	if r.ctx.Err() != nil {
+		// canceled by shutdown, not failed
+		return
+	}
+	r.output.SendDatapoints(result.datapoints(scheduled.transaction.Name)...)
+	if result.failedStep != "" {
+		r.logger.Debug(
+			"HTTP transaction failed",
+			zap.String("transaction", scheduled.transaction.Name),
+			zap.String("step", result.failedStep),
+			zap.String("reason", result.reason),
+		)
+		r.output.SendEvent(result.failureEvent(scheduled.transaction.Name))
+	}
+}
+
+// httpStepResult is the outcome of a transaction step that sent its request.
+type httpStepResult struct {
+	name       string
+	method     string
+	duration   time.Duration
+	statusCode int
+	success    bool
+}
+
+type httpTransactionResult struct {
+	timestamp time.Time
+	steps     []httpStepResult
+	duration  time.Duration
+	// the name of the step that failed, if any, and why
+	failedStep string
+	reason     string
+	statusCode int
+}
+
+// runTransaction runs the steps of the transaction in order, with a cookie jar for the transaction's
+// session, stopping at the first failed step.
+func (r *httpTransactionRunner) runTransaction(ctx context.Context, transaction HTTPTransaction) httpTransactionResult {
+	result := httpTransactionResult{timestamp: time.Now()}
+	jar, _ := cookiejar.New(nil)
+	client := &http.Client{Transport: r.transport, Jar: jar}
+	if r.noRedirects {
+		client.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	}
+	values := map[string]string{}
+	for _, step := range transaction.Steps {
+		stepResult, reason := r.runStep(ctx, client, step, values)
+		result.duration += stepResult.duration
+		if stepResult.method != "" {
+			result.steps = append(result.steps, stepResult)
+		}
+		if reason != "" {
+			result.failedStep, result.reason, result.statusCode = step.Name, reason, stepResult.statusCode
+			break
+		}
+	}
+	return result
+}
+
+// runStep sends the request of the step and checks its response, adding the extracted values.  It returns
+// why the step failed, if it did, with a result without method if the request wasn't sent.
+func (r *httpTransactionRunner) runStep(
+	ctx context.Context, client *http.Client, step HTTPTransactionStep, values map[string]string,
+) (httpStepResult, string) {
+	result := httpStepResult{name: step.Name}
+	req, err := r.newStepRequest(ctx, step, values)
+	if err != nil {
+		return result, err.Error()
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		result.method, result.duration = step.Method, time.Since(start)
+		return result, fmt.Sprintf("request failed: %v", err)
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxHTTPTransactionBodySize))
+	_ = resp.Body.Close()
+	result.method, result.duration, result.statusCode = step.Method, time.Since(start), resp.StatusCode
+	if err != nil {
+		return result, fmt.Sprintf("failed reading response body: %v", err)
+	}
+
+	if reason := step.Assertions.check(resp.StatusCode, body, result.duration); reason != "" {
+		return result, reason
+	}
+	for name, extraction := range step.Extract {
+		value, err := extraction.extract(resp.Header, body)
+		if err != nil {
+			return result, fmt.Sprintf("failed extracting %s: %v", name, err)
+		}
+		values[name] = value
+	}
+	result.success = true
+	return result, ""
+}
+
+func (r *httpTransactionRunner) newStepRequest(ctx context.Context, step HTTPTransactionStep, values map[string]string) (*http.Request, error) {
+	rawURL, err := renderStepTemplate(step.urlTemplate, step.URL, values)
+	if err != nil {
+		return nil, err
+	}
+	stepURL, err := r.baseURL.Parse(rawURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url %q: %w", rawURL, err)
+	}
+	body, err := renderStepTemplate(step.bodyTemplate, step.Body, values)
+	if err != nil {
+		return nil, err
+	}
+	var bodyReader io.Reader
+	if body != "" {
+		bodyReader = strings.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, step.Method, stepURL.String(), bodyReader)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range step.Headers {
+		if value, err = renderStepTemplate(step.headerTemplates[name], value, values); err != nil {
+			return nil, err
+		}
+		if strings.EqualFold(name, "Host") {
+			req.Host = value
+		} else {
+			req.Header.Set(name, value)
+		}
+	}
+	return req, nil
+}
+
+// check returns why the response doesn't meet the assertions, if it doesn't.
+func (a HTTPAssertions) check(statusCode int, body []byte, latency time.Duration) string {
+	if len(a.StatusCodes) == 0 && statusCode >= 400 {
+		return fmt.Sprintf("unexpected status code %d", statusCode)
+	}
+	if len(a.StatusCodes) != 0 {
+		expected := false
+		for _, code := range a.StatusCodes {
+			expected = expected || code == statusCode
+		}
+		if !expected {
+			return fmt.Sprintf("unexpected status code %d, expected one of %v", statusCode, a.StatusCodes)
+		}
+	}
+	if a.BodyContains != "" && !strings.Contains(string(body), a.BodyContains) {
+		return fmt.Sprintf("response body doesn't contain %q", a.BodyContains)
+	}
+	if a.bodyRegexp != nil && !a.bodyRegexp.Match(body) {
+		return fmt.Sprintf("response body doesn't match %q", a.BodyRegex)
+	}
+	if maxLatency := time.Duration(a.MaxLatencyMs) * time.Millisecond; maxLatency > 0 && latency > maxLatency {
+		return fmt.Sprintf("response time %s exceeds the maximum of %s", latency.Round(time.Millisecond), maxLatency)
+	}
+	return ""
+}
+
+// extract returns the value of the response header or body.
+func (e HTTPExtraction) extract(header http.Header, body []byte) (string, error) {
+	switch {
+	case e.Header != "":
+		if value := header.Get(e.Header); value != "" {
+			return value, nil
+		}
+		return "", fmt.Errorf("no %s response header", e.Header)
+	case e.regexp != nil:
+		if match := e.regexp.FindSubmatch(body); match != nil {
+			return string(match[1]), nil
+		}
+		return "", fmt.Errorf("response body doesn't match %q", e.Regex)
+	default:
+		return extractJSONPath(body, e.JSONPath)
+	}
+}
+
+// extractJSONPath returns the value at the dot-separated path of the JSON document, with array elements
+// referenced by their index.  Values that aren't strings are returned as JSON.
+func extractJSONPath(body []byte, path string) (string, error) {
+	var value any
+	if err := json.Unmarshal(body, &value); err != nil {
+		return "", fmt.Errorf("invalid JSON response body: %w", err)
+	}
+	for _, key := range strings.Split(path, ".") {
+		switch v := value.(type) {
+		case map[string]any:
+			var ok bool
+			if value, ok = v[key]; !ok {
+				return "", fmt.Errorf("no %s in the JSON response body", path)
+			}
+		case []any:
+			index, err := strconv.Atoi(key)
+			if err != nil || index < 0 || index >= len(v) {
+				return "", fmt.Errorf("no %s in the JSON response body", path)
+			}
+			value = v[index]
+		default:
+			return "", fmt.Errorf("no %s in the JSON response body", path)
+		}
+	}
+	if s, ok := value.(string); ok {
+		return s, nil
+	}
+	asJSON, err := json.Marshal(value)
+	return string(asJSON), err
+}
+
+// datapoints returns the response time, status code, and success of each step that sent its request,
+// and the duration and success of the transaction.
+func (r httpTransactionResult) datapoints(transaction string) []*datapoint.Datapoint {
+	var datapoints []*datapoint.Datapoint
+	for _, step := range r.steps {
+		dimensions := map[string]string{transactionDimension: transaction, stepDimension: step.name, "method": step.method}
+		datapoints = append(datapoints,
+			datapoint.New("http.transaction.step.response_time", dimensions, datapoint.NewFloatValue(step.duration.Seconds()), datapoint.Gauge, r.timestamp),
+			datapoint.New("http.transaction.step.success", dimensions, boolValue(step.success), datapoint.Gauge, r.timestamp),
+		)
+		if step.statusCode != 0 {
+			datapoints = append(datapoints,
+				datapoint.New("http.transaction.step.status_code", dimensions, datapoint.NewIntValue(int64(step.statusCode)), datapoint.Gauge, r.timestamp),
+			)
+		}
+	}
+	dimensions := map[string]string{transactionDimension: transaction}
+	return append(datapoints,
+		datapoint.New("http.transaction.duration", dimensions, datapoint.NewFloatValue(r.duration.Seconds()), datapoint.Gauge, r.timestamp),
+		datapoint.New("http.transaction.success", dimensions, boolValue(r.failedStep == ""), datapoint.Gauge, r.timestamp),
+	)
+}
+
+// failureEvent returns the synthetic availability event of the failed transaction.
+func (r httpTransactionResult) failureEvent(transaction string) *event.Event {
+	properties := map[string]any{
+		"failed_step": r.failedStep,
+		"reason":      r.reason,
+		"duration_ms": r.duration.Milliseconds(),
+	}
+	if r.statusCode != 0 {
+		properties["status_code"] = r.statusCode
+	}
+	return &event.Event{
+		EventType:  httpTransactionFailedEventType,
+		Category:   event.AGENT,
+		Dimensions: map[string]string{transactionDimension: transaction},
+		Properties: properties,
+		Timestamp:  r.timestamp,
+	}
+}
+
+func boolValue(b bool) datapoint.Value {
+	if b {
+		return datapoint.NewIntValue(1)
+	}
+	return datapoint.NewIntValue(0)
+}
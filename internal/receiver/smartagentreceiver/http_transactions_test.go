@@ -0,0 +1,293 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+func newTestShop(t *testing.T) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/login", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		var credentials map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&credentials); err != nil || credentials["password"] != "s3cr3t" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "abc", Path: "/"})
+		w.Header().Set("X-Request-Id", "42")
+		_, _ = w.Write([]byte(`{"data": {"token": "t0k3n", "items": [{"id": 7}]}}`))
+	})
+	mux.HandleFunc("/api/cart", func(w http.ResponseWriter, r *http.Request) {
+		cookie, err := r.Cookie("session")
+		if err != nil || cookie.Value != "abc" || r.Header.Get("Authorization") != "Bearer t0k3n" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`cart ` + r.URL.Query().Get("item") + ` has 2 items`))
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func newTestTransaction(t *testing.T, password string) HTTPTransaction {
+	transactions := []HTTPTransaction{{
+		Name: "checkout",
+		Steps: []HTTPTransactionStep{
+			{
+				Name:   "login",
+				Method: "post",
+				URL:    "/api/login",
+				Body:   `{"user": "synthetic", "password": "` + password + `"}`,
+				Extract: map[string]HTTPExtraction{
+					"token":     {JSONPath: "data.token"},
+					"item":      {JSONPath: "data.items.0.id"},
+					"requestID": {Header: "X-Request-Id"},
+				},
+				Assertions: HTTPAssertions{StatusCodes: []int{200}},
+			},
+			{
+				URL:     "/api/cart?item={{.item}}",
+				Headers: map[string]string{"Authorization": "Bearer {{.token}}"},
+				Extract: map[string]HTTPExtraction{
+					"count": {Regex: `has (\d+) items`},
+				},
+				Assertions: HTTPAssertions{BodyRegex: `^cart 7 `, MaxLatencyMs: 5000},
+			},
+		},
+	}}
+	require.NoError(t, parseHTTPTransactions(transactions))
+	return transactions[0]
+}
+
+func newTestTransactionRunner(t *testing.T, server *httptest.Server, output types.Output) *httpTransactionRunner {
+	baseURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	return &httpTransactionRunner{
+		output: output, logger: zap.NewNop(), clock: clock.New(), baseURL: baseURL, transport: http.DefaultTransport.(*http.Transport).Clone(),
+	}
+}
+
+func TestParseHTTPTransactions(t *testing.T) {
+	for _, test := range []struct {
+		err         string
+		transaction HTTPTransaction
+	}{
+		{transaction: HTTPTransaction{Steps: []HTTPTransactionStep{{URL: "/"}}}, err: "transactions[0]: name must not be empty"},
+		{transaction: HTTPTransaction{Name: "t"}, err: "transactions[0]: steps must not be empty"},
+		{transaction: HTTPTransaction{Name: "t", IntervalSeconds: -1, Steps: []HTTPTransactionStep{{URL: "/"}}}, err: "transactions[0]: intervalSeconds and timeoutSeconds must be non-negative"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{}}}, err: "transactions[0].steps[0]: url must not be empty"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{URL: "/{{.token"}}}, err: "transactions[0].steps[0]: invalid url template: template: url:1: unclosed action"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{URL: "/", Extract: map[string]HTTPExtraction{"token": {}}}}}, err: "transactions[0].steps[0]: extract::token: exactly one of header, jsonPath, and regex must be set"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{URL: "/", Extract: map[string]HTTPExtraction{"token": {Regex: "token"}}}}}, err: "transactions[0].steps[0]: extract::token: regex must have a capture group"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{URL: "/", Assertions: HTTPAssertions{StatusCodes: []int{99}}}}}, err: "transactions[0].steps[0]: assertions: invalid status code 99"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{URL: "/", Assertions: HTTPAssertions{MaxLatencyMs: -1}}}}, err: "transactions[0].steps[0]: assertions: maxLatencyMs must be non-negative"},
+		{transaction: HTTPTransaction{Name: "t", Steps: []HTTPTransactionStep{{URL: "/", Assertions: HTTPAssertions{BodyRegex: "("}}}}, err: "transactions[0].steps[0]: assertions: invalid bodyRegex: error parsing regexp: missing closing ): `(`"},
+	} {
+		t.Run(test.err, func(t *testing.T) {
+			require.EqualError(t, parseHTTPTransactions([]HTTPTransaction{test.transaction}), test.err)
+		})
+	}
+
+	duplicates := []HTTPTransaction{
+		{Name: "t", Steps: []HTTPTransactionStep{{URL: "/"}}},
+		{Name: "t", Steps: []HTTPTransactionStep{{URL: "/"}}},
+	}
+	require.EqualError(t, parseHTTPTransactions(duplicates), `transactions[1]: duplicate name "t"`)
+}
+
+func TestRunHTTPTransaction(t *testing.T) {
+	server := newTestShop(t)
+	runner := newTestTransactionRunner(t, server, nil)
+
+	result := runner.runTransaction(context.Background(), newTestTransaction(t, "s3cr3t"))
+	assert.Empty(t, result.reason)
+	assert.Empty(t, result.failedStep)
+	require.Len(t, result.steps, 2)
+	assert.Equal(t, "login", result.steps[0].name)
+	assert.Equal(t, "POST", result.steps[0].method)
+	assert.Equal(t, 200, result.steps[0].statusCode)
+	assert.True(t, result.steps[0].success)
+	assert.Equal(t, "step2", result.steps[1].name)
+	assert.Equal(t, "GET", result.steps[1].method)
+	assert.True(t, result.steps[1].success)
+
+	datapoints := result.datapoints("checkout")
+	require.Len(t, datapoints, 8)
+	assert.Equal(t, "http.transaction.step.response_time", datapoints[0].Metric)
+	assert.Equal(t, map[string]string{"transaction": "checkout", "step": "login", "method": "POST"}, datapoints[0].Dimensions)
+	assert.Equal(t, "http.transaction.step.success", datapoints[1].Metric)
+	assert.Equal(t, datapoint.NewIntValue(1), datapoints[1].Value)
+	assert.Equal(t, "http.transaction.step.status_code", datapoints[2].Metric)
+	assert.Equal(t, datapoint.NewIntValue(200), datapoints[2].Value)
+	assert.Equal(t, "http.transaction.duration", datapoints[6].Metric)
+	assert.Equal(t, map[string]string{"transaction": "checkout"}, datapoints[6].Dimensions)
+	assert.Equal(t, "http.transaction.success", datapoints[7].Metric)
+	assert.Equal(t, datapoint.NewIntValue(1), datapoints[7].Value)
+}
+
+func TestRunFailingHTTPTransaction(t *testing.T) {
+	server := newTestShop(t)
+	runner := newTestTransactionRunner(t, server, nil)
+
+	result := runner.runTransaction(context.Background(), newTestTransaction(t, "wrong"))
+	assert.Equal(t, "login", result.failedStep)
+	assert.Equal(t, "unexpected status code 401, expected one of [200]", result.reason)
+	require.Len(t, result.steps, 1)
+	assert.False(t, result.steps[0].success)
+
+	datapoints := result.datapoints("checkout")
+	require.Len(t, datapoints, 5)
+	assert.Equal(t, "http.transaction.success", datapoints[4].Metric)
+	assert.Equal(t, datapoint.NewIntValue(0), datapoints[4].Value)
+
+	ev := result.failureEvent("checkout")
+	assert.Equal(t, "http.transaction.failed", ev.EventType)
+	assert.Equal(t, event.AGENT, ev.Category)
+	assert.Equal(t, map[string]string{"transaction": "checkout"}, ev.Dimensions)
+	assert.Equal(t, "login", ev.Properties["failed_step"])
+	assert.Equal(t, "unexpected status code 401, expected one of [200]", ev.Properties["reason"])
+	assert.Equal(t, 401, ev.Properties["status_code"])
+
+	// a value that can't be extracted fails its step
+	transaction := newTestTransaction(t, "s3cr3t")
+	transaction.Steps[1].Extract["missing"] = HTTPExtraction{Header: "X-Missing"}
+	result = runner.runTransaction(context.Background(), transaction)
+	assert.Equal(t, "step2", result.failedStep)
+	assert.Equal(t, "failed extracting missing: no X-Missing response header", result.reason)
+
+	// a template referencing a value that wasn't extracted fails its step without sending its request
+	transaction = newTestTransaction(t, "s3cr3t")
+	delete(transaction.Steps[0].Extract, "item")
+	result = runner.runTransaction(context.Background(), transaction)
+	assert.Equal(t, "step2", result.failedStep)
+	assert.Contains(t, result.reason, "failed rendering url")
+	assert.Contains(t, result.reason, `map has no entry for key "item"`)
+	assert.Len(t, result.steps, 1)
+}
+
+func TestHTTPAssertions(t *testing.T) {
+	for _, test := range []struct {
+		assertions HTTPAssertions
+		statusCode int
+		latency    time.Duration
+		reason     string
+	}{
+		{statusCode: 302},
+		{statusCode: 500, reason: "unexpected status code 500"},
+		{assertions: HTTPAssertions{StatusCodes: []int{500}}, statusCode: 500},
+		{assertions: HTTPAssertions{BodyContains: "ok"}, statusCode: 200},
+		{assertions: HTTPAssertions{BodyContains: "error"}, statusCode: 200, reason: `response body doesn't contain "error"`},
+		{assertions: HTTPAssertions{BodyRegex: `"status":\s*"ok"`}, statusCode: 200},
+		{assertions: HTTPAssertions{BodyRegex: `"status":\s*"error"`}, statusCode: 200, reason: `response body doesn't match "\"status\":\\s*\"error\""`},
+		{assertions: HTTPAssertions{MaxLatencyMs: 100}, statusCode: 200, latency: 99 * time.Millisecond},
+		{assertions: HTTPAssertions{MaxLatencyMs: 100}, statusCode: 200, latency: 250 * time.Millisecond, reason: "response time 250ms exceeds the maximum of 100ms"},
+	} {
+		transactions := []HTTPTransaction{{Name: "t", Steps: []HTTPTransactionStep{{URL: "/", Assertions: test.assertions}}}}
+		require.NoError(t, parseHTTPTransactions(transactions))
+		assertions := transactions[0].Steps[0].Assertions
+		assert.Equal(t, test.reason, assertions.check(test.statusCode, []byte(`{"status": "ok"}`), test.latency))
+	}
+}
+
+func TestExtractJSONPath(t *testing.T) {
+	body := []byte(`{"data": {"token": "t0k3n", "count": 2, "items": [{"id": 7}]}}`)
+	for path, expected := range map[string]string{
+		"data.token":      "t0k3n",
+		"data.count":      "2",
+		"data.items.0.id": "7",
+		"data.items.0":    `{"id":7}`,
+	} {
+		value, err := extractJSONPath(body, path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, value)
+	}
+	for _, path := range []string{"data.missing", "data.items.1", "data.items.first", "data.token.value"} {
+		_, err := extractJSONPath(body, path)
+		assert.EqualError(t, err, "no "+path+" in the JSON response body")
+	}
+	_, err := extractJSONPath([]byte("not json"), "data")
+	assert.Error(t, err)
+}
+
+type transactionOutput struct {
+	types.Output
+	datapoints chan []*datapoint.Datapoint
+	events     chan *event.Event
+}
+
+func (o *transactionOutput) SendDatapoints(datapoints ...*datapoint.Datapoint) {
+	o.datapoints <- datapoints
+}
+
+func (o *transactionOutput) SendEvent(ev *event.Event) {
+	o.events <- ev
+}
+
+func TestHTTPTransactionRunner(t *testing.T) {
+	server := newTestShop(t)
+	output := &transactionOutput{datapoints: make(chan []*datapoint.Datapoint, 100), events: make(chan *event.Event, 100)}
+	runner := newTestTransactionRunner(t, server, output)
+	fake := clock.NewFake(time.Now())
+	runner.clock = fake
+	runner.transactions = []*scheduledHTTPTransaction{
+		{transaction: newTestTransaction(t, "wrong"), interval: time.Minute, timeout: 5 * time.Second},
+	}
+	runner.start()
+
+	for i := 0; i < 2; i++ {
+		if i > 0 {
+			assert.Empty(t, output.datapoints)
+			fake.Advance(time.Minute)
+		}
+		select {
+		case datapoints := <-output.datapoints:
+			assert.Len(t, datapoints, 5)
+		case <-time.After(5 * time.Second):
+			t.Fatal("http transaction datapoints weren't sent")
+		}
+		select {
+		case ev := <-output.events:
+			assert.Equal(t, "http.transaction.failed", ev.EventType)
+		case <-time.After(5 * time.Second):
+			t.Fatal("http transaction failure event wasn't sent")
+		}
+	}
+	runner.shutdown()
+
+	var nilRunner *httpTransactionRunner
+	nilRunner.start()
+	nilRunner.shutdown()
+}
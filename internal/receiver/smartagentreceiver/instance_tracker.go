@@ -0,0 +1,131 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+)
+
+const (
+	winPerfCountersMonitorType = "telegraf/win_perf_counters"
+
+	instanceDimension      = "instance"
+	objectNameDimension    = "objectname"
+	instanceIndexDimension = "instance_index"
+
+	instanceAddedEventType   = "win_perf_counters.instance.added"
+	instanceRemovedEventType = "win_perf_counters.instance.removed"
+)
+
+// instanceTracker assigns the performance counter object instances expanded from wildcards the lowest
+// index not used by another present instance of their object, so that dashboards can be templated
+// on stable values instead of instance names.  It reports the instances appearing and disappearing
+// as events.
+type instanceTracker struct {
+	now       func() time.Time
+	instances map[string]map[string]*trackedInstance
+	// instances not reported for this long have disappeared
+	expiry time.Duration
+	lock   sync.Mutex
+}
+
+type trackedInstance struct {
+	lastSeen time.Time
+	index    int
+}
+
+func newInstanceTracker(intervalSeconds int) *instanceTracker {
+	return &instanceTracker{
+		now:       time.Now,
+		instances: map[string]map[string]*trackedInstance{},
+		expiry:    2 * time.Duration(intervalSeconds) * time.Second,
+	}
+}
+
+// track adds the instance index dimension to the datapoints of object instances and returns the
+// events for the instances that appeared or disappeared since the previous datapoints.
+func (t *instanceTracker) track(datapoints []*datapoint.Datapoint) []*event.Event {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	var events []*event.Event
+	// remove the disappeared instances first so that their indexes are available to new ones
+	for object, objectInstances := range t.instances {
+		for instance, tracked := range objectInstances {
+			if now.Sub(tracked.lastSeen) > t.expiry {
+				delete(objectInstances, instance)
+				events = append(events, instanceEvent(instanceRemovedEventType, object, instance, tracked.index, now))
+			}
+		}
+		if len(objectInstances) == 0 {
+			delete(t.instances, object)
+		}
+	}
+
+	for _, dp := range datapoints {
+		instance := dp.Dimensions[instanceDimension]
+		if instance == "" {
+			continue
+		}
+		object := dp.Dimensions[objectNameDimension]
+		objectInstances, ok := t.instances[object]
+		if !ok {
+			objectInstances = map[string]*trackedInstance{}
+			t.instances[object] = objectInstances
+		}
+		tracked, ok := objectInstances[instance]
+		if !ok {
+			tracked = &trackedInstance{index: lowestFreeIndex(objectInstances)}
+			objectInstances[instance] = tracked
+			events = append(events, instanceEvent(instanceAddedEventType, object, instance, tracked.index, now))
+		}
+		tracked.lastSeen = now
+		dp.Dimensions[instanceIndexDimension] = strconv.Itoa(tracked.index)
+	}
+
+	return events
+}
+
+func lowestFreeIndex(objectInstances map[string]*trackedInstance) int {
+	used := make(map[int]bool, len(objectInstances))
+	for _, tracked := range objectInstances {
+		used[tracked.index] = true
+	}
+	index := 0
+	for used[index] {
+		index++
+	}
+	return index
+}
+
+func instanceEvent(eventType, object, instance string, index int, timestamp time.Time) *event.Event {
+	return &event.Event{
+		EventType: eventType,
+		Category:  event.AGENT,
+		Dimensions: map[string]string{
+			objectNameDimension:    object,
+			instanceDimension:      instance,
+			instanceIndexDimension: strconv.Itoa(index),
+		},
+		Properties: map[string]any{},
+		Timestamp:  timestamp,
+	}
+}
@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func instanceDatapoint(object, instance string) *datapoint.Datapoint {
+	dims := map[string]string{objectNameDimension: object}
+	if instance != "" {
+		dims[instanceDimension] = instance
+	}
+	return datapoint.New("win_cpu.Percent_Processor_Time", dims, datapoint.NewFloatValue(1), datapoint.Gauge, time.Time{})
+}
+
+func eventSummaries(events []*event.Event) []string {
+	var summaries []string
+	for _, ev := range events {
+		summaries = append(summaries, ev.EventType+" "+ev.Dimensions[objectNameDimension]+"/"+
+			ev.Dimensions[instanceDimension]+"#"+ev.Dimensions[instanceIndexDimension])
+	}
+	return summaries
+}
+
+func TestInstanceTracker(t *testing.T) {
+	tracker := newInstanceTracker(10)
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	datapoints := []*datapoint.Datapoint{
+		instanceDatapoint("Processor", "0"),
+		instanceDatapoint("Processor", "1"),
+		instanceDatapoint("Processor", "0"),
+		instanceDatapoint("Memory", ""),
+		instanceDatapoint("PhysicalDisk", "C:"),
+	}
+	events := tracker.track(datapoints)
+	assert.Equal(t, []string{
+		"win_perf_counters.instance.added Processor/0#0",
+		"win_perf_counters.instance.added Processor/1#1",
+		"win_perf_counters.instance.added PhysicalDisk/C:#0",
+	}, eventSummaries(events))
+	assert.Equal(t, "0", datapoints[0].Dimensions[instanceIndexDimension])
+	assert.Equal(t, "1", datapoints[1].Dimensions[instanceIndexDimension])
+	assert.Equal(t, "0", datapoints[2].Dimensions[instanceIndexDimension])
+	assert.NotContains(t, datapoints[3].Dimensions, instanceIndexDimension)
+	assert.Equal(t, "0", datapoints[4].Dimensions[instanceIndexDimension])
+	for _, ev := range events {
+		assert.Equal(t, event.AGENT, ev.Category)
+		assert.Equal(t, now, ev.Timestamp)
+	}
+
+	// instances keep their index while reported
+	now = now.Add(20 * time.Second)
+	datapoints = []*datapoint.Datapoint{instanceDatapoint("Processor", "1"), instanceDatapoint("PhysicalDisk", "C:")}
+	assert.Empty(t, tracker.track(datapoints))
+	assert.Equal(t, "1", datapoints[0].Dimensions[instanceIndexDimension])
+
+	// unreported instances disappear after two intervals and their index is reused
+	now = now.Add(time.Second)
+	datapoints = []*datapoint.Datapoint{instanceDatapoint("Processor", "2"), instanceDatapoint("Processor", "1")}
+	events = tracker.track(datapoints)
+	assert.ElementsMatch(t, []string{
+		"win_perf_counters.instance.added Processor/2#0",
+		"win_perf_counters.instance.removed Processor/0#0",
+	}, eventSummaries(events))
+	assert.Equal(t, "0", datapoints[0].Dimensions[instanceIndexDimension])
+	assert.Equal(t, "1", datapoints[1].Dimensions[instanceIndexDimension])
+
+	now = now.Add(time.Minute)
+	events = tracker.track(nil)
+	assert.ElementsMatch(t, []string{
+		"win_perf_counters.instance.removed Processor/1#1",
+		"win_perf_counters.instance.removed Processor/2#0",
+		"win_perf_counters.instance.removed PhysicalDisk/C:#0",
+	}, eventSummaries(events))
+	require.Empty(t, tracker.instances)
+}
@@ -0,0 +1,178 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	"go.uber.org/zap"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	// The attributes of entity state log records, as provided by the signalfxdimension receiver.
+	entityEventTypeKey  = "otel.entity.event.type"
+	entityTypeKey       = "otel.entity.type"
+	entityIDKey         = "otel.entity.id"
+	entityAttributesKey = "otel.entity.attributes"
+
+	entityStateEventType = "entity_state"
+	monitorEntityType    = "smartagent.monitor"
+
+	monitorStarted = "started"
+	monitorStopped = "stopped"
+	monitorFailed  = "failed"
+)
+
+// lifecycleEvents reports the monitor starting, stopping, and failing as entity state log records, so that
+// gaps in its metrics can be traced back to the receiver's lifecycle.
+type lifecycleEvents struct {
+	now         func() time.Time
+	consumer    consumer.Logs
+	logger      *zap.Logger
+	receiverID  string
+	monitorType string
+	endpoint    string
+	configHash  string
+}
+
+// newLifecycleEvents returns the lifecycle events of the receiver's monitor, nil if they aren't enabled.
+func newLifecycleEvents(cfg Config, next consumer.Logs, logger *zap.Logger) *lifecycleEvents {
+	if !cfg.LifecycleEvents {
+		return nil
+	}
+	if next == nil {
+		logger.Warn("lifecycleEvents requires the receiver to be used in a logs pipeline, no event will be emitted")
+		return nil
+	}
+	return &lifecycleEvents{
+		now:         time.Now,
+		consumer:    next,
+		logger:      logger,
+		receiverID:  cfg.ID().String(),
+		monitorType: cfg.monitorConfig.MonitorConfigCore().Type,
+		endpoint:    cfg.Endpoint,
+		configHash:  monitorConfigHash(cfg.monitorConfig),
+	}
+}
+
+// monitorConfigHash identifies the monitor config, to tell the restarts that applied config changes apart.
+// Secrets aren't hashed, so that the events don't disclose a hash of them.
+func monitorConfigHash(monitorConfig any) string {
+	content, err := yaml.Marshal(withoutSecrets(reflect.ValueOf(monitorConfig)).Interface())
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(content)
+	return hex.EncodeToString(sum[:8])
+}
+
+// withoutSecrets returns a copy of the value whose struct fields tagged neverLog, the Smart Agent's tag of
+// passwords, tokens, and other secrets, are zeroed.
+func withoutSecrets(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		if v.Kind() == reflect.Interface {
+			return withoutSecrets(v.Elem())
+		}
+		stripped := reflect.New(v.Elem().Type())
+		stripped.Elem().Set(withoutSecrets(v.Elem()))
+		return stripped
+	case reflect.Struct:
+		stripped := reflect.New(v.Type()).Elem()
+		stripped.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if _, secret := field.Tag.Lookup("neverLog"); secret {
+				stripped.Field(i).Set(reflect.Zero(field.Type))
+			} else {
+				stripped.Field(i).Set(withoutSecrets(v.Field(i)))
+			}
+		}
+		return stripped
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		stripped := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			stripped.Index(i).Set(withoutSecrets(v.Index(i)))
+		}
+		return stripped
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		stripped := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for iter := v.MapRange(); iter.Next(); {
+			stripped.SetMapIndex(iter.Key(), withoutSecrets(iter.Value()))
+		}
+		return stripped
+	}
+	return v
+}
+
+// emit sends the entity state of the monitor in the state, with the reason for it, if any.
+func (l *lifecycleEvents) emit(state, reason string, stateErr error) {
+	if l == nil {
+		return
+	}
+	if err := l.consumer.ConsumeLogs(context.Background(), l.toLogs(state, reason, stateErr)); err != nil {
+		l.logger.Warn("failed emitting monitor lifecycle event", zap.String("state", state), zap.Error(err))
+	}
+}
+
+func (l *lifecycleEvents) toLogs(state, reason string, stateErr error) plog.Logs {
+	logs := plog.NewLogs()
+	lr := logs.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(l.now()))
+	attrs := lr.Attributes()
+	attrs.InsertString(entityEventTypeKey, entityStateEventType)
+	attrs.InsertString(entityTypeKey, monitorEntityType)
+
+	id := pcommon.NewValueMap()
+	id.MapVal().InsertString("receiver", l.receiverID)
+	attrs.Insert(entityIDKey, id)
+
+	attributes := pcommon.NewValueMap()
+	attributes.MapVal().InsertString("monitor_type", l.monitorType)
+	attributes.MapVal().InsertString("config_hash", l.configHash)
+	attributes.MapVal().InsertString("state", state)
+	if l.endpoint != "" {
+		attributes.MapVal().InsertString("endpoint", l.endpoint)
+	}
+	if reason != "" {
+		attributes.MapVal().InsertString("reason", reason)
+	}
+	if stateErr != nil {
+		attributes.MapVal().InsertString("error", stateErr.Error())
+	}
+	attributes.MapVal().Sort()
+	attrs.Insert(entityAttributesKey, attributes)
+	return logs
+}
@@ -0,0 +1,185 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.uber.org/zap"
+)
+
+// lifecycleStates returns the state and reason of each monitor lifecycle event received by the sink.
+func lifecycleStates(sink *consumertest.LogsSink) [][2]string {
+	var states [][2]string
+	for _, logs := range sink.AllLogs() {
+		records := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords()
+		for i := 0; i < records.Len(); i++ {
+			attrs := records.At(i).Attributes()
+			if entityType, ok := attrs.Get(entityTypeKey); !ok || entityType.StringVal() != monitorEntityType {
+				continue
+			}
+			attributes, _ := attrs.Get(entityAttributesKey)
+			state, _ := attributes.MapVal().Get("state")
+			reason, _ := attributes.MapVal().Get("reason")
+			states = append(states, [2]string{state.StringVal(), reason.StringVal()})
+		}
+	}
+	return states
+}
+
+func TestNewLifecycleEvents(t *testing.T) {
+	cfg := newConfig("valid", "cpu", 1)
+	assert.Nil(t, newLifecycleEvents(cfg, new(consumertest.LogsSink), zap.NewNop()))
+
+	cfg.LifecycleEvents = true
+	assert.Nil(t, newLifecycleEvents(cfg, nil, zap.NewNop()))
+
+	cfg.Endpoint = "localhost:6379"
+	events := newLifecycleEvents(cfg, new(consumertest.LogsSink), zap.NewNop())
+	require.NotNil(t, events)
+	assert.Equal(t, "smartagent/valid", events.receiverID)
+	assert.Equal(t, "cpu", events.monitorType)
+	assert.Equal(t, "localhost:6379", events.endpoint)
+	assert.Len(t, events.configHash, 16)
+	assert.Equal(t, events.configHash, monitorConfigHash(cfg.monitorConfig))
+
+	other := newConfig("valid", "cpu", 2)
+	assert.NotEqual(t, events.configHash, monitorConfigHash(other.monitorConfig))
+
+	// disabled events are a noop
+	var disabled *lifecycleEvents
+	disabled.emit(monitorStarted, "", nil)
+}
+
+type secretsConfig struct {
+	Nested   *secretsConfig           `yaml:"nested"`
+	Options  map[string]any           `yaml:"options"`
+	Host     string                   `yaml:"host"`
+	Password string                   `yaml:"password" neverLog:"true"`
+	Token    string                   `yaml:"token" neverLog:"omit"`
+	Children []secretsConfig          `yaml:"children"`
+	Named    map[string]secretsConfig `yaml:"named"`
+}
+
+func TestMonitorConfigHashWithoutSecrets(t *testing.T) {
+	config := func(host, secret string) *secretsConfig {
+		return &secretsConfig{
+			Host:     host,
+			Password: secret,
+			Token:    secret,
+			Nested:   &secretsConfig{Password: secret},
+			Options:  map[string]any{"child": secretsConfig{Password: secret}},
+			Children: []secretsConfig{{Host: host, Token: secret}},
+			Named:    map[string]secretsConfig{"a": {Password: secret}},
+		}
+	}
+	cfg := config("localhost", "secret")
+	hash := monitorConfigHash(cfg)
+	assert.Len(t, hash, 16)
+	assert.Equal(t, hash, monitorConfigHash(config("localhost", "other")))
+	assert.Equal(t, hash, monitorConfigHash(config("localhost", "")))
+	assert.NotEqual(t, hash, monitorConfigHash(config("remotehost", "secret")))
+	// the config itself keeps its secrets
+	assert.Equal(t, config("localhost", "secret"), cfg)
+}
+
+func TestLifecycleEventsToLogs(t *testing.T) {
+	now := time.Unix(1654041600, 0)
+	events := &lifecycleEvents{
+		now:         func() time.Time { return now },
+		receiverID:  "smartagent/redis",
+		monitorType: "collectd/redis",
+		endpoint:    "localhost:6379",
+		configHash:  "0123456789abcdef",
+	}
+
+	logs := events.toLogs(monitorFailed, "collection timeout", errors.New("no telemetry"))
+	require.Equal(t, 1, logs.LogRecordCount())
+	lr := logs.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, pcommon.NewTimestampFromTime(now), lr.Timestamp())
+	assert.Equal(t, map[string]any{
+		entityEventTypeKey: entityStateEventType,
+		entityTypeKey:      monitorEntityType,
+		entityIDKey:        map[string]any{"receiver": "smartagent/redis"},
+		entityAttributesKey: map[string]any{
+			"config_hash":  "0123456789abcdef",
+			"endpoint":     "localhost:6379",
+			"error":        "no telemetry",
+			"monitor_type": "collectd/redis",
+			"reason":       "collection timeout",
+			"state":        monitorFailed,
+		},
+	}, lr.Attributes().AsRaw())
+
+	events.endpoint = ""
+	lr = events.toLogs(monitorStarted, "", nil).ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	attributes, ok := lr.Attributes().Get(entityAttributesKey)
+	require.True(t, ok)
+	assert.Equal(t, map[string]any{
+		"config_hash":  "0123456789abcdef",
+		"monitor_type": "collectd/redis",
+		"state":        monitorStarted,
+	}, attributes.MapVal().AsRaw())
+}
+
+func TestReceiverLifecycleEvents(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("valid", "cpu", 1)
+	cfg.LifecycleEvents = true
+	cfg.CollectionTimeoutSeconds = 5
+	sink := new(consumertest.LogsSink)
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	receiver.registerLogsConsumer(sink)
+
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, [][2]string{{monitorStarted, ""}}, lifecycleStates(sink))
+
+	receiver.onCollectionTimeout(false)
+	// exhausted restarts only report the failure
+	receiver.onCollectionTimeout(true)
+	require.NoError(t, receiver.Shutdown(context.Background()))
+	assert.Equal(t, [][2]string{
+		{monitorStarted, ""},
+		{monitorFailed, "collection timeout"},
+		{monitorStopped, "collection timeout"},
+		{monitorStarted, "collection timeout"},
+		{monitorFailed, "collection timeout"},
+		{monitorStopped, "shutdown"},
+	}, lifecycleStates(sink))
+}
+
+func TestReceiverLifecycleEventsOnStartFailure(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("invalid", "notamonitortype", 1)
+	cfg.LifecycleEvents = true
+	sink := new(consumertest.LogsSink)
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	receiver.registerLogsConsumer(sink)
+
+	require.Error(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, [][2]string{{monitorFailed, ""}}, lifecycleStates(sink))
+
+	// shutting down the never started monitor releases its log redirection without reporting it stopped
+	require.Error(t, receiver.Shutdown(context.Background()))
+	assert.Equal(t, [][2]string{{monitorFailed, ""}}, lifecycleStates(sink))
+}
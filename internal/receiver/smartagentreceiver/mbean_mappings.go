@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const jmxMonitorType = "jmx"
+
+// mbeanMappingsScriptHelpers are the Groovy functions of the generated script returning the dimensions of an MBean
+// from its key properties, and the numeric value of an attribute, or of a key of a composite attribute, if any.
+const mbeanMappingsScriptHelpers = `def mbeanMappingDimensions(bean, keyProperties) {
+  def dims = [:]
+  keyProperties.each { dim, key ->
+    def value = bean.name().getKeyProperty(key)
+    if (value != null) {
+      dims[dim] = value
+    }
+  }
+  return dims
+}
+
+def mbeanMappingValue(bean, attribute, key) {
+  try {
+    def value = bean.getProperty(attribute)
+    if (value != null && key != null) {
+      value = value.get(key)
+    }
+    if (value instanceof Boolean) {
+      return value ? 1 : 0
+    }
+    return value instanceof Number ? value : null
+  } catch (Exception e) {
+    return null
+  }
+}
+`
+
+// MBeanMapping declares the datapoints of the MBeans matching an object name pattern, sent by the jmx monitor
+// without writing its Groovy script.
+type MBeanMapping struct {
+	// Dimension names to the key properties of the MBeans' object names providing their values, like
+	// `cache: name` for `com.example:type=Cache,name=users`.  MBeans without a key property omit its dimension.
+	Dimensions map[string]string `mapstructure:"dimensions" yaml:"dimensions"`
+	// An object name or pattern, like `com.example:type=Cache,*`.
+	ObjectName string        `mapstructure:"objectName" yaml:"objectName"`
+	Metrics    []MBeanMetric `mapstructure:"metrics" yaml:"metrics"`
+}
+
+// MBeanMetric describes a datapoint of each MBean matching its mapping.
+type MBeanMetric struct {
+	// The attribute providing the datapoint's value, or a key of a composite attribute, like `HeapMemoryUsage.used`.
+	// Numeric and boolean values are sent, and MBeans without the attribute are skipped.
+	Attribute    string `mapstructure:"attribute" yaml:"attribute"`
+	MetricName   string `mapstructure:"metricName" yaml:"metricName"`
+	IsCumulative bool   `mapstructure:"isCumulative" yaml:"isCumulative"`
+}
+
+func getMBeanMappingsFromAllSettings(allSettings map[string]any) ([]MBeanMapping, error) {
+	value, ok := allSettings["mbeanMappings"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "mbeanMappings")
+	if _, isSlice := value.([]any); !isSlice {
+		return nil, errMBeanMappingsValue
+	}
+	asBytes, err := yaml.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", errMBeanMappingsValue, err)
+	}
+	var mappings []MBeanMapping
+	if err = yaml.UnmarshalStrict(asBytes, &mappings); err != nil {
+		return nil, fmt.Errorf("%w: %v", errMBeanMappingsValue, err)
+	}
+	if err = validateMBeanMappings(mappings); err != nil {
+		return nil, err
+	}
+	return mappings, nil
+}
+
+func validateMBeanMappings(mappings []MBeanMapping) error {
+	for i, mapping := range mappings {
+		if domain, properties, ok := strings.Cut(mapping.ObjectName, ":"); !ok || domain == "" || properties == "" {
+			return fmt.Errorf("mbeanMappings[%d]: objectName must be an object name like domain:key=value,*", i)
+		}
+		for dimension, keyProperty := range mapping.Dimensions {
+			if dimension == "" || keyProperty == "" {
+				return fmt.Errorf("mbeanMappings[%d]: dimensions must map dimension names to key properties", i)
+			}
+		}
+		if len(mapping.Metrics) == 0 {
+			return fmt.Errorf("mbeanMappings[%d]: metrics must not be empty", i)
+		}
+		for j, metric := range mapping.Metrics {
+			if metric.MetricName == "" {
+				return fmt.Errorf("mbeanMappings[%d].metrics[%d]: metricName must not be empty", i, j)
+			}
+			attribute, key, composite := strings.Cut(metric.Attribute, ".")
+			if attribute == "" || (composite && (key == "" || strings.Contains(key, "."))) {
+				return fmt.Errorf("mbeanMappings[%d].metrics[%d]: attribute must be an attribute name or attribute.key", i, j)
+			}
+		}
+	}
+	return nil
+}
+
+// mbeanMappingsScript returns the jmx monitor Groovy script sending the datapoints of the mappings.
+func mbeanMappingsScript(mappings []MBeanMapping) string {
+	var script strings.Builder
+	script.WriteString("// generated from the smartagent receiver's mbeanMappings\n")
+	script.WriteString(mbeanMappingsScriptHelpers)
+	for _, mapping := range mappings {
+		dimensions := make([]string, 0, len(mapping.Dimensions))
+		for dimension := range mapping.Dimensions {
+			dimensions = append(dimensions, dimension)
+		}
+		sort.Strings(dimensions)
+		keyProperties := make([]string, len(dimensions))
+		for i, dimension := range dimensions {
+			keyProperties[i] = quoteGroovyString(dimension) + ": " + quoteGroovyString(mapping.Dimensions[dimension])
+		}
+		keyPropertiesMap := "[:]"
+		if len(keyProperties) != 0 {
+			keyPropertiesMap = "[" + strings.Join(keyProperties, ", ") + "]"
+		}
+
+		fmt.Fprintf(&script, "\nfor (bean in util.queryJMX(%s)) {\n", quoteGroovyString(mapping.ObjectName))
+		fmt.Fprintf(&script, "  def dims = mbeanMappingDimensions(bean, %s)\n", keyPropertiesMap)
+		script.WriteString("  def value\n")
+		for _, metric := range mapping.Metrics {
+			key := "null"
+			attribute, compositeKey, ok := strings.Cut(metric.Attribute, ".")
+			if ok {
+				key = quoteGroovyString(compositeKey)
+			}
+			datapoint := "makeGauge"
+			if metric.IsCumulative {
+				datapoint = "makeCumulative"
+			}
+			fmt.Fprintf(&script, "  value = mbeanMappingValue(bean, %s, %s)\n", quoteGroovyString(attribute), key)
+			script.WriteString("  if (value != null) {\n")
+			fmt.Fprintf(&script, "    output.sendDatapoint(util.%s(%s, value, dims))\n", datapoint, quoteGroovyString(metric.MetricName))
+			script.WriteString("  }\n")
+		}
+		script.WriteString("}\n")
+	}
+	return script.String()
+}
+
+// quoteGroovyString quotes the value as a single-quoted Groovy string, escaping backslashes and single quotes.
+func quoteGroovyString(value string) string {
+	return `'` + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + `'`
+}
+
+// setMBeanMappingsScript adds the script generated from the mbeanMappings to the jmx monitor's own groovyScript,
+// which can be empty.
+func (cfg *Config) setMBeanMappingsScript() error {
+	scriptField, err := GetSettableStructFieldValue(cfg.monitorConfig, "GroovyScript", reflect.TypeOf(""))
+	if err != nil || scriptField == nil {
+		return fmt.Errorf("monitor config of type %q has no groovyScript", cfg.monitorConfig.MonitorConfigCore().Type)
+	}
+	if cfg.monitorGroovyScript == nil {
+		// the monitor's own script, to which the generated one is added each time the config is validated
+		script := scriptField.String()
+		cfg.monitorGroovyScript = &script
+	}
+
+	script := mbeanMappingsScript(cfg.MBeanMappings)
+	if *cfg.monitorGroovyScript != "" {
+		script = strings.TrimRight(*cfg.monitorGroovyScript, "\n") + "\n\n" + script
+	}
+	scriptField.SetString(script)
+	return nil
+}
@@ -0,0 +1,144 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMBeanMappingsScript(t *testing.T) {
+	script := mbeanMappingsScript([]MBeanMapping{
+		{
+			ObjectName: "com.example:type=Cache,*",
+			Dimensions: map[string]string{"cache": "name", "app's": "app"},
+			Metrics: []MBeanMetric{
+				{Attribute: "HitCount", MetricName: "cache.hits", IsCumulative: true},
+				{Attribute: "Size", MetricName: "cache.size"},
+			},
+		},
+		{
+			ObjectName: "java.lang:type=Memory",
+			Metrics: []MBeanMetric{
+				{Attribute: "HeapMemoryUsage.used", MetricName: `jvm\heap.used`},
+			},
+		},
+	})
+
+	assert.Equal(t, "// generated from the smartagent receiver's mbeanMappings\n"+mbeanMappingsScriptHelpers+`
+for (bean in util.queryJMX('com.example:type=Cache,*')) {
+  def dims = mbeanMappingDimensions(bean, ['app\'s': 'app', 'cache': 'name'])
+  def value
+  value = mbeanMappingValue(bean, 'HitCount', null)
+  if (value != null) {
+    output.sendDatapoint(util.makeCumulative('cache.hits', value, dims))
+  }
+  value = mbeanMappingValue(bean, 'Size', null)
+  if (value != null) {
+    output.sendDatapoint(util.makeGauge('cache.size', value, dims))
+  }
+}
+
+for (bean in util.queryJMX('java.lang:type=Memory')) {
+  def dims = mbeanMappingDimensions(bean, [:])
+  def value
+  value = mbeanMappingValue(bean, 'HeapMemoryUsage', 'used')
+  if (value != null) {
+    output.sendDatapoint(util.makeGauge('jvm\\heap.used', value, dims))
+  }
+}
+`, script)
+}
+
+func TestGetMBeanMappingsFromAllSettings(t *testing.T) {
+	mappings, err := getMBeanMappingsFromAllSettings(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, mappings)
+
+	allSettings := map[string]any{
+		"mbeanMappings": []any{
+			map[string]any{
+				"objectName": "java.lang:type=GarbageCollector,*",
+				"dimensions": map[string]any{"gc": "name"},
+				"metrics": []any{
+					map[string]any{"attribute": "CollectionCount", "metricName": "jvm.gc.collections", "isCumulative": true},
+				},
+			},
+		},
+	}
+	mappings, err = getMBeanMappingsFromAllSettings(allSettings)
+	require.NoError(t, err)
+	assert.Equal(t, []MBeanMapping{
+		{
+			ObjectName: "java.lang:type=GarbageCollector,*",
+			Dimensions: map[string]string{"gc": "name"},
+			Metrics:    []MBeanMetric{{Attribute: "CollectionCount", MetricName: "jvm.gc.collections", IsCumulative: true}},
+		},
+	}, mappings)
+	assert.Empty(t, allSettings)
+}
+
+func TestInvalidMBeanMappings(t *testing.T) {
+	for _, tt := range []struct {
+		name          string
+		value         any
+		expectedError string
+	}{
+		{
+			name:          "not a list",
+			value:         map[string]any{"objectName": "java.lang:type=Memory"},
+			expectedError: "mbeanMappings must be a list of mappings with an objectName and metrics",
+		},
+		{
+			name:          "unknown field",
+			value:         []any{map[string]any{"objectName": "java.lang:type=Memory", "attributes": []any{"HeapMemoryUsage"}}},
+			expectedError: "mbeanMappings must be a list of mappings with an objectName and metrics: yaml: unmarshal errors",
+		},
+		{
+			name:          "invalid object name",
+			value:         []any{map[string]any{"objectName": "Memory"}},
+			expectedError: "mbeanMappings[0]: objectName must be an object name like domain:key=value,*",
+		},
+		{
+			name: "empty key property",
+			value: []any{map[string]any{
+				"objectName": "java.lang:type=Memory", "dimensions": map[string]any{"type": ""},
+			}},
+			expectedError: "mbeanMappings[0]: dimensions must map dimension names to key properties",
+		},
+		{
+			name:          "no metrics",
+			value:         []any{map[string]any{"objectName": "java.lang:type=Memory"}},
+			expectedError: "mbeanMappings[0]: metrics must not be empty",
+		},
+		{
+			name: "nested composite key",
+			value: []any{map[string]any{
+				"objectName": "java.lang:type=Memory",
+				"metrics":    []any{map[string]any{"attribute": "HeapMemoryUsage.used.max", "metricName": "jvm.heap"}},
+			}},
+			expectedError: "mbeanMappings[0].metrics[0]: attribute must be an attribute name or attribute.key",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mappings, err := getMBeanMappingsFromAllSettings(map[string]any{"mbeanMappings": tt.value})
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expectedError)
+			assert.Nil(t, mappings)
+		})
+	}
+}
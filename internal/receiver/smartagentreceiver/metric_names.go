@@ -0,0 +1,80 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"strings"
+
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
+)
+
+// newMetricNames returns the renames of the metricNames mapping, whose mapped names must be unique and not also be
+// renamed, so that the receiver's datapoints can be renamed both ways.
+func newMetricNames(metricNames map[string]string) (converter.Renames, error) {
+	if len(metricNames) == 0 {
+		return converter.Renames{}, nil
+	}
+	renames, err := converter.NewRenames(metricNames)
+	if err != nil {
+		return converter.Renames{}, errMetricNamesValue
+	}
+	return renames, nil
+}
+
+// applyMetricNamesToFilters translates the mapped metric names used by the monitor's extraMetrics and
+// datapointsToExclude options back to their Smart Agent names, which the monitor's metadata and filtering use.
+func applyMetricNamesToFilters(metricNames converter.Renames, allSettings map[string]any) {
+	if metricNames.Len() == 0 {
+		return
+	}
+
+	if extraMetrics, ok := allSettings["extraMetrics"].([]any); ok {
+		toSFxMetricNames(extraMetrics, metricNames)
+	}
+	if datapointsToExclude, ok := allSettings["datapointsToExclude"].([]any); ok {
+		for _, filter := range datapointsToExclude {
+			filterAsMap, isMap := filter.(map[string]any)
+			if !isMap {
+				continue
+			}
+			if metricName, isString := filterAsMap["metricName"].(string); isString {
+				filterAsMap["metricName"] = toSFxMetricName(metricName, metricNames)
+			}
+			if filterMetricNames, isSlice := filterAsMap["metricNames"].([]any); isSlice {
+				toSFxMetricNames(filterMetricNames, metricNames)
+			}
+		}
+	}
+}
+
+func toSFxMetricNames(names []any, metricNames converter.Renames) {
+	for i, name := range names {
+		if nameAsString, isString := name.(string); isString {
+			names[i] = toSFxMetricName(nameAsString, metricNames)
+		}
+	}
+}
+
+// toSFxMetricName returns the Smart Agent name of a mapped metric name, preserving any negation prefix of filters.
+func toSFxMetricName(name string, metricNames converter.Renames) string {
+	negated := strings.HasPrefix(name, "!")
+	if sfxName, ok := metricNames.Original(strings.TrimPrefix(name, "!")); ok {
+		if negated {
+			return "!" + sfxName
+		}
+		return sfxName
+	}
+	return name
+}
@@ -0,0 +1,67 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestApplyMetricNamesToFilters(t *testing.T) {
+	metricNames, err := newMetricNames(map[string]string{
+		"bytes.used_memory":   "redis.memory.used",
+		"gauge.uptime_in_sec": "redis.uptime",
+	})
+	require.NoError(t, err)
+	allSettings := map[string]any{
+		"extraMetrics": []any{"redis.memory.used", "!redis.uptime", "gauge.*"},
+		"datapointsToExclude": []any{
+			map[string]any{"metricName": "redis.uptime"},
+			map[string]any{"metricNames": []any{"!redis.memory.used", "unmapped"}},
+		},
+	}
+
+	applyMetricNamesToFilters(metricNames, allSettings)
+	assert.Equal(t, map[string]any{
+		"extraMetrics": []any{"bytes.used_memory", "!gauge.uptime_in_sec", "gauge.*"},
+		"datapointsToExclude": []any{
+			map[string]any{"metricName": "gauge.uptime_in_sec"},
+			map[string]any{"metricNames": []any{"!bytes.used_memory", "unmapped"}},
+		},
+	}, allSettings)
+}
+
+func TestNewMetricNames(t *testing.T) {
+	metricNames, err := newMetricNames(nil)
+	require.NoError(t, err)
+	assert.Zero(t, metricNames.Len())
+
+	metricNames, err = newMetricNames(map[string]string{"a.b": "c.d", "e.f": "g.h"})
+	require.NoError(t, err)
+	name, ok := metricNames.Original("g.h")
+	assert.True(t, ok)
+	assert.Equal(t, "e.f", name)
+
+	for _, invalid := range []map[string]string{
+		{"a.b": ""},
+		{"a.b": "c.d", "e.f": "c.d"},
+		{"a.b": "c.d", "c.d": "e.f"},
+	} {
+		_, err = newMetricNames(invalid)
+		assert.Equal(t, errMetricNamesValue, err)
+	}
+}
@@ -0,0 +1,95 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvidiadcgm
+
+import (
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+)
+
+const monitorType = "nvidia-dcgm"
+
+const (
+	dcgmFiDevDecUtil                = "DCGM_FI_DEV_DEC_UTIL"
+	dcgmFiDevEncUtil                = "DCGM_FI_DEV_ENC_UTIL"
+	dcgmFiDevFbFree                 = "DCGM_FI_DEV_FB_FREE"
+	dcgmFiDevFbUsed                 = "DCGM_FI_DEV_FB_USED"
+	dcgmFiDevGpuTemp                = "DCGM_FI_DEV_GPU_TEMP"
+	dcgmFiDevGpuUtil                = "DCGM_FI_DEV_GPU_UTIL"
+	dcgmFiDevMemClock               = "DCGM_FI_DEV_MEM_CLOCK"
+	dcgmFiDevMemCopyUtil            = "DCGM_FI_DEV_MEM_COPY_UTIL"
+	dcgmFiDevMemoryTemp             = "DCGM_FI_DEV_MEMORY_TEMP"
+	dcgmFiDevNvlinkBandwidthTotal   = "DCGM_FI_DEV_NVLINK_BANDWIDTH_TOTAL"
+	dcgmFiDevPcieReplayCounter      = "DCGM_FI_DEV_PCIE_REPLAY_COUNTER"
+	dcgmFiDevPowerUsage             = "DCGM_FI_DEV_POWER_USAGE"
+	dcgmFiDevSmClock                = "DCGM_FI_DEV_SM_CLOCK"
+	dcgmFiDevTotalEnergyConsumption = "DCGM_FI_DEV_TOTAL_ENERGY_CONSUMPTION"
+	dcgmFiDevXidErrors              = "DCGM_FI_DEV_XID_ERRORS"
+	dcgmFiProfDramActive            = "DCGM_FI_PROF_DRAM_ACTIVE"
+	dcgmFiProfGrEngineActive        = "DCGM_FI_PROF_GR_ENGINE_ACTIVE"
+	dcgmFiProfPcieRxBytes           = "DCGM_FI_PROF_PCIE_RX_BYTES"
+	dcgmFiProfPcieTxBytes           = "DCGM_FI_PROF_PCIE_TX_BYTES"
+	dcgmFiProfPipeTensorActive      = "DCGM_FI_PROF_PIPE_TENSOR_ACTIVE"
+	dcgmFiProfSmActive              = "DCGM_FI_PROF_SM_ACTIVE"
+	dcgmFiProfSmOccupancy           = "DCGM_FI_PROF_SM_OCCUPANCY"
+)
+
+// metricSet are the fields of dcgm-exporter's default counters, of which the profiling ones are
+// the utilization metrics reported per MIG instance.
+var metricSet = map[string]monitors.MetricInfo{
+	dcgmFiDevDecUtil:                {Type: datapoint.Gauge},
+	dcgmFiDevEncUtil:                {Type: datapoint.Gauge},
+	dcgmFiDevFbFree:                 {Type: datapoint.Gauge},
+	dcgmFiDevFbUsed:                 {Type: datapoint.Gauge},
+	dcgmFiDevGpuTemp:                {Type: datapoint.Gauge},
+	dcgmFiDevGpuUtil:                {Type: datapoint.Gauge},
+	dcgmFiDevMemClock:               {Type: datapoint.Gauge},
+	dcgmFiDevMemCopyUtil:            {Type: datapoint.Gauge},
+	dcgmFiDevMemoryTemp:             {Type: datapoint.Gauge},
+	dcgmFiDevNvlinkBandwidthTotal:   {Type: datapoint.Counter},
+	dcgmFiDevPcieReplayCounter:      {Type: datapoint.Counter},
+	dcgmFiDevPowerUsage:             {Type: datapoint.Gauge},
+	dcgmFiDevSmClock:                {Type: datapoint.Gauge},
+	dcgmFiDevTotalEnergyConsumption: {Type: datapoint.Counter},
+	dcgmFiDevXidErrors:              {Type: datapoint.Gauge},
+	dcgmFiProfDramActive:            {Type: datapoint.Gauge},
+	dcgmFiProfGrEngineActive:        {Type: datapoint.Gauge},
+	dcgmFiProfPcieRxBytes:           {Type: datapoint.Gauge},
+	dcgmFiProfPcieTxBytes:           {Type: datapoint.Gauge},
+	dcgmFiProfPipeTensorActive:      {Type: datapoint.Gauge},
+	dcgmFiProfSmActive:              {Type: datapoint.Gauge},
+	dcgmFiProfSmOccupancy:           {Type: datapoint.Gauge},
+}
+
+var defaultMetrics = map[string]bool{
+	dcgmFiDevFbFree:          true,
+	dcgmFiDevFbUsed:          true,
+	dcgmFiDevGpuTemp:         true,
+	dcgmFiDevGpuUtil:         true,
+	dcgmFiDevMemCopyUtil:     true,
+	dcgmFiDevPowerUsage:      true,
+	dcgmFiDevXidErrors:       true,
+	dcgmFiProfGrEngineActive: true,
+}
+
+var monitorMetadata = monitors.Metadata{
+	MonitorType:     monitorType,
+	DefaultMetrics:  defaultMetrics,
+	Metrics:         metricSet,
+	SendUnknown:     false,
+	Groups:          map[string]bool{},
+	GroupMetricsMap: map[string][]string{},
+	SendAll:         false,
+}
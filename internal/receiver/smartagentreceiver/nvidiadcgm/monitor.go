@@ -0,0 +1,48 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package nvidiadcgm provides the nvidia-dcgm Smart Agent monitor, scraping the NVIDIA DCGM metrics of
+// dcgm-exporter with MIG instance dimensions and reporting XID errors as events.
+package nvidiadcgm
+
+import (
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/prometheusexporter"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+)
+
+func init() {
+	monitors.Register(&monitorMetadata, func() any { return &Monitor{} }, &Config{})
+}
+
+// Config is the configuration of the nvidia-dcgm monitor, whose host and port are those of the
+// dcgm-exporter's metrics endpoint, 9400 by default.
+type Config struct {
+	prometheusexporter.Config `yaml:",inline"`
+	// Whether to not report changes of the last XID error of GPUs as nvidia.gpu.xid_error events.
+	DisableXIDEvents bool `yaml:"disableXIDEvents"`
+}
+
+// Monitor scrapes dcgm-exporter with the prometheus-exporter monitor, whose datapoints its output
+// renames the dimensions of and derives XID error events from.
+type Monitor struct {
+	Output types.FilteringOutput
+	prometheusexporter.Monitor
+}
+
+// Configure starts scraping dcgm-exporter.
+func (m *Monitor) Configure(conf *Config) error {
+	m.Monitor.Output = newOutput(m.Output, !conf.DisableXIDEvents)
+	return m.Monitor.Configure(&conf.Config)
+}
@@ -0,0 +1,35 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvidiadcgm
+
+import (
+	"testing"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorRegistered(t *testing.T) {
+	require.Contains(t, monitors.ConfigTemplates, monitorType)
+	assert.IsType(t, &Config{}, monitors.ConfigTemplates[monitorType])
+	assert.IsType(t, &Monitor{}, monitors.MonitorFactories[monitorType]())
+	require.Contains(t, monitors.MonitorMetadatas, monitorType)
+	assert.Equal(t, monitorType, monitors.MonitorMetadatas[monitorType].MonitorType)
+
+	for metric := range defaultMetrics {
+		assert.Contains(t, metricSet, metric)
+	}
+}
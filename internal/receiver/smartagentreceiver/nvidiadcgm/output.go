@@ -0,0 +1,167 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvidiadcgm
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+)
+
+const xidErrorEventType = "nvidia.gpu.xid_error"
+
+// dimensionNames maps the labels dcgm-exporter sets on the fields of GPUs and MIG instances to the
+// dimensions of the monitor's datapoints and events.
+var dimensionNames = map[string]string{
+	"UUID":                   "gpu_uuid",
+	"modelName":              "gpu_model",
+	"device":                 "gpu_device",
+	"GPU_I_ID":               "gpu_instance_id",
+	"GPU_I_PROFILE":          "gpu_instance_profile",
+	"DCGM_FI_DRIVER_VERSION": "gpu_driver_version",
+}
+
+// xidLabels are the labels recent dcgm-exporter versions set on the XID errors field with the code and
+// description of the last XID error, which don't identify the GPU.
+var xidLabels = map[string]bool{
+	"err_code": true,
+	"err_msg":  true,
+}
+
+// xidDescriptions are the descriptions of the XID errors most relevant to GPU health, per
+// https://docs.nvidia.com/deploy/xid-errors/index.html.
+var xidDescriptions = map[int64]string{
+	13: "Graphics Engine Exception",
+	31: "GPU memory page fault",
+	43: "GPU stopped processing",
+	45: "Preemptive cleanup, due to previous errors",
+	48: "Double Bit ECC Error",
+	61: "Internal micro-controller breakpoint/warning",
+	62: "Internal micro-controller halt",
+	63: "ECC page retirement or row remapping recording event",
+	64: "ECC page retirement or row remapper recording failure",
+	74: "NVLINK Error",
+	79: "GPU has fallen off the bus",
+	92: "High single-bit ECC error rate",
+	94: "Contained ECC error",
+	95: "Uncontained ECC error",
+}
+
+// output renames the dimensions of the datapoints of dcgm-exporter and reports changes of the last
+// XID error of each GPU or MIG instance as events.
+type output struct {
+	types.FilteringOutput
+	// lastXIDs are the last XID errors of the GPUs and MIG instances, keyed by their dimensions.
+	lastXIDs  map[string]int64
+	lock      sync.Mutex
+	xidEvents bool
+}
+
+func newOutput(next types.FilteringOutput, xidEvents bool) *output {
+	return &output{
+		FilteringOutput: next,
+		lastXIDs:        map[string]int64{},
+		xidEvents:       xidEvents,
+	}
+}
+
+func (o *output) SendDatapoints(dps ...*datapoint.Datapoint) {
+	for _, dp := range dps {
+		renameDimensions(dp.Dimensions)
+		if o.xidEvents && dp.Metric == dcgmFiDevXidErrors {
+			if ev := o.xidEvent(dp); ev != nil {
+				o.FilteringOutput.SendEvent(ev)
+			}
+		}
+	}
+	o.FilteringOutput.SendDatapoints(dps...)
+}
+
+func renameDimensions(dimensions map[string]string) {
+	for label, dimension := range dimensionNames {
+		if value, ok := dimensions[label]; ok {
+			delete(dimensions, label)
+			dimensions[dimension] = value
+		}
+	}
+}
+
+// xidEvent returns the event of a change of the last XID error reported by the datapoint, if any.
+// DCGM only reports the last XID error of a GPU, so the first one reported after the monitor starts
+// isn't known to be new and only sets the baseline of later changes.
+func (o *output) xidEvent(dp *datapoint.Datapoint) *event.Event {
+	var xid int64
+	switch value := dp.Value.(type) {
+	case datapoint.IntValue:
+		xid = value.Int()
+	case datapoint.FloatValue:
+		xid = int64(value.Float())
+	default:
+		return nil
+	}
+
+	dimensions := make(map[string]string, len(dp.Dimensions))
+	for k, v := range dp.Dimensions {
+		if !xidLabels[k] {
+			dimensions[k] = v
+		}
+	}
+	key := dimensionsKey(dimensions)
+
+	o.lock.Lock()
+	last, seen := o.lastXIDs[key]
+	o.lastXIDs[key] = xid
+	o.lock.Unlock()
+	if !seen || xid == last || xid == 0 {
+		return nil
+	}
+
+	properties := map[string]any{"xid": xid}
+	if description, ok := xidDescriptions[xid]; ok {
+		properties["description"] = description
+	} else if description = dp.Dimensions["err_msg"]; description != "" {
+		properties["description"] = description
+	}
+	timestamp := dp.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+	return &event.Event{
+		EventType:  xidErrorEventType,
+		Category:   event.AGENT,
+		Dimensions: dimensions,
+		Properties: properties,
+		Timestamp:  timestamp,
+	}
+}
+
+func dimensionsKey(dimensions map[string]string) string {
+	keys := make([]string, 0, len(dimensions))
+	for k := range dimensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var key strings.Builder
+	for _, k := range keys {
+		fmt.Fprintf(&key, "%s=%s;", k, dimensions[k])
+	}
+	return key.String()
+}
@@ -0,0 +1,137 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package nvidiadcgm
+
+import (
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testOutput struct {
+	types.FilteringOutput
+	datapoints []*datapoint.Datapoint
+	events     []*event.Event
+}
+
+func (o *testOutput) SendDatapoints(dps ...*datapoint.Datapoint) {
+	o.datapoints = append(o.datapoints, dps...)
+}
+
+func (o *testOutput) SendEvent(ev *event.Event) {
+	o.events = append(o.events, ev)
+}
+
+func xidDatapoint(xid float64, labels map[string]string) *datapoint.Datapoint {
+	dimensions := map[string]string{"gpu": "0", "UUID": "GPU-5fd4", "modelName": "NVIDIA A100-SXM4-40GB"}
+	for k, v := range labels {
+		dimensions[k] = v
+	}
+	return datapoint.New(dcgmFiDevXidErrors, dimensions, datapoint.NewFloatValue(xid), datapoint.Gauge, time.Unix(1000, 0))
+}
+
+func TestOutputRenamesDimensions(t *testing.T) {
+	next := &testOutput{}
+	out := newOutput(next, true)
+
+	out.SendDatapoints(datapoint.New(dcgmFiProfGrEngineActive, map[string]string{
+		"gpu":                    "0",
+		"UUID":                   "GPU-5fd4",
+		"device":                 "nvidia0",
+		"modelName":              "NVIDIA A100-SXM4-40GB",
+		"GPU_I_ID":               "3",
+		"GPU_I_PROFILE":          "1g.5gb",
+		"DCGM_FI_DRIVER_VERSION": "515.48.07",
+		"Hostname":               "gpu-node-1",
+	}, datapoint.NewFloatValue(0.42), datapoint.Gauge, time.Unix(1000, 0)))
+
+	require.Len(t, next.datapoints, 1)
+	assert.Equal(t, map[string]string{
+		"gpu":                  "0",
+		"gpu_uuid":             "GPU-5fd4",
+		"gpu_device":           "nvidia0",
+		"gpu_model":            "NVIDIA A100-SXM4-40GB",
+		"gpu_instance_id":      "3",
+		"gpu_instance_profile": "1g.5gb",
+		"gpu_driver_version":   "515.48.07",
+		"Hostname":             "gpu-node-1",
+	}, next.datapoints[0].Dimensions)
+	assert.Empty(t, next.events)
+}
+
+func TestOutputXIDEvents(t *testing.T) {
+	next := &testOutput{}
+	out := newOutput(next, true)
+
+	// The first XID error of a GPU is the baseline.
+	out.SendDatapoints(xidDatapoint(13, nil))
+	out.SendDatapoints(xidDatapoint(13, nil))
+	assert.Empty(t, next.events)
+
+	out.SendDatapoints(xidDatapoint(79, nil))
+	require.Len(t, next.events, 1)
+	assert.Equal(t, &event.Event{
+		EventType: xidErrorEventType,
+		Category:  event.AGENT,
+		Dimensions: map[string]string{
+			"gpu":       "0",
+			"gpu_uuid":  "GPU-5fd4",
+			"gpu_model": "NVIDIA A100-SXM4-40GB",
+		},
+		Properties: map[string]any{"xid": int64(79), "description": "GPU has fallen off the bus"},
+		Timestamp:  time.Unix(1000, 0),
+	}, next.events[0])
+	assert.Len(t, next.datapoints, 3)
+
+	// Cleared errors aren't reported.
+	out.SendDatapoints(xidDatapoint(0, nil))
+	assert.Len(t, next.events, 1)
+
+	// The labels of the last error don't identify the GPU, and describe unknown errors.
+	out.SendDatapoints(xidDatapoint(119, map[string]string{"err_code": "119", "err_msg": "GSP RPC timeout"}))
+	require.Len(t, next.events, 2)
+	assert.Equal(t, map[string]any{"xid": int64(119), "description": "GSP RPC timeout"}, next.events[1].Properties)
+	assert.NotContains(t, next.events[1].Dimensions, "err_code")
+	assert.Equal(t, "119", next.datapoints[4].Dimensions["err_code"])
+}
+
+func TestOutputXIDEventsOfMIGInstances(t *testing.T) {
+	next := &testOutput{}
+	out := newOutput(next, true)
+
+	instance := map[string]string{"GPU_I_ID": "1", "GPU_I_PROFILE": "3g.20gb"}
+	out.SendDatapoints(xidDatapoint(0, nil), xidDatapoint(0, instance))
+	out.SendDatapoints(xidDatapoint(0, nil), xidDatapoint(43, map[string]string{"GPU_I_ID": "1", "GPU_I_PROFILE": "3g.20gb"}))
+
+	require.Len(t, next.events, 1)
+	assert.Equal(t, "1", next.events[0].Dimensions["gpu_instance_id"])
+	assert.Equal(t, "3g.20gb", next.events[0].Dimensions["gpu_instance_profile"])
+	assert.Equal(t, "GPU stopped processing", next.events[0].Properties["description"])
+}
+
+func TestOutputXIDEventsDisabled(t *testing.T) {
+	next := &testOutput{}
+	out := newOutput(next, false)
+
+	out.SendDatapoints(xidDatapoint(0, nil))
+	out.SendDatapoints(xidDatapoint(79, nil))
+	assert.Empty(t, next.events)
+	assert.Len(t, next.datapoints, 2)
+}
@@ -0,0 +1,152 @@
+// Copyright OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	durationType        = reflect.TypeOf(time.Duration(0))
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// monitorConfigFields returns the fields of the provided monitor config type, including those of its inlined
+// structs, keyed by their yaml option names.  The field indexes are those of the monitor config type.
+func monitorConfigFields(monitorConfigType reflect.Type) map[string]reflect.StructField {
+	fields := map[string]reflect.StructField{}
+	for monitorConfigType.Kind() == reflect.Pointer {
+		monitorConfigType = monitorConfigType.Elem()
+	}
+	if monitorConfigType.Kind() != reflect.Struct {
+		return fields
+	}
+	for i := 0; i < monitorConfigType.NumField(); i++ {
+		field := monitorConfigType.Field(i)
+		tag := field.Tag.Get("yaml")
+		if tag == "-" {
+			continue
+		}
+		name, flags, _ := strings.Cut(tag, ",")
+		if strings.Contains(flags, "inline") {
+			for option, inlined := range monitorConfigFields(field.Type) {
+				inlined.Index = append([]int{i}, inlined.Index...)
+				fields[option] = inlined
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = strings.ToLower(field.Name)
+		}
+		fields[name] = field
+	}
+	return fields
+}
+
+// monitorConfigOptions returns the yaml option names of the provided monitor config type, including those of
+// its inlined structs.
+func monitorConfigOptions(monitorConfigType reflect.Type) map[string]bool {
+	options := map[string]bool{}
+	for option := range monitorConfigFields(monitorConfigType) {
+		options[option] = true
+	}
+	return options
+}
+
+// scalarOptionValue converts the string value to the type of the option's field when it's a boolean or number,
+// so it can be unmarshalled to it.  Values of other fields, like strings and durations, are left as is.
+func scalarOptionValue(field reflect.StructField, value string) (any, error) {
+	t := field.Type
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == durationType || reflect.PointerTo(t).Implements(yamlUnmarshalerType) {
+		// unmarshalled by the type itself, like timeutil.Duration, or from duration strings
+		return value, nil
+	}
+
+	var converted any
+	var err error
+	switch t.Kind() {
+	case reflect.Bool:
+		converted, err = strconv.ParseBool(value)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		converted, err = strconv.ParseInt(value, 10, t.Bits())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		converted, err = strconv.ParseUint(value, 10, t.Bits())
+	case reflect.Float32, reflect.Float64:
+		converted, err = strconv.ParseFloat(value, t.Bits())
+	default:
+		return value, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%q isn't a valid %s", value, t.Kind())
+	}
+	return converted, nil
+}
+
+// optionField returns the named field of the monitor config struct value, including those promoted from its inlined
+// structs, or the zero Value when there's no such field.
+func optionField(options reflect.Value, name string) reflect.Value {
+	if options.Kind() != reflect.Struct {
+		return reflect.Value{}
+	}
+	return options.FieldByName(name)
+}
+
+// stringOption returns the value of the named string option, or "" when there's no such option.
+func stringOption(options reflect.Value, name string) string {
+	if field := optionField(options, name); field.Kind() == reflect.String {
+		return field.String()
+	}
+	return ""
+}
+
+// boolOption returns the value of the named boolean option, or false when there's no such option.
+func boolOption(options reflect.Value, name string) bool {
+	if field := optionField(options, name); field.Kind() == reflect.Bool {
+		return field.Bool()
+	}
+	return false
+}
+
+// intOption returns the value of the named signed integer option, like durations, or 0 when there's no
+// such option.
+func intOption(options reflect.Value, name string) int64 {
+	switch field := optionField(options, name); field.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return field.Int()
+	}
+	return 0
+}
+
+// uintOption returns the value of the named unsigned integer option, like ports, or 0 when there's no
+// such option.
+func uintOption(options reflect.Value, name string) uint64 {
+	switch field := optionField(options, name); field.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return field.Uint()
+	}
+	return 0
+}
@@ -0,0 +1,38 @@
+// Copyright OpenTelemetry Authors
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorConfigFields(t *testing.T) {
+	fields := monitorConfigFields(reflect.TypeOf(&testMappedConfig{}))
+	require.Len(t, fields, 6)
+	assert.Equal(t, "UseTLS", fields["useTLS"].Name)
+	assert.Equal(t, []int{0, 0}, fields["useTLS"].Index)
+	assert.Equal(t, "Port", fields["port"].Name)
+	assert.Equal(t, []int{2}, fields["port"].Index)
+
+	value := reflect.ValueOf(testMappedConfig{TestMappedInlined: TestMappedInlined{UseTLS: true}, Port: 80})
+	assert.True(t, value.FieldByIndex(fields["useTLS"].Index).Bool())
+	assert.EqualValues(t, 80, value.FieldByIndex(fields["port"].Index).Uint())
+
+	assert.Empty(t, monitorConfigFields(reflect.TypeOf("")))
+}
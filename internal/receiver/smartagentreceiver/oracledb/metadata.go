@@ -0,0 +1,100 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracledb
+
+import (
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+)
+
+const monitorType = "oracledb"
+
+const (
+	groupSysstat     = "sysstat"
+	groupSessions    = "sessions"
+	groupLimits      = "limits"
+	groupTablespaces = "tablespaces"
+)
+
+const (
+	oracledbCPUTime             = "oracledb.cpu_time"
+	oracledbExecutions          = "oracledb.executions"
+	oracledbHardParses          = "oracledb.hard_parses"
+	oracledbLogicalReads        = "oracledb.logical_reads"
+	oracledbParseCalls          = "oracledb.parse_calls"
+	oracledbPhysicalReads       = "oracledb.physical_reads"
+	oracledbPhysicalWrites      = "oracledb.physical_writes"
+	oracledbProcessesLimit      = "oracledb.processes.limit"
+	oracledbProcessesUsage      = "oracledb.processes.usage"
+	oracledbSessionsLimit       = "oracledb.sessions.limit"
+	oracledbSessionsUsage       = "oracledb.sessions.usage"
+	oracledbTablespaceSizeLimit = "oracledb.tablespace_size.limit"
+	oracledbTablespaceSizeUsage = "oracledb.tablespace_size.usage"
+	oracledbUserCommits         = "oracledb.user_commits"
+	oracledbUserRollbacks       = "oracledb.user_rollbacks"
+)
+
+var metricSet = map[string]monitors.MetricInfo{
+	oracledbCPUTime:             {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbExecutions:          {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbHardParses:          {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbLogicalReads:        {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbParseCalls:          {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbPhysicalReads:       {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbPhysicalWrites:      {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbProcessesLimit:      {Type: datapoint.Gauge, Group: groupLimits},
+	oracledbProcessesUsage:      {Type: datapoint.Gauge, Group: groupLimits},
+	oracledbSessionsLimit:       {Type: datapoint.Gauge, Group: groupLimits},
+	oracledbSessionsUsage:       {Type: datapoint.Gauge, Group: groupSessions},
+	oracledbTablespaceSizeLimit: {Type: datapoint.Gauge, Group: groupTablespaces},
+	oracledbTablespaceSizeUsage: {Type: datapoint.Gauge, Group: groupTablespaces},
+	oracledbUserCommits:         {Type: datapoint.Counter, Group: groupSysstat},
+	oracledbUserRollbacks:       {Type: datapoint.Counter, Group: groupSysstat},
+}
+
+var defaultMetrics = map[string]bool{
+	oracledbCPUTime:             true,
+	oracledbExecutions:          true,
+	oracledbPhysicalReads:       true,
+	oracledbPhysicalWrites:      true,
+	oracledbProcessesLimit:      true,
+	oracledbProcessesUsage:      true,
+	oracledbSessionsLimit:       true,
+	oracledbSessionsUsage:       true,
+	oracledbTablespaceSizeLimit: true,
+	oracledbTablespaceSizeUsage: true,
+	oracledbUserCommits:         true,
+	oracledbUserRollbacks:       true,
+}
+
+var groupMetricsMap = map[string][]string{
+	groupSysstat: {
+		oracledbCPUTime, oracledbExecutions, oracledbHardParses, oracledbLogicalReads, oracledbParseCalls,
+		oracledbPhysicalReads, oracledbPhysicalWrites, oracledbUserCommits, oracledbUserRollbacks,
+	},
+	groupSessions:    {oracledbSessionsUsage},
+	groupLimits:      {oracledbProcessesLimit, oracledbProcessesUsage, oracledbSessionsLimit},
+	groupTablespaces: {oracledbTablespaceSizeLimit, oracledbTablespaceSizeUsage},
+}
+
+var monitorMetadata = monitors.Metadata{
+	MonitorType:     monitorType,
+	DefaultMetrics:  defaultMetrics,
+	Metrics:         metricSet,
+	SendUnknown:     false,
+	Groups:          map[string]bool{groupSysstat: true, groupSessions: true, groupLimits: true, groupTablespaces: true},
+	GroupMetricsMap: groupMetricsMap,
+	SendAll:         false,
+}
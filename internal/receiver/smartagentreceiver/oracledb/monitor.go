@@ -0,0 +1,153 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package oracledb provides the oracledb Smart Agent monitor, collecting the activity, session, resource limit,
+// and tablespace metrics of Oracle Database servers over TCP or TCPS, with optional Oracle wallet authentication.
+package oracledb
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"github.com/signalfx/signalfx-agent/pkg/utils"
+	goora "github.com/sijms/go-ora/v2" // also registers the oracle database/sql driver
+	"github.com/sirupsen/logrus"
+)
+
+// DriverName is the database/sql driver name of Oracle Database servers.
+const DriverName = "oracle"
+
+func init() {
+	monitors.Register(&monitorMetadata, func() any { return &Monitor{} }, &Config{})
+}
+
+// Config is the configuration of the oracledb monitor.
+type Config struct {
+	config.MonitorConfig `yaml:",inline" acceptsEndpoints:"true"`
+	// Additional go-ora connection options, like `AUTH TYPE: TCPS` to authenticate with the wallet's certificate.
+	ConnectionOptions map[string]string `yaml:"connectionOptions"`
+	Host              string            `yaml:"host" validate:"required"`
+	// The service name of the database, like ORCLPDB1.
+	ServiceName string `yaml:"serviceName" validate:"required"`
+	// The username, optional when the wallet stores the credentials of the service.
+	Username string `yaml:"username"`
+	Password string `yaml:"password" neverLog:"true"`
+	// The directory of the Oracle wallet, whose cwallet.sso file provides the server's certificate authorities
+	// and client certificate for TCPS, and the credentials of its secure external password store.
+	WalletPath string `yaml:"walletPath"`
+	// The query timeout, defaulting to the monitor's intervalSeconds.
+	TimeoutSeconds int    `yaml:"timeoutSeconds"`
+	Port           uint16 `yaml:"port" default:"1521"`
+	// Whether to connect with TCPS.
+	TLS bool `yaml:"tls"`
+	// Whether to skip verifying the server's certificate with TCPS.
+	SkipVerify bool `yaml:"skipVerify"`
+}
+
+// Validate checks that the monitor has credentials.
+func (c *Config) Validate() error {
+	if c.Username == "" && c.WalletPath == "" {
+		return errors.New("username is required unless the credentials are stored in the wallet at walletPath")
+	}
+	if c.TimeoutSeconds < 0 {
+		return errors.New("timeoutSeconds must be non-negative")
+	}
+	return nil
+}
+
+// DataSourceName returns the go-ora connection URL of the monitor's database.
+func DataSourceName(conf *Config) string {
+	options := map[string]string{}
+	if conf.TLS {
+		options["SSL"] = "enable"
+		if conf.SkipVerify {
+			options["SSL VERIFY"] = "false"
+		}
+	}
+	if conf.WalletPath != "" {
+		options["WALLET"] = conf.WalletPath
+	}
+	for option, value := range conf.ConnectionOptions {
+		options[option] = value
+	}
+	return goora.BuildUrl(conf.Host, int(conf.Port), conf.ServiceName, conf.Username, conf.Password, options)
+}
+
+// Monitor collects the metrics of an Oracle Database server every interval.
+type Monitor struct {
+	Output types.FilteringOutput
+	cancel context.CancelFunc
+	db     *sql.DB
+	logger logrus.FieldLogger
+}
+
+// Configure connects to the database and starts collecting its metrics.
+func (m *Monitor) Configure(conf *Config) error {
+	m.logger = logrus.WithFields(logrus.Fields{"monitorType": monitorType, "monitorID": conf.MonitorID})
+	db, err := sql.Open(DriverName, DataSourceName(conf))
+	if err != nil {
+		return err
+	}
+	m.db = db
+
+	interval := time.Duration(conf.IntervalSeconds) * time.Second
+	timeout := time.Duration(conf.TimeoutSeconds) * time.Second
+	if timeout == 0 {
+		timeout = interval
+	}
+	queries := m.enabledQueries()
+
+	var ctx context.Context
+	ctx, m.cancel = context.WithCancel(context.Background())
+	utils.RunOnInterval(ctx, func() {
+		queryCtx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		datapoints, err := collect(queryCtx, m.db, queries)
+		if err != nil && ctx.Err() == nil {
+			m.logger.WithError(err).Error("Failed collecting Oracle Database metrics")
+		}
+		if len(datapoints) != 0 {
+			m.Output.SendDatapoints(datapoints...)
+		}
+	}, interval)
+	return nil
+}
+
+// enabledQueries returns the queries of the metric groups with an enabled metric.
+func (m *Monitor) enabledQueries() []metricQuery {
+	var queries []metricQuery
+	for _, query := range metricQueries {
+		if m.Output.HasEnabledMetricInGroup(query.group) {
+			queries = append(queries, query)
+		}
+	}
+	return queries
+}
+
+// Shutdown stops collecting metrics and closes the database connections.
+func (m *Monitor) Shutdown() {
+	if m.cancel != nil {
+		m.cancel()
+	}
+	if m.db != nil {
+		if err := m.db.Close(); err != nil {
+			m.logger.WithError(err).Warn("Failed closing Oracle Database connections")
+		}
+	}
+}
@@ -0,0 +1,88 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracledb
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorRegistered(t *testing.T) {
+	require.Contains(t, monitors.ConfigTemplates, monitorType)
+	assert.IsType(t, &Config{}, monitors.ConfigTemplates[monitorType])
+	assert.IsType(t, &Monitor{}, monitors.MonitorFactories[monitorType]())
+	require.Contains(t, monitors.MonitorMetadatas, monitorType)
+	assert.Equal(t, monitorType, monitors.MonitorMetadatas[monitorType].MonitorType)
+
+	for metric := range defaultMetrics {
+		assert.Contains(t, metricSet, metric)
+	}
+	for group, metrics := range groupMetricsMap {
+		for _, metric := range metrics {
+			assert.Equal(t, group, metricSet[metric].Group, metric)
+		}
+	}
+	for _, query := range metricQueries {
+		assert.Contains(t, groupMetricsMap, query.group)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	conf := &Config{Host: "localhost", Port: 1521, ServiceName: "ORCLPDB1"}
+	assert.EqualError(t, conf.Validate(), "username is required unless the credentials are stored in the wallet at walletPath")
+
+	conf.WalletPath = "/etc/otel/wallet"
+	assert.NoError(t, conf.Validate())
+
+	conf.WalletPath = ""
+	conf.Username = "monitor"
+	assert.NoError(t, conf.Validate())
+
+	conf.TimeoutSeconds = -1
+	assert.EqualError(t, conf.Validate(), "timeoutSeconds must be non-negative")
+}
+
+func TestDataSourceName(t *testing.T) {
+	dsn, err := url.Parse(DataSourceName(&Config{
+		Host: "db.example.com", Port: 1521, ServiceName: "ORCLPDB1", Username: "monitor", Password: "s3cr3t word",
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "oracle", dsn.Scheme)
+	assert.Equal(t, "db.example.com:1521", dsn.Host)
+	assert.Equal(t, "/ORCLPDB1", dsn.Path)
+	assert.Equal(t, "monitor", dsn.User.Username())
+	password, _ := dsn.User.Password()
+	assert.Equal(t, "s3cr3t word", password)
+	assert.Empty(t, dsn.Query())
+
+	dsn, err = url.Parse(DataSourceName(&Config{
+		Host: "db.example.com", Port: 2484, ServiceName: "ORCLPDB1", WalletPath: "/etc/otel/wallet", TLS: true, SkipVerify: true,
+		ConnectionOptions: map[string]string{"AUTH TYPE": "TCPS"},
+	}))
+	require.NoError(t, err)
+	assert.Equal(t, "db.example.com:2484", dsn.Host)
+	// wallets authenticate without a username
+	assert.Empty(t, dsn.User.Username())
+	assert.Equal(t, url.Values{
+		"SSL":        {"enable"},
+		"SSL VERIFY": {"false"},
+		"WALLET":     {"/etc/otel/wallet"},
+		"AUTH TYPE":  {"TCPS"},
+	}, dsn.Query())
+}
@@ -0,0 +1,259 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracledb
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"go.uber.org/multierr"
+)
+
+// sysstatMetrics are the metrics of the v$sysstat statistics, by statistic name.
+var sysstatMetrics = map[string]string{
+	"CPU used by this session": oracledbCPUTime,
+	"execute count":            oracledbExecutions,
+	"parse count (hard)":       oracledbHardParses,
+	"session logical reads":    oracledbLogicalReads,
+	"parse count (total)":      oracledbParseCalls,
+	"physical reads":           oracledbPhysicalReads,
+	"physical writes":          oracledbPhysicalWrites,
+	"user commits":             oracledbUserCommits,
+	"user rollbacks":           oracledbUserRollbacks,
+}
+
+// metricQuery is a query of a metric group, whose result rows, by lowercase column name, are converted
+// to the group's datapoints.
+type metricQuery struct {
+	datapoints func(row map[string]any, now time.Time) ([]*datapoint.Datapoint, error)
+	group      string
+	statement  string
+}
+
+var metricQueries = []metricQuery{
+	{
+		group:      groupSysstat,
+		statement:  sysstatStatement(),
+		datapoints: sysstatDatapoints,
+	},
+	{
+		group:      groupSessions,
+		statement:  "SELECT status, type, COUNT(*) AS sessions FROM v$session GROUP BY status, type",
+		datapoints: sessionDatapoints,
+	},
+	{
+		group: groupLimits,
+		statement: "SELECT resource_name, current_utilization, TRIM(limit_value) AS limit_value FROM v$resource_limit " +
+			"WHERE resource_name IN ('processes', 'sessions')",
+		datapoints: limitDatapoints,
+	},
+	{
+		group: groupTablespaces,
+		statement: "SELECT um.tablespace_name, um.used_space * ts.block_size AS used_bytes, " +
+			"um.tablespace_size * ts.block_size AS size_bytes FROM dba_tablespace_usage_metrics um " +
+			"JOIN dba_tablespaces ts ON um.tablespace_name = ts.tablespace_name",
+		datapoints: tablespaceDatapoints,
+	},
+}
+
+func sysstatStatement() string {
+	names := make([]string, 0, len(sysstatMetrics))
+	for name := range sysstatMetrics {
+		names = append(names, "'"+name+"'")
+	}
+	sort.Strings(names)
+	return fmt.Sprintf("SELECT name, value FROM v$sysstat WHERE name IN (%s)", strings.Join(names, ", "))
+}
+
+// collect runs the queries, returning the datapoints of their results.  A failing query doesn't prevent
+// running the others, like those not requiring the privileges of the failing one.
+func collect(ctx context.Context, db *sql.DB, queries []metricQuery) ([]*datapoint.Datapoint, error) {
+	now := time.Now()
+	var datapoints []*datapoint.Datapoint
+	var errs error
+	for _, query := range queries {
+		dps, err := query.collect(ctx, db, now)
+		datapoints = append(datapoints, dps...)
+		if err != nil {
+			errs = multierr.Append(errs, fmt.Errorf("%s query: %w", query.group, err))
+		}
+	}
+	return datapoints, errs
+}
+
+func (q metricQuery) collect(ctx context.Context, db *sql.DB, now time.Time) ([]*datapoint.Datapoint, error) {
+	rows, err := db.QueryContext(ctx, q.statement)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	values := make([]any, len(columns))
+	dest := make([]any, len(columns))
+	for i := range values {
+		dest[i] = &values[i]
+	}
+
+	var datapoints []*datapoint.Datapoint
+	var errs error
+	for rows.Next() {
+		if err = rows.Scan(dest...); err != nil {
+			return datapoints, multierr.Append(errs, err)
+		}
+		// Oracle reports unquoted column names in uppercase
+		row := make(map[string]any, len(columns))
+		for i, column := range columns {
+			row[strings.ToLower(column)] = values[i]
+		}
+		dps, dpErr := q.datapoints(row, now)
+		datapoints = append(datapoints, dps...)
+		errs = multierr.Append(errs, dpErr)
+	}
+	return datapoints, multierr.Append(errs, rows.Err())
+}
+
+func sysstatDatapoints(row map[string]any, now time.Time) ([]*datapoint.Datapoint, error) {
+	metric, ok := sysstatMetrics[stringValue(row["name"])]
+	if !ok {
+		return nil, nil
+	}
+	value, err := numericValue(row["value"])
+	if err != nil || value == nil {
+		return nil, err
+	}
+	if metric == oracledbCPUTime {
+		// reported in centiseconds
+		value = datapoint.NewFloatValue(toFloat(value) / 100)
+	}
+	return []*datapoint.Datapoint{datapoint.New(metric, map[string]string{}, value, datapoint.Counter, now)}, nil
+}
+
+func sessionDatapoints(row map[string]any, now time.Time) ([]*datapoint.Datapoint, error) {
+	value, err := numericValue(row["sessions"])
+	if err != nil || value == nil {
+		return nil, err
+	}
+	dimensions := map[string]string{
+		"session_status": strings.ToLower(stringValue(row["status"])),
+		"session_type":   strings.ToLower(stringValue(row["type"])),
+	}
+	return []*datapoint.Datapoint{datapoint.New(oracledbSessionsUsage, dimensions, value, datapoint.Gauge, now)}, nil
+}
+
+func limitDatapoints(row map[string]any, now time.Time) ([]*datapoint.Datapoint, error) {
+	var usageMetric, limitMetric string
+	switch stringValue(row["resource_name"]) {
+	case "processes":
+		usageMetric, limitMetric = oracledbProcessesUsage, oracledbProcessesLimit
+	case "sessions":
+		// the sessions usage is reported by status and type
+		limitMetric = oracledbSessionsLimit
+	default:
+		return nil, nil
+	}
+
+	var datapoints []*datapoint.Datapoint
+	if usageMetric != "" {
+		usage, err := numericValue(row["current_utilization"])
+		if err != nil {
+			return nil, err
+		}
+		if usage != nil {
+			datapoints = append(datapoints, datapoint.New(usageMetric, map[string]string{}, usage, datapoint.Gauge, now))
+		}
+	}
+	// unlimited resources have no limit datapoint
+	if limit := row["limit_value"]; !strings.EqualFold(stringValue(limit), "UNLIMITED") {
+		value, err := numericValue(limit)
+		if err != nil {
+			return datapoints, err
+		}
+		if value != nil {
+			datapoints = append(datapoints, datapoint.New(limitMetric, map[string]string{}, value, datapoint.Gauge, now))
+		}
+	}
+	return datapoints, nil
+}
+
+func tablespaceDatapoints(row map[string]any, now time.Time) ([]*datapoint.Datapoint, error) {
+	dimensions := map[string]string{"tablespace_name": stringValue(row["tablespace_name"])}
+	var datapoints []*datapoint.Datapoint
+	for metric, column := range map[string]string{
+		oracledbTablespaceSizeUsage: "used_bytes",
+		oracledbTablespaceSizeLimit: "size_bytes",
+	} {
+		value, err := numericValue(row[column])
+		if err != nil {
+			return datapoints, err
+		}
+		if value != nil {
+			datapoints = append(datapoints, datapoint.New(metric, dimensions, value, datapoint.Gauge, now))
+		}
+	}
+	return datapoints, nil
+}
+
+func stringValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []byte:
+		return string(v)
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// numericValue converts the NUMBER column value, returning nil for NULL.
+func numericValue(value any) (datapoint.Value, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case int64:
+		return datapoint.NewIntValue(v), nil
+	case float64:
+		if v == float64(int64(v)) {
+			return datapoint.NewIntValue(int64(v)), nil
+		}
+		return datapoint.NewFloatValue(v), nil
+	case string, []byte:
+		s := strings.TrimSpace(stringValue(v))
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return datapoint.NewIntValue(i), nil
+		}
+		if f, err := strconv.ParseFloat(s, 64); err == nil {
+			return datapoint.NewFloatValue(f), nil
+		}
+	}
+	return nil, fmt.Errorf("non-numeric value %v", value)
+}
+
+func toFloat(value datapoint.Value) float64 {
+	if i, ok := value.(datapoint.IntValue); ok {
+		return float64(i.Int())
+	}
+	return value.(datapoint.FloatValue).Float()
+}
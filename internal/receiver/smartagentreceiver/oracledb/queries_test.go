@@ -0,0 +1,188 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package oracledb
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDriverName = "oracledbtest"
+
+// testResults are the results of the test driver's queries, by the table of their statement.
+var testResults = map[string]testResult{
+	"v$sysstat": {
+		columns: []string{"NAME", "VALUE"},
+		rows: [][]driver.Value{
+			{"user commits", int64(120)},
+			{"CPU used by this session", float64(1234)},
+			{"physical reads", "42"},
+			{"redo size", int64(1)},
+		},
+	},
+	"v$session": {
+		columns: []string{"STATUS", "TYPE", "SESSIONS"},
+		rows: [][]driver.Value{
+			{"ACTIVE", "BACKGROUND", int64(50)},
+			{"INACTIVE", "USER", float64(3)},
+		},
+	},
+	"v$resource_limit": {
+		columns: []string{"RESOURCE_NAME", "CURRENT_UTILIZATION", "LIMIT_VALUE"},
+		rows: [][]driver.Value{
+			{"processes", int64(80), "300"},
+			{"sessions", int64(90), "UNLIMITED"},
+		},
+	},
+	"dba_tablespace_usage_metrics": {err: errors.New("ORA-00942: table or view does not exist")},
+}
+
+func init() {
+	sql.Register(testDriverName, testDriver{})
+}
+
+type testResult struct {
+	err     error
+	columns []string
+	rows    [][]driver.Value
+}
+
+type testDriver struct{}
+
+func (testDriver) Open(string) (driver.Conn, error) { return testConn{}, nil }
+
+type testConn struct{}
+
+func (testConn) Prepare(query string) (driver.Stmt, error) {
+	for table, result := range testResults {
+		if strings.Contains(query, "FROM "+table) {
+			return testStmt(result), nil
+		}
+	}
+	return nil, errors.New("unexpected query " + query)
+}
+func (testConn) Close() error              { return nil }
+func (testConn) Begin() (driver.Tx, error) { return nil, errors.New("unsupported") }
+
+type testStmt testResult
+
+func (testStmt) Close() error                               { return nil }
+func (testStmt) NumInput() int                              { return -1 }
+func (testStmt) Exec([]driver.Value) (driver.Result, error) { return nil, errors.New("unsupported") }
+func (s testStmt) Query([]driver.Value) (driver.Rows, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	return &testRows{result: testResult(s)}, nil
+}
+
+type testRows struct {
+	result testResult
+	next   int
+}
+
+func (r *testRows) Columns() []string { return r.result.columns }
+func (r *testRows) Close() error      { return nil }
+func (r *testRows) Next(dest []driver.Value) error {
+	if r.next == len(r.result.rows) {
+		return io.EOF
+	}
+	copy(dest, r.result.rows[r.next])
+	r.next++
+	return nil
+}
+
+func TestCollect(t *testing.T) {
+	db, err := sql.Open(testDriverName, "")
+	require.NoError(t, err)
+	defer db.Close()
+
+	datapoints, err := collect(context.Background(), db, metricQueries)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "tablespaces query: ORA-00942")
+
+	type expectedDatapoint struct {
+		value      datapoint.Value
+		dimensions map[string]string
+		metric     string
+		metricType datapoint.MetricType
+	}
+	var actual []expectedDatapoint
+	for _, dp := range datapoints {
+		assert.False(t, dp.Timestamp.IsZero())
+		actual = append(actual, expectedDatapoint{metric: dp.Metric, value: dp.Value, dimensions: dp.Dimensions, metricType: dp.MetricType})
+	}
+	sort.SliceStable(actual, func(i, j int) bool { return actual[i].metric < actual[j].metric })
+	assert.Equal(t, []expectedDatapoint{
+		{metric: oracledbCPUTime, value: datapoint.NewFloatValue(12.34), dimensions: map[string]string{}, metricType: datapoint.Counter},
+		{metric: oracledbPhysicalReads, value: datapoint.NewIntValue(42), dimensions: map[string]string{}, metricType: datapoint.Counter},
+		{metric: oracledbProcessesLimit, value: datapoint.NewIntValue(300), dimensions: map[string]string{}, metricType: datapoint.Gauge},
+		{metric: oracledbProcessesUsage, value: datapoint.NewIntValue(80), dimensions: map[string]string{}, metricType: datapoint.Gauge},
+		{
+			metric: oracledbSessionsUsage, value: datapoint.NewIntValue(50), metricType: datapoint.Gauge,
+			dimensions: map[string]string{"session_status": "active", "session_type": "background"},
+		},
+		{
+			metric: oracledbSessionsUsage, value: datapoint.NewIntValue(3), metricType: datapoint.Gauge,
+			dimensions: map[string]string{"session_status": "inactive", "session_type": "user"},
+		},
+		{metric: oracledbUserCommits, value: datapoint.NewIntValue(120), dimensions: map[string]string{}, metricType: datapoint.Counter},
+	}, actual)
+}
+
+func TestTablespaceDatapoints(t *testing.T) {
+	datapoints, err := tablespaceDatapoints(map[string]any{
+		"tablespace_name": "USERS", "used_bytes": float64(8192), "size_bytes": nil,
+	}, time.Now())
+	require.NoError(t, err)
+	require.Len(t, datapoints, 1)
+	assert.Equal(t, oracledbTablespaceSizeUsage, datapoints[0].Metric)
+	assert.Equal(t, datapoint.NewIntValue(8192), datapoints[0].Value)
+	assert.Equal(t, map[string]string{"tablespace_name": "USERS"}, datapoints[0].Dimensions)
+
+	_, err = tablespaceDatapoints(map[string]any{"tablespace_name": "USERS", "used_bytes": "many"}, time.Now())
+	assert.EqualError(t, err, "non-numeric value many")
+}
+
+func TestNumericValue(t *testing.T) {
+	for _, test := range []struct {
+		value    any
+		expected datapoint.Value
+	}{
+		{value: int64(1), expected: datapoint.NewIntValue(1)},
+		{value: float64(2), expected: datapoint.NewIntValue(2)},
+		{value: 2.5, expected: datapoint.NewFloatValue(2.5)},
+		{value: []byte(" 3 "), expected: datapoint.NewIntValue(3)},
+		{value: "4.5", expected: datapoint.NewFloatValue(4.5)},
+		{value: nil, expected: nil},
+	} {
+		value, err := numericValue(test.value)
+		require.NoError(t, err)
+		assert.Equal(t, test.expected, value)
+	}
+	_, err := numericValue(true)
+	assert.Error(t, err)
+}
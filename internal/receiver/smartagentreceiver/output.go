@@ -28,8 +28,11 @@ import (
 	collectorConfig "go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
 	"go.opentelemetry.io/collector/obsreport"
+	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.uber.org/zap"
 
+	"github.com/signalfx/splunk-otel-collector/internal/correlation"
+	"github.com/signalfx/splunk-otel-collector/internal/hostmetadata"
 	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
 )
 
@@ -44,6 +47,7 @@ type Output struct {
 	nextLogsConsumer     consumer.Logs
 	nextTracesConsumer   consumer.Traces
 	extraDimensions      map[string]string
+	cloudMetadata        *hostmetadata.Lookup
 	extraSpanTags        map[string]string
 	defaultSpanTags      map[string]string
 	logger               *zap.Logger
@@ -52,6 +56,12 @@ type Output struct {
 	monitorFiltering     *monitorFiltering
 	receiverID           collectorConfig.ComponentID
 	nextDimensionClients []metadata.MetadataExporter
+	debugOutput          *debugOutput
+	instanceTracker      *instanceTracker
+	vsphereInventory     *vsphereInventoryTracker
+	collectionWatchdog   *collectionWatchdog
+	cardinality          *cardinalityTracker
+	correlation          *correlation.Tracker
 }
 
 var _ types.Output = (*Output)(nil)
@@ -62,14 +72,14 @@ func NewOutput(
 	nextLogsConsumer consumer.Logs, nextTracesConsumer consumer.Traces, host component.Host,
 	params component.ReceiverCreateSettings,
 ) *Output {
-	return &Output{
+	output := &Output{
 		receiverID:           config.ID(),
 		nextMetricsConsumer:  nextMetricsConsumer,
 		nextLogsConsumer:     nextLogsConsumer,
 		nextTracesConsumer:   nextTracesConsumer,
 		nextDimensionClients: getMetadataExporters(config, host, nextMetricsConsumer, params.Logger),
 		logger:               params.Logger,
-		translator:           converter.NewTranslator(params.Logger),
+		translator:           newTranslator(config, params.Logger),
 		extraDimensions:      map[string]string{},
 		extraSpanTags:        map[string]string{},
 		defaultSpanTags:      map[string]string{},
@@ -80,7 +90,44 @@ func NewOutput(
 			ReceiverCreateSettings: params,
 		}),
 	}
+	if config.InstanceIndexes {
+		output.instanceTracker = newInstanceTracker(config.monitorConfig.MonitorConfigCore().IntervalSeconds)
+	}
+	if config.VSphereInventoryEvents || config.VSphereTags {
+		output.vsphereInventory = newVSphereInventoryTracker(
+			config.monitorConfig.MonitorConfigCore().IntervalSeconds, config.VSphereInventoryEvents,
+		)
+	}
+	return output
+}
 
+func newTranslator(config Config, logger *zap.Logger) converter.Translator {
+	var options []converter.TranslatorOption
+	if config.TranslateDimensions {
+		options = append(options, converter.WithDimensionTranslation())
+	}
+	if config.MaxAttributeCount > 0 || config.MaxAttributeValueLength > 0 {
+		options = append(options, converter.WithAttributeLimits(converter.AttributeLimits{
+			MaxCount:       config.MaxAttributeCount,
+			MaxValueLength: config.MaxAttributeValueLength,
+		}))
+	}
+	if len(config.DatapointMetaAttributes) > 0 {
+		options = append(options, converter.WithDatapointMetaAttributes(config.DatapointMetaAttributes))
+	}
+	if config.metricNames.Len() > 0 {
+		options = append(options, converter.WithMetricNames(config.metricNames))
+	}
+	if len(config.translationRules) > 0 {
+		options = append(options, converter.WithTranslationRules(config.translationRules))
+	}
+	if config.EventDimensionsTarget != "" {
+		options = append(options, converter.WithEventDimensionsTarget(converter.EventDimensionsTarget(config.EventDimensionsTarget)))
+	}
+	if config.EventIngestionLatency {
+		options = append(options, converter.WithEventIngestionLatency())
+	}
+	return converter.NewTranslator(logger, options...)
 }
 
 // getMetadataExporters walks through obtained Config.MetadataClients and returns all matching registered MetadataExporters,
@@ -193,29 +240,55 @@ func (output *Output) Copy() types.Output {
 }
 
 func (output *Output) SendDatapoints(datapoints ...*datapoint.Datapoint) {
+	output.collectionWatchdog.collection()
 	if output.nextMetricsConsumer == nil {
 		return
 	}
 
 	ctx := output.reporter.StartMetricsOp(context.Background())
 
+	// datapoints named by the metricNames, like those of content renamed by another receiver, are filtered and
+	// translated like those of their Smart Agent names
+	datapoints = output.translator.RestoreMetricNames(datapoints)
 	datapoints = output.filterDatapoints(datapoints)
+	cloudDimensions, cloudResourceAttributes := output.cloudMetadataAttributes()
 	for _, dp := range datapoints {
-		// Output's extraDimensions take priority over datapoint's
-		dp.Dimensions = utils.MergeStringMaps(dp.Dimensions, output.extraDimensions)
+		// Output's extraDimensions take priority over the cloud metadata ones, which take priority over datapoint's
+		dp.Dimensions = utils.MergeStringMaps(dp.Dimensions, cloudDimensions, output.extraDimensions)
+	}
+
+	output.cardinality.track(datapoints)
+
+	var instanceEvents []*event.Event
+	if output.instanceTracker != nil {
+		instanceEvents = output.instanceTracker.track(datapoints)
+	}
+	if output.vsphereInventory != nil {
+		instanceEvents = append(instanceEvents, output.vsphereInventory.track(datapoints)...)
 	}
 
 	metrics, err := output.translator.ToMetrics(datapoints)
 	if err != nil {
 		output.logger.Error("error converting SFx datapoints to ptrace.Traces", zap.Error(err))
 	}
+	rms := metrics.ResourceMetrics()
+	for i := 0; i < rms.Len(); i++ {
+		addResourceAttributes(rms.At(i).Resource(), cloudResourceAttributes)
+	}
+
+	output.debugOutput.consumeMetrics(metrics)
 
 	numPoints := metrics.DataPointCount()
 	err = output.nextMetricsConsumer.ConsumeMetrics(context.Background(), metrics)
 	output.reporter.EndMetricsOp(ctx, typeStr, numPoints, err)
+
+	for _, ev := range instanceEvents {
+		output.SendEvent(ev)
+	}
 }
 
 func (output *Output) SendEvent(event *event.Event) {
+	output.collectionWatchdog.collection()
 	if output.nextLogsConsumer == nil {
 		return
 	}
@@ -224,6 +297,13 @@ func (output *Output) SendEvent(event *event.Event) {
 	if err != nil {
 		output.logger.Error("error converting SFx events to ptrace.Traces", zap.Error(err))
 	}
+	_, cloudResourceAttributes := output.cloudMetadataAttributes()
+	rls := logs.ResourceLogs()
+	for i := 0; i < rls.Len(); i++ {
+		addResourceAttributes(rls.At(i).Resource(), cloudResourceAttributes)
+	}
+
+	output.debugOutput.consumeLogs(logs)
 
 	err = output.nextLogsConsumer.ConsumeLogs(context.Background(), logs)
 	if err != nil {
@@ -232,6 +312,7 @@ func (output *Output) SendEvent(event *event.Event) {
 }
 
 func (output *Output) SendSpans(spans ...*trace.Span) {
+	output.collectionWatchdog.collection()
 	if output.nextTracesConsumer == nil {
 		return
 	}
@@ -256,6 +337,11 @@ func (output *Output) SendSpans(spans ...*trace.Span) {
 		output.logger.Error("error converting SFx spans to ptrace.Traces", zap.Error(err))
 	}
 
+	output.debugOutput.consumeTraces(traces)
+	if output.correlation != nil {
+		_ = output.correlation.ConsumeTraces(context.Background(), traces)
+	}
+
 	err = output.nextTracesConsumer.ConsumeTraces(context.Background(), traces)
 	if err != nil {
 		output.logger.Debug("SendSpans has failed", zap.Error(err))
@@ -286,6 +372,28 @@ func (output *Output) RemoveExtraDimension(key string) {
 	delete(output.extraDimensions, key)
 }
 
+// cloudMetadataAttributes returns the unique cloud host identifier dimensions and the resource attributes of
+// the cloud metadata, once retrieved.
+func (output *Output) cloudMetadataAttributes() (dimensions, resourceAttributes map[string]string) {
+	if output.cloudMetadata == nil {
+		return nil, nil
+	}
+	metadata, err := output.cloudMetadata.Get()
+	if err != nil {
+		return nil, nil
+	}
+	return metadata.Dimensions(), metadata.ResourceAttributes()
+}
+
+// addResourceAttributes adds the attributes to the resource of translated datapoints or events, without
+// overriding those it already has.
+func addResourceAttributes(resource pcommon.Resource, attributes map[string]string) {
+	attrs := resource.Attributes()
+	for k, v := range attributes {
+		attrs.InsertString(k, v)
+	}
+}
+
 func (output *Output) AddExtraSpanTag(key, value string) {
 	output.extraSpanTags[key] = value
 }
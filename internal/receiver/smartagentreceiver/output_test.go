@@ -18,6 +18,7 @@ import (
 	"context"
 	"fmt"
 	"testing"
+	"time"
 
 	metadata "github.com/open-telemetry/opentelemetry-collector-contrib/pkg/experimentalmetricmetadata"
 	"github.com/signalfx/golib/v3/datapoint"
@@ -250,6 +251,95 @@ func TestSendEvent(t *testing.T) {
 	assert.Equal(t, "property_value", val.StringVal())
 }
 
+func TestSendEventWithEventDimensionsTarget(t *testing.T) {
+	mmc := mockMetadataClient{id: config.NewComponentID("signalfx")}
+	output := NewOutput(
+		Config{EventDimensionsTarget: "resource"}, fakeMonitorFiltering(), consumertest.NewNop(), &mmc,
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+
+	output.SendEvent(&event.Event{
+		EventType:  "my_event",
+		Dimensions: map[string]string{"tenant": "a-tenant"},
+	})
+	received := mmc.receivedLogs
+	require.Equal(t, 1, len(received))
+	rl := received[0].ResourceLogs().At(0)
+	tenant, ok := rl.Resource().Attributes().Get("tenant")
+	require.True(t, ok)
+	assert.Equal(t, "a-tenant", tenant.StringVal())
+	_, ok = rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().Get("tenant")
+	assert.False(t, ok)
+}
+
+func TestSendDatapointsWithInstanceIndexes(t *testing.T) {
+	cfg := newConfig("win_perf_counters", winPerfCountersMonitorType, 10)
+	cfg.InstanceIndexes = true
+	metricsSink := new(consumertest.MetricsSink)
+	mmc := mockMetadataClient{id: config.NewComponentID("signalfx")}
+	output := NewOutput(
+		cfg, fakeMonitorFiltering(), metricsSink, &mmc,
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+
+	output.SendDatapoints(datapoint.New(
+		"win_cpu.Percent_Processor_Time",
+		map[string]string{"objectname": "Processor", "instance": "_Total"},
+		datapoint.NewFloatValue(12.5), datapoint.Gauge, time.Now(),
+	))
+
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	dp := metricsSink.AllMetrics()[0].ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0).Gauge().DataPoints().At(0)
+	index, ok := dp.Attributes().Get("instance_index")
+	require.True(t, ok)
+	assert.Equal(t, "0", index.StringVal())
+
+	require.Len(t, mmc.receivedLogs, 1)
+	attributes := mmc.receivedLogs[0].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0).Attributes()
+	eventType, ok := attributes.Get("com.splunk.signalfx.event_type")
+	require.True(t, ok)
+	assert.Equal(t, "win_perf_counters.instance.added", eventType.StringVal())
+	instance, ok := attributes.Get("instance")
+	require.True(t, ok)
+	assert.Equal(t, "_Total", instance.StringVal())
+}
+
+func TestSendDatapointsWithRenamedMetricNames(t *testing.T) {
+	cfg := newConfig("redis", "collectd/redis", 10)
+	var err error
+	cfg.metricNames, err = newMetricNames(map[string]string{
+		"bytes.used_memory":   "redis.memory.used",
+		"gauge.uptime_in_sec": "redis.uptime",
+	})
+	require.NoError(t, err)
+	monitorFiltering, err := newMonitorFiltering(&saconfig.MonitorConfig{
+		DatapointsToExclude: []saconfig.MetricFilter{{MetricName: "gauge.uptime_in_sec"}},
+	}, nil, zap.NewNop())
+	require.NoError(t, err)
+	metricsSink := new(consumertest.MetricsSink)
+	output := NewOutput(
+		cfg, monitorFiltering, metricsSink, consumertest.NewNop(),
+		consumertest.NewNop(), componenttest.NewNopHost(), newReceiverCreateSettings(),
+	)
+
+	// the datapoints already renamed, like by another collector, are filtered by their Smart Agent names
+	renamed := datapoint.New("redis.memory.used", map[string]string{"host": "one"}, datapoint.NewIntValue(1024), datapoint.Gauge, time.Now())
+	output.SendDatapoints(
+		datapoint.New("bytes.used_memory", map[string]string{"host": "two"}, datapoint.NewIntValue(2048), datapoint.Gauge, time.Now()),
+		renamed,
+		datapoint.New("redis.uptime", map[string]string{"host": "one"}, datapoint.NewIntValue(60), datapoint.Gauge, time.Now()),
+	)
+
+	require.Len(t, metricsSink.AllMetrics(), 1)
+	md := metricsSink.AllMetrics()[0]
+	require.Equal(t, 2, md.DataPointCount())
+	metrics := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics()
+	for i := 0; i < metrics.Len(); i++ {
+		assert.Equal(t, "redis.memory.used", metrics.At(i).Name())
+	}
+	assert.Equal(t, "redis.memory.used", renamed.Metric)
+}
+
 func TestDimensionClientDefaultsToSFxExporter(t *testing.T) {
 	mmc := mockMetadataClient{id: config.NewComponentID("signalfx")}
 	output := NewOutput(
@@ -24,6 +24,7 @@ import (
 	"runtime"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/signalfx/signalfx-agent/pkg/core/common/constants"
 	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
@@ -32,12 +33,20 @@ import (
 	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
 	"github.com/signalfx/signalfx-agent/pkg/utils/hostfs"
 	"github.com/sirupsen/logrus"
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/config"
 	"go.opentelemetry.io/collector/consumer"
 	"go.uber.org/zap"
+	"k8s.io/client-go/kubernetes"
 
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+	"github.com/signalfx/splunk-otel-collector/internal/correlation"
 	"github.com/signalfx/splunk-otel-collector/internal/extension/smartagentextension"
+	"github.com/signalfx/splunk-otel-collector/internal/filewatcher"
+	"github.com/signalfx/splunk-otel-collector/internal/receiver/smartagentreceiver/converter"
 )
 
 const setOutputErrMsg = "unable to set Output field of monitor"
@@ -45,10 +54,26 @@ const systemTypeKey = "system.type"
 
 type Receiver struct {
 	monitor             any
+	collectdInstance    *collectdManager
+	tlsWatcher          *filewatcher.Watcher
+	secretWatcher       *secretWatcher
+	secretValues        map[string]string
+	collectionWatchdog  *collectionWatchdog
+	cardinality         *cardinalityTracker
+	customQueries       *customQueryRunner
+	httpTransactions    *httpTransactionRunner
+	vsphereTags         *vsphereTagSyncer
+	haproxyRuntimeAPI   *haproxyRuntimeAPIProxy
+	correlation         *correlation.Tracker
+	debugOutput         *debugOutput
+	lifecycle           *lifecycleEvents
+	usageMonitorType    string
+	host                component.Host
 	nextMetricsConsumer consumer.Metrics
 	nextLogsConsumer    consumer.Logs
 	nextTracesConsumer  consumer.Traces
 	logger              *zap.Logger
+	clock               clock.Clock
 	config              *Config
 	params              component.ReceiverCreateSettings
 	sync.Mutex
@@ -70,6 +95,7 @@ func NewReceiver(params component.ReceiverCreateSettings, config Config) *Receiv
 		logger: params.Logger,
 		params: params,
 		config: &config,
+		clock:  clock.New(),
 	}
 }
 
@@ -91,17 +117,33 @@ func (r *Receiver) registerTracesConsumer(tracesConsumer consumer.Traces) {
 	r.nextTracesConsumer = tracesConsumer
 }
 
-func (r *Receiver) Start(_ context.Context, host component.Host) error {
+func (r *Receiver) Start(ctx context.Context, host component.Host) error {
 	// subsequent Start() invocations should noop
 	if r.monitor != nil {
 		return nil
 	}
 
-	err := r.config.validate()
-	if err != nil {
+	var err error
+	var secretsClient kubernetes.Interface
+	var secretRefs map[string]SecretKeyRef
+	var secretValues map[string]string
+	if len(r.config.SecretKeyRefs) != 0 && r.config.monitorConfig != nil {
+		if secretsClient, secretRefs, secretValues, err = r.resolveSecrets(ctx); err != nil {
+			return fmt.Errorf("failed resolving secret references for %q: %w", r.config.ID().String(), err)
+		}
+		r.secretValues = secretValues
+	}
+
+	if err = r.config.validate(); err != nil {
 		return fmt.Errorf("config validation failed for %q: %w", r.config.ID().String(), err)
 	}
 
+	if r.config.TranslationRulesFile != "" {
+		if r.config.translationRules, err = converter.LoadTranslationRules(r.config.TranslationRulesFile); err != nil {
+			return fmt.Errorf("failed loading translationRulesFile for %q: %w", r.config.ID().String(), err)
+		}
+	}
+
 	configCore := r.config.monitorConfig.MonitorConfigCore()
 	monitorType := configCore.Type
 	monitorName := nonWordCharacters.ReplaceAllString(r.config.ID().String(), "")
@@ -120,33 +162,266 @@ func (r *Receiver) Start(_ context.Context, host component.Host) error {
 	if !r.config.acceptsEndpoints {
 		r.logger.Info("This Smart Agent monitor does not use Host/Port config fields. If either are set, they will be ignored.", zap.String("monitor_type", monitorType))
 	}
-	r.monitor, err = r.createMonitor(monitorType, host)
+	if r.config.DebugOutput {
+		if r.debugOutput, err = newDebugOutput(
+			*r.config, r.params, r.nextMetricsConsumer != nil, r.nextLogsConsumer != nil, r.nextTracesConsumer != nil,
+		); err != nil {
+			return fmt.Errorf("failed creating debug output: %w", err)
+		}
+		if err = r.debugOutput.start(ctx, host); err != nil {
+			return fmt.Errorf("failed starting debug output: %w", err)
+		}
+		r.logger.Info("Logging converted telemetry as debug output", zap.String("monitor_type", monitorType))
+	}
+
+	if r.config.CollectionTimeoutSeconds > 0 || r.config.CardinalityReportIntervalSeconds > 0 {
+		registerMetricViewsOnce.Do(func() {
+			if viewErr := view.Register(metricViews()...); viewErr != nil {
+				r.logger.Warn("failed registering smartagent receiver metric views", zap.Error(viewErr))
+			}
+		})
+	}
+	if r.config.CollectionTimeoutSeconds > 0 {
+		timeout := time.Duration(configCore.IntervalSeconds+r.config.CollectionTimeoutSeconds) * time.Second
+		r.collectionWatchdog = newCollectionWatchdog(timeout, r.clock, r.onCollectionTimeout)
+	}
+	if r.config.CardinalityReportIntervalSeconds > 0 {
+		r.cardinality = newCardinalityTracker(
+			r.config.CardinalityReportIntervalSeconds, r.config.CardinalityReportTopK, r.config.ID(), monitorType, r.logger, r.clock,
+		)
+	}
+
+	if r.config.Correlation != nil {
+		client, clientErr := correlation.NewClient(*r.config.Correlation, host, r.params.TelemetrySettings)
+		if clientErr != nil {
+			return fmt.Errorf("failed creating correlation client: %w", clientErr)
+		}
+		r.correlation = correlation.NewTracker(*r.config.Correlation, client, r.logger)
+		r.correlation.Start()
+	}
+
+	r.lifecycle = newLifecycleEvents(*r.config, r.nextLogsConsumer, r.logger)
+	if r.lifecycle != nil {
+		r.lifecycle.now = r.clock.Now
+	}
+	monitorConfig, err := withSecretValues(r.config.monitorConfig, r.secretValues)
 	if err != nil {
+		return fmt.Errorf("failed applying secret references for %q: %w", r.config.ID().String(), err)
+	}
+	r.monitor, err = r.createMonitor(monitorType, monitorConfig, host)
+	if err != nil {
+		r.lifecycle.emit(monitorFailed, "", err)
 		return fmt.Errorf("failed creating monitor %q: %w", monitorType, err)
 	}
+	reportMonitorUsage(r.logger, monitorType, r.config.ID())
+	r.usageMonitorType = monitorType
+	r.configureHostFS(monitorType)
 
 	configCore.ProcPath = saConfig.ProcPath
+	monitorConfig.MonitorConfigCore().ProcPath = saConfig.ProcPath
+
+	if err = saconfig.CallConfigure(r.monitor, monitorConfig); err != nil {
+		r.lifecycle.emit(monitorFailed, "", err)
+		return err
+	}
+	r.lifecycle.emit(monitorStarted, "", nil)
+	r.customQueries.start()
+	r.httpTransactions.start()
+	r.vsphereTags.start()
+	r.haproxyRuntimeAPI.start()
+
+	if r.collectionWatchdog != nil {
+		r.host = host
+		r.collectionWatchdog.start()
+	}
+	r.cardinality.start()
+
+	files := tlsFiles(r.config.TLS)
+	if r.config.HAProxyRuntimeAPI != nil {
+		files = append(files, tlsFiles(r.config.HAProxyRuntimeAPI.TLS)...)
+	}
+	if len(files) != 0 {
+		r.host = host
+		onChange := func() { r.restartMonitor("tls file changes") }
+		if r.tlsWatcher, err = watchTLSFiles(files, onChange, r.logger); err != nil {
+			return fmt.Errorf("failed watching tls files: %w", err)
+		}
+	}
+	if secretsClient != nil {
+		r.host = host
+		r.secretWatcher = newSecretWatcher(secretsClient, secretRefs, secretValues, r.applySecretChanges, r.logger)
+	}
+	return nil
+}
 
-	return saconfig.CallConfigure(r.monitor, r.config.monitorConfig)
+// resolveSecrets retrieves the current values of the referenced secret keys, returning the client to watch
+// them with along with the namespaced references and their values.
+func (r *Receiver) resolveSecrets(ctx context.Context) (kubernetes.Interface, map[string]SecretKeyRef, map[string]string, error) {
+	refs, err := withDefaultSecretNamespaces(r.config.SecretKeyRefs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	client, err := newKubernetesClient()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("failed creating kubernetes client: %w", err)
+	}
+	values, err := resolveSecretKeyRefs(ctx, client, refs)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return client, refs, values, nil
+}
+
+// applySecretChanges restarts the monitor with the updated secret values.  The config of the running monitor
+// isn't changed, as it may be reading it concurrently.
+func (r *Receiver) applySecretChanges(values map[string]string) {
+	if _, err := withSecretValues(r.config.monitorConfig, values); err != nil {
+		r.logger.Error("failed applying secret changes", zap.Error(err))
+		return
+	}
+	r.Lock()
+	r.secretValues = values
+	r.Unlock()
+	r.restartMonitor("secret changes")
+}
+
+// onCollectionTimeout restarts the monitor when it hasn't sent any telemetry within its interval and collection
+// timeout, unless it was already restarted maxCollectionTimeoutRestarts consecutive times.  The in-flight collection
+// is only canceled by monitors whose shutdown cancels it.
+func (r *Receiver) onCollectionTimeout(exhausted bool) {
+	monitorType := r.config.monitorConfig.MonitorConfigCore().Type
+	if exhausted {
+		r.logger.Error(
+			"Monitor hasn't sent any telemetry after its consecutive collection timeout restarts, not restarting it again until it does",
+			zap.String("monitor_type", monitorType),
+			zap.Int("restarts", maxCollectionTimeoutRestarts),
+		)
+		r.lifecycle.emit(monitorFailed, "collection timeout", fmt.Errorf(
+			"no telemetry sent after %d collection timeout restarts", maxCollectionTimeoutRestarts,
+		))
+		return
+	}
+	_ = stats.RecordWithTags(context.Background(), []tag.Mutator{
+		tag.Upsert(receiverKey, r.config.ID().String()),
+		tag.Upsert(monitorTypeKey, monitorType),
+	}, mCollectionTimeouts.M(1))
+	r.logger.Warn(
+		"Monitor hasn't sent any telemetry within its interval and collection timeout",
+		zap.String("monitor_type", monitorType),
+		zap.Int("collectionTimeoutSeconds", r.config.CollectionTimeoutSeconds),
+	)
+	r.lifecycle.emit(monitorFailed, "collection timeout", fmt.Errorf(
+		"no telemetry sent within %d seconds", r.config.monitorConfig.MonitorConfigCore().IntervalSeconds+r.config.CollectionTimeoutSeconds,
+	))
+	r.restartMonitor("collection timeout")
 }
 
-func (r *Receiver) Shutdown(context.Context) error {
+// restartMonitor recreates and configures the monitor so that it loads the current content of its tls files
+// and secrets, or resumes collection after a collection timeout.
+func (r *Receiver) restartMonitor(reason string) {
+	r.Lock()
+	defer r.Unlock()
+
+	monitorType := r.config.monitorConfig.MonitorConfigCore().Type
+	r.logger.Info("Restarting monitor after "+reason, zap.String("monitor_type", monitorType))
+	monitorConfig, err := withSecretValues(r.config.monitorConfig, r.secretValues)
+	if err != nil {
+		r.logger.Error("failed applying secrets after "+reason, zap.String("monitor_type", monitorType), zap.Error(err))
+		r.lifecycle.emit(monitorFailed, reason, err)
+		return
+	}
+	if shutdownable, ok := (r.monitor).(monitors.Shutdownable); ok {
+		shutdownable.Shutdown()
+	}
+	// an isolated collectd instance terminates once its only monitor has shut down
+	r.collectdInstance = nil
+	r.customQueries.shutdown()
+	r.customQueries = nil
+	r.httpTransactions.shutdown()
+	r.httpTransactions = nil
+	r.vsphereTags.shutdown()
+	r.vsphereTags = nil
+	r.haproxyRuntimeAPI.shutdown()
+	r.haproxyRuntimeAPI = nil
+	r.lifecycle.emit(monitorStopped, reason, nil)
+
+	monitor, err := r.createMonitor(monitorType, monitorConfig, r.host)
+	if err != nil {
+		r.logger.Error("failed recreating monitor after "+reason, zap.String("monitor_type", monitorType), zap.Error(err))
+		r.lifecycle.emit(monitorFailed, reason, err)
+		return
+	}
+	r.monitor = monitor
+	if err = saconfig.CallConfigure(r.monitor, monitorConfig); err != nil {
+		r.logger.Error("failed configuring monitor after "+reason, zap.String("monitor_type", monitorType), zap.Error(err))
+		r.lifecycle.emit(monitorFailed, reason, err)
+		return
+	}
+	r.lifecycle.emit(monitorStarted, reason, nil)
+	r.customQueries.start()
+	r.httpTransactions.start()
+	r.vsphereTags.start()
+	r.haproxyRuntimeAPI.start()
+}
+
+func (r *Receiver) Shutdown(ctx context.Context) error {
 	defer rusToZap.unRedirect(logrusKey{
 		Logger:      logrus.StandardLogger(),
 		monitorType: r.config.monitorConfig.MonitorConfigCore().Type,
 	}, r.logger)
 
+	if r.tlsWatcher != nil {
+		if err := r.tlsWatcher.Close(); err != nil {
+			r.logger.Warn("failed closing tls file watcher", zap.Error(err))
+		}
+		r.tlsWatcher = nil
+	}
+	if r.secretWatcher != nil {
+		r.secretWatcher.Shutdown()
+		r.secretWatcher = nil
+	}
+	r.collectionWatchdog.shutdown()
+	r.collectionWatchdog = nil
+	r.cardinality.shutdown()
+	r.cardinality = nil
+	r.customQueries.shutdown()
+	r.customQueries = nil
+	r.httpTransactions.shutdown()
+	r.httpTransactions = nil
+	r.vsphereTags.shutdown()
+	r.vsphereTags = nil
+	r.haproxyRuntimeAPI.shutdown()
+	r.haproxyRuntimeAPI = nil
+	if err := r.debugOutput.shutdown(ctx); err != nil {
+		r.logger.Warn("failed shutting down debug output", zap.Error(err))
+	}
+	r.debugOutput = nil
+	if r.correlation != nil {
+		if err := r.correlation.Shutdown(ctx); err != nil {
+			r.logger.Warn("failed shutting down correlation", zap.Error(err))
+		}
+		r.correlation = nil
+	}
 	if r.monitor == nil {
 		return fmt.Errorf("smartagentreceiver's Shutdown() called before Start() or with invalid monitor state")
 	} else if shutdownable, ok := (r.monitor).(monitors.Shutdownable); !ok {
 		return fmt.Errorf("invalid monitor state at Shutdown(): %#v", r.monitor)
 	} else {
 		shutdownable.Shutdown()
+		r.lifecycle.emit(monitorStopped, "shutdown", nil)
+	}
+	// an isolated collectd instance terminates once its only monitor has shut down
+	r.collectdInstance = nil
+	if r.usageMonitorType != "" {
+		monitorUsage.release(r.usageMonitorType)
+		r.usageMonitorType = ""
 	}
 	return nil
 }
 
-func (r *Receiver) createMonitor(monitorType string, host component.Host) (monitor any, err error) {
+// createMonitor creates the monitor and its companion runners, which read their options from the provided
+// monitor config with any secret values applied.
+func (r *Receiver) createMonitor(monitorType string, monitorConfig saconfig.MonitorCustomConfig, host component.Host) (monitor any, err error) {
 	// retrieve registered MonitorFactory from agent's registration store
 	monitorFactory, ok := monitors.MonitorFactories[monitorType]
 	if !ok {
@@ -171,6 +446,16 @@ func (r *Receiver) createMonitor(monitorType string, host component.Host) (monit
 	output := NewOutput(
 		*r.config, monitorFiltering, r.nextMetricsConsumer, r.nextLogsConsumer, r.nextTracesConsumer, host, r.params,
 	)
+	output.debugOutput = r.debugOutput
+	output.collectionWatchdog = r.collectionWatchdog
+	output.cardinality = r.cardinality
+	output.correlation = r.correlation
+	if output.instanceTracker != nil {
+		output.instanceTracker.now = r.clock.Now
+	}
+	if output.vsphereInventory != nil {
+		output.vsphereInventory.now = r.clock.Now
+	}
 	set, err := SetStructFieldWithExplicitType(
 		monitor, "Output", output,
 		reflect.TypeOf((*types.Output)(nil)).Elem(),
@@ -183,12 +468,57 @@ func (r *Receiver) createMonitor(monitorType string, host component.Host) (monit
 		return nil, fmt.Errorf("%s", setOutputErrMsg)
 	}
 
+	// added first so the monitor's own extraDimensions take precedence
+	for k, v := range r.extraDimensionsFromEnv() {
+		output.AddExtraDimension(k, v)
+	}
 	for k, v := range r.config.monitorConfig.MonitorConfigCore().ExtraDimensions {
 		output.AddExtraDimension(k, v)
 	}
 
 	output.AddExtraDimension(systemTypeKey, stripMonitorTypePrefix(monitorType))
 
+	if len(r.config.CustomQueries) != 0 {
+		queryOutput := output.Copy().(*Output)
+		// custom query datapoints don't indicate that the monitor itself is collecting
+		queryOutput.collectionWatchdog = nil
+		if r.customQueries, err = newCustomQueryRunner(r.config.CustomQueries, monitorConfig, queryOutput, r.logger, r.clock); err != nil {
+			return nil, fmt.Errorf("failed creating custom queries: %w", err)
+		}
+	}
+
+	if len(r.config.Transactions) != 0 {
+		transactionOutput := output.Copy().(*Output)
+		// transaction datapoints don't indicate that the monitor itself is collecting
+		transactionOutput.collectionWatchdog = nil
+		r.httpTransactions = newHTTPTransactionRunner(r.config.Transactions, monitorConfig, transactionOutput, r.logger, r.clock)
+	}
+
+	if r.config.VSphereTags {
+		if r.vsphereTags, err = newVSphereTagSyncer(monitorConfig, output.vsphereInventory, output, r.logger, r.clock); err != nil {
+			return nil, fmt.Errorf("failed creating vsphere tag syncer: %w", err)
+		}
+	}
+
+	if r.config.HAProxyRuntimeAPI != nil {
+		runtimeAPIOutput := output.Copy().(*Output)
+		// server state change events don't indicate that the monitor itself is collecting
+		runtimeAPIOutput.collectionWatchdog = nil
+		if r.haproxyRuntimeAPI, err = newHAProxyRuntimeAPIProxy(*r.config.HAProxyRuntimeAPI, monitorConfig, runtimeAPIOutput, r.logger, r.clock); err != nil {
+			return nil, fmt.Errorf("failed creating runtime API proxy: %w", err)
+		}
+	}
+
+	if len(r.config.CollectdTypesDB) != 0 || len(r.config.CollectdPluginConfigDirs) != 0 {
+		if err = r.config.setCustomCollectdTemplates(); err != nil {
+			return nil, fmt.Errorf("failed loading custom collectd files: %w", err)
+		}
+	}
+
+	if hostMetadataMonitors[monitorType] {
+		lookupCloudMetadata(output, cloudMetadataProvider(), r.logger)
+	}
+
 	// Configure SmartAgentConfigProvider to gather any global config overrides and
 	// set required envs.
 	configureEnvironmentOnce.Do(func() {
@@ -197,6 +527,9 @@ func (r *Receiver) createMonitor(monitorType string, host component.Host) (monit
 	})
 
 	if r.config.monitorConfig.MonitorConfigCore().IsCollectdBased() {
+		if r.config.IsolatedCollectd {
+			return monitor, r.setIsolatedCollectdInstance(monitor)
+		}
 		configureCollectdOnce.Do(func() {
 			r.logger.Info("Configuring collectd")
 			err = collectd.ConfigureMainCollectd(&saConfig.Collectd)
@@ -206,6 +539,24 @@ func (r *Receiver) createMonitor(monitorType string, host component.Host) (monit
 	return monitor, err
 }
 
+// extraDimensionsFromEnv resolves the values of the configured extraDimensionsFromEnv environment variables,
+// omitting those that are unset or empty.
+func (r *Receiver) extraDimensionsFromEnv() map[string]string {
+	dimensions := make(map[string]string, len(r.config.ExtraDimensionsFromEnv))
+	for dimension, envVar := range r.config.ExtraDimensionsFromEnv {
+		value := os.Getenv(envVar)
+		if value == "" {
+			r.logger.Warn(
+				"Not adding extra dimension from unset environment variable",
+				zap.String("dimension", dimension), zap.String("env", envVar),
+			)
+			continue
+		}
+		dimensions[dimension] = value
+	}
+	return dimensions
+}
+
 func stripMonitorTypePrefix(s string) string {
 	idx := strings.Index(s, "/")
 	if idx == -1 {
@@ -0,0 +1,49 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package smartagentreceiver
+
+import (
+	"fmt"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors/collectd"
+	"go.uber.org/zap"
+)
+
+type collectdManager = collectd.Manager
+
+// collectdInstanceSetter is implemented by monitors embedding the collectd MonitorCore.
+type collectdInstanceSetter interface {
+	SetCollectdInstance(instance *collectd.Manager)
+}
+
+// setIsolatedCollectdInstance configures the monitor to use its own collectd instance, whose config files are
+// rendered in a receiver-specific subdirectory of the collectd config dir and whose write server uses a free port.
+func (r *Receiver) setIsolatedCollectdInstance(monitor any) error {
+	setter, ok := monitor.(collectdInstanceSetter)
+	if !ok {
+		return fmt.Errorf("monitor type %q doesn't support isolated collectd instances", r.config.monitorConfig.MonitorConfigCore().Type)
+	}
+
+	collectdConfig := saConfig.Collectd
+	collectdConfig.InstanceName = string(r.config.monitorConfig.MonitorConfigCore().MonitorID)
+	collectdConfig.WriteServerPort = 0
+	r.logger.Info("Configuring isolated collectd instance", zap.String("instance", collectdConfig.InstanceName))
+	r.collectdInstance = collectd.InitCollectd(&collectdConfig)
+	setter.SetCollectdInstance(r.collectdInstance)
+	return nil
+}
@@ -0,0 +1,32 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build linux
+// +build linux
+
+package smartagentreceiver
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetIsolatedCollectdInstanceUnsupportedMonitor(t *testing.T) {
+	cfg := newConfig("valid", "cpu", 1)
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	err := receiver.setIsolatedCollectdInstance(&struct{}{})
+	assert.EqualError(t, err, "monitor type \"cpu\" doesn't support isolated collectd instances")
+	assert.Nil(t, receiver.collectdInstance)
+}
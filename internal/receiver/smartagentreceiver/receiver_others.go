@@ -0,0 +1,31 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+//go:build !linux
+// +build !linux
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// collectdManager stands in for the collectd instance manager, which is only available on linux.
+type collectdManager struct{}
+
+// setIsolatedCollectdInstance rejects isolated collectd instances, since collectd only runs on linux.
+func (r *Receiver) setIsolatedCollectdInstance(any) error {
+	return fmt.Errorf("isolated collectd instances aren't supported on %s", runtime.GOOS)
+}
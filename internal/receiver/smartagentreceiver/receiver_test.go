@@ -34,6 +34,7 @@ import (
 	"go.opentelemetry.io/collector/component"
 	"go.opentelemetry.io/collector/component/componenttest"
 	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/config/configtls"
 	"go.opentelemetry.io/collector/consumer/consumertest"
 	"go.opentelemetry.io/collector/pdata/pcommon"
 	"go.opentelemetry.io/collector/pdata/pmetric"
@@ -191,6 +192,68 @@ func TestStartReceiverWithInvalidMonitorConfig(t *testing.T) {
 	)
 }
 
+func TestStartReceiverWithIsolatedCollectdForNonCollectdMonitor(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("invalid", "cpu", 1)
+	cfg.IsolatedCollectd = true
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	err := receiver.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err,
+		"config validation failed for \"smartagent/invalid\": isolatedCollectd is only supported by collectd based monitors, not \"cpu\"",
+	)
+}
+
+func TestStartReceiverWithInstanceIndexesForUnsupportedMonitor(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("invalid", "cpu", 1)
+	cfg.InstanceIndexes = true
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	err := receiver.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err,
+		"config validation failed for \"smartagent/invalid\": instanceIndexes is only supported by the telegraf/win_perf_counters monitor, not \"cpu\"",
+	)
+}
+
+func TestStartReceiverWithCustomQueriesForUnsupportedMonitor(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("invalid", "cpu", 1)
+	cfg.CustomQueries = []CustomQuery{{Statement: "SELECT 1 AS one", Metrics: []CustomQueryMetric{{MetricName: "one", ValueColumn: "one"}}}}
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	err := receiver.Start(context.Background(), componenttest.NewNopHost())
+	assert.EqualError(t, err,
+		"config validation failed for \"smartagent/invalid\": customQueries is only supported by the postgresql, collectd/postgresql, collectd/mysql, and oracledb monitors, not \"cpu\"",
+	)
+}
+
+func TestStartReceiverWithTranslationRulesFile(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("valid", "cpu", 1)
+	cfg.TranslationRulesFile = "./testdata/translation_rules/rules.yaml"
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	require.Len(t, receiver.config.translationRules, 3)
+	assert.EqualValues(t, "rename_metrics", receiver.config.translationRules[0].Action)
+	assert.Equal(t, map[string]int64{"system.memory.usage": 1024}, receiver.config.translationRules[1].ScaleFactorsInt)
+	assert.Equal(t, map[string]map[string]bool{"plugin_instance": {}}, receiver.config.translationRules[2].DimensionPairs)
+	require.NoError(t, receiver.Shutdown(context.Background()))
+}
+
+func TestStartReceiverWithInvalidTranslationRulesFile(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("invalid", "cpu", 1)
+	cfg.TranslationRulesFile = "./testdata/translation_rules/invalid_rules.yaml"
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	assert.EqualError(t, receiver.Start(context.Background(), componenttest.NewNopHost()),
+		`failed loading translationRulesFile for "smartagent/invalid": invalid translation rule 0 of ./testdata/translation_rules/invalid_rules.yaml: unsupported translation rule action "split_metric"`,
+	)
+
+	cfg.TranslationRulesFile = "./testdata/translation_rules/missing.yaml"
+	receiver = NewReceiver(newReceiverCreateSettings(), cfg)
+	err := receiver.Start(context.Background(), componenttest.NewNopHost())
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed loading translationRulesFile for "smartagent/invalid": open ./testdata/translation_rules/missing.yaml`)
+}
+
 func TestStartReceiverWithUnknownMonitorType(t *testing.T) {
 	t.Cleanup(cleanUp)
 	cfg := newConfig("invalid", "notamonitortype", 1)
@@ -212,6 +275,86 @@ func TestStartAndShutdown(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestMonitorRestartedOnTLSFileChange(t *testing.T) {
+	t.Cleanup(cleanUp)
+	previous := tlsReloadDebounce
+	tlsReloadDebounce = 10 * time.Millisecond
+	t.Cleanup(func() { tlsReloadDebounce = previous })
+
+	caFile := filepath.Join(t.TempDir(), "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("original"), 0600))
+
+	cfg := newConfig("valid", "cpu", 1)
+	cfg.TLS = &configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{CAFile: caFile}}
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	require.NotNil(t, receiver.tlsWatcher)
+
+	receiver.Lock()
+	original := receiver.monitor
+	receiver.Unlock()
+
+	require.NoError(t, os.WriteFile(caFile, []byte("rotated"), 0600))
+	require.Eventually(t, func() bool {
+		receiver.Lock()
+		defer receiver.Unlock()
+		return receiver.monitor != original
+	}, 5*time.Second, 10*time.Millisecond)
+
+	require.NoError(t, receiver.Shutdown(context.Background()))
+	assert.Nil(t, receiver.tlsWatcher)
+}
+
+func TestMonitorRestartedOnCollectionTimeout(t *testing.T) {
+	t.Cleanup(cleanUp)
+	cfg := newConfig("valid", "cpu", 1)
+	cfg.CollectionTimeoutSeconds = 5
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+	require.NotNil(t, receiver.collectionWatchdog)
+	assert.Equal(t, 6*time.Second, receiver.collectionWatchdog.timeout)
+
+	receiver.Lock()
+	original := receiver.monitor
+	receiver.Unlock()
+
+	receiver.onCollectionTimeout(false)
+
+	receiver.Lock()
+	assert.NotSame(t, original, receiver.monitor)
+	receiver.Unlock()
+
+	require.NoError(t, receiver.Shutdown(context.Background()))
+	assert.Nil(t, receiver.collectionWatchdog)
+}
+
+func TestExtraDimensionsFromEnv(t *testing.T) {
+	t.Cleanup(cleanUp)
+	t.Setenv("SMARTAGENT_TEST_POD_NAME", "some-pod")
+	t.Setenv("SMARTAGENT_TEST_REQUIRED", "from-env")
+
+	cfg := newConfig("valid", "cpu", 1)
+	cfg.ExtraDimensionsFromEnv = map[string]string{
+		"k8s_pod_name":       "SMARTAGENT_TEST_POD_NAME",
+		"required_dimension": "SMARTAGENT_TEST_REQUIRED",
+		"unset":              "SMARTAGENT_TEST_UNSET",
+	}
+	receiver := NewReceiver(newReceiverCreateSettings(), cfg)
+	require.NoError(t, receiver.Start(context.Background(), componenttest.NewNopHost()))
+
+	monitor, isMonitor := receiver.monitor.(*cpu.Monitor)
+	require.True(t, isMonitor)
+	output, isOutput := monitor.Output.(*Output)
+	require.True(t, isOutput)
+
+	assert.Equal(t, "some-pod", output.extraDimensions["k8s_pod_name"])
+	// the monitor's own extraDimensions take precedence
+	assert.Equal(t, "required_value", output.extraDimensions["required_dimension"])
+	assert.NotContains(t, output.extraDimensions, "unset")
+
+	require.NoError(t, receiver.Shutdown(context.Background()))
+}
+
 func TestOutOfOrderShutdownInvocations(t *testing.T) {
 	t.Cleanup(cleanUp)
 	cfg := newConfig("valid", "cpu", 1)
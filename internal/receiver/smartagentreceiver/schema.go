@@ -0,0 +1,207 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+)
+
+// jsonSchemaDraft is the JSON Schema version of the monitor config schemas.
+const jsonSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// maxSchemaDepth bounds the nesting of described config options, guarding against recursive types.
+const maxSchemaDepth = 10
+
+// MonitorTypes returns the sorted types of the registered Smart Agent monitors.
+func MonitorTypes() []string {
+	monitorTypes := make([]string, 0, len(monitors.ConfigTemplates))
+	for monitorType := range monitors.ConfigTemplates {
+		monitorTypes = append(monitorTypes, monitorType)
+	}
+	sort.Strings(monitorTypes)
+	return monitorTypes
+}
+
+// MonitorConfigSchema returns the JSON schema of the config of the monitor type, derived from the yaml,
+// default, and validate tags of the config struct the monitor registered with the Smart Agent.  The
+// receiver's own options are accepted alongside the monitor's, so only nested objects reject unknown
+// options.
+func MonitorConfigSchema(monitorType string) (map[string]any, error) {
+	customMonitorConfig, ok := monitors.ConfigTemplates[monitorType]
+	if !ok {
+		return nil, fmt.Errorf("no known monitor type %q", monitorType)
+	}
+	schema := objectSchema(reflect.TypeOf(customMonitorConfig), 0)
+	schema["$schema"] = jsonSchemaDraft
+	schema["title"] = fmt.Sprintf("%s monitor config", monitorType)
+	delete(schema, "additionalProperties")
+
+	properties := schema["properties"].(map[string]any)
+	if typeSchema, ok := properties["type"].(map[string]any); ok {
+		typeSchema["const"] = monitorType
+	}
+	if groups := monitorGroups(monitorType); len(groups) != 0 {
+		if groupsSchema, ok := properties["extraGroups"].(map[string]any); ok {
+			groupsSchema["items"] = map[string]any{"type": "string", "enum": groups}
+		}
+	}
+	if acceptsEndpoints, err := monitorAcceptsEndpoints(customMonitorConfig); err == nil && acceptsEndpoints {
+		requireHostAndPortOrEndpoint(schema)
+	}
+	return schema, nil
+}
+
+// requireHostAndPortOrEndpoint replaces the required host and port options of the monitor schema with the
+// alternative of the receiver's endpoint option, which sets them.
+func requireHostAndPortOrEndpoint(schema map[string]any) {
+	required, _ := schema["required"].([]string)
+	var hostAndPort, others []string
+	for _, name := range required {
+		if name == "host" || name == "port" {
+			hostAndPort = append(hostAndPort, name)
+		} else {
+			others = append(others, name)
+		}
+	}
+	if len(hostAndPort) == 0 {
+		return
+	}
+
+	schema["properties"].(map[string]any)["endpoint"] = map[string]any{
+		"type":        "string",
+		"description": "The host:port of the monitored service, setting the host and port options",
+	}
+	schema["anyOf"] = []any{
+		map[string]any{"required": hostAndPort},
+		map[string]any{"required": []string{"endpoint"}},
+	}
+	if len(others) == 0 {
+		delete(schema, "required")
+	} else {
+		schema["required"] = others
+	}
+}
+
+// monitorGroups returns the sorted metric groups of the monitor type's metadata.
+func monitorGroups(monitorType string) []string {
+	metadata, ok := monitors.MonitorMetadatas[monitorType]
+	if !ok || metadata == nil {
+		return nil
+	}
+	set := map[string]bool{}
+	for _, metric := range metadata.Metrics {
+		if metric.Group != "" {
+			set[metric.Group] = true
+		}
+	}
+	groups := make([]string, 0, len(set))
+	for group := range set {
+		groups = append(groups, group)
+	}
+	sort.Strings(groups)
+	return groups
+}
+
+// objectSchema describes the options of the struct type, flattening inlined ones.
+func objectSchema(t reflect.Type, depth int) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	properties := map[string]any{}
+	var required []string
+	addOptions(t, depth, properties, &required)
+
+	schema := map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) != 0 {
+		sort.Strings(required)
+		schema["required"] = required
+	}
+	return schema
+}
+
+func addOptions(t reflect.Type, depth int, properties map[string]any, required *[]string) {
+	for name, field := range monitorConfigFields(t) {
+		option := valueSchema(field.Type, depth)
+		if def, ok := field.Tag.Lookup("default"); ok {
+			option["default"] = defaultValue(field.Type, def)
+		}
+		if strings.Contains(field.Tag.Get("validate"), "required") {
+			*required = append(*required, name)
+		}
+		properties[name] = option
+	}
+}
+
+// valueSchema describes the values of the type as unmarshaled from yaml.
+func valueSchema(t reflect.Type, depth int) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	switch {
+	case t == durationType || (t.Kind() == reflect.Int64 && reflect.PointerTo(t).Implements(yamlUnmarshalerType)):
+		return map[string]any{"type": "string", "description": "A duration, like 10s"}
+	case t.Kind() != reflect.Struct && reflect.PointerTo(t).Implements(yamlUnmarshalerType):
+		// unmarshaled by the type itself, from values it alone knows
+		return map[string]any{}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": valueSchema(t.Elem(), depth+1)}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": valueSchema(t.Elem(), depth+1)}
+	case reflect.Struct:
+		if depth >= maxSchemaDepth {
+			return map[string]any{"type": "object"}
+		}
+		return objectSchema(t, depth+1)
+	}
+	return map[string]any{}
+}
+
+// defaultValue returns the value of the default tag of a field of the type, as set by the defaults
+// package: a JSON document for lists, maps, and objects, or the scalar value.
+func defaultValue(t reflect.Type, def string) any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.String || t == durationType {
+		return def
+	}
+	var value any
+	if err := json.Unmarshal([]byte(def), &value); err != nil {
+		return def
+	}
+	return value
+}
@@ -0,0 +1,139 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/signalfx/signalfx-agent/pkg/utils/timeutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMonitorTypes(t *testing.T) {
+	monitorTypes := MonitorTypes()
+	assert.Contains(t, monitorTypes, "collectd/consul")
+	assert.Contains(t, monitorTypes, "oracledb")
+	for i := 1; i < len(monitorTypes); i++ {
+		assert.Less(t, monitorTypes[i-1], monitorTypes[i])
+	}
+}
+
+func TestMonitorConfigSchema(t *testing.T) {
+	schema, err := MonitorConfigSchema("collectd/consul")
+	require.NoError(t, err)
+	assert.Equal(t, jsonSchemaDraft, schema["$schema"])
+	assert.Equal(t, "collectd/consul monitor config", schema["title"])
+	assert.Equal(t, "object", schema["type"])
+	// the receiver's own options are accepted too
+	assert.NotContains(t, schema, "additionalProperties")
+	// the required host and port can be set by the receiver's endpoint instead
+	assert.NotContains(t, schema, "required")
+	assert.Equal(t, []any{
+		map[string]any{"required": []string{"host", "port"}},
+		map[string]any{"required": []string{"endpoint"}},
+	}, schema["anyOf"])
+
+	properties := schema["properties"].(map[string]any)
+	assert.Equal(t, map[string]any{"type": "string", "const": "collectd/consul"}, properties["type"])
+	assert.Equal(t, map[string]any{"type": "string"}, properties["host"])
+	assert.Equal(t, "string", properties["endpoint"].(map[string]any)["type"])
+	assert.Equal(t, map[string]any{"type": "string", "default": "0.0.0.0"}, properties["telemetryHost"])
+	assert.Equal(t, map[string]any{"type": "integer", "default": float64(8125)}, properties["telemetryPort"])
+	assert.Equal(t, map[string]any{"type": "integer"}, properties["intervalSeconds"])
+	assert.Equal(t, map[string]any{"type": "object", "additionalProperties": map[string]any{"type": "string"}}, properties["extraDimensions"])
+
+	_, err = MonitorConfigSchema("notamonitor")
+	require.EqualError(t, err, `no known monitor type "notamonitor"`)
+}
+
+type testSchemaNested struct {
+	Enabled bool   `yaml:"enabled" default:"true"`
+	Name    string `yaml:"name" validate:"required"`
+}
+
+// TestSchemaInlined is exported since yaml only inlines exported embedded structs.
+type TestSchemaInlined struct {
+	Host string `yaml:"host" validate:"required"`
+}
+
+type testSchemaConfig struct {
+	TestSchemaInlined `yaml:",inline"`
+	Nested            testSchemaNested    `yaml:"nested"`
+	NestedList        []*testSchemaNested `yaml:"nestedList"`
+	Timeout           timeutil.Duration   `yaml:"timeout" default:"5s"`
+	Interval          time.Duration       `yaml:"interval"`
+	Ratio             float64             `yaml:"ratio" default:"0.5"`
+	Labels            map[string]string   `yaml:"labels" default:"{\"a\": \"b\"}"`
+	Ports             []uint16            `yaml:"ports" default:"[80, 443]"`
+	Raw               map[string]any      `yaml:"raw"`
+	Untagged          int
+	Ignored           string `yaml:"-"`
+	unexported        string
+}
+
+func TestRequireHostAndPortOrEndpoint(t *testing.T) {
+	schema := map[string]any{
+		"properties": map[string]any{},
+		"required":   []string{"host", "password", "port"},
+	}
+	requireHostAndPortOrEndpoint(schema)
+	assert.Equal(t, []string{"password"}, schema["required"])
+	assert.Equal(t, []any{
+		map[string]any{"required": []string{"host", "port"}},
+		map[string]any{"required": []string{"endpoint"}},
+	}, schema["anyOf"])
+	assert.Contains(t, schema["properties"], "endpoint")
+
+	schema = map[string]any{"properties": map[string]any{}, "required": []string{"password"}}
+	requireHostAndPortOrEndpoint(schema)
+	assert.Equal(t, map[string]any{"properties": map[string]any{}, "required": []string{"password"}}, schema)
+}
+
+func TestObjectSchema(t *testing.T) {
+	nested := map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"enabled": map[string]any{"type": "boolean", "default": true},
+			"name":    map[string]any{"type": "string"},
+		},
+		"additionalProperties": false,
+		"required":             []string{"name"},
+	}
+	duration := map[string]any{"type": "string", "description": "A duration, like 10s"}
+	assert.Equal(t, map[string]any{
+		"type": "object",
+		"properties": map[string]any{
+			"host":       map[string]any{"type": "string"},
+			"nested":     nested,
+			"nestedList": map[string]any{"type": "array", "items": nested},
+			"timeout":    map[string]any{"type": "string", "description": "A duration, like 10s", "default": "5s"},
+			"interval":   duration,
+			"ratio":      map[string]any{"type": "number", "default": 0.5},
+			"labels": map[string]any{
+				"type":                 "object",
+				"additionalProperties": map[string]any{"type": "string"},
+				"default":              map[string]any{"a": "b"},
+			},
+			"ports":    map[string]any{"type": "array", "items": map[string]any{"type": "integer"}, "default": []any{float64(80), float64(443)}},
+			"raw":      map[string]any{"type": "object", "additionalProperties": map[string]any{}},
+			"untagged": map[string]any{"type": "integer"},
+		},
+		"additionalProperties": false,
+		"required":             []string{"host"},
+	}, objectSchema(reflect.TypeOf(&testSchemaConfig{}), 0))
+}
@@ -0,0 +1,365 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+const serviceAccountNamespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+var (
+	// secretRewatchInterval is how long to wait before reestablishing a closed or failed secret watch.
+	secretRewatchInterval = 5 * time.Second
+
+	// newKubernetesClient provides the client used to resolve secret references, by default the in-cluster
+	// one authenticated with the collector pod's service account.
+	newKubernetesClient = func() (kubernetes.Interface, error) {
+		restConfig, err := rest.InClusterConfig()
+		if err != nil {
+			return nil, err
+		}
+		return kubernetes.NewForConfig(restConfig)
+	}
+
+	// serviceAccountNamespace provides the namespace of secrets whose references don't specify one.
+	serviceAccountNamespace = func() (string, error) {
+		namespace, err := os.ReadFile(serviceAccountNamespaceFile)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(namespace)), nil
+	}
+)
+
+// SecretKeyRef references the value of a Kubernetes secret key, like the equivalent container env var source.
+// Without a namespace, the secret is retrieved from the collector pod's own namespace.
+type SecretKeyRef struct {
+	Name      string `mapstructure:"name"`
+	Key       string `mapstructure:"key"`
+	Namespace string `mapstructure:"namespace"`
+}
+
+func (ref SecretKeyRef) secretID() string {
+	return ref.Namespace + "/" + ref.Name
+}
+
+// getSecretKeyRefsFromAllSettings removes the monitor options provided as `valueFrom: {secretKeyRef: ...}` blocks,
+// returning their references.  Only string options the monitor tags as secrets (with `neverLog`) are supported.
+func getSecretKeyRefsFromAllSettings(allSettings map[string]any, monitorConfigType reflect.Type) (map[string]SecretKeyRef, error) {
+	var secretOptions map[string]reflect.StructField
+	refs := map[string]SecretKeyRef{}
+	for option, value := range allSettings {
+		valueAsMap, isMap := value.(map[string]any)
+		if !isMap {
+			continue
+		}
+		valueFrom, ok := valueAsMap["valueFrom"]
+		if !ok {
+			continue
+		}
+		if len(valueAsMap) != 1 {
+			return nil, fmt.Errorf("%s: valueFrom can't be combined with other settings", option)
+		}
+		if secretOptions == nil {
+			secretOptions = monitorSecretOptions(monitorConfigType)
+		}
+		if _, isSecret := secretOptions[option]; !isSecret {
+			return nil, fmt.Errorf("%s: valueFrom is only supported by secret string options of this monitor type", option)
+		}
+		ref, err := parseSecretKeyRef(valueFrom)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", option, err)
+		}
+		refs[option] = ref
+		delete(allSettings, option)
+	}
+	if len(refs) == 0 {
+		return nil, nil
+	}
+	return refs, nil
+}
+
+func parseSecretKeyRef(valueFrom any) (SecretKeyRef, error) {
+	valueFromAsMap, ok := valueFrom.(map[string]any)
+	if !ok || len(valueFromAsMap) != 1 {
+		return SecretKeyRef{}, fmt.Errorf("valueFrom must only contain a secretKeyRef")
+	}
+	refAsMap, ok := valueFromAsMap["secretKeyRef"].(map[string]any)
+	if !ok {
+		return SecretKeyRef{}, fmt.Errorf("valueFrom must only contain a secretKeyRef")
+	}
+	var ref SecretKeyRef
+	for key, value := range refAsMap {
+		valueAsString, isString := value.(string)
+		if !isString {
+			return SecretKeyRef{}, fmt.Errorf("secretKeyRef %q must be a string", key)
+		}
+		switch key {
+		case "name":
+			ref.Name = valueAsString
+		case "key":
+			ref.Key = valueAsString
+		case "namespace":
+			ref.Namespace = valueAsString
+		default:
+			return SecretKeyRef{}, fmt.Errorf("unsupported secretKeyRef setting %q", key)
+		}
+	}
+	if ref.Name == "" || ref.Key == "" {
+		return SecretKeyRef{}, fmt.Errorf("secretKeyRef must specify a name and key")
+	}
+	return ref, nil
+}
+
+// monitorSecretOptions returns the fields of the provided monitor config type's string options tagged with
+// `neverLog`, including those of its inlined structs, keyed by their yaml option names.
+func monitorSecretOptions(monitorConfigType reflect.Type) map[string]reflect.StructField {
+	options := map[string]reflect.StructField{}
+	for option, field := range monitorConfigFields(monitorConfigType) {
+		if field.Type.Kind() == reflect.String && field.Tag.Get("neverLog") != "" {
+			options[option] = field
+		}
+	}
+	return options
+}
+
+// withDefaultSecretNamespaces returns a copy of the references with the collector pod's namespace set
+// for those that don't specify one.
+func withDefaultSecretNamespaces(refs map[string]SecretKeyRef) (map[string]SecretKeyRef, error) {
+	var namespace string
+	withNamespaces := make(map[string]SecretKeyRef, len(refs))
+	for option, ref := range refs {
+		if ref.Namespace == "" {
+			if namespace == "" {
+				var err error
+				if namespace, err = serviceAccountNamespace(); err != nil {
+					return nil, fmt.Errorf("failed determining the namespace of the secret referenced by %s: %w", option, err)
+				}
+			}
+			ref.Namespace = namespace
+		}
+		withNamespaces[option] = ref
+	}
+	return withNamespaces, nil
+}
+
+// resolveSecretKeyRefs retrieves the referenced secret values, keyed by monitor option.
+func resolveSecretKeyRefs(ctx context.Context, client kubernetes.Interface, refs map[string]SecretKeyRef) (map[string]string, error) {
+	secrets := map[string]*corev1.Secret{}
+	values := make(map[string]string, len(refs))
+	for option, ref := range refs {
+		secret, ok := secrets[ref.secretID()]
+		if !ok {
+			var err error
+			if secret, err = client.CoreV1().Secrets(ref.Namespace).Get(ctx, ref.Name, metav1.GetOptions{}); err != nil {
+				return nil, fmt.Errorf("failed retrieving secret %q referenced by %s: %w", ref.secretID(), option, err)
+			}
+			secrets[ref.secretID()] = secret
+		}
+		value, ok := secretValue(secret, ref.Key)
+		if !ok {
+			return nil, fmt.Errorf("secret %q referenced by %s has no key %q", ref.secretID(), option, ref.Key)
+		}
+		values[option] = value
+	}
+	return values, nil
+}
+
+func secretValue(secret *corev1.Secret, key string) (string, bool) {
+	if value, ok := secret.Data[key]; ok {
+		return string(value), true
+	}
+	value, ok := secret.StringData[key]
+	return value, ok
+}
+
+// applySecretValues sets the resolved secret values to their respective monitor config fields.
+func applySecretValues(monitorConfig any, values map[string]string) error {
+	fields := monitorSecretOptions(reflect.TypeOf(monitorConfig))
+	config := reflect.Indirect(reflect.ValueOf(monitorConfig))
+	for option, value := range values {
+		field, ok := fields[option]
+		if !ok {
+			return fmt.Errorf("%s isn't a secret option of the monitor", option)
+		}
+		fieldValue, err := config.FieldByIndexErr(field.Index)
+		if err != nil || !fieldValue.CanSet() {
+			return fmt.Errorf("failed setting %s: the monitor config field isn't settable", option)
+		}
+		fieldValue.SetString(value)
+	}
+	return nil
+}
+
+// withSecretValues returns a copy of the monitor config with the resolved secret values applied, or the config
+// itself without any.  The provided config, which a running monitor may be reading, isn't changed.
+func withSecretValues(monitorConfig saconfig.MonitorCustomConfig, values map[string]string) (saconfig.MonitorCustomConfig, error) {
+	if len(values) == 0 {
+		return monitorConfig, nil
+	}
+	original := reflect.ValueOf(monitorConfig)
+	if original.Kind() != reflect.Pointer || original.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("monitor config of type %T doesn't support secrets", monitorConfig)
+	}
+	// a shallow copy, as only its string fields are set
+	configured := reflect.New(original.Elem().Type())
+	configured.Elem().Set(original.Elem())
+	configuredConfig := configured.Interface().(saconfig.MonitorCustomConfig)
+	if err := applySecretValues(configuredConfig, values); err != nil {
+		return nil, err
+	}
+	return configuredConfig, nil
+}
+
+// secretWatcher invokes a callback with the updated values of all references when the content of any
+// referenced key changes.  Deleted secrets and keys retain their last values.
+type secretWatcher struct {
+	client   kubernetes.Interface
+	refs     map[string]SecretKeyRef
+	onChange func(values map[string]string)
+	logger   *zap.Logger
+	values   map[string]string
+	cancel   context.CancelFunc
+	wg       sync.WaitGroup
+	lock     sync.Mutex
+}
+
+func newSecretWatcher(
+	client kubernetes.Interface, refs map[string]SecretKeyRef, values map[string]string,
+	onChange func(values map[string]string), logger *zap.Logger,
+) *secretWatcher {
+	ctx, cancel := context.WithCancel(context.Background())
+	sw := &secretWatcher{
+		client:   client,
+		refs:     refs,
+		onChange: onChange,
+		logger:   logger,
+		values:   map[string]string{},
+		cancel:   cancel,
+	}
+	for option, value := range values {
+		sw.values[option] = value
+	}
+
+	secretIDs := map[string]SecretKeyRef{}
+	for _, ref := range refs {
+		secretIDs[ref.secretID()] = ref
+	}
+	ids := make([]string, 0, len(secretIDs))
+	for id := range secretIDs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		sw.wg.Add(1)
+		go sw.watch(ctx, secretIDs[id])
+	}
+	return sw
+}
+
+// watch follows the events of the referenced secret until the context is canceled, reestablishing
+// the watch whenever it fails or is closed by the API server.
+func (sw *secretWatcher) watch(ctx context.Context, ref SecretKeyRef) {
+	defer sw.wg.Done()
+	for {
+		watcher, err := sw.client.CoreV1().Secrets(ref.Namespace).Watch(ctx, metav1.ListOptions{
+			FieldSelector: fields.OneTermEqualSelector("metadata.name", ref.Name).String(),
+		})
+		if err != nil {
+			sw.logger.Warn("failed watching secret", zap.String("secret", ref.secretID()), zap.Error(err))
+		} else {
+			sw.handleEvents(ctx, watcher)
+			watcher.Stop()
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(secretRewatchInterval):
+		}
+	}
+}
+
+func (sw *secretWatcher) handleEvents(ctx context.Context, watcher watch.Interface) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case event, ok := <-watcher.ResultChan():
+			if !ok {
+				return
+			}
+			secret, isSecret := event.Object.(*corev1.Secret)
+			if !isSecret || (event.Type != watch.Added && event.Type != watch.Modified) {
+				continue
+			}
+			sw.updateValues(secret)
+		}
+	}
+}
+
+// updateValues records the values of the references to the provided secret, invoking the callback with
+// a copy of all current values if any have changed.  Callbacks are serialized so none observe stale values.
+func (sw *secretWatcher) updateValues(secret *corev1.Secret) {
+	sw.lock.Lock()
+	defer sw.lock.Unlock()
+
+	var changed bool
+	for option, ref := range sw.refs {
+		if ref.Namespace != secret.Namespace || ref.Name != secret.Name {
+			continue
+		}
+		value, ok := secretValue(secret, ref.Key)
+		if !ok {
+			sw.logger.Warn("referenced secret key not found, retaining its last value",
+				zap.String("secret", ref.secretID()), zap.String("key", ref.Key))
+			continue
+		}
+		if value != sw.values[option] {
+			sw.logger.Info("secret modified", zap.String("secret", ref.secretID()), zap.String("key", ref.Key))
+			sw.values[option] = value
+			changed = true
+		}
+	}
+	if !changed {
+		return
+	}
+	values := make(map[string]string, len(sw.values))
+	for option, value := range sw.values {
+		values[option] = value
+	}
+	sw.onChange(values)
+}
+
+func (sw *secretWatcher) Shutdown() {
+	sw.cancel()
+	sw.wg.Wait()
+}
@@ -0,0 +1,243 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+type secretTestCredentials struct {
+	Username string `yaml:"username"`
+	Password string `yaml:"password" neverLog:"true"`
+}
+
+type secretTestMonitorConfig struct {
+	secretTestCredentials `yaml:",inline"`
+	Host                  string            `yaml:"host"`
+	Token                 string            `neverLog:"true"`
+	Headers               map[string]string `yaml:"headers" neverLog:"true"`
+	Ignored               string            `yaml:"-" neverLog:"true"`
+}
+
+func secretKeyRefSetting(ref map[string]any) map[string]any {
+	return map[string]any{"valueFrom": map[string]any{"secretKeyRef": ref}}
+}
+
+type secretTestCustomConfig struct {
+	saconfig.MonitorConfig `yaml:",inline"`
+	Password               string `yaml:"password" neverLog:"true"`
+}
+
+func TestMonitorSecretOptions(t *testing.T) {
+	fieldNames := map[string]string{}
+	for option, field := range monitorSecretOptions(reflect.TypeOf(&secretTestMonitorConfig{})) {
+		fieldNames[option] = field.Name
+	}
+	assert.Equal(t, map[string]string{"password": "Password", "token": "Token"}, fieldNames)
+}
+
+func TestGetSecretKeyRefsFromAllSettings(t *testing.T) {
+	monitorConfigType := reflect.TypeOf(secretTestMonitorConfig{})
+	for _, tt := range []struct {
+		name         string
+		allSettings  map[string]any
+		expected     map[string]SecretKeyRef
+		expectedRest map[string]any
+		expectedErr  string
+	}{
+		{
+			name:         "no references",
+			allSettings:  map[string]any{"host": "localhost", "password": "plaintext"},
+			expectedRest: map[string]any{"host": "localhost", "password": "plaintext"},
+		},
+		{
+			name: "references",
+			allSettings: map[string]any{
+				"host":     "localhost",
+				"password": secretKeyRefSetting(map[string]any{"name": "db", "key": "password"}),
+				"token":    secretKeyRefSetting(map[string]any{"name": "api", "key": "token", "namespace": "monitoring"}),
+			},
+			expected: map[string]SecretKeyRef{
+				"password": {Name: "db", Key: "password"},
+				"token":    {Name: "api", Key: "token", Namespace: "monitoring"},
+			},
+			expectedRest: map[string]any{"host": "localhost"},
+		},
+		{
+			name:        "non secret option",
+			allSettings: map[string]any{"host": secretKeyRefSetting(map[string]any{"name": "db", "key": "host"})},
+			expectedErr: "host: valueFrom is only supported by secret string options of this monitor type",
+		},
+		{
+			name: "combined settings",
+			allSettings: map[string]any{"password": map[string]any{
+				"valueFrom": map[string]any{"secretKeyRef": map[string]any{"name": "db", "key": "password"}},
+				"other":     true,
+			}},
+			expectedErr: "password: valueFrom can't be combined with other settings",
+		},
+		{
+			name:        "missing key",
+			allSettings: map[string]any{"password": secretKeyRefSetting(map[string]any{"name": "db"})},
+			expectedErr: "password: secretKeyRef must specify a name and key",
+		},
+		{
+			name:        "unsupported setting",
+			allSettings: map[string]any{"password": secretKeyRefSetting(map[string]any{"name": "db", "key": "password", "optional": "true"})},
+			expectedErr: `password: unsupported secretKeyRef setting "optional"`,
+		},
+		{
+			name:        "unsupported source",
+			allSettings: map[string]any{"password": map[string]any{"valueFrom": map[string]any{"configMapKeyRef": map[string]any{}}}},
+			expectedErr: "password: valueFrom must only contain a secretKeyRef",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			refs, err := getSecretKeyRefsFromAllSettings(tt.allSettings, monitorConfigType)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, refs)
+			assert.Equal(t, tt.expectedRest, tt.allSettings)
+		})
+	}
+}
+
+func TestWithDefaultSecretNamespaces(t *testing.T) {
+	previous := serviceAccountNamespace
+	serviceAccountNamespace = func() (string, error) { return "collector", nil }
+	t.Cleanup(func() { serviceAccountNamespace = previous })
+
+	refs := map[string]SecretKeyRef{
+		"password": {Name: "db", Key: "password"},
+		"token":    {Name: "api", Key: "token", Namespace: "monitoring"},
+	}
+	withNamespaces, err := withDefaultSecretNamespaces(refs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]SecretKeyRef{
+		"password": {Name: "db", Key: "password", Namespace: "collector"},
+		"token":    {Name: "api", Key: "token", Namespace: "monitoring"},
+	}, withNamespaces)
+	// the provided references aren't modified
+	assert.Empty(t, refs["password"].Namespace)
+}
+
+func TestResolveAndApplySecretKeyRefs(t *testing.T) {
+	client := fake.NewSimpleClientset(&corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "collector"},
+		Data:       map[string][]byte{"password": []byte("hunter2")},
+	})
+
+	refs := map[string]SecretKeyRef{"password": {Name: "db", Key: "password", Namespace: "collector"}}
+	values, err := resolveSecretKeyRefs(context.Background(), client, refs)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"password": "hunter2"}, values)
+
+	monitorConfig := &secretTestMonitorConfig{Host: "localhost"}
+	require.NoError(t, applySecretValues(monitorConfig, values))
+	assert.Equal(t, "hunter2", monitorConfig.Password)
+	assert.Equal(t, "localhost", monitorConfig.Host)
+
+	require.EqualError(t, applySecretValues(monitorConfig, map[string]string{"host": "other"}), "host isn't a secret option of the monitor")
+
+	_, err = resolveSecretKeyRefs(context.Background(), client, map[string]SecretKeyRef{
+		"password": {Name: "db", Key: "missing", Namespace: "collector"},
+	})
+	require.EqualError(t, err, `secret "collector/db" referenced by password has no key "missing"`)
+
+	_, err = resolveSecretKeyRefs(context.Background(), client, map[string]SecretKeyRef{
+		"password": {Name: "missing", Key: "password", Namespace: "collector"},
+	})
+	require.ErrorContains(t, err, `failed retrieving secret "collector/missing" referenced by password`)
+}
+
+func TestWithSecretValues(t *testing.T) {
+	monitorConfig := &secretTestCustomConfig{MonitorConfig: saconfig.MonitorConfig{Type: "test"}, Password: "unresolved"}
+
+	unchanged, err := withSecretValues(monitorConfig, nil)
+	require.NoError(t, err)
+	assert.Same(t, monitorConfig, unchanged)
+
+	configured, err := withSecretValues(monitorConfig, map[string]string{"password": "hunter2"})
+	require.NoError(t, err)
+	require.IsType(t, &secretTestCustomConfig{}, configured)
+	assert.Equal(t, "hunter2", configured.(*secretTestCustomConfig).Password)
+	assert.Equal(t, "test", configured.MonitorConfigCore().Type)
+	// the running monitor's config isn't changed
+	assert.Equal(t, "unresolved", monitorConfig.Password)
+
+	_, err = withSecretValues(monitorConfig, map[string]string{"type": "other"})
+	require.EqualError(t, err, "type isn't a secret option of the monitor")
+}
+
+func TestSecretWatcher(t *testing.T) {
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "collector"},
+		Data:       map[string][]byte{"password": []byte("original"), "other": []byte("value")},
+	}
+	client := fake.NewSimpleClientset(secret)
+	refs := map[string]SecretKeyRef{"password": {Name: "db", Key: "password", Namespace: "collector"}}
+
+	changes := make(chan map[string]string, 10)
+	watcher := newSecretWatcher(client, refs, map[string]string{"password": "original"}, func(values map[string]string) {
+		changes <- values
+	}, zap.NewNop())
+	defer watcher.Shutdown()
+
+	update := func(data map[string][]byte) {
+		// the fake watch only receives events after it has been established
+		require.Eventually(t, func() bool {
+			updated := secret.DeepCopy()
+			updated.Data = data
+			_, err := client.CoreV1().Secrets("collector").Update(context.Background(), updated, metav1.UpdateOptions{})
+			require.NoError(t, err)
+			select {
+			case values := <-changes:
+				changes <- values
+				return true
+			case <-time.After(50 * time.Millisecond):
+				return false
+			}
+		}, 5*time.Second, 10*time.Millisecond)
+	}
+
+	update(map[string][]byte{"password": []byte("rotated"), "other": []byte("value")})
+	assert.Equal(t, map[string]string{"password": "rotated"}, <-changes)
+
+	// unreferenced key changes aren't a change
+	_, err := client.CoreV1().Secrets("collector").Update(context.Background(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "db", Namespace: "collector"},
+		Data:       map[string][]byte{"password": []byte("rotated"), "other": []byte("changed")},
+	}, metav1.UpdateOptions{})
+	require.NoError(t, err)
+	select {
+	case values := <-changes:
+		t.Fatalf("unexpected change for unreferenced key: %v", values)
+	case <-time.After(100 * time.Millisecond):
+	}
+}
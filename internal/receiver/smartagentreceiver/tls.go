@@ -0,0 +1,138 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"fmt"
+	"reflect"
+	"time"
+
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/confmap"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/filewatcher"
+)
+
+// tlsReloadDebounce is how long to wait for the bursts of events typical of certificate rotation
+// to settle before checking whether the content of a watched tls file has changed.
+var tlsReloadDebounce = time.Second
+
+// tlsOption is a configtls client setting and the Smart Agent monitor config option it's translated to.
+type tlsOption struct {
+	value         any
+	key           string
+	monitorOption string
+}
+
+// getTLSSettingFromAllSettings unmarshals the standard collector tls block, if provided.
+func getTLSSettingFromAllSettings(allSettings map[string]any) (*configtls.TLSClientSetting, error) {
+	value, ok := allSettings["tls"]
+	if !ok {
+		return nil, nil
+	}
+	delete(allSettings, "tls")
+	valueAsMap, isMap := value.(map[string]any)
+	if !isMap {
+		if value == nil {
+			valueAsMap = map[string]any{}
+		} else {
+			return nil, fmt.Errorf("tls must be a map of tls client settings")
+		}
+	}
+	setting := &configtls.TLSClientSetting{}
+	if err := confmap.NewFromStringMap(valueAsMap).UnmarshalExact(setting); err != nil {
+		return nil, fmt.Errorf("failed parsing tls settings: %w", err)
+	}
+	return setting, nil
+}
+
+// applyTLSSetting translates the tls client settings to their equivalent options of the provided monitor
+// config type.  Settings the monitor doesn't support and ones conflicting with the monitor's own options
+// are rejected instead of being silently ignored.  Without a ca_file, monitors use the system cert pool.
+func applyTLSSetting(setting configtls.TLSClientSetting, monitorConfigType reflect.Type, allSettings map[string]any) error {
+	if setting.MinVersion != "" {
+		return fmt.Errorf("the tls \"min_version\" setting isn't supported by Smart Agent monitors")
+	}
+	if setting.MaxVersion != "" {
+		return fmt.Errorf("the tls \"max_version\" setting isn't supported by Smart Agent monitors")
+	}
+
+	monitorOptions := monitorConfigOptions(monitorConfigType)
+	options := []tlsOption{
+		{key: "ca_file", monitorOption: "caCertPath", value: setting.CAFile},
+		{key: "cert_file", monitorOption: "clientCertPath", value: setting.CertFile},
+		{key: "key_file", monitorOption: "clientKeyPath", value: setting.KeyFile},
+		{key: "server_name_override", monitorOption: "sniServerName", value: setting.ServerName},
+		{key: "insecure_skip_verify", monitorOption: "skipVerify", value: setting.InsecureSkipVerify},
+	}
+
+	var toApply []tlsOption
+	for _, option := range options {
+		if reflect.ValueOf(option.value).IsZero() {
+			continue
+		}
+		if !monitorOptions[option.monitorOption] {
+			return fmt.Errorf("the tls %q setting isn't supported by this monitor type", option.key)
+		}
+		toApply = append(toApply, option)
+	}
+	if monitorOptions["useHTTPS"] {
+		// TLS is used unless the block is explicitly insecure, like for collector components.
+		toApply = append(toApply, tlsOption{key: "insecure", monitorOption: "useHTTPS", value: !setting.Insecure})
+	}
+
+	for _, option := range toApply {
+		if existing, ok := allSettings[option.monitorOption]; ok && fmt.Sprintf("%v", existing) != fmt.Sprintf("%v", option.value) {
+			return fmt.Errorf("the tls %q setting conflicts with the monitor's %q option", option.key, option.monitorOption)
+		}
+		allSettings[option.monitorOption] = option.value
+	}
+	return nil
+}
+
+// tlsFiles returns the files referenced by the tls client settings.
+func tlsFiles(setting *configtls.TLSClientSetting) []string {
+	if setting == nil {
+		return nil
+	}
+	var files []string
+	for _, file := range []string{setting.CAFile, setting.CertFile, setting.KeyFile} {
+		if file != "" {
+			files = append(files, file)
+		}
+	}
+	return files
+}
+
+// watchTLSFiles invokes onChange when the content of any of the files changes.
+func watchTLSFiles(files []string, onChange func(), logger *zap.Logger) (*filewatcher.Watcher, error) {
+	watcher, err := filewatcher.New(tlsReloadDebounce, func(changed []string) {
+		logger.Info("tls files modified", zap.Strings("files", changed))
+		onChange()
+	}, func(err error) {
+		logger.Warn("error watching tls files", zap.Error(err))
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		if err = watcher.Add(file); err != nil {
+			_ = watcher.Close()
+			return nil, fmt.Errorf("failed watching tls file %q: %w", file, err)
+		}
+	}
+	return watcher, nil
+}
@@ -0,0 +1,193 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.uber.org/zap"
+)
+
+type tlsTestHTTPConfig struct {
+	UseHTTPS       bool   `yaml:"useHTTPS"`
+	SkipVerify     bool   `yaml:"skipVerify"`
+	CACertPath     string `yaml:"caCertPath"`
+	ClientCertPath string `yaml:"clientCertPath"`
+	ClientKeyPath  string `yaml:"clientKeyPath"`
+}
+
+type tlsTestMonitorConfig struct {
+	tlsTestHTTPConfig `yaml:",inline"`
+	Host              string `yaml:"host"`
+	Port              uint16
+	Ignored           string `yaml:"-"`
+}
+
+func TestMonitorConfigOptions(t *testing.T) {
+	assert.Equal(t, map[string]bool{
+		"useHTTPS": true, "skipVerify": true, "caCertPath": true, "clientCertPath": true,
+		"clientKeyPath": true, "host": true, "port": true,
+	}, monitorConfigOptions(reflect.TypeOf(&tlsTestMonitorConfig{})))
+}
+
+func TestApplyTLSSetting(t *testing.T) {
+	monitorConfigType := reflect.TypeOf(tlsTestMonitorConfig{})
+	for _, tt := range []struct {
+		name        string
+		setting     configtls.TLSClientSetting
+		allSettings map[string]any
+		expected    map[string]any
+		expectedErr string
+	}{
+		{
+			name:        "system cert pool",
+			setting:     configtls.TLSClientSetting{},
+			allSettings: map[string]any{},
+			expected:    map[string]any{"useHTTPS": true},
+		},
+		{
+			name: "all supported",
+			setting: configtls.TLSClientSetting{
+				TLSSetting: configtls.TLSSetting{
+					CAFile: "/ca.pem", CertFile: "/cert.pem", KeyFile: "/key.pem",
+				},
+				InsecureSkipVerify: true,
+			},
+			allSettings: map[string]any{"host": "localhost"},
+			expected: map[string]any{
+				"host": "localhost", "useHTTPS": true, "skipVerify": true,
+				"caCertPath": "/ca.pem", "clientCertPath": "/cert.pem", "clientKeyPath": "/key.pem",
+			},
+		},
+		{
+			name:        "insecure",
+			setting:     configtls.TLSClientSetting{Insecure: true},
+			allSettings: map[string]any{},
+			expected:    map[string]any{"useHTTPS": false},
+		},
+		{
+			name:        "matching monitor option",
+			setting:     configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{CAFile: "/ca.pem"}},
+			allSettings: map[string]any{"caCertPath": "/ca.pem", "useHTTPS": "true"},
+			expected:    map[string]any{"caCertPath": "/ca.pem", "useHTTPS": true},
+		},
+		{
+			name:        "conflicting monitor option",
+			setting:     configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{CAFile: "/ca.pem"}},
+			allSettings: map[string]any{"caCertPath": "/other.pem"},
+			expectedErr: `the tls "ca_file" setting conflicts with the monitor's "caCertPath" option`,
+		},
+		{
+			name:        "insecure conflicting with useHTTPS",
+			setting:     configtls.TLSClientSetting{Insecure: true},
+			allSettings: map[string]any{"useHTTPS": true},
+			expectedErr: `the tls "insecure" setting conflicts with the monitor's "useHTTPS" option`,
+		},
+		{
+			name:        "unsupported server name",
+			setting:     configtls.TLSClientSetting{ServerName: "etcd.local"},
+			allSettings: map[string]any{},
+			expectedErr: `the tls "server_name_override" setting isn't supported by this monitor type`,
+		},
+		{
+			name:        "unsupported min version",
+			setting:     configtls.TLSClientSetting{TLSSetting: configtls.TLSSetting{MinVersion: "1.2"}},
+			allSettings: map[string]any{},
+			expectedErr: `the tls "min_version" setting isn't supported by Smart Agent monitors`,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			err := applyTLSSetting(tt.setting, monitorConfigType, tt.allSettings)
+			if tt.expectedErr != "" {
+				require.EqualError(t, err, tt.expectedErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, tt.allSettings)
+		})
+	}
+}
+
+func TestGetTLSSettingFromAllSettings(t *testing.T) {
+	setting, err := getTLSSettingFromAllSettings(map[string]any{})
+	require.NoError(t, err)
+	assert.Nil(t, setting)
+
+	allSettings := map[string]any{"tls": map[string]any{"ca_file": "/ca.pem", "insecure_skip_verify": true}}
+	setting, err = getTLSSettingFromAllSettings(allSettings)
+	require.NoError(t, err)
+	assert.Equal(t, &configtls.TLSClientSetting{
+		TLSSetting:         configtls.TLSSetting{CAFile: "/ca.pem"},
+		InsecureSkipVerify: true,
+	}, setting)
+	assert.Empty(t, allSettings)
+
+	setting, err = getTLSSettingFromAllSettings(map[string]any{"tls": "/ca.pem"})
+	require.EqualError(t, err, "tls must be a map of tls client settings")
+	assert.Nil(t, setting)
+
+	setting, err = getTLSSettingFromAllSettings(map[string]any{"tls": map[string]any{"unknown": true}})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed parsing tls settings")
+	assert.Nil(t, setting)
+}
+
+func TestTLSFiles(t *testing.T) {
+	assert.Nil(t, tlsFiles(nil))
+	assert.Equal(t, []string{"/ca.pem", "/key.pem"}, tlsFiles(&configtls.TLSClientSetting{
+		TLSSetting: configtls.TLSSetting{CAFile: "/ca.pem", KeyFile: "/key.pem"},
+	}))
+}
+
+func TestWatchTLSFiles(t *testing.T) {
+	previous := tlsReloadDebounce
+	tlsReloadDebounce = 10 * time.Millisecond
+	t.Cleanup(func() { tlsReloadDebounce = previous })
+
+	dir := t.TempDir()
+	caFile := filepath.Join(dir, "ca.pem")
+	require.NoError(t, os.WriteFile(caFile, []byte("original"), 0600))
+
+	changes := make(chan struct{}, 10)
+	watcher, err := watchTLSFiles([]string{caFile}, func() { changes <- struct{}{} }, zap.NewNop())
+	require.NoError(t, err)
+
+	// unmodified content isn't a change
+	require.NoError(t, os.WriteFile(caFile, []byte("original"), 0600))
+	select {
+	case <-changes:
+		t.Fatal("unexpected change for unmodified content")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	// files replaced via rename remain watched
+	replacement := filepath.Join(dir, "ca.pem.tmp")
+	require.NoError(t, os.WriteFile(replacement, []byte("rotated"), 0600))
+	require.NoError(t, os.Rename(replacement, caFile))
+	select {
+	case <-changes:
+	case <-time.After(5 * time.Second):
+		t.Fatal("no change for rotated file")
+	}
+
+	require.NoError(t, watcher.Close())
+}
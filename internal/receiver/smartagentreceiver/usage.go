@@ -0,0 +1,94 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"os"
+	"sort"
+	"strconv"
+	"sync"
+
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// monitorUsageReportingDisabledEnvVar opts out of the monitor usage event when set to a true value.
+const monitorUsageReportingDisabledEnvVar = "SPLUNK_SMARTAGENT_MONITOR_USAGE_REPORTING_DISABLED"
+
+var monitorUsage = newMonitorUsageTracker()
+
+// monitorUsageTracker keeps count of the instantiated Smart Agent monitors by type
+// so operators can determine which ones remain to be migrated to native receivers.
+type monitorUsageTracker struct {
+	instances map[string]int
+	lock      sync.Mutex
+}
+
+func newMonitorUsageTracker() *monitorUsageTracker {
+	return &monitorUsageTracker{instances: map[string]int{}}
+}
+
+// record registers an instance of the monitor type and returns its instance count.
+func (t *monitorUsageTracker) record(monitorType string) int {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.instances[monitorType]++
+	return t.instances[monitorType]
+}
+
+// release unregisters an instance of the monitor type.
+func (t *monitorUsageTracker) release(monitorType string) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if t.instances[monitorType] <= 1 {
+		delete(t.instances, monitorType)
+		return
+	}
+	t.instances[monitorType]--
+}
+
+// inUse returns the sorted monitor types with at least one instance.
+func (t *monitorUsageTracker) inUse() []string {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	monitorTypes := make([]string, 0, len(t.instances))
+	for monitorType := range t.instances {
+		monitorTypes = append(monitorTypes, monitorType)
+	}
+	sort.Strings(monitorTypes)
+	return monitorTypes
+}
+
+func monitorUsageReportingDisabled() bool {
+	disabled, err := strconv.ParseBool(os.Getenv(monitorUsageReportingDisabledEnvVar))
+	return err == nil && disabled
+}
+
+// reportMonitorUsage records the instantiated monitor type and emits a structured event
+// enumerating all Smart Agent monitor types in use, unless opted out.
+func reportMonitorUsage(logger *zap.Logger, monitorType string, id config.ComponentID) {
+	instances := monitorUsage.record(monitorType)
+	if monitorUsageReportingDisabled() {
+		return
+	}
+	logger.Info(
+		"Smart Agent monitor instantiated. Consider migrating to an equivalent native OpenTelemetry receiver if available.",
+		zap.String("event", "smartagent_monitor_usage"),
+		zap.String("monitor_type", monitorType),
+		zap.Stringer("receiver", id),
+		zap.Int("monitor_type_instances", instances),
+		zap.Strings("monitor_types_in_use", monitorUsage.inUse()),
+	)
+}
@@ -0,0 +1,97 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"testing"
+
+	"github.com/signalfx/signalfx-agent/pkg/monitors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestMonitorUsageTracker(t *testing.T) {
+	tracker := newMonitorUsageTracker()
+	assert.Empty(t, tracker.inUse())
+
+	assert.Equal(t, 1, tracker.record("cpu"))
+	assert.Equal(t, 2, tracker.record("cpu"))
+	assert.Equal(t, 1, tracker.record("collectd/redis"))
+	assert.Equal(t, []string{"collectd/redis", "cpu"}, tracker.inUse())
+
+	tracker.release("cpu")
+	assert.Equal(t, []string{"collectd/redis", "cpu"}, tracker.inUse())
+	tracker.release("cpu")
+	assert.Equal(t, []string{"collectd/redis"}, tracker.inUse())
+	tracker.release("collectd/redis")
+	tracker.release("notinuse")
+	assert.Empty(t, tracker.inUse())
+}
+
+func TestReportMonitorUsage(t *testing.T) {
+	monitorUsage = newMonitorUsageTracker()
+	t.Cleanup(func() { monitorUsage = newMonitorUsageTracker() })
+
+	logCore, logs := observer.New(zap.InfoLevel)
+	logger := zap.New(logCore)
+
+	reportMonitorUsage(logger, "cpu", config.NewComponentIDWithName(typeStr, "cpu"))
+	reportMonitorUsage(logger, "memory", config.NewComponentIDWithName(typeStr, "memory"))
+
+	entries := logs.FilterField(zap.String("event", "smartagent_monitor_usage")).All()
+	require.Len(t, entries, 2)
+	fields := entries[1].ContextMap()
+	assert.Equal(t, "memory", fields["monitor_type"])
+	assert.Equal(t, "smartagent/memory", fields["receiver"])
+	assert.EqualValues(t, 1, fields["monitor_type_instances"])
+	assert.Equal(t, []any{"cpu", "memory"}, fields["monitor_types_in_use"])
+}
+
+func TestReportMonitorUsageOptOut(t *testing.T) {
+	monitorUsage = newMonitorUsageTracker()
+	t.Cleanup(func() { monitorUsage = newMonitorUsageTracker() })
+	t.Setenv(monitorUsageReportingDisabledEnvVar, "true")
+
+	logCore, logs := observer.New(zap.DebugLevel)
+	reportMonitorUsage(zap.New(logCore), "cpu", config.NewComponentID(typeStr))
+
+	assert.Zero(t, logs.Len())
+	// usage is still tracked for when reporting is reenabled
+	assert.Equal(t, []string{"cpu"}, monitorUsage.inUse())
+}
+
+func TestMonitorUsageReleasedOnlyWhenRecorded(t *testing.T) {
+	t.Cleanup(cleanUp)
+	monitorUsage = newMonitorUsageTracker()
+	t.Cleanup(func() { monitorUsage = newMonitorUsageTracker() })
+
+	started := NewReceiver(newReceiverCreateSettings(), newConfig("started", "cpu", 1))
+	require.NoError(t, started.Start(context.Background(), componenttest.NewNopHost()))
+	assert.Equal(t, []string{"cpu"}, monitorUsage.inUse())
+
+	// a receiver whose Start failed after creating its monitor, before its usage was recorded
+	notStarted := NewReceiver(newReceiverCreateSettings(), newConfig("notstarted", "cpu", 1))
+	notStarted.monitor = monitors.MonitorFactories["cpu"]()
+	require.NoError(t, notStarted.Shutdown(context.Background()))
+	assert.Equal(t, []string{"cpu"}, monitorUsage.inUse())
+
+	require.NoError(t, started.Shutdown(context.Background()))
+	assert.Empty(t, monitorUsage.inUse())
+}
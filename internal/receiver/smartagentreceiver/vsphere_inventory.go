@@ -0,0 +1,196 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+)
+
+const (
+	vsphereMonitorType = "vsphere"
+
+	vsphereVirtualMachine = "VirtualMachine"
+	vsphereHostSystem     = "HostSystem"
+
+	vcenterDimension    = "vcenter"
+	refIDDimension      = "ref_id"
+	objectTypeDimension = "object_type"
+	vmNameDimension     = "vm_name"
+	esxIPDimension      = "esx_ip"
+	clusterDimension    = "cluster"
+	datacenterDimension = "datacenter"
+
+	vsphereAddedEventType   = "vsphere.inventory.added"
+	vsphereRemovedEventType = "vsphere.inventory.removed"
+	vsphereMovedEventType   = "vsphere.inventory.moved"
+)
+
+// vspherePlacementDimensions are the dimensions locating each object type in the vCenter inventory,
+// whose changes are reported as moves.
+var vspherePlacementDimensions = map[string][]string{
+	vsphereVirtualMachine: {esxIPDimension, clusterDimension, datacenterDimension},
+	vsphereHostSystem:     {clusterDimension, datacenterDimension},
+}
+
+// vsphereInventoryTracker keeps the virtual machines and hosts reported by the vsphere monitor's datapoints,
+// returning events for the objects that appeared, disappeared, or moved to another host, cluster, or
+// datacenter.  Its objects are also those whose vCenter tags are synced by the vsphereTagSyncer.
+type vsphereInventoryTracker struct {
+	now     func() time.Time
+	objects map[string]*vsphereObject
+	// objects not reported for this long have disappeared
+	expiry time.Duration
+	lock   sync.Mutex
+	// whether track returns the events, instead of only tracking the objects whose tags are synced
+	events bool
+}
+
+type vsphereObject struct {
+	lastSeen   time.Time
+	placement  map[string]string
+	vcenter    string
+	refID      string
+	objectType string
+	name       string
+}
+
+func newVSphereInventoryTracker(intervalSeconds int, events bool) *vsphereInventoryTracker {
+	return &vsphereInventoryTracker{
+		now:     time.Now,
+		objects: map[string]*vsphereObject{},
+		expiry:  2 * time.Duration(intervalSeconds) * time.Second,
+		events:  events,
+	}
+}
+
+// nameDimension is the dimension identifying the object in the monitor's datapoints, to which
+// its synced tags are added as properties.
+func (o *vsphereObject) nameDimension() string {
+	if o.objectType == vsphereHostSystem {
+		return esxIPDimension
+	}
+	return vmNameDimension
+}
+
+// track updates the tracked objects from the datapoints and, if enabled, returns the events for the objects
+// that appeared, disappeared, or moved since the previous datapoints.
+func (t *vsphereInventoryTracker) track(datapoints []*datapoint.Datapoint) []*event.Event {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	now := t.now()
+	var events []*event.Event
+	for _, dp := range datapoints {
+		refID := dp.Dimensions[refIDDimension]
+		objectType := dp.Dimensions[objectTypeDimension]
+		placementDimensions, ok := vspherePlacementDimensions[objectType]
+		if refID == "" || !ok {
+			continue
+		}
+		key := dp.Dimensions[vcenterDimension] + "/" + refID
+		placement := make(map[string]string, len(placementDimensions))
+		for _, dim := range placementDimensions {
+			placement[dim] = dp.Dimensions[dim]
+		}
+
+		tracked, ok := t.objects[key]
+		if !ok {
+			tracked = &vsphereObject{
+				vcenter:    dp.Dimensions[vcenterDimension],
+				refID:      refID,
+				objectType: objectType,
+				placement:  placement,
+			}
+			tracked.name = dp.Dimensions[tracked.nameDimension()]
+			t.objects[key] = tracked
+			events = append(events, vsphereEvent(vsphereAddedEventType, tracked, nil, now))
+		} else if placementChanged(tracked.placement, placement) {
+			previous := tracked.placement
+			tracked.placement = placement
+			events = append(events, vsphereEvent(vsphereMovedEventType, tracked, previous, now))
+		}
+		tracked.lastSeen = now
+	}
+
+	for key, tracked := range t.objects {
+		if now.Sub(tracked.lastSeen) > t.expiry {
+			delete(t.objects, key)
+			events = append(events, vsphereEvent(vsphereRemovedEventType, tracked, nil, now))
+		}
+	}
+	if !t.events {
+		return nil
+	}
+	return events
+}
+
+// snapshot returns copies of the tracked objects, ordered by their vCenter and reference id.
+func (t *vsphereInventoryTracker) snapshot() []vsphereObject {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	objects := make([]vsphereObject, 0, len(t.objects))
+	for _, tracked := range t.objects {
+		objects = append(objects, *tracked)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if objects[i].vcenter != objects[j].vcenter {
+			return objects[i].vcenter < objects[j].vcenter
+		}
+		return objects[i].refID < objects[j].refID
+	})
+	return objects
+}
+
+func placementChanged(previous, current map[string]string) bool {
+	for dim, value := range current {
+		if previous[dim] != value {
+			return true
+		}
+	}
+	return false
+}
+
+func vsphereEvent(eventType string, object *vsphereObject, previousPlacement map[string]string, timestamp time.Time) *event.Event {
+	dimensions := map[string]string{
+		vcenterDimension:       object.vcenter,
+		refIDDimension:         object.refID,
+		objectTypeDimension:    object.objectType,
+		object.nameDimension(): object.name,
+	}
+	for dim, value := range object.placement {
+		if value != "" {
+			dimensions[dim] = value
+		}
+	}
+	properties := map[string]any{}
+	for dim, value := range previousPlacement {
+		if value != object.placement[dim] {
+			properties["previous_"+dim] = value
+		}
+	}
+	return &event.Event{
+		EventType:  eventType,
+		Category:   event.AGENT,
+		Dimensions: dimensions,
+		Properties: properties,
+		Timestamp:  timestamp,
+	}
+}
@@ -0,0 +1,127 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/golib/v3/event"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func vmDatapoint(refID, name, esxIP string) *datapoint.Datapoint {
+	return datapoint.New("vsphere.cpu_usage_percent", map[string]string{
+		vcenterDimension:    "vcenter.example.com",
+		refIDDimension:      refID,
+		objectTypeDimension: vsphereVirtualMachine,
+		vmNameDimension:     name,
+		esxIPDimension:      esxIP,
+		clusterDimension:    "cluster-1",
+		datacenterDimension: "dc-1",
+	}, datapoint.NewFloatValue(1), datapoint.Gauge, time.Time{})
+}
+
+func hostDatapoint(refID, esxIP, cluster string) *datapoint.Datapoint {
+	return datapoint.New("vsphere.cpu_usage_percent", map[string]string{
+		vcenterDimension:    "vcenter.example.com",
+		refIDDimension:      refID,
+		objectTypeDimension: vsphereHostSystem,
+		esxIPDimension:      esxIP,
+		clusterDimension:    cluster,
+		datacenterDimension: "dc-1",
+	}, datapoint.NewFloatValue(1), datapoint.Gauge, time.Time{})
+}
+
+func vsphereEventSummaries(events []*event.Event) []string {
+	var summaries []string
+	for _, ev := range events {
+		objectType := ev.Dimensions[objectTypeDimension]
+		summary := ev.EventType + " " + objectType + "/" + ev.Dimensions[refIDDimension]
+		if objectType == vsphereVirtualMachine {
+			summary += " " + ev.Dimensions[vmNameDimension] + "@" + ev.Dimensions[esxIPDimension]
+		} else {
+			summary += " " + ev.Dimensions[esxIPDimension] + "@" + ev.Dimensions[clusterDimension]
+		}
+		summaries = append(summaries, summary)
+	}
+	return summaries
+}
+
+func TestVSphereInventoryTracker(t *testing.T) {
+	tracker := newVSphereInventoryTracker(20, true)
+	now := time.Date(2022, 7, 1, 0, 0, 0, 0, time.UTC)
+	tracker.now = func() time.Time { return now }
+
+	events := tracker.track([]*datapoint.Datapoint{
+		vmDatapoint("vm-1", "web", "10.0.0.1"),
+		vmDatapoint("vm-1", "web", "10.0.0.1"),
+		vmDatapoint("vm-2", "db", "10.0.0.1"),
+		hostDatapoint("host-1", "10.0.0.1", "cluster-1"),
+		datapoint.New("vsphere.other", map[string]string{refIDDimension: "datastore-1", objectTypeDimension: "Datastore"},
+			datapoint.NewFloatValue(1), datapoint.Gauge, time.Time{}),
+	})
+	assert.Equal(t, []string{
+		"vsphere.inventory.added VirtualMachine/vm-1 web@10.0.0.1",
+		"vsphere.inventory.added VirtualMachine/vm-2 db@10.0.0.1",
+		"vsphere.inventory.added HostSystem/host-1 10.0.0.1@cluster-1",
+	}, vsphereEventSummaries(events))
+	for _, ev := range events {
+		assert.Equal(t, event.AGENT, ev.Category)
+		assert.Equal(t, now, ev.Timestamp)
+		assert.Equal(t, "vcenter.example.com", ev.Dimensions[vcenterDimension])
+		assert.Empty(t, ev.Properties)
+	}
+
+	// vMotion and host cluster changes are moves
+	now = now.Add(20 * time.Second)
+	events = tracker.track([]*datapoint.Datapoint{
+		vmDatapoint("vm-1", "web", "10.0.0.2"),
+		vmDatapoint("vm-2", "db", "10.0.0.1"),
+		hostDatapoint("host-1", "10.0.0.1", "cluster-2"),
+	})
+	require.Equal(t, []string{
+		"vsphere.inventory.moved VirtualMachine/vm-1 web@10.0.0.2",
+		"vsphere.inventory.moved HostSystem/host-1 10.0.0.1@cluster-2",
+	}, vsphereEventSummaries(events))
+	assert.Equal(t, map[string]any{"previous_esx_ip": "10.0.0.1"}, events[0].Properties)
+	assert.Equal(t, map[string]any{"previous_cluster": "cluster-1"}, events[1].Properties)
+
+	// unreported objects disappear after two intervals
+	now = now.Add(41 * time.Second)
+	events = tracker.track([]*datapoint.Datapoint{vmDatapoint("vm-1", "web", "10.0.0.2")})
+	assert.ElementsMatch(t, []string{
+		"vsphere.inventory.removed VirtualMachine/vm-2 db@10.0.0.1",
+		"vsphere.inventory.removed HostSystem/host-1 10.0.0.1@cluster-2",
+	}, vsphereEventSummaries(events))
+
+	objects := tracker.snapshot()
+	require.Len(t, objects, 1)
+	assert.Equal(t, "vm-1", objects[0].refID)
+	assert.Equal(t, "web", objects[0].name)
+	assert.Equal(t, vmNameDimension, objects[0].nameDimension())
+}
+
+func TestVSphereInventoryTrackerWithoutEvents(t *testing.T) {
+	tracker := newVSphereInventoryTracker(20, false)
+	assert.Empty(t, tracker.track([]*datapoint.Datapoint{hostDatapoint("host-1", "10.0.0.1", "cluster-1")}))
+
+	objects := tracker.snapshot()
+	require.Len(t, objects, 1)
+	assert.Equal(t, "10.0.0.1", objects[0].name)
+	assert.Equal(t, esxIPDimension, objects[0].nameDimension())
+}
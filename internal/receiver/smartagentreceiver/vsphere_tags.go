@@ -0,0 +1,293 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	saconfig "github.com/signalfx/signalfx-agent/pkg/core/config"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"github.com/vmware/govmomi"
+	"github.com/vmware/govmomi/vapi/rest"
+	"github.com/vmware/govmomi/vapi/tags"
+	"github.com/vmware/govmomi/vim25/mo"
+	"github.com/vmware/govmomi/vim25/soap"
+	vimtypes "github.com/vmware/govmomi/vim25/types"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+const (
+	defaultVSphereTagSyncInterval = 60 * time.Second
+	vsphereTagPropertyPrefix      = "vsphere_tag_"
+)
+
+// vsphereTagSource provides the vCenter tags attached to the inventory objects.
+type vsphereTagSource interface {
+	// attachedTags returns the names of the tags attached to each object by their category name,
+	// keyed by the object's reference id.
+	attachedTags(ctx context.Context, objects []vsphereObject) (map[string]map[string][]string, error)
+	close(ctx context.Context)
+}
+
+// vsphereTagSyncer periodically syncs the vCenter tags of the tracked virtual machines and hosts as
+// properties of their vm_name and esx_ip dimensions, one property for each tag category.  Only changed
+// properties are sent, and the properties of categories whose tags were all detached are removed.
+type vsphereTagSyncer struct {
+	ctx       context.Context
+	source    vsphereTagSource
+	inventory *vsphereInventoryTracker
+	output    types.Output
+	cancel    context.CancelFunc
+	logger    *zap.Logger
+	clock     clock.Clock
+	// the last synced properties of each object
+	synced   map[string]map[string]string
+	interval time.Duration
+	wg       sync.WaitGroup
+}
+
+func newVSphereTagSyncer(
+	monitorConfig saconfig.MonitorCustomConfig, inventory *vsphereInventoryTracker, output types.Output, logger *zap.Logger, clk clock.Clock,
+) (*vsphereTagSyncer, error) {
+	source, err := newGovmomiTagSource(monitorConfig)
+	if err != nil {
+		return nil, err
+	}
+	interval := defaultVSphereTagSyncInterval
+	options := reflect.Indirect(reflect.ValueOf(monitorConfig))
+	if refresh := intOption(options, "InventoryRefreshInterval"); refresh > 0 {
+		interval = time.Duration(refresh)
+	}
+	return &vsphereTagSyncer{
+		source:    source,
+		inventory: inventory,
+		output:    output,
+		logger:    logger,
+		clock:     clk,
+		synced:    map[string]map[string]string{},
+		interval:  interval,
+	}, nil
+}
+
+// start syncs the tags every interval until shutdown.  It's a noop for a nil instance.
+func (s *vsphereTagSyncer) start() {
+	if s == nil {
+		return
+	}
+	s.ctx, s.cancel = context.WithCancel(context.Background())
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := s.clock.NewTicker(s.interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-s.ctx.Done():
+				return
+			case <-ticker.Chan():
+				s.sync(s.ctx)
+			}
+		}
+	}()
+}
+
+// shutdown stops syncing and logs out of vCenter.  It's a noop for a nil instance.
+func (s *vsphereTagSyncer) shutdown() {
+	if s == nil {
+		return
+	}
+	if s.cancel != nil {
+		s.cancel()
+	}
+	s.wg.Wait()
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	s.source.close(ctx)
+}
+
+func (s *vsphereTagSyncer) sync(ctx context.Context) {
+	objects := s.inventory.snapshot()
+	if len(objects) == 0 {
+		return
+	}
+	attached, err := s.source.attachedTags(ctx, objects)
+	if err != nil {
+		if ctx.Err() == nil {
+			s.logger.Error("failed retrieving vSphere tags", zap.Error(err))
+		}
+		return
+	}
+
+	tracked := make(map[string]bool, len(objects))
+	for i := range objects {
+		object := &objects[i]
+		if object.name == "" {
+			continue
+		}
+		key := object.vcenter + "/" + object.refID
+		tracked[key] = true
+		properties := vsphereTagProperties(attached[object.refID])
+		previous := s.synced[key]
+		changed := map[string]string{}
+		for property, value := range properties {
+			if previous[property] != value {
+				changed[property] = value
+			}
+		}
+		for property := range previous {
+			if _, ok := properties[property]; !ok {
+				// an empty value removes the property
+				changed[property] = ""
+			}
+		}
+		s.synced[key] = properties
+		if len(changed) == 0 {
+			continue
+		}
+		s.output.SendDimensionUpdate(&types.Dimension{
+			Name:              object.nameDimension(),
+			Value:             object.name,
+			Properties:        changed,
+			MergeIntoExisting: true,
+		})
+	}
+	for key := range s.synced {
+		if !tracked[key] {
+			delete(s.synced, key)
+		}
+	}
+}
+
+// vsphereTagProperties returns a property for each tag category, whose value is the sorted, comma-separated
+// names of the category's attached tags.
+func vsphereTagProperties(tagsByCategory map[string][]string) map[string]string {
+	properties := make(map[string]string, len(tagsByCategory))
+	for category, names := range tagsByCategory {
+		if len(names) == 0 {
+			continue
+		}
+		sorted := append([]string(nil), names...)
+		sort.Strings(sorted)
+		property := vsphereTagPropertyPrefix + strings.ToLower(nonWordCharacters.ReplaceAllString(category, "_"))
+		properties[property] = strings.Join(sorted, ",")
+	}
+	return properties
+}
+
+// govmomiTagSource retrieves the tags with the vSphere Automation API of the monitor's vCenter, using the
+// monitor's connection and credential options.  It logs in on first use and after failed retrievals.
+type govmomiTagSource struct {
+	url        *url.URL
+	vimClient  *govmomi.Client
+	restClient *rest.Client
+	insecure   bool
+}
+
+func newGovmomiTagSource(monitorConfig saconfig.MonitorCustomConfig) (*govmomiTagSource, error) {
+	options := reflect.Indirect(reflect.ValueOf(monitorConfig))
+	host := stringOption(options, "Host")
+	if host == "" {
+		return nil, fmt.Errorf("vsphereTags requires the monitor's host option")
+	}
+	if port := uintOption(options, "Port"); port != 0 {
+		host = net.JoinHostPort(host, strconv.FormatUint(port, 10))
+	}
+	u, err := soap.ParseURL(host)
+	if err != nil {
+		return nil, fmt.Errorf("invalid vCenter host %q: %w", host, err)
+	}
+	u.User = url.UserPassword(stringOption(options, "Username"), stringOption(options, "Password"))
+	return &govmomiTagSource{url: u, insecure: boolOption(options, "InsecureSkipVerify")}, nil
+}
+
+func (s *govmomiTagSource) connect(ctx context.Context) error {
+	if s.restClient != nil {
+		return nil
+	}
+	vimClient, err := govmomi.NewClient(ctx, s.url, s.insecure)
+	if err != nil {
+		return fmt.Errorf("failed connecting to vCenter: %w", err)
+	}
+	restClient := rest.NewClient(vimClient.Client)
+	if err = restClient.Login(ctx, s.url.User); err != nil {
+		_ = vimClient.Logout(ctx)
+		return fmt.Errorf("failed logging in to the vSphere Automation API: %w", err)
+	}
+	s.vimClient, s.restClient = vimClient, restClient
+	return nil
+}
+
+func (s *govmomiTagSource) attachedTags(ctx context.Context, objects []vsphereObject) (map[string]map[string][]string, error) {
+	if err := s.connect(ctx); err != nil {
+		return nil, err
+	}
+	manager := tags.NewManager(s.restClient)
+	categories, err := manager.GetCategories(ctx)
+	if err != nil {
+		// the session may have expired, so log in again on the next retrieval
+		s.close(ctx)
+		return nil, fmt.Errorf("failed retrieving tag categories: %w", err)
+	}
+	categoryNames := make(map[string]string, len(categories))
+	for _, category := range categories {
+		categoryNames[category.ID] = category.Name
+	}
+
+	refs := make([]mo.Reference, 0, len(objects))
+	for _, object := range objects {
+		refs = append(refs, vimtypes.ManagedObjectReference{Type: object.objectType, Value: object.refID})
+	}
+	attached, err := manager.GetAttachedTagsOnObjects(ctx, refs)
+	if err != nil {
+		s.close(ctx)
+		return nil, fmt.Errorf("failed retrieving attached tags: %w", err)
+	}
+
+	tagsByRef := make(map[string]map[string][]string, len(attached))
+	for _, objectTags := range attached {
+		tagsByCategory := map[string][]string{}
+		for _, tag := range objectTags.Tags {
+			category, ok := categoryNames[tag.CategoryID]
+			if !ok {
+				category = tag.CategoryID
+			}
+			tagsByCategory[category] = append(tagsByCategory[category], tag.Name)
+		}
+		tagsByRef[objectTags.ObjectID.Reference().Value] = tagsByCategory
+	}
+	return tagsByRef, nil
+}
+
+func (s *govmomiTagSource) close(ctx context.Context) {
+	if s.restClient != nil {
+		_ = s.restClient.Logout(ctx)
+		s.restClient = nil
+	}
+	if s.vimClient != nil {
+		_ = s.vimClient.Logout(ctx)
+		s.vimClient = nil
+	}
+}
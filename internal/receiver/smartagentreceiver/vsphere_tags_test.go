@@ -0,0 +1,126 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package smartagentreceiver
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/signalfx/golib/v3/datapoint"
+	"github.com/signalfx/signalfx-agent/pkg/monitors/types"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/internal/clock"
+)
+
+type fakeVSphereTagSource struct {
+	err    error
+	tags   map[string]map[string][]string
+	closed bool
+}
+
+func (s *fakeVSphereTagSource) attachedTags(context.Context, []vsphereObject) (map[string]map[string][]string, error) {
+	return s.tags, s.err
+}
+
+func (s *fakeVSphereTagSource) close(context.Context) {
+	s.closed = true
+}
+
+type dimensionRecordingOutput struct {
+	types.Output
+	dimensions []*types.Dimension
+}
+
+func (o *dimensionRecordingOutput) SendDimensionUpdate(dimension *types.Dimension) {
+	o.dimensions = append(o.dimensions, dimension)
+}
+
+func TestVSphereTagSyncer(t *testing.T) {
+	inventory := newVSphereInventoryTracker(20, false)
+	inventory.track([]*datapoint.Datapoint{
+		vmDatapoint("vm-1", "web", "10.0.0.1"),
+		hostDatapoint("host-1", "10.0.0.1", "cluster-1"),
+	})
+	source := &fakeVSphereTagSource{tags: map[string]map[string][]string{
+		"vm-1":   {"Owner Team": {"payments"}, "Environment": {"prod", "pci"}},
+		"host-1": {"Rack": {"r12"}},
+	}}
+	output := &dimensionRecordingOutput{}
+	syncer := &vsphereTagSyncer{
+		source:    source,
+		inventory: inventory,
+		output:    output,
+		logger:    zap.NewNop(),
+		clock:     clock.New(),
+		synced:    map[string]map[string]string{},
+		interval:  time.Hour,
+	}
+
+	ctx := context.Background()
+	syncer.sync(ctx)
+	assert.Equal(t, []*types.Dimension{
+		{
+			Name: "esx_ip", Value: "10.0.0.1", MergeIntoExisting: true,
+			Properties: map[string]string{"vsphere_tag_rack": "r12"},
+		},
+		{
+			Name: "vm_name", Value: "web", MergeIntoExisting: true,
+			Properties: map[string]string{"vsphere_tag_owner_team": "payments", "vsphere_tag_environment": "pci,prod"},
+		},
+	}, output.dimensions)
+
+	// unchanged tags aren't sent again
+	output.dimensions = nil
+	syncer.sync(ctx)
+	assert.Empty(t, output.dimensions)
+
+	// failed retrievals don't change the synced properties
+	source.err = errors.New("session expired")
+	syncer.sync(ctx)
+	assert.Empty(t, output.dimensions)
+
+	// only changed properties are sent and detached categories are removed
+	source.err = nil
+	source.tags = map[string]map[string][]string{
+		"vm-1":   {"Owner Team": {"payments"}, "Environment": {"prod"}},
+		"host-1": {},
+	}
+	syncer.sync(ctx)
+	assert.Equal(t, []*types.Dimension{
+		{
+			Name: "esx_ip", Value: "10.0.0.1", MergeIntoExisting: true,
+			Properties: map[string]string{"vsphere_tag_rack": ""},
+		},
+		{
+			Name: "vm_name", Value: "web", MergeIntoExisting: true,
+			Properties: map[string]string{"vsphere_tag_environment": "prod"},
+		},
+	}, output.dimensions)
+
+	syncer.start()
+	syncer.shutdown()
+	assert.True(t, source.closed)
+}
+
+func TestNewVSphereTagSyncerRequiresHost(t *testing.T) {
+	cfg := newConfig("cpu", "cpu", 1)
+	_, err := newVSphereTagSyncer(cfg.monitorConfig, newVSphereInventoryTracker(20, false), &dimensionRecordingOutput{}, zap.NewNop(), clock.New())
+	require.EqualError(t, err, "vsphereTags requires the monitor's host option")
+}
@@ -0,0 +1,111 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"go.opentelemetry.io/collector/config"
+)
+
+// minPasswordLength is the minimum length of SNMPv3 passwords (RFC 3414 11.2).
+const minPasswordLength = 8
+
+var _ config.Receiver = (*Config)(nil)
+
+type Config struct {
+	config.ReceiverSettings `mapstructure:",squash"`
+	// Endpoint is the UDP address traps are received on, 0.0.0.0:162 by default.
+	Endpoint string `mapstructure:"endpoint"`
+	// Communities are the accepted SNMPv2c communities.  SNMPv2c traps are rejected if empty.
+	Communities []string `mapstructure:"communities"`
+	// Users are the accepted SNMPv3 users.  SNMPv3 traps are rejected if empty.
+	Users []UserConfig `mapstructure:"users"`
+	// MIBDirectories are directories whose MIB files define the names of the OIDs of traps and their
+	// variable bindings.  The generic traps of SNMPv2-MIB and IF-MIB are resolved without them.
+	MIBDirectories []string `mapstructure:"mib_directories"`
+}
+
+// UserConfig is an SNMPv3 user of the user-based security model, whose traps are authenticated and
+// decrypted with the keys of its passwords.
+type UserConfig struct {
+	Name string `mapstructure:"name"`
+	// AuthProtocol is MD5, SHA or SHA256.  Traps are unauthenticated if empty.
+	AuthProtocol string `mapstructure:"auth_protocol"`
+	AuthPassword string `mapstructure:"auth_password"`
+	// PrivProtocol is DES or AES (AES-128).  Traps are unencrypted if empty.
+	PrivProtocol string `mapstructure:"priv_protocol"`
+	PrivPassword string `mapstructure:"priv_password"`
+}
+
+func (cfg *Config) Validate() error {
+	if cfg.Endpoint == "" {
+		return errors.New("endpoint must not be empty")
+	}
+	if len(cfg.Communities) == 0 && len(cfg.Users) == 0 {
+		return errors.New("communities or users must not be empty")
+	}
+	for i, community := range cfg.Communities {
+		if community == "" {
+			return fmt.Errorf("communities[%d] must not be empty", i)
+		}
+	}
+	names := map[string]bool{}
+	for i, user := range cfg.Users {
+		if user.Name == "" {
+			return fmt.Errorf("users[%d]: name must not be empty", i)
+		}
+		if names[user.Name] {
+			return fmt.Errorf("users[%d]: duplicate user name %q", i, user.Name)
+		}
+		names[user.Name] = true
+		if err := user.validate(); err != nil {
+			return fmt.Errorf("users[%d]: %w", i, err)
+		}
+	}
+	for i, directory := range cfg.MIBDirectories {
+		if directory == "" {
+			return fmt.Errorf("mib_directories[%d] must not be empty", i)
+		}
+	}
+	return nil
+}
+
+func (user UserConfig) validate() error {
+	if user.AuthProtocol == "" {
+		if user.PrivProtocol != "" {
+			return errors.New("priv_protocol requires an auth_protocol")
+		}
+		return nil
+	}
+	if _, ok := authProtocols[strings.ToUpper(user.AuthProtocol)]; !ok {
+		return fmt.Errorf("unsupported auth_protocol %q, must be one of %s, %s or %s", user.AuthProtocol, authMD5, authSHA, authSHA256)
+	}
+	if len(user.AuthPassword) < minPasswordLength {
+		return fmt.Errorf("auth_password must be at least %d characters", minPasswordLength)
+	}
+	if user.PrivProtocol == "" {
+		return nil
+	}
+	if _, ok := privProtocols[strings.ToUpper(user.PrivProtocol)]; !ok {
+		return fmt.Errorf("unsupported priv_protocol %q, must be %s or %s", user.PrivProtocol, privDES, privAES)
+	}
+	if len(user.PrivPassword) < minPasswordLength {
+		return fmt.Errorf("priv_password must be at least %d characters", minPasswordLength)
+	}
+	return nil
+}
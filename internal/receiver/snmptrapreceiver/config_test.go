@@ -0,0 +1,133 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/service/servicetest"
+)
+
+func TestLoadConfig(t *testing.T) {
+	factories, err := componenttest.NopFactories()
+	require.NoError(t, err)
+
+	factory := NewFactory()
+	factories.Receivers[typeStr] = factory
+	cfg, err := servicetest.LoadConfig(path.Join(".", "testdata", "config.yaml"), factories)
+	require.NoError(t, err)
+	require.NotNil(t, cfg)
+	assert.Equal(t, len(cfg.Receivers), 4)
+
+	defaultCfg := cfg.Receivers[config.NewComponentID(typeStr)]
+	assert.Equal(t, factory.CreateDefaultConfig(), defaultCfg)
+	assert.EqualError(t, defaultCfg.Validate(), "communities or users must not be empty")
+
+	allSettings := cfg.Receivers[config.NewComponentIDWithName(typeStr, "allsettings")].(*Config)
+	assert.Equal(t, &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentIDWithName(typeStr, "allsettings")),
+		Endpoint:         "0.0.0.0:1162",
+		Communities:      []string{"public", "monitoring"},
+		Users: []UserConfig{
+			{
+				Name:         "collector",
+				AuthProtocol: "SHA",
+				AuthPassword: "authpassword",
+				PrivProtocol: "AES",
+				PrivPassword: "privpassword",
+			},
+			{
+				Name:         "unencrypted",
+				AuthProtocol: "MD5",
+				AuthPassword: "authpassword",
+			},
+		},
+		MIBDirectories: []string{"/usr/share/snmp/mibs"},
+	}, allSettings)
+	require.NoError(t, allSettings.Validate())
+
+	duplicateUser := cfg.Receivers[config.NewComponentIDWithName(typeStr, "duplicateuser")]
+	assert.EqualError(t, duplicateUser.Validate(), `users[1]: duplicate user name "collector"`)
+
+	shortPassword := cfg.Receivers[config.NewComponentIDWithName(typeStr, "shortpassword")]
+	assert.EqualError(t, shortPassword.Validate(), "users[0]: auth_password must be at least 8 characters")
+}
+
+func TestValidate(t *testing.T) {
+	for _, test := range []struct {
+		name        string
+		config      Config
+		expectedErr string
+	}{
+		{
+			name:        "missing endpoint",
+			config:      Config{},
+			expectedErr: "endpoint must not be empty",
+		},
+		{
+			name:        "missing communities and users",
+			config:      Config{Endpoint: defaultEndpoint},
+			expectedErr: "communities or users must not be empty",
+		},
+		{
+			name:        "empty community",
+			config:      Config{Endpoint: defaultEndpoint, Communities: []string{"public", ""}},
+			expectedErr: "communities[1] must not be empty",
+		},
+		{
+			name:        "missing user name",
+			config:      Config{Endpoint: defaultEndpoint, Users: []UserConfig{{AuthProtocol: "SHA"}}},
+			expectedErr: "users[0]: name must not be empty",
+		},
+		{
+			name:        "unsupported auth protocol",
+			config:      Config{Endpoint: defaultEndpoint, Users: []UserConfig{{Name: "a", AuthProtocol: "SHA512"}}},
+			expectedErr: `users[0]: unsupported auth_protocol "SHA512", must be one of MD5, SHA or SHA256`,
+		},
+		{
+			name:        "privacy without authentication",
+			config:      Config{Endpoint: defaultEndpoint, Users: []UserConfig{{Name: "a", PrivProtocol: "AES"}}},
+			expectedErr: "users[0]: priv_protocol requires an auth_protocol",
+		},
+		{
+			name: "unsupported priv protocol",
+			config: Config{Endpoint: defaultEndpoint, Users: []UserConfig{
+				{Name: "a", AuthProtocol: "sha", AuthPassword: "password", PrivProtocol: "3DES"},
+			}},
+			expectedErr: `users[0]: unsupported priv_protocol "3DES", must be DES or AES`,
+		},
+		{
+			name: "short priv password",
+			config: Config{Endpoint: defaultEndpoint, Users: []UserConfig{
+				{Name: "a", AuthProtocol: "sha", AuthPassword: "password", PrivProtocol: "des", PrivPassword: "short"},
+			}},
+			expectedErr: "users[0]: priv_password must be at least 8 characters",
+		},
+		{
+			name:        "empty mib directory",
+			config:      Config{Endpoint: defaultEndpoint, Communities: []string{"public"}, MIBDirectories: []string{"mibs", ""}},
+			expectedErr: "mib_directories[1] must not be empty",
+		},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			assert.EqualError(t, test.config.Validate(), test.expectedErr)
+		})
+	}
+}
@@ -0,0 +1,183 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// trap is a decoded SNMPv2-Trap notification.
+type trap struct {
+	// version is 2c or 3.
+	version     string
+	community   string
+	user        string
+	contextName string
+	varbinds    []varbind
+}
+
+// varbind is a variable binding of a notification, whose value gosnmp decoded to a string, integer, float,
+// octet string or nil, without the leading dot of OIDs.
+type varbind struct {
+	value any
+	oid   string
+	tag   gosnmp.Asn1BER
+}
+
+// trapDecoder decodes the notifications of the accepted communities and users with gosnmp.
+type trapDecoder struct {
+	// header decodes the version, community and user of messages, without the keys of any user.
+	header      *gosnmp.GoSNMP
+	communities map[string]bool
+	users       map[string]*usmUser
+	clocks      *engineClocks
+}
+
+func newTrapDecoder(cfg *Config) *trapDecoder {
+	d := &trapDecoder{
+		header:      &gosnmp.GoSNMP{Version: gosnmp.Version2c},
+		communities: map[string]bool{},
+		users:       map[string]*usmUser{},
+		clocks:      newEngineClocks(),
+	}
+	for _, community := range cfg.Communities {
+		d.communities[community] = true
+	}
+	for _, user := range cfg.Users {
+		d.users[user.Name] = newUSMUser(user)
+	}
+	return d
+}
+
+// decode returns the trap of the message, or an error if it isn't an SNMPv2-Trap PDU of an accepted
+// community or user.
+func (d *trapDecoder) decode(message []byte) (tr *trap, err error) {
+	defer func() {
+		// messages of any sender are decoded, which mustn't stop the collector if they're malformed
+		if r := recover(); r != nil {
+			tr, err = nil, fmt.Errorf("failed decoding SNMP message: %v", r)
+		}
+	}()
+
+	// gosnmp decodes the header of SNMPv3 messages even if it can't authenticate them without the keys of
+	// their user, and zeroes their authentication parameters in place to verify their digest
+	header, err := d.header.SnmpDecodePacket(append([]byte(nil), message...))
+	if header.Version == gosnmp.Version3 {
+		return d.decodeV3(message, header)
+	}
+	if err != nil {
+		return nil, err
+	}
+	switch header.Version {
+	case gosnmp.Version2c:
+		return d.decodeV2c(header)
+	case gosnmp.Version1:
+		return nil, errors.New("SNMPv1 traps aren't supported")
+	default:
+		return nil, fmt.Errorf("unsupported SNMP version %d", header.Version)
+	}
+}
+
+func (d *trapDecoder) decodeV2c(packet *gosnmp.SnmpPacket) (*trap, error) {
+	if !d.communities[packet.Community] {
+		return nil, fmt.Errorf("unknown community %q", packet.Community)
+	}
+	varbinds, err := trapVarbinds(packet)
+	if err != nil {
+		return nil, err
+	}
+	return &trap{version: "2c", community: packet.Community, varbinds: varbinds}, nil
+}
+
+// decodeV3 authenticates and decrypts the message with the keys of the user its header names, and rejects
+// the authenticated messages outside the time window of their engine.
+func (d *trapDecoder) decodeV3(message []byte, header *gosnmp.SnmpPacket) (*trap, error) {
+	if header.SecurityModel != gosnmp.UserSecurityModel {
+		return nil, fmt.Errorf("unsupported security model %d", header.SecurityModel)
+	}
+	headerParams, ok := header.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok {
+		return nil, errors.New("failed decoding security parameters")
+	}
+	userName := headerParams.UserName
+	user, ok := d.users[userName]
+	if !ok {
+		return nil, fmt.Errorf("unknown user %q", userName)
+	}
+	if level := header.MsgFlags & gosnmp.AuthPriv; level != user.securityLevel() {
+		return nil, fmt.Errorf("security level 0x%x of user %q doesn't match its configured one 0x%x", level, userName, user.securityLevel())
+	}
+
+	packet, err := user.snmp.UnmarshalTrap(append([]byte(nil), message...), false)
+	if err != nil {
+		return nil, fmt.Errorf("user %q: %w", userName, err)
+	}
+	params, ok := packet.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	if !ok || params.UserName != userName {
+		return nil, fmt.Errorf("user %q: failed decoding security parameters", userName)
+	}
+	if user.macLength != 0 {
+		// gosnmp only compares as many bytes of the digest as the message has
+		if len(params.AuthenticationParameters) != user.macLength {
+			return nil, fmt.Errorf("user %q: invalid authentication parameters length %d", userName, len(params.AuthenticationParameters))
+		}
+		if err = d.clocks.check(params.AuthoritativeEngineID, params.AuthoritativeEngineBoots, params.AuthoritativeEngineTime); err != nil {
+			return nil, fmt.Errorf("user %q: %w", userName, err)
+		}
+	}
+	varbinds, err := trapVarbinds(packet)
+	if err != nil {
+		return nil, err
+	}
+	return &trap{version: "3", user: userName, contextName: packet.ContextName, varbinds: varbinds}, nil
+}
+
+// trapVarbinds returns the variable bindings of the SNMPv2-Trap PDU of the packet.
+func trapVarbinds(packet *gosnmp.SnmpPacket) ([]varbind, error) {
+	if packet.PDUType != gosnmp.SNMPv2Trap {
+		return nil, fmt.Errorf("unsupported PDU type 0x%02x", byte(packet.PDUType))
+	}
+	varbinds := make([]varbind, 0, len(packet.Variables))
+	for _, pdu := range packet.Variables {
+		vb := varbind{oid: strings.TrimPrefix(pdu.Name, "."), tag: pdu.Type}
+		switch value := pdu.Value.(type) {
+		case int:
+			vb.value = int64(value)
+		case uint:
+			vb.value = uint64(value)
+		case uint32:
+			vb.value = uint64(value)
+		case uint64:
+			vb.value = value
+		case float32:
+			vb.value = float64(value)
+		case float64:
+			vb.value = value
+		case string:
+			if pdu.Type == gosnmp.ObjectIdentifier {
+				value = strings.TrimPrefix(value, ".")
+			}
+			vb.value = value
+		case []byte:
+			vb.value = value
+		}
+		varbinds = append(varbinds, vb)
+	}
+	return varbinds, nil
+}
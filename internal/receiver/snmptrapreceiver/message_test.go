@@ -0,0 +1,213 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"net"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testEngineID = "\x80\x00\x1f\x88\x04test"
+
+// trapPDUs returns the variable bindings of a trap, which start with its sysUpTime.0 and snmpTrapOID.0.
+func trapPDUs(trapOID string, varbinds ...gosnmp.SnmpPDU) []gosnmp.SnmpPDU {
+	return append([]gosnmp.SnmpPDU{
+		{Name: "." + sysUpTimeInstance, Type: gosnmp.TimeTicks, Value: uint32(12345)},
+		{Name: "." + snmpTrapOIDInstance, Type: gosnmp.ObjectIdentifier, Value: "." + trapOID},
+	}, varbinds...)
+}
+
+func encode(t *testing.T, snmp *gosnmp.GoSNMP, pduType gosnmp.PDUType, pdus []gosnmp.SnmpPDU) []byte {
+	message, err := snmp.SnmpEncodePacket(pduType, pdus, 0, 0)
+	require.NoError(t, err)
+	return message
+}
+
+func v2cMessage(t *testing.T, community string, pdus []gosnmp.SnmpPDU) []byte {
+	return encode(t, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: community}, gosnmp.SNMPv2Trap, pdus)
+}
+
+// v3Message returns the SNMPv3 message of the trap gosnmp sends, authenticated and encrypted with the keys
+// of the user by an engine with the boots and time.
+func v3Message(t *testing.T, user UserConfig, boots, engineTime uint32, pdus []gosnmp.SnmpPDU) []byte {
+	conn, err := net.ListenPacket(udpTransport, "127.0.0.1:0")
+	require.NoError(t, err)
+	defer conn.Close()
+
+	snmp := newUSMUser(user).snmp
+	snmp.Target = "127.0.0.1"
+	snmp.Port = uint16(conn.LocalAddr().(*net.UDPAddr).Port)
+	snmp.ContextName = "ctx"
+	snmp.Timeout = 5 * time.Second
+	params := snmp.SecurityParameters.(*gosnmp.UsmSecurityParameters)
+	params.AuthoritativeEngineID = testEngineID
+	params.AuthoritativeEngineBoots = boots
+	params.AuthoritativeEngineTime = engineTime
+	require.NoError(t, snmp.Connect())
+	defer snmp.Conn.Close()
+	_, err = snmp.SendTrap(gosnmp.SnmpTrap{Variables: pdus})
+	require.NoError(t, err)
+
+	buf := make([]byte, maxMessageSize)
+	require.NoError(t, conn.SetReadDeadline(time.Now().Add(5*time.Second)))
+	n, _, err := conn.ReadFrom(buf)
+	require.NoError(t, err)
+	return buf[:n]
+}
+
+var testUsers = []UserConfig{
+	{Name: "noauth"},
+	{Name: "md5", AuthProtocol: "MD5", AuthPassword: "authpassword"},
+	{Name: "sha-des", AuthProtocol: "SHA", AuthPassword: "authpassword", PrivProtocol: "DES", PrivPassword: "privpassword"},
+	{Name: "sha256-aes", AuthProtocol: "sha256", AuthPassword: "authpassword", PrivProtocol: "aes", PrivPassword: "privpassword"},
+}
+
+func TestDecodeV2c(t *testing.T) {
+	decoder := newTrapDecoder(&Config{Communities: []string{"public"}})
+	tr, err := decoder.decode(v2cMessage(t, "public", trapPDUs("1.3.6.1.6.3.1.1.5.3",
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.1.2", Type: gosnmp.Integer, Value: 2},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.2.2.1.2.2", Type: gosnmp.OctetString, Value: "eth0"},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.2.1.31.1.1.1.10.2", Type: gosnmp.Counter64, Value: uint64(1 << 40)},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.99999.1", Type: gosnmp.IPAddress, Value: "10.0.0.1"},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.99999.2", Type: gosnmp.Null},
+		gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.99999.3", Type: gosnmp.Integer, Value: -300},
+	)))
+	require.NoError(t, err)
+	assert.Equal(t, &trap{version: "2c", community: "public", varbinds: []varbind{
+		{oid: sysUpTimeInstance, tag: gosnmp.TimeTicks, value: uint64(12345)},
+		{oid: snmpTrapOIDInstance, tag: gosnmp.ObjectIdentifier, value: "1.3.6.1.6.3.1.1.5.3"},
+		{oid: "1.3.6.1.2.1.2.2.1.1.2", tag: gosnmp.Integer, value: int64(2)},
+		{oid: "1.3.6.1.2.1.2.2.1.2.2", tag: gosnmp.OctetString, value: []byte("eth0")},
+		{oid: "1.3.6.1.2.1.31.1.1.1.10.2", tag: gosnmp.Counter64, value: uint64(1 << 40)},
+		{oid: "1.3.6.1.4.1.99999.1", tag: gosnmp.IPAddress, value: "10.0.0.1"},
+		{oid: "1.3.6.1.4.1.99999.2", tag: gosnmp.Null},
+		{oid: "1.3.6.1.4.1.99999.3", tag: gosnmp.Integer, value: int64(-300)},
+	}}, tr)
+
+	_, err = decoder.decode(v2cMessage(t, "private", trapPDUs("1.3.6.1.6.3.1.1.5.3")))
+	assert.EqualError(t, err, `unknown community "private"`)
+
+	_, err = newTrapDecoder(&Config{Users: testUsers}).decode(v2cMessage(t, "public", trapPDUs("1.3.6.1.6.3.1.1.5.3")))
+	assert.EqualError(t, err, `unknown community "public"`)
+}
+
+func TestDecodeV3(t *testing.T) {
+	decoder := newTrapDecoder(&Config{Users: testUsers})
+	for _, user := range testUsers {
+		t.Run(user.Name, func(t *testing.T) {
+			tr, err := decoder.decode(v3Message(t, user, 3, 1234, trapPDUs("1.3.6.1.6.3.1.1.5.1")))
+			require.NoError(t, err)
+			assert.Equal(t, "3", tr.version)
+			assert.Equal(t, user.Name, tr.user)
+			assert.Equal(t, "ctx", tr.contextName)
+			require.Len(t, tr.varbinds, 2)
+			assert.Equal(t, "1.3.6.1.6.3.1.1.5.1", tr.varbinds[1].value)
+		})
+	}
+}
+
+func TestDecodeV3Rejected(t *testing.T) {
+	decoder := newTrapDecoder(&Config{Users: testUsers})
+	pdus := trapPDUs("1.3.6.1.6.3.1.1.5.1")
+
+	_, err := decoder.decode(v3Message(t, UserConfig{Name: "unknown"}, 3, 1234, pdus))
+	assert.EqualError(t, err, `unknown user "unknown"`)
+
+	_, err = decoder.decode(v3Message(t, UserConfig{Name: "md5"}, 3, 1234, pdus))
+	assert.EqualError(t, err, `security level 0x0 of user "md5" doesn't match its configured one 0x1`)
+
+	_, err = decoder.decode(v3Message(t, UserConfig{Name: "md5", AuthProtocol: "MD5", AuthPassword: "wrongpassword"}, 3, 1234, pdus))
+	assert.ErrorContains(t, err, `user "md5": incoming packet is not authentic`)
+
+	message := v3Message(t, testUsers[2], 3, 1234, pdus)
+	message[len(message)-1] ^= 0xff
+	_, err = decoder.decode(message)
+	assert.Error(t, err)
+}
+
+func TestDecodeV3TimeWindow(t *testing.T) {
+	decoder := newTrapDecoder(&Config{Users: testUsers})
+	now := time.Date(2022, 10, 16, 12, 0, 0, 0, time.UTC)
+	decoder.clocks.now = func() time.Time { return now }
+	pdus := trapPDUs("1.3.6.1.6.3.1.1.5.1")
+
+	message := v3Message(t, testUsers[1], 3, 1234, pdus)
+	_, err := decoder.decode(message)
+	require.NoError(t, err)
+
+	// replays are accepted within the time window of the engine
+	now = now.Add(timeWindow * time.Second)
+	_, err = decoder.decode(message)
+	require.NoError(t, err)
+
+	now = now.Add(time.Second)
+	_, err = decoder.decode(message)
+	assert.EqualError(t, err, `user "md5": engine boots 3 and time 1234 outside the time window of engine boots 3 and time 1385`)
+
+	_, err = decoder.decode(v3Message(t, testUsers[1], 3, 1385, pdus))
+	require.NoError(t, err)
+	_, err = decoder.decode(v3Message(t, testUsers[1], 2, 5000, pdus))
+	assert.EqualError(t, err, `user "md5": engine boots 2 and time 5000 outside the time window of engine boots 3 and time 1385`)
+
+	// the engine rebooted
+	_, err = decoder.decode(v3Message(t, testUsers[1], 4, 10, pdus))
+	require.NoError(t, err)
+	_, err = decoder.decode(v3Message(t, testUsers[1], maxEngineBoots, 10, pdus))
+	assert.EqualError(t, err, `user "md5": engine boots 2147483647 reached their maximum`)
+
+	// unauthenticated messages have no time window
+	_, err = decoder.decode(v3Message(t, testUsers[0], 1, 10, pdus))
+	require.NoError(t, err)
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	decoder := newTrapDecoder(&Config{Communities: []string{"public"}})
+	getRequest := []gosnmp.SnmpPDU{{Name: "." + sysUpTimeInstance, Type: gosnmp.Null}}
+	for name, test := range map[string]struct {
+		message     []byte
+		expectedErr string
+	}{
+		"not SNMP": {
+			message:     []byte("not an SNMP message"),
+			expectedErr: "invalid packet header",
+		},
+		"truncated": {
+			message:     v2cMessage(t, "public", trapPDUs("1.3.6.1.6.3.1.1.5.1"))[:20],
+			expectedErr: "error verifying packet sanity",
+		},
+		"v1": {
+			message:     encode(t, &gosnmp.GoSNMP{Version: gosnmp.Version1, Community: "public"}, gosnmp.GetRequest, getRequest),
+			expectedErr: "SNMPv1 traps aren't supported",
+		},
+		"get request": {
+			message:     encode(t, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"}, gosnmp.GetRequest, getRequest),
+			expectedErr: "unsupported PDU type 0xa0",
+		},
+		"inform": {
+			message:     encode(t, &gosnmp.GoSNMP{Version: gosnmp.Version2c, Community: "public"}, gosnmp.InformRequest, trapPDUs("1.3.6.1.6.3.1.1.5.1")),
+			expectedErr: "unsupported PDU type 0xa6",
+		},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := decoder.decode(test.message)
+			assert.ErrorContains(t, err, test.expectedErr)
+		})
+	}
+}
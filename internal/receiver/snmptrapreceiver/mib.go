@@ -0,0 +1,420 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// maxMIBFileSize skips files of MIB directories that are too large to be MIB modules.
+const maxMIBFileSize = 10 << 20
+
+// mibObject is a named node of the OID tree, with the labels of its enumerated integer values.
+type mibObject struct {
+	enums  map[int64]string
+	module string
+	name   string
+}
+
+// builtinObjects are the nodes resolved without MIB files: the roots MIB modules define their objects
+// under, and the objects of the generic traps.
+var builtinObjects = []struct {
+	oid, module, name string
+	enums             map[int64]string
+}{
+	{oid: "0", name: "ccitt"},
+	{oid: "1", name: "iso"},
+	{oid: "2", name: "joint-iso-ccitt"},
+	{oid: "1.3", module: "SNMPv2-SMI", name: "org"},
+	{oid: "1.3.6", module: "SNMPv2-SMI", name: "dod"},
+	{oid: "1.3.6.1", module: "SNMPv2-SMI", name: "internet"},
+	{oid: "1.3.6.1.1", module: "SNMPv2-SMI", name: "directory"},
+	{oid: "1.3.6.1.2", module: "SNMPv2-SMI", name: "mgmt"},
+	{oid: "1.3.6.1.2.1", module: "SNMPv2-SMI", name: "mib-2"},
+	{oid: "1.3.6.1.2.1.10", module: "SNMPv2-SMI", name: "transmission"},
+	{oid: "1.3.6.1.3", module: "SNMPv2-SMI", name: "experimental"},
+	{oid: "1.3.6.1.4", module: "SNMPv2-SMI", name: "private"},
+	{oid: "1.3.6.1.4.1", module: "SNMPv2-SMI", name: "enterprises"},
+	{oid: "1.3.6.1.5", module: "SNMPv2-SMI", name: "security"},
+	{oid: "1.3.6.1.6", module: "SNMPv2-SMI", name: "snmpV2"},
+	{oid: "1.3.6.1.6.1", module: "SNMPv2-SMI", name: "snmpDomains"},
+	{oid: "1.3.6.1.6.2", module: "SNMPv2-SMI", name: "snmpProxys"},
+	{oid: "1.3.6.1.6.3", module: "SNMPv2-SMI", name: "snmpModules"},
+	{oid: "1.3.6.1.2.1.1", module: "SNMPv2-MIB", name: "system"},
+	{oid: "1.3.6.1.2.1.1.1", module: "SNMPv2-MIB", name: "sysDescr"},
+	{oid: "1.3.6.1.2.1.1.2", module: "SNMPv2-MIB", name: "sysObjectID"},
+	{oid: "1.3.6.1.2.1.1.3", module: "SNMPv2-MIB", name: "sysUpTime"},
+	{oid: "1.3.6.1.2.1.1.4", module: "SNMPv2-MIB", name: "sysContact"},
+	{oid: "1.3.6.1.2.1.1.5", module: "SNMPv2-MIB", name: "sysName"},
+	{oid: "1.3.6.1.2.1.1.6", module: "SNMPv2-MIB", name: "sysLocation"},
+	{oid: "1.3.6.1.6.3.1", module: "SNMPv2-MIB", name: "snmpMIB"},
+	{oid: "1.3.6.1.6.3.1.1.4.1", module: "SNMPv2-MIB", name: "snmpTrapOID"},
+	{oid: "1.3.6.1.6.3.1.1.4.3", module: "SNMPv2-MIB", name: "snmpTrapEnterprise"},
+	{oid: "1.3.6.1.6.3.1.1.5.1", module: "SNMPv2-MIB", name: "coldStart"},
+	{oid: "1.3.6.1.6.3.1.1.5.2", module: "SNMPv2-MIB", name: "warmStart"},
+	{oid: "1.3.6.1.6.3.1.1.5.3", module: "IF-MIB", name: "linkDown"},
+	{oid: "1.3.6.1.6.3.1.1.5.4", module: "IF-MIB", name: "linkUp"},
+	{oid: "1.3.6.1.6.3.1.1.5.5", module: "SNMPv2-MIB", name: "authenticationFailure"},
+	{oid: "1.3.6.1.2.1.2.2.1.1", module: "IF-MIB", name: "ifIndex"},
+	{oid: "1.3.6.1.2.1.2.2.1.2", module: "IF-MIB", name: "ifDescr"},
+	{oid: "1.3.6.1.2.1.2.2.1.7", module: "IF-MIB", name: "ifAdminStatus", enums: map[int64]string{1: "up", 2: "down", 3: "testing"}},
+	{oid: "1.3.6.1.2.1.2.2.1.8", module: "IF-MIB", name: "ifOperStatus", enums: map[int64]string{
+		1: "up", 2: "down", 3: "testing", 4: "unknown", 5: "dormant", 6: "notPresent", 7: "lowerLayerDown",
+	}},
+}
+
+// mibTree resolves OIDs to the names of the MIB objects they identify, or are instances of.
+type mibTree struct {
+	objects map[string]*mibObject
+}
+
+// resolve returns the module qualified name of the OID, like IF-MIB::ifOperStatus.2 for an instance of
+// the ifOperStatus object, with the object, or false if no object prefixes the OID.
+func (t *mibTree) resolve(oid string) (string, *mibObject, bool) {
+	for prefix := oid; prefix != ""; {
+		if object, ok := t.objects[prefix]; ok {
+			name := object.name
+			if object.module != "" {
+				name = object.module + "::" + name
+			}
+			return name + oid[len(prefix):], object, true
+		}
+		i := strings.LastIndexByte(prefix, '.')
+		if i < 0 {
+			break
+		}
+		prefix = prefix[:i]
+	}
+	return "", nil, false
+}
+
+// mibDefinition is an OID assignment of a MIB module, like `ifOperStatus OBJECT-TYPE ... ::= { ifEntry 8 }`.
+type mibDefinition struct {
+	enums  map[int64]string
+	module string
+	name   string
+	parent string
+	// the arcs from the parent, with the names of the intermediate nodes like `org(3)`
+	arcs   []string
+	syntax string
+}
+
+// mibParser collects the definitions of MIB modules, which can reference those of other modules.
+type mibParser struct {
+	definitions map[string]*mibDefinition
+	// the enumerations of textual conventions, by their name
+	conventions map[string]map[int64]string
+}
+
+// loadMIBs parses the MIB files of the directories, returning the tree of their objects that can be
+// resolved, and the names of those that can't.
+func loadMIBs(directories []string) (*mibTree, []string, error) {
+	p := &mibParser{definitions: map[string]*mibDefinition{}, conventions: map[string]map[int64]string{}}
+	for _, directory := range directories {
+		err := filepath.WalkDir(directory, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+				return nil
+			}
+			info, err := entry.Info()
+			if err != nil || !info.Mode().IsRegular() || info.Size() > maxMIBFileSize {
+				return err
+			}
+			content, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			p.parse(string(content))
+			return nil
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed loading MIBs of %s: %w", directory, err)
+		}
+	}
+	tree, unresolved := p.tree()
+	return tree, unresolved, nil
+}
+
+// mibMacros are the macros whose invocations assign an OID to their name.
+var mibMacros = map[string]bool{
+	"MODULE-IDENTITY":    true,
+	"OBJECT-IDENTITY":    true,
+	"OBJECT-TYPE":        true,
+	"NOTIFICATION-TYPE":  true,
+	"TRAP-TYPE":          true,
+	"OBJECT-GROUP":       true,
+	"NOTIFICATION-GROUP": true,
+	"MODULE-COMPLIANCE":  true,
+	"AGENT-CAPABILITIES": true,
+}
+
+// parse collects the definitions of the MIB module text.  Constructs that don't assign OIDs or define
+// enumerations are skipped, so that modules don't need to be valid SMI to resolve their objects.
+func (p *mibParser) parse(text string) {
+	tokens := tokenizeMIB(text)
+	module := ""
+	for i := 0; i < len(tokens); i++ {
+		token := tokens[i]
+		next := tokenAt(tokens, i+1)
+		switch {
+		case next == "DEFINITIONS":
+			module = token
+		case next == "::=" && isTypeName(token):
+			// textual conventions and type assignments with enumerations, like `Status ::= INTEGER { up(1) }`
+			end := indexOf(tokens, "::=", i+2)
+			if end < 0 {
+				end = len(tokens)
+			}
+			if syntax := indexOf(tokens[:end], "SYNTAX", i+2); syntax >= 0 {
+				if enums, ok := parseEnums(tokens, syntax+2); ok {
+					p.conventions[token] = enums
+				}
+			} else if enums, ok := parseEnums(tokens, i+3); ok {
+				p.conventions[token] = enums
+			}
+		case isValueName(token) && (mibMacros[next] || (next == "OBJECT" && tokenAt(tokens, i+2) == "IDENTIFIER")):
+			assignment := indexOf(tokens, "::=", i+2)
+			if assignment < 0 {
+				return
+			}
+			definition := &mibDefinition{module: module, name: token}
+			body := tokens[i+2 : assignment]
+			if syntax := indexOf(body, "SYNTAX", 0); syntax >= 0 {
+				definition.syntax = tokenAt(body, syntax+1)
+				definition.enums, _ = parseEnums(body, syntax+2)
+			}
+			if next == "TRAP-TYPE" {
+				// SNMPv1 traps are translated to the enterprise OID, 0, and the specific trap number (RFC 3584)
+				enterprise := indexOf(body, "ENTERPRISE", 0)
+				definition.parent = tokenAt(body, enterprise+1)
+				definition.arcs = []string{"0", tokenAt(tokens, assignment+1)}
+				i = assignment + 1
+			} else {
+				end := indexOf(tokens, "}", assignment)
+				if end < 0 || tokenAt(tokens, assignment+1) != "{" {
+					continue
+				}
+				components := tokens[assignment+2 : end]
+				if len(components) == 0 {
+					continue
+				}
+				definition.parent, definition.arcs = components[0], components[1:]
+				i = end
+			}
+			if definition.parent != "" {
+				p.definitions[token] = definition
+			}
+		}
+	}
+}
+
+// tree resolves the OIDs of the definitions.
+func (p *mibParser) tree() (*mibTree, []string) {
+	tree := &mibTree{objects: map[string]*mibObject{}}
+	oids := map[string]string{}
+	for _, builtin := range builtinObjects {
+		tree.objects[builtin.oid] = &mibObject{module: builtin.module, name: builtin.name, enums: builtin.enums}
+		oids[builtin.name] = builtin.oid
+	}
+
+	var resolve func(name string, depth int) (string, bool)
+	resolve = func(name string, depth int) (string, bool) {
+		if oid, ok := oids[name]; ok {
+			return oid, true
+		}
+		definition, ok := p.definitions[name]
+		if !ok || depth > 64 {
+			return "", false
+		}
+		oid, ok := definition.parent, true
+		if _, err := strconv.ParseUint(definition.parent, 10, 32); err != nil {
+			if oid, ok = resolve(definition.parent, depth+1); !ok {
+				return "", false
+			}
+		}
+		for _, arc := range definition.arcs {
+			label, number, named := parseNamedNumber(arc)
+			if _, err := strconv.ParseUint(number, 10, 32); err != nil {
+				return "", false
+			}
+			oid += "." + number
+			if named {
+				if _, defined := tree.objects[oid]; !defined {
+					tree.objects[oid] = &mibObject{module: definition.module, name: label}
+				}
+				oids[label] = oid
+			}
+		}
+		enums := definition.enums
+		if enums == nil {
+			enums = p.conventions[definition.syntax]
+		}
+		tree.objects[oid] = &mibObject{module: definition.module, name: definition.name, enums: enums}
+		oids[name] = oid
+		return oid, true
+	}
+
+	var unresolved []string
+	for name := range p.definitions {
+		if _, ok := resolve(name, 0); !ok {
+			unresolved = append(unresolved, name)
+		}
+	}
+	return tree, unresolved
+}
+
+// parseNamedNumber splits OID components like `org(3)`, which are tokenized as a single component.
+func parseNamedNumber(arc string) (string, string, bool) {
+	if label, number, found := strings.Cut(arc, "("); found {
+		return label, strings.TrimSuffix(number, ")"), true
+	}
+	return "", arc, false
+}
+
+// parseEnums parses the enumeration at the start index, like `{ up(1), down(2) }`, returning false if there's none.
+func parseEnums(tokens []string, start int) (map[int64]string, bool) {
+	if tokenAt(tokens, start) != "{" {
+		return nil, false
+	}
+	enums := map[int64]string{}
+	for i := start + 1; i < len(tokens) && tokens[i] != "}"; i++ {
+		label, number, named := parseNamedNumber(tokens[i])
+		if !named {
+			continue
+		}
+		if value, err := strconv.ParseInt(number, 10, 64); err == nil {
+			enums[value] = label
+		}
+	}
+	return enums, len(enums) != 0
+}
+
+// tokenizeMIB splits the MIB text into identifiers, numbers, and symbols, with `name(number)` as a single
+// token, and without comments and quoted strings like descriptions.
+func tokenizeMIB(text string) []string {
+	var tokens []string
+	runes := []rune(text)
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case r == '-' && i+1 < len(runes) && runes[i+1] == '-':
+			// comments end at the end of the line or the next --
+			i += 2
+			for i < len(runes) && runes[i] != '\n' {
+				if runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-' {
+					i++
+					break
+				}
+				i++
+			}
+			i++
+		case r == '"':
+			i++
+			for i < len(runes) && runes[i] != '"' {
+				i++
+			}
+			i++
+		case r == ':' && strings.HasPrefix(string(runes[i:min(i+3, len(runes))]), "::="):
+			tokens = append(tokens, "::=")
+			i += 3
+		case isIdentifierRune(r):
+			start := i
+			for i < len(runes) && isIdentifierRune(runes[i]) && !(runes[i] == '-' && i+1 < len(runes) && runes[i+1] == '-') {
+				i++
+			}
+			token := string(runes[start:i])
+			// named numbers, like up(1) and org(3)
+			if j := skipSpaces(runes, i); j < len(runes) && runes[j] == '(' {
+				if k := indexRune(runes, ')', j); k > 0 && isNumber(strings.TrimSpace(string(runes[j+1:k]))) {
+					token += "(" + strings.TrimSpace(string(runes[j+1:k])) + ")"
+					i = k + 1
+				}
+			}
+			tokens = append(tokens, token)
+		default:
+			tokens = append(tokens, string(r))
+			i++
+		}
+	}
+	return tokens
+}
+
+func isIdentifierRune(r rune) bool {
+	return r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_')
+}
+
+func isNumber(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+// isValueName returns whether the token is a value reference, which starts with a lowercase letter.
+func isValueName(token string) bool {
+	return token != "" && unicode.IsLower(rune(token[0]))
+}
+
+// isTypeName returns whether the token is a type reference, which starts with an uppercase letter.
+func isTypeName(token string) bool {
+	return token != "" && unicode.IsUpper(rune(token[0]))
+}
+
+func tokenAt(tokens []string, i int) string {
+	if i < 0 || i >= len(tokens) {
+		return ""
+	}
+	return tokens[i]
+}
+
+func indexOf(tokens []string, token string, start int) int {
+	for i := start; i < len(tokens); i++ {
+		if tokens[i] == token {
+			return i
+		}
+	}
+	return -1
+}
+
+func skipSpaces(runes []rune, i int) int {
+	for i < len(runes) && unicode.IsSpace(runes[i]) {
+		i++
+	}
+	return i
+}
+
+func indexRune(runes []rune, r rune, start int) int {
+	for i := start; i < len(runes); i++ {
+		if runes[i] == r {
+			return i
+		}
+	}
+	return -1
+}
+
+func min(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,66 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadMIBs(t *testing.T) {
+	tree, unresolved, err := loadMIBs([]string{filepath.Join("testdata", "mibs")})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"testMissingParent"}, unresolved)
+
+	for oid, expected := range map[string]string{
+		"1.3.6.1.4.1.99999":         "TEST-MIB::testMIB",
+		"1.3.6.1.4.1.99999.0.1":     "TEST-MIB::testAlarm",
+		"1.3.6.1.4.1.99999.1.2.0":   "TEST-MIB::testAlarmText.0",
+		"1.3.6.1.4.1.99999.1.3.7.1": "TEST-MIB::testAlarmState.7.1",
+		"1.3.6.1.4.1.99999.2":       "TEST-MIB::testMIB.2",
+		"1.3.6.1.4.1.99998.0.1":     "TEST-V1-MIB::testV1Restarted",
+		"1.3.6.1.4.1.12345.1":       "SNMPv2-SMI::enterprises.12345.1",
+		"1.3.6.1.6.3.1.1.5.3":       "IF-MIB::linkDown",
+		"1.3.6.1.2.1.2.2.1.8.2":     "IF-MIB::ifOperStatus.2",
+		"2.5":                       "joint-iso-ccitt.5",
+	} {
+		name, _, ok := tree.resolve(oid)
+		assert.True(t, ok, oid)
+		assert.Equal(t, expected, name, oid)
+	}
+	_, _, ok := tree.resolve("3.1")
+	assert.False(t, ok)
+
+	_, severity, _ := tree.resolve("1.3.6.1.4.1.99999.1.1.0")
+	assert.Equal(t, map[int64]string{1: "cleared", 2: "minor", 3: "major", 4: "critical"}, severity.enums)
+	_, state, _ := tree.resolve("1.3.6.1.4.1.99999.1.3.0")
+	assert.Equal(t, map[int64]string{1: "active", 2: "inactive"}, state.enums)
+	_, text, _ := tree.resolve("1.3.6.1.4.1.99999.1.2.0")
+	assert.Nil(t, text.enums)
+}
+
+func TestLoadMIBsMissingDirectory(t *testing.T) {
+	_, _, err := loadMIBs([]string{filepath.Join("testdata", "missing")})
+	assert.ErrorContains(t, err, "failed loading MIBs of testdata/missing")
+}
+
+func TestTokenizeMIB(t *testing.T) {
+	assert.Equal(t,
+		[]string{"a", "OBJECT", "IDENTIFIER", "::=", "{", "iso", "org(3)", "b-c", "4", "}", "d", "e"},
+		tokenizeMIB("a OBJECT IDENTIFIER ::= -- comment\n{ iso org (3) b-c 4 } \"description\n-- of d\" d -- inline -- e"))
+}
@@ -0,0 +1,129 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sync"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/consumer"
+	"go.opentelemetry.io/collector/obsreport"
+	"go.uber.org/zap"
+)
+
+const (
+	udpTransport = "udp"
+
+	// maxMessageSize is the largest UDP payload.
+	maxMessageSize = 65535
+)
+
+// snmpTrapReceiver converts the SNMP traps it receives to logs.
+type snmpTrapReceiver struct {
+	nextConsumer consumer.Logs
+	conn         net.PacketConn
+	obsrecv      *obsreport.Receiver
+	decoder      *trapDecoder
+	mibs         *mibTree
+	config       *Config
+	settings     component.ReceiverCreateSettings
+	shutdownWG   sync.WaitGroup
+}
+
+var _ component.LogsReceiver = (*snmpTrapReceiver)(nil)
+
+func newReceiver(settings component.ReceiverCreateSettings, config *Config, nextConsumer consumer.Logs) *snmpTrapReceiver {
+	return &snmpTrapReceiver{
+		nextConsumer: nextConsumer,
+		config:       config,
+		settings:     settings,
+		decoder:      newTrapDecoder(config),
+		obsrecv: obsreport.NewReceiver(obsreport.ReceiverSettings{
+			ReceiverID:             config.ID(),
+			Transport:              udpTransport,
+			ReceiverCreateSettings: settings,
+		}),
+	}
+}
+
+func (r *snmpTrapReceiver) Start(_ context.Context, _ component.Host) error {
+	mibs, unresolved, err := loadMIBs(r.config.MIBDirectories)
+	if err != nil {
+		return err
+	}
+	if len(unresolved) != 0 {
+		r.settings.Logger.Warn("MIB objects whose OIDs can't be resolved, missing MIB modules they reference?",
+			zap.Int("count", len(unresolved)), zap.Strings("objects", unresolved))
+	}
+	r.settings.Logger.Debug("Loaded MIBs", zap.Int("objects", len(mibs.objects)))
+	r.mibs = mibs
+
+	if r.conn, err = net.ListenPacket(udpTransport, r.config.Endpoint); err != nil {
+		return fmt.Errorf("failed to bind to address %s: %w", r.config.Endpoint, err)
+	}
+	r.shutdownWG.Add(1)
+	go func() {
+		defer r.shutdownWG.Done()
+		r.serve()
+	}()
+	return nil
+}
+
+func (r *snmpTrapReceiver) Shutdown(context.Context) error {
+	if r.conn == nil {
+		return nil
+	}
+	err := r.conn.Close()
+	r.shutdownWG.Wait()
+	return err
+}
+
+// serve consumes the traps received until the connection is closed.
+func (r *snmpTrapReceiver) serve() {
+	buf := make([]byte, maxMessageSize)
+	for {
+		n, peer, err := r.conn.ReadFrom(buf)
+		if errors.Is(err, net.ErrClosed) {
+			return
+		}
+		if err != nil {
+			r.settings.Logger.Debug("Failed to read SNMP message", zap.Error(err))
+			continue
+		}
+		r.consume(buf[:n], peer)
+	}
+}
+
+// consume provides the log of the message to the next consumer.  Messages that aren't traps of accepted
+// communities and users are reported as refused.
+func (r *snmpTrapReceiver) consume(message []byte, peer net.Addr) {
+	ctx := r.obsrecv.StartLogsOp(context.Background())
+	tr, err := r.decoder.decode(message)
+	if err != nil {
+		r.settings.Logger.Debug("Dropping invalid SNMP message", zap.Stringer("peer", peer), zap.Error(err))
+		r.obsrecv.EndLogsOp(ctx, typeStr, 1, err)
+		return
+	}
+	err = r.nextConsumer.ConsumeLogs(ctx, r.mibs.newLogs(tr, peer, time.Now()))
+	r.obsrecv.EndLogsOp(ctx, typeStr, 1, err)
+	if err != nil {
+		r.settings.Logger.Debug("Failed to consume SNMP trap", zap.Stringer("peer", peer), zap.Error(err))
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"context"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config"
+	"go.opentelemetry.io/collector/consumer/consumertest"
+)
+
+func TestReceiver(t *testing.T) {
+	cfg := &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
+		Endpoint:         "127.0.0.1:0",
+		Communities:      []string{"public"},
+		Users:            testUsers,
+		MIBDirectories:   []string{filepath.Join("testdata", "mibs")},
+	}
+	sink := &consumertest.LogsSink{}
+	r := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, sink)
+	require.NoError(t, r.Start(context.Background(), componenttest.NewNopHost()))
+	defer func() {
+		require.NoError(t, r.Shutdown(context.Background()))
+	}()
+
+	conn, err := net.Dial("udp", r.conn.LocalAddr().String())
+	require.NoError(t, err)
+	defer conn.Close()
+
+	pdus := trapPDUs("1.3.6.1.4.1.99999.0.1", gosnmp.SnmpPDU{Name: ".1.3.6.1.4.1.99999.1.1.0", Type: gosnmp.Integer, Value: 4})
+	for _, message := range [][]byte{
+		v2cMessage(t, "private", pdus),
+		[]byte("not an SNMP message"),
+		v2cMessage(t, "public", pdus),
+		v3Message(t, testUsers[3], 3, 1234, pdus),
+	} {
+		_, err = conn.Write(message)
+		require.NoError(t, err)
+	}
+
+	require.Eventually(t, func() bool { return sink.LogRecordCount() == 2 }, 5*time.Second, 10*time.Millisecond)
+	logs := sink.AllLogs()
+	for i, user := range []string{"", "sha256-aes"} {
+		lr := logs[i].ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		assert.Equal(t, "TEST-MIB::testAlarm", lr.Body().StringVal())
+		userVal, ok := lr.Attributes().Get(userAttr)
+		assert.Equal(t, user != "", ok)
+		if ok {
+			assert.Equal(t, user, userVal.StringVal())
+		}
+		varbinds, ok := lr.Attributes().Get(varbindsAttr)
+		require.True(t, ok)
+		assert.Equal(t, map[string]any{"TEST-MIB::testAlarmSeverity.0": "critical"}, varbinds.MapVal().AsRaw())
+	}
+}
+
+func TestReceiverStartErrors(t *testing.T) {
+	cfg := &Config{
+		ReceiverSettings: config.NewReceiverSettings(config.NewComponentID(typeStr)),
+		Endpoint:         "127.0.0.1:0",
+		Communities:      []string{"public"},
+		MIBDirectories:   []string{filepath.Join("testdata", "missing")},
+	}
+	r := newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewNop())
+	assert.ErrorContains(t, r.Start(context.Background(), componenttest.NewNopHost()), "failed loading MIBs")
+
+	cfg.MIBDirectories = nil
+	cfg.Endpoint = "invalid"
+	r = newReceiver(componenttest.NewNopReceiverCreateSettings(), cfg, consumertest.NewNop())
+	assert.ErrorContains(t, r.Start(context.Background(), componenttest.NewNopHost()), "failed to bind to address invalid")
+	require.NoError(t, r.Shutdown(context.Background()))
+}
@@ -0,0 +1,154 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"encoding/hex"
+	"math"
+	"net"
+	"strconv"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/gosnmp/gosnmp"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+	conventions "go.opentelemetry.io/collector/semconv/v1.6.1"
+)
+
+const (
+	scopeName = "github.com/signalfx/splunk-otel-collector/internal/receiver/snmptrapreceiver"
+
+	trapOIDAttr  = "snmp.trap.oid"
+	trapNameAttr = "snmp.trap.name"
+	versionAttr  = "snmp.version"
+	userAttr     = "snmp.user"
+	contextAttr  = "snmp.context"
+	uptimeAttr   = "snmp.uptime"
+	varbindsAttr = "snmp.varbinds"
+
+	// the instances of the variable bindings that start every SNMPv2-Trap PDU (RFC 3416 4.2.6)
+	sysUpTimeInstance   = "1.3.6.1.2.1.1.3.0"
+	snmpTrapOIDInstance = "1.3.6.1.6.3.1.1.4.1.0"
+)
+
+// newLogs returns the log record of the trap received from the peer, whose body is the name of the trap
+// and whose snmp.varbinds attribute maps the names of its variable bindings to their values.
+func (t *mibTree) newLogs(tr *trap, peer net.Addr, received time.Time) plog.Logs {
+	ld := plog.NewLogs()
+	sl := ld.ResourceLogs().AppendEmpty().ScopeLogs().AppendEmpty()
+	sl.Scope().SetName(scopeName)
+
+	lr := sl.LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(received))
+	lr.SetObservedTimestamp(pcommon.NewTimestampFromTime(received))
+
+	attrs := lr.Attributes()
+	attrs.InsertString(versionAttr, tr.version)
+	if tr.user != "" {
+		attrs.InsertString(userAttr, tr.user)
+	}
+	if tr.contextName != "" {
+		attrs.InsertString(contextAttr, tr.contextName)
+	}
+	if udpAddr, ok := peer.(*net.UDPAddr); ok {
+		attrs.InsertString(conventions.AttributeNetPeerIP, udpAddr.IP.String())
+		attrs.InsertInt(conventions.AttributeNetPeerPort, int64(udpAddr.Port))
+	}
+
+	varbindsVal := pcommon.NewValueMap()
+	varbinds := varbindsVal.MapVal()
+	body := "SNMP trap"
+	for _, vb := range tr.varbinds {
+		switch {
+		case vb.oid == sysUpTimeInstance && vb.tag == gosnmp.TimeTicks:
+			t.insertValue(attrs, uptimeAttr, nil, vb)
+		case vb.oid == snmpTrapOIDInstance && vb.tag == gosnmp.ObjectIdentifier:
+			oid := vb.value.(string)
+			attrs.InsertString(trapOIDAttr, oid)
+			if name, _, ok := t.resolve(oid); ok {
+				attrs.InsertString(trapNameAttr, name)
+				body = name
+			} else {
+				body = oid
+			}
+		default:
+			name, object, ok := t.resolve(vb.oid)
+			if !ok {
+				name = vb.oid
+			}
+			t.insertValue(varbinds, name, object, vb)
+		}
+	}
+	lr.Body().SetStringVal(body)
+	if varbinds.Len() != 0 {
+		attrs.Insert(varbindsAttr, varbindsVal)
+	}
+	return ld
+}
+
+// insertValue inserts the value of the variable binding, with the label of enumerated integers of the
+// object, the name of OIDs, and octet strings as text if they're printable or hex otherwise.
+func (t *mibTree) insertValue(m pcommon.Map, key string, object *mibObject, vb varbind) {
+	switch value := vb.value.(type) {
+	case int64:
+		if object != nil && object.enums[value] != "" {
+			m.InsertString(key, object.enums[value])
+		} else {
+			m.InsertInt(key, value)
+		}
+	case uint64:
+		if value <= math.MaxInt64 {
+			m.InsertInt(key, int64(value))
+		} else {
+			m.InsertString(key, strconv.FormatUint(value, 10))
+		}
+	case float64:
+		m.InsertDouble(key, value)
+	case string:
+		if vb.tag == gosnmp.ObjectIdentifier {
+			if name, _, ok := t.resolve(value); ok {
+				value = name
+			}
+		}
+		m.InsertString(key, value)
+	case []byte:
+		if text, ok := printable(value); ok && vb.tag == gosnmp.OctetString {
+			m.InsertString(key, text)
+		} else {
+			m.InsertString(key, hex.EncodeToString(value))
+		}
+	default:
+		m.Insert(key, pcommon.NewValueEmpty())
+	}
+}
+
+// printable returns the octet string as text if it's printable UTF-8, ignoring trailing NUL padding.
+func printable(value []byte) (string, bool) {
+	for len(value) != 0 && value[len(value)-1] == 0 {
+		value = value[:len(value)-1]
+	}
+	if !utf8.Valid(value) {
+		return "", false
+	}
+	text := string(value)
+	for _, r := range text {
+		if !unicode.IsPrint(r) && r != '\t' && r != '\n' && r != '\r' {
+			return "", false
+		}
+	}
+	return text, true
+}
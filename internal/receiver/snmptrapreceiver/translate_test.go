@@ -0,0 +1,93 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func TestNewLogs(t *testing.T) {
+	tree, _, err := loadMIBs([]string{filepath.Join("testdata", "mibs")})
+	require.NoError(t, err)
+
+	received := time.Date(2022, 10, 16, 12, 0, 0, 0, time.UTC)
+	ld := tree.newLogs(&trap{version: "3", user: "admin", contextName: "ctx", varbinds: []varbind{
+		{oid: sysUpTimeInstance, tag: gosnmp.TimeTicks, value: uint64(12345)},
+		{oid: snmpTrapOIDInstance, tag: gosnmp.ObjectIdentifier, value: "1.3.6.1.4.1.99999.0.1"},
+		{oid: "1.3.6.1.4.1.99999.1.1.0", tag: gosnmp.Integer, value: int64(3)},
+		{oid: "1.3.6.1.4.1.99999.1.2.0", tag: gosnmp.OctetString, value: []byte("disk full\x00")},
+		{oid: "1.3.6.1.4.1.99999.1.3.0", tag: gosnmp.Integer, value: int64(5)},
+		{oid: "1.3.6.1.4.1.99999.1.4.0", tag: gosnmp.OctetString, value: []byte{0x00, 0x1a, 0x2b}},
+		{oid: "1.3.6.1.4.1.99999.1.5.0", tag: gosnmp.ObjectIdentifier, value: "1.3.6.1.6.3.1.1.5.3"},
+		{oid: "1.3.6.1.4.1.99999.1.6.0", tag: gosnmp.Counter64, value: uint64(1 << 63)},
+		{oid: "1.3.6.1.4.1.99999.1.7.0", tag: gosnmp.NoSuchInstance},
+		{oid: "3.1", tag: gosnmp.Gauge32, value: uint64(7)},
+		{oid: "3.2", tag: gosnmp.OpaqueFloat, value: float64(1.5)},
+	}}, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 40000}, received)
+
+	require.Equal(t, 1, ld.LogRecordCount())
+	sl := ld.ResourceLogs().At(0).ScopeLogs().At(0)
+	assert.Equal(t, scopeName, sl.Scope().Name())
+	lr := sl.LogRecords().At(0)
+	assert.Equal(t, pcommon.NewTimestampFromTime(received), lr.Timestamp())
+	assert.Equal(t, "TEST-MIB::testAlarm", lr.Body().StringVal())
+	assert.Equal(t, map[string]any{
+		"snmp.version":   "3",
+		"snmp.user":      "admin",
+		"snmp.context":   "ctx",
+		"net.peer.ip":    "10.0.0.1",
+		"net.peer.port":  int64(40000),
+		"snmp.uptime":    int64(12345),
+		"snmp.trap.oid":  "1.3.6.1.4.1.99999.0.1",
+		"snmp.trap.name": "TEST-MIB::testAlarm",
+		"snmp.varbinds": map[string]any{
+			"TEST-MIB::testAlarmSeverity.0": "major",
+			"TEST-MIB::testAlarmText.0":     "disk full",
+			"TEST-MIB::testAlarmState.0":    int64(5),
+			"TEST-MIB::testObjects.4.0":     "001a2b",
+			"TEST-MIB::testObjects.5.0":     "IF-MIB::linkDown",
+			"TEST-MIB::testObjects.6.0":     "9223372036854775808",
+			"TEST-MIB::testObjects.7.0":     nil,
+			"3.1":                           int64(7),
+			"3.2":                           1.5,
+		},
+	}, lr.Attributes().AsRaw())
+}
+
+func TestNewLogsUnknownTrap(t *testing.T) {
+	tree, _, err := loadMIBs(nil)
+	require.NoError(t, err)
+	ld := tree.newLogs(&trap{version: "2c", community: "public", varbinds: []varbind{
+		{oid: snmpTrapOIDInstance, tag: gosnmp.ObjectIdentifier, value: "3.1.2"},
+	}}, &net.UDPAddr{IP: net.IPv4(10, 0, 0, 1), Port: 162}, time.Now())
+
+	lr := ld.ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+	assert.Equal(t, "3.1.2", lr.Body().StringVal())
+	attrs := lr.Attributes()
+	_, ok := attrs.Get(trapNameAttr)
+	assert.False(t, ok)
+	_, ok = attrs.Get(varbindsAttr)
+	assert.False(t, ok)
+	assert.Equal(t, plog.SeverityNumberUNDEFINED, lr.SeverityNumber())
+}
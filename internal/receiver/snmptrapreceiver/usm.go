@@ -0,0 +1,135 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+)
+
+// The SNMPv3 authentication and privacy protocols of users.
+const (
+	authMD5    = "MD5"
+	authSHA    = "SHA"
+	authSHA256 = "SHA256"
+	privDES    = "DES"
+	privAES    = "AES"
+)
+
+const (
+	// timeWindow is how many seconds the snmpEngineTime of an authenticated message can be behind the
+	// estimated time of its engine (RFC 3414 2.2.3).
+	timeWindow = 150
+
+	// maxEngineBoots is the snmpEngineBoots of engines that must be rekeyed (RFC 3414 2.2.2).
+	maxEngineBoots = math.MaxInt32
+)
+
+// authProtocols are the gosnmp protocols and truncated HMAC lengths of the authentication protocols.
+var authProtocols = map[string]struct {
+	protocol  gosnmp.SnmpV3AuthProtocol
+	macLength int
+}{
+	authMD5:    {protocol: gosnmp.MD5, macLength: 12},
+	authSHA:    {protocol: gosnmp.SHA, macLength: 12},
+	authSHA256: {protocol: gosnmp.SHA256, macLength: 24},
+}
+
+var privProtocols = map[string]gosnmp.SnmpV3PrivProtocol{
+	privDES: gosnmp.DES,
+	privAES: gosnmp.AES,
+}
+
+// usmUser is a configured SNMPv3 user, whose traps gosnmp authenticates and decrypts with the keys of its
+// passwords, which it localizes to the engine id of each sender.
+type usmUser struct {
+	snmp      *gosnmp.GoSNMP
+	macLength int
+}
+
+func newUSMUser(cfg UserConfig) *usmUser {
+	params := &gosnmp.UsmSecurityParameters{
+		UserName:               cfg.Name,
+		AuthenticationProtocol: gosnmp.NoAuth,
+		PrivacyProtocol:        gosnmp.NoPriv,
+	}
+	user := &usmUser{snmp: &gosnmp.GoSNMP{
+		Version:            gosnmp.Version3,
+		SecurityModel:      gosnmp.UserSecurityModel,
+		MsgFlags:           gosnmp.NoAuthNoPriv,
+		SecurityParameters: params,
+	}}
+	if cfg.AuthProtocol == "" {
+		return user
+	}
+	auth := authProtocols[strings.ToUpper(cfg.AuthProtocol)]
+	params.AuthenticationProtocol, params.AuthenticationPassphrase = auth.protocol, cfg.AuthPassword
+	user.macLength = auth.macLength
+	user.snmp.MsgFlags = gosnmp.AuthNoPriv
+	if cfg.PrivProtocol != "" {
+		params.PrivacyProtocol, params.PrivacyPassphrase = privProtocols[strings.ToUpper(cfg.PrivProtocol)], cfg.PrivPassword
+		user.snmp.MsgFlags = gosnmp.AuthPriv
+	}
+	return user
+}
+
+// securityLevel returns the msgFlags the messages of the user must have.
+func (u *usmUser) securityLevel() gosnmp.SnmpV3MsgFlags {
+	return u.snmp.MsgFlags
+}
+
+// engineClocks are the latest snmpEngineBoots and snmpEngineTime of the engines that sent authenticated
+// traps, to reject the replays of traps outside the time window of their engine (RFC 3414 3.2.7b).
+type engineClocks struct {
+	now     func() time.Time
+	engines map[string]engineClock
+}
+
+// engineClock is the latest snmpEngineBoots and snmpEngineTime of an engine, and when they were received.
+type engineClock struct {
+	received time.Time
+	boots    uint32
+	time     uint32
+}
+
+func newEngineClocks() *engineClocks {
+	return &engineClocks{now: time.Now, engines: map[string]engineClock{}}
+}
+
+// check returns an error if the snmpEngineBoots and snmpEngineTime of an authenticated message of the
+// engine are outside its time window, and otherwise records them if they're its latest.  The first message
+// of an engine sets its clock.
+func (c *engineClocks) check(engineID string, boots, engineTime uint32) error {
+	if boots >= maxEngineBoots {
+		return fmt.Errorf("engine boots %d reached their maximum", boots)
+	}
+	now := c.now()
+	latest, known := c.engines[engineID]
+	if known {
+		estimated := int64(latest.time) + int64(now.Sub(latest.received)/time.Second)
+		if boots < latest.boots || (boots == latest.boots && int64(engineTime) < estimated-timeWindow) {
+			return fmt.Errorf("engine boots %d and time %d outside the time window of engine boots %d and time %d",
+				boots, engineTime, latest.boots, estimated)
+		}
+	}
+	if !known || boots > latest.boots || engineTime > latest.time {
+		c.engines[engineID] = engineClock{received: now, boots: boots, time: engineTime}
+	}
+	return nil
+}
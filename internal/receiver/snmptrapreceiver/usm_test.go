@@ -0,0 +1,63 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package snmptrapreceiver
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gosnmp/gosnmp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewUSMUser(t *testing.T) {
+	user := newUSMUser(UserConfig{Name: "noauth"})
+	assert.Equal(t, gosnmp.NoAuthNoPriv, user.securityLevel())
+	assert.Zero(t, user.macLength)
+
+	user = newUSMUser(UserConfig{Name: "auth", AuthProtocol: "sha", AuthPassword: "password"})
+	assert.Equal(t, gosnmp.AuthNoPriv, user.securityLevel())
+	assert.Equal(t, 12, user.macLength)
+
+	user = newUSMUser(UserConfig{Name: "priv", AuthProtocol: "SHA256", AuthPassword: "password", PrivProtocol: "aes", PrivPassword: "privpassword"})
+	assert.Equal(t, gosnmp.AuthPriv, user.securityLevel())
+	assert.Equal(t, 24, user.macLength)
+	assert.Equal(t, &gosnmp.UsmSecurityParameters{
+		UserName:                 "priv",
+		AuthenticationProtocol:   gosnmp.SHA256,
+		AuthenticationPassphrase: "password",
+		PrivacyProtocol:          gosnmp.AES,
+		PrivacyPassphrase:        "privpassword",
+	}, user.snmp.SecurityParameters)
+}
+
+func TestEngineClocks(t *testing.T) {
+	clocks := newEngineClocks()
+	now := time.Date(2022, 10, 16, 12, 0, 0, 0, time.UTC)
+	clocks.now = func() time.Time { return now }
+
+	require.NoError(t, clocks.check("engine", 1, 1000))
+	// the clocks of engines are independent
+	require.NoError(t, clocks.check("other", 1, 10))
+
+	now = now.Add(time.Hour)
+	assert.EqualError(t, clocks.check("engine", 1, 4449), "engine boots 1 and time 4449 outside the time window of engine boots 1 and time 4600")
+	require.NoError(t, clocks.check("engine", 1, 4450))
+	// later messages can be received first
+	require.NoError(t, clocks.check("engine", 1, 4500))
+	require.NoError(t, clocks.check("engine", 1, 4400))
+	assert.Equal(t, engineClock{received: now, boots: 1, time: 4500}, clocks.engines["engine"])
+}
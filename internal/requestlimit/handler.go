@@ -0,0 +1,147 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requestlimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// ErrBodyTooLarge is returned by reads of request bodies exceeding the maximum decompressed size.
+var ErrBodyTooLarge = errors.New("request body exceeds the maximum decompressed size")
+
+var registerViewsOnce sync.Once
+
+// NewHandler wraps the handler of a receiver, limiting the decompressed size of request bodies to the
+// provided number of bytes.  Requests exceeding it are responded to with 413 Request Entity Too Large,
+// replacing the response of the wrapped handler, and counted by the receiver's requests_too_large metric.
+// The returned handler must be served by a confighttp server for compressed bodies to be limited.
+func NewHandler(next http.Handler, receiverID config.ComponentID, logger *zap.Logger, limit int64) http.Handler {
+	// the views are shared by all receivers, whose measurements are distinguished by the receiver tag
+	registerViewsOnce.Do(func() {
+		if err := view.Register(metricViews()...); err != nil {
+			logger.Warn("failed registering request limit metric views", zap.Error(err))
+		}
+	})
+	return &handler{
+		next:     next,
+		receiver: receiverID.String(),
+		logger:   logger,
+		limit:    limit,
+	}
+}
+
+type handler struct {
+	next     http.Handler
+	logger   *zap.Logger
+	receiver string
+	limit    int64
+}
+
+func (h *handler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	// the length of uncompressed bodies is known before reading them
+	if req.ContentLength > h.limit {
+		h.reject(req.Context(), w)
+		return
+	}
+	body := &limitedBody{ReadCloser: req.Body, remaining: h.limit}
+	req.Body = body
+	lw := &limitedResponseWriter{ResponseWriter: w, body: body, handler: h, ctx: req.Context()}
+	h.next.ServeHTTP(lw, req)
+	if !lw.wroteHeader && body.exceeded {
+		lw.WriteHeader(http.StatusOK)
+	}
+}
+
+func (h *handler) reject(ctx context.Context, w http.ResponseWriter) {
+	h.logger.Debug("rejected request exceeding the maximum decompressed size", zap.Int64("limit", h.limit))
+	_ = stats.RecordWithTags(ctx, []tag.Mutator{tag.Upsert(receiverKey, h.receiver)}, mRequestsTooLarge.M(1))
+	// the rest of the body isn't read, so the connection can't be reused
+	w.Header().Set("Connection", "close")
+	http.Error(w, fmt.Sprintf("%s of %d bytes", ErrBodyTooLarge, h.limit), http.StatusRequestEntityTooLarge)
+}
+
+// limitedBody fails reads beyond the remaining number of bytes, like http.MaxBytesReader.
+type limitedBody struct {
+	io.ReadCloser
+	remaining int64
+	exceeded  bool
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.exceeded {
+		return 0, ErrBodyTooLarge
+	}
+	if len(p) == 0 {
+		return 0, nil
+	}
+	// reading a byte more than remaining determines whether the limit is exceeded
+	if readLimit := b.remaining + 1; readLimit > 0 && int64(len(p)) > readLimit {
+		p = p[:readLimit]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) <= b.remaining {
+		b.remaining -= int64(n)
+		return n, err
+	}
+	n = int(b.remaining)
+	b.remaining = 0
+	b.exceeded = true
+	return n, ErrBodyTooLarge
+}
+
+// limitedResponseWriter replaces the response of the wrapped handler once its request body exceeded the limit.
+type limitedResponseWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	body        *limitedBody
+	handler     *handler
+	rejected    bool
+	wroteHeader bool
+}
+
+func (w *limitedResponseWriter) WriteHeader(statusCode int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	if w.body.exceeded {
+		w.rejected = true
+		w.handler.reject(w.ctx, w.ResponseWriter)
+		return
+	}
+	w.ResponseWriter.WriteHeader(statusCode)
+}
+
+func (w *limitedResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.rejected {
+		// discarded, as if written
+		return len(p), nil
+	}
+	return w.ResponseWriter.Write(p)
+}
@@ -0,0 +1,112 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requestlimit
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opencensus.io/stats/view"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+// echoHandler responds with the request body, or 400 Bad Request if it can't be read.
+var echoHandler = http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+	body, err := io.ReadAll(req.Body)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	_, _ = w.Write(body)
+})
+
+func serve(t *testing.T, next http.Handler, body io.Reader, contentLength int64) *http.Response {
+	req := httptest.NewRequest(http.MethodPost, "/", body)
+	req.ContentLength = contentLength
+	rec := httptest.NewRecorder()
+	NewHandler(next, config.NewComponentID("test"), zap.NewNop(), 8).ServeHTTP(rec, req)
+	return rec.Result()
+}
+
+func readBody(t *testing.T, resp *http.Response) string {
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return string(body)
+}
+
+func TestHandlerWithinLimit(t *testing.T) {
+	for _, body := range []string{"", "1234567", "12345678"} {
+		// an unknown length, as with decompressed bodies
+		resp := serve(t, echoHandler, iotest.OneByteReader(strings.NewReader(body)), -1)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Equal(t, body, readBody(t, resp))
+	}
+}
+
+func TestHandlerExceedingLimit(t *testing.T) {
+	for _, test := range []struct {
+		name          string
+		body          io.Reader
+		contentLength int64
+	}{
+		{name: "unknown length", body: strings.NewReader("123456789"), contentLength: -1},
+		{name: "unknown length with short reads", body: iotest.OneByteReader(strings.NewReader("123456789")), contentLength: -1},
+		{name: "known length", body: strings.NewReader("123456789"), contentLength: 9},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			resp := serve(t, echoHandler, test.body, test.contentLength)
+			assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+			assert.Equal(t, "close", resp.Header.Get("Connection"))
+			assert.Equal(t, "request body exceeds the maximum decompressed size of 8 bytes\n", readBody(t, resp))
+		})
+	}
+}
+
+func TestHandlerIgnoringReadError(t *testing.T) {
+	// handlers succeeding despite the failed read are still rejected
+	ignoring := http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		_, _ = io.Copy(io.Discard, req.Body)
+	})
+	resp := serve(t, ignoring, strings.NewReader("123456789"), -1)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+}
+
+func TestHandlerRequestsTooLargeMetric(t *testing.T) {
+	handler := NewHandler(echoHandler, config.NewComponentIDWithName("test", "metric"), zap.NewNop(), 8)
+	for _, body := range []string{"123456789", "12345678", "1234567890"} {
+		req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(body))
+		req.ContentLength = -1
+		handler.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	rows, err := view.RetrieveData("receiver/requests_too_large")
+	require.NoError(t, err)
+	var count float64
+	for _, row := range rows {
+		require.Len(t, row.Tags, 1)
+		if row.Tags[0].Value == "test/metric" {
+			count = row.Data.(*view.SumData).Value
+		}
+	}
+	assert.Equal(t, float64(2), count)
+}
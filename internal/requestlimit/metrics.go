@@ -0,0 +1,42 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requestlimit
+
+import (
+	"go.opencensus.io/stats"
+	"go.opencensus.io/stats/view"
+	"go.opencensus.io/tag"
+)
+
+var (
+	receiverKey = tag.MustNewKey("receiver")
+
+	mRequestsTooLarge = stats.Int64(
+		"receiver/requests_too_large", "Number of requests rejected for exceeding the maximum decompressed size", stats.UnitDimensionless,
+	)
+)
+
+// metricViews returns the views of the request limit metrics, which are reported by the collector's own telemetry.
+func metricViews() []*view.View {
+	return []*view.View{
+		{
+			Name:        mRequestsTooLarge.Name(),
+			Description: mRequestsTooLarge.Description(),
+			Measure:     mRequestsTooLarge,
+			TagKeys:     []tag.Key{receiverKey},
+			Aggregation: view.Sum(),
+		},
+	}
+}
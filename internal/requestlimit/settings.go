@@ -0,0 +1,78 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package requestlimit limits the decompressed size of the request bodies of the HTTP receivers implemented by
+// the distribution, protecting gateways from decompression bombs.  The receivers built from contrib and core, like
+// splunk_hec, signalfx, and otlp, create their servers internally, so they aren't limited, and the
+// max_request_body_size of their confighttp settings is enforced before decompression.  The confighttp server decompresses gzip and deflate request
+// bodies while they are read, so limiting the body read by a receiver's handler bounds its decompressed size.
+package requestlimit
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+)
+
+// MaxDecompressedSizeEnvVar is the environment variable providing the default maximum decompressed size, in MiB,
+// of the receivers using these settings that don't configure their own.
+const MaxDecompressedSizeEnvVar = "SPLUNK_MAX_DECOMPRESSED_SIZE_MIB"
+
+// Settings configure the maximum decompressed size of request bodies, to be embedded in receiver configs.
+type Settings struct {
+	// MaxDecompressedSize is the maximum size of request bodies after decompression, in bytes.  If not set,
+	// the SPLUNK_MAX_DECOMPRESSED_SIZE_MIB environment variable or the receiver's default is used.
+	MaxDecompressedSize int64 `mapstructure:"max_decompressed_size"`
+}
+
+// Validate checks if the settings, and the distribution-level default, are valid.
+func (s Settings) Validate() error {
+	if s.MaxDecompressedSize < 0 {
+		return fmt.Errorf("max_decompressed_size must not be negative, not %d", s.MaxDecompressedSize)
+	}
+	_, err := envMaxDecompressedSize()
+	return err
+}
+
+// Limit returns the maximum decompressed size of request bodies, in bytes: the configured one, the
+// distribution-level default, or the provided receiver default, in that order.
+func (s Settings) Limit(receiverDefault int64) int64 {
+	if s.MaxDecompressedSize > 0 {
+		return s.MaxDecompressedSize
+	}
+	if envLimit, err := envMaxDecompressedSize(); err == nil && envLimit > 0 {
+		return envLimit
+	}
+	return receiverDefault
+}
+
+// envMaxDecompressedSize returns the distribution-level default in bytes, zero if not set.
+func envMaxDecompressedSize() (int64, error) {
+	value, ok := os.LookupEnv(MaxDecompressedSizeEnvVar)
+	if !ok || value == "" {
+		return 0, nil
+	}
+	mib, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || mib <= 0 {
+		return 0, fmt.Errorf("invalid %s %q: must be a positive number of MiB", MaxDecompressedSizeEnvVar, value)
+	}
+	if mib > maxMiB {
+		return 0, errors.New(MaxDecompressedSizeEnvVar + " is too large")
+	}
+	return mib << 20, nil
+}
+
+// maxMiB is the largest size in MiB whose number of bytes fits in an int64.
+const maxMiB = 1<<43 - 1
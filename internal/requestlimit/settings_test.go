@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package requestlimit
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSettingsLimit(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		env      string
+		settings Settings
+		expected int64
+	}{
+		{name: "receiver default", expected: 1024},
+		{name: "distribution default", env: "2", expected: 2 << 20},
+		{name: "configured", env: "2", settings: Settings{MaxDecompressedSize: 512}, expected: 512},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(MaxDecompressedSizeEnvVar, test.env)
+			require.NoError(t, test.settings.Validate())
+			assert.Equal(t, test.expected, test.settings.Limit(1024))
+		})
+	}
+}
+
+func TestSettingsValidate(t *testing.T) {
+	for _, test := range []struct {
+		name     string
+		env      string
+		settings Settings
+		err      string
+	}{
+		{name: "negative", settings: Settings{MaxDecompressedSize: -1}, err: "max_decompressed_size must not be negative, not -1"},
+		{name: "invalid env", env: "64MiB", err: `invalid SPLUNK_MAX_DECOMPRESSED_SIZE_MIB "64MiB": must be a positive number of MiB`},
+		{name: "zero env", env: "0", err: `invalid SPLUNK_MAX_DECOMPRESSED_SIZE_MIB "0": must be a positive number of MiB`},
+		{name: "too large env", env: "9007199254740992", err: "SPLUNK_MAX_DECOMPRESSED_SIZE_MIB is too large"},
+	} {
+		t.Run(test.name, func(t *testing.T) {
+			t.Setenv(MaxDecompressedSizeEnvVar, test.env)
+			assert.EqualError(t, test.settings.Validate(), test.err)
+		})
+	}
+}
@@ -0,0 +1,184 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package signalfxdimension converts the property and tag updates of the SignalFx dimension API to and
+// from entity state log records, so that they can be received and exported by logs pipelines.
+package signalfxdimension
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+const (
+	// The attributes of entity state log records, describing the state of an entity as a whole.
+	entityEventTypeKey  = "otel.entity.event.type"
+	entityTypeKey       = "otel.entity.type"
+	entityIDKey         = "otel.entity.id"
+	entityAttributesKey = "otel.entity.attributes"
+
+	entityStateEventType = "entity_state"
+	dimensionEntityType  = "signalfx.dimension"
+
+	// The SignalFx specific parts of a dimension update without entity state equivalents.
+	mergeKey              = "com.splunk.signalfx.dimension.merge"
+	tagsKey               = "com.splunk.signalfx.dimension.tags"
+	tagsToRemoveKey       = "com.splunk.signalfx.dimension.tags_to_remove"
+	propertiesToRemoveKey = "com.splunk.signalfx.dimension.properties_to_remove"
+
+	// AccessTokenKey is the resource attribute with the access token an update was sent with.
+	AccessTokenKey = "com.splunk.signalfx.access_token"
+)
+
+// ErrNotUpdate is returned for log records that aren't entity states of dimensions.
+var ErrNotUpdate = errors.New("not a dimension entity state")
+
+// Update is a property and tag update for a single dimension, as sent to the SignalFx
+// dimension API.  Replacing updates (PUT) overwrite all existing properties and tags
+// while merging ones (PATCH) only affect those provided, removing the properties with
+// null values.
+type Update struct {
+	// CustomProperties values are nil for properties to remove.
+	CustomProperties map[string]*string `json:"customProperties"`
+	Key              string             `json:"key"`
+	Value            string             `json:"value"`
+	Tags             []string           `json:"tags"`
+	TagsToRemove     []string           `json:"tagsToRemove,omitempty"`
+	Merge            bool               `json:"-"`
+}
+
+// ToLogs returns the update as an entity state log record.
+func (update Update) ToLogs(accessToken string) plog.Logs {
+	logs := plog.NewLogs()
+	rl := logs.ResourceLogs().AppendEmpty()
+	if accessToken != "" {
+		rl.Resource().Attributes().InsertString(AccessTokenKey, accessToken)
+	}
+
+	lr := rl.ScopeLogs().AppendEmpty().LogRecords().AppendEmpty()
+	lr.SetTimestamp(pcommon.NewTimestampFromTime(time.Now()))
+	attrs := lr.Attributes()
+	attrs.InsertString(entityEventTypeKey, entityStateEventType)
+	attrs.InsertString(entityTypeKey, dimensionEntityType)
+
+	id := pcommon.NewValueMap()
+	id.MapVal().InsertString(update.Key, update.Value)
+	attrs.Insert(entityIDKey, id)
+
+	properties := pcommon.NewValueMap()
+	var propertiesToRemove []string
+	for property, value := range update.CustomProperties {
+		if value == nil {
+			propertiesToRemove = append(propertiesToRemove, property)
+			continue
+		}
+		properties.MapVal().InsertString(property, *value)
+	}
+	properties.MapVal().Sort()
+	attrs.Insert(entityAttributesKey, properties)
+
+	attrs.InsertBool(mergeKey, update.Merge)
+	insertStrings(attrs, tagsKey, update.Tags)
+	insertStrings(attrs, tagsToRemoveKey, update.TagsToRemove)
+	sort.Strings(propertiesToRemove)
+	insertStrings(attrs, propertiesToRemoveKey, propertiesToRemove)
+	return logs
+}
+
+// FromLogRecord returns the update of the entity state log record of a dimension, or ErrNotUpdate
+// for other log records.
+func FromLogRecord(lr plog.LogRecord) (Update, error) {
+	var update Update
+	attrs := lr.Attributes()
+	if eventType, ok := attrs.Get(entityEventTypeKey); !ok || eventType.StringVal() != entityStateEventType {
+		return update, ErrNotUpdate
+	}
+	if entityType, ok := attrs.Get(entityTypeKey); !ok || entityType.StringVal() != dimensionEntityType {
+		return update, ErrNotUpdate
+	}
+
+	id, ok := attrs.Get(entityIDKey)
+	if !ok || id.Type() != pcommon.ValueTypeMap || id.MapVal().Len() != 1 {
+		return update, fmt.Errorf("%s must be a map of the dimension key to its value", entityIDKey)
+	}
+	id.MapVal().Range(func(key string, value pcommon.Value) bool {
+		update.Key, update.Value = key, value.AsString()
+		return false
+	})
+	if update.Key == "" || update.Value == "" {
+		return update, fmt.Errorf("%s must not have an empty dimension key or value", entityIDKey)
+	}
+
+	update.CustomProperties = map[string]*string{}
+	if properties, ok := attrs.Get(entityAttributesKey); ok {
+		if properties.Type() != pcommon.ValueTypeMap {
+			return update, fmt.Errorf("%s must be a map of the dimension properties", entityAttributesKey)
+		}
+		properties.MapVal().Range(func(property string, value pcommon.Value) bool {
+			propertyValue := value.AsString()
+			update.CustomProperties[property] = &propertyValue
+			return true
+		})
+	}
+	if merge, ok := attrs.Get(mergeKey); ok {
+		update.Merge = merge.BoolVal()
+	}
+
+	var err error
+	if update.Tags, err = getStrings(attrs, tagsKey); err != nil {
+		return update, err
+	}
+	if update.TagsToRemove, err = getStrings(attrs, tagsToRemoveKey); err != nil {
+		return update, err
+	}
+	propertiesToRemove, err := getStrings(attrs, propertiesToRemoveKey)
+	if err != nil {
+		return update, err
+	}
+	for _, property := range propertiesToRemove {
+		update.CustomProperties[property] = nil
+	}
+	return update, nil
+}
+
+func insertStrings(attrs pcommon.Map, key string, values []string) {
+	if len(values) == 0 {
+		return
+	}
+	slice := pcommon.NewValueSlice()
+	for _, value := range values {
+		slice.SliceVal().AppendEmpty().SetStringVal(value)
+	}
+	attrs.Insert(key, slice)
+}
+
+func getStrings(attrs pcommon.Map, key string) ([]string, error) {
+	value, ok := attrs.Get(key)
+	if !ok {
+		return nil, nil
+	}
+	if value.Type() != pcommon.ValueTypeSlice {
+		return nil, fmt.Errorf("%s must be a list of strings", key)
+	}
+	values := make([]string, 0, value.SliceVal().Len())
+	for i := 0; i < value.SliceVal().Len(); i++ {
+		values = append(values, value.SliceVal().At(i).AsString())
+	}
+	return values, nil
+}
@@ -0,0 +1,157 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package signalfxdimension
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/plog"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+func TestUpdateToLogs(t *testing.T) {
+	update := Update{
+		Key:   "host",
+		Value: "my-host",
+		CustomProperties: map[string]*string{
+			"role":     strPtr("db"),
+			"env":      strPtr("prod"),
+			"obsolete": nil,
+			"stale":    nil,
+		},
+		Tags:         []string{"tag1", "tag2"},
+		TagsToRemove: []string{"tag3"},
+		Merge:        true,
+	}
+
+	logs := update.ToLogs("token")
+	require.Equal(t, 1, logs.LogRecordCount())
+	rl := logs.ResourceLogs().At(0)
+	assert.Equal(t, map[string]any{"com.splunk.signalfx.access_token": "token"}, rl.Resource().Attributes().AsRaw())
+
+	lr := rl.ScopeLogs().At(0).LogRecords().At(0)
+	assert.NotZero(t, lr.Timestamp())
+	assert.Equal(t, map[string]any{
+		"otel.entity.event.type":                             "entity_state",
+		"otel.entity.type":                                   "signalfx.dimension",
+		"otel.entity.id":                                     map[string]any{"host": "my-host"},
+		"otel.entity.attributes":                             map[string]any{"env": "prod", "role": "db"},
+		"com.splunk.signalfx.dimension.merge":                true,
+		"com.splunk.signalfx.dimension.tags":                 []any{"tag1", "tag2"},
+		"com.splunk.signalfx.dimension.tags_to_remove":       []any{"tag3"},
+		"com.splunk.signalfx.dimension.properties_to_remove": []any{"obsolete", "stale"},
+	}, lr.Attributes().AsRaw())
+}
+
+func TestUpdateToLogsMinimal(t *testing.T) {
+	logs := Update{Key: "host", Value: "my-host"}.ToLogs("")
+	rl := logs.ResourceLogs().At(0)
+	assert.Zero(t, rl.Resource().Attributes().Len())
+	assert.Equal(t, map[string]any{
+		"otel.entity.event.type":              "entity_state",
+		"otel.entity.type":                    "signalfx.dimension",
+		"otel.entity.id":                      map[string]any{"host": "my-host"},
+		"otel.entity.attributes":              map[string]any{},
+		"com.splunk.signalfx.dimension.merge": false,
+	}, rl.ScopeLogs().At(0).LogRecords().At(0).Attributes().AsRaw())
+}
+
+func TestFromLogRecord(t *testing.T) {
+	for _, update := range []Update{
+		{
+			Key:   "host",
+			Value: "my-host",
+			CustomProperties: map[string]*string{
+				"role":     strPtr("db"),
+				"obsolete": nil,
+			},
+			Tags:         []string{"tag1"},
+			TagsToRemove: []string{"tag2"},
+			Merge:        true,
+		},
+		{Key: "host", Value: "my-host", CustomProperties: map[string]*string{}},
+	} {
+		lr := update.ToLogs("").ResourceLogs().At(0).ScopeLogs().At(0).LogRecords().At(0)
+		roundTripped, err := FromLogRecord(lr)
+		require.NoError(t, err)
+		assert.Equal(t, update, roundTripped)
+	}
+}
+
+func TestFromLogRecordErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		attrs map[string]any
+		err   string
+	}{
+		{
+			name:  "not_entity_state",
+			attrs: map[string]any{"otel.entity.type": "signalfx.dimension"},
+			err:   ErrNotUpdate.Error(),
+		},
+		{
+			name:  "not_dimension",
+			attrs: map[string]any{"otel.entity.event.type": "entity_state", "otel.entity.type": "k8s.pod"},
+			err:   ErrNotUpdate.Error(),
+		},
+		{
+			name:  "missing_id",
+			attrs: map[string]any{"otel.entity.event.type": "entity_state", "otel.entity.type": "signalfx.dimension"},
+			err:   "otel.entity.id must be a map of the dimension key to its value",
+		},
+		{
+			name: "composite_id",
+			attrs: map[string]any{
+				"otel.entity.event.type": "entity_state",
+				"otel.entity.type":       "signalfx.dimension",
+				"otel.entity.id":         map[string]any{"host": "my-host", "region": "us"},
+			},
+			err: "otel.entity.id must be a map of the dimension key to its value",
+		},
+		{
+			name: "empty_value",
+			attrs: map[string]any{
+				"otel.entity.event.type": "entity_state",
+				"otel.entity.type":       "signalfx.dimension",
+				"otel.entity.id":         map[string]any{"host": ""},
+			},
+			err: "otel.entity.id must not have an empty dimension key or value",
+		},
+		{
+			name: "invalid_tags",
+			attrs: map[string]any{
+				"otel.entity.event.type":             "entity_state",
+				"otel.entity.type":                   "signalfx.dimension",
+				"otel.entity.id":                     map[string]any{"host": "my-host"},
+				"com.splunk.signalfx.dimension.tags": "tag1",
+			},
+			err: "com.splunk.signalfx.dimension.tags must be a list of strings",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			lr := plog.NewLogRecord()
+			pcommon.NewMapFromRaw(tt.attrs).CopyTo(lr.Attributes())
+			_, err := FromLogRecord(lr)
+			assert.EqualError(t, err, tt.err)
+		})
+	}
+}
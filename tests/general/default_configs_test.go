@@ -0,0 +1,60 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package tests
+
+import (
+	"path"
+	"testing"
+
+	"github.com/signalfx/splunk-otel-collector/tests/testutils"
+)
+
+var defaultConfigDir = path.Join("..", "..", "cmd", "otelcol", "config", "collector")
+
+func defaultConfigEnv() map[string]string {
+	return map[string]string{
+		"SPLUNK_ACCESS_TOKEN":     "12345",
+		"SPLUNK_REALM":            "us0",
+		"SPLUNK_MEMORY_TOTAL_MIB": "512",
+		"SPLUNK_BALLAST_SIZE_MIB": "168",
+		"SPLUNK_MEMORY_LIMIT_MIB": "460",
+	}
+}
+
+func TestDefaultGatewayConfig(t *testing.T) {
+	tc := testutils.NewTestcase(t)
+	defer tc.PrintLogsOnFailure()
+	defer tc.ShutdownOTLPMetricsReceiverSink()
+
+	tc.AssertEffectiveConfig(
+		"gateway_config.yaml", defaultConfigEnv(),
+		"--config", path.Join(defaultConfigDir, "gateway_config.yaml"),
+	)
+}
+
+func TestDefaultAgentConfig(t *testing.T) {
+	tc := testutils.NewTestcase(t)
+	defer tc.PrintLogsOnFailure()
+	defer tc.ShutdownOTLPMetricsReceiverSink()
+
+	env := defaultConfigEnv()
+	env["SPLUNK_BUNDLE_DIR"] = "/usr/lib/splunk-otel-collector/agent-bundle"
+	env["SPLUNK_COLLECTD_DIR"] = "/usr/lib/splunk-otel-collector/agent-bundle/run/collectd"
+	env["SPLUNK_GATEWAY_URL"] = "localhost"
+	tc.AssertEffectiveConfig(
+		"agent_config.yaml", env,
+		"--config", path.Join(defaultConfigDir, "agent_config.yaml"),
+	)
+}
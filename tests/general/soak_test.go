@@ -0,0 +1,117 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//go:build soak
+// +build soak
+
+package tests
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+
+	"github.com/signalfx/splunk-otel-collector/tests/testutils"
+)
+
+// soakDuration is configurable via the SOAK_DURATION environment variable, 10m by default.
+func soakDuration(t *testing.T) time.Duration {
+	duration := 10 * time.Minute
+	if fromEnv := os.Getenv("SOAK_DURATION"); fromEnv != "" {
+		var err error
+		duration, err = time.ParseDuration(fromEnv)
+		require.NoError(t, err)
+	}
+	return duration
+}
+
+// soakMaxRSSGrowthMiB is configurable via the SOAK_MAX_RSS_GROWTH_MIB environment variable, 50 by default.
+func soakMaxRSSGrowthMiB(t *testing.T) float64 {
+	growth := 50.0
+	if fromEnv := os.Getenv("SOAK_MAX_RSS_GROWTH_MIB"); fromEnv != "" {
+		var err error
+		growth, err = strconv.ParseFloat(fromEnv, 64)
+		require.NoError(t, err)
+	}
+	return growth
+}
+
+func TestSoak(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		newLoad func(otlpEndpoint, sfxEndpoint string) (testutils.LoadGenerator, error)
+	}{
+		{
+			name: "otlp",
+			newLoad: func(otlpEndpoint, _ string) (testutils.LoadGenerator, error) {
+				return testutils.NewOTLPMetricsLoadGenerator().WithEndpoint(otlpEndpoint).WithDatapointsPerBatch(500).Build()
+			},
+		},
+		{
+			name: "signalfx",
+			newLoad: func(_, sfxEndpoint string) (testutils.LoadGenerator, error) {
+				return testutils.NewSFxDatapointLoadGenerator().WithEndpoint(sfxEndpoint).WithDatapointsPerBatch(500).Build()
+			},
+		},
+	} {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			logger, err := zap.NewDevelopment()
+			require.NoError(t, err)
+
+			sinkEndpoint := fmt.Sprintf("localhost:%d", testutils.GetAvailablePort(t))
+			sink, err := testutils.NewOTLPMetricsReceiverSink().WithEndpoint(sinkEndpoint).Build()
+			require.NoError(t, err)
+			require.NoError(t, sink.Start())
+			defer func() { require.NoError(t, sink.Shutdown()) }()
+
+			otlpEndpoint := fmt.Sprintf("localhost:%d", testutils.GetAvailablePort(t))
+			sfxEndpoint := fmt.Sprintf("localhost:%d", testutils.GetAvailablePort(t))
+			collector, err := testutils.NewCollectorProcess().
+				WithConfigPath(path.Join(".", "testdata", "soak_config.yaml")).
+				WithEnv(map[string]string{
+					"OTLP_RECEIVER_ENDPOINT":     otlpEndpoint,
+					"SIGNALFX_RECEIVER_ENDPOINT": sfxEndpoint,
+					"OTLP_EXPORTER_ENDPOINT":     sinkEndpoint,
+				}).Build()
+			require.NoError(t, err)
+			require.NoError(t, collector.Start())
+			defer func() { require.NoError(t, collector.Shutdown()) }()
+			require.NoError(t, collector.ExpectLogPattern("Everything is ready", 20*time.Second))
+
+			load, err := tc.newLoad(otlpEndpoint, sfxEndpoint)
+			require.NoError(t, err)
+
+			soak, err := testutils.NewSoakTest().
+				WithLoadGenerator(load).
+				WithReceivedCount(sink.DataPointCount).
+				WithDuration(soakDuration(t)).
+				WithMaxRSSGrowthMiB(soakMaxRSSGrowthMiB(t)).
+				WithPID(int32(collector.(*testutils.CollectorProcess).Process.Pid())).
+				WithLogger(logger).
+				Build()
+			require.NoError(t, err)
+
+			result, err := soak.Run(context.Background())
+			require.NoError(t, err)
+			require.NoError(t, soak.Verify(result), result.String())
+		})
+	}
+}
@@ -22,10 +22,11 @@ import (
 )
 
 func TestCollectdSolrReceiverProvidesAllMetrics(t *testing.T) {
+	t.Parallel()
 	containers := []testutils.Container{
 		testutils.NewContainer().WithContext(
 			path.Join(".", "testdata", "server"),
-		).WithExposedPorts("8983:8983").WithName(
+		).WithDynamicExposedPort("SOLR_PORT", "8983").WithName(
 			"solr",
 		).WillWaitForPorts("8983").WillWaitForLogs("example launched successfully"),
 	}
@@ -15,11 +15,15 @@
 package testutils
 
 import (
+	"time"
+
 	"go.uber.org/zap"
 )
 
 type Collector interface {
 	WithConfigPath(path string) Collector
+	// WithConfigVars renders the config as a Go template with the provided variables upon Build().
+	WithConfigVars(vars map[string]any) Collector
 	WithArgs(args ...string) Collector
 	WithEnv(env map[string]string) Collector
 	WithLogger(logger *zap.Logger) Collector
@@ -28,4 +32,9 @@ type Collector interface {
 	Build() (Collector, error)
 	Start() error
 	Shutdown() error
+	// Logs returns all log lines emitted by the started Collector so far.
+	Logs() []string
+	// ExpectLogPattern waits until an emitted log line matches the provided regular expression,
+	// returning an error if none does within the specified duration.
+	ExpectLogPattern(regex string, within time.Duration) error
 }
@@ -0,0 +1,74 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/template"
+)
+
+// RenderConfigTemplate renders the Collector config at the provided path as a Go text/template using the provided
+// variables (e.g. `{{ .OTLP_ENDPOINT }}`), writing the result to a new temporary file whose path is returned.
+// Referencing a variable that isn't provided is an error.
+func RenderConfigTemplate(path string, vars map[string]any) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := template.New(filepath.Base(path)).Option("missingkey=error").Parse(string(content))
+	if err != nil {
+		return "", fmt.Errorf("invalid config template %q: %w", path, err)
+	}
+
+	var rendered bytes.Buffer
+	if err = tmpl.Execute(&rendered, vars); err != nil {
+		return "", fmt.Errorf("failed rendering config template %q: %w", path, err)
+	}
+
+	renderedFile, err := os.CreateTemp("", "rendered-*-"+filepath.Base(path))
+	if err != nil {
+		return "", err
+	}
+	defer renderedFile.Close()
+	if _, err = renderedFile.Write(rendered.Bytes()); err != nil {
+		return "", err
+	}
+	return renderedFile.Name(), nil
+}
+
+// renderConfigPath returns the path of the config rendered with the provided variables, or the
+// unmodified path if there are none to render.
+func renderConfigPath(path string, vars map[string]any) (string, error) {
+	if path == "" || vars == nil {
+		return path, nil
+	}
+	return RenderConfigTemplate(path, vars)
+}
+
+// mergeConfigVars returns a copy of existing updated with additional.
+func mergeConfigVars(existing, additional map[string]any) map[string]any {
+	merged := make(map[string]any, len(existing)+len(additional))
+	for k, v := range existing {
+		merged[k] = v
+	}
+	for k, v := range additional {
+		merged[k] = v
+	}
+	return merged
+}
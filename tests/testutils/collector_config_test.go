@@ -0,0 +1,99 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderConfigTemplate(t *testing.T) {
+	rendered, err := RenderConfigTemplate(
+		path.Join(".", "testdata", "collector_config_template.yaml"),
+		map[string]any{"OTLP_ENDPOINT": "172.17.0.2:4317", "Token": "some-token"},
+	)
+	require.NoError(t, err)
+	defer os.Remove(rendered)
+
+	content, err := os.ReadFile(rendered)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "endpoint: 172.17.0.2:4317\n")
+	assert.Contains(t, string(content), "X-SF-Token: some-token\n")
+	assert.NotContains(t, string(content), "{{")
+}
+
+func TestRenderConfigTemplateMissingVar(t *testing.T) {
+	rendered, err := RenderConfigTemplate(
+		path.Join(".", "testdata", "collector_config_template.yaml"),
+		map[string]any{"OTLP_ENDPOINT": "172.17.0.2:4317"},
+	)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `failed rendering config template "testdata/collector_config_template.yaml"`)
+	assert.Contains(t, err.Error(), `map has no entry for key "Token"`)
+	assert.Empty(t, rendered)
+}
+
+func TestRenderConfigTemplateInvalid(t *testing.T) {
+	invalid, err := os.CreateTemp("", "invalid-*.yaml")
+	require.NoError(t, err)
+	defer os.Remove(invalid.Name())
+	_, err = invalid.WriteString("endpoint: {{ .OTLP_ENDPOINT ")
+	require.NoError(t, err)
+	require.NoError(t, invalid.Close())
+
+	rendered, err := RenderConfigTemplate(invalid.Name(), map[string]any{})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid config template")
+	assert.Empty(t, rendered)
+}
+
+func TestRenderConfigTemplateMissingFile(t *testing.T) {
+	rendered, err := RenderConfigTemplate("notaconfig", map[string]any{})
+	require.Error(t, err)
+	assert.Empty(t, rendered)
+}
+
+func TestCollectorProcessBuildRendersConfigVars(t *testing.T) {
+	configPath := path.Join(".", "testdata", "collector_config_template.yaml")
+	builder := NewCollectorProcess().WithPath("somepath").WithConfigPath(configPath).WithConfigVars(
+		map[string]any{"OTLP_ENDPOINT": "localhost:4317", "Token": "some-token"},
+	).WithConfigVars(map[string]any{"Token": "another-token"})
+
+	c, err := builder.Build()
+	require.NoError(t, err)
+	collector, ok := c.(*CollectorProcess)
+	require.True(t, ok)
+	defer os.Remove(collector.ConfigPath)
+
+	assert.NotEqual(t, configPath, collector.ConfigPath)
+	assert.Equal(t, []string{"--set=service.telemetry.logs.level=info", "--config", collector.ConfigPath, "--metrics-level", "none"}, collector.Args)
+
+	content, err := os.ReadFile(collector.ConfigPath)
+	require.NoError(t, err)
+	assert.Contains(t, string(content), "endpoint: localhost:4317\n")
+	assert.Contains(t, string(content), "X-SF-Token: another-token\n")
+}
+
+func TestCollectorProcessBuildInvalidConfigVars(t *testing.T) {
+	collector, err := NewCollectorProcess().WithPath("somepath").WithConfigPath(
+		path.Join(".", "testdata", "collector_config_template.yaml"),
+	).WithConfigVars(map[string]any{}).Build()
+	require.Error(t, err)
+	assert.Nil(t, collector)
+}
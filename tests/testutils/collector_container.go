@@ -21,18 +21,29 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/testcontainers/testcontainers-go"
 	"go.uber.org/zap"
 )
 
+const (
+	// containerCoverDir is where the host coverage directory is mounted in the container, as its GOCOVERDIR.
+	containerCoverDir = "/otelcol-coverage"
+	// coverageStopTimeout is how long the Collector has to write its coverage data when shut down.
+	coverageStopTimeout = 30 * time.Second
+)
+
 var _ Collector = (*CollectorContainer)(nil)
 var _ testcontainers.LogConsumer = (*collectorLogConsumer)(nil)
 
 type CollectorContainer struct {
 	Image          string
 	ConfigPath     string
+	CoverDir       string
+	ConfigVars     map[string]any
 	Args           []string
 	Ports          []string
 	Logger         *zap.Logger
@@ -59,12 +70,25 @@ func (collector CollectorContainer) WithExposedPorts(ports ...string) CollectorC
 	return collector
 }
 
+// Host directory the coverage data of an image's Collector built with `go build -cover` is written to
+// upon Shutdown(), for use with `go tool covdata`.  Disabled by default.
+func (collector CollectorContainer) WithCoverDir(dir string) CollectorContainer {
+	collector.CoverDir = dir
+	return collector
+}
+
 // Will use bundled config by default
 func (collector CollectorContainer) WithConfigPath(path string) Collector {
 	collector.ConfigPath = path
 	return &collector
 }
 
+// Rendered as a Go template upon Build() if provided.  Merged with prior vars, none by default
+func (collector CollectorContainer) WithConfigVars(vars map[string]any) Collector {
+	collector.ConfigVars = mergeConfigVars(collector.ConfigVars, vars)
+	return &collector
+}
+
 // []string{} by default
 func (collector CollectorContainer) WithArgs(args ...string) Collector {
 	collector.Args = args
@@ -105,9 +129,14 @@ func (collector CollectorContainer) Build() (Collector, error) {
 		collector.LogLevel = "info"
 	}
 
-	collector.logConsumer = newCollectorLogConsumer(collector.Logger)
+	collector.logConsumer = newCollectorLogConsumer(collector.Logger, newLogBuffer())
+
+	configPath, err := renderConfigPath(collector.ConfigPath, collector.ConfigVars)
+	if err != nil {
+		return nil, err
+	}
+	collector.ConfigPath = configPath
 
-	var err error
 	collector.contextArchive, err = collector.buildContextArchive()
 	if err != nil {
 		return nil, err
@@ -128,6 +157,16 @@ func (collector CollectorContainer) Build() (Collector, error) {
 		collector.Container = collector.Container.WithCmd(collector.Args...)
 	}
 
+	if collector.CoverDir != "" {
+		coverDir, err := prepareCoverDir(collector.CoverDir)
+		if err != nil {
+			return nil, err
+		}
+		collector.Container = collector.Container.WithBindMount(
+			coverDir, containerCoverDir,
+		).WithEnvVar("GOCOVERDIR", containerCoverDir)
+	}
+
 	collector.Container = *(collector.Container.Build())
 
 	return &collector, nil
@@ -151,9 +190,42 @@ func (collector *CollectorContainer) Shutdown() error {
 		return fmt.Errorf("cannot Shutdown a CollectorContainer that hasn't been successfully built")
 	}
 	defer collector.Container.StopLogProducer()
+	if collector.CoverDir != "" {
+		// coverage data is only written when the Collector exits, not when it's killed by Terminate()
+		if err := collector.Container.StopGracefully(context.Background(), coverageStopTimeout); err != nil {
+			return err
+		}
+	}
 	return collector.Container.Terminate(context.Background())
 }
 
+func (collector *CollectorContainer) Logs() []string {
+	if collector.logConsumer.logs == nil {
+		return nil
+	}
+	return collector.logConsumer.logs.Lines()
+}
+
+func (collector *CollectorContainer) ExpectLogPattern(regex string, within time.Duration) error {
+	if collector.logConsumer.logs == nil {
+		return fmt.Errorf("cannot ExpectLogPattern on a CollectorContainer that hasn't been successfully built")
+	}
+	return collector.logConsumer.logs.ExpectPattern(regex, within)
+}
+
+// prepareCoverDir creates the absolute coverage directory, writable by the Collector image's user.
+func prepareCoverDir(dir string) (string, error) {
+	coverDir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if err = os.MkdirAll(coverDir, 0o777); err != nil {
+		return "", err
+	}
+	// not subject to the umask like MkdirAll()
+	return coverDir, os.Chmod(coverDir, 0o777) // #nosec G302
+}
+
 func (collector *CollectorContainer) buildContextArchive() (io.Reader, error) {
 	var buf bytes.Buffer
 	tarWriter := tar.NewWriter(&buf)
@@ -222,14 +294,16 @@ func (collector *CollectorContainer) buildContextArchive() (io.Reader, error) {
 
 type collectorLogConsumer struct {
 	logger *zap.Logger
+	logs   *logBuffer
 }
 
-func newCollectorLogConsumer(logger *zap.Logger) collectorLogConsumer {
-	return collectorLogConsumer{logger: logger}
+func newCollectorLogConsumer(logger *zap.Logger, logs *logBuffer) collectorLogConsumer {
+	return collectorLogConsumer{logger: logger, logs: logs}
 }
 
 func (l collectorLogConsumer) Accept(log testcontainers.Log) {
 	msg := string(log.Content)
+	l.logs.Add(msg)
 	if log.LogType == testcontainers.StderrLog {
 		l.logger.Info(msg)
 	} else {
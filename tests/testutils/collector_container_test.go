@@ -15,6 +15,8 @@
 package testutils
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -90,6 +92,26 @@ func TestCollectorContainerBuildDefaults(t *testing.T) {
 	assert.Equal(t, []string{}, collector.Args)
 }
 
+func TestCollectorContainerWithCoverDir(t *testing.T) {
+	builder := NewCollectorContainer()
+	coverDir := filepath.Join(t.TempDir(), "coverage")
+	withCoverDir := builder.WithCoverDir(coverDir)
+	assert.Equal(t, coverDir, withCoverDir.CoverDir)
+	assert.Empty(t, builder.CoverDir)
+
+	c, err := withCoverDir.WithArgs("--version").Build()
+	require.NoError(t, err)
+	collector, ok := c.(*CollectorContainer)
+	require.True(t, ok)
+
+	info, err := os.Stat(coverDir)
+	require.NoError(t, err)
+	assert.True(t, info.IsDir())
+	assert.Equal(t, os.FileMode(0o777), info.Mode().Perm())
+	assert.Equal(t, map[string]string{coverDir: "/otelcol-coverage"}, collector.Container.BindMounts)
+	assert.Equal(t, "/otelcol-coverage", collector.Container.Env["GOCOVERDIR"])
+}
+
 func TestStartAndShutdownInvalidWithoutBuildingContainer(t *testing.T) {
 	builder := NewCollectorContainer()
 
@@ -0,0 +1,304 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/confmap"
+	"go.opentelemetry.io/collector/confmap/converter/expandconverter"
+	"go.opentelemetry.io/collector/confmap/provider/envprovider"
+	"go.opentelemetry.io/collector/confmap/provider/fileprovider"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/processor/batchprocessor"
+	"go.opentelemetry.io/collector/processor/memorylimiterprocessor"
+	"go.opentelemetry.io/collector/receiver/otlpreceiver"
+	"go.opentelemetry.io/collector/service"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const inProcessStatePollInterval = 10 * time.Millisecond
+
+var _ Collector = (*CollectorInProcess)(nil)
+
+// CollectorInProcess runs a Collector service in the test's own process, which avoids the need for a built
+// binary and allows profiling the tested components directly.  Only the provided Factories are available.
+type CollectorInProcess struct {
+	Factories    component.Factories
+	ConfigPath   string
+	ConfigVars   map[string]any
+	Env          map[string]string
+	Logger       *zap.Logger
+	LogLevel     string
+	Fail         bool
+	StartTimeout time.Duration
+	args         []string
+	service      *service.Collector
+	runErr       chan error
+	priorEnv     map[string]*string
+	logs         *logBuffer
+}
+
+// To be used as a builder whose Build() method provides the actual instance capable of running the service.
+func NewCollectorInProcess() CollectorInProcess {
+	return CollectorInProcess{Env: map[string]string{}}
+}
+
+// The otlp receiver and exporter with batch and memory_limiter processors by default
+func (collector CollectorInProcess) WithFactories(factories component.Factories) CollectorInProcess {
+	collector.Factories = factories
+	return collector
+}
+
+// 10s by default
+func (collector CollectorInProcess) WithStartTimeout(timeout time.Duration) CollectorInProcess {
+	collector.StartTimeout = timeout
+	return collector
+}
+
+// Required
+func (collector CollectorInProcess) WithConfigPath(path string) Collector {
+	collector.ConfigPath = path
+	return &collector
+}
+
+// Rendered as a Go template upon Build() if provided.  Merged with prior vars, none by default
+func (collector CollectorInProcess) WithConfigVars(vars map[string]any) Collector {
+	collector.ConfigVars = mergeConfigVars(collector.ConfigVars, vars)
+	return &collector
+}
+
+// Command line arguments aren't supported by an in-process Collector and will fail Build()
+func (collector CollectorInProcess) WithArgs(args ...string) Collector {
+	collector.args = args
+	return &collector
+}
+
+// Set in the test process environment for the lifetime of the service.  Empty by default
+func (collector CollectorInProcess) WithEnv(env map[string]string) Collector {
+	envCopy := make(map[string]string, len(collector.Env)+len(env))
+	for k, v := range collector.Env {
+		envCopy[k] = v
+	}
+	for k, v := range env {
+		envCopy[k] = v
+	}
+	collector.Env = envCopy
+	return &collector
+}
+
+// Nop logger by default
+func (collector CollectorInProcess) WithLogger(logger *zap.Logger) Collector {
+	collector.Logger = logger
+	return &collector
+}
+
+// info by default
+func (collector CollectorInProcess) WithLogLevel(level string) Collector {
+	collector.LogLevel = level
+	return &collector
+}
+
+// noop at this time
+func (collector CollectorInProcess) WillFail(fail bool) Collector {
+	collector.Fail = fail
+	return &collector
+}
+
+func (collector CollectorInProcess) Build() (Collector, error) {
+	if len(collector.args) != 0 {
+		return nil, fmt.Errorf("command line arguments aren't supported by CollectorInProcess")
+	}
+	if collector.ConfigPath == "" {
+		return nil, fmt.Errorf("you must specify a ConfigPath for your CollectorInProcess before building")
+	}
+	if collector.Factories.Receivers == nil {
+		factories, err := defaultInProcessFactories()
+		if err != nil {
+			return nil, err
+		}
+		collector.Factories = factories
+	}
+	if collector.Logger == nil {
+		collector.Logger = zap.NewNop()
+	}
+	if collector.LogLevel == "" {
+		collector.LogLevel = "info"
+	}
+	if collector.StartTimeout == 0 {
+		collector.StartTimeout = 10 * time.Second
+	}
+	var level zapcore.Level
+	if err := level.UnmarshalText([]byte(collector.LogLevel)); err != nil {
+		return nil, fmt.Errorf("invalid LogLevel %q: %w", collector.LogLevel, err)
+	}
+
+	configPath, err := renderConfigPath(collector.ConfigPath, collector.ConfigVars)
+	if err != nil {
+		return nil, err
+	}
+	collector.ConfigPath = configPath
+
+	emp := envprovider.New()
+	fmp := fileprovider.New()
+	configProvider, err := service.NewConfigProvider(
+		service.ConfigProviderSettings{
+			Locations: []string{fmt.Sprintf("%s:%s", fmp.Scheme(), collector.ConfigPath)},
+			MapProviders: map[string]confmap.Provider{
+				emp.Scheme(): emp,
+				fmp.Scheme(): fmp,
+			},
+			MapConverters: []confmap.Converter{expandconverter.New()},
+		})
+	if err != nil {
+		return nil, err
+	}
+
+	collector.logs = newLogBuffer()
+	logs, logger := collector.logs, collector.Logger
+	captureCore := zapcore.NewCore(
+		zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig()),
+		zapcore.AddSync(logBufferWriter{logs}),
+		level,
+	)
+	collector.service, err = service.New(service.CollectorSettings{
+		BuildInfo:               component.NewDefaultBuildInfo(),
+		Factories:               collector.Factories,
+		ConfigProvider:          configProvider,
+		DisableGracefulShutdown: true,
+		LoggingOptions: []zap.Option{
+			zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+				return zapcore.NewTee(core, captureCore, logger.Core())
+			}),
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &collector, nil
+}
+
+func (collector *CollectorInProcess) Start() error {
+	if collector.service == nil {
+		return fmt.Errorf("cannot Start a CollectorInProcess that hasn't been successfully built")
+	}
+	if collector.runErr != nil {
+		return fmt.Errorf("cannot Start a CollectorInProcess more than once")
+	}
+
+	collector.setEnv()
+	collector.runErr = make(chan error, 1)
+	go func() {
+		collector.runErr <- collector.service.Run(context.Background())
+	}()
+
+	deadline := time.Now().Add(collector.StartTimeout)
+	for collector.service.GetState() != service.Running {
+		select {
+		case err := <-collector.runErr:
+			collector.runErr <- err
+			collector.restoreEnv()
+			return fmt.Errorf("collector service failed to start: %w", err)
+		default:
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("collector service not running within %s", collector.StartTimeout)
+		}
+		time.Sleep(inProcessStatePollInterval)
+	}
+	return nil
+}
+
+func (collector *CollectorInProcess) Shutdown() error {
+	if collector.service == nil {
+		return fmt.Errorf("cannot Shutdown a CollectorInProcess that hasn't been successfully built")
+	}
+	if collector.runErr == nil {
+		return fmt.Errorf("cannot Shutdown a CollectorInProcess that hasn't been started")
+	}
+	defer collector.restoreEnv()
+	collector.service.Shutdown()
+	return <-collector.runErr
+}
+
+func (collector *CollectorInProcess) Logs() []string {
+	if collector.logs == nil {
+		return nil
+	}
+	return collector.logs.Lines()
+}
+
+func (collector *CollectorInProcess) ExpectLogPattern(regex string, within time.Duration) error {
+	if collector.logs == nil {
+		return fmt.Errorf("cannot ExpectLogPattern on a CollectorInProcess that hasn't been successfully built")
+	}
+	return collector.logs.ExpectPattern(regex, within)
+}
+
+func (collector *CollectorInProcess) setEnv() {
+	collector.priorEnv = map[string]*string{}
+	for k, v := range collector.Env {
+		if prior, ok := os.LookupEnv(k); ok {
+			collector.priorEnv[k] = &prior
+		} else {
+			collector.priorEnv[k] = nil
+		}
+		os.Setenv(k, v)
+	}
+}
+
+func (collector *CollectorInProcess) restoreEnv() {
+	for k, prior := range collector.priorEnv {
+		if prior == nil {
+			os.Unsetenv(k)
+		} else {
+			os.Setenv(k, *prior)
+		}
+	}
+	collector.priorEnv = nil
+}
+
+// logBufferWriter adapts a logBuffer to the io.Writer required by zapcore.
+type logBufferWriter struct {
+	buffer *logBuffer
+}
+
+func (w logBufferWriter) Write(p []byte) (int, error) {
+	w.buffer.Add(string(p))
+	return len(p), nil
+}
+
+func defaultInProcessFactories() (component.Factories, error) {
+	var factories component.Factories
+	var err error
+	if factories.Receivers, err = component.MakeReceiverFactoryMap(otlpreceiver.NewFactory()); err != nil {
+		return factories, err
+	}
+	if factories.Processors, err = component.MakeProcessorFactoryMap(
+		batchprocessor.NewFactory(), memorylimiterprocessor.NewFactory(),
+	); err != nil {
+		return factories, err
+	}
+	if factories.Exporters, err = component.MakeExporterFactoryMap(otlpexporter.NewFactory()); err != nil {
+		return factories, err
+	}
+	factories.Extensions, err = component.MakeExtensionFactoryMap()
+	return factories, err
+}
@@ -0,0 +1,121 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/config"
+	"go.uber.org/zap"
+)
+
+func TestCollectorInProcessBuilders(t *testing.T) {
+	builder := NewCollectorInProcess()
+	require.NotNil(t, builder)
+	require.Empty(t, builder.ConfigPath)
+	require.Empty(t, builder.Env)
+	require.Nil(t, builder.Logger)
+
+	factories := component.Factories{Receivers: map[config.Type]component.ReceiverFactory{}}
+	withFactories := builder.WithFactories(factories)
+	require.NotNil(t, withFactories.Factories.Receivers)
+	require.Nil(t, builder.Factories.Receivers)
+
+	withStartTimeout := builder.WithStartTimeout(time.Minute)
+	require.Equal(t, time.Minute, withStartTimeout.StartTimeout)
+
+	c := builder.WithConfigPath("some_config_path")
+	require.Equal(t, "some_config_path", c.(*CollectorInProcess).ConfigPath)
+	require.Empty(t, builder.ConfigPath)
+
+	c = builder.WithEnv(map[string]string{"one": "one.value"})
+	require.Equal(t, map[string]string{"one": "one.value"}, c.(*CollectorInProcess).Env)
+	require.Empty(t, builder.Env)
+
+	logger := zap.NewNop()
+	c = builder.WithLogger(logger)
+	require.Same(t, logger, c.(*CollectorInProcess).Logger)
+
+	c = builder.WithLogLevel("debug")
+	require.Equal(t, "debug", c.(*CollectorInProcess).LogLevel)
+}
+
+func TestCollectorInProcessBuildErrors(t *testing.T) {
+	c, err := NewCollectorInProcess().Build()
+	assert.EqualError(t, err, "you must specify a ConfigPath for your CollectorInProcess before building")
+	assert.Nil(t, c)
+
+	c, err = NewCollectorInProcess().WithConfigPath("config.yaml").WithArgs("--set", "key=value").Build()
+	assert.EqualError(t, err, "command line arguments aren't supported by CollectorInProcess")
+	assert.Nil(t, c)
+
+	c, err = NewCollectorInProcess().WithConfigPath("config.yaml").WithLogLevel("chatty").Build()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), `invalid LogLevel "chatty"`)
+	assert.Nil(t, c)
+}
+
+func TestCollectorInProcessMethodsWithoutBuildingDisallowed(t *testing.T) {
+	c := NewCollectorInProcess().WithConfigPath("config.yaml").(*CollectorInProcess)
+	assert.EqualError(t, c.Start(), "cannot Start a CollectorInProcess that hasn't been successfully built")
+	assert.EqualError(t, c.Shutdown(), "cannot Shutdown a CollectorInProcess that hasn't been successfully built")
+	assert.Nil(t, c.Logs())
+}
+
+func TestCollectorInProcessSoak(t *testing.T) {
+	sinkEndpoint := fmt.Sprintf("localhost:%d", GetAvailablePort(t))
+	sink, err := NewOTLPMetricsReceiverSink().WithEndpoint(sinkEndpoint).Build()
+	require.NoError(t, err)
+	require.NoError(t, sink.Start())
+	defer func() { require.NoError(t, sink.Shutdown()) }()
+
+	receiverEndpoint := fmt.Sprintf("localhost:%d", GetAvailablePort(t))
+	collector, err := NewCollectorInProcess().
+		WithConfigPath(path.Join(".", "testdata", "in_process_config.yaml")).
+		WithEnv(map[string]string{
+			"OTLP_RECEIVER_ENDPOINT": receiverEndpoint,
+			"OTLP_EXPORTER_ENDPOINT": sinkEndpoint,
+		}).Build()
+	require.NoError(t, err)
+	require.NoError(t, collector.Start())
+	require.Equal(t, receiverEndpoint, os.Getenv("OTLP_RECEIVER_ENDPOINT"))
+
+	load, err := NewOTLPMetricsLoadGenerator().WithEndpoint(receiverEndpoint).WithDatapointsPerBatch(10).Build()
+	require.NoError(t, err)
+
+	soak, err := NewSoakTest().WithLoadGenerator(load).WithReceivedCount(sink.DataPointCount).
+		WithDuration(2 * time.Second).WithSendInterval(20 * time.Millisecond).
+		WithSampleInterval(100 * time.Millisecond).WithDrainTimeout(5 * time.Second).
+		WithMaxRSSGrowthMiB(-1).Build()
+	require.NoError(t, err)
+
+	result, err := soak.Run(context.Background())
+	require.NoError(t, err)
+	assert.NoError(t, soak.Verify(result), result.String())
+	assert.Equal(t, result.Sent, sink.DataPointCount())
+
+	require.NoError(t, collector.ExpectLogPattern("Everything is ready", 5*time.Second))
+	require.NoError(t, collector.Shutdown())
+	_, ok := os.LookupEnv("OTLP_RECEIVER_ENDPOINT")
+	require.False(t, ok)
+}
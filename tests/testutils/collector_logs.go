@@ -0,0 +1,92 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+const logPollInterval = 10 * time.Millisecond
+
+// logBuffer captures Collector log lines so tests can assert on their emission.
+// It's safe for concurrent use.
+type logBuffer struct {
+	lines []string
+	lock  sync.RWMutex
+}
+
+func newLogBuffer() *logBuffer {
+	return &logBuffer{}
+}
+
+// Add records the provided content, splitting it into individual non-empty lines.
+func (buffer *logBuffer) Add(content string) {
+	var lines []string
+	for _, line := range strings.Split(content, "\n") {
+		if line = strings.TrimRight(line, "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if len(lines) == 0 {
+		return
+	}
+	buffer.lock.Lock()
+	defer buffer.lock.Unlock()
+	buffer.lines = append(buffer.lines, lines...)
+}
+
+// Lines returns a copy of all captured lines.
+func (buffer *logBuffer) Lines() []string {
+	buffer.lock.RLock()
+	defer buffer.lock.RUnlock()
+	lines := make([]string, len(buffer.lines))
+	copy(lines, buffer.lines)
+	return lines
+}
+
+func (buffer *logBuffer) matches(pattern *regexp.Regexp) bool {
+	buffer.lock.RLock()
+	defer buffer.lock.RUnlock()
+	for _, line := range buffer.lines {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
+
+// ExpectPattern waits until a captured line matches the provided regular expression,
+// returning an error if none do within the specified duration.
+func (buffer *logBuffer) ExpectPattern(regex string, within time.Duration) error {
+	pattern, err := regexp.Compile(regex)
+	if err != nil {
+		return fmt.Errorf("invalid log pattern %q: %w", regex, err)
+	}
+
+	deadline := time.Now().Add(within)
+	for {
+		if buffer.matches(pattern) {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("no log statement matching %q within %s", regex, within)
+		}
+		time.Sleep(logPollInterval)
+	}
+}
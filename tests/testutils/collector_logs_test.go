@@ -0,0 +1,75 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLogBufferAddSplitsLines(t *testing.T) {
+	buffer := newLogBuffer()
+	require.Empty(t, buffer.Lines())
+
+	buffer.Add("first line\r\nsecond line\n\n")
+	buffer.Add("")
+	buffer.Add("third line")
+	assert.Equal(t, []string{"first line", "second line", "third line"}, buffer.Lines())
+
+	lines := buffer.Lines()
+	lines[0] = "mutated"
+	assert.Equal(t, "first line", buffer.Lines()[0])
+}
+
+func TestLogBufferExpectPattern(t *testing.T) {
+	buffer := newLogBuffer()
+	buffer.Add(`2022-07-01T00:00:00.000Z	warn	configconverter/move_hec_tls.go:36	Deprecated config value`)
+
+	require.NoError(t, buffer.ExpectPattern(`warn\s+configconverter/\S+\s+Deprecated`, 0))
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		buffer.Add("Everything is ready. Begin running and processing data.")
+	}()
+	require.NoError(t, buffer.ExpectPattern("Everything is ready", 5*time.Second))
+
+	err := buffer.ExpectPattern("never logged", 50*time.Millisecond)
+	require.EqualError(t, err, `no log statement matching "never logged" within 50ms`)
+}
+
+func TestLogBufferExpectInvalidPattern(t *testing.T) {
+	err := newLogBuffer().ExpectPattern("(unclosed", time.Second)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid log pattern "(unclosed"`)
+}
+
+func TestCollectorLogAssertionsRequireBuild(t *testing.T) {
+	process := NewCollectorProcess()
+	assert.Nil(t, process.Logs())
+	require.EqualError(
+		t, process.ExpectLogPattern(".*", time.Second),
+		"cannot ExpectLogPattern on a CollectorProcess that hasn't been successfully built",
+	)
+
+	container := NewCollectorContainer()
+	assert.Nil(t, container.Logs())
+	require.EqualError(
+		t, container.ExpectLogPattern(".*", time.Second),
+		"cannot ExpectLogPattern on a CollectorContainer that hasn't been successfully built",
+	)
+}
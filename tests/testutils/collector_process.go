@@ -19,6 +19,7 @@ import (
 	"fmt"
 	"os"
 	"path"
+	"time"
 
 	"go.uber.org/zap"
 
@@ -33,6 +34,7 @@ var _ Collector = (*CollectorProcess)(nil)
 type CollectorProcess struct {
 	Path             string
 	ConfigPath       string
+	ConfigVars       map[string]any
 	Args             []string
 	Env              map[string]string
 	Logger           *zap.Logger
@@ -40,6 +42,7 @@ type CollectorProcess struct {
 	Fail             bool
 	Process          *subprocess.Subprocess
 	subprocessConfig *subprocess.Config
+	logs             *logBuffer
 }
 
 // To be used as a builder whose Build() method provides the actual instance capable of launching the process.
@@ -59,6 +62,12 @@ func (collector CollectorProcess) WithConfigPath(path string) Collector {
 	return &collector
 }
 
+// Rendered as a Go template upon Build() if provided.  Merged with prior vars, none by default
+func (collector CollectorProcess) WithConfigVars(vars map[string]any) Collector {
+	collector.ConfigVars = mergeConfigVars(collector.ConfigVars, vars)
+	return &collector
+}
+
 // []string{"--set=service.telemetry.logs.level={collector.LogLevel}", "--config", collector.ConfigPath, "--metrics-level", "none"} by default
 func (collector CollectorProcess) WithArgs(args ...string) Collector {
 	collector.Args = args
@@ -108,6 +117,11 @@ func (collector CollectorProcess) Build() (Collector, error) {
 	if collector.LogLevel == "" {
 		collector.LogLevel = "info"
 	}
+	configPath, err := renderConfigPath(collector.ConfigPath, collector.ConfigVars)
+	if err != nil {
+		return nil, err
+	}
+	collector.ConfigPath = configPath
 	if collector.Args == nil {
 		collector.Args = []string{
 			fmt.Sprintf("--set=service.telemetry.logs.level=%s", collector.LogLevel), "--config", collector.ConfigPath, "--metrics-level", "none",
@@ -120,6 +134,7 @@ func (collector CollectorProcess) Build() (Collector, error) {
 		EnvironmentVariables: collector.Env,
 	}
 	collector.Process = subprocess.NewSubprocess(collector.subprocessConfig, collector.Logger)
+	collector.logs = newLogBuffer()
 	return &collector, nil
 }
 
@@ -128,8 +143,9 @@ func (collector *CollectorProcess) Start() error {
 		return fmt.Errorf("cannot Start a CollectorProcess that hasn't been successfully built")
 	}
 	go func() {
-		// drain stdout/err buffer (already logged for us)
-		for range collector.Process.Stdout {
+		// drain stdout/err buffer (already logged for us) and capture it for log assertions
+		for line := range collector.Process.Stdout {
+			collector.logs.Add(line)
 		}
 	}()
 
@@ -144,6 +160,20 @@ func (collector *CollectorProcess) Shutdown() error {
 	return collector.Process.Shutdown(context.Background())
 }
 
+func (collector *CollectorProcess) Logs() []string {
+	if collector.logs == nil {
+		return nil
+	}
+	return collector.logs.Lines()
+}
+
+func (collector *CollectorProcess) ExpectLogPattern(regex string, within time.Duration) error {
+	if collector.logs == nil {
+		return fmt.Errorf("cannot ExpectLogPattern on a CollectorProcess that hasn't been successfully built")
+	}
+	return collector.logs.ExpectPattern(regex, within)
+}
+
 // Walks up parent directories looking for bin/otelcol
 func findCollectorPath() (string, error) {
 	dir, err := os.Getwd()
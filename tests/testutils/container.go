@@ -18,8 +18,10 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"time"
 
 	dockerContainer "github.com/docker/docker/api/types/container"
+	dockerClient "github.com/docker/docker/client"
 	"github.com/docker/docker/errdefs"
 	"github.com/docker/go-connections/nat"
 	"github.com/testcontainers/testcontainers-go"
@@ -34,10 +36,14 @@ type Container struct {
 	Cmd                  []string
 	Env                  map[string]string
 	ExposedPorts         []string
+	DynamicPorts         map[string]string
+	BindMounts           map[string]string
 	ContainerName        string
 	ContainerNetworks    []string
 	ContainerNetworkMode string
 	WaitingFor           []wait.Strategy
+	PlatformImages       map[string]string
+	SkippedPlatforms     []string
 	req                  *testcontainers.ContainerRequest
 	container            *testcontainers.Container
 }
@@ -57,6 +63,32 @@ func (container Container) WithImage(image string) Container {
 	return container
 }
 
+// WithPlatformImage will use the provided image instead of the default one when the tests are run
+// for the specified Platform() (e.g. "arm64").
+func (container Container) WithPlatformImage(platform, image string) Container {
+	builder := container
+	builder.PlatformImages = copyMap(builder.PlatformImages)
+	builder.PlatformImages[platform] = image
+	return builder
+}
+
+// WillSkipOnPlatforms will have Testcase.Containers() skip the test when run for any of the
+// specified platforms, for images that aren't available for them.
+func (container Container) WillSkipOnPlatforms(platforms ...string) Container {
+	container.SkippedPlatforms = append(append([]string{}, container.SkippedPlatforms...), platforms...)
+	return container
+}
+
+// SkipsPlatform determines if the Container has been configured to be skipped for the provided platform.
+func (container Container) SkipsPlatform(platform string) bool {
+	for _, skipped := range container.SkippedPlatforms {
+		if skipped == platform {
+			return true
+		}
+	}
+	return false
+}
+
 func (container Container) WithDockerfile(dockerfile string) Container {
 	container.Dockerfile.Dockerfile = dockerfile
 	return container
@@ -106,6 +138,24 @@ func (container Container) WithExposedPorts(ports ...string) Container {
 	return container
 }
 
+// WithDynamicExposedPort requests that the provided container port be bound to an available host port,
+// allocated by Testcase.Containers() and rendered to the Collector config as the name environment variable.
+func (container Container) WithDynamicExposedPort(name, containerPort string) Container {
+	builder := container
+	builder.DynamicPorts = copyMap(builder.DynamicPorts)
+	builder.DynamicPorts[name] = containerPort
+	return builder
+}
+
+// WithBindMount mounts the provided host path at the container path.  Mounts are merged with prior ones,
+// none by default.
+func (container Container) WithBindMount(hostPath, containerPath string) Container {
+	builder := container
+	builder.BindMounts = copyMap(builder.BindMounts)
+	builder.BindMounts[hostPath] = containerPath
+	return builder
+}
+
 func (container Container) WithName(name string) Container {
 	container.ContainerName = name
 	return container
@@ -136,6 +186,9 @@ func (container Container) WillWaitForLogs(logStatements ...string) Container {
 }
 
 func (container Container) Build() *Container {
+	if image, ok := container.PlatformImages[Platform()]; ok {
+		container.Image = image
+	}
 	networkMode := dockerContainer.NetworkMode("default")
 	if container.ContainerNetworkMode != "" {
 		networkMode = dockerContainer.NetworkMode(container.ContainerNetworkMode)
@@ -146,6 +199,7 @@ func (container Container) Build() *Container {
 		Cmd:            container.Cmd,
 		Env:            container.Env,
 		ExposedPorts:   container.ExposedPorts,
+		BindMounts:     container.BindMounts,
 		Name:           container.ContainerName,
 		Networks:       container.ContainerNetworks,
 		NetworkMode:    networkMode,
@@ -187,6 +241,20 @@ func (container *Container) Stop(ctx context.Context) error {
 	return container.Terminate(ctx)
 }
 
+// StopGracefully sends the container's process a SIGTERM and waits for it to exit, killing it after the timeout.
+// This allows it to write state on exit, like coverage data, that's lost when a running container is terminated.
+func (container *Container) StopGracefully(ctx context.Context, timeout time.Duration) error {
+	if err := container.assertStarted("StopGracefully"); err != nil {
+		return err
+	}
+	client, err := dockerClient.NewClientWithOpts(dockerClient.FromEnv, dockerClient.WithAPIVersionNegotiation())
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.ContainerStop(ctx, container.GetContainerID(), &timeout)
+}
+
 func (container *Container) GetContainerID() string {
 	if err := container.assertStarted("GetContainerID"); err != nil {
 		return ""
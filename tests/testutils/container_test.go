@@ -126,6 +126,56 @@ func TestExposedPortsBuilderMethod(t *testing.T) {
 	assert.Empty(t, builder.ExposedPorts)
 }
 
+func TestDynamicExposedPortBuilderMethod(t *testing.T) {
+	builder := NewContainer()
+	withDynamicPort := builder.WithDynamicExposedPort("SOME_PORT", "123")
+	assert.Equal(t, map[string]string{"SOME_PORT": "123"}, withDynamicPort.DynamicPorts)
+	assert.Empty(t, builder.DynamicPorts)
+
+	additionalWithDynamicPort := withDynamicPort.WithDynamicExposedPort("ANOTHER_PORT", "234")
+	assert.Equal(t, map[string]string{"SOME_PORT": "123", "ANOTHER_PORT": "234"}, additionalWithDynamicPort.DynamicPorts)
+	assert.Equal(t, map[string]string{"SOME_PORT": "123"}, withDynamicPort.DynamicPorts)
+	assert.Empty(t, builder.DynamicPorts)
+}
+
+func TestBindMountBuilderMethod(t *testing.T) {
+	builder := NewContainer()
+	withBindMount := builder.WithBindMount("/some/host/path", "/some/container/path")
+	assert.Equal(t, map[string]string{"/some/host/path": "/some/container/path"}, withBindMount.BindMounts)
+	assert.Empty(t, builder.BindMounts)
+
+	additionalWithBindMount := withBindMount.WithBindMount("/another/host/path", "/another/container/path")
+	assert.Equal(t, map[string]string{
+		"/some/host/path": "/some/container/path", "/another/host/path": "/another/container/path",
+	}, additionalWithBindMount.BindMounts)
+	assert.Equal(t, map[string]string{"/some/host/path": "/some/container/path"}, withBindMount.BindMounts)
+
+	container := additionalWithBindMount.Build()
+	assert.Equal(t, additionalWithBindMount.BindMounts, container.req.BindMounts)
+}
+
+func TestPlatformBuilderMethods(t *testing.T) {
+	builder := NewContainer().WithImage("some-image")
+	withPlatformImage := builder.WithPlatformImage("arm64", "some-arm64-image")
+	assert.Equal(t, map[string]string{"arm64": "some-arm64-image"}, withPlatformImage.PlatformImages)
+	assert.Empty(t, builder.PlatformImages)
+
+	t.Setenv(dockerDefaultPlatformEnvVar, "linux/arm64")
+	assert.Equal(t, "some-arm64-image", withPlatformImage.Build().Image)
+	assert.Equal(t, "some-image", builder.Build().Image)
+
+	t.Setenv(dockerDefaultPlatformEnvVar, "linux/amd64")
+	assert.Equal(t, "some-image", withPlatformImage.Build().Image)
+
+	withSkippedPlatforms := builder.WillSkipOnPlatforms("arm64", "ppc64le")
+	assert.Equal(t, []string{"arm64", "ppc64le"}, withSkippedPlatforms.SkippedPlatforms)
+	assert.Empty(t, builder.SkippedPlatforms)
+	assert.True(t, withSkippedPlatforms.SkipsPlatform("arm64"))
+	assert.True(t, withSkippedPlatforms.SkipsPlatform("ppc64le"))
+	assert.False(t, withSkippedPlatforms.SkipsPlatform("amd64"))
+	assert.False(t, builder.SkipsPlatform("arm64"))
+}
+
 func TestWaitingForPortsBuilderMethod(t *testing.T) {
 	builder := NewContainer()
 	waitForPorts := builder.WillWaitForPorts("123", "234")
@@ -253,6 +303,10 @@ func TestTestcontainersContainerMethodsRequireBuilding(t *testing.T) {
 	// doesn't panic
 	builder.FollowOutput(nil)
 
+	err = builder.StopGracefully(context.Background(), time.Second)
+	require.Error(t, err)
+	assert.Equal(t, "cannot invoke StopGracefully() on unstarted container", err.Error())
+
 	err = builder.StartLogProducer(context.Background())
 	require.Error(t, err)
 	assert.Equal(t, "cannot invoke StartLogProducer() on unstarted container", err.Error())
@@ -0,0 +1,212 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	effectiveConfigPath = "/debug/configz/effective"
+	// AnyConfigValue is a golden config value that matches any provided value, for settings like hostnames
+	// and memory sizes that vary by environment.  The setting must still be present.
+	AnyConfigValue = "<any>"
+	// UpdateGoldenConfigsEnvVar is the environment variable that, when "true", overwrites golden configs
+	// with the scraped effective config instead of comparing them.
+	UpdateGoldenConfigsEnvVar = "UPDATE_GOLDEN_CONFIGS"
+)
+
+// GetEffectiveConfig scrapes the effective (resolved and redacted) config from the Collector config server
+// at the provided endpoint (e.g. "localhost:55554").
+func GetEffectiveConfig(endpoint string) (map[string]any, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", endpoint, effectiveConfigPath))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected effective config response status: %s", resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]any
+	if err = yaml.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("invalid effective config: %w", err)
+	}
+	return normalizeConfigValue(config).(map[string]any), nil
+}
+
+// LoadGoldenConfig returns the golden config at the provided path.
+func LoadGoldenConfig(path string) (map[string]any, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var config map[string]any
+	if err = yaml.Unmarshal(content, &config); err != nil {
+		return nil, fmt.Errorf("invalid golden config %q: %w", path, err)
+	}
+	return normalizeConfigValue(config).(map[string]any), nil
+}
+
+// DiffConfigs semantically compares the expected and actual configs, returning a description of each difference
+// keyed by its path (e.g. "service::pipelines::metrics::receivers[1]").  Map ordering and scalar types
+// are disregarded (e.g. `size_mib: 64` and `size_mib: "64"` are equivalent), while list order is significant.
+func DiffConfigs(expected, actual map[string]any) []string {
+	var diffs []string
+	diffConfigValues("", expected, actual, &diffs)
+	sort.Strings(diffs)
+	return diffs
+}
+
+func diffConfigValues(key string, expected, actual any, diffs *[]string) {
+	if expected == AnyConfigValue {
+		return
+	}
+	switch expectedValue := expected.(type) {
+	case map[string]any:
+		actualValue, ok := actual.(map[string]any)
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected a map but got %v", key, actual))
+			return
+		}
+		for k, v := range expectedValue {
+			subKey := joinConfigKey(key, k)
+			if av, present := actualValue[k]; present {
+				diffConfigValues(subKey, v, av, diffs)
+			} else {
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing", subKey))
+			}
+		}
+		for k, v := range actualValue {
+			if _, present := expectedValue[k]; !present {
+				*diffs = append(*diffs, fmt.Sprintf("%s: unexpected %v", joinConfigKey(key, k), v))
+			}
+		}
+	case []any:
+		actualValue, ok := actual.([]any)
+		if !ok {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected a list but got %v", key, actual))
+			return
+		}
+		for i, v := range expectedValue {
+			itemKey := fmt.Sprintf("%s[%d]", key, i)
+			if i < len(actualValue) {
+				diffConfigValues(itemKey, v, actualValue[i], diffs)
+			} else {
+				*diffs = append(*diffs, fmt.Sprintf("%s: missing", itemKey))
+			}
+		}
+		for i := len(expectedValue); i < len(actualValue); i++ {
+			*diffs = append(*diffs, fmt.Sprintf("%s[%d]: unexpected %v", key, i, actualValue[i]))
+		}
+	default:
+		if !scalarConfigValuesEqual(expected, actual) {
+			*diffs = append(*diffs, fmt.Sprintf("%s: expected %v but got %v", key, expected, actual))
+		}
+	}
+}
+
+func scalarConfigValuesEqual(expected, actual any) bool {
+	if expected == nil || actual == nil {
+		return expected == nil && actual == nil
+	}
+	switch actual.(type) {
+	case map[string]any, []any:
+		return false
+	}
+	return fmt.Sprint(expected) == fmt.Sprint(actual)
+}
+
+func joinConfigKey(parent, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "::" + key
+}
+
+// normalizeConfigValue converts the map[any]any values unmarshalled by yaml.v2 to map[string]any.
+func normalizeConfigValue(value any) any {
+	switch v := value.(type) {
+	case map[any]any:
+		normalized := make(map[string]any, len(v))
+		for k, item := range v {
+			normalized[fmt.Sprint(k)] = normalizeConfigValue(item)
+		}
+		return normalized
+	case map[string]any:
+		normalized := make(map[string]any, len(v))
+		for k, item := range v {
+			normalized[k] = normalizeConfigValue(item)
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, len(v))
+		for i, item := range v {
+			normalized[i] = normalizeConfigValue(item)
+		}
+		return normalized
+	}
+	return value
+}
+
+// AssertEffectiveConfig starts a CollectorProcess with the provided env and args (e.g. "--config", "path/to/config.yaml")
+// and asserts that its effective config, scraped from the config server, semantically matches the golden config
+// (assuming it's in ./testdata/golden_configs).  Golden configs are overwritten with the effective config instead
+// when the UPDATE_GOLDEN_CONFIGS environment variable is "true".
+func (t *Testcase) AssertEffectiveConfig(goldenFilename string, env map[string]string, args ...string) {
+	configServerPort := strconv.Itoa(int(GetAvailablePort(t.T)))
+	collector, err := NewCollectorProcess().WithArgs(args...).WithEnv(env).WithEnv(map[string]string{
+		"SPLUNK_DEBUG_CONFIG_SERVER":      "true",
+		"SPLUNK_DEBUG_CONFIG_SERVER_PORT": configServerPort,
+	}).WithLogger(t.Logger).Build()
+	require.NoError(t, err)
+	require.NoError(t, collector.Start())
+	defer func() { require.NoError(t, collector.Shutdown()) }()
+
+	var effective map[string]any
+	require.Eventually(t, func() bool {
+		effective, err = GetEffectiveConfig("localhost:" + configServerPort)
+		return err == nil
+	}, 30*time.Second, 100*time.Millisecond, "failed retrieving effective config")
+
+	goldenPath := path.Join(".", "testdata", "golden_configs", goldenFilename)
+	if os.Getenv(UpdateGoldenConfigsEnvVar) == "true" {
+		content, marshalErr := yaml.Marshal(effective)
+		require.NoError(t, marshalErr)
+		require.NoError(t, os.WriteFile(goldenPath, content, 0600))
+		return
+	}
+
+	golden, err := LoadGoldenConfig(goldenPath)
+	require.NoError(t, err)
+	if diffs := DiffConfigs(golden, effective); len(diffs) != 0 {
+		t.Errorf("effective config doesn't match %s (set %s=true to update it):\n%s",
+			goldenPath, UpdateGoldenConfigsEnvVar, strings.Join(diffs, "\n"))
+	}
+}
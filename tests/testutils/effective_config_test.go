@@ -0,0 +1,131 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"path"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadGoldenConfig(t *testing.T) {
+	golden, err := LoadGoldenConfig(path.Join(".", "testdata", "golden_config.yaml"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"extensions": map[string]any{"memory_ballast": map[string]any{"size_mib": AnyConfigValue}},
+		"receivers": map[string]any{"otlp": map[string]any{"protocols": map[string]any{
+			"grpc": map[string]any{"endpoint": "0.0.0.0:4317"},
+		}}},
+		"exporters": map[string]any{"signalfx": map[string]any{"access_token": "<redacted>", "realm": "us0"}},
+		"service": map[string]any{
+			"extensions": []any{"memory_ballast"},
+			"pipelines": map[string]any{"metrics": map[string]any{
+				"receivers": []any{"otlp"}, "processors": nil, "exporters": []any{"signalfx"},
+			}},
+		},
+	}, golden)
+}
+
+func TestGetEffectiveConfig(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != effectiveConfigPath {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = writer.Write([]byte("exporters:\n  signalfx:\n    access_token: <redacted>\n    realm: us0\n"))
+	}))
+	defer server.Close()
+
+	effective, err := GetEffectiveConfig(strings.TrimPrefix(server.URL, "http://"))
+	require.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"exporters": map[string]any{"signalfx": map[string]any{"access_token": "<redacted>", "realm": "us0"}},
+	}, effective)
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	effective, err = GetEffectiveConfig(strings.TrimPrefix(notFound.URL, "http://"))
+	require.EqualError(t, err, "unexpected effective config response status: 404 Not Found")
+	assert.Nil(t, effective)
+}
+
+func TestDiffConfigs(t *testing.T) {
+	golden, err := LoadGoldenConfig(path.Join(".", "testdata", "golden_config.yaml"))
+	require.NoError(t, err)
+
+	matching := map[string]any{
+		"extensions": map[string]any{"memory_ballast": map[string]any{"size_mib": "168"}},
+		"receivers": map[string]any{"otlp": map[string]any{"protocols": map[string]any{
+			"grpc": map[string]any{"endpoint": "0.0.0.0:4317"},
+		}}},
+		"exporters": map[string]any{"signalfx": map[string]any{"realm": "us0", "access_token": "<redacted>"}},
+		"service": map[string]any{
+			"pipelines": map[string]any{"metrics": map[string]any{
+				"exporters": []any{"signalfx"}, "processors": nil, "receivers": []any{"otlp"},
+			}},
+			"extensions": []any{"memory_ballast"},
+		},
+	}
+	assert.Empty(t, DiffConfigs(golden, matching))
+
+	different := map[string]any{
+		"extensions": map[string]any{"memory_ballast": map[string]any{}},
+		"receivers": map[string]any{"otlp": map[string]any{"protocols": map[string]any{
+			"grpc": map[string]any{"endpoint": "0.0.0.0:4317"},
+			"http": map[string]any{"endpoint": "0.0.0.0:4318"},
+		}}},
+		"exporters": map[string]any{"signalfx": map[string]any{"realm": "us1", "access_token": "<redacted>"}},
+		"service": map[string]any{
+			"pipelines": map[string]any{"metrics": map[string]any{
+				"exporters": []any{"signalfx", "logging"}, "processors": []any{"batch"}, "receivers": "otlp",
+			}},
+			"extensions": []any{},
+		},
+	}
+	assert.Equal(t, []string{
+		"exporters::signalfx::realm: expected us0 but got us1",
+		"extensions::memory_ballast::size_mib: missing",
+		"receivers::otlp::protocols::http: unexpected map[endpoint:0.0.0.0:4318]",
+		"service::extensions[0]: missing",
+		"service::pipelines::metrics::exporters[1]: unexpected logging",
+		"service::pipelines::metrics::processors: expected <nil> but got [batch]",
+		"service::pipelines::metrics::receivers: expected a list but got otlp",
+	}, DiffConfigs(golden, different))
+}
+
+func TestDiffConfigsScalarTypes(t *testing.T) {
+	for _, tt := range []struct {
+		expected any
+		actual   any
+		equal    bool
+	}{
+		{expected: 64, actual: "64", equal: true},
+		{expected: true, actual: "true", equal: true},
+		{expected: 1.5, actual: 1.5, equal: true},
+		{expected: "", actual: nil, equal: false},
+		{expected: "a", actual: []any{"a"}, equal: false},
+	} {
+		t.Run(fmt.Sprintf("%v-%v", tt.expected, tt.actual), func(t *testing.T) {
+			diffs := DiffConfigs(map[string]any{"key": tt.expected}, map[string]any{"key": tt.actual})
+			assert.Equal(t, tt.equal, len(diffs) == 0, diffs)
+		})
+	}
+}
@@ -21,6 +21,7 @@ import (
 	"runtime"
 	"strconv"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -31,6 +32,24 @@ type portpair struct {
 	last  string
 }
 
+// reservedPorts tracks every port provided by GetAvailablePort so that parallel tests
+// in the same process are never handed the same port.
+var reservedPorts = struct {
+	ports map[string]bool
+	sync.Mutex
+}{ports: map[string]bool{}}
+
+// reservePort records the provided port as in use, returning false if it was already reserved.
+func reservePort(port string) bool {
+	reservedPorts.Lock()
+	defer reservedPorts.Unlock()
+	if reservedPorts.ports[port] {
+		return false
+	}
+	reservedPorts.ports[port] = true
+	return true
+}
+
 // getAvailableLocalAddress finds an available local port and returns an endpoint
 // describing it. The port is available for opening when this function returns
 // provided that there is no race by some other code to grab the same port
@@ -46,7 +65,8 @@ func getAvailableLocalAddress(t *testing.T) string {
 
 // GetAvailablePort finds an available local port and returns it. The port is
 // available for opening when this function returns provided that there is no
-// race by some other code to grab the same port immediately.
+// race by some other code to grab the same port immediately.  Ports are never
+// returned more than once per process to be safe for parallel test usage.
 func GetAvailablePort(t *testing.T) uint16 {
 	// Retry has been added for windows as net.Listen can return a port that is not actually available. Details can be
 	// found in https://github.com/docker/for-win/issues/3171 but to summarize Hyper-V will reserve ranges of ports
@@ -74,6 +94,9 @@ func GetAvailablePort(t *testing.T) uint16 {
 				}
 			}
 		}
+		if portFound {
+			portFound = reservePort(port)
+		}
 	}
 
 	portInt, err := strconv.Atoi(port)
@@ -34,6 +34,21 @@ func TestGetAvailablePort(t *testing.T) {
 	testEndpointAvailable(t, "localhost:"+portStr)
 }
 
+func TestGetAvailablePortIsUnique(t *testing.T) {
+	provided := make(chan uint16, 50)
+	for i := 0; i < 50; i++ {
+		go func() {
+			provided <- GetAvailablePort(t)
+		}()
+	}
+	ports := map[uint16]bool{}
+	for i := 0; i < 50; i++ {
+		port := <-provided
+		require.False(t, ports[port], "port %d provided more than once", port)
+		ports[port] = true
+	}
+}
+
 func testEndpointAvailable(t *testing.T, endpoint string) {
 	// Endpoint should be free.
 	ln0, err := net.Listen("tcp", endpoint)
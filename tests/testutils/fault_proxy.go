@@ -0,0 +1,412 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+)
+
+// Fault is a failure injected by a FaultProxy into a proxied request.
+type Fault struct {
+	name         string
+	latency      time.Duration
+	status       int
+	reset        bool
+	dripSize     int
+	dripInterval time.Duration
+}
+
+// LatencyFault delays the request by the duration before it's proxied, or failed by the other faults.
+func LatencyFault(latency time.Duration) Fault {
+	return Fault{name: fmt.Sprintf("latency %s", latency), latency: latency}
+}
+
+// StatusFault responds with the HTTP status instead of proxying the request.  gRPC requests receive the
+// gRPC status of the HTTP status, e.g. UNAVAILABLE for 503, so that the client handles it like a server's.
+func StatusFault(status int) Fault {
+	return Fault{name: fmt.Sprintf("status %d", status), status: status}
+}
+
+// ResetFault resets the connection instead of proxying the request.  HTTP/2 requests, like gRPC ones,
+// have their stream reset instead.
+func ResetFault() Fault {
+	return Fault{name: "reset", reset: true}
+}
+
+// SlowDripFault proxies the request, but sends the response body in chunks of the size every interval.
+func SlowDripFault(chunkSize int, interval time.Duration) Fault {
+	return Fault{name: fmt.Sprintf("slow drip %dB/%s", chunkSize, interval), dripSize: chunkSize, dripInterval: interval}
+}
+
+func (f Fault) String() string {
+	return f.name
+}
+
+// FaultProxyRequest is a request received by a FaultProxy, with the faults injected into it.
+type FaultProxyRequest struct {
+	Method string
+	Path   string
+	Faults []string
+	// Status is the HTTP status of the response, 0 if the connection was reset and 502 if the target is
+	// unreachable.
+	Status int
+}
+
+type faultInjection struct {
+	faults []Fault
+	// remaining is the number of requests the faults are injected into, all of them if negative
+	remaining int
+}
+
+type dripContextKey struct{}
+
+// To be used as a builder whose Build() method provides the actual instance capable of proxying HTTP and
+// gRPC requests to a target, like a backend sink, injecting latency, error statuses, connection resets,
+// and slow responses.  A running Collector's exporter can use its Endpoint instead of the target's to
+// test its retry and queue behavior.  Plaintext gRPC is proxied over HTTP/2, so TLS isn't supported.
+type FaultProxy struct {
+	server     *http.Server
+	listener   net.Listener
+	Logger     *zap.Logger
+	proxy      *httputil.ReverseProxy
+	Endpoint   string
+	Target     string
+	injections []faultInjection
+	requests   []FaultProxyRequest
+	lock       *sync.Mutex
+}
+
+func NewFaultProxy() FaultProxy {
+	return FaultProxy{}
+}
+
+// Required
+func (fp FaultProxy) WithEndpoint(endpoint string) FaultProxy {
+	fp.Endpoint = endpoint
+	return fp
+}
+
+// Required, the host:port requests are proxied to.
+func (fp FaultProxy) WithTarget(target string) FaultProxy {
+	fp.Target = target
+	return fp
+}
+
+// Nop logger by default
+func (fp FaultProxy) WithLogger(logger *zap.Logger) FaultProxy {
+	fp.Logger = logger
+	return fp
+}
+
+func (fp FaultProxy) Build() (*FaultProxy, error) {
+	if fp.Endpoint == "" {
+		return nil, fmt.Errorf("must provide an Endpoint for FaultProxy")
+	}
+	if fp.Target == "" {
+		return nil, fmt.Errorf("must provide a Target for FaultProxy")
+	}
+	if fp.Logger == nil {
+		fp.Logger = zap.NewNop()
+	}
+
+	proxy := &FaultProxy{
+		Endpoint: fp.Endpoint,
+		Target:   fp.Target,
+		Logger:   fp.Logger,
+		lock:     &sync.Mutex{},
+	}
+	target := &url.URL{Scheme: "http", Host: fp.Target}
+	proxy.proxy = httputil.NewSingleHostReverseProxy(target)
+	proxy.proxy.Transport = faultProxyTransport{
+		http1: http.DefaultTransport.(*http.Transport).Clone(),
+		http2: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, _ *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+	// streamed responses, like those of gRPC, and dripped chunks are sent as soon as they are read
+	proxy.proxy.FlushInterval = -1
+	proxy.proxy.ModifyResponse = dripResponse
+	proxy.proxy.ErrorHandler = func(w http.ResponseWriter, r *http.Request, err error) {
+		proxy.Logger.Debug("FaultProxy failed proxying request", zap.String("path", r.URL.Path), zap.Error(err))
+		w.WriteHeader(http.StatusBadGateway)
+	}
+	proxy.server = &http.Server{
+		Handler:           h2c.NewHandler(http.HandlerFunc(proxy.handle), &http2.Server{}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	return proxy, nil
+}
+
+func (fp *FaultProxy) assertBuilt(operation string) error {
+	if fp.server == nil {
+		return fmt.Errorf("cannot invoke %s() on a FaultProxy that hasn't been built", operation)
+	}
+	return nil
+}
+
+func (fp *FaultProxy) Start() error {
+	if err := fp.assertBuilt("Start"); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", fp.Endpoint)
+	if err != nil {
+		return err
+	}
+	fp.listener = listener
+	go func() {
+		if serveErr := fp.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			fp.Logger.Error("FaultProxy server failed", zap.Error(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (fp *FaultProxy) Shutdown() error {
+	if err := fp.assertBuilt("Shutdown"); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return fp.server.Shutdown(ctx)
+}
+
+// InjectFaults injects the faults into the next count requests, or into all of them until ClearFaults() if
+// count is negative.  Injections apply in order: the faults of an injection are injected once those of the
+// previous injections have been injected into their count of requests.
+func (fp *FaultProxy) InjectFaults(count int, faults ...Fault) {
+	if count == 0 || len(faults) == 0 {
+		return
+	}
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	fp.injections = append(fp.injections, faultInjection{faults: faults, remaining: count})
+}
+
+// ClearFaults removes all the injected faults, so that requests are proxied as is.
+func (fp *FaultProxy) ClearFaults() {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	fp.injections = nil
+}
+
+// Requests returns all received requests in order.
+func (fp *FaultProxy) Requests() []FaultProxyRequest {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	return append([]FaultProxyRequest{}, fp.requests...)
+}
+
+// Reset clears all recorded requests.  Injected faults aren't cleared.
+func (fp *FaultProxy) Reset() {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	fp.requests = nil
+}
+
+// nextFaults returns the faults to inject into the next request.
+func (fp *FaultProxy) nextFaults() []Fault {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	if len(fp.injections) == 0 {
+		return nil
+	}
+	injection := &fp.injections[0]
+	faults := injection.faults
+	if injection.remaining > 0 {
+		if injection.remaining--; injection.remaining == 0 {
+			fp.injections = fp.injections[1:]
+		}
+	}
+	return faults
+}
+
+func (fp *FaultProxy) record(request FaultProxyRequest) {
+	fp.lock.Lock()
+	defer fp.lock.Unlock()
+	fp.requests = append(fp.requests, request)
+}
+
+func (fp *FaultProxy) handle(w http.ResponseWriter, r *http.Request) {
+	faults := fp.nextFaults()
+	request := FaultProxyRequest{Method: r.Method, Path: r.URL.Path}
+	for _, fault := range faults {
+		request.Faults = append(request.Faults, fault.String())
+	}
+
+	var status int
+	var reset bool
+	ctx := r.Context()
+	for _, fault := range faults {
+		if fault.latency > 0 {
+			select {
+			case <-time.After(fault.latency):
+			case <-ctx.Done():
+				fp.record(request)
+				return
+			}
+		}
+		if fault.status != 0 && status == 0 {
+			status = fault.status
+		}
+		reset = reset || fault.reset
+		if fault.dripSize > 0 {
+			ctx = context.WithValue(ctx, dripContextKey{}, fault)
+		}
+	}
+
+	switch {
+	case reset:
+		fp.record(request)
+		resetConnection(w)
+	case status != 0:
+		request.Status = status
+		fp.record(request)
+		writeFaultStatus(w, r, status)
+	default:
+		recorder := &statusRecorder{ResponseWriter: w}
+		fp.proxy.ServeHTTP(recorder, r.WithContext(ctx))
+		request.Status = recorder.status
+		fp.record(request)
+	}
+}
+
+// resetConnection closes the connection of the request with a TCP reset, or resets its HTTP/2 stream.
+func resetConnection(w http.ResponseWriter) {
+	if hijacker, ok := w.(http.Hijacker); ok {
+		if conn, _, err := hijacker.Hijack(); err == nil {
+			if tcpConn, ok := conn.(*net.TCPConn); ok {
+				_ = tcpConn.SetLinger(0)
+			}
+			_ = conn.Close()
+			return
+		}
+	}
+	panic(http.ErrAbortHandler)
+}
+
+// writeFaultStatus responds with the status, or with its gRPC status in a trailers-only response to gRPC requests.
+func writeFaultStatus(w http.ResponseWriter, r *http.Request, status int) {
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "application/grpc") {
+		http.Error(w, http.StatusText(status), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(grpcStatusCode(status)))
+	w.Header().Set("Grpc-Message", http.StatusText(status))
+	w.WriteHeader(http.StatusOK)
+}
+
+// grpcStatusCode returns the gRPC status code of the HTTP status, per
+// https://github.com/grpc/grpc/blob/master/doc/http-grpc-status-mapping.md
+func grpcStatusCode(status int) int {
+	switch status {
+	case http.StatusBadRequest:
+		return 13 // INTERNAL
+	case http.StatusUnauthorized:
+		return 16 // UNAUTHENTICATED
+	case http.StatusForbidden:
+		return 7 // PERMISSION_DENIED
+	case http.StatusNotFound:
+		return 12 // UNIMPLEMENTED
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return 14 // UNAVAILABLE
+	default:
+		return 2 // UNKNOWN
+	}
+}
+
+// faultProxyTransport proxies HTTP/2 requests, like gRPC ones, over plaintext HTTP/2 and the others over HTTP/1.1.
+type faultProxyTransport struct {
+	http1 http.RoundTripper
+	http2 http.RoundTripper
+}
+
+func (t faultProxyTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.ProtoMajor == 2 {
+		return t.http2.RoundTrip(r)
+	}
+	return t.http1.RoundTrip(r)
+}
+
+// dripResponse makes the body of the proxied response be read in the chunks of the request's slow drip fault.
+func dripResponse(resp *http.Response) error {
+	if fault, ok := resp.Request.Context().Value(dripContextKey{}).(Fault); ok {
+		resp.Body = &dripReader{ReadCloser: resp.Body, ctx: resp.Request.Context(), fault: fault}
+	}
+	return nil
+}
+
+type dripReader struct {
+	io.ReadCloser
+	ctx   context.Context
+	fault Fault
+}
+
+func (d *dripReader) Read(p []byte) (int, error) {
+	select {
+	case <-time.After(d.fault.dripInterval):
+	case <-d.ctx.Done():
+		return 0, d.ctx.Err()
+	}
+	if len(p) > d.fault.dripSize {
+		p = p[:d.fault.dripSize]
+	}
+	return d.ReadCloser.Read(p)
+}
+
+// statusRecorder records the status of the proxied response.
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+	s.ResponseWriter.WriteHeader(status)
+}
+
+func (s *statusRecorder) Write(b []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return s.ResponseWriter.Write(b)
+}
+
+func (s *statusRecorder) Flush() {
+	if flusher, ok := s.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
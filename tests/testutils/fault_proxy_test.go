@@ -0,0 +1,206 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+func newStartedFaultProxy(t *testing.T, target string) *FaultProxy {
+	proxy, err := NewFaultProxy().WithEndpoint(getAvailableLocalAddress(t)).WithTarget(target).Build()
+	require.NoError(t, err)
+	require.NoError(t, proxy.Start())
+	t.Cleanup(func() { require.NoError(t, proxy.Shutdown()) })
+	return proxy
+}
+
+func newFaultProxyTarget(t *testing.T, body string) (*httptest.Server, *int64) {
+	var received int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt64(&received, 1)
+		_, _ = io.WriteString(w, body)
+	}))
+	t.Cleanup(server.Close)
+	return server, &received
+}
+
+func getThroughFaultProxy(proxy *FaultProxy, path string) (int, string, error) {
+	client := &http.Client{Transport: &http.Transport{DisableKeepAlives: true}}
+	resp, err := client.Get("http://" + proxy.Endpoint + path)
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(body), err
+}
+
+func TestFaultProxyBuilder(t *testing.T) {
+	proxy, err := NewFaultProxy().WithTarget("localhost:4318").Build()
+	require.EqualError(t, err, "must provide an Endpoint for FaultProxy")
+	assert.Nil(t, proxy)
+
+	proxy, err = NewFaultProxy().WithEndpoint("localhost:14318").Build()
+	require.EqualError(t, err, "must provide a Target for FaultProxy")
+	assert.Nil(t, proxy)
+
+	proxy, err = NewFaultProxy().WithEndpoint("localhost:14318").WithTarget("localhost:4318").Build()
+	require.NoError(t, err)
+	assert.NotNil(t, proxy.Logger)
+	assert.Equal(t, "localhost:4318", proxy.Target)
+
+	unbuilt := NewFaultProxy()
+	assert.EqualError(t, unbuilt.Start(), "cannot invoke Start() on a FaultProxy that hasn't been built")
+}
+
+func TestFaultProxyStatusAndLatency(t *testing.T) {
+	target, received := newFaultProxyTarget(t, "ok")
+	proxy := newStartedFaultProxy(t, target.Listener.Addr().String())
+
+	proxy.InjectFaults(2, StatusFault(http.StatusServiceUnavailable))
+	proxy.InjectFaults(1, LatencyFault(100*time.Millisecond))
+
+	for i := 0; i < 2; i++ {
+		code, _, err := getThroughFaultProxy(proxy, "/v1/logs")
+		require.NoError(t, err)
+		assert.Equal(t, http.StatusServiceUnavailable, code)
+	}
+	assert.Zero(t, atomic.LoadInt64(received))
+
+	start := time.Now()
+	code, body, err := getThroughFaultProxy(proxy, "/v1/logs")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body)
+
+	code, _, err = getThroughFaultProxy(proxy, "/v1/metrics")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.EqualValues(t, 2, atomic.LoadInt64(received))
+
+	assert.Equal(t, []FaultProxyRequest{
+		{Method: http.MethodGet, Path: "/v1/logs", Faults: []string{"status 503"}, Status: http.StatusServiceUnavailable},
+		{Method: http.MethodGet, Path: "/v1/logs", Faults: []string{"status 503"}, Status: http.StatusServiceUnavailable},
+		{Method: http.MethodGet, Path: "/v1/logs", Faults: []string{"latency 100ms"}, Status: http.StatusOK},
+		{Method: http.MethodGet, Path: "/v1/metrics", Status: http.StatusOK},
+	}, proxy.Requests())
+
+	proxy.Reset()
+	assert.Empty(t, proxy.Requests())
+}
+
+func TestFaultProxyResetAndClear(t *testing.T) {
+	target, received := newFaultProxyTarget(t, "ok")
+	proxy := newStartedFaultProxy(t, target.Listener.Addr().String())
+
+	proxy.InjectFaults(-1, ResetFault())
+	for i := 0; i < 3; i++ {
+		_, _, err := getThroughFaultProxy(proxy, "/")
+		require.Error(t, err)
+	}
+	assert.Zero(t, atomic.LoadInt64(received))
+
+	proxy.ClearFaults()
+	code, body, err := getThroughFaultProxy(proxy, "/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "ok", body)
+
+	requests := proxy.Requests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, FaultProxyRequest{Method: http.MethodGet, Path: "/", Faults: []string{"reset"}}, requests[0])
+}
+
+func TestFaultProxySlowDrip(t *testing.T) {
+	target, _ := newFaultProxyTarget(t, "0123456789")
+	proxy := newStartedFaultProxy(t, target.Listener.Addr().String())
+
+	proxy.InjectFaults(1, SlowDripFault(2, 20*time.Millisecond))
+	start := time.Now()
+	code, body, err := getThroughFaultProxy(proxy, "/")
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), 100*time.Millisecond)
+	assert.Equal(t, http.StatusOK, code)
+	assert.Equal(t, "0123456789", body)
+	assert.Equal(t, []string{"slow drip 2B/20ms"}, proxy.Requests()[0].Faults)
+}
+
+func TestFaultProxyUnreachableTarget(t *testing.T) {
+	proxy := newStartedFaultProxy(t, getAvailableLocalAddress(t))
+
+	code, _, err := getThroughFaultProxy(proxy, "/")
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusBadGateway, code)
+}
+
+func TestFaultProxyGRPC(t *testing.T) {
+	listener, err := net.Listen("tcp", "localhost:0")
+	require.NoError(t, err)
+	server := grpc.NewServer()
+	healthpb.RegisterHealthServer(server, health.NewServer())
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	proxy := newStartedFaultProxy(t, listener.Addr().String())
+	conn, err := grpc.Dial(proxy.Endpoint, grpc.WithTransportCredentials(insecure.NewCredentials()))
+	require.NoError(t, err)
+	t.Cleanup(func() { require.NoError(t, conn.Close()) })
+	client := healthpb.NewHealthClient(conn)
+
+	check := func() (*healthpb.HealthCheckResponse, error) {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return client.Check(ctx, &healthpb.HealthCheckRequest{})
+	}
+
+	proxy.InjectFaults(1, StatusFault(http.StatusServiceUnavailable))
+	_, err = check()
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	proxy.InjectFaults(1, StatusFault(http.StatusForbidden))
+	_, err = check()
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	proxy.InjectFaults(1, ResetFault())
+	_, err = check()
+	assert.Error(t, err)
+
+	proxy.InjectFaults(1, LatencyFault(50*time.Millisecond))
+	resp, err := check()
+	require.NoError(t, err)
+	assert.Equal(t, healthpb.HealthCheckResponse_SERVING, resp.Status)
+
+	requests := proxy.Requests()
+	require.Len(t, requests, 4)
+	assert.Equal(t, "/grpc.health.v1.Health/Check", requests[3].Path)
+	assert.Equal(t, http.StatusOK, requests[3].Status)
+}
@@ -0,0 +1,429 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	hecEventPath  = "/services/collector"
+	hecRawPath    = "/services/collector/raw"
+	hecAckPath    = "/services/collector/ack"
+	hecHealthPath = "/services/collector/health"
+
+	hecChannelHeader = "X-Splunk-Request-Channel"
+)
+
+// HECEvent is a recorded Splunk HEC event with its metadata.  Events received by the raw endpoint have a string
+// Event for each of their lines, and their metadata is from the request's query parameters.
+type HECEvent struct {
+	Event      any            `json:"event"`
+	Fields     map[string]any `json:"fields,omitempty"`
+	Time       float64        `json:"time,omitempty"`
+	Host       string         `json:"host,omitempty"`
+	Source     string         `json:"source,omitempty"`
+	Sourcetype string         `json:"sourcetype,omitempty"`
+	Index      string         `json:"index,omitempty"`
+	Token      string         `json:"-"`
+	Channel    string         `json:"-"`
+	Raw        bool           `json:"-"`
+}
+
+// hecResponse is the HEC response body, with the codes of
+// https://docs.splunk.com/Documentation/Splunk/latest/Data/TroubleshootHTTPEventCollector
+type hecResponse struct {
+	AckID *int   `json:"ackId,omitempty"`
+	Text  string `json:"text"`
+	Code  int    `json:"code"`
+}
+
+type hecEventPayload struct {
+	Event      any            `json:"event"`
+	Fields     map[string]any `json:"fields"`
+	Time       json.Number    `json:"time"`
+	Host       string         `json:"host"`
+	Source     string         `json:"source"`
+	Sourcetype string         `json:"sourcetype"`
+	Index      string         `json:"index"`
+}
+
+// To be used as a builder whose Build() method provides the actual instance capable of serving a fake Splunk HTTP
+// Event Collector that records all received events with their metadata for test assertions.  A running Collector's
+// splunk_hec exporter can use its URL() as its endpoint.
+type HECBackend struct {
+	server   *http.Server
+	listener net.Listener
+	Logger   *zap.Logger
+	// the next ack ID of each channel
+	ackIDs   map[string]int
+	Endpoint string
+	Token    string
+	events   []HECEvent
+	lock     *sync.RWMutex
+	Acks     bool
+}
+
+func NewHECBackend() HECBackend {
+	return HECBackend{}
+}
+
+// Required
+func (hec HECBackend) WithEndpoint(endpoint string) HECBackend {
+	hec.Endpoint = endpoint
+	return hec
+}
+
+// Nop logger by default
+func (hec HECBackend) WithLogger(logger *zap.Logger) HECBackend {
+	hec.Logger = logger
+	return hec
+}
+
+// Requests with other tokens are rejected.  Any token is accepted by default.
+func (hec HECBackend) WithToken(token string) HECBackend {
+	hec.Token = token
+	return hec
+}
+
+// Enables indexer acknowledgement, which requires requests to specify a channel.  All events are
+// acknowledged as soon as they are received.
+func (hec HECBackend) WithAcks() HECBackend {
+	hec.Acks = true
+	return hec
+}
+
+func (hec HECBackend) Build() (*HECBackend, error) {
+	if hec.Endpoint == "" {
+		return nil, fmt.Errorf("must provide an Endpoint for HECBackend")
+	}
+	if hec.Logger == nil {
+		hec.Logger = zap.NewNop()
+	}
+
+	backend := &HECBackend{
+		Endpoint: hec.Endpoint,
+		Logger:   hec.Logger,
+		Token:    hec.Token,
+		Acks:     hec.Acks,
+		ackIDs:   map[string]int{},
+		lock:     &sync.RWMutex{},
+	}
+	mux := http.NewServeMux()
+	for _, path := range []string{hecEventPath, hecEventPath + "/event", hecEventPath + "/event/1.0"} {
+		mux.HandleFunc(path, backend.handleEvents)
+	}
+	mux.HandleFunc(hecRawPath, backend.handleRaw)
+	mux.HandleFunc(hecRawPath+"/1.0", backend.handleRaw)
+	mux.HandleFunc(hecAckPath, backend.handleAck)
+	mux.HandleFunc(hecAckPath+"/1.0", backend.handleAck)
+	mux.HandleFunc(hecHealthPath, backend.handleHealth)
+	mux.HandleFunc(hecHealthPath+"/1.0", backend.handleHealth)
+	backend.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return backend, nil
+}
+
+func (hec *HECBackend) assertBuilt(operation string) error {
+	if hec.server == nil {
+		return fmt.Errorf("cannot invoke %s() on an HECBackend that hasn't been built", operation)
+	}
+	return nil
+}
+
+func (hec *HECBackend) Start() error {
+	if err := hec.assertBuilt("Start"); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", hec.Endpoint)
+	if err != nil {
+		return err
+	}
+	hec.listener = listener
+	go func() {
+		if serveErr := hec.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			hec.Logger.Error("HECBackend server failed", zap.Error(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (hec *HECBackend) Shutdown() error {
+	if err := hec.assertBuilt("Shutdown"); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return hec.server.Shutdown(ctx)
+}
+
+// URL is the url of the backend's event endpoint, to be used as the splunk_hec exporter endpoint.
+func (hec *HECBackend) URL() string {
+	return fmt.Sprintf("http://%s%s", hec.Endpoint, hecEventPath)
+}
+
+// Events returns all received events in order.
+func (hec *HECBackend) Events() []HECEvent {
+	hec.lock.RLock()
+	defer hec.lock.RUnlock()
+	return append([]HECEvent{}, hec.events...)
+}
+
+// Reset clears all recorded events.  Ack IDs aren't reused.
+func (hec *HECBackend) Reset() {
+	hec.lock.Lock()
+	defer hec.lock.Unlock()
+	hec.events = nil
+}
+
+// AssertEventsReceived waits until all the expected events have been received, in any order and
+// among any others.  Their Token, Channel, and Raw fields are ignored.
+func (hec *HECBackend) AssertEventsReceived(t testing.TB, expected []HECEvent, waitTime time.Duration) error {
+	if err := hec.assertBuilt("AssertEventsReceived"); err != nil {
+		return err
+	}
+	var missing []HECEvent
+	if !assert.Eventually(t, func() bool {
+		received := hec.Events()
+		for i := range received {
+			received[i].Token, received[i].Channel, received[i].Raw = "", "", false
+		}
+		missing = nil
+		for _, event := range expected {
+			event.Token, event.Channel, event.Raw = "", "", false
+			if !containsHECEvent(received, event) {
+				missing = append(missing, event)
+			}
+		}
+		return len(missing) == 0
+	}, waitTime, 10*time.Millisecond, "Failed to receive expected HEC events") {
+		return fmt.Errorf("expected HEC events not received: %v", missing)
+	}
+	return nil
+}
+
+func containsHECEvent(events []HECEvent, event HECEvent) bool {
+	for _, candidate := range events {
+		if assert.ObjectsAreEqual(event, candidate) {
+			return true
+		}
+	}
+	return false
+}
+
+func (hec *HECBackend) handleEvents(w http.ResponseWriter, r *http.Request) {
+	token, channel, ok := hec.authorize(w, r)
+	if !ok {
+		return
+	}
+	body, err := readHECBody(r)
+	if err != nil {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+		return
+	}
+
+	var events []HECEvent
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	for {
+		var payload hecEventPayload
+		if err = decoder.Decode(&payload); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+			return
+		}
+		if payload.Event == nil {
+			writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Event field is required", Code: 12})
+			return
+		}
+		if payload.Event == "" {
+			writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Event field cannot be blank", Code: 13})
+			return
+		}
+		var eventTime float64
+		if payload.Time != "" {
+			if eventTime, err = payload.Time.Float64(); err != nil {
+				writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+				return
+			}
+		}
+		events = append(events, HECEvent{
+			Event:      payload.Event,
+			Fields:     payload.Fields,
+			Time:       eventTime,
+			Host:       payload.Host,
+			Source:     payload.Source,
+			Sourcetype: payload.Sourcetype,
+			Index:      payload.Index,
+			Token:      token,
+			Channel:    channel,
+		})
+	}
+	if len(events) == 0 {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "No data", Code: 5})
+		return
+	}
+	hec.record(w, channel, events)
+}
+
+// handleRaw records each line of the body as an event, with metadata from the query parameters.
+func (hec *HECBackend) handleRaw(w http.ResponseWriter, r *http.Request) {
+	token, channel, ok := hec.authorize(w, r)
+	if !ok {
+		return
+	}
+	body, err := readHECBody(r)
+	if err != nil {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+		return
+	}
+
+	query := r.URL.Query()
+	var events []HECEvent
+	scanner := bufio.NewScanner(bytes.NewReader(body))
+	scanner.Buffer(make([]byte, 0, 64*1024), len(body)+1)
+	for scanner.Scan() {
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		events = append(events, HECEvent{
+			Event:      line,
+			Host:       query.Get("host"),
+			Source:     query.Get("source"),
+			Sourcetype: query.Get("sourcetype"),
+			Index:      query.Get("index"),
+			Token:      token,
+			Channel:    channel,
+			Raw:        true,
+		})
+	}
+	if len(events) == 0 {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "No data", Code: 5})
+		return
+	}
+	hec.record(w, channel, events)
+}
+
+// handleAck reports all requested ack IDs of the channel that were issued as indexed.
+func (hec *HECBackend) handleAck(w http.ResponseWriter, r *http.Request) {
+	_, channel, ok := hec.authorize(w, r)
+	if !ok {
+		return
+	}
+	if !hec.Acks {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "ACK is disabled", Code: 14})
+		return
+	}
+	var request struct {
+		Acks []int `json:"acks"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Invalid data format", Code: 6})
+		return
+	}
+
+	hec.lock.RLock()
+	nextAckID := hec.ackIDs[channel]
+	hec.lock.RUnlock()
+	acks := map[string]bool{}
+	for _, ackID := range request.Acks {
+		acks[strconv.Itoa(ackID)] = ackID >= 0 && ackID < nextAckID
+	}
+	writeJSON(w, map[string]any{"acks": acks})
+}
+
+func (hec *HECBackend) handleHealth(w http.ResponseWriter, _ *http.Request) {
+	writeHECResponse(w, http.StatusOK, hecResponse{Text: "HEC is healthy", Code: 17})
+}
+
+// authorize validates the request's token and channel, writing the error response if they are invalid.
+func (hec *HECBackend) authorize(w http.ResponseWriter, r *http.Request) (token, channel string, ok bool) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return "", "", false
+	}
+	authorization := r.Header.Get("Authorization")
+	if authorization == "" {
+		writeHECResponse(w, http.StatusUnauthorized, hecResponse{Text: "Token is required", Code: 2})
+		return "", "", false
+	}
+	token = strings.TrimPrefix(authorization, "Splunk ")
+	if token == authorization {
+		writeHECResponse(w, http.StatusUnauthorized, hecResponse{Text: "Invalid authorization", Code: 3})
+		return "", "", false
+	}
+	if hec.Token != "" && token != hec.Token {
+		writeHECResponse(w, http.StatusForbidden, hecResponse{Text: "Invalid token", Code: 4})
+		return "", "", false
+	}
+	channel = r.Header.Get(hecChannelHeader)
+	if channel == "" {
+		channel = r.URL.Query().Get("channel")
+	}
+	if hec.Acks && channel == "" {
+		writeHECResponse(w, http.StatusBadRequest, hecResponse{Text: "Data channel is missing", Code: 10})
+		return "", "", false
+	}
+	return token, channel, true
+}
+
+func (hec *HECBackend) record(w http.ResponseWriter, channel string, events []HECEvent) {
+	response := hecResponse{Text: "Success", Code: 0}
+	hec.lock.Lock()
+	hec.events = append(hec.events, events...)
+	if hec.Acks {
+		ackID := hec.ackIDs[channel]
+		hec.ackIDs[channel] = ackID + 1
+		response.AckID = &ackID
+	}
+	hec.lock.Unlock()
+	writeHECResponse(w, http.StatusOK, response)
+}
+
+func readHECBody(r *http.Request) ([]byte, error) {
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return nil, fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+	return io.ReadAll(body)
+}
+
+func writeHECResponse(w http.ResponseWriter, status int, response hecResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,209 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStartedHECBackend(t *testing.T, builder HECBackend) *HECBackend {
+	backend, err := builder.WithEndpoint(getAvailableLocalAddress(t)).Build()
+	require.NoError(t, err)
+	require.NoError(t, backend.Start())
+	t.Cleanup(func() { require.NoError(t, backend.Shutdown()) })
+	return backend
+}
+
+func sendToHECBackend(t *testing.T, url string, body []byte, headers map[string]string) (int, map[string]any) {
+	req, err := http.NewRequest(http.MethodPost, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Splunk token")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	content, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	response := map[string]any{}
+	require.NoError(t, json.Unmarshal(content, &response))
+	return resp.StatusCode, response
+}
+
+func TestHECBackendBuilder(t *testing.T) {
+	backend, err := NewHECBackend().Build()
+	require.EqualError(t, err, "must provide an Endpoint for HECBackend")
+	assert.Nil(t, backend)
+
+	backend, err = NewHECBackend().WithEndpoint("localhost:8088").WithToken("token").WithAcks().Build()
+	require.NoError(t, err)
+	assert.NotNil(t, backend.Logger)
+	assert.Equal(t, "token", backend.Token)
+	assert.True(t, backend.Acks)
+	assert.Equal(t, "http://localhost:8088/services/collector", backend.URL())
+
+	unbuilt := NewHECBackend()
+	assert.EqualError(t, unbuilt.Start(), "cannot invoke Start() on an HECBackend that hasn't been built")
+}
+
+func TestHECBackendEvents(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend())
+
+	body := []byte(`{"time":1654041600.5,"host":"myhost","source":"mysource","sourcetype":"mysourcetype","index":"myindex","event":"a log","fields":{"k":"v"}}
+{"time":"1654041601","event":"metric","fields":{"metric_name:cpu.utilization":12.5}}`)
+	status, response := sendToHECBackend(t, backend.URL(), body, nil)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"text": "Success", "code": float64(0)}, response)
+
+	expected := []HECEvent{
+		{
+			Event:      "a log",
+			Fields:     map[string]any{"k": "v"},
+			Time:       1654041600.5,
+			Host:       "myhost",
+			Source:     "mysource",
+			Sourcetype: "mysourcetype",
+			Index:      "myindex",
+			Token:      "token",
+		},
+		{
+			Event:  "metric",
+			Fields: map[string]any{"metric_name:cpu.utilization": 12.5},
+			Time:   1654041601,
+			Token:  "token",
+		},
+	}
+	assert.Equal(t, expected, backend.Events())
+	require.NoError(t, backend.AssertEventsReceived(t, expected[1:], time.Second))
+
+	backend.Reset()
+	assert.Empty(t, backend.Events())
+}
+
+func TestHECBackendGzipEvents(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend())
+
+	var body bytes.Buffer
+	gzipWriter := gzip.NewWriter(&body)
+	_, err := gzipWriter.Write([]byte(`{"event":{"message":"a log"}}`))
+	require.NoError(t, err)
+	require.NoError(t, gzipWriter.Close())
+
+	status, _ := sendToHECBackend(t, backend.URL()+"/event", body.Bytes(), map[string]string{"Content-Encoding": "gzip"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, []HECEvent{{Event: map[string]any{"message": "a log"}, Token: "token"}}, backend.Events())
+}
+
+func TestHECBackendRaw(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend())
+
+	status, _ := sendToHECBackend(
+		t, backend.URL()+"/raw?channel=mychannel&index=myindex&sourcetype=mysourcetype&source=mysource&host=myhost",
+		[]byte("line one\r\n\nline two\n"), nil,
+	)
+	assert.Equal(t, http.StatusOK, status)
+
+	expected := HECEvent{
+		Host:       "myhost",
+		Source:     "mysource",
+		Sourcetype: "mysourcetype",
+		Index:      "myindex",
+		Token:      "token",
+		Channel:    "mychannel",
+		Raw:        true,
+	}
+	lineOne, lineTwo := expected, expected
+	lineOne.Event, lineTwo.Event = "line one", "line two"
+	assert.Equal(t, []HECEvent{lineOne, lineTwo}, backend.Events())
+}
+
+func TestHECBackendAcks(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend().WithAcks())
+
+	status, response := sendToHECBackend(t, backend.URL(), []byte(`{"event":"a log"}`), nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, map[string]any{"text": "Data channel is missing", "code": float64(10)}, response)
+
+	headers := map[string]string{hecChannelHeader: "mychannel"}
+	for i := 0; i < 2; i++ {
+		status, response = sendToHECBackend(t, backend.URL(), []byte(`{"event":"a log"}`), headers)
+		assert.Equal(t, http.StatusOK, status)
+		assert.Equal(t, map[string]any{"text": "Success", "code": float64(0), "ackId": float64(i)}, response)
+	}
+	assert.Len(t, backend.Events(), 2)
+
+	status, response = sendToHECBackend(t, backend.URL()+"/ack", []byte(`{"acks":[0,1,2]}`), headers)
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"acks": map[string]any{"0": true, "1": true, "2": false}}, response)
+
+	status, response = sendToHECBackend(t, backend.URL()+"/ack", []byte(`{"acks":[0]}`), map[string]string{hecChannelHeader: "other"})
+	assert.Equal(t, http.StatusOK, status)
+	assert.Equal(t, map[string]any{"acks": map[string]any{"0": false}}, response)
+}
+
+func TestHECBackendAcksDisabled(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend())
+
+	status, response := sendToHECBackend(t, backend.URL()+"/ack", []byte(`{"acks":[0]}`), nil)
+	assert.Equal(t, http.StatusBadRequest, status)
+	assert.Equal(t, map[string]any{"text": "ACK is disabled", "code": float64(14)}, response)
+}
+
+func TestHECBackendHealth(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend())
+
+	resp, err := http.Get(backend.URL() + "/health")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+}
+
+func TestHECBackendInvalidRequests(t *testing.T) {
+	backend := newStartedHECBackend(t, NewHECBackend().WithToken("token"))
+
+	for _, test := range []struct {
+		headers map[string]string
+		name    string
+		body    string
+		text    string
+		status  int
+		code    int
+	}{
+		{name: "invalid token", headers: map[string]string{"Authorization": "Splunk other"}, body: `{"event":"a log"}`, status: http.StatusForbidden, code: 4, text: "Invalid token"},
+		{name: "invalid authorization", headers: map[string]string{"Authorization": "token"}, body: `{"event":"a log"}`, status: http.StatusUnauthorized, code: 3, text: "Invalid authorization"},
+		{name: "no data", body: "", status: http.StatusBadRequest, code: 5, text: "No data"},
+		{name: "invalid data", body: `{"event":`, status: http.StatusBadRequest, code: 6, text: "Invalid data format"},
+		{name: "missing event", body: `{"host":"myhost"}`, status: http.StatusBadRequest, code: 12, text: "Event field is required"},
+		{name: "blank event", body: `{"event":""}`, status: http.StatusBadRequest, code: 13, text: "Event field cannot be blank"},
+	} {
+		test := test
+		t.Run(test.name, func(t *testing.T) {
+			status, response := sendToHECBackend(t, backend.URL(), []byte(test.body), test.headers)
+			assert.Equal(t, test.status, status)
+			assert.Equal(t, map[string]any{"text": test.text, "code": float64(test.code)}, response)
+		})
+	}
+	assert.Empty(t, backend.Events())
+}
@@ -0,0 +1,333 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	internalMetricsPath = "/metrics"
+	// InternalMetricsPortName is the allocated port name of Testcase.InternalMetricsEndpoint(), rendered to the
+	// tested config as the ${INTERNAL_METRICS_PORT} environment variable.
+	InternalMetricsPortName = "INTERNAL_METRICS_PORT"
+
+	// The signals of the obsreport counters, the suffixes of their names.
+	SpansSignal        = "spans"
+	MetricPointsSignal = "metric_points"
+	LogRecordsSignal   = "log_records"
+)
+
+// InternalMetric is a sample of the Collector's internal metrics, like the otelcol_receiver_accepted_spans
+// obsreport counter of a receiver.
+type InternalMetric struct {
+	Labels map[string]string
+	Name   string
+	Value  float64
+}
+
+// InternalMetrics are the samples scraped from the Collector's internal Prometheus endpoint.
+type InternalMetrics []InternalMetric
+
+// InternalMetricsCheck returns an error describing how the internal metrics don't meet its expectation, if they don't.
+type InternalMetricsCheck func(metrics InternalMetrics) error
+
+// ObsreportCounts are the obsreport counters of a component for a signal, summed across their other labels (e.g. the
+// transport of receivers).  Receivers report Accepted and Refused items, processors Accepted, Refused, and Dropped
+// ones, and exporters Sent, SendFailed, and EnqueueFailed ones.
+type ObsreportCounts struct {
+	Accepted      float64
+	Refused       float64
+	Dropped       float64
+	Sent          float64
+	SendFailed    float64
+	EnqueueFailed float64
+}
+
+// ScrapeInternalMetrics scrapes the internal metrics of the Collector whose service::telemetry::metrics::address
+// is the provided endpoint (e.g. "localhost:8888").
+func ScrapeInternalMetrics(endpoint string) (InternalMetrics, error) {
+	resp, err := http.Get(fmt.Sprintf("http://%s%s", endpoint, internalMetricsPath))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected internal metrics response status: %s", resp.Status)
+	}
+	return ParseInternalMetrics(resp.Body)
+}
+
+// ParseInternalMetrics parses the samples of the Prometheus text exposition format.
+func ParseInternalMetrics(reader io.Reader) (InternalMetrics, error) {
+	var metrics InternalMetrics
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		metric, err := parseInternalMetric(line)
+		if err != nil {
+			return nil, fmt.Errorf("invalid internal metrics sample %q: %w", line, err)
+		}
+		metrics = append(metrics, metric)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return metrics, nil
+}
+
+// parseInternalMetric parses a `name{label="value",...} value [timestamp]` sample.
+func parseInternalMetric(line string) (InternalMetric, error) {
+	metric := InternalMetric{Labels: map[string]string{}}
+	nameEnd := strings.IndexAny(line, "{ \t")
+	if nameEnd <= 0 {
+		return metric, fmt.Errorf("missing value")
+	}
+	metric.Name, line = line[:nameEnd], line[nameEnd:]
+
+	if strings.HasPrefix(line, "{") {
+		line = line[1:]
+		for {
+			line = strings.TrimLeft(line, " \t,")
+			if strings.HasPrefix(line, "}") {
+				line = line[1:]
+				break
+			}
+			name, rest, ok := strings.Cut(line, "=")
+			if !ok || !strings.HasPrefix(rest, `"`) {
+				return metric, fmt.Errorf("invalid labels")
+			}
+			value, rest, err := unquoteLabelValue(rest[1:])
+			if err != nil {
+				return metric, err
+			}
+			metric.Labels[strings.TrimSpace(name)] = value
+			line = rest
+		}
+	}
+
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return metric, fmt.Errorf("missing value")
+	}
+	if len(fields) > 2 {
+		return metric, fmt.Errorf("unexpected %q after the value and timestamp", fields[2])
+	}
+	value, err := strconv.ParseFloat(fields[0], 64)
+	if err != nil {
+		return metric, err
+	}
+	metric.Value = value
+	return metric, nil
+}
+
+// unquoteLabelValue returns the escaped label value up to its closing quote, and what follows it.
+func unquoteLabelValue(quoted string) (string, string, error) {
+	var value strings.Builder
+	for i := 0; i < len(quoted); i++ {
+		switch c := quoted[i]; c {
+		case '"':
+			return value.String(), quoted[i+1:], nil
+		case '\\':
+			i++
+			if i == len(quoted) {
+				break
+			}
+			if quoted[i] == 'n' {
+				value.WriteByte('\n')
+			} else {
+				value.WriteByte(quoted[i])
+			}
+		default:
+			value.WriteByte(c)
+		}
+	}
+	return "", "", fmt.Errorf("unterminated label value")
+}
+
+// Sum returns the sum of the values of the metric's samples with the provided labels, regardless of their others.
+func (metrics InternalMetrics) Sum(name string, labels map[string]string) float64 {
+	var sum float64
+	for _, metric := range metrics {
+		if metric.Name == name && hasLabels(metric, labels) {
+			sum += metric.Value
+		}
+	}
+	return sum
+}
+
+func hasLabels(metric InternalMetric, labels map[string]string) bool {
+	for k, v := range labels {
+		if metric.Labels[k] != v {
+			return false
+		}
+	}
+	return true
+}
+
+// Receiver returns the obsreport counters of the receiver (e.g. "otlp" or "smartagent/redis") for the signal.
+func (metrics InternalMetrics) Receiver(receiver, signal string) ObsreportCounts {
+	labels := map[string]string{"receiver": receiver}
+	return ObsreportCounts{
+		Accepted: metrics.Sum("otelcol_receiver_accepted_"+signal, labels),
+		Refused:  metrics.Sum("otelcol_receiver_refused_"+signal, labels),
+	}
+}
+
+// Processor returns the obsreport counters of the processor for the signal.
+func (metrics InternalMetrics) Processor(processor, signal string) ObsreportCounts {
+	labels := map[string]string{"processor": processor}
+	return ObsreportCounts{
+		Accepted: metrics.Sum("otelcol_processor_accepted_"+signal, labels),
+		Refused:  metrics.Sum("otelcol_processor_refused_"+signal, labels),
+		Dropped:  metrics.Sum("otelcol_processor_dropped_"+signal, labels),
+	}
+}
+
+// Exporter returns the obsreport counters of the exporter for the signal.
+func (metrics InternalMetrics) Exporter(exporter, signal string) ObsreportCounts {
+	labels := map[string]string{"exporter": exporter}
+	return ObsreportCounts{
+		Sent:          metrics.Sum("otelcol_exporter_sent_"+signal, labels),
+		SendFailed:    metrics.Sum("otelcol_exporter_send_failed_"+signal, labels),
+		EnqueueFailed: metrics.Sum("otelcol_exporter_enqueue_failed_"+signal, labels),
+	}
+}
+
+// lossCounters are the name prefixes of the obsreport counters of refused, dropped, and failed items, by the label
+// of their component.
+var lossCounters = map[string][]string{
+	"receiver":  {"otelcol_receiver_refused_"},
+	"processor": {"otelcol_processor_refused_", "otelcol_processor_dropped_"},
+	"exporter":  {"otelcol_exporter_send_failed_", "otelcol_exporter_enqueue_failed_"},
+}
+
+// Losses returns the nonzero obsreport counters of refused, dropped, and failed items of every component, sorted, as
+// `name{component="id"} value` (e.g. `otelcol_exporter_send_failed_spans{exporter="sapm"} 12`).
+func (metrics InternalMetrics) Losses() []string {
+	counts := map[string]float64{}
+	for _, metric := range metrics {
+		if metric.Value == 0 {
+			continue
+		}
+		for label, prefixes := range lossCounters {
+			for _, prefix := range prefixes {
+				if strings.HasPrefix(metric.Name, prefix) {
+					counts[fmt.Sprintf("%s{%s=%q}", metric.Name, label, metric.Labels[label])] += metric.Value
+				}
+			}
+		}
+	}
+	losses := make([]string, 0, len(counts))
+	for counter, value := range counts {
+		losses = append(losses, fmt.Sprintf("%s %v", counter, value))
+	}
+	sort.Strings(losses)
+	return losses
+}
+
+// NoLosses checks that no receiver refused, no processor refused or dropped, and no exporter failed to send or
+// enqueue any items.
+func NoLosses(metrics InternalMetrics) error {
+	if losses := metrics.Losses(); len(losses) != 0 {
+		return fmt.Errorf("items were lost: %s", strings.Join(losses, ", "))
+	}
+	return nil
+}
+
+// ReceiverAccepted checks that the receiver accepted at least the minimum number of items of the signal.
+func ReceiverAccepted(receiver, signal string, minimum float64) InternalMetricsCheck {
+	return func(metrics InternalMetrics) error {
+		if accepted := metrics.Receiver(receiver, signal).Accepted; accepted < minimum {
+			return fmt.Errorf("receiver %q accepted %v %s, expected at least %v", receiver, accepted, signal, minimum)
+		}
+		return nil
+	}
+}
+
+// ExporterSent checks that the exporter sent at least the minimum number of items of the signal.
+func ExporterSent(exporter, signal string, minimum float64) InternalMetricsCheck {
+	return func(metrics InternalMetrics) error {
+		if sent := metrics.Exporter(exporter, signal).Sent; sent < minimum {
+			return fmt.Errorf("exporter %q sent %v %s, expected at least %v", exporter, sent, signal, minimum)
+		}
+		return nil
+	}
+}
+
+// AllAcceptedSent checks that the exporter sent as many items of the signal as the receiver accepted, which
+// detects items silently dropped in between (e.g. by a processor without obsreport counters) if the receiver is
+// the only one of the exporter's pipelines.
+func AllAcceptedSent(receiver, exporter, signal string) InternalMetricsCheck {
+	return func(metrics InternalMetrics) error {
+		accepted := metrics.Receiver(receiver, signal).Accepted
+		if sent := metrics.Exporter(exporter, signal).Sent; sent != accepted {
+			return fmt.Errorf("exporter %q sent %v %s but receiver %q accepted %v", exporter, sent, signal, receiver, accepted)
+		}
+		return nil
+	}
+}
+
+// AssertInternalMetrics waits until the internal metrics scraped from the endpoint pass all the checks, returning
+// the last scrape or check error if they don't within the wait time.
+func AssertInternalMetrics(t testing.TB, endpoint string, waitTime time.Duration, checks ...InternalMetricsCheck) error {
+	var lastErr error
+	if !assert.Eventually(t, func() bool {
+		lastErr = checkInternalMetrics(endpoint, checks)
+		return lastErr == nil
+	}, waitTime, 100*time.Millisecond, "Failed to scrape expected internal metrics") {
+		return lastErr
+	}
+	return nil
+}
+
+func checkInternalMetrics(endpoint string, checks []InternalMetricsCheck) error {
+	metrics, err := ScrapeInternalMetrics(endpoint)
+	if err != nil {
+		return err
+	}
+	for _, check := range checks {
+		if err = check(metrics); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// InternalMetricsEndpoint allocates the internal metrics port of the tested Collector, rendered to its config as the
+// ${INTERNAL_METRICS_PORT} environment variable, and returns their endpoint.  It must be called before starting the
+// Collector, whose config should set `service::telemetry::metrics::address: localhost:${INTERNAL_METRICS_PORT}`.
+func (t *Testcase) InternalMetricsEndpoint() string {
+	return fmt.Sprintf("localhost:%d", t.AllocatePort(InternalMetricsPortName))
+}
+
+// AssertInternalMetrics waits until the internal metrics of the tested Collector pass all the checks.
+func (t *Testcase) AssertInternalMetrics(waitTime time.Duration, checks ...InternalMetricsCheck) {
+	require.NoError(t, AssertInternalMetrics(t, t.InternalMetricsEndpoint(), waitTime, checks...))
+}
@@ -0,0 +1,141 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const internalMetrics = `# HELP otelcol_exporter_send_failed_spans Number of spans in failed attempts to send to destination.
+# TYPE otelcol_exporter_send_failed_spans counter
+otelcol_exporter_send_failed_spans{exporter="sapm",service_instance_id="abc",service_version="v0.54.0"} 12
+otelcol_exporter_sent_spans{exporter="sapm",service_instance_id="abc",service_version="v0.54.0"} 28
+otelcol_exporter_send_failed_metric_points{exporter="signalfx",service_instance_id="abc",service_version="v0.54.0"} 0
+otelcol_exporter_sent_metric_points{exporter="signalfx",service_instance_id="abc",service_version="v0.54.0"} 120
+otelcol_processor_accepted_log_records{processor="memory_limiter",service_instance_id="abc",service_version="v0.54.0"} 7
+otelcol_processor_dropped_metric_points{processor="memory_limiter",service_instance_id="abc",service_version="v0.54.0"} 0
+otelcol_processor_refused_log_records{processor="memory_limiter",service_instance_id="abc",service_version="v0.54.0"} 3
+otelcol_receiver_accepted_metric_points{receiver="otlp",service_instance_id="abc",service_version="v0.54.0",transport="grpc"} 100
+otelcol_receiver_accepted_metric_points{receiver="otlp",service_instance_id="abc",service_version="v0.54.0",transport="http"} 20
+otelcol_receiver_refused_metric_points{receiver="otlp",service_instance_id="abc",service_version="v0.54.0",transport="grpc"} 0
+otelcol_receiver_accepted_spans{receiver="jaeger",service_instance_id="abc",service_version="v0.54.0",transport="grpc"} 40
+# TYPE otelcol_process_uptime counter
+otelcol_process_uptime{service_instance_id="abc",service_version="v0.54.0"} 12.5 1657000000000
+`
+
+func TestParseInternalMetrics(t *testing.T) {
+	metrics, err := ParseInternalMetrics(strings.NewReader(internalMetrics +
+		`up 1` + "\n" + `escaped{path="C:\\otel",description="a \"quoted\"\nvalue",} 2.5e+06` + "\n"))
+	require.NoError(t, err)
+	require.Len(t, metrics, 14)
+	assert.Equal(t, InternalMetric{
+		Name:   "otelcol_exporter_send_failed_spans",
+		Labels: map[string]string{"exporter": "sapm", "service_instance_id": "abc", "service_version": "v0.54.0"},
+		Value:  12,
+	}, metrics[0])
+	assert.Equal(t, InternalMetric{
+		Name:   "otelcol_process_uptime",
+		Labels: map[string]string{"service_instance_id": "abc", "service_version": "v0.54.0"},
+		Value:  12.5,
+	}, metrics[11])
+	assert.Equal(t, InternalMetric{Name: "up", Labels: map[string]string{}, Value: 1}, metrics[12])
+	assert.Equal(t, InternalMetric{
+		Name:   "escaped",
+		Labels: map[string]string{"path": `C:\otel`, "description": "a \"quoted\"\nvalue"},
+		Value:  2.5e6,
+	}, metrics[13])
+
+	for _, invalid := range []string{"up", `up{a="b"}`, `up{a="b} 1`, `up{a=b} 1`, "up one", "up 1 2 3"} {
+		metrics, err = ParseInternalMetrics(strings.NewReader(invalid))
+		assert.Error(t, err, invalid)
+		assert.Nil(t, metrics)
+	}
+}
+
+func TestInternalMetricsCounts(t *testing.T) {
+	metrics, err := ParseInternalMetrics(strings.NewReader(internalMetrics))
+	require.NoError(t, err)
+
+	assert.Equal(t, 120.0, metrics.Sum("otelcol_receiver_accepted_metric_points", nil))
+	assert.Equal(t, 20.0, metrics.Sum("otelcol_receiver_accepted_metric_points", map[string]string{"transport": "http"}))
+	assert.Equal(t, ObsreportCounts{Accepted: 120}, metrics.Receiver("otlp", MetricPointsSignal))
+	assert.Equal(t, ObsreportCounts{Accepted: 40}, metrics.Receiver("jaeger", SpansSignal))
+	assert.Equal(t, ObsreportCounts{}, metrics.Receiver("otlp", LogRecordsSignal))
+	assert.Equal(t, ObsreportCounts{Accepted: 7, Refused: 3}, metrics.Processor("memory_limiter", LogRecordsSignal))
+	assert.Equal(t, ObsreportCounts{Sent: 28, SendFailed: 12}, metrics.Exporter("sapm", SpansSignal))
+	assert.Equal(t, ObsreportCounts{Sent: 120}, metrics.Exporter("signalfx", MetricPointsSignal))
+
+	assert.Equal(t, []string{
+		`otelcol_exporter_send_failed_spans{exporter="sapm"} 12`,
+		`otelcol_processor_refused_log_records{processor="memory_limiter"} 3`,
+	}, metrics.Losses())
+	assert.EqualError(t, NoLosses(metrics), `items were lost: otelcol_exporter_send_failed_spans{exporter="sapm"} 12, `+
+		`otelcol_processor_refused_log_records{processor="memory_limiter"} 3`)
+	assert.NoError(t, NoLosses(metrics[3:6]))
+}
+
+func TestInternalMetricsChecks(t *testing.T) {
+	metrics, err := ParseInternalMetrics(strings.NewReader(internalMetrics))
+	require.NoError(t, err)
+
+	assert.NoError(t, ReceiverAccepted("otlp", MetricPointsSignal, 120)(metrics))
+	assert.EqualError(t, ReceiverAccepted("jaeger", SpansSignal, 41)(metrics), `receiver "jaeger" accepted 40 spans, expected at least 41`)
+	assert.NoError(t, ExporterSent("sapm", SpansSignal, 1)(metrics))
+	assert.EqualError(t, ExporterSent("otlp", LogRecordsSignal, 1)(metrics), `exporter "otlp" sent 0 log_records, expected at least 1`)
+	assert.NoError(t, AllAcceptedSent("otlp", "signalfx", MetricPointsSignal)(metrics))
+	assert.EqualError(t, AllAcceptedSent("jaeger", "sapm", SpansSignal)(metrics), `exporter "sapm" sent 28 spans but receiver "jaeger" accepted 40`)
+}
+
+func TestAssertInternalMetrics(t *testing.T) {
+	var scrapes int64
+	server := httptest.NewServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if request.URL.Path != internalMetricsPath {
+			writer.WriteHeader(http.StatusNotFound)
+			return
+		}
+		// the exporter catches up with the receiver on the third scrape
+		sent := "100"
+		if atomic.AddInt64(&scrapes, 1) >= 3 {
+			sent = "120"
+		}
+		_, _ = writer.Write([]byte(strings.Replace(internalMetrics,
+			`otelcol_exporter_sent_metric_points{exporter="signalfx",service_instance_id="abc",service_version="v0.54.0"} 120`,
+			`otelcol_exporter_sent_metric_points{exporter="signalfx",service_instance_id="abc",service_version="v0.54.0"} `+sent, 1)))
+	}))
+	defer server.Close()
+	endpoint := strings.TrimPrefix(server.URL, "http://")
+
+	metrics, err := ScrapeInternalMetrics(endpoint)
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, metrics.Exporter("signalfx", MetricPointsSignal).Sent)
+
+	require.NoError(t, AssertInternalMetrics(t, endpoint, 5*time.Second,
+		ReceiverAccepted("otlp", MetricPointsSignal, 1), AllAcceptedSent("otlp", "signalfx", MetricPointsSignal)))
+	assert.GreaterOrEqual(t, atomic.LoadInt64(&scrapes), int64(3))
+
+	notFound := httptest.NewServer(http.NotFoundHandler())
+	defer notFound.Close()
+	metrics, err = ScrapeInternalMetrics(strings.TrimPrefix(notFound.URL, "http://"))
+	require.EqualError(t, err, "unexpected internal metrics response status: 404 Not Found")
+	assert.Nil(t, metrics)
+}
@@ -0,0 +1,262 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"go.opentelemetry.io/collector/component"
+	"go.opentelemetry.io/collector/component/componenttest"
+	"go.opentelemetry.io/collector/config/configtls"
+	"go.opentelemetry.io/collector/exporter/exporterhelper"
+	"go.opentelemetry.io/collector/exporter/otlpexporter"
+	"go.opentelemetry.io/collector/pdata/pcommon"
+	"go.opentelemetry.io/collector/pdata/pmetric"
+)
+
+const loadMetricName = "soak.load"
+
+// LoadGenerator sends batches of telemetry to a Collector under test.
+type LoadGenerator interface {
+	Start() error
+	// Send delivers a single batch, returning the number of datapoints it contained.
+	Send(ctx context.Context) (int, error)
+	Shutdown() error
+}
+
+var _ LoadGenerator = (*OTLPMetricsLoadGenerator)(nil)
+var _ LoadGenerator = (*SFxDatapointLoadGenerator)(nil)
+
+// To be used as a builder whose Build() method provides the actual instance capable of sending
+// gauge batches to an OTLP gRPC receiver.
+type OTLPMetricsLoadGenerator struct {
+	exporter           *component.MetricsExporter
+	Endpoint           string
+	DatapointsPerBatch int
+	Host               component.Host
+}
+
+func NewOTLPMetricsLoadGenerator() OTLPMetricsLoadGenerator {
+	return OTLPMetricsLoadGenerator{}
+}
+
+// Required
+func (otlp OTLPMetricsLoadGenerator) WithEndpoint(endpoint string) OTLPMetricsLoadGenerator {
+	otlp.Endpoint = endpoint
+	return otlp
+}
+
+// 100 by default
+func (otlp OTLPMetricsLoadGenerator) WithDatapointsPerBatch(count int) OTLPMetricsLoadGenerator {
+	otlp.DatapointsPerBatch = count
+	return otlp
+}
+
+// If not set will use NopHost
+func (otlp OTLPMetricsLoadGenerator) WithHost(host component.Host) OTLPMetricsLoadGenerator {
+	otlp.Host = host
+	return otlp
+}
+
+// Will create an insecure OTLP exporter without queueing or retries, so every failed batch is reported by Send().
+func (otlp OTLPMetricsLoadGenerator) Build() (*OTLPMetricsLoadGenerator, error) {
+	if otlp.Endpoint == "" {
+		return nil, fmt.Errorf("must provide an Endpoint for OTLPMetricsLoadGenerator")
+	}
+	if otlp.DatapointsPerBatch < 0 {
+		return nil, fmt.Errorf("DatapointsPerBatch must be positive: %d", otlp.DatapointsPerBatch)
+	}
+	if otlp.DatapointsPerBatch == 0 {
+		otlp.DatapointsPerBatch = 100
+	}
+	if otlp.Host == nil {
+		otlp.Host = componenttest.NewNopHost()
+	}
+
+	factory := otlpexporter.NewFactory()
+	cfg := factory.CreateDefaultConfig().(*otlpexporter.Config)
+	cfg.GRPCClientSettings.Endpoint = otlp.Endpoint
+	cfg.GRPCClientSettings.TLSSetting = configtls.TLSClientSetting{Insecure: true}
+	cfg.QueueSettings = exporterhelper.QueueSettings{Enabled: false}
+	cfg.RetrySettings = exporterhelper.RetrySettings{Enabled: false}
+
+	exporter, err := factory.CreateMetricsExporter(context.Background(), componenttest.NewNopExporterCreateSettings(), cfg)
+	if err != nil {
+		return nil, err
+	}
+	otlp.exporter = &exporter
+	return &otlp, nil
+}
+
+func (otlp *OTLPMetricsLoadGenerator) assertBuilt(operation string) error {
+	if otlp.exporter == nil {
+		return fmt.Errorf("cannot invoke %s() on an OTLPMetricsLoadGenerator that hasn't been built", operation)
+	}
+	return nil
+}
+
+func (otlp *OTLPMetricsLoadGenerator) Start() error {
+	if err := otlp.assertBuilt("Start"); err != nil {
+		return err
+	}
+	return (*otlp.exporter).Start(context.Background(), otlp.Host)
+}
+
+func (otlp *OTLPMetricsLoadGenerator) Send(ctx context.Context) (int, error) {
+	if err := otlp.assertBuilt("Send"); err != nil {
+		return 0, err
+	}
+	return otlp.DatapointsPerBatch, (*otlp.exporter).ConsumeMetrics(ctx, newLoadMetrics(otlp.DatapointsPerBatch))
+}
+
+func (otlp *OTLPMetricsLoadGenerator) Shutdown() error {
+	if err := otlp.assertBuilt("Shutdown"); err != nil {
+		return err
+	}
+	return (*otlp.exporter).Shutdown(context.Background())
+}
+
+// newLoadMetrics returns a single gauge with count datapoints distinguished by their "index" attribute.
+func newLoadMetrics(count int) pmetric.Metrics {
+	md := pmetric.NewMetrics()
+	rm := md.ResourceMetrics().AppendEmpty()
+	rm.Resource().Attributes().InsertString("service.name", "soak-test")
+	metric := rm.ScopeMetrics().AppendEmpty().Metrics().AppendEmpty()
+	metric.SetName(loadMetricName)
+	metric.SetDataType(pmetric.MetricDataTypeGauge)
+	dps := metric.Gauge().DataPoints()
+	dps.EnsureCapacity(count)
+	now := pcommon.NewTimestampFromTime(time.Now())
+	for i := 0; i < count; i++ {
+		dp := dps.AppendEmpty()
+		dp.SetTimestamp(now)
+		dp.SetIntVal(int64(i))
+		dp.Attributes().InsertString("index", strconv.Itoa(i))
+	}
+	return md
+}
+
+// To be used as a builder whose Build() method provides the actual instance capable of sending
+// protobuf datapoint batches to a SignalFx receiver's /v2/datapoint endpoint.
+type SFxDatapointLoadGenerator struct {
+	client             *http.Client
+	Endpoint           string
+	DatapointsPerBatch int
+	Timeout            time.Duration
+}
+
+func NewSFxDatapointLoadGenerator() SFxDatapointLoadGenerator {
+	return SFxDatapointLoadGenerator{}
+}
+
+// Required, the receiver's host:port
+func (sfx SFxDatapointLoadGenerator) WithEndpoint(endpoint string) SFxDatapointLoadGenerator {
+	sfx.Endpoint = endpoint
+	return sfx
+}
+
+// 100 by default
+func (sfx SFxDatapointLoadGenerator) WithDatapointsPerBatch(count int) SFxDatapointLoadGenerator {
+	sfx.DatapointsPerBatch = count
+	return sfx
+}
+
+// 5s by default
+func (sfx SFxDatapointLoadGenerator) WithTimeout(timeout time.Duration) SFxDatapointLoadGenerator {
+	sfx.Timeout = timeout
+	return sfx
+}
+
+func (sfx SFxDatapointLoadGenerator) Build() (*SFxDatapointLoadGenerator, error) {
+	if sfx.Endpoint == "" {
+		return nil, fmt.Errorf("must provide an Endpoint for SFxDatapointLoadGenerator")
+	}
+	if sfx.DatapointsPerBatch < 0 {
+		return nil, fmt.Errorf("DatapointsPerBatch must be positive: %d", sfx.DatapointsPerBatch)
+	}
+	if sfx.DatapointsPerBatch == 0 {
+		sfx.DatapointsPerBatch = 100
+	}
+	if sfx.Timeout == 0 {
+		sfx.Timeout = 5 * time.Second
+	}
+	sfx.client = &http.Client{Timeout: sfx.Timeout}
+	return &sfx, nil
+}
+
+func (sfx *SFxDatapointLoadGenerator) Start() error {
+	if sfx.client == nil {
+		return fmt.Errorf("cannot invoke Start() on an SFxDatapointLoadGenerator that hasn't been built")
+	}
+	return nil
+}
+
+func (sfx *SFxDatapointLoadGenerator) Send(ctx context.Context) (int, error) {
+	if sfx.client == nil {
+		return 0, fmt.Errorf("cannot invoke Send() on an SFxDatapointLoadGenerator that hasn't been built")
+	}
+	body, err := newLoadDatapoints(sfx.DatapointsPerBatch).Marshal()
+	if err != nil {
+		return 0, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fmt.Sprintf("http://%s/v2/datapoint", sfx.Endpoint), bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-protobuf")
+
+	resp, err := sfx.client.Do(req)
+	if err != nil {
+		return sfx.DatapointsPerBatch, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode != http.StatusOK {
+		return sfx.DatapointsPerBatch, fmt.Errorf("unexpected datapoint response status: %s", resp.Status)
+	}
+	return sfx.DatapointsPerBatch, nil
+}
+
+func (sfx *SFxDatapointLoadGenerator) Shutdown() error {
+	if sfx.client != nil {
+		sfx.client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// newLoadDatapoints returns count gauge datapoints distinguished by their "index" dimension.
+func newLoadDatapoints(count int) *sfxpb.DataPointUploadMessage {
+	msg := &sfxpb.DataPointUploadMessage{Datapoints: make([]*sfxpb.DataPoint, 0, count)}
+	now := time.Now().UnixNano() / int64(time.Millisecond)
+	metricType := sfxpb.MetricType_GAUGE
+	for i := 0; i < count; i++ {
+		value := int64(i)
+		msg.Datapoints = append(msg.Datapoints, &sfxpb.DataPoint{
+			Metric:     loadMetricName,
+			Timestamp:  now,
+			Value:      sfxpb.Datum{IntValue: &value},
+			MetricType: &metricType,
+			Dimensions: []*sfxpb.Dimension{{Key: "index", Value: strconv.Itoa(i)}},
+		})
+	}
+	return msg
+}
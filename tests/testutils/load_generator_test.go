@@ -0,0 +1,147 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOTLPMetricsLoadGeneratorBuild(t *testing.T) {
+	otlp, err := NewOTLPMetricsLoadGenerator().Build()
+	assert.EqualError(t, err, "must provide an Endpoint for OTLPMetricsLoadGenerator")
+	assert.Nil(t, otlp)
+
+	otlp, err = NewOTLPMetricsLoadGenerator().WithEndpoint("localhost:4317").WithDatapointsPerBatch(-1).Build()
+	assert.EqualError(t, err, "DatapointsPerBatch must be positive: -1")
+	assert.Nil(t, otlp)
+
+	otlp, err = NewOTLPMetricsLoadGenerator().WithEndpoint("localhost:4317").Build()
+	require.NoError(t, err)
+	assert.Equal(t, 100, otlp.DatapointsPerBatch)
+	assert.NotNil(t, otlp.Host)
+	assert.NotNil(t, otlp.exporter)
+}
+
+func TestOTLPMetricsLoadGeneratorMethodsWithoutBuildingDisallowed(t *testing.T) {
+	otlp := NewOTLPMetricsLoadGenerator()
+
+	assert.EqualError(t, otlp.Start(), "cannot invoke Start() on an OTLPMetricsLoadGenerator that hasn't been built")
+	_, err := otlp.Send(context.Background())
+	assert.EqualError(t, err, "cannot invoke Send() on an OTLPMetricsLoadGenerator that hasn't been built")
+	assert.EqualError(t, otlp.Shutdown(), "cannot invoke Shutdown() on an OTLPMetricsLoadGenerator that hasn't been built")
+}
+
+func TestOTLPMetricsLoadGeneratorSend(t *testing.T) {
+	endpoint := fmt.Sprintf("localhost:%d", GetAvailablePort(t))
+	sink, err := NewOTLPMetricsReceiverSink().WithEndpoint(endpoint).Build()
+	require.NoError(t, err)
+	require.NoError(t, sink.Start())
+	defer func() { require.NoError(t, sink.Shutdown()) }()
+
+	otlp, err := NewOTLPMetricsLoadGenerator().WithEndpoint(endpoint).WithDatapointsPerBatch(25).Build()
+	require.NoError(t, err)
+	require.NoError(t, otlp.Start())
+	defer func() { require.NoError(t, otlp.Shutdown()) }()
+
+	sent, err := otlp.Send(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 25, sent)
+	require.Eventually(t, func() bool { return sink.DataPointCount() == 25 }, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestNewLoadMetrics(t *testing.T) {
+	md := newLoadMetrics(3)
+	require.Equal(t, 3, md.DataPointCount())
+	metric := md.ResourceMetrics().At(0).ScopeMetrics().At(0).Metrics().At(0)
+	assert.Equal(t, "soak.load", metric.Name())
+	index, ok := metric.Gauge().DataPoints().At(2).Attributes().Get("index")
+	require.True(t, ok)
+	assert.Equal(t, "2", index.StringVal())
+}
+
+func TestSFxDatapointLoadGeneratorBuild(t *testing.T) {
+	sfx, err := NewSFxDatapointLoadGenerator().Build()
+	assert.EqualError(t, err, "must provide an Endpoint for SFxDatapointLoadGenerator")
+	assert.Nil(t, sfx)
+
+	sfx, err = NewSFxDatapointLoadGenerator().WithEndpoint("localhost:9943").WithDatapointsPerBatch(-1).Build()
+	assert.EqualError(t, err, "DatapointsPerBatch must be positive: -1")
+	assert.Nil(t, sfx)
+
+	sfx, err = NewSFxDatapointLoadGenerator().WithEndpoint("localhost:9943").Build()
+	require.NoError(t, err)
+	assert.Equal(t, 100, sfx.DatapointsPerBatch)
+	assert.Equal(t, 5*time.Second, sfx.Timeout)
+	assert.NotNil(t, sfx.client)
+
+	unbuilt := NewSFxDatapointLoadGenerator()
+	assert.EqualError(t, unbuilt.Start(), "cannot invoke Start() on an SFxDatapointLoadGenerator that hasn't been built")
+}
+
+func TestSFxDatapointLoadGeneratorSend(t *testing.T) {
+	received := make(chan *sfxpb.DataPointUploadMessage, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v2/datapoint", r.URL.Path)
+		assert.Equal(t, "application/x-protobuf", r.Header.Get("Content-Type"))
+		body, err := io.ReadAll(r.Body)
+		require.NoError(t, err)
+		msg := &sfxpb.DataPointUploadMessage{}
+		require.NoError(t, msg.Unmarshal(body))
+		received <- msg
+	}))
+	defer server.Close()
+
+	sfx, err := NewSFxDatapointLoadGenerator().WithEndpoint(strings.TrimPrefix(server.URL, "http://")).
+		WithDatapointsPerBatch(5).Build()
+	require.NoError(t, err)
+	require.NoError(t, sfx.Start())
+	defer func() { require.NoError(t, sfx.Shutdown()) }()
+
+	sent, err := sfx.Send(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 5, sent)
+
+	msg := <-received
+	require.Len(t, msg.Datapoints, 5)
+	assert.Equal(t, "soak.load", msg.Datapoints[4].Metric)
+	assert.Equal(t, int64(4), *msg.Datapoints[4].Value.IntValue)
+	assert.Equal(t, "4", msg.Datapoints[4].Dimensions[0].Value)
+}
+
+func TestSFxDatapointLoadGeneratorSendRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	sfx, err := NewSFxDatapointLoadGenerator().WithEndpoint(strings.TrimPrefix(server.URL, "http://")).
+		WithDatapointsPerBatch(5).Build()
+	require.NoError(t, err)
+
+	sent, err := sfx.Send(context.Background())
+	assert.EqualError(t, err, "unexpected datapoint response status: 503 Service Unavailable")
+	assert.Equal(t, 5, sent)
+}
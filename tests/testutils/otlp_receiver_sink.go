@@ -172,3 +172,24 @@ func (otlp *OTLPMetricsReceiverSink) AssertAllMetricsReceived(t *testing.T, expe
 
 	return err
 }
+
+// AssertResourceAttributeSchema waits for metrics to be received and confirms that the Resources of
+// all received metrics conform to the provided ResourceAttributeSchema.
+func (otlp *OTLPMetricsReceiverSink) AssertResourceAttributeSchema(t *testing.T, schema ResourceAttributeSchema, waitTime time.Duration) error {
+	if err := otlp.assertBuilt("AssertResourceAttributeSchema"); err != nil {
+		return err
+	}
+
+	if !assert.Eventually(t, func() bool {
+		return otlp.DataPointCount() > 0
+	}, waitTime, 10*time.Millisecond, "Failed to receive any metrics") {
+		return fmt.Errorf("no metrics received")
+	}
+
+	receivedResourceMetrics, err := PDataToResourceMetrics(otlp.AllMetrics()...)
+	require.NoError(t, err)
+	require.NotNil(t, receivedResourceMetrics)
+
+	_, err = FlattenResourceMetrics(receivedResourceMetrics).ConformsToSchema(schema)
+	return err
+}
@@ -0,0 +1,42 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"os"
+	"runtime"
+	"strings"
+)
+
+const dockerDefaultPlatformEnvVar = "DOCKER_DEFAULT_PLATFORM"
+
+// Platform returns the architecture (e.g. "amd64" or "arm64") of the images that will be run for tests.
+// It's determined by the standard DOCKER_DEFAULT_PLATFORM environment variable (e.g. "linux/arm64")
+// if set, otherwise by the architecture of the test binary.
+func Platform() string {
+	return platformFrom(os.Getenv(dockerDefaultPlatformEnvVar))
+}
+
+func platformFrom(dockerDefaultPlatform string) string {
+	if dockerDefaultPlatform = strings.TrimSpace(dockerDefaultPlatform); dockerDefaultPlatform != "" {
+		// <os>/<arch>[/<variant>]
+		parts := strings.Split(dockerDefaultPlatform, "/")
+		if len(parts) > 1 {
+			return parts[1]
+		}
+		return parts[0]
+	}
+	return runtime.GOARCH
+}
@@ -0,0 +1,38 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPlatformFrom(t *testing.T) {
+	assert.Equal(t, runtime.GOARCH, platformFrom(""))
+	assert.Equal(t, runtime.GOARCH, platformFrom("  "))
+	assert.Equal(t, "arm64", platformFrom("linux/arm64"))
+	assert.Equal(t, "arm", platformFrom("linux/arm/v7"))
+	assert.Equal(t, "amd64", platformFrom("amd64"))
+}
+
+func TestPlatform(t *testing.T) {
+	t.Setenv(dockerDefaultPlatformEnvVar, "linux/arm64")
+	assert.Equal(t, "arm64", Platform())
+
+	t.Setenv(dockerDefaultPlatformEnvVar, "")
+	assert.Equal(t, runtime.GOARCH, Platform())
+}
@@ -0,0 +1,139 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ResourceAttributeSchema declares the resource attributes that all received telemetry is expected to have.
+// It's intended to be defined in yaml files and used to confirm that attributes like host.name, os.type,
+// and k8s.* are consistently stamped by a tested config, regardless of their specific values.
+type ResourceAttributeSchema struct {
+	Attributes []AttributeRule `yaml:"resource_attributes"`
+}
+
+// AttributeRule is the expectation for a single resource attribute.  Required attributes must be present
+// in every Resource.  If a Pattern is provided, the attribute's value (in string form) must fully match it
+// when present.
+type AttributeRule struct {
+	Key      string `yaml:"key"`
+	Pattern  string `yaml:"pattern,omitempty"`
+	Required bool   `yaml:"required,omitempty"`
+	pattern  *regexp.Regexp
+}
+
+// Returns a ResourceAttributeSchema instance generated via parsing a valid yaml file at the provided path.
+func LoadResourceAttributeSchema(path string) (*ResourceAttributeSchema, error) {
+	schemaFile, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer schemaFile.Close()
+
+	buffer := new(bytes.Buffer)
+	if _, err = buffer.ReadFrom(schemaFile); err != nil {
+		return nil, err
+	}
+
+	var loaded ResourceAttributeSchema
+	if err = yaml.UnmarshalStrict(buffer.Bytes(), &loaded); err != nil {
+		return nil, err
+	}
+	if err = loaded.Validate(); err != nil {
+		return nil, err
+	}
+	return &loaded, nil
+}
+
+// Determines if all rules in the ResourceAttributeSchema are valid, compiling their value patterns.
+func (schema *ResourceAttributeSchema) Validate() error {
+	keys := map[string]bool{}
+	for i, rule := range schema.Attributes {
+		if rule.Key == "" {
+			return fmt.Errorf("resource attribute rule %d must specify a key", i)
+		}
+		if keys[rule.Key] {
+			return fmt.Errorf("duplicate resource attribute rule for %q", rule.Key)
+		}
+		keys[rule.Key] = true
+		if rule.Pattern == "" {
+			continue
+		}
+		pattern, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", rule.Pattern))
+		if err != nil {
+			return fmt.Errorf("invalid pattern for resource attribute %q: %w", rule.Key, err)
+		}
+		schema.Attributes[i].pattern = pattern
+	}
+	return nil
+}
+
+// ValidateResource determines if the provided Resource satisfies all rules of the ResourceAttributeSchema,
+// returning an error describing every violation.
+func (schema ResourceAttributeSchema) ValidateResource(resource Resource) error {
+	var violations []string
+	for _, rule := range schema.Attributes {
+		value, ok := resource.Attributes[rule.Key]
+		if !ok {
+			if rule.Required {
+				violations = append(violations, fmt.Sprintf("missing required attribute %q", rule.Key))
+			}
+			continue
+		}
+		if rule.Pattern == "" {
+			continue
+		}
+		pattern := rule.pattern
+		if pattern == nil {
+			// the schema was constructed directly instead of loaded
+			var err error
+			if pattern, err = regexp.Compile(fmt.Sprintf("^(?:%s)$", rule.Pattern)); err != nil {
+				violations = append(violations, fmt.Sprintf("invalid pattern for %q: %v", rule.Key, err))
+				continue
+			}
+		}
+		if stringValue := fmt.Sprintf("%v", value); !pattern.MatchString(stringValue) {
+			violations = append(violations, fmt.Sprintf(
+				"attribute %q value %q doesn't match pattern %q", rule.Key, stringValue, rule.Pattern,
+			))
+		}
+	}
+	if len(violations) != 0 {
+		return fmt.Errorf("%v doesn't conform to resource attribute schema: %s", resource.Attributes, strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+// ConformsToSchema determines that every Resource in the receiver ResourceMetrics satisfies the provided
+// ResourceAttributeSchema.  Empty ResourceMetrics trivially conform.
+func (received ResourceMetrics) ConformsToSchema(schema ResourceAttributeSchema) (bool, error) {
+	var errs []string
+	for _, rm := range received.ResourceMetrics {
+		if err := schema.ValidateResource(rm.Resource); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if len(errs) != 0 {
+		return false, fmt.Errorf("%d of %d resources are invalid: %s", len(errs), len(received.ResourceMetrics), strings.Join(errs, "\n"))
+	}
+	return true, nil
+}
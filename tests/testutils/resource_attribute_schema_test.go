@@ -0,0 +1,125 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"path"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func loadedResourceAttributeSchema(t *testing.T) ResourceAttributeSchema {
+	schema, err := LoadResourceAttributeSchema(path.Join(".", "testdata", "resourceAttributeSchema.yaml"))
+	require.NoError(t, err)
+	require.NotNil(t, schema)
+	return *schema
+}
+
+func TestLoadResourceAttributeSchemaHappyPath(t *testing.T) {
+	schema := loadedResourceAttributeSchema(t)
+	require.Equal(t, 3, len(schema.Attributes))
+
+	assert.Equal(t, "host.name", schema.Attributes[0].Key)
+	assert.True(t, schema.Attributes[0].Required)
+	assert.Empty(t, schema.Attributes[0].Pattern)
+
+	assert.Equal(t, "os.type", schema.Attributes[1].Key)
+	assert.True(t, schema.Attributes[1].Required)
+	assert.Equal(t, "linux|windows", schema.Attributes[1].Pattern)
+
+	assert.Equal(t, "k8s.pod.uid", schema.Attributes[2].Key)
+	assert.False(t, schema.Attributes[2].Required)
+	assert.Equal(t, "[0-9a-f-]+", schema.Attributes[2].Pattern)
+}
+
+func TestLoadResourceAttributeSchemaNotAValidPath(t *testing.T) {
+	schema, err := LoadResourceAttributeSchema("notafile")
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "no such file or directory")
+	require.Nil(t, schema)
+}
+
+func TestLoadResourceAttributeSchemaInvalidPattern(t *testing.T) {
+	schema, err := LoadResourceAttributeSchema(path.Join(".", "testdata", "invalidResourceAttributeSchema.yaml"))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `invalid pattern for resource attribute "host.name"`)
+	require.Nil(t, schema)
+}
+
+func TestResourceAttributeSchemaValidateRules(t *testing.T) {
+	schema := ResourceAttributeSchema{Attributes: []AttributeRule{{Key: ""}}}
+	require.EqualError(t, schema.Validate(), "resource attribute rule 0 must specify a key")
+
+	schema = ResourceAttributeSchema{Attributes: []AttributeRule{{Key: "one"}, {Key: "one"}}}
+	require.EqualError(t, schema.Validate(), `duplicate resource attribute rule for "one"`)
+}
+
+func TestValidateResource(t *testing.T) {
+	schema := loadedResourceAttributeSchema(t)
+
+	valid := Resource{Attributes: map[string]any{
+		"host.name": "a.host", "os.type": "linux", "k8s.pod.uid": "0123-abcd", "another": "attr",
+	}}
+	require.NoError(t, schema.ValidateResource(valid))
+
+	withoutOptional := Resource{Attributes: map[string]any{"host.name": "a.host", "os.type": "windows"}}
+	require.NoError(t, schema.ValidateResource(withoutOptional))
+
+	missingRequired := Resource{Attributes: map[string]any{"os.type": "linux"}}
+	err := schema.ValidateResource(missingRequired)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `missing required attribute "host.name"`)
+
+	invalidValues := Resource{Attributes: map[string]any{
+		"host.name": "a.host", "os.type": "darwin", "k8s.pod.uid": "not-a-uid",
+	}}
+	err = schema.ValidateResource(invalidValues)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), `attribute "os.type" value "darwin" doesn't match pattern "linux|windows"`)
+	require.Contains(t, err.Error(), `attribute "k8s.pod.uid" value "not-a-uid" doesn't match pattern "[0-9a-f-]+"`)
+}
+
+func TestValidateResourceUncompiledPattern(t *testing.T) {
+	schema := ResourceAttributeSchema{Attributes: []AttributeRule{{Key: "an.int", Pattern: `\d+`}}}
+	require.NoError(t, schema.ValidateResource(Resource{Attributes: map[string]any{"an.int": 123}}))
+	require.Error(t, schema.ValidateResource(Resource{Attributes: map[string]any{"an.int": "one"}}))
+}
+
+func TestResourceMetricsConformsToSchema(t *testing.T) {
+	schema := loadedResourceAttributeSchema(t)
+
+	received := ResourceMetrics{ResourceMetrics: []ResourceMetric{
+		{Resource: Resource{Attributes: map[string]any{"host.name": "a.host", "os.type": "linux"}}},
+		{Resource: Resource{Attributes: map[string]any{"host.name": "another.host", "os.type": "windows"}}},
+	}}
+	conforms, err := received.ConformsToSchema(schema)
+	require.True(t, conforms)
+	require.NoError(t, err)
+
+	received.ResourceMetrics = append(received.ResourceMetrics, ResourceMetric{
+		Resource: Resource{Attributes: map[string]any{"os.type": "linux"}},
+	})
+	conforms, err = received.ConformsToSchema(schema)
+	require.False(t, conforms)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "1 of 3 resources are invalid")
+	require.Contains(t, err.Error(), `missing required attribute "host.name"`)
+
+	conforms, err = ResourceMetrics{}.ConformsToSchema(schema)
+	require.True(t, conforms)
+	require.NoError(t, err)
+}
@@ -0,0 +1,426 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+const (
+	sfxDatapointPath = "/v2/datapoint"
+	sfxEventPath     = "/v2/event"
+	sfxDimensionPath = "/v2/dimension/"
+)
+
+// SFxDimensionUpdate is a recorded SignalFx API dimension PUT or PATCH request.  Nil CustomProperties
+// values of PATCH requests remove the property.
+type SFxDimensionUpdate struct {
+	CustomProperties map[string]*string `json:"customProperties"`
+	Method           string             `json:"-"`
+	Token            string             `json:"-"`
+	Key              string             `json:"key"`
+	Value            string             `json:"value"`
+	Tags             []string           `json:"tags"`
+	TagsToRemove     []string           `json:"tagsToRemove"`
+}
+
+// SFxDimension is the state of a dimension after all recorded updates have been applied to it.
+type SFxDimension struct {
+	CustomProperties map[string]string `json:"customProperties"`
+	Key              string            `json:"key"`
+	Value            string            `json:"value"`
+	Tags             []string          `json:"tags"`
+}
+
+// To be used as a builder whose Build() method provides the actual instance capable of serving fake SignalFx
+// ingest (datapoint and event) and API (dimension) endpoints that record all calls for test assertions.
+// A running Collector's signalfx exporter can use its address as both its ingest_url and api_url.
+type SFxBackend struct {
+	server           *http.Server
+	listener         net.Listener
+	dimensions       map[string]*SFxDimension
+	Logger           *zap.Logger
+	Endpoint         string
+	datapoints       []*sfxpb.DataPoint
+	events           []*sfxpb.Event
+	dimensionUpdates []SFxDimensionUpdate
+	tokens           []string
+	lock             *sync.RWMutex
+}
+
+func NewSFxBackend() SFxBackend {
+	return SFxBackend{}
+}
+
+// Required
+func (sfx SFxBackend) WithEndpoint(endpoint string) SFxBackend {
+	sfx.Endpoint = endpoint
+	return sfx
+}
+
+// Nop logger by default
+func (sfx SFxBackend) WithLogger(logger *zap.Logger) SFxBackend {
+	sfx.Logger = logger
+	return sfx
+}
+
+func (sfx SFxBackend) Build() (*SFxBackend, error) {
+	if sfx.Endpoint == "" {
+		return nil, fmt.Errorf("must provide an Endpoint for SFxBackend")
+	}
+	if sfx.Logger == nil {
+		sfx.Logger = zap.NewNop()
+	}
+
+	backend := &SFxBackend{
+		Endpoint:   sfx.Endpoint,
+		Logger:     sfx.Logger,
+		dimensions: map[string]*SFxDimension{},
+		lock:       &sync.RWMutex{},
+	}
+	mux := http.NewServeMux()
+	mux.HandleFunc(sfxDatapointPath, backend.handleDatapoints)
+	mux.HandleFunc(sfxEventPath, backend.handleEvents)
+	mux.HandleFunc(sfxDimensionPath, backend.handleDimension)
+	backend.server = &http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	return backend, nil
+}
+
+func (sfx *SFxBackend) assertBuilt(operation string) error {
+	if sfx.server == nil {
+		return fmt.Errorf("cannot invoke %s() on an SFxBackend that hasn't been built", operation)
+	}
+	return nil
+}
+
+func (sfx *SFxBackend) Start() error {
+	if err := sfx.assertBuilt("Start"); err != nil {
+		return err
+	}
+	listener, err := net.Listen("tcp", sfx.Endpoint)
+	if err != nil {
+		return err
+	}
+	sfx.listener = listener
+	go func() {
+		if serveErr := sfx.server.Serve(listener); serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			sfx.Logger.Error("SFxBackend server failed", zap.Error(serveErr))
+		}
+	}()
+	return nil
+}
+
+func (sfx *SFxBackend) Shutdown() error {
+	if err := sfx.assertBuilt("Shutdown"); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	return sfx.server.Shutdown(ctx)
+}
+
+// URL is the base url of the backend, to be used for signalfx exporter ingest_url and api_url.
+func (sfx *SFxBackend) URL() string {
+	return fmt.Sprintf("http://%s", sfx.Endpoint)
+}
+
+// Datapoints returns all received datapoints.
+func (sfx *SFxBackend) Datapoints() []*sfxpb.DataPoint {
+	sfx.lock.RLock()
+	defer sfx.lock.RUnlock()
+	return append([]*sfxpb.DataPoint{}, sfx.datapoints...)
+}
+
+// Events returns all received events.
+func (sfx *SFxBackend) Events() []*sfxpb.Event {
+	sfx.lock.RLock()
+	defer sfx.lock.RUnlock()
+	return append([]*sfxpb.Event{}, sfx.events...)
+}
+
+// DimensionUpdates returns all received dimension PUT and PATCH requests in order.
+func (sfx *SFxBackend) DimensionUpdates() []SFxDimensionUpdate {
+	sfx.lock.RLock()
+	defer sfx.lock.RUnlock()
+	return append([]SFxDimensionUpdate{}, sfx.dimensionUpdates...)
+}
+
+// Tokens returns the X-SF-Token header values of all received requests in order.
+func (sfx *SFxBackend) Tokens() []string {
+	sfx.lock.RLock()
+	defer sfx.lock.RUnlock()
+	return append([]string{}, sfx.tokens...)
+}
+
+// Dimension returns the current state of the dimension, if it's been updated.
+func (sfx *SFxBackend) Dimension(key, value string) (SFxDimension, bool) {
+	sfx.lock.RLock()
+	defer sfx.lock.RUnlock()
+	dimension, ok := sfx.dimensions[dimensionID(key, value)]
+	if !ok {
+		return SFxDimension{}, false
+	}
+	return dimension.copy(), true
+}
+
+// Reset clears all recorded calls and dimension state.
+func (sfx *SFxBackend) Reset() {
+	sfx.lock.Lock()
+	defer sfx.lock.Unlock()
+	sfx.datapoints = nil
+	sfx.events = nil
+	sfx.dimensionUpdates = nil
+	sfx.tokens = nil
+	sfx.dimensions = map[string]*SFxDimension{}
+}
+
+// AssertDimensionProperties waits until the dimension's custom properties equal the expected ones.
+func (sfx *SFxBackend) AssertDimensionProperties(t testing.TB, key, value string, expected map[string]string, waitTime time.Duration) error {
+	if err := sfx.assertBuilt("AssertDimensionProperties"); err != nil {
+		return err
+	}
+	var actual map[string]string
+	if !assert.Eventually(t, func() bool {
+		dimension, _ := sfx.Dimension(key, value)
+		actual = dimension.CustomProperties
+		return assert.ObjectsAreEqual(expected, actual)
+	}, waitTime, 10*time.Millisecond, "Failed to receive expected dimension properties") {
+		return fmt.Errorf("dimension %s=%s properties %v don't match expected %v", key, value, actual, expected)
+	}
+	return nil
+}
+
+// AssertEventTypesReceived waits until events of all the expected types have been received.
+func (sfx *SFxBackend) AssertEventTypesReceived(t testing.TB, expected []string, waitTime time.Duration) error {
+	if err := sfx.assertBuilt("AssertEventTypesReceived"); err != nil {
+		return err
+	}
+	var missing []string
+	if !assert.Eventually(t, func() bool {
+		received := map[string]bool{}
+		for _, event := range sfx.Events() {
+			received[event.EventType] = true
+		}
+		missing = nil
+		for _, eventType := range expected {
+			if !received[eventType] {
+				missing = append(missing, eventType)
+			}
+		}
+		return len(missing) == 0
+	}, waitTime, 10*time.Millisecond, "Failed to receive expected events") {
+		return fmt.Errorf("no events of types %v received", missing)
+	}
+	return nil
+}
+
+func (sfx *SFxBackend) handleDatapoints(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	msg := &sfxpb.DataPointUploadMessage{}
+	if err := readProtobuf(r, msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sfx.lock.Lock()
+	sfx.tokens = append(sfx.tokens, r.Header.Get("X-SF-Token"))
+	sfx.datapoints = append(sfx.datapoints, msg.Datapoints...)
+	sfx.lock.Unlock()
+	writeOK(w)
+}
+
+func (sfx *SFxBackend) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	msg := &sfxpb.EventUploadMessage{}
+	if err := readProtobuf(r, msg); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	sfx.lock.Lock()
+	sfx.tokens = append(sfx.tokens, r.Header.Get("X-SF-Token"))
+	sfx.events = append(sfx.events, msg.Events...)
+	sfx.lock.Unlock()
+	writeOK(w)
+}
+
+// handleDimension serves PUT /v2/dimension/{key}/{value}, which replaces the dimension's properties and tags,
+// PATCH /v2/dimension/{key}/{value}[/_/sfxagent], which updates them, and GET for their current state.
+func (sfx *SFxBackend) handleDimension(w http.ResponseWriter, r *http.Request) {
+	key, value, err := parseDimensionPath(r.URL.EscapedPath(), r.Method == http.MethodPatch)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if r.Method == http.MethodGet {
+		dimension, ok := sfx.Dimension(key, value)
+		if !ok {
+			http.Error(w, "dimension not found", http.StatusNotFound)
+			return
+		}
+		writeJSON(w, dimension)
+		return
+	}
+	if r.Method != http.MethodPut && r.Method != http.MethodPatch {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var update SFxDimensionUpdate
+	if err = json.NewDecoder(r.Body).Decode(&update); err != nil {
+		http.Error(w, fmt.Sprintf("invalid dimension update: %v", err), http.StatusBadRequest)
+		return
+	}
+	update.Method = r.Method
+	update.Token = r.Header.Get("X-SF-Token")
+	update.Key = key
+	update.Value = value
+
+	sfx.lock.Lock()
+	sfx.tokens = append(sfx.tokens, update.Token)
+	sfx.dimensionUpdates = append(sfx.dimensionUpdates, update)
+	dimension := sfx.applyDimensionUpdate(update)
+	sfx.lock.Unlock()
+	writeJSON(w, dimension)
+}
+
+// applyDimensionUpdate must be called with the lock held.
+func (sfx *SFxBackend) applyDimensionUpdate(update SFxDimensionUpdate) SFxDimension {
+	id := dimensionID(update.Key, update.Value)
+	dimension, ok := sfx.dimensions[id]
+	if !ok || update.Method == http.MethodPut {
+		dimension = &SFxDimension{Key: update.Key, Value: update.Value, CustomProperties: map[string]string{}}
+		sfx.dimensions[id] = dimension
+	}
+
+	for property, propertyValue := range update.CustomProperties {
+		if propertyValue == nil {
+			delete(dimension.CustomProperties, property)
+			continue
+		}
+		dimension.CustomProperties[property] = *propertyValue
+	}
+
+	tags := map[string]bool{}
+	for _, tag := range dimension.Tags {
+		tags[tag] = true
+	}
+	for _, tag := range update.Tags {
+		tags[tag] = true
+	}
+	for _, tag := range update.TagsToRemove {
+		delete(tags, tag)
+	}
+	dimension.Tags = nil
+	for tag := range tags {
+		dimension.Tags = append(dimension.Tags, tag)
+	}
+	sort.Strings(dimension.Tags)
+	return dimension.copy()
+}
+
+func (dimension SFxDimension) copy() SFxDimension {
+	properties := make(map[string]string, len(dimension.CustomProperties))
+	for k, v := range dimension.CustomProperties {
+		properties[k] = v
+	}
+	dimension.CustomProperties = properties
+	dimension.Tags = append([]string(nil), dimension.Tags...)
+	return dimension
+}
+
+func dimensionID(key, value string) string {
+	return key + "=" + value
+}
+
+func parseDimensionPath(escapedPath string, allowAgentSuffix bool) (string, string, error) {
+	segments := strings.Split(strings.TrimPrefix(escapedPath, sfxDimensionPath), "/")
+	if allowAgentSuffix && len(segments) == 4 && segments[2] == "_" && segments[3] == "sfxagent" {
+		segments = segments[:2]
+	}
+	if len(segments) != 2 || segments[0] == "" || segments[1] == "" {
+		return "", "", fmt.Errorf("invalid dimension path %q", escapedPath)
+	}
+	key, err := url.PathUnescape(segments[0])
+	if err != nil {
+		return "", "", err
+	}
+	value, err := url.PathUnescape(segments[1])
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+type protobufMessage interface {
+	Unmarshal([]byte) error
+}
+
+func readProtobuf(r *http.Request, msg protobufMessage) error {
+	if contentType := r.Header.Get("Content-Type"); contentType != "application/x-protobuf" {
+		return fmt.Errorf("unsupported content type %q", contentType)
+	}
+	body := r.Body
+	if r.Header.Get("Content-Encoding") == "gzip" {
+		gzipReader, err := gzip.NewReader(r.Body)
+		if err != nil {
+			return fmt.Errorf("invalid gzip body: %w", err)
+		}
+		defer gzipReader.Close()
+		body = gzipReader
+	}
+	content, err := io.ReadAll(body)
+	if err != nil {
+		return err
+	}
+	if err = msg.Unmarshal(content); err != nil {
+		return fmt.Errorf("invalid protobuf body: %w", err)
+	}
+	return nil
+}
+
+func writeOK(w http.ResponseWriter) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write([]byte(`"OK"`))
+}
+
+func writeJSON(w http.ResponseWriter, content any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(content); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	sfxpb "github.com/signalfx/com_signalfx_metrics_protobuf/model"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newStartedSFxBackend(t *testing.T) *SFxBackend {
+	backend, err := NewSFxBackend().WithEndpoint(getAvailableLocalAddress(t)).Build()
+	require.NoError(t, err)
+	require.NoError(t, backend.Start())
+	t.Cleanup(func() { require.NoError(t, backend.Shutdown()) })
+	return backend
+}
+
+func sendToSFxBackend(t *testing.T, method, url string, body []byte, headers map[string]string) *http.Response {
+	req, err := http.NewRequest(method, url, bytes.NewReader(body))
+	require.NoError(t, err)
+	req.Header.Set("X-SF-Token", "token")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	require.NoError(t, resp.Body.Close())
+	return resp
+}
+
+func TestSFxBackendBuilder(t *testing.T) {
+	backend, err := NewSFxBackend().Build()
+	require.EqualError(t, err, "must provide an Endpoint for SFxBackend")
+	assert.Nil(t, backend)
+
+	backend, err = NewSFxBackend().WithEndpoint("localhost:9943").Build()
+	require.NoError(t, err)
+	assert.NotNil(t, backend.Logger)
+	assert.Equal(t, "http://localhost:9943", backend.URL())
+
+	unbuilt := NewSFxBackend()
+	assert.EqualError(t, unbuilt.Start(), "cannot invoke Start() on an SFxBackend that hasn't been built")
+}
+
+func TestSFxBackendDatapoints(t *testing.T) {
+	backend := newStartedSFxBackend(t)
+
+	sfx, err := NewSFxDatapointLoadGenerator().WithEndpoint(backend.Endpoint).WithDatapointsPerBatch(3).Build()
+	require.NoError(t, err)
+	require.NoError(t, sfx.Start())
+	defer func() { require.NoError(t, sfx.Shutdown()) }()
+
+	_, err = sfx.Send(context.Background())
+	require.NoError(t, err)
+
+	datapoints := backend.Datapoints()
+	require.Len(t, datapoints, 3)
+	assert.Equal(t, "soak.load", datapoints[2].Metric)
+
+	backend.Reset()
+	assert.Empty(t, backend.Datapoints())
+}
+
+func TestSFxBackendGzippedEvents(t *testing.T) {
+	backend := newStartedSFxBackend(t)
+
+	category := sfxpb.EventCategory_USER_DEFINED
+	msg := &sfxpb.EventUploadMessage{Events: []*sfxpb.Event{{EventType: "deploy", Category: &category}}}
+	content, err := msg.Marshal()
+	require.NoError(t, err)
+	var gzipped bytes.Buffer
+	writer := gzip.NewWriter(&gzipped)
+	_, err = writer.Write(content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+
+	resp := sendToSFxBackend(t, http.MethodPost, backend.URL()+"/v2/event", gzipped.Bytes(), map[string]string{
+		"Content-Type": "application/x-protobuf", "Content-Encoding": "gzip",
+	})
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	require.NoError(t, backend.AssertEventTypesReceived(t, []string{"deploy"}, time.Second))
+	assert.Equal(t, []string{"token"}, backend.Tokens())
+
+	resp = sendToSFxBackend(t, http.MethodPost, backend.URL()+"/v2/event", []byte("{}"), map[string]string{
+		"Content-Type": "application/json",
+	})
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
+
+func TestSFxBackendDimensionUpdates(t *testing.T) {
+	backend := newStartedSFxBackend(t)
+
+	put, err := json.Marshal(map[string]any{
+		"customProperties": map[string]string{"one": "1", "two": "2"},
+		"tags":             []string{"a"},
+	})
+	require.NoError(t, err)
+	resp := sendToSFxBackend(t, http.MethodPut, backend.URL()+"/v2/dimension/host/my%2Fhost", put, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	patch, err := json.Marshal(map[string]any{
+		"customProperties": map[string]any{"one": nil, "three": "3"},
+		"tags":             []string{"b"},
+		"tagsToRemove":     []string{"a"},
+	})
+	require.NoError(t, err)
+	resp = sendToSFxBackend(t, http.MethodPatch, backend.URL()+"/v2/dimension/host/my%2Fhost/_/sfxagent", patch, nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	require.NoError(t, backend.AssertDimensionProperties(t, "host", "my/host", map[string]string{"two": "2", "three": "3"}, time.Second))
+	dimension, ok := backend.Dimension("host", "my/host")
+	require.True(t, ok)
+	assert.Equal(t, []string{"b"}, dimension.Tags)
+
+	updates := backend.DimensionUpdates()
+	require.Len(t, updates, 2)
+	assert.Equal(t, http.MethodPut, updates[0].Method)
+	assert.Equal(t, http.MethodPatch, updates[1].Method)
+	assert.Equal(t, "token", updates[1].Token)
+	assert.Nil(t, updates[1].CustomProperties["one"])
+	assert.Equal(t, []string{"a"}, updates[1].TagsToRemove)
+
+	// a PUT replaces the existing properties and tags
+	resp = sendToSFxBackend(t, http.MethodPut, backend.URL()+"/v2/dimension/host/my%2Fhost", []byte(`{"customProperties":{"four":"4"}}`), nil)
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	dimension, ok = backend.Dimension("host", "my/host")
+	require.True(t, ok)
+	assert.Equal(t, map[string]string{"four": "4"}, dimension.CustomProperties)
+	assert.Empty(t, dimension.Tags)
+
+	resp = sendToSFxBackend(t, http.MethodGet, backend.URL()+"/v2/dimension/host/unknown", nil, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+	resp = sendToSFxBackend(t, http.MethodPut, backend.URL()+"/v2/dimension/host/my%2Fhost/_/sfxagent", put, nil)
+	assert.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
@@ -0,0 +1,362 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/shirou/gopsutil/v3/process"
+	"go.uber.org/zap"
+)
+
+const bytesPerMiB = 1024 * 1024
+
+// SoakTest drives sustained load from a LoadGenerator through a Collector for a configured Duration,
+// sampling the resident set size of the Collector's process and comparing the datapoints sent with those
+// received downstream.  Its thresholds are intended to catch memory leaks and dropped data that only
+// manifest under prolonged throughput.
+//
+// To be used as a builder whose Build() method provides the actual instance capable of running the test.
+type SoakTest struct {
+	Load            LoadGenerator
+	Received        func() int
+	Duration        time.Duration
+	Warmup          time.Duration
+	SendInterval    time.Duration
+	SampleInterval  time.Duration
+	DrainTimeout    time.Duration
+	MaxRSSGrowthMiB float64
+	MaxDropRatio    float64
+	PID             int32
+	Logger          *zap.Logger
+}
+
+func NewSoakTest() SoakTest {
+	return SoakTest{MaxRSSGrowthMiB: 50}
+}
+
+// Required
+func (soak SoakTest) WithLoadGenerator(load LoadGenerator) SoakTest {
+	soak.Load = load
+	return soak
+}
+
+// Required, typically an OTLPMetricsReceiverSink's DataPointCount
+func (soak SoakTest) WithReceivedCount(received func() int) SoakTest {
+	soak.Received = received
+	return soak
+}
+
+// Required
+func (soak SoakTest) WithDuration(duration time.Duration) SoakTest {
+	soak.Duration = duration
+	return soak
+}
+
+// A tenth of Duration by default.  RSS growth is measured from the end of the warmup.
+func (soak SoakTest) WithWarmup(warmup time.Duration) SoakTest {
+	soak.Warmup = warmup
+	return soak
+}
+
+// 100ms by default
+func (soak SoakTest) WithSendInterval(interval time.Duration) SoakTest {
+	soak.SendInterval = interval
+	return soak
+}
+
+// 1s by default
+func (soak SoakTest) WithSampleInterval(interval time.Duration) SoakTest {
+	soak.SampleInterval = interval
+	return soak
+}
+
+// 30s by default.  How long to wait for in-flight data to be received after load ends.
+func (soak SoakTest) WithDrainTimeout(timeout time.Duration) SoakTest {
+	soak.DrainTimeout = timeout
+	return soak
+}
+
+// 50 MiB by default.  Negative values disable the check.
+func (soak SoakTest) WithMaxRSSGrowthMiB(growth float64) SoakTest {
+	soak.MaxRSSGrowthMiB = growth
+	return soak
+}
+
+// 0 by default.  Negative values disable the check.
+func (soak SoakTest) WithMaxDropRatio(ratio float64) SoakTest {
+	soak.MaxDropRatio = ratio
+	return soak
+}
+
+// The test process by default, for use with a CollectorInProcess.  Use a CollectorProcess' Process.Pid()
+// to monitor a subprocess.
+func (soak SoakTest) WithPID(pid int32) SoakTest {
+	soak.PID = pid
+	return soak
+}
+
+// Nop logger by default
+func (soak SoakTest) WithLogger(logger *zap.Logger) SoakTest {
+	soak.Logger = logger
+	return soak
+}
+
+func (soak SoakTest) Build() (*SoakTest, error) {
+	if soak.Load == nil {
+		return nil, fmt.Errorf("must provide a LoadGenerator for SoakTest")
+	}
+	if soak.Received == nil {
+		return nil, fmt.Errorf("must provide a received count func for SoakTest")
+	}
+	if soak.Duration <= 0 {
+		return nil, fmt.Errorf("must provide a positive Duration for SoakTest")
+	}
+	if soak.Warmup < 0 || soak.Warmup >= soak.Duration {
+		return nil, fmt.Errorf("Warmup must be non-negative and less than Duration: %s", soak.Warmup)
+	}
+	if soak.Warmup == 0 {
+		soak.Warmup = soak.Duration / 10
+	}
+	if soak.SendInterval == 0 {
+		soak.SendInterval = 100 * time.Millisecond
+	}
+	if soak.SampleInterval == 0 {
+		soak.SampleInterval = time.Second
+	}
+	if soak.DrainTimeout == 0 {
+		soak.DrainTimeout = 30 * time.Second
+	}
+	if soak.PID == 0 {
+		soak.PID = int32(os.Getpid())
+	}
+	if soak.Logger == nil {
+		soak.Logger = zap.NewNop()
+	}
+	return &soak, nil
+}
+
+// RSSSample is a resident set size measurement of the monitored process.
+type RSSSample struct {
+	Time time.Time
+	RSS  uint64
+}
+
+// SoakResult summarizes a completed SoakTest run.
+type SoakResult struct {
+	// Samples are the RSS measurements taken after the warmup.
+	Samples    []RSSSample
+	Sent       int
+	Received   int
+	SendErrors int
+	PeakRSS    uint64
+}
+
+// Dropped is the number of sent datapoints that weren't received.
+func (result SoakResult) Dropped() int {
+	if dropped := result.Sent - result.Received; dropped > 0 {
+		return dropped
+	}
+	return 0
+}
+
+// DropRatio is the fraction of sent datapoints that weren't received.
+func (result SoakResult) DropRatio() float64 {
+	if result.Sent == 0 {
+		return 0
+	}
+	return float64(result.Dropped()) / float64(result.Sent)
+}
+
+// BaselineRSS is the median of the earliest post-warmup samples.
+func (result SoakResult) BaselineRSS() uint64 {
+	return medianRSS(result.Samples[:rssWindow(result.Samples)])
+}
+
+// FinalRSS is the median of the latest samples.
+func (result SoakResult) FinalRSS() uint64 {
+	return medianRSS(result.Samples[len(result.Samples)-rssWindow(result.Samples):])
+}
+
+// RSSGrowthMiB is the difference between the final and baseline RSS, which is negative if memory was released.
+func (result SoakResult) RSSGrowthMiB() float64 {
+	return (float64(result.FinalRSS()) - float64(result.BaselineRSS())) / bytesPerMiB
+}
+
+func (result SoakResult) String() string {
+	return fmt.Sprintf(
+		"sent: %d, received: %d, dropped: %d (%.4f), send errors: %d, rss baseline: %.1f MiB, final: %.1f MiB, peak: %.1f MiB, growth: %.1f MiB",
+		result.Sent, result.Received, result.Dropped(), result.DropRatio(), result.SendErrors,
+		float64(result.BaselineRSS())/bytesPerMiB, float64(result.FinalRSS())/bytesPerMiB,
+		float64(result.PeakRSS)/bytesPerMiB, result.RSSGrowthMiB(),
+	)
+}
+
+// rssWindow is the number of samples to smooth over for baseline and final measurements, to avoid
+// mistaking garbage collection cycles for growth.
+func rssWindow(samples []RSSSample) int {
+	window := len(samples) / 10
+	if window < 1 {
+		window = 1
+	}
+	if window > len(samples) {
+		window = len(samples)
+	}
+	return window
+}
+
+func medianRSS(samples []RSSSample) uint64 {
+	if len(samples) == 0 {
+		return 0
+	}
+	values := make([]uint64, len(samples))
+	for i, sample := range samples {
+		values[i] = sample.RSS
+	}
+	sort.Slice(values, func(i, j int) bool { return values[i] < values[j] })
+	return values[len(values)/2]
+}
+
+// Run starts the LoadGenerator and sends a batch every SendInterval for the Duration, sampling RSS every
+// SampleInterval, then waits up to DrainTimeout for all sent datapoints to be received.  The LoadGenerator
+// is shut down before returning.  Threshold violations aren't errors; use Verify() on the result.
+func (soak *SoakTest) Run(ctx context.Context) (SoakResult, error) {
+	var result SoakResult
+	proc, err := process.NewProcessWithContext(ctx, soak.PID)
+	if err != nil {
+		return result, fmt.Errorf("failed to monitor process %d: %w", soak.PID, err)
+	}
+	if err = soak.Load.Start(); err != nil {
+		return result, fmt.Errorf("failed to start load generator: %w", err)
+	}
+	defer func() {
+		if shutdownErr := soak.Load.Shutdown(); shutdownErr != nil {
+			soak.Logger.Warn("failed to shut down load generator", zap.Error(shutdownErr))
+		}
+	}()
+
+	loadCtx, cancel := context.WithTimeout(ctx, soak.Duration)
+	defer cancel()
+
+	wg := sync.WaitGroup{}
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		soak.sendLoad(loadCtx, &result)
+	}()
+
+	start := time.Now()
+	sampleErr := soak.sampleRSS(loadCtx, proc, start.Add(soak.Warmup), &result)
+	cancel()
+	wg.Wait()
+	if sampleErr != nil {
+		return result, sampleErr
+	}
+	if ctx.Err() != nil {
+		return result, ctx.Err()
+	}
+	if len(result.Samples) == 0 {
+		return result, fmt.Errorf("no RSS samples taken after the %s warmup", soak.Warmup)
+	}
+
+	deadline := time.Now().Add(soak.DrainTimeout)
+	for result.Received = soak.Received(); result.Received < result.Sent && time.Now().Before(deadline); {
+		time.Sleep(soak.SendInterval)
+		result.Received = soak.Received()
+	}
+
+	soak.Logger.Info("soak test complete", zap.Duration("duration", time.Since(start)), zap.Stringer("result", result))
+	return result, nil
+}
+
+func (soak *SoakTest) sendLoad(ctx context.Context, result *SoakResult) {
+	ticker := time.NewTicker(soak.SendInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// attempted datapoints are counted as sent so that rejections are reported as drops
+			sent, err := soak.Load.Send(ctx)
+			if err != nil && ctx.Err() != nil {
+				// the batch was interrupted by the end of the test rather than rejected
+				return
+			}
+			result.Sent += sent
+			if err != nil {
+				result.SendErrors++
+				soak.Logger.Debug("failed sending load", zap.Error(err))
+			}
+		}
+	}
+}
+
+func (soak *SoakTest) sampleRSS(ctx context.Context, proc *process.Process, warmupEnd time.Time, result *SoakResult) error {
+	ticker := time.NewTicker(soak.SampleInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case now := <-ticker.C:
+			info, err := proc.MemoryInfoWithContext(ctx)
+			if err != nil {
+				if ctx.Err() != nil {
+					return nil
+				}
+				return fmt.Errorf("failed to sample RSS of process %d: %w", soak.PID, err)
+			}
+			if info.RSS > result.PeakRSS {
+				result.PeakRSS = info.RSS
+			}
+			if now.Before(warmupEnd) {
+				continue
+			}
+			result.Samples = append(result.Samples, RSSSample{Time: now, RSS: info.RSS})
+		}
+	}
+}
+
+// Verify determines if the result is within the SoakTest's thresholds, returning an error describing
+// every violation.
+func (soak *SoakTest) Verify(result SoakResult) error {
+	var violations []string
+	if soak.MaxRSSGrowthMiB >= 0 && len(result.Samples) != 0 {
+		if growth := result.RSSGrowthMiB(); growth > soak.MaxRSSGrowthMiB {
+			violations = append(violations, fmt.Sprintf("RSS grew %.1f MiB, exceeding %.1f MiB", growth, soak.MaxRSSGrowthMiB))
+		}
+	}
+	if soak.MaxDropRatio >= 0 {
+		if ratio := result.DropRatio(); ratio > soak.MaxDropRatio {
+			violations = append(violations, fmt.Sprintf(
+				"dropped %d of %d datapoints (%.4f), exceeding ratio %.4f", result.Dropped(), result.Sent, ratio, soak.MaxDropRatio,
+			))
+		}
+	}
+	if result.Sent == 0 {
+		violations = append(violations, "no datapoints were sent")
+	}
+	if len(violations) != 0 {
+		return fmt.Errorf("soak test failed: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+type fakeLoadGenerator struct {
+	err      error
+	sent     int
+	perBatch int
+	started  bool
+	shutdown bool
+	lock     sync.Mutex
+}
+
+func (f *fakeLoadGenerator) Start() error {
+	f.started = true
+	return nil
+}
+
+func (f *fakeLoadGenerator) Send(context.Context) (int, error) {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	if f.err == nil {
+		f.sent += f.perBatch
+	}
+	return f.perBatch, f.err
+}
+
+func (f *fakeLoadGenerator) Shutdown() error {
+	f.shutdown = true
+	return nil
+}
+
+func (f *fakeLoadGenerator) received() int {
+	f.lock.Lock()
+	defer f.lock.Unlock()
+	return f.sent
+}
+
+func TestSoakTestBuilderMethods(t *testing.T) {
+	soak := NewSoakTest()
+	assert.Equal(t, float64(50), soak.MaxRSSGrowthMiB)
+	assert.Zero(t, soak.MaxDropRatio)
+
+	load := &fakeLoadGenerator{}
+	withLoad := soak.WithLoadGenerator(load)
+	assert.Same(t, load, withLoad.Load)
+	assert.Nil(t, soak.Load)
+
+	assert.NotNil(t, soak.WithReceivedCount(load.received).Received)
+	assert.Equal(t, time.Minute, soak.WithDuration(time.Minute).Duration)
+	assert.Equal(t, time.Second, soak.WithWarmup(time.Second).Warmup)
+	assert.Equal(t, time.Second, soak.WithSendInterval(time.Second).SendInterval)
+	assert.Equal(t, time.Second, soak.WithSampleInterval(time.Second).SampleInterval)
+	assert.Equal(t, time.Second, soak.WithDrainTimeout(time.Second).DrainTimeout)
+	assert.Equal(t, float64(10), soak.WithMaxRSSGrowthMiB(10).MaxRSSGrowthMiB)
+	assert.Equal(t, 0.5, soak.WithMaxDropRatio(0.5).MaxDropRatio)
+	assert.Equal(t, int32(123), soak.WithPID(123).PID)
+
+	logger := zap.NewNop()
+	assert.Same(t, logger, soak.WithLogger(logger).Logger)
+}
+
+func TestSoakTestBuild(t *testing.T) {
+	load := &fakeLoadGenerator{}
+
+	_, err := NewSoakTest().Build()
+	assert.EqualError(t, err, "must provide a LoadGenerator for SoakTest")
+
+	_, err = NewSoakTest().WithLoadGenerator(load).Build()
+	assert.EqualError(t, err, "must provide a received count func for SoakTest")
+
+	_, err = NewSoakTest().WithLoadGenerator(load).WithReceivedCount(load.received).Build()
+	assert.EqualError(t, err, "must provide a positive Duration for SoakTest")
+
+	_, err = NewSoakTest().WithLoadGenerator(load).WithReceivedCount(load.received).
+		WithDuration(time.Second).WithWarmup(time.Second).Build()
+	assert.EqualError(t, err, "Warmup must be non-negative and less than Duration: 1s")
+
+	soak, err := NewSoakTest().WithLoadGenerator(load).WithReceivedCount(load.received).WithDuration(time.Minute).Build()
+	require.NoError(t, err)
+	assert.Equal(t, 6*time.Second, soak.Warmup)
+	assert.Equal(t, 100*time.Millisecond, soak.SendInterval)
+	assert.Equal(t, time.Second, soak.SampleInterval)
+	assert.Equal(t, 30*time.Second, soak.DrainTimeout)
+	assert.NotZero(t, soak.PID)
+	assert.NotNil(t, soak.Logger)
+}
+
+func TestSoakTestRun(t *testing.T) {
+	load := &fakeLoadGenerator{perBatch: 10}
+	soak, err := NewSoakTest().WithLoadGenerator(load).WithReceivedCount(load.received).
+		WithDuration(500 * time.Millisecond).WithWarmup(100 * time.Millisecond).
+		WithSendInterval(10 * time.Millisecond).WithSampleInterval(20 * time.Millisecond).
+		WithMaxRSSGrowthMiB(-1).Build()
+	require.NoError(t, err)
+
+	result, err := soak.Run(context.Background())
+	require.NoError(t, err)
+	assert.True(t, load.started)
+	assert.True(t, load.shutdown)
+
+	assert.Positive(t, result.Sent)
+	assert.Equal(t, result.Sent, result.Received)
+	assert.Zero(t, result.SendErrors)
+	assert.Zero(t, result.Dropped())
+	assert.NotEmpty(t, result.Samples)
+	assert.NotZero(t, result.BaselineRSS())
+	assert.GreaterOrEqual(t, result.PeakRSS, result.FinalRSS())
+	assert.NoError(t, soak.Verify(result))
+}
+
+func TestSoakTestRunReportsRejectedLoadAsDropped(t *testing.T) {
+	load := &fakeLoadGenerator{perBatch: 10, err: fmt.Errorf("rejected")}
+	soak, err := NewSoakTest().WithLoadGenerator(load).WithReceivedCount(load.received).
+		WithDuration(200 * time.Millisecond).WithWarmup(10 * time.Millisecond).
+		WithSendInterval(10 * time.Millisecond).WithSampleInterval(20 * time.Millisecond).
+		WithDrainTimeout(10 * time.Millisecond).Build()
+	require.NoError(t, err)
+
+	result, err := soak.Run(context.Background())
+	require.NoError(t, err)
+	assert.Positive(t, result.SendErrors)
+	assert.Equal(t, result.Sent, result.Dropped())
+	assert.Equal(t, float64(1), result.DropRatio())
+
+	err = soak.Verify(result)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), fmt.Sprintf("dropped %d of %d datapoints (1.0000), exceeding ratio 0.0000", result.Sent, result.Sent))
+}
+
+func TestSoakTestRunCanceled(t *testing.T) {
+	load := &fakeLoadGenerator{perBatch: 10}
+	soak, err := NewSoakTest().WithLoadGenerator(load).WithReceivedCount(load.received).
+		WithDuration(time.Minute).Build()
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	_, err = soak.Run(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.True(t, load.shutdown)
+}
+
+func TestSoakTestVerify(t *testing.T) {
+	soak := &SoakTest{MaxRSSGrowthMiB: 10, MaxDropRatio: 0.01}
+	samples := []RSSSample{
+		{RSS: 100 * bytesPerMiB}, {RSS: 105 * bytesPerMiB}, {RSS: 130 * bytesPerMiB},
+	}
+
+	result := SoakResult{Sent: 1000, Received: 995, Samples: samples[:2], PeakRSS: 105 * bytesPerMiB}
+	assert.Equal(t, uint64(100*bytesPerMiB), result.BaselineRSS())
+	assert.Equal(t, uint64(105*bytesPerMiB), result.FinalRSS())
+	assert.Equal(t, float64(5), result.RSSGrowthMiB())
+	assert.Equal(t, 0.005, result.DropRatio())
+	assert.NoError(t, soak.Verify(result))
+
+	result = SoakResult{Sent: 1000, Received: 900, Samples: samples, PeakRSS: 130 * bytesPerMiB}
+	assert.Equal(t, float64(30), result.RSSGrowthMiB())
+	assert.EqualError(t, soak.Verify(result),
+		"soak test failed: RSS grew 30.0 MiB, exceeding 10.0 MiB; dropped 100 of 1000 datapoints (0.1000), exceeding ratio 0.0100",
+	)
+
+	disabled := &SoakTest{MaxRSSGrowthMiB: -1, MaxDropRatio: -1}
+	assert.NoError(t, disabled.Verify(result))
+
+	assert.EqualError(t, disabled.Verify(SoakResult{}), "soak test failed: no datapoints were sent")
+}
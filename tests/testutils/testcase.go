@@ -20,7 +20,9 @@ import (
 	"os"
 	"path"
 	"runtime"
+	"strconv"
 	"strings"
+	"sync"
 	"testing"
 	"time"
 
@@ -42,12 +44,15 @@ type Testcase struct {
 	OTLPMetricsReceiverSink *OTLPMetricsReceiverSink
 	OTLPEndpoint            string
 	ID                      string
+	ports                   map[string]uint16
+	collectorVersion        *CollectorVersion
+	portsLock               sync.Mutex
 }
 
 // NewTestcase is the recommended constructor that will automatically configure an OTLPMetricsReceiverSink
 // with available endpoint and ObservedLogs.
 func NewTestcase(t *testing.T) *Testcase {
-	tc := Testcase{T: t}
+	tc := &Testcase{T: t, ports: map[string]uint16{}}
 	var logCore zapcore.Core
 	logCore, tc.ObservedLogs = observer.New(zap.DebugLevel)
 	tc.Logger = zap.New(logCore)
@@ -62,7 +67,7 @@ func NewTestcase(t *testing.T) *Testcase {
 	id, err := uuid.NewRandom()
 	require.NoError(tc, err)
 	tc.ID = id.String()
-	return &tc
+	return tc
 }
 
 // SkipIfNotContainer will skip the test if SPLUNK_OTEL_COLLECTOR_IMAGE env var is empty, otherwise it will
@@ -86,9 +91,44 @@ func (t *Testcase) ResourceMetrics(filename string) *ResourceMetrics {
 	return expectedResourceMetrics
 }
 
+// Loads and validates a ResourceAttributeSchema instance, assuming it's located in ./testdata/resource_attribute_schemas
+func (t *Testcase) ResourceAttributeSchema(filename string) *ResourceAttributeSchema {
+	schema, err := LoadResourceAttributeSchema(
+		path.Join(".", "testdata", "resource_attribute_schemas", filename),
+	)
+	require.NoError(t, err)
+	require.NotNil(t, schema)
+	return schema
+}
+
+// AllocatePort provides an available host port for the provided name, allocating it on first use.  All allocated
+// ports are rendered to the Collector config as environment variables of their name (e.g. "${MY_SERVICE_PORT}").
+func (t *Testcase) AllocatePort(name string) uint16 {
+	t.portsLock.Lock()
+	defer t.portsLock.Unlock()
+	if port, ok := t.ports[name]; ok {
+		return port
+	}
+	port := GetAvailablePort(t.T)
+	t.ports[name] = port
+	return port
+}
+
 // Builds and starts all provided Container builder instances, returning them and a validating stop function.
+// Any dynamic exposed ports are bound to host ports provided by Testcase.AllocatePort().  The test is skipped
+// if any Container has been configured to skip the current Platform().
 func (t *Testcase) Containers(builders ...Container) (containers []*Container, stop func()) {
+	platform := Platform()
+	for _, builder := range builders {
+		if builder.SkipsPlatform(platform) {
+			t.Skipf("skipping test: container %q isn't supported on %s", builder.ContainerName, platform)
+		}
+	}
+
 	for _, builder := range builders {
+		for name, containerPort := range builder.DynamicPorts {
+			builder = builder.WithExposedPorts(fmt.Sprintf("%d:%s", t.AllocatePort(name), containerPort))
+		}
 		containers = append(containers, builder.Build())
 	}
 
@@ -108,17 +148,32 @@ func (t *Testcase) Containers(builders ...Container) (containers []*Container, s
 // SplunkOtelCollector builds and starts a collector container or process using the desired config filename
 // (assuming it's in the ./testdata directory) returning it and a validating shutdown function.
 func (t *Testcase) SplunkOtelCollector(configFilename string) (collector Collector, shutdown func()) {
-	return t.splunkOtelCollector(configFilename, nil)
+	return t.splunkOtelCollector(configFilename, nil, nil)
 }
 
 // SplunkOtelCollectorWithEnv works as Testcase.SplunkOtelCollector but also passes an environment variable map
 func (t *Testcase) SplunkOtelCollectorWithEnv(configFilename string, env map[string]string) (collector Collector, shutdown func()) {
-	return t.splunkOtelCollector(configFilename, env)
+	return t.splunkOtelCollector(configFilename, env, nil)
+}
+
+// SplunkOtelCollectorWithConfigVars works as Testcase.SplunkOtelCollector but renders the config as a Go template
+// with the provided variables (e.g. container IPs and tokens).  The OTLP_ENDPOINT, SPLUNK_TEST_ID, and allocated
+// port variables otherwise provided as environment variables are also available (e.g. "{{ .OTLP_ENDPOINT }}").
+func (t *Testcase) SplunkOtelCollectorWithConfigVars(configFilename string, vars map[string]any) (collector Collector, shutdown func()) {
+	return t.splunkOtelCollector(configFilename, nil, vars)
 }
 
-func (t *Testcase) splunkOtelCollector(configFilename string, env map[string]string) (collector Collector, shutdown func()) {
-	if image := os.Getenv("SPLUNK_OTEL_COLLECTOR_IMAGE"); strings.TrimSpace(image) != "" {
+func (t *Testcase) splunkOtelCollector(configFilename string, env map[string]string, vars map[string]any) (collector Collector, shutdown func()) {
+	image := os.Getenv("SPLUNK_OTEL_COLLECTOR_IMAGE")
+	if t.collectorVersion != nil {
+		image = t.collectorVersion.Image
+	}
+	if strings.TrimSpace(image) != "" {
 		cc := NewCollectorContainer().WithImage(image)
+		if coverDir := os.Getenv("GOCOVERDIR"); coverDir != "" {
+			// CollectorProcesses already inherit it
+			cc = cc.WithCoverDir(coverDir)
+		}
 		collector = &cc
 	} else {
 		cp := NewCollectorProcess()
@@ -136,6 +191,12 @@ func (t *Testcase) splunkOtelCollector(configFilename string, env map[string]str
 		"SPLUNK_TEST_ID": t.ID,
 	}
 
+	t.portsLock.Lock()
+	for name, port := range t.ports {
+		envVars[name] = strconv.Itoa(int(port))
+	}
+	t.portsLock.Unlock()
+
 	for k, v := range env {
 		envVars[k] = v
 	}
@@ -145,6 +206,14 @@ func (t *Testcase) splunkOtelCollector(configFilename string, env map[string]str
 		path.Join(".", "testdata", configFilename),
 	).WithEnv(envVars).WithLogLevel("debug").WithLogger(t.Logger)
 
+	if vars != nil {
+		configVars := make(map[string]any, len(envVars)+len(vars))
+		for k, v := range envVars {
+			configVars[k] = v
+		}
+		collector = collector.WithConfigVars(configVars).WithConfigVars(vars)
+	}
+
 	splunkEnv := map[string]string{}
 	for _, s := range os.Environ() {
 		split := strings.Split(s, "=")
@@ -0,0 +1,234 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const defaultReleaseImage = "quay.io/signalfx/splunk-otel-collector:latest"
+
+// A CollectorVersion is a Collector a VersionMatrix runs its spec against: a CollectorContainer of the
+// Image, or a CollectorProcess of the current build if the Image is empty.
+type CollectorVersion struct {
+	Name  string
+	Image string
+}
+
+// CurrentCollectorVersion is the Collector a Testcase runs by default: the SPLUNK_OTEL_COLLECTOR_IMAGE
+// image if set, otherwise the bin/otelcol CollectorProcess.
+func CurrentCollectorVersion() CollectorVersion {
+	return CollectorVersion{Name: "current", Image: strings.TrimSpace(os.Getenv("SPLUNK_OTEL_COLLECTOR_IMAGE"))}
+}
+
+// ReleaseCollectorVersion is the last released Collector: the SPLUNK_OTEL_COLLECTOR_RELEASE_IMAGE image if set,
+// otherwise quay.io/signalfx/splunk-otel-collector:latest.
+func ReleaseCollectorVersion() CollectorVersion {
+	image := strings.TrimSpace(os.Getenv("SPLUNK_OTEL_COLLECTOR_RELEASE_IMAGE"))
+	if image == "" {
+		image = defaultReleaseImage
+	}
+	return CollectorVersion{Name: "release", Image: image}
+}
+
+// A MatrixSpec starts the tested Collector and its dependencies with the provided Testcase, returning a
+// function stopping them once the VersionMatrix has collected the received telemetry.
+type MatrixSpec func(tc *Testcase) (stop func())
+
+// VersionMatrix runs the same integration spec against multiple Collector versions, each in its own subtest
+// and Testcase, and diffs the telemetry they produce.  It's intended to catch unintended metric name, type, and
+// attribute changes between releases: the metrics received from each version are compared to those of the
+// first, baseline version by their resource attribute keys, metric names and types, and label keys, regardless
+// of their values.
+//
+// To be used as a builder whose Run() method runs the spec.
+type VersionMatrix struct {
+	Versions           []CollectorVersion
+	AllowedChanges     []string
+	Timeout            time.Duration
+	CollectionDuration time.Duration
+}
+
+// Compares the current build to the last release by default.
+func NewVersionMatrix() VersionMatrix {
+	return VersionMatrix{
+		Versions:           []CollectorVersion{ReleaseCollectorVersion(), CurrentCollectorVersion()},
+		Timeout:            30 * time.Second,
+		CollectionDuration: 10 * time.Second,
+	}
+}
+
+// The first version is the baseline the others are compared to.  Version names must be unique.
+func (matrix VersionMatrix) WithVersions(versions ...CollectorVersion) VersionMatrix {
+	matrix.Versions = versions
+	return matrix
+}
+
+// Regular expressions fully matching the intended telemetry changes, like `metric my\.renamed\.metric .*`,
+// which aren't reported by the diff.  See TelemetrySignatures() for their format.
+func (matrix VersionMatrix) WithAllowedChanges(patterns ...string) VersionMatrix {
+	matrix.AllowedChanges = append(matrix.AllowedChanges, patterns...)
+	return matrix
+}
+
+// 30s by default.  How long to wait for the first metrics from each version.
+func (matrix VersionMatrix) WithTimeout(timeout time.Duration) VersionMatrix {
+	matrix.Timeout = timeout
+	return matrix
+}
+
+// 10s by default.  How long to collect the metrics of each version after the first are received, which should
+// cover the collection intervals of the tested receivers.
+func (matrix VersionMatrix) WithCollectionDuration(duration time.Duration) VersionMatrix {
+	matrix.CollectionDuration = duration
+	return matrix
+}
+
+// Run runs the spec against every version, and asserts that the telemetry received from each matches that of
+// the baseline version, returning the telemetry by version name.
+func (matrix VersionMatrix) Run(t *testing.T, spec MatrixSpec) map[string]ResourceMetrics {
+	require.NotEmpty(t, matrix.Versions, "no Collector versions to run")
+	allowed := make([]*regexp.Regexp, 0, len(matrix.AllowedChanges))
+	for _, pattern := range matrix.AllowedChanges {
+		re, err := regexp.Compile(fmt.Sprintf("^(?:%s)$", pattern))
+		require.NoError(t, err, "invalid allowed change pattern")
+		allowed = append(allowed, re)
+	}
+
+	names := map[string]bool{}
+	for _, version := range matrix.Versions {
+		require.False(t, names[version.Name], "duplicate Collector version name %q", version.Name)
+		names[version.Name] = true
+	}
+
+	received := map[string]ResourceMetrics{}
+	for _, version := range matrix.Versions {
+		version := version
+		t.Run(version.Name, func(t *testing.T) {
+			received[version.Name] = matrix.collect(t, version, spec)
+		})
+	}
+
+	baseline := matrix.Versions[0]
+	baselineTelemetry, ok := received[baseline.Name]
+	if !ok {
+		return received
+	}
+	for _, version := range matrix.Versions[1:] {
+		telemetry, ok := received[version.Name]
+		if !ok {
+			continue
+		}
+		added, removed := DiffTelemetry(baselineTelemetry, telemetry)
+		added, removed = withoutAllowed(added, allowed), withoutAllowed(removed, allowed)
+		assert.Empty(t, added, "telemetry of %q (%s) not produced by %q (%s)", version.Name, version.Image, baseline.Name, baseline.Image)
+		assert.Empty(t, removed, "telemetry of %q (%s) not produced by %q (%s)", baseline.Name, baseline.Image, version.Name, version.Image)
+	}
+	return received
+}
+
+func (matrix VersionMatrix) collect(t *testing.T, version CollectorVersion, spec MatrixSpec) ResourceMetrics {
+	tc := NewTestcase(t)
+	tc.collectorVersion = &version
+	defer tc.PrintLogsOnFailure()
+	defer tc.ShutdownOTLPMetricsReceiverSink()
+
+	stop := spec(tc)
+	defer stop()
+
+	sink := tc.OTLPMetricsReceiverSink
+	require.Eventually(t, func() bool {
+		return sink.DataPointCount() > 0
+	}, matrix.Timeout, 10*time.Millisecond, "Failed to receive any metrics from %s", version.Name)
+	time.Sleep(matrix.CollectionDuration)
+
+	telemetry, err := PDataToResourceMetrics(sink.AllMetrics()...)
+	require.NoError(t, err)
+	return FlattenResourceMetrics(telemetry)
+}
+
+// DiffTelemetry returns the TelemetrySignatures of the compared ResourceMetrics that aren't in the baseline,
+// and those of the baseline that aren't in the compared ResourceMetrics.
+func DiffTelemetry(baseline, compared ResourceMetrics) (added, removed []string) {
+	baselineSignatures := map[string]bool{}
+	for _, signature := range TelemetrySignatures(baseline) {
+		baselineSignatures[signature] = true
+	}
+	for _, signature := range TelemetrySignatures(compared) {
+		if !baselineSignatures[signature] {
+			added = append(added, signature)
+		}
+		delete(baselineSignatures, signature)
+	}
+	for signature := range baselineSignatures {
+		removed = append(removed, signature)
+	}
+	sort.Strings(removed)
+	return added, removed
+}
+
+// TelemetrySignatures returns the sorted, unique descriptions of the shape of the ResourceMetrics, regardless
+// of their values: `resource attribute <key>`, `metric <name> <type>`, and `metric <name> label <key>`.
+func TelemetrySignatures(resourceMetrics ResourceMetrics) []string {
+	signatures := map[string]bool{}
+	for _, rm := range resourceMetrics.ResourceMetrics {
+		for key := range rm.Resource.Attributes {
+			signatures[fmt.Sprintf("resource attribute %s", key)] = true
+		}
+		for _, sm := range rm.ILMs {
+			for _, metric := range sm.Metrics {
+				signatures[fmt.Sprintf("metric %s %s", metric.Name, metric.Type)] = true
+				if metric.Labels == nil {
+					continue
+				}
+				for key := range *metric.Labels {
+					signatures[fmt.Sprintf("metric %s label %s", metric.Name, key)] = true
+				}
+			}
+		}
+	}
+	sorted := make([]string, 0, len(signatures))
+	for signature := range signatures {
+		sorted = append(sorted, signature)
+	}
+	sort.Strings(sorted)
+	return sorted
+}
+
+func withoutAllowed(signatures []string, allowed []*regexp.Regexp) []string {
+	var remaining []string
+	for _, signature := range signatures {
+		isAllowed := false
+		for _, re := range allowed {
+			if re.MatchString(signature) {
+				isAllowed = true
+				break
+			}
+		}
+		if !isAllowed {
+			remaining = append(remaining, signature)
+		}
+	}
+	return remaining
+}
@@ -0,0 +1,106 @@
+// Copyright Splunk, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package testutils
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func matrixTelemetry(metricName string, labels map[string]string, resourceAttrs map[string]any) ResourceMetrics {
+	return ResourceMetrics{
+		ResourceMetrics: []ResourceMetric{
+			{
+				Resource: Resource{Attributes: resourceAttrs},
+				ILMs: []ScopeMetrics{
+					{
+						Metrics: []Metric{
+							{Name: metricName, Type: IntGauge, Labels: &labels, Value: 1},
+							{Name: "unlabeled", Type: DoubleMonotonicCumulativeSum, Value: 2.0},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func TestTelemetrySignatures(t *testing.T) {
+	telemetry := matrixTelemetry("my.gauge", map[string]string{"b": "1", "a": "2"}, map[string]any{"host.name": "h"})
+	assert.Equal(t, []string{
+		"metric my.gauge IntGauge",
+		"metric my.gauge label a",
+		"metric my.gauge label b",
+		"metric unlabeled DoubleMonotonicCumulativeSum",
+		"resource attribute host.name",
+	}, TelemetrySignatures(telemetry))
+}
+
+func TestDiffTelemetry(t *testing.T) {
+	baseline := matrixTelemetry("my.gauge", map[string]string{"a": "1"}, map[string]any{"host.name": "h"})
+
+	// values aren't compared
+	added, removed := DiffTelemetry(baseline, matrixTelemetry("my.gauge", map[string]string{"a": "2"}, map[string]any{"host.name": "other"}))
+	assert.Empty(t, added)
+	assert.Empty(t, removed)
+
+	added, removed = DiffTelemetry(baseline, matrixTelemetry("my.renamed.gauge", map[string]string{"b": "1"}, map[string]any{"os.type": "linux"}))
+	assert.Equal(t, []string{
+		"metric my.renamed.gauge IntGauge",
+		"metric my.renamed.gauge label b",
+		"resource attribute os.type",
+	}, added)
+	assert.Equal(t, []string{
+		"metric my.gauge IntGauge",
+		"metric my.gauge label a",
+		"resource attribute host.name",
+	}, removed)
+}
+
+func TestWithoutAllowedChanges(t *testing.T) {
+	matrix := NewVersionMatrix().WithAllowedChanges(`metric my\.gauge .*`, "resource attribute os.type")
+	var allowed []*regexp.Regexp
+	for _, pattern := range matrix.AllowedChanges {
+		allowed = append(allowed, regexp.MustCompile("^(?:"+pattern+")$"))
+	}
+	assert.Equal(t, []string{"metric my.gauge.total IntGauge", "resource attribute os.type.id"}, withoutAllowed([]string{
+		"metric my.gauge IntGauge",
+		"metric my.gauge label a",
+		"metric my.gauge.total IntGauge",
+		"resource attribute os.type",
+		"resource attribute os.type.id",
+	}, allowed))
+}
+
+func TestCollectorVersions(t *testing.T) {
+	t.Setenv("SPLUNK_OTEL_COLLECTOR_IMAGE", "")
+	t.Setenv("SPLUNK_OTEL_COLLECTOR_RELEASE_IMAGE", "")
+	assert.Equal(t, []CollectorVersion{
+		{Name: "release", Image: "quay.io/signalfx/splunk-otel-collector:latest"},
+		{Name: "current"},
+	}, NewVersionMatrix().Versions)
+
+	t.Setenv("SPLUNK_OTEL_COLLECTOR_IMAGE", "otelcol:dev")
+	t.Setenv("SPLUNK_OTEL_COLLECTOR_RELEASE_IMAGE", "quay.io/signalfx/splunk-otel-collector:0.53.0")
+	assert.Equal(t, []CollectorVersion{
+		{Name: "release", Image: "quay.io/signalfx/splunk-otel-collector:0.53.0"},
+		{Name: "current", Image: "otelcol:dev"},
+	}, NewVersionMatrix().Versions)
+
+	matrix := NewVersionMatrix().WithVersions(CollectorVersion{Name: "0.52.0", Image: "quay.io/signalfx/splunk-otel-collector:0.52.0"})
+	assert.Len(t, matrix.Versions, 1)
+}